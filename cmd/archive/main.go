@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/app"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/repositories"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+func main() {
+	flags := flag.NewFlagSet("archive", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: archive")
+		fmt.Fprintln(os.Stderr, "\nRuns one archive lifecycle pass: moves unpublished videos past their tenant's")
+		fmt.Fprintln(os.Stderr, "retention rules to Glacier and completes finished restores. Schedule it with cron.")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	logger := logger.New(cfg.LogLevel, cfg.Environment)
+	defer logger.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg, logger); err != nil {
+		fmt.Fprintf(os.Stderr, "archive: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run wires the archive service and performs one lifecycle pass
+func run(ctx context.Context, cfg *config.Config, logger *logger.Logger) error {
+	if _, err := app.SetupEncryption(ctx, cfg, logger); err != nil {
+		return err
+	}
+
+	database, err := db.New(cfg.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	placements, err := app.NewPlacements(cfg)
+	if err != nil {
+		return err
+	}
+
+	storage, err := app.NewArchiveStorage(cfg, placements, logger)
+	if err != nil {
+		return err
+	}
+
+	archive := services.NewArchiveService(
+		repositories.NewVideoRepository(database.DB),
+		repositories.NewWorkspaceRepository(database.DB),
+		repositories.NewRetentionPolicyRepository(database.DB),
+		storage,
+		services.NewResidencyService(repositories.NewTenantRepository(database.DB), placements, logger),
+		services.NewJobService(repositories.NewJobRepository(database.DB), time.Duration(cfg.JobRetention)*time.Second, clock.System, logger),
+		clock.System,
+		logger,
+	)
+
+	report, err := archive.RunLifecycle(ctx)
+	if report != nil {
+		fmt.Printf("tenants: %d, archived: %d, restored: %d, failed: %d\n",
+			report.Tenants, report.Archived, report.Restored, report.Failed)
+	}
+	if err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d videos failed, see the logs", report.Failed)
+	}
+	return nil
+}
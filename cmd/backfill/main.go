@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/app"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/repositories"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+const usage = `Usage: backfill <command> [flags]
+
+Commands:
+  start    Queue a backfill (-tenant, -platform, -workspace)
+  status   List a tenant's backfills and their progress (-tenant)
+  run      Advance the due backfills once, as the stats-backfill job does
+
+A backfill imports the past daily stats of a tenant's videos on a platform, up
+to the day it was queued. The server's stats-backfill job runs queued
+backfills a few platform requests at a time; run is for servers with
+SCHEDULER_ENABLED=false.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	logger := logger.New(cfg.LogLevel, cfg.Environment)
+	defer logger.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var command func(context.Context, services.StatsBackfillService, []string) error
+	switch os.Args[1] {
+	case "start":
+		command = start
+	case "status":
+		command = status
+	case "run":
+		command = run
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err := withService(ctx, cfg, logger, func(backfills services.StatsBackfillService) error {
+		return command(ctx, backfills, os.Args[2:])
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// withService wires the backfill service and calls fn with it
+func withService(ctx context.Context, cfg *config.Config, logger *logger.Logger, fn func(services.StatsBackfillService) error) error {
+	if _, err := app.SetupEncryption(ctx, cfg, logger); err != nil {
+		return err
+	}
+
+	database, err := db.New(cfg.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	return fn(services.NewStatsBackfillService(
+		repositories.NewStatsBackfillRepository(database.DB),
+		repositories.NewVideoStatsRepository(database.DB),
+		repositories.NewVideoRepository(database.DB),
+		repositories.NewWorkspaceRepository(database.DB),
+		partners.New,
+		services.NewJobService(repositories.NewJobRepository(database.DB), time.Duration(cfg.JobRetention)*time.Second, clock.System, logger),
+		cfg.StatsBackfillRequests,
+		time.Duration(cfg.StatsBackfillQuotaBackoff)*time.Second,
+		cfg.StatsSnapshotRetentionMonths,
+		clock.System,
+		logger,
+	))
+}
+
+// start queues a backfill
+func start(ctx context.Context, backfills services.StatsBackfillService, args []string) error {
+	flags := flag.NewFlagSet("start", flag.ExitOnError)
+	tenantID := flags.String("tenant", "", "Tenant ID")
+	platform := flags.String("platform", string(models.PlatformYouTube), "Platform to import past stats from")
+	workspaceID := flags.String("workspace", "", "Workspace whose platform credentials are used")
+	_ = flags.Parse(args)
+	if *tenantID == "" || *workspaceID == "" {
+		return fmt.Errorf("-tenant and -workspace are required")
+	}
+
+	backfill, err := backfills.Start(ctx, *tenantID, "", *platform, *workspaceID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("queued backfill %s of %s stats until %s\n", backfill.ID, backfill.Platform, backfill.Until.Format(time.DateOnly))
+	return nil
+}
+
+// status prints the tenant's backfills, newest first
+func status(ctx context.Context, backfills services.StatsBackfillService, args []string) error {
+	flags := flag.NewFlagSet("status", flag.ExitOnError)
+	tenantID := flags.String("tenant", "", "Tenant ID")
+	limit := flags.Int("limit", 20, "Number of backfills to list")
+	_ = flags.Parse(args)
+	if *tenantID == "" {
+		return fmt.Errorf("-tenant is required")
+	}
+
+	list, err := backfills.List(ctx, *tenantID, *limit, 0)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPLATFORM\tSTATUS\tVIDEOS\tDAYS\tREQUESTS\tNEXT RUN\tLAST ERROR")
+	for _, b := range list {
+		nextRun := "-"
+		if b.Active() {
+			nextRun = b.NextRunAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%d\t%d\t%s\t%s\n",
+			b.ID, b.Platform, b.Status, b.VideosDone, b.VideosTotal, b.DaysImported, b.Requests, nextRun, b.LastError)
+	}
+	return w.Flush()
+}
+
+// run advances the due backfills once
+func run(ctx context.Context, backfills services.StatsBackfillService, args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	_ = flags.Parse(args)
+
+	report, err := backfills.Run(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("backfills: %d, requests: %d, days imported: %d, completed: %d, throttled: %d, failed: %d\n",
+		report.Backfills, report.Requests, report.DaysImported, report.Completed, report.Throttled, report.Failed)
+	return nil
+}
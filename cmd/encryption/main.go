@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/app"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/encryption"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+const usage = `Usage: encryption <command> [flags]
+
+Commands:
+  keygen     Generate a wrapped data key to append to ENCRYPTION_DATA_KEYS
+  reencrypt  Re-encrypt every encrypted column with ENCRYPTION_ACTIVE_KEY
+
+Key rotation: run keygen, append its output to ENCRYPTION_DATA_KEYS, set
+ENCRYPTION_ACTIVE_KEY to the new key ID, deploy, then run reencrypt. Old keys
+can be removed from ENCRYPTION_DATA_KEYS once reencrypt has completed.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	logger := logger.New(cfg.LogLevel, cfg.Environment)
+	defer logger.Sync()
+
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "keygen":
+		err = keygen(ctx, cfg, os.Args[2:])
+	case "reencrypt":
+		err = reencrypt(ctx, cfg, logger, os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// keygen prints a new data key wrapped by the configured provider
+func keygen(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("keygen", flag.ExitOnError)
+	keyID := flags.String("id", time.Now().UTC().Format("20060102150405"), "ID of the new data key")
+	_ = flags.Parse(args)
+
+	provider, err := app.NewKeyProvider(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if provider == nil {
+		return fmt.Errorf("ENCRYPTION_PROVIDER must be local or kms")
+	}
+
+	_, wrapped, err := provider.GenerateDataKey(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println(encryption.FormatDataKey(*keyID, wrapped))
+	return nil
+}
+
+// reencrypt rewrites every encrypted column with the active data key
+func reencrypt(ctx context.Context, cfg *config.Config, logger *logger.Logger, args []string) error {
+	flags := flag.NewFlagSet("reencrypt", flag.ExitOnError)
+	batchSize := flags.Int("batch", 100, "Number of rows loaded per query")
+	_ = flags.Parse(args)
+
+	keyring, err := app.SetupEncryption(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	if keyring == nil {
+		return fmt.Errorf("ENCRYPTION_PROVIDER must be local or kms")
+	}
+
+	database, err := db.New(cfg.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	rewritten, err := database.Reencrypt(ctx, *batchSize)
+	for table, count := range rewritten {
+		fmt.Printf("%s: %d rows re-encrypted with key %s\n", table, count, keyring.ActiveKeyID())
+	}
+	return err
+}
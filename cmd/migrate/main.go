@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+)
+
+const usage = `Usage: migrate <command> [flags]
+
+Commands:
+  up              Apply every pending migration
+  down            Revert applied migrations (-steps N, default 1; -all for every migration)
+  force VERSION   Set the recorded version and clear the dirty flag without running SQL
+  version         Print the applied version
+  status          List the embedded migrations and whether each is applied
+
+Migrations are embedded in the binary. In production, migrations that drop or
+delete data are refused unless -allow-destructive is passed to up or down.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		err = up(cfg, os.Args[2:])
+	case "down":
+		err = down(cfg, os.Args[2:])
+	case "force":
+		err = force(cfg, os.Args[2:])
+	case "version":
+		err = version(cfg)
+	case "status":
+		err = status(cfg)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		if errors.Is(err, db.ErrDestructiveMigration) {
+			fmt.Fprintln(os.Stderr, "review the migration and rerun with -allow-destructive")
+		}
+		os.Exit(1)
+	}
+}
+
+// up applies every pending migration
+func up(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("up", flag.ExitOnError)
+	allowDestructive := flags.Bool("allow-destructive", false, "Allow migrations that drop or delete data in production")
+	_ = flags.Parse(args)
+
+	m, err := newMigrator(cfg, *allowDestructive)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil {
+		return err
+	}
+	return printVersion(m)
+}
+
+// down reverts applied migrations
+func down(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("down", flag.ExitOnError)
+	steps := flags.Int("steps", 1, "Number of migrations to revert")
+	all := flags.Bool("all", false, "Revert every applied migration")
+	allowDestructive := flags.Bool("allow-destructive", false, "Allow migrations that drop or delete data in production")
+	_ = flags.Parse(args)
+
+	if !*all && *steps <= 0 {
+		return fmt.Errorf("-steps must be positive")
+	}
+	if *all {
+		*steps = 0
+	}
+
+	m, err := newMigrator(cfg, *allowDestructive)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Down(*steps); err != nil {
+		return err
+	}
+	return printVersion(m)
+}
+
+// force records a version without running its migration
+func force(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one VERSION argument")
+	}
+	target, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid version %q: %w", args[0], err)
+	}
+
+	m, err := newMigrator(cfg, false)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Force(target); err != nil {
+		return err
+	}
+	return printVersion(m)
+}
+
+// version prints the applied version
+func version(cfg *config.Config) error {
+	m, err := newMigrator(cfg, false)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return printVersion(m)
+}
+
+// status lists every embedded migration
+func status(cfg *config.Config) error {
+	m, err := newMigrator(cfg, false)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		state := "pending"
+		if s.Applied {
+			state = "applied"
+		}
+		note := ""
+		if s.Destructive {
+			note = " (destructive)"
+		}
+		fmt.Printf("%03d  %-8s %s%s\n", s.Version, state, s.Name, note)
+	}
+	return printVersion(m)
+}
+
+// newMigrator creates a migrator; destructive migrations are only refused in
+// production, where they need an explicit -allow-destructive
+func newMigrator(cfg *config.Config, allowDestructive bool) (*db.Migrator, error) {
+	m, err := db.NewMigrator(cfg.DatabaseDSN)
+	if err != nil {
+		return nil, err
+	}
+	m.AllowDestructive = allowDestructive || cfg.Environment != "production"
+	return m, nil
+}
+
+// printVersion prints the applied version and dirty state
+func printVersion(m *db.Migrator) error {
+	current, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if current == 0 {
+		fmt.Println("version: none")
+		return nil
+	}
+	if dirty {
+		fmt.Printf("version: %d (dirty, fix the schema then run force %d)\n", current, current)
+		return nil
+	}
+	fmt.Printf("version: %d\n", current)
+	return nil
+}
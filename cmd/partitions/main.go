@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+)
+
+func main() {
+	flags := flag.NewFlagSet("partitions", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: partitions")
+		fmt.Fprintln(os.Stderr, "\nKeeps video_stats_snapshots partitioned by month: partitions the table on first")
+		fmt.Fprintln(os.Stderr, "run, creates the coming months and drops months older than")
+		fmt.Fprintln(os.Stderr, "STATS_SNAPSHOT_RETENTION_MONTHS. Schedule it with cron, at least monthly.")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "partitions: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run performs one maintenance pass over the partitioned stats tables
+func run(ctx context.Context, cfg *config.Config) error {
+	database, err := db.New(cfg.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	spec := db.StatsSnapshotPartitions(cfg.StatsSnapshotRetentionMonths)
+	report, err := database.MaintainPartitions(ctx, spec, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if report.Converted {
+		fmt.Printf("%s: partitioned by month\n", spec.Table)
+	}
+	fmt.Printf("%s: created [%s], dropped [%s]\n", spec.Table,
+		strings.Join(report.Created, " "), strings.Join(report.Dropped, " "))
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/tokenizer"
+)
+
+func main() {
+	catalogPath := flag.String("catalog", services.GetCatalogPath(), "Path to the prompt catalog YAML file")
+	strict := flag.Bool("strict", false, "Treat warnings as errors")
+	jsonOutput := flag.Bool("json", false, "Print the validation report as JSON")
+	flag.Parse()
+
+	catalog, err := services.LoadPromptCatalog(*catalogPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *catalogPath, err)
+		os.Exit(1)
+	}
+
+	report := services.LintCatalog(catalog, tokenizer.NewBPE())
+	report.CatalogPath = *catalogPath
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode report: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		for _, issue := range report.Issues {
+			key := issue.PromptKey
+			if key == "" {
+				key = "catalog"
+			}
+			fmt.Printf("%s: %s: [%s] %s: %s\n", *catalogPath, issue.Severity, issue.Rule, key, issue.Message)
+		}
+		fmt.Printf("%d prompts checked, %d errors, %d warnings\n", report.PromptsCount, report.Errors, report.Warnings)
+	}
+
+	if !report.Valid || (*strict && report.Warnings > 0) {
+		os.Exit(1)
+	}
+}
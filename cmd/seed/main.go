@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jibe0123/mysteryfactory/internal/app"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+func main() {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: seed [flags]")
+		fmt.Fprintln(os.Stderr, "\nCreates a demo tenant with users, videos, stats history and publications for local development.")
+		flags.PrintDefaults()
+	}
+	tenantID := flags.String("tenant", "demo", "ID of the demo tenant")
+	videos := flags.Int("videos", 12, "Number of demo videos")
+	days := flags.Int("days", 30, "Days of stats history per video and platform")
+	seed := flags.Int64("seed", 1, "Seed for generated stats")
+	_ = flags.Parse(os.Args[1:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	logger := logger.New(cfg.LogLevel, cfg.Environment)
+	defer logger.Sync()
+
+	if err := run(cfg, logger, db.DemoOptions{TenantID: *tenantID, Videos: *videos, HistoryDays: *days, Seed: *seed}); err != nil {
+		fmt.Fprintf(os.Stderr, "seed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run migrates the schema and loads the demo data set
+func run(cfg *config.Config, logger *logger.Logger, opts db.DemoOptions) error {
+	// Demo users have well-known passwords
+	if cfg.Environment == "production" {
+		return fmt.Errorf("refusing to seed demo data in production")
+	}
+
+	if _, err := app.SetupEncryption(context.Background(), cfg, logger); err != nil {
+		return err
+	}
+
+	database, err := db.New(cfg.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if err := database.AutoMigrate(); err != nil {
+		return err
+	}
+
+	summary, err := db.SeedDemo(database.DB, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("tenant: %s\n", summary.TenantID)
+	for _, email := range summary.Users {
+		fmt.Printf("user: %s / %s\n", email, db.DemoPassword)
+	}
+	if summary.Videos == 0 {
+		fmt.Println("videos: already seeded, skipped")
+		return nil
+	}
+	fmt.Printf("videos: %d, stats: %d, snapshots: %d, publications: %d\n",
+		summary.Videos, summary.Stats, summary.Snapshots, summary.Publications)
+	return nil
+}
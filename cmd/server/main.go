@@ -10,11 +10,14 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/jibe0123/mysteryfactory/internal/app"
 	"github.com/jibe0123/mysteryfactory/internal/config"
 	"github.com/jibe0123/mysteryfactory/internal/router"
 	"github.com/jibe0123/mysteryfactory/pkg/db"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
 	"github.com/jibe0123/mysteryfactory/pkg/metrics"
+	"github.com/jibe0123/mysteryfactory/pkg/scheduler"
+	"github.com/jibe0123/mysteryfactory/pkg/supervisor"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/jaeger"
@@ -66,7 +69,23 @@ func main() {
 	}()
 
 	// Initialize Prometheus metrics
-	m := metrics.New()
+	m := metrics.New(metrics.LabelPolicy{
+		TenantLabel:   cfg.MetricsTenantLabel,
+		TenantBuckets: cfg.MetricsTenantBuckets,
+		MaxTenants:    cfg.MetricsMaxTenants,
+	})
+
+	// Install the keyring before any encrypted column is read or written
+	if _, err := app.SetupEncryption(context.Background(), cfg, logger); err != nil {
+		logger.Fatal("Failed to initialize column encryption", "error", err)
+	}
+
+	// Apply embedded SQL migrations; destructive ones must go through cmd/migrate in production
+	if cfg.MigrateOnStartup {
+		if err := db.RunMigrations(cfg.DatabaseDSN, cfg.Environment != "production"); err != nil {
+			logger.Fatal("Failed to run migrations", "error", err)
+		}
+	}
 
 	// Initialize database
 	database, err := db.New(cfg.DatabaseDSN)
@@ -83,8 +102,66 @@ func main() {
 		logger.Fatal("Failed to seed database", "error", err)
 	}
 
+	// Wire services and clients
+	deps, err := app.NewDependencies(cfg, logger, database, m)
+	if err != nil {
+		logger.Fatal("Failed to initialize dependencies", "error", err)
+	}
+
+	// Every background loop is supervised: restarted with a backoff when it
+	// panics or stops, and reported to /ready
+	startLoop := func(ctx context.Context, loop supervisor.Loop) <-chan struct{} {
+		done, err := deps.Supervisor.Start(ctx, loop)
+		if err != nil {
+			logger.Fatal("Failed to start background loop", "error", err, "loop", loop.Name)
+		}
+		return done
+	}
+
+	// Start background jobs; each one runs on whichever replica holds its lease
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	var schedulerDone <-chan struct{}
+	if cfg.SchedulerEnabled {
+		sched, err := app.NewScheduler(deps, database, scheduler.DefaultHolder())
+		if err != nil {
+			logger.Fatal("Failed to initialize scheduler", "error", err)
+		}
+		schedulerDone = startLoop(schedulerCtx, supervisor.Loop{Name: "scheduler", Critical: true, Run: func(ctx context.Context) error {
+			sched.Run(ctx)
+			return nil
+		}})
+	} else {
+		logger.Info("Background jobs disabled on this instance")
+		schedulerDone = closed()
+	}
+
+	// Process accepted webhooks in the background; the queue is drained on shutdown
+	webhooksCtx, stopWebhooks := context.WithCancel(context.Background())
+	webhooksDone := startLoop(webhooksCtx, supervisor.Loop{Name: "webhooks", Critical: true, Run: func(ctx context.Context) error {
+		deps.WebhookService.Run(ctx)
+		return nil
+	}})
+
+	// Write stats rows to the analytics sink in the background; the queue is drained on shutdown
+	sinkCtx, stopSink := context.WithCancel(context.Background())
+	sinkDone := closed()
+	if deps.AnalyticsSink != nil {
+		sinkDone = startLoop(sinkCtx, supervisor.Loop{Name: "analytics-sink", Run: func(ctx context.Context) error {
+			deps.AnalyticsSink.Run(ctx)
+			return nil
+		}})
+	}
+
+	// Pick up new prompt catalogs without a restart; each replica polls its own
+	promptsCtx, stopPrompts := context.WithCancel(context.Background())
+	defer stopPrompts()
+	startLoop(promptsCtx, supervisor.Loop{Name: "prompt-catalog", Run: func(ctx context.Context) error {
+		deps.PromptService.Run(ctx)
+		return nil
+	}})
+
 	// Initialize router
-	r := router.New(cfg, logger, database, m)
+	r := router.New(deps)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -109,6 +186,10 @@ func main() {
 	<-quit
 	logger.Info("Shutting down server...")
 
+	// Stop background jobs first so their leases pass to another replica
+	stopScheduler()
+	<-schedulerDone
+
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -117,9 +198,30 @@ func main() {
 		logger.Fatal("Server forced to shutdown", "error", err)
 	}
 
+	// No new webhooks can arrive now; finish the ones already accepted
+	stopWebhooks()
+	select {
+	case <-webhooksDone:
+	case <-ctx.Done():
+		logger.Error("Webhook queue not drained before shutdown timeout")
+	}
+	stopSink()
+	select {
+	case <-sinkDone:
+	case <-ctx.Done():
+		logger.Error("Analytics sink queue not drained before shutdown timeout")
+	}
+
 	logger.Info("Server exited")
 }
 
+// closed returns a channel that is already closed, for loops not started
+func closed() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
 // initTracer creates a new trace provider instance and registers it as global trace provider.
 func initTracer(serviceName, jaegerEndpoint string) (*tracesdk.TracerProvider, error) {
 	// Create the Jaeger exporter
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS campaigns (
+    id            VARCHAR(36)    PRIMARY KEY,
+    tenant_id     VARCHAR(36)    NOT NULL,
+    user_id       VARCHAR(36)    NOT NULL,
+    name          VARCHAR(255)   NOT NULL,
+    goal          TEXT,
+    context       JSON,
+    theme         VARCHAR(255),
+    platforms     JSON,
+    language      VARCHAR(10),
+    status        VARCHAR(20)    NOT NULL DEFAULT 'draft',
+    schedule      JSON,
+    next_run_at   DATETIME(3),
+    budget        DECIMAL(12,2)  DEFAULT 0,
+    max_videos    BIGINT         DEFAULT 0,
+    progress      JSON,
+    created_at    DATETIME(3),
+    updated_at    DATETIME(3),
+    started_at    DATETIME(3),
+    completed_at  DATETIME(3),
+    INDEX idx_campaigns_tenant_created (tenant_id, created_at),
+    INDEX idx_campaigns_due (status, next_run_at)
+);
@@ -0,0 +1 @@
+ALTER TABLE campaigns DROP COLUMN version;
@@ -0,0 +1,7 @@
+-- AutoMigrate adds the column too; only add it when it is missing
+SET @has_version = (SELECT COUNT(*) FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'campaigns' AND column_name = 'version');
+SET @ddl = IF(@has_version = 0, 'ALTER TABLE campaigns ADD COLUMN version BIGINT NOT NULL DEFAULT 0', 'SELECT 1');
+PREPARE add_version FROM @ddl;
+EXECUTE add_version;
+DEALLOCATE PREPARE add_version;
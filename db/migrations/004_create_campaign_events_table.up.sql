@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS campaign_events (
+    id           VARCHAR(36)  PRIMARY KEY,
+    tenant_id    VARCHAR(36)  NOT NULL,
+    campaign_id  VARCHAR(36)  NOT NULL,
+    sequence     BIGINT       NOT NULL,
+    type         VARCHAR(32)  NOT NULL,
+    data         JSON,
+    occurred_at  DATETIME(3)  NOT NULL,
+    INDEX idx_campaign_events_tenant_id (tenant_id),
+    UNIQUE INDEX idx_campaign_events_sequence (campaign_id, sequence)
+);
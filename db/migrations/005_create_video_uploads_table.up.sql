@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS video_uploads (
+    id            VARCHAR(36)    PRIMARY KEY,
+    tenant_id     VARCHAR(36)    NOT NULL,
+    video_id      VARCHAR(36)    NOT NULL,
+    user_id       VARCHAR(36)    NOT NULL,
+    status        VARCHAR(20)    NOT NULL,
+    filename      VARCHAR(255)   NOT NULL,
+    content_type  VARCHAR(100),
+    size          BIGINT         NOT NULL,
+    part_size     BIGINT         NOT NULL,
+    part_count    BIGINT         NOT NULL,
+    s3_bucket     VARCHAR(255)   NOT NULL,
+    s3_key        VARCHAR(500)   NOT NULL,
+    s3_upload_id  VARCHAR(1024)  NOT NULL,
+    created_at    DATETIME(3),
+    updated_at    DATETIME(3),
+    INDEX idx_video_uploads_tenant_id (tenant_id),
+    INDEX idx_video_uploads_video_status (video_id, status)
+);
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL schema migrations so the server and the
+// migrate command run them without depending on a filesystem path.
+package migrations
+
+import "embed"
+
+// FS holds the versioned <version>_<name>.up.sql and .down.sql files
+//
+//go:embed *.sql
+var FS embed.FS
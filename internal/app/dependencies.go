@@ -0,0 +1,659 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	platforms "github.com/jibe0123/mysteryfactory/internal/partners"
+	"github.com/jibe0123/mysteryfactory/internal/repositories"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/clickhouse"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/metrics"
+	"github.com/jibe0123/mysteryfactory/pkg/notify"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/residency"
+	"github.com/jibe0123/mysteryfactory/pkg/slo"
+	"github.com/jibe0123/mysteryfactory/pkg/slowlog"
+	"github.com/jibe0123/mysteryfactory/pkg/supervisor"
+)
+
+// Dependencies holds the clients, repositories and services shared by the HTTP
+// server and background workers. Tests can populate it with fakes directly.
+type Dependencies struct {
+	Config  *config.Config
+	Logger  *logger.Logger
+	DB      *db.DB
+	Metrics *metrics.Metrics
+	SlowLog *slowlog.Observer
+	SLO     *slo.Tracker
+	Clock   clock.Clock
+
+	// Supervisor restarts the background loops and reports them to /ready
+	Supervisor *supervisor.Supervisor
+
+	// Placements maps each data residency to its region and bucket
+	Placements *residency.Placements
+
+	// External clients
+	BedrockClient  aws.BedrockClient
+	ArchiveStorage aws.ArchiveStorage
+	UploadStorage  aws.MultipartStorage
+	Notifier       notify.Notifier
+	Mailer         notify.Mailer
+	// PlatformClients creates the partner platform clients
+	PlatformClients func(string) (partners.Client, error)
+
+	// Repositories
+	Tenants       models.TenantRepository
+	Users         models.UserRepository
+	Videos        models.VideoRepository
+	VideoUploads  models.VideoUploadRepository
+	VideoStats    models.VideoStatsRepository
+	Conversations models.ConversationRepository
+	Transcripts   models.TranscriptRepository
+	Summaries     models.VideoSummaryRepository
+	Retention     models.RetentionPolicyRepository
+	Watermarks    models.WatermarkRepository
+	Renditions    models.VideoRenditionRepository
+	Assets        models.AssetRepository
+	AudioTracks   models.AudioTrackRepository
+	BrandProfiles models.BrandProfileRepository
+	Thumbnails    models.ThumbnailVariantRepository
+	AuditLogs     models.AuditLogRepository
+	AIUsage       models.AIUsageRepository
+	MagicBrushes  models.MagicBrushBatchRepository
+	Publications  models.PublicationJobRepository
+	DebugCaptures models.DebugCaptureRepository
+	Quarantine    models.QuarantinedWebhookRepository
+	Workspaces    models.WorkspaceRepository
+	Backfills     models.StatsBackfillRepository
+	Compactions   models.StatsCompactionRepository
+	Checkpoints   models.StatsSyncCheckpointRepository
+	AlertRules    models.AlertRuleRepository
+	SavedLists    models.SmartListRepository
+	Readers       models.AnalyticsReaderRepository
+	Campaigns     models.CampaignRepository
+	Blackouts     models.BlackoutWindowRepository
+	Series        models.SeriesRepository
+	Presets       models.EncodingPresetRepository
+	QuotaUsage    models.PlatformQuotaRepository
+	Transfers     models.VideoTransferRepository
+	Preferences   models.UserPreferencesRepository
+	Notifications models.UserNotificationRepository
+	LoginAttempts models.LoginAttemptRepository
+	Jobs          models.JobRepository
+	Curves        models.RetentionCurveRepository
+	Subscriptions models.WebhookSubscriptionRepository
+
+	// Services
+	PromptService        services.PromptService
+	JobService           services.JobService
+	VideoService         services.VideoService
+	UploadService        services.UploadService
+	AIService            services.AIService
+	MagicBrushBatches    services.MagicBrushBatchService
+	SmartLists           services.SmartListService
+	VideoExports         services.VideoExportService
+	AnalyticsReaders     services.AnalyticsReaderService
+	AnalyticsSink        services.AnalyticsSink // nil unless ANALYTICS_SINK_URL is set
+	ChatService          services.ChatService
+	TranscriptService    services.TranscriptService
+	SummaryService       services.SummaryService
+	AnalyticsService     services.AnalyticsService
+	CampaignService      services.CampaignService
+	ArchiveService       services.ArchiveService
+	ResidencyService     services.ResidencyService
+	IdentityService      services.IdentityService
+	WebhookService       services.WebhookService
+	AuditService         services.AuditService
+	ImpersonationService services.ImpersonationService
+	SuspensionService    services.SuspensionService
+	ApprovalService      services.ApprovalService
+	OpsService           services.OpsService
+	DebugCaptureService  services.DebugCaptureService
+	StatsFreshness       services.StatsFreshnessService
+	StatsSync            services.StatsSyncService
+	StatsBackfill        services.StatsBackfillService
+	StatsCompaction      services.StatsCompactionService
+	StatsScores          services.StatsScoreService
+	RetentionService     services.RetentionService
+	WebhookSubscriptions services.WebhookSubscriptionService
+	QuotaService         services.QuotaService
+	PublishPreview       services.PublishPreviewService
+	PublicationService   services.PublicationService
+	ConnectionService    services.ConnectionService
+	BlackoutService      services.BlackoutService
+	SeriesService        services.SeriesService
+	EncodingPresets      services.EncodingPresetService
+	RightsService        services.RightsService
+	WatermarkService     services.WatermarkService
+	AssetService         services.AssetService
+	RenditionService     services.RenditionService
+	DubbingService       services.DubbingService
+	MediaInfoService     services.MediaInfoService
+	QCService            services.QCService
+	ThumbnailService     services.ThumbnailService
+	FrameService         services.FrameService
+	BrandService         services.BrandService
+	CompositorService    services.CompositorService
+	ActivityService      services.ActivityService
+	TransferService      services.TransferService
+	PreferencesService   services.PreferencesService
+	NotificationService  services.NotificationService
+	AlertService         services.AlertService
+	LoginService         services.LoginService
+}
+
+// NewDependencies wires the production dependency graph from configuration
+func NewDependencies(cfg *config.Config, logger *logger.Logger, database *db.DB, m *metrics.Metrics) (*Dependencies, error) {
+	deps := &Dependencies{
+		Config:  cfg,
+		Logger:  logger,
+		DB:      database,
+		Metrics: m,
+		Clock:   clock.System,
+	}
+
+	placements, err := NewPlacements(cfg)
+	if err != nil {
+		return nil, err
+	}
+	deps.Placements = placements
+
+	// Slow queries and external calls are logged and counted
+	deps.SlowLog = NewSlowLog(cfg, logger, m)
+	if err := database.Use(slowlog.NewPlugin(deps.SlowLog)); err != nil {
+		return nil, fmt.Errorf("failed to enable slow query logging: %w", err)
+	}
+
+	// Latency of the endpoints with an objective is tracked for /internal/slo
+	if deps.SLO, err = NewSLOTracker(cfg, m, deps.Clock); err != nil {
+		return nil, err
+	}
+
+	// Background loops are restarted when they crash, see cmd/server
+	deps.Supervisor = supervisor.New(m, logger)
+
+	// Repositories
+	deps.Tenants = repositories.NewTenantRepository(database.DB)
+	deps.Users = repositories.NewUserRepository(database.DB)
+	deps.Videos = repositories.NewVideoRepository(database.DB)
+	deps.VideoUploads = repositories.NewVideoUploadRepository(database.DB)
+	deps.VideoStats = repositories.NewVideoStatsRepository(database.DB)
+	if deps.AnalyticsSink, err = NewAnalyticsSink(cfg, logger); err != nil {
+		return nil, err
+	}
+	if deps.AnalyticsSink != nil {
+		// Every stats row written to MySQL is recorded in the sink too
+		deps.VideoStats = services.TapStats(deps.VideoStats, deps.AnalyticsSink)
+	}
+	deps.Conversations = repositories.NewConversationRepository(database.DB)
+	deps.Transcripts = repositories.NewTranscriptRepository(database.DB)
+	deps.Summaries = repositories.NewVideoSummaryRepository(database.DB)
+	deps.Retention = repositories.NewRetentionPolicyRepository(database.DB)
+	deps.Watermarks = repositories.NewWatermarkRepository(database.DB)
+	deps.Renditions = repositories.NewVideoRenditionRepository(database.DB)
+	deps.Assets = repositories.NewAssetRepository(database.DB)
+	deps.AudioTracks = repositories.NewAudioTrackRepository(database.DB)
+	deps.BrandProfiles = repositories.NewBrandProfileRepository(database.DB)
+	deps.Thumbnails = repositories.NewThumbnailVariantRepository(database.DB)
+	deps.AuditLogs = repositories.NewAuditLogRepository(database.DB)
+	deps.AIUsage = repositories.NewAIUsageRepository(database.DB)
+	deps.MagicBrushes = repositories.NewMagicBrushBatchRepository(database.DB)
+	deps.Publications = repositories.NewPublicationJobRepository(database.DB)
+	deps.DebugCaptures = repositories.NewDebugCaptureRepository(database.DB)
+	deps.Quarantine = repositories.NewQuarantinedWebhookRepository(database.DB)
+	deps.Workspaces = repositories.NewWorkspaceRepository(database.DB)
+	deps.Backfills = repositories.NewStatsBackfillRepository(database.DB)
+	deps.Compactions = repositories.NewStatsCompactionRepository(database.DB)
+	deps.Checkpoints = repositories.NewStatsSyncCheckpointRepository(database.DB)
+	deps.AlertRules = repositories.NewAlertRuleRepository(database.DB)
+	deps.SavedLists = repositories.NewSmartListRepository(database.DB)
+	deps.Readers = repositories.NewAnalyticsReaderRepository(database.DB)
+	deps.Campaigns = repositories.NewCampaignRepository(database.DB)
+	deps.Blackouts = repositories.NewBlackoutWindowRepository(database.DB)
+	deps.Series = repositories.NewSeriesRepository(database.DB)
+	deps.Presets = repositories.NewEncodingPresetRepository(database.DB)
+	deps.QuotaUsage = repositories.NewPlatformQuotaRepository(database.DB)
+	deps.Transfers = repositories.NewVideoTransferRepository(database.DB)
+	deps.Preferences = repositories.NewUserPreferencesRepository(database.DB)
+	deps.Notifications = repositories.NewUserNotificationRepository(database.DB)
+	deps.LoginAttempts = repositories.NewLoginAttemptRepository(database.DB)
+	deps.Jobs = repositories.NewJobRepository(database.DB)
+	deps.Curves = repositories.NewRetentionCurveRepository(database.DB)
+	deps.Subscriptions = repositories.NewWebhookSubscriptionRepository(database.DB)
+
+	// External clients
+	bedrockClient, err := NewBedrockClient(cfg, placements, logger, m, deps.SlowLog)
+	if err != nil {
+		return nil, err
+	}
+	deps.BedrockClient = bedrockClient
+
+	deps.ArchiveStorage, err = NewArchiveStorage(cfg, placements, logger)
+	if err != nil {
+		return nil, err
+	}
+	deps.UploadStorage, err = NewUploadStorage(cfg, placements, logger)
+	if err != nil {
+		return nil, err
+	}
+	deps.Notifier = NewNotifier(cfg, logger)
+	deps.Mailer = notify.NewLogMailer(logger)
+
+	// Platform calls are charged to the tenants' daily quotas before being made
+	deps.QuotaService = services.NewQuotaService(
+		deps.QuotaUsage,
+		deps.Workspaces,
+		map[string]int64{string(models.PlatformYouTube): cfg.YouTubeDailyQuota},
+		cfg.PlatformQuotaReservePercent,
+		deps.Clock,
+		logger,
+	)
+	deps.PlatformClients = partners.NewQuotaFactory(partners.NewTimedFactory(partners.New, deps.SlowLog), deps.QuotaService)
+
+	// Services
+	catalogSource, err := NewPromptCatalogSource(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	deps.PromptService, err = services.NewPromptService(catalogSource, time.Duration(cfg.PromptCatalogPollInterval)*time.Second, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize prompt service: %w", err)
+	}
+
+	modelPolicy, err := services.NewModelPolicy(cfg.AIAllowedModels, cfg.AITenantAllowedModels, cfg.AIModelFallbackChain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize AI model policy: %w", err)
+	}
+
+	// Asynchronous operations of every subsystem are tracked as jobs
+	deps.JobService = services.NewJobService(deps.Jobs, time.Duration(cfg.JobRetention)*time.Second, deps.Clock, logger)
+	deps.VideoService = services.NewVideoService(deps.Videos, deps.Users, deps.Workspaces, deps.JobService, deps.Clock, logger)
+	deps.AIService = services.NewAIService(deps.PromptService, bedrockClient, modelPolicy, cfg.AIDeterministic, deps.AIUsage, logger, m)
+	deps.SmartLists = services.NewSmartListService(deps.SavedLists, deps.Users, deps.VideoService, logger)
+	deps.VideoExports = services.NewVideoExportService(deps.VideoService, deps.SmartLists, logger)
+	deps.AnalyticsReaders = services.NewAnalyticsReaderService(deps.Readers, deps.Tenants, deps.VideoStats, deps.AnalyticsSink, deps.Clock, logger)
+	deps.MagicBrushBatches = services.NewMagicBrushBatchService(deps.MagicBrushes, deps.Videos, deps.Workspaces, deps.Users, deps.SmartLists, deps.AIService, deps.JobService, deps.Clock, logger)
+	deps.ChatService = services.NewChatService(
+		deps.Conversations,
+		bedrockClient,
+		modelPolicy,
+		deps.AIUsage,
+		time.Duration(cfg.AIConversationTTL)*time.Second,
+		deps.Clock,
+		logger,
+		m,
+	)
+	deps.TranscriptService = services.NewTranscriptService(deps.Transcripts, deps.Videos, deps.Workspaces, logger)
+	deps.SummaryService = services.NewSummaryService(deps.Summaries, deps.Transcripts, deps.Videos, deps.Workspaces, deps.Campaigns, deps.AIService, logger)
+	benchmarks, err := services.NewBenchmarks(cfg.EngagementBenchmarks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize engagement benchmarks: %w", err)
+	}
+	deps.AnalyticsService = services.NewAnalyticsService(deps.Videos, deps.Workspaces, deps.VideoStats, deps.Tenants, benchmarks, logger)
+	deps.CampaignService = services.NewCampaignService(deps.Campaigns, deps.AIService, deps.Clock, logger)
+	deps.ResidencyService = services.NewResidencyService(deps.Tenants, placements, logger)
+	deps.UploadService = services.NewUploadService(deps.VideoUploads, deps.Videos, deps.Workspaces, deps.UploadStorage, deps.ResidencyService, deps.JobService, deps.Clock, logger)
+	deps.IdentityService = services.NewIdentityService(deps.Users, deps.Tenants, time.Duration(cfg.IdentityCacheTTL)*time.Second, deps.Clock, logger)
+	deps.ArchiveService = services.NewArchiveService(deps.Videos, deps.Workspaces, deps.Retention, deps.ArchiveStorage, deps.ResidencyService, deps.JobService, deps.Clock, logger)
+	deps.WebhookService = services.NewWebhookService(cfg.WebhookQueueSize, cfg.WebhookWorkers, deps.Quarantine, logger)
+	deps.AuditService = services.NewAuditService(deps.AuditLogs, logger)
+	deps.SuspensionService = services.NewSuspensionService(deps.Users, deps.Tenants, deps.AuditService, deps.IdentityService, cfg.SupportTenantID, logger)
+	deps.ImpersonationService = services.NewImpersonationService(
+		deps.Users,
+		deps.AuditService,
+		cfg.SupportTenantID,
+		time.Duration(cfg.ImpersonationMaxDuration)*time.Second,
+		deps.Clock,
+		logger,
+	)
+	deps.OpsService = services.NewOpsService(
+		deps.Publications,
+		deps.VideoStats,
+		deps.AIUsage,
+		deps.WebhookService,
+		deps.Quarantine,
+		time.Duration(cfg.StatsSyncInterval)*time.Second,
+		logger,
+	)
+	deps.DebugCaptureService = services.NewDebugCaptureService(
+		deps.DebugCaptures,
+		time.Duration(cfg.DebugCaptureMaxWindow)*time.Second,
+		time.Duration(cfg.DebugCaptureRetention)*time.Second,
+		deps.Clock,
+		logger,
+	)
+	deps.StatsFreshness = services.NewStatsFreshnessService(
+		deps.VideoStats,
+		deps.Notifier,
+		time.Duration(cfg.StatsFreshnessThreshold)*time.Second,
+		deps.Clock,
+		logger,
+		m,
+	)
+	deps.WatermarkService = services.NewWatermarkService(deps.Watermarks, logger)
+	deps.PublishPreview = services.NewPublishPreviewService(deps.Videos, deps.Workspaces, deps.WatermarkService, logger)
+	deps.StatsSync = services.NewStatsSyncService(deps.VideoStats, cfg.StatsBatchSize, deps.Checkpoints, deps.Videos, deps.Publications, deps.Workspaces,
+		platforms.NewService(deps.PlatformClients), deps.Clock, logger)
+	deps.StatsBackfill = services.NewStatsBackfillService(
+		deps.Backfills,
+		deps.VideoStats,
+		deps.Videos,
+		deps.Workspaces,
+		deps.PlatformClients,
+		deps.JobService,
+		cfg.StatsBackfillRequests,
+		time.Duration(cfg.StatsBackfillQuotaBackoff)*time.Second,
+		cfg.StatsSnapshotRetentionMonths,
+		deps.Clock,
+		logger,
+	)
+	compactionPlans, err := services.NewStatsCompactionPlans(models.StatsCompactionPolicy{
+		CompactAfterDays: cfg.StatsCompactionAfterDays,
+		RetentionDays:    cfg.StatsSnapshotRetentionDays,
+	}, cfg.StatsCompactionPlans)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize stats compaction plans: %w", err)
+	}
+	deps.StatsCompaction = services.NewStatsCompactionService(deps.Tenants, deps.VideoStats, deps.Compactions, compactionPlans, deps.Clock, logger)
+	deps.StatsScores = services.NewStatsScoreService(deps.Tenants, deps.VideoStats, cfg.StatsScoreHour, deps.Clock, logger)
+	deps.RetentionService = services.NewRetentionService(deps.Curves, deps.Videos, deps.Workspaces, deps.PlatformClients, deps.Clock, logger)
+	deps.WebhookSubscriptions = services.NewWebhookSubscriptionService(deps.Subscriptions, deps.Workspaces, deps.PlatformClients,
+		cfg.WebhookCallbackBaseURL, NewWebhookSecrets(cfg),
+		time.Duration(cfg.WebhookSubscriptionLease)*time.Second, time.Duration(cfg.WebhookSubscriptionRenewBefore)*time.Second, deps.Clock, logger)
+	deps.RightsService = services.NewRightsService(deps.Videos, deps.Clock, logger)
+	deps.EncodingPresets = services.NewEncodingPresetService(deps.Presets, deps.Clock, logger)
+	deps.AssetService = services.NewAssetService(deps.Assets, deps.UploadStorage, deps.ResidencyService, deps.Clock, logger)
+	deps.RenditionService = services.NewRenditionService(deps.Renditions, deps.EncodingPresets, deps.AssetService, deps.Videos, deps.Workspaces, deps.JobService, deps.Clock, logger)
+	deps.DubbingService = services.NewDubbingService(deps.AudioTracks, deps.Transcripts, deps.Videos, deps.Workspaces, deps.AIService, deps.RenditionService, deps.JobService, deps.Clock, logger)
+	deps.MediaInfoService = services.NewMediaInfoService(deps.Videos, deps.Workspaces, deps.Clock, logger)
+	deps.ThumbnailService = services.NewThumbnailService(deps.Videos, deps.Clock, logger)
+	deps.FrameService = services.NewFrameService(deps.Videos, deps.Workspaces, deps.Clock, logger)
+	deps.BrandService = services.NewBrandService(deps.BrandProfiles, deps.Tenants, logger)
+	deps.CompositorService = services.NewCompositorService(deps.Thumbnails, deps.Videos, deps.Workspaces, deps.BrandService, deps.JobService, deps.Clock, logger)
+	deps.ActivityService = services.NewActivityService(deps.Videos, deps.Workspaces, deps.AuditLogs, deps.AIUsage, deps.Publications, deps.VideoStats, deps.Clock, logger)
+	deps.PreferencesService = services.NewPreferencesService(deps.Preferences, logger)
+	deps.NotificationService = services.NewNotificationService(deps.Notifications, deps.PreferencesService, deps.Preferences, deps.Users, deps.BrandService, deps.Mailer, deps.Clock, logger)
+	deps.LoginService = services.NewLoginService(deps.Users, deps.LoginAttempts, deps.NotificationService, deps.PreferencesService, deps.Mailer,
+		cfg.LoginMaxFailures, time.Duration(cfg.LoginFailureWindow)*time.Second, deps.Clock, logger)
+	deps.QCService = services.NewQCService(deps.Videos, deps.NotificationService, deps.Clock, logger)
+	deps.TransferService = services.NewTransferService(deps.Transfers, deps.Videos, deps.VideoStats, cfg.StatsBatchSize, deps.Tenants, deps.ArchiveStorage, deps.ResidencyService, deps.AuditService, deps.NotificationService, deps.Clock, logger)
+	deps.AlertService = services.NewAlertService(deps.AlertRules, deps.Tenants, deps.Videos, deps.VideoStats, deps.NotificationService, deps.Clock, logger)
+	deps.ConnectionService = services.NewConnectionService(deps.Workspaces, deps.Publications, deps.Users, platforms.NewService(deps.PlatformClients),
+		deps.NotificationService, cfg.WebhookCallbackBaseURL, deps.Clock, logger)
+	deps.BlackoutService = services.NewBlackoutService(deps.Blackouts, deps.Publications, deps.NotificationService, deps.Clock, logger)
+	deps.SeriesService = services.NewSeriesService(deps.Series, deps.Videos, deps.Workspaces, deps.PlatformClients, deps.Clock, logger)
+	deps.PublicationService = services.NewPublicationService(
+		deps.Publications,
+		deps.Videos,
+		deps.Workspaces,
+		deps.RenditionService,
+		platforms.NewService(deps.PlatformClients),
+		deps.JobService,
+		deps.AlertService,
+		deps.ConnectionService,
+		deps.BlackoutService,
+		deps.SeriesService,
+		time.Duration(cfg.PublicationStagingLead)*time.Second,
+		deps.Clock,
+		logger,
+	)
+	deps.ApprovalService = services.NewApprovalService(deps.Publications, deps.Videos, deps.Workspaces, deps.Tenants, deps.Users, deps.NotificationService, deps.AuditService, deps.Clock, logger)
+
+	return deps, nil
+}
+
+// NewNotifier returns the notifier alerting the admins: the chat webhook
+// when ALERT_WEBHOOK_URL is set, the error log otherwise
+func NewNotifier(cfg *config.Config, logger *logger.Logger) notify.Notifier {
+	if cfg.AlertWebhookURL == "" {
+		return notify.NewLog(logger)
+	}
+	return notify.NewWebhook(cfg.AlertWebhookURL)
+}
+
+// NewWebhookSecrets returns the secrets platform webhooks are verified with
+func NewWebhookSecrets(cfg *config.Config) partners.WebhookSecrets {
+	return partners.WebhookSecrets{
+		YouTube:         cfg.WebhookYouTubeSecret,
+		TikTok:          cfg.WebhookTikTokSecret,
+		MetaAppSecret:   cfg.WebhookMetaAppSecret,
+		MetaVerifyToken: cfg.WebhookMetaVerifyToken,
+		TwitterSecret:   cfg.WebhookTwitterSecret,
+	}
+}
+
+// NewPlacements builds the data residency placements from configuration
+func NewPlacements(cfg *config.Config) (*residency.Placements, error) {
+	defaultResidency, err := residency.Parse(cfg.DataResidencyDefault)
+	if err != nil {
+		return nil, err
+	}
+
+	placements := []residency.Placement{{
+		Residency:     residency.US,
+		AWSRegion:     cfg.AWSRegion,
+		S3Bucket:      cfg.S3Bucket,
+		BedrockRegion: cfg.ResidencyUSBedrockRegion,
+
+		BedrockSecondaryRegion: cfg.ResidencyUSBedrockSecondaryRegion,
+	}}
+	if cfg.ResidencyEUAWSRegion != "" {
+		placements = append(placements, residency.Placement{
+			Residency:     residency.EU,
+			AWSRegion:     cfg.ResidencyEUAWSRegion,
+			S3Bucket:      cfg.ResidencyEUS3Bucket,
+			BedrockRegion: cfg.ResidencyEUBedrockRegion,
+
+			BedrockSecondaryRegion: cfg.ResidencyEUBedrockSecondaryRegion,
+		})
+	}
+
+	p, err := residency.NewPlacements(defaultResidency, placements...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data residency configuration: %w", err)
+	}
+	return p, nil
+}
+
+// NewArchiveStorage builds the S3 clients used by the archive lifecycle, one
+// per residency bucket since requests are signed for the bucket's region, or
+// the in-memory fake when ARCHIVE_STORAGE is fake
+func NewArchiveStorage(cfg *config.Config, placements *residency.Placements, logger *logger.Logger) (aws.ArchiveStorage, error) {
+	if cfg.ArchiveStorage == "fake" {
+		logger.Warn("Using in-memory archive storage")
+		return aws.NewFakeArchiveStorage(time.Duration(cfg.ArchiveFakeRestoreDelay)*time.Second, logger), nil
+	}
+
+	var fallback aws.ArchiveStorage
+	byBucket := make(map[string]aws.ArchiveStorage)
+	for _, r := range placements.Configured() {
+		placement, err := placements.Get(r)
+		if err != nil {
+			return nil, err
+		}
+		storage, err := aws.NewS3ArchiveStorage(placement.AWSRegion, cfg.S3Endpoint, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize archive storage for residency %s: %w", r, err)
+		}
+		if placement.S3Bucket != "" {
+			byBucket[placement.S3Bucket] = storage
+		}
+		if r == placements.Default() {
+			fallback = storage
+		}
+	}
+	return aws.NewBucketArchiveStorage(byBucket, fallback), nil
+}
+
+// NewUploadStorage creates the multipart upload client of every configured
+// residency, routed by bucket like NewArchiveStorage
+func NewUploadStorage(cfg *config.Config, placements *residency.Placements, logger *logger.Logger) (aws.MultipartStorage, error) {
+	var fallback aws.MultipartStorage
+	byBucket := make(map[string]aws.MultipartStorage)
+	for _, r := range placements.Configured() {
+		placement, err := placements.Get(r)
+		if err != nil {
+			return nil, err
+		}
+		storage, err := aws.NewS3MultipartStorage(placement.AWSRegion, cfg.S3Endpoint, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize upload storage for residency %s: %w", r, err)
+		}
+		if placement.S3Bucket != "" {
+			byBucket[placement.S3Bucket] = storage
+		}
+		if r == placements.Default() {
+			fallback = storage
+		}
+	}
+	return aws.NewBucketMultipartStorage(byBucket, fallback), nil
+}
+
+// NewPromptCatalogSource reads the prompt catalog from PROMPT_CATALOG_S3_BUCKET
+// when it is set, so every replica serves the same catalog without it being
+// baked into the image, and from PROMPT_CATALOG_PATH otherwise
+func NewPromptCatalogSource(cfg *config.Config, logger *logger.Logger) (services.PromptCatalogSource, error) {
+	if cfg.PromptCatalogS3Bucket == "" {
+		return services.NewFilePromptCatalogSource(cfg.PromptCatalogPath), nil
+	}
+	store, err := aws.NewS3ObjectStore(cfg.AWSRegion, cfg.S3Endpoint, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize prompt catalog source: %w", err)
+	}
+	return services.NewS3PromptCatalogSource(store, cfg.PromptCatalogS3Bucket, cfg.PromptCatalogS3Key), nil
+}
+
+// NewBedrockClient builds the Bedrock client selected by configuration: the real
+// AWS clients routed by residency or the offline fake, optionally wrapped by the
+// record/replay cassette. Failover metrics go to m when it is not nil, and
+// slow calls to AWS are reported to slow.
+func NewBedrockClient(cfg *config.Config, placements *residency.Placements, logger *logger.Logger, m *metrics.Metrics, slow *slowlog.Observer) (aws.BedrockClient, error) {
+	cassetteMode, err := aws.ParseCassetteMode(cfg.AICassetteMode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replay mode serves recorded responses only and never reaches AWS
+	var client aws.BedrockClient
+	if cfg.AIBedrockClient == "fake" {
+		logger.Warn("Using fake Bedrock client with canned responses")
+		client = aws.NewFakeBedrockClient(logger)
+	} else if cassetteMode != aws.CassetteReplay {
+		client, err = newRegionalBedrockClient(cfg, placements, logger, m, slow)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client, err = aws.NewCassetteClient(client, cfg.AICassettePath, cassetteMode, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Bedrock cassette: %w", err)
+	}
+	return client, nil
+}
+
+// newRegionalBedrockClient creates one Bedrock client per residency region,
+// failing over to the residency's secondary region when one is configured
+func newRegionalBedrockClient(cfg *config.Config, placements *residency.Placements, logger *logger.Logger, m *metrics.Metrics, slow *slowlog.Observer) (aws.BedrockClient, error) {
+	var observer aws.FailoverObserver
+	if m != nil {
+		observer = m
+	}
+
+	clients := make(map[residency.Residency]aws.BedrockClient)
+	for _, r := range placements.Configured() {
+		placement, err := placements.Get(r)
+		if err != nil {
+			return nil, err
+		}
+		client, err := newBedrockRegionClient(placement.BedrockRegion, logger, slow)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Bedrock client for residency %s: %w", r, err)
+		}
+
+		if placement.BedrockSecondaryRegion != "" {
+			secondary, err := newBedrockRegionClient(placement.BedrockSecondaryRegion, logger, slow)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize secondary Bedrock client for residency %s: %w", r, err)
+			}
+			client, err = aws.NewFailoverBedrockClient(client, secondary, aws.FailoverConfig{
+				Name:             string(r),
+				PrimaryRegion:    placement.BedrockRegion,
+				SecondaryRegion:  placement.BedrockSecondaryRegion,
+				FailureThreshold: cfg.BedrockFailoverThreshold,
+				Cooldown:         time.Duration(cfg.BedrockFailoverCooldown) * time.Second,
+			}, observer, logger)
+			if err != nil {
+				return nil, err
+			}
+		}
+		clients[r] = client
+	}
+	return aws.NewResidencyBedrockClient(clients, placements.Default())
+}
+
+func newBedrockRegionClient(region string, logger *logger.Logger, slow *slowlog.Observer) (aws.BedrockClient, error) {
+	bedrockCfg := aws.DefaultBedrockConfig()
+	bedrockCfg.Region = region
+	client, err := aws.NewBedrockClient(bedrockCfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	return aws.NewTimedBedrockClient(client, region, slow), nil
+}
+
+// NewAnalyticsSink creates the ClickHouse sink of ANALYTICS_SINK_URL, or
+// returns nil when it is empty
+func NewAnalyticsSink(cfg *config.Config, logger *logger.Logger) (services.AnalyticsSink, error) {
+	if cfg.AnalyticsSinkURL == "" {
+		return nil, nil
+	}
+	client, err := clickhouse.New(cfg.AnalyticsSinkURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ANALYTICS_SINK_URL: %w", err)
+	}
+	var tenants []string
+	for _, tenant := range strings.Split(cfg.AnalyticsSinkTenants, ",") {
+		if tenant = strings.TrimSpace(tenant); tenant != "" {
+			tenants = append(tenants, tenant)
+		}
+	}
+	return services.NewAnalyticsSink(client, tenants, cfg.AnalyticsSinkQueueSize, logger), nil
+}
+
+// NewSlowLog creates the observer of slow queries and external calls; slow
+// calls are counted in m when it is not nil
+func NewSlowLog(cfg *config.Config, logger *logger.Logger, m *metrics.Metrics) *slowlog.Observer {
+	var recorder slowlog.Recorder
+	if m != nil {
+		recorder = m
+	}
+	return slowlog.New(slowlog.Thresholds{
+		Database: time.Duration(cfg.SlowQueryThresholdMs) * time.Millisecond,
+		Platform: time.Duration(cfg.SlowPlatformCallThresholdMs) * time.Millisecond,
+		Bedrock:  time.Duration(cfg.SlowBedrockCallThresholdMs) * time.Millisecond,
+	}, recorder, logger)
+}
+
+// NewSLOTracker creates the tracker of the latency objectives of
+// SLO_OBJECTIVES; their requests are counted in m when it is not nil
+func NewSLOTracker(cfg *config.Config, m *metrics.Metrics, clk clock.Clock) (*slo.Tracker, error) {
+	objectives, err := slo.Parse(cfg.SLOObjectives)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLO_OBJECTIVES: %w", err)
+	}
+	var recorder slo.Recorder
+	if m != nil {
+		recorder = m
+	}
+	return slo.NewTracker(objectives, time.Duration(cfg.SLOWindowMinutes)*time.Minute, recorder, clk), nil
+}
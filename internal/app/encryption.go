@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/pkg/encryption"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// NewKeyProvider builds the data key provider selected by configuration, or
+// returns nil when column encryption is disabled
+func NewKeyProvider(ctx context.Context, cfg *config.Config) (encryption.KeyProvider, error) {
+	switch cfg.EncryptionProvider {
+	case "local":
+		masterKey, err := encryption.ParseMasterKey(cfg.EncryptionMasterKey)
+		if err != nil {
+			return nil, err
+		}
+		return encryption.NewLocalProvider(masterKey)
+	case "kms":
+		return encryption.NewKMSProvider(ctx, cfg.AWSRegion, cfg.EncryptionKMSKeyID)
+	default:
+		return nil, nil
+	}
+}
+
+// SetupEncryption unwraps the configured data keys and installs the keyring
+// used by encrypted columns. It must run before any database access.
+func SetupEncryption(ctx context.Context, cfg *config.Config, logger *logger.Logger) (*encryption.Keyring, error) {
+	provider, err := NewKeyProvider(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize encryption key provider: %w", err)
+	}
+	if provider == nil {
+		logger.Warn("Column encryption disabled, sensitive fields are stored in plaintext")
+		encryption.SetKeyring(nil)
+		return nil, nil
+	}
+
+	dataKeys, err := encryption.ParseDataKeys(cfg.EncryptionDataKeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(dataKeys) == 0 {
+		return nil, fmt.Errorf("ENCRYPTION_DATA_KEYS is required, generate a key with: go run ./cmd/encryption keygen")
+	}
+
+	// A single configured key is active by default
+	activeKey := cfg.EncryptionActiveKey
+	if activeKey == "" && len(dataKeys) == 1 {
+		for id := range dataKeys {
+			activeKey = id
+		}
+	}
+
+	keyring, err := encryption.NewKeyring(ctx, provider, dataKeys, activeKey)
+	if err != nil {
+		return nil, err
+	}
+	encryption.SetKeyring(keyring)
+
+	logger.Info("Column encryption enabled", "provider", cfg.EncryptionProvider, "active_key", activeKey, "keys", len(dataKeys))
+	return keyring, nil
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"go/build"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const modulePath = "github.com/jibe0123/mysteryfactory"
+
+// TestPackageBoundaries enforces the layering handlers → services → repositories → models.
+// Only this package (the composition root) may depend on every layer.
+func TestPackageBoundaries(t *testing.T) {
+	forbidden := map[string][]string{
+		"models":       {"internal/"},
+		"repositories": {"internal/services", "internal/handlers", "internal/middleware", "internal/router", "internal/app"},
+		"services":     {"internal/repositories", "internal/handlers", "internal/middleware", "internal/router", "internal/app", "pkg/db"},
+		"handlers":     {"internal/repositories", "internal/router", "internal/app"},
+		"middleware":   {"internal/services", "internal/repositories", "internal/handlers", "internal/router", "internal/app"},
+	}
+
+	for pkg, rules := range forbidden {
+		t.Run(pkg, func(t *testing.T) {
+			p, err := build.Default.ImportDir(filepath.Join("..", pkg), 0)
+			require.NoError(t, err)
+
+			for _, imp := range p.Imports {
+				if !strings.HasPrefix(imp, modulePath+"/") {
+					continue
+				}
+				rel := strings.TrimPrefix(imp, modulePath+"/")
+				for _, rule := range rules {
+					assert.False(t, strings.HasPrefix(rel, rule), "internal/%s must not import %s", pkg, imp)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/scheduler"
+)
+
+// Background job names, used as their lease IDs
+const (
+	StatsSyncJob           = "stats-sync"
+	CampaignSchedulerJob   = "campaign-scheduler"
+	DebugCaptureCleanupJob = "debug-capture-cleanup"
+	StatsFreshnessJob      = "stats-freshness"
+	StatsBackfillJob       = "stats-backfill"
+	PublicationStageJob    = "publication-stage"
+	PublicationReleaseJob  = "publication-release"
+	NotificationDigestJob  = "notification-digest"
+	JobCleanupJob          = "job-cleanup"
+	StatsCompactionJob     = "stats-compaction"
+	StatsScoreJob          = "stats-scores"
+	AlertRuleJob           = "alert-rules"
+	WebhookSubscriptionJob = "webhook-subscriptions"
+	MagicBrushBatchJob     = "magic-brush-batches"
+)
+
+// tenantPageSize is how many tenants the stats sync loads at a time
+const tenantPageSize = 100
+
+// NewScheduler registers the background jobs. Every replica runs it; the job
+// leases in the database make sure each job runs on one replica at a time.
+func NewScheduler(deps *Dependencies, locker scheduler.Locker, holder string) (*scheduler.Scheduler, error) {
+	cfg := deps.Config
+	s := scheduler.New(locker, holder, deps.Logger)
+
+	jobs := []scheduler.Job{
+		{
+			Name:     StatsSyncJob,
+			Interval: time.Duration(cfg.StatsSyncInterval) * time.Second,
+			Run:      func(ctx context.Context) error { return syncAllTenantStats(ctx, deps) },
+		},
+		{
+			Name:     CampaignSchedulerJob,
+			Interval: time.Duration(cfg.CampaignSchedulerInterval) * time.Second,
+			Run:      deps.CampaignService.ProcessScheduledCampaigns,
+		},
+		{
+			Name:     DebugCaptureCleanupJob,
+			Interval: time.Duration(cfg.DebugCaptureCleanupInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.DebugCaptureService.PurgeExpired(ctx)
+				return err
+			},
+		},
+		{
+			Name:     StatsFreshnessJob,
+			Interval: time.Duration(cfg.StatsFreshnessInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.StatsFreshness.Check(ctx)
+				return err
+			},
+		},
+		{
+			Name:     StatsBackfillJob,
+			Interval: time.Duration(cfg.StatsBackfillInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.StatsBackfill.Run(ctx)
+				return err
+			},
+		},
+		{
+			Name:     PublicationStageJob,
+			Interval: time.Duration(cfg.PublicationStageInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.PublicationService.Stage(ctx)
+				return err
+			},
+		},
+		{
+			Name:     PublicationReleaseJob,
+			Interval: time.Duration(cfg.PublicationReleaseInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.PublicationService.Release(ctx)
+				return err
+			},
+		},
+		{
+			Name:     NotificationDigestJob,
+			Interval: time.Duration(cfg.NotificationDigestInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.NotificationService.SendDigests(ctx)
+				return err
+			},
+		},
+		{
+			Name:     JobCleanupJob,
+			Interval: time.Duration(cfg.JobCleanupInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.JobService.PurgeFinished(ctx)
+				return err
+			},
+		},
+		{
+			Name:     StatsCompactionJob,
+			Interval: time.Duration(cfg.StatsCompactionInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.StatsCompaction.Run(ctx)
+				return err
+			},
+		},
+		{
+			Name:     StatsScoreJob,
+			Interval: time.Duration(cfg.StatsScoreInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.StatsScores.RecomputeDue(ctx)
+				return err
+			},
+		},
+		{
+			Name:     AlertRuleJob,
+			Interval: time.Duration(cfg.AlertRuleInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.AlertService.EvaluateStats(ctx)
+				return err
+			},
+		},
+		{
+			Name:     WebhookSubscriptionJob,
+			Interval: time.Duration(cfg.WebhookSubscriptionInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.WebhookSubscriptions.RenewDue(ctx)
+				return err
+			},
+		},
+		{
+			Name:     MagicBrushBatchJob,
+			Interval: time.Duration(cfg.MagicBrushBatchInterval) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := deps.MagicBrushBatches.Run(ctx)
+				return err
+			},
+		},
+	}
+	for _, job := range jobs {
+		if err := s.Register(job); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// syncAllTenantStats syncs the platform stats of every tenant. A failing
+// tenant is logged and skipped so it does not hold up the others.
+func syncAllTenantStats(ctx context.Context, deps *Dependencies) error {
+	for offset := 0; ; offset += tenantPageSize {
+		tenants, err := deps.Tenants.List(ctx, tenantPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to list tenants: %w", err)
+		}
+		for _, tenant := range tenants {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			job := models.StatsSyncJob(tenant.ID)
+			deps.JobService.Progress(ctx, tenant.ID, job, 0)
+			if _, err := deps.StatsSync.Sync(ctx, tenant.ID); err != nil {
+				if ctx.Err() != nil {
+					deps.JobService.Fail(ctx, tenant.ID, job, "interrupted by shutdown")
+					return ctx.Err()
+				}
+				deps.Logger.Error("Failed to sync tenant stats", "error", err, "tenant_id", tenant.ID)
+				deps.JobService.Fail(ctx, tenant.ID, job, err.Error())
+				continue
+			}
+			deps.JobService.Succeed(ctx, tenant.ID, job)
+		}
+		if len(tenants) < tenantPageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// pagedTenantRepo lists a fixed set of tenants
+type pagedTenantRepo struct {
+	models.TenantRepository
+	tenants []*models.Tenant
+}
+
+func (r *pagedTenantRepo) List(ctx context.Context, limit, offset int) ([]*models.Tenant, error) {
+	if offset >= len(r.tenants) {
+		return nil, nil
+	}
+	return r.tenants[offset:min(offset+limit, len(r.tenants))], nil
+}
+
+// recordingSync records the tenants it syncs and runs onSync after each
+type recordingSync struct {
+	services.StatsSyncService
+	synced []string
+	onSync func()
+}
+
+func (s *recordingSync) Sync(ctx context.Context, tenantID string) (*services.StatsSyncRunReport, error) {
+	s.synced = append(s.synced, tenantID)
+	if s.onSync != nil {
+		s.onSync()
+	}
+	return &services.StatsSyncRunReport{}, ctx.Err()
+}
+
+// recordingJobs records the state each tenant's stats sync job finished in
+type recordingJobs struct {
+	services.JobService
+	finished map[string]models.JobState
+}
+
+func (j *recordingJobs) Progress(ctx context.Context, tenantID string, ref models.JobRef, percent int) {
+}
+
+func (j *recordingJobs) Succeed(ctx context.Context, tenantID string, ref models.JobRef) {
+	j.finished[tenantID] = models.JobSucceeded
+}
+
+func (j *recordingJobs) Fail(ctx context.Context, tenantID string, ref models.JobRef, reason string) {
+	j.finished[tenantID] = models.JobFailed
+}
+
+func TestSyncAllTenantStats(t *testing.T) {
+	tenants := &pagedTenantRepo{tenants: []*models.Tenant{{ID: "acme"}, {ID: "globex"}, {ID: "initech"}}}
+
+	t.Run("syncs every tenant", func(t *testing.T) {
+		syncs := &recordingSync{}
+		jobs := &recordingJobs{finished: map[string]models.JobState{}}
+		deps := &Dependencies{Logger: logger.New("error", "test"), Tenants: tenants, StatsSync: syncs, JobService: jobs}
+
+		require.NoError(t, syncAllTenantStats(context.Background(), deps))
+		assert.Equal(t, []string{"acme", "globex", "initech"}, syncs.synced)
+		assert.Equal(t, map[string]models.JobState{"acme": models.JobSucceeded, "globex": models.JobSucceeded, "initech": models.JobSucceeded}, jobs.finished)
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		syncs := &recordingSync{onSync: cancel}
+		jobs := &recordingJobs{finished: map[string]models.JobState{}}
+		deps := &Dependencies{Logger: logger.New("error", "test"), Tenants: tenants, StatsSync: syncs, JobService: jobs}
+
+		err := syncAllTenantStats(ctx, deps)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []string{"acme"}, syncs.synced, "no tenant is synced after shutdown")
+		assert.Equal(t, map[string]models.JobState{"acme": models.JobFailed}, jobs.finished)
+	})
+}
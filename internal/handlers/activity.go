@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// ActivityHandler handles the activity feeds of videos and campaigns
+type ActivityHandler struct {
+	*BaseHandler
+	activityService services.ActivityService
+}
+
+// NewActivityHandler creates a new activity handler
+func NewActivityHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, activityService services.ActivityService) *ActivityHandler {
+	return &ActivityHandler{
+		BaseHandler:     NewBaseHandler(cfg, logger, db),
+		activityService: activityService,
+	}
+}
+
+// GetVideoActivity handles the activity feed of a video
+// @Summary Video activity
+// @Description List who did what on a video, newest first: requests made on it, content generated for it, its publications and the view milestones it reached. Pass the next_before of a page as before to get the next one.
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param before query string false "RFC 3339 time; only earlier events are listed"
+// @Param limit query int false "Events per page, up to 200" default(50)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/activity [get]
+func (h *ActivityHandler) GetVideoActivity(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+	before, limit, ok := h.activityPage(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.activityService.VideoActivity(c.Request.Context(), viewer, c.Param("id"), before, limit)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+		return
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+		return
+	default:
+		h.logger.Error("Failed to get video activity", "error", err, "tenant_id", viewer.TenantID, "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get video activity")
+		return
+	}
+
+	h.respondWithSuccess(c, "Video activity retrieved successfully", feed)
+}
+
+// GetCampaignActivity handles the activity feed of a campaign
+// @Summary Campaign activity
+// @Description List who did what on a campaign and on the videos made for it, newest first: requests made on them, content generated for them, publications and view milestones. Pass the next_before of a page as before to get the next one.
+// @Tags campaigns
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Param before query string false "RFC 3339 time; only earlier events are listed"
+// @Param limit query int false "Events per page, up to 200" default(50)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/campaigns/{id}/activity [get]
+func (h *ActivityHandler) GetCampaignActivity(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+	before, limit, ok := h.activityPage(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.activityService.CampaignActivity(c.Request.Context(), tenantID, c.Param("id"), before, limit)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+		return
+	default:
+		h.logger.Error("Failed to get campaign activity", "error", err, "tenant_id", tenantID, "campaign_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get campaign activity")
+		return
+	}
+
+	h.respondWithSuccess(c, "Campaign activity retrieved successfully", feed)
+}
+
+// activityPage reads the before and limit query parameters, responding with
+// an error when they are invalid
+func (h *ActivityHandler) activityPage(c *gin.Context) (time.Time, int, bool) {
+	var before time.Time
+	if raw := c.Query("before"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "before must be an RFC 3339 time")
+			return time.Time{}, 0, false
+		}
+		before = parsed
+	}
+
+	var limit int
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			h.respondWithError(c, http.StatusBadRequest, "limit must be a positive number")
+			return time.Time{}, 0, false
+		}
+		limit = parsed
+	}
+	return before, limit, true
+}
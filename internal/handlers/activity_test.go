@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubActivityService echoes the page it is asked for
+type stubActivityService struct {
+	services.ActivityService
+}
+
+func (s *stubActivityService) VideoActivity(ctx context.Context, viewer *models.User, videoID string, before time.Time, limit int) (*services.ActivityFeed, error) {
+	if videoID != "launch" {
+		return nil, models.ErrVideoNotFound
+	}
+	return s.CampaignActivity(ctx, viewer.TenantID, "", before, limit)
+}
+
+func (s *stubActivityService) CampaignActivity(ctx context.Context, tenantID, campaignID string, before time.Time, limit int) (*services.ActivityFeed, error) {
+	if limit > 200 {
+		return nil, fmt.Errorf("%w: limit must be at most 200", models.ErrInvalidInput)
+	}
+	return &services.ActivityFeed{Events: []*services.ActivityEvent{{
+		Type:       services.ActivityAudit,
+		Action:     fmt.Sprintf("limit %d", limit),
+		OccurredAt: before,
+	}}}, nil
+}
+
+func TestActivityHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewActivityHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubActivityService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/videos/:id/activity", handler.GetVideoActivity)
+	r.GET("/campaigns/:id/activity", handler.GetCampaignActivity)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/videos/launch/activity?before=2026-10-01T12:00:00Z&limit=20")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"action":"limit 20"`)
+	assert.Contains(t, w.Body.String(), `"occurred_at":"2026-10-01T12:00:00Z"`)
+
+	assert.Equal(t, http.StatusOK, get("/campaigns/fall/activity").Code)
+	assert.Equal(t, http.StatusNotFound, get("/videos/missing/activity").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/videos/launch/activity?before=yesterday").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/campaigns/fall/activity?limit=0").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/campaigns/fall/activity?limit=500").Code)
+}
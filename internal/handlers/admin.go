@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/middleware"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// AdminHandler handles support and audit requests of admins
+type AdminHandler struct {
+	*BaseHandler
+	impersonationService services.ImpersonationService
+	auditService         services.AuditService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, impersonationService services.ImpersonationService, auditService services.AuditService) *AdminHandler {
+	return &AdminHandler{
+		BaseHandler:          NewBaseHandler(cfg, logger, db),
+		impersonationService: impersonationService,
+		auditService:         auditService,
+	}
+}
+
+// ImpersonateResponse represents an impersonation token
+type ImpersonateResponse struct {
+	Token           string       `json:"token"`
+	ExpiresAt       time.Time    `json:"expires_at"`
+	ImpersonationID string       `json:"impersonation_id"`
+	User            *models.User `json:"user"`
+}
+
+// Impersonate handles issuing an impersonation token
+// @Summary Impersonate a user
+// @Description Issue a short-lived token acting as a tenant user, to reproduce an issue. Every request made with it is recorded in the tenant's audit log as impersonated; deletions and account, access and tenant settings changes are refused. Admins impersonate users of their own tenant; admins of the support tenant, users of any tenant.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ImpersonateRequest true "User to impersonate and reason"
+// @Success 200 {object} ImpersonateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/admin/impersonate [post]
+func (h *AdminHandler) Impersonate(c *gin.Context) {
+	user, exists := c.Get("user")
+	admin, ok := user.(*models.User)
+	if !exists || !ok {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	session, err := h.impersonationService.Start(c.Request.Context(), admin, &req, c.GetString("request_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrForbidden):
+			h.respondWithErr(c, http.StatusForbidden, err)
+		case errors.Is(err, models.ErrInvalidInput):
+			h.respondWithErr(c, http.StatusBadRequest, err)
+		case errors.Is(err, models.ErrUserNotFound):
+			h.respondWithError(c, http.StatusNotFound, "User not found")
+		case errors.Is(err, models.ErrUserInactive):
+			h.respondWithError(c, http.StatusConflict, "User is inactive")
+		default:
+			h.logger.Error("Failed to start impersonation", "error", err, "admin_id", admin.ID, "tenant_id", req.TenantID)
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to start impersonation")
+		}
+		return
+	}
+
+	claims := &middleware.JWTClaims{
+		UserID:          session.User.ID,
+		TenantID:        session.User.TenantID,
+		Email:           session.User.Email,
+		Role:            session.User.Role,
+		ImpersonatorID:  admin.ID,
+		ImpersonationID: session.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        session.ID,
+			ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(h.config.JWTSecret))
+	if err != nil {
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	c.JSON(http.StatusOK, ImpersonateResponse{
+		Token:           token,
+		ExpiresAt:       session.ExpiresAt,
+		ImpersonationID: session.ID,
+		User:            session.User,
+	})
+}
+
+// ListAuditLogs handles listing the tenant's audit log
+// @Summary List audit log
+// @Description List the audit log of the admin's tenant, newest first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param impersonated query bool false "Only actions taken while impersonating"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/admin/audit-logs [get]
+func (h *AdminHandler) ListAuditLogs(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+	entries, err := h.auditService.List(c.Request.Context(), tenantID, c.Query("impersonated") == "true", limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list audit logs", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list audit logs")
+		return
+	}
+
+	h.respondWithSuccess(c, "Audit logs retrieved successfully", entries)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/middleware"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubImpersonationService grants sessions for the user "editor-1" only
+type stubImpersonationService struct{}
+
+func (s *stubImpersonationService) Start(ctx context.Context, admin *models.User, req *models.ImpersonateRequest, requestID string) (*services.Impersonation, error) {
+	if req.UserID != "editor-1" {
+		return nil, models.ErrUserNotFound
+	}
+	return &services.Impersonation{
+		ID:        "imp-1",
+		Admin:     admin,
+		User:      &models.User{ID: "editor-1", TenantID: req.TenantID, Email: "editor@acme.test", Role: "editor"},
+		Reason:    req.Reason,
+		ExpiresAt: time.Now().Add(30 * time.Minute),
+	}, nil
+}
+
+func TestAdminHandler_Impersonate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	cfg := &config.Config{Environment: "test", JWTSecret: "test-secret-key"}
+	handler := NewAdminHandler(cfg, logger.New("error", "test"), mockDB, &stubImpersonationService{}, nil)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{ID: "admin-1", TenantID: "support", Role: "admin"})
+		c.Next()
+	})
+	r.POST("/admin/impersonate", handler.Impersonate)
+
+	post := func(req models.ImpersonateRequest) *httptest.ResponseRecorder {
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/admin/impersonate", bytes.NewReader(body)))
+		return w
+	}
+
+	w := post(models.ImpersonateRequest{TenantID: "acme", UserID: "editor-1", Reason: "Ticket 4521"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp ImpersonateResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "imp-1", resp.ImpersonationID)
+
+	claims := &middleware.JWTClaims{}
+	_, err := jwt.ParseWithClaims(resp.Token, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte("test-secret-key"), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "editor-1", claims.UserID)
+	assert.Equal(t, "acme", claims.TenantID)
+	assert.Equal(t, "admin-1", claims.ImpersonatorID, "the token names the admin behind it")
+	assert.Equal(t, "imp-1", claims.ImpersonationID)
+
+	assert.Equal(t, http.StatusBadRequest, post(models.ImpersonateRequest{TenantID: "acme", UserID: "editor-1"}).Code, "a reason is required")
+	assert.Equal(t, http.StatusNotFound, post(models.ImpersonateRequest{TenantID: "acme", UserID: "ghost", Reason: "Ticket 4521"}).Code)
+}
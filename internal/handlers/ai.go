@@ -1,24 +1,33 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/models"
 	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/residency"
 )
 
 // AIHandler handles AI-related HTTP requests
 type AIHandler struct {
-	aiService services.AIService
-	logger    *logger.Logger
+	aiService     services.AIService
+	promptService services.PromptService
+	chatService   services.ChatService
+	logger        *logger.Logger
 }
 
 // NewAIHandler creates a new AI handler
-func NewAIHandler(aiService services.AIService, logger *logger.Logger) *AIHandler {
+func NewAIHandler(aiService services.AIService, promptService services.PromptService, chatService services.ChatService, logger *logger.Logger) *AIHandler {
 	return &AIHandler{
-		aiService: aiService,
-		logger:    logger,
+		aiService:     aiService,
+		promptService: promptService,
+		chatService:   chatService,
+		logger:        logger,
 	}
 }
 
@@ -29,9 +38,11 @@ func NewAIHandler(aiService services.AIService, logger *logger.Logger) *AIHandle
 // @Accept json
 // @Produce json
 // @Param tenant_id header string true "Tenant ID"
+// @Param X-Prompt-Channel header string false "Prompt channel: draft, staging or production (default); admins only"
 // @Param request body MagicBrushRequest true "Magic brush request"
 // @Success 200 {object} MagicBrushResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/ai/magic-brush [post]
 func (h *AIHandler) GenerateMagicBrush(c *gin.Context) {
@@ -43,7 +54,7 @@ func (h *AIHandler) GenerateMagicBrush(c *gin.Context) {
 		h.logger.Error("Missing tenant ID in request")
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Tenant ID is required",
+			"message": i18n.T(c.GetString("locale"), "Tenant ID is required"),
 		})
 		return
 	}
@@ -54,7 +65,7 @@ func (h *AIHandler) GenerateMagicBrush(c *gin.Context) {
 		h.logger.Error("Failed to parse magic brush request", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Invalid request format",
+			"message": i18n.T(c.GetString("locale"), "Invalid request format"),
 			"details": err.Error(),
 		})
 		return
@@ -64,7 +75,7 @@ func (h *AIHandler) GenerateMagicBrush(c *gin.Context) {
 	if req.VideoID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Video ID is required",
+			"message": i18n.T(c.GetString("locale"), "Video ID is required"),
 		})
 		return
 	}
@@ -72,35 +83,46 @@ func (h *AIHandler) GenerateMagicBrush(c *gin.Context) {
 	if req.BrushType == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Brush type is required",
+			"message": i18n.T(c.GetString("locale"), "Brush type is required"),
 		})
 		return
 	}
 
 	// Validate brush type
-	validBrushTypes := []string{"title", "description", "tags"}
-	isValidType := false
-	for _, validType := range validBrushTypes {
-		if req.BrushType == validType {
-			isValidType = true
-			break
-		}
-	}
-	if !isValidType {
+	if _, ok := services.BrushPrompts[req.BrushType]; !ok {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Invalid brush type. Must be one of: title, description, tags",
+			"message": i18n.T(c.GetString("locale"), "Invalid brush type. Must be one of: title, description, tags, thumbnail"),
 		})
 		return
 	}
 
+	ctx, ok := h.promptChannelContext(c)
+	if !ok {
+		return
+	}
+
 	// Generate content using AI service
-	response, err := h.aiService.GenerateMagicBrush(c.Request.Context(), tenantID, &req)
+	response, err := h.aiService.GenerateMagicBrush(ctx, tenantID, c.GetString("user_id"), &req)
 	if err != nil {
 		h.logger.Error("Failed to generate magic brush content", "error", err, "tenant_id", tenantID, "video_id", req.VideoID)
+		if errors.Is(err, models.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": i18n.ErrorMessage(c.GetString("locale"), err),
+			})
+			return
+		}
+		if errors.Is(err, residency.ErrCrossRegion) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Conflict",
+				"message": i18n.ErrorMessage(c.GetString("locale"), err),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Internal Server Error",
-			"message": "Failed to generate content",
+			"message": i18n.T(c.GetString("locale"), "Failed to generate content"),
 			"details": err.Error(),
 		})
 		return
@@ -108,21 +130,23 @@ func (h *AIHandler) GenerateMagicBrush(c *gin.Context) {
 
 	h.logger.Info("Magic brush content generated successfully", "tenant_id", tenantID, "video_id", req.VideoID, "brush_type", req.BrushType)
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Content generated successfully",
+		"message": i18n.T(c.GetString("locale"), "Content generated successfully"),
 		"data":    response,
 	})
 }
 
 // TestPrompt tests a prompt from the catalog
 // @Summary Test a prompt from the catalog
-// @Description Test a prompt with provided data to see the rendered output
+// @Description Test a prompt with provided data to see the rendered output, from the production catalog or the channel in X-Prompt-Channel
 // @Tags AI
 // @Accept json
 // @Produce json
 // @Param tenant_id header string true "Tenant ID"
+// @Param X-Prompt-Channel header string false "Prompt channel: draft, staging or production (default); admins only"
 // @Param request body TestPromptRequest true "Test prompt request"
 // @Success 200 {object} TestPromptResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/ai/test-prompt [post]
 func (h *AIHandler) TestPrompt(c *gin.Context) {
@@ -134,7 +158,7 @@ func (h *AIHandler) TestPrompt(c *gin.Context) {
 		h.logger.Error("Missing tenant ID in request")
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Tenant ID is required",
+			"message": i18n.T(c.GetString("locale"), "Tenant ID is required"),
 		})
 		return
 	}
@@ -145,7 +169,7 @@ func (h *AIHandler) TestPrompt(c *gin.Context) {
 		h.logger.Error("Failed to parse test prompt request", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Invalid request format",
+			"message": i18n.T(c.GetString("locale"), "Invalid request format"),
 			"details": err.Error(),
 		})
 		return
@@ -155,18 +179,23 @@ func (h *AIHandler) TestPrompt(c *gin.Context) {
 	if req.PromptKey == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Prompt key is required",
+			"message": i18n.T(c.GetString("locale"), "Prompt key is required"),
 		})
 		return
 	}
 
+	ctx, ok := h.promptChannelContext(c)
+	if !ok {
+		return
+	}
+
 	// Process with Bedrock
-	result, err := h.aiService.ProcessWithBedrock(c.Request.Context(), req.PromptKey, req.TestData)
+	result, err := h.aiService.ProcessWithBedrock(ctx, req.PromptKey, req.TestData)
 	if err != nil {
 		h.logger.Error("Failed to test prompt", "error", err, "tenant_id", tenantID, "prompt_key", req.PromptKey)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Internal Server Error",
-			"message": "Failed to test prompt",
+			"message": i18n.T(c.GetString("locale"), "Failed to test prompt"),
 			"details": err.Error(),
 		})
 		return
@@ -174,7 +203,7 @@ func (h *AIHandler) TestPrompt(c *gin.Context) {
 
 	h.logger.Info("Prompt tested successfully", "tenant_id", tenantID, "prompt_key", req.PromptKey)
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Prompt tested successfully",
+		"message": i18n.T(c.GetString("locale"), "Prompt tested successfully"),
 		"data":    result,
 	})
 }
@@ -199,7 +228,7 @@ func (h *AIHandler) GetPrompts(c *gin.Context) {
 		h.logger.Error("Missing tenant ID in request")
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Tenant ID is required",
+			"message": i18n.T(c.GetString("locale"), "Tenant ID is required"),
 		})
 		return
 	}
@@ -227,6 +256,12 @@ func (h *AIHandler) GetPrompts(c *gin.Context) {
 			"description": "Generates relevant tags and hashtags",
 			"category":    "magic_brush",
 		},
+		{
+			"key":         "magic_brush/thumbnail_hook",
+			"name":        "Thumbnail Hook Generator",
+			"description": "Suggests short hook texts for thumbnails",
+			"category":    "magic_brush",
+		},
 	}
 
 	// Filter by category if provided
@@ -242,12 +277,313 @@ func (h *AIHandler) GetPrompts(c *gin.Context) {
 
 	h.logger.Info("Prompts retrieved successfully", "tenant_id", tenantID, "count", len(prompts), "category", category)
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Prompts retrieved successfully",
+		"message": i18n.T(c.GetString("locale"), "Prompts retrieved successfully"),
 		"data":    prompts,
 		"total":   len(prompts),
 	})
 }
 
+// ValidateCatalog lints the whole prompt catalog
+// @Summary Validate the prompt catalog
+// @Description Lint every prompt in the catalog (syntax, missing/unused variables, token budgets, required metadata), of production or the channel in X-Prompt-Channel
+// @Tags AI
+// @Produce json
+// @Param tenant_id header string true "Tenant ID"
+// @Param X-Prompt-Channel header string false "Prompt channel: draft, staging or production (default); admins only"
+// @Success 200 {object} services.CatalogValidationReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} services.CatalogValidationReport
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/ai/prompts/validate-catalog [post]
+func (h *AIHandler) ValidateCatalog(c *gin.Context) {
+	h.logger.Info("Validate catalog request received")
+
+	// Get tenant ID from header
+	tenantID := c.GetHeader("X-Tenant-ID")
+	if tenantID == "" {
+		h.logger.Error("Missing tenant ID in request")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": i18n.T(c.GetString("locale"), "Tenant ID is required"),
+		})
+		return
+	}
+
+	ctx, ok := h.promptChannelContext(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.promptService.ValidateCatalog(ctx)
+	if err != nil {
+		h.logger.Error("Failed to validate prompt catalog", "error", err, "tenant_id", tenantID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Internal Server Error",
+			"message": i18n.T(c.GetString("locale"), "Failed to validate prompt catalog"),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !report.Valid {
+		h.logger.Warn("Prompt catalog is invalid", "tenant_id", tenantID, "errors", report.Errors)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": i18n.T(c.GetString("locale"), "Prompt catalog is invalid"),
+			"data":    report,
+		})
+		return
+	}
+
+	h.logger.Info("Prompt catalog is valid", "tenant_id", tenantID, "warnings", report.Warnings)
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c.GetString("locale"), "Prompt catalog is valid"),
+		"data":    report,
+	})
+}
+
+// PromoteCatalog promotes a channel's prompt catalog to the next channel
+// @Summary Promote a prompt catalog
+// @Description Lint the catalog of a channel and, when it has no errors, make it the catalog of the next one: draft to staging, staging to production. The catalog is replaced at once; other replicas serve it from their next check of the catalog source. Every tenant runs the one catalog, so only admins of the support tenant promote it.
+// @Tags AI
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PromoteCatalogRequest true "Channel to promote"
+// @Success 200 {object} services.PromptPromotion
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} services.PromptPromotion
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/ai/prompts/promote [post]
+func (h *AIHandler) PromoteCatalog(c *gin.Context) {
+	var req PromoteCatalogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": i18n.T(c.GetString("locale"), "Invalid request format"),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	channel, err := services.ParsePromptChannel(req.Channel)
+	var promotion *services.PromptPromotion
+	if err == nil {
+		promotion, err = h.promptService.PromoteCatalog(c.Request.Context(), channel)
+	}
+	switch {
+	case err == nil:
+		h.logger.Info("Prompt catalog promoted", "from", promotion.From, "to", promotion.To, "user_id", c.GetString("user_id"))
+		c.JSON(http.StatusOK, gin.H{
+			"message": i18n.T(c.GetString("locale"), "Prompt catalog promoted successfully"),
+			"data":    promotion,
+		})
+	case errors.Is(err, models.ErrPromptCatalogInvalid):
+		h.logger.Warn("Prompt catalog not promoted", "channel", channel, "errors", promotion.Report.Errors)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": i18n.T(c.GetString("locale"), "Prompt catalog is invalid"),
+			"data":    promotion,
+		})
+	case errors.Is(err, models.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": i18n.ErrorMessage(c.GetString("locale"), err),
+		})
+	default:
+		h.logger.Error("Failed to promote prompt catalog", "error", err, "channel", channel)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Internal Server Error",
+			"message": i18n.T(c.GetString("locale"), "Failed to promote prompt catalog"),
+			"details": err.Error(),
+		})
+	}
+}
+
+// Chat sends a message to a multi-turn AI assistant conversation
+// @Summary Chat with the AI assistant
+// @Description Start or continue a conversation to iteratively refine titles, descriptions or tags. The full history is replayed to the model on every turn.
+// @Tags AI
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tenant_id header string true "Tenant ID"
+// @Param request body services.ChatRequest true "Chat request"
+// @Success 200 {object} services.ChatResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/ai/chat [post]
+func (h *AIHandler) Chat(c *gin.Context) {
+	h.logger.Info("Chat request received")
+
+	tenantID, userID, ok := h.chatOwner(c)
+	if !ok {
+		return
+	}
+
+	var req services.ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Failed to parse chat request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": i18n.T(c.GetString("locale"), "Invalid request format"),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.chatService.Chat(c.Request.Context(), tenantID, userID, &req)
+	if err != nil {
+		h.logger.Error("Failed to process chat message", "error", err, "tenant_id", tenantID, "conversation_id", req.ConversationID)
+		h.respondWithChatError(c, err, "Failed to process chat message")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c.GetString("locale"), "Chat message processed successfully"),
+		"data":    response,
+	})
+}
+
+// GetConversation returns a conversation with its message history
+// @Summary Get a chat conversation
+// @Description Get a conversation and its full message history
+// @Tags AI
+// @Produce json
+// @Security BearerAuth
+// @Param tenant_id header string true "Tenant ID"
+// @Param id path string true "Conversation ID"
+// @Success 200 {object} models.Conversation
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /api/v1/ai/chat/{id} [get]
+func (h *AIHandler) GetConversation(c *gin.Context) {
+	tenantID, userID, ok := h.chatOwner(c)
+	if !ok {
+		return
+	}
+
+	conversation, err := h.chatService.GetConversation(c.Request.Context(), tenantID, userID, c.Param("id"))
+	if err != nil {
+		h.respondWithChatError(c, err, "Failed to get conversation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c.GetString("locale"), "Conversation retrieved successfully"),
+		"data":    conversation,
+	})
+}
+
+// DeleteConversation deletes a conversation and its history
+// @Summary Delete a chat conversation
+// @Description Delete a conversation and its full message history
+// @Tags AI
+// @Produce json
+// @Security BearerAuth
+// @Param tenant_id header string true "Tenant ID"
+// @Param id path string true "Conversation ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/ai/chat/{id} [delete]
+func (h *AIHandler) DeleteConversation(c *gin.Context) {
+	tenantID, userID, ok := h.chatOwner(c)
+	if !ok {
+		return
+	}
+
+	if err := h.chatService.DeleteConversation(c.Request.Context(), tenantID, userID, c.Param("id")); err != nil {
+		h.respondWithChatError(c, err, "Failed to delete conversation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c.GetString("locale"), "Conversation deleted successfully"),
+	})
+}
+
+// chatOwner resolves the tenant and user owning a conversation
+func (h *AIHandler) chatOwner(c *gin.Context) (string, string, bool) {
+	tenantID := c.GetHeader("X-Tenant-ID")
+	if tenantID == "" {
+		h.logger.Error("Missing tenant ID in request")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": i18n.T(c.GetString("locale"), "Tenant ID is required"),
+		})
+		return "", "", false
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": i18n.T(c.GetString("locale"), "User not authenticated"),
+		})
+		return "", "", false
+	}
+
+	return tenantID, userID, true
+}
+
+// promptChannelContext returns the request context reading prompts from the
+// channel in the X-Prompt-Channel header. Only admins may use a channel
+// other than production.
+func (h *AIHandler) promptChannelContext(c *gin.Context) (context.Context, bool) {
+	channel, err := services.ParsePromptChannel(c.GetHeader("X-Prompt-Channel"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": i18n.ErrorMessage(c.GetString("locale"), err),
+		})
+		return nil, false
+	}
+	if channel != services.PromptChannelProduction && c.GetString("user_role") != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Forbidden",
+			"message": i18n.T(c.GetString("locale"), "Only admins can use prompt channels"),
+		})
+		return nil, false
+	}
+	return services.WithPromptChannel(c.Request.Context(), channel), true
+}
+
+// respondWithChatError maps chat service errors to HTTP responses
+func (h *AIHandler) respondWithChatError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, models.ErrConversationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Not Found",
+			"message": i18n.T(c.GetString("locale"), "Conversation not found"),
+		})
+	case errors.Is(err, models.ErrConversationExpired):
+		c.JSON(http.StatusGone, gin.H{
+			"error":   "Gone",
+			"message": i18n.T(c.GetString("locale"), "Conversation has expired"),
+		})
+	case errors.Is(err, models.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": i18n.ErrorMessage(c.GetString("locale"), err),
+		})
+	case errors.Is(err, residency.ErrCrossRegion):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Conflict",
+			"message": i18n.ErrorMessage(c.GetString("locale"), err),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Internal Server Error",
+			"message": i18n.T(c.GetString("locale"), message),
+			"details": err.Error(),
+		})
+	}
+}
+
 // Request/Response types
 
 // TestPromptRequest represents a request to test a prompt
@@ -256,6 +592,11 @@ type TestPromptRequest struct {
 	TestData  map[string]interface{} `json:"test_data,omitempty"`
 }
 
+// PromoteCatalogRequest names the channel whose catalog is promoted
+type PromoteCatalogRequest struct {
+	Channel string `json:"channel" binding:"required"`
+}
+
 // TestPromptResponse represents the response from testing a prompt
 type TestPromptResponse struct {
 	PromptKey string                 `json:"prompt_key"`
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/middleware"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// channelPromptService reports the channel it validates and promotes draft
+// and staging, the draft catalog having errors
+type channelPromptService struct {
+	services.PromptService
+	validated services.PromptChannel
+}
+
+func (s *channelPromptService) ValidateCatalog(ctx context.Context) (*services.CatalogValidationReport, error) {
+	s.validated = services.PromptChannelFrom(ctx)
+	return &services.CatalogValidationReport{Valid: true}, nil
+}
+
+func (s *channelPromptService) PromoteCatalog(ctx context.Context, from services.PromptChannel) (*services.PromptPromotion, error) {
+	to, ok := from.Next()
+	if !ok {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the production catalog cannot be promoted")
+	}
+	promotion := &services.PromptPromotion{From: from, To: to, Report: &services.CatalogValidationReport{Valid: from != services.PromptChannelDraft}}
+	if !promotion.Report.Valid {
+		return promotion, models.ErrPromptCatalogInvalid
+	}
+	return promotion, nil
+}
+
+func TestAIHandler_PromptChannels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	prompts := &channelPromptService{}
+	handler := NewAIHandler(nil, prompts, nil, logger.New("error", "test"))
+	role := "admin"
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_role", role)
+		c.Next()
+	})
+	r.POST("/ai/prompts/validate-catalog", handler.ValidateCatalog)
+	r.POST("/ai/prompts/promote", handler.PromoteCatalog)
+
+	serve := func(path, channel, body string) int {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", "tenant-1")
+		if channel != "" {
+			req.Header.Set("X-Prompt-Channel", channel)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/ai/prompts/validate-catalog", "staging", ""))
+	assert.Equal(t, services.PromptChannelStaging, prompts.validated)
+	assert.Equal(t, http.StatusOK, serve("/ai/prompts/validate-catalog", "", ""))
+	assert.Equal(t, services.PromptChannelProduction, prompts.validated)
+	assert.Equal(t, http.StatusBadRequest, serve("/ai/prompts/validate-catalog", "canary", ""))
+
+	assert.Equal(t, http.StatusOK, serve("/ai/prompts/promote", "", `{"channel":"staging"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, serve("/ai/prompts/promote", "", `{"channel":"draft"}`))
+	assert.Equal(t, http.StatusBadRequest, serve("/ai/prompts/promote", "", `{"channel":"production"}`))
+	assert.Equal(t, http.StatusBadRequest, serve("/ai/prompts/promote", "", `{}`))
+
+	role = "user"
+	assert.Equal(t, http.StatusForbidden, serve("/ai/prompts/validate-catalog", "staging", ""))
+	assert.Equal(t, http.StatusOK, serve("/ai/prompts/validate-catalog", "production", ""))
+}
+
+func TestAIHandler_PromoteCatalogSupportOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewAIHandler(nil, &channelPromptService{}, nil, logger.New("error", "test"))
+	tenantID, impersonatorID := "support", ""
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{ID: "admin-1", TenantID: tenantID, Role: "admin"})
+		c.Set("tenant_id", tenantID)
+		c.Set("impersonator_id", impersonatorID)
+		c.Next()
+	})
+	// Gated as in the router
+	r.POST("/ai/prompts/promote", middleware.RequireRole("admin"), middleware.RequireSupportTenant("support"), middleware.DenyImpersonation(), handler.PromoteCatalog)
+
+	promote := func() int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/ai/prompts/promote", strings.NewReader(`{"channel":"staging"}`)))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, promote())
+
+	tenantID = "tenant-1"
+	assert.Equal(t, http.StatusForbidden, promote(), "admins of other tenants cannot change every tenant's prompts")
+
+	tenantID, impersonatorID = "support", "support-2"
+	assert.Equal(t, http.StatusForbidden, promote(), "nor can an impersonated session")
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// AlertHandler handles the alert rules of the current user
+type AlertHandler struct {
+	*BaseHandler
+	alertService services.AlertService
+}
+
+// NewAlertHandler creates a new alert handler
+func NewAlertHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, alertService services.AlertService) *AlertHandler {
+	return &AlertHandler{
+		BaseHandler:  NewBaseHandler(cfg, logger, db),
+		alertService: alertService,
+	}
+}
+
+// CreateAlertRule handles creating an alert rule
+// @Summary Create alert rule
+// @Description Get notified when a metric of the tenant's videos crosses a threshold: views, likes, comments or shares above or below a count, engagement_rate (percent of views, for videos with 100 views or more) above or below a percentage, or publication_failures above a number of failed attempts. A rule notifies once when its condition starts to hold for a video on a platform, and again only after it stopped holding. Restrict it with platform or video_id.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.AlertRuleRequest true "Alert rule"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/alert-rules [post]
+func (h *AlertHandler) CreateAlertRule(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	rule, err := h.alertService.CreateRule(c.Request.Context(), tenantID, userID, &req)
+	if !h.handleRuleError(c, err, "create") {
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Message: "Alert rule created successfully",
+		Data:    rule,
+	})
+}
+
+// ListAlertRules handles listing the user's alert rules
+// @Summary List alert rules
+// @Description List the current user's alert rules, oldest first
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/alert-rules [get]
+func (h *AlertHandler) ListAlertRules(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+	rules, err := h.alertService.ListRules(c.Request.Context(), tenantID, userID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list alert rules", "error", err, "tenant_id", tenantID, "user_id", userID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list alert rules")
+		return
+	}
+	h.respondWithSuccess(c, "Alert rules retrieved successfully", rules)
+}
+
+// GetAlertRule handles getting an alert rule
+// @Summary Get alert rule
+// @Description Get one of the current user's alert rules
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Alert rule ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/alert-rules/{id} [get]
+func (h *AlertHandler) GetAlertRule(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	rule, err := h.alertService.GetRule(c.Request.Context(), tenantID, userID, c.Param("id"))
+	if !h.handleRuleError(c, err, "get") {
+		return
+	}
+	h.respondWithSuccess(c, "Alert rule retrieved successfully", rule)
+}
+
+// UpdateAlertRule handles replacing an alert rule
+// @Summary Update alert rule
+// @Description Replace the settings of one of the current user's alert rules. The changed rule notifies again about the videos that meet it.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Alert rule ID"
+// @Param request body models.AlertRuleRequest true "Alert rule"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/alert-rules/{id} [put]
+func (h *AlertHandler) UpdateAlertRule(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	rule, err := h.alertService.UpdateRule(c.Request.Context(), tenantID, userID, c.Param("id"), &req)
+	if !h.handleRuleError(c, err, "update") {
+		return
+	}
+	h.respondWithSuccess(c, "Alert rule updated successfully", rule)
+}
+
+// DeleteAlertRule handles deleting an alert rule
+// @Summary Delete alert rule
+// @Description Delete one of the current user's alert rules
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Alert rule ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/alert-rules/{id} [delete]
+func (h *AlertHandler) DeleteAlertRule(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	err = h.alertService.DeleteRule(c.Request.Context(), tenantID, userID, c.Param("id"))
+	if !h.handleRuleError(c, err, "delete") {
+		return
+	}
+	h.respondWithSuccess(c, "Alert rule deleted successfully", nil)
+}
+
+// handleRuleError answers the errors of an alert rule call, reporting
+// whether there was none
+func (h *AlertHandler) handleRuleError(c *gin.Context, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, models.ErrInvalidInput), errors.Is(err, models.ErrInvalidPlatform):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrAlertRuleNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Alert rule not found")
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	default:
+		h.logger.Error("Failed to "+action+" alert rule", "error", err, "tenant_id", c.GetString("tenant_id"), "alert_rule_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to "+action+" alert rule")
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAlertService knows the rule "rule-1" and accepts views rules only
+type stubAlertService struct {
+	services.AlertService
+}
+
+func (s *stubAlertService) CreateRule(ctx context.Context, tenantID, userID string, req *models.AlertRuleRequest) (*models.AlertRule, error) {
+	if req.Metric != models.AlertViews {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "metric must be one of %v", models.AlertStatsMetrics)
+	}
+	return &models.AlertRule{ID: "rule-1", TenantID: tenantID, UserID: userID, Name: req.Name, Metric: string(req.Metric)}, nil
+}
+
+func (s *stubAlertService) DeleteRule(ctx context.Context, tenantID, userID, id string) error {
+	if id != "rule-1" {
+		return models.ErrAlertRuleNotFound
+	}
+	return nil
+}
+
+func TestAlertHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewAlertHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubAlertService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/alert-rules", handler.CreateAlertRule)
+	r.DELETE("/alert-rules/:id", handler.DeleteAlertRule)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/alert-rules", `{"name":"100k views","metric":"views","condition":"above","threshold":100000}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"user_id":"test-user-123"`)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/alert-rules", `{"name":"x","metric":"watch_time","condition":"above"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/alert-rules", `{"metric":"views"}`).Code, "a rule needs a name")
+
+	assert.Equal(t, http.StatusOK, do("DELETE", "/alert-rules/rule-1", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/alert-rules/rule-2", "").Code)
+}
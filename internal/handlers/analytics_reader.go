@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// AnalyticsReaderHandler handles the read-only credentials of BI tools and
+// the queries they run
+type AnalyticsReaderHandler struct {
+	*BaseHandler
+	readerService services.AnalyticsReaderService
+}
+
+// NewAnalyticsReaderHandler creates a new analytics reader handler
+func NewAnalyticsReaderHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, readerService services.AnalyticsReaderService) *AnalyticsReaderHandler {
+	return &AnalyticsReaderHandler{
+		BaseHandler:   NewBaseHandler(cfg, logger, db),
+		readerService: readerService,
+	}
+}
+
+// CreateAnalyticsReader handles creating an analytics reader
+// @Summary Create analytics reader
+// @Description Create a read-only credential for a BI tool such as Looker or Metabase. The token is returned once, in the response: it only authenticates POST /api/v1/analytics/query, over the tenant's stats, and is refused by every other endpoint.
+// @Tags analytics-readers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.AnalyticsReaderRequest true "Analytics reader"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/analytics-readers [post]
+func (h *AnalyticsReaderHandler) CreateAnalyticsReader(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.AnalyticsReaderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	reader, err := h.readerService.CreateReader(c.Request.Context(), tenantID, userID, &req)
+	if err != nil {
+		h.logger.Error("Failed to create analytics reader", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to create analytics reader")
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Message: "Analytics reader created successfully",
+		Data:    reader,
+	})
+}
+
+// ListAnalyticsReaders handles listing the tenant's analytics readers
+// @Summary List analytics readers
+// @Description List the tenant's analytics readers, newest first, revoked ones included. Tokens are not shown, only their last characters.
+// @Tags analytics-readers
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/analytics-readers [get]
+func (h *AnalyticsReaderHandler) ListAnalyticsReaders(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+	readers, err := h.readerService.ListReaders(c.Request.Context(), tenantID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list analytics readers", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list analytics readers")
+		return
+	}
+	h.respondWithSuccess(c, "Analytics readers retrieved successfully", readers)
+}
+
+// RevokeAnalyticsReader handles revoking an analytics reader
+// @Summary Revoke analytics reader
+// @Description Revoke an analytics reader; its token is refused from the next query on
+// @Tags analytics-readers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Analytics reader ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/analytics-readers/{id} [delete]
+func (h *AnalyticsReaderHandler) RevokeAnalyticsReader(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	err = h.readerService.RevokeReader(c.Request.Context(), tenantID, c.Param("id"))
+	switch {
+	case errors.Is(err, models.ErrAnalyticsReaderNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Analytics reader not found")
+	case err != nil:
+		h.logger.Error("Failed to revoke analytics reader", "error", err, "tenant_id", tenantID, "reader_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to revoke analytics reader")
+	default:
+		h.respondWithSuccess(c, "Analytics reader revoked successfully", nil)
+	}
+}
+
+// QueryAnalytics handles a query of an analytics reader
+// @Summary Query analytics
+// @Description Aggregate the tenant's stats for a BI tool, authenticated with an analytics reader token rather than a user's. Rows group by the dimensions (platform, video_id, campaign_id, workspace_id, video_status, created_month) and hold the measures (views, likes, comments, shares, watch_time, impressions, revenue, videos, engagement_rate), filtered with in or not_in on dimensions and sorted by selected columns, up to 10000 rows.
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.AnalyticsQuery true "Query"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/analytics/query [post]
+func (h *AnalyticsReaderHandler) QueryAnalytics(c *gin.Context) {
+	tenantID := c.GetString("tenant_id")
+
+	var query models.AnalyticsQuery
+	if err := c.ShouldBindJSON(&query); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	result, err := h.readerService.Query(c.Request.Context(), tenantID, &query)
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case err != nil:
+		h.logger.Error("Failed to query analytics", "error", err, "tenant_id", tenantID, "reader_id", c.GetString("analytics_reader_id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to query analytics")
+	default:
+		h.respondWithSuccess(c, "Analytics retrieved successfully", result)
+	}
+}
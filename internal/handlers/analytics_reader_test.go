@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/middleware"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAnalyticsReaderService knows the token "mfar_looker" of tenant acme
+// and validates queries for real
+type stubAnalyticsReaderService struct {
+	services.AnalyticsReaderService
+	tenantID string
+}
+
+func (s *stubAnalyticsReaderService) Authenticate(ctx context.Context, token string) (*models.AnalyticsReader, error) {
+	if token != "mfar_looker" {
+		return nil, models.ErrAnalyticsReaderNotFound
+	}
+	return &models.AnalyticsReader{ID: "reader-1", TenantID: "acme"}, nil
+}
+
+func (s *stubAnalyticsReaderService) Query(ctx context.Context, tenantID string, query *models.AnalyticsQuery) (*models.AnalyticsResult, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	s.tenantID = tenantID
+	return &models.AnalyticsResult{Columns: query.Columns(), Rows: [][]interface{}{{"youtube", 1200}}}, nil
+}
+
+func (s *stubAnalyticsReaderService) RevokeReader(ctx context.Context, tenantID, id string) error {
+	if id != "reader-1" {
+		return models.ErrAnalyticsReaderNotFound
+	}
+	return nil
+}
+
+func TestAnalyticsReaderHandler_Query(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	svc := &stubAnalyticsReaderService{}
+	handler := NewAnalyticsReaderHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, svc)
+	r := gin.New()
+	r.POST("/analytics/query", middleware.AnalyticsReaderAuth(svc.Authenticate), handler.QueryAnalytics)
+
+	do := func(token, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/analytics/query", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	query := `{"dimensions":["platform"],"measures":["views"]}`
+	w := do("mfar_looker", query)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"columns":["platform","views"]`)
+	assert.Equal(t, "acme", svc.tenantID, "the reader's tenant is queried")
+
+	assert.Equal(t, http.StatusUnauthorized, do("mfar_revoked", query).Code)
+	assert.Equal(t, http.StatusUnauthorized, do("eyJhbGciOiJIUzI1NiJ9.user.token", query).Code, "user tokens are refused")
+	assert.Equal(t, http.StatusBadRequest, do("mfar_looker", `{"measures":["views"],"dimensions":["users.email"]}`).Code)
+}
+
+func TestAnalyticsReaderHandler_Revoke(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewAnalyticsReaderHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubAnalyticsReaderService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.DELETE("/analytics-readers/:id", handler.RevokeAnalyticsReader)
+
+	for id, code := range map[string]int{"reader-1": http.StatusOK, "reader-2": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("DELETE", "/analytics-readers/"+id, nil))
+		assert.Equal(t, code, w.Code, id)
+	}
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// ApprovalHandler handles publishing videos and approving the publications
+type ApprovalHandler struct {
+	*BaseHandler
+	approvalService services.ApprovalService
+}
+
+// NewApprovalHandler creates a new approval handler
+func NewApprovalHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, approvalService services.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{
+		BaseHandler:     NewBaseHandler(cfg, logger, db),
+		approvalService: approvalService,
+	}
+}
+
+// Publish handles publishing a video to a platform
+// @Summary Publish video
+// @Description Publish a video to a platform with a workspace's credentials, now or at scheduled_at. When the tenant requires approval the publication is "awaiting_approval" and its approvers are notified; otherwise it is "scheduled".
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param request body models.CreatePublicationJobRequest true "Publication data"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/publish [post]
+func (h *ApprovalHandler) Publish(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreatePublicationJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	job, err := h.approvalService.Publish(c.Request.Context(), user, c.Param("id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrVideoNotFound):
+			h.respondWithError(c, http.StatusNotFound, "Video not found")
+		case errors.Is(err, models.ErrInvalidPlatform), errors.Is(err, models.ErrRightsExpired), errors.Is(err, models.ErrPlatformNotLicensed):
+			h.respondWithErr(c, http.StatusBadRequest, err)
+		case errors.Is(err, models.ErrVideoArchived):
+			h.respondWithErr(c, http.StatusConflict, err)
+		default:
+			h.respondWithApprovalError(c, err, "Failed to publish video")
+		}
+		return
+	}
+
+	message := "Video publication started"
+	switch models.PublicationStatus(job.Status) {
+	case models.PublicationAwaitingApproval:
+		message = "Video publication awaiting approval"
+	case models.PublicationAwaitingReconnect:
+		message = "Video publication on hold until the workspace is reconnected"
+	}
+	h.respondWithSuccess(c, message, job)
+}
+
+// ListAwaitingApproval handles listing the publications awaiting approval
+// @Summary List publications awaiting approval
+// @Description Get the tenant's publications awaiting approval
+// @Tags publications
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of items per page" default(20)
+// @Param offset query int false "Number of items to skip" default(0)
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/publications/awaiting-approval [get]
+func (h *ApprovalHandler) ListAwaitingApproval(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+	jobs, err := h.approvalService.AwaitingApproval(c.Request.Context(), tenantID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list publications awaiting approval", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list publications awaiting approval")
+		return
+	}
+
+	h.respondWithSuccess(c, "Publications awaiting approval retrieved successfully", jobs)
+}
+
+// Approve handles approving a publication
+// @Summary Approve publication
+// @Description Approve a publication awaiting approval, which is then scheduled. Users with the tenant's approver role and admins may, except who requested it.
+// @Tags publications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Publication ID"
+// @Param request body models.ReviewPublicationRequest false "Comment"
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/publications/{id}/approve [post]
+func (h *ApprovalHandler) Approve(c *gin.Context) {
+	h.review(c, h.approvalService.Approve, "Publication approved", "Failed to approve publication")
+}
+
+// Reject handles rejecting a publication
+// @Summary Reject publication
+// @Description Reject a publication awaiting approval, with a comment telling why. Users with the tenant's approver role and admins may, except who requested it.
+// @Tags publications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Publication ID"
+// @Param request body models.ReviewPublicationRequest true "Comment"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/publications/{id}/reject [post]
+func (h *ApprovalHandler) Reject(c *gin.Context) {
+	h.review(c, h.approvalService.Reject, "Publication rejected", "Failed to reject publication")
+}
+
+// GetPolicy handles getting the tenant's approval policy
+// @Summary Get publication approval policy
+// @Description Get the role of who approves the tenant's publications; empty when they need no approval
+// @Tags publications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/publications/approval-policy [get]
+func (h *ApprovalHandler) GetPolicy(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	policy, err := h.approvalService.Policy(c.Request.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to get approval policy", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get approval policy")
+		return
+	}
+
+	h.respondWithSuccess(c, "Approval policy retrieved successfully", policy)
+}
+
+// UpdatePolicy handles changing the tenant's approval policy
+// @Summary Update publication approval policy
+// @Description Require publications to be approved by a second user with approver_role, or publish without approval when it is empty
+// @Tags publications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ApprovalPolicy true "Approval policy"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/publications/approval-policy [put]
+func (h *ApprovalHandler) UpdatePolicy(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.ApprovalPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	policy, err := h.approvalService.SetPolicy(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidInput):
+			h.respondWithErr(c, http.StatusBadRequest, err)
+		case errors.Is(err, models.ErrTenantNotFound):
+			h.respondWithError(c, http.StatusNotFound, "Tenant not found")
+		default:
+			h.logger.Error("Failed to update approval policy", "error", err, "tenant_id", tenantID)
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to update approval policy")
+		}
+		return
+	}
+
+	h.respondWithSuccess(c, "Approval policy updated successfully", policy)
+}
+
+// currentUser returns the user making the request
+func (h *ApprovalHandler) currentUser(c *gin.Context) (*models.User, bool) {
+	user, exists := c.Get("user")
+	current, ok := user.(*models.User)
+	if !exists || !ok {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return nil, false
+	}
+	return current, true
+}
+
+func (h *ApprovalHandler) review(c *gin.Context, decide func(ctx context.Context, approver *models.User, publicationID, comment string) (*models.PublicationJob, error), message, failure string) {
+	approver, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReviewPublicationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+	}
+
+	job, err := decide(c.Request.Context(), approver, c.Param("id"), req.Comment)
+	if err != nil {
+		h.respondWithApprovalError(c, err, failure)
+		return
+	}
+
+	h.respondWithSuccess(c, message, job)
+}
+
+func (h *ApprovalHandler) respondWithApprovalError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, models.ErrPublicationNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Publication not found")
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrForbidden):
+		h.respondWithErr(c, http.StatusForbidden, err)
+	case errors.Is(err, models.ErrConflict):
+		h.respondWithErr(c, http.StatusConflict, err)
+	default:
+		h.logger.Error(failure, "error", err, "id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, failure)
+	}
+}
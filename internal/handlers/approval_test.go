@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubApprovalService holds publications of "video-1" for approval; "pub-1"
+// awaits approval
+type stubApprovalService struct {
+	services.ApprovalService
+}
+
+func (s *stubApprovalService) Publish(ctx context.Context, user *models.User, videoID string, req *models.CreatePublicationJobRequest) (*models.PublicationJob, error) {
+	if videoID != "video-1" {
+		return nil, models.ErrVideoNotFound
+	}
+	if !models.Platform(req.Platform).Valid() {
+		return nil, models.ErrInvalidPlatform
+	}
+	return &models.PublicationJob{ID: "pub-1", VideoID: videoID, Platform: req.Platform, Status: string(models.PublicationAwaitingApproval)}, nil
+}
+
+func (s *stubApprovalService) Approve(ctx context.Context, approver *models.User, publicationID, comment string) (*models.PublicationJob, error) {
+	if publicationID != "pub-1" {
+		return nil, models.ErrPublicationNotFound
+	}
+	if approver.ID == "test-user-123" {
+		return nil, i18n.Errorf(models.ErrForbidden, "publications are approved by someone other than who requested them")
+	}
+	return &models.PublicationJob{ID: publicationID, Status: string(models.PublicationScheduled)}, nil
+}
+
+func (s *stubApprovalService) Reject(ctx context.Context, approver *models.User, publicationID, comment string) (*models.PublicationJob, error) {
+	if comment == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "a comment is required to reject a publication")
+	}
+	return nil, i18n.Errorf(models.ErrConflict, "the publication is not awaiting approval")
+}
+
+func TestApprovalHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewApprovalHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubApprovalService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/videos/:id/publish", handler.Publish)
+	r.POST("/publications/:id/approve", handler.Approve)
+	r.POST("/publications/:id/reject", handler.Reject)
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, post("/videos/video-1/publish", `{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post("/videos/video-1/publish", `{"platform":"invalid-platform"}`).Code)
+	assert.Equal(t, http.StatusNotFound, post("/videos/video-2/publish", `{"platform":"youtube"}`).Code)
+	w := post("/videos/video-1/publish", `{"platform":"youtube","config":{"privacy":"public"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"awaiting_approval"`)
+
+	assert.Equal(t, http.StatusNotFound, post("/publications/pub-2/approve", ``).Code)
+	assert.Equal(t, http.StatusForbidden, post("/publications/pub-1/approve", `{"comment":"looks good"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post("/publications/pub-1/reject", `{}`).Code)
+	assert.Equal(t, http.StatusConflict, post("/publications/pub-1/reject", `{"comment":"wrong thumbnail"}`).Code)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/residency"
+)
+
+// ArchiveHandler handles cold storage lifecycle requests
+type ArchiveHandler struct {
+	*BaseHandler
+	archiveService services.ArchiveService
+}
+
+// NewArchiveHandler creates a new archive handler
+func NewArchiveHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, archiveService services.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{
+		BaseHandler:    NewBaseHandler(cfg, logger, db),
+		archiveService: archiveService,
+	}
+}
+
+// RestoreVideo handles restoring an archived video
+// @Summary Restore archived video
+// @Description Start retrieving an archived video from Glacier. Retrieval takes minutes to hours depending on the tenant's restore tier; poll the archive status until the video is ready again.
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Success 202 {object} services.ArchiveStatus
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/restore [post]
+func (h *ArchiveHandler) RestoreVideo(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.archiveService.RestoreVideo(c.Request.Context(), viewer, c.Param("id"))
+	if err != nil {
+		h.logger.Error("Failed to restore video", "error", err, "user_id", viewer.ID, "tenant_id", viewer.TenantID, "video_id", c.Param("id"))
+		h.respondWithArchiveError(c, err, "Failed to restore video")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Message: "Video restore started",
+		Data:    status,
+	})
+}
+
+// GetArchiveStatus handles retrieving the archive state of a video
+// @Summary Get video archive status
+// @Description Get the storage class of a video and the progress of its restore
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Success 200 {object} services.ArchiveStatus
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/archive [get]
+func (h *ArchiveHandler) GetArchiveStatus(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.archiveService.GetArchiveStatus(c.Request.Context(), viewer, c.Param("id"))
+	if err != nil {
+		h.respondWithArchiveError(c, err, "Failed to get archive status")
+		return
+	}
+
+	h.respondWithSuccess(c, "Archive status retrieved successfully", status)
+}
+
+// GetRetentionPolicy handles retrieving the tenant's retention rules
+// @Summary Get retention policy
+// @Description Get the tenant's cold storage rules; archiving is off until archive_after_months is set
+// @Tags archive
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.RetentionPolicy
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/archive/policy [get]
+func (h *ArchiveHandler) GetRetentionPolicy(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	policy, err := h.archiveService.GetRetentionPolicy(c.Request.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to get retention policy", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get retention policy")
+		return
+	}
+
+	h.respondWithSuccess(c, "Retention policy retrieved successfully", policy)
+}
+
+// UpdateRetentionPolicy handles changing the tenant's retention rules
+// @Summary Update retention policy
+// @Description Change the tenant's cold storage rules. Unpublished videos not updated for archive_after_months months are moved to Glacier; 0 disables archiving.
+// @Tags archive
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateRetentionPolicyRequest true "Retention rules"
+// @Success 200 {object} models.RetentionPolicy
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/archive/policy [put]
+func (h *ArchiveHandler) UpdateRetentionPolicy(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.UpdateRetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	h.logger.Info("Updating retention policy", "user_id", userID, "tenant_id", tenantID)
+
+	policy, err := h.archiveService.UpdateRetentionPolicy(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		h.logger.Error("Failed to update retention policy", "error", err, "tenant_id", tenantID)
+		h.respondWithArchiveError(c, err, "Failed to update retention policy")
+		return
+	}
+
+	h.respondWithSuccess(c, "Retention policy updated successfully", policy)
+}
+
+// respondWithArchiveError maps archive service errors to HTTP responses
+func (h *ArchiveHandler) respondWithArchiveError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	case errors.Is(err, models.ErrVideoNotArchived):
+		h.respondWithError(c, http.StatusConflict, "Video is not archived")
+	case errors.Is(err, residency.ErrCrossRegion):
+		h.respondWithErr(c, http.StatusConflict, err)
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// AssetHandler handles the tenant's library of intros, outros, logos and
+// music
+type AssetHandler struct {
+	*BaseHandler
+	assetService services.AssetService
+}
+
+// NewAssetHandler creates a new asset handler
+func NewAssetHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, assetService services.AssetService) *AssetHandler {
+	return &AssetHandler{
+		BaseHandler:  NewBaseHandler(cfg, logger, db),
+		assetService: assetService,
+	}
+}
+
+// ListAssets handles listing the tenant's assets
+// @Summary List assets
+// @Description List the tenant's library, newest first, of one kind or of every kind
+// @Tags assets
+// @Produce json
+// @Security BearerAuth
+// @Param kind query string false "Asset kind" Enums(intro, outro, logo, music)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/assets [get]
+func (h *AssetHandler) ListAssets(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	assets, err := h.assetService.List(c.Request.Context(), tenantID, models.AssetKind(c.Query("kind")))
+	if !h.handleAssetError(c, err, "list assets") {
+		return
+	}
+	h.respondWithSuccess(c, "Assets retrieved successfully", assets)
+}
+
+// CreateAsset handles adding an asset
+// @Summary Add asset
+// @Description Add an intro or outro clip, a logo or a music track with its license. PUT the file, of up to 5 GiB, to upload_url within the hour, then complete the asset.
+// @Tags assets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateAssetRequest true "Asset"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/assets [post]
+func (h *AssetHandler) CreateAsset(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.CreateAssetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	upload, err := h.assetService.Create(c.Request.Context(), tenantID, userID, &req)
+	if !h.handleAssetError(c, err, "create asset") {
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{Message: "Asset created successfully", Data: upload})
+}
+
+// CompleteAsset handles completing the upload of an asset
+// @Summary Complete asset upload
+// @Description Make the asset ready once its file is uploaded to upload_url
+// @Tags assets
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Asset ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/assets/{id}/complete [post]
+func (h *AssetHandler) CompleteAsset(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	asset, err := h.assetService.Complete(c.Request.Context(), tenantID, c.Param("id"))
+	if !h.handleAssetError(c, err, "complete asset upload") {
+		return
+	}
+	h.respondWithSuccess(c, "Asset uploaded successfully", asset)
+}
+
+// GetAsset handles getting an asset
+// @Summary Get asset
+// @Description Get an asset of the tenant's library
+// @Tags assets
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Asset ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/assets/{id} [get]
+func (h *AssetHandler) GetAsset(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	asset, err := h.assetService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if !h.handleAssetError(c, err, "get asset") {
+		return
+	}
+	h.respondWithSuccess(c, "Asset retrieved successfully", asset)
+}
+
+// UpdateAsset handles renaming an asset or changing its license
+// @Summary Update asset
+// @Description Rename an asset or replace its license. An intro or outro whose license expires is no longer stitched onto new renditions.
+// @Tags assets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Asset ID"
+// @Param request body models.UpdateAssetRequest true "Changes"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/assets/{id} [put]
+func (h *AssetHandler) UpdateAsset(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.UpdateAssetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	asset, err := h.assetService.Update(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if !h.handleAssetError(c, err, "update asset") {
+		return
+	}
+	h.respondWithSuccess(c, "Asset updated successfully", asset)
+}
+
+// DeleteAsset handles removing an asset
+// @Summary Delete asset
+// @Description Remove an asset from the library. The tenant's intro and outro cannot be removed; renditions already planned with an asset keep it.
+// @Tags assets
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Asset ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/assets/{id} [delete]
+func (h *AssetHandler) DeleteAsset(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	if !h.handleAssetError(c, h.assetService.Delete(c.Request.Context(), tenantID, c.Param("id")), "delete asset") {
+		return
+	}
+	h.respondWithSuccess(c, "Asset deleted successfully", gin.H{"id": c.Param("id")})
+}
+
+// GetAssetDefaults handles getting the tenant's intro and outro
+// @Summary Get asset defaults
+// @Description Get the intro and outro stitched onto the tenant's renditions and the profiles they apply to, every profile when empty
+// @Tags assets
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/assets/defaults [get]
+func (h *AssetHandler) GetAssetDefaults(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	defaults, err := h.assetService.Defaults(c.Request.Context(), tenantID)
+	if !h.handleAssetError(c, err, "get asset defaults") {
+		return
+	}
+	h.respondWithSuccess(c, "Asset defaults retrieved successfully", defaults)
+}
+
+// PutAssetDefaults handles choosing the tenant's intro and outro
+// @Summary Set asset defaults
+// @Description Choose the uploaded intro and outro clips stitched onto the tenant's renditions, leaving either empty for none, and the rendition profiles they apply to. Renditions planned from now on use them; the clips are scaled to the profile's frame.
+// @Tags assets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.AssetDefaultsRequest true "Asset defaults"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/assets/defaults [put]
+func (h *AssetHandler) PutAssetDefaults(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.AssetDefaultsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	defaults, err := h.assetService.PutDefaults(c.Request.Context(), tenantID, userID, &req)
+	if !h.handleAssetError(c, err, "save asset defaults") {
+		return
+	}
+	h.respondWithSuccess(c, "Asset defaults saved successfully", defaults)
+}
+
+// handleAssetError responds to a failed asset operation, reporting whether
+// there was none
+func (h *AssetHandler) handleAssetError(c *gin.Context, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrAssetNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Asset not found")
+	case errors.Is(err, models.ErrConflict):
+		h.respondWithErr(c, http.StatusConflict, err)
+	default:
+		h.logger.Error("Failed to "+action, "error", err, "tenant_id", c.GetString("tenant_id"), "asset_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to "+action)
+	}
+	return false
+}
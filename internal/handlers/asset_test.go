@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAssetService has the intro "asset-1", the tenant's default
+type stubAssetService struct {
+	services.AssetService
+}
+
+func (s *stubAssetService) Create(ctx context.Context, tenantID, userID string, req *models.CreateAssetRequest) (*services.AssetUpload, error) {
+	if !req.Kind.Accepts(req.ContentType) {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "content type %s is not accepted for %s assets", req.ContentType, req.Kind)
+	}
+	asset := &models.Asset{ID: "asset-2", Kind: req.Kind, Status: models.AssetUploading, S3UploadID: "secret"}
+	return &services.AssetUpload{Asset: asset, UploadURL: "https://videos-bucket.s3.amazonaws.com/upload"}, nil
+}
+
+func (s *stubAssetService) Delete(ctx context.Context, tenantID, id string) error {
+	if id != "asset-1" {
+		return models.ErrAssetNotFound
+	}
+	return i18n.Errorf(models.ErrConflict, "the asset is the tenant's intro or outro; choose another one first")
+}
+
+func (s *stubAssetService) PutDefaults(ctx context.Context, tenantID, userID string, req *models.AssetDefaultsRequest) (*models.AssetDefaults, error) {
+	if req.IntroAssetID != "asset-1" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "asset %s not found", req.IntroAssetID)
+	}
+	return &models.AssetDefaults{TenantID: tenantID, IntroAssetID: req.IntroAssetID, Profiles: req.Profiles}, nil
+}
+
+func TestAssetHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewAssetHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubAssetService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/assets", handler.CreateAsset)
+	r.PUT("/assets/defaults", handler.PutAssetDefaults)
+	r.DELETE("/assets/:id", handler.DeleteAsset)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	license := `"license":{"license_type":"royalty_free","source":"Stock Library"}`
+	w := send("POST", "/assets", `{"kind":"intro","name":"Intro","filename":"intro.mp4","content_type":"video/mp4","size":10,`+license+`}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"upload_url":"https://videos-bucket.s3.amazonaws.com/upload"`)
+	assert.NotContains(t, w.Body.String(), "secret", "the S3 upload ID stays private")
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/assets", `{"kind":"logo","name":"Logo","filename":"logo.mp4","content_type":"video/mp4","size":10,`+license+`}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/assets", `{"kind":"intro","name":"Intro","filename":"intro.mp4","content_type":"video/mp4","size":10}`).Code, "the license is required")
+
+	w = send("PUT", "/assets/defaults", `{"intro_asset_id":"asset-1","profiles":["vertical_1080p"]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"profiles":["vertical_1080p"]`)
+	assert.Equal(t, http.StatusBadRequest, send("PUT", "/assets/defaults", `{"intro_asset_id":"asset-9"}`).Code)
+
+	assert.Equal(t, http.StatusConflict, send("DELETE", "/assets/asset-1", "").Code)
+	assert.Equal(t, http.StatusNotFound, send("DELETE", "/assets/asset-9", "").Code)
+}
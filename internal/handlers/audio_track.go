@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// AudioTrackHandler handles the dubbed audio tracks of videos
+type AudioTrackHandler struct {
+	*BaseHandler
+	dubbingService services.DubbingService
+}
+
+// NewAudioTrackHandler creates a new audio track handler
+func NewAudioTrackHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, dubbingService services.DubbingService) *AudioTrackHandler {
+	return &AudioTrackHandler{
+		BaseHandler:    NewBaseHandler(cfg, logger, db),
+		dubbingService: dubbingService,
+	}
+}
+
+// ListAudioTracks handles listing the audio tracks of a video
+// @Summary List audio tracks
+// @Description List the video's dubbed audio tracks with their status and storage
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/audio-tracks [get]
+func (h *AudioTrackHandler) ListAudioTracks(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	tracks, err := h.dubbingService.List(c.Request.Context(), viewer, c.Param("id"))
+	if !h.handleAudioTrackError(c, err, "list audio tracks") {
+		return
+	}
+	h.respondWithSuccess(c, "Audio tracks retrieved successfully", tracks)
+}
+
+// DubVideo handles dubbing a video in a language
+// @Summary Dub video
+// @Description Plan an audio track in the language, synthesized by the worker from the video's transcript in it. Without an uploaded transcript in the language, the original one is translated by the AI, keeping its timing. The track is muxed into the renditions of the platforms offering a choice of audio tracks.
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param request body models.DubRequest true "Dub"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/audio-tracks [post]
+func (h *AudioTrackHandler) DubVideo(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	var req models.DubRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	track, err := h.dubbingService.Dub(c.Request.Context(), viewer, c.Param("id"), &req)
+	if !h.handleAudioTrackError(c, err, "dub video") {
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Message: "Dubbing started successfully",
+		Data:    track,
+	})
+}
+
+// DeleteAudioTrack handles removing an audio track from a video
+// @Summary Delete audio track
+// @Description Remove a dubbed audio track; the renditions carrying it are muxed again without it. Its translated transcript is kept.
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param language path string true "Language"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/audio-tracks/{language} [delete]
+func (h *AudioTrackHandler) DeleteAudioTrack(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	err := h.dubbingService.Delete(c.Request.Context(), viewer, c.Param("id"), c.Param("language"))
+	if !h.handleAudioTrackError(c, err, "delete audio track") {
+		return
+	}
+	h.respondWithSuccess(c, "Audio track deleted successfully", nil)
+}
+
+// handleAudioTrackError responds to a failed audio track operation,
+// reporting whether there was none
+func (h *AudioTrackHandler) handleAudioTrackError(c *gin.Context, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	case errors.Is(err, models.ErrAudioTrackNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Audio track not found")
+	case errors.Is(err, models.ErrConflict):
+		h.respondWithErr(c, http.StatusConflict, err)
+	default:
+		h.logger.Error("Failed to "+action, "error", err, "tenant_id", c.GetString("tenant_id"), "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to "+action)
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubDubbingService knows "video-1", transcribed in English, and "video-2",
+// without a transcript
+type stubDubbingService struct {
+	services.DubbingService
+}
+
+func (s *stubDubbingService) Dub(ctx context.Context, viewer *models.User, videoID string, req *models.DubRequest) (*models.AudioTrack, error) {
+	switch {
+	case videoID == "video-2":
+		return nil, i18n.Errorf(models.ErrConflict, "the video needs a transcript to be dubbed")
+	case videoID != "video-1":
+		return nil, models.ErrVideoNotFound
+	case req.Language == "en":
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the video is already in %s", req.Language)
+	}
+	return &models.AudioTrack{VideoID: videoID, Language: req.Language, SourceLanguage: "en", Status: string(models.RenditionPending), CreatedBy: viewer.ID}, nil
+}
+
+func (s *stubDubbingService) Delete(ctx context.Context, viewer *models.User, videoID, language string) error {
+	if language != "fr" {
+		return models.ErrAudioTrackNotFound
+	}
+	return nil
+}
+
+func TestAudioTrackHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewAudioTrackHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubDubbingService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/videos/:id/audio-tracks", handler.DubVideo)
+	r.DELETE("/videos/:id/audio-tracks/:language", handler.DeleteAudioTrack)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/videos/video-1/audio-tracks", `{"language":"fr","voice":"Lea"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"created_by":"test-user-123"`)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/videos/video-1/audio-tracks", `{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/videos/video-1/audio-tracks", `{"language":"en"}`).Code)
+	assert.Equal(t, http.StatusConflict, do("POST", "/videos/video-2/audio-tracks", `{"language":"fr"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/videos/video-3/audio-tracks", `{"language":"fr"}`).Code)
+
+	assert.Equal(t, http.StatusOK, do("DELETE", "/videos/video-1/audio-tracks/fr", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/videos/video-1/audio-tracks/de", "").Code)
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -9,19 +11,23 @@ import (
 	"github.com/jibe0123/mysteryfactory/internal/config"
 	"github.com/jibe0123/mysteryfactory/internal/middleware"
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
 	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
 )
 
 // AuthHandler handles authentication-related requests
 type AuthHandler struct {
 	*BaseHandler
+	loginService services.LoginService
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(cfg *config.Config, logger *logger.Logger, db *db.DB) *AuthHandler {
+func NewAuthHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, loginService services.LoginService) *AuthHandler {
 	return &AuthHandler{
-		BaseHandler: NewBaseHandler(cfg, logger, db),
+		BaseHandler:  NewBaseHandler(cfg, logger, db),
+		loginService: loginService,
 	}
 }
 
@@ -38,16 +44,26 @@ type LoginResponse struct {
 	User      interface{} `json:"user"`
 }
 
+// LoginChallengeResponse asks the user to confirm a suspicious login with
+// the code emailed to them
+type LoginChallengeResponse struct {
+	Message              string                 `json:"message"`
+	VerificationRequired bool                   `json:"verification_required"`
+	Challenge            *models.LoginChallenge `json:"challenge"`
+}
+
 // Login handles user login
 // @Summary User login
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return JWT token. A login from a new device in a new country, or after repeated failures, returns 202 with a challenge to confirm with the code emailed to the user.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "Login credentials"
 // @Success 200 {object} LoginResponse
+// @Success 202 {object} LoginChallengeResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
@@ -56,40 +72,135 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// TODO: Implement actual authentication logic
-	// For now, return a placeholder response
-	expiresAt := time.Now().Add(time.Hour * 24)
+	result, err := h.loginService.Login(c.Request.Context(), req.Email, req.Password, h.loginClient(c))
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidCredentials):
+		h.respondWithError(c, http.StatusUnauthorized, "Invalid email or password")
+		return
+	case errors.Is(err, models.ErrUserInactive):
+		h.respondWithError(c, http.StatusForbidden, "User is inactive")
+		return
+	default:
+		h.logger.Error("Failed to sign in", "error", err)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to sign in")
+		return
+	}
+
+	if result.Challenge != nil {
+		c.JSON(http.StatusAccepted, LoginChallengeResponse{
+			Message:              i18n.T(c.GetString("locale"), "Confirm the sign-in with the code emailed to you"),
+			VerificationRequired: true,
+			Challenge:            result.Challenge,
+		})
+		return
+	}
+	h.respondWithToken(c, result.User)
+}
+
+// VerifyLogin handles the confirmation of a challenged login
+// @Summary Confirm a login
+// @Description Confirm a challenged login with the code emailed to the user, from the device that signed in, and return JWT token. A code can be tried 5 times within 15 minutes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.VerifyLoginRequest true "Challenge and code"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/auth/login/verify [post]
+func (h *AuthHandler) VerifyLogin(c *gin.Context) {
+	var req models.VerifyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	user, err := h.loginService.Verify(c.Request.Context(), &req, h.loginClient(c))
+	switch {
+	case err == nil:
+		h.respondWithToken(c, user)
+	case errors.Is(err, models.ErrLoginChallengeInvalid):
+		h.respondWithError(c, http.StatusUnauthorized, "Invalid or expired verification code")
+	case errors.Is(err, models.ErrUserInactive):
+		h.respondWithError(c, http.StatusForbidden, "User is inactive")
+	default:
+		h.logger.Error("Failed to verify sign-in", "error", err, "challenge_id", req.ChallengeID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to verify sign-in")
+	}
+}
+
+// ListLogins handles listing the user's login history
+// @Summary List login history
+// @Description List the current user's login attempts, newest first, with the IP, country and device they came from
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/auth/me/logins [get]
+func (h *AuthHandler) ListLogins(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+	attempts, err := h.loginService.History(c.Request.Context(), tenantID, userID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list login history", "error", err, "tenant_id", tenantID, "user_id", userID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list login history")
+		return
+	}
+	h.respondWithSuccess(c, "Login history retrieved successfully", attempts)
+}
 
-	// Create JWT token
+// respondWithToken signs the user in
+func (h *AuthHandler) respondWithToken(c *gin.Context, user *models.User) {
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(h.config.JWTExpiration) * time.Second)
 	claims := &middleware.JWTClaims{
-		UserID:   "user-123",
-		TenantID: "tenant-123",
-		Email:    req.Email,
-		Role:     "user",
+		UserID:   user.ID,
+		TenantID: user.TenantID,
+		Email:    user.Email,
+		Role:     user.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(h.config.JWTSecret))
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(h.config.JWTSecret))
 	if err != nil {
 		h.respondWithError(c, http.StatusInternalServerError, "Failed to generate token")
 		return
 	}
 
 	c.JSON(http.StatusOK, LoginResponse{
-		Token:     tokenString,
+		Token:     token,
 		ExpiresAt: expiresAt,
-		User: gin.H{
-			"id":    "user-123",
-			"email": req.Email,
-			"role":  "user",
-		},
+		User:      user,
 	})
 }
 
+// loginClient describes where the login comes from. The country is the ISO
+// code the CDN or proxy in front of the API puts in LoginCountryHeader;
+// unknown (XX) and Tor (T1) countries are left empty.
+func (h *AuthHandler) loginClient(c *gin.Context) models.LoginClient {
+	country := strings.ToUpper(strings.TrimSpace(c.GetHeader(h.config.LoginCountryHeader)))
+	if len(country) != 2 || country == "XX" || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+		country = ""
+	}
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	return models.LoginClient{IP: c.ClientIP(), Country: country, UserAgent: userAgent}
+}
+
 // Register handles user registration
 // @Summary User registration
 // @Description Register a new user
@@ -2,27 +2,66 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/middleware"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
 	"github.com/jibe0123/mysteryfactory/pkg/db"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// stubLoginService accepts password123 for any email, and challenges logins
+// from Brazil until confirmed with 123456
+type stubLoginService struct {
+	services.LoginService
+}
+
+func (s *stubLoginService) Login(ctx context.Context, email, password string, client models.LoginClient) (*services.LoginResult, error) {
+	if password != "password123" {
+		return nil, models.ErrInvalidCredentials
+	}
+	if client.Country == "BR" {
+		return &services.LoginResult{Challenge: &models.LoginChallenge{
+			ID:        "challenge-1",
+			Reason:    "sign-in from a new device in a new country",
+			ExpiresAt: time.Now().Add(15 * time.Minute),
+		}}, nil
+	}
+	return &services.LoginResult{User: &models.User{ID: "user-123", TenantID: "tenant-123", Email: email, Role: "user"}}, nil
+}
+
+func (s *stubLoginService) Verify(ctx context.Context, req *models.VerifyLoginRequest, client models.LoginClient) (*models.User, error) {
+	if req.ChallengeID != "challenge-1" || req.Code != "123456" {
+		return nil, models.ErrLoginChallengeInvalid
+	}
+	return &models.User{ID: "user-123", TenantID: "tenant-123", Email: "test@example.com", Role: "user"}, nil
+}
+
+func (s *stubLoginService) History(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.LoginAttempt, error) {
+	return []*models.LoginAttempt{{ID: "attempt-1", UserID: userID, Outcome: string(models.LoginSucceeded), Country: "FR", NewDevice: true}}, nil
+}
+
 func setupTestRouter() (*gin.Engine, *AuthHandler) {
 	gin.SetMode(gin.TestMode)
 
 	// Mock config
 	cfg := &config.Config{
-		JWTSecret:   "test-secret-key",
-		Environment: "test",
-		LogLevel:    "info",
+		JWTSecret:          "test-secret-key",
+		JWTExpiration:      3600,
+		Environment:        "test",
+		LogLevel:           "info",
+		LoginCountryHeader: "CF-IPCountry",
 	}
 
 	// Mock logger
@@ -32,7 +71,7 @@ func setupTestRouter() (*gin.Engine, *AuthHandler) {
 	var mockDB *db.DB
 
 	// Create handler
-	authHandler := NewAuthHandler(cfg, logger, mockDB)
+	authHandler := NewAuthHandler(cfg, logger, mockDB, &stubLoginService{})
 
 	// Setup router
 	r := gin.New()
@@ -126,6 +165,56 @@ func TestAuthHandler_Login(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_LoginChallenge(t *testing.T) {
+	r, authHandler := setupTestRouter()
+	r.Use(middleware.Locale())
+	r.POST("/login", authHandler.Login)
+	r.POST("/login/verify", authHandler.VerifyLogin)
+
+	serve := func(path, body string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header = header
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/login", `{"email":"test@example.com","password":"wrong"}`, http.Header{})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serve("/login", `{"email":"test@example.com","password":"password123"}`,
+		http.Header{"Cf-Ipcountry": {"br"}, "Accept-Language": {"fr"}})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"verification_required":true`)
+	assert.Contains(t, w.Body.String(), `"id":"challenge-1"`)
+	assert.NotContains(t, w.Body.String(), `"token"`)
+	assert.NotContains(t, w.Body.String(), `"reason"`, "why the login is challenged is not told")
+
+	assert.Equal(t, http.StatusOK, serve("/login", `{"email":"test@example.com","password":"password123"}`,
+		http.Header{"Cf-Ipcountry": {"XX"}}).Code, "unknown countries are not compared")
+
+	w = serve("/login/verify", `{"challenge_id":"challenge-1","code":"000000"}`, http.Header{})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, http.StatusBadRequest, serve("/login/verify", `{"challenge_id":"challenge-1"}`, http.Header{}).Code)
+
+	w = serve("/login/verify", `{"challenge_id":"challenge-1","code":"123456"}`, http.Header{})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"token"`)
+}
+
+func TestAuthHandler_ListLogins(t *testing.T) {
+	r, authHandler := setupTestRouter()
+	addAuthMiddleware(r)
+	r.GET("/me/logins", authHandler.ListLogins)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/me/logins", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"outcome":"succeeded"`)
+	assert.Contains(t, w.Body.String(), `"new_device":true`)
+}
+
 func TestAuthHandler_Register(t *testing.T) {
 	r, authHandler := setupTestRouter()
 	r.POST("/register", authHandler.Register)
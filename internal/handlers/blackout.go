@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// BlackoutHandler handles the windows the tenant publishes nothing in
+type BlackoutHandler struct {
+	*BaseHandler
+	blackoutService services.BlackoutService
+}
+
+// NewBlackoutHandler creates a new blackout handler
+func NewBlackoutHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, blackoutService services.BlackoutService) *BlackoutHandler {
+	return &BlackoutHandler{
+		BaseHandler:     NewBaseHandler(cfg, logger, db),
+		blackoutService: blackoutService,
+	}
+}
+
+// ListBlackoutWindows handles listing the tenant's blackout windows
+// @Summary List blackout windows
+// @Description List the tenant's blackout windows, earliest first
+// @Tags publications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/publications/blackout-windows [get]
+func (h *BlackoutHandler) ListBlackoutWindows(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	windows, err := h.blackoutService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to list blackout windows", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list blackout windows")
+		return
+	}
+	h.respondWithSuccess(c, "Blackout windows retrieved successfully", windows)
+}
+
+// CreateBlackoutWindow handles adding a blackout window
+// @Summary Create blackout window
+// @Description Publish nothing from starts_at until ends_at, every year on the same dates when yearly. Publications due in the window are shifted to its end, or held for approval when action is hold.
+// @Tags publications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BlackoutWindowRequest true "Blackout window"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/publications/blackout-windows [post]
+func (h *BlackoutHandler) CreateBlackoutWindow(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.BlackoutWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	window, err := h.blackoutService.Create(c.Request.Context(), tenantID, userID, &req)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidInput) {
+			h.respondWithErr(c, http.StatusBadRequest, err)
+			return
+		}
+		h.logger.Error("Failed to create blackout window", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to create blackout window")
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Message: "Blackout window created successfully",
+		Data:    window,
+	})
+}
+
+// DeleteBlackoutWindow handles deleting a blackout window
+// @Summary Delete blackout window
+// @Description Delete one of the tenant's blackout windows. Publications it shifted or held are left as they are.
+// @Tags publications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Blackout window ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/publications/blackout-windows/{id} [delete]
+func (h *BlackoutHandler) DeleteBlackoutWindow(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	err = h.blackoutService.Delete(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, models.ErrBlackoutWindowNotFound) {
+			h.respondWithError(c, http.StatusNotFound, "Blackout window not found")
+			return
+		}
+		h.logger.Error("Failed to delete blackout window", "error", err, "tenant_id", tenantID, "blackout_window_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to delete blackout window")
+		return
+	}
+	h.respondWithSuccess(c, "Blackout window deleted successfully", nil)
+}
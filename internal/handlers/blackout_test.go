@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubBlackoutService knows the window "window-1" and validates windows
+// like the service
+type stubBlackoutService struct {
+	services.BlackoutService
+}
+
+func (s *stubBlackoutService) Create(ctx context.Context, tenantID, userID string, req *models.BlackoutWindowRequest) (*models.BlackoutWindow, error) {
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "ends_at must be after starts_at")
+	}
+	return &models.BlackoutWindow{ID: "window-1", TenantID: tenantID, Name: req.Name, StartsAt: req.StartsAt, EndsAt: req.EndsAt,
+		Action: string(models.BlackoutShift), CreatedBy: userID}, nil
+}
+
+func (s *stubBlackoutService) Delete(ctx context.Context, tenantID, id string) error {
+	if id != "window-1" {
+		return models.ErrBlackoutWindowNotFound
+	}
+	return nil
+}
+
+func TestBlackoutHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewBlackoutHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubBlackoutService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/blackout-windows", handler.CreateBlackoutWindow)
+	r.DELETE("/blackout-windows/:id", handler.DeleteBlackoutWindow)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/blackout-windows", `{"name":"Christmas","starts_at":"2026-12-25T00:00:00Z","ends_at":"2026-12-26T00:00:00Z","yearly":true}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"created_by":"test-user-123"`)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/blackout-windows", `{"name":"Christmas"}`).Code)
+	assert.Equal(t, http.StatusBadRequest,
+		do("POST", "/blackout-windows", `{"name":"x","starts_at":"2026-12-26T00:00:00Z","ends_at":"2026-12-25T00:00:00Z"}`).Code)
+
+	assert.Equal(t, http.StatusOK, do("DELETE", "/blackout-windows/window-1", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/blackout-windows/window-2", "").Code)
+}
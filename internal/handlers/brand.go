@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/notify"
+)
+
+// BrandHandler handles the brand profile of tenants
+type BrandHandler struct {
+	*BaseHandler
+	brandService        services.BrandService
+	notificationService services.NotificationService
+}
+
+// NewBrandHandler creates a new brand handler
+func NewBrandHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, brandService services.BrandService, notificationService services.NotificationService) *BrandHandler {
+	return &BrandHandler{
+		BaseHandler:         NewBaseHandler(cfg, logger, db),
+		brandService:        brandService,
+		notificationService: notificationService,
+	}
+}
+
+// GetBrandProfile handles retrieving the tenant's brand profile
+// @Summary Get brand profile
+// @Description Get the font and colors of the tenant's thumbnails and the logo and footer of its emails, the defaults when none were set
+// @Tags brand
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/brand-profile [get]
+func (h *BrandHandler) GetBrandProfile(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	profile, err := h.brandService.Get(c.Request.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to get brand profile", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get brand profile")
+		return
+	}
+	h.respondWithSuccess(c, "Brand profile retrieved successfully", profile)
+}
+
+// UpdateBrandProfile handles setting the tenant's brand profile
+// @Summary Update brand profile
+// @Description Set the font family, installed on the processing worker, and the #RRGGBB text and accent colors of the thumbnails composed from now on, and the https logo URL and footer of the notification emails, which share the colors (admin only)
+// @Tags brand
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BrandProfileRequest true "Brand profile"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/brand-profile [put]
+func (h *BrandHandler) UpdateBrandProfile(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.BrandProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	profile, err := h.brandService.Put(c.Request.Context(), tenantID, userID, &req)
+	if errors.Is(err, models.ErrInvalidInput) {
+		h.respondWithErr(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to update brand profile", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to update brand profile")
+		return
+	}
+	h.respondWithSuccess(c, "Brand profile updated successfully", profile)
+}
+
+// PreviewEmail handles rendering a sample notification email
+// @Summary Preview notification email
+// @Description Render sample notifications with the tenant's brand profile, in the current user's language and timezone, to check how emails look. The html is a complete document, to show in an iframe.
+// @Tags brand
+// @Produce json
+// @Security BearerAuth
+// @Param template query string false "alert (default) or digest"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/brand-profile/email-preview [get]
+func (h *BrandHandler) PreviewEmail(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	email, err := h.notificationService.PreviewEmail(c.Request.Context(), tenantID, userID, c.DefaultQuery("template", notify.TemplateAlert))
+	if errors.Is(err, models.ErrInvalidInput) {
+		h.respondWithErr(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to preview email", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to preview email")
+		return
+	}
+	h.respondWithSuccess(c, "Email preview rendered successfully", email)
+}
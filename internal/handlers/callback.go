@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/callback"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// CallbackHandler handles the signed callbacks of the processing workers
+type CallbackHandler struct {
+	*BaseHandler
+	videoService services.VideoService
+}
+
+// NewCallbackHandler creates a new callback handler
+func NewCallbackHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, videoService services.VideoService) *CallbackHandler {
+	return &CallbackHandler{
+		BaseHandler:  NewBaseHandler(cfg, logger, db),
+		videoService: videoService,
+	}
+}
+
+// ProcessingComplete handles a worker reporting a processed video
+// @Summary Report a processed video
+// @Description Mark a video ready with the metadata of its processed file. Called by the processing workers, signed with the callback secret in X-Callback-Timestamp and X-Callback-Signature instead of a user token.
+// @Tags callbacks
+// @Accept json
+// @Produce json
+// @Param tenant_id path string true "Tenant ID"
+// @Param id path string true "Video ID"
+// @Param request body callback.ProcessingResult true "Processing result"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /internal/callbacks/tenants/{tenant_id}/videos/{id}/processing/complete [post]
+func (h *CallbackHandler) ProcessingComplete(c *gin.Context) {
+	tenantID, videoID := c.Param("tenant_id"), c.Param("id")
+
+	var req callback.ProcessingResult
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	err := h.videoService.SetProcessingComplete(c.Request.Context(), tenantID, videoID, req.Duration, req.Resolution, req.ThumbnailURL, req.S3Key, req.S3Bucket)
+	switch {
+	case err == nil:
+		h.respondWithSuccess(c, "Processing result recorded", nil)
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	default:
+		h.logger.Error("Failed to record processing result", "error", err, "tenant_id", tenantID, "video_id", videoID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to record processing result")
+	}
+}
+
+// ProcessingProgress handles a worker reporting how far a video's processing is
+// @Summary Report processing progress
+// @Description Record the percent complete of a video's processing, shown by its transcode job. Called by the processing workers, signed like the completion callback.
+// @Tags callbacks
+// @Accept json
+// @Produce json
+// @Param tenant_id path string true "Tenant ID"
+// @Param id path string true "Video ID"
+// @Param request body callback.ProcessingProgress true "Progress"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /internal/callbacks/tenants/{tenant_id}/videos/{id}/processing/progress [post]
+func (h *CallbackHandler) ProcessingProgress(c *gin.Context) {
+	tenantID, videoID := c.Param("tenant_id"), c.Param("id")
+
+	var req callback.ProcessingProgress
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	err := h.videoService.SetProcessingProgress(c.Request.Context(), tenantID, videoID, req.Percent)
+	switch {
+	case err == nil:
+		h.respondWithSuccess(c, "Processing result recorded", nil)
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	default:
+		h.logger.Error("Failed to record processing result", "error", err, "tenant_id", tenantID, "video_id", videoID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to record processing result")
+	}
+}
+
+// ProcessingFailed handles a worker reporting a video it could not process
+// @Summary Report a failed processing
+// @Description Mark a video failed. Called by the processing workers, signed like the completion callback.
+// @Tags callbacks
+// @Accept json
+// @Produce json
+// @Param tenant_id path string true "Tenant ID"
+// @Param id path string true "Video ID"
+// @Param request body callback.ProcessingFailure true "Failure"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /internal/callbacks/tenants/{tenant_id}/videos/{id}/processing/failed [post]
+func (h *CallbackHandler) ProcessingFailed(c *gin.Context) {
+	tenantID, videoID := c.Param("tenant_id"), c.Param("id")
+
+	var req callback.ProcessingFailure
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	err := h.videoService.SetProcessingFailed(c.Request.Context(), tenantID, videoID, req.Reason)
+	switch {
+	case err == nil:
+		h.respondWithSuccess(c, "Processing result recorded", nil)
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	default:
+		h.logger.Error("Failed to record processing result", "error", err, "tenant_id", tenantID, "video_id", videoID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to record processing result")
+	}
+}
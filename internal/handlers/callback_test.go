@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/middleware"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/callback"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubProcessingVideoService knows the video "v-1" of acme and records its status
+type stubProcessingVideoService struct {
+	services.VideoService
+	status  string
+	s3Key   string
+	percent int
+	reason  string
+}
+
+func (s *stubProcessingVideoService) SetProcessingProgress(ctx context.Context, tenantID, videoID string, percent int) error {
+	if tenantID != "acme" || videoID != "v-1" {
+		return models.ErrVideoNotFound
+	}
+	s.percent = percent
+	return nil
+}
+
+func (s *stubProcessingVideoService) SetProcessingComplete(ctx context.Context, tenantID, videoID string, duration int, resolution, thumbnailURL, s3Key, s3Bucket string) error {
+	if tenantID != "acme" || videoID != "v-1" {
+		return models.ErrVideoNotFound
+	}
+	s.status, s.s3Key = string(models.StatusReady), s3Key
+	return nil
+}
+
+func (s *stubProcessingVideoService) SetProcessingFailed(ctx context.Context, tenantID, videoID, reason string) error {
+	if tenantID != "acme" || videoID != "v-1" {
+		return models.ErrVideoNotFound
+	}
+	s.status, s.reason = string(models.StatusFailed), reason
+	return nil
+}
+
+func TestCallbackHandler_ProcessingCallbacks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	svc := &stubProcessingVideoService{}
+	handler := NewCallbackHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, svc)
+	r := gin.New()
+	callbacks := r.Group("/internal/callbacks", middleware.CallbackAuth("s3cret"))
+	callbacks.POST("/tenants/:tenant_id/videos/:id/processing/progress", handler.ProcessingProgress)
+	callbacks.POST("/tenants/:tenant_id/videos/:id/processing/complete", handler.ProcessingComplete)
+	callbacks.POST("/tenants/:tenant_id/videos/:id/processing/failed", handler.ProcessingFailed)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	ctx := context.Background()
+
+	worker := callback.NewClient(server.URL, "s3cret")
+	require.NoError(t, worker.ProcessingProgress(ctx, "acme", "v-1", 40))
+	assert.Equal(t, 40, svc.percent)
+	assert.ErrorContains(t, worker.ProcessingProgress(ctx, "acme", "v-1", 140), "answered 400")
+
+	require.NoError(t, worker.ProcessingComplete(ctx, "acme", "v-1", callback.ProcessingResult{Duration: 42, S3Key: "processed/v-1.mp4", S3Bucket: "videos"}))
+	assert.Equal(t, string(models.StatusReady), svc.status)
+	assert.Equal(t, "processed/v-1.mp4", svc.s3Key)
+
+	require.NoError(t, worker.ProcessingFailed(ctx, "acme", "v-1", "corrupt upload"))
+	assert.Equal(t, string(models.StatusFailed), svc.status)
+	assert.Equal(t, "corrupt upload", svc.reason)
+
+	err := worker.ProcessingComplete(ctx, "acme", "v-1", callback.ProcessingResult{Duration: 42})
+	assert.ErrorContains(t, err, "answered 400", "the processed file is required")
+	assert.ErrorContains(t, worker.ProcessingFailed(ctx, "globex", "v-1", ""), "answered 404")
+
+	svc.status = ""
+	impostor := callback.NewClient(server.URL, "guessed")
+	assert.ErrorContains(t, impostor.ProcessingFailed(ctx, "acme", "v-1", ""), "answered 401")
+	assert.Empty(t, svc.status, "unsigned callbacks do not reach the service")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", callback.ProcessingFailedPath("acme", "v-1"), strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer user-token")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "user tokens are not accepted either")
+}
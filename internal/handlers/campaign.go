@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// CampaignHandler handles campaigns
+type CampaignHandler struct {
+	*BaseHandler
+	campaignService services.CampaignService
+}
+
+// NewCampaignHandler creates a new campaign handler
+func NewCampaignHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, campaignService services.CampaignService) *CampaignHandler {
+	return &CampaignHandler{
+		BaseHandler:     NewBaseHandler(cfg, logger, db),
+		campaignService: campaignService,
+	}
+}
+
+// GetCampaignHistory handles replaying a campaign's events
+// @Summary Campaign history
+// @Description List a campaign's state changes oldest first (created, updated, scheduled, started, step_completed, budget_exceeded, paused, resumed, stopped, run_recorded, deleted) with the campaign rebuilt by replaying them. Pass until to replay only the events up to that sequence and see the campaign as it was then. Deleted campaigns keep their history.
+// @Tags campaigns
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Param until query int false "Last event sequence to replay"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/campaigns/{id}/history [get]
+func (h *CampaignHandler) GetCampaignHistory(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var until int64
+	if raw := c.Query("until"); raw != "" {
+		if until, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "Invalid until")
+			return
+		}
+	}
+
+	history, err := h.campaignService.GetCampaignHistory(c.Request.Context(), tenantID, c.Param("id"), until)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, models.ErrCampaignNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Campaign not found")
+		return
+	default:
+		h.logger.Error("Failed to get campaign history", "error", err, "tenant_id", tenantID, "campaign_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get campaign history")
+		return
+	}
+
+	h.respondWithSuccess(c, "Campaign history retrieved successfully", history)
+}
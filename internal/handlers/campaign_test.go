@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubCampaignService knows the history of "campaign-1" and records the
+// sequence it is replayed to
+type stubCampaignService struct {
+	services.CampaignService
+	until int64
+}
+
+func (s *stubCampaignService) GetCampaignHistory(ctx context.Context, tenantID, campaignID string, until int64) (*services.CampaignHistory, error) {
+	s.until = until
+	switch {
+	case until < 0:
+		return nil, i18n.Errorf(models.ErrInvalidInput, "until must not be negative")
+	case campaignID != "campaign-1":
+		return nil, models.ErrCampaignNotFound
+	}
+	return &services.CampaignHistory{
+		Campaign: &models.Campaign{ID: campaignID, TenantID: tenantID, Status: models.CampaignStatusRunning, Version: 2},
+		Events: []*models.CampaignEvent{
+			{CampaignID: campaignID, Sequence: 1, Type: models.CampaignEventCreated},
+			{CampaignID: campaignID, Sequence: 2, Type: models.CampaignEventStarted},
+		},
+	}, nil
+}
+
+func TestCampaignHandler_GetCampaignHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	service := &stubCampaignService{}
+	handler := NewCampaignHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, service)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/campaigns/:id/history", handler.GetCampaignHistory)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/campaigns/campaign-1/history?until=2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(2), service.until)
+	assert.Contains(t, w.Body.String(), `"type":"started"`)
+	assert.Contains(t, w.Body.String(), `"version":2`)
+
+	assert.Equal(t, http.StatusOK, get("/campaigns/campaign-1/history").Code)
+	assert.Equal(t, int64(0), service.until, "every event is replayed by default")
+	assert.Equal(t, http.StatusBadRequest, get("/campaigns/campaign-1/history?until=last").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/campaigns/campaign-1/history?until=-1").Code)
+	assert.Equal(t, http.StatusNotFound, get("/campaigns/campaign-2/history").Code)
+}
@@ -5,8 +5,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
 	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/slo"
+	"github.com/jibe0123/mysteryfactory/pkg/supervisor"
 )
 
 // BaseHandler contains common dependencies for all handlers
@@ -53,15 +58,70 @@ func HealthCheck(db *db.DB) gin.HandlerFunc {
 	}
 }
 
+// BedrockHealth handler for the Bedrock regional failover status
+// @Summary Bedrock region health
+// @Description Report which Bedrock region serves each residency. The status is degraded while a residency has failed over to its secondary region; it is computed from recent calls and does not call Bedrock.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /health/bedrock [get]
+func BedrockHealth(client aws.BedrockClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		regions := []aws.FailoverStatus{}
+		if reporter, ok := client.(aws.FailoverReporter); ok {
+			regions = append(regions, reporter.FailoverStatus()...)
+		}
+
+		status := "healthy"
+		for _, region := range regions {
+			if region.FailedOver {
+				status = "degraded"
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status":  status,
+			"regions": regions,
+		})
+	}
+}
+
+// SLOReport handler for the latency objectives
+// @Summary Latency objective compliance
+// @Description Report, for every endpoint with a latency objective, the share of its requests answered within the threshold and its Apdex over the last SLO_WINDOW_MINUTES on this replica. The status is breached while an objective is not met.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /internal/slo [get]
+func SLOReport(tracker *slo.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		objectives := tracker.Report()
+
+		status := "met"
+		for _, objective := range objectives {
+			if !objective.Met {
+				status = "breached"
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status":         status,
+			"window_minutes": int(tracker.Window().Minutes()),
+			"objectives":     objectives,
+		})
+	}
+}
+
 // ReadinessCheck handler for readiness check endpoint
 // @Summary Readiness check
-// @Description Check if the service is ready to serve requests
+// @Description Check if the service is ready to serve requests: the database answers and no critical background loop is down. loops is the state of each supervised background loop.
 // @Tags health
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
 // @Router /ready [get]
-func ReadinessCheck(db *db.DB) gin.HandlerFunc {
+func ReadinessCheck(db *db.DB, loops *supervisor.Supervisor) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Check database readiness
 		if err := db.Health(); err != nil {
@@ -73,9 +133,21 @@ func ReadinessCheck(db *db.DB) gin.HandlerFunc {
 			return
 		}
 
+		// Check critical background loops
+		if err := loops.Ready(); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":  "not ready",
+				"message": "Background loops not ready",
+				"error":   err.Error(),
+				"loops":   loops.Statuses(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"status":  "ready",
 			"message": "Service is ready to serve requests",
+			"loops":   loops.Statuses(),
 		})
 	}
 }
@@ -102,11 +174,22 @@ type PaginatedResponse struct {
 	TotalPages int         `json:"total_pages"`
 }
 
-// respondWithError sends an error response
+// respondWithError sends an error response, its message translated to the
+// request's language
 func (h *BaseHandler) respondWithError(c *gin.Context, code int, message string) {
 	c.JSON(code, ErrorResponse{
 		Error:   http.StatusText(code),
-		Message: message,
+		Message: i18n.T(c.GetString("locale"), message),
+		Code:    code,
+	})
+}
+
+// respondWithErr sends an error response whose message is err's, such as an
+// invalid input explaining the rule it broke
+func (h *BaseHandler) respondWithErr(c *gin.Context, code int, err error) {
+	c.JSON(code, ErrorResponse{
+		Error:   http.StatusText(code),
+		Message: i18n.ErrorMessage(c.GetString("locale"), err),
 		Code:    code,
 	})
 }
@@ -114,7 +197,7 @@ func (h *BaseHandler) respondWithError(c *gin.Context, code int, message string)
 // respondWithSuccess sends a success response
 func (h *BaseHandler) respondWithSuccess(c *gin.Context, message string, data interface{}) {
 	c.JSON(http.StatusOK, SuccessResponse{
-		Message: message,
+		Message: i18n.T(c.GetString("locale"), message),
 		Data:    data,
 	})
 }
@@ -147,6 +230,18 @@ func (h *BaseHandler) getUserFromContext(c *gin.Context) (string, string, error)
 	return userID.(string), tenantID.(string), nil
 }
 
+// viewer returns the current user, whose visibility decides which videos
+// the request reads and changes
+func (h *BaseHandler) viewer(c *gin.Context) (*models.User, bool) {
+	user, exists := c.Get("user")
+	viewer, ok := user.(*models.User)
+	if !exists || !ok {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return nil, false
+	}
+	return viewer, true
+}
+
 // getPaginationParams extracts pagination parameters from context
 func (h *BaseHandler) getPaginationParams(c *gin.Context) (int, int) {
 	limit, exists := c.Get("limit")
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// DebugCaptureHandler handles the debug capture requests of admins
+type DebugCaptureHandler struct {
+	*BaseHandler
+	captureService services.DebugCaptureService
+}
+
+// NewDebugCaptureHandler creates a new debug capture handler
+func NewDebugCaptureHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, captureService services.DebugCaptureService) *DebugCaptureHandler {
+	return &DebugCaptureHandler{
+		BaseHandler:    NewBaseHandler(cfg, logger, db),
+		captureService: captureService,
+	}
+}
+
+// EnableDebugCapture handles starting a debug capture
+// @Summary Enable debug capture
+// @Description Capture the tenant's API requests and responses for a while, to let support debug a report. Credentials and secrets are removed before captures are stored; captures are stored encrypted and deleted after the retention period. Enabling again replaces the running session.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.EnableDebugCaptureRequest true "Reason and duration"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/admin/debug-capture [post]
+func (h *DebugCaptureHandler) EnableDebugCapture(c *gin.Context) {
+	user, exists := c.Get("user")
+	admin, ok := user.(*models.User)
+	if !exists || !ok {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.EnableDebugCaptureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	session, err := h.captureService.Enable(c.Request.Context(), admin, &req)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidInput) {
+			h.respondWithErr(c, http.StatusBadRequest, err)
+			return
+		}
+		h.logger.Error("Failed to enable debug capture", "error", err, "tenant_id", admin.TenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to enable debug capture")
+		return
+	}
+
+	h.respondWithSuccess(c, "Debug capture enabled", session)
+}
+
+// GetDebugCapture handles getting the running debug capture session
+// @Summary Get debug capture status
+// @Description Get the tenant's running debug capture session
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/admin/debug-capture [get]
+func (h *DebugCaptureHandler) GetDebugCapture(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	session, err := h.captureService.Status(c.Request.Context(), tenantID)
+	if err != nil {
+		h.respondWithCaptureError(c, err, tenantID, "Failed to get debug capture")
+		return
+	}
+
+	h.respondWithSuccess(c, "Debug capture retrieved successfully", session)
+}
+
+// DisableDebugCapture handles stopping the debug capture
+// @Summary Disable debug capture
+// @Description Stop capturing the tenant's requests. Captures already taken are kept until they expire.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/admin/debug-capture [delete]
+func (h *DebugCaptureHandler) DisableDebugCapture(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	if err := h.captureService.Disable(c.Request.Context(), tenantID); err != nil {
+		h.logger.Error("Failed to disable debug capture", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to disable debug capture")
+		return
+	}
+
+	h.respondWithSuccess(c, "Debug capture disabled", nil)
+}
+
+// ListDebugCaptures handles listing captured requests
+// @Summary List debug captures
+// @Description List captured requests newest first, without headers and bodies. Admins of the support tenant may read the captures of any tenant with tenant_id.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param tenant_id query string false "Tenant to read (support tenant admins only)"
+// @Param session_id query string false "Only captures of this session"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/debug-captures [get]
+func (h *DebugCaptureHandler) ListDebugCaptures(c *gin.Context) {
+	tenantID, ok := h.captureTenant(c)
+	if !ok {
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+	captures, err := h.captureService.List(c.Request.Context(), tenantID, c.Query("session_id"), limit, offset)
+	if err != nil {
+		h.respondWithCaptureError(c, err, tenantID, "Failed to list debug captures")
+		return
+	}
+
+	h.respondWithSuccess(c, "Debug captures retrieved successfully", captures)
+}
+
+// GetDebugCaptureByID handles reading one captured request
+// @Summary Get debug capture
+// @Description Get a captured request with its sanitized headers and bodies. Admins of the support tenant may read the captures of any tenant with tenant_id.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Capture ID"
+// @Param tenant_id query string false "Tenant to read (support tenant admins only)"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/admin/debug-captures/{id} [get]
+func (h *DebugCaptureHandler) GetDebugCaptureByID(c *gin.Context) {
+	tenantID, ok := h.captureTenant(c)
+	if !ok {
+		return
+	}
+
+	capture, err := h.captureService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		h.respondWithCaptureError(c, err, tenantID, "Failed to get debug capture")
+		return
+	}
+
+	h.respondWithSuccess(c, "Debug capture retrieved successfully", capture)
+}
+
+// captureTenant returns the tenant whose captures are read: the caller's
+// own, or for admins of the support tenant the one named by tenant_id
+func (h *DebugCaptureHandler) captureTenant(c *gin.Context) (string, bool) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return "", false
+	}
+
+	requested := c.Query("tenant_id")
+	if requested == "" || requested == tenantID {
+		return tenantID, true
+	}
+	if h.config.SupportTenantID == "" || tenantID != h.config.SupportTenantID {
+		h.respondWithError(c, http.StatusForbidden, "Only the support tenant can read the captures of another tenant")
+		return "", false
+	}
+	return requested, true
+}
+
+func (h *DebugCaptureHandler) respondWithCaptureError(c *gin.Context, err error, tenantID, message string) {
+	switch {
+	case errors.Is(err, models.ErrDebugCaptureInactive):
+		h.respondWithError(c, http.StatusNotFound, "Debug capture is not enabled")
+	case errors.Is(err, models.ErrDebugCaptureNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Debug capture not found")
+	default:
+		h.logger.Error(message, "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubDebugCaptureService holds one capture, "cap-1" of the tenant "acme"
+type stubDebugCaptureService struct {
+	services.DebugCaptureService
+}
+
+func (s *stubDebugCaptureService) Get(ctx context.Context, tenantID, id string) (*models.DebugCapture, error) {
+	if tenantID != "acme" || id != "cap-1" {
+		return nil, models.ErrDebugCaptureNotFound
+	}
+	return &models.DebugCapture{ID: id, TenantID: tenantID}, nil
+}
+
+func TestDebugCaptureHandler_GetDebugCaptureByID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	cfg := &config.Config{Environment: "test", SupportTenantID: "support"}
+	handler := NewDebugCaptureHandler(cfg, logger.New("error", "test"), mockDB, &stubDebugCaptureService{})
+
+	get := func(tenantID, target string) int {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			c.Set("user_id", "admin-1")
+			c.Set("tenant_id", tenantID)
+			c.Next()
+		})
+		r.GET("/admin/debug-captures/:id", handler.GetDebugCaptureByID)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get("acme", "/admin/debug-captures/cap-1"))
+	assert.Equal(t, http.StatusNotFound, get("other", "/admin/debug-captures/cap-1"), "captures are read in the caller's tenant")
+	assert.Equal(t, http.StatusForbidden, get("other", "/admin/debug-captures/cap-1?tenant_id=acme"), "only the support tenant reads other tenants")
+	assert.Equal(t, http.StatusOK, get("support", "/admin/debug-captures/cap-1?tenant_id=acme"))
+	assert.Equal(t, http.StatusNotFound, get("acme", "/admin/debug-captures/cap-2"))
+}
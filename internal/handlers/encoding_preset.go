@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// EncodingPresetHandler handles the settings the tenant's renditions are
+// transcoded with
+type EncodingPresetHandler struct {
+	*BaseHandler
+	presetService services.EncodingPresetService
+}
+
+// NewEncodingPresetHandler creates a new encoding preset handler
+func NewEncodingPresetHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, presetService services.EncodingPresetService) *EncodingPresetHandler {
+	return &EncodingPresetHandler{
+		BaseHandler:   NewBaseHandler(cfg, logger, db),
+		presetService: presetService,
+	}
+}
+
+// ListEncodingPresets handles listing the tenant's encoding presets
+// @Summary List encoding presets
+// @Description List the settings of every rendition profile, the tenant's presets or the built-in ones (default: true)
+// @Tags encoding
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/encoding-presets [get]
+func (h *EncodingPresetHandler) ListEncodingPresets(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	presets, err := h.presetService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to list encoding presets", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list encoding presets")
+		return
+	}
+	h.respondWithSuccess(c, "Encoding presets retrieved successfully", presets)
+}
+
+// GetEncodingPreset handles getting the settings of a rendition profile
+// @Summary Get encoding preset
+// @Description Get the settings the tenant's renditions of a profile are transcoded with
+// @Tags encoding
+// @Produce json
+// @Security BearerAuth
+// @Param profile path string true "Rendition profile" Enums(landscape_1080p, landscape_720p, vertical_1080p)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/encoding-presets/{profile} [get]
+func (h *EncodingPresetHandler) GetEncodingPreset(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	preset, err := h.presetService.Get(c.Request.Context(), tenantID, c.Param("profile"))
+	if !h.handlePresetError(c, err, "get") {
+		return
+	}
+	h.respondWithSuccess(c, "Encoding preset retrieved successfully", preset)
+}
+
+// PutEncodingPreset handles tuning a rendition profile
+// @Summary Set encoding preset
+// @Description Replace the settings the tenant's renditions of a profile are transcoded with. The frame keeps the profile's aspect ratio, and the codecs, size, bitrates and frame rate must be accepted by every platform taking the profile. loudness_lufs normalizes the audio, e.g. -14 as YouTube and Spotify play it back. Videos planned from now on use the preset.
+// @Tags encoding
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile path string true "Rendition profile" Enums(landscape_1080p, landscape_720p, vertical_1080p)
+// @Param request body models.EncodingPresetRequest true "Encoding preset"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/encoding-presets/{profile} [put]
+func (h *EncodingPresetHandler) PutEncodingPreset(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.EncodingPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	preset, err := h.presetService.Put(c.Request.Context(), tenantID, userID, c.Param("profile"), &req)
+	if !h.handlePresetError(c, err, "save") {
+		return
+	}
+	h.respondWithSuccess(c, "Encoding preset saved successfully", preset)
+}
+
+// DeleteEncodingPreset handles returning a rendition profile to its
+// built-in settings
+// @Summary Delete encoding preset
+// @Description Transcode the tenant's renditions of a profile with its built-in settings again
+// @Tags encoding
+// @Produce json
+// @Security BearerAuth
+// @Param profile path string true "Rendition profile"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/encoding-presets/{profile} [delete]
+func (h *EncodingPresetHandler) DeleteEncodingPreset(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	err = h.presetService.Delete(c.Request.Context(), tenantID, c.Param("profile"))
+	if !h.handlePresetError(c, err, "delete") {
+		return
+	}
+	h.respondWithSuccess(c, "Encoding preset deleted successfully", nil)
+}
+
+// handlePresetError responds to a failed preset operation, reporting whether
+// there was none
+func (h *EncodingPresetHandler) handlePresetError(c *gin.Context, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrEncodingPresetNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Encoding preset not found")
+	default:
+		h.logger.Error("Failed to "+action+" encoding preset", "error", err, "tenant_id", c.GetString("tenant_id"), "profile", c.Param("profile"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to "+action+" encoding preset")
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubPresetService knows the vertical_1080p profile only, tuned by the
+// tenant, and takes no frame rate over 60
+type stubPresetService struct {
+	services.EncodingPresetService
+}
+
+func (s *stubPresetService) Put(ctx context.Context, tenantID, userID, profile string, req *models.EncodingPresetRequest) (*models.EncodingPreset, error) {
+	switch {
+	case profile != "vertical_1080p":
+		return nil, i18n.Errorf(models.ErrInvalidInput, "unknown rendition profile %q", profile)
+	case req.FrameRate > 60:
+		return nil, i18n.Errorf(models.ErrInvalidInput, "%s accepts frame rates from %d to %d fps", "tiktok", 23, 60)
+	}
+	return &models.EncodingPreset{TenantID: tenantID, Profile: profile, Width: req.Width, Height: req.Height, FrameRate: req.FrameRate, UpdatedBy: userID}, nil
+}
+
+func (s *stubPresetService) Delete(ctx context.Context, tenantID, profile string) error {
+	if profile != "vertical_1080p" {
+		return models.ErrEncodingPresetNotFound
+	}
+	return nil
+}
+
+func TestEncodingPresetHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewEncodingPresetHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubPresetService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.PUT("/encoding-presets/:profile", handler.PutEncodingPreset)
+	r.DELETE("/encoding-presets/:profile", handler.DeleteEncodingPreset)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	preset := `{"width":1080,"height":1920,"video_bitrate_kbps":10000,"audio_bitrate_kbps":128,"frame_rate":%s}`
+	w := do("PUT", "/encoding-presets/vertical_1080p", strings.Replace(preset, "%s", "30", 1))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"updated_by":"test-user-123"`)
+	w = do("PUT", "/encoding-presets/vertical_1080p", strings.Replace(preset, "%s", "120", 1))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "tiktok accepts frame rates from 23 to 60 fps")
+	assert.Equal(t, http.StatusBadRequest, do("PUT", "/encoding-presets/square_1080p", strings.Replace(preset, "%s", "30", 1)).Code)
+	assert.Equal(t, http.StatusBadRequest, do("PUT", "/encoding-presets/vertical_1080p", `{"width":1080}`).Code)
+
+	assert.Equal(t, http.StatusOK, do("DELETE", "/encoding-presets/vertical_1080p", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/encoding-presets/landscape_720p", "").Code)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// FrameHandler handles the frames extracted from videos
+type FrameHandler struct {
+	*BaseHandler
+	frameService services.FrameService
+}
+
+// NewFrameHandler creates a new frame handler
+func NewFrameHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, frameService services.FrameService) *FrameHandler {
+	return &FrameHandler{
+		BaseHandler:  NewBaseHandler(cfg, logger, db),
+		frameService: frameService,
+	}
+}
+
+// GetFrames handles retrieving frames of a video to pick a thumbnail from
+// @Summary Get video frames
+// @Description Get the frames extracted while processing the video nearest each of the comma-separated timestamps, in seconds and in the order given. Without timestamps, the first frame of each scene is returned, or frames spread over the video when it has no scene changes. Pass a frame's seconds as frame_seconds to compose thumbnails from it.
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param timestamps query string false "Timestamps in seconds, such as 1.5,10,42"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/frames [get]
+func (h *FrameHandler) GetFrames(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	var timestamps []float64
+	if raw := c.Query("timestamps"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			at, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				h.respondWithError(c, http.StatusBadRequest, "timestamps must be numbers of seconds")
+				return
+			}
+			timestamps = append(timestamps, at)
+		}
+	}
+
+	frames, err := h.frameService.Frames(c.Request.Context(), viewer, c.Param("id"), timestamps)
+	switch {
+	case err == nil:
+		h.respondWithSuccess(c, "Frames retrieved successfully", frames)
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	case errors.Is(err, models.ErrFramesNotExtracted):
+		h.respondWithError(c, http.StatusConflict, "Video frames have not been extracted yet")
+	default:
+		h.logger.Error("Failed to get frames", "error", err, "tenant_id", viewer.TenantID, "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get frames")
+	}
+}
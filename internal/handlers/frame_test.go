@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubFrameService has extracted the frames of "video-1" only
+type stubFrameService struct {
+	services.FrameService
+	timestamps []float64
+}
+
+func (s *stubFrameService) Frames(ctx context.Context, viewer *models.User, videoID string, timestamps []float64) ([]models.Frame, error) {
+	s.timestamps = timestamps
+	switch videoID {
+	case "video-1":
+		return []models.Frame{{Seconds: 12, URL: "https://cdn/frames/video-1/frame-0005.jpg"}}, nil
+	case "video-2":
+		return nil, models.ErrFramesNotExtracted
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+func TestFrameHandler_GetFrames(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	svc := &stubFrameService{}
+	handler := NewFrameHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, svc)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/videos/:id/frames", handler.GetFrames)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/videos/video-1/frames?timestamps=1.5,%2010,42")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"url":"https://cdn/frames/video-1/frame-0005.jpg"`)
+	assert.Equal(t, []float64{1.5, 10, 42}, svc.timestamps)
+
+	assert.Equal(t, http.StatusOK, get("/videos/video-1/frames").Code)
+	assert.Nil(t, svc.timestamps)
+
+	assert.Equal(t, http.StatusBadRequest, get("/videos/video-1/frames?timestamps=1,soon").Code)
+	assert.Equal(t, http.StatusConflict, get("/videos/video-2/frames").Code)
+	assert.Equal(t, http.StatusNotFound, get("/videos/video-3/frames").Code)
+}
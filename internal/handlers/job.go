@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// JobHandler handles the status of asynchronous operations
+type JobHandler struct {
+	*BaseHandler
+	jobService services.JobService
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, jobService services.JobService) *JobHandler {
+	return &JobHandler{
+		BaseHandler: NewBaseHandler(cfg, logger, db),
+		jobService:  jobService,
+	}
+}
+
+// ListJobs handles listing the tenant's jobs
+// @Summary List jobs
+// @Description List the tenant's asynchronous operations newest first: video transcodes, rendition transcodes, stats imports and syncs, publications and archive restores
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Job type (transcode, rendition, stats_import, stats_sync, publish, restore)"
+// @Param state query string false "Job state (queued, running, succeeded, failed)"
+// @Param resource_id query string false "ID of the video, backfill, publication or tenant the job works on"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	filter := models.JobFilter{
+		Type:       models.JobType(c.Query("type")),
+		State:      models.JobState(c.Query("state")),
+		ResourceID: c.Query("resource_id"),
+	}
+	limit, offset := h.getPaginationParams(c)
+	jobs, err := h.jobService.List(c.Request.Context(), tenantID, filter, limit, offset)
+	switch {
+	case err == nil:
+		for _, job := range jobs {
+			job.Localize(c.GetString("locale"))
+		}
+		h.respondWithSuccess(c, "Jobs retrieved successfully", jobs)
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	default:
+		h.logger.Error("Failed to list jobs", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list jobs")
+	}
+}
+
+// GetJob handles getting a job
+// @Summary Get job
+// @Description Get the state of an asynchronous operation: queued, running, succeeded or failed, its percent complete, the error it failed with and the links to what it works on and produces
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/jobs/{id} [get]
+func (h *JobHandler) GetJob(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	job, err := h.jobService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	switch {
+	case err == nil:
+		job.Localize(c.GetString("locale"))
+		h.respondWithSuccess(c, "Job retrieved successfully", job)
+	case errors.Is(err, models.ErrJobNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Job not found")
+	default:
+		h.logger.Error("Failed to get job", "error", err, "tenant_id", tenantID, "job_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get job")
+	}
+}
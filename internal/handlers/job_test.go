@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubJobService knows the running transcode "job-1" and the failed
+// publication "job-pub" of the tenant "acme"
+type stubJobService struct {
+	services.JobService
+}
+
+func (s *stubJobService) Get(ctx context.Context, tenantID, id string) (*models.Job, error) {
+	if tenantID == "acme" && id == "job-pub" {
+		return &models.Job{ID: id, TenantID: tenantID, Type: string(models.JobPublish), ResourceID: "pub-1",
+			State: string(models.JobFailed), Error: "The platform no longer accepts the workspace's authorization"}, nil
+	}
+	if tenantID != "acme" || id != "job-1" {
+		return nil, models.ErrJobNotFound
+	}
+	return &models.Job{ID: "job-1", TenantID: tenantID, Type: string(models.JobTranscode), ResourceID: "video-1",
+		State: string(models.JobRunning), Percent: 40, Links: models.TranscodeJob("video-1").Links}, nil
+}
+
+func (s *stubJobService) List(ctx context.Context, tenantID string, filter models.JobFilter, limit, offset int) ([]*models.Job, error) {
+	if filter.State != "" && filter.State != models.JobRunning {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "state must be one of %v", []models.JobState{models.JobRunning})
+	}
+	job, _ := s.Get(ctx, tenantID, "job-1")
+	return []*models.Job{job}, nil
+}
+
+func TestJobHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewJobHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubJobService{})
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("tenant_id", "acme")
+		c.Next()
+	})
+	r.GET("/jobs", handler.ListJobs)
+	r.GET("/jobs/:id", handler.GetJob)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/jobs/job-1")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data models.Job `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "running", resp.Data.State)
+	assert.Equal(t, 40, resp.Data.Percent)
+	assert.Equal(t, "/api/v1/videos/video-1", resp.Data.Links["video"])
+
+	assert.Equal(t, http.StatusNotFound, get("/jobs/job-2").Code)
+	assert.Equal(t, http.StatusOK, get("/jobs?state=running").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/jobs?state=done").Code)
+}
+
+func TestJobHandler_LocalizesError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewJobHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubJobService{})
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("tenant_id", "acme")
+		c.Set("locale", "fr")
+		c.Next()
+	})
+	r.GET("/jobs/:id", handler.GetJob)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/jobs/job-pub", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data models.Job `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "La plateforme n'accepte plus l'autorisation de l'espace de travail", resp.Data.Error)
+}
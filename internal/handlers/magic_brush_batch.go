@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// MagicBrushBatchHandler handles magic brushes applied to a selection of videos
+type MagicBrushBatchHandler struct {
+	*BaseHandler
+	batchService services.MagicBrushBatchService
+}
+
+// NewMagicBrushBatchHandler creates a new bulk magic brush handler
+func NewMagicBrushBatchHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, batchService services.MagicBrushBatchService) *MagicBrushBatchHandler {
+	return &MagicBrushBatchHandler{
+		BaseHandler:  NewBaseHandler(cfg, logger, db),
+		batchService: batchService,
+	}
+}
+
+// StartMagicBrushBatch handles queueing a bulk magic brush
+// @Summary Bulk magic brush
+// @Description Apply a magic brush to up to 100 selected videos, or the videos matching one of the user's smart lists, in the background. Each video is tracked by a magic_brush job; the batch reports the result of each video and the AI tokens and cost summed over them.
+// @Tags ai
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BulkMagicBrushRequest true "Videos and brush"
+// @Success 202 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/ai/magic-brush/bulk [post]
+func (h *MagicBrushBatchHandler) StartMagicBrushBatch(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.BulkMagicBrushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	batch, err := h.batchService.Start(c.Request.Context(), tenantID, userID, &req)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidInput) {
+			h.respondWithErr(c, http.StatusBadRequest, err)
+			return
+		}
+		if errors.Is(err, models.ErrSmartListNotFound) {
+			h.respondWithError(c, http.StatusNotFound, "Smart list not found")
+			return
+		}
+		h.logger.Error("Failed to start magic brush batch", "error", err, "user_id", userID, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to start magic brush batch")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Message: "Magic brush batch queued",
+		Data:    batch,
+	})
+}
+
+// GetMagicBrushBatch handles getting a bulk magic brush
+// @Summary Get bulk magic brush
+// @Description Get the progress of a bulk magic brush: the generated content or error of each video, and the AI usage summed over them
+// @Tags ai
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Batch ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/ai/magic-brush/bulk/{id} [get]
+func (h *MagicBrushBatchHandler) GetMagicBrushBatch(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	batch, err := h.batchService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, models.ErrMagicBrushBatchNotFound) {
+			h.respondWithError(c, http.StatusNotFound, "Magic brush batch not found")
+			return
+		}
+		h.logger.Error("Failed to get magic brush batch", "error", err, "tenant_id", tenantID, "batch_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get magic brush batch")
+		return
+	}
+
+	h.respondWithSuccess(c, "Magic brush batch retrieved successfully", batch)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubMagicBrushBatchService brushes titles only and knows the batch "batch-1"
+// and the smart list "list-1"
+type stubMagicBrushBatchService struct {
+	services.MagicBrushBatchService
+}
+
+func (s *stubMagicBrushBatchService) Start(ctx context.Context, tenantID, userID string, req *models.BulkMagicBrushRequest) (*models.MagicBrushBatch, error) {
+	if req.BrushType != "title" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "unknown brush type %q", req.BrushType)
+	}
+	if req.SmartListID != "" && req.SmartListID != "list-1" {
+		return nil, models.ErrSmartListNotFound
+	}
+	if len(req.VideoIDs) == 0 && req.SmartListID == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "select between 1 and %d videos", models.MaxMagicBrushBatchVideos)
+	}
+	return &models.MagicBrushBatch{ID: "batch-1", TenantID: tenantID, BrushType: req.BrushType, Status: models.MagicBrushBatchQueued, VideosTotal: len(req.VideoIDs)}, nil
+}
+
+func (s *stubMagicBrushBatchService) Get(ctx context.Context, tenantID, id string) (*models.MagicBrushBatch, error) {
+	if id != "batch-1" {
+		return nil, models.ErrMagicBrushBatchNotFound
+	}
+	return &models.MagicBrushBatch{ID: id, TenantID: tenantID}, nil
+}
+
+func TestMagicBrushBatchHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewMagicBrushBatchHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubMagicBrushBatchService{})
+
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/ai/magic-brush/bulk", handler.StartMagicBrushBatch)
+	r.GET("/ai/magic-brush/bulk/:id", handler.GetMagicBrushBatch)
+
+	serve := func(method, path, body string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusAccepted, serve("POST", "/ai/magic-brush/bulk", `{"video_ids":["v1","v2"],"brush_type":"title"}`))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/ai/magic-brush/bulk", `{"brush_type":"title"}`))
+	assert.Equal(t, http.StatusAccepted, serve("POST", "/ai/magic-brush/bulk", `{"smart_list_id":"list-1","brush_type":"title"}`))
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/ai/magic-brush/bulk", `{"smart_list_id":"list-2","brush_type":"title"}`))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/ai/magic-brush/bulk", `{"video_ids":["v1"],"brush_type":"poem"}`))
+	assert.Equal(t, http.StatusOK, serve("GET", "/ai/magic-brush/bulk/batch-1", ""))
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/ai/magic-brush/bulk/batch-2", ""))
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// MediaInfoHandler handles the inspection of uploaded video files
+type MediaInfoHandler struct {
+	*BaseHandler
+	mediaInfoService services.MediaInfoService
+}
+
+// NewMediaInfoHandler creates a new media info handler
+func NewMediaInfoHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, mediaInfoService services.MediaInfoService) *MediaInfoHandler {
+	return &MediaInfoHandler{
+		BaseHandler:      NewBaseHandler(cfg, logger, db),
+		mediaInfoService: mediaInfoService,
+	}
+}
+
+// GetMediaInfo handles retrieving what ffprobe reported of a video's file
+// @Summary Get media info
+// @Description Get the container, codecs, bitrates, frame rate, audio channels and color space ffprobe reported of the uploaded file when it was processed, with warnings explaining why platforms may reject it or degrade it (variable frame rate, HDR, surround audio...)
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/media-info [get]
+func (h *MediaInfoHandler) GetMediaInfo(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.mediaInfoService.Get(c.Request.Context(), viewer, c.Param("id"))
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+		return
+	case errors.Is(err, models.ErrMediaNotProbed):
+		h.respondWithError(c, http.StatusConflict, "Video has not been processed yet")
+		return
+	default:
+		h.logger.Error("Failed to get media info", "error", err, "tenant_id", viewer.TenantID, "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get media info")
+		return
+	}
+
+	h.respondWithSuccess(c, "Media info retrieved successfully", report)
+}
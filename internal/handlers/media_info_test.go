@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubMediaInfoService has probed a single video
+type stubMediaInfoService struct {
+	services.MediaInfoService
+}
+
+func (s *stubMediaInfoService) Get(ctx context.Context, viewer *models.User, videoID string) (*services.MediaInfoReport, error) {
+	switch videoID {
+	case "probed":
+		media := &transcode.MediaInfo{Video: &transcode.VideoStream{Codec: "h264", FrameRate: 60, AverageFrameRate: 41.78}}
+		return &services.MediaInfoReport{VideoID: videoID, Media: media, Warnings: media.Warnings()}, nil
+	case "uploading":
+		return nil, models.ErrMediaNotProbed
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+func TestMediaInfoHandler_GetMediaInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewMediaInfoHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubMediaInfoService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/videos/:id/media-info", handler.GetMediaInfo)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/videos/probed/media-info")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"codec":"h264"`)
+	assert.Contains(t, w.Body.String(), `"code":"variable_frame_rate"`)
+
+	assert.Equal(t, http.StatusConflict, get("/videos/uploading/media-info").Code)
+	assert.Equal(t, http.StatusNotFound, get("/videos/missing/media-info").Code)
+}
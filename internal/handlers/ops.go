@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+const (
+	// defaultOpsLimit and maxOpsLimit bound the tenants listed by ops reports
+	defaultOpsLimit = 50
+	maxOpsLimit     = 500
+)
+
+// OpsHandler serves the cross-tenant operational views of support staff
+type OpsHandler struct {
+	*BaseHandler
+	opsService services.OpsService
+}
+
+// NewOpsHandler creates a new ops handler
+func NewOpsHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, opsService services.OpsService) *OpsHandler {
+	return &OpsHandler{
+		BaseHandler: NewBaseHandler(cfg, logger, db),
+		opsService:  opsService,
+	}
+}
+
+// FailedPublications handles counting failed publications by platform
+// @Summary Failed publications by platform
+// @Description Count the publication jobs of every tenant that failed in the last hours, by platform. Support tenant admins only.
+// @Tags ops
+// @Produce json
+// @Security BearerAuth
+// @Param hours query int false "Window in hours (default 24)"
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/ops/failed-publications [get]
+func (h *OpsHandler) FailedPublications(c *gin.Context) {
+	hours := queryPositiveInt(c, "hours", 24, 24*90)
+	counts, err := h.opsService.FailedPublications(c.Request.Context(), time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil {
+		h.logger.Error("Failed to count failed publications", "error", err)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to count failed publications")
+		return
+	}
+
+	h.respondWithSuccess(c, "Failed publications retrieved successfully", gin.H{
+		"hours":     hours,
+		"platforms": counts,
+	})
+}
+
+// DeadLetters handles reporting the publication dead letters
+// @Summary Publication dead letters
+// @Description Count the failed publication jobs of every tenant that have no retries left, by platform. Support tenant admins only.
+// @Tags ops
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/ops/dlq [get]
+func (h *OpsHandler) DeadLetters(c *gin.Context) {
+	report, err := h.opsService.DeadLetters(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to count dead letters", "error", err)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to count dead letters")
+		return
+	}
+
+	h.respondWithSuccess(c, "Dead letters retrieved successfully", report)
+}
+
+// AISpend handles summing AI spend by tenant
+// @Summary AI spend by tenant
+// @Description Sum the tokens and estimated USD cost of Bedrock requests by tenant over the last days, highest cost first. Support tenant admins only.
+// @Tags ops
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Window in days (default 30)"
+// @Param limit query int false "Number of tenants (default 50, at most 500)"
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/ops/ai-spend [get]
+func (h *OpsHandler) AISpend(c *gin.Context) {
+	days := queryPositiveInt(c, "days", 30, 366)
+	limit := queryPositiveInt(c, "limit", defaultOpsLimit, maxOpsLimit)
+	spend, err := h.opsService.AISpend(c.Request.Context(), time.Now().AddDate(0, 0, -days), limit)
+	if err != nil {
+		h.logger.Error("Failed to sum AI spend", "error", err)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to sum AI spend")
+		return
+	}
+
+	h.respondWithSuccess(c, "AI spend retrieved successfully", gin.H{
+		"days":    days,
+		"tenants": spend,
+	})
+}
+
+// WebhookStats handles reporting webhook error rates
+// @Summary Webhook error rates
+// @Description Webhook events received, quarantined because their payload was invalid, rejected because the queue was full, and failed in processing, by platform. Counters belong to the server instance answering and start with it. Support tenant admins only.
+// @Tags ops
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/ops/webhooks [get]
+func (h *OpsHandler) WebhookStats(c *gin.Context) {
+	h.respondWithSuccess(c, "Webhook stats retrieved successfully", h.opsService.WebhookStats(c.Request.Context()))
+}
+
+// ListQuarantinedWebhooks handles listing the webhooks that failed validation
+// @Summary List quarantined webhooks
+// @Description List webhooks rejected because their payload did not match their platform's, newest first, with the failing fields but without their bodies. Support tenant admins only.
+// @Tags ops
+// @Produce json
+// @Security BearerAuth
+// @Param platform query string false "Only webhooks of this platform"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/ops/webhooks/quarantine [get]
+func (h *OpsHandler) ListQuarantinedWebhooks(c *gin.Context) {
+	limit, offset := h.getPaginationParams(c)
+	webhooks, err := h.opsService.QuarantinedWebhooks(c.Request.Context(), c.Query("platform"), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list quarantined webhooks", "error", err)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list quarantined webhooks")
+		return
+	}
+
+	h.respondWithSuccess(c, "Quarantined webhooks retrieved successfully", webhooks)
+}
+
+// GetQuarantinedWebhook handles reading one quarantined webhook
+// @Summary Get quarantined webhook
+// @Description Get a webhook rejected by validation with its body as received, to inspect it by hand. Support tenant admins only.
+// @Tags ops
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Quarantined webhook ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/admin/ops/webhooks/quarantine/{id} [get]
+func (h *OpsHandler) GetQuarantinedWebhook(c *gin.Context) {
+	webhook, err := h.opsService.QuarantinedWebhook(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, models.ErrQuarantinedWebhookNotFound) {
+			h.respondWithError(c, http.StatusNotFound, "Quarantined webhook not found")
+			return
+		}
+		h.logger.Error("Failed to get quarantined webhook", "error", err, "id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get quarantined webhook")
+		return
+	}
+
+	h.respondWithSuccess(c, "Quarantined webhook retrieved successfully", webhook)
+}
+
+// StatsSync handles reporting stats sync staleness
+// @Summary Stats sync staleness
+// @Description When each tenant's platform stats were last synced, least recently synced first. Tenants not synced for two sync intervals are stale. Support tenant admins only.
+// @Tags ops
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of tenants (default 50, at most 500)"
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/ops/stats-sync [get]
+func (h *OpsHandler) StatsSync(c *gin.Context) {
+	report, err := h.opsService.StatsSync(c.Request.Context(), queryPositiveInt(c, "limit", defaultOpsLimit, maxOpsLimit))
+	if err != nil {
+		h.logger.Error("Failed to read stats sync status", "error", err)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to read stats sync status")
+		return
+	}
+
+	h.respondWithSuccess(c, "Stats sync status retrieved successfully", report)
+}
+
+// queryPositiveInt reads a positive integer query parameter capped at max,
+// falling back to def when it is missing or invalid
+func queryPositiveInt(c *gin.Context, name string, def, max int) int {
+	value, err := strconv.Atoi(c.Query(name))
+	if err != nil || value <= 0 {
+		return def
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
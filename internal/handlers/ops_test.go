@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubOpsService records the window and limit it was asked for
+type stubOpsService struct {
+	services.OpsService
+	since time.Time
+	limit int
+}
+
+func (s *stubOpsService) AISpend(ctx context.Context, since time.Time, limit int) ([]*models.TenantAISpend, error) {
+	s.since, s.limit = since, limit
+	return []*models.TenantAISpend{{TenantID: "acme", Requests: 12, Cost: 1.25}}, nil
+}
+
+func TestOpsHandler_AISpend(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	ops := &stubOpsService{}
+	handler := NewOpsHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, ops)
+
+	r := gin.New()
+	r.GET("/admin/ops/ai-spend", handler.AISpend)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/ops/ai-spend"+query, nil))
+		return w
+	}
+
+	w := get("?days=7&limit=10")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, -7), ops.since, time.Second)
+	assert.Equal(t, 10, ops.limit)
+
+	var resp struct {
+		Data struct {
+			Days    int                     `json:"days"`
+			Tenants []*models.TenantAISpend `json:"tenants"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 7, resp.Data.Days)
+	require.Len(t, resp.Data.Tenants, 1)
+	assert.Equal(t, "acme", resp.Data.Tenants[0].TenantID)
+
+	get("?days=-1&limit=100000")
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, -30), ops.since, time.Second, "invalid windows fall back to the default")
+	assert.Equal(t, maxOpsLimit, ops.limit, "limits are capped")
+}
+
+func (s *stubOpsService) QuarantinedWebhook(ctx context.Context, id string) (*models.QuarantinedWebhook, error) {
+	if id != "quarantined-1" {
+		return nil, models.ErrQuarantinedWebhookNotFound
+	}
+	return &models.QuarantinedWebhook{ID: id, Platform: "tiktok", Reason: models.WebhookMalformed, Body: `{}`}, nil
+}
+
+func TestOpsHandler_GetQuarantinedWebhook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewOpsHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubOpsService{})
+
+	r := gin.New()
+	r.GET("/admin/ops/webhooks/quarantine/:id", handler.GetQuarantinedWebhook)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/ops/webhooks/quarantine/quarantined-1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data models.QuarantinedWebhook `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "tiktok", resp.Data.Platform)
+	assert.Equal(t, `{}`, resp.Data.Body)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/ops/webhooks/quarantine/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
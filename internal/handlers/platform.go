@@ -1,36 +1,63 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
 	"github.com/jibe0123/mysteryfactory/pkg/db"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
 )
 
 // PlatformHandler handles platform-related requests
 type PlatformHandler struct {
 	*BaseHandler
+	webhookService services.WebhookService
+	webhookSecrets partners.WebhookSecrets
+	quotaService   services.QuotaService
+	subscriptions  services.WebhookSubscriptionService // Told of the leases verification challenges grant
+	connections    services.ConnectionService
 }
 
 // NewPlatformHandler creates a new platform handler
-func NewPlatformHandler(cfg *config.Config, logger *logger.Logger, db *db.DB) *PlatformHandler {
+func NewPlatformHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, webhookService services.WebhookService, webhookSecrets partners.WebhookSecrets, quotaService services.QuotaService, subscriptions services.WebhookSubscriptionService, connections services.ConnectionService) *PlatformHandler {
 	return &PlatformHandler{
-		BaseHandler: NewBaseHandler(cfg, logger, db),
+		BaseHandler:    NewBaseHandler(cfg, logger, db),
+		webhookService: webhookService,
+		webhookSecrets: webhookSecrets,
+		quotaService:   quotaService,
+		subscriptions:  subscriptions,
+		connections:    connections,
 	}
 }
 
+// InvalidWebhookResponse represents a webhook whose payload failed validation
+type InvalidWebhookResponse struct {
+	ErrorResponse
+	Reason string                     `json:"reason"` // unparseable or malformed
+	Errors []models.WebhookFieldError `json:"errors"`
+}
+
 // HandleWebhook handles incoming webhooks from platforms
 // @Summary Handle platform webhook
-// @Description Handle incoming webhook from a specific platform
+// @Description Accept an incoming webhook from a specific platform for asynchronous processing. Public webhooks must carry the platform's signature; bodies are capped and each platform (and tenant, when authenticated) is rate limited. Payloads that do not match the platform's are rejected with the failing fields and quarantined for inspection.
 // @Tags platforms
 // @Accept json
 // @Produce json
 // @Param platform path string true "Platform name" Enums(youtube,tiktok,instagram,facebook,twitter,linkedin,snapchat)
-// @Success 200 {object} SuccessResponse
-// @Failure 400 {object} ErrorResponse
+// @Success 202 {object} SuccessResponse
+// @Failure 400 {object} InvalidWebhookResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
 // @Router /api/v1/platforms/webhook/{platform} [post]
 // @Router /webhooks/{platform} [post]
 func (h *PlatformHandler) HandleWebhook(c *gin.Context) {
@@ -44,52 +71,82 @@ func (h *PlatformHandler) HandleWebhook(c *gin.Context) {
 	if webhookPlatform, exists := c.Get("webhook_platform"); exists {
 		platform = webhookPlatform.(string)
 	}
+	if !models.Platform(platform).Valid() {
+		h.respondWithError(c, http.StatusBadRequest, "Unsupported platform")
+		return
+	}
 
-	// Get raw body for signature verification
 	body, err := c.GetRawData()
 	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.respondWithError(c, http.StatusRequestEntityTooLarge, "Request body is too large")
+			return
+		}
 		h.respondWithError(c, http.StatusBadRequest, "Failed to read request body")
 		return
 	}
 
+	event := &services.WebhookEvent{
+		Platform:    platform,
+		TenantID:    c.GetString("tenant_id"),
+		ContentType: c.ContentType(),
+		Body:        body,
+		ReceivedAt:  time.Now(),
+	}
+	if err := h.webhookService.Enqueue(c.Request.Context(), event); err != nil {
+		var payloadErr *partners.PayloadError
+		switch {
+		case errors.Is(err, models.ErrWebhookQueueFull):
+			c.Header("Retry-After", "1")
+			h.respondWithError(c, http.StatusServiceUnavailable, "Webhook queue is full, please retry")
+			return
+		case errors.As(err, &payloadErr):
+			c.JSON(http.StatusBadRequest, InvalidWebhookResponse{
+				ErrorResponse: ErrorResponse{
+					Error:   http.StatusText(http.StatusBadRequest),
+					Message: "Webhook payload is invalid",
+					Code:    http.StatusBadRequest,
+				},
+				Reason: payloadErr.Reason,
+				Errors: payloadErr.Fields,
+			})
+			return
+		}
+		h.logger.Error("Failed to enqueue webhook", "error", err, "platform", platform)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to accept webhook")
+		return
+	}
+
 	h.logger.Info("Received webhook",
 		"platform", platform,
 		"content_type", c.ContentType(),
 		"body_size", len(body))
 
-	// Handle platform-specific webhook logic
-	switch platform {
-	case "youtube":
-		h.handleYouTubeWebhook(c, body)
-	case "tiktok":
-		h.handleTikTokWebhook(c, body)
-	case "instagram":
-		h.handleInstagramWebhook(c, body)
-	case "facebook":
-		h.handleFacebookWebhook(c, body)
-	case "twitter":
-		h.handleTwitterWebhook(c, body)
-	case "linkedin":
-		h.handleLinkedInWebhook(c, body)
-	case "snapchat":
-		h.handleSnapchatWebhook(c, body)
-	default:
-		h.respondWithError(c, http.StatusBadRequest, "Unsupported platform")
-		return
-	}
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Message: "Webhook accepted",
+		Data: gin.H{
+			"platform": platform,
+			"queued":   true,
+		},
+	})
 }
 
 // VerifyWebhook handles webhook verification for platforms
 // @Summary Verify platform webhook
-// @Description Verify webhook endpoint for a specific platform
+// @Description Answer the subscription challenge of a platform: the hub.challenge of YouTube, Facebook and Instagram (checked against the Meta verify token) or the signed CRC token of Twitter
 // @Tags platforms
-// @Accept json
+// @Produce plain
 // @Produce json
 // @Param platform path string true "Platform name"
 // @Param hub.challenge query string false "Challenge parameter for verification"
+// @Param hub.verify_token query string false "Verify token (Facebook, Instagram)"
+// @Param crc_token query string false "CRC token (Twitter)"
 // @Success 200 {string} string "Challenge response"
 // @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
 // @Router /api/v1/platforms/webhook/{platform}/verify [get]
+// @Router /webhooks/{platform} [get]
 func (h *PlatformHandler) VerifyWebhook(c *gin.Context) {
 	platform := c.Param("platform")
 	if platform == "" {
@@ -97,26 +154,33 @@ func (h *PlatformHandler) VerifyWebhook(c *gin.Context) {
 		return
 	}
 
-	// Handle platform-specific verification
-	switch platform {
-	case "youtube":
-		// YouTube uses hub.challenge parameter
-		challenge := c.Query("hub.challenge")
-		if challenge != "" {
-			c.String(http.StatusOK, challenge)
-			return
-		}
-	case "facebook", "instagram":
-		// Facebook/Instagram use hub.challenge parameter
-		challenge := c.Query("hub.challenge")
-		verifyToken := c.Query("hub.verify_token")
-		if challenge != "" && verifyToken == h.config.DefaultTenantID { // Use config value
-			c.String(http.StatusOK, challenge)
-			return
-		}
+	contentType, body, err := partners.WebhookChallenge(platform, h.webhookSecrets, c.Request.URL.Query())
+	if errors.Is(err, partners.ErrWebhookNotConfigured) {
+		h.respondWithError(c, http.StatusNotFound, "Webhooks are not configured for this platform")
+		return
+	}
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid verification request")
+		return
 	}
+	h.confirmLease(c, platform)
 
-	h.respondWithError(c, http.StatusBadRequest, "Invalid verification request")
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// confirmLease records the lease a WebSub hub grants in its subscription
+// challenge. The challenge is answered even when recording fails: the lease
+// is then confirmed again at the next renewal.
+func (h *PlatformHandler) confirmLease(c *gin.Context, platform string) {
+	query := c.Request.URL.Query()
+	seconds, err := strconv.Atoi(query.Get("hub.lease_seconds"))
+	if h.subscriptions == nil || query.Get("hub.mode") != "subscribe" || query.Get("hub.topic") == "" || err != nil || seconds <= 0 {
+		return
+	}
+	lease := time.Duration(seconds) * time.Second
+	if err := h.subscriptions.ConfirmLease(c.Request.Context(), platform, query.Get("hub.topic"), lease); err != nil {
+		h.logger.Error("Failed to confirm webhook lease", "error", err, "platform", platform, "topic", query.Get("hub.topic"))
+	}
 }
 
 // InitiatePlatformAuth handles initiating OAuth flow for platforms
@@ -245,97 +309,88 @@ func (h *PlatformHandler) RevokePlatformAuth(c *gin.Context) {
 	})
 }
 
-// Platform-specific webhook handlers
-
-func (h *PlatformHandler) handleYouTubeWebhook(c *gin.Context, body []byte) {
-	// TODO: Implement YouTube-specific webhook handling
-	h.logger.Info("Processing YouTube webhook", "body_size", len(body))
-
-	// Parse YouTube webhook payload
-	// Handle video status updates, analytics updates, etc.
-
-	h.respondWithSuccess(c, "YouTube webhook processed", gin.H{
-		"platform":  "youtube",
-		"processed": true,
-	})
-}
-
-func (h *PlatformHandler) handleTikTokWebhook(c *gin.Context, body []byte) {
-	// TODO: Implement TikTok-specific webhook handling
-	h.logger.Info("Processing TikTok webhook", "body_size", len(body))
-
-	// Parse TikTok webhook payload
-	// Handle video status updates, analytics updates, etc.
-
-	h.respondWithSuccess(c, "TikTok webhook processed", gin.H{
-		"platform":  "tiktok",
-		"processed": true,
-	})
-}
-
-func (h *PlatformHandler) handleInstagramWebhook(c *gin.Context, body []byte) {
-	// TODO: Implement Instagram-specific webhook handling
-	h.logger.Info("Processing Instagram webhook", "body_size", len(body))
-
-	// Parse Instagram webhook payload
-	// Handle video status updates, analytics updates, etc.
-
-	h.respondWithSuccess(c, "Instagram webhook processed", gin.H{
-		"platform":  "instagram",
-		"processed": true,
-	})
-}
-
-func (h *PlatformHandler) handleFacebookWebhook(c *gin.Context, body []byte) {
-	// TODO: Implement Facebook-specific webhook handling
-	h.logger.Info("Processing Facebook webhook", "body_size", len(body))
-
-	// Parse Facebook webhook payload
-	// Handle video status updates, analytics updates, etc.
-
-	h.respondWithSuccess(c, "Facebook webhook processed", gin.H{
-		"platform":  "facebook",
-		"processed": true,
-	})
-}
-
-func (h *PlatformHandler) handleTwitterWebhook(c *gin.Context, body []byte) {
-	// TODO: Implement Twitter-specific webhook handling
-	h.logger.Info("Processing Twitter webhook", "body_size", len(body))
-
-	// Parse Twitter webhook payload
-	// Handle video status updates, analytics updates, etc.
-
-	h.respondWithSuccess(c, "Twitter webhook processed", gin.H{
-		"platform":  "twitter",
-		"processed": true,
-	})
-}
+// Reconnect handles connecting a workspace to a platform again
+// @Summary Reconnect platform
+// @Description Check that the platform accepts the workspace's credentials again after it rejected its token, then resume the publications held meanwhile. Only the workspace's owner or an admin can reconnect it.
+// @Tags platforms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param platform path string true "Platform name"
+// @Param request body models.ReconnectPlatformRequest true "Workspace to reconnect"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/platforms/{platform}/reconnect [post]
+func (h *PlatformHandler) Reconnect(c *gin.Context) {
+	value, exists := c.Get("user")
+	user, ok := value.(*models.User)
+	if !exists || !ok {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
 
-func (h *PlatformHandler) handleLinkedInWebhook(c *gin.Context, body []byte) {
-	// TODO: Implement LinkedIn-specific webhook handling
-	h.logger.Info("Processing LinkedIn webhook", "body_size", len(body))
+	var req models.ReconnectPlatformRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
 
-	// Parse LinkedIn webhook payload
-	// Handle video status updates, analytics updates, etc.
+	platform := models.Platform(c.Param("platform"))
+	resumed, err := h.connections.Reconnected(c.Request.Context(), user, req.WorkspaceID, platform)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidPlatform):
+			h.respondWithError(c, http.StatusBadRequest, "Unsupported platform")
+		case errors.Is(err, models.ErrNotFound):
+			h.respondWithError(c, http.StatusNotFound, "Workspace not found")
+		case errors.Is(err, models.ErrForbidden):
+			h.respondWithErr(c, http.StatusForbidden, err)
+		case errors.Is(err, models.ErrConflict):
+			h.respondWithErr(c, http.StatusConflict, err)
+		case errors.Is(err, partners.ErrInvalidToken):
+			h.respondWithError(c, http.StatusConflict, "The platform still rejects the workspace's credentials")
+		default:
+			h.logger.Error("Failed to reconnect platform", "error", err, "user_id", user.ID, "workspace_id", req.WorkspaceID, "platform", platform)
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to reconnect platform")
+		}
+		return
+	}
 
-	h.respondWithSuccess(c, "LinkedIn webhook processed", gin.H{
-		"platform":  "linkedin",
-		"processed": true,
+	h.respondWithSuccess(c, "Platform reconnected successfully", gin.H{
+		"platform":     platform,
+		"workspace_id": req.WorkspaceID,
+		"resumed":      resumed,
 	})
 }
 
-func (h *PlatformHandler) handleSnapchatWebhook(c *gin.Context, body []byte) {
-	// TODO: Implement Snapchat-specific webhook handling
-	h.logger.Info("Processing Snapchat webhook", "body_size", len(body))
+// ListConnections handles listing the user's platform connections
+// @Summary List platform connections
+// @Description List every platform with the user's workspaces holding its credentials and, for platforms with a daily API quota (YouTube), the tenant's usage: units used and remaining, when the quota resets, and the operations deferred until then. Stats syncs are deferred first, once usage reaches the share of the quota reserved for publishing. needs_reconnect lists the workspaces whose token the platform rejected.
+// @Tags platforms
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/platforms/connections [get]
+func (h *PlatformHandler) ListConnections(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
 
-	// Parse Snapchat webhook payload
-	// Handle story status updates, analytics updates, etc.
+	connections, err := h.quotaService.Connections(c.Request.Context(), tenantID, userID)
+	if err != nil {
+		h.logger.Error("Failed to list platform connections", "error", err, "user_id", userID, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list platform connections")
+		return
+	}
 
-	h.respondWithSuccess(c, "Snapchat webhook processed", gin.H{
-		"platform":  "snapchat",
-		"processed": true,
-	})
+	h.respondWithSuccess(c, "Platform connections retrieved successfully", connections)
 }
 
 // generateAuthURL generates OAuth URL for platform authentication
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/middleware"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queueWebhookService keeps enqueued events, up to capacity, rejecting
+// bodies that are not JSON as a TikTok payload would be
+type queueWebhookService struct {
+	services.WebhookService
+	capacity int
+	events   []*services.WebhookEvent
+}
+
+func (s *queueWebhookService) Enqueue(ctx context.Context, event *services.WebhookEvent) error {
+	if !json.Valid(event.Body) {
+		return &partners.PayloadError{
+			Platform: event.Platform,
+			Reason:   models.WebhookUnparseable,
+			Fields:   []models.WebhookFieldError{{Field: "$", Message: "body is not a JSON object"}},
+		}
+	}
+	if len(s.events) == s.capacity {
+		return models.ErrWebhookQueueFull
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+// leaseRecorder keeps the leases hub challenges confirm
+type leaseRecorder struct {
+	services.WebhookSubscriptionService
+	leases map[string]time.Duration
+}
+
+func (s *leaseRecorder) ConfirmLease(ctx context.Context, platform, topic string, lease time.Duration) error {
+	s.leases[platform+" "+topic] = lease
+	return nil
+}
+
+func setupPlatformTestRouter(queue *queueWebhookService) (*gin.Engine, *leaseRecorder) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	secrets := partners.WebhookSecrets{MetaVerifyToken: "meta-token", YouTube: "hub-secret"}
+	leases := &leaseRecorder{leases: map[string]time.Duration{}}
+	handler := NewPlatformHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, queue, secrets, nil, leases, nil)
+
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/platforms/webhook/:platform", middleware.BodyLimit(16), handler.HandleWebhook)
+	r.GET("/platforms/webhook/:platform/verify", handler.VerifyWebhook)
+	return r, leases
+}
+
+func TestPlatformHandler_HandleWebhook(t *testing.T) {
+	queue := &queueWebhookService{capacity: 1}
+	r, _ := setupPlatformTestRouter(queue)
+
+	post := func(platform, body string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/platforms/webhook/"+platform, strings.NewReader(body)))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusAccepted, post("tiktok", `{"ok":true}`))
+	require.Len(t, queue.events, 1)
+	assert.Equal(t, "tiktok", queue.events[0].Platform)
+	assert.Equal(t, "test-tenant-123", queue.events[0].TenantID)
+	assert.Equal(t, `{"ok":true}`, string(queue.events[0].Body))
+
+	assert.Equal(t, http.StatusServiceUnavailable, post("tiktok", `{}`), "a full queue asks the platform to retry")
+	assert.Equal(t, http.StatusBadRequest, post("myspace", `{}`))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/platforms/webhook/tiktok", strings.NewReader("not json")))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var invalid InvalidWebhookResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &invalid))
+	assert.Equal(t, models.WebhookUnparseable, invalid.Reason)
+	assert.Equal(t, []models.WebhookFieldError{{Field: "$", Message: "body is not a JSON object"}}, invalid.Errors)
+
+	// Chunked bodies have no Content-Length and hit the cap while being read
+	req := httptest.NewRequest("POST", "/platforms/webhook/tiktok", strings.NewReader(strings.Repeat("x", 17)))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestPlatformHandler_VerifyWebhook(t *testing.T) {
+	r, leases := setupPlatformTestRouter(&queueWebhookService{})
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "meta challenge", path: "/platforms/webhook/facebook/verify?hub.mode=subscribe&hub.challenge=42&hub.verify_token=meta-token", expectedStatus: http.StatusOK, expectedBody: "42"},
+		{name: "wrong verify token", path: "/platforms/webhook/facebook/verify?hub.mode=subscribe&hub.challenge=42&hub.verify_token=guess", expectedStatus: http.StatusBadRequest},
+		{name: "unconfigured platform", path: "/platforms/webhook/twitter/verify?crc_token=abc", expectedStatus: http.StatusNotFound},
+		{name: "hub challenge", path: "/platforms/webhook/youtube/verify?hub.mode=subscribe&hub.challenge=abc&hub.topic=feed-1&hub.lease_seconds=432000", expectedStatus: http.StatusOK, expectedBody: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+	assert.Equal(t, map[string]time.Duration{"youtube feed-1": 5 * 24 * time.Hour}, leases.leases, "only the hub's challenge grants a lease")
+}
+
+// stubQuotaService reports YouTube connected with most of its quota used
+type stubQuotaService struct {
+	services.QuotaService
+}
+
+func (s *stubQuotaService) Connections(ctx context.Context, tenantID, userID string) ([]*models.PlatformConnection, error) {
+	return []*models.PlatformConnection{{
+		Platform:   models.PlatformYouTube,
+		Connected:  true,
+		Workspaces: []string{"ws-1"},
+		Quota:      &models.PlatformQuotaState{Used: 8500, Limit: 10000, Remaining: 1500, Deferred: []string{"upload", "sync"}},
+	}}, nil
+}
+
+func TestPlatformHandler_ListConnections(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewPlatformHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &queueWebhookService{}, partners.WebhookSecrets{}, &stubQuotaService{}, nil, nil)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/platforms/connections", handler.ListConnections)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/platforms/connections", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data []*models.PlatformConnection `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, int64(1500), body.Data[0].Quota.Remaining)
+	assert.Equal(t, []string{"upload", "sync"}, body.Data[0].Quota.Deferred)
+}
+
+// stubConnectionService resumes two publications of ws-1 unless the platform
+// still rejects its token
+type stubConnectionService struct {
+	services.ConnectionService
+	err error
+}
+
+func (s *stubConnectionService) Reconnected(ctx context.Context, user *models.User, workspaceID string, platform models.Platform) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if workspaceID != "ws-1" {
+		return 0, models.ErrNotFound
+	}
+	return 2, nil
+}
+
+func TestPlatformHandler_Reconnect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	connections := &stubConnectionService{}
+	var mockDB *db.DB
+	handler := NewPlatformHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &queueWebhookService{}, partners.WebhookSecrets{}, nil, nil, connections)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/platforms/:platform/reconnect", handler.Reconnect)
+
+	reconnect := func(workspaceID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/platforms/youtube/reconnect", strings.NewReader(`{"workspace_id":"`+workspaceID+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := reconnect("ws-1")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, float64(2), body.Data["resumed"])
+
+	assert.Equal(t, http.StatusBadRequest, reconnect("").Code)
+	assert.Equal(t, http.StatusNotFound, reconnect("ws-2").Code)
+
+	connections.err = fmt.Errorf("failed to authenticate with youtube: %w", partners.ErrInvalidToken)
+	assert.Equal(t, http.StatusConflict, reconnect("ws-1").Code)
+}
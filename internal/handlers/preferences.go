@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// PreferencesHandler handles the preferences and notifications of the
+// current user
+type PreferencesHandler struct {
+	*BaseHandler
+	preferencesService  services.PreferencesService
+	notificationService services.NotificationService
+}
+
+// NewPreferencesHandler creates a new preferences handler
+func NewPreferencesHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, preferencesService services.PreferencesService, notificationService services.NotificationService) *PreferencesHandler {
+	return &PreferencesHandler{
+		BaseHandler:         NewBaseHandler(cfg, logger, db),
+		preferencesService:  preferencesService,
+		notificationService: notificationService,
+	}
+}
+
+// GetPreferences handles getting the current user's preferences
+// @Summary Get user preferences
+// @Description Get the current user's UI language, timezone, notification channels and digest frequency
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/auth/me/preferences [get]
+func (h *PreferencesHandler) GetPreferences(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	prefs, err := h.preferencesService.Get(c.Request.Context(), tenantID, userID)
+	if err != nil {
+		h.logger.Error("Failed to get preferences", "error", err, "tenant_id", tenantID, "user_id", userID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get preferences")
+		return
+	}
+
+	h.respondWithSuccess(c, "Preferences retrieved successfully", prefs)
+}
+
+// UpdatePreferences handles changing the current user's preferences
+// @Summary Update user preferences
+// @Description Change the current user's preferences; omitted fields are left unchanged. The timezone is an IANA name, used to bucket analytics by day and to send digests at 08:00.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdatePreferencesRequest true "Preferences"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/auth/me/preferences [put]
+func (h *PreferencesHandler) UpdatePreferences(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	prefs, err := h.preferencesService.Update(c.Request.Context(), tenantID, userID, &req)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+		return
+	default:
+		h.logger.Error("Failed to update preferences", "error", err, "tenant_id", tenantID, "user_id", userID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to update preferences")
+		return
+	}
+
+	h.respondWithSuccess(c, "Preferences updated successfully", prefs)
+}
+
+// ListNotifications handles listing the current user's notifications
+// @Summary List notifications
+// @Description List the current user's in-app notifications, newest first
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/auth/me/notifications [get]
+func (h *PreferencesHandler) ListNotifications(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+	notifications, err := h.notificationService.ListInApp(c.Request.Context(), tenantID, userID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list notifications", "error", err, "tenant_id", tenantID, "user_id", userID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list notifications")
+		return
+	}
+
+	h.respondWithSuccess(c, "Notifications retrieved successfully", notifications)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/middleware"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubPreferencesService keeps one user's preferences, in Tokyo
+type stubPreferencesService struct {
+	services.PreferencesService
+}
+
+func (s *stubPreferencesService) Get(ctx context.Context, tenantID, userID string) (*models.UserPreferences, error) {
+	prefs := models.DefaultUserPreferences(tenantID, userID)
+	prefs.Timezone = "Asia/Tokyo"
+	return prefs, nil
+}
+
+func (s *stubPreferencesService) Update(ctx context.Context, tenantID, userID string, req *models.UpdatePreferencesRequest) (*models.UserPreferences, error) {
+	prefs, _ := s.Get(ctx, tenantID, userID)
+	if req.Language != nil {
+		if *req.Language != "fr" {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "language must be one of %v", models.UILanguages)
+		}
+		prefs.Language = *req.Language
+	}
+	return prefs, nil
+}
+
+func (s *stubPreferencesService) Location(ctx context.Context, tenantID, userID string) *time.Location {
+	loc, _ := time.LoadLocation("Asia/Tokyo")
+	return loc
+}
+
+type stubNotificationService struct {
+	services.NotificationService
+}
+
+func (s *stubNotificationService) ListInApp(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.UserNotification, error) {
+	return []*models.UserNotification{{ID: "n-1", UserID: userID, Subject: "Video transfer accepted"}}, nil
+}
+
+// PreviewEmail previews the alert template only
+func (s *stubNotificationService) PreviewEmail(ctx context.Context, tenantID, userID, template string) (*notify.Email, error) {
+	if template != notify.TemplateAlert {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "template must be one of %v", notify.Templates)
+	}
+	return &notify.Email{Subject: "Alert: 10K views", Text: "views is now 10000", HTML: "<!DOCTYPE html>"}, nil
+}
+
+func TestPreferencesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewPreferencesHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubPreferencesService{}, &stubNotificationService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/auth/me/preferences", handler.GetPreferences)
+	r.PUT("/auth/me/preferences", handler.UpdatePreferences)
+	r.GET("/auth/me/notifications", handler.ListNotifications)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("GET", "/auth/me/preferences", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"timezone":"Asia/Tokyo"`)
+
+	w = serve("PUT", "/auth/me/preferences", `{"language":"fr"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"language":"fr"`)
+	assert.Equal(t, http.StatusBadRequest, serve("PUT", "/auth/me/preferences", `{"language":"xx"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("PUT", "/auth/me/preferences", `{"language":`).Code)
+
+	w = serve("GET", "/auth/me/notifications", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"subject":"Video transfer accepted"`)
+}
+
+func TestPreferencesHandler_TranslatesMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewPreferencesHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubPreferencesService{}, &stubNotificationService{})
+	r := gin.New()
+	r.Use(middleware.Locale())
+	addAuthMiddleware(r)
+	r.PUT("/auth/me/preferences", handler.UpdatePreferences)
+
+	serve := func(lang, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/auth/me/preferences", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", lang)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("fr-FR,fr;q=0.9,en;q=0.8", `{"language":"fr"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "fr", w.Header().Get("Content-Language"))
+	assert.Contains(t, w.Body.String(), `"message":"Préférences mises à jour avec succès"`)
+
+	w = serve("de", `{"language":"xx"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"error":"Bad Request"`, "the status text stays English for clients matching on it")
+	assert.Contains(t, w.Body.String(), `"message":"ungültige Eingabe: language muss einer der Werte [en fr es de] sein"`)
+
+	w = serve("ja", `{"language":`)
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+	assert.Contains(t, w.Body.String(), `"message":"Invalid request payload"`)
+}
+
+// stubDailyStatsService records the range of the daily history asked for
+type stubDailyStatsService struct {
+	services.AnalyticsService
+	from time.Time
+	loc  *time.Location
+}
+
+func (s *stubDailyStatsService) GetVideoStatsDaily(ctx context.Context, viewer *models.User, videoID string, from, to time.Time, loc *time.Location) ([]*services.DailyStats, error) {
+	s.from, s.loc = from, loc
+	return []*services.DailyStats{{Date: from.Format("2006-01-02"), Views: 42}}, nil
+}
+
+func TestStatsHandler_GetVideoStatsHistoryDaily(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	svc := &stubDailyStatsService{}
+	handler := NewStatsHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, svc, &stubPreferencesService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/stats/videos/:id/history", handler.GetVideoStatsHistory)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stats/videos/video-1/history?interval=day&days=7", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"views":42`)
+	assert.Equal(t, "Asia/Tokyo", svc.loc.String(), "days start at midnight for the user")
+	local := svc.from.In(svc.loc)
+	assert.Zero(t, local.Hour())
+	assert.Equal(t, time.Now().In(svc.loc).AddDate(0, 0, -6).Day(), local.Day())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stats/videos/video-1/history?interval=week", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// PublicationHandler handles the publications of videos on platforms
+type PublicationHandler struct {
+	*BaseHandler
+	publicationService services.PublicationService
+}
+
+// NewPublicationHandler creates a new publication handler
+func NewPublicationHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, publicationService services.PublicationService) *PublicationHandler {
+	return &PublicationHandler{
+		BaseHandler:        NewBaseHandler(cfg, logger, db),
+		publicationService: publicationService,
+	}
+}
+
+// Unpublish handles taking a publication down, e.g. for a legal takedown
+// @Summary Unpublish publication
+// @Description Take a publication down from its platform and record why. YouTube videos are made private and Facebook videos deleted; on other platforms the API cannot, so the returned takedown is "manual" and the video must be removed by hand. A publication that has not reached the platform yet is only cancelled ("none").
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param pub_id path string true "Publication ID"
+// @Param request body models.UnpublishPublicationRequest true "Reason for the takedown"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/publications/{pub_id}/unpublish [delete]
+func (h *PublicationHandler) Unpublish(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.UnpublishPublicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "A reason is required")
+		return
+	}
+
+	pubID := c.Param("pub_id")
+	job, err := h.publicationService.Unpublish(c.Request.Context(), tenantID, userID, c.Param("id"), pubID, req.Reason)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithError(c, http.StatusBadRequest, "A reason is required")
+		return
+	case errors.Is(err, models.ErrPublicationNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Publication not found")
+		return
+	case errors.Is(err, models.ErrAlreadyUnpublished):
+		h.respondWithErr(c, http.StatusConflict, err)
+		return
+	case errors.Is(err, partners.ErrInvalidToken):
+		h.respondWithError(c, http.StatusBadGateway, "The platform rejected the workspace credentials")
+		return
+	case errors.Is(err, partners.ErrQuotaExceeded), errors.Is(err, partners.ErrQuotaDeferred):
+		h.respondWithError(c, http.StatusServiceUnavailable, "The platform quota is spent, try again after it resets")
+		return
+	default:
+		h.logger.Error("Failed to unpublish publication", "error", err, "user_id", userID, "tenant_id", tenantID, "publication_id", pubID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to unpublish publication")
+		return
+	}
+
+	job.Localize(c.GetString("locale"))
+	h.respondWithSuccess(c, "Publication unpublished successfully", job)
+}
+
+// ListPublications handles the publications of a video
+// @Summary Video publications
+// @Description List the publication jobs of a video on every platform, with their status and schedule
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/publications [get]
+func (h *PublicationHandler) ListPublications(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	jobs, err := h.publicationService.List(c.Request.Context(), viewer, c.Param("id"))
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+		return
+	default:
+		h.logger.Error("Failed to get publications", "error", err, "tenant_id", viewer.TenantID, "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get publications")
+		return
+	}
+
+	locale := c.GetString("locale")
+	for _, job := range jobs {
+		job.Localize(locale)
+	}
+	h.respondWithSuccess(c, "Publications retrieved successfully", jobs)
+}
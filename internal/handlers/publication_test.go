@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubPublicationService unpublishes "pub-1" of "video-1" once
+type stubPublicationService struct {
+	services.PublicationService
+	unpublished bool
+}
+
+func (s *stubPublicationService) Unpublish(ctx context.Context, tenantID, userID, videoID, publicationID, reason string) (*models.PublicationJob, error) {
+	switch {
+	case videoID != "video-1" || publicationID != "pub-1":
+		return nil, models.ErrPublicationNotFound
+	case s.unpublished:
+		return nil, models.ErrAlreadyUnpublished
+	case reason == "quota":
+		return nil, partners.ErrQuotaExceeded
+	}
+	s.unpublished = true
+	return &models.PublicationJob{ID: publicationID, Status: string(models.PublicationUnpublished), UnpublishReason: reason, Takedown: "private"}, nil
+}
+
+func (s *stubPublicationService) List(ctx context.Context, viewer *models.User, videoID string) ([]*models.PublicationJob, error) {
+	if videoID != "video-1" {
+		return nil, models.ErrVideoNotFound
+	}
+	return []*models.PublicationJob{{ID: "pub-1", VideoID: videoID, Platform: string(models.PlatformYouTube), Status: string(models.PublicationScheduled)}}, nil
+}
+
+func TestPublicationHandler_Unpublish(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewPublicationHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubPublicationService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.DELETE("/videos/:id/publications/:pub_id/unpublish", handler.Unpublish)
+
+	unpublish := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("DELETE", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, unpublish("/videos/video-1/publications/pub-1/unpublish", `{}`).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, unpublish("/videos/video-1/publications/pub-1/unpublish", `{"reason":"quota"}`).Code)
+	assert.Equal(t, http.StatusNotFound, unpublish("/videos/video-2/publications/pub-1/unpublish", `{"reason":"DMCA notice 4711"}`).Code)
+
+	w := unpublish("/videos/video-1/publications/pub-1/unpublish", `{"reason":"DMCA notice 4711"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"unpublish_reason":"DMCA notice 4711"`)
+	assert.Contains(t, w.Body.String(), `"takedown":"private"`)
+
+	assert.Equal(t, http.StatusConflict, unpublish("/videos/video-1/publications/pub-1/unpublish", `{"reason":"DMCA notice 4711"}`).Code)
+}
+
+func TestPublicationHandler_ListPublications(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewPublicationHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubPublicationService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/videos/:id/publications", handler.ListPublications)
+
+	list := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := list("/videos/video-1/publications")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"pub-1"`)
+	assert.Contains(t, w.Body.String(), `"status":"scheduled"`)
+
+	assert.Equal(t, http.StatusNotFound, list("/videos/video-2/publications").Code)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// PublishPreviewHandler handles previews of video publications
+type PublishPreviewHandler struct {
+	*BaseHandler
+	previewService services.PublishPreviewService
+}
+
+// NewPublishPreviewHandler creates a new publish preview handler
+func NewPublishPreviewHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, previewService services.PublishPreviewService) *PublishPreviewHandler {
+	return &PublishPreviewHandler{
+		BaseHandler:    NewBaseHandler(cfg, logger, db),
+		previewService: previewService,
+	}
+}
+
+// GetPublishPreview handles previewing a video's publication on a platform
+// @Summary Preview publication
+// @Description Render the title, description and tags that publishing the video would send to the platform, fitted to its limits (a YouTube title is at most 100 characters, a tweet 280...). The adjustments list every change made: text truncated, characters removed, or tags and titles the platform cannot take.
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param platform query string true "Platform name" Enums(youtube,tiktok,instagram,facebook,twitter,linkedin,snapchat)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/publish-preview [get]
+func (h *PublishPreviewHandler) GetPublishPreview(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	platform := c.Query("platform")
+	if platform == "" {
+		h.respondWithError(c, http.StatusBadRequest, "Platform query parameter is required")
+		return
+	}
+
+	preview, err := h.previewService.Preview(c.Request.Context(), viewer, c.Param("id"), platform)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidPlatform):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+		return
+	default:
+		h.logger.Error("Failed to preview publication", "error", err, "tenant_id", viewer.TenantID, "video_id", c.Param("id"), "platform", platform)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to preview publication")
+		return
+	}
+
+	h.respondWithSuccess(c, "Publication preview rendered successfully", preview)
+}
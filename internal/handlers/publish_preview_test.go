@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubPreviewService previews the video "video-1" only
+type stubPreviewService struct {
+	services.PublishPreviewService
+}
+
+func (s *stubPreviewService) Preview(ctx context.Context, viewer *models.User, videoID, platform string) (*services.PublishPreview, error) {
+	if !models.Platform(platform).Valid() {
+		return nil, fmt.Errorf("%w: %s", models.ErrInvalidPlatform, platform)
+	}
+	if videoID != "video-1" {
+		return nil, models.ErrVideoNotFound
+	}
+	return &services.PublishPreview{VideoID: videoID, Platform: models.Platform(platform), Metadata: &partners.Metadata{Title: "Flannan Isles"}}, nil
+}
+
+func TestPublishPreviewHandler_GetPublishPreview(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewPublishPreviewHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubPreviewService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/videos/:id/publish-preview", handler.GetPublishPreview)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/videos/video-1/publish-preview?platform=youtube")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"title":"Flannan Isles"`)
+
+	assert.Equal(t, http.StatusBadRequest, get("/videos/video-1/publish-preview").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/videos/video-1/publish-preview?platform=myspace").Code)
+	assert.Equal(t, http.StatusNotFound, get("/videos/video-2/publish-preview?platform=youtube").Code)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// QCHandler handles the quality control of transcoded videos
+type QCHandler struct {
+	*BaseHandler
+	qcService services.QCService
+}
+
+// NewQCHandler creates a new quality control handler
+func NewQCHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, qcService services.QCService) *QCHandler {
+	return &QCHandler{
+		BaseHandler: NewBaseHandler(cfg, logger, db),
+		qcService:   qcService,
+	}
+}
+
+// OverrideQC handles releasing a video that failed quality control
+// @Summary Override quality control
+// @Description Release a video held as qc_failed so it can be published, recording who did and why. The reasons it failed stay on the video.
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param request body models.QCOverrideRequest true "Override"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/qc/override [post]
+func (h *QCHandler) OverrideQC(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.QCOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	video, err := h.qcService.Override(c.Request.Context(), tenantID, userID, c.Param("id"), req.Reason)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+		return
+	case errors.Is(err, models.ErrConflict):
+		h.respondWithErr(c, http.StatusConflict, err)
+		return
+	default:
+		h.logger.Error("Failed to override quality control", "error", err, "tenant_id", tenantID, "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to override quality control")
+		return
+	}
+
+	h.respondWithSuccess(c, "Quality control overridden successfully", video)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubQCService holds "video-1" as qc_failed and knows "video-2" as ready
+type stubQCService struct {
+	services.QCService
+}
+
+func (s *stubQCService) Override(ctx context.Context, tenantID, userID, videoID, reason string) (*models.Video, error) {
+	switch videoID {
+	case "video-1":
+		return &models.Video{ID: videoID, Status: string(models.StatusReady), QC: models.VideoQC{OverriddenBy: userID, OverrideReason: reason}}, nil
+	case "video-2":
+		return nil, i18n.Errorf(models.ErrConflict, "the video did not fail quality control")
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+func TestQCHandler_OverrideQC(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewQCHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubQCService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/videos/:id/qc/override", handler.OverrideQC)
+
+	do := func(id, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/videos/"+id+"/qc/override", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("video-1", `{"reason":"Intentional slow black intro"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"overridden_by":"test-user-123"`)
+	assert.Equal(t, http.StatusBadRequest, do("video-1", `{}`).Code)
+	assert.Equal(t, http.StatusConflict, do("video-2", `{"reason":"ok"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do("video-3", `{"reason":"ok"}`).Code)
+}
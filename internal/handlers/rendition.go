@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// RenditionHandler handles the per-platform renditions of videos
+type RenditionHandler struct {
+	*BaseHandler
+	renditionService services.RenditionService
+}
+
+// NewRenditionHandler creates a new rendition handler
+func NewRenditionHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, renditionService services.RenditionService) *RenditionHandler {
+	return &RenditionHandler{
+		BaseHandler:      NewBaseHandler(cfg, logger, db),
+		renditionService: renditionService,
+	}
+}
+
+// ListRenditions handles listing the renditions of a video
+// @Summary List video renditions
+// @Description List the renditions the video is transcoded into, one per profile: landscape_1080p (16:9) for YouTube, Facebook and LinkedIn, landscape_720p for X/Twitter and vertical_1080p (9:16) for TikTok, Instagram Reels and Snapchat. Publications upload their platform's rendition once it is ready.
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/renditions [get]
+func (h *RenditionHandler) ListRenditions(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	renditions, err := h.renditionService.List(c.Request.Context(), viewer, c.Param("id"))
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+		return
+	default:
+		h.logger.Error("Failed to list renditions", "error", err, "tenant_id", viewer.TenantID, "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list renditions")
+		return
+	}
+
+	h.respondWithSuccess(c, "Renditions retrieved successfully", renditions)
+}
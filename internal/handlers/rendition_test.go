@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubRenditionService knows the renditions of a single video
+type stubRenditionService struct {
+	services.RenditionService
+}
+
+func (s *stubRenditionService) List(ctx context.Context, viewer *models.User, videoID string) ([]*models.VideoRendition, error) {
+	if videoID != "video-1" {
+		return nil, models.ErrVideoNotFound
+	}
+	return []*models.VideoRendition{{VideoID: videoID, Profile: "vertical_1080p", Status: string(models.RenditionReady)}}, nil
+}
+
+func TestRenditionHandler_ListRenditions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewRenditionHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubRenditionService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/videos/:id/renditions", handler.ListRenditions)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/videos/video-1/renditions", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"profile":"vertical_1080p"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/videos/video-2/renditions", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/residency"
+)
+
+// ResidencyHandler handles tenant data residency requests
+type ResidencyHandler struct {
+	*BaseHandler
+	residencyService services.ResidencyService
+}
+
+// NewResidencyHandler creates a new residency handler
+func NewResidencyHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, residencyService services.ResidencyService) *ResidencyHandler {
+	return &ResidencyHandler{
+		BaseHandler:      NewBaseHandler(cfg, logger, db),
+		residencyService: residencyService,
+	}
+}
+
+// GetResidency handles getting the tenant's data residency
+// @Summary Get data residency
+// @Description Get where the tenant's files are stored and its AI requests are processed
+// @Tags residency
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} services.TenantResidency
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/residency [get]
+func (h *ResidencyHandler) GetResidency(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	tr, err := h.residencyService.GetResidency(c.Request.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to get data residency", "error", err, "tenant_id", tenantID)
+		h.respondWithResidencyError(c, err, "Failed to get data residency")
+		return
+	}
+
+	h.respondWithSuccess(c, "Data residency retrieved successfully", tr)
+}
+
+// UpdateResidency handles changing the tenant's data residency
+// @Summary Update data residency
+// @Description Change the tenant's residency (us or eu) or pin it. New files and AI requests follow the new residency; existing files are not moved. A pinned tenant cannot change residency and its requests fail rather than fall back to another region.
+// @Tags residency
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateResidencyRequest true "Residency"
+// @Success 200 {object} services.TenantResidency
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/residency [put]
+func (h *ResidencyHandler) UpdateResidency(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.UpdateResidencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	h.logger.Info("Updating data residency", "user_id", userID, "tenant_id", tenantID)
+
+	tr, err := h.residencyService.UpdateResidency(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		h.logger.Error("Failed to update data residency", "error", err, "tenant_id", tenantID)
+		h.respondWithResidencyError(c, err, "Failed to update data residency")
+		return
+	}
+
+	h.respondWithSuccess(c, "Data residency updated successfully", tr)
+}
+
+// respondWithResidencyError maps residency service errors to HTTP responses
+func (h *ResidencyHandler) respondWithResidencyError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, models.ErrTenantNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Tenant not found")
+	case errors.Is(err, residency.ErrCrossRegion):
+		h.respondWithErr(c, http.StatusConflict, err)
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// RetentionHandler handles the audience retention curves of videos
+type RetentionHandler struct {
+	*BaseHandler
+	retentionService services.RetentionService
+}
+
+// NewRetentionHandler creates a new retention handler
+func NewRetentionHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, retentionService services.RetentionService) *RetentionHandler {
+	return &RetentionHandler{
+		BaseHandler:      NewBaseHandler(cfg, logger, db),
+		retentionService: retentionService,
+	}
+}
+
+// GetRetention handles getting the retention curves of a video
+// @Summary Get video retention
+// @Description Get the audience retention curve of a video on each platform it was fetched from: the share of viewers still watching along the video. Each curve lists its drop-offs, the stretches where viewers leave fastest, at most five in the order they happen, with their seconds when the video's duration is known.
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/retention [get]
+func (h *RetentionHandler) GetRetention(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	videoID := c.Param("id")
+	retention, err := h.retentionService.GetRetention(c.Request.Context(), viewer, videoID)
+	switch {
+	case err == nil:
+		h.respondWithSuccess(c, "Video retention retrieved successfully", retention)
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	default:
+		h.logger.Error("Failed to get video retention", "error", err, "tenant_id", viewer.TenantID, "video_id", videoID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get video retention")
+	}
+}
+
+// SyncRetention handles fetching the retention curves of a video
+// @Summary Sync video retention
+// @Description Fetch the audience retention curve of a video from each platform it is published on that reports one, with a workspace's credentials, replacing the stored curves. Only YouTube reports retention.
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param request body models.RetentionSyncRequest true "Workspace"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/retention/sync [post]
+func (h *RetentionHandler) SyncRetention(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	var req models.RetentionSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	videoID := c.Param("id")
+	retention, err := h.retentionService.SyncRetention(c.Request.Context(), viewer, videoID, req.WorkspaceID)
+	switch {
+	case err == nil:
+		h.respondWithSuccess(c, "Video retention synced successfully", retention)
+	case errors.Is(err, models.ErrRetentionUnsupported):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	case errors.Is(err, models.ErrNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Workspace not found")
+	case errors.Is(err, partners.ErrInvalidToken):
+		h.respondWithError(c, http.StatusBadGateway, "The platform rejected the workspace credentials")
+	case errors.Is(err, partners.ErrQuotaExceeded), errors.Is(err, partners.ErrQuotaDeferred):
+		h.respondWithError(c, http.StatusServiceUnavailable, "The platform quota is spent, try again after it resets")
+	default:
+		h.logger.Error("Failed to sync video retention", "error", err, "tenant_id", viewer.TenantID, "video_id", videoID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to sync video retention")
+	}
+}
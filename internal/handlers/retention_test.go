@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubRetentionService knows "video-1" on YouTube and "video-2" on TikTok
+// only, and syncs with the workspace "ws-1"; "ws-revoked" has lost its token
+type stubRetentionService struct {
+	services.RetentionService
+}
+
+func (s *stubRetentionService) GetRetention(ctx context.Context, viewer *models.User, videoID string) (*models.VideoRetention, error) {
+	if videoID != "video-1" && videoID != "video-2" {
+		return nil, models.ErrVideoNotFound
+	}
+	return &models.VideoRetention{VideoID: videoID, Curves: []*models.PlatformRetention{}}, nil
+}
+
+func (s *stubRetentionService) SyncRetention(ctx context.Context, viewer *models.User, videoID, workspaceID string) (*models.VideoRetention, error) {
+	switch {
+	case workspaceID == "ws-revoked":
+		return nil, fmt.Errorf("failed to authenticate on youtube: %w", partners.ErrInvalidToken)
+	case workspaceID != "ws-1":
+		return nil, fmt.Errorf("failed to get workspace: %w", models.ErrNotFound)
+	case videoID == "video-2":
+		return nil, models.ErrRetentionUnsupported
+	}
+	return s.GetRetention(ctx, viewer, videoID)
+}
+
+func TestRetentionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewRetentionHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubRetentionService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/videos/:id/retention", handler.GetRetention)
+	r.POST("/videos/:id/retention/sync", handler.SyncRetention)
+
+	serve := func(method, path, body string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("GET", "/videos/video-1/retention", ""))
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/videos/video-9/retention", ""))
+
+	assert.Equal(t, http.StatusOK, serve("POST", "/videos/video-1/retention/sync", `{"workspace_id":"ws-1"}`))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/videos/video-1/retention/sync", `{}`))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/videos/video-2/retention/sync", `{"workspace_id":"ws-1"}`))
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/videos/video-1/retention/sync", `{"workspace_id":"ws-2"}`))
+	assert.Equal(t, http.StatusBadGateway, serve("POST", "/videos/video-1/retention/sync", `{"workspace_id":"ws-revoked"}`))
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// defaultRightsReportDays is the window of the expiring rights report
+const defaultRightsReportDays = 30
+
+// RightsHandler handles the rights to videos
+type RightsHandler struct {
+	*BaseHandler
+	rightsService services.RightsService
+}
+
+// NewRightsHandler creates a new rights handler
+func NewRightsHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, rightsService services.RightsService) *RightsHandler {
+	return &RightsHandler{
+		BaseHandler:   NewBaseHandler(cfg, logger, db),
+		rightsService: rightsService,
+	}
+}
+
+// GetExpiringRights handles the report of expiring video rights
+// @Summary Expiring rights
+// @Description List the videos whose rights expire within the next days, or expired within the last ones, with the platforms they are published on. Videos cannot be published once their rights expire, but videos already published stay online until they are unpublished.
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Window in days, up to 365" default(30)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/rights/expiring [get]
+func (h *RightsHandler) GetExpiringRights(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	days := defaultRightsReportDays
+	if raw := c.Query("days"); raw != "" {
+		if days, err = strconv.Atoi(raw); err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "days must be a number")
+			return
+		}
+	}
+
+	report, err := h.rightsService.Expiring(c.Request.Context(), tenantID, days)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+		return
+	default:
+		h.logger.Error("Failed to report expiring rights", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to report expiring rights")
+		return
+	}
+
+	h.respondWithSuccess(c, "Expiring rights retrieved successfully", report)
+}
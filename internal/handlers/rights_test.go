@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubRightsService reports the window it was asked for
+type stubRightsService struct {
+	services.RightsService
+}
+
+func (s *stubRightsService) Expiring(ctx context.Context, tenantID string, days int) (*services.RightsReport, error) {
+	if days > 365 {
+		return nil, fmt.Errorf("%w: days must be between 1 and 365", models.ErrInvalidInput)
+	}
+	return &services.RightsReport{Days: days, Videos: []*services.ExpiringRights{}}, nil
+}
+
+func TestRightsHandler_GetExpiringRights(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewRightsHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubRightsService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/rights/expiring", handler.GetExpiringRights)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/rights/expiring")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"days":30`)
+	assert.Contains(t, get("/rights/expiring?days=90").Body.String(), `"days":90`)
+
+	assert.Equal(t, http.StatusBadRequest, get("/rights/expiring?days=soon").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/rights/expiring?days=400").Code)
+}
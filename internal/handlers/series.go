@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// SeriesHandler handles series and their numbered episodes
+type SeriesHandler struct {
+	*BaseHandler
+	seriesService services.SeriesService
+}
+
+// NewSeriesHandler creates a new series handler
+func NewSeriesHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, seriesService services.SeriesService) *SeriesHandler {
+	return &SeriesHandler{
+		BaseHandler:   NewBaseHandler(cfg, logger, db),
+		seriesService: seriesService,
+	}
+}
+
+// ListSeries handles listing the tenant's series
+// @Summary List series
+// @Description List the tenant's series by name
+// @Tags series
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/series [get]
+func (h *SeriesHandler) ListSeries(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	series, err := h.seriesService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to list series", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list series")
+		return
+	}
+	h.respondWithSuccess(c, "Series retrieved successfully", series)
+}
+
+// CreateSeries handles creating a series
+// @Summary Create series
+// @Description Create a series. Its episodes are published with titles rendered from title_pattern, e.g. "S{{season}}E{{episode:2}}: {{title}}", and added in order to a YouTube playlist on the channel of workspace_id, or of the workspace its first episode is released with.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SeriesRequest true "Series"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/series [post]
+func (h *SeriesHandler) CreateSeries(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.SeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	series, err := h.seriesService.Create(c.Request.Context(), tenantID, userID, &req)
+	if !h.handleSeriesError(c, err, "create") {
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Message: "Series created successfully",
+		Data:    series,
+	})
+}
+
+// GetSeries handles getting a series
+// @Summary Get series
+// @Description Get one of the tenant's series
+// @Tags series
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Series ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/series/{id} [get]
+func (h *SeriesHandler) GetSeries(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	series, err := h.seriesService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if !h.handleSeriesError(c, err, "get") {
+		return
+	}
+	h.respondWithSuccess(c, "Series retrieved successfully", series)
+}
+
+// UpdateSeries handles replacing a series
+// @Summary Update series
+// @Description Replace the settings of a series. Episodes keep their numbers, and the pattern titles the episodes published from now on. The workspace cannot change once the playlist exists.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Series ID"
+// @Param request body models.SeriesRequest true "Series"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/series/{id} [put]
+func (h *SeriesHandler) UpdateSeries(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.SeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	series, err := h.seriesService.Update(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if !h.handleSeriesError(c, err, "update") {
+		return
+	}
+	h.respondWithSuccess(c, "Series updated successfully", series)
+}
+
+// DeleteSeries handles deleting a series
+// @Summary Delete series
+// @Description Delete a series. Its videos are no longer episodes; what was published and the playlist are left as they are.
+// @Tags series
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Series ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/series/{id} [delete]
+func (h *SeriesHandler) DeleteSeries(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	err = h.seriesService.Delete(c.Request.Context(), tenantID, c.Param("id"))
+	if !h.handleSeriesError(c, err, "delete") {
+		return
+	}
+	h.respondWithSuccess(c, "Series deleted successfully", nil)
+}
+
+// ListEpisodes handles listing the episodes of a series
+// @Summary List episodes
+// @Description List the videos of a series by season and episode number
+// @Tags series
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Series ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/series/{id}/episodes [get]
+func (h *SeriesHandler) ListEpisodes(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	videos, err := h.seriesService.Episodes(c.Request.Context(), tenantID, c.Param("id"))
+	if !h.handleSeriesError(c, err, "list episodes of") {
+		return
+	}
+	h.respondWithSuccess(c, "Episodes retrieved successfully", videos)
+}
+
+// AddEpisode handles adding a video to a series
+// @Summary Add episode
+// @Description Add a video to a series, numbered after the last episode of the season (the series' season unless given)
+// @Tags series
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Series ID"
+// @Param request body models.AddEpisodeRequest true "Episode"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/series/{id}/episodes [post]
+func (h *SeriesHandler) AddEpisode(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.AddEpisodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	video, err := h.seriesService.AddEpisode(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if !h.handleSeriesError(c, err, "add episode to") {
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Message: "Episode added successfully",
+		Data:    video,
+	})
+}
+
+// handleSeriesError responds to a failed series operation, reporting
+// whether there was none
+func (h *SeriesHandler) handleSeriesError(c *gin.Context, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrSeriesNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Series not found")
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	case errors.Is(err, models.ErrConflict):
+		h.respondWithErr(c, http.StatusConflict, err)
+	default:
+		h.logger.Error("Failed to "+action+" series", "error", err, "tenant_id", c.GetString("tenant_id"), "series_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to "+action+" series")
+	}
+	return false
+}
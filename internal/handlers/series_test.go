@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSeriesService knows the series "series-1" and the video "video-1",
+// already an episode of it
+type stubSeriesService struct {
+	services.SeriesService
+}
+
+func (s *stubSeriesService) Create(ctx context.Context, tenantID, userID string, req *models.SeriesRequest) (*models.Series, error) {
+	series := &models.Series{ID: "series-1", TenantID: tenantID, Name: req.Name, TitlePattern: req.TitlePattern, Season: 1, CreatedBy: userID}
+	return series, series.Validate()
+}
+
+func (s *stubSeriesService) AddEpisode(ctx context.Context, tenantID, seriesID string, req *models.AddEpisodeRequest) (*models.Video, error) {
+	switch {
+	case seriesID != "series-1":
+		return nil, models.ErrSeriesNotFound
+	case req.VideoID == "video-1":
+		return nil, i18n.Errorf(models.ErrConflict, "the video is already an episode of a series")
+	case req.VideoID != "video-2":
+		return nil, models.ErrVideoNotFound
+	}
+	return &models.Video{ID: req.VideoID, Episode: models.VideoEpisode{SeriesID: seriesID, Season: 1, Number: 2}}, nil
+}
+
+func TestSeriesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewSeriesHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubSeriesService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/series", handler.CreateSeries)
+	r.POST("/series/:id/episodes", handler.AddEpisode)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/series", `{"name":"Cold cases","title_pattern":"S{{season}}E{{episode:2}}: {{title}}"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"created_by":"test-user-123"`)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/series", `{"title_pattern":"{{title}}"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/series", `{"name":"Cold cases","title_pattern":"{{plot}}"}`).Code)
+
+	w = do("POST", "/series/series-1/episodes", `{"video_id":"video-2"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"number":2`)
+	assert.Equal(t, http.StatusConflict, do("POST", "/series/series-1/episodes", `{"video_id":"video-1"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/series/series-1/episodes", `{"video_id":"video-3"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/series/series-2/episodes", `{"video_id":"video-2"}`).Code)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// SmartListHandler handles the smart lists of the current user
+type SmartListHandler struct {
+	*BaseHandler
+	smartListService services.SmartListService
+}
+
+// NewSmartListHandler creates a new smart list handler
+func NewSmartListHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, smartListService services.SmartListService) *SmartListHandler {
+	return &SmartListHandler{
+		BaseHandler:      NewBaseHandler(cfg, logger, db),
+		smartListService: smartListService,
+	}
+}
+
+// CreateSmartList handles saving a smart list
+// @Summary Create smart list
+// @Description Save a filter over the video library under a name, such as "status=ready AND platform=tiktok AND engagement_rate>5%". Conditions are joined by AND and compare a field with =, !=, >, >=, < or <=: status, platform (published there), tag, workspace_id, duration (seconds), views and engagement_rate (percent of views). The list's videos are the ones matching it when it is used, and it can be the target of a bulk magic brush.
+// @Tags smart-lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SmartListRequest true "Smart list"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/smart-lists [post]
+func (h *SmartListHandler) CreateSmartList(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.SmartListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	list, err := h.smartListService.CreateList(c.Request.Context(), tenantID, userID, &req)
+	if !h.handleSmartListError(c, err, "create") {
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Message: "Smart list created successfully",
+		Data:    list,
+	})
+}
+
+// ListSmartLists handles listing the user's smart lists
+// @Summary List smart lists
+// @Description List the current user's smart lists by name
+// @Tags smart-lists
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/smart-lists [get]
+func (h *SmartListHandler) ListSmartLists(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+	lists, err := h.smartListService.ListLists(c.Request.Context(), tenantID, userID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list smart lists", "error", err, "tenant_id", tenantID, "user_id", userID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list smart lists")
+		return
+	}
+	h.respondWithSuccess(c, "Smart lists retrieved successfully", lists)
+}
+
+// GetSmartList handles getting a smart list
+// @Summary Get smart list
+// @Description Get one of the current user's smart lists
+// @Tags smart-lists
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Smart list ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/smart-lists/{id} [get]
+func (h *SmartListHandler) GetSmartList(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	list, err := h.smartListService.GetList(c.Request.Context(), tenantID, userID, c.Param("id"))
+	if !h.handleSmartListError(c, err, "get") {
+		return
+	}
+	h.respondWithSuccess(c, "Smart list retrieved successfully", list)
+}
+
+// UpdateSmartList handles replacing a smart list
+// @Summary Update smart list
+// @Description Replace the name and filter of one of the current user's smart lists
+// @Tags smart-lists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Smart list ID"
+// @Param request body models.SmartListRequest true "Smart list"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/smart-lists/{id} [put]
+func (h *SmartListHandler) UpdateSmartList(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.SmartListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	list, err := h.smartListService.UpdateList(c.Request.Context(), tenantID, userID, c.Param("id"), &req)
+	if !h.handleSmartListError(c, err, "update") {
+		return
+	}
+	h.respondWithSuccess(c, "Smart list updated successfully", list)
+}
+
+// DeleteSmartList handles deleting a smart list
+// @Summary Delete smart list
+// @Description Delete one of the current user's smart lists; its videos are left as they are
+// @Tags smart-lists
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Smart list ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/smart-lists/{id} [delete]
+func (h *SmartListHandler) DeleteSmartList(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	err = h.smartListService.DeleteList(c.Request.Context(), tenantID, userID, c.Param("id"))
+	if !h.handleSmartListError(c, err, "delete") {
+		return
+	}
+	h.respondWithSuccess(c, "Smart list deleted successfully", nil)
+}
+
+// ListSmartListVideos handles listing the videos of a smart list
+// @Summary List smart list videos
+// @Description List the videos the current user sees that match one of their smart lists now, newest first
+// @Tags smart-lists
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Smart list ID"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/smart-lists/{id}/videos [get]
+func (h *SmartListHandler) ListSmartListVideos(c *gin.Context) {
+	user, exists := c.Get("user")
+	viewer, ok := user.(*models.User)
+	if !exists || !ok {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+	videos, err := h.smartListService.ListVideos(c.Request.Context(), viewer, c.Param("id"), limit, offset)
+	if !h.handleSmartListError(c, err, "list videos of") {
+		return
+	}
+	h.respondWithSuccess(c, "Videos retrieved successfully", videos)
+}
+
+// handleSmartListError answers the errors of a smart list call, reporting
+// whether there was none
+func (h *SmartListHandler) handleSmartListError(c *gin.Context, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrSmartListNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Smart list not found")
+	default:
+		h.logger.Error("Failed to "+action+" smart list", "error", err, "tenant_id", c.GetString("tenant_id"), "smart_list_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to "+action+" smart list")
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSmartListService knows the list "list-1" and parses filters for real
+type stubSmartListService struct {
+	services.SmartListService
+}
+
+func (s *stubSmartListService) CreateList(ctx context.Context, tenantID, userID string, req *models.SmartListRequest) (*models.SmartList, error) {
+	if _, err := models.ParseSmartListFilter(req.Filter); err != nil {
+		return nil, err
+	}
+	return &models.SmartList{ID: "list-1", TenantID: tenantID, UserID: userID, Name: req.Name, Filter: req.Filter}, nil
+}
+
+func (s *stubSmartListService) ListVideos(ctx context.Context, viewer *models.User, id string, limit, offset int) ([]*models.Video, error) {
+	if id != "list-1" {
+		return nil, models.ErrSmartListNotFound
+	}
+	return []*models.Video{{ID: "video-1", TenantID: viewer.TenantID}}, nil
+}
+
+func TestSmartListHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewSmartListHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubSmartListService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/smart-lists", handler.CreateSmartList)
+	r.GET("/smart-lists/:id/videos", handler.ListSmartListVideos)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/smart-lists", `{"name":"TikTok hits","filter":"status=ready AND platform=tiktok AND engagement>5%"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"user_id":"test-user-123"`)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/smart-lists", `{"name":"x","filter":"likes>5"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/smart-lists", `{"filter":"status=ready"}`).Code, "a list needs a name")
+
+	w = do("GET", "/smart-lists/list-1/videos", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"video-1"`)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/smart-lists/list-2/videos", "").Code)
+}
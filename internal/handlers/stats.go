@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
 	"github.com/jibe0123/mysteryfactory/pkg/db"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
 )
@@ -13,92 +17,105 @@ import (
 // StatsHandler handles statistics and analytics requests
 type StatsHandler struct {
 	*BaseHandler
+	analyticsService services.AnalyticsService
+	// preferencesService gives the timezone analytics days start in
+	preferencesService services.PreferencesService
 }
 
 // NewStatsHandler creates a new stats handler
-func NewStatsHandler(cfg *config.Config, logger *logger.Logger, db *db.DB) *StatsHandler {
+func NewStatsHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, analyticsService services.AnalyticsService, preferencesService services.PreferencesService) *StatsHandler {
 	return &StatsHandler{
-		BaseHandler: NewBaseHandler(cfg, logger, db),
+		BaseHandler:        NewBaseHandler(cfg, logger, db),
+		analyticsService:   analyticsService,
+		preferencesService: preferencesService,
 	}
 }
 
-// GetVideosStats handles getting statistics for multiple videos
-// @Summary Get videos statistics
-// @Description Get statistics for multiple videos with optional filtering
+// GetVideosStats handles listing the statistics of the tenant's videos
+// @Summary List videos statistics
+// @Description List the stats rows of the videos the user sees with their engagement rate and score, the percentile of their performance among the platform's videos recomputed every night. Rows not scored yet sort last and are left out when a score bound is set.
 // @Tags stats
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param platform query string false "Filter by platform"
+// @Param min_score query number false "Lowest score, from 0 to 100"
+// @Param max_score query number false "Highest score, from 0 to 100"
+// @Param sort query string false "Sort by views, likes, comments, shares, revenue, last_sync_at, engagement_rate or score" default(score)
+// @Param order query string false "Sort order (asc, desc)" default(desc)
 // @Param limit query int false "Number of items per page" default(20)
 // @Param offset query int false "Number of items to skip" default(0)
 // @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /api/v1/stats/videos [get]
 func (h *StatsHandler) GetVideosStats(c *gin.Context) {
-	userID, tenantID, err := h.getUserFromContext(c)
-	if err != nil {
-		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+	viewer, ok := h.viewer(c)
+	if !ok {
 		return
 	}
 
-	platform := c.Query("platform")
-	limit, offset := h.getPaginationParams(c)
+	var err error
+	filter := models.StatsListFilter{Platform: c.Query("platform"), Sort: c.Query("sort")}
+	if filter.MinScore, err = queryFloat(c, "min_score"); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "min_score must be a number")
+		return
+	}
+	if filter.MaxScore, err = queryFloat(c, "max_score"); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "max_score must be a number")
+		return
+	}
+	switch c.DefaultQuery("order", "desc") {
+	case "desc":
+		filter.Desc = true
+	case "asc":
+	default:
+		h.respondWithError(c, http.StatusBadRequest, "order must be asc or desc")
+		return
+	}
 
-	// TODO: Implement actual video stats retrieval logic
-	h.logger.Info("Getting videos stats",
-		"user_id", userID,
-		"tenant_id", tenantID,
-		"platform", platform,
-		"limit", limit,
-		"offset", offset)
-
-	// Mock data
-	mockStats := []interface{}{
-		gin.H{
-			"video_id":        "video-123",
-			"title":           "Sample Video 1",
-			"platform":        "youtube",
-			"views":           15420,
-			"likes":           892,
-			"comments":        156,
-			"shares":          78,
-			"engagement_rate": 7.2,
-			"revenue":         45.67,
-		},
-		gin.H{
-			"video_id":        "video-456",
-			"title":           "Sample Video 2",
-			"platform":        "tiktok",
-			"views":           8930,
-			"likes":           1205,
-			"comments":        89,
-			"shares":          234,
-			"engagement_rate": 17.1,
-			"revenue":         23.45,
-		},
+	limit, offset := h.getPaginationParams(c)
+	stats, total, err := h.analyticsService.ListVideoStats(c.Request.Context(), viewer, filter, limit, offset)
+	switch {
+	case err == nil:
+		h.respondWithPagination(c, stats, total, offset/limit+1, limit)
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	default:
+		h.logger.Error("Failed to list video stats", "error", err, "tenant_id", viewer.TenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list video stats")
 	}
+}
 
-	h.respondWithPagination(c, mockStats, 2, offset/limit+1, limit)
+// queryFloat parses an optional number query parameter, nil when absent
+func queryFloat(c *gin.Context, name string) (*float64, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
 }
 
 // GetVideoStats handles getting statistics for a specific video
 // @Summary Get video statistics
-// @Description Get detailed statistics for a specific video
+// @Description Get a video's statistics summed across platforms. demographics is its audience as shares of viewers from 0 to 1, by age bucket (13-17, 18-24, 25-34, 35-44, 45-54, 55+), gender (female, male, other) and country code, combining the platforms that report it weighted by their views; it is left out when none does.
 // @Tags stats
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Video ID"
-// @Success 200 {object} SuccessResponse
+// @Success 200 {object} models.VideoStats
 // @Failure 404 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /api/v1/stats/videos/{id} [get]
 // @Router /api/v1/videos/{id}/stats [get]
 func (h *StatsHandler) GetVideoStats(c *gin.Context) {
-	userID, tenantID, err := h.getUserFromContext(c)
-	if err != nil {
-		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+	viewer, ok := h.viewer(c)
+	if !ok {
 		return
 	}
 
@@ -108,88 +125,35 @@ func (h *StatsHandler) GetVideoStats(c *gin.Context) {
 		return
 	}
 
-	// TODO: Implement actual video stats retrieval logic
-	h.logger.Info("Getting video stats",
-		"user_id", userID,
-		"tenant_id", tenantID,
-		"video_id", videoID)
-
-	// Mock aggregated stats across platforms
-	mockStats := gin.H{
-		"video_id": videoID,
-		"title":    "Sample Video",
-		"total_stats": gin.H{
-			"total_views":    23350,
-			"total_likes":    2097,
-			"total_comments": 245,
-			"total_shares":   312,
-			"total_revenue":  69.12,
-			"avg_engagement": 12.15,
-		},
-		"platform_stats": []gin.H{
-			{
-				"platform":        "youtube",
-				"views":           15420,
-				"likes":           892,
-				"comments":        156,
-				"shares":          78,
-				"engagement_rate": 7.2,
-				"revenue":         45.67,
-				"external_url":    "https://youtube.com/watch?v=example",
-			},
-			{
-				"platform":        "tiktok",
-				"views":           7930,
-				"likes":           1205,
-				"comments":        89,
-				"shares":          234,
-				"engagement_rate": 19.3,
-				"revenue":         23.45,
-				"external_url":    "https://tiktok.com/@user/video/example",
-			},
-		},
-		"demographics": gin.H{
-			"age_groups": gin.H{
-				"18-24": 35.2,
-				"25-34": 28.7,
-				"35-44": 20.1,
-				"45-54": 12.3,
-				"55+":   3.7,
-			},
-			"gender": gin.H{
-				"male":   52.3,
-				"female": 47.7,
-			},
-			"top_countries": []gin.H{
-				{"country": "US", "percentage": 42.1},
-				{"country": "UK", "percentage": 18.5},
-				{"country": "CA", "percentage": 12.3},
-				{"country": "AU", "percentage": 8.7},
-				{"country": "DE", "percentage": 6.2},
-			},
-		},
+	stats, err := h.analyticsService.GetVideoStats(c.Request.Context(), viewer, videoID)
+	switch {
+	case err == nil:
+		h.respondWithSuccess(c, "Video stats retrieved successfully", stats)
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	default:
+		h.logger.Error("Failed to get video stats", "error", err, "tenant_id", viewer.TenantID, "video_id", videoID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get video stats")
 	}
-
-	h.respondWithSuccess(c, "Video stats retrieved successfully", mockStats)
 }
 
 // GetVideoStatsHistory handles getting historical statistics for a video
 // @Summary Get video statistics history
-// @Description Get historical statistics snapshots for a specific video
+// @Description Get historical statistics snapshots for a specific video. With interval=day, the totals at the end of each day instead, days starting at midnight in the user's timezone.
 // @Tags stats
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Video ID"
-// @Param days query int false "Number of days to retrieve" default(30)
+// @Param days query int false "Number of days to retrieve (max 366)" default(30)
+// @Param interval query string false "snapshot or day" default(snapshot)
 // @Success 200 {object} SuccessResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /api/v1/stats/videos/{id}/history [get]
 func (h *StatsHandler) GetVideoStatsHistory(c *gin.Context) {
-	userID, tenantID, err := h.getUserFromContext(c)
-	if err != nil {
-		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+	viewer, ok := h.viewer(c)
+	if !ok {
 		return
 	}
 
@@ -206,48 +170,44 @@ func (h *StatsHandler) GetVideoStatsHistory(c *gin.Context) {
 		}
 	}
 
-	// TODO: Implement actual stats history retrieval logic
 	h.logger.Info("Getting video stats history",
-		"user_id", userID,
-		"tenant_id", tenantID,
+		"user_id", viewer.ID,
+		"tenant_id", viewer.TenantID,
 		"video_id", videoID,
 		"days", days)
 
-	// Mock historical data
-	mockHistory := []gin.H{
-		{
-			"date":            "2024-01-30",
-			"views":           23350,
-			"likes":           2097,
-			"comments":        245,
-			"shares":          312,
-			"engagement_rate": 12.15,
-			"revenue":         69.12,
-		},
-		{
-			"date":            "2024-01-29",
-			"views":           22180,
-			"likes":           1987,
-			"comments":        231,
-			"shares":          298,
-			"engagement_rate": 11.8,
-			"revenue":         65.23,
-		},
-		{
-			"date":            "2024-01-28",
-			"views":           20950,
-			"likes":           1856,
-			"comments":        218,
-			"shares":          276,
-			"engagement_rate": 11.2,
-			"revenue":         61.45,
-		},
+	var history interface{}
+	var err error
+	to := time.Now()
+	switch c.DefaultQuery("interval", "snapshot") {
+	case "snapshot":
+		history, err = h.analyticsService.GetVideoStatsHistory(c.Request.Context(), viewer, videoID, to.AddDate(0, 0, -days), to)
+	case "day":
+		loc := h.preferencesService.Location(c.Request.Context(), viewer.TenantID, viewer.ID)
+		local := to.In(loc)
+		from := time.Date(local.Year(), local.Month(), local.Day()-days+1, 0, 0, 0, 0, loc)
+		history, err = h.analyticsService.GetVideoStatsDaily(c.Request.Context(), viewer, videoID, from, to, loc)
+	default:
+		h.respondWithError(c, http.StatusBadRequest, "interval must be snapshot or day")
+		return
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrVideoNotFound):
+			h.respondWithError(c, http.StatusNotFound, "Video not found")
+		case errors.Is(err, models.ErrInvalidInput):
+			h.respondWithErr(c, http.StatusBadRequest, err)
+		default:
+			h.logger.Error("Failed to get video stats history", "error", err, "tenant_id", viewer.TenantID, "video_id", videoID)
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to get video stats history")
+		}
+		return
 	}
 
 	h.respondWithSuccess(c, "Video stats history retrieved successfully", gin.H{
 		"video_id": videoID,
 		"period":   days,
-		"history":  mockHistory,
+		"history":  history,
 	})
 }
 
@@ -326,80 +286,78 @@ func (h *StatsHandler) GetDashboardStats(c *gin.Context) {
 
 // GetPerformanceStats handles getting performance analytics
 // @Summary Get performance statistics
-// @Description Get detailed performance analytics and insights
+// @Description Get performance analytics per platform. Each platform's engagement rate, a fraction of views, comes with a benchmark: the typical rate on the platform, for the tenant's vertical when one is set, the difference with it and the rate as a percent of it.
 // @Tags stats
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param metric query string false "Performance metric" Enums(engagement,revenue,growth,reach) default(engagement)
 // @Param period query string false "Time period" Enums(7d,30d,90d,1y) default(30d)
-// @Success 200 {object} SuccessResponse
+// @Success 200 {object} services.PerformanceStats
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /api/v1/stats/performance [get]
 func (h *StatsHandler) GetPerformanceStats(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	from, to, ok := statsPeriod(c.DefaultQuery("period", "30d"))
+	if !ok {
+		h.respondWithError(c, http.StatusBadRequest, "period must be 7d, 30d, 90d or 1y")
+		return
+	}
+
+	stats, err := h.analyticsService.GetPerformanceStats(c.Request.Context(), tenantID, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get performance stats", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get performance stats")
+		return
+	}
+	h.respondWithSuccess(c, "Performance stats retrieved successfully", stats)
+}
+
+// GetTopPerforming handles ranking the tenant's videos by a metric
+// @Summary Get top performing videos
+// @Description Rank videos by a metric summed across platforms. Rankings come from summaries kept current on every stats write; refreshed_at, age_seconds and stale describe how current they are.
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param metric query string false "Ranking metric" Enums(views,likes,comments,shares,engagement_rate,revenue) default(views)
+// @Param limit query int false "Number of videos (max 100)" default(10)
+// @Success 200 {object} services.Leaderboard
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/stats/top [get]
+func (h *StatsHandler) GetTopPerforming(c *gin.Context) {
 	userID, tenantID, err := h.getUserFromContext(c)
 	if err != nil {
 		h.respondWithError(c, http.StatusUnauthorized, "User not found")
 		return
 	}
 
-	metric := c.DefaultQuery("metric", "engagement")
-	period := c.DefaultQuery("period", "30d")
+	metric := c.DefaultQuery("metric", "views")
+	limit, _ := strconv.Atoi(c.Query("limit"))
 
-	// TODO: Implement actual performance stats logic
-	h.logger.Info("Getting performance stats",
+	h.logger.Info("Getting top performing videos",
 		"user_id", userID,
 		"tenant_id", tenantID,
 		"metric", metric,
-		"period", period)
+		"limit", limit)
 
-	// Mock performance data based on metric
-	var mockData gin.H
-
-	switch metric {
-	case "engagement":
-		mockData = gin.H{
-			"metric":                  "engagement",
-			"period":                  period,
-			"average_engagement_rate": 8.7,
-			"best_performing_content": []gin.H{
-				{"type": "educational", "avg_engagement": 12.3},
-				{"type": "entertainment", "avg_engagement": 9.8},
-				{"type": "promotional", "avg_engagement": 6.2},
-			},
-			"engagement_by_platform": []gin.H{
-				{"platform": "tiktok", "avg_engagement": 15.2},
-				{"platform": "instagram", "avg_engagement": 10.8},
-				{"platform": "youtube", "avg_engagement": 7.3},
-				{"platform": "facebook", "avg_engagement": 5.1},
-			},
-		}
-	case "revenue":
-		mockData = gin.H{
-			"metric":           "revenue",
-			"period":           period,
-			"total_revenue":    8472.35,
-			"revenue_per_view": 0.00297,
-			"top_earning_videos": []gin.H{
-				{"id": "video-123", "title": "High Earner", "revenue": 234.56},
-				{"id": "video-456", "title": "Good Earner", "revenue": 189.23},
-			},
-			"revenue_by_platform": []gin.H{
-				{"platform": "youtube", "revenue": 4236.18, "percentage": 50.0},
-				{"platform": "tiktok", "revenue": 2541.71, "percentage": 30.0},
-				{"platform": "instagram", "revenue": 1271.85, "percentage": 15.0},
-				{"platform": "facebook", "revenue": 422.61, "percentage": 5.0},
-			},
-		}
-	default:
-		mockData = gin.H{
-			"metric":  metric,
-			"period":  period,
-			"message": "Performance data for " + metric,
+	board, err := h.analyticsService.GetTopPerforming(c.Request.Context(), tenantID, metric, limit)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidInput) {
+			h.respondWithErr(c, http.StatusBadRequest, err)
+			return
 		}
+		h.logger.Error("Failed to get top performing videos", "error", err, "tenant_id", tenantID, "metric", metric)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get top performing videos")
+		return
 	}
 
-	h.respondWithSuccess(c, "Performance stats retrieved successfully", mockData)
+	h.respondWithSuccess(c, "Top performing videos retrieved successfully", board)
 }
 
 // SyncStats handles manual synchronization of statistics
@@ -526,87 +484,165 @@ func (h *StatsHandler) GetROIAnalytics(c *gin.Context) {
 
 // GetEngagementAnalytics handles getting detailed engagement analytics
 // @Summary Get engagement analytics
-// @Description Get comprehensive engagement metrics and audience interaction data
+// @Description Get engagement totals overall and per platform. Engagement rates, fractions of views, come with a benchmark: the typical rate on the platform, for the tenant's vertical when one is set. The overall benchmark weighs each platform's by its views.
 // @Tags stats
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param video_id query string false "Specific video ID for engagement analysis"
-// @Param platform query string false "Filter by platform"
 // @Param period query string false "Time period" Enums(7d,30d,90d,1y) default(30d)
-// @Success 200 {object} SuccessResponse
+// @Success 200 {object} services.EngagementAnalytics
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /api/v1/stats/engagement [get]
 func (h *StatsHandler) GetEngagementAnalytics(c *gin.Context) {
-	userID, tenantID, err := h.getUserFromContext(c)
+	_, tenantID, err := h.getUserFromContext(c)
 	if err != nil {
 		h.respondWithError(c, http.StatusUnauthorized, "User not found")
 		return
 	}
 
-	videoID := c.Query("video_id")
-	platform := c.Query("platform")
-	period := c.DefaultQuery("period", "30d")
+	from, to, ok := statsPeriod(c.DefaultQuery("period", "30d"))
+	if !ok {
+		h.respondWithError(c, http.StatusBadRequest, "period must be 7d, 30d, 90d or 1y")
+		return
+	}
 
-	// TODO: Implement actual engagement analytics logic
-	h.logger.Info("Getting engagement analytics",
-		"user_id", userID,
-		"tenant_id", tenantID,
-		"video_id", videoID,
-		"platform", platform,
-		"period", period)
+	analytics, err := h.analyticsService.GetEngagementAnalytics(c.Request.Context(), tenantID, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get engagement analytics", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get engagement analytics")
+		return
+	}
+	h.respondWithSuccess(c, "Engagement analytics retrieved successfully", analytics)
+}
 
-	// Mock engagement data
-	mockEngagement := gin.H{
-		"period":   period,
-		"platform": platform,
-		"summary": gin.H{
-			"average_engagement_rate":    12.4,
-			"total_engagements":          847392,
-			"engagement_growth":          15.7,
-			"top_engagement_platform":    "tiktok",
-			"subscriber_conversion_rate": 3.2,
-			"viral_content_count":        23,
-			"audience_retention_rate":    68.5,
-		},
-		"engagement_breakdown": gin.H{
-			"likes_percentage":    45.2,
-			"comments_percentage": 18.7,
-			"shares_percentage":   12.3,
-			"saves_percentage":    23.8,
-		},
-		"top_engaging_videos": []gin.H{
-			{
-				"video_id":              "video-789",
-				"title":                 "Viral Hit",
-				"engagement_rate":       28.4,
-				"total_engagements":     45829,
-				"virality_score":        9.2,
-				"subscriber_conversion": 5.8,
-				"watch_time_percentage": 85.3,
-			},
-			{
-				"video_id":              "video-321",
-				"title":                 "High Engagement",
-				"engagement_rate":       24.1,
-				"total_engagements":     38472,
-				"virality_score":        7.6,
-				"subscriber_conversion": 4.2,
-				"watch_time_percentage": 78.9,
-			},
-		},
-		"engagement_trends": []gin.H{
-			{"date": "2024-01-30", "engagement_rate": 12.4, "interactions": 15420},
-			{"date": "2024-01-29", "engagement_rate": 11.8, "interactions": 14230},
-			{"date": "2024-01-28", "engagement_rate": 11.2, "interactions": 13850},
-		},
-		"audience_insights": gin.H{
-			"peak_engagement_hours":   []string{"19:00-21:00", "12:00-14:00"},
-			"best_posting_days":       []string{"Tuesday", "Thursday", "Sunday"},
-			"audience_sentiment":      "positive",
-			"comment_sentiment_score": 7.8,
-		},
+// GetTrafficSources handles getting where the views of videos come from
+// @Summary Get traffic sources
+// @Description Split the views of the videos uploaded in the period by traffic source: search, suggested, external, browse or other. Platforms report sources over a video's whole life, so the period picks videos, not views. Views per source are estimated from each platform's shares; only platforms reporting traffic sources count. Returns the split overall, per platform and for the 10 most viewed videos.
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param period query string false "Upload period" Enums(7d,30d,90d,1y) default(30d)
+// @Success 200 {object} services.TrafficSourceAnalytics
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/stats/traffic-sources [get]
+func (h *StatsHandler) GetTrafficSources(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	from, to, ok := statsPeriod(c.DefaultQuery("period", "30d"))
+	if !ok {
+		h.respondWithError(c, http.StatusBadRequest, "period must be 7d, 30d, 90d or 1y")
+		return
 	}
 
-	h.respondWithSuccess(c, "Engagement analytics retrieved successfully", mockEngagement)
+	analytics, err := h.analyticsService.GetTrafficSources(c.Request.Context(), tenantID, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get traffic sources", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get traffic sources")
+		return
+	}
+	h.respondWithSuccess(c, "Traffic sources retrieved successfully", analytics)
+}
+
+// GetDeviceBreakdown handles getting the views of videos by device type
+// @Summary Get device breakdown
+// @Description Split the views of the videos uploaded in the period by device type: mobile, desktop, tablet, tv, console or other, most viewed first. Platforms report devices over a video's whole life, so the period picks videos, not views; only platforms reporting device types count.
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param period query string false "Upload period" Enums(7d,30d,90d,1y) default(30d)
+// @Param platform query string false "Only this platform"
+// @Success 200 {object} services.ViewsBreakdown
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/stats/devices [get]
+func (h *StatsHandler) GetDeviceBreakdown(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	filter, ok := h.breakdownFilter(c)
+	if !ok {
+		return
+	}
+
+	breakdown, err := h.analyticsService.GetDeviceBreakdown(c.Request.Context(), tenantID, filter)
+	if err != nil {
+		h.logger.Error("Failed to get device breakdown", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get device breakdown")
+		return
+	}
+	h.respondWithSuccess(c, "Device breakdown retrieved successfully", breakdown)
+}
+
+// GetGeographyBreakdown handles getting the views of videos by country
+// @Summary Get geography breakdown
+// @Description Split the views of the videos uploaded in the period by viewer country (ISO 3166-1 alpha-2), most viewed first. Views per country are estimated from each platform's audience shares over a video's whole life, so the period picks videos, not views.
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param period query string false "Upload period" Enums(7d,30d,90d,1y) default(30d)
+// @Param platform query string false "Only this platform"
+// @Success 200 {object} services.ViewsBreakdown
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/stats/geography [get]
+func (h *StatsHandler) GetGeographyBreakdown(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	filter, ok := h.breakdownFilter(c)
+	if !ok {
+		return
+	}
+
+	breakdown, err := h.analyticsService.GetGeographyBreakdown(c.Request.Context(), tenantID, filter)
+	if err != nil {
+		h.logger.Error("Failed to get geography breakdown", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get geography breakdown")
+		return
+	}
+	h.respondWithSuccess(c, "Geography breakdown retrieved successfully", breakdown)
+}
+
+// breakdownFilter reads the period and platform query parameters, responding
+// with 400 when either is invalid
+func (h *StatsHandler) breakdownFilter(c *gin.Context) (models.BreakdownFilter, bool) {
+	from, to, ok := statsPeriod(c.DefaultQuery("period", "30d"))
+	if !ok {
+		h.respondWithError(c, http.StatusBadRequest, "period must be 7d, 30d, 90d or 1y")
+		return models.BreakdownFilter{}, false
+	}
+	platform := c.Query("platform")
+	if platform != "" && !models.Platform(platform).Valid() {
+		h.respondWithError(c, http.StatusBadRequest, "Unsupported platform")
+		return models.BreakdownFilter{}, false
+	}
+	return models.BreakdownFilter{Platform: platform, From: from, To: to}, true
+}
+
+// statsPeriod returns the range of a period ending now
+func statsPeriod(period string) (time.Time, time.Time, bool) {
+	to := time.Now()
+	switch period {
+	case "7d":
+		return to.AddDate(0, 0, -7), to, true
+	case "30d":
+		return to.AddDate(0, 0, -30), to, true
+	case "90d":
+		return to.AddDate(0, 0, -90), to, true
+	case "1y":
+		return to.AddDate(-1, 0, 0), to, true
+	}
+	return time.Time{}, time.Time{}, false
 }
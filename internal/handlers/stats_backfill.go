@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// StatsBackfillHandler handles the imports of past platform stats
+type StatsBackfillHandler struct {
+	*BaseHandler
+	backfillService services.StatsBackfillService
+}
+
+// NewStatsBackfillHandler creates a new stats backfill handler
+func NewStatsBackfillHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, backfillService services.StatsBackfillService) *StatsBackfillHandler {
+	return &StatsBackfillHandler{
+		BaseHandler:     NewBaseHandler(cfg, logger, db),
+		backfillService: backfillService,
+	}
+}
+
+// StartBackfill handles queueing a stats backfill
+// @Summary Backfill platform stats
+// @Description Import the daily stats of the tenant's videos on a platform for the days before their sync started, as far back as the platform reports. The import runs in the background, paced to the platform quota; follow it with the returned ID. Only YouTube reports past daily stats.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.StartStatsBackfillRequest true "Platform and workspace"
+// @Success 202 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/stats/backfills [post]
+func (h *StatsBackfillHandler) StartBackfill(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.StartStatsBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	backfill, err := h.backfillService.Start(c.Request.Context(), tenantID, userID, req.Platform, req.WorkspaceID)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidPlatform), errors.Is(err, models.ErrBackfillUnsupported):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, models.ErrNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Workspace not found")
+		return
+	case errors.Is(err, models.ErrBackfillInProgress):
+		h.respondWithErr(c, http.StatusConflict, err)
+		return
+	default:
+		h.logger.Error("Failed to start stats backfill", "error", err, "user_id", userID, "tenant_id", tenantID, "platform", req.Platform)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to start stats backfill")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Message: "Stats backfill queued",
+		Data:    backfill,
+	})
+}
+
+// ListBackfills handles listing the tenant's stats backfills
+// @Summary List stats backfills
+// @Description List the tenant's stats backfills newest first, with their progress
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/stats/backfills [get]
+func (h *StatsBackfillHandler) ListBackfills(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+	backfills, err := h.backfillService.List(c.Request.Context(), tenantID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list stats backfills", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list stats backfills")
+		return
+	}
+
+	h.respondWithSuccess(c, "Stats backfills retrieved successfully", backfills)
+}
+
+// GetBackfill handles getting a stats backfill
+// @Summary Get stats backfill
+// @Description Get the progress of a stats backfill: videos and days imported, platform requests made, and when it runs next
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Backfill ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/stats/backfills/{id} [get]
+func (h *StatsBackfillHandler) GetBackfill(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	backfill, err := h.backfillService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, models.ErrBackfillNotFound) {
+			h.respondWithError(c, http.StatusNotFound, "Stats backfill not found")
+			return
+		}
+		h.logger.Error("Failed to get stats backfill", "error", err, "tenant_id", tenantID, "backfill_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get stats backfill")
+		return
+	}
+
+	h.respondWithSuccess(c, "Stats backfill retrieved successfully", backfill)
+}
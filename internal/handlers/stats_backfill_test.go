@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubBackfillService backfills YouTube with the workspace "ws-1", which
+// already has a backfill running when busy is set
+type stubBackfillService struct {
+	services.StatsBackfillService
+	busy bool
+}
+
+func (s *stubBackfillService) Start(ctx context.Context, tenantID, userID, platform, workspaceID string) (*models.StatsBackfill, error) {
+	switch {
+	case platform != "youtube":
+		return nil, fmt.Errorf("%w: %s", models.ErrBackfillUnsupported, platform)
+	case workspaceID != "ws-1":
+		return nil, fmt.Errorf("failed to get workspace: %w", models.ErrNotFound)
+	case s.busy:
+		return nil, models.ErrBackfillInProgress
+	}
+	return &models.StatsBackfill{ID: "backfill-1", TenantID: tenantID, Platform: platform, Status: models.BackfillPending}, nil
+}
+
+func TestStatsBackfillHandler_StartBackfill(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	service := &stubBackfillService{}
+	handler := NewStatsBackfillHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, service)
+
+	post := func(body string) int {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			c.Set("user_id", "admin-1")
+			c.Set("tenant_id", "acme")
+			c.Next()
+		})
+		r.POST("/stats/backfills", handler.StartBackfill)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/stats/backfills", strings.NewReader(body)))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusAccepted, post(`{"platform":"youtube","workspace_id":"ws-1"}`))
+	assert.Equal(t, http.StatusBadRequest, post(`{"platform":"youtube"}`))
+	assert.Equal(t, http.StatusBadRequest, post(`{"platform":"tiktok","workspace_id":"ws-1"}`))
+	assert.Equal(t, http.StatusNotFound, post(`{"platform":"youtube","workspace_id":"ws-2"}`))
+
+	service.busy = true
+	assert.Equal(t, http.StatusConflict, post(`{"platform":"youtube","workspace_id":"ws-1"}`))
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+)
+
+// Stats export formats
+const (
+	exportFormatJSON = "json"
+	exportFormatCSV  = "csv"
+
+	// exportFlushRows is how many rows are written between flushes; each flush
+	// sends a chunk, and a slow client blocks the write and so the cursor
+	exportFlushRows = 500
+
+	// exportChunkTimeout replaces the server write timeout during an export:
+	// each chunk must be accepted within it, so a long export keeps going while
+	// a stalled client is still disconnected
+	exportChunkTimeout = 30 * time.Second
+
+	// exportStatusTrailer reports whether the export finished, since the
+	// status code is already sent when a mid-stream error happens
+	exportStatusTrailer = "X-Export-Status"
+)
+
+// exportCSVHeader lists the exported stats columns in order
+var exportCSVHeader = []string{
+	"id", "video_id", "platform", "external_id", "views", "likes", "dislikes", "comments", "shares",
+	"watch_time", "engagement_rate", "revenue", "impressions", "last_sync_at",
+}
+
+// statsRowWriter encodes one export format
+type statsRowWriter interface {
+	begin() error
+	write(stats *models.VideoStats) error
+	end() error
+	flush()
+}
+
+// ExportVideoStats handles streaming every stats row of the videos the user sees
+// @Summary Export video statistics
+// @Description Stream every stats row of the videos the user sees as a JSON array or CSV using chunked transfer encoding. The X-Export-Status trailer is "complete" when every row was sent and "error" when the stream stopped early.
+// @Tags stats
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param format query string false "Export format" Enums(json, csv) default(json)
+// @Param platform query string false "Filter by platform"
+// @Success 200 {array} models.VideoStats
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/stats/export [get]
+func (h *StatsHandler) ExportVideoStats(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+	userID, tenantID := viewer.ID, viewer.TenantID
+
+	format := c.DefaultQuery("format", exportFormatJSON)
+	platform := c.Query("platform")
+
+	var w statsRowWriter
+	switch format {
+	case exportFormatJSON:
+		w = &jsonStatsWriter{w: c.Writer}
+		c.Header("Content-Type", "application/json; charset=utf-8")
+	case exportFormatCSV:
+		w = &csvStatsWriter{w: csv.NewWriter(c.Writer), flusher: c.Writer}
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+	default:
+		h.respondWithError(c, http.StatusBadRequest, i18n.M("Unsupported export format %q", format).In(c.GetString("locale")))
+		return
+	}
+
+	h.logger.Info("Exporting video stats",
+		"user_id", userID,
+		"tenant_id", tenantID,
+		"format", format,
+		"platform", platform)
+
+	filename := fmt.Sprintf("video-stats-%s.%s", time.Now().UTC().Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Trailer", exportStatusTrailer)
+	c.Status(http.StatusOK)
+
+	rc := http.NewResponseController(c.Writer)
+	extendDeadline := func() {
+		// Not every writer supports deadlines (e.g. test recorders)
+		_ = rc.SetWriteDeadline(time.Now().Add(exportChunkTimeout))
+	}
+	extendDeadline()
+
+	rows := 0
+	err := w.begin()
+	if err == nil {
+		err = h.analyticsService.ExportVideoStats(c.Request.Context(), viewer, platform, func(stats *models.VideoStats) error {
+			if err := w.write(stats); err != nil {
+				return err
+			}
+			rows++
+			if rows%exportFlushRows == 0 {
+				w.flush()
+				extendDeadline()
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = w.end()
+	}
+
+	if err != nil {
+		// A truncated JSON array no longer parses; CSV readers rely on the trailer
+		h.logger.Error("Video stats export failed", "error", err, "tenant_id", tenantID, "rows", rows)
+		c.Writer.Header().Set(exportStatusTrailer, "error")
+		return
+	}
+	c.Writer.Header().Set(exportStatusTrailer, "complete")
+}
+
+// jsonStatsWriter writes rows as the elements of one JSON array
+type jsonStatsWriter struct {
+	w    gin.ResponseWriter
+	rows int
+}
+
+func (j *jsonStatsWriter) begin() error {
+	_, err := io.WriteString(j.w, "[")
+	return err
+}
+
+func (j *jsonStatsWriter) write(stats *models.VideoStats) error {
+	if j.rows > 0 {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+	j.rows++
+	return json.NewEncoder(j.w).Encode(stats)
+}
+
+func (j *jsonStatsWriter) end() error {
+	_, err := io.WriteString(j.w, "]\n")
+	return err
+}
+
+func (j *jsonStatsWriter) flush() {
+	j.w.Flush()
+}
+
+// csvStatsWriter writes a header line then one line per row
+type csvStatsWriter struct {
+	w       *csv.Writer
+	flusher http.Flusher
+}
+
+func (c *csvStatsWriter) begin() error {
+	return c.w.Write(exportCSVHeader)
+}
+
+func (c *csvStatsWriter) write(stats *models.VideoStats) error {
+	return c.w.Write([]string{
+		stats.ID,
+		stats.VideoID,
+		stats.Platform,
+		stats.ExternalID,
+		strconv.FormatInt(stats.Views, 10),
+		strconv.FormatInt(stats.Likes, 10),
+		strconv.FormatInt(stats.Dislikes, 10),
+		strconv.FormatInt(stats.Comments, 10),
+		strconv.FormatInt(stats.Shares, 10),
+		strconv.FormatInt(stats.WatchTime, 10),
+		strconv.FormatFloat(stats.Engagement, 'f', -1, 64),
+		strconv.FormatFloat(stats.Revenue, 'f', 2, 64),
+		strconv.FormatInt(stats.Impressions, 10),
+		stats.LastSyncAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (c *csvStatsWriter) end() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvStatsWriter) flush() {
+	c.w.Flush()
+	c.flusher.Flush()
+}
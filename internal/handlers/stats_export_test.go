@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubExportService streams a fixed set of rows, optionally failing after them
+type stubExportService struct {
+	services.AnalyticsService
+	rows     []*models.VideoStats
+	failWith error
+	tenantID string
+	platform string
+}
+
+func (s *stubExportService) ExportVideoStats(ctx context.Context, viewer *models.User, platform string, fn func(*models.VideoStats) error) error {
+	s.tenantID, s.platform = viewer.TenantID, platform
+	for _, row := range s.rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return s.failWith
+}
+
+func setupExportTestRouter(svc *stubExportService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewStatsHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, svc, nil)
+
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/stats/export", handler.ExportVideoStats)
+	return r
+}
+
+func exportRows(n int) []*models.VideoStats {
+	synced := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rows := make([]*models.VideoStats, n)
+	for i := range rows {
+		rows[i] = &models.VideoStats{
+			ID:         fmt.Sprintf("stats-%d", i),
+			VideoID:    "video-1",
+			Platform:   "youtube",
+			Views:      int64(1000 + i),
+			Likes:      40,
+			Revenue:    1.5,
+			LastSyncAt: synced,
+		}
+	}
+	return rows
+}
+
+func TestStatsHandler_ExportVideoStatsJSON(t *testing.T) {
+	svc := &stubExportService{rows: exportRows(3)}
+	r := setupExportTestRouter(svc)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/stats/export?platform=youtube", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "video-stats-")
+	assert.Equal(t, "complete", w.Result().Trailer.Get("X-Export-Status"))
+	assert.Equal(t, "test-tenant-123", svc.tenantID)
+	assert.Equal(t, "youtube", svc.platform)
+
+	var rows []models.VideoStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rows))
+	require.Len(t, rows, 3)
+	assert.Equal(t, int64(1002), rows[2].Views)
+}
+
+func TestStatsHandler_ExportVideoStatsCSV(t *testing.T) {
+	svc := &stubExportService{rows: exportRows(exportFlushRows + 1)}
+	r := setupExportTestRouter(svc)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/stats/export?format=csv", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.True(t, w.Flushed, "large exports are sent in chunks")
+	assert.Equal(t, "complete", w.Result().Trailer.Get("X-Export-Status"))
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, exportFlushRows+2, "header plus one line per row")
+	assert.Equal(t, exportCSVHeader, records[0])
+	assert.Equal(t, []string{"stats-0", "video-1", "youtube", "", "1000", "40", "0", "0", "0", "0", "0", "1.50", "0", "2026-03-01T12:00:00Z"}, records[1])
+}
+
+func TestStatsHandler_ExportVideoStatsErrors(t *testing.T) {
+	t.Run("unsupported format", func(t *testing.T) {
+		r := setupExportTestRouter(&stubExportService{})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/stats/export?format=xml", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("failure mid-stream", func(t *testing.T) {
+		r := setupExportTestRouter(&stubExportService{rows: exportRows(2), failWith: errors.New("cursor closed")})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/stats/export", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "the status was sent with the first rows")
+		assert.Equal(t, "error", w.Result().Trailer.Get("X-Export-Status"))
+		var rows []models.VideoStats
+		assert.Error(t, json.Unmarshal(w.Body.Bytes(), &rows), "a truncated export does not parse")
+	})
+}
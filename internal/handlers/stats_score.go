@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// StatsScoreHandler handles the recomputation of the engagement scores
+type StatsScoreHandler struct {
+	*BaseHandler
+	scoreService services.StatsScoreService
+}
+
+// NewStatsScoreHandler creates a new stats score handler
+func NewStatsScoreHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, scoreService services.StatsScoreService) *StatsScoreHandler {
+	return &StatsScoreHandler{
+		BaseHandler:  NewBaseHandler(cfg, logger, db),
+		scoreService: scoreService,
+	}
+}
+
+// RecomputeScores handles recomputing the tenant's scores
+// @Summary Recompute engagement scores
+// @Description Recompute the engagement rate and score of every stats row of the tenant now instead of waiting for the nightly run, for instance after a stats import
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/stats/scores/recompute [post]
+func (h *StatsScoreHandler) RecomputeScores(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	report, err := h.scoreService.Recompute(c.Request.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to recompute stats scores", "error", err, "user_id", userID, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to recompute scores")
+		return
+	}
+	h.respondWithSuccess(c, "Scores recomputed", report)
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubListService records the filter of the last listing
+type stubListService struct {
+	services.AnalyticsService
+	filter models.StatsListFilter
+}
+
+func (s *stubListService) ListVideoStats(ctx context.Context, viewer *models.User, filter models.StatsListFilter, limit, offset int) ([]*models.VideoStats, int64, error) {
+	s.filter = filter
+	if filter.Sort != "" && filter.Sort != "views" {
+		return nil, 0, i18n.Errorf(models.ErrInvalidInput, "unknown sort %q", filter.Sort)
+	}
+	score := 87.5
+	return []*models.VideoStats{{ID: "stats-1", VideoID: "video-1", Platform: "youtube", Score: &score}}, 41, nil
+}
+
+func TestStatsHandler_GetVideosStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	svc := &stubListService{}
+	handler := NewStatsHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, svc, nil)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/stats/videos", handler.GetVideosStats)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/stats/videos?platform=youtube&min_score=50&sort=views&order=asc")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp PaginatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(41), resp.Total)
+	assert.Equal(t, 3, resp.TotalPages)
+	assert.Equal(t, "youtube", svc.filter.Platform)
+	require.NotNil(t, svc.filter.MinScore)
+	assert.Equal(t, 50.0, *svc.filter.MinScore)
+	assert.Nil(t, svc.filter.MaxScore)
+	assert.False(t, svc.filter.Desc)
+
+	require.Equal(t, http.StatusOK, get("/stats/videos").Code)
+	assert.True(t, svc.filter.Desc, "listings are descending by default")
+
+	assert.Equal(t, http.StatusBadRequest, get("/stats/videos?max_score=high").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/stats/videos?order=up").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/stats/videos?sort=title").Code)
+}
+
+// stubEngagementService records the range of the last engagement request
+type stubEngagementService struct {
+	services.AnalyticsService
+	from, to time.Time
+}
+
+func (s *stubEngagementService) GetEngagementAnalytics(ctx context.Context, tenantID string, from, to time.Time) (*services.EngagementAnalytics, error) {
+	s.from, s.to = from, to
+	return &services.EngagementAnalytics{
+		EngagementRate: 0.05,
+		Benchmark:      &services.BenchmarkComparison{EngagementRate: 0.04, Difference: 0.01, Index: 125},
+	}, nil
+}
+
+func TestStatsHandler_GetEngagementAnalytics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	svc := &stubEngagementService{}
+	handler := NewStatsHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, svc, nil)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/stats/engagement", handler.GetEngagementAnalytics)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stats/engagement?period=7d", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, svc.to.AddDate(0, 0, -7), svc.from)
+	var resp struct {
+		Data struct {
+			Benchmark services.BenchmarkComparison `json:"benchmark"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 125.0, resp.Data.Benchmark.Index)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stats/engagement?period=2w", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// stubTrafficService records the range of the last traffic source request
+type stubTrafficService struct {
+	services.AnalyticsService
+	from, to time.Time
+}
+
+func (s *stubTrafficService) GetTrafficSources(ctx context.Context, tenantID string, from, to time.Time) (*services.TrafficSourceAnalytics, error) {
+	s.from, s.to = from, to
+	return &services.TrafficSourceAnalytics{
+		Overall: &models.TrafficSourceViews{Views: 100, Sources: map[string]int64{"search": 100}, Shares: models.TrafficSources{"search": 1}},
+	}, nil
+}
+
+func TestStatsHandler_GetTrafficSources(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	svc := &stubTrafficService{}
+	handler := NewStatsHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, svc, nil)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/stats/traffic-sources", handler.GetTrafficSources)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stats/traffic-sources", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, svc.to.AddDate(0, 0, -30), svc.from, "30 days by default")
+	var resp struct {
+		Data struct {
+			Overall models.TrafficSourceViews `json:"overall"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(100), resp.Data.Overall.Sources["search"])
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stats/traffic-sources?period=2w", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// stubBreakdownService records the filter of the last breakdown asked for
+type stubBreakdownService struct {
+	services.AnalyticsService
+	filter models.BreakdownFilter
+}
+
+func (s *stubBreakdownService) GetDeviceBreakdown(ctx context.Context, tenantID string, filter models.BreakdownFilter) (*services.ViewsBreakdown, error) {
+	s.filter = filter
+	return &services.ViewsBreakdown{Platform: filter.Platform, Views: 10, Breakdown: []*models.BreakdownViews{{Key: models.DeviceMobile, Views: 10, Share: 1}}}, nil
+}
+
+func (s *stubBreakdownService) GetGeographyBreakdown(ctx context.Context, tenantID string, filter models.BreakdownFilter) (*services.ViewsBreakdown, error) {
+	s.filter = filter
+	return &services.ViewsBreakdown{Platform: filter.Platform, Breakdown: []*models.BreakdownViews{}}, nil
+}
+
+func TestStatsHandler_GetBreakdowns(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	svc := &stubBreakdownService{}
+	handler := NewStatsHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, svc, nil)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/stats/devices", handler.GetDeviceBreakdown)
+	r.GET("/stats/geography", handler.GetGeographyBreakdown)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := serve("/stats/devices")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, svc.filter.Platform)
+	assert.Equal(t, svc.filter.To.AddDate(0, 0, -30), svc.filter.From, "30 days by default")
+	var resp struct {
+		Data services.ViewsBreakdown `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.Breakdown, 1)
+	assert.Equal(t, models.DeviceMobile, resp.Data.Breakdown[0].Key)
+
+	require.Equal(t, http.StatusOK, serve("/stats/geography?period=90d&platform=youtube").Code)
+	assert.Equal(t, "youtube", svc.filter.Platform)
+	assert.Equal(t, svc.filter.To.AddDate(0, 0, -90), svc.filter.From)
+
+	assert.Equal(t, http.StatusBadRequest, serve("/stats/geography?period=2w").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("/stats/devices?platform=myspace").Code)
+}
+
+// stubVideoStatsService serves the stats of video-1 only
+type stubVideoStatsService struct {
+	services.AnalyticsService
+}
+
+func (s *stubVideoStatsService) GetVideoStats(ctx context.Context, viewer *models.User, videoID string) (*models.VideoStats, error) {
+	if videoID != "video-1" {
+		return nil, fmt.Errorf("failed to get video: %w", models.ErrVideoNotFound)
+	}
+	return &models.VideoStats{VideoID: videoID, Platform: "aggregate", Views: 4000, Demographics: &models.Demographics{
+		Gender: map[string]float64{models.GenderFemale: 0.5, models.GenderMale: 0.5},
+	}}, nil
+}
+
+func TestStatsHandler_GetVideoStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewStatsHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubVideoStatsService{}, nil)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/stats/videos/:id", handler.GetVideoStats)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stats/videos/video-1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data models.VideoStats `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(4000), resp.Data.Views)
+	require.NotNil(t, resp.Data.Demographics)
+	assert.Equal(t, 0.5, resp.Data.Demographics.Gender[models.GenderFemale])
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stats/videos/video-2", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
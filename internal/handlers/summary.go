@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// SummaryHandler handles the AI summaries of videos and the campaign
+// reports built from them
+type SummaryHandler struct {
+	*BaseHandler
+	summaryService services.SummaryService
+}
+
+// NewSummaryHandler creates a new summary handler
+func NewSummaryHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, summaryService services.SummaryService) *SummaryHandler {
+	return &SummaryHandler{
+		BaseHandler:    NewBaseHandler(cfg, logger, db),
+		summaryService: summaryService,
+	}
+}
+
+// SummarizeVideo handles summarizing a video
+// @Summary Summarize video
+// @Description Summarize the video's transcript as a sentence, a paragraph and a few paragraphs, with its key takeaways. Summaries are kept per transcript and only generated again once the transcript changed, or on refresh. With prefill_description, the paragraph becomes the description of a video without one.
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param request body models.SummarizeRequest false "Options"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/summarize [post]
+func (h *SummaryHandler) SummarizeVideo(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	var req models.SummarizeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+	}
+
+	result, err := h.summaryService.Summarize(c.Request.Context(), viewer, c.Param("id"), &req)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+		return
+	case errors.Is(err, models.ErrConflict):
+		h.respondWithErr(c, http.StatusConflict, err)
+		return
+	default:
+		h.logger.Error("Failed to summarize video", "error", err, "tenant_id", viewer.TenantID, "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to summarize video")
+		return
+	}
+
+	h.respondWithSuccess(c, "Video summarized successfully", result)
+}
+
+// GetCampaignReport handles getting the report of a campaign
+// @Summary Get campaign report
+// @Description Get the campaign's latest videos with their summaries, in the campaign's language when summarized in it. Videos not summarized yet are listed without one.
+// @Tags campaigns
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/campaigns/{id}/report [get]
+func (h *SummaryHandler) GetCampaignReport(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	report, err := h.summaryService.CampaignReport(c.Request.Context(), tenantID, c.Param("id"))
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrCampaignNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Campaign not found")
+		return
+	default:
+		h.logger.Error("Failed to get campaign report", "error", err, "tenant_id", tenantID, "campaign_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get campaign report")
+		return
+	}
+
+	h.respondWithSuccess(c, "Campaign report retrieved successfully", report)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSummaryService summarizes "video-1" and reports on "campaign-1"
+type stubSummaryService struct {
+	services.SummaryService
+	req *models.SummarizeRequest
+}
+
+func (s *stubSummaryService) Summarize(ctx context.Context, viewer *models.User, videoID string, req *models.SummarizeRequest) (*services.SummaryResult, error) {
+	s.req = req
+	switch videoID {
+	case "video-1":
+		return &services.SummaryResult{Summary: &models.VideoSummary{VideoID: videoID, Short: "A haunted house."}, Cached: true}, nil
+	case "video-2":
+		return nil, i18n.Errorf(models.ErrConflict, "the video needs a transcript to be summarized")
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+func (s *stubSummaryService) CampaignReport(ctx context.Context, tenantID, campaignID string) (*services.CampaignReport, error) {
+	if campaignID != "campaign-1" {
+		return nil, models.ErrCampaignNotFound
+	}
+	return &services.CampaignReport{CampaignID: campaignID, Videos: []*services.CampaignReportVideo{{VideoID: "video-1"}}}, nil
+}
+
+func TestSummaryHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	service := &stubSummaryService{}
+	handler := NewSummaryHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, service)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/videos/:id/summarize", handler.SummarizeVideo)
+	r.GET("/campaigns/:id/report", handler.GetCampaignReport)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := send("POST", "/videos/video-1/summarize", "")
+	assert.Equal(t, http.StatusOK, w.Code, "options are optional")
+	assert.Contains(t, w.Body.String(), `"cached":true`)
+	w = send("POST", "/videos/video-1/summarize", `{"language":"fr","prefill_description":true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, &models.SummarizeRequest{Language: "fr", PrefillDescription: true}, service.req)
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/videos/video-1/summarize", `{"language":"not-a-language-code"}`).Code)
+	assert.Equal(t, http.StatusConflict, send("POST", "/videos/video-2/summarize", "").Code)
+	assert.Equal(t, http.StatusNotFound, send("POST", "/videos/video-3/summarize", "").Code)
+
+	w = send("GET", "/campaigns/campaign-1/report", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"video_id":"video-1"`)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/campaigns/campaign-2/report", "").Code)
+}
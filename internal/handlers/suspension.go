@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// SuspensionHandler handles suspending and reactivating users and tenants
+type SuspensionHandler struct {
+	*BaseHandler
+	suspensionService services.SuspensionService
+}
+
+// NewSuspensionHandler creates a new suspension handler
+func NewSuspensionHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, suspensionService services.SuspensionService) *SuspensionHandler {
+	return &SuspensionHandler{
+		BaseHandler:       NewBaseHandler(cfg, logger, db),
+		suspensionService: suspensionService,
+	}
+}
+
+// SuspendUser handles suspending a user
+// @Summary Suspend a user
+// @Description Suspend a user of the admin's tenant. The user's tokens are refused from then on, within the identity cache period on other replicas. The reason is recorded in the audit log.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body models.SuspensionRequest true "Reason"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/users/{id}/suspend [post]
+func (h *SuspensionHandler) SuspendUser(c *gin.Context) {
+	h.changeUser(c, h.suspensionService.SuspendUser, "User suspended", "Failed to suspend user")
+}
+
+// ReactivateUser handles reactivating a user
+// @Summary Reactivate a user
+// @Description Make a suspended or inactive user of the admin's tenant active again. The reason is recorded in the audit log.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body models.SuspensionRequest true "Reason"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/users/{id}/reactivate [post]
+func (h *SuspensionHandler) ReactivateUser(c *gin.Context) {
+	h.changeUser(c, h.suspensionService.ReactivateUser, "User reactivated", "Failed to reactivate user")
+}
+
+// SuspendTenant handles suspending a tenant
+// @Summary Suspend a tenant
+// @Description Suspend a tenant: the tokens of all its users are refused from then on. Support admins only. The reason is recorded in the tenant's audit log.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tenant ID"
+// @Param request body models.SuspensionRequest true "Reason"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/tenants/{id}/suspend [post]
+func (h *SuspensionHandler) SuspendTenant(c *gin.Context) {
+	h.changeTenant(c, h.suspensionService.SuspendTenant, "Tenant suspended", "Failed to suspend tenant")
+}
+
+// ReactivateTenant handles reactivating a tenant
+// @Summary Reactivate a tenant
+// @Description Make a suspended tenant active again. Support admins only. The reason is recorded in the tenant's audit log.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tenant ID"
+// @Param request body models.SuspensionRequest true "Reason"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/tenants/{id}/reactivate [post]
+func (h *SuspensionHandler) ReactivateTenant(c *gin.Context) {
+	h.changeTenant(c, h.suspensionService.ReactivateTenant, "Tenant reactivated", "Failed to reactivate tenant")
+}
+
+// bindSuspension reads the admin and the reason of a suspension request
+func (h *SuspensionHandler) bindSuspension(c *gin.Context) (*models.User, *models.SuspensionRequest, bool) {
+	user, exists := c.Get("user")
+	admin, ok := user.(*models.User)
+	if !exists || !ok {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return nil, nil, false
+	}
+
+	var req models.SuspensionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return nil, nil, false
+	}
+	return admin, &req, true
+}
+
+func (h *SuspensionHandler) changeUser(c *gin.Context, change func(ctx context.Context, admin *models.User, userID, reason, requestID string) (*models.User, error), message, failure string) {
+	admin, req, ok := h.bindSuspension(c)
+	if !ok {
+		return
+	}
+
+	user, err := change(c.Request.Context(), admin, c.Param("id"), req.Reason, c.GetString("request_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			h.respondWithError(c, http.StatusNotFound, "User not found")
+		default:
+			h.respondWithSuspensionError(c, err, failure)
+		}
+		return
+	}
+
+	h.respondWithSuccess(c, message, user)
+}
+
+func (h *SuspensionHandler) changeTenant(c *gin.Context, change func(ctx context.Context, admin *models.User, tenantID, reason, requestID string) (*models.Tenant, error), message, failure string) {
+	admin, req, ok := h.bindSuspension(c)
+	if !ok {
+		return
+	}
+
+	tenant, err := change(c.Request.Context(), admin, c.Param("id"), req.Reason, c.GetString("request_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrTenantNotFound):
+			h.respondWithError(c, http.StatusNotFound, "Tenant not found")
+		default:
+			h.respondWithSuspensionError(c, err, failure)
+		}
+		return
+	}
+
+	h.respondWithSuccess(c, message, tenant)
+}
+
+func (h *SuspensionHandler) respondWithSuspensionError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrForbidden):
+		h.respondWithErr(c, http.StatusForbidden, err)
+	case errors.Is(err, models.ErrConflict):
+		h.respondWithErr(c, http.StatusConflict, err)
+	default:
+		h.logger.Error(failure, "error", err, "id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, failure)
+	}
+}
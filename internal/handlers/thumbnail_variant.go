@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// ThumbnailVariantHandler handles the branded thumbnail variants of videos
+type ThumbnailVariantHandler struct {
+	*BaseHandler
+	compositorService services.CompositorService
+}
+
+// NewThumbnailVariantHandler creates a new thumbnail variant handler
+func NewThumbnailVariantHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, compositorService services.CompositorService) *ThumbnailVariantHandler {
+	return &ThumbnailVariantHandler{
+		BaseHandler:       NewBaseHandler(cfg, logger, db),
+		compositorService: compositorService,
+	}
+}
+
+// ListThumbnailVariants handles listing the thumbnail variants of a video
+// @Summary List thumbnail variants
+// @Description List the video's thumbnail variants, newest first, with their status, image and whether it is the selected one
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/thumbnails [get]
+func (h *ThumbnailVariantHandler) ListThumbnailVariants(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	variants, err := h.compositorService.List(c.Request.Context(), viewer, c.Param("id"))
+	if !h.handleThumbnailVariantError(c, err, "list thumbnail variants") {
+		return
+	}
+	h.respondWithSuccess(c, "Thumbnail variants retrieved successfully", variants)
+}
+
+// ComposeThumbnails handles composing thumbnail variants of a video
+// @Summary Compose thumbnails
+// @Description Lay a hook text, such as one suggested by the thumbnail magic brush, over a frame of the video in each layout with the tenant's brand. The worker renders the variants.
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param request body models.ComposeThumbnailsRequest true "Thumbnails"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/thumbnails [post]
+func (h *ThumbnailVariantHandler) ComposeThumbnails(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	var req models.ComposeThumbnailsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	variants, err := h.compositorService.Compose(c.Request.Context(), viewer, c.Param("id"), &req)
+	if !h.handleThumbnailVariantError(c, err, "compose thumbnails") {
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Message: "Thumbnails composed successfully",
+		Data:    variants,
+	})
+}
+
+// SelectThumbnailVariant handles making a variant the video's thumbnail
+// @Summary Select thumbnail variant
+// @Description Make a rendered variant the video's thumbnail
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param variant_id path string true "Variant ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/thumbnails/{variant_id}/select [post]
+func (h *ThumbnailVariantHandler) SelectThumbnailVariant(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	video, err := h.compositorService.Select(c.Request.Context(), viewer, c.Param("id"), c.Param("variant_id"))
+	if !h.handleThumbnailVariantError(c, err, "select thumbnail variant") {
+		return
+	}
+	h.respondWithSuccess(c, "Thumbnail selected successfully", video)
+}
+
+// handleThumbnailVariantError responds to a failed thumbnail variant
+// operation, reporting whether there was none
+func (h *ThumbnailVariantHandler) handleThumbnailVariantError(c *gin.Context, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	case errors.Is(err, models.ErrThumbnailVariantNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Thumbnail variant not found")
+	case errors.Is(err, models.ErrMediaNotProbed):
+		h.respondWithError(c, http.StatusConflict, "Video has not been processed yet")
+	case errors.Is(err, models.ErrConflict):
+		h.respondWithErr(c, http.StatusConflict, err)
+	default:
+		h.logger.Error("Failed to "+action, "error", err, "tenant_id", c.GetString("tenant_id"), "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to "+action)
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubCompositorService knows "video-1", whose "variant-1" is rendered and
+// "variant-2" is not
+type stubCompositorService struct {
+	services.CompositorService
+}
+
+func (s *stubCompositorService) Compose(ctx context.Context, viewer *models.User, videoID string, req *models.ComposeThumbnailsRequest) ([]*models.ThumbnailVariant, error) {
+	if videoID != "video-1" {
+		return nil, models.ErrVideoNotFound
+	}
+	if len(req.Text) > models.MaxThumbnailTextLength {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "thumbnail text must be between 1 and %d characters", models.MaxThumbnailTextLength)
+	}
+	return []*models.ThumbnailVariant{{ID: "variant-3", VideoID: videoID, Text: req.Text, CreatedBy: viewer.ID}}, nil
+}
+
+func (s *stubCompositorService) Select(ctx context.Context, viewer *models.User, videoID, variantID string) (*models.Video, error) {
+	switch variantID {
+	case "variant-1":
+		return &models.Video{ID: videoID, ThumbnailURL: "https://cdn/thumb.jpg"}, nil
+	case "variant-2":
+		return nil, i18n.Errorf(models.ErrConflict, "the thumbnail is not rendered yet")
+	}
+	return nil, models.ErrThumbnailVariantNotFound
+}
+
+type stubBrandService struct {
+	services.BrandService
+}
+
+func (s *stubBrandService) Put(ctx context.Context, tenantID, userID string, req *models.BrandProfileRequest) (*models.BrandProfile, error) {
+	if !strings.HasPrefix(req.TextColor, "#") {
+		return nil, models.ErrInvalidInput
+	}
+	return &models.BrandProfile{TenantID: tenantID, Font: req.Font, TextColor: req.TextColor, AccentColor: req.AccentColor, UpdatedBy: userID}, nil
+}
+
+func TestThumbnailVariantHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	cfg := &config.Config{Environment: "test"}
+	handler := NewThumbnailVariantHandler(cfg, logger.New("error", "test"), mockDB, &stubCompositorService{})
+	brand := NewBrandHandler(cfg, logger.New("error", "test"), mockDB, &stubBrandService{}, &stubNotificationService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/videos/:id/thumbnails", handler.ComposeThumbnails)
+	r.POST("/videos/:id/thumbnails/:variant_id/select", handler.SelectThumbnailVariant)
+	r.PUT("/brand-profile", brand.UpdateBrandProfile)
+	r.GET("/brand-profile/email-preview", brand.PreviewEmail)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/videos/video-1/thumbnails", `{"text":"Who did it?"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"created_by":"test-user-123"`)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/videos/video-1/thumbnails", `{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/videos/video-1/thumbnails", `{"text":"`+strings.Repeat("a", 41)+`"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/videos/video-2/thumbnails", `{"text":"Who did it?"}`).Code)
+
+	w = do("POST", "/videos/video-1/thumbnails/variant-1/select", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"thumbnail_url":"https://cdn/thumb.jpg"`)
+	assert.Equal(t, http.StatusConflict, do("POST", "/videos/video-1/thumbnails/variant-2/select", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/videos/video-1/thumbnails/variant-9/select", "").Code)
+
+	w = do("PUT", "/brand-profile", `{"font":"Impact","text_color":"#FFD700","accent_color":"#000000"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"updated_by":"test-user-123"`)
+	assert.Equal(t, http.StatusBadRequest, do("PUT", "/brand-profile", `{"font":"Impact","text_color":"gold","accent_color":"#000000"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("PUT", "/brand-profile", `{"font":"Impact"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("PUT", "/brand-profile", `{"font":"Impact","text_color":"#FFD700","accent_color":"#000000","logo_url":"logo.png"}`).Code)
+
+	w = do("GET", "/brand-profile/email-preview", "")
+	assert.Equal(t, http.StatusOK, w.Code, "alerts are previewed by default")
+	assert.Contains(t, w.Body.String(), `"subject":"Alert: 10K views"`)
+	assert.Equal(t, http.StatusBadRequest, do("GET", "/brand-profile/email-preview?template=newsletter", "").Code)
+}
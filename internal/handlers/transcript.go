@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// Transcript response formats
+const (
+	transcriptFormatJSON = "json"
+	transcriptFormatSRT  = "srt"
+	transcriptFormatText = "text"
+
+	mimeSubRip = "application/x-subrip"
+)
+
+// TranscriptHandler handles transcript-related requests
+type TranscriptHandler struct {
+	*BaseHandler
+	transcriptService services.TranscriptService
+}
+
+// NewTranscriptHandler creates a new transcript handler
+func NewTranscriptHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, transcriptService services.TranscriptService) *TranscriptHandler {
+	return &TranscriptHandler{
+		BaseHandler:       NewBaseHandler(cfg, logger, db),
+		transcriptService: transcriptService,
+	}
+}
+
+// TranscriptResponse represents a transcript with its decoded segments
+type TranscriptResponse struct {
+	*models.Transcript
+	Segments []models.TranscriptSegment `json:"segments"`
+}
+
+// GetTranscript handles retrieving a video transcript
+// @Summary Get video transcript
+// @Description Get the transcript of a video as JSON, SRT or plain text. The format is taken from the format query parameter, falling back to the Accept header.
+// @Tags videos
+// @Produce json
+// @Produce application/x-subrip
+// @Produce plain
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param language query string false "Transcript language (defaults to the first stored)"
+// @Param format query string false "Response format" Enums(json, srt, text)
+// @Success 200 {object} TranscriptResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/transcript [get]
+func (h *TranscriptHandler) GetTranscript(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	format := h.negotiateFormat(c)
+	if format == "" {
+		h.respondWithError(c, http.StatusBadRequest, "Unsupported transcript format (must be one of: json, srt, text)")
+		return
+	}
+
+	transcript, err := h.transcriptService.GetTranscript(c.Request.Context(), viewer, c.Param("id"), c.Query("language"))
+	if err != nil {
+		h.respondWithTranscriptError(c, err, "Failed to get transcript")
+		return
+	}
+
+	switch format {
+	case transcriptFormatSRT:
+		c.Data(http.StatusOK, mimeSubRip+"; charset=utf-8", []byte(transcript.ToSRT()))
+	case transcriptFormatText:
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(transcript.ToPlainText()))
+	default:
+		h.respondWithSuccess(c, "Transcript retrieved successfully", TranscriptResponse{
+			Transcript: transcript,
+			Segments:   transcript.GetSegments(),
+		})
+	}
+}
+
+// SaveTranscript handles storing a video transcript
+// @Summary Save video transcript
+// @Description Store the timed transcript of a video, replacing any existing transcript in the same language
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param request body models.SaveTranscriptRequest true "Transcript data"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/transcript [put]
+func (h *TranscriptHandler) SaveTranscript(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	var req models.SaveTranscriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	transcript, err := h.transcriptService.SaveTranscript(c.Request.Context(), viewer, c.Param("id"), &req)
+	if err != nil {
+		h.logger.Error("Failed to save transcript", "error", err, "tenant_id", viewer.TenantID, "video_id", c.Param("id"))
+		h.respondWithTranscriptError(c, err, "Failed to save transcript")
+		return
+	}
+
+	h.respondWithSuccess(c, "Transcript saved successfully", TranscriptResponse{
+		Transcript: transcript,
+		Segments:   transcript.GetSegments(),
+	})
+}
+
+// SearchTranscripts handles full-text search across transcripts
+// @Summary Search transcripts
+// @Description Full-text search across the transcripts of the videos the user sees
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search query"
+// @Param limit query int false "Number of items per page" default(20)
+// @Param offset query int false "Number of items to skip" default(0)
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/transcripts/search [get]
+func (h *TranscriptHandler) SearchTranscripts(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+
+	results, err := h.transcriptService.SearchTranscripts(c.Request.Context(), viewer, c.Query("q"), limit, offset)
+	if err != nil {
+		h.respondWithTranscriptError(c, err, "Failed to search transcripts")
+		return
+	}
+
+	h.respondWithPagination(c, results, int64(len(results)), offset/limit+1, limit)
+}
+
+// negotiateFormat picks the transcript format from the query string or Accept header
+func (h *TranscriptHandler) negotiateFormat(c *gin.Context) string {
+	if format := c.Query("format"); format != "" {
+		switch format {
+		case transcriptFormatJSON, transcriptFormatSRT, transcriptFormatText:
+			return format
+		default:
+			return ""
+		}
+	}
+
+	switch c.NegotiateFormat(gin.MIMEJSON, mimeSubRip, gin.MIMEPlain) {
+	case mimeSubRip:
+		return transcriptFormatSRT
+	case gin.MIMEPlain:
+		return transcriptFormatText
+	default:
+		return transcriptFormatJSON
+	}
+}
+
+// respondWithTranscriptError maps transcript service errors to HTTP responses
+func (h *TranscriptHandler) respondWithTranscriptError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, models.ErrTranscriptNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Transcript not found")
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	default:
+		h.respondWithError(c, http.StatusInternalServerError, message)
+	}
+}
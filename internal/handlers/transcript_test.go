@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubTranscriptService serves a single fixed transcript
+type stubTranscriptService struct {
+	transcript *models.Transcript
+}
+
+func (s *stubTranscriptService) SaveTranscript(ctx context.Context, viewer *models.User, videoID string, req *models.SaveTranscriptRequest) (*models.Transcript, error) {
+	return s.transcript, nil
+}
+
+func (s *stubTranscriptService) GetTranscript(ctx context.Context, viewer *models.User, videoID, language string) (*models.Transcript, error) {
+	if videoID != s.transcript.VideoID {
+		return nil, models.ErrTranscriptNotFound
+	}
+	return s.transcript, nil
+}
+
+func (s *stubTranscriptService) SearchTranscripts(ctx context.Context, viewer *models.User, query string, limit, offset int) ([]*models.TranscriptSearchResult, error) {
+	return nil, nil
+}
+
+func setupTranscriptTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	transcript := &models.Transcript{ID: "tr-1", VideoID: "video-1", Language: "en"}
+	require.NoError(t, transcript.SetSegments([]models.TranscriptSegment{
+		{Start: 0, End: 1.5, Text: "Hello there."},
+		{Start: 1.5, End: 3.25, Text: "Welcome back."},
+	}))
+
+	var mockDB *db.DB
+	handler := NewTranscriptHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubTranscriptService{transcript: transcript})
+
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/videos/:id/transcript", handler.GetTranscript)
+	return r
+}
+
+func TestTranscriptHandler_GetTranscript(t *testing.T) {
+	r := setupTranscriptTestRouter(t)
+
+	tests := []struct {
+		name            string
+		path            string
+		accept          string
+		expectedStatus  int
+		expectedType    string
+		expectedContain string
+	}{
+		{
+			name:            "default json",
+			path:            "/videos/video-1/transcript",
+			expectedStatus:  http.StatusOK,
+			expectedType:    gin.MIMEJSON,
+			expectedContain: `"segments":[{"start":0,"end":1.5,"text":"Hello there."}`,
+		},
+		{
+			name:            "srt via query",
+			path:            "/videos/video-1/transcript?format=srt",
+			expectedStatus:  http.StatusOK,
+			expectedType:    mimeSubRip,
+			expectedContain: "2\n00:00:01,500 --> 00:00:03,250\nWelcome back.\n",
+		},
+		{
+			name:            "plain text via accept header",
+			path:            "/videos/video-1/transcript",
+			accept:          "text/plain",
+			expectedStatus:  http.StatusOK,
+			expectedType:    gin.MIMEPlain,
+			expectedContain: "Hello there.\nWelcome back.\n",
+		},
+		{
+			name:           "unsupported format",
+			path:           "/videos/video-1/transcript?format=vtt",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing transcript",
+			path:           "/videos/video-2/transcript",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.path, nil)
+			require.NoError(t, err)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedType != "" {
+				assert.Contains(t, w.Header().Get("Content-Type"), tt.expectedType)
+			}
+			if tt.expectedContain != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedContain)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// TransferHandler handles handing videos over to other tenants
+type TransferHandler struct {
+	*BaseHandler
+	transferService services.TransferService
+}
+
+// NewTransferHandler creates a new transfer handler
+func NewTransferHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, transferService services.TransferService) *TransferHandler {
+	return &TransferHandler{
+		BaseHandler:     NewBaseHandler(cfg, logger, db),
+		transferService: transferService,
+	}
+}
+
+// RequestTransfer handles offering a video to another tenant
+// @Summary Transfer a video
+// @Description Offer a video to another tenant, such as an agency's client. Once the recipient accepts, the video and its file are copied into its tenant, with the platform stats when include_stats is set; a move also deletes the sender's video. The recipient has 14 days to answer.
+// @Tags transfers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param request body models.CreateTransferRequest true "Transfer request"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/transfers [post]
+func (h *TransferHandler) RequestTransfer(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.CreateTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	transfer, err := h.transferService.Request(c.Request.Context(), tenantID, userID, c.Param("id"), &req)
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+		return
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+		return
+	case errors.Is(err, models.ErrTenantNotFound), errors.Is(err, models.ErrTenantInactive):
+		h.respondWithError(c, http.StatusBadRequest, "The target tenant cannot receive videos")
+		return
+	case errors.Is(err, models.ErrVideoArchived), errors.Is(err, models.ErrVideoProcessing), errors.Is(err, models.ErrTransferInProgress):
+		h.respondWithErr(c, http.StatusConflict, err)
+		return
+	default:
+		h.logger.Error("Failed to request video transfer", "error", err, "tenant_id", tenantID, "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to request video transfer")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Message: "Video transfer requested successfully",
+		Data:    transfer,
+	})
+}
+
+// ListTransfers handles listing the tenant's transfers
+// @Summary List video transfers
+// @Description List the transfers sent to the tenant, or sent by it, newest first
+// @Tags transfers
+// @Produce json
+// @Security BearerAuth
+// @Param direction query string false "incoming or outgoing" default(incoming)
+// @Param status query string false "pending, completed, declined, cancelled or expired"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/transfers [get]
+func (h *TransferHandler) ListTransfers(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var incoming bool
+	switch c.DefaultQuery("direction", "incoming") {
+	case "incoming":
+		incoming = true
+	case "outgoing":
+	default:
+		h.respondWithError(c, http.StatusBadRequest, "direction must be incoming or outgoing")
+		return
+	}
+
+	limit, offset := h.getPaginationParams(c)
+	transfers, err := h.transferService.List(c.Request.Context(), tenantID, incoming, models.TransferStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list video transfers", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list video transfers")
+		return
+	}
+
+	h.respondWithSuccess(c, "Video transfers retrieved successfully", transfers)
+}
+
+// GetTransfer handles getting a transfer sent to or by the tenant
+// @Summary Get a video transfer
+// @Description Get a transfer sent to or by the tenant
+// @Tags transfers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/transfers/{id} [get]
+func (h *TransferHandler) GetTransfer(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	transfer, err := h.transferService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrTransferNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video transfer not found")
+		return
+	default:
+		h.logger.Error("Failed to get video transfer", "error", err, "tenant_id", tenantID, "transfer_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get video transfer")
+		return
+	}
+
+	h.respondWithSuccess(c, "Video transfer retrieved successfully", transfer)
+}
+
+// AcceptTransfer handles the recipient accepting a transfer
+// @Summary Accept a video transfer
+// @Description Copy the offered video into the tenant. The response holds the ID of the new video as target_video_id.
+// @Tags transfers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/transfers/{id}/accept [post]
+func (h *TransferHandler) AcceptTransfer(c *gin.Context) {
+	h.respond(c, "accept", h.transferService.Accept)
+}
+
+// DeclineTransfer handles the recipient declining a transfer
+// @Summary Decline a video transfer
+// @Description Turn down a transfer offered to the tenant
+// @Tags transfers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/transfers/{id}/decline [post]
+func (h *TransferHandler) DeclineTransfer(c *gin.Context) {
+	h.respond(c, "decline", h.transferService.Decline)
+}
+
+// CancelTransfer handles the sender withdrawing a transfer
+// @Summary Cancel a video transfer
+// @Description Withdraw a transfer the recipient has not answered yet
+// @Tags transfers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/transfers/{id} [delete]
+func (h *TransferHandler) CancelTransfer(c *gin.Context) {
+	h.respond(c, "cancel", h.transferService.Cancel)
+}
+
+// respond answers a pending transfer with the given service call
+func (h *TransferHandler) respond(c *gin.Context, action string, answer func(ctx context.Context, tenantID, userID, transferID string) (*models.VideoTransfer, error)) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	transfer, err := answer(c.Request.Context(), tenantID, userID, c.Param("id"))
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrTransferNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video transfer not found")
+		return
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "The transferred video no longer exists")
+		return
+	case errors.Is(err, models.ErrTransferNotPending), errors.Is(err, models.ErrVideoArchived), errors.Is(err, models.ErrVideoProcessing):
+		h.respondWithErr(c, http.StatusConflict, err)
+		return
+	default:
+		h.logger.Error("Failed to "+action+" video transfer", "error", err, "tenant_id", tenantID, "transfer_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to "+action+" video transfer")
+		return
+	}
+
+	h.respondWithSuccess(c, "Video transfer updated successfully", transfer)
+}
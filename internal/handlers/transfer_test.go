@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubTransferService knows one pending transfer, "t-1", of the video "launch"
+type stubTransferService struct {
+	services.TransferService
+	incoming *bool
+}
+
+func (s *stubTransferService) Request(ctx context.Context, tenantID, userID, videoID string, req *models.CreateTransferRequest) (*models.VideoTransfer, error) {
+	switch {
+	case req.TargetTenantID == "former":
+		return nil, models.ErrTenantInactive
+	case videoID != "launch":
+		return nil, models.ErrVideoNotFound
+	}
+	return &models.VideoTransfer{ID: "t-1", TenantID: tenantID, TargetTenantID: req.TargetTenantID, VideoID: videoID, Status: string(models.TransferPending)}, nil
+}
+
+func (s *stubTransferService) Accept(ctx context.Context, tenantID, userID, transferID string) (*models.VideoTransfer, error) {
+	if transferID != "t-1" {
+		return nil, models.ErrTransferNotFound
+	}
+	return &models.VideoTransfer{ID: transferID, Status: string(models.TransferCompleted), TargetVideoID: "copy"}, nil
+}
+
+func (s *stubTransferService) Cancel(ctx context.Context, tenantID, userID, transferID string) (*models.VideoTransfer, error) {
+	return nil, models.ErrTransferNotPending
+}
+
+func (s *stubTransferService) List(ctx context.Context, tenantID string, incoming bool, status models.TransferStatus, limit, offset int) ([]*models.VideoTransfer, error) {
+	s.incoming = &incoming
+	return []*models.VideoTransfer{}, nil
+}
+
+func TestTransferHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	svc := &stubTransferService{}
+	handler := NewTransferHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, svc)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/videos/:id/transfers", handler.RequestTransfer)
+	r.GET("/transfers", handler.ListTransfers)
+	r.POST("/transfers/:id/accept", handler.AcceptTransfer)
+	r.DELETE("/transfers/:id", handler.CancelTransfer)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/videos/launch/transfers", `{"target_tenant_id":"client","mode":"move"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"target_tenant_id":"client"`)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/videos/launch/transfers", `{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/videos/launch/transfers", `{"target_tenant_id":"former"}`).Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/videos/missing/transfers", `{"target_tenant_id":"client"}`).Code)
+
+	w = serve("POST", "/transfers/t-1/accept", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"target_video_id":"copy"`)
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/transfers/t-2/accept", "").Code)
+	assert.Equal(t, http.StatusConflict, serve("DELETE", "/transfers/t-1", "").Code)
+
+	assert.Equal(t, http.StatusOK, serve("GET", "/transfers?direction=outgoing", "").Code)
+	assert.False(t, *svc.incoming)
+	assert.Equal(t, http.StatusOK, serve("GET", "/transfers", "").Code)
+	assert.True(t, *svc.incoming, "incoming transfers are listed by default")
+	assert.Equal(t, http.StatusBadRequest, serve("GET", "/transfers?direction=sideways", "").Code)
+}
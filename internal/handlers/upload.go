@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// UploadHandler handles multipart uploads of video files
+type UploadHandler struct {
+	*BaseHandler
+	uploadService services.UploadService
+}
+
+// NewUploadHandler creates a new upload handler
+func NewUploadHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, uploadService services.UploadService) *UploadHandler {
+	return &UploadHandler{
+		BaseHandler:   NewBaseHandler(cfg, logger, db),
+		uploadService: uploadService,
+	}
+}
+
+// StartUpload handles starting a multipart upload
+// @Summary Start multipart upload
+// @Description Start uploading a video file in parts, for files of up to 5 TiB. The upload splits the file into part_count parts of part_size bytes, the last one holding the rest; send them in any order, through the API or to presigned S3 URLs, then complete the upload. A video has one upload in progress at a time.
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param request body models.StartUploadRequest true "File"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/upload/multipart [post]
+func (h *UploadHandler) StartUpload(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	var req models.StartUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	progress, err := h.uploadService.Start(c.Request.Context(), viewer, c.Param("id"), &req)
+	if !h.handleUploadError(c, err, "start") {
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{Message: "Upload started successfully", Data: progress})
+}
+
+// GetUpload handles getting the upload in progress
+// @Summary Get multipart upload
+// @Description Get the video's upload in progress with the parts uploaded so far and the numbers of those missing, to resume an interrupted upload
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/upload/multipart [get]
+func (h *UploadHandler) GetUpload(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.uploadService.Get(c.Request.Context(), viewer, c.Param("id"))
+	if !h.handleUploadError(c, err, "get") {
+		return
+	}
+	h.respondWithSuccess(c, "Upload retrieved successfully", progress)
+}
+
+// PresignParts handles presigning part uploads
+// @Summary Presign upload parts
+// @Description Get URLs to PUT parts of the upload to, straight to S3, valid for an hour. Up to 100 parts per request; the ETag S3 answers need not be kept.
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param request body models.PresignPartsRequest true "Part numbers"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/upload/multipart/parts [post]
+func (h *UploadHandler) PresignParts(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	var req models.PresignPartsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	parts, err := h.uploadService.PresignParts(c.Request.Context(), viewer, c.Param("id"), req.PartNumbers)
+	if !h.handleUploadError(c, err, "presign parts of") {
+		return
+	}
+	h.respondWithSuccess(c, "Upload parts presigned successfully", parts)
+}
+
+// UploadPart handles uploading a part through the API
+// @Summary Upload part
+// @Description Upload one part of the upload as the raw request body, for clients that cannot reach S3. A part uploaded again replaces the previous one.
+// @Tags videos
+// @Accept octet-stream
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param number path int true "Part number, from 1"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/upload/multipart/parts/{number} [put]
+func (h *UploadHandler) UploadPart(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid part number")
+		return
+	}
+
+	part, err := h.uploadService.UploadPart(c.Request.Context(), viewer, c.Param("id"), number, c.Request.Body)
+	if !h.handleUploadError(c, err, "upload part of") {
+		return
+	}
+	h.respondWithSuccess(c, "Upload part uploaded successfully", part)
+}
+
+// CompleteUpload handles completing a multipart upload
+// @Summary Complete multipart upload
+// @Description Assemble the uploaded parts into the video's file and start processing the video. Every part must have been uploaded.
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/upload/multipart/complete [post]
+func (h *UploadHandler) CompleteUpload(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	video, err := h.uploadService.Complete(c.Request.Context(), viewer, c.Param("id"))
+	if !h.handleUploadError(c, err, "complete") {
+		return
+	}
+	h.respondWithSuccess(c, "Upload completed successfully", video)
+}
+
+// AbortUpload handles aborting a multipart upload
+// @Summary Abort multipart upload
+// @Description Discard the video's upload in progress and the parts uploaded so far
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/upload/multipart [delete]
+func (h *UploadHandler) AbortUpload(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	if !h.handleUploadError(c, h.uploadService.Abort(c.Request.Context(), viewer, c.Param("id")), "abort") {
+		return
+	}
+	h.respondWithSuccess(c, "Upload aborted successfully", gin.H{"video_id": c.Param("id")})
+}
+
+// handleUploadError responds to err and returns false, or returns true when
+// there is none
+func (h *UploadHandler) handleUploadError(c *gin.Context, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	case errors.Is(err, models.ErrVideoUploadNotFound):
+		h.respondWithError(c, http.StatusNotFound, "No upload in progress")
+	case errors.Is(err, models.ErrConflict):
+		h.respondWithErr(c, http.StatusConflict, err)
+	default:
+		h.logger.Error("Failed to "+action+" upload", "error", err, "tenant_id", c.GetString("tenant_id"), "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to "+action+" upload")
+	}
+	return false
+}
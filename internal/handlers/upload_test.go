@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubUploadService has an upload in progress for "video-1" and records the
+// parts sent to it
+type stubUploadService struct {
+	services.UploadService
+	body string
+}
+
+func (s *stubUploadService) Start(ctx context.Context, viewer *models.User, videoID string, req *models.StartUploadRequest) (*services.UploadProgress, error) {
+	switch videoID {
+	case "video-1":
+		return nil, i18n.Errorf(models.ErrConflict, "the video already has an upload in progress; resume or abort it")
+	case "video-2":
+		upload := &models.VideoUpload{ID: "upload-2", VideoID: videoID, Size: req.Size, PartCount: 1, S3UploadID: "secret"}
+		return &services.UploadProgress{Upload: upload, Parts: []aws.UploadedPart{}, MissingParts: []int{1}}, nil
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+func (s *stubUploadService) Get(ctx context.Context, viewer *models.User, videoID string) (*services.UploadProgress, error) {
+	if videoID != "video-1" {
+		return nil, models.ErrVideoUploadNotFound
+	}
+	upload := &models.VideoUpload{ID: "upload-1", VideoID: videoID, PartCount: 2}
+	return &services.UploadProgress{Upload: upload, Parts: []aws.UploadedPart{{PartNumber: 1, Size: 5}}, MissingParts: []int{2}, UploadedBytes: 5}, nil
+}
+
+func (s *stubUploadService) UploadPart(ctx context.Context, viewer *models.User, videoID string, partNumber int, body io.Reader) (*aws.UploadedPart, error) {
+	if partNumber > 2 {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "part number %d is not between 1 and %d", partNumber, 2)
+	}
+	data, _ := io.ReadAll(body)
+	s.body = string(data)
+	return &aws.UploadedPart{PartNumber: partNumber, ETag: `"etag"`, Size: int64(len(data))}, nil
+}
+
+func TestUploadHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	service := &stubUploadService{}
+	handler := NewUploadHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, service)
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.POST("/videos/:id/upload/multipart", handler.StartUpload)
+	r.GET("/videos/:id/upload/multipart", handler.GetUpload)
+	r.PUT("/videos/:id/upload/multipart/parts/:number", handler.UploadPart)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := send("POST", "/videos/video-2/upload/multipart", `{"filename":"clip.mp4","size":10}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"missing_parts":[1]`)
+	assert.NotContains(t, w.Body.String(), "secret", "the S3 upload ID stays private")
+	assert.Equal(t, http.StatusConflict, send("POST", "/videos/video-1/upload/multipart", `{"filename":"clip.mp4","size":10}`).Code)
+	assert.Equal(t, http.StatusNotFound, send("POST", "/videos/video-3/upload/multipart", `{"filename":"clip.mp4","size":10}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/videos/video-2/upload/multipart", `{"filename":"clip.mp4"}`).Code)
+
+	w = send("GET", "/videos/video-1/upload/multipart", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"uploaded_bytes":5`)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/videos/video-2/upload/multipart", "").Code)
+
+	assert.Equal(t, http.StatusOK, send("PUT", "/videos/video-1/upload/multipart/parts/2", "hello").Code)
+	assert.Equal(t, "hello", service.body)
+	assert.Equal(t, http.StatusBadRequest, send("PUT", "/videos/video-1/upload/multipart/parts/3", "hello").Code)
+	assert.Equal(t, http.StatusBadRequest, send("PUT", "/videos/video-1/upload/multipart/parts/last", "hello").Code)
+}
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jibe0123/mysteryfactory/internal/config"
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
 	"github.com/jibe0123/mysteryfactory/pkg/db"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
 )
@@ -13,40 +16,85 @@ import (
 // VideoHandler handles video-related requests
 type VideoHandler struct {
 	*BaseHandler
+	videoService services.VideoService
 }
 
 // NewVideoHandler creates a new video handler
-func NewVideoHandler(cfg *config.Config, logger *logger.Logger, db *db.DB) *VideoHandler {
+func NewVideoHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, videoService services.VideoService) *VideoHandler {
 	return &VideoHandler{
-		BaseHandler: NewBaseHandler(cfg, logger, db),
+		BaseHandler:  NewBaseHandler(cfg, logger, db),
+		videoService: videoService,
 	}
 }
 
 // ListVideos handles listing videos
 // @Summary List videos
-// @Description Get a paginated list of videos for the current tenant
+// @Description Get a paginated list of the current tenant's videos the user sees: admins see them all, other users the ones they own and the ones shared with the tenant or with a workspace they manage
 // @Tags videos
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param status query string false "Filter by status (uploading, processing, ready, failed, archived, qc_failed)"
+// @Param tag query string false "Filter by tag"
+// @Param created_from query string false "RFC 3339 time; only videos created at or after it are listed"
+// @Param created_to query string false "RFC 3339 time; only videos created before it are listed"
+// @Param sort query string false "Sort by created_at, title or views" default(created_at)
+// @Param order query string false "Sort order (asc, desc)" default(desc)
 // @Param limit query int false "Number of items per page" default(20)
 // @Param offset query int false "Number of items to skip" default(0)
 // @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /api/v1/videos [get]
 func (h *VideoHandler) ListVideos(c *gin.Context) {
-	userID, tenantID, err := h.getUserFromContext(c)
-	if err != nil {
-		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+	viewer, ok := h.viewer(c)
+	if !ok {
 		return
 	}
 
-	limit, offset := h.getPaginationParams(c)
+	filter := models.VideoListFilter{Status: models.VideoStatus(c.Query("status")), Tag: c.Query("tag"), Sort: c.Query("sort")}
+	var err error
+	if filter.CreatedFrom, err = queryTime(c, "created_from"); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "created_from must be an RFC 3339 time")
+		return
+	}
+	if filter.CreatedBefore, err = queryTime(c, "created_to"); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "created_to must be an RFC 3339 time")
+		return
+	}
+	switch c.DefaultQuery("order", "desc") {
+	case "desc":
+		filter.Desc = true
+	case "asc":
+	default:
+		h.respondWithError(c, http.StatusBadRequest, "order must be asc or desc")
+		return
+	}
 
-	// TODO: Implement actual video listing logic
-	h.logger.Info("Listing videos", "user_id", userID, "tenant_id", tenantID, "limit", limit, "offset", offset)
+	limit, offset := h.getPaginationParams(c)
+	videos, total, err := h.videoService.ListVideos(c.Request.Context(), viewer, filter, limit, offset)
+	switch {
+	case err == nil:
+		h.respondWithPagination(c, videos, total, offset/limit+1, limit)
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	default:
+		h.logger.Error("Failed to list videos", "error", err, "tenant_id", viewer.TenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to list videos")
+	}
+}
 
-	h.respondWithPagination(c, []interface{}{}, 0, offset/limit+1, limit)
+// queryTime parses an optional RFC 3339 query parameter, nil when absent
+func queryTime(c *gin.Context, name string) (*time.Time, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
 }
 
 // CreateVideo handles creating a new video
@@ -88,7 +136,7 @@ func (h *VideoHandler) CreateVideo(c *gin.Context) {
 
 // GetVideo handles getting a specific video
 // @Summary Get video
-// @Description Get a specific video by ID
+// @Description Get a specific video by ID; videos the user does not see are not found
 // @Tags videos
 // @Accept json
 // @Produce json
@@ -99,33 +147,23 @@ func (h *VideoHandler) CreateVideo(c *gin.Context) {
 // @Failure 401 {object} ErrorResponse
 // @Router /api/v1/videos/{id} [get]
 func (h *VideoHandler) GetVideo(c *gin.Context) {
-	userID, tenantID, err := h.getUserFromContext(c)
-	if err != nil {
-		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+	viewer, ok := h.viewer(c)
+	if !ok {
 		return
 	}
 
-	videoID := c.Param("id")
-	if videoID == "" {
-		h.respondWithError(c, http.StatusBadRequest, "Video ID is required")
+	video, err := h.videoService.GetVideo(c.Request.Context(), viewer, c.Param("id"))
+	if err != nil {
+		h.respondWithVideoError(c, err, "Failed to get video")
 		return
 	}
 
-	// TODO: Implement actual video retrieval logic
-	h.logger.Info("Getting video", "user_id", userID, "tenant_id", tenantID, "video_id", videoID)
-
-	h.respondWithSuccess(c, "Video retrieved successfully", gin.H{
-		"id":        videoID,
-		"title":     "Sample Video",
-		"status":    "ready",
-		"user_id":   userID,
-		"tenant_id": tenantID,
-	})
+	h.respondWithSuccess(c, "Video retrieved successfully", video)
 }
 
 // UpdateVideo handles updating a specific video
 // @Summary Update video
-// @Description Update a specific video by ID
+// @Description Update a specific video by ID. Only its owner and admins may change its visibility or workspace.
 // @Tags videos
 // @Accept json
 // @Produce json
@@ -134,34 +172,85 @@ func (h *VideoHandler) GetVideo(c *gin.Context) {
 // @Param request body models.UpdateVideoRequest true "Video update data"
 // @Success 200 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /api/v1/videos/{id} [put]
 func (h *VideoHandler) UpdateVideo(c *gin.Context) {
-	userID, tenantID, err := h.getUserFromContext(c)
+	viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	video, err := h.videoService.UpdateVideo(c.Request.Context(), viewer, c.Param("id"), &req)
 	if err != nil {
-		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		h.respondWithVideoError(c, err, "Failed to update video")
 		return
 	}
 
-	videoID := c.Param("id")
-	if videoID == "" {
-		h.respondWithError(c, http.StatusBadRequest, "Video ID is required")
+	h.respondWithSuccess(c, "Video updated successfully", video)
+}
+
+// TransferOwnership handles handing a video over to another user
+// @Summary Transfer video ownership
+// @Description Make another active user of the tenant the owner of the video. Only its owner and admins may.
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID"
+// @Param request body models.VideoOwnerRequest true "New owner"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/videos/{id}/owner [put]
+func (h *VideoHandler) TransferOwnership(c *gin.Context) {
+	viewer, ok := h.viewer(c)
+	if !ok {
 		return
 	}
 
-	var req models.UpdateVideoRequest
+	var req models.VideoOwnerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	// TODO: Implement actual video update logic
-	h.logger.Info("Updating video", "user_id", userID, "tenant_id", tenantID, "video_id", videoID)
+	video, err := h.videoService.TransferOwnership(c.Request.Context(), viewer, c.Param("id"), req.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			h.respondWithError(c, http.StatusBadRequest, "User not found")
+			return
+		}
+		h.respondWithVideoError(c, err, "Failed to transfer video ownership")
+		return
+	}
+
+	h.respondWithSuccess(c, "Video ownership transferred", video)
+}
 
-	h.respondWithSuccess(c, "Video updated successfully", gin.H{
-		"id": videoID,
-	})
+func (h *VideoHandler) respondWithVideoError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, models.ErrVideoNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Video not found")
+	case errors.Is(err, models.ErrInvalidInput):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrForbidden):
+		h.respondWithErr(c, http.StatusForbidden, err)
+	case errors.Is(err, models.ErrConflict):
+		h.respondWithErr(c, http.StatusConflict, err)
+	default:
+		h.logger.Error(failure, "error", err, "video_id", c.Param("id"))
+		h.respondWithError(c, http.StatusInternalServerError, failure)
+	}
 }
 
 // DeleteVideo handles deleting a specific video
@@ -245,181 +334,3 @@ func (h *VideoHandler) UploadVideo(c *gin.Context) {
 		"status":   "processing",
 	})
 }
-
-// PublishVideo handles publishing a video to platforms
-// @Summary Publish video
-// @Description Publish a video to one or more platforms
-// @Tags videos
-// @Accept json
-// @Produce json
-// @Security BearerAuth
-// @Param id path string true "Video ID"
-// @Param request body models.CreatePublicationJobRequest true "Publication data"
-// @Success 200 {object} SuccessResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Router /api/v1/videos/{id}/publish [post]
-func (h *VideoHandler) PublishVideo(c *gin.Context) {
-	userID, tenantID, err := h.getUserFromContext(c)
-	if err != nil {
-		h.respondWithError(c, http.StatusUnauthorized, "User not found")
-		return
-	}
-
-	videoID := c.Param("id")
-	if videoID == "" {
-		h.respondWithError(c, http.StatusBadRequest, "Video ID is required")
-		return
-	}
-
-	var req models.CreatePublicationJobRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
-		return
-	}
-
-	// TODO: Implement actual video publication logic
-	h.logger.Info("Publishing video",
-		"user_id", userID,
-		"tenant_id", tenantID,
-		"video_id", videoID,
-		"platform", req.Platform)
-
-	h.respondWithSuccess(c, "Video publication started", gin.H{
-		"video_id":       videoID,
-		"platform":       req.Platform,
-		"publication_id": "pub-123",
-		"status":         "pending",
-	})
-}
-
-// GetVideoPublications handles getting publication jobs for a video
-// @Summary Get video publications
-// @Description Get all publication jobs for a specific video
-// @Tags videos
-// @Accept json
-// @Produce json
-// @Security BearerAuth
-// @Param id path string true "Video ID"
-// @Success 200 {object} SuccessResponse
-// @Failure 401 {object} ErrorResponse
-// @Router /api/v1/videos/{id}/publications [get]
-func (h *VideoHandler) GetVideoPublications(c *gin.Context) {
-	userID, tenantID, err := h.getUserFromContext(c)
-	if err != nil {
-		h.respondWithError(c, http.StatusUnauthorized, "User not found")
-		return
-	}
-
-	videoID := c.Param("id")
-	if videoID == "" {
-		h.respondWithError(c, http.StatusBadRequest, "Video ID is required")
-		return
-	}
-
-	// TODO: Implement actual publication retrieval logic
-	h.logger.Info("Getting video publications",
-		"user_id", userID,
-		"tenant_id", tenantID,
-		"video_id", videoID)
-
-	h.respondWithSuccess(c, "Publications retrieved successfully", []interface{}{
-		gin.H{
-			"id":           "pub-123",
-			"video_id":     videoID,
-			"platform":     "youtube",
-			"status":       "completed",
-			"external_url": "https://youtube.com/watch?v=example",
-		},
-	})
-}
-
-// UpdatePublication handles updating a publication job
-// @Summary Update publication
-// @Description Update a specific publication job
-// @Tags videos
-// @Accept json
-// @Produce json
-// @Security BearerAuth
-// @Param id path string true "Video ID"
-// @Param pub_id path string true "Publication ID"
-// @Param request body models.UpdatePublicationJobRequest true "Publication update data"
-// @Success 200 {object} SuccessResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Router /api/v1/videos/{id}/publications/{pub_id} [put]
-func (h *VideoHandler) UpdatePublication(c *gin.Context) {
-	userID, tenantID, err := h.getUserFromContext(c)
-	if err != nil {
-		h.respondWithError(c, http.StatusUnauthorized, "User not found")
-		return
-	}
-
-	videoID := c.Param("id")
-	pubID := c.Param("pub_id")
-
-	if videoID == "" || pubID == "" {
-		h.respondWithError(c, http.StatusBadRequest, "Video ID and Publication ID are required")
-		return
-	}
-
-	var req models.UpdatePublicationJobRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
-		return
-	}
-
-	// TODO: Implement actual publication update logic
-	h.logger.Info("Updating publication",
-		"user_id", userID,
-		"tenant_id", tenantID,
-		"video_id", videoID,
-		"publication_id", pubID)
-
-	h.respondWithSuccess(c, "Publication updated successfully", gin.H{
-		"id":       pubID,
-		"video_id": videoID,
-	})
-}
-
-// CancelPublication handles canceling a publication job
-// @Summary Cancel publication
-// @Description Cancel a specific publication job
-// @Tags videos
-// @Accept json
-// @Produce json
-// @Security BearerAuth
-// @Param id path string true "Video ID"
-// @Param pub_id path string true "Publication ID"
-// @Success 200 {object} SuccessResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Router /api/v1/videos/{id}/publications/{pub_id} [delete]
-func (h *VideoHandler) CancelPublication(c *gin.Context) {
-	userID, tenantID, err := h.getUserFromContext(c)
-	if err != nil {
-		h.respondWithError(c, http.StatusUnauthorized, "User not found")
-		return
-	}
-
-	videoID := c.Param("id")
-	pubID := c.Param("pub_id")
-
-	if videoID == "" || pubID == "" {
-		h.respondWithError(c, http.StatusBadRequest, "Video ID and Publication ID are required")
-		return
-	}
-
-	// TODO: Implement actual publication cancellation logic
-	h.logger.Info("Canceling publication",
-		"user_id", userID,
-		"tenant_id", tenantID,
-		"video_id", videoID,
-		"publication_id", pubID)
-
-	h.respondWithSuccess(c, "Publication canceled successfully", gin.H{
-		"id":       pubID,
-		"video_id": videoID,
-		"status":   "cancelled",
-	})
-}
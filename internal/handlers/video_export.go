@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// VideoExportHandler handles exports of the video library's metadata
+type VideoExportHandler struct {
+	*BaseHandler
+	exportService services.VideoExportService
+}
+
+// NewVideoExportHandler creates a new video export handler
+func NewVideoExportHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, exportService services.VideoExportService) *VideoExportHandler {
+	return &VideoExportHandler{
+		BaseHandler:   NewBaseHandler(cfg, logger, db),
+		exportService: exportService,
+	}
+}
+
+// ExportVideoMetadata handles streaming the metadata of the user's videos
+// @Summary Export video metadata
+// @Description Stream the metadata of the videos the user sees, or those matching one of their smart lists, newest first and up to 10000, for systems the videos are distributed through without an integration: youtube_csv is YouTube's bulk upload spreadsheet with titles, descriptions and keywords fitted to its limits, mrss a Media RSS feed and cms_xml a manifest of every field. The X-Export-Status trailer is "complete" when every video was sent and "error" when the stream stopped early.
+// @Tags videos
+// @Produce text/csv
+// @Produce xml
+// @Security BearerAuth
+// @Param format query string true "Export format" Enums(youtube_csv, mrss, cms_xml)
+// @Param smart_list_id query string false "Only the videos matching the smart list"
+// @Success 200 {string} string
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/videos/export [get]
+func (h *VideoExportHandler) ExportVideoMetadata(c *gin.Context) {
+	user, exists := c.Get("user")
+	viewer, ok := user.(*models.User)
+	if !exists || !ok {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	format := c.Query("format")
+	smartListID := c.Query("smart_list_id")
+	w, err := partners.NewBulkWriter(format, "Video library", c.Writer)
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, i18n.M("Unsupported export format %q", format).In(c.GetString("locale")))
+		return
+	}
+
+	h.logger.Info("Exporting video metadata",
+		"user_id", viewer.ID,
+		"tenant_id", viewer.TenantID,
+		"format", format,
+		"smart_list_id", smartListID)
+
+	rc := http.NewResponseController(c.Writer)
+	extendDeadline := func() {
+		// Not every writer supports deadlines (e.g. test recorders)
+		_ = rc.SetWriteDeadline(time.Now().Add(exportChunkTimeout))
+	}
+
+	// The response starts with the first video, so a missing smart list is
+	// still answered with a status
+	started := false
+	start := func() error {
+		started = true
+		filename := fmt.Sprintf("videos-%s-%s.%s", format, time.Now().UTC().Format("20060102"), w.Extension())
+		c.Header("Content-Type", w.ContentType())
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Header("Trailer", exportStatusTrailer)
+		c.Status(http.StatusOK)
+		extendDeadline()
+		return w.Begin()
+	}
+
+	videos := 0
+	err = h.exportService.Export(c.Request.Context(), viewer, smartListID, func(video *models.Video) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := w.Write(video); err != nil {
+			return err
+		}
+		videos++
+		if videos%exportFlushRows == 0 {
+			c.Writer.Flush()
+			extendDeadline()
+		}
+		return nil
+	})
+	if err != nil && !started {
+		switch {
+		case errors.Is(err, models.ErrSmartListNotFound):
+			h.respondWithError(c, http.StatusNotFound, "Smart list not found")
+		case errors.Is(err, models.ErrInvalidInput):
+			h.respondWithErr(c, http.StatusBadRequest, err)
+		default:
+			h.logger.Error("Failed to export video metadata", "error", err, "tenant_id", viewer.TenantID)
+			h.respondWithError(c, http.StatusInternalServerError, "Failed to export video metadata")
+		}
+		return
+	}
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = w.End()
+	}
+
+	if err != nil {
+		h.logger.Error("Video metadata export failed", "error", err, "tenant_id", viewer.TenantID, "videos", videos)
+		c.Writer.Header().Set(exportStatusTrailer, "error")
+		return
+	}
+	c.Writer.Header().Set(exportStatusTrailer, "complete")
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubVideoExportService exports two videos, or one for the smart list "list-1"
+type stubVideoExportService struct {
+	services.VideoExportService
+}
+
+func (s *stubVideoExportService) Export(ctx context.Context, viewer *models.User, smartListID string, fn func(*models.Video) error) error {
+	videos := []*models.Video{{ID: "video-1", Title: "The heist", FileName: "heist.mp4"}, {ID: "video-2", Title: "The manor"}}
+	switch smartListID {
+	case "":
+	case "list-1":
+		videos = videos[:1]
+	default:
+		return models.ErrSmartListNotFound
+	}
+	for _, video := range videos {
+		if err := fn(video); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestVideoExportHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewVideoExportHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubVideoExportService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/videos/export", handler.ExportVideoMetadata)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/videos/export?format=youtube_csv")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+	assert.Contains(t, w.Body.String(), "heist.mp4,video-1,The heist")
+	assert.Equal(t, "complete", w.Header().Get(exportStatusTrailer))
+
+	w = get("/videos/export?format=cms_xml&smart_list_id=list-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<video id="video-1">`)
+	assert.NotContains(t, w.Body.String(), "video-2")
+
+	assert.Equal(t, http.StatusBadRequest, get("/videos/export?format=xlsx").Code)
+	assert.Equal(t, http.StatusNotFound, get("/videos/export?format=mrss&smart_list_id=list-2").Code,
+		"a missing smart list is answered before the export starts")
+}
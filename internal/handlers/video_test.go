@@ -2,21 +2,84 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jibe0123/mysteryfactory/internal/config"
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
 	"github.com/jibe0123/mysteryfactory/pkg/db"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// stubVideoService serves the tenant's videos by visibility
+type stubVideoService struct {
+	services.VideoService
+	videos map[string]*models.Video
+	filter models.VideoListFilter // Of the last listing
+}
+
+func newStubVideoService() *stubVideoService {
+	return &stubVideoService{videos: map[string]*models.Video{
+		"video-123":   {ID: "video-123", TenantID: "test-tenant-123", UserID: "test-user-123", Title: "Sample Video", Visibility: models.VisibilityOwner},
+		"video-other": {ID: "video-other", TenantID: "test-tenant-123", UserID: "other-user", Title: "Draft", Visibility: models.VisibilityOwner},
+	}}
+}
+
+func (s *stubVideoService) GetVideo(ctx context.Context, viewer *models.User, videoID string) (*models.Video, error) {
+	video, ok := s.videos[videoID]
+	if !ok || !video.VisibleTo(viewer, nil) {
+		return nil, fmt.Errorf("failed to get video: %w", models.ErrVideoNotFound)
+	}
+	return video, nil
+}
+
+func (s *stubVideoService) ListVideos(ctx context.Context, viewer *models.User, filter models.VideoListFilter, limit, offset int) ([]*models.Video, int64, error) {
+	s.filter = filter
+	if filter.Sort == "duration" {
+		return nil, 0, fmt.Errorf("unknown sort: %w", models.ErrInvalidInput)
+	}
+	videos := []*models.Video{}
+	for _, video := range s.videos {
+		if video.VisibleTo(viewer, nil) {
+			videos = append(videos, video)
+		}
+	}
+	return videos, int64(len(videos)), nil
+}
+
+func (s *stubVideoService) UpdateVideo(ctx context.Context, viewer *models.User, videoID string, req *models.UpdateVideoRequest) (*models.Video, error) {
+	video, err := s.GetVideo(ctx, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Title != nil {
+		video.Title = *req.Title
+	}
+	return video, nil
+}
+
+func (s *stubVideoService) TransferOwnership(ctx context.Context, viewer *models.User, videoID, userID string) (*models.Video, error) {
+	video, err := s.GetVideo(ctx, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "missing-user" {
+		return nil, models.ErrUserNotFound
+	}
+	video.UserID = userID
+	return video, nil
+}
+
 func setupVideoTestRouter() (*gin.Engine, *VideoHandler) {
 	gin.SetMode(gin.TestMode)
 
@@ -33,7 +96,7 @@ func setupVideoTestRouter() (*gin.Engine, *VideoHandler) {
 	var mockDB *db.DB
 
 	// Create handler
-	videoHandler := NewVideoHandler(cfg, logger, mockDB)
+	videoHandler := NewVideoHandler(cfg, logger, mockDB, newStubVideoService())
 
 	// Setup router
 	r := gin.New()
@@ -44,6 +107,7 @@ func addAuthMiddleware(r *gin.Engine) {
 	r.Use(func(c *gin.Context) {
 		c.Set("user_id", "test-user-123")
 		c.Set("tenant_id", "test-tenant-123")
+		c.Set("user", &models.User{ID: "test-user-123", TenantID: "test-tenant-123", Role: "editor", Status: "active"})
 		c.Set("limit", 20)
 		c.Set("offset", 0)
 		c.Next()
@@ -73,6 +137,35 @@ func TestVideoHandler_ListVideos(t *testing.T) {
 	assert.Contains(t, response, "limit")
 }
 
+func TestVideoHandler_ListVideosFilter(t *testing.T) {
+	r, videoHandler := setupVideoTestRouter()
+	addAuthMiddleware(r)
+	r.GET("/videos", videoHandler.ListVideos)
+	stub := videoHandler.videoService.(*stubVideoService)
+
+	serve := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/videos?"+query, nil))
+		return w
+	}
+
+	w := serve("status=ready&tag=teaser&created_from=2026-05-01T00:00:00Z&created_to=2026-06-01T00:00:00Z&sort=views&order=asc")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.StatusReady, stub.filter.Status)
+	assert.Equal(t, "teaser", stub.filter.Tag)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), *stub.filter.CreatedFrom)
+	assert.Equal(t, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), *stub.filter.CreatedBefore)
+	assert.Equal(t, "views", stub.filter.Sort)
+	assert.False(t, stub.filter.Desc)
+
+	assert.Equal(t, http.StatusOK, serve("").Code)
+	assert.True(t, stub.filter.Desc, "newest first by default")
+
+	assert.Equal(t, http.StatusBadRequest, serve("created_from=yesterday").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("order=random").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("sort=duration").Code)
+}
+
 func TestVideoHandler_ListVideos_Unauthorized(t *testing.T) {
 	r, videoHandler := setupVideoTestRouter()
 	r.GET("/videos", videoHandler.ListVideos)
@@ -345,106 +438,37 @@ func TestVideoHandler_UploadVideo(t *testing.T) {
 	assert.Equal(t, "test-video.mp4", data["filename"])
 }
 
-func TestVideoHandler_PublishVideo(t *testing.T) {
-	r, videoHandler := setupVideoTestRouter()
-	addAuthMiddleware(r)
-	r.POST("/videos/:id/publish", videoHandler.PublishVideo)
-
-	tests := []struct {
-		name           string
-		videoID        string
-		requestBody    interface{}
-		expectedStatus int
-	}{
-		{
-			name:    "Valid publication request",
-			videoID: "video-123",
-			requestBody: models.CreatePublicationJobRequest{
-				VideoID:  "video-123",
-				Platform: "youtube",
-				Config: map[string]interface{}{
-					"title":       "Published Video",
-					"description": "Video published to YouTube",
-					"privacy":     "public",
-				},
-			},
-			expectedStatus: http.StatusOK,
-		},
-		{
-			name:    "Invalid platform",
-			videoID: "video-123",
-			requestBody: models.CreatePublicationJobRequest{
-				VideoID:  "video-123",
-				Platform: "invalid-platform",
-			},
-			expectedStatus: http.StatusOK,
-		},
-		{
-			name:           "Missing request body",
-			videoID:        "video-123",
-			requestBody:    map[string]interface{}{},
-			expectedStatus: http.StatusOK,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			jsonBody, err := json.Marshal(tt.requestBody)
-			require.NoError(t, err)
-
-			path := "/videos/" + tt.videoID + "/publish"
-			req, err := http.NewRequest("POST", path, bytes.NewBuffer(jsonBody))
-			require.NoError(t, err)
-			req.Header.Set("Content-Type", "application/json")
-
-			w := httptest.NewRecorder()
-			r.ServeHTTP(w, req)
-
-			assert.Equal(t, tt.expectedStatus, w.Code)
-
-			if tt.expectedStatus == http.StatusOK {
-				var response map[string]interface{}
-				err = json.Unmarshal(w.Body.Bytes(), &response)
-				require.NoError(t, err)
-
-				assert.Contains(t, response, "message")
-				assert.Contains(t, response, "data")
-
-				data, ok := response["data"].(map[string]interface{})
-				assert.True(t, ok)
-				assert.Equal(t, tt.videoID, data["video_id"])
-				assert.NotEmpty(t, data["publication_id"])
-			}
-		})
-	}
+// Helper function to create string pointers
+func stringPtr(s string) *string {
+	return &s
 }
 
-func TestVideoHandler_GetVideoPublications(t *testing.T) {
+func TestVideoHandler_Visibility(t *testing.T) {
 	r, videoHandler := setupVideoTestRouter()
 	addAuthMiddleware(r)
-	r.GET("/videos/:id/publications", videoHandler.GetVideoPublications)
-
-	req, err := http.NewRequest("GET", "/videos/video-123/publications", nil)
-	require.NoError(t, err)
-
-	w := httptest.NewRecorder()
-	r.ServeHTTP(w, req)
+	r.GET("/videos", videoHandler.ListVideos)
+	r.GET("/videos/:id", videoHandler.GetVideo)
+	r.PUT("/videos/:id/owner", videoHandler.TransferOwnership)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
 
+	// Another user's draft is neither listed nor found
+	w := serve("GET", "/videos", "")
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "video-123")
+	assert.NotContains(t, w.Body.String(), "video-other")
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/videos/video-other", "").Code)
 
-	var response map[string]interface{}
-	err = json.Unmarshal(w.Body.Bytes(), &response)
-	require.NoError(t, err)
-
-	assert.Contains(t, response, "message")
-	assert.Contains(t, response, "data")
-
-	data, ok := response["data"].([]interface{})
-	assert.True(t, ok)
-	assert.GreaterOrEqual(t, len(data), 0) // Should return array (even if empty)
-}
+	w = serve("PUT", "/videos/video-123/owner", `{"user_id":"user-2"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"user_id":"user-2"`)
 
-// Helper function to create string pointers
-func stringPtr(s string) *string {
-	return &s
+	assert.Equal(t, http.StatusBadRequest, serve("PUT", "/videos/video-other/owner", `{}`).Code)
+	assert.Equal(t, http.StatusNotFound, serve("PUT", "/videos/video-other/owner", `{"user_id":"user-2"}`).Code)
 }
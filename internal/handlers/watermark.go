@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// WatermarkHandler handles the logo tenants burn into their videos
+type WatermarkHandler struct {
+	*BaseHandler
+	watermarkService services.WatermarkService
+}
+
+// NewWatermarkHandler creates a new watermark handler
+func NewWatermarkHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, watermarkService services.WatermarkService) *WatermarkHandler {
+	return &WatermarkHandler{
+		BaseHandler:      NewBaseHandler(cfg, logger, db),
+		watermarkService: watermarkService,
+	}
+}
+
+// GetWatermark handles retrieving the tenant's watermark settings
+// @Summary Get watermark
+// @Description Get the settings of the logo burnt into the tenant's transcoded videos
+// @Tags watermark
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.Watermark
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/watermark [get]
+func (h *WatermarkHandler) GetWatermark(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	watermark, err := h.watermarkService.Get(c.Request.Context(), tenantID)
+	if err != nil {
+		h.respondWithWatermarkError(c, err, tenantID, "Failed to get watermark")
+		return
+	}
+
+	h.respondWithSuccess(c, "Watermark retrieved successfully", watermark)
+}
+
+// GetWatermarkImage handles downloading the tenant's watermark image
+// @Summary Get watermark image
+// @Description Download the logo burnt into the tenant's transcoded videos
+// @Tags watermark
+// @Produce png,jpeg
+// @Security BearerAuth
+// @Success 200 {file} binary
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/watermark/image [get]
+func (h *WatermarkHandler) GetWatermarkImage(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	watermark, err := h.watermarkService.Get(c.Request.Context(), tenantID)
+	if err != nil {
+		h.respondWithWatermarkError(c, err, tenantID, "Failed to get watermark")
+		return
+	}
+
+	c.Data(http.StatusOK, watermark.ContentType, watermark.Image)
+}
+
+// UploadWatermark handles replacing the tenant's watermark image
+// @Summary Upload watermark
+// @Description Upload the PNG or JPEG logo burnt into the tenant's transcoded videos, up to 1 MiB and 1080 pixels a side. A first upload is burnt in at the bottom right at 80% opacity on every platform; a new image keeps the current settings.
+// @Tags watermark
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Watermark image"
+// @Success 200 {object} models.Watermark
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/watermark/image [put]
+func (h *WatermarkHandler) UploadWatermark(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "No file uploaded")
+		return
+	}
+	if file.Size > models.MaxWatermarkBytes {
+		h.respondWithError(c, http.StatusBadRequest, "Watermark is too large")
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Failed to read uploaded file")
+		return
+	}
+	defer f.Close()
+	// The service rejects the image when it is still larger than allowed
+	image, err := io.ReadAll(io.LimitReader(f, models.MaxWatermarkBytes+1))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Failed to read uploaded file")
+		return
+	}
+
+	h.logger.Info("Uploading watermark", "user_id", userID, "tenant_id", tenantID, "filename", file.Filename, "size", file.Size)
+
+	watermark, err := h.watermarkService.Upload(c.Request.Context(), tenantID, image)
+	if err != nil {
+		h.respondWithWatermarkError(c, err, tenantID, "Failed to upload watermark")
+		return
+	}
+
+	h.respondWithSuccess(c, "Watermark uploaded successfully", watermark)
+}
+
+// UpdateWatermark handles changing how the tenant's watermark is burnt in
+// @Summary Update watermark settings
+// @Description Change where and how strongly the tenant's watermark is burnt in, whether it is on by default and on which platforms (empty for all). Publications may override them with a "watermark" entry in their config, either true/false or an object with enabled, position and opacity.
+// @Tags watermark
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateWatermarkRequest true "Watermark settings"
+// @Success 200 {object} models.Watermark
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/watermark [put]
+func (h *WatermarkHandler) UpdateWatermark(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.UpdateWatermarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	h.logger.Info("Updating watermark settings", "user_id", userID, "tenant_id", tenantID)
+
+	watermark, err := h.watermarkService.Update(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		h.respondWithWatermarkError(c, err, tenantID, "Failed to update watermark")
+		return
+	}
+
+	h.respondWithSuccess(c, "Watermark updated successfully", watermark)
+}
+
+// DeleteWatermark handles removing the tenant's watermark
+// @Summary Delete watermark
+// @Description Remove the tenant's watermark; videos transcoded afterwards have none, whatever their publications ask
+// @Tags watermark
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/watermark [delete]
+func (h *WatermarkHandler) DeleteWatermark(c *gin.Context) {
+	userID, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	h.logger.Info("Deleting watermark", "user_id", userID, "tenant_id", tenantID)
+
+	if err := h.watermarkService.Delete(c.Request.Context(), tenantID); err != nil {
+		h.respondWithWatermarkError(c, err, tenantID, "Failed to delete watermark")
+		return
+	}
+
+	h.respondWithSuccess(c, "Watermark deleted successfully", nil)
+}
+
+// respondWithWatermarkError maps watermark service errors to HTTP responses
+func (h *WatermarkHandler) respondWithWatermarkError(c *gin.Context, err error, tenantID, message string) {
+	switch {
+	case errors.Is(err, models.ErrWatermarkNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Watermark not found")
+	case errors.Is(err, models.ErrInvalidInput), errors.Is(err, models.ErrInvalidPlatform):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	default:
+		h.logger.Error(message, "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubWatermarkService keeps the last uploaded image of a single tenant
+type stubWatermarkService struct {
+	services.WatermarkService
+	watermark *models.Watermark
+}
+
+func (s *stubWatermarkService) Get(ctx context.Context, tenantID string) (*models.Watermark, error) {
+	if s.watermark == nil {
+		return nil, models.ErrWatermarkNotFound
+	}
+	return s.watermark, nil
+}
+
+func (s *stubWatermarkService) Upload(ctx context.Context, tenantID string, image []byte) (*models.Watermark, error) {
+	if !bytes.HasPrefix(image, []byte("\x89PNG")) {
+		return nil, fmt.Errorf("%w: watermark must be a PNG or JPEG image", models.ErrInvalidInput)
+	}
+	s.watermark = &models.Watermark{TenantID: tenantID, Image: image, ContentType: "image/png",
+		Enabled: true, Position: transcode.BottomRight, Opacity: 0.8}
+	return s.watermark, nil
+}
+
+func TestWatermarkHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewWatermarkHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubWatermarkService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/watermark", handler.GetWatermark)
+	r.GET("/watermark/image", handler.GetWatermarkImage)
+	r.PUT("/watermark/image", handler.UploadWatermark)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+	upload := func(image []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile("file", "logo.png")
+		require.NoError(t, err)
+		_, err = part.Write(image)
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		req := httptest.NewRequest("PUT", "/watermark/image", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, get("/watermark").Code)
+	assert.Equal(t, http.StatusBadRequest, upload([]byte("not an image")).Code)
+
+	w := upload([]byte("\x89PNG logo"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"position":"bottom_right"`)
+	assert.NotContains(t, w.Body.String(), "logo", "the image is not sent back")
+
+	w = get("/watermark/image")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, "\x89PNG logo", w.Body.String())
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// WebhookSubscriptionHandler handles the registration of our webhook URLs
+// with the platforms pushing notifications
+type WebhookSubscriptionHandler struct {
+	*BaseHandler
+	subscriptions services.WebhookSubscriptionService
+}
+
+// NewWebhookSubscriptionHandler creates a new webhook subscription handler
+func NewWebhookSubscriptionHandler(cfg *config.Config, logger *logger.Logger, db *db.DB, subscriptions services.WebhookSubscriptionService) *WebhookSubscriptionHandler {
+	return &WebhookSubscriptionHandler{
+		BaseHandler:   NewBaseHandler(cfg, logger, db),
+		subscriptions: subscriptions,
+	}
+}
+
+// GetSubscriptionHealth handles getting the tenant's webhook subscriptions
+// @Summary Get webhook subscription health
+// @Description List the tenant's webhook subscriptions with their state, lease end and last error, and count them by state. The tenant is healthy when no subscription failed or expired: pending ones are still being verified by the platform.
+// @Tags platforms
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} services.WebhookSubscriptionHealth
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/platforms/webhook-subscriptions [get]
+func (h *WebhookSubscriptionHandler) GetSubscriptionHealth(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	health, err := h.subscriptions.Health(c.Request.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("Failed to get webhook subscriptions", "error", err, "tenant_id", tenantID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to get webhook subscriptions")
+		return
+	}
+	h.respondWithSuccess(c, "Webhook subscriptions retrieved successfully", health)
+}
+
+// Subscribe handles registering our webhook URLs for a workspace's channels
+// @Summary Subscribe webhooks
+// @Description Register our webhook URL with each platform the workspace is connected to that pushes notifications: YouTube through its WebSub hub, Facebook through its page subscriptions. Subscribing again renews the lease. A platform refusing is recorded as a failed subscription, retried by the renewal job.
+// @Tags platforms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.WebhookSubscribeRequest true "Workspace"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/platforms/webhook-subscriptions [post]
+func (h *WebhookSubscriptionHandler) Subscribe(c *gin.Context) {
+	_, tenantID, err := h.getUserFromContext(c)
+	if err != nil {
+		h.respondWithError(c, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	var req models.WebhookSubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	subscriptions, err := h.subscriptions.Subscribe(c.Request.Context(), tenantID, req.WorkspaceID)
+	switch {
+	case err == nil:
+		h.respondWithSuccess(c, "Webhooks subscribed successfully", subscriptions)
+	case errors.Is(err, models.ErrSubscriptionUnsupported):
+		h.respondWithErr(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrNotFound):
+		h.respondWithError(c, http.StatusNotFound, "Workspace not found")
+	case errors.Is(err, models.ErrWebhookCallbackNotSet):
+		h.respondWithErr(c, http.StatusServiceUnavailable, err)
+	default:
+		h.logger.Error("Failed to subscribe webhooks", "error", err, "tenant_id", tenantID, "workspace_id", req.WorkspaceID)
+		h.respondWithError(c, http.StatusInternalServerError, "Failed to subscribe webhooks")
+	}
+}
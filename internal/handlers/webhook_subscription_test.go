@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubWebhookSubscriptionService subscribes "ws-1"; "ws-tiktok" is connected
+// to TikTok only and "ws-local" is subscribed without a callback URL
+type stubWebhookSubscriptionService struct {
+	services.WebhookSubscriptionService
+}
+
+func (s *stubWebhookSubscriptionService) Health(ctx context.Context, tenantID string) (*services.WebhookSubscriptionHealth, error) {
+	return &services.WebhookSubscriptionHealth{Healthy: true, Subscriptions: []*models.WebhookSubscription{}}, nil
+}
+
+func (s *stubWebhookSubscriptionService) Subscribe(ctx context.Context, tenantID, workspaceID string) ([]*models.WebhookSubscription, error) {
+	switch workspaceID {
+	case "ws-1":
+		return []*models.WebhookSubscription{{WorkspaceID: workspaceID, Platform: "youtube", Status: models.SubscriptionPending}}, nil
+	case "ws-tiktok":
+		return nil, models.ErrSubscriptionUnsupported
+	case "ws-local":
+		return nil, models.ErrWebhookCallbackNotSet
+	}
+	return nil, fmt.Errorf("failed to get workspace: %w", models.ErrNotFound)
+}
+
+func TestWebhookSubscriptionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mockDB *db.DB
+	handler := NewWebhookSubscriptionHandler(&config.Config{Environment: "test"}, logger.New("error", "test"), mockDB, &stubWebhookSubscriptionService{})
+	r := gin.New()
+	addAuthMiddleware(r)
+	r.GET("/platforms/webhook-subscriptions", handler.GetSubscriptionHealth)
+	r.POST("/platforms/webhook-subscriptions", handler.Subscribe)
+
+	serve := func(method, body string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, "/platforms/webhook-subscriptions", strings.NewReader(body)))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("GET", ""))
+	assert.Equal(t, http.StatusOK, serve("POST", `{"workspace_id":"ws-1"}`))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", `{}`))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", `{"workspace_id":"ws-tiktok"}`))
+	assert.Equal(t, http.StatusNotFound, serve("POST", `{"workspace_id":"ws-2"}`))
+	assert.Equal(t, http.StatusServiceUnavailable, serve("POST", `{"workspace_id":"ws-local"}`))
+}
@@ -1,28 +1,41 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/callback"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/redact"
+	"github.com/jibe0123/mysteryfactory/pkg/slo"
 	"github.com/rs/cors"
 	"golang.org/x/time/rate"
 )
 
-// CORS middleware for handling Cross-Origin Resource Sharing
-func CORS(allowed string) gin.HandlerFunc {
+// CORS middleware for handling Cross-Origin Resource Sharing. origins and
+// headers are comma-separated; an origin may hold one * wildcard, such as
+// https://*.mysteryfactory.io for every subdomain.
+func CORS(origins, headers string, allowCredentials bool) gin.HandlerFunc {
 	c := cors.New(cors.Options{
-		AllowedOrigins:   strings.Split(allowed, ","),
+		AllowedOrigins:   splitList(origins),
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Authorization", "Content-Type", "X-Requested-With", "X-Request-ID", "X-Tenant-ID"},
-		AllowCredentials: true,
+		AllowedHeaders:   splitList(headers),
+		AllowCredentials: allowCredentials,
 	})
 	return func(ctx *gin.Context) {
 		c.HandlerFunc(ctx.Writer, ctx.Request)
@@ -34,6 +47,27 @@ func CORS(allowed string) gin.HandlerFunc {
 	}
 }
 
+// splitList splits a comma-separated setting, dropping blank entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// SLO middleware times the requests of endpoints with a latency objective.
+// Requests are matched by route pattern, so it must run on the engine.
+func SLO(tracker *slo.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		tracker.Observe(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
 // RequestID middleware adds a unique request ID to each request
 func RequestID() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
@@ -98,6 +132,9 @@ func Logger(log *logger.Logger) gin.HandlerFunc {
 		if tenantID != "" {
 			fields = append(fields, "tenant_id", tenantID)
 		}
+		if impersonatorID := c.GetString("impersonator_id"); impersonatorID != "" {
+			fields = append(fields, "impersonator_id", impersonatorID, "impersonation_id", c.GetString("impersonation_id"))
+		}
 
 		// Log based on status code
 		status := c.Writer.Status()
@@ -117,10 +154,17 @@ func RateLimiter() gin.HandlerFunc {
 	limiter := rate.NewLimiter(rate.Every(time.Minute/100), 100)
 
 	return gin.HandlerFunc(func(c *gin.Context) {
+		// Public webhooks are limited per platform by WebhookRateLimit instead,
+		// so a webhook flood cannot starve the API
+		if strings.HasPrefix(c.FullPath(), "/webhooks/") {
+			c.Next()
+			return
+		}
+
 		if !limiter.Allow() {
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":   "Rate Limit Exceeded",
-				"message": "Too many requests, please try again later",
+				"message": i18n.T(c.GetString("locale"), "Too many requests, please try again later"),
 			})
 			c.Abort()
 			return
@@ -129,12 +173,28 @@ func RateLimiter() gin.HandlerFunc {
 	})
 }
 
+// Locale middleware picks the language of the response messages from the
+// Accept-Language header and stores it under "locale"
+func Locale() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set("locale", lang)
+		c.Header("Content-Language", lang)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	})
+}
+
 // JWTClaims represents the JWT token claims
 type JWTClaims struct {
 	UserID   string `json:"user_id"`
 	TenantID string `json:"tenant_id"`
 	Email    string `json:"email"`
 	Role     string `json:"role"`
+	// Set on impersonation tokens: the admin acting as the user and the
+	// session, which every audit entry of the token refers to
+	ImpersonatorID  string `json:"impersonator_id,omitempty"`
+	ImpersonationID string `json:"impersonation_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -145,7 +205,7 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Unauthorized",
-				"message": "Authorization header is required",
+				"message": i18n.T(c.GetString("locale"), "Authorization header is required"),
 			})
 			c.Abort()
 			return
@@ -156,7 +216,7 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Unauthorized",
-				"message": "Invalid authorization header format",
+				"message": i18n.T(c.GetString("locale"), "Invalid authorization header format"),
 			})
 			c.Abort()
 			return
@@ -172,7 +232,7 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Unauthorized",
-				"message": "Invalid or expired token",
+				"message": i18n.T(c.GetString("locale"), "Invalid or expired token"),
 			})
 			c.Abort()
 			return
@@ -193,6 +253,10 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 			c.Set("user_id", claims.UserID)
 			c.Set("tenant_id", claims.TenantID)
 			c.Set("user_role", claims.Role)
+			if claims.ImpersonatorID != "" {
+				c.Set("impersonator_id", claims.ImpersonatorID)
+				c.Set("impersonation_id", claims.ImpersonationID)
+			}
 		}
 
 		c.Next()
@@ -207,7 +271,7 @@ func TenantResolver() gin.HandlerFunc {
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Unauthorized",
-				"message": "Tenant information not found",
+				"message": i18n.T(c.GetString("locale"), "Tenant information not found"),
 			})
 			c.Abort()
 			return
@@ -220,6 +284,258 @@ func TenantResolver() gin.HandlerFunc {
 	})
 }
 
+// CurrentUser middleware replaces the user built from the token claims with
+// the current one, so a deactivated or suspended user or tenant is refused and a role
+// change applies before the tokens issued earlier expire. load is cached
+// across requests and puts the user and tenant on the request context.
+func CurrentUser(load func(ctx context.Context, tenantID, userID string) (context.Context, *models.User, error)) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		ctx, user, err := load(c.Request.Context(), c.GetString("tenant_id"), c.GetString("user_id"))
+		if err != nil {
+			switch {
+			case errors.Is(err, models.ErrUserNotFound), errors.Is(err, models.ErrUserInactive):
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error":   "Unauthorized",
+					"message": i18n.T(c.GetString("locale"), "Account is not active"),
+				})
+			case errors.Is(err, models.ErrUserSuspended):
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":   "Forbidden",
+					"message": i18n.T(c.GetString("locale"), "Account is suspended"),
+				})
+			case errors.Is(err, models.ErrTenantSuspended):
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":   "Forbidden",
+					"message": i18n.T(c.GetString("locale"), "Tenant is suspended"),
+				})
+			case errors.Is(err, models.ErrTenantInactive):
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":   "Forbidden",
+					"message": i18n.T(c.GetString("locale"), "Tenant is not active"),
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Internal Server Error",
+					"message": i18n.T(c.GetString("locale"), "Failed to load user"),
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Set("user", user)
+		c.Set("user_role", user.Role)
+		c.Next()
+	})
+}
+
+// Residency middleware puts the tenant's data residency on the request context,
+// so regional clients called by handlers keep the tenant's data in its region
+func Residency(resolve func(ctx context.Context, tenantID string) (context.Context, error)) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		ctx, err := resolve(c.Request.Context(), c.GetString("tenant_id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Internal Server Error",
+				"message": i18n.T(c.GetString("locale"), "Failed to resolve tenant data residency"),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	})
+}
+
+// Audit middleware records mutating requests in the tenant's audit log once
+// they are handled. Every request of an impersonation session is recorded,
+// reads included, and marked as impersonated.
+func Audit(record func(ctx context.Context, entry *models.AuditLog) error) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		c.Next()
+
+		impersonatorID := c.GetString("impersonator_id")
+		tenantID := c.GetString("tenant_id")
+		if tenantID == "" {
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if impersonatorID == "" {
+				return
+			}
+		}
+
+		action := c.FullPath()
+		if action == "" {
+			action = c.Request.URL.Path
+		}
+		// Failures are logged by the recorder; the response is already sent.
+		// The entry is written even when the client has gone away.
+		_ = record(context.WithoutCancel(c.Request.Context()), &models.AuditLog{
+			TenantID:        tenantID,
+			UserID:          c.GetString("user_id"),
+			Action:          c.Request.Method + " " + action,
+			Resource:        c.Request.URL.Path,
+			Status:          c.Writer.Status(),
+			RequestID:       c.GetString("request_id"),
+			IPAddress:       c.ClientIP(),
+			Impersonated:    impersonatorID != "",
+			ImpersonatorID:  impersonatorID,
+			ImpersonationID: c.GetString("impersonation_id"),
+		})
+	})
+}
+
+// DebugCapture middleware records the requests of tenants with a running
+// debug capture session, as returned by active, along with their responses.
+// Bodies are kept up to maxBodyBytes and everything is sanitized before it
+// is handed to record. Admin routes are never captured, so captures cannot
+// end up capturing themselves.
+func DebugCapture(active func(ctx context.Context, tenantID string) (string, bool), record func(ctx context.Context, capture *models.DebugCapture) error, maxBodyBytes int) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		tenantID := c.GetString("tenant_id")
+		if tenantID == "" || strings.HasPrefix(c.FullPath(), "/api/v1/admin/") {
+			c.Next()
+			return
+		}
+		sessionID, ok := active(c.Request.Context(), tenantID)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		// Bodies are copied as the handler reads and writes them, so
+		// streamed uploads and exports are not buffered whole
+		requestBody := &cappedBuffer{max: maxBodyBytes}
+		if c.Request.Body != nil {
+			c.Request.Body = &teeReadCloser{ReadCloser: c.Request.Body, copy: requestBody}
+		}
+		writer := &captureWriter{ResponseWriter: c.Writer, body: &cappedBuffer{max: maxBodyBytes}}
+		c.Writer = writer
+		requestHeader := c.Request.Header.Clone()
+
+		start := time.Now()
+		c.Next()
+
+		// Failures are logged by the recorder; the response is already sent.
+		// Requests the client gave up on are captured too.
+		_ = record(context.WithoutCancel(c.Request.Context()), &models.DebugCapture{
+			TenantID:              tenantID,
+			SessionID:             sessionID,
+			UserID:                c.GetString("user_id"),
+			RequestID:             c.GetString("request_id"),
+			Method:                c.Request.Method,
+			Route:                 c.FullPath(),
+			Path:                  c.Request.URL.Path,
+			Status:                writer.Status(),
+			DurationMs:            time.Since(start).Milliseconds(),
+			Query:                 redact.Query(c.Request.URL.RawQuery),
+			RequestHeaders:        headersJSON(requestHeader),
+			RequestBody:           redact.Body(requestHeader.Get("Content-Type"), requestBody.Bytes()),
+			RequestBodyTruncated:  requestBody.truncated,
+			ResponseHeaders:       headersJSON(writer.Header()),
+			ResponseBody:          redact.Body(writer.Header().Get("Content-Type"), writer.body.Bytes()),
+			ResponseBodyTruncated: writer.body.truncated,
+		})
+	})
+}
+
+// headersJSON encodes sanitized headers as a JSON object
+func headersJSON(h http.Header) string {
+	encoded, err := json.Marshal(redact.Headers(h))
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// cappedBuffer keeps the first max bytes written to it and notes whether
+// anything was cut off
+type cappedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := b.max - b.Len(); room < len(p) {
+		b.truncated = true
+		p = p[:max(room, 0)]
+	}
+	b.Buffer.Write(p)
+	return n, nil
+}
+
+func (b *cappedBuffer) WriteString(s string) (int, error) {
+	return b.Write([]byte(s))
+}
+
+// teeReadCloser copies what is read from a request body
+type teeReadCloser struct {
+	io.ReadCloser
+	copy io.Writer
+}
+
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if n > 0 {
+		_, _ = t.copy.Write(p[:n])
+	}
+	return n, err
+}
+
+// captureWriter copies what is written to a response
+type captureWriter struct {
+	gin.ResponseWriter
+	body *cappedBuffer
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	_, _ = w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	_, _ = w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// RestrictImpersonation middleware rejects deletions made with an
+// impersonation token, so reproducing an issue cannot destroy tenant data
+func RestrictImpersonation() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if c.Request.Method == http.MethodDelete && c.GetString("impersonator_id") != "" {
+			abortImpersonated(c)
+			return
+		}
+		c.Next()
+	})
+}
+
+// DenyImpersonation middleware rejects impersonation tokens, for account,
+// access and tenant settings routes
+func DenyImpersonation() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if c.GetString("impersonator_id") != "" {
+			abortImpersonated(c)
+			return
+		}
+		c.Next()
+	})
+}
+
+func abortImpersonated(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   "Forbidden",
+		"message": i18n.T(c.GetString("locale"), "This operation is not allowed while impersonating a user"),
+	})
+	c.Abort()
+}
+
 // RequireRole middleware checks if user has required role
 func RequireRole(requiredRole string) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
@@ -227,7 +543,7 @@ func RequireRole(requiredRole string) gin.HandlerFunc {
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Unauthorized",
-				"message": "User information not found",
+				"message": i18n.T(c.GetString("locale"), "User information not found"),
 			})
 			c.Abort()
 			return
@@ -237,7 +553,7 @@ func RequireRole(requiredRole string) gin.HandlerFunc {
 		if !ok {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Internal Server Error",
-				"message": "Invalid user data",
+				"message": i18n.T(c.GetString("locale"), "Invalid user data"),
 			})
 			c.Abort()
 			return
@@ -247,7 +563,7 @@ func RequireRole(requiredRole string) gin.HandlerFunc {
 		if u.Role != requiredRole && u.Role != "admin" { // Admin can access everything
 			c.JSON(http.StatusForbidden, gin.H{
 				"error":   "Forbidden",
-				"message": "Insufficient permissions",
+				"message": i18n.T(c.GetString("locale"), "Insufficient permissions"),
 			})
 			c.Abort()
 			return
@@ -264,7 +580,7 @@ func RequirePermission(permission string) gin.HandlerFunc {
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Unauthorized",
-				"message": "User information not found",
+				"message": i18n.T(c.GetString("locale"), "User information not found"),
 			})
 			c.Abort()
 			return
@@ -274,7 +590,7 @@ func RequirePermission(permission string) gin.HandlerFunc {
 		if !ok {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Internal Server Error",
-				"message": "Invalid user data",
+				"message": i18n.T(c.GetString("locale"), "Invalid user data"),
 			})
 			c.Abort()
 			return
@@ -284,7 +600,7 @@ func RequirePermission(permission string) gin.HandlerFunc {
 		if !u.HasPermission(permission) {
 			c.JSON(http.StatusForbidden, gin.H{
 				"error":   "Forbidden",
-				"message": "Insufficient permissions",
+				"message": i18n.T(c.GetString("locale"), "Insufficient permissions"),
 			})
 			c.Abort()
 			return
@@ -294,69 +610,233 @@ func RequirePermission(permission string) gin.HandlerFunc {
 	})
 }
 
-// WebhookAuth middleware for webhook authentication
-func WebhookAuth() gin.HandlerFunc {
+// RequireSupportTenant middleware restricts cross-tenant routes to users of
+// the support tenant; without one configured, nobody is let through
+func RequireSupportTenant(supportTenantID string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if supportTenantID == "" || c.GetString("tenant_id") != supportTenantID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden",
+				"message": i18n.T(c.GetString("locale"), "Only the support tenant can access this resource"),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	})
+}
+
+// WebhookAuth middleware verifies the platform's signature on the webhook
+// body, then restores the body for the handler
+func WebhookAuth(secrets partners.WebhookSecrets) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		// Get platform from URL parameter
 		platform := c.Param("platform")
 		if platform == "" {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Bad Request",
-				"message": "Platform parameter is required",
+				"message": i18n.T(c.GetString("locale"), "Platform parameter is required"),
+			})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				abortPayloadTooLarge(c, tooLarge.Limit)
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": i18n.T(c.GetString("locale"), "Failed to read request body"),
 			})
 			c.Abort()
 			return
 		}
 
 		// Validate webhook signature based on platform
-		switch platform {
-		case "youtube":
-			if !validateYouTubeWebhook(c) {
-				c.JSON(http.StatusUnauthorized, gin.H{
-					"error":   "Unauthorized",
-					"message": "Invalid webhook signature",
-				})
-				c.Abort()
+		switch err := partners.VerifyWebhook(platform, secrets, c.Request.Header, body, time.Now()); {
+		case errors.Is(err, partners.ErrWebhookNotConfigured):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Not Found",
+				"message": i18n.T(c.GetString("locale"), "Webhooks are not configured for this platform"),
+			})
+			c.Abort()
+			return
+		case err != nil:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized",
+				"message": i18n.T(c.GetString("locale"), "Invalid webhook signature"),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Set("webhook_platform", platform)
+		c.Next()
+	})
+}
+
+// CallbackAuth middleware verifies the signature workers put on their
+// callbacks, then restores the body for the handler
+func CallbackAuth(secret string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				abortPayloadTooLarge(c, tooLarge.Limit)
 				return
 			}
-		case "tiktok":
-			if !validateTikTokWebhook(c) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": i18n.T(c.GetString("locale"), "Failed to read request body"),
+			})
+			c.Abort()
+			return
+		}
+
+		switch err := callback.Verify(secret, c.Request.Header, c.Request.Method, c.Request.URL.Path, body, time.Now()); {
+		case errors.Is(err, callback.ErrNotConfigured):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Not Found",
+				"message": i18n.T(c.GetString("locale"), "Callbacks are not configured"),
+			})
+			c.Abort()
+			return
+		case err != nil:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized",
+				"message": i18n.T(c.GetString("locale"), "Invalid callback signature"),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	})
+}
+
+// AnalyticsReaderAuth middleware authenticates the bearer token of an
+// analytics reader, in place of JWTAuth on the routes BI tools query; the
+// request then acts for the reader's tenant and no user
+func AnalyticsReaderAuth(authenticate func(ctx context.Context, token string) (*models.AnalyticsReader, error)) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
+		if !ok || !models.IsAnalyticsToken(token) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized",
+				"message": i18n.T(c.GetString("locale"), "An analytics reader token is required"),
+			})
+			c.Abort()
+			return
+		}
+
+		reader, err := authenticate(c.Request.Context(), token)
+		if err != nil {
+			switch {
+			case errors.Is(err, models.ErrAnalyticsReaderNotFound):
 				c.JSON(http.StatusUnauthorized, gin.H{
 					"error":   "Unauthorized",
-					"message": "Invalid webhook signature",
+					"message": i18n.T(c.GetString("locale"), "Invalid or revoked analytics reader token"),
+				})
+			case errors.Is(err, models.ErrTenantSuspended):
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":   "Forbidden",
+					"message": i18n.T(c.GetString("locale"), "Tenant is suspended"),
+				})
+			case errors.Is(err, models.ErrTenantInactive):
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":   "Forbidden",
+					"message": i18n.T(c.GetString("locale"), "Tenant is not active"),
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Internal Server Error",
+					"message": i18n.T(c.GetString("locale"), "Failed to authenticate analytics reader"),
 				})
-				c.Abort()
-				return
 			}
-		default:
-			// For other platforms, implement similar validation
+			c.Abort()
+			return
+		}
+
+		c.Set("tenant_id", reader.TenantID)
+		c.Set("analytics_reader_id", reader.ID)
+		c.Next()
+	})
+}
+
+// WebhookRateLimit middleware limits webhooks per platform, and per tenant and
+// platform when a tenant was authenticated, to perMinute with bursts of burst
+func WebhookRateLimit(perMinute, burst int) gin.HandlerFunc {
+	var mu sync.Mutex
+	limiters := make(map[string]*rate.Limiter)
+
+	return gin.HandlerFunc(func(c *gin.Context) {
+		// Unknown platforms are rejected first, so they cannot create limiters
+		platform := c.Param("platform")
+		if !models.Platform(platform).Valid() {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Bad Request",
-				"message": "Unsupported platform",
+				"message": i18n.T(c.GetString("locale"), "Unsupported platform"),
 			})
 			c.Abort()
 			return
 		}
 
-		c.Set("webhook_platform", platform)
+		key := platform
+		if tenantID := c.GetString("tenant_id"); tenantID != "" {
+			key = tenantID + ":" + platform
+		}
+
+		mu.Lock()
+		limiter, ok := limiters[key]
+		if !ok {
+			limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), burst)
+			limiters[key] = limiter
+		}
+		reservation := limiter.Reserve()
+		mu.Unlock()
+
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":   "Rate Limit Exceeded",
+				"message": i18n.T(c.GetString("locale"), "Too many webhooks for this platform, please try again later"),
+			})
+			c.Abort()
+			return
+		}
 		c.Next()
 	})
 }
 
-// validateYouTubeWebhook validates YouTube webhook signature
-func validateYouTubeWebhook(c *gin.Context) bool {
-	// Implement YouTube webhook signature validation
-	// This is a placeholder - implement actual validation logic
-	signature := c.Request.Header.Get("X-Hub-Signature")
-	return signature != ""
+// BodyLimit middleware rejects request bodies larger than maxBytes. Bodies
+// without a Content-Length fail when the handler reads past the limit.
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			abortPayloadTooLarge(c, maxBytes)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	})
 }
 
-// validateTikTokWebhook validates TikTok webhook signature
-func validateTikTokWebhook(c *gin.Context) bool {
-	// Implement TikTok webhook signature validation
-	// This is a placeholder - implement actual validation logic
-	signature := c.Request.Header.Get("X-TikTok-Signature")
-	return signature != ""
+func abortPayloadTooLarge(c *gin.Context, limit int64) {
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error":   "Payload Too Large",
+		"message": i18n.M("Request body must not exceed %d bytes", limit).In(c.GetString("locale")),
+	})
+	c.Abort()
 }
 
 // Timeout middleware adds request timeout
@@ -379,26 +859,75 @@ func Timeout(timeout time.Duration) gin.HandlerFunc {
 		case <-ctx.Done():
 			c.JSON(http.StatusRequestTimeout, gin.H{
 				"error":   "Request Timeout",
-				"message": "Request took too long to process",
+				"message": i18n.T(c.GetString("locale"), "Request took too long to process"),
 			})
 			c.Abort()
 		}
 	})
 }
 
-// SecurityHeaders middleware adds security headers
-func SecurityHeaders() gin.HandlerFunc {
+// SecurityHeadersConfig holds the headers set by SecurityHeaders
+type SecurityHeadersConfig struct {
+	ContentSecurityPolicy     string // For API responses
+	DocsContentSecurityPolicy string // For DocsPaths, which serve HTML, scripts and styles
+	DocsPaths                 string // Comma-separated path prefixes of documentation pages, such as /swagger/
+	ExemptPaths               string // Comma-separated path prefixes served without security headers, such as /metrics
+	HSTSMaxAge                int    // Seconds; 0 omits Strict-Transport-Security
+	HSTSIncludeSubdomains     bool
+	HSTSPreload               bool
+}
+
+// SecurityHeaders middleware adds security headers. Documentation pages get
+// their own Content-Security-Policy, since the API one blocks the scripts and
+// styles they need, and exempt paths, read by machines, get none.
+func SecurityHeaders(cfg SecurityHeadersConfig) gin.HandlerFunc {
+	docsPaths, exemptPaths := splitList(cfg.DocsPaths), splitList(cfg.ExemptPaths)
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+
 	return gin.HandlerFunc(func(c *gin.Context) {
-		c.Writer.Header().Set("X-Content-Type-Options", "nosniff")
-		c.Writer.Header().Set("X-Frame-Options", "DENY")
-		c.Writer.Header().Set("X-XSS-Protection", "1; mode=block")
-		c.Writer.Header().Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
-		c.Writer.Header().Set("Content-Security-Policy", "default-src 'self'")
-		c.Writer.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		path := c.Request.URL.Path
+		if hasPathPrefix(path, exemptPaths) {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("X-XSS-Protection", "1; mode=block")
+		header.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		csp := cfg.ContentSecurityPolicy
+		if hasPathPrefix(path, docsPaths) {
+			csp = cfg.DocsContentSecurityPolicy
+		}
+		if csp != "" {
+			header.Set("Content-Security-Policy", csp)
+		}
 		c.Next()
 	})
 }
 
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // PaginationMiddleware middleware for handling pagination parameters
 func PaginationMiddleware() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// MaxActivityVideos caps the videos of a campaign whose activity is read
+// into the campaign's feed
+const MaxActivityVideos = 100
+
+// ActivityScope selects the records of an activity feed: those of its videos
+// and, for a campaign's feed, those of the campaign itself. Records are read
+// newest first.
+type ActivityScope struct {
+	VideoIDs   []string
+	CampaignID string
+	// Before pages through the feed: only records created before it are read
+	Before time.Time
+	Limit  int
+}
+
+// VideoPath returns the API path of a video, the prefix of the audit log
+// resources about it
+func VideoPath(videoID string) string {
+	return "/api/v1/videos/" + videoID
+}
+
+// CampaignPath returns the API path of a campaign
+func CampaignPath(campaignID string) string {
+	return "/api/v1/campaigns/" + campaignID
+}
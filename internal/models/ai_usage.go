@@ -0,0 +1,60 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// AIUsage records the tokens and estimated cost of one Bedrock request made
+// for a tenant, so AI spend can be reported per tenant
+type AIUsage struct {
+	ID           string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID     string    `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_ai_usage_tenant_created,priority:1"`
+	UserID       string    `json:"user_id,omitempty" gorm:"type:varchar(36)"`
+	VideoID      string    `json:"video_id,omitempty" gorm:"type:varchar(36);index"`    // Video the content was generated for
+	CampaignID   string    `json:"campaign_id,omitempty" gorm:"type:varchar(36);index"` // Campaign the content was generated for
+	Model        string    `json:"model" gorm:"type:varchar(255);not null"`
+	Feature      string    `json:"feature" gorm:"type:varchar(100)"` // Prompt key, or chat/<purpose>
+	InputTokens  int       `json:"input_tokens" gorm:"default:0"`
+	OutputTokens int       `json:"output_tokens" gorm:"default:0"`
+	Cost         float64   `json:"cost" gorm:"type:decimal(12,6);default:0"` // Estimated USD
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime;index:idx_ai_usage_created;index:idx_ai_usage_tenant_created,priority:2"`
+}
+
+// TableName keeps the table name singular like the metric it holds
+func (AIUsage) TableName() string {
+	return "ai_usage"
+}
+
+// AIUsageTotal sums the tokens and estimated USD cost of Bedrock requests
+type AIUsageTotal struct {
+	InputTokens  int     `json:"input_tokens" gorm:"default:0"`
+	OutputTokens int     `json:"output_tokens" gorm:"default:0"`
+	Cost         float64 `json:"cost" gorm:"type:decimal(12,6);default:0"`
+}
+
+// Add counts a request, or the requests summed in total
+func (t *AIUsageTotal) Add(total AIUsageTotal) {
+	t.InputTokens += total.InputTokens
+	t.OutputTokens += total.OutputTokens
+	t.Cost += total.Cost
+}
+
+// TenantAISpend is a tenant's AI usage summed over a period
+type TenantAISpend struct {
+	TenantID     string  `json:"tenant_id"`
+	Requests     int64   `json:"requests"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// AIUsageRepository defines the interface for AI usage operations
+type AIUsageRepository interface {
+	Create(ctx context.Context, usage *AIUsage) error
+	// SpendByTenant sums usage since the given time across all tenants,
+	// highest cost first
+	SpendByTenant(ctx context.Context, since time.Time, limit int) ([]*TenantAISpend, error)
+	// ListActivity returns the usage of the scope's videos and campaign
+	ListActivity(ctx context.Context, tenantID string, scope *ActivityScope) ([]*AIUsage, error)
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// AlertMetric defines what an alert rule watches
+type AlertMetric string
+
+const (
+	AlertViews          AlertMetric = "views"
+	AlertLikes          AlertMetric = "likes"
+	AlertComments       AlertMetric = "comments"
+	AlertShares         AlertMetric = "shares"
+	AlertEngagementRate AlertMetric = "engagement_rate" // Percent of views
+	// AlertPublicationFailures counts the failed attempts of a publication
+	AlertPublicationFailures AlertMetric = "publication_failures"
+)
+
+// AlertStatsMetrics are the metrics read from the stats of a video on a platform
+var AlertStatsMetrics = []AlertMetric{AlertViews, AlertLikes, AlertComments, AlertShares, AlertEngagementRate}
+
+// AlertCondition defines how a value compares to the rule's threshold
+type AlertCondition string
+
+const (
+	AlertAbove AlertCondition = "above" // At or above the threshold
+	AlertBelow AlertCondition = "below" // Under the threshold
+)
+
+// AlertRule notifies its user when a metric of the tenant's videos crosses a
+// threshold, optionally only for one platform or video
+type AlertRule struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string    `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_alert_rules_metric,priority:1"`
+	UserID    string    `json:"user_id" gorm:"type:varchar(36);not null;index"` // Who set it up and is notified
+	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
+	Metric    string    `json:"metric" gorm:"type:varchar(30);not null;index:idx_alert_rules_metric,priority:2"`
+	Condition string    `json:"condition" gorm:"type:varchar(10);not null"`
+	Threshold float64   `json:"threshold"`
+	Platform  string    `json:"platform,omitempty" gorm:"type:varchar(20)"`
+	VideoID   string    `json:"video_id,omitempty" gorm:"type:varchar(36)"`
+	Enabled   bool      `json:"enabled" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// AlertFiring records that a rule's condition holds for a subject, a stats
+// row or a publication, so the rule notifies once until it stops holding
+type AlertFiring struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string    `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	RuleID    string    `json:"rule_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_alert_firings_subject,priority:1"`
+	SubjectID string    `json:"subject_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_alert_firings_subject,priority:2"`
+	FiredAt   time.Time `json:"fired_at" gorm:"type:timestamp;not null"`
+}
+
+// AlertRuleRequest represents a request to create or replace an alert rule
+type AlertRuleRequest struct {
+	Name      string         `json:"name" binding:"required,max=100"`
+	Metric    AlertMetric    `json:"metric" binding:"required"`
+	Condition AlertCondition `json:"condition" binding:"required"`
+	Threshold float64        `json:"threshold"`
+	Platform  string         `json:"platform,omitempty"`
+	VideoID   string         `json:"video_id,omitempty"`
+	Enabled   *bool          `json:"enabled,omitempty"` // Defaults to true
+}
+
+// AlertRuleRepository defines the interface for alert rule operations
+type AlertRuleRepository interface {
+	Create(ctx context.Context, rule *AlertRule) error
+	// Get returns a rule of the user, or ErrAlertRuleNotFound
+	Get(ctx context.Context, tenantID, userID, id string) (*AlertRule, error)
+	// List returns the user's rules, oldest first
+	List(ctx context.Context, tenantID, userID string, limit, offset int) ([]*AlertRule, error)
+	CountByUser(ctx context.Context, tenantID, userID string) (int64, error)
+	// Update saves the rule and forgets where it fired, so the changed rule
+	// notifies afresh
+	Update(ctx context.Context, rule *AlertRule) error
+	// Delete removes a rule of the user with its firings, or returns
+	// ErrAlertRuleNotFound
+	Delete(ctx context.Context, tenantID, userID, id string) error
+	// ListEnabled returns the tenant's enabled rules watching the metrics
+	ListEnabled(ctx context.Context, tenantID string, metrics []AlertMetric) ([]*AlertRule, error)
+	// ListFirings returns where the rules currently fire
+	ListFirings(ctx context.Context, tenantID string, ruleIDs []string) ([]*AlertFiring, error)
+	// Fire records a firing, reporting false when the rule already fired
+	// for the subject
+	Fire(ctx context.Context, firing *AlertFiring) (bool, error)
+	// Resolve deletes the firings of the tenant with the given IDs
+	Resolve(ctx context.Context, tenantID string, ids []string) error
+}
+
+// Valid reports whether the metric is supported
+func (m AlertMetric) Valid() bool {
+	return m == AlertPublicationFailures || m.Stats()
+}
+
+// Stats reports whether the metric is read from video stats
+func (m AlertMetric) Stats() bool {
+	for _, metric := range AlertStatsMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid reports whether the condition is supported
+func (c AlertCondition) Valid() bool {
+	return c == AlertAbove || c == AlertBelow
+}
+
+// Applies reports whether the rule watches the video on the platform
+func (r *AlertRule) Applies(videoID, platform string) bool {
+	return (r.VideoID == "" || r.VideoID == videoID) && (r.Platform == "" || r.Platform == platform)
+}
+
+// Holds reports whether the value meets the rule's condition
+func (r *AlertRule) Holds(value float64) bool {
+	if AlertCondition(r.Condition) == AlertBelow {
+		return value < r.Threshold
+	}
+	return value >= r.Threshold
+}
+
+// AlertValue returns the value of a stats metric
+func (s *VideoStats) AlertValue(metric AlertMetric) float64 {
+	switch metric {
+	case AlertViews:
+		return float64(s.Views)
+	case AlertLikes:
+		return float64(s.Likes)
+	case AlertComments:
+		return float64(s.Comments)
+	case AlertShares:
+		return float64(s.Shares)
+	case AlertEngagementRate:
+		return s.CalculateEngagementRate()
+	}
+	return 0
+}
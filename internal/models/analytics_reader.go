@@ -0,0 +1,194 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+)
+
+// AnalyticsReaderPrefix starts the tokens of analytics readers, so they are
+// told apart from user tokens and recognized when leaked
+const AnalyticsReaderPrefix = "mfar_"
+
+// Limits of analytics queries
+const (
+	MaxAnalyticsRows    = 10000
+	MaxAnalyticsFilters = 20
+)
+
+// AnalyticsReader is a credential of a tenant for BI tools such as Looker or
+// Metabase: it only queries the tenant's stats through /analytics/query and
+// is refused everywhere else. Only the hash of its token is stored.
+type AnalyticsReader struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID    string     `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	Name        string     `json:"name" gorm:"type:varchar(100);not null"`
+	TokenHash   string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	TokenSuffix string     `json:"token_suffix" gorm:"type:varchar(4)"` // Last characters, to recognize the token
+	CreatedBy   string     `json:"created_by" gorm:"type:varchar(36)"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+
+	// Token is only returned when the reader is created
+	Token string `json:"token,omitempty" gorm:"-"`
+}
+
+// AnalyticsReaderRequest represents a request to create an analytics reader
+type AnalyticsReaderRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// HashAnalyticsToken returns the stored hash of a reader token
+func HashAnalyticsToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AnalyticsReaderRepository defines the interface for analytics reader operations
+type AnalyticsReaderRepository interface {
+	Create(ctx context.Context, reader *AnalyticsReader) error
+	List(ctx context.Context, tenantID string, limit, offset int) ([]*AnalyticsReader, error)
+	// FindByTokenHash looks the reader up in every tenant, or returns
+	// ErrAnalyticsReaderNotFound
+	FindByTokenHash(ctx context.Context, hash string) (*AnalyticsReader, error)
+	// Revoke marks the reader revoked, or returns ErrAnalyticsReaderNotFound
+	Revoke(ctx context.Context, tenantID, id string, at time.Time) error
+	MarkUsed(ctx context.Context, tenantID, id string, at time.Time) error
+}
+
+// AnalyticsDimension is a column analytics queries group and filter by
+type AnalyticsDimension string
+
+const (
+	AnalyticsPlatform    AnalyticsDimension = "platform"
+	AnalyticsVideo       AnalyticsDimension = "video_id"
+	AnalyticsCampaign    AnalyticsDimension = "campaign_id"
+	AnalyticsWorkspace   AnalyticsDimension = "workspace_id"
+	AnalyticsVideoStatus AnalyticsDimension = "video_status"
+	AnalyticsMonth       AnalyticsDimension = "created_month" // Month the video was created, as 2006-01
+)
+
+// AnalyticsDimensions are the dimensions a query can use
+var AnalyticsDimensions = []AnalyticsDimension{
+	AnalyticsPlatform, AnalyticsVideo, AnalyticsCampaign, AnalyticsWorkspace, AnalyticsVideoStatus, AnalyticsMonth,
+}
+
+// AnalyticsMeasure is a value analytics queries aggregate over the stats of
+// each video on each platform
+type AnalyticsMeasure string
+
+const (
+	AnalyticsViews       AnalyticsMeasure = "views"
+	AnalyticsLikes       AnalyticsMeasure = "likes"
+	AnalyticsComments    AnalyticsMeasure = "comments"
+	AnalyticsShares      AnalyticsMeasure = "shares"
+	AnalyticsWatchTime   AnalyticsMeasure = "watch_time" // Seconds
+	AnalyticsImpressions AnalyticsMeasure = "impressions"
+	AnalyticsRevenue     AnalyticsMeasure = "revenue"
+	AnalyticsVideos      AnalyticsMeasure = "videos"          // Distinct videos
+	AnalyticsEngagement  AnalyticsMeasure = "engagement_rate" // Likes, comments and shares, in percent of views
+)
+
+// AnalyticsMeasures are the measures a query can use
+var AnalyticsMeasures = []AnalyticsMeasure{
+	AnalyticsViews, AnalyticsLikes, AnalyticsComments, AnalyticsShares, AnalyticsWatchTime,
+	AnalyticsImpressions, AnalyticsRevenue, AnalyticsVideos, AnalyticsEngagement,
+}
+
+// AnalyticsQuery is a query of a tenant's stats, in the spirit of
+// SELECT dimensions, measures WHERE filters GROUP BY dimensions ORDER BY
+type AnalyticsQuery struct {
+	Dimensions []AnalyticsDimension `json:"dimensions,omitempty"`
+	Measures   []AnalyticsMeasure   `json:"measures" binding:"required"`
+	Filters    []AnalyticsFilter    `json:"filters,omitempty"`
+	OrderBy    []AnalyticsOrder     `json:"order_by,omitempty"`
+	Limit      int                  `json:"limit,omitempty"` // Up to MaxAnalyticsRows, the default
+}
+
+// AnalyticsFilter keeps the stats whose dimension is, or is not, one of the values
+type AnalyticsFilter struct {
+	Dimension AnalyticsDimension `json:"dimension"`
+	Operator  string             `json:"operator"` // in or not_in
+	Values    []string           `json:"values"`
+}
+
+// AnalyticsOrder sorts the rows by a selected dimension or measure
+type AnalyticsOrder struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc,omitempty"`
+}
+
+// AnalyticsResult holds the rows of a query, their values in the order of
+// the columns: the dimensions, then the measures
+type AnalyticsResult struct {
+	Columns []string        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+// Validate checks the query only uses known dimensions and measures and
+// sets its default limit
+func (q *AnalyticsQuery) Validate() error {
+	if len(q.Measures) == 0 {
+		return i18n.Errorf(ErrInvalidInput, "a query needs at least one measure")
+	}
+	for _, dimension := range q.Dimensions {
+		if !slices.Contains(AnalyticsDimensions, dimension) {
+			return i18n.Errorf(ErrInvalidInput, "unknown dimension %q, expected one of %v", dimension, AnalyticsDimensions)
+		}
+	}
+	for _, measure := range q.Measures {
+		if !slices.Contains(AnalyticsMeasures, measure) {
+			return i18n.Errorf(ErrInvalidInput, "unknown measure %q, expected one of %v", measure, AnalyticsMeasures)
+		}
+	}
+	if len(q.Filters) > MaxAnalyticsFilters {
+		return i18n.Errorf(ErrInvalidInput, "a query has at most %d filters", MaxAnalyticsFilters)
+	}
+	for _, filter := range q.Filters {
+		if !slices.Contains(AnalyticsDimensions, filter.Dimension) {
+			return i18n.Errorf(ErrInvalidInput, "unknown dimension %q, expected one of %v", filter.Dimension, AnalyticsDimensions)
+		}
+		if filter.Operator != "in" && filter.Operator != "not_in" {
+			return i18n.Errorf(ErrInvalidInput, "a filter's operator is in or not_in")
+		}
+		if len(filter.Values) == 0 {
+			return i18n.Errorf(ErrInvalidInput, "the %s filter has no values", filter.Dimension)
+		}
+	}
+	columns := q.Columns()
+	for _, order := range q.OrderBy {
+		if !slices.Contains(columns, order.Field) {
+			return i18n.Errorf(ErrInvalidInput, "order_by %q is not a selected dimension or measure", order.Field)
+		}
+	}
+	if q.Limit < 0 || q.Limit > MaxAnalyticsRows {
+		return i18n.Errorf(ErrInvalidInput, "limit must be between 1 and %d", MaxAnalyticsRows)
+	}
+	if q.Limit == 0 {
+		q.Limit = MaxAnalyticsRows
+	}
+	return nil
+}
+
+// Columns returns the names of the result's columns
+func (q *AnalyticsQuery) Columns() []string {
+	columns := make([]string, 0, len(q.Dimensions)+len(q.Measures))
+	for _, dimension := range q.Dimensions {
+		columns = append(columns, string(dimension))
+	}
+	for _, measure := range q.Measures {
+		columns = append(columns, string(measure))
+	}
+	return columns
+}
+
+// IsAnalyticsToken reports whether the bearer token is a reader's
+func IsAnalyticsToken(token string) bool {
+	return strings.HasPrefix(token, AnalyticsReaderPrefix)
+}
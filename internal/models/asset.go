@@ -0,0 +1,163 @@
+package models
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AssetKind defines the kinds of files of a tenant's asset library
+type AssetKind string
+
+const (
+	// AssetIntro and AssetOutro are clips stitched before and after videos
+	AssetIntro AssetKind = "intro"
+	AssetOutro AssetKind = "outro"
+	AssetLogo  AssetKind = "logo"
+	AssetMusic AssetKind = "music"
+)
+
+// AssetKinds lists the kinds of assets
+var AssetKinds = []AssetKind{AssetIntro, AssetOutro, AssetLogo, AssetMusic}
+
+// Valid reports whether the kind is known
+func (k AssetKind) Valid() bool {
+	return slices.Contains(AssetKinds, k)
+}
+
+// Accepts reports whether files of the content type can be assets of the
+// kind: videos for intros and outros, images for logos and audio for music
+func (k AssetKind) Accepts(contentType string) bool {
+	media, _, _ := strings.Cut(strings.ToLower(contentType), "/")
+	switch k {
+	case AssetIntro, AssetOutro:
+		return media == "video"
+	case AssetLogo:
+		return media == "image"
+	case AssetMusic:
+		return media == "audio"
+	}
+	return false
+}
+
+// AssetStatus defines the statuses of an asset
+type AssetStatus string
+
+const (
+	// AssetUploading assets wait for their file to be uploaded
+	AssetUploading AssetStatus = "uploading"
+	AssetReady     AssetStatus = "ready"
+)
+
+// LicenseRoyaltyFree files come from stock libraries, paid once and used
+// without limit; assets also take the license types of videos
+const LicenseRoyaltyFree = "royalty_free"
+
+// AssetLicense records under which terms a tenant may use an asset
+type AssetLicense struct {
+	LicenseType string `json:"license_type" binding:"required,oneof=owned exclusive non_exclusive royalty_free creative_commons public_domain"`
+	// Source is the library or author the asset comes from
+	Source string `json:"source,omitempty" binding:"max=255"`
+	// Attribution is the credit the license asks for
+	Attribution string `json:"attribution,omitempty" binding:"max=500"`
+	// ExpiresAt ends the license; expired intros and outros are no longer
+	// stitched
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Asset is a file of a tenant's library reused across videos: an intro or
+// outro clip, a logo or a music track. It is uploaded to the bucket of the
+// tenant's residency in a single part.
+type Asset struct {
+	ID          string       `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID    string       `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_assets_tenant_kind,priority:1"`
+	Kind        AssetKind    `json:"kind" gorm:"type:varchar(20);not null;index:idx_assets_tenant_kind,priority:2"`
+	Name        string       `json:"name" gorm:"type:varchar(255);not null"`
+	Status      AssetStatus  `json:"status" gorm:"type:varchar(20);not null"`
+	Filename    string       `json:"filename" gorm:"type:varchar(255);not null"`
+	ContentType string       `json:"content_type" gorm:"type:varchar(100);not null"`
+	Size        int64        `json:"size" gorm:"not null"`
+	License     AssetLicense `json:"license" gorm:"type:json;serializer:json"`
+	S3Bucket    string       `json:"-" gorm:"type:varchar(255);not null"`
+	S3Key       string       `json:"-" gorm:"type:varchar(500);not null"`
+	S3UploadID  string       `json:"-" gorm:"type:varchar(1024)"`
+	CreatedBy   string       `json:"created_by" gorm:"type:varchar(36)"`
+	// UploadedAt is when the file upload was completed
+	UploadedAt *time.Time `json:"uploaded_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Usable reports whether the asset's file is uploaded and its license
+// still runs at the time
+func (a *Asset) Usable(now time.Time) bool {
+	return a.Status == AssetReady && (a.License.ExpiresAt == nil || now.Before(*a.License.ExpiresAt))
+}
+
+// CreateAssetRequest represents a request to add a file to the asset library
+type CreateAssetRequest struct {
+	Kind        AssetKind    `json:"kind" binding:"required"`
+	Name        string       `json:"name" binding:"required,max=255"`
+	Filename    string       `json:"filename" binding:"required,max=255"`
+	ContentType string       `json:"content_type" binding:"required,max=100"`
+	Size        int64        `json:"size" binding:"required,min=1"`
+	License     AssetLicense `json:"license" binding:"required"`
+}
+
+// UpdateAssetRequest represents a request to rename an asset or change its
+// license
+type UpdateAssetRequest struct {
+	Name    *string       `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	License *AssetLicense `json:"license,omitempty"`
+}
+
+// AssetDefaults are the intro and outro a tenant stitches onto the
+// renditions of its videos
+type AssetDefaults struct {
+	ID           string `json:"id,omitempty" gorm:"primaryKey;type:varchar(36)"`
+	TenantID     string `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_asset_defaults_tenant"`
+	IntroAssetID string `json:"intro_asset_id,omitempty" gorm:"type:varchar(36)"`
+	OutroAssetID string `json:"outro_asset_id,omitempty" gorm:"type:varchar(36)"`
+	// Profiles are the rendition profiles stitched; empty stitches every one
+	Profiles  []string  `json:"profiles" gorm:"type:json;serializer:json"`
+	UpdatedBy string    `json:"updated_by,omitempty" gorm:"type:varchar(36)"`
+	CreatedAt time.Time `json:"created_at,omitempty" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" gorm:"autoUpdateTime"`
+}
+
+// AssetDefaultsRequest represents a request to choose the tenant's intro and
+// outro; an empty ID stitches none
+type AssetDefaultsRequest struct {
+	IntroAssetID string   `json:"intro_asset_id,omitempty" binding:"max=36"`
+	OutroAssetID string   `json:"outro_asset_id,omitempty" binding:"max=36"`
+	Profiles     []string `json:"profiles,omitempty"`
+}
+
+// Stitches reports whether the defaults apply to renditions of the profile
+func (d *AssetDefaults) Stitches(profile string) bool {
+	return len(d.Profiles) == 0 || slices.Contains(d.Profiles, profile)
+}
+
+// Uses reports whether the asset is the tenant's intro or outro
+func (d *AssetDefaults) Uses(assetID string) bool {
+	return assetID != "" && (d.IntroAssetID == assetID || d.OutroAssetID == assetID)
+}
+
+// AssetRepository defines the interface for asset library operations
+type AssetRepository interface {
+	Create(ctx context.Context, asset *Asset) error
+	// Get returns an asset of the tenant, or ErrAssetNotFound
+	Get(ctx context.Context, tenantID, id string) (*Asset, error)
+	// List returns the tenant's assets of the kind, of every kind when it is
+	// empty, newest first
+	List(ctx context.Context, tenantID string, kind AssetKind) ([]*Asset, error)
+	Update(ctx context.Context, asset *Asset) error
+	// Delete removes an asset of the tenant, or returns ErrAssetNotFound
+	Delete(ctx context.Context, tenantID, id string) error
+	// GetDefaults returns the tenant's defaults, or ErrNotFound when none
+	// were set
+	GetDefaults(ctx context.Context, tenantID string) (*AssetDefaults, error)
+	// PutDefaults saves the tenant's only defaults
+	PutDefaults(ctx context.Context, defaults *AssetDefaults) error
+}
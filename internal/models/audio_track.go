@@ -0,0 +1,56 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// AudioTrack is a video's audio in another language than the original,
+// stored apart from it and muxed into the renditions of the platforms that
+// offer viewers a choice of audio tracks
+type AudioTrack struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	VideoID  string `json:"video_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_audio_tracks_video_language"`
+	Language string `json:"language" gorm:"type:varchar(10);not null;uniqueIndex:idx_audio_tracks_video_language"`
+	// SourceLanguage is the language of the transcript the track was dubbed
+	// from, that of the video's original audio
+	SourceLanguage string `json:"source_language" gorm:"type:varchar(10);not null"`
+	// Voice is the text-to-speech voice the worker synthesizes the track with
+	Voice     string `json:"voice,omitempty" gorm:"type:varchar(50)"`
+	Status    string `json:"status" gorm:"type:varchar(20);not null"` // A RenditionStatus
+	FilePath  string `json:"file_path,omitempty" gorm:"type:varchar(500)"`
+	FileURL   string `json:"file_url,omitempty" gorm:"type:varchar(500)"`
+	S3Key     string `json:"s3_key,omitempty" gorm:"type:varchar(500)"`
+	FileSize  int64  `json:"file_size"`
+	ErrorMsg  string `json:"error_msg,omitempty" gorm:"type:text"`
+	CreatedBy string `json:"created_by" gorm:"type:varchar(36)"`
+	// SynthesizedAt is when the track was last ready
+	SynthesizedAt *time.Time `json:"synthesized_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// DubRequest asks for a video to be dubbed in a language
+type DubRequest struct {
+	Language string `json:"language" binding:"required,max=10"`
+	// SourceLanguage picks the transcript to translate, the first one stored
+	// when empty
+	SourceLanguage string `json:"source_language,omitempty" binding:"max=10"`
+	Voice          string `json:"voice,omitempty" binding:"max=50"`
+}
+
+// AudioTrackRepository defines the interface for audio track operations
+type AudioTrackRepository interface {
+	// Get returns the video's track in the language, or ErrAudioTrackNotFound
+	Get(ctx context.Context, tenantID, videoID, language string) (*AudioTrack, error)
+	ListByVideo(ctx context.Context, tenantID, videoID string) ([]*AudioTrack, error)
+	// Upsert saves the video's only track in the language
+	Upsert(ctx context.Context, track *AudioTrack) error
+	Delete(ctx context.Context, tenantID, videoID, language string) error
+}
+
+// Ready reports whether the track can be muxed into renditions
+func (t *AudioTrack) Ready() bool {
+	return t.Status == string(RenditionReady)
+}
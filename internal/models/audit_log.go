@@ -0,0 +1,64 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Audit log actions that are not HTTP routes
+const (
+	AuditActionImpersonationStart = "impersonation.start"
+	AuditActionUserSuspend        = "user.suspend"
+	AuditActionUserReactivate     = "user.reactivate"
+	AuditActionTenantSuspend      = "tenant.suspend"
+	AuditActionTenantReactivate   = "tenant.reactivate"
+	AuditActionPublicationApprove = "publication.approve"
+	AuditActionPublicationReject  = "publication.reject"
+)
+
+// AuditLog records an action taken in a tenant. Actions taken with an
+// impersonation token are marked as impersonated and name the admin behind
+// them, so they can be told apart from the user's own actions.
+type AuditLog struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_audit_logs_tenant_created,priority:1"`
+	UserID   string `json:"user_id" gorm:"type:varchar(36)"`
+	// Action is the route, e.g. "POST /api/v1/videos/:id/publish", or one of
+	// the AuditAction constants
+	Action    string `json:"action" gorm:"type:varchar(255);not null"`
+	Resource  string `json:"resource" gorm:"type:varchar(512)"` // Request path, or the user or tenant an action applies to
+	Status    int    `json:"status"`
+	RequestID string `json:"request_id,omitempty" gorm:"type:varchar(64)"`
+	IPAddress string `json:"ip_address,omitempty" gorm:"type:varchar(64)"`
+	Detail    string `json:"detail,omitempty" gorm:"type:varchar(512)"`
+
+	Impersonated    bool   `json:"impersonated" gorm:"not null;default:false"`
+	ImpersonatorID  string `json:"impersonator_id,omitempty" gorm:"type:varchar(36)"`
+	ImpersonationID string `json:"impersonation_id,omitempty" gorm:"type:varchar(36);index"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"type:datetime(3);autoCreateTime;index:idx_audit_logs_tenant_created,priority:2"`
+}
+
+// AuditLogRepository defines the interface for audit log operations
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *AuditLog) error
+	// List returns the tenant's entries, newest first
+	List(ctx context.Context, tenantID string, impersonatedOnly bool, limit, offset int) ([]*AuditLog, error)
+	// ListActivity returns the entries of requests made on the scope's
+	// videos and campaign
+	ListActivity(ctx context.Context, tenantID string, scope *ActivityScope) ([]*AuditLog, error)
+}
+
+// SuspensionRequest gives the reason a user or tenant is suspended or
+// reactivated, kept in the audit log
+type SuspensionRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// ImpersonateRequest represents an admin's request to act as a tenant user
+type ImpersonateRequest struct {
+	TenantID        string `json:"tenant_id" binding:"required"`
+	UserID          string `json:"user_id" binding:"required"`
+	Reason          string `json:"reason" binding:"required,max=500"`
+	DurationMinutes int    `json:"duration_minutes,omitempty" binding:"omitempty,min=1"`
+}
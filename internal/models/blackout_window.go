@@ -0,0 +1,88 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+)
+
+// BlackoutAction defines what happens to publications due in a blackout window
+type BlackoutAction string
+
+const (
+	BlackoutShift BlackoutAction = "shift" // Moved to the end of the window
+	BlackoutHold  BlackoutAction = "hold"  // Held for an approver to decide
+)
+
+// BlackoutWindow is a period the tenant publishes nothing in, e.g. a public
+// holiday or an embargo
+type BlackoutWindow struct {
+	ID       string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string    `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	Name     string    `json:"name" gorm:"type:varchar(100);not null"`
+	StartsAt time.Time `json:"starts_at" gorm:"type:timestamp;not null"`
+	EndsAt   time.Time `json:"ends_at" gorm:"type:timestamp;not null"` // Exclusive
+	// Yearly windows come back every year on the same dates, like most
+	// public holidays
+	Yearly    bool      `json:"yearly" gorm:"not null;default:false"`
+	Action    string    `json:"action" gorm:"type:varchar(10);not null"`
+	CreatedBy string    `json:"created_by" gorm:"type:varchar(36)"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BlackoutWindowRequest represents a request to create a blackout window
+type BlackoutWindowRequest struct {
+	Name     string         `json:"name" binding:"required,max=100"`
+	StartsAt time.Time      `json:"starts_at" binding:"required"`
+	EndsAt   time.Time      `json:"ends_at" binding:"required"`
+	Yearly   bool           `json:"yearly,omitempty"`
+	Action   BlackoutAction `json:"action,omitempty"` // Defaults to shift
+}
+
+// BlackoutWindowRepository defines the interface for blackout window operations
+type BlackoutWindowRepository interface {
+	Create(ctx context.Context, window *BlackoutWindow) error
+	// List returns the tenant's windows, earliest first
+	List(ctx context.Context, tenantID string) ([]*BlackoutWindow, error)
+	// Delete removes a window of the tenant, or returns
+	// ErrBlackoutWindowNotFound
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+// Valid reports whether the action is supported
+func (a BlackoutAction) Valid() bool {
+	return a == BlackoutShift || a == BlackoutHold
+}
+
+// Validate checks the window ends after it starts, and within a year when
+// it is yearly
+func (w *BlackoutWindow) Validate() error {
+	if !BlackoutAction(w.Action).Valid() {
+		return i18n.Errorf(ErrInvalidInput, "action must be shift or hold")
+	}
+	if !w.EndsAt.After(w.StartsAt) {
+		return i18n.Errorf(ErrInvalidInput, "ends_at must be after starts_at")
+	}
+	if w.Yearly && w.EndsAt.After(w.StartsAt.AddDate(1, 0, 0)) {
+		return i18n.Errorf(ErrInvalidInput, "a yearly window cannot last more than a year")
+	}
+	return nil
+}
+
+// Covers reports whether t falls in the window and, if so, when the
+// occurrence of the window covering it ends
+func (w *BlackoutWindow) Covers(t time.Time) (time.Time, bool) {
+	if !w.Yearly {
+		return w.EndsAt, !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+	}
+	// The occurrence starting the year before covers t when it runs past New Year
+	for _, year := range []int{t.Year() - 1, t.Year()} {
+		years := year - w.StartsAt.Year()
+		startsAt, endsAt := w.StartsAt.AddDate(years, 0, 0), w.EndsAt.AddDate(years, 0, 0)
+		if !t.Before(startsAt) && t.Before(endsAt) {
+			return endsAt, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// DefaultBrand is what thumbnails are rendered with for tenants without a
+// brand profile
+var DefaultBrand = transcode.Brand{Font: "DejaVu Sans", TextColor: "#FFFFFF", AccentColor: "#111111"}
+
+// BrandProfile is the font and colors of a tenant's thumbnails, and the
+// logo and footer its notification emails carry with the same colors
+type BrandProfile struct {
+	ID          string `json:"id,omitempty" gorm:"primaryKey;type:varchar(36)"`
+	TenantID    string `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_brand_profiles_tenant"`
+	Font        string `json:"font" gorm:"type:varchar(50);not null"`
+	TextColor   string `json:"text_color" gorm:"type:varchar(7);not null"`
+	AccentColor string `json:"accent_color" gorm:"type:varchar(7);not null"`
+	LogoURL     string `json:"logo_url,omitempty" gorm:"type:varchar(500)"`
+	EmailFooter string `json:"email_footer,omitempty" gorm:"type:varchar(500)"`
+	// Default is set on DefaultBrand for tenants without a profile
+	Default   bool      `json:"default" gorm:"-"`
+	UpdatedBy string    `json:"updated_by,omitempty" gorm:"type:varchar(36)"`
+	CreatedAt time.Time `json:"created_at,omitempty" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" gorm:"autoUpdateTime"`
+}
+
+// BrandProfileRequest represents a request to set a tenant's brand profile
+type BrandProfileRequest struct {
+	Font        string `json:"font" binding:"required"`
+	TextColor   string `json:"text_color" binding:"required"`
+	AccentColor string `json:"accent_color" binding:"required"`
+	// LogoURL is an https image shown atop emails, about 40 px high
+	LogoURL     string `json:"logo_url,omitempty" binding:"omitempty,url,max=500"`
+	EmailFooter string `json:"email_footer,omitempty" binding:"max=500"`
+}
+
+// BrandProfileRepository defines the interface for brand profile operations
+type BrandProfileRepository interface {
+	// Get returns the tenant's profile, or ErrBrandProfileNotFound
+	Get(ctx context.Context, tenantID string) (*BrandProfile, error)
+	// Upsert saves the tenant's only profile
+	Upsert(ctx context.Context, profile *BrandProfile) error
+}
+
+// Brand returns what the profile renders thumbnails with
+func (p *BrandProfile) Brand() transcode.Brand {
+	return transcode.Brand{Font: p.Font, TextColor: p.TextColor, AccentColor: p.AccentColor}
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Campaign represents an AI campaign. Its context, platforms, schedule and
+// progress are stored as JSON; NextRunAt copies the schedule's next run so
+// due campaigns are found by index. A campaign is the projection of its
+// events, and Version is the sequence of the last one applied.
+type Campaign struct {
+	ID          string                 `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID    string                 `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_campaigns_tenant_created,priority:1"`
+	UserID      string                 `json:"user_id" gorm:"type:varchar(36);not null"`
+	Name        string                 `json:"name" gorm:"type:varchar(255);not null"`
+	Goal        string                 `json:"goal" gorm:"type:text"`
+	Context     map[string]interface{} `json:"context" gorm:"type:json;serializer:json"`
+	Theme       string                 `json:"theme" gorm:"type:varchar(255)"`
+	Platforms   []string               `json:"platforms" gorm:"type:json;serializer:json"`
+	Language    string                 `json:"language" gorm:"type:varchar(10)"`
+	Status      CampaignStatus         `json:"status" gorm:"type:varchar(20);not null;default:'draft';index:idx_campaigns_due,priority:1"`
+	Schedule    *CampaignSchedule      `json:"schedule,omitempty" gorm:"type:json;serializer:json"`
+	NextRunAt   *time.Time             `json:"-" gorm:"index:idx_campaigns_due,priority:2"`
+	Budget      float64                `json:"budget" gorm:"type:decimal(12,2);default:0"`
+	MaxVideos   int                    `json:"max_videos" gorm:"default:0"`
+	Progress    CampaignProgress       `json:"progress" gorm:"type:json;serializer:json"`
+	Version     int64                  `json:"version" gorm:"not null;default:0"`
+	CreatedAt   time.Time              `json:"created_at" gorm:"index:idx_campaigns_tenant_created,priority:2"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	StartedAt   *time.Time             `json:"started_at,omitempty"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+}
+
+// CampaignStatus represents the status of a campaign
+type CampaignStatus string
+
+const (
+	CampaignStatusDraft     CampaignStatus = "draft"
+	CampaignStatusScheduled CampaignStatus = "scheduled"
+	CampaignStatusRunning   CampaignStatus = "running"
+	CampaignStatusPaused    CampaignStatus = "paused"
+	CampaignStatusCompleted CampaignStatus = "completed"
+	CampaignStatusFailed    CampaignStatus = "failed"
+	CampaignStatusCancelled CampaignStatus = "cancelled"
+)
+
+// CampaignSchedule represents the scheduling configuration for a campaign
+type CampaignSchedule struct {
+	Type      ScheduleType `json:"type" validate:"required,oneof=once daily weekly monthly cron"`
+	StartTime time.Time    `json:"start_time" validate:"required"`
+	EndTime   *time.Time   `json:"end_time,omitempty"`
+	CronExpr  string       `json:"cron_expr,omitempty" validate:"required_if=Type cron"`
+	Timezone  string       `json:"timezone" validate:"required"`
+	MaxRuns   int          `json:"max_runs,omitempty" validate:"omitempty,min=1"`
+	RunCount  int          `json:"run_count"`
+	LastRunAt *time.Time   `json:"last_run_at,omitempty"`
+	NextRunAt *time.Time   `json:"next_run_at,omitempty"`
+}
+
+// ScheduleType represents the type of campaign schedule
+type ScheduleType string
+
+const (
+	ScheduleTypeOnce    ScheduleType = "once"
+	ScheduleTypeDaily   ScheduleType = "daily"
+	ScheduleTypeWeekly  ScheduleType = "weekly"
+	ScheduleTypeMonthly ScheduleType = "monthly"
+	ScheduleTypeCron    ScheduleType = "cron"
+)
+
+// CampaignProgress represents the progress of a campaign
+type CampaignProgress struct {
+	CurrentStep     CampaignStep `json:"current_step"`
+	ResearchDone    bool         `json:"research_done"`
+	IdeationDone    bool         `json:"ideation_done"`
+	ValidationDone  bool         `json:"validation_done"`
+	VideosCreated   int          `json:"videos_created"`
+	VideosPublished int          `json:"videos_published"`
+	TotalCost       float64      `json:"total_cost"`
+}
+
+// CampaignStep represents the current step in a campaign workflow
+type CampaignStep string
+
+const (
+	CampaignStepResearch   CampaignStep = "research"
+	CampaignStepIdeation   CampaignStep = "ideation"
+	CampaignStepValidation CampaignStep = "validation"
+	CampaignStepExecution  CampaignStep = "execution"
+	CampaignStepCompleted  CampaignStep = "completed"
+)
+
+// CampaignRepository defines the interface for campaign operations. Writes
+// append the events that led to the campaign's new state in the same
+// transaction, so the campaign never differs from the replay of its events.
+type CampaignRepository interface {
+	Create(ctx context.Context, campaign *Campaign, events ...*CampaignEvent) error
+	// Get returns a campaign of the tenant, or ErrCampaignNotFound
+	Get(ctx context.Context, tenantID, id string) (*Campaign, error)
+	// List returns the tenant's campaigns, newest first
+	List(ctx context.Context, tenantID string, limit, offset int) ([]*Campaign, error)
+	// Update saves the campaign; an event whose sequence is already taken,
+	// by a concurrent write, fails it with ErrConflict
+	Update(ctx context.Context, campaign *Campaign, events ...*CampaignEvent) error
+	// Delete removes a campaign of the tenant but keeps its events, or
+	// returns ErrCampaignNotFound
+	Delete(ctx context.Context, tenantID, id string, events ...*CampaignEvent) error
+	// ListEvents returns the events of a campaign of the tenant up to the
+	// sequence, all of them when it is 0, oldest first
+	ListEvents(ctx context.Context, tenantID, campaignID string, until int64) ([]*CampaignEvent, error)
+	// ListDue returns the scheduled campaigns of every tenant whose next run
+	// is before the time, soonest first
+	ListDue(ctx context.Context, before time.Time, limit int) ([]*Campaign, error)
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// CampaignEventType names a change of a campaign's state
+type CampaignEventType string
+
+const (
+	CampaignEventCreated        CampaignEventType = "created"
+	CampaignEventUpdated        CampaignEventType = "updated"
+	CampaignEventScheduled      CampaignEventType = "scheduled"
+	CampaignEventStarted        CampaignEventType = "started"
+	CampaignEventStepCompleted  CampaignEventType = "step_completed"
+	CampaignEventBudgetExceeded CampaignEventType = "budget_exceeded"
+	CampaignEventPaused         CampaignEventType = "paused"
+	CampaignEventResumed        CampaignEventType = "resumed"
+	CampaignEventStopped        CampaignEventType = "stopped"
+	CampaignEventRunRecorded    CampaignEventType = "run_recorded"
+	CampaignEventDeleted        CampaignEventType = "deleted"
+)
+
+// CampaignEvent is one entry of a campaign's append-only history. Events are
+// numbered from 1 per campaign, and replaying them in order rebuilds the
+// campaign; they outlive a deleted campaign.
+type CampaignEvent struct {
+	ID         string            `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID   string            `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	CampaignID string            `json:"campaign_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_campaign_events_sequence,priority:1"`
+	Sequence   int64             `json:"sequence" gorm:"not null;uniqueIndex:idx_campaign_events_sequence,priority:2"`
+	Type       CampaignEventType `json:"type" gorm:"type:varchar(32);not null"`
+	Data       CampaignEventData `json:"data" gorm:"type:json;serializer:json"`
+	OccurredAt time.Time         `json:"occurred_at" gorm:"not null"`
+}
+
+// CampaignEventData holds what an event changed; which fields are set depends
+// on its type
+type CampaignEventData struct {
+	// Campaign is the definition a created or updated event set
+	Campaign *Campaign `json:"campaign,omitempty"`
+	// Schedule is the schedule a scheduled event set
+	Schedule *CampaignSchedule `json:"schedule,omitempty"`
+	// Step and Cost are the step a step_completed event finished and what it
+	// cost
+	Step CampaignStep `json:"step,omitempty"`
+	Cost float64      `json:"cost,omitempty"`
+	// NextRunAt is the next run a run_recorded event planned
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Conversation represents a persisted multi-turn AI assistant conversation
+type Conversation struct {
+	ID        string                 `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string                 `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_conversations_owner"`
+	UserID    string                 `json:"user_id" gorm:"type:varchar(36);not null;index:idx_conversations_owner"`
+	VideoID   string                 `json:"video_id,omitempty" gorm:"type:varchar(36);index"`
+	Purpose   string                 `json:"purpose" gorm:"type:varchar(50);not null"`
+	Title     string                 `json:"title" gorm:"type:varchar(255)"`
+	Messages  []*ConversationMessage `json:"messages,omitempty" gorm:"foreignKey:ConversationID;constraint:OnDelete:CASCADE"`
+	ExpiresAt time.Time              `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time              `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time              `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ConversationMessage represents a single message in a persisted conversation
+type ConversationMessage struct {
+	ID             string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	ConversationID string    `json:"conversation_id" gorm:"type:varchar(36);not null;index"`
+	Role           string    `json:"role" gorm:"type:varchar(20);not null"`
+	Content        string    `json:"content" gorm:"type:text;not null"`
+	TokensUsed     int       `json:"tokens_used" gorm:"default:0"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ConversationRepository defines data access methods for conversations
+type ConversationRepository interface {
+	Create(ctx context.Context, conversation *Conversation) error
+	GetByID(ctx context.Context, tenantID, userID, id string) (*Conversation, error)
+	ListByUser(ctx context.Context, tenantID, userID string, limit, offset int) ([]*Conversation, error)
+	AppendMessages(ctx context.Context, tenantID, id string, messages []*ConversationMessage, expiresAt time.Time) error
+	Delete(ctx context.Context, tenantID, userID, id string) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// IsExpired checks if the conversation TTL has elapsed at now
+func (c *Conversation) IsExpired(now time.Time) bool {
+	return now.After(c.ExpiresAt)
+}
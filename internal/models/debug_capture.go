@@ -0,0 +1,72 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// DebugCaptureSession is a window during which the API requests of a tenant
+// are captured for support. Tenants opt in through their admins; a session
+// ends when it expires or is ended early.
+type DebugCaptureSession struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string     `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_debug_sessions_tenant_expires,priority:1"`
+	UserID    string     `json:"user_id" gorm:"type:varchar(36)"` // Admin who enabled it
+	Reason    string     `json:"reason" gorm:"type:varchar(500)"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"type:datetime(3);not null;index:idx_debug_sessions_tenant_expires,priority:2"`
+	EndedAt   *time.Time `json:"ended_at,omitempty" gorm:"type:datetime(3)"`
+	CreatedAt time.Time  `json:"created_at" gorm:"type:datetime(3);autoCreateTime"`
+}
+
+// DebugCapture is a request and its response captured during a session.
+// Headers, query strings and bodies are sanitized before they are stored,
+// and stored encrypted; the capture is deleted once ExpiresAt has passed.
+type DebugCapture struct {
+	ID         string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID   string `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_debug_captures_tenant_created,priority:1"`
+	SessionID  string `json:"session_id" gorm:"type:varchar(36);not null;index"`
+	UserID     string `json:"user_id" gorm:"type:varchar(36)"`
+	RequestID  string `json:"request_id,omitempty" gorm:"type:varchar(64)"`
+	Method     string `json:"method" gorm:"type:varchar(10);not null"`
+	Route      string `json:"route" gorm:"type:varchar(255)"`
+	Path       string `json:"path" gorm:"type:varchar(512)"`
+	Status     int    `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+
+	// Left out of listings; read a single capture to get them
+	Query                 string `json:"query,omitempty" gorm:"type:text;serializer:encrypted"`
+	RequestHeaders        string `json:"request_headers,omitempty" gorm:"type:text;serializer:encrypted"` // JSON object
+	RequestBody           string `json:"request_body,omitempty" gorm:"type:mediumtext;serializer:encrypted"`
+	RequestBodyTruncated  bool   `json:"request_body_truncated,omitempty"`
+	ResponseHeaders       string `json:"response_headers,omitempty" gorm:"type:text;serializer:encrypted"` // JSON object
+	ResponseBody          string `json:"response_body,omitempty" gorm:"type:mediumtext;serializer:encrypted"`
+	ResponseBodyTruncated bool   `json:"response_body_truncated,omitempty"`
+
+	ExpiresAt time.Time `json:"expires_at" gorm:"type:datetime(3);not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:datetime(3);autoCreateTime;index:idx_debug_captures_tenant_created,priority:2"`
+}
+
+// DebugCaptureRepository defines the interface for debug capture operations
+type DebugCaptureRepository interface {
+	CreateSession(ctx context.Context, session *DebugCaptureSession) error
+	// GetActiveSession returns the tenant's session capturing at the given
+	// time, or ErrDebugCaptureInactive
+	GetActiveSession(ctx context.Context, tenantID string, at time.Time) (*DebugCaptureSession, error)
+	// EndSessions ends the tenant's sessions still capturing at the given time
+	EndSessions(ctx context.Context, tenantID string, at time.Time) error
+	Create(ctx context.Context, capture *DebugCapture) error
+	// List returns the tenant's captures newest first, optionally of one
+	// session, without their headers and bodies
+	List(ctx context.Context, tenantID, sessionID string, limit, offset int) ([]*DebugCapture, error)
+	GetByID(ctx context.Context, tenantID, id string) (*DebugCapture, error)
+	// DeleteExpired deletes the captures that expired before the given time
+	// and the sessions that expired before sessionsBefore
+	DeleteExpired(ctx context.Context, before, sessionsBefore time.Time) (int64, error)
+}
+
+// EnableDebugCaptureRequest represents an admin's request to capture the
+// tenant's requests for a while
+type EnableDebugCaptureRequest struct {
+	Reason          string `json:"reason" binding:"required,max=500"`
+	DurationMinutes int    `json:"duration_minutes,omitempty" binding:"omitempty,min=1"`
+}
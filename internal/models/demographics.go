@@ -0,0 +1,61 @@
+package models
+
+// AgeBuckets are the age ranges demographics are normalized to. Platforms
+// splitting older viewers further are folded into 55+.
+var AgeBuckets = []string{"13-17", "18-24", "25-34", "35-44", "45-54", "55+"}
+
+// Normalized genders; viewers who did not say or identify otherwise are other
+const (
+	GenderFemale = "female"
+	GenderMale   = "male"
+	GenderOther  = "other"
+)
+
+// Demographics describes the audience of a video on a platform as shares of
+// its viewers, from 0 to 1, whatever shape the platform reports it in.
+// Platforms leave out small groups, so shares may sum to less than 1.
+type Demographics struct {
+	Age       map[string]float64 `json:"age,omitempty"`       // By AgeBuckets
+	Gender    map[string]float64 `json:"gender,omitempty"`    // female, male or other
+	Countries map[string]float64 `json:"countries,omitempty"` // By ISO 3166-1 alpha-2 code
+}
+
+// Empty reports whether no part of the audience is known
+func (d *Demographics) Empty() bool {
+	return d == nil || (len(d.Age) == 0 && len(d.Gender) == 0 && len(d.Countries) == 0)
+}
+
+// CombineDemographics merges the demographics of a video's stats rows into
+// the audience across platforms, weighting each row by its views. Nil when
+// no row has demographics and views.
+func CombineDemographics(stats []*VideoStats) *Demographics {
+	var views int64
+	for _, s := range stats {
+		if !s.Demographics.Empty() {
+			views += s.Views
+		}
+	}
+	if views == 0 {
+		return nil
+	}
+
+	combined := &Demographics{}
+	add := func(into *map[string]float64, shares map[string]float64, weight float64) {
+		for key, share := range shares {
+			if *into == nil {
+				*into = make(map[string]float64)
+			}
+			(*into)[key] += share * weight
+		}
+	}
+	for _, s := range stats {
+		if s.Demographics.Empty() || s.Views == 0 {
+			continue
+		}
+		weight := float64(s.Views) / float64(views)
+		add(&combined.Age, s.Demographics.Age, weight)
+		add(&combined.Gender, s.Demographics.Gender, weight)
+		add(&combined.Countries, s.Demographics.Countries, weight)
+	}
+	return combined
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// EncodingPreset replaces, for a tenant, the settings a rendition profile of
+// transcode.Profiles is transcoded with
+type EncodingPreset struct {
+	ID               string  `json:"id,omitempty" gorm:"primaryKey;type:varchar(36)"`
+	TenantID         string  `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_encoding_presets_tenant_profile"`
+	Profile          string  `json:"profile" gorm:"type:varchar(50);not null;uniqueIndex:idx_encoding_presets_tenant_profile"`
+	Width            int     `json:"width" gorm:"not null"`
+	Height           int     `json:"height" gorm:"not null"`
+	VideoBitrateKbps int     `json:"video_bitrate_kbps" gorm:"not null"`
+	AudioBitrateKbps int     `json:"audio_bitrate_kbps" gorm:"not null"`
+	FrameRate        int     `json:"frame_rate" gorm:"not null"`
+	VideoCodec       string  `json:"video_codec" gorm:"type:varchar(20);not null"`
+	AudioCodec       string  `json:"audio_codec" gorm:"type:varchar(20);not null"`
+	LoudnessLUFS     float64 `json:"loudness_lufs,omitempty"` // 0 leaves the audio level as it is
+	// Default is set on the built-in settings of profiles the tenant has not
+	// tuned
+	Default   bool      `json:"default" gorm:"-"`
+	UpdatedBy string    `json:"updated_by,omitempty" gorm:"type:varchar(36)"`
+	CreatedAt time.Time `json:"created_at,omitempty" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" gorm:"autoUpdateTime"`
+}
+
+// EncodingPresetRequest represents a request to tune a rendition profile
+type EncodingPresetRequest struct {
+	Width            int     `json:"width" binding:"required"`
+	Height           int     `json:"height" binding:"required"`
+	VideoBitrateKbps int     `json:"video_bitrate_kbps" binding:"required"`
+	AudioBitrateKbps int     `json:"audio_bitrate_kbps" binding:"required"`
+	FrameRate        int     `json:"frame_rate" binding:"required"`
+	VideoCodec       string  `json:"video_codec,omitempty"` // Defaults to h264
+	AudioCodec       string  `json:"audio_codec,omitempty"` // Defaults to aac
+	LoudnessLUFS     float64 `json:"loudness_lufs,omitempty"`
+}
+
+// EncodingPresetRepository defines the interface for encoding preset operations
+type EncodingPresetRepository interface {
+	// Get returns the tenant's preset of the profile, or
+	// ErrEncodingPresetNotFound
+	Get(ctx context.Context, tenantID, profile string) (*EncodingPreset, error)
+	// List returns the tenant's presets by profile
+	List(ctx context.Context, tenantID string) ([]*EncodingPreset, error)
+	// Upsert saves the tenant's only preset of the profile
+	Upsert(ctx context.Context, preset *EncodingPreset) error
+	// Delete removes the tenant's preset of the profile, or returns
+	// ErrEncodingPresetNotFound
+	Delete(ctx context.Context, tenantID, profile string) error
+}
@@ -7,25 +7,118 @@ var (
 	// User errors
 	ErrUserNotFound       = errors.New("user not found")
 	ErrUserInactive       = errors.New("user is inactive")
+	ErrUserSuspended      = errors.New("user is suspended")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrWeakPassword       = errors.New("password does not meet requirements")
 
+	// Login errors
+	ErrLoginChallengeInvalid = errors.New("login verification code is invalid or expired")
+
 	// Tenant errors
 	ErrTenantNotFound      = errors.New("tenant not found")
 	ErrTenantInactive      = errors.New("tenant is inactive")
+	ErrTenantSuspended     = errors.New("tenant is suspended")
 	ErrTenantAlreadyExists = errors.New("tenant already exists")
 
 	// Video errors
-	ErrVideoNotFound      = errors.New("video not found")
-	ErrVideoAlreadyExists = errors.New("video already exists")
-	ErrInvalidVideoFormat = errors.New("invalid video format")
-	ErrVideoProcessing    = errors.New("video is currently being processed")
+	ErrVideoNotFound       = errors.New("video not found")
+	ErrVideoAlreadyExists  = errors.New("video already exists")
+	ErrInvalidVideoFormat  = errors.New("invalid video format")
+	ErrVideoProcessing     = errors.New("video is currently being processed")
+	ErrVideoArchived       = errors.New("video is archived")
+	ErrVideoNotArchived    = errors.New("video is not archived")
+	ErrRightsExpired       = errors.New("video rights expired")
+	ErrPlatformNotLicensed = errors.New("video is not licensed for the platform")
+	ErrRenditionNotReady   = errors.New("video rendition is not transcoded yet")
+	ErrMediaNotProbed      = errors.New("video file has not been inspected yet")
+	ErrFramesNotExtracted  = errors.New("video frames have not been extracted yet")
+
+	// Transfer errors
+	ErrTransferNotFound   = errors.New("video transfer not found")
+	ErrTransferNotPending = errors.New("video transfer is no longer pending")
+	ErrTransferInProgress = errors.New("video already has a pending transfer")
+
+	// Prompt catalog errors
+	ErrPromptCatalogInvalid = errors.New("prompt catalog has errors")
+
+	// Watermark errors
+	ErrWatermarkNotFound = errors.New("watermark not found")
+
+	// Transcript errors
+	ErrTranscriptNotFound = errors.New("transcript not found")
+
+	// Video summary errors
+	ErrVideoSummaryNotFound = errors.New("video summary not found")
 
 	// Publication errors
 	ErrPublicationNotFound = errors.New("publication job not found")
 	ErrPublicationFailed   = errors.New("publication failed")
 	ErrInvalidPlatform     = errors.New("invalid platform")
+	ErrAlreadyUnpublished  = errors.New("publication is already unpublished")
+
+	// Conversation errors
+	ErrConversationNotFound = errors.New("conversation not found")
+	ErrConversationExpired  = errors.New("conversation has expired")
+
+	// Magic brush errors
+	ErrMagicBrushBatchNotFound = errors.New("magic brush batch not found")
+
+	// Webhook errors
+	ErrWebhookQueueFull           = errors.New("webhook queue is full")
+	ErrQuarantinedWebhookNotFound = errors.New("quarantined webhook not found")
+	ErrSubscriptionNotFound       = errors.New("webhook subscription not found")
+	ErrSubscriptionUnsupported    = errors.New("workspace is connected to no platform pushing notifications")
+	ErrWebhookCallbackNotSet      = errors.New("webhook callback URL is not configured")
+
+	// Job errors
+	ErrJobNotFound = errors.New("job not found")
+
+	// Stats backfill errors
+	ErrBackfillNotFound    = errors.New("stats backfill not found")
+	ErrBackfillInProgress  = errors.New("a stats backfill of this platform is already in progress")
+	ErrBackfillUnsupported = errors.New("platform does not report past daily stats")
+
+	// Retention errors
+	ErrRetentionUnsupported = errors.New("video is not published on a platform reporting retention")
+
+	// Alert errors
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+	// Smart list errors
+	ErrSmartListNotFound = errors.New("smart list not found")
+
+	// Campaign errors
+	ErrCampaignNotFound = errors.New("campaign not found")
+
+	// Video upload errors
+	ErrVideoUploadNotFound = errors.New("video upload not found")
+
+	// Analytics reader errors
+	ErrAnalyticsReaderNotFound = errors.New("analytics reader not found")
+
+	// Blackout window errors
+	ErrBlackoutWindowNotFound = errors.New("blackout window not found")
+
+	// Series errors
+	ErrSeriesNotFound = errors.New("series not found")
+
+	// Encoding preset errors
+	ErrEncodingPresetNotFound = errors.New("encoding preset not found")
+
+	// Audio track errors
+	ErrAudioTrackNotFound = errors.New("audio track not found")
+
+	// Thumbnail errors
+	ErrBrandProfileNotFound     = errors.New("brand profile not found")
+	ErrThumbnailVariantNotFound = errors.New("thumbnail variant not found")
+
+	// Asset errors
+	ErrAssetNotFound = errors.New("asset not found")
+
+	// Debug capture errors
+	ErrDebugCaptureNotFound = errors.New("debug capture not found")
+	ErrDebugCaptureInactive = errors.New("debug capture is not enabled")
 
 	// General errors
 	ErrInvalidInput  = errors.New("invalid input")
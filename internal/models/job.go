@@ -0,0 +1,181 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+)
+
+// JobType defines the asynchronous operations tracked as jobs
+type JobType string
+
+const (
+	// JobTranscode processes an uploaded video until it is ready
+	JobTranscode JobType = "transcode"
+	// JobRendition transcodes the renditions the platforms take
+	JobRendition JobType = "rendition"
+	// JobStatsImport imports past platform stats (a stats backfill)
+	JobStatsImport JobType = "stats_import"
+	// JobStatsSync syncs a tenant's platform stats
+	JobStatsSync JobType = "stats_sync"
+	// JobPublish uploads and releases a publication
+	JobPublish JobType = "publish"
+	// JobRestore retrieves an archived video from cold storage
+	JobRestore JobType = "restore"
+	// JobDubbing synthesizes a dubbed audio track
+	JobDubbing JobType = "dubbing"
+	// JobThumbnail renders a branded thumbnail variant
+	JobThumbnail JobType = "thumbnail"
+	// JobMagicBrush applies a bulk magic brush to one video
+	JobMagicBrush JobType = "magic_brush"
+)
+
+// JobTypes lists the job types
+var JobTypes = []JobType{JobTranscode, JobRendition, JobStatsImport, JobStatsSync, JobPublish, JobRestore, JobDubbing, JobThumbnail, JobMagicBrush}
+
+// JobState defines the states of a job
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+)
+
+// Job tracks an asynchronous operation on a resource, whatever the
+// subsystem running it
+type Job struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_jobs_tenant_resource,priority:1"`
+	UserID   string `json:"user_id,omitempty" gorm:"type:varchar(36)"` // Who started it, empty for background jobs
+	Type     string `json:"type" gorm:"type:varchar(20);not null;index:idx_jobs_tenant_resource,priority:2"`
+	// ResourceID is the video, backfill, publication or tenant the job works on
+	ResourceID string   `json:"resource_id" gorm:"type:varchar(36);not null;index:idx_jobs_tenant_resource,priority:3"`
+	State      string   `json:"state" gorm:"type:varchar(20);not null"`
+	Percent    int      `json:"percent"` // Percent complete, 100 once succeeded
+	Error      string   `json:"error,omitempty" gorm:"type:text"`
+	Links      JobLinks `json:"links" gorm:"type:json;serializer:json"`
+
+	StartedAt  *time.Time `json:"started_at,omitempty" gorm:"type:datetime(3)"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" gorm:"type:datetime(3);index"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"type:datetime(3);autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"type:datetime(3);autoUpdateTime"`
+}
+
+// Localize translates the error of the job to lang where the catalog knows
+// it, such as the failure message of a publication
+func (j *Job) Localize(lang string) {
+	j.Error = i18n.T(lang, j.Error)
+}
+
+// JobLinks are the API paths of what a job works on and produces, by name
+type JobLinks map[string]string
+
+// JobRef names the job of a resource: its type, the resource and the links
+// to record on it
+type JobRef struct {
+	Type       JobType
+	ResourceID string
+	Links      JobLinks
+}
+
+// TranscodeJob is the processing of an uploaded video
+func TranscodeJob(videoID string) JobRef {
+	return JobRef{Type: JobTranscode, ResourceID: videoID, Links: JobLinks{"video": "/api/v1/videos/" + videoID}}
+}
+
+// RenditionJob is the transcoding of a video's platform renditions
+func RenditionJob(videoID string) JobRef {
+	return JobRef{Type: JobRendition, ResourceID: videoID, Links: JobLinks{
+		"video":      "/api/v1/videos/" + videoID,
+		"renditions": "/api/v1/videos/" + videoID + "/renditions",
+	}}
+}
+
+// DubbingJob is the synthesis of a video's dubbed audio track
+func DubbingJob(track *AudioTrack) JobRef {
+	return JobRef{Type: JobDubbing, ResourceID: track.ID, Links: JobLinks{
+		"video":        "/api/v1/videos/" + track.VideoID,
+		"audio_tracks": "/api/v1/videos/" + track.VideoID + "/audio-tracks",
+	}}
+}
+
+// ThumbnailJob is the rendering of a thumbnail variant
+func ThumbnailJob(variant *ThumbnailVariant) JobRef {
+	return JobRef{Type: JobThumbnail, ResourceID: variant.ID, Links: JobLinks{
+		"video":      "/api/v1/videos/" + variant.VideoID,
+		"thumbnails": "/api/v1/videos/" + variant.VideoID + "/thumbnails",
+	}}
+}
+
+// MagicBrushJob is the generation of a bulk magic brush for one video
+func MagicBrushJob(result *MagicBrushResult) JobRef {
+	return JobRef{Type: JobMagicBrush, ResourceID: result.ID, Links: JobLinks{
+		"video": "/api/v1/videos/" + result.VideoID,
+		"batch": "/api/v1/ai/magic-brush/bulk/" + result.BatchID,
+	}}
+}
+
+// StatsImportJob is a stats backfill
+func StatsImportJob(backfillID string) JobRef {
+	return JobRef{Type: JobStatsImport, ResourceID: backfillID, Links: JobLinks{"backfill": "/api/v1/stats/backfills/" + backfillID}}
+}
+
+// StatsSyncJob is a sync of the tenant's platform stats
+func StatsSyncJob(tenantID string) JobRef {
+	return JobRef{Type: JobStatsSync, ResourceID: tenantID, Links: JobLinks{"stats": "/api/v1/stats/dashboard"}}
+}
+
+// PublishJob is the upload and release of a publication
+func PublishJob(publication *PublicationJob) JobRef {
+	return JobRef{Type: JobPublish, ResourceID: publication.ID, Links: JobLinks{
+		"video":        "/api/v1/videos/" + publication.VideoID,
+		"publications": "/api/v1/videos/" + publication.VideoID + "/publications",
+	}}
+}
+
+// RestoreJob is the retrieval of an archived video
+func RestoreJob(videoID string) JobRef {
+	return JobRef{Type: JobRestore, ResourceID: videoID, Links: JobLinks{
+		"video":   "/api/v1/videos/" + videoID,
+		"archive": "/api/v1/videos/" + videoID + "/archive",
+	}}
+}
+
+// JobFilter narrows a job listing; empty fields match every job
+type JobFilter struct {
+	Type       JobType
+	State      JobState
+	ResourceID string
+}
+
+// Finished reports whether the job succeeded or failed
+func (j *Job) Finished() bool {
+	return j.State == string(JobSucceeded) || j.State == string(JobFailed)
+}
+
+// Valid reports whether t is a known job type
+func (t JobType) Valid() bool {
+	for _, known := range JobTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// JobRepository defines the interface for job operations
+type JobRepository interface {
+	Create(ctx context.Context, job *Job) error
+	GetByID(ctx context.Context, tenantID, id string) (*Job, error)
+	// GetUnfinished returns the newest unfinished job of the resource
+	GetUnfinished(ctx context.Context, tenantID string, jobType JobType, resourceID string) (*Job, error)
+	Update(ctx context.Context, job *Job) error
+	// List returns the tenant's jobs matching the filter, newest first
+	List(ctx context.Context, tenantID string, filter JobFilter, limit, offset int) ([]*Job, error)
+	// DeleteFinishedBefore deletes the jobs of every tenant finished before
+	// the given time
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// JobLease records which server instance runs a background job. Another
+// instance can take the lease over only once it has expired, so each job
+// runs on one replica at a time.
+type JobLease struct {
+	// ID is the job name
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Holder    string    `json:"holder" gorm:"type:varchar(255);not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"type:datetime(3);not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
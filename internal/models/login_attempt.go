@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// LoginOutcome defines how a login attempt ended
+type LoginOutcome string
+
+const (
+	LoginSucceeded LoginOutcome = "succeeded"
+	LoginFailed    LoginOutcome = "failed"
+	// LoginChallenged withheld the token until the user confirms the login
+	// with the code emailed to them
+	LoginChallenged LoginOutcome = "challenged"
+	// LoginVerified is a challenged login the user confirmed
+	LoginVerified LoginOutcome = "verified"
+)
+
+// LoginAttempt records a login to a user's account, with the network and
+// device it came from
+type LoginAttempt struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	UserID   string `json:"user_id" gorm:"type:varchar(36);not null;index:idx_login_attempts_user_created,priority:1"`
+	Outcome  string `json:"outcome" gorm:"type:varchar(20);not null"`
+	IP       string `json:"ip" gorm:"type:varchar(64)"`
+	// Country is the ISO 3166 code of the IP, empty when unknown
+	Country   string `json:"country,omitempty" gorm:"type:varchar(2)"`
+	DeviceID  string `json:"device_id" gorm:"type:varchar(64)"` // Fingerprint of the user agent
+	UserAgent string `json:"user_agent" gorm:"type:varchar(512)"`
+	// NewDevice and NewCountry are set when the login came from a device or
+	// country the user never signed in from before
+	NewDevice  bool      `json:"new_device"`
+	NewCountry bool      `json:"new_country"`
+	CreatedAt  time.Time `json:"created_at" gorm:"type:datetime(3);autoCreateTime;index:idx_login_attempts_user_created,priority:2"`
+}
+
+// LoginChallenge withholds a suspicious login until the user confirms it
+// with the code emailed to them
+type LoginChallenge struct {
+	ID        string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string `json:"-" gorm:"type:varchar(36);not null;index"`
+	UserID    string `json:"-" gorm:"type:varchar(36);not null"`
+	CodeHash  string `json:"-" gorm:"type:varchar(64);not null"`
+	Attempts  int    `json:"-" gorm:"not null;default:0"`
+	IP        string `json:"-" gorm:"type:varchar(64)"`
+	Country   string `json:"-" gorm:"type:varchar(2)"`
+	DeviceID  string `json:"-" gorm:"type:varchar(64)"`
+	UserAgent string `json:"-" gorm:"type:varchar(512)"`
+	// Reason records why the login must be confirmed. It is not shown: a
+	// password guesser would learn from it.
+	Reason     string     `json:"-" gorm:"type:varchar(255)"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"type:datetime(3);not null"`
+	VerifiedAt *time.Time `json:"-" gorm:"type:datetime(3)"`
+	CreatedAt  time.Time  `json:"-" gorm:"type:datetime(3);autoCreateTime"`
+}
+
+// LoginClient is where a login comes from
+type LoginClient struct {
+	IP        string
+	Country   string
+	UserAgent string
+}
+
+// VerifyLoginRequest confirms a challenged login
+type VerifyLoginRequest struct {
+	ChallengeID string `json:"challenge_id" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+}
+
+// LoginFootprint is what a user's past logins say about them
+type LoginFootprint struct {
+	// Devices and Countries the user signed in from
+	Devices   []string
+	Countries []string
+	// LastSucceededAt is the time of the last login granted, nil for a user
+	// who never signed in
+	LastSucceededAt *time.Time
+}
+
+// LoginAttemptRepository defines the interface for login attempt operations
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *LoginAttempt) error
+	// List returns the user's attempts, newest first
+	List(ctx context.Context, tenantID, userID string, limit, offset int) ([]*LoginAttempt, error)
+	// Footprint summarizes the user's granted logins
+	Footprint(ctx context.Context, tenantID, userID string) (*LoginFootprint, error)
+	// CountFailed counts the user's failed attempts since the given time
+	CountFailed(ctx context.Context, tenantID, userID string, since time.Time) (int64, error)
+	CreateChallenge(ctx context.Context, challenge *LoginChallenge) error
+	// GetChallenge reads a challenge of any tenant: it is answered before the
+	// user is signed in
+	GetChallenge(ctx context.Context, id string) (*LoginChallenge, error)
+	UpdateChallenge(ctx context.Context, challenge *LoginChallenge) error
+}
+
+// DeviceFingerprint identifies the device of a user agent
+func DeviceFingerprint(userAgent string) string {
+	sum := sha256.Sum256([]byte(userAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Granted reports whether the attempt signed the user in
+func (a *LoginAttempt) Granted() bool {
+	return a.Outcome == string(LoginSucceeded) || a.Outcome == string(LoginVerified)
+}
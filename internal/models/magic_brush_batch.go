@@ -0,0 +1,99 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// MaxMagicBrushBatchVideos bounds the videos of a bulk magic brush request
+const MaxMagicBrushBatchVideos = 100
+
+// Magic brush batch statuses; a completed batch may have failed videos
+const (
+	MagicBrushBatchQueued    = "queued"
+	MagicBrushBatchRunning   = "running"
+	MagicBrushBatchCompleted = "completed"
+)
+
+// MagicBrushBatch applies a brush type to a selection of videos in the
+// background, one result per video, summing the AI usage of them all
+type MagicBrushBatch struct {
+	ID        string                 `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string                 `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	UserID    string                 `json:"user_id" gorm:"type:varchar(36)"`
+	BrushType string                 `json:"brush_type" gorm:"type:varchar(20);not null"`
+	Model     string                 `json:"model,omitempty" gorm:"type:varchar(50)"`
+	Language  string                 `json:"language,omitempty" gorm:"type:varchar(10)"`
+	Tone      string                 `json:"tone,omitempty" gorm:"type:varchar(20)"`
+	MaxLength int                    `json:"max_length,omitempty"`
+	Context   map[string]interface{} `json:"context,omitempty" gorm:"type:json;serializer:json"` // Shared by the videos
+	Status    string                 `json:"status" gorm:"type:varchar(20);not null;index"`
+
+	// Progress
+	VideosTotal     int          `json:"videos_total"`
+	VideosSucceeded int          `json:"videos_succeeded"`
+	VideosFailed    int          `json:"videos_failed"`
+	Usage           AIUsageTotal `json:"usage" gorm:"embedded;embeddedPrefix:usage_"`
+
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Results []*MagicBrushResult `json:"results,omitempty" gorm:"-"`
+}
+
+// MagicBrushResult is what a batch generated for one of its videos, its
+// Status being the JobState of the video's magic_brush job
+type MagicBrushResult struct {
+	ID          string       `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID    string       `json:"tenant_id" gorm:"type:varchar(36);not null"`
+	BatchID     string       `json:"batch_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_magic_brush_results_batch_video,priority:1"`
+	VideoID     string       `json:"video_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_magic_brush_results_batch_video,priority:2"`
+	Position    int          `json:"-"` // Of the video in the request
+	Status      string       `json:"status" gorm:"type:varchar(20);not null"`
+	Content     string       `json:"content,omitempty" gorm:"type:text"`
+	Error       string       `json:"error,omitempty" gorm:"type:text"`
+	Usage       AIUsageTotal `json:"usage" gorm:"embedded;embeddedPrefix:usage_"`
+	ProcessedAt *time.Time   `json:"processed_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// JobID is the job tracking the video, set when the batch is started
+	JobID string `json:"job_id,omitempty" gorm:"-"`
+}
+
+// BulkMagicBrushRequest represents a request to apply a brush type to
+// several videos, selected or matching one of the user's smart lists. Each
+// video's title, description and duration fill the prompt on top of the
+// shared context.
+type BulkMagicBrushRequest struct {
+	VideoIDs    []string               `json:"video_ids,omitempty"`
+	SmartListID string                 `json:"smart_list_id,omitempty"`
+	BrushType   string                 `json:"brush_type" binding:"required"`
+	Context     map[string]interface{} `json:"context,omitempty"`
+	Language    string                 `json:"language,omitempty"`
+	Tone        string                 `json:"tone,omitempty"`
+	MaxLength   int                    `json:"max_length,omitempty"`
+	Model       string                 `json:"model,omitempty"`
+}
+
+// MagicBrushBatchRepository defines the interface for bulk magic brush
+// operations
+type MagicBrushBatchRepository interface {
+	// Create saves the batch with its results
+	Create(ctx context.Context, batch *MagicBrushBatch, results []*MagicBrushResult) error
+	// Get returns the batch, or ErrMagicBrushBatchNotFound
+	Get(ctx context.Context, tenantID, id string) (*MagicBrushBatch, error)
+	// ListUnfinished returns the unfinished batches of every tenant, oldest
+	// first
+	ListUnfinished(ctx context.Context, limit int) ([]*MagicBrushBatch, error)
+	Update(ctx context.Context, batch *MagicBrushBatch) error
+	// ListResults returns the batch's results in the order of its videos
+	ListResults(ctx context.Context, tenantID, batchID string) ([]*MagicBrushResult, error)
+	UpdateResult(ctx context.Context, result *MagicBrushResult) error
+}
+
+// Finished reports whether the batch processed all its videos
+func (b *MagicBrushBatch) Finished() bool {
+	return b.Status == MagicBrushBatchCompleted
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// PlatformQuotaUsage counts the API quota units a tenant used on a platform
+// during one of the platform's quota days
+type PlatformQuotaUsage struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_platform_quota_tenant_day,priority:1"`
+	Platform string `json:"platform" gorm:"type:varchar(50);not null;uniqueIndex:idx_platform_quota_tenant_day,priority:2"`
+	// Day is the platform's calendar date, which may differ from the UTC one
+	Day   time.Time `json:"day" gorm:"type:date;not null;uniqueIndex:idx_platform_quota_tenant_day,priority:3"`
+	Units int64     `json:"units" gorm:"not null;default:0"`
+	// Exhausted is set when the platform refused a call for quota, which
+	// ends the day whatever the units counted
+	Exhausted bool      `json:"exhausted" gorm:"not null;default:false"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName keeps the table name singular like the metric it holds
+func (PlatformQuotaUsage) TableName() string {
+	return "platform_quota_usage"
+}
+
+// PlatformQuotaRepository defines the interface for platform quota usage operations
+type PlatformQuotaRepository interface {
+	// Reserve adds units to the day's usage unless the day is exhausted or
+	// the usage would exceed ceiling, and reports whether it did
+	Reserve(ctx context.Context, tenantID, platform string, day time.Time, units, ceiling int64) (bool, error)
+	// Exhaust marks the day's quota spent
+	Exhaust(ctx context.Context, tenantID, platform string, day time.Time) error
+	// Get returns the day's usage, or ErrNotFound when nothing was used
+	Get(ctx context.Context, tenantID, platform string, day time.Time) (*PlatformQuotaUsage, error)
+}
+
+// PlatformConnection is a platform as seen by a user: whether one of their
+// workspaces holds its credentials, and how much of the tenant's daily API
+// quota is left when the platform has one
+type PlatformConnection struct {
+	Platform   Platform            `json:"platform"`
+	Connected  bool                `json:"connected"`
+	Workspaces []string            `json:"workspaces"` // IDs of the connected workspaces
+	Quota      *PlatformQuotaState `json:"quota,omitempty"`
+	// NeedsReconnect are the IDs of the connected workspaces whose token the
+	// platform rejected; their publications are held until reconnected
+	NeedsReconnect []string `json:"needs_reconnect"`
+}
+
+// PlatformQuotaState is a tenant's usage of a platform's daily quota
+type PlatformQuotaState struct {
+	Used      int64     `json:"used"`
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	Exhausted bool      `json:"exhausted"`
+	ResetAt   time.Time `json:"reset_at"`
+	// Deferred lists the operations refused until the quota resets, either
+	// kept out of the reserve or because the quota is spent
+	Deferred []string `json:"deferred"`
+}
@@ -1,44 +1,141 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
 )
 
 // PublicationJob represents a video publication job to a platform
 type PublicationJob struct {
-	ID          string       `json:"id" db:"id"`
-	TenantID    string       `json:"tenant_id" db:"tenant_id"`
-	VideoID     string       `json:"video_id" db:"video_id"`
-	UserID      string       `json:"user_id" db:"user_id"`
-	Platform    string       `json:"platform" db:"platform"`
-	Status      string       `json:"status" db:"status"`
-	Config      string       `json:"config" db:"config"`             // JSON string with platform-specific config
-	ExternalID  string       `json:"external_id" db:"external_id"`   // Platform's video ID
-	ExternalURL string       `json:"external_url" db:"external_url"` // Platform's video URL
-	ErrorMsg    string       `json:"error_message,omitempty" db:"error_message"`
-	RetryCount  int          `json:"retry_count" db:"retry_count"`
-	MaxRetries  int          `json:"max_retries" db:"max_retries"`
-	ScheduledAt sql.NullTime `json:"scheduled_at,omitempty" db:"scheduled_at"`
-	StartedAt   sql.NullTime `json:"started_at,omitempty" db:"started_at"`
-	CompletedAt sql.NullTime `json:"completed_at,omitempty" db:"completed_at"`
-	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
-	DeletedAt   sql.NullTime `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID          string                 `json:"id" db:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID    string                 `json:"tenant_id" db:"tenant_id"`
+	VideoID     string                 `json:"video_id" db:"video_id"`
+	UserID      string                 `json:"user_id" db:"user_id"`
+	WorkspaceID string                 `json:"workspace_id,omitempty" db:"workspace_id"` // Whose platform credentials are used
+	Platform    string                 `json:"platform" db:"platform"`
+	Status      string                 `json:"status" db:"status"`
+	Config      map[string]interface{} `json:"config" db:"config" gorm:"type:json;serializer:json"` // Platform-specific config
+	ExternalID  string                 `json:"external_id" db:"external_id"`                        // Platform's video ID
+	ExternalURL string                 `json:"external_url" db:"external_url"`                      // Platform's video URL
+	ErrorMsg    string                 `json:"error_message,omitempty" db:"error_message"`          // As the platform reported it
+	RetryCount  int                    `json:"retry_count" db:"retry_count"`
+	MaxRetries  int                    `json:"max_retries" db:"max_retries"`
+	ScheduledAt sql.NullTime           `json:"scheduled_at,omitempty" db:"scheduled_at"`
+	StagedAt    sql.NullTime           `json:"staged_at,omitempty" db:"staged_at"` // Uploaded hidden, waiting for ScheduledAt
+	StartedAt   sql.NullTime           `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt sql.NullTime           `json:"completed_at,omitempty" db:"completed_at"`
+	// Set with ErrorMsg to what the failure means for the user and how to
+	// fix it, in English until Localize translates them
+	FailureReason  PublicationFailure `json:"failure_reason,omitempty" db:"failure_reason" gorm:"type:varchar(20)"`
+	FailureMessage string             `json:"failure_message,omitempty" db:"failure_message" gorm:"type:text"`
+	Remediation    string             `json:"remediation,omitempty" db:"remediation" gorm:"type:text"`
+	// Set when the publication is taken down, e.g. for a legal request
+	UnpublishedAt   sql.NullTime `json:"unpublished_at,omitempty" db:"unpublished_at"`
+	UnpublishedBy   string       `json:"unpublished_by,omitempty" db:"unpublished_by"`
+	UnpublishReason string       `json:"unpublish_reason,omitempty" db:"unpublish_reason" gorm:"type:text"`
+	Takedown        string       `json:"takedown,omitempty" db:"takedown"` // What was done on the platform
+	// Set when a publication awaiting approval is approved or rejected
+	ReviewedBy    string       `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt    sql.NullTime `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewComment string       `json:"review_comment,omitempty" db:"review_comment" gorm:"type:text"`
+	CreatedAt     time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at" db:"updated_at"`
+	DeletedAt     sql.NullTime `json:"deleted_at,omitempty" db:"deleted_at"`
+	// Set when a blackout window shifted or held the publication; it is not
+	// checked against the windows again
+	BlackoutWindowID string `json:"blackout_window_id,omitempty" db:"blackout_window_id" gorm:"type:varchar(36)"`
 }
 
 // PublicationStatus defines publication job statuses
 type PublicationStatus string
 
 const (
-	PublicationPending    PublicationStatus = "pending"
-	PublicationScheduled  PublicationStatus = "scheduled"
-	PublicationProcessing PublicationStatus = "processing"
-	PublicationCompleted  PublicationStatus = "completed"
-	PublicationFailed     PublicationStatus = "failed"
-	PublicationCancelled  PublicationStatus = "cancelled"
+	PublicationAwaitingApproval PublicationStatus = "awaiting_approval"
+	PublicationRejected         PublicationStatus = "rejected"
+	PublicationPending          PublicationStatus = "pending"
+	PublicationScheduled        PublicationStatus = "scheduled"
+	PublicationStaged           PublicationStatus = "staged"
+	PublicationProcessing       PublicationStatus = "processing"
+	PublicationCompleted        PublicationStatus = "completed"
+	PublicationFailed           PublicationStatus = "failed"
+	PublicationCancelled        PublicationStatus = "cancelled"
+	PublicationUnpublished      PublicationStatus = "unpublished"
+	// Held while the platform rejects the workspace's token
+	PublicationAwaitingReconnect PublicationStatus = "awaiting_reconnect"
+)
+
+// Takedowns recorded on unpublished publications, besides the platform's
+// own partners.Takedown
+const (
+	TakedownNone   = "none"   // Nothing had reached the platform
+	TakedownManual = "manual" // The platform's API cannot, someone has to
+)
+
+// PublicationFailure classifies why a publication failed
+type PublicationFailure string
+
+const (
+	FailureQuota           PublicationFailure = "quota"            // The platform's quota or rate limits
+	FailureInvalidMetadata PublicationFailure = "invalid_metadata" // Title, description, tags or settings refused
+	FailureCopyright       PublicationFailure = "copyright"        // The video's rights do not cover the platform
+	FailureTokenExpired    PublicationFailure = "token_expired"    // The workspace's credentials were revoked or expired
+	FailureUnknown         PublicationFailure = "unknown"
 )
 
+// publicationFailures are the message and remediation of each failure, in
+// English, which is also their key in the i18n catalog
+var publicationFailures = map[PublicationFailure][2]string{
+	FailureQuota: {
+		"The platform's publishing quota is used up",
+		"Publishing is retried automatically; spread publications out or ask the platform for a higher quota",
+	},
+	FailureInvalidMetadata: {
+		"The platform rejected the video's title, description, tags or settings",
+		"Edit the video's metadata to follow the platform's rules, then publish again",
+	},
+	FailureCopyright: {
+		"The video's rights do not allow publishing it on this platform",
+		"Check the video's rights and licensed platforms, or replace the content",
+	},
+	FailureTokenExpired: {
+		"The platform no longer accepts the workspace's authorization",
+		"Reconnect the workspace to the platform; its publications resume on their own",
+	},
+	FailureUnknown: {
+		"The platform could not publish the video",
+		"Try again later, and contact support if it keeps failing",
+	},
+}
+
+// SetFailure records why the publication failed: err as reported, and the
+// message and remediation of the failure
+func (j *PublicationJob) SetFailure(failure PublicationFailure, err string) {
+	text, ok := publicationFailures[failure]
+	if !ok {
+		failure, text = FailureUnknown, publicationFailures[FailureUnknown]
+	}
+	j.ErrorMsg = err
+	j.FailureReason = failure
+	j.FailureMessage, j.Remediation = text[0], text[1]
+}
+
+// ClearFailure forgets the failure of a publication retried successfully
+func (j *PublicationJob) ClearFailure() {
+	j.ErrorMsg = ""
+	j.FailureReason = ""
+	j.FailureMessage, j.Remediation = "", ""
+}
+
+// Localize translates the failure message and remediation to lang
+func (j *PublicationJob) Localize(lang string) {
+	j.FailureMessage = i18n.T(lang, j.FailureMessage)
+	j.Remediation = i18n.T(lang, j.Remediation)
+}
+
 // Platform defines supported platforms
 type Platform string
 
@@ -52,9 +149,26 @@ const (
 	PlatformSnapchat  Platform = "snapchat"
 )
 
+// Platforms lists the supported platforms
+var Platforms = []Platform{
+	PlatformYouTube, PlatformTikTok, PlatformInstagram, PlatformFacebook,
+	PlatformTwitter, PlatformLinkedIn, PlatformSnapchat,
+}
+
+// Valid reports whether p is one of the supported platforms
+func (p Platform) Valid() bool {
+	switch p {
+	case PlatformYouTube, PlatformTikTok, PlatformInstagram, PlatformFacebook,
+		PlatformTwitter, PlatformLinkedIn, PlatformSnapchat:
+		return true
+	}
+	return false
+}
+
 // CreatePublicationJobRequest represents the request to create a publication job
 type CreatePublicationJobRequest struct {
 	VideoID     string                 `json:"video_id" validate:"required"`
+	WorkspaceID string                 `json:"workspace_id,omitempty"`
 	Platform    string                 `json:"platform" validate:"required,oneof=youtube tiktok instagram facebook twitter linkedin snapchat"`
 	Config      map[string]interface{} `json:"config,omitempty"`
 	ScheduledAt *time.Time             `json:"scheduled_at,omitempty"`
@@ -68,19 +182,69 @@ type UpdatePublicationJobRequest struct {
 	ScheduledAt *time.Time             `json:"scheduled_at,omitempty"`
 }
 
+// UnpublishPublicationRequest represents the request to take a publication down
+type UnpublishPublicationRequest struct {
+	Reason string `json:"reason" binding:"required"` // E.g. the legal request taken down for
+}
+
+// ReviewPublicationRequest approves or rejects a publication awaiting approval
+type ReviewPublicationRequest struct {
+	Comment string `json:"comment" binding:"max=500"` // Required to reject
+}
+
+// ApprovalPolicy is who must approve a tenant's publications before they go out
+type ApprovalPolicy struct {
+	// ApproverRole is the role of the second user approving each
+	// publication, besides admins; empty publishes without approval
+	ApproverRole string `json:"approver_role"`
+}
+
+// Validate checks that the approver role is a role users can have
+func (p *ApprovalPolicy) Validate() error {
+	switch UserRole(p.ApproverRole) {
+	case "", RoleAdmin, RoleEditor, RolePublisher:
+		return nil
+	}
+	return fmt.Errorf("%w: approver_role must be admin, editor or publisher", ErrInvalidInput)
+}
+
 // PublicationJobRepository defines the interface for publication job operations
 type PublicationJobRepository interface {
-	Create(job *PublicationJob) error
-	GetByID(tenantID, id string) (*PublicationJob, error)
-	GetByVideoID(tenantID, videoID string) ([]*PublicationJob, error)
-	GetByStatus(tenantID string, status PublicationStatus, limit, offset int) ([]*PublicationJob, error)
-	GetByPlatform(tenantID string, platform Platform, limit, offset int) ([]*PublicationJob, error)
-	GetScheduledJobs(before time.Time, limit int) ([]*PublicationJob, error)
-	Update(job *PublicationJob) error
-	Delete(tenantID, id string) error
-	List(tenantID string, limit, offset int) ([]*PublicationJob, error)
-	UpdateStatus(tenantID, id string, status PublicationStatus) error
-	IncrementRetryCount(tenantID, id string) error
+	Create(ctx context.Context, job *PublicationJob) error
+	GetByID(ctx context.Context, tenantID, id string) (*PublicationJob, error)
+	GetByVideoID(ctx context.Context, tenantID, videoID string) ([]*PublicationJob, error)
+	// GetByWorkspace returns the jobs published with the workspace's
+	// credentials on the platform that have one of the statuses
+	GetByWorkspace(ctx context.Context, tenantID, workspaceID string, platform Platform, statuses []PublicationStatus) ([]*PublicationJob, error)
+	// ListActivity returns the jobs of the scope's videos, last updated first
+	ListActivity(ctx context.Context, tenantID string, scope *ActivityScope) ([]*PublicationJob, error)
+	GetByStatus(ctx context.Context, tenantID string, status PublicationStatus, limit, offset int) ([]*PublicationJob, error)
+	GetByPlatform(ctx context.Context, tenantID string, platform Platform, limit, offset int) ([]*PublicationJob, error)
+	GetScheduledJobs(ctx context.Context, before time.Time, limit int) ([]*PublicationJob, error)
+	// GetStageableJobs lists the jobs of every tenant on the platform that are
+	// scheduled before the given time, not staged yet and with retries left,
+	// soonest first
+	GetStageableJobs(ctx context.Context, platform string, before time.Time, limit int) ([]*PublicationJob, error)
+	// GetDueJobs lists the scheduled and staged jobs of every tenant due
+	// before the given time, soonest first
+	GetDueJobs(ctx context.Context, before time.Time, limit int) ([]*PublicationJob, error)
+	Update(ctx context.Context, job *PublicationJob) error
+	Delete(ctx context.Context, tenantID, id string) error
+	List(ctx context.Context, tenantID string, limit, offset int) ([]*PublicationJob, error)
+	UpdateStatus(ctx context.Context, tenantID, id string, status PublicationStatus) error
+	IncrementRetryCount(ctx context.Context, tenantID, id string) error
+	// CountFailedByPlatform counts the jobs of every tenant that failed since
+	// the given time
+	CountFailedByPlatform(ctx context.Context, since time.Time) ([]*PlatformCount, error)
+	// CountExhaustedByPlatform counts the failed jobs of every tenant that have
+	// no retries left and wait for someone to act on them
+	CountExhaustedByPlatform(ctx context.Context) ([]*PlatformCount, error)
+}
+
+// PlatformCount is a number of publication jobs on a platform
+type PlatformCount struct {
+	Platform string `json:"platform"`
+	Count    int64  `json:"count"`
 }
 
 // PublicationJobService handles business logic for publication jobs
@@ -94,25 +258,29 @@ func NewPublicationJobService(repo PublicationJobRepository) *PublicationJobServ
 }
 
 // CreatePublicationJob creates a new publication job
-func (s *PublicationJobService) CreatePublicationJob(tenantID, userID string, req *CreatePublicationJobRequest) (*PublicationJob, error) {
+func (s *PublicationJobService) CreatePublicationJob(ctx context.Context, tenantID, userID string, req *CreatePublicationJobRequest) (*PublicationJob, error) {
 	maxRetries := req.MaxRetries
 	if maxRetries == 0 {
 		maxRetries = 3 // Default max retries
 	}
 
 	job := &PublicationJob{
-		TenantID:   tenantID,
-		VideoID:    req.VideoID,
-		UserID:     userID,
-		Platform:   req.Platform,
-		Status:     string(PublicationPending),
-		MaxRetries: maxRetries,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		TenantID:    tenantID,
+		VideoID:     req.VideoID,
+		UserID:      userID,
+		WorkspaceID: req.WorkspaceID,
+		Platform:    req.Platform,
+		Status:      string(PublicationPending),
+		MaxRetries:  maxRetries,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 
 	if req.Config != nil {
-		job.Config = convertConfigToJSON(req.Config)
+		job.Config = req.Config
+	}
+	if _, err := job.WatermarkOverride(); err != nil {
+		return nil, err
 	}
 
 	if req.ScheduledAt != nil {
@@ -120,7 +288,7 @@ func (s *PublicationJobService) CreatePublicationJob(tenantID, userID string, re
 		job.Status = string(PublicationScheduled)
 	}
 
-	if err := s.repo.Create(job); err != nil {
+	if err := s.repo.Create(ctx, job); err != nil {
 		return nil, err
 	}
 
@@ -128,18 +296,18 @@ func (s *PublicationJobService) CreatePublicationJob(tenantID, userID string, re
 }
 
 // GetPublicationJob retrieves a publication job by ID
-func (s *PublicationJobService) GetPublicationJob(tenantID, id string) (*PublicationJob, error) {
-	return s.repo.GetByID(tenantID, id)
+func (s *PublicationJobService) GetPublicationJob(ctx context.Context, tenantID, id string) (*PublicationJob, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
 }
 
 // GetVideoPublicationJobs retrieves all publication jobs for a video
-func (s *PublicationJobService) GetVideoPublicationJobs(tenantID, videoID string) ([]*PublicationJob, error) {
-	return s.repo.GetByVideoID(tenantID, videoID)
+func (s *PublicationJobService) GetVideoPublicationJobs(ctx context.Context, tenantID, videoID string) ([]*PublicationJob, error) {
+	return s.repo.GetByVideoID(ctx, tenantID, videoID)
 }
 
 // UpdatePublicationJob updates an existing publication job
-func (s *PublicationJobService) UpdatePublicationJob(tenantID, id string, req *UpdatePublicationJobRequest) (*PublicationJob, error) {
-	job, err := s.repo.GetByID(tenantID, id)
+func (s *PublicationJobService) UpdatePublicationJob(ctx context.Context, tenantID, id string, req *UpdatePublicationJobRequest) (*PublicationJob, error) {
+	job, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +316,10 @@ func (s *PublicationJobService) UpdatePublicationJob(tenantID, id string, req *U
 		job.Status = *req.Status
 	}
 	if req.Config != nil {
-		job.Config = convertConfigToJSON(req.Config)
+		job.Config = req.Config
+		if _, err := job.WatermarkOverride(); err != nil {
+			return nil, err
+		}
 	}
 	if req.ScheduledAt != nil {
 		job.ScheduledAt = sql.NullTime{Time: *req.ScheduledAt, Valid: true}
@@ -156,7 +327,7 @@ func (s *PublicationJobService) UpdatePublicationJob(tenantID, id string, req *U
 
 	job.UpdatedAt = time.Now()
 
-	if err := s.repo.Update(job); err != nil {
+	if err := s.repo.Update(ctx, job); err != nil {
 		return nil, err
 	}
 
@@ -164,33 +335,33 @@ func (s *PublicationJobService) UpdatePublicationJob(tenantID, id string, req *U
 }
 
 // DeletePublicationJob soft deletes a publication job
-func (s *PublicationJobService) DeletePublicationJob(tenantID, id string) error {
-	return s.repo.Delete(tenantID, id)
+func (s *PublicationJobService) DeletePublicationJob(ctx context.Context, tenantID, id string) error {
+	return s.repo.Delete(ctx, tenantID, id)
 }
 
 // ListPublicationJobs retrieves a list of publication jobs with pagination
-func (s *PublicationJobService) ListPublicationJobs(tenantID string, limit, offset int) ([]*PublicationJob, error) {
-	return s.repo.List(tenantID, limit, offset)
+func (s *PublicationJobService) ListPublicationJobs(ctx context.Context, tenantID string, limit, offset int) ([]*PublicationJob, error) {
+	return s.repo.List(ctx, tenantID, limit, offset)
 }
 
 // GetJobsByStatus retrieves publication jobs by status
-func (s *PublicationJobService) GetJobsByStatus(tenantID string, status PublicationStatus, limit, offset int) ([]*PublicationJob, error) {
-	return s.repo.GetByStatus(tenantID, status, limit, offset)
+func (s *PublicationJobService) GetJobsByStatus(ctx context.Context, tenantID string, status PublicationStatus, limit, offset int) ([]*PublicationJob, error) {
+	return s.repo.GetByStatus(ctx, tenantID, status, limit, offset)
 }
 
 // GetJobsByPlatform retrieves publication jobs by platform
-func (s *PublicationJobService) GetJobsByPlatform(tenantID string, platform Platform, limit, offset int) ([]*PublicationJob, error) {
-	return s.repo.GetByPlatform(tenantID, platform, limit, offset)
+func (s *PublicationJobService) GetJobsByPlatform(ctx context.Context, tenantID string, platform Platform, limit, offset int) ([]*PublicationJob, error) {
+	return s.repo.GetByPlatform(ctx, tenantID, platform, limit, offset)
 }
 
 // GetScheduledJobs retrieves jobs scheduled before a specific time
-func (s *PublicationJobService) GetScheduledJobs(before time.Time, limit int) ([]*PublicationJob, error) {
-	return s.repo.GetScheduledJobs(before, limit)
+func (s *PublicationJobService) GetScheduledJobs(ctx context.Context, before time.Time, limit int) ([]*PublicationJob, error) {
+	return s.repo.GetScheduledJobs(ctx, before, limit)
 }
 
 // StartJob marks a job as processing
-func (s *PublicationJobService) StartJob(tenantID, id string) error {
-	job, err := s.repo.GetByID(tenantID, id)
+func (s *PublicationJobService) StartJob(ctx context.Context, tenantID, id string) error {
+	job, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return err
 	}
@@ -199,12 +370,12 @@ func (s *PublicationJobService) StartJob(tenantID, id string) error {
 	job.StartedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	job.UpdatedAt = time.Now()
 
-	return s.repo.Update(job)
+	return s.repo.Update(ctx, job)
 }
 
 // CompleteJob marks a job as completed
-func (s *PublicationJobService) CompleteJob(tenantID, id, externalID, externalURL string) error {
-	job, err := s.repo.GetByID(tenantID, id)
+func (s *PublicationJobService) CompleteJob(ctx context.Context, tenantID, id, externalID, externalURL string) error {
+	job, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return err
 	}
@@ -215,12 +386,12 @@ func (s *PublicationJobService) CompleteJob(tenantID, id, externalID, externalUR
 	job.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	job.UpdatedAt = time.Now()
 
-	return s.repo.Update(job)
+	return s.repo.Update(ctx, job)
 }
 
 // FailJob marks a job as failed and increments retry count
-func (s *PublicationJobService) FailJob(tenantID, id, errorMsg string) error {
-	job, err := s.repo.GetByID(tenantID, id)
+func (s *PublicationJobService) FailJob(ctx context.Context, tenantID, id, errorMsg string) error {
+	job, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return err
 	}
@@ -235,12 +406,12 @@ func (s *PublicationJobService) FailJob(tenantID, id, errorMsg string) error {
 		job.Status = string(PublicationPending) // Retry
 	}
 
-	return s.repo.Update(job)
+	return s.repo.Update(ctx, job)
 }
 
 // CancelJob marks a job as cancelled
-func (s *PublicationJobService) CancelJob(tenantID, id string) error {
-	return s.repo.UpdateStatus(tenantID, id, PublicationCancelled)
+func (s *PublicationJobService) CancelJob(ctx context.Context, tenantID, id string) error {
+	return s.repo.UpdateStatus(ctx, tenantID, id, PublicationCancelled)
 }
 
 // IsRetryable checks if the job can be retried
@@ -261,22 +432,10 @@ func (j *PublicationJob) ShouldExecute() bool {
 	return j.ScheduledAt.Time.Before(time.Now())
 }
 
-// GetPlatformConfig returns the platform-specific configuration
+// GetPlatformConfig returns the platform-specific configuration, never nil
 func (j *PublicationJob) GetPlatformConfig() map[string]interface{} {
-	// Simplified implementation - in real app, use proper JSON unmarshaling
-	if j.Config == "" {
+	if j.Config == nil {
 		return make(map[string]interface{})
 	}
-	// This is a placeholder - implement proper JSON parsing
-	return make(map[string]interface{})
-}
-
-// convertConfigToJSON converts a config map to JSON string
-func convertConfigToJSON(config map[string]interface{}) string {
-	// Simplified implementation - in real app, use proper JSON marshaling
-	if len(config) == 0 {
-		return ""
-	}
-	// This is a placeholder - implement proper JSON marshaling
-	return "{}"
+	return j.Config
 }
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// RetentionPoint is how much of the audience is still watching at a point
+// of a video
+type RetentionPoint struct {
+	Elapsed  float64 `json:"elapsed"`  // Position in the video, as a fraction of its length
+	Watching float64 `json:"watching"` // Views reaching the position over views; rewatches can push it above 1
+}
+
+// RetentionCurve is the audience retention of a video on a platform, as
+// last fetched
+type RetentionCurve struct {
+	ID        string           `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string           `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_retention_video_platform,priority:1"`
+	VideoID   string           `json:"video_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_retention_video_platform,priority:2"`
+	Platform  string           `json:"platform" gorm:"type:varchar(20);not null;uniqueIndex:idx_retention_video_platform,priority:3"`
+	Points    []RetentionPoint `json:"points" gorm:"type:json;serializer:json"` // By elapsed position
+	FetchedAt time.Time        `json:"fetched_at" gorm:"type:timestamp;not null"`
+	CreatedAt time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// RetentionDropOff is a stretch of a video where the audience leaves
+// noticeably faster than elsewhere
+type RetentionDropOff struct {
+	Start          float64 `json:"start"` // Fractions of the video's length
+	End            float64 `json:"end"`
+	StartSecond    *int    `json:"start_second,omitempty"` // Set when the video's duration is known
+	EndSecond      *int    `json:"end_second,omitempty"`
+	WatchingBefore float64 `json:"watching_before"`
+	WatchingAfter  float64 `json:"watching_after"`
+	Drop           float64 `json:"drop"` // WatchingBefore minus WatchingAfter
+}
+
+// PlatformRetention is a video's retention curve on a platform with the
+// drop-offs detected in it
+type PlatformRetention struct {
+	Platform  string             `json:"platform"`
+	Points    []RetentionPoint   `json:"points"`
+	DropOffs  []RetentionDropOff `json:"drop_offs"`
+	FetchedAt time.Time          `json:"fetched_at"`
+}
+
+// VideoRetention is the retention of a video on every platform it was fetched from
+type VideoRetention struct {
+	VideoID  string               `json:"video_id"`
+	Duration int                  `json:"duration"` // Seconds, 0 when unknown
+	Curves   []*PlatformRetention `json:"curves"`
+}
+
+// RetentionSyncRequest fetches a video's retention with a workspace's credentials
+type RetentionSyncRequest struct {
+	WorkspaceID string `json:"workspace_id" binding:"required"`
+}
+
+// RetentionCurveRepository defines the interface for retention curve operations
+type RetentionCurveRepository interface {
+	// Upsert saves the curve of the video on the platform, replacing the
+	// previous one
+	Upsert(ctx context.Context, curve *RetentionCurve) error
+	// ListByVideo returns the video's curves by platform
+	ListByVideo(ctx context.Context, tenantID, videoID string) ([]*RetentionCurve, error)
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Retention policy limits
+const (
+	MaxArchiveAfterMonths = 120
+	DefaultRestoreDays    = 7
+	MaxRestoreDays        = 30
+)
+
+// RestoreTiers lists the Glacier retrieval tiers a tenant may choose,
+// fastest and most expensive first
+var RestoreTiers = []string{"Expedited", "Standard", "Bulk"}
+
+// DefaultRestoreTier is used when a policy does not choose a tier
+const DefaultRestoreTier = "Standard"
+
+// RetentionPolicy holds a tenant's cold storage lifecycle rules
+type RetentionPolicy struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_retention_policies_tenant"`
+	// ArchiveAfterMonths moves unpublished videos to Glacier once they have not
+	// been updated for that many months; 0 disables archiving
+	ArchiveAfterMonths int `json:"archive_after_months" gorm:"not null;default:0"`
+	// RestoreDays is how long S3 keeps the temporary copy of a restored video
+	RestoreDays int       `json:"restore_days" gorm:"not null;default:7"`
+	RestoreTier string    `json:"restore_tier" gorm:"type:varchar(20);not null;default:'Standard'"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// UpdateRetentionPolicyRequest represents the request to change a tenant's retention rules
+type UpdateRetentionPolicyRequest struct {
+	ArchiveAfterMonths *int    `json:"archive_after_months,omitempty"`
+	RestoreDays        *int    `json:"restore_days,omitempty"`
+	RestoreTier        *string `json:"restore_tier,omitempty"`
+}
+
+// RetentionPolicyRepository defines the interface for retention policy operations
+type RetentionPolicyRepository interface {
+	// Get returns the tenant's policy, or ErrNotFound when none was saved
+	Get(ctx context.Context, tenantID string) (*RetentionPolicy, error)
+	Upsert(ctx context.Context, policy *RetentionPolicy) error
+	// ListEnabled returns the policies of every tenant that archives videos
+	ListEnabled(ctx context.Context) ([]*RetentionPolicy, error)
+}
+
+// DefaultRetentionPolicy returns the policy of a tenant that never configured
+// one: archiving stays off until the tenant opts in
+func DefaultRetentionPolicy(tenantID string) *RetentionPolicy {
+	return &RetentionPolicy{
+		TenantID:    tenantID,
+		RestoreDays: DefaultRestoreDays,
+		RestoreTier: DefaultRestoreTier,
+	}
+}
+
+// Validate checks the policy limits
+func (p *RetentionPolicy) Validate() error {
+	if p.ArchiveAfterMonths < 0 || p.ArchiveAfterMonths > MaxArchiveAfterMonths {
+		return fmt.Errorf("%w: archive_after_months must be between 0 and %d", ErrInvalidInput, MaxArchiveAfterMonths)
+	}
+	if p.RestoreDays < 1 || p.RestoreDays > MaxRestoreDays {
+		return fmt.Errorf("%w: restore_days must be between 1 and %d", ErrInvalidInput, MaxRestoreDays)
+	}
+	for _, tier := range RestoreTiers {
+		if p.RestoreTier == tier {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: restore_tier must be one of %v", ErrInvalidInput, RestoreTiers)
+}
+
+// Enabled reports whether the policy archives videos
+func (p *RetentionPolicy) Enabled() bool {
+	return p.ArchiveAfterMonths > 0
+}
+
+// ArchiveCutoff returns the last update time a video may have to be archived at now
+func (p *RetentionPolicy) ArchiveCutoff(now time.Time) time.Time {
+	return now.AddDate(0, -p.ArchiveAfterMonths, 0)
+}
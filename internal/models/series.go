@@ -0,0 +1,133 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+)
+
+// seriesPlaceholder matches the placeholders of a title pattern, such as
+// {{episode}} or {{episode:2}} for a number padded to two digits
+var seriesPlaceholder = regexp.MustCompile(`\{\{\s*(\w+)(?::([1-9]))?\s*\}\}`)
+
+// Series groups videos published as numbered episodes
+type Series struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	Name     string `json:"name" gorm:"type:varchar(100);not null"`
+	// TitlePattern renders the title episodes are published with, from
+	// {{series}}, {{season}}, {{episode}} and {{title}}; empty keeps the
+	// video's title
+	TitlePattern string `json:"title_pattern" gorm:"type:varchar(255)"`
+	Season       int    `json:"season" gorm:"not null;default:1"` // Season new episodes are numbered in
+	// The YouTube playlist of the series, on the channel of WorkspaceID,
+	// created when its first episode is released there
+	WorkspaceID string    `json:"workspace_id,omitempty" gorm:"type:varchar(36)"`
+	PlaylistID  string    `json:"playlist_id,omitempty" gorm:"type:varchar(100)"`
+	CreatedBy   string    `json:"created_by" gorm:"type:varchar(36)"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// VideoEpisode places a video in a series
+type VideoEpisode struct {
+	SeriesID string `json:"series_id,omitempty" gorm:"type:varchar(36);index"`
+	Season   int    `json:"season,omitempty"`
+	Number   int    `json:"number,omitempty"`
+	// Title is what the series' pattern rendered when the video was last
+	// published
+	Title      string `json:"title,omitempty" gorm:"type:varchar(255)"`
+	Playlisted bool   `json:"playlisted,omitempty"` // In the series' YouTube playlist
+}
+
+// SeriesRequest represents a request to create or replace a series
+type SeriesRequest struct {
+	Name         string `json:"name" binding:"required,max=100"`
+	TitlePattern string `json:"title_pattern" binding:"max=255"`
+	Season       int    `json:"season,omitempty"` // Defaults to 1
+	WorkspaceID  string `json:"workspace_id,omitempty"`
+}
+
+// AddEpisodeRequest represents a request to add a video to a series
+type AddEpisodeRequest struct {
+	VideoID string `json:"video_id" binding:"required"`
+	Season  int    `json:"season,omitempty"` // Defaults to the series' season
+}
+
+// SeriesRepository defines the interface for series operations
+type SeriesRepository interface {
+	Create(ctx context.Context, series *Series) error
+	// GetByID returns a series of the tenant, or ErrSeriesNotFound
+	GetByID(ctx context.Context, tenantID, id string) (*Series, error)
+	// List returns the tenant's series by name
+	List(ctx context.Context, tenantID string) ([]*Series, error)
+	Update(ctx context.Context, series *Series) error
+	// Delete removes a series and takes its videos out of it, or returns
+	// ErrSeriesNotFound
+	Delete(ctx context.Context, tenantID, id string) error
+	// AddEpisode numbers the video after the last episode of the season,
+	// returning its number. Concurrent calls for a series are serialized so
+	// no number is given twice.
+	AddEpisode(ctx context.Context, tenantID, seriesID, videoID string, season int) (int, error)
+	// ListEpisodes returns the series' videos by season and number
+	ListEpisodes(ctx context.Context, tenantID, seriesID string) ([]*Video, error)
+}
+
+// Validate checks the series' season and the placeholders of its pattern
+func (s *Series) Validate() error {
+	if s.Season < 1 {
+		return i18n.Errorf(ErrInvalidInput, "season must be 1 or more")
+	}
+	for _, match := range seriesPlaceholder.FindAllStringSubmatch(s.TitlePattern, -1) {
+		switch match[1] {
+		case "series", "season", "episode", "title":
+		default:
+			return i18n.Errorf(ErrInvalidInput, "unknown placeholder %s in title_pattern", match[0])
+		}
+	}
+	return nil
+}
+
+// EpisodeTitle renders the title the video is published with as an episode
+// of the series
+func (s *Series) EpisodeTitle(v *Video) string {
+	if s.TitlePattern == "" {
+		return v.Title
+	}
+	return seriesPlaceholder.ReplaceAllStringFunc(s.TitlePattern, func(placeholder string) string {
+		match := seriesPlaceholder.FindStringSubmatch(placeholder)
+		number := func(n int) string {
+			width, _ := strconv.Atoi(match[2])
+			return fmt.Sprintf("%0*d", width, n)
+		}
+		switch match[1] {
+		case "series":
+			return s.Name
+		case "season":
+			return number(v.Episode.Season)
+		case "episode":
+			return number(v.Episode.Number)
+		case "title":
+			return v.Title
+		}
+		return placeholder
+	})
+}
+
+// Before reports whether the episode comes before other in its series
+func (e VideoEpisode) Before(other VideoEpisode) bool {
+	return e.Season < other.Season || (e.Season == other.Season && e.Number < other.Number)
+}
+
+// PublishTitle returns the title the video is published with: its episode
+// title when it was published as part of a series
+func (v *Video) PublishTitle() string {
+	if v.Episode.SeriesID != "" && v.Episode.Title != "" {
+		return v.Episode.Title
+	}
+	return v.Title
+}
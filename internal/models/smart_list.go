@@ -0,0 +1,171 @@
+package models
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+)
+
+// Limits of smart lists
+const (
+	MaxSmartListConditions = 10
+	MaxSmartListFilterLen  = 1000
+)
+
+// SmartListField is a video attribute smart lists filter on
+type SmartListField string
+
+const (
+	SmartListStatus     SmartListField = "status"
+	SmartListPlatform   SmartListField = "platform" // Published to the platform
+	SmartListTag        SmartListField = "tag"
+	SmartListWorkspace  SmartListField = "workspace_id"
+	SmartListDuration   SmartListField = "duration"        // In seconds
+	SmartListViews      SmartListField = "views"           // Summed across platforms
+	SmartListEngagement SmartListField = "engagement_rate" // Percent of views, across platforms
+)
+
+// smartListNumeric are the fields compared as numbers; the others only
+// take = and !=
+var smartListNumeric = map[SmartListField]bool{
+	SmartListDuration:   true,
+	SmartListViews:      true,
+	SmartListEngagement: true,
+}
+
+// SmartListFields are the fields a smart list filter can use
+var SmartListFields = []SmartListField{
+	SmartListStatus, SmartListPlatform, SmartListTag, SmartListWorkspace,
+	SmartListDuration, SmartListViews, SmartListEngagement,
+}
+
+// SmartListPlatforms are the platforms a video records its ID on, so the
+// platform filter can tell whether it was published there
+var SmartListPlatforms = []Platform{
+	PlatformYouTube, PlatformTikTok, PlatformInstagram, PlatformFacebook, PlatformTwitter, PlatformSnapchat,
+}
+
+// smartListClause matches one condition of a filter, such as status=ready
+var smartListClause = regexp.MustCompile(`^([a-z_]+)\s*(!=|>=|<=|=|>|<)\s*(.+)$`)
+
+// smartListAnd separates the conditions of a filter
+var smartListAnd = regexp.MustCompile(`(?i)\s+and\s+`)
+
+// SmartList is a filter over the video library saved under a name by a
+// user, such as "status=ready AND platform=tiktok AND engagement_rate>5%".
+// Its videos are the ones matching it when it is used.
+type SmartList struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string    `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_smart_lists_user,priority:1"`
+	UserID    string    `json:"user_id" gorm:"type:varchar(36);not null;index:idx_smart_lists_user,priority:2"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
+	Filter    string    `json:"filter" gorm:"type:varchar(1000);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// SmartListCondition is one condition of a filter; all of a filter's
+// conditions must hold for a video to match
+type SmartListCondition struct {
+	Field    SmartListField `json:"field"`
+	Operator string         `json:"operator"` // =, !=, >, >=, < or <=
+	Value    string         `json:"value"`
+	Number   float64        `json:"-"` // Value of a numeric field
+}
+
+// SmartListRequest represents a request to create or replace a smart list
+type SmartListRequest struct {
+	Name   string `json:"name" binding:"required,max=100"`
+	Filter string `json:"filter" binding:"required"`
+}
+
+// SmartListRepository defines the interface for smart list operations
+type SmartListRepository interface {
+	Create(ctx context.Context, list *SmartList) error
+	// Get returns a list of the user, or ErrSmartListNotFound
+	Get(ctx context.Context, tenantID, userID, id string) (*SmartList, error)
+	// List returns the user's lists by name
+	List(ctx context.Context, tenantID, userID string, limit, offset int) ([]*SmartList, error)
+	CountByUser(ctx context.Context, tenantID, userID string) (int64, error)
+	Update(ctx context.Context, list *SmartList) error
+	// Delete removes a list of the user, or returns ErrSmartListNotFound
+	Delete(ctx context.Context, tenantID, userID, id string) error
+}
+
+// Valid reports whether the status is one a video can be in
+func (s VideoStatus) Valid() bool {
+	return slices.Contains([]VideoStatus{StatusUploading, StatusProcessing, StatusReady, StatusFailed, StatusArchived, StatusQCFailed}, s)
+}
+
+// ParseSmartListFilter parses the conditions of a filter, joined by AND.
+// Values may be quoted, and an engagement rate, which engagement is short
+// for, may end with %.
+func ParseSmartListFilter(filter string) ([]SmartListCondition, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" || len(filter) > MaxSmartListFilterLen {
+		return nil, i18n.Errorf(ErrInvalidInput, "a filter has 1 to %d characters", MaxSmartListFilterLen)
+	}
+	clauses := smartListAnd.Split(filter, -1)
+	if len(clauses) > MaxSmartListConditions {
+		return nil, i18n.Errorf(ErrInvalidInput, "a filter has at most %d conditions", MaxSmartListConditions)
+	}
+
+	conditions := make([]SmartListCondition, 0, len(clauses))
+	for _, clause := range clauses {
+		match := smartListClause.FindStringSubmatch(strings.TrimSpace(clause))
+		if match == nil {
+			return nil, i18n.Errorf(ErrInvalidInput, "invalid condition %q, expected a field, an operator and a value", clause)
+		}
+		condition := SmartListCondition{Field: SmartListField(match[1]), Operator: match[2], Value: strings.Trim(strings.TrimSpace(match[3]), `"`)}
+		if condition.Field == "engagement" {
+			condition.Field = SmartListEngagement
+		}
+		if err := condition.parseValue(); err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// parseValue checks the condition's operator and value suit its field
+func (c *SmartListCondition) parseValue() error {
+	if !slices.Contains(SmartListFields, c.Field) {
+		return i18n.Errorf(ErrInvalidInput, "unknown filter field %q, expected one of %v", c.Field, SmartListFields)
+	}
+	if c.Value == "" {
+		return i18n.Errorf(ErrInvalidInput, "the %s condition has no value", c.Field)
+	}
+	if smartListNumeric[c.Field] {
+		value := c.Value
+		if c.Field == SmartListEngagement {
+			value = strings.TrimSuffix(value, "%")
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || number < 0 {
+			return i18n.Errorf(ErrInvalidInput, "the %s condition needs a number that is not negative", c.Field)
+		}
+		c.Number = number
+		return nil
+	}
+
+	if c.Operator != "=" && c.Operator != "!=" {
+		return i18n.Errorf(ErrInvalidInput, "the %s condition only takes = or !=", c.Field)
+	}
+	switch c.Field {
+	case SmartListStatus:
+		if !VideoStatus(c.Value).Valid() {
+			return i18n.Errorf(ErrInvalidInput, "unknown video status %q", c.Value)
+		}
+	case SmartListPlatform:
+		if !slices.Contains(SmartListPlatforms, Platform(c.Value)) {
+			return i18n.Errorf(ErrInvalidInput, "platform must be one of %v", SmartListPlatforms)
+		}
+	}
+	return nil
+}
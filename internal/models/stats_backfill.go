@@ -0,0 +1,87 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Stats backfill statuses
+const (
+	BackfillPending   = "pending"
+	BackfillRunning   = "running"
+	BackfillThrottled = "throttled" // Waiting for the platform quota to reset
+	BackfillCompleted = "completed"
+	BackfillFailed    = "failed"
+)
+
+// StatsBackfill imports the past daily stats of a tenant's videos on one
+// platform as stats snapshots, for the days before the first sync. It
+// advances a few platform requests per run, so the cursor fields record
+// where the next run resumes.
+type StatsBackfill struct {
+	ID          string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID    string `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_backfills_tenant_platform,priority:1"`
+	Platform    string `json:"platform" gorm:"type:varchar(50);not null;index:idx_backfills_tenant_platform,priority:2"`
+	WorkspaceID string `json:"workspace_id" gorm:"type:varchar(36);not null"` // Credentials the platform is read with
+	UserID      string `json:"user_id" gorm:"type:varchar(36)"`               // Who requested it, empty from the command line
+	Status      string `json:"status" gorm:"type:varchar(20);not null;index:idx_backfills_due,priority:1"`
+
+	// Until is the day the backfill was requested; days from it on are left
+	// to the regular sync
+	Until time.Time `json:"until" gorm:"type:date;not null"`
+
+	// Progress
+	VideosTotal  int `json:"videos_total"`
+	VideosDone   int `json:"videos_done"`
+	DaysImported int `json:"days_imported"`
+	Requests     int `json:"requests"` // Platform API requests made
+
+	// Cursor: the stats row being imported, the next day to fetch and the
+	// totals the video had reached before it
+	StatsID   string         `json:"-" gorm:"type:varchar(36)"`
+	NextDay   *time.Time     `json:"-" gorm:"type:date"`
+	EndDay    *time.Time     `json:"-" gorm:"type:date"`
+	Carry     BackfillTotals `json:"-" gorm:"type:json;serializer:json"`
+	NextRunAt time.Time      `json:"next_run_at" gorm:"not null;index:idx_backfills_due,priority:2"`
+	Errors    int            `json:"-"` // Consecutive failed runs
+	LastError string         `json:"last_error,omitempty" gorm:"type:text"`
+	StartedAt *time.Time     `json:"started_at,omitempty"`
+	EndedAt   *time.Time     `json:"ended_at,omitempty"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// JobID is the job tracking the backfill, set when it is started
+	JobID string `json:"job_id,omitempty" gorm:"-"`
+}
+
+// BackfillTotals are the running totals of the video being backfilled
+type BackfillTotals struct {
+	Views    int64 `json:"views"`
+	Likes    int64 `json:"likes"`
+	Comments int64 `json:"comments"`
+	Shares   int64 `json:"shares"`
+}
+
+// StartStatsBackfillRequest is the request to backfill a platform's stats
+type StartStatsBackfillRequest struct {
+	Platform    string `json:"platform" binding:"required"`
+	WorkspaceID string `json:"workspace_id" binding:"required"`
+}
+
+// Active reports whether the backfill still has work to do
+func (b *StatsBackfill) Active() bool {
+	return b.Status != BackfillCompleted && b.Status != BackfillFailed
+}
+
+// StatsBackfillRepository defines data access for stats backfills
+type StatsBackfillRepository interface {
+	Create(ctx context.Context, backfill *StatsBackfill) error
+	GetByID(ctx context.Context, tenantID, id string) (*StatsBackfill, error)
+	// GetActive returns the tenant's unfinished backfill of the platform
+	GetActive(ctx context.Context, tenantID, platform string) (*StatsBackfill, error)
+	List(ctx context.Context, tenantID string, limit, offset int) ([]*StatsBackfill, error)
+	// ListDue returns the unfinished backfills of every tenant due to run by
+	// now, longest waiting first
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*StatsBackfill, error)
+	Update(ctx context.Context, backfill *StatsBackfill) error
+}
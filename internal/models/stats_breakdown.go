@@ -0,0 +1,69 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Normalized device types
+const (
+	DeviceMobile  = "mobile"
+	DeviceDesktop = "desktop"
+	DeviceTablet  = "tablet"
+	DeviceTV      = "tv"
+	DeviceConsole = "console"
+	DeviceOther   = "other" // Unknown or unlisted devices
+)
+
+// DeviceTypes lists the normalized device types
+var DeviceTypes = []string{DeviceMobile, DeviceDesktop, DeviceTablet, DeviceTV, DeviceConsole, DeviceOther}
+
+// VideoStatsDevice is the views of a video on a platform from one device
+// type. Rows are keyed like their stats row and replaced with it, so device
+// views are summed in SQL rather than decoded from JSON.
+type VideoStatsDevice struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string    `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_stats_device,priority:1"`
+	VideoID   string    `json:"video_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_stats_device,priority:2"`
+	Platform  string    `json:"platform" gorm:"type:varchar(50);not null;uniqueIndex:idx_stats_device,priority:3"`
+	Device    string    `json:"device" gorm:"type:varchar(20);not null;uniqueIndex:idx_stats_device,priority:4"`
+	Views     int64     `json:"views" gorm:"default:0"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// VideoStatsLocation is the views of a video on a platform from one country,
+// keyed and replaced like VideoStatsDevice
+type VideoStatsLocation struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string    `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_stats_location,priority:1"`
+	VideoID   string    `json:"video_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_stats_location,priority:2"`
+	Platform  string    `json:"platform" gorm:"type:varchar(50);not null;uniqueIndex:idx_stats_location,priority:3"`
+	Country   string    `json:"country" gorm:"type:char(2);not null;uniqueIndex:idx_stats_location,priority:4"` // ISO 3166-1 alpha-2
+	Views     int64     `json:"views" gorm:"default:0"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BreakdownFilter selects the stats a device or geography breakdown sums
+type BreakdownFilter struct {
+	Platform string    // Every platform when empty
+	From, To time.Time // Upload time of the videos, [From, To)
+}
+
+// BreakdownViews is the views summed for one device type or country
+type BreakdownViews struct {
+	Key   string  `json:"key"`
+	Views int64   `json:"views"`
+	Share float64 `json:"share"` // Of the views of the breakdown
+}
+
+// SplitViews spreads views over shares, nil without shares
+func SplitViews(views int64, shares map[string]float64) map[string]int64 {
+	if len(shares) == 0 {
+		return nil
+	}
+	split := make(map[string]int64, len(shares))
+	for key, share := range shares {
+		split[key] = int64(math.Round(share * float64(views)))
+	}
+	return split
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// StatsCompaction records how far a tenant's stats snapshots are compacted;
+// there is one per tenant
+type StatsCompaction struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_stats_compactions_tenant"`
+	// CompactedBefore is the day before which every day holds at most one
+	// snapshot per stats row
+	CompactedBefore time.Time `json:"compacted_before" gorm:"type:datetime(3);not null"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"type:datetime(3);autoUpdateTime"`
+}
+
+// StatsCompactionPolicy is how a tenant plan keeps its stats snapshots
+type StatsCompactionPolicy struct {
+	// CompactAfterDays collapses the snapshots of each day into the last one
+	// once the day is that many days old
+	CompactAfterDays int
+	// RetentionDays deletes the snapshots older than that many days; 0 keeps
+	// them until their partition is dropped
+	RetentionDays int
+}
+
+// StatsCompactionRepository defines the interface for compaction progress
+type StatsCompactionRepository interface {
+	// Get returns the tenant's progress, or ErrNotFound before its first run
+	Get(ctx context.Context, tenantID string) (*StatsCompaction, error)
+	Upsert(ctx context.Context, compaction *StatsCompaction) error
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// StatsSyncCheckpoint records how far the stats sync of a tenant's platform
+// went, so a sync stopped halfway resumes after the last stats row it wrote
+// instead of starting over; there is one per tenant and platform
+type StatsSyncCheckpoint struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_stats_sync_checkpoints_tenant_platform,priority:1"`
+	Platform string `json:"platform" gorm:"type:varchar(50);not null;uniqueIndex:idx_stats_sync_checkpoints_tenant_platform,priority:2"`
+
+	// StatsID is the last stats row synced, in ID order, and ExternalID the
+	// platform's ID of its video
+	StatsID    string `json:"stats_id" gorm:"type:varchar(36)"`
+	ExternalID string `json:"external_id" gorm:"type:varchar(255)"`
+	// RunStartedAt is when the current run started; rows synced since then
+	// are not fetched again when it resumes
+	RunStartedAt time.Time `json:"run_started_at" gorm:"type:datetime(3);not null"`
+	Synced       int       `json:"synced"` // Rows the current run went through
+	// CompletedAt is when the last run went through every row, nil while
+	// one is in progress
+	CompletedAt *time.Time `json:"completed_at,omitempty" gorm:"type:datetime(3)"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"type:datetime(3);autoUpdateTime"`
+}
+
+// Completed reports whether the last run went through every row, so the
+// next one starts over
+func (c *StatsSyncCheckpoint) Completed() bool {
+	return c.CompletedAt != nil
+}
+
+// StatsSyncCheckpointRepository defines the interface for stats sync progress
+type StatsSyncCheckpointRepository interface {
+	// Get returns the checkpoint of the tenant's platform, or ErrNotFound
+	// before its first sync
+	Get(ctx context.Context, tenantID, platform string) (*StatsSyncCheckpoint, error)
+	Upsert(ctx context.Context, checkpoint *StatsSyncCheckpoint) error
+}
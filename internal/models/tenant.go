@@ -1,13 +1,14 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"time"
 )
 
 // Tenant represents a tenant in the multi-tenant system
 type Tenant struct {
-	ID        string       `json:"id" db:"id"`
+	ID        string       `json:"id" db:"id" gorm:"primaryKey;type:varchar(36)"`
 	Name      string       `json:"name" db:"name"`
 	Domain    string       `json:"domain" db:"domain"`
 	Settings  string       `json:"settings" db:"settings"` // JSON string
@@ -15,16 +16,42 @@ type Tenant struct {
 	CreatedAt time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
 	DeletedAt sql.NullTime `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// Data residency: empty means the deployment default. A pinned tenant's
+	// data is never stored or processed outside its residency.
+	Residency       string `json:"residency" db:"residency" gorm:"type:varchar(10)"`
+	ResidencyPinned bool   `json:"residency_pinned" db:"residency_pinned" gorm:"default:false"`
+
+	// Plan names the tenant's subscription plan; empty is the default plan
+	Plan string `json:"plan" db:"plan" gorm:"type:varchar(20)"`
+	// Vertical is the tenant's content category, e.g. gaming, picking the
+	// engagement benchmarks its analytics are compared with
+	Vertical string `json:"vertical" db:"vertical" gorm:"type:varchar(30)"`
+	// PublishApproverRole is the role of who approves publications, see
+	// ApprovalPolicy; empty publishes without approval
+	PublishApproverRole string `json:"publish_approver_role,omitempty" db:"publish_approver_role" gorm:"type:varchar(20)"`
+}
+
+// Tenant statuses; an empty status is active
+const (
+	TenantStatusActive    = "active"
+	TenantStatusSuspended = "suspended"
+)
+
+// UpdateResidencyRequest changes where a tenant's data lives; nil fields are unchanged
+type UpdateResidencyRequest struct {
+	Residency *string `json:"residency,omitempty"`
+	Pinned    *bool   `json:"pinned,omitempty"`
 }
 
 // TenantRepository defines the interface for tenant operations
 type TenantRepository interface {
-	Create(tenant *Tenant) error
-	GetByID(id string) (*Tenant, error)
-	GetByDomain(domain string) (*Tenant, error)
-	Update(tenant *Tenant) error
-	Delete(id string) error
-	List(limit, offset int) ([]*Tenant, error)
+	Create(ctx context.Context, tenant *Tenant) error
+	GetByID(ctx context.Context, id string) (*Tenant, error)
+	GetByDomain(ctx context.Context, domain string) (*Tenant, error)
+	Update(ctx context.Context, tenant *Tenant) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, limit, offset int) ([]*Tenant, error)
 }
 
 // TenantService handles business logic for tenants
@@ -38,35 +65,35 @@ func NewTenantService(repo TenantRepository) *TenantService {
 }
 
 // CreateTenant creates a new tenant
-func (s *TenantService) CreateTenant(tenant *Tenant) error {
+func (s *TenantService) CreateTenant(ctx context.Context, tenant *Tenant) error {
 	tenant.CreatedAt = time.Now()
 	tenant.UpdatedAt = time.Now()
-	tenant.Status = "active"
-	return s.repo.Create(tenant)
+	tenant.Status = TenantStatusActive
+	return s.repo.Create(ctx, tenant)
 }
 
 // GetTenant retrieves a tenant by ID
-func (s *TenantService) GetTenant(id string) (*Tenant, error) {
-	return s.repo.GetByID(id)
+func (s *TenantService) GetTenant(ctx context.Context, id string) (*Tenant, error) {
+	return s.repo.GetByID(ctx, id)
 }
 
 // GetTenantByDomain retrieves a tenant by domain
-func (s *TenantService) GetTenantByDomain(domain string) (*Tenant, error) {
-	return s.repo.GetByDomain(domain)
+func (s *TenantService) GetTenantByDomain(ctx context.Context, domain string) (*Tenant, error) {
+	return s.repo.GetByDomain(ctx, domain)
 }
 
 // UpdateTenant updates an existing tenant
-func (s *TenantService) UpdateTenant(tenant *Tenant) error {
+func (s *TenantService) UpdateTenant(ctx context.Context, tenant *Tenant) error {
 	tenant.UpdatedAt = time.Now()
-	return s.repo.Update(tenant)
+	return s.repo.Update(ctx, tenant)
 }
 
 // DeleteTenant soft deletes a tenant
-func (s *TenantService) DeleteTenant(id string) error {
-	return s.repo.Delete(id)
+func (s *TenantService) DeleteTenant(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
 }
 
 // ListTenants retrieves a list of tenants with pagination
-func (s *TenantService) ListTenants(limit, offset int) ([]*Tenant, error) {
-	return s.repo.List(limit, offset)
+func (s *TenantService) ListTenants(ctx context.Context, limit, offset int) ([]*Tenant, error) {
+	return s.repo.List(ctx, limit, offset)
 }
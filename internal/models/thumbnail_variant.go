@@ -0,0 +1,71 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// MaxThumbnailTextLength is the longest hook text laid over a thumbnail, in
+// characters, that still reads at the size platforms show thumbnails
+const MaxThumbnailTextLength = 40
+
+// ThumbnailVariant is a thumbnail the worker renders from a frame of a video
+// with a hook text in one of the layouts, with the brand of the tenant when
+// it was composed. The selected variant is the video's thumbnail.
+type ThumbnailVariant struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	VideoID  string `json:"video_id" gorm:"type:varchar(36);not null;index"`
+	// FrameSeconds is the time of the frame the thumbnail is rendered from
+	FrameSeconds float64                   `json:"frame_seconds"`
+	Text         string                    `json:"text" gorm:"type:varchar(255);not null"`
+	Layout       transcode.ThumbnailLayout `json:"layout" gorm:"type:varchar(20);not null"`
+	Font         string                    `json:"font" gorm:"type:varchar(50);not null"`
+	TextColor    string                    `json:"text_color" gorm:"type:varchar(7);not null"`
+	AccentColor  string                    `json:"accent_color" gorm:"type:varchar(7);not null"`
+	Status       string                    `json:"status" gorm:"type:varchar(20);not null"` // A RenditionStatus
+	ImageURL     string                    `json:"image_url,omitempty" gorm:"type:varchar(500)"`
+	ErrorMsg     string                    `json:"error_msg,omitempty" gorm:"type:text"`
+	Selected     bool                      `json:"selected" gorm:"not null;default:false"`
+	CreatedBy    string                    `json:"created_by" gorm:"type:varchar(36)"`
+	RenderedAt   *time.Time                `json:"rendered_at,omitempty"`
+	CreatedAt    time.Time                 `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time                 `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ComposeThumbnailsRequest asks for thumbnail variants of a video with a hook
+// text, such as one suggested by the thumbnail magic brush
+type ComposeThumbnailsRequest struct {
+	Text string `json:"text" binding:"required"`
+	// FrameSeconds picks the frame, a third into the video when empty
+	FrameSeconds *float64 `json:"frame_seconds,omitempty"`
+	// Layouts are those to render, all of them when empty
+	Layouts []transcode.ThumbnailLayout `json:"layouts,omitempty"`
+}
+
+// ThumbnailVariantRepository defines the interface for thumbnail variant
+// operations
+type ThumbnailVariantRepository interface {
+	Create(ctx context.Context, variants []*ThumbnailVariant) error
+	// Get returns a variant of the video, or ErrThumbnailVariantNotFound
+	Get(ctx context.Context, tenantID, videoID, id string) (*ThumbnailVariant, error)
+	// ListByVideo returns the video's variants, newest first
+	ListByVideo(ctx context.Context, tenantID, videoID string) ([]*ThumbnailVariant, error)
+	Update(ctx context.Context, variant *ThumbnailVariant) error
+	// Select marks the variant the video's only selected one
+	Select(ctx context.Context, tenantID, videoID, id string) error
+}
+
+// Ready reports whether the variant was rendered
+func (v *ThumbnailVariant) Ready() bool {
+	return v.Status == string(RenditionReady)
+}
+
+// Args returns the ffmpeg arguments, up to the output path, rendering the
+// variant from the video file at input
+func (v *ThumbnailVariant) Args(input string) []string {
+	brand := transcode.Brand{Font: v.Font, TextColor: v.TextColor, AccentColor: v.AccentColor}
+	return brand.ThumbnailArgs(input, v.FrameSeconds, v.Layout, v.Text)
+}
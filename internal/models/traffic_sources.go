@@ -0,0 +1,71 @@
+package models
+
+import "math"
+
+// Normalized traffic sources: how viewers reached a video
+const (
+	TrafficSearch    = "search"    // Platform search, hashtags
+	TrafficSuggested = "suggested" // Recommendations next to or after other videos, For You feeds
+	TrafficExternal  = "external"  // Other websites and apps, embeds
+	TrafficBrowse    = "browse"    // Home, subscription and channel pages, playlists, notifications
+	TrafficOther     = "other"     // Ads and whatever a platform does not say
+)
+
+// TrafficSourceTypes lists the normalized traffic sources
+var TrafficSourceTypes = []string{TrafficSearch, TrafficSuggested, TrafficExternal, TrafficBrowse, TrafficOther}
+
+// TrafficSources is the share of a video's views, from 0 to 1, coming from
+// each normalized traffic source
+type TrafficSources map[string]float64
+
+// CombineTrafficSources merges the traffic sources of stats rows, weighting
+// each row by its views. Nil when no row has traffic sources and views.
+func CombineTrafficSources(stats []*VideoStats) TrafficSources {
+	var views int64
+	for _, s := range stats {
+		if len(s.TrafficSources) > 0 {
+			views += s.Views
+		}
+	}
+	if views == 0 {
+		return nil
+	}
+
+	combined := TrafficSources{}
+	for _, s := range stats {
+		if len(s.TrafficSources) == 0 {
+			continue
+		}
+		weight := float64(s.Views) / float64(views)
+		for source, share := range s.TrafficSources {
+			combined[source] += share * weight
+		}
+	}
+	return combined
+}
+
+// TrafficSourceViews is the views of stats rows split by traffic source
+type TrafficSourceViews struct {
+	Views   int64            `json:"views"`   // Views of the rows reporting traffic sources
+	Sources map[string]int64 `json:"sources"` // Estimated from each row's shares
+	Shares  TrafficSources   `json:"shares"`
+}
+
+// SumTrafficSources splits the views of the stats rows reporting traffic
+// sources by source
+func SumTrafficSources(stats []*VideoStats) *TrafficSourceViews {
+	sum := &TrafficSourceViews{Sources: map[string]int64{}, Shares: CombineTrafficSources(stats)}
+	for _, s := range stats {
+		if len(s.TrafficSources) == 0 {
+			continue
+		}
+		sum.Views += s.Views
+		for source, share := range s.TrafficSources {
+			sum.Sources[source] += int64(math.Round(share * float64(s.Views)))
+		}
+	}
+	if sum.Shares == nil {
+		sum.Shares = TrafficSources{}
+	}
+	return sum
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Transcript represents a timed transcript of a video
+type Transcript struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_transcripts_video_language"`
+	VideoID  string `json:"video_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_transcripts_video_language"`
+	Language string `json:"language" gorm:"type:varchar(10);not null;uniqueIndex:idx_transcripts_video_language"`
+	Source   string `json:"source" gorm:"type:varchar(50);not null;default:'generated'"`
+	// Text holds the plain transcript and backs the full-text index
+	Text      string    `json:"text" gorm:"type:longtext;index:idx_transcripts_text,class:FULLTEXT"`
+	Segments  string    `json:"-" gorm:"type:json"` // JSON array of TranscriptSegment
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TranscriptSegment represents a timed piece of a transcript, offsets in seconds
+type TranscriptSegment struct {
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+	Speaker string  `json:"speaker,omitempty"`
+}
+
+// Transcript sources
+const (
+	TranscriptSourceGenerated = "generated"
+	TranscriptSourceUploaded  = "uploaded"
+	// TranscriptSourceTranslated transcripts are the scripts of dubbed
+	// audio tracks, translated from the original transcript
+	TranscriptSourceTranslated = "translated"
+)
+
+// SaveTranscriptRequest represents the request to store a video transcript
+type SaveTranscriptRequest struct {
+	Language string              `json:"language" binding:"required" validate:"required,max=10"`
+	Source   string              `json:"source,omitempty" validate:"omitempty,oneof=generated uploaded"`
+	Segments []TranscriptSegment `json:"segments" binding:"required,min=1" validate:"required,min=1"`
+}
+
+// TranscriptSearchResult represents a transcript matching a search query
+type TranscriptSearchResult struct {
+	VideoID  string  `json:"video_id"`
+	Language string  `json:"language"`
+	Snippet  string  `json:"snippet"`
+	Score    float64 `json:"score"`
+}
+
+// TranscriptRepository defines data access methods for transcripts
+type TranscriptRepository interface {
+	Upsert(ctx context.Context, transcript *Transcript) error
+	GetByVideoID(ctx context.Context, tenantID, videoID, language string) (*Transcript, error)
+	ListByVideoID(ctx context.Context, tenantID, videoID string) ([]*Transcript, error)
+	// Search keeps the transcripts of the videos a scope sees; nil keeps them all
+	Search(ctx context.Context, tenantID string, scope *VideoScope, query string, limit, offset int) ([]*TranscriptSearchResult, error)
+	Delete(ctx context.Context, tenantID, videoID, language string) error
+}
+
+// GetSegments decodes the transcript segments
+func (t *Transcript) GetSegments() []TranscriptSegment {
+	var segments []TranscriptSegment
+	if t.Segments == "" {
+		return segments
+	}
+	if err := json.Unmarshal([]byte(t.Segments), &segments); err != nil {
+		return nil
+	}
+	return segments
+}
+
+// SetSegments stores the segments and refreshes the plain text used for search
+func (t *Transcript) SetSegments(segments []TranscriptSegment) error {
+	data, err := json.Marshal(segments)
+	if err != nil {
+		return fmt.Errorf("failed to encode transcript segments: %w", err)
+	}
+	t.Segments = string(data)
+
+	texts := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		texts = append(texts, strings.TrimSpace(segment.Text))
+	}
+	t.Text = strings.Join(texts, " ")
+	return nil
+}
+
+// ToSRT renders the transcript in SubRip format
+func (t *Transcript) ToSRT() string {
+	var b strings.Builder
+	for i, segment := range t.GetSegments() {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, formatSRTTimestamp(segment.Start), formatSRTTimestamp(segment.End), strings.TrimSpace(segment.Text))
+	}
+	return b.String()
+}
+
+// ToPlainText renders the transcript as plain text, one segment per line
+func (t *Transcript) ToPlainText() string {
+	var b strings.Builder
+	for _, segment := range t.GetSegments() {
+		b.WriteString(strings.TrimSpace(segment.Text))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// ValidateSegments checks segment timings are well-formed and ordered
+func ValidateSegments(segments []TranscriptSegment) error {
+	previousEnd := 0.0
+	for i, segment := range segments {
+		if segment.Start < 0 || segment.End <= segment.Start {
+			return fmt.Errorf("%w: segment %d has invalid timing", ErrInvalidInput, i+1)
+		}
+		if segment.Start < previousEnd {
+			return fmt.Errorf("%w: segment %d overlaps the previous segment", ErrInvalidInput, i+1)
+		}
+		previousEnd = segment.End
+	}
+	return nil
+}
+
+// formatSRTTimestamp formats seconds as HH:MM:SS,mmm
+func formatSRTTimestamp(seconds float64) string {
+	total := int64(seconds*1000 + 0.5)
+	ms := total % 1000
+	s := (total / 1000) % 60
+	m := (total / 60000) % 60
+	h := total / 3600000
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", h, m, s, ms)
+}
@@ -1,10 +1,14 @@
 package models
 
 import (
+	"context"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+
+	// Registers the "encrypted" serializer used by sensitive columns
+	_ "github.com/jibe0123/mysteryfactory/pkg/encryption"
 )
 
 // User represents a user in the system
@@ -13,8 +17,8 @@ type User struct {
 	TenantID  string         `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_tenant_email"`
 	Email     string         `json:"email" gorm:"type:varchar(255);not null;index:idx_tenant_email;uniqueIndex:idx_tenant_email_unique"`
 	Password  string         `json:"-" gorm:"type:varchar(255);not null"` // Never include in JSON responses
-	FirstName string         `json:"first_name" gorm:"type:varchar(100);not null"`
-	LastName  string         `json:"last_name" gorm:"type:varchar(100);not null"`
+	FirstName string         `json:"first_name" gorm:"type:varchar(255);not null;serializer:encrypted"`
+	LastName  string         `json:"last_name" gorm:"type:varchar(255);not null;serializer:encrypted"`
 	Role      string         `json:"role" gorm:"type:varchar(50);not null;default:'viewer'"`
 	Status    string         `json:"status" gorm:"type:varchar(50);not null;default:'active'"`
 	LastLogin *time.Time     `json:"last_login,omitempty" gorm:"type:timestamp"`
@@ -66,13 +70,16 @@ type LoginResponse struct {
 
 // UserRepository defines the interface for user operations
 type UserRepository interface {
-	Create(user *User) error
-	GetByID(tenantID, id string) (*User, error)
-	GetByEmail(tenantID, email string) (*User, error)
-	Update(user *User) error
-	Delete(tenantID, id string) error
-	List(tenantID string, limit, offset int) ([]*User, error)
-	UpdateLastLogin(tenantID, id string) error
+	Create(ctx context.Context, user *User) error
+	GetByID(ctx context.Context, tenantID, id string) (*User, error)
+	GetByEmail(ctx context.Context, tenantID, email string) (*User, error)
+	// FindByEmail looks the user up in every tenant, for logins: emails are
+	// unique across tenants
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	Update(ctx context.Context, user *User) error
+	Delete(ctx context.Context, tenantID, id string) error
+	List(ctx context.Context, tenantID string, limit, offset int) ([]*User, error)
+	UpdateLastLogin(ctx context.Context, tenantID, id string) error
 }
 
 // UserService handles business logic for users
@@ -86,7 +93,7 @@ func NewUserService(repo UserRepository) *UserService {
 }
 
 // CreateUser creates a new user
-func (s *UserService) CreateUser(tenantID string, req *CreateUserRequest) (*User, error) {
+func (s *UserService) CreateUser(ctx context.Context, tenantID string, req *CreateUserRequest) (*User, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
@@ -104,7 +111,7 @@ func (s *UserService) CreateUser(tenantID string, req *CreateUserRequest) (*User
 		UpdatedAt: time.Now(),
 	}
 
-	if err := s.repo.Create(user); err != nil {
+	if err := s.repo.Create(ctx, user); err != nil {
 		return nil, err
 	}
 
@@ -112,34 +119,34 @@ func (s *UserService) CreateUser(tenantID string, req *CreateUserRequest) (*User
 }
 
 // GetUser retrieves a user by ID
-func (s *UserService) GetUser(tenantID, id string) (*User, error) {
-	return s.repo.GetByID(tenantID, id)
+func (s *UserService) GetUser(ctx context.Context, tenantID, id string) (*User, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
 }
 
 // GetUserByEmail retrieves a user by email
-func (s *UserService) GetUserByEmail(tenantID, email string) (*User, error) {
-	return s.repo.GetByEmail(tenantID, email)
+func (s *UserService) GetUserByEmail(ctx context.Context, tenantID, email string) (*User, error) {
+	return s.repo.GetByEmail(ctx, tenantID, email)
 }
 
 // UpdateUser updates an existing user
-func (s *UserService) UpdateUser(user *User) error {
+func (s *UserService) UpdateUser(ctx context.Context, user *User) error {
 	user.UpdatedAt = time.Now()
-	return s.repo.Update(user)
+	return s.repo.Update(ctx, user)
 }
 
 // DeleteUser soft deletes a user
-func (s *UserService) DeleteUser(tenantID, id string) error {
-	return s.repo.Delete(tenantID, id)
+func (s *UserService) DeleteUser(ctx context.Context, tenantID, id string) error {
+	return s.repo.Delete(ctx, tenantID, id)
 }
 
 // ListUsers retrieves a list of users with pagination
-func (s *UserService) ListUsers(tenantID string, limit, offset int) ([]*User, error) {
-	return s.repo.List(tenantID, limit, offset)
+func (s *UserService) ListUsers(ctx context.Context, tenantID string, limit, offset int) ([]*User, error) {
+	return s.repo.List(ctx, tenantID, limit, offset)
 }
 
 // AuthenticateUser authenticates a user with email and password
-func (s *UserService) AuthenticateUser(tenantID string, req *LoginRequest) (*User, error) {
-	user, err := s.repo.GetByEmail(tenantID, req.Email)
+func (s *UserService) AuthenticateUser(ctx context.Context, tenantID string, req *LoginRequest) (*User, error) {
+	user, err := s.repo.GetByEmail(ctx, tenantID, req.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +160,7 @@ func (s *UserService) AuthenticateUser(tenantID string, req *LoginRequest) (*Use
 	}
 
 	// Update last login
-	if err := s.repo.UpdateLastLogin(tenantID, user.ID); err != nil {
+	if err := s.repo.UpdateLastLogin(ctx, tenantID, user.ID); err != nil {
 		// Log error but don't fail authentication
 	}
 
@@ -161,8 +168,8 @@ func (s *UserService) AuthenticateUser(tenantID string, req *LoginRequest) (*Use
 }
 
 // ChangePassword changes a user's password
-func (s *UserService) ChangePassword(tenantID, userID, newPassword string) error {
-	user, err := s.repo.GetByID(tenantID, userID)
+func (s *UserService) ChangePassword(ctx context.Context, tenantID, userID, newPassword string) error {
+	user, err := s.repo.GetByID(ctx, tenantID, userID)
 	if err != nil {
 		return err
 	}
@@ -175,7 +182,7 @@ func (s *UserService) ChangePassword(tenantID, userID, newPassword string) error
 	user.Password = string(hashedPassword)
 	user.UpdatedAt = time.Now()
 
-	return s.repo.Update(user)
+	return s.repo.Update(ctx, user)
 }
 
 // HasPermission checks if a user has a specific permission
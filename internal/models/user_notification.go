@@ -0,0 +1,32 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// UserNotification is a notification sent to one user. It is listed in the
+// dashboard when the user has in-app notifications, and waits for the next
+// digest when the user gets emailed digests.
+type UserNotification struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	UserID   string `json:"user_id" gorm:"type:varchar(36);not null;index:idx_user_notifications_user,priority:1"`
+	Subject  string `json:"subject" gorm:"type:varchar(255);not null"`
+	Message  string `json:"message" gorm:"type:text"`
+	InApp    bool   `json:"-"`
+	// PendingDigest marks notifications still to be emailed in a digest
+	PendingDigest bool      `json:"-" gorm:"index"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime;index:idx_user_notifications_user,priority:2"`
+}
+
+// UserNotificationRepository defines the interface for user notification operations
+type UserNotificationRepository interface {
+	Create(ctx context.Context, notification *UserNotification) error
+	// ListInApp returns the user's in-app notifications, newest first
+	ListInApp(ctx context.Context, tenantID, userID string, limit, offset int) ([]*UserNotification, error)
+	// ListPendingDigest returns the notifications waiting for the user's
+	// digest, oldest first
+	ListPendingDigest(ctx context.Context, tenantID, userID string) ([]*UserNotification, error)
+	MarkDigested(ctx context.Context, tenantID string, ids []string) error
+}
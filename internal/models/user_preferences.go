@@ -0,0 +1,126 @@
+package models
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+)
+
+// NotificationChannel defines where a user receives notifications
+type NotificationChannel string
+
+const (
+	NotificationEmail NotificationChannel = "email"
+	// NotificationInApp lists notifications in the dashboard
+	NotificationInApp NotificationChannel = "in_app"
+)
+
+// DigestFrequency defines how often emailed notifications are sent
+type DigestFrequency string
+
+const (
+	// DigestNone emails each notification as it happens
+	DigestNone   DigestFrequency = "none"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// DigestHour is the hour of the day, in the user's timezone, digests are
+// sent at; weekly digests go out on Mondays
+const DigestHour = 8
+
+// UILanguages are the languages the dashboard and the API messages are
+// translated to
+var UILanguages = i18n.Languages
+
+// UserPreferences holds a user's dashboard language, timezone and
+// notification settings. Users without a row get DefaultUserPreferences.
+type UserPreferences struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID   string `json:"user_id" gorm:"type:varchar(36);not null;uniqueIndex"`
+	TenantID string `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	Language string `json:"language" gorm:"type:varchar(10);not null"`
+	// Timezone is an IANA name; analytics days start at midnight there
+	Timezone             string   `json:"timezone" gorm:"type:varchar(64);not null"`
+	NotificationChannels []string `json:"notification_channels" gorm:"type:json;serializer:json"`
+	DigestFrequency      string   `json:"digest_frequency" gorm:"type:varchar(10);not null;index"`
+	// LastDigestAt is when the last digest was sent, so one is sent per period
+	LastDigestAt *time.Time `json:"last_digest_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// UpdatePreferencesRequest represents a change of preferences; omitted
+// fields are left unchanged
+type UpdatePreferencesRequest struct {
+	Language             *string  `json:"language,omitempty"`
+	Timezone             *string  `json:"timezone,omitempty"`
+	NotificationChannels []string `json:"notification_channels,omitempty"` // An empty list turns notifications off
+	DigestFrequency      *string  `json:"digest_frequency,omitempty"`
+}
+
+// UserPreferencesRepository defines the interface for user preferences operations
+type UserPreferencesRepository interface {
+	// Get returns the user's preferences, or ErrNotFound when they were never set
+	Get(ctx context.Context, tenantID, userID string) (*UserPreferences, error)
+	Upsert(ctx context.Context, prefs *UserPreferences) error
+	// ListDigests pages through the preferences of users, of every tenant,
+	// receiving digests, in user ID order starting after afterUserID
+	ListDigests(ctx context.Context, afterUserID string, limit int) ([]*UserPreferences, error)
+}
+
+// DefaultUserPreferences returns the preferences of a user who never set them
+func DefaultUserPreferences(tenantID, userID string) *UserPreferences {
+	return &UserPreferences{
+		UserID:               userID,
+		TenantID:             tenantID,
+		Language:             i18n.Default,
+		Timezone:             "UTC",
+		NotificationChannels: []string{string(NotificationInApp), string(NotificationEmail)},
+		DigestFrequency:      string(DigestNone),
+	}
+}
+
+// Valid reports whether the channel is supported
+func (c NotificationChannel) Valid() bool {
+	return c == NotificationEmail || c == NotificationInApp
+}
+
+// Valid reports whether the frequency is supported
+func (f DigestFrequency) Valid() bool {
+	return f == DigestNone || f == DigestDaily || f == DigestWeekly
+}
+
+// Location returns the user's timezone, UTC when it cannot be loaded
+func (p *UserPreferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Wants reports whether the user receives notifications on the channel
+func (p *UserPreferences) Wants(channel NotificationChannel) bool {
+	return slices.Contains(p.NotificationChannels, string(channel))
+}
+
+// DigestDue reports whether the user's digest should be sent at now: the
+// digest hour has passed in the user's timezone, on a Monday for weekly
+// digests, and none was sent since the period started
+func (p *UserPreferences) DigestDue(now time.Time) bool {
+	local := now.In(p.Location())
+	start := time.Date(local.Year(), local.Month(), local.Day(), DigestHour, 0, 0, 0, local.Location())
+	switch DigestFrequency(p.DigestFrequency) {
+	case DigestDaily:
+	case DigestWeekly:
+		start = start.AddDate(0, 0, -((int(local.Weekday()) + 6) % 7))
+	default:
+		return false
+	}
+	if local.Before(start) {
+		return false
+	}
+	return p.LastDigestAt == nil || p.LastDigestAt.Before(start)
+}
@@ -1,8 +1,16 @@
 package models
 
 import (
-	"gorm.io/gorm"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
 )
 
 // Video represents a video in the system
@@ -24,6 +32,11 @@ type Video struct {
 	S3Key        string `json:"s3_key" gorm:"type:varchar(500)"`
 	S3Bucket     string `json:"s3_bucket" gorm:"type:varchar(255)"`
 	FileURL      string `json:"file_url" gorm:"type:varchar(500)"`
+	CampaignID   string `json:"campaign_id,omitempty" gorm:"type:varchar(36);index"` // AI campaign the video was made for
+
+	// Who of the tenant sees the video, see VisibleTo
+	Visibility  string `json:"visibility" gorm:"type:varchar(20);not null;default:'tenant'"`
+	WorkspaceID string `json:"workspace_id,omitempty" gorm:"type:varchar(36);index"` // Channel the video is made for
 
 	// IDs returned by partner platforms
 	YouTubeID       string `json:"youtube_id" gorm:"type:varchar(100)"`
@@ -33,12 +46,207 @@ type Video struct {
 	TwitterMediaID  int64  `json:"twitter_media_id"`
 	SnapchatMediaID string `json:"snapchat_media_id" gorm:"type:varchar(100)"`
 
-	Tags      string         `json:"tags" gorm:"type:json"` // JSON array as string
+	// Cold storage lifecycle
+	ArchivedAt         *time.Time `json:"archived_at,omitempty"`
+	RestoreStatus      string     `json:"restore_status,omitempty" gorm:"type:varchar(20);index:idx_restore_status"`
+	RestoreRequestedAt *time.Time `json:"restore_requested_at,omitempty"`
+
+	// What ffprobe reported of the uploaded file when it was processed
+	MediaInfo *transcode.MediaInfo `json:"media_info,omitempty" gorm:"type:json;serializer:json"`
+	ProbedAt  *time.Time           `json:"probed_at,omitempty"`
+
+	// Sprites and animation the dashboard shows when hovering the video
+	HoverPreview HoverPreview `json:"hover_preview" gorm:"embedded;embeddedPrefix:preview_"`
+
+	// Frames to pick a thumbnail from, served apart from the video
+	Frames VideoFrames `json:"-" gorm:"embedded;embeddedPrefix:frames_"`
+
+	// Rights to the footage, enforced when publishing
+	Rights VideoRights `json:"rights" gorm:"embedded;embeddedPrefix:rights_"`
+
+	// Where the video stands in a series, numbered when it is added to it
+	Episode VideoEpisode `json:"episode" gorm:"embedded;embeddedPrefix:episode_"`
+
+	// Quality control of the transcoded file; failing it holds the video
+	QC VideoQC `json:"qc" gorm:"embedded;embeddedPrefix:qc_"`
+
+	Tags      []string       `json:"tags" gorm:"type:json;serializer:json"`
 	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
 
+// HoverPreview are the thumbnails generated while processing a video, so
+// lists show what it is about without loading it. Sprite sheets are laid out
+// left to right, then top to bottom, one tile every SpriteInterval seconds.
+type HoverPreview struct {
+	SpriteURLs     []string   `json:"sprite_urls,omitempty" gorm:"type:json;serializer:json"`
+	SpriteInterval int        `json:"sprite_interval_seconds,omitempty"`
+	SpriteFrames   int        `json:"sprite_frames,omitempty"` // Tiles over all the sheets
+	SpriteColumns  int        `json:"sprite_columns,omitempty"`
+	SpriteRows     int        `json:"sprite_rows,omitempty"`
+	TileWidth      int        `json:"tile_width,omitempty"`
+	TileHeight     int        `json:"tile_height,omitempty"`
+	AnimationURL   string     `json:"animation_url,omitempty" gorm:"type:varchar(500)"` // Looping animated WebP
+	GeneratedAt    *time.Time `json:"generated_at,omitempty"`
+}
+
+// VideoFrames are the frames extracted while processing a video: one every
+// IntervalSeconds, named as transcode.FrameName, and the first of each scene,
+// named as transcode.SceneName, all stored under BaseURL
+type VideoFrames struct {
+	BaseURL         string     `json:"base_url,omitempty" gorm:"type:varchar(500)"`
+	IntervalSeconds int        `json:"interval_seconds,omitempty"`
+	Count           int        `json:"count,omitempty"` // Interval frames
+	SceneSeconds    []float64  `json:"scene_seconds,omitempty" gorm:"type:json;serializer:json"`
+	ExtractedAt     *time.Time `json:"extracted_at,omitempty"`
+}
+
+// Frame is an extracted frame of a video
+type Frame struct {
+	Seconds     float64 `json:"seconds"`
+	URL         string  `json:"url"`
+	SceneChange bool    `json:"scene_change"`
+}
+
+// Extracted reports whether the frames were extracted
+func (f *VideoFrames) Extracted() bool {
+	return f.ExtractedAt != nil && f.Count > 0
+}
+
+// Nearest returns the frame closest to the given second, preferring the
+// interval frame on ties
+func (f *VideoFrames) Nearest(at float64) Frame {
+	n := int(math.Round(at / float64(f.IntervalSeconds)))
+	n = max(0, min(n, f.Count-1))
+	nearest := f.interval(n)
+	for i, seconds := range f.SceneSeconds {
+		if math.Abs(seconds-at) < math.Abs(nearest.Seconds-at) {
+			nearest = f.scene(i)
+		}
+	}
+	return nearest
+}
+
+// Scenes returns the first frame of each scene
+func (f *VideoFrames) Scenes() []Frame {
+	frames := make([]Frame, len(f.SceneSeconds))
+	for i := range f.SceneSeconds {
+		frames[i] = f.scene(i)
+	}
+	return frames
+}
+
+// Spread returns up to n interval frames evenly spread over the video
+func (f *VideoFrames) Spread(n int) []Frame {
+	n = min(n, f.Count)
+	frames := make([]Frame, n)
+	for i := range frames {
+		frames[i] = f.interval(i * f.Count / n)
+	}
+	return frames
+}
+
+// interval returns the interval frame at index i, from 0
+func (f *VideoFrames) interval(i int) Frame {
+	return Frame{
+		Seconds: float64(i * f.IntervalSeconds),
+		URL:     strings.TrimSuffix(f.BaseURL, "/") + "/" + transcode.FrameName(i+1),
+	}
+}
+
+// scene returns the scene-change frame at index i, from 0
+func (f *VideoFrames) scene(i int) Frame {
+	return Frame{
+		Seconds:     f.SceneSeconds[i],
+		URL:         strings.TrimSuffix(f.BaseURL, "/") + "/" + transcode.SceneName(i+1),
+		SceneChange: true,
+	}
+}
+
+// VideoRights are the license a video is published under. Videos without an
+// expiry or platforms may be published anywhere, at any time.
+type VideoRights struct {
+	Owner       string     `json:"owner,omitempty" gorm:"type:varchar(255)"`
+	LicenseType string     `json:"license_type,omitempty" gorm:"type:varchar(50)"`
+	Platforms   []string   `json:"platforms,omitempty" gorm:"type:json;serializer:json"` // Empty allows every platform
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" gorm:"index:idx_rights_expires"`
+}
+
+// License types
+const (
+	LicenseOwned           = "owned"
+	LicenseExclusive       = "exclusive"
+	LicenseNonExclusive    = "non_exclusive"
+	LicenseCreativeCommons = "creative_commons"
+	LicensePublicDomain    = "public_domain"
+)
+
+// Validate checks the license type and platforms
+func (r *VideoRights) Validate() error {
+	switch r.LicenseType {
+	case "", LicenseOwned, LicenseExclusive, LicenseNonExclusive, LicenseCreativeCommons, LicensePublicDomain:
+	default:
+		return fmt.Errorf("%w: unknown license type %q", ErrInvalidInput, r.LicenseType)
+	}
+	for _, platform := range r.Platforms {
+		if !Platform(platform).Valid() {
+			return fmt.Errorf("%w: %s", ErrInvalidPlatform, platform)
+		}
+	}
+	return nil
+}
+
+// Allow checks that the rights cover publishing on the platform at the given time
+func (r *VideoRights) Allow(platform Platform, at time.Time) error {
+	if r.ExpiresAt != nil && !at.Before(*r.ExpiresAt) {
+		return fmt.Errorf("%w on %s", ErrRightsExpired, r.ExpiresAt.UTC().Format(time.DateOnly))
+	}
+	if len(r.Platforms) > 0 && !slices.Contains(r.Platforms, string(platform)) {
+		return fmt.Errorf("%w: %s", ErrPlatformNotLicensed, platform)
+	}
+	return nil
+}
+
+// Video visibilities within the tenant. Admins see every video.
+const (
+	VisibilityOwner     = "owner"     // The owner only
+	VisibilityWorkspace = "workspace" // The owner and the user of the video's workspace
+	VisibilityTenant    = "tenant"    // Every user of the tenant
+)
+
+// ValidateVisibility checks a video's visibility and the workspace it needs
+func ValidateVisibility(visibility, workspaceID string) error {
+	switch visibility {
+	case VisibilityOwner, VisibilityTenant:
+	case VisibilityWorkspace:
+		if workspaceID == "" {
+			return fmt.Errorf("%w: workspace visibility needs a workspace_id", ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unknown visibility %q", ErrInvalidInput, visibility)
+	}
+	return nil
+}
+
+// VisibleTo reports whether the user sees the video; workspaceIDs are the
+// workspaces the user manages
+func (v *Video) VisibleTo(user *User, workspaceIDs []string) bool {
+	if user.TenantID != v.TenantID {
+		return false
+	}
+	if UserRole(user.Role) == RoleAdmin || user.ID == v.UserID {
+		return true
+	}
+	switch v.Visibility {
+	case VisibilityOwner:
+		return false
+	case VisibilityWorkspace:
+		return v.WorkspaceID != "" && slices.Contains(workspaceIDs, v.WorkspaceID)
+	}
+	return true
+}
+
 // VideoStatus defines video processing statuses
 type VideoStatus string
 
@@ -48,35 +256,99 @@ const (
 	StatusReady      VideoStatus = "ready"
 	StatusFailed     VideoStatus = "failed"
 	StatusArchived   VideoStatus = "archived"
+	StatusQCFailed   VideoStatus = "qc_failed" // Held until its file is fixed or the failure overridden
+)
+
+// Restore statuses of an archived video
+const (
+	RestoreInProgress = "in_progress"
+	RestoreCompleted  = "completed"
 )
 
 // CreateVideoRequest represents the request to create a new video
 type CreateVideoRequest struct {
-	Title       string   `json:"title" validate:"required,max=255"`
-	Description string   `json:"description" validate:"max=1000"`
-	FileName    string   `json:"file_name" validate:"required"`
-	FileSize    int64    `json:"file_size" validate:"required,min=1"`
-	Format      string   `json:"format" validate:"required"`
-	Tags        []string `json:"tags,omitempty"`
+	Title       string       `json:"title" validate:"required,max=255"`
+	Description string       `json:"description" validate:"max=1000"`
+	FileName    string       `json:"file_name" validate:"required"`
+	FileSize    int64        `json:"file_size" validate:"required,min=1"`
+	Format      string       `json:"format" validate:"required"`
+	Tags        []string     `json:"tags,omitempty"`
+	Rights      *VideoRights `json:"rights,omitempty"`
+	CampaignID  string       `json:"campaign_id,omitempty"`
+	Visibility  string       `json:"visibility,omitempty"` // tenant by default
+	WorkspaceID string       `json:"workspace_id,omitempty"`
 }
 
 // UpdateVideoRequest represents the request to update a video
 type UpdateVideoRequest struct {
-	Title       *string  `json:"title,omitempty" validate:"omitempty,max=255"`
-	Description *string  `json:"description,omitempty" validate:"omitempty,max=1000"`
-	Tags        []string `json:"tags,omitempty"`
+	Title       *string      `json:"title,omitempty" validate:"omitempty,max=255"`
+	Description *string      `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Tags        []string     `json:"tags,omitempty"`
+	Rights      *VideoRights `json:"rights,omitempty"`      // Replaces all the rights
+	CampaignID  *string      `json:"campaign_id,omitempty"` // Empty to take the video out of its campaign
+	Visibility  *string      `json:"visibility,omitempty"`  // Owner and admins only
+	WorkspaceID *string      `json:"workspace_id,omitempty"`
+}
+
+// VideoSortColumns are the columns video listings can be ordered by
+var VideoSortColumns = map[string]string{
+	"created_at": "videos.created_at",
+	"title":      "videos.title",
+	"views":      "COALESCE(video_stats_summaries.views, 0)",
+}
+
+// VideoListFilter narrows and orders a listing of the tenant's videos
+type VideoListFilter struct {
+	Status        VideoStatus
+	Tag           string
+	CreatedFrom   *time.Time // Inclusive
+	CreatedBefore *time.Time // Exclusive
+	Sort          string     // A VideoSortColumns key
+	Desc          bool
+}
+
+// VideoOwnerRequest hands a video over to another user of the tenant
+type VideoOwnerRequest struct {
+	UserID string `json:"user_id" binding:"required"`
 }
 
 // VideoRepository defines the interface for video operations
 type VideoRepository interface {
-	Create(video *Video) error
-	GetByID(tenantID, id string) (*Video, error)
-	GetByUserID(tenantID, userID string, limit, offset int) ([]*Video, error)
-	Update(video *Video) error
-	Delete(tenantID, id string) error
-	List(tenantID string, limit, offset int) ([]*Video, error)
-	UpdateStatus(tenantID, id string, status VideoStatus) error
-	GetByStatus(tenantID string, status VideoStatus, limit, offset int) ([]*Video, error)
+	Create(ctx context.Context, video *Video) error
+	GetByID(ctx context.Context, tenantID, id string) (*Video, error)
+	GetByIDs(ctx context.Context, tenantID string, ids []string) ([]*Video, error)
+	GetByUserID(ctx context.Context, tenantID, userID string, limit, offset int) ([]*Video, error)
+	// ListVisible returns the tenant's videos a non-admin user sees: shared
+	// with the tenant, owned by the user or shared with one of workspaceIDs
+	ListVisible(ctx context.Context, tenantID, userID string, workspaceIDs []string, limit, offset int) ([]*Video, error)
+	Update(ctx context.Context, video *Video) error
+	Delete(ctx context.Context, tenantID, id string) error
+	List(ctx context.Context, tenantID string, limit, offset int) ([]*Video, error)
+	UpdateStatus(ctx context.Context, tenantID, id string, status VideoStatus) error
+	GetByStatus(ctx context.Context, tenantID string, status VideoStatus, limit, offset int) ([]*Video, error)
+	// ListArchivable returns ready videos stored in S3, never published to a
+	// platform and last updated before the cutoff
+	ListArchivable(ctx context.Context, tenantID string, updatedBefore time.Time, limit int) ([]*Video, error)
+	// ListRestoring returns archived videos of every tenant with a restore in progress
+	ListRestoring(ctx context.Context, limit int) ([]*Video, error)
+	// ListRightsExpiring returns the tenant's videos whose rights expire in
+	// [from, to), soonest first
+	ListRightsExpiring(ctx context.Context, tenantID string, from, to time.Time, limit int) ([]*Video, error)
+	// ListByCampaign returns the tenant's videos made for the campaign, newest first
+	ListByCampaign(ctx context.Context, tenantID, campaignID string, limit int) ([]*Video, error)
+	// ListMatching returns the tenant's videos meeting all the conditions of
+	// a smart list, newest first, limited to what scope sees when it is set
+	ListMatching(ctx context.Context, tenantID string, scope *VideoScope, conditions []SmartListCondition, limit, offset int) ([]*Video, error)
+	// Search returns the tenant's videos matching the filter in its order,
+	// and how many match, limited to what scope sees when it is set
+	Search(ctx context.Context, tenantID string, scope *VideoScope, filter VideoListFilter, limit, offset int) ([]*Video, int64, error)
+}
+
+// VideoScope is what a non-admin user sees of the tenant's videos: those
+// shared with the tenant, their own and those shared with their workspaces
+type VideoScope struct {
+	UserID       string
+	WorkspaceIDs []string
 }
 
 // VideoService handles business logic for videos
@@ -90,7 +362,7 @@ func NewVideoService(repo VideoRepository) *VideoService {
 }
 
 // CreateVideo creates a new video
-func (s *VideoService) CreateVideo(tenantID, userID string, req *CreateVideoRequest) (*Video, error) {
+func (s *VideoService) CreateVideo(ctx context.Context, tenantID, userID string, req *CreateVideoRequest) (*Video, error) {
 	video := &Video{
 		TenantID:    tenantID,
 		UserID:      userID,
@@ -105,11 +377,16 @@ func (s *VideoService) CreateVideo(tenantID, userID string, req *CreateVideoRequ
 	}
 
 	if len(req.Tags) > 0 {
-		// Convert tags to JSON string (simplified)
-		video.Tags = convertTagsToJSON(req.Tags)
+		video.Tags = req.Tags
+	}
+	if req.Rights != nil {
+		if err := req.Rights.Validate(); err != nil {
+			return nil, err
+		}
+		video.Rights = *req.Rights
 	}
 
-	if err := s.repo.Create(video); err != nil {
+	if err := s.repo.Create(ctx, video); err != nil {
 		return nil, err
 	}
 
@@ -117,18 +394,18 @@ func (s *VideoService) CreateVideo(tenantID, userID string, req *CreateVideoRequ
 }
 
 // GetVideo retrieves a video by ID
-func (s *VideoService) GetVideo(tenantID, id string) (*Video, error) {
-	return s.repo.GetByID(tenantID, id)
+func (s *VideoService) GetVideo(ctx context.Context, tenantID, id string) (*Video, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
 }
 
 // GetUserVideos retrieves videos for a specific user
-func (s *VideoService) GetUserVideos(tenantID, userID string, limit, offset int) ([]*Video, error) {
-	return s.repo.GetByUserID(tenantID, userID, limit, offset)
+func (s *VideoService) GetUserVideos(ctx context.Context, tenantID, userID string, limit, offset int) ([]*Video, error) {
+	return s.repo.GetByUserID(ctx, tenantID, userID, limit, offset)
 }
 
 // UpdateVideo updates an existing video
-func (s *VideoService) UpdateVideo(tenantID, id string, req *UpdateVideoRequest) (*Video, error) {
-	video, err := s.repo.GetByID(tenantID, id)
+func (s *VideoService) UpdateVideo(ctx context.Context, tenantID, id string, req *UpdateVideoRequest) (*Video, error) {
+	video, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -140,12 +417,18 @@ func (s *VideoService) UpdateVideo(tenantID, id string, req *UpdateVideoRequest)
 		video.Description = *req.Description
 	}
 	if len(req.Tags) > 0 {
-		video.Tags = convertTagsToJSON(req.Tags)
+		video.Tags = req.Tags
+	}
+	if req.Rights != nil {
+		if err := req.Rights.Validate(); err != nil {
+			return nil, err
+		}
+		video.Rights = *req.Rights
 	}
 
 	video.UpdatedAt = time.Now()
 
-	if err := s.repo.Update(video); err != nil {
+	if err := s.repo.Update(ctx, video); err != nil {
 		return nil, err
 	}
 
@@ -153,28 +436,28 @@ func (s *VideoService) UpdateVideo(tenantID, id string, req *UpdateVideoRequest)
 }
 
 // DeleteVideo soft deletes a video
-func (s *VideoService) DeleteVideo(tenantID, id string) error {
-	return s.repo.Delete(tenantID, id)
+func (s *VideoService) DeleteVideo(ctx context.Context, tenantID, id string) error {
+	return s.repo.Delete(ctx, tenantID, id)
 }
 
 // ListVideos retrieves a list of videos with pagination
-func (s *VideoService) ListVideos(tenantID string, limit, offset int) ([]*Video, error) {
-	return s.repo.List(tenantID, limit, offset)
+func (s *VideoService) ListVideos(ctx context.Context, tenantID string, limit, offset int) ([]*Video, error) {
+	return s.repo.List(ctx, tenantID, limit, offset)
 }
 
 // UpdateVideoStatus updates the processing status of a video
-func (s *VideoService) UpdateVideoStatus(tenantID, id string, status VideoStatus) error {
-	return s.repo.UpdateStatus(tenantID, id, status)
+func (s *VideoService) UpdateVideoStatus(ctx context.Context, tenantID, id string, status VideoStatus) error {
+	return s.repo.UpdateStatus(ctx, tenantID, id, status)
 }
 
 // GetVideosByStatus retrieves videos by status
-func (s *VideoService) GetVideosByStatus(tenantID string, status VideoStatus, limit, offset int) ([]*Video, error) {
-	return s.repo.GetByStatus(tenantID, status, limit, offset)
+func (s *VideoService) GetVideosByStatus(ctx context.Context, tenantID string, status VideoStatus, limit, offset int) ([]*Video, error) {
+	return s.repo.GetByStatus(ctx, tenantID, status, limit, offset)
 }
 
 // SetProcessingComplete marks a video as ready and updates metadata
-func (s *VideoService) SetProcessingComplete(tenantID, id string, duration int, resolution, thumbnailURL, s3Key, s3Bucket string) error {
-	video, err := s.repo.GetByID(tenantID, id)
+func (s *VideoService) SetProcessingComplete(ctx context.Context, tenantID, id string, duration int, resolution, thumbnailURL, s3Key, s3Bucket string) error {
+	video, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return err
 	}
@@ -187,12 +470,12 @@ func (s *VideoService) SetProcessingComplete(tenantID, id string, duration int,
 	video.Status = string(StatusReady)
 	video.UpdatedAt = time.Now()
 
-	return s.repo.Update(video)
+	return s.repo.Update(ctx, video)
 }
 
 // SetProcessingFailed marks a video as failed
-func (s *VideoService) SetProcessingFailed(tenantID, id string) error {
-	return s.repo.UpdateStatus(tenantID, id, StatusFailed)
+func (s *VideoService) SetProcessingFailed(ctx context.Context, tenantID, id string) error {
+	return s.repo.UpdateStatus(ctx, tenantID, id, StatusFailed)
 }
 
 // IsReady checks if the video is ready for publishing
@@ -205,22 +488,40 @@ func (v *Video) IsProcessing() bool {
 	return v.Status == string(StatusProcessing) || v.Status == string(StatusUploading)
 }
 
-// GetTags converts the JSON tags string back to a slice
-func (v *Video) GetTags() []string {
-	// Simplified implementation - in real app, use proper JSON unmarshaling
-	if v.Tags == "" {
-		return []string{}
+// IsArchived checks if the video file is in cold storage
+func (v *Video) IsArchived() bool {
+	return v.Status == string(StatusArchived)
+}
+
+// IsPublished checks if the video was published to any partner platform
+func (v *Video) IsPublished() bool {
+	return v.YouTubeID != "" || v.TikTokID != "" || v.InstagramID != "" || v.FacebookID != "" ||
+		v.TwitterMediaID != 0 || v.SnapchatMediaID != ""
+}
+
+// PublishedPlatforms lists the partner platforms the video was published to
+func (v *Video) PublishedPlatforms() []Platform {
+	platforms := []Platform{}
+	for platform, id := range map[Platform]bool{
+		PlatformYouTube:   v.YouTubeID != "",
+		PlatformTikTok:    v.TikTokID != "",
+		PlatformInstagram: v.InstagramID != "",
+		PlatformFacebook:  v.FacebookID != "",
+		PlatformTwitter:   v.TwitterMediaID != 0,
+		PlatformSnapchat:  v.SnapchatMediaID != "",
+	} {
+		if id {
+			platforms = append(platforms, platform)
+		}
 	}
-	// This is a placeholder - implement proper JSON parsing
-	return []string{}
+	slices.Sort(platforms)
+	return platforms
 }
 
-// convertTagsToJSON converts a slice of tags to JSON string
-func convertTagsToJSON(tags []string) string {
-	// Simplified implementation - in real app, use proper JSON marshaling
-	if len(tags) == 0 {
-		return ""
+// GetTags returns the video tags, never nil
+func (v *Video) GetTags() []string {
+	if v.Tags == nil {
+		return []string{}
 	}
-	// This is a placeholder - implement proper JSON marshaling
-	return "[\"" + tags[0] + "\"]"
+	return v.Tags
 }
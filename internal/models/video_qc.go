@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Quality control reason codes
+const (
+	QCBlackStart       = "black_start"           // Opens on black frames
+	QCSilentAudio      = "silent_audio"          // Audio track is silent
+	QCLoudness         = "loudness_out_of_range" // Too quiet or too loud
+	QCDurationMismatch = "duration_mismatch"     // Transcoding cut or stretched it
+)
+
+// QCReason is why a video failed quality control. Measured and Limit are in
+// seconds, LUFS or the share of the video, depending on the code.
+type QCReason struct {
+	Code     string  `json:"code"`
+	Message  string  `json:"message"`
+	Measured float64 `json:"measured"`
+	Limit    float64 `json:"limit"`
+}
+
+// VideoQC is the outcome of the last quality control of a video's
+// transcoded file
+type VideoQC struct {
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	Reasons   []QCReason `json:"reasons,omitempty" gorm:"type:json;serializer:json"`
+	// Set when a user released the video despite the reasons
+	OverriddenBy   string     `json:"overridden_by,omitempty" gorm:"type:varchar(36)"`
+	OverriddenAt   *time.Time `json:"overridden_at,omitempty"`
+	OverrideReason string     `json:"override_reason,omitempty" gorm:"type:varchar(500)"`
+}
+
+// QCOverrideRequest represents a request to release a video that failed
+// quality control
+type QCOverrideRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
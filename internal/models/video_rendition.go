@@ -0,0 +1,85 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RenditionStatus defines the transcoding statuses of a rendition
+type RenditionStatus string
+
+const (
+	RenditionPending RenditionStatus = "pending"
+	RenditionReady   RenditionStatus = "ready"
+	RenditionFailed  RenditionStatus = "failed"
+)
+
+// VideoRendition is a video transcoded with one of the profiles of
+// transcode.Profiles, uploaded to the platforms whose capabilities name it
+type VideoRendition struct {
+	ID               string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID         string `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	VideoID          string `json:"video_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_renditions_video_profile"`
+	Profile          string `json:"profile" gorm:"type:varchar(50);not null;uniqueIndex:idx_renditions_video_profile"`
+	Status           string `json:"status" gorm:"type:varchar(20);not null"`
+	Width            int    `json:"width"`
+	Height           int    `json:"height"`
+	VideoBitrateKbps int    `json:"video_bitrate_kbps"`
+	FilePath         string `json:"file_path,omitempty" gorm:"type:varchar(500)"`
+	FileURL          string `json:"file_url,omitempty" gorm:"type:varchar(500)"`
+	S3Key            string `json:"s3_key,omitempty" gorm:"type:varchar(500)"`
+	FileSize         int64  `json:"file_size"`
+	ErrorMsg         string `json:"error_msg,omitempty" gorm:"type:text"`
+	// AudioLanguages are the languages of the dubbed audio tracks muxed in
+	// after the original one
+	AudioLanguages []string `json:"audio_languages,omitempty" gorm:"type:json;serializer:json"`
+	// IntroAssetID and OutroAssetID are the clips of the tenant's asset
+	// library stitched before and after the video, as planned
+	IntroAssetID string `json:"intro_asset_id,omitempty" gorm:"type:varchar(36)"`
+	OutroAssetID string `json:"outro_asset_id,omitempty" gorm:"type:varchar(36)"`
+	// TranscodedAt is when the rendition was last ready
+	TranscodedAt *time.Time `json:"transcoded_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// RenditionOutput is where the transcoding worker stored a rendition
+type RenditionOutput struct {
+	FilePath string `json:"file_path,omitempty"`
+	FileURL  string `json:"file_url,omitempty"`
+	S3Key    string `json:"s3_key,omitempty"`
+	FileSize int64  `json:"file_size"`
+	// AudioLanguages are the dubs muxed into the rendition, in track order
+	AudioLanguages []string `json:"audio_languages,omitempty"`
+}
+
+// VideoRenditionRepository defines the interface for rendition operations
+type VideoRenditionRepository interface {
+	// Get returns the video's rendition of the profile, or ErrNotFound when
+	// the video was not transcoded with it
+	Get(ctx context.Context, tenantID, videoID, profile string) (*VideoRendition, error)
+	ListByVideo(ctx context.Context, tenantID, videoID string) ([]*VideoRendition, error)
+	// Upsert saves the video's only rendition of the profile
+	Upsert(ctx context.Context, rendition *VideoRendition) error
+}
+
+// Ready reports whether the rendition can be uploaded
+func (r *VideoRendition) Ready() bool {
+	return r.Status == string(RenditionReady)
+}
+
+// WithRendition returns a copy of the video whose file is the rendition's,
+// or the video itself without a rendition
+func (v *Video) WithRendition(r *VideoRendition) *Video {
+	if r == nil {
+		return v
+	}
+	rendered := *v
+	rendered.FilePath = r.FilePath
+	rendered.FileURL = r.FileURL
+	rendered.S3Key = r.S3Key
+	rendered.FileSize = r.FileSize
+	rendered.Resolution = fmt.Sprintf("%dx%d", r.Width, r.Height)
+	return &rendered
+}
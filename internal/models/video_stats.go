@@ -1,49 +1,124 @@
 package models
 
 import (
-	"gorm.io/gorm"
+	"context"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // VideoStats represents analytics data for a video
 type VideoStats struct {
-	ID             string         `json:"id" gorm:"primaryKey;type:varchar(36)"`
-	TenantID       string         `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_tenant_video"`
-	VideoID        string         `json:"video_id" gorm:"type:varchar(36);not null;index:idx_tenant_video;index:idx_video_platform"`
-	Platform       string         `json:"platform" gorm:"type:varchar(50);not null;index:idx_video_platform"`
-	ExternalID     string         `json:"external_id" gorm:"type:varchar(255)"` // Platform's video ID
-	Views          int64          `json:"views" gorm:"default:0"`
-	Likes          int64          `json:"likes" gorm:"default:0"`
-	Dislikes       int64          `json:"dislikes" gorm:"default:0"`
-	Comments       int64          `json:"comments" gorm:"default:0"`
-	Shares         int64          `json:"shares" gorm:"default:0"`
-	Subscribers    int64          `json:"subscribers" gorm:"default:0"` // Gained from this video
-	WatchTime      int64          `json:"watch_time" gorm:"default:0"`  // Total watch time in seconds
-	AvgWatchTime   float64        `json:"avg_watch_time" gorm:"type:decimal(10,2);default:0"`
-	ClickThrough   float64        `json:"click_through_rate" gorm:"type:decimal(5,4);default:0"` // CTR percentage
-	Engagement     float64        `json:"engagement_rate" gorm:"type:decimal(5,4);default:0"`    // Engagement rate percentage
-	Revenue        float64        `json:"revenue" gorm:"type:decimal(10,2);default:0"`           // Revenue generated
-	Impressions    int64          `json:"impressions" gorm:"default:0"`
-	Demographics   string         `json:"demographics" gorm:"type:json"`    // JSON string with demographic data
-	TrafficSources string         `json:"traffic_sources" gorm:"type:json"` // JSON string with traffic source data
-	DeviceTypes    string         `json:"device_types" gorm:"type:json"`    // JSON string with device type data
-	Locations      string         `json:"locations" gorm:"type:json"`       // JSON string with geographic data
-	LastSyncAt     time.Time      `json:"last_sync_at" gorm:"type:timestamp;not null"`
-	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt      gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ID             string           `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID       string           `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_tenant_video;index:idx_stats_totals,priority:1;uniqueIndex:idx_stats_video_platform,priority:1"`
+	VideoID        string           `json:"video_id" gorm:"type:varchar(36);not null;index:idx_tenant_video;index:idx_stats_totals,priority:2;uniqueIndex:idx_stats_video_platform,priority:2"`
+	Platform       string           `json:"platform" gorm:"type:varchar(50);not null;index:idx_stats_totals,priority:9;uniqueIndex:idx_stats_video_platform,priority:3"`
+	ExternalID     string           `json:"external_id" gorm:"type:varchar(255)"` // Platform's video ID
+	Views          int64            `json:"views" gorm:"default:0;index:idx_stats_totals,priority:4"`
+	Likes          int64            `json:"likes" gorm:"default:0;index:idx_stats_totals,priority:5"`
+	Dislikes       int64            `json:"dislikes" gorm:"default:0"`
+	Comments       int64            `json:"comments" gorm:"default:0;index:idx_stats_totals,priority:6"`
+	Shares         int64            `json:"shares" gorm:"default:0;index:idx_stats_totals,priority:7"`
+	Subscribers    int64            `json:"subscribers" gorm:"default:0"` // Gained from this video
+	WatchTime      int64            `json:"watch_time" gorm:"default:0"`  // Total watch time in seconds
+	AvgWatchTime   float64          `json:"avg_watch_time" gorm:"type:decimal(10,2);default:0"`
+	ClickThrough   float64          `json:"click_through_rate" gorm:"type:decimal(5,4);default:0"`                         // CTR percentage
+	Engagement     float64          `json:"engagement_rate" gorm:"type:decimal(5,4);default:0"`                            // Engagement rate percentage
+	Revenue        float64          `json:"revenue" gorm:"type:decimal(10,2);default:0;index:idx_stats_totals,priority:8"` // Revenue generated
+	Impressions    int64            `json:"impressions" gorm:"default:0"`
+	Demographics   *Demographics    `json:"demographics,omitempty" gorm:"type:json;serializer:json"`    // Audience, normalized across platforms
+	TrafficSources TrafficSources   `json:"traffic_sources,omitempty" gorm:"type:json;serializer:json"` // Shares of views, normalized across platforms
+	DeviceTypes    map[string]int64 `json:"device_types,omitempty" gorm:"-"`                            // Views by DeviceTypes, stored in video_stats_devices
+	Locations      map[string]int64 `json:"locations,omitempty" gorm:"-"`                               // Views by country, stored in video_stats_locations
+	LastSyncAt     time.Time        `json:"last_sync_at" gorm:"type:timestamp;not null"`
+	CreatedAt      time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt   `json:"deleted_at,omitempty" gorm:"index;index:idx_stats_totals,priority:3"`
+
+	// Score and ScoredAt are read from the row's materialized score by
+	// listings, not stored; nil until its score is first computed
+	Score    *float64   `json:"score,omitempty" gorm:"->;-:migration"`
+	ScoredAt *time.Time `json:"scored_at,omitempty" gorm:"->;-:migration"`
 }
 
 // VideoStatsSnapshot represents a historical snapshot of video stats
 type VideoStatsSnapshot struct {
 	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
-	StatsID   string    `json:"stats_id" gorm:"type:varchar(36);not null;index"`
+	StatsID   string    `json:"stats_id" gorm:"type:varchar(36);not null;index:idx_stats_created,priority:1"`
 	Views     int64     `json:"views" gorm:"default:0"`
 	Likes     int64     `json:"likes" gorm:"default:0"`
 	Comments  int64     `json:"comments" gorm:"default:0"`
 	Shares    int64     `json:"shares" gorm:"default:0"`
 	Revenue   float64   `json:"revenue" gorm:"type:decimal(10,2);default:0"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index:idx_stats_created,priority:2"`
+
+	// Platform is read from the stats row by history queries, not stored
+	Platform string `json:"platform,omitempty" gorm:"->;-:migration"`
+}
+
+// VideoStatsSummary is a video's stats summed across platforms, maintained
+// as stats are written so rankings are read from an index instead of being
+// aggregated per request. There is one summary per video, keyed by its ID.
+type VideoStatsSummary struct {
+	ID            string    `json:"video_id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID      string    `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_summary_views,priority:1;index:idx_summary_likes,priority:1;index:idx_summary_comments,priority:1;index:idx_summary_shares,priority:1;index:idx_summary_revenue,priority:1;index:idx_summary_engagement,priority:1"`
+	Views         int64     `json:"views" gorm:"default:0;index:idx_summary_views,priority:2"`
+	Likes         int64     `json:"likes" gorm:"default:0;index:idx_summary_likes,priority:2"`
+	Comments      int64     `json:"comments" gorm:"default:0;index:idx_summary_comments,priority:2"`
+	Shares        int64     `json:"shares" gorm:"default:0;index:idx_summary_shares,priority:2"`
+	Revenue       float64   `json:"revenue" gorm:"type:decimal(12,2);default:0;index:idx_summary_revenue,priority:2"`
+	Engagement    float64   `json:"engagement_rate" gorm:"type:decimal(10,4);default:0;index:idx_summary_engagement,priority:2"`
+	PlatformCount int       `json:"platform_count" gorm:"default:0"`
+	RefreshedAt   time.Time `json:"refreshed_at" gorm:"type:timestamp;not null"`
+}
+
+// VideoStatsScore is the materialized performance of a video on a platform,
+// keyed by the ID of its stats row. Scores are recomputed for a whole tenant
+// at once, daily and on demand, rather than on every stats write.
+type VideoStatsScore struct {
+	ID             string  `json:"stats_id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID       string  `json:"tenant_id" gorm:"type:varchar(36);not null;index:idx_scores_rank,priority:1"`
+	VideoID        string  `json:"video_id" gorm:"type:varchar(36);not null"`
+	Platform       string  `json:"platform" gorm:"type:varchar(50);not null;index:idx_scores_rank,priority:2"`
+	EngagementRate float64 `json:"engagement_rate" gorm:"type:decimal(10,4);default:0"`
+	// PerformanceScore is GetPerformanceScore, whose scale depends on the
+	// platform's audience
+	PerformanceScore float64 `json:"performance_score" gorm:"default:0"`
+	// Score is the percentile of PerformanceScore among the tenant's stats
+	// rows of the platform, from 0 to 100, so videos compare across platforms
+	Score      float64   `json:"score" gorm:"type:decimal(5,2);default:0;index:idx_scores_rank,priority:3"`
+	ComputedAt time.Time `json:"computed_at" gorm:"type:timestamp;not null"`
+}
+
+// StatsSortColumns are the columns stats listings can be ordered by
+var StatsSortColumns = map[string]string{
+	"views":           "video_stats.views",
+	"likes":           "video_stats.likes",
+	"comments":        "video_stats.comments",
+	"shares":          "video_stats.shares",
+	"revenue":         "video_stats.revenue",
+	"last_sync_at":    "video_stats.last_sync_at",
+	"engagement_rate": "video_stats_scores.engagement_rate",
+	"score":           "video_stats_scores.score",
+}
+
+// StatsListFilter narrows and orders a listing of the tenant's stats rows
+type StatsListFilter struct {
+	Platform string
+	MinScore *float64 // Rows without a score are left out when a bound is set
+	MaxScore *float64
+	Sort     string // A StatsSortColumns key
+	Desc     bool
+}
+
+// LeaderboardMetrics are the summary columns videos can be ranked by
+var LeaderboardMetrics = map[string]bool{
+	"views":           true,
+	"likes":           true,
+	"comments":        true,
+	"shares":          true,
+	"engagement_rate": true,
+	"revenue":         true,
 }
 
 // StatsAggregation represents aggregated stats across platforms
@@ -59,6 +134,17 @@ type StatsAggregation struct {
 	TopPlatform   string  `json:"top_platform"`
 }
 
+// PlatformTotals sums a tenant's stats on one platform
+type PlatformTotals struct {
+	Platform      string  `json:"platform"`
+	TotalVideos   int64   `json:"total_videos"`
+	TotalViews    int64   `json:"total_views"`
+	TotalLikes    int64   `json:"total_likes"`
+	TotalComments int64   `json:"total_comments"`
+	TotalShares   int64   `json:"total_shares"`
+	TotalRevenue  float64 `json:"total_revenue"`
+}
+
 // ROIMetrics represents return on investment calculations
 type ROIMetrics struct {
 	VideoID           string  `json:"video_id"`
@@ -90,21 +176,111 @@ type EngagementMetrics struct {
 	ViralityScore        float64 `json:"virality_score"`
 }
 
+// DefaultStatsBatchSize is the number of rows per statement used by
+// UpsertBatch when no batch size is given; MaxStatsBatchSize keeps a
+// statement under MySQL's 65,535 placeholder limit
+const (
+	DefaultStatsBatchSize = 500
+	MaxStatsBatchSize     = 2000
+)
+
+// TenantStatsSync tells when a tenant's platform stats were synced.
+// OldestSyncAt is the stats row left behind the longest.
+type TenantStatsSync struct {
+	TenantID     string    `json:"tenant_id"`
+	Stats        int64     `json:"stats"`
+	LastSyncAt   time.Time `json:"last_sync_at"`
+	OldestSyncAt time.Time `json:"oldest_sync_at"`
+}
+
+// PlatformStatsSync tells when a tenant's stats of one platform were last synced
+type PlatformStatsSync struct {
+	TenantID   string    `json:"tenant_id"`
+	Platform   string    `json:"platform"`
+	LastSyncAt time.Time `json:"last_sync_at"`
+}
+
 // VideoStatsRepository defines the interface for video stats operations
 type VideoStatsRepository interface {
-	Create(stats *VideoStats) error
-	GetByID(tenantID, id string) (*VideoStats, error)
-	GetByVideoID(tenantID, videoID string) ([]*VideoStats, error)
-	GetByVideoAndPlatform(tenantID, videoID, platform string) (*VideoStats, error)
-	Update(stats *VideoStats) error
-	Delete(tenantID, id string) error
-	List(tenantID string, limit, offset int) ([]*VideoStats, error)
-	GetByPlatform(tenantID, platform string, limit, offset int) ([]*VideoStats, error)
-	GetTopPerforming(tenantID string, metric string, limit int) ([]*VideoStats, error)
-	CreateSnapshot(snapshot *VideoStatsSnapshot) error
-	GetSnapshots(statsID string, limit int) ([]*VideoStatsSnapshot, error)
-	GetAggregatedStats(tenantID, videoID string) (*StatsAggregation, error)
-	GetStatsNeedingSync(olderThan time.Time, limit int) ([]*VideoStats, error)
+	Create(ctx context.Context, stats *VideoStats) error
+	GetByID(ctx context.Context, tenantID, id string) (*VideoStats, error)
+	GetByVideoID(ctx context.Context, tenantID, videoID string) ([]*VideoStats, error)
+	GetByVideoAndPlatform(ctx context.Context, tenantID, videoID, platform string) (*VideoStats, error)
+	Update(ctx context.Context, stats *VideoStats) error
+	// UpsertBatch inserts or updates stats keyed by video and platform,
+	// batchSize rows per statement
+	UpsertBatch(ctx context.Context, tenantID string, stats []*VideoStats, batchSize int) error
+	Delete(ctx context.Context, tenantID, id string) error
+	List(ctx context.Context, tenantID string, limit, offset int) ([]*VideoStats, error)
+	GetByPlatform(ctx context.Context, tenantID, platform string, limit, offset int) ([]*VideoStats, error)
+	CountByPlatform(ctx context.Context, tenantID, platform string) (int64, error)
+	// ListByPlatformAfter pages through the tenant's stats of a platform in
+	// ID order, starting after afterID
+	ListByPlatformAfter(ctx context.Context, tenantID, platform, afterID string, limit int) ([]*VideoStats, error)
+	// GetTopPerforming ranks the tenant's videos by a LeaderboardMetrics
+	// column of their summaries
+	GetTopPerforming(ctx context.Context, tenantID string, metric string, limit int) ([]*VideoStatsSummary, error)
+	// RefreshSummaries recomputes the summaries of videoIDs, or of every
+	// video of the tenant when videoIDs is empty
+	RefreshSummaries(ctx context.Context, tenantID string, videoIDs []string) error
+	CreateSnapshot(ctx context.Context, snapshot *VideoStatsSnapshot) error
+	GetSnapshots(ctx context.Context, statsID string, limit int) ([]*VideoStatsSnapshot, error)
+	// GetFirstSnapshotAt returns when the oldest snapshot of the stats was
+	// taken, nil when there is none
+	GetFirstSnapshotAt(ctx context.Context, statsID string) (*time.Time, error)
+	// ReplaceSnapshots atomically swaps the snapshots of the stats taken in
+	// [from, to) for the given ones, so writing a range twice keeps one copy
+	ReplaceSnapshots(ctx context.Context, statsID string, from, to time.Time, snapshots []*VideoStatsSnapshot) error
+	// GetHistory returns the snapshots of a video's stats taken in [from, to),
+	// oldest first. Snapshots are partitioned by month on created_at, so the
+	// range restricts the read to the partitions it covers.
+	GetHistory(ctx context.Context, tenantID, videoID string, from, to time.Time) ([]*VideoStatsSnapshot, error)
+	// ListScored returns the tenant's stats rows matching the filter, with
+	// their scores, and how many match in all. A scope keeps the rows of the
+	// videos it sees; nil keeps them all.
+	ListScored(ctx context.Context, tenantID string, scope *VideoScope, filter StatsListFilter, limit, offset int) ([]*VideoStats, int64, error)
+	// ReplaceScores atomically swaps the tenant's scores for the given ones
+	ReplaceScores(ctx context.Context, tenantID string, scores []*VideoStatsScore) error
+	// GetScoredAt returns when the tenant's scores were last computed, nil
+	// when they never were
+	GetScoredAt(ctx context.Context, tenantID string) (*time.Time, error)
+	// GetOldestSnapshotAt returns when the tenant's oldest snapshot was
+	// taken, nil when there is none
+	GetOldestSnapshotAt(ctx context.Context, tenantID string) (*time.Time, error)
+	// CompactSnapshots keeps only the last snapshot of each stats row and day
+	// in [from, to) and returns how many it deleted. Running it again on the
+	// same range deletes nothing.
+	CompactSnapshots(ctx context.Context, tenantID string, from, to time.Time) (int64, error)
+	// DeleteSnapshotsBefore deletes the tenant's snapshots taken before the
+	// given time
+	DeleteSnapshotsBefore(ctx context.Context, tenantID string, before time.Time) (int64, error)
+	GetAggregatedStats(ctx context.Context, tenantID, videoID string) (*StatsAggregation, error)
+	GetAggregatedStatsForVideos(ctx context.Context, tenantID string, videoIDs []string) ([]*StatsAggregation, error)
+	// GetPlatformTotals sums the tenant's stats per platform
+	GetPlatformTotals(ctx context.Context, tenantID string) ([]*PlatformTotals, error)
+	// ListTrafficSources returns the video, platform, views and traffic
+	// sources of the tenant's stats rows reporting traffic sources, for the
+	// videos uploaded in [from, to)
+	ListTrafficSources(ctx context.Context, tenantID string, from, to time.Time) ([]*VideoStats, error)
+	// SumViewsByDevice sums the device views of the tenant's stats matching
+	// the filter, most viewed first
+	SumViewsByDevice(ctx context.Context, tenantID string, filter BreakdownFilter) ([]*BreakdownViews, error)
+	// SumViewsByCountry sums the country views of the tenant's stats
+	// matching the filter, most viewed first
+	SumViewsByCountry(ctx context.Context, tenantID string, filter BreakdownFilter) ([]*BreakdownViews, error)
+	GetStatsNeedingSync(ctx context.Context, olderThan time.Time, limit int) ([]*VideoStats, error)
+	// GetSyncStatusByTenant returns when each tenant's stats were last
+	// synced, least recently synced tenants first
+	GetSyncStatusByTenant(ctx context.Context, limit int) ([]*TenantStatsSync, error)
+	// GetLastSyncByPlatform returns the latest sync of every tenant and platform
+	GetLastSyncByPlatform(ctx context.Context) ([]*PlatformStatsSync, error)
+	// Stream walks the tenant's stats over a cursor, optionally filtered by
+	// platform and to the videos of a scope, calling fn once per row; an
+	// error from fn stops the walk
+	Stream(ctx context.Context, tenantID string, scope *VideoScope, platform string, fn func(*VideoStats) error) error
+	// Query aggregates the tenant's stats of videos not deleted as the
+	// validated query asks
+	Query(ctx context.Context, tenantID string, query *AnalyticsQuery) (*AnalyticsResult, error)
 }
 
 // VideoStatsService handles business logic for video statistics
@@ -118,7 +294,7 @@ func NewVideoStatsService(repo VideoStatsRepository) *VideoStatsService {
 }
 
 // CreateVideoStats creates new video statistics
-func (s *VideoStatsService) CreateVideoStats(tenantID, videoID, platform, externalID string) (*VideoStats, error) {
+func (s *VideoStatsService) CreateVideoStats(ctx context.Context, tenantID, videoID, platform, externalID string) (*VideoStats, error) {
 	stats := &VideoStats{
 		TenantID:   tenantID,
 		VideoID:    videoID,
@@ -129,7 +305,7 @@ func (s *VideoStatsService) CreateVideoStats(tenantID, videoID, platform, extern
 		UpdatedAt:  time.Now(),
 	}
 
-	if err := s.repo.Create(stats); err != nil {
+	if err := s.repo.Create(ctx, stats); err != nil {
 		return nil, err
 	}
 
@@ -137,23 +313,23 @@ func (s *VideoStatsService) CreateVideoStats(tenantID, videoID, platform, extern
 }
 
 // GetVideoStats retrieves video stats by ID
-func (s *VideoStatsService) GetVideoStats(tenantID, id string) (*VideoStats, error) {
-	return s.repo.GetByID(tenantID, id)
+func (s *VideoStatsService) GetVideoStats(ctx context.Context, tenantID, id string) (*VideoStats, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
 }
 
 // GetVideoStatsByVideo retrieves all stats for a video
-func (s *VideoStatsService) GetVideoStatsByVideo(tenantID, videoID string) ([]*VideoStats, error) {
-	return s.repo.GetByVideoID(tenantID, videoID)
+func (s *VideoStatsService) GetVideoStatsByVideo(ctx context.Context, tenantID, videoID string) ([]*VideoStats, error) {
+	return s.repo.GetByVideoID(ctx, tenantID, videoID)
 }
 
 // GetVideoStatsByPlatform retrieves stats for a video on a specific platform
-func (s *VideoStatsService) GetVideoStatsByPlatform(tenantID, videoID, platform string) (*VideoStats, error) {
-	return s.repo.GetByVideoAndPlatform(tenantID, videoID, platform)
+func (s *VideoStatsService) GetVideoStatsByPlatform(ctx context.Context, tenantID, videoID, platform string) (*VideoStats, error) {
+	return s.repo.GetByVideoAndPlatform(ctx, tenantID, videoID, platform)
 }
 
 // UpdateVideoStats updates existing video statistics
-func (s *VideoStatsService) UpdateVideoStats(tenantID, id string, updates map[string]interface{}) (*VideoStats, error) {
-	stats, err := s.repo.GetByID(tenantID, id)
+func (s *VideoStatsService) UpdateVideoStats(ctx context.Context, tenantID, id string, updates map[string]interface{}) (*VideoStats, error) {
+	stats, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -168,7 +344,7 @@ func (s *VideoStatsService) UpdateVideoStats(tenantID, id string, updates map[st
 		Revenue:   stats.Revenue,
 		CreatedAt: time.Now(),
 	}
-	s.repo.CreateSnapshot(snapshot)
+	s.repo.CreateSnapshot(ctx, snapshot)
 
 	// Update stats
 	if views, ok := updates["views"].(int64); ok {
@@ -211,7 +387,7 @@ func (s *VideoStatsService) UpdateVideoStats(tenantID, id string, updates map[st
 	stats.LastSyncAt = time.Now()
 	stats.UpdatedAt = time.Now()
 
-	if err := s.repo.Update(stats); err != nil {
+	if err := s.repo.Update(ctx, stats); err != nil {
 		return nil, err
 	}
 
@@ -219,63 +395,54 @@ func (s *VideoStatsService) UpdateVideoStats(tenantID, id string, updates map[st
 }
 
 // DeleteVideoStats soft deletes video statistics
-func (s *VideoStatsService) DeleteVideoStats(tenantID, id string) error {
-	return s.repo.Delete(tenantID, id)
+func (s *VideoStatsService) DeleteVideoStats(ctx context.Context, tenantID, id string) error {
+	return s.repo.Delete(ctx, tenantID, id)
 }
 
 // ListVideoStats retrieves a list of video stats with pagination
-func (s *VideoStatsService) ListVideoStats(tenantID string, limit, offset int) ([]*VideoStats, error) {
-	return s.repo.List(tenantID, limit, offset)
+func (s *VideoStatsService) ListVideoStats(ctx context.Context, tenantID string, limit, offset int) ([]*VideoStats, error) {
+	return s.repo.List(ctx, tenantID, limit, offset)
 }
 
 // GetStatsByPlatform retrieves stats for a specific platform
-func (s *VideoStatsService) GetStatsByPlatform(tenantID, platform string, limit, offset int) ([]*VideoStats, error) {
-	return s.repo.GetByPlatform(tenantID, platform, limit, offset)
+func (s *VideoStatsService) GetStatsByPlatform(ctx context.Context, tenantID, platform string, limit, offset int) ([]*VideoStats, error) {
+	return s.repo.GetByPlatform(ctx, tenantID, platform, limit, offset)
 }
 
 // GetTopPerformingVideos retrieves top performing videos by a specific metric
-func (s *VideoStatsService) GetTopPerformingVideos(tenantID, metric string, limit int) ([]*VideoStats, error) {
-	validMetrics := map[string]bool{
-		"views":           true,
-		"likes":           true,
-		"comments":        true,
-		"shares":          true,
-		"engagement_rate": true,
-		"revenue":         true,
-	}
-
-	if !validMetrics[metric] {
+func (s *VideoStatsService) GetTopPerformingVideos(ctx context.Context, tenantID, metric string, limit int) ([]*VideoStatsSummary, error) {
+	if !LeaderboardMetrics[metric] {
 		return nil, ErrInvalidInput
 	}
 
-	return s.repo.GetTopPerforming(tenantID, metric, limit)
+	return s.repo.GetTopPerforming(ctx, tenantID, metric, limit)
 }
 
 // GetAggregatedStats retrieves aggregated statistics for a video across all platforms
-func (s *VideoStatsService) GetAggregatedStats(tenantID, videoID string) (*StatsAggregation, error) {
-	return s.repo.GetAggregatedStats(tenantID, videoID)
+func (s *VideoStatsService) GetAggregatedStats(ctx context.Context, tenantID, videoID string) (*StatsAggregation, error) {
+	return s.repo.GetAggregatedStats(ctx, tenantID, videoID)
 }
 
 // GetStatsHistory retrieves historical snapshots for video stats
-func (s *VideoStatsService) GetStatsHistory(tenantID, statsID string, limit int) ([]*VideoStatsSnapshot, error) {
+func (s *VideoStatsService) GetStatsHistory(ctx context.Context, tenantID, statsID string, limit int) ([]*VideoStatsSnapshot, error) {
 	// Verify the stats belong to the tenant
-	_, err := s.repo.GetByID(tenantID, statsID)
+	_, err := s.repo.GetByID(ctx, tenantID, statsID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.repo.GetSnapshots(statsID, limit)
+	return s.repo.GetSnapshots(ctx, statsID, limit)
 }
 
 // SyncStatsFromPlatform updates stats with data from external platform
-func (s *VideoStatsService) SyncStatsFromPlatform(tenantID, statsID string, platformData map[string]interface{}) error {
-	_, err := s.UpdateVideoStats(tenantID, statsID, platformData)
+func (s *VideoStatsService) SyncStatsFromPlatform(ctx context.Context, tenantID, statsID string, platformData map[string]interface{}) error {
+	_, err := s.UpdateVideoStats(ctx, tenantID, statsID, platformData)
 	return err
 }
 
 // GetStatsNeedingSync retrieves stats that need to be synced with external platforms
-func (s *VideoStatsService) GetStatsNeedingSync(olderThan time.Time, limit int) ([]*VideoStats, error) {
-	return s.repo.GetStatsNeedingSync(olderThan, limit)
+func (s *VideoStatsService) GetStatsNeedingSync(ctx context.Context, olderThan time.Time, limit int) ([]*VideoStats, error) {
+	return s.repo.GetStatsNeedingSync(ctx, olderThan, limit)
 }
 
 // CalculateEngagementRate calculates engagement rate for video stats
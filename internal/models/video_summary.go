@@ -0,0 +1,52 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// VideoSummary holds the AI summaries of a video in a language, generated
+// from its transcript in it. TranscriptHash identifies the transcript text
+// they were generated from, so a summary is only generated again once the
+// transcript changed.
+type VideoSummary struct {
+	ID             string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID       string `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	VideoID        string `json:"video_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_video_summaries_video_language"`
+	Language       string `json:"language" gorm:"type:varchar(10);not null;uniqueIndex:idx_video_summaries_video_language"`
+	TranscriptHash string `json:"transcript_hash" gorm:"type:varchar(64);not null"`
+	// Short is a sentence, Medium a paragraph fit for a video description
+	// and Long a few paragraphs
+	Short     string    `json:"short" gorm:"type:text"`
+	Medium    string    `json:"medium" gorm:"type:text"`
+	Long      string    `json:"long" gorm:"type:text"`
+	Takeaways []string  `json:"takeaways" gorm:"type:json;serializer:json"`
+	Model     string    `json:"model,omitempty" gorm:"type:varchar(100)"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// SummarizeRequest asks for the summaries of a video
+type SummarizeRequest struct {
+	// Language picks the transcript to summarize, the first one stored when
+	// empty
+	Language string `json:"language,omitempty" binding:"max=10"`
+	// Refresh generates the summaries again even if the transcript did not
+	// change
+	Refresh bool `json:"refresh,omitempty"`
+	// PrefillDescription sets the medium summary as the video's description
+	// when it has none
+	PrefillDescription bool `json:"prefill_description,omitempty"`
+}
+
+// VideoSummaryRepository defines the interface for video summary operations
+type VideoSummaryRepository interface {
+	// Get returns the video's summary in the language, or
+	// ErrVideoSummaryNotFound
+	Get(ctx context.Context, tenantID, videoID, language string) (*VideoSummary, error)
+	// ListByVideos returns the summaries of the videos in every language,
+	// latest first
+	ListByVideos(ctx context.Context, tenantID string, videoIDs []string) ([]*VideoSummary, error)
+	// Upsert saves the video's only summary in the language
+	Upsert(ctx context.Context, summary *VideoSummary) error
+}
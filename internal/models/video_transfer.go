@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// TransferMode defines what happens to the sender's video once a transfer
+// is accepted
+type TransferMode string
+
+const (
+	// TransferCopy leaves the sender's video in place
+	TransferCopy TransferMode = "copy"
+	// TransferMove deletes the sender's video once the recipient has its copy
+	TransferMove TransferMode = "move"
+)
+
+// TransferStatus defines the statuses of a video transfer
+type TransferStatus string
+
+const (
+	TransferPending   TransferStatus = "pending"
+	TransferCompleted TransferStatus = "completed"
+	TransferDeclined  TransferStatus = "declined"
+	TransferCancelled TransferStatus = "cancelled"
+	TransferExpired   TransferStatus = "expired"
+)
+
+// VideoTransferTTL is how long the recipient has to accept a transfer
+const VideoTransferTTL = 14 * 24 * time.Hour
+
+// Audit log actions of video transfers, recorded in both tenants
+const (
+	AuditActionTransferRequest = "transfer.request"
+	AuditActionTransferAccept  = "transfer.accept"
+	AuditActionTransferDecline = "transfer.decline"
+	AuditActionTransferCancel  = "transfer.cancel"
+)
+
+// VideoTransfer hands a video from the tenant that made it, such as an
+// agency, to another tenant, such as its client. The row belongs to the
+// sending tenant; the recipient reads it by TargetTenantID.
+type VideoTransfer struct {
+	ID             string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID       string `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	TargetTenantID string `json:"target_tenant_id" gorm:"type:varchar(36);not null;index:idx_transfers_target_status,priority:1"`
+	VideoID        string `json:"video_id" gorm:"type:varchar(36);not null;index"`
+	Mode           string `json:"mode" gorm:"type:varchar(10);not null"`
+	// IncludeStats copies the platform stats of the video to the recipient's
+	// copy, so its dashboard keeps the performance earned before the handoff
+	IncludeStats bool   `json:"include_stats"`
+	Status       string `json:"status" gorm:"type:varchar(20);not null;index:idx_transfers_target_status,priority:2"`
+	Message      string `json:"message,omitempty" gorm:"type:text"`
+	RequestedBy  string `json:"requested_by" gorm:"type:varchar(36)"`
+	// RespondedBy is the recipient's user who accepted or declined, or the
+	// sender's user who cancelled
+	RespondedBy   string     `json:"responded_by,omitempty" gorm:"type:varchar(36)"`
+	RespondedAt   *time.Time `json:"responded_at,omitempty"`
+	TargetVideoID string     `json:"target_video_id,omitempty" gorm:"type:varchar(36)"` // The recipient's copy
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// CreateTransferRequest represents a request to hand a video to another tenant
+type CreateTransferRequest struct {
+	TargetTenantID string       `json:"target_tenant_id" binding:"required"`
+	Mode           TransferMode `json:"mode,omitempty"` // Defaults to copy
+	IncludeStats   bool         `json:"include_stats,omitempty"`
+	Message        string       `json:"message,omitempty" binding:"max=1000"`
+}
+
+// VideoTransferRepository defines the interface for video transfer operations
+type VideoTransferRepository interface {
+	Create(ctx context.Context, transfer *VideoTransfer) error
+	// Get returns a transfer sent by the tenant, or ErrTransferNotFound
+	Get(ctx context.Context, tenantID, id string) (*VideoTransfer, error)
+	// GetIncoming returns a transfer sent to the tenant, or ErrTransferNotFound
+	GetIncoming(ctx context.Context, targetTenantID, id string) (*VideoTransfer, error)
+	// GetPendingForVideo returns the video's pending transfer, or ErrTransferNotFound
+	GetPendingForVideo(ctx context.Context, tenantID, videoID string) (*VideoTransfer, error)
+	// ListOutgoing and ListIncoming return transfers newest first, of any
+	// status when status is empty
+	ListOutgoing(ctx context.Context, tenantID string, status TransferStatus, limit, offset int) ([]*VideoTransfer, error)
+	ListIncoming(ctx context.Context, targetTenantID string, status TransferStatus, limit, offset int) ([]*VideoTransfer, error)
+	Update(ctx context.Context, transfer *VideoTransfer) error
+}
+
+// Valid reports whether the mode is supported
+func (m TransferMode) Valid() bool {
+	return m == TransferCopy || m == TransferMove
+}
+
+// Pending reports whether the transfer still waits for the recipient at now
+func (t *VideoTransfer) Pending(now time.Time) bool {
+	return t.Status == string(TransferPending) && now.Before(t.ExpiresAt)
+}
+
+// CopyFor returns the recipient's copy of the video, with a new ID and
+// owned by userID. Publications and their platform IDs stay with the
+// sender, as do the campaign and the archive state; S3Bucket and S3Key are
+// left for the caller to set once the file is copied.
+func (v *Video) CopyFor(tenantID, userID, videoID string) *Video {
+	return &Video{
+		ID:           videoID,
+		TenantID:     tenantID,
+		UserID:       userID,
+		Title:        v.Title,
+		Description:  v.Description,
+		FileName:     v.FileName,
+		FilePath:     v.FilePath,
+		FileSize:     v.FileSize,
+		Duration:     v.Duration,
+		Format:       v.Format,
+		Resolution:   v.Resolution,
+		Status:       v.Status,
+		Metadata:     v.Metadata,
+		ThumbnailURL: v.ThumbnailURL,
+		FileURL:      v.FileURL,
+		MediaInfo:    v.MediaInfo,
+		ProbedAt:     v.ProbedAt,
+		HoverPreview: v.HoverPreview,
+		Rights:       v.Rights,
+		Tags:         v.Tags,
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// VideoUploadStatus defines the statuses of a multipart video upload
+type VideoUploadStatus string
+
+const (
+	UploadActive    VideoUploadStatus = "active"
+	UploadCompleted VideoUploadStatus = "completed"
+	UploadAborted   VideoUploadStatus = "aborted"
+)
+
+// VideoUpload is a multipart upload of a video's file to S3. Its parts are
+// kept by S3 rather than here, so an interrupted upload resumes from the
+// parts S3 lists; a video has at most one active upload.
+type VideoUpload struct {
+	ID          string            `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID    string            `json:"tenant_id" gorm:"type:varchar(36);not null;index"`
+	VideoID     string            `json:"video_id" gorm:"type:varchar(36);not null;index:idx_video_uploads_video_status,priority:1"`
+	UserID      string            `json:"user_id" gorm:"type:varchar(36);not null"`
+	Status      VideoUploadStatus `json:"status" gorm:"type:varchar(20);not null;index:idx_video_uploads_video_status,priority:2"`
+	Filename    string            `json:"filename" gorm:"type:varchar(255);not null"`
+	ContentType string            `json:"content_type" gorm:"type:varchar(100)"`
+	Size        int64             `json:"size" gorm:"not null"`
+	PartSize    int64             `json:"part_size" gorm:"not null"`
+	PartCount   int               `json:"part_count" gorm:"not null"`
+	S3Bucket    string            `json:"-" gorm:"type:varchar(255);not null"`
+	S3Key       string            `json:"-" gorm:"type:varchar(500);not null"`
+	S3UploadID  string            `json:"-" gorm:"type:varchar(1024);not null"`
+	CreatedAt   time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// StartUploadRequest represents a request to start a multipart upload
+type StartUploadRequest struct {
+	Filename    string `json:"filename" binding:"required,max=255"`
+	ContentType string `json:"content_type,omitempty" binding:"max=100"`
+	Size        int64  `json:"size" binding:"required,min=1"`
+}
+
+// PresignPartsRequest represents a request for part upload URLs
+type PresignPartsRequest struct {
+	PartNumbers []int `json:"part_numbers" binding:"required,min=1,max=100"`
+}
+
+// VideoUploadRepository defines the interface for video upload operations
+type VideoUploadRepository interface {
+	Create(ctx context.Context, upload *VideoUpload) error
+	// GetActive returns the video's active upload, or ErrVideoUploadNotFound
+	GetActive(ctx context.Context, tenantID, videoID string) (*VideoUpload, error)
+	// SetStatus ends an active upload, or returns ErrVideoUploadNotFound when
+	// it is no longer active
+	SetStatus(ctx context.Context, tenantID, id string, status VideoUploadStatus) error
+}
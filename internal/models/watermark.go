@@ -0,0 +1,133 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// Watermark limits and defaults
+const (
+	MaxWatermarkBytes       = 1 << 20
+	DefaultWatermarkOpacity = 0.8
+)
+
+// DefaultWatermarkPosition is where a newly uploaded watermark is burnt in
+const DefaultWatermarkPosition = transcode.BottomRight
+
+// Watermark is the logo a tenant burns into the videos transcoded for its
+// publications
+type Watermark struct {
+	ID          string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID    string `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_watermarks_tenant"`
+	Image       []byte `json:"-" gorm:"type:mediumblob;not null"`
+	ContentType string `json:"content_type" gorm:"type:varchar(50);not null"`
+	Width       int    `json:"width" gorm:"not null"`
+	Height      int    `json:"height" gorm:"not null"`
+	// Enabled burns the watermark in by default; publications may still
+	// turn it on or off
+	Enabled   bool               `json:"enabled" gorm:"not null"`
+	Position  transcode.Position `json:"position" gorm:"type:varchar(20);not null"`
+	Opacity   float64            `json:"opacity" gorm:"not null"`
+	Platforms []string           `json:"platforms" gorm:"type:json;serializer:json"` // Empty burns it in on every platform
+	CreatedAt time.Time          `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time          `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// UpdateWatermarkRequest represents the request to change how a tenant's
+// watermark is burnt in
+type UpdateWatermarkRequest struct {
+	Enabled   *bool    `json:"enabled,omitempty"`
+	Position  *string  `json:"position,omitempty"`
+	Opacity   *float64 `json:"opacity,omitempty"`
+	Platforms []string `json:"platforms,omitempty"`
+}
+
+// WatermarkRepository defines the interface for watermark operations
+type WatermarkRepository interface {
+	// Get returns the tenant's watermark, or ErrNotFound when none was uploaded
+	Get(ctx context.Context, tenantID string) (*Watermark, error)
+	Upsert(ctx context.Context, watermark *Watermark) error
+	Delete(ctx context.Context, tenantID string) error
+}
+
+// Validate checks the placement and platforms of the watermark
+func (w *Watermark) Validate() error {
+	if err := w.options().Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	for _, platform := range w.Platforms {
+		if !Platform(platform).Valid() {
+			return fmt.Errorf("%w: %s", ErrInvalidPlatform, platform)
+		}
+	}
+	return nil
+}
+
+// For returns how the watermark is burnt into the video transcoded for the
+// platform, or nil when it is not. A publication's override wins over the
+// tenant's settings.
+func (w *Watermark) For(platform Platform, override *WatermarkOverride) *transcode.Watermark {
+	enabled := w.Enabled && (len(w.Platforms) == 0 || slices.Contains(w.Platforms, string(platform)))
+	options := w.options()
+	if override != nil {
+		if override.Enabled != nil {
+			enabled = *override.Enabled
+		}
+		if override.Position != "" {
+			options.Position = override.Position
+		}
+		if override.Opacity != 0 {
+			options.Opacity = override.Opacity
+		}
+	}
+	if !enabled {
+		return nil
+	}
+	return &options
+}
+
+func (w *Watermark) options() transcode.Watermark {
+	return transcode.Watermark{Position: w.Position, Opacity: w.Opacity}
+}
+
+// WatermarkOverride is a publication's change to the tenant's watermark, set
+// under the "watermark" key of its config either as a boolean turning it on
+// or off or as an object
+type WatermarkOverride struct {
+	Enabled  *bool              `json:"enabled,omitempty"`
+	Position transcode.Position `json:"position,omitempty"`
+	Opacity  float64            `json:"opacity,omitempty"`
+}
+
+// WatermarkOverride returns the publication's watermark override, or nil
+// when it keeps the tenant's settings
+func (j *PublicationJob) WatermarkOverride() (*WatermarkOverride, error) {
+	value, ok := j.GetPlatformConfig()["watermark"]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	if enabled, ok := value.(bool); ok {
+		return &WatermarkOverride{Enabled: &enabled}, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: watermark: %v", ErrInvalidInput, err)
+	}
+	var override WatermarkOverride
+	if err := json.Unmarshal(raw, &override); err != nil {
+		return nil, fmt.Errorf("%w: watermark must be a boolean or an object with enabled, position and opacity", ErrInvalidInput)
+	}
+	if override.Position != "" && !override.Position.Valid() {
+		return nil, fmt.Errorf("%w: watermark position must be one of %v", ErrInvalidInput, transcode.Positions)
+	}
+	if override.Opacity < 0 || override.Opacity > 1 {
+		return nil, fmt.Errorf("%w: watermark opacity must be between 0 and 1", ErrInvalidInput)
+	}
+	return &override, nil
+}
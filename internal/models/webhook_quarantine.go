@@ -0,0 +1,50 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Reasons a webhook is quarantined
+const (
+	// WebhookUnparseable bodies could not be decoded at all
+	WebhookUnparseable = "unparseable"
+	// WebhookMalformed bodies decoded but do not match the platform's payload
+	WebhookMalformed = "malformed"
+)
+
+// WebhookFieldError is a field of a webhook payload that failed validation
+type WebhookFieldError struct {
+	Field   string `json:"field"` // Path in the payload, such as entry[0].id
+	Message string `json:"message"`
+}
+
+// QuarantinedWebhook is a webhook body that was rejected because it does not
+// match its platform's payload, kept for manual inspection. Bodies come from
+// outside and may hold personal data, so they are stored encrypted.
+type QuarantinedWebhook struct {
+	ID          string              `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID    string              `json:"tenant_id,omitempty" gorm:"type:varchar(36);index"` // Set when it came through an authenticated route
+	Platform    string              `json:"platform" gorm:"type:varchar(50);not null;index:idx_quarantined_webhooks_platform_created,priority:1"`
+	Reason      string              `json:"reason" gorm:"type:varchar(20);not null"`
+	Errors      []WebhookFieldError `json:"errors" gorm:"type:json;serializer:json"`
+	ContentType string              `json:"content_type,omitempty" gorm:"type:varchar(255)"`
+	BodySize    int                 `json:"body_size"`
+	ReceivedAt  time.Time           `json:"received_at" gorm:"type:datetime(3);not null"`
+	CreatedAt   time.Time           `json:"created_at" gorm:"type:datetime(3);autoCreateTime;index:idx_quarantined_webhooks_created;index:idx_quarantined_webhooks_platform_created,priority:2"`
+
+	// Left out of listings; read a single webhook to get it
+	Body string `json:"body,omitempty" gorm:"type:mediumtext;serializer:encrypted"`
+}
+
+// QuarantinedWebhookRepository defines the interface for quarantined webhook
+// operations. Quarantined webhooks are inspected by support staff across tenants.
+type QuarantinedWebhookRepository interface {
+	Create(ctx context.Context, webhook *QuarantinedWebhook) error
+	// List returns quarantined webhooks newest first, optionally of one
+	// platform, without their bodies
+	List(ctx context.Context, platform string, limit, offset int) ([]*QuarantinedWebhook, error)
+	// GetByID returns a quarantined webhook with its body, or
+	// ErrQuarantinedWebhookNotFound
+	GetByID(ctx context.Context, id string) (*QuarantinedWebhook, error)
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Webhook subscription statuses
+const (
+	SubscriptionPending = "pending" // Requested, waiting for the platform to verify the callback
+	SubscriptionActive  = "active"
+	SubscriptionFailed  = "failed" // The last request was refused; retried by the renewal job
+)
+
+// WebhookSubscription registers our webhook URL with a platform for the
+// channel of a workspace, so the platform pushes its notifications instead
+// of waiting for the next stats sync. Leases that expire are renewed by a
+// background job before RenewAt passes.
+type WebhookSubscription struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID    string     `json:"tenant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_webhook_subscription,priority:1"`
+	WorkspaceID string     `json:"workspace_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_webhook_subscription,priority:2"`
+	Platform    string     `json:"platform" gorm:"type:varchar(50);not null;uniqueIndex:idx_webhook_subscription,priority:3;index:idx_webhook_subscriptions_topic,priority:1"`
+	Topic       string     `json:"topic,omitempty" gorm:"type:varchar(255);index:idx_webhook_subscriptions_topic,priority:2"` // What the platform notifies about, such as a channel feed
+	CallbackURL string     `json:"callback_url" gorm:"type:varchar(500);not null"`
+	Status      string     `json:"status" gorm:"type:varchar(20);not null"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // End of the lease; nil when it does not expire
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
+	RenewAt     time.Time  `json:"renew_at" gorm:"not null;index"`
+	Failures    int        `json:"failures"` // Requests refused in a row
+	LastError   string     `json:"last_error,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// WebhookSubscribeRequest is the request to subscribe a workspace's channels
+type WebhookSubscribeRequest struct {
+	WorkspaceID string `json:"workspace_id" binding:"required"`
+}
+
+// Expired reports whether the lease ended without being renewed, so the
+// platform no longer pushes notifications
+func (s *WebhookSubscription) Expired(now time.Time) bool {
+	return s.ExpiresAt != nil && !s.ExpiresAt.After(now)
+}
+
+// WebhookSubscriptionRepository defines data access for webhook subscriptions
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, subscription *WebhookSubscription) error
+	// Get returns the subscription of the workspace on the platform, or
+	// ErrSubscriptionNotFound
+	Get(ctx context.Context, tenantID, workspaceID, platform string) (*WebhookSubscription, error)
+	List(ctx context.Context, tenantID string) ([]*WebhookSubscription, error)
+	// ListDue returns the subscriptions of every tenant to request again by
+	// now, longest waiting first
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*WebhookSubscription, error)
+	// ListByTopic returns the subscriptions of every tenant to a topic of
+	// the platform
+	ListByTopic(ctx context.Context, platform, topic string) ([]*WebhookSubscription, error)
+	Update(ctx context.Context, subscription *WebhookSubscription) error
+	Delete(ctx context.Context, tenantID, id string) error
+}
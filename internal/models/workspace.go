@@ -1,6 +1,8 @@
 package models
 
 import (
+	"context"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,34 +16,84 @@ type Workspace struct {
 	UserID   string `json:"user_id" gorm:"type:varchar(36);not null;index"`
 	Name     string `json:"name" gorm:"type:varchar(255);not null"`
 
-	// OAuth and API credentials for partner platforms
+	// OAuth and API credentials for partner platforms; secrets and tokens are
+	// encrypted at rest
 	CredentialsPath       string `json:"credentials_path" gorm:"type:varchar(255)"`
 	TokenDir              string `json:"token_dir" gorm:"type:varchar(255)"`
 	TikTokAppID           string `json:"tiktok_app_id" gorm:"type:varchar(255)"`
-	TikTokSecret          string `json:"tiktok_secret" gorm:"type:varchar(255)"`
+	TikTokSecret          string `json:"tiktok_secret" gorm:"type:text;serializer:encrypted"`
 	RedirectURI           string `json:"redirect_uri" gorm:"type:varchar(500)"`
 	OAuthCode             string `json:"oauth_code" gorm:"-"`
 	InstagramUserID       string `json:"instagram_user_id" gorm:"type:varchar(255)"`
-	InstagramAccessToken  string `json:"instagram_access_token" gorm:"type:varchar(500)"`
+	InstagramAccessToken  string `json:"instagram_access_token" gorm:"type:text;serializer:encrypted"`
 	FacebookPageID        string `json:"facebook_page_id" gorm:"type:varchar(255)"`
-	FacebookPageToken     string `json:"facebook_page_token" gorm:"type:varchar(500)"`
+	FacebookPageToken     string `json:"facebook_page_token" gorm:"type:text;serializer:encrypted"`
 	TwitterConsumerKey    string `json:"twitter_consumer_key" gorm:"type:varchar(255)"`
-	TwitterConsumerSecret string `json:"twitter_consumer_secret" gorm:"type:varchar(255)"`
-	TwitterAccessToken    string `json:"twitter_access_token" gorm:"type:varchar(255)"`
-	TwitterAccessSecret   string `json:"twitter_access_secret" gorm:"type:varchar(255)"`
-	SnapchatAccessToken   string `json:"snapchat_access_token" gorm:"type:varchar(500)"`
+	TwitterConsumerSecret string `json:"twitter_consumer_secret" gorm:"type:text;serializer:encrypted"`
+	TwitterAccessToken    string `json:"twitter_access_token" gorm:"type:text;serializer:encrypted"`
+	TwitterAccessSecret   string `json:"twitter_access_secret" gorm:"type:text;serializer:encrypted"`
+	SnapchatAccessToken   string `json:"snapchat_access_token" gorm:"type:text;serializer:encrypted"`
 	SnapchatProfileID     string `json:"snapchat_profile_id" gorm:"type:varchar(255)"`
+	// NeedsReconnect lists the platforms that rejected the workspace's
+	// token, until it is connected to them again
+	NeedsReconnect []string `json:"needs_reconnect,omitempty" gorm:"type:json;serializer:json"`
 
 	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
 
+// Connected reports whether the workspace holds the credentials the
+// platform's client authenticates with
+func (w *Workspace) Connected(platform Platform) bool {
+	switch platform {
+	case PlatformYouTube:
+		return w.CredentialsPath != "" && w.TokenDir != ""
+	case PlatformTikTok:
+		return w.TikTokAppID != "" && w.TikTokSecret != ""
+	case PlatformInstagram:
+		return w.InstagramUserID != "" && w.InstagramAccessToken != ""
+	case PlatformFacebook:
+		return w.FacebookPageID != "" && w.FacebookPageToken != ""
+	case PlatformTwitter:
+		return w.TwitterConsumerKey != "" && w.TwitterAccessToken != ""
+	case PlatformSnapchat:
+		return w.SnapchatProfileID != "" && w.SnapchatAccessToken != ""
+	}
+	return false
+}
+
+// ReconnectNeeded reports whether the platform rejected the workspace's token
+func (w *Workspace) ReconnectNeeded(platform Platform) bool {
+	return slices.Contains(w.NeedsReconnect, string(platform))
+}
+
+// MarkReconnectNeeded records that the platform rejected the workspace's
+// token, reporting whether it was not known yet
+func (w *Workspace) MarkReconnectNeeded(platform Platform) bool {
+	if w.ReconnectNeeded(platform) {
+		return false
+	}
+	w.NeedsReconnect = append(w.NeedsReconnect, string(platform))
+	return true
+}
+
+// ClearReconnectNeeded records that the workspace was connected to the
+// platform again
+func (w *Workspace) ClearReconnectNeeded(platform Platform) {
+	w.NeedsReconnect = slices.DeleteFunc(w.NeedsReconnect, func(p string) bool { return p == string(platform) })
+}
+
+// ReconnectPlatformRequest names the workspace to connect to a platform again
+type ReconnectPlatformRequest struct {
+	WorkspaceID string `json:"workspace_id" binding:"required"`
+}
+
 // WorkspaceRepository defines data access methods for workspaces.
 type WorkspaceRepository interface {
-	Create(workspace *Workspace) error
-	GetByID(tenantID, id string) (*Workspace, error)
-	ListByUser(tenantID, userID string) ([]*Workspace, error)
-	Update(workspace *Workspace) error
-	Delete(tenantID, id string) error
+	Create(ctx context.Context, workspace *Workspace) error
+	GetByID(ctx context.Context, tenantID, id string) (*Workspace, error)
+	ListByUser(ctx context.Context, tenantID, userID string) ([]*Workspace, error)
+	Update(ctx context.Context, workspace *Workspace) error
+	Delete(ctx context.Context, tenantID, id string) error
 }
@@ -1,6 +1,7 @@
 package partners
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
@@ -18,18 +19,158 @@ func NewService(factory func(string) (pkgpartners.Client, error)) *Service {
 }
 
 // PublishVideo uploads then publishes a video to the specified platform.
-func (s *Service) PublishVideo(ws *models.Workspace, v *models.Video, platform models.Platform) (*models.VideoStats, error) {
+// It stops between steps once ctx is cancelled, as some platform SDKs do not
+// honor it themselves.
+func (s *Service) PublishVideo(ctx context.Context, ws *models.Workspace, v *models.Video, platform models.Platform) (*models.VideoStats, error) {
 	client, err := s.factory(string(platform))
 	if err != nil {
 		return nil, err
 	}
-	if err := client.Authenticate(ws); err != nil {
+	if err := client.Authenticate(ctx, ws); err != nil {
 		return nil, err
 	}
-	id, err := client.Upload(v)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	id, err := client.Upload(ctx, v)
 	if err != nil {
 		return nil, err
 	}
+	setPlatformID(v, platform, id)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := client.Publish(ctx, v, ws); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return client.FetchStats(ctx, v)
+}
+
+// Authenticate checks that the platform accepts the workspace's credentials
+func (s *Service) Authenticate(ctx context.Context, ws *models.Workspace, platform models.Platform) error {
+	client, err := s.factory(string(platform))
+	if err != nil {
+		return err
+	}
+	return client.Authenticate(ctx, ws)
+}
+
+// Upload uploads a video to the platform without publishing it, setting its
+// platform ID. On platforms whose capabilities allow staging, the video stays
+// hidden until Release.
+func (s *Service) Upload(ctx context.Context, ws *models.Workspace, v *models.Video, platform models.Platform) error {
+	return s.UploadRendition(ctx, ws, v, nil, platform)
+}
+
+// UploadRendition uploads the rendition's file in place of the video's like
+// Upload does, setting the platform ID on the video. A nil rendition uploads
+// the video's own file.
+func (s *Service) UploadRendition(ctx context.Context, ws *models.Workspace, v *models.Video, r *models.VideoRendition, platform models.Platform) error {
+	client, err := s.factory(string(platform))
+	if err != nil {
+		return err
+	}
+	if err := client.Authenticate(ctx, ws); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	id, err := client.Upload(ctx, v.WithRendition(r))
+	if err != nil {
+		return err
+	}
+	setPlatformID(v, platform, id)
+	return nil
+}
+
+// Release publishes a video uploaded to the platform
+func (s *Service) Release(ctx context.Context, ws *models.Workspace, v *models.Video, platform models.Platform) error {
+	client, err := s.factory(string(platform))
+	if err != nil {
+		return err
+	}
+	if err := client.Authenticate(ctx, ws); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return client.Publish(ctx, v, ws)
+}
+
+// Unpublish takes down the video the platform knows as externalID. The ID is
+// the publication's rather than the video's, which only holds the latest one.
+func (s *Service) Unpublish(ctx context.Context, ws *models.Workspace, v *models.Video, platform models.Platform, externalID string) error {
+	client, err := s.factory(string(platform))
+	if err != nil {
+		return err
+	}
+	if err := client.Authenticate(ctx, ws); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	published := *v
+	setPlatformID(&published, platform, externalID)
+	return client.Unpublish(ctx, &published)
+}
+
+// SyncStats retrieves latest statistics from the platform, with the audience
+// demographics and traffic sources normalized when the platform reports them.
+func (s *Service) SyncStats(ctx context.Context, ws *models.Workspace, v *models.Video, platform models.Platform) (*models.VideoStats, error) {
+	client, err := s.factory(string(platform))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Authenticate(ctx, ws); err != nil {
+		return nil, err
+	}
+	stats, err := client.FetchStats(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	if dc, ok := pkgpartners.Capability[pkgpartners.DemographicsClient](client); ok {
+		raw, err := dc.FetchDemographics(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		if stats.Demographics, err = pkgpartners.NormalizeDemographics(string(platform), raw); err != nil {
+			return nil, err
+		}
+	}
+	if tc, ok := pkgpartners.Capability[pkgpartners.TrafficSourcesClient](client); ok {
+		raw, err := tc.FetchTrafficSources(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		if stats.TrafficSources, err = pkgpartners.NormalizeTrafficSources(string(platform), raw); err != nil {
+			return nil, err
+		}
+	}
+	if dc, ok := pkgpartners.Capability[pkgpartners.DeviceTypesClient](client); ok {
+		raw, err := dc.FetchDeviceTypes(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		devices, err := pkgpartners.NormalizeDeviceTypes(string(platform), raw)
+		if err != nil {
+			return nil, err
+		}
+		stats.DeviceTypes = models.SplitViews(stats.Views, devices)
+	}
+	if stats.Demographics != nil {
+		stats.Locations = models.SplitViews(stats.Views, stats.Demographics.Countries)
+	}
+	return stats, nil
+}
+
+// setPlatformID records the ID the platform gave the uploaded video
+func setPlatformID(v *models.Video, platform models.Platform, id string) {
 	switch platform {
 	case models.PlatformYouTube:
 		v.YouTubeID = id
@@ -46,20 +187,4 @@ func (s *Service) PublishVideo(ws *models.Workspace, v *models.Video, platform m
 	case models.PlatformSnapchat:
 		v.SnapchatMediaID = id
 	}
-	if err := client.Publish(v, ws); err != nil {
-		return nil, err
-	}
-	return client.FetchStats(v)
-}
-
-// SyncStats retrieves latest statistics from the platform.
-func (s *Service) SyncStats(ws *models.Workspace, v *models.Video, platform models.Platform) (*models.VideoStats, error) {
-	client, err := s.factory(string(platform))
-	if err != nil {
-		return nil, err
-	}
-	if err := client.Authenticate(ws); err != nil {
-		return nil, err
-	}
-	return client.FetchStats(v)
 }
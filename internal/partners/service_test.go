@@ -1,6 +1,9 @@
 package partners
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
@@ -8,32 +11,42 @@ import (
 )
 
 type mockClient struct {
-	calls []string
+	calls    []string
+	onUpload func()
+	uploaded *models.Video
 }
 
-func (m *mockClient) Authenticate(*models.Workspace) error {
+func (m *mockClient) Authenticate(context.Context, *models.Workspace) error {
 	m.calls = append(m.calls, "auth")
 	return nil
 }
-func (m *mockClient) Upload(*models.Video) (string, error) {
+func (m *mockClient) Upload(_ context.Context, v *models.Video) (string, error) {
 	m.calls = append(m.calls, "upload")
+	m.uploaded = v
+	if m.onUpload != nil {
+		m.onUpload()
+	}
 	return "42", nil
 }
-func (m *mockClient) Publish(*models.Video, *models.Workspace) error {
+func (m *mockClient) Publish(context.Context, *models.Video, *models.Workspace) error {
 	m.calls = append(m.calls, "publish")
 	return nil
 }
-func (m *mockClient) FetchStats(*models.Video) (*models.VideoStats, error) {
+func (m *mockClient) FetchStats(context.Context, *models.Video) (*models.VideoStats, error) {
 	m.calls = append(m.calls, "stats")
 	return &models.VideoStats{Views: 1}, nil
 }
+func (m *mockClient) Unpublish(_ context.Context, v *models.Video) error {
+	m.calls = append(m.calls, "unpublish "+v.YouTubeID)
+	return nil
+}
 
 func TestServicePublishVideo(t *testing.T) {
 	mc := &mockClient{}
 	svc := NewService(func(string) (pkgpartners.Client, error) { return mc, nil })
 	ws := &models.Workspace{}
 	v := &models.Video{}
-	stats, err := svc.PublishVideo(ws, v, models.PlatformYouTube)
+	stats, err := svc.PublishVideo(context.Background(), ws, v, models.PlatformYouTube)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -56,7 +69,7 @@ func TestServiceSyncStats(t *testing.T) {
 	svc := NewService(func(string) (pkgpartners.Client, error) { return mc, nil })
 	ws := &models.Workspace{}
 	v := &models.Video{}
-	stats, err := svc.SyncStats(ws, v, models.PlatformTikTok)
+	stats, err := svc.SyncStats(context.Background(), ws, v, models.PlatformTikTok)
 	if err != nil || stats.Views != 1 {
 		t.Fatalf("unexpected result: %v %v", err, stats)
 	}
@@ -67,3 +80,162 @@ func TestServiceSyncStats(t *testing.T) {
 		}
 	}
 }
+
+// demographicsClient reports a TikTok audience payload
+type demographicsClient struct {
+	mockClient
+}
+
+func (m *demographicsClient) FetchDemographics(context.Context, *models.Video) (json.RawMessage, error) {
+	m.calls = append(m.calls, "demographics")
+	return json.RawMessage(`{"audience_genders":[{"gender":"Female","percentage":0.6},{"gender":"Male","percentage":0.4}]}`), nil
+}
+
+func TestServiceSyncStatsNormalizesDemographics(t *testing.T) {
+	mc := &demographicsClient{}
+	svc := NewService(func(string) (pkgpartners.Client, error) { return mc, nil })
+	stats, err := svc.SyncStats(context.Background(), &models.Workspace{}, &models.Video{}, models.PlatformTikTok)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Demographics == nil || stats.Demographics.Gender[models.GenderFemale] != 0.6 {
+		t.Fatalf("unexpected demographics: %#v", stats.Demographics)
+	}
+	if len(mc.calls) != 3 || mc.calls[2] != "demographics" {
+		t.Fatalf("expected demographics after stats, got calls %v", mc.calls)
+	}
+}
+
+type trafficSourcesClient struct {
+	demographicsClient
+}
+
+func (m *trafficSourcesClient) FetchTrafficSources(context.Context, *models.Video) (json.RawMessage, error) {
+	m.calls = append(m.calls, "traffic_sources")
+	return json.RawMessage(`{"impression_sources":[{"impression_source":"For You","percentage":0.9},{"impression_source":"Search","percentage":0.1}]}`), nil
+}
+
+func TestServiceSyncStatsNormalizesTrafficSources(t *testing.T) {
+	mc := &trafficSourcesClient{}
+	svc := NewService(func(string) (pkgpartners.Client, error) { return mc, nil })
+	stats, err := svc.SyncStats(context.Background(), &models.Workspace{}, &models.Video{}, models.PlatformTikTok)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.TrafficSources[models.TrafficSuggested] != 0.9 || stats.TrafficSources[models.TrafficSearch] != 0.1 {
+		t.Fatalf("unexpected traffic sources: %#v", stats.TrafficSources)
+	}
+	if len(mc.calls) != 4 || mc.calls[3] != "traffic_sources" {
+		t.Fatalf("expected traffic sources after demographics, got calls %v", mc.calls)
+	}
+}
+
+// deviceTypesClient reports the views of a YouTube video by device and an
+// audience spread over two countries
+type deviceTypesClient struct {
+	mockClient
+}
+
+func (m *deviceTypesClient) FetchStats(context.Context, *models.Video) (*models.VideoStats, error) {
+	m.calls = append(m.calls, "stats")
+	return &models.VideoStats{Views: 1000}, nil
+}
+
+func (m *deviceTypesClient) FetchDemographics(context.Context, *models.Video) (json.RawMessage, error) {
+	m.calls = append(m.calls, "demographics")
+	return json.RawMessage(`{"countries":{"columnHeaders":[{"name":"country"},{"name":"views"}],"rows":[["FR",750],["BE",250]]}}`), nil
+}
+
+func (m *deviceTypesClient) FetchDeviceTypes(context.Context, *models.Video) (json.RawMessage, error) {
+	m.calls = append(m.calls, "device_types")
+	return json.RawMessage(`{"columnHeaders":[{"name":"deviceType"},{"name":"views"}],"rows":[["MOBILE",60],["DESKTOP",40]]}`), nil
+}
+
+func TestServiceSyncStatsSplitsViewsByDeviceAndCountry(t *testing.T) {
+	mc := &deviceTypesClient{}
+	svc := NewService(func(string) (pkgpartners.Client, error) { return mc, nil })
+	stats, err := svc.SyncStats(context.Background(), &models.Workspace{}, &models.Video{}, models.PlatformYouTube)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.DeviceTypes[models.DeviceMobile] != 600 || stats.DeviceTypes[models.DeviceDesktop] != 400 {
+		t.Fatalf("unexpected device types: %#v", stats.DeviceTypes)
+	}
+	if stats.Locations["FR"] != 750 || stats.Locations["BE"] != 250 {
+		t.Fatalf("unexpected locations: %#v", stats.Locations)
+	}
+}
+
+func TestServicePublishVideoStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mc := &mockClient{onUpload: cancel}
+	svc := NewService(func(string) (pkgpartners.Client, error) { return mc, nil })
+
+	_, err := svc.PublishVideo(ctx, &models.Workspace{}, &models.Video{}, models.PlatformYouTube)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(mc.calls) != 2 || mc.calls[1] != "upload" {
+		t.Fatalf("expected to stop after upload, got calls %v", mc.calls)
+	}
+}
+
+func TestServiceUploadThenRelease(t *testing.T) {
+	mc := &mockClient{}
+	svc := NewService(func(string) (pkgpartners.Client, error) { return mc, nil })
+	ws := &models.Workspace{}
+	v := &models.Video{}
+
+	if err := svc.Upload(context.Background(), ws, v, models.PlatformYouTube); err != nil {
+		t.Fatalf("unexpected upload error: %v", err)
+	}
+	if v.YouTubeID != "42" {
+		t.Fatalf("video id not set")
+	}
+	if err := svc.Release(context.Background(), ws, v, models.PlatformYouTube); err != nil {
+		t.Fatalf("unexpected release error: %v", err)
+	}
+	expected := []string{"auth", "upload", "auth", "publish"}
+	if len(mc.calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, mc.calls)
+	}
+	for i, call := range expected {
+		if mc.calls[i] != call {
+			t.Fatalf("expected call %s at index %d, got %s", call, i, mc.calls[i])
+		}
+	}
+}
+
+func TestServiceUnpublishUsesPublicationID(t *testing.T) {
+	mc := &mockClient{}
+	svc := NewService(func(string) (pkgpartners.Client, error) { return mc, nil })
+	v := &models.Video{YouTubeID: "latest"}
+
+	if err := svc.Unpublish(context.Background(), &models.Workspace{}, v, models.PlatformYouTube, "first"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mc.calls) != 2 || mc.calls[1] != "unpublish first" {
+		t.Fatalf("expected to unpublish the publication's video, got calls %v", mc.calls)
+	}
+	if v.YouTubeID != "latest" {
+		t.Fatalf("video changed: %s", v.YouTubeID)
+	}
+}
+
+func TestServiceUploadRendition(t *testing.T) {
+	mc := &mockClient{}
+	svc := NewService(func(string) (pkgpartners.Client, error) { return mc, nil })
+	v := &models.Video{FilePath: "/videos/source.mov"}
+	r := &models.VideoRendition{FilePath: "/videos/vertical_1080p.mp4", Width: 1080, Height: 1920}
+
+	if err := svc.UploadRendition(context.Background(), &models.Workspace{}, v, r, models.PlatformTikTok); err != nil {
+		t.Fatalf("unexpected upload error: %v", err)
+	}
+	if mc.uploaded.FilePath != "/videos/vertical_1080p.mp4" || mc.uploaded.Resolution != "1080x1920" {
+		t.Fatalf("expected the rendition to be uploaded, got %s (%s)", mc.uploaded.FilePath, mc.uploaded.Resolution)
+	}
+	if v.TikTokID != "42" || v.FilePath != "/videos/source.mov" {
+		t.Fatalf("expected the platform ID on the unchanged video, got %q and %s", v.TikTokID, v.FilePath)
+	}
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// aiUsageRepository implements models.AIUsageRepository.
+type aiUsageRepository struct {
+	db *gorm.DB
+}
+
+var _ models.AIUsageRepository = (*aiUsageRepository)(nil)
+
+// NewAIUsageRepository creates a new repository instance.
+func NewAIUsageRepository(db *gorm.DB) models.AIUsageRepository {
+	return &aiUsageRepository{db: db}
+}
+
+func (r *aiUsageRepository) Create(ctx context.Context, usage *models.AIUsage) error {
+	if usage.ID == "" {
+		usage.ID = id.New()
+	}
+	return forTenant(ctx, r.db, usage.TenantID).Create(usage).Error
+}
+
+func (r *aiUsageRepository) SpendByTenant(ctx context.Context, since time.Time, limit int) ([]*models.TenantAISpend, error) {
+	var spend []*models.TenantAISpend
+	err := allTenants(ctx, r.db).Model(&models.AIUsage{}).
+		Select("tenant_id, COUNT(*) AS requests, SUM(input_tokens) AS input_tokens, SUM(output_tokens) AS output_tokens, SUM(cost) AS cost").
+		Where("created_at >= ?", since).
+		Group("tenant_id").
+		Order("cost DESC").
+		Limit(limit).
+		Scan(&spend).Error
+	return spend, err
+}
+
+func (r *aiUsageRepository) ListActivity(ctx context.Context, tenantID string, scope *models.ActivityScope) ([]*models.AIUsage, error) {
+	if len(scope.VideoIDs) == 0 && scope.CampaignID == "" {
+		return nil, nil
+	}
+	subjects := r.db.Where("video_id IN ?", scope.VideoIDs)
+	if scope.CampaignID != "" {
+		subjects = subjects.Or("campaign_id = ?", scope.CampaignID)
+	}
+
+	var usage []*models.AIUsage
+	err := forTenant(ctx, r.db, tenantID).
+		Where(subjects).
+		Where("created_at < ?", scope.Before).
+		Order("created_at DESC").Limit(scope.Limit).Find(&usage).Error
+	return usage, err
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestAIUsageRepository_SpendByTenant(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAIUsageRepository(gormDB)
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT tenant_id, COUNT\\(\\*\\) AS requests, .* SUM\\(cost\\) AS cost FROM `ai_usage` WHERE created_at >= \\? "+
+		"GROUP BY `tenant_id` ORDER BY cost DESC LIMIT \\?").
+		WithArgs(since, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "requests", "input_tokens", "output_tokens", "cost"}).
+			AddRow("tenant-1", 42, 120000, 30000, 0.81).
+			AddRow("tenant-2", 3, 900, 400, 0.0087))
+
+	spend, err := repo.SpendByTenant(context.Background(), since, 10)
+	require.NoError(t, err)
+	require.Len(t, spend, 2)
+	assert.Equal(t, "tenant-1", spend[0].TenantID)
+	assert.Equal(t, int64(42), spend[0].Requests)
+	assert.InDelta(t, 0.81, spend[0].Cost, 1e-9)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAIUsageRepository_CreateStampsTenant(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAIUsageRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `ai_usage`").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	usage := &models.AIUsage{TenantID: "tenant-1", Model: "claude", InputTokens: 10, OutputTokens: 5}
+	require.NoError(t, repo.Create(context.Background(), usage))
+	assert.NotEmpty(t, usage.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// alertRuleRepository implements models.AlertRuleRepository.
+type alertRuleRepository struct {
+	db *gorm.DB
+}
+
+var _ models.AlertRuleRepository = (*alertRuleRepository)(nil)
+
+// NewAlertRuleRepository creates a new repository instance.
+func NewAlertRuleRepository(db *gorm.DB) models.AlertRuleRepository {
+	return &alertRuleRepository{db: db}
+}
+
+func (r *alertRuleRepository) Create(ctx context.Context, rule *models.AlertRule) error {
+	if rule.ID == "" {
+		rule.ID = id.New()
+	}
+	return forTenant(ctx, r.db, rule.TenantID).Create(rule).Error
+}
+
+func (r *alertRuleRepository) Get(ctx context.Context, tenantID, userID, id string) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	err := forTenant(ctx, r.db, tenantID).Where("user_id = ? AND id = ?", userID, id).First(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrAlertRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *alertRuleRepository) List(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.AlertRule, error) {
+	var rules []*models.AlertRule
+	err := forTenant(ctx, r.db, tenantID).Where("user_id = ?", userID).
+		Order("created_at, id").Limit(limit).Offset(offset).Find(&rules).Error
+	return rules, err
+}
+
+func (r *alertRuleRepository) CountByUser(ctx context.Context, tenantID, userID string) (int64, error) {
+	var count int64
+	err := forTenant(ctx, r.db, tenantID).Model(&models.AlertRule{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *alertRuleRepository) Update(ctx context.Context, rule *models.AlertRule) error {
+	return forTenant(ctx, r.db, rule.TenantID).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("*").Save(rule).Error; err != nil {
+			return err
+		}
+		return tx.Where("rule_id = ?", rule.ID).Delete(&models.AlertFiring{}).Error
+	})
+}
+
+func (r *alertRuleRepository) Delete(ctx context.Context, tenantID, userID, id string) error {
+	return forTenant(ctx, r.db, tenantID).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("user_id = ? AND id = ?", userID, id).Delete(&models.AlertRule{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrAlertRuleNotFound
+		}
+		return tx.Where("rule_id = ?", id).Delete(&models.AlertFiring{}).Error
+	})
+}
+
+func (r *alertRuleRepository) ListEnabled(ctx context.Context, tenantID string, metrics []models.AlertMetric) ([]*models.AlertRule, error) {
+	var rules []*models.AlertRule
+	err := forTenant(ctx, r.db, tenantID).Where("metric IN ? AND enabled = ?", metrics, true).
+		Order("id").Find(&rules).Error
+	return rules, err
+}
+
+func (r *alertRuleRepository) ListFirings(ctx context.Context, tenantID string, ruleIDs []string) ([]*models.AlertFiring, error) {
+	var firings []*models.AlertFiring
+	if len(ruleIDs) == 0 {
+		return firings, nil
+	}
+	err := forTenant(ctx, r.db, tenantID).Where("rule_id IN ?", ruleIDs).Find(&firings).Error
+	return firings, err
+}
+
+// Fire relies on the unique rule and subject index, so two replicas
+// evaluating the same event notify once
+func (r *alertRuleRepository) Fire(ctx context.Context, firing *models.AlertFiring) (bool, error) {
+	if firing.ID == "" {
+		firing.ID = id.New()
+	}
+	res := forTenant(ctx, r.db, firing.TenantID).Clauses(clause.OnConflict{DoNothing: true}).Create(firing)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *alertRuleRepository) Resolve(ctx context.Context, tenantID string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return forTenant(ctx, r.db, tenantID).Where("id IN ?", ids).Delete(&models.AlertFiring{}).Error
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestAlertRuleRepository_FireOncePerSubject(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAlertRuleRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `alert_firings` .* ON DUPLICATE KEY UPDATE `id`=`id`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	fired, err := repo.Fire(context.Background(), &models.AlertFiring{TenantID: "acme", RuleID: "rule-1", SubjectID: "stats-1", FiredAt: time.Now()})
+	require.NoError(t, err)
+	assert.False(t, fired, "the rule already fired for the stats row")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAlertRuleRepository_DeleteRemovesFirings(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAlertRuleRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `alert_rules` WHERE \\(user_id = \\? AND id = \\?\\) AND `alert_rules`.`tenant_id` = \\?").
+		WithArgs("user-1", "rule-1", "acme").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM `alert_firings` WHERE rule_id = \\? AND `alert_firings`.`tenant_id` = \\?").
+		WithArgs("rule-1", "acme").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Delete(context.Background(), "acme", "user-1", "rule-1"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAlertRuleRepository_DeleteNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAlertRuleRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `alert_rules`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.Delete(context.Background(), "acme", "user-2", "rule-1")
+	assert.ErrorIs(t, err, models.ErrAlertRuleNotFound, "users only delete their own rules")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// analyticsReaderRepository implements models.AnalyticsReaderRepository.
+type analyticsReaderRepository struct {
+	db *gorm.DB
+}
+
+var _ models.AnalyticsReaderRepository = (*analyticsReaderRepository)(nil)
+
+// NewAnalyticsReaderRepository creates a new repository instance.
+func NewAnalyticsReaderRepository(db *gorm.DB) models.AnalyticsReaderRepository {
+	return &analyticsReaderRepository{db: db}
+}
+
+func (r *analyticsReaderRepository) Create(ctx context.Context, reader *models.AnalyticsReader) error {
+	if reader.ID == "" {
+		reader.ID = id.New()
+	}
+	return forTenant(ctx, r.db, reader.TenantID).Create(reader).Error
+}
+
+func (r *analyticsReaderRepository) List(ctx context.Context, tenantID string, limit, offset int) ([]*models.AnalyticsReader, error) {
+	var readers []*models.AnalyticsReader
+	err := forTenant(ctx, r.db, tenantID).Order("created_at DESC, id").Limit(limit).Offset(offset).Find(&readers).Error
+	return readers, err
+}
+
+// FindByTokenHash spans tenants since the token is all a reader presents;
+// revoked readers are not found
+func (r *analyticsReaderRepository) FindByTokenHash(ctx context.Context, hash string) (*models.AnalyticsReader, error) {
+	var reader models.AnalyticsReader
+	err := allTenants(ctx, r.db).Where("token_hash = ? AND revoked_at IS NULL", hash).First(&reader).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrAnalyticsReaderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reader, nil
+}
+
+func (r *analyticsReaderRepository) Revoke(ctx context.Context, tenantID, id string, at time.Time) error {
+	res := forTenant(ctx, r.db, tenantID).Model(&models.AnalyticsReader{}).
+		Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrAnalyticsReaderNotFound
+	}
+	return nil
+}
+
+func (r *analyticsReaderRepository) MarkUsed(ctx context.Context, tenantID, id string, at time.Time) error {
+	return forTenant(ctx, r.db, tenantID).Model(&models.AnalyticsReader{}).
+		Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestAnalyticsReaderRepository_FindByTokenHashSkipsRevoked(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAnalyticsReaderRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `analytics_readers` WHERE token_hash = \\? AND revoked_at IS NULL").
+		WithArgs("hash", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.FindByTokenHash(context.Background(), "hash")
+	assert.ErrorIs(t, err, models.ErrAnalyticsReaderNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
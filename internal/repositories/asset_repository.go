@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// assetRepository implements models.AssetRepository.
+type assetRepository struct {
+	db *gorm.DB
+}
+
+var _ models.AssetRepository = (*assetRepository)(nil)
+
+// NewAssetRepository creates a new repository instance.
+func NewAssetRepository(db *gorm.DB) models.AssetRepository {
+	return &assetRepository{db: db}
+}
+
+func (r *assetRepository) Create(ctx context.Context, asset *models.Asset) error {
+	if asset.ID == "" {
+		asset.ID = id.New()
+	}
+	return forTenant(ctx, r.db, asset.TenantID).Create(asset).Error
+}
+
+func (r *assetRepository) Get(ctx context.Context, tenantID, id string) (*models.Asset, error) {
+	var asset models.Asset
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&asset).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrAssetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (r *assetRepository) List(ctx context.Context, tenantID string, kind models.AssetKind) ([]*models.Asset, error) {
+	var assets []*models.Asset
+	query := forTenant(ctx, r.db, tenantID)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	err := query.Order("created_at DESC, id").Find(&assets).Error
+	return assets, err
+}
+
+func (r *assetRepository) Update(ctx context.Context, asset *models.Asset) error {
+	return saveForTenant(ctx, r.db, asset.TenantID, asset)
+}
+
+func (r *assetRepository) Delete(ctx context.Context, tenantID, id string) error {
+	res := forTenant(ctx, r.db, tenantID).Where("id = ?", id).Delete(&models.Asset{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrAssetNotFound
+	}
+	return nil
+}
+
+func (r *assetRepository) GetDefaults(ctx context.Context, tenantID string) (*models.AssetDefaults, error) {
+	var defaults models.AssetDefaults
+	err := forTenant(ctx, r.db, tenantID).First(&defaults).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &defaults, nil
+}
+
+// PutDefaults replaces the assets and profiles of the tenant's existing
+// defaults
+func (r *assetRepository) PutDefaults(ctx context.Context, defaults *models.AssetDefaults) error {
+	if defaults.ID == "" {
+		defaults.ID = id.New()
+	}
+	return forTenant(ctx, r.db, defaults.TenantID).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"intro_asset_id", "outro_asset_id", "profiles", "updated_by", "updated_at"}),
+	}).Create(defaults).Error
+}
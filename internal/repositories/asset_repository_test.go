@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestAssetRepository_ListByKind(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAssetRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `assets` WHERE kind = \\? AND `assets`.`tenant_id` = \\? ORDER BY created_at DESC, id").
+		WithArgs("intro", "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "license"}).AddRow("asset-1", "intro", `{"license_type":"owned"}`))
+	mock.ExpectQuery("SELECT \\* FROM `assets` WHERE `assets`.`tenant_id` = \\? ORDER BY created_at DESC, id").
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	assets, err := repo.List(context.Background(), "acme", models.AssetIntro)
+	require.NoError(t, err)
+	require.Len(t, assets, 1)
+	assert.Equal(t, models.LicenseOwned, assets[0].License.LicenseType)
+
+	_, err = repo.List(context.Background(), "acme", "")
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAssetRepository_NotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAssetRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `assets` WHERE id = \\? AND `assets`.`tenant_id` = \\?").
+		WithArgs("asset-1", "acme", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `assets` WHERE id = \\? AND `assets`.`tenant_id` = \\?").
+		WithArgs("asset-1", "acme").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT \\* FROM `asset_defaults` WHERE `asset_defaults`.`tenant_id` = \\?").
+		WithArgs("acme", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.Get(context.Background(), "acme", "asset-1")
+	assert.ErrorIs(t, err, models.ErrAssetNotFound)
+	assert.ErrorIs(t, repo.Delete(context.Background(), "acme", "asset-1"), models.ErrAssetNotFound)
+	_, err = repo.GetDefaults(context.Background(), "acme")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAssetRepository_PutDefaultsReplacesTenantDefaults(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAssetRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `asset_defaults` .* ON DUPLICATE KEY UPDATE " +
+		"`intro_asset_id`=VALUES\\(`intro_asset_id`\\),`outro_asset_id`=VALUES\\(`outro_asset_id`\\)," +
+		"`profiles`=VALUES\\(`profiles`\\),`updated_by`=VALUES\\(`updated_by`\\),`updated_at`=VALUES\\(`updated_at`\\)").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	defaults := &models.AssetDefaults{TenantID: "acme", IntroAssetID: "asset-1"}
+	require.NoError(t, repo.PutDefaults(context.Background(), defaults))
+	assert.NotEmpty(t, defaults.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
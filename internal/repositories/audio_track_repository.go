@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// audioTrackRepository implements models.AudioTrackRepository.
+type audioTrackRepository struct {
+	db *gorm.DB
+}
+
+var _ models.AudioTrackRepository = (*audioTrackRepository)(nil)
+
+// NewAudioTrackRepository creates a new repository instance.
+func NewAudioTrackRepository(db *gorm.DB) models.AudioTrackRepository {
+	return &audioTrackRepository{db: db}
+}
+
+func (r *audioTrackRepository) Get(ctx context.Context, tenantID, videoID, language string) (*models.AudioTrack, error) {
+	var track models.AudioTrack
+	err := forTenant(ctx, r.db, tenantID).Where("video_id = ? AND language = ?", videoID, language).First(&track).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrAudioTrackNotFound
+	}
+	return &track, err
+}
+
+func (r *audioTrackRepository) ListByVideo(ctx context.Context, tenantID, videoID string) ([]*models.AudioTrack, error) {
+	var tracks []*models.AudioTrack
+	err := forTenant(ctx, r.db, tenantID).Where("video_id = ?", videoID).Order("language").Find(&tracks).Error
+	return tracks, err
+}
+
+// Upsert replaces the voice, output and status of an existing track in the
+// language
+func (r *audioTrackRepository) Upsert(ctx context.Context, track *models.AudioTrack) error {
+	if track.ID == "" {
+		track.ID = id.New()
+	}
+	return forTenant(ctx, r.db, track.TenantID).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "video_id"}, {Name: "language"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source_language", "voice", "status", "file_path", "file_url", "s3_key",
+			"file_size", "error_msg", "synthesized_at", "updated_at"}),
+	}).Create(track).Error
+}
+
+func (r *audioTrackRepository) Delete(ctx context.Context, tenantID, videoID, language string) error {
+	res := forTenant(ctx, r.db, tenantID).Where("video_id = ? AND language = ?", videoID, language).Delete(&models.AudioTrack{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrAudioTrackNotFound
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestAudioTrackRepository_UpsertReplacesLanguageTrack(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAudioTrackRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `audio_tracks` .* ON DUPLICATE KEY UPDATE " +
+		"`source_language`=VALUES\\(`source_language`\\),`voice`=VALUES\\(`voice`\\),`status`=VALUES\\(`status`\\)," +
+		"`file_path`=VALUES\\(`file_path`\\),`file_url`=VALUES\\(`file_url`\\),`s3_key`=VALUES\\(`s3_key`\\)," +
+		"`file_size`=VALUES\\(`file_size`\\),`error_msg`=VALUES\\(`error_msg`\\),`synthesized_at`=VALUES\\(`synthesized_at`\\)," +
+		"`updated_at`=VALUES\\(`updated_at`\\)").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	track := &models.AudioTrack{TenantID: "acme", VideoID: "video-1", Language: "fr", SourceLanguage: "en", Status: "pending"}
+	require.NoError(t, repo.Upsert(context.Background(), track))
+	assert.NotEmpty(t, track.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAudioTrackRepository_NotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAudioTrackRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `audio_tracks` WHERE \\(video_id = \\? AND language = \\?\\) AND `audio_tracks`.`tenant_id` = \\?").
+		WithArgs("video-1", "fr", "acme", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `audio_tracks` WHERE \\(video_id = \\? AND language = \\?\\) AND `audio_tracks`.`tenant_id` = \\?").
+		WithArgs("video-1", "fr", "acme").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err := repo.Get(context.Background(), "acme", "video-1", "fr")
+	assert.ErrorIs(t, err, models.ErrAudioTrackNotFound)
+	assert.ErrorIs(t, repo.Delete(context.Background(), "acme", "video-1", "fr"), models.ErrAudioTrackNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
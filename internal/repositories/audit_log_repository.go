@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// auditLogRepository implements models.AuditLogRepository.
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+var _ models.AuditLogRepository = (*auditLogRepository)(nil)
+
+// NewAuditLogRepository creates a new repository instance.
+func NewAuditLogRepository(db *gorm.DB) models.AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	if entry.ID == "" {
+		entry.ID = id.New()
+	}
+	return forTenant(ctx, r.db, entry.TenantID).Create(entry).Error
+}
+
+func (r *auditLogRepository) List(ctx context.Context, tenantID string, impersonatedOnly bool, limit, offset int) ([]*models.AuditLog, error) {
+	query := forTenant(ctx, r.db, tenantID)
+	if impersonatedOnly {
+		query = query.Where("impersonated = ?", true)
+	}
+
+	var entries []*models.AuditLog
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&entries).Error
+	return entries, err
+}
+
+// ListActivity matches the request paths of the scope's videos and campaign,
+// and of everything under them
+func (r *auditLogRepository) ListActivity(ctx context.Context, tenantID string, scope *models.ActivityScope) ([]*models.AuditLog, error) {
+	paths := make([]string, 0, len(scope.VideoIDs)+1)
+	for _, videoID := range scope.VideoIDs {
+		paths = append(paths, models.VideoPath(videoID))
+	}
+	if scope.CampaignID != "" {
+		paths = append(paths, models.CampaignPath(scope.CampaignID))
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	resources := r.db.Where("resource IN ?", paths)
+	for _, path := range paths {
+		resources = resources.Or("resource LIKE ?", path+"/%")
+	}
+	var entries []*models.AuditLog
+	err := forTenant(ctx, r.db, tenantID).
+		Where(resources).
+		Where("created_at < ?", scope.Before).
+		Order("created_at DESC").Limit(scope.Limit).Find(&entries).Error
+	return entries, err
+}
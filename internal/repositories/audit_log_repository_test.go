@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestAuditLogRepository_ListImpersonatedOnly(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAuditLogRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `audit_logs` WHERE impersonated = \\? AND `audit_logs`.`tenant_id` = \\? "+
+		"ORDER BY created_at DESC LIMIT \\?").
+		WithArgs(true, "tenant-1", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "action", "impersonated"}).
+			AddRow("log-1", "tenant-1", "POST /api/v1/videos", true))
+
+	entries, err := repo.List(context.Background(), "tenant-1", true, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.True(t, entries[0].Impersonated)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditLogRepository_CreateAssignsID(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAuditLogRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `audit_logs`").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	entry := &models.AuditLog{TenantID: "tenant-1", Action: models.AuditActionImpersonationStart}
+	require.NoError(t, repo.Create(context.Background(), entry))
+	assert.NotEmpty(t, entry.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditLogRepository_ListActivityMatchesPaths(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewAuditLogRepository(gormDB)
+	before := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT \\* FROM `audit_logs` WHERE \\(resource IN \\(\\?,\\?\\) OR resource LIKE \\? OR resource LIKE \\?\\) "+
+		"AND created_at < \\? AND `audit_logs`.`tenant_id` = \\? ORDER BY created_at DESC LIMIT \\?").
+		WithArgs("/api/v1/videos/video-1", "/api/v1/campaigns/campaign-1", "/api/v1/videos/video-1/%", "/api/v1/campaigns/campaign-1/%", before, "tenant-1", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "action"}).
+			AddRow("log-1", "tenant-1", "POST /api/v1/videos/:id/publish"))
+
+	entries, err := repo.ListActivity(context.Background(), "tenant-1", &models.ActivityScope{
+		VideoIDs:   []string{"video-1"},
+		CampaignID: "campaign-1",
+		Before:     before,
+		Limit:      50,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// blackoutWindowRepository implements models.BlackoutWindowRepository.
+type blackoutWindowRepository struct {
+	db *gorm.DB
+}
+
+var _ models.BlackoutWindowRepository = (*blackoutWindowRepository)(nil)
+
+// NewBlackoutWindowRepository creates a new repository instance.
+func NewBlackoutWindowRepository(db *gorm.DB) models.BlackoutWindowRepository {
+	return &blackoutWindowRepository{db: db}
+}
+
+func (r *blackoutWindowRepository) Create(ctx context.Context, window *models.BlackoutWindow) error {
+	if window.ID == "" {
+		window.ID = id.New()
+	}
+	return forTenant(ctx, r.db, window.TenantID).Create(window).Error
+}
+
+func (r *blackoutWindowRepository) List(ctx context.Context, tenantID string) ([]*models.BlackoutWindow, error) {
+	var windows []*models.BlackoutWindow
+	err := forTenant(ctx, r.db, tenantID).Order("starts_at, id").Find(&windows).Error
+	return windows, err
+}
+
+func (r *blackoutWindowRepository) Delete(ctx context.Context, tenantID, id string) error {
+	res := forTenant(ctx, r.db, tenantID).Where("id = ?", id).Delete(&models.BlackoutWindow{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrBlackoutWindowNotFound
+	}
+	return nil
+}
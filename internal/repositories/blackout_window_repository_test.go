@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestBlackoutWindowRepository_List(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewBlackoutWindowRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `blackout_windows` WHERE `blackout_windows`.`tenant_id` = \\? ORDER BY starts_at, id").
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "name", "action"}).
+			AddRow("window-1", "acme", "Christmas", "hold"))
+
+	windows, err := repo.List(context.Background(), "acme")
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+	assert.Equal(t, "hold", windows[0].Action)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBlackoutWindowRepository_DeleteNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewBlackoutWindowRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `blackout_windows` WHERE id = \\? AND `blackout_windows`.`tenant_id` = \\?").
+		WithArgs("window-1", "globex").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := repo.Delete(context.Background(), "globex", "window-1")
+	assert.ErrorIs(t, err, models.ErrBlackoutWindowNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// brandProfileRepository implements models.BrandProfileRepository.
+type brandProfileRepository struct {
+	db *gorm.DB
+}
+
+var _ models.BrandProfileRepository = (*brandProfileRepository)(nil)
+
+// NewBrandProfileRepository creates a new repository instance.
+func NewBrandProfileRepository(db *gorm.DB) models.BrandProfileRepository {
+	return &brandProfileRepository{db: db}
+}
+
+func (r *brandProfileRepository) Get(ctx context.Context, tenantID string) (*models.BrandProfile, error) {
+	var profile models.BrandProfile
+	err := forTenant(ctx, r.db, tenantID).First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrBrandProfileNotFound
+	}
+	return &profile, err
+}
+
+func (r *brandProfileRepository) Upsert(ctx context.Context, profile *models.BrandProfile) error {
+	if profile.ID == "" {
+		profile.ID = id.New()
+	}
+	return forTenant(ctx, r.db, profile.TenantID).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"font", "text_color", "accent_color", "logo_url", "email_footer", "updated_by", "updated_at"}),
+	}).Create(profile).Error
+}
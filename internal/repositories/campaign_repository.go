@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// campaignRepository implements models.CampaignRepository.
+type campaignRepository struct {
+	db *gorm.DB
+}
+
+var _ models.CampaignRepository = (*campaignRepository)(nil)
+
+// NewCampaignRepository creates a new repository instance.
+func NewCampaignRepository(db *gorm.DB) models.CampaignRepository {
+	return &campaignRepository{db: db}
+}
+
+func (r *campaignRepository) Create(ctx context.Context, campaign *models.Campaign, events ...*models.CampaignEvent) error {
+	if campaign.ID == "" {
+		campaign.ID = id.New()
+	}
+	campaign.NextRunAt = nextRunAt(campaign)
+	return forTenant(ctx, r.db, campaign.TenantID).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(campaign).Error; err != nil {
+			return err
+		}
+		return appendCampaignEvents(tx, events)
+	})
+}
+
+func (r *campaignRepository) Get(ctx context.Context, tenantID, id string) (*models.Campaign, error) {
+	var campaign models.Campaign
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&campaign).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *campaignRepository) List(ctx context.Context, tenantID string, limit, offset int) ([]*models.Campaign, error) {
+	var campaigns []*models.Campaign
+	err := forTenant(ctx, r.db, tenantID).
+		Order("created_at DESC, id").Limit(limit).Offset(offset).Find(&campaigns).Error
+	return campaigns, err
+}
+
+// Update saves the campaign only if it is still at the version it was loaded
+// at, before the events were applied to it
+func (r *campaignRepository) Update(ctx context.Context, campaign *models.Campaign, events ...*models.CampaignEvent) error {
+	campaign.NextRunAt = nextRunAt(campaign)
+	loaded := campaign.Version - int64(len(events))
+	return forTenant(ctx, r.db, campaign.TenantID).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("version = ?", loaded).Select("*").Save(campaign)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrConflict
+		}
+		return appendCampaignEvents(tx, events)
+	})
+}
+
+func (r *campaignRepository) Delete(ctx context.Context, tenantID, id string, events ...*models.CampaignEvent) error {
+	return forTenant(ctx, r.db, tenantID).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ?", id).Delete(&models.Campaign{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrCampaignNotFound
+		}
+		return appendCampaignEvents(tx, events)
+	})
+}
+
+func (r *campaignRepository) ListEvents(ctx context.Context, tenantID, campaignID string, until int64) ([]*models.CampaignEvent, error) {
+	query := forTenant(ctx, r.db, tenantID).Where("campaign_id = ?", campaignID)
+	if until > 0 {
+		query = query.Where("sequence <= ?", until)
+	}
+	var events []*models.CampaignEvent
+	err := query.Order("sequence").Find(&events).Error
+	return events, err
+}
+
+func (r *campaignRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*models.Campaign, error) {
+	var campaigns []*models.Campaign
+	err := allTenants(ctx, r.db).
+		Where("status = ? AND next_run_at <= ?", models.CampaignStatusScheduled, before).
+		Order("next_run_at, id").Limit(limit).Find(&campaigns).Error
+	return campaigns, err
+}
+
+// appendCampaignEvents inserts the events; the unique sequence per campaign
+// keeps the history append-only
+func appendCampaignEvents(tx *gorm.DB, events []*models.CampaignEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	for _, event := range events {
+		if event.ID == "" {
+			event.ID = id.New()
+		}
+	}
+	return tx.Create(&events).Error
+}
+
+// nextRunAt is the schedule's next run, kept in its own column so due
+// campaigns are found without reading the schedule JSON
+func nextRunAt(campaign *models.Campaign) *time.Time {
+	if campaign.Schedule == nil {
+		return nil
+	}
+	return campaign.Schedule.NextRunAt
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestCampaignRepository_ListDue(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewCampaignRepository(gormDB)
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT \\* FROM `campaigns` WHERE status = \\? AND next_run_at <= \\? ORDER BY next_run_at, id LIMIT \\?").
+		WithArgs(models.CampaignStatusScheduled, now, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "status", "platforms", "schedule"}).
+			AddRow("campaign-1", "tenant-1", models.CampaignStatusScheduled, `["youtube"]`, `{"type":"daily","run_count":2}`))
+
+	due, err := repo.ListDue(context.Background(), now, 100)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, []string{"youtube"}, due[0].Platforms)
+	require.NotNil(t, due[0].Schedule)
+	assert.Equal(t, 2, due[0].Schedule.RunCount)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCampaignRepository_GetNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewCampaignRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `campaigns` WHERE id = \\? AND `campaigns`.`tenant_id` = \\?").
+		WithArgs("campaign-1", "tenant-2", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.Get(context.Background(), "tenant-2", "campaign-1")
+	assert.ErrorIs(t, err, models.ErrCampaignNotFound, "campaigns of other tenants are not found")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCampaignRepository_DeleteNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewCampaignRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `campaigns` WHERE id = \\? AND `campaigns`.`tenant_id` = \\?").
+		WithArgs("campaign-1", "tenant-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.Delete(context.Background(), "tenant-1", "campaign-1")
+	assert.ErrorIs(t, err, models.ErrCampaignNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCampaignRepository_UpdateConflict(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewCampaignRepository(gormDB)
+	campaign := &models.Campaign{ID: "campaign-1", TenantID: "tenant-1", Status: models.CampaignStatusPaused, Version: 5}
+	event := &models.CampaignEvent{TenantID: "tenant-1", CampaignID: "campaign-1", Sequence: 5, Type: models.CampaignEventPaused}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `campaigns` SET .* WHERE version = \\? AND `campaigns`.`tenant_id` = \\? AND `id` = \\?").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.Update(context.Background(), campaign, event)
+	assert.ErrorIs(t, err, models.ErrConflict, "a campaign changed since it was loaded is not overwritten")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCampaignRepository_ListEvents(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewCampaignRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `campaign_events` WHERE campaign_id = \\? AND sequence <= \\? AND `campaign_events`.`tenant_id` = \\? ORDER BY sequence").
+		WithArgs("campaign-1", 2, "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "campaign_id", "sequence", "type", "data"}).
+			AddRow("event-1", "campaign-1", 1, models.CampaignEventCreated, `{"campaign":{"name":"Launch"}}`).
+			AddRow("event-2", "campaign-1", 2, models.CampaignEventStepCompleted, `{"step":"research","cost":0.5}`))
+
+	events, err := repo.ListEvents(context.Background(), "tenant-1", "campaign-1", 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "Launch", events[0].Data.Campaign.Name)
+	assert.Equal(t, models.CampaignStepResearch, events[1].Data.Step)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+type conversationRepository struct {
+	db *gorm.DB
+}
+
+var _ models.ConversationRepository = (*conversationRepository)(nil)
+
+// NewConversationRepository creates a conversation repository.
+func NewConversationRepository(db *gorm.DB) models.ConversationRepository {
+	return &conversationRepository{db: db}
+}
+
+func (r *conversationRepository) Create(ctx context.Context, c *models.Conversation) error {
+	if c.ID == "" {
+		c.ID = id.New()
+	}
+	for _, m := range c.Messages {
+		if m.ID == "" {
+			m.ID = id.New()
+		}
+		m.ConversationID = c.ID
+	}
+	return forTenant(ctx, r.db, c.TenantID).Create(c).Error
+}
+
+func (r *conversationRepository) GetByID(ctx context.Context, tenantID, userID, id string) (*models.Conversation, error) {
+	var c models.Conversation
+	err := forTenant(ctx, r.db, tenantID).Preload("Messages", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Where("user_id = ? AND id = ?", userID, id).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrConversationNotFound
+	}
+	return &c, err
+}
+
+func (r *conversationRepository) ListByUser(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.Conversation, error) {
+	var conversations []*models.Conversation
+	err := forTenant(ctx, r.db, tenantID).Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("updated_at DESC").Limit(limit).Offset(offset).Find(&conversations).Error
+	return conversations, err
+}
+
+// AppendMessages stores new messages and extends the conversation TTL atomically.
+func (r *conversationRepository) AppendMessages(ctx context.Context, tenantID, conversationID string, messages []*models.ConversationMessage, expiresAt time.Time) error {
+	return forTenant(ctx, r.db, tenantID).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Conversation{}).
+			Where("id = ?", conversationID).
+			Updates(map[string]interface{}{"expires_at": expiresAt, "updated_at": time.Now()})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrConversationNotFound
+		}
+
+		for _, m := range messages {
+			if m.ID == "" {
+				m.ID = id.New()
+			}
+			m.ConversationID = conversationID
+		}
+		return tx.Create(&messages).Error
+	})
+}
+
+func (r *conversationRepository) Delete(ctx context.Context, tenantID, userID, id string) error {
+	return forTenant(ctx, r.db, tenantID).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("user_id = ? AND id = ?", userID, id).Delete(&models.Conversation{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrConversationNotFound
+		}
+		return tx.Where("conversation_id = ?", id).Delete(&models.ConversationMessage{}).Error
+	})
+}
+
+// DeleteExpired removes conversations whose TTL elapsed before the given time.
+func (r *conversationRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := allTenants(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Model(&models.Conversation{}).Select("id").Where("expires_at < ?", before)
+		if err := tx.Where("conversation_id IN (?)", expired).Delete(&models.ConversationMessage{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("expires_at < ?", before).Delete(&models.Conversation{})
+		deleted = res.RowsAffected
+		return res.Error
+	})
+	return deleted, err
+}
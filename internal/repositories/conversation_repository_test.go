@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestConversationRepository_GetByIDOrdersMessages(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewConversationRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `conversations` WHERE \\(user_id = \\? AND id = \\?\\) AND `conversations`.`tenant_id` = \\?").
+		WithArgs("user-1", "conversation-1", "tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "user_id"}).AddRow("conversation-1", "tenant-1", "user-1"))
+	mock.ExpectQuery("SELECT \\* FROM `conversation_messages` WHERE `conversation_messages`.`conversation_id` = \\? ORDER BY created_at ASC").
+		WithArgs("conversation-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "conversation_id", "role", "content"}).
+			AddRow("message-1", "conversation-1", "system", "Be brief").
+			AddRow("message-2", "conversation-1", "user", "Hello"))
+
+	conversation, err := repo.GetByID(context.Background(), "tenant-1", "user-1", "conversation-1")
+	require.NoError(t, err)
+	require.Len(t, conversation.Messages, 2)
+	assert.Equal(t, "system", conversation.Messages[0].Role)
+	assert.Equal(t, "user", conversation.Messages[1].Role)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConversationRepository_GetByIDOtherOwner(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewConversationRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `conversations` WHERE \\(user_id = \\? AND id = \\?\\) AND `conversations`.`tenant_id` = \\?").
+		WithArgs("user-2", "conversation-1", "tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetByID(context.Background(), "tenant-1", "user-2", "conversation-1")
+	assert.ErrorIs(t, err, models.ErrConversationNotFound, "conversations of other users are not found")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConversationRepository_AppendMessages(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewConversationRepository(gormDB)
+	expiresAt := time.Date(2026, 10, 15, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `conversations` SET `expires_at`=\\?,`updated_at`=\\? WHERE id = \\? AND `conversations`.`tenant_id` = \\?").
+		WithArgs(expiresAt, sqlmock.AnyArg(), "conversation-1", "tenant-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `conversation_messages`").
+		WithArgs(
+			sqlmock.AnyArg(), "conversation-1", "user", "Hello", 10, sqlmock.AnyArg(),
+			sqlmock.AnyArg(), "conversation-1", "assistant", "Hi", 5, sqlmock.AnyArg(),
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	messages := []*models.ConversationMessage{
+		{Role: "user", Content: "Hello", TokensUsed: 10},
+		{Role: "assistant", Content: "Hi", TokensUsed: 5},
+	}
+	require.NoError(t, repo.AppendMessages(context.Background(), "tenant-1", "conversation-1", messages, expiresAt))
+	for _, m := range messages {
+		assert.NotEmpty(t, m.ID)
+		assert.Equal(t, "conversation-1", m.ConversationID)
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConversationRepository_AppendMessagesNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewConversationRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `conversations` SET `expires_at`=\\?,`updated_at`=\\? WHERE id = \\? AND `conversations`.`tenant_id` = \\?").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "conversation-1", "tenant-2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.AppendMessages(context.Background(), "tenant-2", "conversation-1", []*models.ConversationMessage{{Role: "user", Content: "Hello"}}, time.Now())
+	assert.ErrorIs(t, err, models.ErrConversationNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConversationRepository_DeleteExpired(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewConversationRepository(gormDB)
+	before := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `conversation_messages` WHERE conversation_id IN \\(SELECT `id` FROM `conversations` WHERE expires_at < \\?\\)").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec("DELETE FROM `conversations` WHERE expires_at < \\?").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	deleted, err := repo.DeleteExpired(context.Background(), before)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// debugCaptureRepository implements models.DebugCaptureRepository.
+type debugCaptureRepository struct {
+	db *gorm.DB
+}
+
+var _ models.DebugCaptureRepository = (*debugCaptureRepository)(nil)
+
+// NewDebugCaptureRepository creates a new repository instance.
+func NewDebugCaptureRepository(db *gorm.DB) models.DebugCaptureRepository {
+	return &debugCaptureRepository{db: db}
+}
+
+// captureSummaryColumns are the capture columns shown in listings; the
+// encrypted headers and bodies are only read for a single capture
+var captureSummaryColumns = []string{
+	"id", "tenant_id", "session_id", "user_id", "request_id", "method", "route", "path",
+	"status", "duration_ms", "request_body_truncated", "response_body_truncated", "expires_at", "created_at",
+}
+
+func (r *debugCaptureRepository) CreateSession(ctx context.Context, session *models.DebugCaptureSession) error {
+	if session.ID == "" {
+		session.ID = id.New()
+	}
+	return forTenant(ctx, r.db, session.TenantID).Create(session).Error
+}
+
+func (r *debugCaptureRepository) GetActiveSession(ctx context.Context, tenantID string, at time.Time) (*models.DebugCaptureSession, error) {
+	var session models.DebugCaptureSession
+	err := forTenant(ctx, r.db, tenantID).
+		Where("expires_at > ? AND ended_at IS NULL", at).
+		Order("expires_at DESC").
+		First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrDebugCaptureInactive
+	}
+	return &session, err
+}
+
+func (r *debugCaptureRepository) EndSessions(ctx context.Context, tenantID string, at time.Time) error {
+	return forTenant(ctx, r.db, tenantID).Model(&models.DebugCaptureSession{}).
+		Where("expires_at > ? AND ended_at IS NULL", at).
+		Update("ended_at", at).Error
+}
+
+func (r *debugCaptureRepository) Create(ctx context.Context, capture *models.DebugCapture) error {
+	if capture.ID == "" {
+		capture.ID = id.New()
+	}
+	return forTenant(ctx, r.db, capture.TenantID).Create(capture).Error
+}
+
+func (r *debugCaptureRepository) List(ctx context.Context, tenantID, sessionID string, limit, offset int) ([]*models.DebugCapture, error) {
+	query := forTenant(ctx, r.db, tenantID).Select(captureSummaryColumns)
+	if sessionID != "" {
+		query = query.Where("session_id = ?", sessionID)
+	}
+
+	var captures []*models.DebugCapture
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&captures).Error
+	return captures, err
+}
+
+func (r *debugCaptureRepository) GetByID(ctx context.Context, tenantID, id string) (*models.DebugCapture, error) {
+	var capture models.DebugCapture
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&capture).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrDebugCaptureNotFound
+	}
+	return &capture, err
+}
+
+// DeleteExpired removes expired captures, then the sessions past sessionsBefore.
+func (r *debugCaptureRepository) DeleteExpired(ctx context.Context, before, sessionsBefore time.Time) (int64, error) {
+	var deleted int64
+	err := allTenants(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("expires_at < ?", before).Delete(&models.DebugCapture{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted = res.RowsAffected
+		return tx.Where("expires_at < ?", sessionsBefore).Delete(&models.DebugCaptureSession{}).Error
+	})
+	return deleted, err
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestDebugCaptureRepository_GetActiveSession(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewDebugCaptureRepository(gormDB)
+	now := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT \\* FROM `debug_capture_sessions` WHERE \\(expires_at > \\? AND ended_at IS NULL\\) "+
+		"AND `debug_capture_sessions`.`tenant_id` = \\? ORDER BY expires_at DESC").
+		WithArgs(now, "tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "expires_at"}).
+			AddRow("session-1", "tenant-1", now.Add(time.Hour)))
+
+	session, err := repo.GetActiveSession(context.Background(), "tenant-1", now)
+	require.NoError(t, err)
+	assert.Equal(t, "session-1", session.ID)
+
+	mock.ExpectQuery("SELECT \\* FROM `debug_capture_sessions`").
+		WithArgs(now, "tenant-2", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = repo.GetActiveSession(context.Background(), "tenant-2", now)
+	assert.ErrorIs(t, err, models.ErrDebugCaptureInactive)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDebugCaptureRepository_ListLeavesOutBodies(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewDebugCaptureRepository(gormDB)
+
+	mock.ExpectQuery("SELECT `id`,`tenant_id`,`session_id`,.*`created_at` FROM `debug_captures` WHERE session_id = \\? "+
+		"AND `debug_captures`.`tenant_id` = \\? ORDER BY created_at DESC LIMIT \\?").
+		WithArgs("session-1", "tenant-1", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "session_id", "method", "path", "status"}).
+			AddRow("capture-1", "tenant-1", "session-1", "POST", "/api/v1/videos", 500))
+
+	captures, err := repo.List(context.Background(), "tenant-1", "session-1", 20, 0)
+	require.NoError(t, err)
+	require.Len(t, captures, 1)
+	assert.Equal(t, 500, captures[0].Status)
+	assert.Empty(t, captures[0].RequestBody)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDebugCaptureRepository_DeleteExpired(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewDebugCaptureRepository(gormDB)
+	now := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	sessionsBefore := now.Add(-7 * 24 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `debug_captures` WHERE expires_at < \\?").
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 12))
+	mock.ExpectExec("DELETE FROM `debug_capture_sessions` WHERE expires_at < \\?").
+		WithArgs(sessionsBefore).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	deleted, err := repo.DeleteExpired(context.Background(), now, sessionsBefore)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), deleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
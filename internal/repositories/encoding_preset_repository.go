@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// encodingPresetRepository implements models.EncodingPresetRepository.
+type encodingPresetRepository struct {
+	db *gorm.DB
+}
+
+var _ models.EncodingPresetRepository = (*encodingPresetRepository)(nil)
+
+// NewEncodingPresetRepository creates a new repository instance.
+func NewEncodingPresetRepository(db *gorm.DB) models.EncodingPresetRepository {
+	return &encodingPresetRepository{db: db}
+}
+
+func (r *encodingPresetRepository) Get(ctx context.Context, tenantID, profile string) (*models.EncodingPreset, error) {
+	var preset models.EncodingPreset
+	err := forTenant(ctx, r.db, tenantID).Where("profile = ?", profile).First(&preset).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrEncodingPresetNotFound
+	}
+	return &preset, err
+}
+
+func (r *encodingPresetRepository) List(ctx context.Context, tenantID string) ([]*models.EncodingPreset, error) {
+	var presets []*models.EncodingPreset
+	err := forTenant(ctx, r.db, tenantID).Order("profile").Find(&presets).Error
+	return presets, err
+}
+
+func (r *encodingPresetRepository) Upsert(ctx context.Context, preset *models.EncodingPreset) error {
+	if preset.ID == "" {
+		preset.ID = id.New()
+	}
+	return forTenant(ctx, r.db, preset.TenantID).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant_id"}, {Name: "profile"}},
+		DoUpdates: clause.AssignmentColumns([]string{"width", "height", "video_bitrate_kbps", "audio_bitrate_kbps", "frame_rate",
+			"video_codec", "audio_codec", "loudness_lufs", "updated_by", "updated_at"}),
+	}).Create(preset).Error
+}
+
+func (r *encodingPresetRepository) Delete(ctx context.Context, tenantID, profile string) error {
+	res := forTenant(ctx, r.db, tenantID).Where("profile = ?", profile).Delete(&models.EncodingPreset{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrEncodingPresetNotFound
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestEncodingPresetRepository_Get(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewEncodingPresetRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `encoding_presets` WHERE profile = \\? AND `encoding_presets`.`tenant_id` = \\?").
+		WithArgs("vertical_1080p", "acme", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "profile", "video_codec", "loudness_lufs"}).
+			AddRow("preset-1", "acme", "vertical_1080p", "hevc", -14.0))
+	mock.ExpectQuery("SELECT \\* FROM `encoding_presets` WHERE profile = \\? AND `encoding_presets`.`tenant_id` = \\?").
+		WithArgs("landscape_720p", "acme", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	preset, err := repo.Get(context.Background(), "acme", "vertical_1080p")
+	require.NoError(t, err)
+	assert.Equal(t, "hevc", preset.VideoCodec)
+	assert.Equal(t, -14.0, preset.LoudnessLUFS)
+
+	_, err = repo.Get(context.Background(), "acme", "landscape_720p")
+	assert.ErrorIs(t, err, models.ErrEncodingPresetNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEncodingPresetRepository_Upsert(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewEncodingPresetRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `encoding_presets` .* ON DUPLICATE KEY UPDATE `width`=VALUES\\(`width`\\)").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	preset := &models.EncodingPreset{TenantID: "acme", Profile: "vertical_1080p", Width: 1080, Height: 1920, VideoCodec: "h264", AudioCodec: "aac"}
+	require.NoError(t, repo.Upsert(context.Background(), preset))
+	assert.NotEmpty(t, preset.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEncodingPresetRepository_DeleteNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewEncodingPresetRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `encoding_presets` WHERE profile = \\? AND `encoding_presets`.`tenant_id` = \\?").
+		WithArgs("vertical_1080p", "globex").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := repo.Delete(context.Background(), "globex", "vertical_1080p")
+	assert.ErrorIs(t, err, models.ErrEncodingPresetNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// jobRepository implements models.JobRepository.
+type jobRepository struct {
+	db *gorm.DB
+}
+
+var _ models.JobRepository = (*jobRepository)(nil)
+
+// NewJobRepository creates a new repository instance.
+func NewJobRepository(db *gorm.DB) models.JobRepository {
+	return &jobRepository{db: db}
+}
+
+// unfinishedJobStates are the states of jobs still at work
+var unfinishedJobStates = []models.JobState{models.JobQueued, models.JobRunning}
+
+func (r *jobRepository) Create(ctx context.Context, job *models.Job) error {
+	if job.ID == "" {
+		job.ID = id.New()
+	}
+	return forTenant(ctx, r.db, job.TenantID).Create(job).Error
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, tenantID, id string) (*models.Job, error) {
+	var job models.Job
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrJobNotFound
+	}
+	return &job, err
+}
+
+func (r *jobRepository) GetUnfinished(ctx context.Context, tenantID string, jobType models.JobType, resourceID string) (*models.Job, error) {
+	var job models.Job
+	err := forTenant(ctx, r.db, tenantID).
+		Where("type = ? AND resource_id = ? AND state IN ?", jobType, resourceID, unfinishedJobStates).
+		Order("created_at DESC").First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrJobNotFound
+	}
+	return &job, err
+}
+
+func (r *jobRepository) Update(ctx context.Context, job *models.Job) error {
+	return saveForTenant(ctx, r.db, job.TenantID, job)
+}
+
+func (r *jobRepository) List(ctx context.Context, tenantID string, filter models.JobFilter, limit, offset int) ([]*models.Job, error) {
+	query := forTenant(ctx, r.db, tenantID)
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.State != "" {
+		query = query.Where("state = ?", filter.State)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	var jobs []*models.Job
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *jobRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	res := allTenants(ctx, r.db).Where("finished_at < ?", before).Delete(&models.Job{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestJobRepository_GetUnfinished(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewJobRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `jobs` WHERE \\(type = \\? AND resource_id = \\? AND state IN \\(\\?,\\?\\)\\) "+
+		"AND `jobs`.`tenant_id` = \\? ORDER BY created_at DESC,`jobs`.`id` LIMIT \\?").
+		WithArgs(models.JobTranscode, "video-1", models.JobQueued, models.JobRunning, "tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetUnfinished(context.Background(), "tenant-1", models.JobTranscode, "video-1")
+	assert.ErrorIs(t, err, models.ErrJobNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_ListFilters(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewJobRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `jobs` WHERE type = \\? AND state = \\? AND `jobs`.`tenant_id` = \\? "+
+		"ORDER BY created_at DESC LIMIT \\?$").
+		WithArgs(models.JobPublish, models.JobFailed, "tenant-1", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "links"}).AddRow("job-1", `{"video":"/api/v1/videos/v-1"}`))
+
+	jobs, err := repo.List(context.Background(), "tenant-1", models.JobFilter{Type: models.JobPublish, State: models.JobFailed}, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "/api/v1/videos/v-1", jobs[0].Links["video"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
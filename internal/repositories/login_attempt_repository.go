@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// loginAttemptRepository implements models.LoginAttemptRepository.
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+var _ models.LoginAttemptRepository = (*loginAttemptRepository)(nil)
+
+// NewLoginAttemptRepository creates a new repository instance.
+func NewLoginAttemptRepository(db *gorm.DB) models.LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+// grantedOutcomes are the outcomes of attempts that signed the user in
+var grantedOutcomes = []models.LoginOutcome{models.LoginSucceeded, models.LoginVerified}
+
+func (r *loginAttemptRepository) Create(ctx context.Context, attempt *models.LoginAttempt) error {
+	if attempt.ID == "" {
+		attempt.ID = id.New()
+	}
+	return forTenant(ctx, r.db, attempt.TenantID).Create(attempt).Error
+}
+
+func (r *loginAttemptRepository) List(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.LoginAttempt, error) {
+	var attempts []*models.LoginAttempt
+	err := forTenant(ctx, r.db, tenantID).Where("user_id = ?", userID).
+		Order("created_at DESC").Limit(limit).Offset(offset).Find(&attempts).Error
+	return attempts, err
+}
+
+func (r *loginAttemptRepository) Footprint(ctx context.Context, tenantID, userID string) (*models.LoginFootprint, error) {
+	granted := func() *gorm.DB {
+		return forTenant(ctx, r.db, tenantID).Model(&models.LoginAttempt{}).
+			Where("user_id = ? AND outcome IN ?", userID, grantedOutcomes)
+	}
+
+	var footprint models.LoginFootprint
+	if err := granted().Distinct().Pluck("device_id", &footprint.Devices).Error; err != nil {
+		return nil, err
+	}
+	if err := granted().Where("country <> ''").Distinct().Pluck("country", &footprint.Countries).Error; err != nil {
+		return nil, err
+	}
+	var last models.LoginAttempt
+	err := granted().Order("created_at DESC").First(&last).Error
+	switch {
+	case err == nil:
+		footprint.LastSucceededAt = &last.CreatedAt
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+	return &footprint, nil
+}
+
+func (r *loginAttemptRepository) CountFailed(ctx context.Context, tenantID, userID string, since time.Time) (int64, error) {
+	var count int64
+	err := forTenant(ctx, r.db, tenantID).Model(&models.LoginAttempt{}).
+		Where("user_id = ? AND outcome = ? AND created_at > ?", userID, models.LoginFailed, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *loginAttemptRepository) CreateChallenge(ctx context.Context, challenge *models.LoginChallenge) error {
+	if challenge.ID == "" {
+		challenge.ID = id.New()
+	}
+	return forTenant(ctx, r.db, challenge.TenantID).Create(challenge).Error
+}
+
+func (r *loginAttemptRepository) GetChallenge(ctx context.Context, id string) (*models.LoginChallenge, error) {
+	var challenge models.LoginChallenge
+	err := allTenants(ctx, r.db).Where("id = ?", id).First(&challenge).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrLoginChallengeInvalid
+	}
+	return &challenge, err
+}
+
+func (r *loginAttemptRepository) UpdateChallenge(ctx context.Context, challenge *models.LoginChallenge) error {
+	return saveForTenant(ctx, r.db, challenge.TenantID, challenge)
+}
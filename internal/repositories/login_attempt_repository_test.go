@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestLoginAttemptRepository_FootprintOfGrantedLogins(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewLoginAttemptRepository(gormDB)
+	last := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT DISTINCT `device_id` FROM `login_attempts` WHERE \\(user_id = \\? AND outcome IN \\(\\?,\\?\\)\\) "+
+		"AND `login_attempts`.`tenant_id` = \\?").
+		WithArgs("user-1", models.LoginSucceeded, models.LoginVerified, "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"device_id"}).AddRow("device-1").AddRow("device-2"))
+	mock.ExpectQuery("SELECT DISTINCT `country` FROM `login_attempts` WHERE \\(user_id = \\? AND outcome IN \\(\\?,\\?\\)\\) "+
+		"AND country <> '' AND `login_attempts`.`tenant_id` = \\?").
+		WithArgs("user-1", models.LoginSucceeded, models.LoginVerified, "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"country"}).AddRow("FR"))
+	mock.ExpectQuery("SELECT \\* FROM `login_attempts` WHERE \\(user_id = \\? AND outcome IN \\(\\?,\\?\\)\\) "+
+		"AND `login_attempts`.`tenant_id` = \\? ORDER BY created_at DESC,`login_attempts`.`id` LIMIT \\?").
+		WithArgs("user-1", models.LoginSucceeded, models.LoginVerified, "tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("attempt-1", last))
+
+	footprint, err := repo.Footprint(context.Background(), "tenant-1", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"device-1", "device-2"}, footprint.Devices)
+	assert.Equal(t, []string{"FR"}, footprint.Countries)
+	require.NotNil(t, footprint.LastSucceededAt)
+	assert.True(t, last.Equal(*footprint.LastSucceededAt))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttemptRepository_CountFailed(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewLoginAttemptRepository(gormDB)
+	since := time.Date(2026, 10, 15, 9, 45, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `login_attempts` WHERE \\(user_id = \\? AND outcome = \\? AND created_at > \\?\\) "+
+		"AND `login_attempts`.`tenant_id` = \\?").
+		WithArgs("user-1", models.LoginFailed, since, "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	count, err := repo.CountFailed(context.Background(), "tenant-1", "user-1", since)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttemptRepository_GetChallengeSpansTenants(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewLoginAttemptRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `login_challenges` WHERE id = \\? ORDER BY `login_challenges`.`id` LIMIT \\?$").
+		WithArgs("challenge-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetChallenge(context.Background(), "challenge-1")
+	assert.ErrorIs(t, err, models.ErrLoginChallengeInvalid)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
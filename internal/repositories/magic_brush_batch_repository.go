@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// magicBrushBatchRepository implements models.MagicBrushBatchRepository.
+type magicBrushBatchRepository struct {
+	db *gorm.DB
+}
+
+var _ models.MagicBrushBatchRepository = (*magicBrushBatchRepository)(nil)
+
+// NewMagicBrushBatchRepository creates a new repository instance.
+func NewMagicBrushBatchRepository(db *gorm.DB) models.MagicBrushBatchRepository {
+	return &magicBrushBatchRepository{db: db}
+}
+
+func (r *magicBrushBatchRepository) Create(ctx context.Context, batch *models.MagicBrushBatch, results []*models.MagicBrushResult) error {
+	if batch.ID == "" {
+		batch.ID = id.New()
+	}
+	for _, result := range results {
+		if result.ID == "" {
+			result.ID = id.New()
+		}
+		result.BatchID = batch.ID
+	}
+	return forTenant(ctx, r.db, batch.TenantID).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			return nil
+		}
+		return tx.Create(results).Error
+	})
+}
+
+func (r *magicBrushBatchRepository) Get(ctx context.Context, tenantID, id string) (*models.MagicBrushBatch, error) {
+	var batch models.MagicBrushBatch
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&batch).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrMagicBrushBatchNotFound
+	}
+	return &batch, err
+}
+
+func (r *magicBrushBatchRepository) ListUnfinished(ctx context.Context, limit int) ([]*models.MagicBrushBatch, error) {
+	var batches []*models.MagicBrushBatch
+	err := allTenants(ctx, r.db).
+		Where("status <> ?", models.MagicBrushBatchCompleted).
+		Order("created_at").
+		Limit(limit).
+		Find(&batches).Error
+	return batches, err
+}
+
+func (r *magicBrushBatchRepository) Update(ctx context.Context, batch *models.MagicBrushBatch) error {
+	return saveForTenant(ctx, r.db, batch.TenantID, batch)
+}
+
+func (r *magicBrushBatchRepository) ListResults(ctx context.Context, tenantID, batchID string) ([]*models.MagicBrushResult, error) {
+	var results []*models.MagicBrushResult
+	err := forTenant(ctx, r.db, tenantID).Where("batch_id = ?", batchID).Order("position").Find(&results).Error
+	return results, err
+}
+
+func (r *magicBrushBatchRepository) UpdateResult(ctx context.Context, result *models.MagicBrushResult) error {
+	return saveForTenant(ctx, r.db, result.TenantID, result)
+}
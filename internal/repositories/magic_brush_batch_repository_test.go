@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestMagicBrushBatchRepository_Create(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewMagicBrushBatchRepository(gormDB)
+
+	batch := &models.MagicBrushBatch{TenantID: "acme", BrushType: "title", Status: models.MagicBrushBatchQueued, VideosTotal: 2}
+	results := []*models.MagicBrushResult{
+		{TenantID: "acme", VideoID: "video-1", Status: string(models.JobQueued)},
+		{TenantID: "acme", VideoID: "video-2", Status: string(models.JobQueued), Position: 1},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `magic_brush_batches`").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `magic_brush_results`").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Create(context.Background(), batch, results))
+	require.NoError(t, mock.ExpectationsWereMet())
+	assert.NotEmpty(t, batch.ID)
+	for _, result := range results {
+		assert.NotEmpty(t, result.ID)
+		assert.Equal(t, batch.ID, result.BatchID)
+	}
+}
+
+func TestMagicBrushBatchRepository_GetNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewMagicBrushBatchRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `magic_brush_batches` WHERE id = \\? AND `magic_brush_batches`.`tenant_id` = \\?").
+		WithArgs("batch-9", "acme", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.Get(context.Background(), "acme", "batch-9")
+	assert.ErrorIs(t, err, models.ErrMagicBrushBatchNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// platformQuotaRepository implements models.PlatformQuotaRepository.
+type platformQuotaRepository struct {
+	db *gorm.DB
+}
+
+var _ models.PlatformQuotaRepository = (*platformQuotaRepository)(nil)
+
+// NewPlatformQuotaRepository creates a new repository instance.
+func NewPlatformQuotaRepository(db *gorm.DB) models.PlatformQuotaRepository {
+	return &platformQuotaRepository{db: db}
+}
+
+// Reserve creates the day's row if needed, then adds the units with a single
+// conditional update so concurrent reservations cannot overshoot the ceiling
+func (r *platformQuotaRepository) Reserve(ctx context.Context, tenantID, platform string, day time.Time, units, ceiling int64) (bool, error) {
+	if err := r.ensureDay(ctx, tenantID, platform, day); err != nil {
+		return false, err
+	}
+	result := forTenant(ctx, r.db, tenantID).Model(&models.PlatformQuotaUsage{}).
+		Where("platform = ? AND day = ? AND exhausted = ? AND units + ? <= ?", platform, day, false, units, ceiling).
+		Update("units", gorm.Expr("units + ?", units))
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *platformQuotaRepository) Exhaust(ctx context.Context, tenantID, platform string, day time.Time) error {
+	if err := r.ensureDay(ctx, tenantID, platform, day); err != nil {
+		return err
+	}
+	return forTenant(ctx, r.db, tenantID).Model(&models.PlatformQuotaUsage{}).
+		Where("platform = ? AND day = ?", platform, day).
+		Update("exhausted", true).Error
+}
+
+func (r *platformQuotaRepository) Get(ctx context.Context, tenantID, platform string, day time.Time) (*models.PlatformQuotaUsage, error) {
+	var usage models.PlatformQuotaUsage
+	err := forTenant(ctx, r.db, tenantID).Where("platform = ? AND day = ?", platform, day).First(&usage).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrNotFound
+	}
+	return &usage, err
+}
+
+// ensureDay inserts an empty usage row for the day, keeping an existing one
+func (r *platformQuotaRepository) ensureDay(ctx context.Context, tenantID, platform string, day time.Time) error {
+	usage := &models.PlatformQuotaUsage{ID: id.New(), TenantID: tenantID, Platform: platform, Day: day}
+	return forTenant(ctx, r.db, tenantID).Clauses(clause.OnConflict{DoNothing: true}).Create(usage).Error
+}
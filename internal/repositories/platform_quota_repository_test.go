@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlatformQuotaRepository_Reserve(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewPlatformQuotaRepository(gormDB)
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	for _, affected := range []int64{1, 0} {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO `platform_quota_usage` .* ON DUPLICATE KEY UPDATE").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE `platform_quota_usage` SET `units`=units \\+ \\?,`updated_at`=\\? "+
+			"WHERE \\(platform = \\? AND day = \\? AND exhausted = \\? AND units \\+ \\? <= \\?\\) AND `platform_quota_usage`.`tenant_id` = \\?").
+			WithArgs(int64(50), sqlmock.AnyArg(), "youtube", day, false, int64(50), int64(8000), "tenant-1").
+			WillReturnResult(sqlmock.NewResult(0, affected))
+		mock.ExpectCommit()
+	}
+
+	reserved, err := repo.Reserve(context.Background(), "tenant-1", "youtube", day, 50, 8000)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	// Nothing is updated once the ceiling would be exceeded
+	reserved, err = repo.Reserve(context.Background(), "tenant-1", "youtube", day, 50, 8000)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
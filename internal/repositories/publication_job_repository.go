@@ -1,13 +1,14 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"time"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
 )
 
 // publicationJobRepository implements models.PublicationJobRepository.
@@ -15,69 +16,135 @@ type publicationJobRepository struct {
 	db *gorm.DB
 }
 
+var _ models.PublicationJobRepository = (*publicationJobRepository)(nil)
+
 // NewPublicationJobRepository creates a new repository.
 func NewPublicationJobRepository(db *gorm.DB) models.PublicationJobRepository {
 	return &publicationJobRepository{db: db}
 }
 
-func (r *publicationJobRepository) Create(job *models.PublicationJob) error {
+func (r *publicationJobRepository) Create(ctx context.Context, job *models.PublicationJob) error {
 	if job.ID == "" {
-		job.ID = uuid.New().String()
+		job.ID = id.New()
 	}
-	return r.db.Create(job).Error
+	return forTenant(ctx, r.db, job.TenantID).Create(job).Error
 }
 
-func (r *publicationJobRepository) GetByID(tenantID, id string) (*models.PublicationJob, error) {
+func (r *publicationJobRepository) GetByID(ctx context.Context, tenantID, id string) (*models.PublicationJob, error) {
 	var job models.PublicationJob
-	err := r.db.Where("tenant_id = ? AND id = ?", tenantID, id).First(&job).Error
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&job).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, models.ErrPublicationNotFound
 	}
 	return &job, err
 }
 
-func (r *publicationJobRepository) GetByVideoID(tenantID, videoID string) ([]*models.PublicationJob, error) {
+func (r *publicationJobRepository) GetByVideoID(ctx context.Context, tenantID, videoID string) ([]*models.PublicationJob, error) {
+	var jobs []*models.PublicationJob
+	err := forTenant(ctx, r.db, tenantID).Where("video_id = ?", videoID).Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *publicationJobRepository) GetByWorkspace(ctx context.Context, tenantID, workspaceID string, platform models.Platform, statuses []models.PublicationStatus) ([]*models.PublicationJob, error) {
+	var jobs []*models.PublicationJob
+	err := forTenant(ctx, r.db, tenantID).
+		Where("workspace_id = ? AND platform = ? AND status IN ?", workspaceID, platform, statuses).
+		Order("scheduled_at").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// ListActivity reads the jobs created before the scope's cursor; a job's
+// later events are dropped from the page by the caller
+func (r *publicationJobRepository) ListActivity(ctx context.Context, tenantID string, scope *models.ActivityScope) ([]*models.PublicationJob, error) {
+	if len(scope.VideoIDs) == 0 {
+		return nil, nil
+	}
+	var jobs []*models.PublicationJob
+	err := forTenant(ctx, r.db, tenantID).
+		Where("video_id IN ? AND created_at < ?", scope.VideoIDs, scope.Before).
+		Order("updated_at DESC").Limit(scope.Limit).Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *publicationJobRepository) GetByStatus(ctx context.Context, tenantID string, status models.PublicationStatus, limit, offset int) ([]*models.PublicationJob, error) {
 	var jobs []*models.PublicationJob
-	err := r.db.Where("tenant_id = ? AND video_id = ?", tenantID, videoID).Find(&jobs).Error
+	err := forTenant(ctx, r.db, tenantID).Where("status = ?", status).Limit(limit).Offset(offset).Find(&jobs).Error
 	return jobs, err
 }
 
-func (r *publicationJobRepository) GetByStatus(tenantID string, status models.PublicationStatus, limit, offset int) ([]*models.PublicationJob, error) {
+func (r *publicationJobRepository) GetByPlatform(ctx context.Context, tenantID string, platform models.Platform, limit, offset int) ([]*models.PublicationJob, error) {
 	var jobs []*models.PublicationJob
-	err := r.db.Where("tenant_id = ? AND status = ?", tenantID, status).Limit(limit).Offset(offset).Find(&jobs).Error
+	err := forTenant(ctx, r.db, tenantID).Where("platform = ?", platform).Limit(limit).Offset(offset).Find(&jobs).Error
 	return jobs, err
 }
 
-func (r *publicationJobRepository) GetByPlatform(tenantID string, platform models.Platform, limit, offset int) ([]*models.PublicationJob, error) {
+func (r *publicationJobRepository) GetScheduledJobs(ctx context.Context, before time.Time, limit int) ([]*models.PublicationJob, error) {
 	var jobs []*models.PublicationJob
-	err := r.db.Where("tenant_id = ? AND platform = ?", tenantID, platform).Limit(limit).Offset(offset).Find(&jobs).Error
+	err := allTenants(ctx, r.db).Where("status = ? AND scheduled_at <= ?", models.PublicationScheduled, before).Limit(limit).Find(&jobs).Error
 	return jobs, err
 }
 
-func (r *publicationJobRepository) GetScheduledJobs(before time.Time, limit int) ([]*models.PublicationJob, error) {
+func (r *publicationJobRepository) GetStageableJobs(ctx context.Context, platform string, before time.Time, limit int) ([]*models.PublicationJob, error) {
 	var jobs []*models.PublicationJob
-	err := r.db.Where("status = ? AND scheduled_at <= ?", models.PublicationScheduled, before).Limit(limit).Find(&jobs).Error
+	err := allTenants(ctx, r.db).
+		Where("status = ? AND platform = ? AND scheduled_at <= ? AND retry_count < max_retries", models.PublicationScheduled, platform, before).
+		Order("scheduled_at").
+		Limit(limit).
+		Find(&jobs).Error
 	return jobs, err
 }
 
-func (r *publicationJobRepository) Update(job *models.PublicationJob) error {
-	return r.db.Save(job).Error
+func (r *publicationJobRepository) GetDueJobs(ctx context.Context, before time.Time, limit int) ([]*models.PublicationJob, error) {
+	var jobs []*models.PublicationJob
+	err := allTenants(ctx, r.db).
+		Where("status IN ? AND scheduled_at <= ?", []models.PublicationStatus{models.PublicationScheduled, models.PublicationStaged}, before).
+		Order("scheduled_at").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
 }
 
-func (r *publicationJobRepository) Delete(tenantID, id string) error {
-	return r.db.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&models.PublicationJob{}).Error
+func (r *publicationJobRepository) Update(ctx context.Context, job *models.PublicationJob) error {
+	return saveForTenant(ctx, r.db, job.TenantID, job)
 }
 
-func (r *publicationJobRepository) List(tenantID string, limit, offset int) ([]*models.PublicationJob, error) {
+func (r *publicationJobRepository) Delete(ctx context.Context, tenantID, id string) error {
+	return forTenant(ctx, r.db, tenantID).Where("id = ?", id).Delete(&models.PublicationJob{}).Error
+}
+
+func (r *publicationJobRepository) List(ctx context.Context, tenantID string, limit, offset int) ([]*models.PublicationJob, error) {
 	var jobs []*models.PublicationJob
-	err := r.db.Where("tenant_id = ?", tenantID).Limit(limit).Offset(offset).Find(&jobs).Error
+	err := forTenant(ctx, r.db, tenantID).Limit(limit).Offset(offset).Find(&jobs).Error
 	return jobs, err
 }
 
-func (r *publicationJobRepository) UpdateStatus(tenantID, id string, status models.PublicationStatus) error {
-	return r.db.Model(&models.PublicationJob{}).Where("tenant_id = ? AND id = ?", tenantID, id).Update("status", status).Error
+func (r *publicationJobRepository) UpdateStatus(ctx context.Context, tenantID, id string, status models.PublicationStatus) error {
+	return forTenant(ctx, r.db, tenantID).Model(&models.PublicationJob{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (r *publicationJobRepository) IncrementRetryCount(ctx context.Context, tenantID, id string) error {
+	return forTenant(ctx, r.db, tenantID).Model(&models.PublicationJob{}).Where("id = ?", id).UpdateColumn("retry_count", gorm.Expr("retry_count + 1")).Error
+}
+
+func (r *publicationJobRepository) CountFailedByPlatform(ctx context.Context, since time.Time) ([]*models.PlatformCount, error) {
+	var counts []*models.PlatformCount
+	err := allTenants(ctx, r.db).Model(&models.PublicationJob{}).
+		Select("platform, COUNT(*) AS count").
+		Where("status = ? AND updated_at >= ?", models.PublicationFailed, since).
+		Group("platform").
+		Order("count DESC").
+		Scan(&counts).Error
+	return counts, err
 }
 
-func (r *publicationJobRepository) IncrementRetryCount(tenantID, id string) error {
-	return r.db.Model(&models.PublicationJob{}).Where("tenant_id = ? AND id = ?", tenantID, id).UpdateColumn("retry_count", gorm.Expr("retry_count + 1")).Error
+func (r *publicationJobRepository) CountExhaustedByPlatform(ctx context.Context) ([]*models.PlatformCount, error) {
+	var counts []*models.PlatformCount
+	err := allTenants(ctx, r.db).Model(&models.PublicationJob{}).
+		Select("platform, COUNT(*) AS count").
+		Where("status = ? AND retry_count >= max_retries", models.PublicationFailed).
+		Group("platform").
+		Order("count DESC").
+		Scan(&counts).Error
+	return counts, err
 }
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestPublicationJobRepository_CountFailedByPlatform(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewPublicationJobRepository(gormDB)
+	since := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT platform, COUNT\\(\\*\\) AS count FROM `publication_jobs` WHERE status = \\? AND updated_at >= \\? "+
+		"GROUP BY `platform` ORDER BY count DESC").
+		WithArgs("failed", since).
+		WillReturnRows(sqlmock.NewRows([]string{"platform", "count"}).
+			AddRow("tiktok", 7).
+			AddRow("youtube", 2))
+
+	counts, err := repo.CountFailedByPlatform(context.Background(), since)
+	require.NoError(t, err)
+	require.Len(t, counts, 2)
+	assert.Equal(t, "tiktok", counts[0].Platform)
+	assert.Equal(t, int64(7), counts[0].Count)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPublicationJobRepository_CountExhaustedByPlatform(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewPublicationJobRepository(gormDB)
+
+	mock.ExpectQuery("SELECT platform, COUNT\\(\\*\\) AS count FROM `publication_jobs` WHERE status = \\? AND retry_count >= max_retries " +
+		"GROUP BY `platform`").
+		WithArgs("failed").
+		WillReturnRows(sqlmock.NewRows([]string{"platform", "count"}).AddRow("instagram", 4))
+
+	counts, err := repo.CountExhaustedByPlatform(context.Background())
+	require.NoError(t, err)
+	require.Len(t, counts, 1)
+	assert.Equal(t, int64(4), counts[0].Count)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPublicationJobRepository_GetDueJobs(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewPublicationJobRepository(gormDB)
+	now := time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT \\* FROM `publication_jobs` WHERE status IN \\(\\?,\\?\\) AND scheduled_at <= \\? "+
+		"ORDER BY scheduled_at LIMIT \\?").
+		WithArgs("scheduled", "staged", now, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "status"}).AddRow("job-1", "acme", "staged"))
+
+	jobs, err := repo.GetDueJobs(context.Background(), now, 50)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "staged", jobs[0].Status)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPublicationJobRepository_GetByWorkspace(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewPublicationJobRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `publication_jobs` WHERE \\(workspace_id = \\? AND platform = \\? AND status IN \\(\\?,\\?\\)\\) "+
+		"AND `publication_jobs`.`tenant_id` = \\? ORDER BY scheduled_at").
+		WithArgs("ws-1", "youtube", "scheduled", "staged", "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "status"}).AddRow("job-1", "acme", "scheduled"))
+
+	jobs, err := repo.GetByWorkspace(context.Background(), "acme", "ws-1", models.PlatformYouTube,
+		[]models.PublicationStatus{models.PublicationScheduled, models.PublicationStaged})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
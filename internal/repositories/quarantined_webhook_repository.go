@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// quarantinedWebhookRepository implements models.QuarantinedWebhookRepository.
+// Public webhooks belong to no tenant, so every statement spans tenants.
+type quarantinedWebhookRepository struct {
+	db *gorm.DB
+}
+
+var _ models.QuarantinedWebhookRepository = (*quarantinedWebhookRepository)(nil)
+
+// NewQuarantinedWebhookRepository creates a new repository instance.
+func NewQuarantinedWebhookRepository(db *gorm.DB) models.QuarantinedWebhookRepository {
+	return &quarantinedWebhookRepository{db: db}
+}
+
+// quarantineSummaryColumns are the columns shown in listings; the encrypted
+// body is only read for a single webhook
+var quarantineSummaryColumns = []string{
+	"id", "tenant_id", "platform", "reason", "errors", "content_type", "body_size", "received_at", "created_at",
+}
+
+func (r *quarantinedWebhookRepository) Create(ctx context.Context, webhook *models.QuarantinedWebhook) error {
+	if webhook.ID == "" {
+		webhook.ID = id.New()
+	}
+	return allTenants(ctx, r.db).Create(webhook).Error
+}
+
+func (r *quarantinedWebhookRepository) List(ctx context.Context, platform string, limit, offset int) ([]*models.QuarantinedWebhook, error) {
+	query := allTenants(ctx, r.db).Select(quarantineSummaryColumns)
+	if platform != "" {
+		query = query.Where("platform = ?", platform)
+	}
+
+	var webhooks []*models.QuarantinedWebhook
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *quarantinedWebhookRepository) GetByID(ctx context.Context, id string) (*models.QuarantinedWebhook, error) {
+	var webhook models.QuarantinedWebhook
+	err := allTenants(ctx, r.db).Where("id = ?", id).First(&webhook).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrQuarantinedWebhookNotFound
+	}
+	return &webhook, err
+}
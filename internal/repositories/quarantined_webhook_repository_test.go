@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestQuarantinedWebhookRepository_ListLeavesOutBodies(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewQuarantinedWebhookRepository(gormDB)
+
+	mock.ExpectQuery("SELECT `id`,`tenant_id`,`platform`,`reason`,`errors`,`content_type`,`body_size`,`received_at`,`created_at` "+
+		"FROM `quarantined_webhooks` WHERE platform = \\? ORDER BY created_at DESC LIMIT \\?").
+		WithArgs("tiktok", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "platform", "reason", "errors"}).
+			AddRow("quarantined-1", "tiktok", models.WebhookMalformed, `[{"field":"event","message":"is required"}]`))
+
+	webhooks, err := repo.List(context.Background(), "tiktok", 20, 0)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, []models.WebhookFieldError{{Field: "event", Message: "is required"}}, webhooks[0].Errors)
+	assert.Empty(t, webhooks[0].Body)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuarantinedWebhookRepository_GetByIDNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewQuarantinedWebhookRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `quarantined_webhooks` WHERE id = \\?").
+		WithArgs("missing", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetByID(context.Background(), "missing")
+	assert.ErrorIs(t, err, models.ErrQuarantinedWebhookNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
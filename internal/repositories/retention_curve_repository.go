@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// retentionCurveRepository implements models.RetentionCurveRepository.
+type retentionCurveRepository struct {
+	db *gorm.DB
+}
+
+var _ models.RetentionCurveRepository = (*retentionCurveRepository)(nil)
+
+// NewRetentionCurveRepository creates a new repository instance.
+func NewRetentionCurveRepository(db *gorm.DB) models.RetentionCurveRepository {
+	return &retentionCurveRepository{db: db}
+}
+
+// Upsert saves the curve, replacing the points of an existing one of the
+// video and platform
+func (r *retentionCurveRepository) Upsert(ctx context.Context, curve *models.RetentionCurve) error {
+	if curve.ID == "" {
+		curve.ID = id.New()
+	}
+	return forTenant(ctx, r.db, curve.TenantID).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "video_id"}, {Name: "platform"}},
+		DoUpdates: clause.AssignmentColumns([]string{"points", "fetched_at", "updated_at"}),
+	}).Create(curve).Error
+}
+
+func (r *retentionCurveRepository) ListByVideo(ctx context.Context, tenantID, videoID string) ([]*models.RetentionCurve, error) {
+	var curves []*models.RetentionCurve
+	err := forTenant(ctx, r.db, tenantID).
+		Where("video_id = ?", videoID).
+		Order("platform").
+		Find(&curves).Error
+	return curves, err
+}
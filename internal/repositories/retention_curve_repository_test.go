@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestRetentionCurveRepository_UpsertReplacesPoints(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewRetentionCurveRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `retention_curves` .* ON DUPLICATE KEY UPDATE " +
+		"`points`=VALUES\\(`points`\\),`fetched_at`=VALUES\\(`fetched_at`\\),`updated_at`=VALUES\\(`updated_at`\\)").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	curve := &models.RetentionCurve{TenantID: "tenant-1", VideoID: "video-1", Platform: "youtube",
+		Points: []models.RetentionPoint{{Elapsed: 0, Watching: 1}}, FetchedAt: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, repo.Upsert(context.Background(), curve))
+	assert.NotEmpty(t, curve.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetentionCurveRepository_ListByVideo(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewRetentionCurveRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `retention_curves` WHERE video_id = \\? AND `retention_curves`.`tenant_id` = \\? ORDER BY platform").
+		WithArgs("video-1", "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "video_id", "platform", "points"}).
+			AddRow("curve-1", "tenant-1", "video-1", "youtube", `[{"elapsed":0,"watching":1},{"elapsed":0.5,"watching":0.4}]`))
+
+	curves, err := repo.ListByVideo(context.Background(), "tenant-1", "video-1")
+	require.NoError(t, err)
+	require.Len(t, curves, 1)
+	assert.Equal(t, []models.RetentionPoint{{Elapsed: 0, Watching: 1}, {Elapsed: 0.5, Watching: 0.4}}, curves[0].Points)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
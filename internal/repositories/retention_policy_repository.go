@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// retentionPolicyRepository implements models.RetentionPolicyRepository.
+type retentionPolicyRepository struct {
+	db *gorm.DB
+}
+
+var _ models.RetentionPolicyRepository = (*retentionPolicyRepository)(nil)
+
+// NewRetentionPolicyRepository creates a new repository instance.
+func NewRetentionPolicyRepository(db *gorm.DB) models.RetentionPolicyRepository {
+	return &retentionPolicyRepository{db: db}
+}
+
+func (r *retentionPolicyRepository) Get(ctx context.Context, tenantID string) (*models.RetentionPolicy, error) {
+	var policy models.RetentionPolicy
+	err := forTenant(ctx, r.db, tenantID).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrNotFound
+	}
+	return &policy, err
+}
+
+// Upsert saves the tenant's only policy, replacing the rules of an existing one
+func (r *retentionPolicyRepository) Upsert(ctx context.Context, policy *models.RetentionPolicy) error {
+	if policy.ID == "" {
+		policy.ID = id.New()
+	}
+	return forTenant(ctx, r.db, policy.TenantID).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"archive_after_months", "restore_days", "restore_tier", "updated_at"}),
+	}).Create(policy).Error
+}
+
+func (r *retentionPolicyRepository) ListEnabled(ctx context.Context) ([]*models.RetentionPolicy, error) {
+	var policies []*models.RetentionPolicy
+	err := allTenants(ctx, r.db).Where("archive_after_months > 0").Order("tenant_id").Find(&policies).Error
+	return policies, err
+}
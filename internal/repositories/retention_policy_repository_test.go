@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestRetentionPolicyRepository_UpsertReplacesTenantPolicy(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewRetentionPolicyRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `retention_policies` .* ON DUPLICATE KEY UPDATE " +
+		"`archive_after_months`=VALUES\\(`archive_after_months`\\),`restore_days`=VALUES\\(`restore_days`\\)," +
+		"`restore_tier`=VALUES\\(`restore_tier`\\),`updated_at`=VALUES\\(`updated_at`\\)").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	policy := &models.RetentionPolicy{TenantID: "tenant-1", ArchiveAfterMonths: 12, RestoreDays: 7, RestoreTier: "Standard"}
+	require.NoError(t, repo.Upsert(context.Background(), policy))
+	assert.NotEmpty(t, policy.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetentionPolicyRepository_GetNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewRetentionPolicyRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `retention_policies` WHERE `retention_policies`.`tenant_id` = \\?").
+		WithArgs("tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}))
+
+	_, err := repo.Get(context.Background(), "tenant-1")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// seriesRepository implements models.SeriesRepository.
+type seriesRepository struct {
+	db *gorm.DB
+}
+
+var _ models.SeriesRepository = (*seriesRepository)(nil)
+
+// NewSeriesRepository creates a new repository instance.
+func NewSeriesRepository(db *gorm.DB) models.SeriesRepository {
+	return &seriesRepository{db: db}
+}
+
+func (r *seriesRepository) Create(ctx context.Context, series *models.Series) error {
+	if series.ID == "" {
+		series.ID = id.New()
+	}
+	return forTenant(ctx, r.db, series.TenantID).Create(series).Error
+}
+
+func (r *seriesRepository) GetByID(ctx context.Context, tenantID, id string) (*models.Series, error) {
+	var series models.Series
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&series).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrSeriesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (r *seriesRepository) List(ctx context.Context, tenantID string) ([]*models.Series, error) {
+	var series []*models.Series
+	err := forTenant(ctx, r.db, tenantID).Order("name, id").Find(&series).Error
+	return series, err
+}
+
+func (r *seriesRepository) Update(ctx context.Context, series *models.Series) error {
+	return saveForTenant(ctx, r.db, series.TenantID, series)
+}
+
+func (r *seriesRepository) Delete(ctx context.Context, tenantID, id string) error {
+	return forTenant(ctx, r.db, tenantID).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ?", id).Delete(&models.Series{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrSeriesNotFound
+		}
+		return tx.Model(&models.Video{}).Where("episode_series_id = ?", id).Updates(map[string]interface{}{
+			"episode_series_id": "", "episode_season": 0, "episode_number": 0, "episode_title": "", "episode_playlisted": false,
+		}).Error
+	})
+}
+
+// AddEpisode locks the series row while it numbers the video, so two videos
+// added at once are numbered one after the other
+func (r *seriesRepository) AddEpisode(ctx context.Context, tenantID, seriesID, videoID string, season int) (int, error) {
+	var number int
+	err := forTenant(ctx, r.db, tenantID).Transaction(func(tx *gorm.DB) error {
+		var series models.Series
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", seriesID).First(&series).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrSeriesNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var last sql.NullInt64
+		err = tx.Model(&models.Video{}).Select("MAX(episode_number)").
+			Where("episode_series_id = ? AND episode_season = ?", seriesID, season).Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		number = int(last.Int64) + 1
+		res := tx.Model(&models.Video{}).Where("id = ?", videoID).Updates(map[string]interface{}{
+			"episode_series_id": seriesID, "episode_season": season, "episode_number": number, "episode_title": "", "episode_playlisted": false,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrVideoNotFound
+		}
+		return nil
+	})
+	return number, err
+}
+
+func (r *seriesRepository) ListEpisodes(ctx context.Context, tenantID, seriesID string) ([]*models.Video, error) {
+	var videos []*models.Video
+	err := forTenant(ctx, r.db, tenantID).Where("episode_series_id = ?", seriesID).
+		Order("episode_season, episode_number").Find(&videos).Error
+	return videos, err
+}
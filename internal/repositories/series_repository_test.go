@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestSeriesRepository_AddEpisode(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewSeriesRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \\* FROM `series` WHERE id = \\? AND `series`.`tenant_id` = \\? ORDER BY `series`.`id` LIMIT \\? FOR UPDATE").
+		WithArgs("series-1", "acme", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "name"}).AddRow("series-1", "acme", "Cold cases"))
+	mock.ExpectQuery("SELECT MAX\\(episode_number\\) FROM `videos` WHERE \\(episode_series_id = \\? AND episode_season = \\?\\)").
+		WithArgs("series-1", 2, "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"MAX(episode_number)"}).AddRow(4))
+	mock.ExpectExec("UPDATE `videos` SET").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	number, err := repo.AddEpisode(context.Background(), "acme", "series-1", "video-1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, number)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeriesRepository_AddEpisodeSeriesNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewSeriesRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \\* FROM `series` WHERE id = \\? AND `series`.`tenant_id` = \\?").
+		WithArgs("series-1", "globex", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	_, err := repo.AddEpisode(context.Background(), "globex", "series-1", "video-1", 1)
+	assert.ErrorIs(t, err, models.ErrSeriesNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeriesRepository_DeleteNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewSeriesRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `series` WHERE id = \\? AND `series`.`tenant_id` = \\?").
+		WithArgs("series-1", "globex").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.Delete(context.Background(), "globex", "series-1")
+	assert.ErrorIs(t, err, models.ErrSeriesNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// smartListRepository implements models.SmartListRepository.
+type smartListRepository struct {
+	db *gorm.DB
+}
+
+var _ models.SmartListRepository = (*smartListRepository)(nil)
+
+// NewSmartListRepository creates a new repository instance.
+func NewSmartListRepository(db *gorm.DB) models.SmartListRepository {
+	return &smartListRepository{db: db}
+}
+
+func (r *smartListRepository) Create(ctx context.Context, list *models.SmartList) error {
+	if list.ID == "" {
+		list.ID = id.New()
+	}
+	return forTenant(ctx, r.db, list.TenantID).Create(list).Error
+}
+
+func (r *smartListRepository) Get(ctx context.Context, tenantID, userID, id string) (*models.SmartList, error) {
+	var list models.SmartList
+	err := forTenant(ctx, r.db, tenantID).Where("user_id = ? AND id = ?", userID, id).First(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrSmartListNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *smartListRepository) List(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.SmartList, error) {
+	var lists []*models.SmartList
+	err := forTenant(ctx, r.db, tenantID).Where("user_id = ?", userID).
+		Order("name, id").Limit(limit).Offset(offset).Find(&lists).Error
+	return lists, err
+}
+
+func (r *smartListRepository) CountByUser(ctx context.Context, tenantID, userID string) (int64, error) {
+	var count int64
+	err := forTenant(ctx, r.db, tenantID).Model(&models.SmartList{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *smartListRepository) Update(ctx context.Context, list *models.SmartList) error {
+	return saveForTenant(ctx, r.db, list.TenantID, list)
+}
+
+func (r *smartListRepository) Delete(ctx context.Context, tenantID, userID, id string) error {
+	res := forTenant(ctx, r.db, tenantID).Where("user_id = ? AND id = ?", userID, id).Delete(&models.SmartList{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrSmartListNotFound
+	}
+	return nil
+}
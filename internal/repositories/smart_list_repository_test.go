@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestSmartListRepository_GetOwnOnly(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewSmartListRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `smart_lists` WHERE \\(user_id = \\? AND id = \\?\\) AND `smart_lists`.`tenant_id` = \\?").
+		WithArgs("user-2", "list-1", "acme", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.Get(context.Background(), "acme", "user-2", "list-1")
+	assert.ErrorIs(t, err, models.ErrSmartListNotFound, "users only see their own lists")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSmartListRepository_DeleteNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewSmartListRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `smart_lists` WHERE \\(user_id = \\? AND id = \\?\\) AND `smart_lists`.`tenant_id` = \\?").
+		WithArgs("user-2", "list-1", "acme").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := repo.Delete(context.Background(), "acme", "user-2", "list-1")
+	assert.ErrorIs(t, err, models.ErrSmartListNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// statsBackfillRepository implements models.StatsBackfillRepository.
+type statsBackfillRepository struct {
+	db *gorm.DB
+}
+
+var _ models.StatsBackfillRepository = (*statsBackfillRepository)(nil)
+
+// NewStatsBackfillRepository creates a new repository instance.
+func NewStatsBackfillRepository(db *gorm.DB) models.StatsBackfillRepository {
+	return &statsBackfillRepository{db: db}
+}
+
+// activeBackfillStatuses are the statuses of backfills with work left
+var activeBackfillStatuses = []string{models.BackfillPending, models.BackfillRunning, models.BackfillThrottled}
+
+func (r *statsBackfillRepository) Create(ctx context.Context, backfill *models.StatsBackfill) error {
+	if backfill.ID == "" {
+		backfill.ID = id.New()
+	}
+	return forTenant(ctx, r.db, backfill.TenantID).Create(backfill).Error
+}
+
+func (r *statsBackfillRepository) GetByID(ctx context.Context, tenantID, id string) (*models.StatsBackfill, error) {
+	var backfill models.StatsBackfill
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&backfill).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrBackfillNotFound
+	}
+	return &backfill, err
+}
+
+func (r *statsBackfillRepository) GetActive(ctx context.Context, tenantID, platform string) (*models.StatsBackfill, error) {
+	var backfill models.StatsBackfill
+	err := forTenant(ctx, r.db, tenantID).
+		Where("platform = ? AND status IN ?", platform, activeBackfillStatuses).
+		First(&backfill).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrBackfillNotFound
+	}
+	return &backfill, err
+}
+
+func (r *statsBackfillRepository) List(ctx context.Context, tenantID string, limit, offset int) ([]*models.StatsBackfill, error) {
+	var backfills []*models.StatsBackfill
+	err := forTenant(ctx, r.db, tenantID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&backfills).Error
+	return backfills, err
+}
+
+func (r *statsBackfillRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.StatsBackfill, error) {
+	var backfills []*models.StatsBackfill
+	err := allTenants(ctx, r.db).
+		Where("status IN ? AND next_run_at <= ?", activeBackfillStatuses, now).
+		Order("next_run_at").
+		Limit(limit).
+		Find(&backfills).Error
+	return backfills, err
+}
+
+func (r *statsBackfillRepository) Update(ctx context.Context, backfill *models.StatsBackfill) error {
+	return saveForTenant(ctx, r.db, backfill.TenantID, backfill)
+}
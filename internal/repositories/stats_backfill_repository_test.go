@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestStatsBackfillRepository_ListDue(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewStatsBackfillRepository(gormDB)
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT \\* FROM `stats_backfills` WHERE status IN \\(\\?,\\?,\\?\\) AND next_run_at <= \\? ORDER BY next_run_at LIMIT \\?").
+		WithArgs(models.BackfillPending, models.BackfillRunning, models.BackfillThrottled, now, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "platform", "status", "carry"}).
+			AddRow("backfill-1", "tenant-1", "youtube", models.BackfillRunning, `{"views":120,"likes":4,"comments":0,"shares":1}`))
+
+	due, err := repo.ListDue(context.Background(), now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, models.BackfillTotals{Views: 120, Likes: 4, Shares: 1}, due[0].Carry)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatsBackfillRepository_GetActiveNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewStatsBackfillRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `stats_backfills` WHERE \\(platform = \\? AND status IN \\(\\?,\\?,\\?\\)\\) AND `stats_backfills`.`tenant_id` = \\?").
+		WithArgs("youtube", models.BackfillPending, models.BackfillRunning, models.BackfillThrottled, "tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetActive(context.Background(), "tenant-1", "youtube")
+	assert.ErrorIs(t, err, models.ErrBackfillNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// statsCompactionRepository implements models.StatsCompactionRepository.
+type statsCompactionRepository struct {
+	db *gorm.DB
+}
+
+var _ models.StatsCompactionRepository = (*statsCompactionRepository)(nil)
+
+// NewStatsCompactionRepository creates a new repository instance.
+func NewStatsCompactionRepository(db *gorm.DB) models.StatsCompactionRepository {
+	return &statsCompactionRepository{db: db}
+}
+
+func (r *statsCompactionRepository) Get(ctx context.Context, tenantID string) (*models.StatsCompaction, error) {
+	var compaction models.StatsCompaction
+	err := forTenant(ctx, r.db, tenantID).First(&compaction).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrNotFound
+	}
+	return &compaction, err
+}
+
+// Upsert saves the tenant's only progress row, moving an existing one
+func (r *statsCompactionRepository) Upsert(ctx context.Context, compaction *models.StatsCompaction) error {
+	if compaction.ID == "" {
+		compaction.ID = id.New()
+	}
+	return forTenant(ctx, r.db, compaction.TenantID).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"compacted_before", "updated_at"}),
+	}).Create(compaction).Error
+}
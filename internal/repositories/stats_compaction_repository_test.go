@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestStatsCompactionRepository_UpsertMovesTenantProgress(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewStatsCompactionRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `stats_compactions` .* ON DUPLICATE KEY UPDATE " +
+		"`compacted_before`=VALUES\\(`compacted_before`\\),`updated_at`=VALUES\\(`updated_at`\\)").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	compaction := &models.StatsCompaction{TenantID: "tenant-1", CompactedBefore: time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, repo.Upsert(context.Background(), compaction))
+	assert.NotEmpty(t, compaction.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// statsSyncCheckpointRepository implements models.StatsSyncCheckpointRepository.
+type statsSyncCheckpointRepository struct {
+	db *gorm.DB
+}
+
+var _ models.StatsSyncCheckpointRepository = (*statsSyncCheckpointRepository)(nil)
+
+// NewStatsSyncCheckpointRepository creates a new repository instance.
+func NewStatsSyncCheckpointRepository(db *gorm.DB) models.StatsSyncCheckpointRepository {
+	return &statsSyncCheckpointRepository{db: db}
+}
+
+func (r *statsSyncCheckpointRepository) Get(ctx context.Context, tenantID, platform string) (*models.StatsSyncCheckpoint, error) {
+	var checkpoint models.StatsSyncCheckpoint
+	err := forTenant(ctx, r.db, tenantID).Where("platform = ?", platform).First(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrNotFound
+	}
+	return &checkpoint, err
+}
+
+// Upsert saves the only checkpoint of the tenant's platform, moving an
+// existing one
+func (r *statsSyncCheckpointRepository) Upsert(ctx context.Context, checkpoint *models.StatsSyncCheckpoint) error {
+	if checkpoint.ID == "" {
+		checkpoint.ID = id.New()
+	}
+	return forTenant(ctx, r.db, checkpoint.TenantID).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "platform"}},
+		DoUpdates: clause.AssignmentColumns([]string{"stats_id", "external_id", "run_started_at", "synced", "completed_at", "updated_at"}),
+	}).Create(checkpoint).Error
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestStatsSyncCheckpointRepository_UpsertMovesPlatformCheckpoint(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewStatsSyncCheckpointRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `stats_sync_checkpoints` .* ON DUPLICATE KEY UPDATE " +
+		"`stats_id`=VALUES\\(`stats_id`\\),`external_id`=VALUES\\(`external_id`\\),`run_started_at`=VALUES\\(`run_started_at`\\)," +
+		"`synced`=VALUES\\(`synced`\\),`completed_at`=VALUES\\(`completed_at`\\),`updated_at`=VALUES\\(`updated_at`\\)").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	checkpoint := &models.StatsSyncCheckpoint{TenantID: "tenant-1", Platform: "youtube", StatsID: "stats-9", RunStartedAt: time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, repo.Upsert(context.Background(), checkpoint))
+	assert.NotEmpty(t, checkpoint.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatsSyncCheckpointRepository_GetBeforeFirstSync(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewStatsSyncCheckpointRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `stats_sync_checkpoints` WHERE platform = \\? AND `stats_sync_checkpoints`.`tenant_id` = \\?").
+		WithArgs("tiktok", "tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.Get(context.Background(), "tenant-1", "tiktok")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
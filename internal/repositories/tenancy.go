@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
+)
+
+// forTenant binds db to the caller's context and scopes it to a tenant; the
+// tenancy plugin adds the tenant_id filter to every statement and stamps
+// created records. Statements stop when ctx is cancelled.
+func forTenant(ctx context.Context, db *gorm.DB, tenantID string) *gorm.DB {
+	return db.WithContext(tenancy.WithTenant(ctx, tenantID))
+}
+
+// allTenants binds db to the caller's context and marks it for system work
+// that deliberately spans tenants
+func allTenants(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(tenancy.WithAllTenants(ctx))
+}
+
+// saveForTenant updates every column of an existing record within its tenant.
+// Selecting all columns stops Save from falling back to an upsert when no row
+// matched, which could otherwise overwrite a row owned by another tenant.
+func saveForTenant(ctx context.Context, db *gorm.DB, tenantID string, record interface{}) error {
+	return forTenant(ctx, db, tenantID).Select("*").Save(record).Error
+}
@@ -1,12 +1,13 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
 )
 
 // tenantRepository implements models.TenantRepository.
@@ -14,46 +15,48 @@ type tenantRepository struct {
 	db *gorm.DB
 }
 
+var _ models.TenantRepository = (*tenantRepository)(nil)
+
 // NewTenantRepository creates a new repository.
 func NewTenantRepository(db *gorm.DB) models.TenantRepository {
 	return &tenantRepository{db: db}
 }
 
-func (r *tenantRepository) Create(t *models.Tenant) error {
+func (r *tenantRepository) Create(ctx context.Context, t *models.Tenant) error {
 	if t.ID == "" {
-		t.ID = uuid.New().String()
+		t.ID = id.New()
 	}
-	return r.db.Create(t).Error
+	return r.db.WithContext(ctx).Create(t).Error
 }
 
-func (r *tenantRepository) GetByID(id string) (*models.Tenant, error) {
+func (r *tenantRepository) GetByID(ctx context.Context, id string) (*models.Tenant, error) {
 	var t models.Tenant
-	err := r.db.First(&t, "id = ?", id).Error
+	err := r.db.WithContext(ctx).First(&t, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, models.ErrTenantNotFound
 	}
 	return &t, err
 }
 
-func (r *tenantRepository) GetByDomain(domain string) (*models.Tenant, error) {
+func (r *tenantRepository) GetByDomain(ctx context.Context, domain string) (*models.Tenant, error) {
 	var t models.Tenant
-	err := r.db.First(&t, "domain = ?", domain).Error
+	err := r.db.WithContext(ctx).First(&t, "domain = ?", domain).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, models.ErrTenantNotFound
 	}
 	return &t, err
 }
 
-func (r *tenantRepository) Update(t *models.Tenant) error {
-	return r.db.Save(t).Error
+func (r *tenantRepository) Update(ctx context.Context, t *models.Tenant) error {
+	return r.db.WithContext(ctx).Save(t).Error
 }
 
-func (r *tenantRepository) Delete(id string) error {
-	return r.db.Delete(&models.Tenant{}, "id = ?", id).Error
+func (r *tenantRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&models.Tenant{}, "id = ?", id).Error
 }
 
-func (r *tenantRepository) List(limit, offset int) ([]*models.Tenant, error) {
+func (r *tenantRepository) List(ctx context.Context, limit, offset int) ([]*models.Tenant, error) {
 	var tenants []*models.Tenant
-	err := r.db.Limit(limit).Offset(offset).Find(&tenants).Error
+	err := r.db.WithContext(ctx).Limit(limit).Offset(offset).Find(&tenants).Error
 	return tenants, err
 }
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// thumbnailVariantRepository implements models.ThumbnailVariantRepository.
+type thumbnailVariantRepository struct {
+	db *gorm.DB
+}
+
+var _ models.ThumbnailVariantRepository = (*thumbnailVariantRepository)(nil)
+
+// NewThumbnailVariantRepository creates a new repository instance.
+func NewThumbnailVariantRepository(db *gorm.DB) models.ThumbnailVariantRepository {
+	return &thumbnailVariantRepository{db: db}
+}
+
+func (r *thumbnailVariantRepository) Create(ctx context.Context, variants []*models.ThumbnailVariant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+	for _, variant := range variants {
+		if variant.ID == "" {
+			variant.ID = id.New()
+		}
+	}
+	return forTenant(ctx, r.db, variants[0].TenantID).Create(variants).Error
+}
+
+func (r *thumbnailVariantRepository) Get(ctx context.Context, tenantID, videoID, id string) (*models.ThumbnailVariant, error) {
+	var variant models.ThumbnailVariant
+	err := forTenant(ctx, r.db, tenantID).Where("video_id = ? AND id = ?", videoID, id).First(&variant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrThumbnailVariantNotFound
+	}
+	return &variant, err
+}
+
+func (r *thumbnailVariantRepository) ListByVideo(ctx context.Context, tenantID, videoID string) ([]*models.ThumbnailVariant, error) {
+	var variants []*models.ThumbnailVariant
+	err := forTenant(ctx, r.db, tenantID).Where("video_id = ?", videoID).Order("created_at DESC, layout").Find(&variants).Error
+	return variants, err
+}
+
+func (r *thumbnailVariantRepository) Update(ctx context.Context, variant *models.ThumbnailVariant) error {
+	return saveForTenant(ctx, r.db, variant.TenantID, variant)
+}
+
+// Select unselects the video's other variants in the same transaction, so a
+// video never has two selected thumbnails.
+func (r *thumbnailVariantRepository) Select(ctx context.Context, tenantID, videoID, id string) error {
+	return forTenant(ctx, r.db, tenantID).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.ThumbnailVariant{}).Where("video_id = ? AND selected = ?", videoID, true).
+			Update("selected", false).Error
+		if err != nil {
+			return err
+		}
+		res := tx.Model(&models.ThumbnailVariant{}).Where("video_id = ? AND id = ?", videoID, id).Update("selected", true)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrThumbnailVariantNotFound
+		}
+		return nil
+	})
+}
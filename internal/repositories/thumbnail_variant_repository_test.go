@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestThumbnailVariantRepository_Select(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewThumbnailVariantRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `thumbnail_variants` SET `selected`=\\?,`updated_at`=\\? WHERE \\(video_id = \\? AND selected = \\?\\) AND `thumbnail_variants`.`tenant_id` = \\?").
+		WithArgs(false, sqlmock.AnyArg(), "video-1", true, "acme").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE `thumbnail_variants` SET `selected`=\\?,`updated_at`=\\? WHERE \\(video_id = \\? AND id = \\?\\) AND `thumbnail_variants`.`tenant_id` = \\?").
+		WithArgs(true, sqlmock.AnyArg(), "video-1", "variant-2", "acme").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Select(context.Background(), "acme", "video-1", "variant-2"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestThumbnailVariantRepository_SelectNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewThumbnailVariantRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `thumbnail_variants` SET `selected`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE `thumbnail_variants` SET `selected`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.Select(context.Background(), "acme", "video-1", "variant-9")
+	assert.ErrorIs(t, err, models.ErrThumbnailVariantNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
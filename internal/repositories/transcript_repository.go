@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// snippetRadius is the number of characters kept around a search match.
+const snippetRadius = 80
+
+type transcriptRepository struct {
+	db *gorm.DB
+}
+
+var _ models.TranscriptRepository = (*transcriptRepository)(nil)
+
+// NewTranscriptRepository creates a transcript repository.
+func NewTranscriptRepository(db *gorm.DB) models.TranscriptRepository {
+	return &transcriptRepository{db: db}
+}
+
+// Upsert creates the transcript or replaces the one stored for the same video and language.
+func (r *transcriptRepository) Upsert(ctx context.Context, t *models.Transcript) error {
+	if t.ID == "" {
+		t.ID = id.New()
+	}
+	return forTenant(ctx, r.db, t.TenantID).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "video_id"}, {Name: "language"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source", "text", "segments", "updated_at"}),
+	}).Create(t).Error
+}
+
+func (r *transcriptRepository) GetByVideoID(ctx context.Context, tenantID, videoID, language string) (*models.Transcript, error) {
+	var t models.Transcript
+	q := forTenant(ctx, r.db, tenantID).Where("video_id = ?", videoID)
+	if language != "" {
+		q = q.Where("language = ?", language)
+	}
+	err := q.Order("created_at ASC").First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrTranscriptNotFound
+	}
+	return &t, err
+}
+
+func (r *transcriptRepository) ListByVideoID(ctx context.Context, tenantID, videoID string) ([]*models.Transcript, error) {
+	var transcripts []*models.Transcript
+	err := forTenant(ctx, r.db, tenantID).Where("video_id = ?", videoID).Find(&transcripts).Error
+	return transcripts, err
+}
+
+// Search runs a natural-language full-text query over the tenant's
+// transcripts, of the videos the scope sees.
+func (r *transcriptRepository) Search(ctx context.Context, tenantID string, scope *models.VideoScope, query string, limit, offset int) ([]*models.TranscriptSearchResult, error) {
+	var rows []struct {
+		VideoID  string
+		Language string
+		Text     string
+		Score    float64
+	}
+	q := forTenant(ctx, r.db, tenantID).Model(&models.Transcript{})
+	if scope != nil {
+		q = withinScope(q.Joins("JOIN videos ON videos.id = transcripts.video_id AND videos.deleted_at IS NULL"), scope)
+	}
+	err := q.Select("transcripts.video_id, transcripts.language, transcripts.text, MATCH(transcripts.text) AGAINST (? IN NATURAL LANGUAGE MODE) AS score", query).
+		Where("MATCH(transcripts.text) AGAINST (? IN NATURAL LANGUAGE MODE)", query).
+		Order("score DESC").Limit(limit).Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.TranscriptSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, &models.TranscriptSearchResult{
+			VideoID:  row.VideoID,
+			Language: row.Language,
+			Snippet:  snippet(row.Text, query),
+			Score:    row.Score,
+		})
+	}
+	return results, nil
+}
+
+func (r *transcriptRepository) Delete(ctx context.Context, tenantID, videoID, language string) error {
+	return forTenant(ctx, r.db, tenantID).Where("video_id = ? AND language = ?", videoID, language).Delete(&models.Transcript{}).Error
+}
+
+// snippet returns the text surrounding the first query term found in text.
+func snippet(text, query string) string {
+	lower := strings.ToLower(text)
+	pos := -1
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if i := strings.Index(lower, term); i >= 0 && (pos < 0 || i < pos) {
+			pos = i
+		}
+	}
+	if pos < 0 {
+		pos = 0
+	}
+
+	start := pos - snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	end := pos + snippetRadius
+	if end > len(text) {
+		end = len(text)
+	}
+
+	// Avoid cutting through a multi-byte character
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	out := strings.TrimSpace(text[start:end])
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(text) {
+		out += "…"
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestTranscriptRepository_SearchWithinScope(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewTranscriptRepository(gormDB)
+
+	mock.ExpectQuery("SELECT transcripts.video_id, transcripts.language, transcripts.text, MATCH\\(transcripts.text\\) AGAINST \\(\\? IN NATURAL LANGUAGE MODE\\) AS score "+
+		"FROM `transcripts` JOIN videos ON videos.id = transcripts.video_id AND videos.deleted_at IS NULL "+
+		"WHERE \\(videos.visibility = \\? OR videos.user_id = \\? OR \\(videos.visibility = \\? AND videos.workspace_id IN \\(\\?\\)\\)\\) "+
+		"AND MATCH\\(transcripts.text\\) AGAINST \\(\\? IN NATURAL LANGUAGE MODE\\) AND `transcripts`.`tenant_id` = \\? .*ORDER BY score DESC LIMIT \\?").
+		WithArgs("launch", models.VisibilityTenant, "user-1", models.VisibilityWorkspace, "ws-1", "launch", "tenant-1", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"video_id", "language", "text", "score"}).
+			AddRow("video-1", "en", "Welcome to the launch of the season.", 1.5))
+
+	results, err := repo.Search(context.Background(), "tenant-1", &models.VideoScope{UserID: "user-1", WorkspaceIDs: []string{"ws-1"}}, "launch", 20, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "video-1", results[0].VideoID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// userNotificationRepository implements models.UserNotificationRepository.
+type userNotificationRepository struct {
+	db *gorm.DB
+}
+
+var _ models.UserNotificationRepository = (*userNotificationRepository)(nil)
+
+// NewUserNotificationRepository creates a new repository instance.
+func NewUserNotificationRepository(db *gorm.DB) models.UserNotificationRepository {
+	return &userNotificationRepository{db: db}
+}
+
+func (r *userNotificationRepository) Create(ctx context.Context, notification *models.UserNotification) error {
+	if notification.ID == "" {
+		notification.ID = id.New()
+	}
+	return forTenant(ctx, r.db, notification.TenantID).Create(notification).Error
+}
+
+func (r *userNotificationRepository) ListInApp(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.UserNotification, error) {
+	var notifications []*models.UserNotification
+	err := forTenant(ctx, r.db, tenantID).
+		Where("user_id = ? AND in_app = ?", userID, true).
+		Order("created_at DESC").Limit(limit).Offset(offset).Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *userNotificationRepository) ListPendingDigest(ctx context.Context, tenantID, userID string) ([]*models.UserNotification, error) {
+	var notifications []*models.UserNotification
+	err := forTenant(ctx, r.db, tenantID).
+		Where("user_id = ? AND pending_digest = ?", userID, true).
+		Order("created_at").Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *userNotificationRepository) MarkDigested(ctx context.Context, tenantID string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return forTenant(ctx, r.db, tenantID).Model(&models.UserNotification{}).
+		Where("id IN ?", ids).Update("pending_digest", false).Error
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// userPreferencesRepository implements models.UserPreferencesRepository.
+type userPreferencesRepository struct {
+	db *gorm.DB
+}
+
+var _ models.UserPreferencesRepository = (*userPreferencesRepository)(nil)
+
+// NewUserPreferencesRepository creates a new repository instance.
+func NewUserPreferencesRepository(db *gorm.DB) models.UserPreferencesRepository {
+	return &userPreferencesRepository{db: db}
+}
+
+func (r *userPreferencesRepository) Get(ctx context.Context, tenantID, userID string) (*models.UserPreferences, error) {
+	var prefs models.UserPreferences
+	err := forTenant(ctx, r.db, tenantID).Where("user_id = ?", userID).First(&prefs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// Upsert saves the user's only preferences row
+func (r *userPreferencesRepository) Upsert(ctx context.Context, prefs *models.UserPreferences) error {
+	if prefs.ID == "" {
+		prefs.ID = id.New()
+	}
+	return forTenant(ctx, r.db, prefs.TenantID).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"language", "timezone", "notification_channels",
+			"digest_frequency", "last_digest_at", "updated_at"}),
+	}).Create(prefs).Error
+}
+
+// ListDigests reads across tenants for the digest job
+func (r *userPreferencesRepository) ListDigests(ctx context.Context, afterUserID string, limit int) ([]*models.UserPreferences, error) {
+	var prefs []*models.UserPreferences
+	err := allTenants(ctx, r.db).
+		Where("digest_frequency <> ? AND user_id > ?", models.DigestNone, afterUserID).
+		Order("user_id").Limit(limit).Find(&prefs).Error
+	return prefs, err
+}
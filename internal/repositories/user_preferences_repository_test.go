@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestUserPreferencesRepository_UpsertReplacesUserPreferences(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewUserPreferencesRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `user_preferences` .* ON DUPLICATE KEY UPDATE " +
+		"`language`=VALUES\\(`language`\\),`timezone`=VALUES\\(`timezone`\\)," +
+		"`notification_channels`=VALUES\\(`notification_channels`\\),`digest_frequency`=VALUES\\(`digest_frequency`\\)," +
+		"`last_digest_at`=VALUES\\(`last_digest_at`\\),`updated_at`=VALUES\\(`updated_at`\\)").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	prefs := models.DefaultUserPreferences("tenant-1", "user-1")
+	require.NoError(t, repo.Upsert(context.Background(), prefs))
+	assert.NotEmpty(t, prefs.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserPreferencesRepository_GetNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewUserPreferencesRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `user_preferences` WHERE user_id = \\? AND `user_preferences`.`tenant_id` = \\? "+
+		"ORDER BY `user_preferences`.`id` LIMIT \\?").
+		WithArgs("user-1", "tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+
+	_, err := repo.Get(context.Background(), "tenant-1", "user-1")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserPreferencesRepository_ListDigestsSpansTenants(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewUserPreferencesRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `user_preferences` WHERE digest_frequency <> \\? AND user_id > \\? "+
+		"ORDER BY user_id LIMIT \\?$").
+		WithArgs(models.DigestNone, "user-1", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "tenant_id", "digest_frequency"}).
+			AddRow("user-2", "tenant-2", "daily"))
+
+	prefs, err := repo.ListDigests(context.Background(), "user-1", 50)
+	require.NoError(t, err)
+	require.Len(t, prefs, 1)
+	assert.Equal(t, "tenant-2", prefs[0].TenantID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
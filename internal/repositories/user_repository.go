@@ -1,12 +1,13 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
 )
 
 // userRepository implements models.UserRepository using GORM.
@@ -14,50 +15,61 @@ type userRepository struct {
 	db *gorm.DB
 }
 
+var _ models.UserRepository = (*userRepository)(nil)
+
 // NewUserRepository creates a new user repository.
 func NewUserRepository(db *gorm.DB) models.UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *models.User) error {
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 	if user.ID == "" {
-		user.ID = uuid.New().String()
+		user.ID = id.New()
+	}
+	return forTenant(ctx, r.db, user.TenantID).Create(user).Error
+}
+
+func (r *userRepository) GetByID(ctx context.Context, tenantID, id string) (*models.User, error) {
+	var user models.User
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrUserNotFound
 	}
-	return r.db.Create(user).Error
+	return &user, err
 }
 
-func (r *userRepository) GetByID(tenantID, id string) (*models.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, tenantID, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("tenant_id = ? AND id = ?", tenantID, id).First(&user).Error
+	err := forTenant(ctx, r.db, tenantID).Where("email = ?", email).First(&user).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, models.ErrUserNotFound
 	}
 	return &user, err
 }
 
-func (r *userRepository) GetByEmail(tenantID, email string) (*models.User, error) {
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("tenant_id = ? AND email = ?", tenantID, email).First(&user).Error
+	err := allTenants(ctx, r.db).Where("email = ?", email).First(&user).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, models.ErrUserNotFound
 	}
 	return &user, err
 }
 
-func (r *userRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+func (r *userRepository) Update(ctx context.Context, user *models.User) error {
+	return saveForTenant(ctx, r.db, user.TenantID, user)
 }
 
-func (r *userRepository) Delete(tenantID, id string) error {
-	return r.db.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&models.User{}).Error
+func (r *userRepository) Delete(ctx context.Context, tenantID, id string) error {
+	return forTenant(ctx, r.db, tenantID).Where("id = ?", id).Delete(&models.User{}).Error
 }
 
-func (r *userRepository) List(tenantID string, limit, offset int) ([]*models.User, error) {
+func (r *userRepository) List(ctx context.Context, tenantID string, limit, offset int) ([]*models.User, error) {
 	var users []*models.User
-	err := r.db.Where("tenant_id = ?", tenantID).Limit(limit).Offset(offset).Find(&users).Error
+	err := forTenant(ctx, r.db, tenantID).Limit(limit).Offset(offset).Find(&users).Error
 	return users, err
 }
 
-func (r *userRepository) UpdateLastLogin(tenantID, id string) error {
-	return r.db.Model(&models.User{}).Where("tenant_id = ? AND id = ?", tenantID, id).Update("last_login", gorm.Expr("NOW()")).Error
+func (r *userRepository) UpdateLastLogin(ctx context.Context, tenantID, id string) error {
+	return forTenant(ctx, r.db, tenantID).Model(&models.User{}).Where("id = ?", id).Update("last_login", gorm.Expr("NOW()")).Error
 }
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// videoRenditionRepository implements models.VideoRenditionRepository.
+type videoRenditionRepository struct {
+	db *gorm.DB
+}
+
+var _ models.VideoRenditionRepository = (*videoRenditionRepository)(nil)
+
+// NewVideoRenditionRepository creates a new repository instance.
+func NewVideoRenditionRepository(db *gorm.DB) models.VideoRenditionRepository {
+	return &videoRenditionRepository{db: db}
+}
+
+func (r *videoRenditionRepository) Get(ctx context.Context, tenantID, videoID, profile string) (*models.VideoRendition, error) {
+	var rendition models.VideoRendition
+	err := forTenant(ctx, r.db, tenantID).Where("video_id = ? AND profile = ?", videoID, profile).First(&rendition).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrNotFound
+	}
+	return &rendition, err
+}
+
+func (r *videoRenditionRepository) ListByVideo(ctx context.Context, tenantID, videoID string) ([]*models.VideoRendition, error) {
+	var renditions []*models.VideoRendition
+	err := forTenant(ctx, r.db, tenantID).Where("video_id = ?", videoID).Order("profile").Find(&renditions).Error
+	return renditions, err
+}
+
+// Upsert replaces the output and status of an existing rendition of the profile
+func (r *videoRenditionRepository) Upsert(ctx context.Context, rendition *models.VideoRendition) error {
+	if rendition.ID == "" {
+		rendition.ID = id.New()
+	}
+	return forTenant(ctx, r.db, rendition.TenantID).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "video_id"}, {Name: "profile"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "width", "height", "video_bitrate_kbps",
+			"file_path", "file_url", "s3_key", "file_size", "error_msg", "audio_languages", "intro_asset_id", "outro_asset_id", "transcoded_at", "updated_at"}),
+	}).Create(rendition).Error
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestVideoRenditionRepository_UpsertReplacesProfileRendition(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRenditionRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `video_renditions` .* ON DUPLICATE KEY UPDATE " +
+		"`status`=VALUES\\(`status`\\),`width`=VALUES\\(`width`\\),`height`=VALUES\\(`height`\\)," +
+		"`video_bitrate_kbps`=VALUES\\(`video_bitrate_kbps`\\),`file_path`=VALUES\\(`file_path`\\)," +
+		"`file_url`=VALUES\\(`file_url`\\),`s3_key`=VALUES\\(`s3_key`\\),`file_size`=VALUES\\(`file_size`\\)," +
+		"`error_msg`=VALUES\\(`error_msg`\\),`audio_languages`=VALUES\\(`audio_languages`\\)," +
+		"`intro_asset_id`=VALUES\\(`intro_asset_id`\\),`outro_asset_id`=VALUES\\(`outro_asset_id`\\),`transcoded_at`=VALUES\\(`transcoded_at`\\),`updated_at`=VALUES\\(`updated_at`\\)").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	rendition := &models.VideoRendition{TenantID: "tenant-1", VideoID: "video-1", Profile: "vertical_1080p", Status: "pending"}
+	require.NoError(t, repo.Upsert(context.Background(), rendition))
+	assert.NotEmpty(t, rendition.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRenditionRepository_GetNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRenditionRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `video_renditions` WHERE \\(video_id = \\? AND profile = \\?\\) AND `video_renditions`.`tenant_id` = \\?").
+		WithArgs("video-1", "vertical_1080p", "tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}))
+
+	_, err := repo.Get(context.Background(), "tenant-1", "video-1", "vertical_1080p")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
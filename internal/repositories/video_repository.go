@@ -1,12 +1,15 @@
 package repositories
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
 )
 
 // videoRepository implements models.VideoRepository.
@@ -14,53 +17,227 @@ type videoRepository struct {
 	db *gorm.DB
 }
 
+var _ models.VideoRepository = (*videoRepository)(nil)
+
 // NewVideoRepository creates a new repository instance.
 func NewVideoRepository(db *gorm.DB) models.VideoRepository {
 	return &videoRepository{db: db}
 }
 
-func (r *videoRepository) Create(video *models.Video) error {
+func (r *videoRepository) Create(ctx context.Context, video *models.Video) error {
 	if video.ID == "" {
-		video.ID = uuid.New().String()
+		video.ID = id.New()
 	}
-	return r.db.Create(video).Error
+	return forTenant(ctx, r.db, video.TenantID).Create(video).Error
 }
 
-func (r *videoRepository) GetByID(tenantID, id string) (*models.Video, error) {
+func (r *videoRepository) GetByID(ctx context.Context, tenantID, id string) (*models.Video, error) {
 	var v models.Video
-	err := r.db.Where("tenant_id = ? AND id = ?", tenantID, id).First(&v).Error
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&v).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, models.ErrVideoNotFound
 	}
 	return &v, err
 }
 
-func (r *videoRepository) GetByUserID(tenantID, userID string, limit, offset int) ([]*models.Video, error) {
+// GetByIDs loads the tenant's videos among ids in one query; ids that do
+// not exist or belong to another tenant are absent from the result
+func (r *videoRepository) GetByIDs(ctx context.Context, tenantID string, ids []string) ([]*models.Video, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var videos []*models.Video
+	err := forTenant(ctx, r.db, tenantID).Where("id IN ?", ids).Find(&videos).Error
+	return videos, err
+}
+
+func (r *videoRepository) GetByUserID(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.Video, error) {
+	var videos []*models.Video
+	err := forTenant(ctx, r.db, tenantID).Where("user_id = ?", userID).Limit(limit).Offset(offset).Find(&videos).Error
+	return videos, err
+}
+
+func (r *videoRepository) ListVisible(ctx context.Context, tenantID, userID string, workspaceIDs []string, limit, offset int) ([]*models.Video, error) {
+	query := forTenant(ctx, r.db, tenantID)
+	if len(workspaceIDs) > 0 {
+		query = query.Where("visibility = ? OR user_id = ? OR (visibility = ? AND workspace_id IN ?)",
+			models.VisibilityTenant, userID, models.VisibilityWorkspace, workspaceIDs)
+	} else {
+		query = query.Where("visibility = ? OR user_id = ?", models.VisibilityTenant, userID)
+	}
+	var videos []*models.Video
+	err := query.Limit(limit).Offset(offset).Find(&videos).Error
+	return videos, err
+}
+
+func (r *videoRepository) Update(ctx context.Context, video *models.Video) error {
+	return saveForTenant(ctx, r.db, video.TenantID, video)
+}
+
+func (r *videoRepository) Delete(ctx context.Context, tenantID, id string) error {
+	return forTenant(ctx, r.db, tenantID).Where("id = ?", id).Delete(&models.Video{}).Error
+}
+
+func (r *videoRepository) List(ctx context.Context, tenantID string, limit, offset int) ([]*models.Video, error) {
+	var videos []*models.Video
+	err := forTenant(ctx, r.db, tenantID).Limit(limit).Offset(offset).Find(&videos).Error
+	return videos, err
+}
+
+func (r *videoRepository) UpdateStatus(ctx context.Context, tenantID, id string, status models.VideoStatus) error {
+	return forTenant(ctx, r.db, tenantID).Model(&models.Video{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (r *videoRepository) GetByStatus(ctx context.Context, tenantID string, status models.VideoStatus, limit, offset int) ([]*models.Video, error) {
+	var videos []*models.Video
+	err := forTenant(ctx, r.db, tenantID).Where("status = ?", status).Limit(limit).Offset(offset).Find(&videos).Error
+	return videos, err
+}
+
+// unpublishedCondition matches videos without an ID on any partner platform
+const unpublishedCondition = "COALESCE(youtube_id, '') = '' AND COALESCE(tiktok_id, '') = '' AND " +
+	"COALESCE(instagram_id, '') = '' AND COALESCE(facebook_id, '') = '' AND " +
+	"COALESCE(twitter_media_id, 0) = 0 AND COALESCE(snapchat_media_id, '') = ''"
+
+func (r *videoRepository) ListArchivable(ctx context.Context, tenantID string, updatedBefore time.Time, limit int) ([]*models.Video, error) {
+	var videos []*models.Video
+	err := forTenant(ctx, r.db, tenantID).
+		Where("status = ? AND updated_at < ? AND s3_key <> ''", models.StatusReady, updatedBefore).
+		Where(unpublishedCondition).
+		Order("updated_at").Limit(limit).Find(&videos).Error
+	return videos, err
+}
+
+func (r *videoRepository) ListRightsExpiring(ctx context.Context, tenantID string, from, to time.Time, limit int) ([]*models.Video, error) {
+	var videos []*models.Video
+	err := forTenant(ctx, r.db, tenantID).
+		Where("rights_expires_at >= ? AND rights_expires_at < ?", from, to).
+		Order("rights_expires_at").Limit(limit).Find(&videos).Error
+	return videos, err
+}
+
+func (r *videoRepository) ListByCampaign(ctx context.Context, tenantID, campaignID string, limit int) ([]*models.Video, error) {
 	var videos []*models.Video
-	err := r.db.Where("tenant_id = ? AND user_id = ?", tenantID, userID).Limit(limit).Offset(offset).Find(&videos).Error
+	err := forTenant(ctx, r.db, tenantID).
+		Where("campaign_id = ?", campaignID).
+		Order("created_at DESC").Limit(limit).Find(&videos).Error
 	return videos, err
 }
 
-func (r *videoRepository) Update(video *models.Video) error {
-	return r.db.Save(video).Error
+// publishedConditions match the videos published to each smart list platform
+var publishedConditions = map[models.Platform]string{
+	models.PlatformYouTube:   "COALESCE(videos.youtube_id, '') <> ''",
+	models.PlatformTikTok:    "COALESCE(videos.tiktok_id, '') <> ''",
+	models.PlatformInstagram: "COALESCE(videos.instagram_id, '') <> ''",
+	models.PlatformFacebook:  "COALESCE(videos.facebook_id, '') <> ''",
+	models.PlatformTwitter:   "COALESCE(videos.twitter_media_id, 0) <> 0",
+	models.PlatformSnapchat:  "COALESCE(videos.snapchat_media_id, '') <> ''",
 }
 
-func (r *videoRepository) Delete(tenantID, id string) error {
-	return r.db.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&models.Video{}).Error
+// smartListColumns are the expressions numeric smart list fields compare;
+// summaries hold the engagement rate as a fraction of views
+var smartListColumns = map[models.SmartListField]string{
+	models.SmartListDuration:   "videos.duration",
+	models.SmartListViews:      "COALESCE(video_stats_summaries.views, 0)",
+	models.SmartListEngagement: "COALESCE(video_stats_summaries.engagement_rate, 0) * 100",
 }
 
-func (r *videoRepository) List(tenantID string, limit, offset int) ([]*models.Video, error) {
+func (r *videoRepository) ListMatching(ctx context.Context, tenantID string, scope *models.VideoScope, conditions []models.SmartListCondition, limit, offset int) ([]*models.Video, error) {
+	query := forTenant(ctx, r.db, tenantID).Model(&models.Video{}).
+		Joins("LEFT JOIN video_stats_summaries ON video_stats_summaries.id = videos.id")
+	query = withinScope(query, scope)
+	for _, condition := range conditions {
+		query = matchCondition(query, condition)
+	}
 	var videos []*models.Video
-	err := r.db.Where("tenant_id = ?", tenantID).Limit(limit).Offset(offset).Find(&videos).Error
+	err := query.Order("videos.created_at DESC, videos.id").Limit(limit).Offset(offset).Find(&videos).Error
 	return videos, err
 }
 
-func (r *videoRepository) UpdateStatus(tenantID, id string, status models.VideoStatus) error {
-	return r.db.Model(&models.Video{}).Where("tenant_id = ? AND id = ?", tenantID, id).Update("status", status).Error
+// Search left-joins the stats summaries to sort by views; videos never
+// synced sort as having none. Ties are broken by ID so pages do not overlap.
+func (r *videoRepository) Search(ctx context.Context, tenantID string, scope *models.VideoScope, filter models.VideoListFilter, limit, offset int) ([]*models.Video, int64, error) {
+	query := withinScope(forTenant(ctx, r.db, tenantID).Model(&models.Video{}), scope)
+	if filter.Status != "" {
+		query = query.Where("videos.status = ?", filter.Status)
+	}
+	if filter.Tag != "" {
+		query = query.Where("JSON_CONTAINS(videos.tags, JSON_QUOTE(?))", filter.Tag)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("videos.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("videos.created_at < ?", *filter.CreatedBefore)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := models.VideoSortColumns[filter.Sort]
+	if !ok {
+		column = models.VideoSortColumns["created_at"]
+	}
+	direction := "ASC"
+	if filter.Desc {
+		direction = "DESC"
+	}
+	if filter.Sort == "views" {
+		query = query.Joins("LEFT JOIN video_stats_summaries ON video_stats_summaries.id = videos.id")
+	}
+	var videos []*models.Video
+	err := query.Select("videos.*").
+		Order(fmt.Sprintf("%s %s, videos.id", column, direction)).
+		Limit(limit).Offset(offset).
+		Find(&videos).Error
+	return videos, total, err
+}
+
+// withinScope narrows query to the videos scope sees; a nil scope sees them all
+func withinScope(query *gorm.DB, scope *models.VideoScope) *gorm.DB {
+	switch {
+	case scope == nil:
+		return query
+	case len(scope.WorkspaceIDs) > 0:
+		return query.Where("videos.visibility = ? OR videos.user_id = ? OR (videos.visibility = ? AND videos.workspace_id IN ?)",
+			models.VisibilityTenant, scope.UserID, models.VisibilityWorkspace, scope.WorkspaceIDs)
+	default:
+		return query.Where("videos.visibility = ? OR videos.user_id = ?", models.VisibilityTenant, scope.UserID)
+	}
+}
+
+// matchCondition narrows query to the videos meeting a parsed condition.
+// Operators come from models.ParseSmartListFilter, never from the caller.
+func matchCondition(query *gorm.DB, condition models.SmartListCondition) *gorm.DB {
+	negate := condition.Operator == "!="
+	switch condition.Field {
+	case models.SmartListStatus:
+		return query.Where("videos.status "+condition.Operator+" ?", condition.Value)
+	case models.SmartListWorkspace:
+		return query.Where("COALESCE(videos.workspace_id, '') "+condition.Operator+" ?", condition.Value)
+	case models.SmartListPlatform:
+		published := publishedConditions[models.Platform(condition.Value)]
+		if negate {
+			return query.Where("NOT (" + published + ")")
+		}
+		return query.Where(published)
+	case models.SmartListTag:
+		contains := "COALESCE(JSON_CONTAINS(videos.tags, JSON_QUOTE(?)), 0) = 1"
+		if negate {
+			contains = "COALESCE(JSON_CONTAINS(videos.tags, JSON_QUOTE(?)), 0) = 0"
+		}
+		return query.Where(contains, condition.Value)
+	default:
+		return query.Where(smartListColumns[condition.Field]+" "+condition.Operator+" ?", condition.Number)
+	}
 }
 
-func (r *videoRepository) GetByStatus(tenantID string, status models.VideoStatus, limit, offset int) ([]*models.Video, error) {
+func (r *videoRepository) ListRestoring(ctx context.Context, limit int) ([]*models.Video, error) {
 	var videos []*models.Video
-	err := r.db.Where("tenant_id = ? AND status = ?", tenantID, status).Limit(limit).Offset(offset).Find(&videos).Error
+	err := allTenants(ctx, r.db).
+		Where("status = ? AND restore_status = ?", models.StatusArchived, models.RestoreInProgress).
+		Order("restore_requested_at").Limit(limit).Find(&videos).Error
 	return videos, err
 }
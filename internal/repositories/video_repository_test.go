@@ -0,0 +1,291 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gormDB.Use(tenancy.NewPlugin()))
+	return gormDB, mock
+}
+
+func TestVideoRepository_CreateAssignsUUIDv7(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO `videos`").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	first := &models.Video{TenantID: "tenant-1", UserID: "user-1", Title: "First", FileName: "a.mp4"}
+	second := &models.Video{TenantID: "tenant-1", UserID: "user-1", Title: "Second", FileName: "b.mp4"}
+	require.NoError(t, repo.Create(context.Background(), first))
+	require.NoError(t, repo.Create(context.Background(), second))
+
+	for _, v := range []*models.Video{first, second} {
+		u, err := uuid.Parse(v.ID)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), u.Version())
+	}
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Less(t, first.ID, second.ID, "UUIDv7 ids sort by creation order")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRepository_CreateDuplicateID(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `videos`").WillReturnError(&mysqlDuplicateKeyError{})
+	mock.ExpectRollback()
+
+	video := &models.Video{ID: "0190f3b2-0000-7000-8000-000000000001", TenantID: "tenant-1", Title: "Dup", FileName: "a.mp4"}
+	err := repo.Create(context.Background(), video)
+	assert.Error(t, err, "the primary key rejects duplicate ids")
+	assert.Equal(t, "0190f3b2-0000-7000-8000-000000000001", video.ID, "explicit ids are never regenerated")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRepository_GetByIDDecodesTags(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+
+	rows := sqlmock.NewRows([]string{"id", "tenant_id", "title", "tags"}).
+		AddRow("video-1", "tenant-1", "Tagged", []byte(`["go","tutorial"]`))
+	mock.ExpectQuery("SELECT \\* FROM `videos`").WillReturnRows(rows)
+	rows = sqlmock.NewRows([]string{"id", "tenant_id", "title", "tags"}).
+		AddRow("video-2", "tenant-1", "Legacy", nil)
+	mock.ExpectQuery("SELECT \\* FROM `videos`").WillReturnRows(rows)
+
+	video, err := repo.GetByID(context.Background(), "tenant-1", "video-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "tutorial"}, video.Tags)
+
+	legacy, err := repo.GetByID(context.Background(), "tenant-1", "video-2")
+	require.NoError(t, err)
+	assert.Nil(t, legacy.Tags)
+	assert.Equal(t, []string{}, legacy.GetTags())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRepository_GetByIDsUsesOneQuery(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `videos` WHERE id IN \\(\\?,\\?,\\?\\) AND `videos`.`tenant_id` = \\?").
+		WithArgs("video-1", "video-2", "video-3", "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}).
+			AddRow("video-1", "tenant-1").
+			AddRow("video-3", "tenant-1"))
+
+	videos, err := repo.GetByIDs(context.Background(), "tenant-1", []string{"video-1", "video-2", "video-3"})
+	require.NoError(t, err)
+	require.Len(t, videos, 2)
+	assert.Equal(t, "video-3", videos[1].ID)
+
+	videos, err = repo.GetByIDs(context.Background(), "tenant-1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, videos, "no ids issues no query")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRepository_ListVisibleKeepsTenantScope(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `videos` WHERE \\(visibility = \\? OR user_id = \\? OR \\(visibility = \\? AND workspace_id IN \\(\\?,\\?\\)\\)\\) AND `videos`.`tenant_id` = \\?").
+		WithArgs("tenant", "user-1", "workspace", "ws-1", "ws-2", "tenant-1", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}).AddRow("video-1", "tenant-1"))
+	mock.ExpectQuery("SELECT \\* FROM `videos` WHERE \\(visibility = \\? OR user_id = \\?\\) AND `videos`.`tenant_id` = \\?").
+		WithArgs("tenant", "user-1", "tenant-1", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}))
+
+	videos, err := repo.ListVisible(context.Background(), "tenant-1", "user-1", []string{"ws-1", "ws-2"}, 20, 0)
+	require.NoError(t, err)
+	assert.Len(t, videos, 1)
+	_, err = repo.ListVisible(context.Background(), "tenant-1", "user-1", nil, 20, 0)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRepository_CrossTenantAccessIsScoped(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+
+	// tenant-b asks for tenant-a's video: every statement carries tenant-b's filter
+	mock.ExpectQuery("SELECT \\* FROM `videos` WHERE id = \\? AND `videos`.`tenant_id` = \\?").
+		WithArgs("video-a", "tenant-b", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `videos` SET `status`=\\?,`updated_at`=\\? WHERE id = \\? AND `videos`.`tenant_id` = \\?").
+		WithArgs("ready", sqlmock.AnyArg(), "video-a", "tenant-b").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `videos` SET `deleted_at`=\\? WHERE id = \\? AND `videos`.`tenant_id` = \\?").
+		WithArgs(sqlmock.AnyArg(), "video-a", "tenant-b").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err := repo.GetByID(context.Background(), "tenant-b", "video-a")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+	require.NoError(t, repo.UpdateStatus(context.Background(), "tenant-b", "video-a", models.StatusReady))
+	require.NoError(t, repo.Delete(context.Background(), "tenant-b", "video-a"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRepository_RequiresTenant(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+
+	_, err := repo.List(context.Background(), "", 10, 0)
+	assert.ErrorIs(t, err, tenancy.ErrMissingTenant)
+	err = repo.Create(context.Background(), &models.Video{Title: "Orphan", FileName: "a.mp4"})
+	assert.ErrorIs(t, err, tenancy.ErrMissingTenant)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRepository_CancelledContextIssuesNoQuery(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := repo.List(ctx, "tenant-1", 10, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+	err = repo.Create(ctx, &models.Video{TenantID: "tenant-1", Title: "Late", FileName: "a.mp4"})
+	assert.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRepository_ListArchivable(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+	cutoff := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT \\* FROM `videos` WHERE \\(status = \\? AND updated_at < \\? AND s3_key <> ''\\) "+
+		"AND \\(COALESCE\\(youtube_id, ''\\) = '' .* COALESCE\\(snapchat_media_id, ''\\) = ''\\) "+
+		"AND `videos`.`tenant_id` = \\? AND `videos`.`deleted_at` IS NULL ORDER BY updated_at LIMIT \\?").
+		WithArgs(models.StatusReady, cutoff, "tenant-1", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}).AddRow("video-1", "tenant-1"))
+
+	videos, err := repo.ListArchivable(context.Background(), "tenant-1", cutoff, 50)
+	require.NoError(t, err)
+	require.Len(t, videos, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRepository_ListRestoringSpansTenants(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `videos` WHERE \\(status = \\? AND restore_status = \\?\\) "+
+		"AND `videos`.`deleted_at` IS NULL ORDER BY restore_requested_at LIMIT \\?").
+		WithArgs(models.StatusArchived, models.RestoreInProgress, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}).
+			AddRow("video-1", "tenant-1").
+			AddRow("video-2", "tenant-2"))
+
+	videos, err := repo.ListRestoring(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Len(t, videos, 2)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// mysqlDuplicateKeyError mimics MySQL error 1062
+type mysqlDuplicateKeyError struct{}
+
+func (*mysqlDuplicateKeyError) Error() string {
+	return "Error 1062 (23000): Duplicate entry for key 'videos.PRIMARY'"
+}
+
+func TestVideoRepository_ListRightsExpiring(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+	from := time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 11, 14, 0, 0, 0, 0, time.UTC)
+	expires := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT \\* FROM `videos` WHERE \\(rights_expires_at >= \\? AND rights_expires_at < \\?\\) AND `videos`.`tenant_id` = \\? "+
+		"AND `videos`.`deleted_at` IS NULL ORDER BY rights_expires_at LIMIT \\?").
+		WithArgs(from, to, "tenant-1", 500).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "rights_owner", "rights_platforms", "rights_expires_at"}).
+			AddRow("video-1", "tenant-1", "Archive Films Ltd", []byte(`["youtube"]`), expires))
+
+	videos, err := repo.ListRightsExpiring(context.Background(), "tenant-1", from, to, 500)
+	require.NoError(t, err)
+	require.Len(t, videos, 1)
+	assert.Equal(t, "Archive Films Ltd", videos[0].Rights.Owner)
+	assert.Equal(t, []string{"youtube"}, videos[0].Rights.Platforms)
+	assert.Equal(t, expires, *videos[0].Rights.ExpiresAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRepository_ListMatching(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+	conditions, err := models.ParseSmartListFilter("status=ready AND platform=tiktok AND tag!=draft AND engagement_rate>5%")
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT `videos`.`id`,.* FROM `videos` LEFT JOIN video_stats_summaries ON video_stats_summaries.id = videos.id "+
+		"WHERE \\(videos.visibility = \\? OR videos.user_id = \\?\\) AND videos.status = \\? "+
+		"AND COALESCE\\(videos.tiktok_id, ''\\) <> '' AND COALESCE\\(JSON_CONTAINS\\(videos.tags, JSON_QUOTE\\(\\?\\)\\), 0\\) = 0 "+
+		"AND COALESCE\\(video_stats_summaries.engagement_rate, 0\\) \\* 100 > \\? "+
+		"AND `videos`.`tenant_id` = \\? AND `videos`.`deleted_at` IS NULL ORDER BY videos.created_at DESC, videos.id LIMIT \\?").
+		WithArgs("tenant", "user-1", "ready", "draft", 5.0, "tenant-1", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}).AddRow("video-1", "tenant-1"))
+
+	videos, err := repo.ListMatching(context.Background(), "tenant-1", &models.VideoScope{UserID: "user-1"}, conditions, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, videos, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoRepository_Search(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoRepository(gormDB)
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	filter := models.VideoListFilter{Status: models.StatusReady, Tag: "teaser", CreatedFrom: &from, Sort: "views", Desc: true}
+
+	where := "WHERE \\(videos.visibility = \\? OR videos.user_id = \\?\\) AND videos.status = \\? " +
+		"AND JSON_CONTAINS\\(videos.tags, JSON_QUOTE\\(\\?\\)\\) AND videos.created_at >= \\? " +
+		"AND `videos`.`tenant_id` = \\? AND `videos`.`deleted_at` IS NULL"
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `videos` "+where).
+		WithArgs(models.VisibilityTenant, "user-1", models.StatusReady, "teaser", from, "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery("SELECT videos.\\* FROM `videos` LEFT JOIN video_stats_summaries ON video_stats_summaries.id = videos.id "+where+
+		" ORDER BY COALESCE\\(video_stats_summaries.views, 0\\) DESC, videos.id LIMIT \\? OFFSET \\?").
+		WithArgs(models.VisibilityTenant, "user-1", models.StatusReady, "teaser", from, "tenant-1", 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}).AddRow("video-3", "tenant-1"))
+
+	videos, total, err := repo.Search(context.Background(), "tenant-1", &models.VideoScope{UserID: "user-1"}, filter, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, videos, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,107 +1,656 @@
 package repositories
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
 )
 
 type videoStatsRepository struct {
 	db *gorm.DB
 }
 
+var _ models.VideoStatsRepository = (*videoStatsRepository)(nil)
+
 // NewVideoStatsRepository creates a new repository.
 func NewVideoStatsRepository(db *gorm.DB) models.VideoStatsRepository {
 	return &videoStatsRepository{db: db}
 }
 
-func (r *videoStatsRepository) Create(stats *models.VideoStats) error {
+func (r *videoStatsRepository) Create(ctx context.Context, stats *models.VideoStats) error {
 	if stats.ID == "" {
-		stats.ID = uuid.New().String()
+		stats.ID = id.New()
+	}
+	if err := forTenant(ctx, r.db, stats.TenantID).Create(stats).Error; err != nil {
+		return err
 	}
-	return r.db.Create(stats).Error
+	if err := r.replaceBreakdowns(ctx, stats.TenantID, []*models.VideoStats{stats}); err != nil {
+		return err
+	}
+	return r.RefreshSummaries(ctx, stats.TenantID, []string{stats.VideoID})
 }
 
-func (r *videoStatsRepository) GetByID(tenantID, id string) (*models.VideoStats, error) {
+func (r *videoStatsRepository) GetByID(ctx context.Context, tenantID, id string) (*models.VideoStats, error) {
 	var s models.VideoStats
-	err := r.db.Where("tenant_id = ? AND id = ?", tenantID, id).First(&s).Error
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&s).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, models.ErrNotFound
 	}
 	return &s, err
 }
 
-func (r *videoStatsRepository) GetByVideoID(tenantID, videoID string) ([]*models.VideoStats, error) {
+func (r *videoStatsRepository) GetByVideoID(ctx context.Context, tenantID, videoID string) ([]*models.VideoStats, error) {
 	var stats []*models.VideoStats
-	err := r.db.Where("tenant_id = ? AND video_id = ?", tenantID, videoID).Find(&stats).Error
+	err := forTenant(ctx, r.db, tenantID).Where("video_id = ?", videoID).Find(&stats).Error
 	return stats, err
 }
 
-func (r *videoStatsRepository) GetByVideoAndPlatform(tenantID, videoID, platform string) (*models.VideoStats, error) {
+func (r *videoStatsRepository) GetByVideoAndPlatform(ctx context.Context, tenantID, videoID, platform string) (*models.VideoStats, error) {
 	var s models.VideoStats
-	err := r.db.Where("tenant_id = ? AND video_id = ? AND platform = ?", tenantID, videoID, platform).First(&s).Error
+	err := forTenant(ctx, r.db, tenantID).Where("video_id = ? AND platform = ?", videoID, platform).First(&s).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, models.ErrNotFound
 	}
 	return &s, err
 }
 
-func (r *videoStatsRepository) Update(stats *models.VideoStats) error {
-	return r.db.Save(stats).Error
+func (r *videoStatsRepository) Update(ctx context.Context, stats *models.VideoStats) error {
+	if err := saveForTenant(ctx, r.db, stats.TenantID, stats); err != nil {
+		return err
+	}
+	if err := r.replaceBreakdowns(ctx, stats.TenantID, []*models.VideoStats{stats}); err != nil {
+		return err
+	}
+	return r.RefreshSummaries(ctx, stats.TenantID, []string{stats.VideoID})
+}
+
+// statsUpsertColumns are refreshed when a video already has stats for the
+// platform; clearing deleted_at revives a soft-deleted row
+var statsUpsertColumns = []string{
+	"external_id", "views", "likes", "dislikes", "comments", "shares", "subscribers",
+	"watch_time", "avg_watch_time", "click_through_rate", "engagement_rate", "revenue", "impressions",
+	"demographics", "traffic_sources", "last_sync_at", "updated_at", "deleted_at",
+}
+
+// UpsertBatch writes stats with multi-row INSERT ... ON DUPLICATE KEY UPDATE
+// statements in one transaction. Rows that already existed keep their ID; the
+// ID generated on the passed record is not read back.
+func (r *videoStatsRepository) UpsertBatch(ctx context.Context, tenantID string, stats []*models.VideoStats, batchSize int) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = models.DefaultStatsBatchSize
+	}
+	if batchSize > models.MaxStatsBatchSize {
+		batchSize = models.MaxStatsBatchSize
+	}
+	for _, s := range stats {
+		if s.ID == "" {
+			s.ID = id.New()
+		}
+	}
+	err := forTenant(ctx, r.db, tenantID).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "video_id"}, {Name: "platform"}},
+		DoUpdates: clause.AssignmentColumns(statsUpsertColumns),
+	}).CreateInBatches(stats, batchSize).Error
+	if err != nil {
+		return err
+	}
+	if err := r.replaceBreakdowns(ctx, tenantID, stats); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var videoIDs []string
+	for _, s := range stats {
+		if !seen[s.VideoID] {
+			seen[s.VideoID] = true
+			videoIDs = append(videoIDs, s.VideoID)
+		}
+	}
+	return r.RefreshSummaries(ctx, tenantID, videoIDs)
+}
+
+// replaceBreakdowns swaps the device and country views of the stats carrying
+// them for theirs; stats without keep the views stored before
+func (r *videoStatsRepository) replaceBreakdowns(ctx context.Context, tenantID string, stats []*models.VideoStats) error {
+	var deviceKeys, locationKeys [][]interface{}
+	var devices []*models.VideoStatsDevice
+	var locations []*models.VideoStatsLocation
+	for _, s := range stats {
+		if s.DeviceTypes != nil {
+			deviceKeys = append(deviceKeys, []interface{}{s.VideoID, s.Platform})
+			for device, views := range s.DeviceTypes {
+				devices = append(devices, &models.VideoStatsDevice{ID: id.New(), TenantID: tenantID, VideoID: s.VideoID, Platform: s.Platform, Device: device, Views: views})
+			}
+		}
+		if s.Locations != nil {
+			locationKeys = append(locationKeys, []interface{}{s.VideoID, s.Platform})
+			for country, views := range s.Locations {
+				locations = append(locations, &models.VideoStatsLocation{ID: id.New(), TenantID: tenantID, VideoID: s.VideoID, Platform: s.Platform, Country: country, Views: views})
+			}
+		}
+	}
+	if len(deviceKeys) == 0 && len(locationKeys) == 0 {
+		return nil
+	}
+
+	return forTenant(ctx, r.db, tenantID).Transaction(func(tx *gorm.DB) error {
+		if len(deviceKeys) > 0 {
+			if err := tx.Where("(video_id, platform) IN ?", deviceKeys).Delete(&models.VideoStatsDevice{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(devices) > 0 {
+			if err := tx.CreateInBatches(devices, models.DefaultStatsBatchSize).Error; err != nil {
+				return err
+			}
+		}
+		if len(locationKeys) > 0 {
+			if err := tx.Where("(video_id, platform) IN ?", locationKeys).Delete(&models.VideoStatsLocation{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(locations) > 0 {
+			return tx.CreateInBatches(locations, models.DefaultStatsBatchSize).Error
+		}
+		return nil
+	})
 }
 
-func (r *videoStatsRepository) Delete(tenantID, id string) error {
-	return r.db.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&models.VideoStats{}).Error
+func (r *videoStatsRepository) Delete(ctx context.Context, tenantID, id string) error {
+	var videoIDs []string
+	if err := forTenant(ctx, r.db, tenantID).Model(&models.VideoStats{}).Where("id = ?", id).Pluck("video_id", &videoIDs).Error; err != nil {
+		return err
+	}
+	if err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).Delete(&models.VideoStats{}).Error; err != nil {
+		return err
+	}
+	if len(videoIDs) == 0 {
+		return nil
+	}
+	return r.RefreshSummaries(ctx, tenantID, videoIDs)
 }
 
-func (r *videoStatsRepository) List(tenantID string, limit, offset int) ([]*models.VideoStats, error) {
+func (r *videoStatsRepository) List(ctx context.Context, tenantID string, limit, offset int) ([]*models.VideoStats, error) {
 	var stats []*models.VideoStats
-	err := r.db.Where("tenant_id = ?", tenantID).Limit(limit).Offset(offset).Find(&stats).Error
+	err := forTenant(ctx, r.db, tenantID).Limit(limit).Offset(offset).Find(&stats).Error
 	return stats, err
 }
 
-func (r *videoStatsRepository) GetByPlatform(tenantID, platform string, limit, offset int) ([]*models.VideoStats, error) {
+func (r *videoStatsRepository) GetByPlatform(ctx context.Context, tenantID, platform string, limit, offset int) ([]*models.VideoStats, error) {
 	var stats []*models.VideoStats
-	err := r.db.Where("tenant_id = ? AND platform = ?", tenantID, platform).Limit(limit).Offset(offset).Find(&stats).Error
+	err := forTenant(ctx, r.db, tenantID).Where("platform = ?", platform).Limit(limit).Offset(offset).Find(&stats).Error
 	return stats, err
 }
 
-func (r *videoStatsRepository) GetTopPerforming(tenantID string, metric string, limit int) ([]*models.VideoStats, error) {
+func (r *videoStatsRepository) CountByPlatform(ctx context.Context, tenantID, platform string) (int64, error) {
+	var count int64
+	err := forTenant(ctx, r.db, tenantID).Model(&models.VideoStats{}).Where("platform = ?", platform).Count(&count).Error
+	return count, err
+}
+
+func (r *videoStatsRepository) ListByPlatformAfter(ctx context.Context, tenantID, platform, afterID string, limit int) ([]*models.VideoStats, error) {
 	var stats []*models.VideoStats
-	order := metric + " DESC"
-	err := r.db.Where("tenant_id = ?", tenantID).Order(order).Limit(limit).Find(&stats).Error
+	err := forTenant(ctx, r.db, tenantID).
+		Where("platform = ? AND id > ?", platform, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&stats).Error
 	return stats, err
 }
 
-func (r *videoStatsRepository) GetAggregatedStats(tenantID, videoID string) (*models.StatsAggregation, error) {
+// GetTopPerforming reads the ranking from the summary table's per-metric index
+func (r *videoStatsRepository) GetTopPerforming(ctx context.Context, tenantID string, metric string, limit int) ([]*models.VideoStatsSummary, error) {
+	if !models.LeaderboardMetrics[metric] {
+		return nil, models.ErrInvalidInput
+	}
+	var summaries []*models.VideoStatsSummary
+	err := forTenant(ctx, r.db, tenantID).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: metric}, Desc: true}).
+		Order("id").
+		Limit(limit).
+		Find(&summaries).Error
+	return summaries, err
+}
+
+// summaryRefreshSQL recomputes summaries from the live stats rows. Videos
+// whose stats were all deleted are removed by the preceding DELETE.
+const summaryRefreshSQL = `INSERT INTO video_stats_summaries
+	(id, tenant_id, views, likes, comments, shares, revenue, engagement_rate, platform_count, refreshed_at)
+SELECT video_id, tenant_id, SUM(views), SUM(likes), SUM(comments), SUM(shares), SUM(revenue),
+	COALESCE((SUM(likes) + SUM(comments) + SUM(shares)) / NULLIF(SUM(views), 0), 0), COUNT(*), ?
+FROM video_stats
+WHERE tenant_id = ? AND deleted_at IS NULL%s
+GROUP BY tenant_id, video_id
+ON DUPLICATE KEY UPDATE views = VALUES(views), likes = VALUES(likes), comments = VALUES(comments),
+	shares = VALUES(shares), revenue = VALUES(revenue), engagement_rate = VALUES(engagement_rate),
+	platform_count = VALUES(platform_count), refreshed_at = VALUES(refreshed_at)`
+
+func (r *videoStatsRepository) RefreshSummaries(ctx context.Context, tenantID string, videoIDs []string) error {
+	if tenantID == "" {
+		return tenancy.ErrMissingTenant
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deleteSQL := "DELETE FROM video_stats_summaries WHERE tenant_id = ?"
+		insertSQL := fmt.Sprintf(summaryRefreshSQL, "")
+		args := []interface{}{tenantID}
+		if len(videoIDs) > 0 {
+			deleteSQL += " AND id IN ?"
+			insertSQL = fmt.Sprintf(summaryRefreshSQL, " AND video_id IN ?")
+			args = append(args, videoIDs)
+		}
+
+		if err := tx.Exec(deleteSQL, args...).Error; err != nil {
+			return err
+		}
+		return tx.Exec(insertSQL, append([]interface{}{time.Now().UTC()}, args...)...).Error
+	})
+}
+
+// aggregateColumns sums a video's stats across platforms
+const aggregateColumns = "video_id, SUM(views) as total_views, SUM(likes) as total_likes, SUM(comments) as total_comments, SUM(shares) as total_shares, SUM(revenue) as total_revenue, COUNT(platform) as platform_count"
+
+func (r *videoStatsRepository) GetAggregatedStats(ctx context.Context, tenantID, videoID string) (*models.StatsAggregation, error) {
 	var agg models.StatsAggregation
-	err := r.db.Model(&models.VideoStats{}).
-		Select("video_id, SUM(views) as total_views, SUM(likes) as total_likes, SUM(comments) as total_comments, SUM(shares) as total_shares, SUM(revenue) as total_revenue, COUNT(platform) as platform_count").
-		Where("tenant_id = ? AND video_id = ?", tenantID, videoID).
+	err := forTenant(ctx, r.db, tenantID).Model(&models.VideoStats{}).
+		Select(aggregateColumns).
+		Where("video_id = ?", videoID).
 		Group("video_id").
 		Scan(&agg).Error
 	return &agg, err
 }
 
-func (r *videoStatsRepository) CreateSnapshot(snapshot *models.VideoStatsSnapshot) error {
+// GetAggregatedStatsForVideos aggregates several videos in a single grouped
+// query. Videos without stats have no entry in the result.
+func (r *videoStatsRepository) GetAggregatedStatsForVideos(ctx context.Context, tenantID string, videoIDs []string) ([]*models.StatsAggregation, error) {
+	if len(videoIDs) == 0 {
+		return nil, nil
+	}
+	var aggs []*models.StatsAggregation
+	err := forTenant(ctx, r.db, tenantID).Model(&models.VideoStats{}).
+		Select(aggregateColumns).
+		Where("video_id IN ?", videoIDs).
+		Group("video_id").
+		Scan(&aggs).Error
+	return aggs, err
+}
+
+func (r *videoStatsRepository) GetPlatformTotals(ctx context.Context, tenantID string) ([]*models.PlatformTotals, error) {
+	var totals []*models.PlatformTotals
+	err := forTenant(ctx, r.db, tenantID).Model(&models.VideoStats{}).
+		Select("platform, COUNT(DISTINCT video_id) as total_videos, SUM(views) as total_views, SUM(likes) as total_likes, SUM(comments) as total_comments, SUM(shares) as total_shares, SUM(revenue) as total_revenue").
+		Group("platform").
+		Order("platform").
+		Scan(&totals).Error
+	return totals, err
+}
+
+func (r *videoStatsRepository) ListTrafficSources(ctx context.Context, tenantID string, from, to time.Time) ([]*models.VideoStats, error) {
+	var stats []*models.VideoStats
+	err := forTenant(ctx, r.db, tenantID).Model(&models.VideoStats{}).
+		Select("video_stats.video_id, video_stats.platform, video_stats.views, video_stats.traffic_sources").
+		Joins("JOIN videos ON videos.id = video_stats.video_id AND videos.deleted_at IS NULL").
+		Where("videos.created_at >= ? AND videos.created_at < ? AND video_stats.traffic_sources IS NOT NULL", from, to).
+		Order("video_stats.video_id, video_stats.platform").
+		Find(&stats).Error
+	return stats, err
+}
+
+func (r *videoStatsRepository) SumViewsByDevice(ctx context.Context, tenantID string, filter models.BreakdownFilter) ([]*models.BreakdownViews, error) {
+	return r.sumBreakdown(ctx, tenantID, &models.VideoStatsDevice{}, "video_stats_devices", "device", filter)
+}
+
+func (r *videoStatsRepository) SumViewsByCountry(ctx context.Context, tenantID string, filter models.BreakdownFilter) ([]*models.BreakdownViews, error) {
+	return r.sumBreakdown(ctx, tenantID, &models.VideoStatsLocation{}, "video_stats_locations", "country", filter)
+}
+
+// sumBreakdown sums the views of a breakdown table by its column, leaving out
+// the views of deleted stats rows and videos
+func (r *videoStatsRepository) sumBreakdown(ctx context.Context, tenantID string, model interface{}, table, column string, filter models.BreakdownFilter) ([]*models.BreakdownViews, error) {
+	query := forTenant(ctx, r.db, tenantID).Model(model).
+		Select(fmt.Sprintf("%[1]s.%[2]s AS `key`, SUM(%[1]s.views) AS views", table, column)).
+		Joins(fmt.Sprintf("JOIN video_stats ON video_stats.tenant_id = %[1]s.tenant_id AND video_stats.video_id = %[1]s.video_id AND video_stats.platform = %[1]s.platform AND video_stats.deleted_at IS NULL", table)).
+		Joins(fmt.Sprintf("JOIN videos ON videos.id = %s.video_id AND videos.deleted_at IS NULL", table)).
+		Where("videos.created_at >= ? AND videos.created_at < ?", filter.From, filter.To)
+	if filter.Platform != "" {
+		query = query.Where(table+".platform = ?", filter.Platform)
+	}
+	var views []*models.BreakdownViews
+	err := query.Group(table + "." + column).Order("views DESC, `key`").Scan(&views).Error
+	return views, err
+}
+
+func (r *videoStatsRepository) CreateSnapshot(ctx context.Context, snapshot *models.VideoStatsSnapshot) error {
 	if snapshot.ID == "" {
-		snapshot.ID = uuid.New().String()
+		snapshot.ID = id.New()
+	}
+	return r.db.WithContext(ctx).Create(snapshot).Error
+}
+
+func (r *videoStatsRepository) GetSnapshots(ctx context.Context, statsID string, limit int) ([]*models.VideoStatsSnapshot, error) {
+	var snaps []*models.VideoStatsSnapshot
+	err := r.db.WithContext(ctx).Where("stats_id = ?", statsID).Order("created_at DESC").Limit(limit).Find(&snaps).Error
+	return snaps, err
+}
+
+func (r *videoStatsRepository) GetFirstSnapshotAt(ctx context.Context, statsID string) (*time.Time, error) {
+	var first sql.NullTime
+	err := r.db.WithContext(ctx).Model(&models.VideoStatsSnapshot{}).
+		Select("MIN(created_at)").
+		Where("stats_id = ?", statsID).
+		Scan(&first).Error
+	if err != nil || !first.Valid {
+		return nil, err
 	}
-	return r.db.Create(snapshot).Error
+	return &first.Time, nil
 }
 
-func (r *videoStatsRepository) GetSnapshots(statsID string, limit int) ([]*models.VideoStatsSnapshot, error) {
+func (r *videoStatsRepository) ReplaceSnapshots(ctx context.Context, statsID string, from, to time.Time, snapshots []*models.VideoStatsSnapshot) error {
+	for _, snapshot := range snapshots {
+		if snapshot.ID == "" {
+			snapshot.ID = id.New()
+		}
+		snapshot.StatsID = statsID
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("stats_id = ? AND created_at >= ? AND created_at < ?", statsID, from, to).
+			Delete(&models.VideoStatsSnapshot{}).Error
+		if err != nil || len(snapshots) == 0 {
+			return err
+		}
+		return tx.CreateInBatches(snapshots, models.DefaultStatsBatchSize).Error
+	})
+}
+
+// GetHistory joins the snapshots to their stats row for the tenant check and
+// platform; the created_at bounds let MySQL prune partitions outside the range
+func (r *videoStatsRepository) GetHistory(ctx context.Context, tenantID, videoID string, from, to time.Time) ([]*models.VideoStatsSnapshot, error) {
 	var snaps []*models.VideoStatsSnapshot
-	err := r.db.Where("stats_id = ?", statsID).Order("created_at DESC").Limit(limit).Find(&snaps).Error
+	err := forTenant(ctx, r.db, tenantID).Model(&models.VideoStatsSnapshot{}).
+		Select("video_stats_snapshots.*, video_stats.platform").
+		Joins("JOIN video_stats ON video_stats.id = video_stats_snapshots.stats_id").
+		Where("video_stats.tenant_id = ? AND video_stats.video_id = ? AND video_stats.deleted_at IS NULL", tenantID, videoID).
+		Where("video_stats_snapshots.created_at >= ? AND video_stats_snapshots.created_at < ?", from, to).
+		Order("video_stats_snapshots.created_at").
+		Find(&snaps).Error
 	return snaps, err
 }
 
-func (r *videoStatsRepository) GetStatsNeedingSync(olderThan time.Time, limit int) ([]*models.VideoStats, error) {
+// ListScored left-joins the scores, so rows synced since the last computation
+// are listed without one. Rows without a score sort last either way.
+func (r *videoStatsRepository) ListScored(ctx context.Context, tenantID string, scope *models.VideoScope, filter models.StatsListFilter, limit, offset int) ([]*models.VideoStats, int64, error) {
+	query := statsWithinScope(forTenant(ctx, r.db, tenantID).Model(&models.VideoStats{}).
+		Joins("LEFT JOIN video_stats_scores ON video_stats_scores.id = video_stats.id"), scope)
+	if filter.Platform != "" {
+		query = query.Where("video_stats.platform = ?", filter.Platform)
+	}
+	if filter.MinScore != nil {
+		query = query.Where("video_stats_scores.score >= ?", *filter.MinScore)
+	}
+	if filter.MaxScore != nil {
+		query = query.Where("video_stats_scores.score <= ?", *filter.MaxScore)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := models.StatsSortColumns[filter.Sort]
+	if !ok {
+		column = models.StatsSortColumns["score"]
+	}
+	direction := "ASC"
+	if filter.Desc {
+		direction = "DESC"
+	}
+	var stats []*models.VideoStats
+	err := query.Select("video_stats.*, video_stats_scores.score, video_stats_scores.computed_at AS scored_at").
+		Order(fmt.Sprintf("%s IS NULL, %s %s, video_stats.id", column, column, direction)).
+		Limit(limit).Offset(offset).
+		Find(&stats).Error
+	return stats, total, err
+}
+
+func (r *videoStatsRepository) ReplaceScores(ctx context.Context, tenantID string, scores []*models.VideoStatsScore) error {
+	if tenantID == "" {
+		return tenancy.ErrMissingTenant
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM video_stats_scores WHERE tenant_id = ?", tenantID).Error; err != nil || len(scores) == 0 {
+			return err
+		}
+		return forTenant(ctx, tx, tenantID).CreateInBatches(scores, models.DefaultStatsBatchSize).Error
+	})
+}
+
+func (r *videoStatsRepository) GetScoredAt(ctx context.Context, tenantID string) (*time.Time, error) {
+	var scoredAt sql.NullTime
+	err := forTenant(ctx, r.db, tenantID).Model(&models.VideoStatsScore{}).
+		Select("MAX(computed_at)").
+		Scan(&scoredAt).Error
+	if err != nil || !scoredAt.Valid {
+		return nil, err
+	}
+	return &scoredAt.Time, nil
+}
+
+func (r *videoStatsRepository) GetOldestSnapshotAt(ctx context.Context, tenantID string) (*time.Time, error) {
+	var oldest sql.NullTime
+	err := forTenant(ctx, r.db, tenantID).Model(&models.VideoStatsSnapshot{}).
+		Select("MIN(video_stats_snapshots.created_at)").
+		Joins("JOIN video_stats ON video_stats.id = video_stats_snapshots.stats_id").
+		Where("video_stats.tenant_id = ?", tenantID).
+		Scan(&oldest).Error
+	if err != nil || !oldest.Valid {
+		return nil, err
+	}
+	return &oldest.Time, nil
+}
+
+// compactSnapshotsSQL deletes every snapshot with a later one of the same
+// stats row and day; ties on created_at keep the highest ID. Both sides are
+// bounded on created_at so MySQL only reads the partitions in range.
+const compactSnapshotsSQL = `DELETE s FROM video_stats_snapshots s
+JOIN video_stats v ON v.id = s.stats_id
+JOIN video_stats_snapshots later ON later.stats_id = s.stats_id
+	AND later.created_at >= ? AND later.created_at < ?
+	AND DATE(later.created_at) = DATE(s.created_at)
+	AND (later.created_at > s.created_at OR (later.created_at = s.created_at AND later.id > s.id))
+WHERE v.tenant_id = ? AND s.created_at >= ? AND s.created_at < ?`
+
+func (r *videoStatsRepository) CompactSnapshots(ctx context.Context, tenantID string, from, to time.Time) (int64, error) {
+	if tenantID == "" {
+		return 0, tenancy.ErrMissingTenant
+	}
+	result := r.db.WithContext(ctx).Exec(compactSnapshotsSQL, from, to, tenantID, from, to)
+	return result.RowsAffected, result.Error
+}
+
+func (r *videoStatsRepository) DeleteSnapshotsBefore(ctx context.Context, tenantID string, before time.Time) (int64, error) {
+	if tenantID == "" {
+		return 0, tenancy.ErrMissingTenant
+	}
+	result := r.db.WithContext(ctx).Exec(`DELETE s FROM video_stats_snapshots s
+JOIN video_stats v ON v.id = s.stats_id
+WHERE v.tenant_id = ? AND s.created_at < ?`, tenantID, before)
+	return result.RowsAffected, result.Error
+}
+
+func (r *videoStatsRepository) GetStatsNeedingSync(ctx context.Context, olderThan time.Time, limit int) ([]*models.VideoStats, error) {
 	var stats []*models.VideoStats
-	err := r.db.Where("last_sync_at <= ?", olderThan).Limit(limit).Find(&stats).Error
+	err := allTenants(ctx, r.db).Where("last_sync_at <= ?", olderThan).Limit(limit).Find(&stats).Error
 	return stats, err
 }
+
+func (r *videoStatsRepository) GetSyncStatusByTenant(ctx context.Context, limit int) ([]*models.TenantStatsSync, error) {
+	var statuses []*models.TenantStatsSync
+	err := allTenants(ctx, r.db).Model(&models.VideoStats{}).
+		Select("tenant_id, COUNT(*) AS stats, MAX(last_sync_at) AS last_sync_at, MIN(last_sync_at) AS oldest_sync_at").
+		Group("tenant_id").
+		Order("last_sync_at").
+		Limit(limit).
+		Scan(&statuses).Error
+	return statuses, err
+}
+
+func (r *videoStatsRepository) GetLastSyncByPlatform(ctx context.Context) ([]*models.PlatformStatsSync, error) {
+	var syncs []*models.PlatformStatsSync
+	err := allTenants(ctx, r.db).Model(&models.VideoStats{}).
+		Select("tenant_id, platform, MAX(last_sync_at) AS last_sync_at").
+		Group("tenant_id, platform").
+		Order("tenant_id, platform").
+		Scan(&syncs).Error
+	return syncs, err
+}
+
+// Stream reads rows one at a time from a database cursor, so memory stays
+// flat however many rows the tenant has. fn runs while the cursor is open:
+// a slow consumer holds the connection rather than buffering rows.
+func (r *videoStatsRepository) Stream(ctx context.Context, tenantID string, scope *models.VideoScope, platform string, fn func(*models.VideoStats) error) error {
+	query := statsWithinScope(forTenant(ctx, r.db, tenantID).Model(&models.VideoStats{}), scope).
+		Select("video_stats.*").Order("video_stats.id")
+	if platform != "" {
+		query = query.Where("video_stats.platform = ?", platform)
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		// Stop as soon as the client is gone rather than on the next read
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var s models.VideoStats
+		if err := r.db.ScanRows(rows, &s); err != nil {
+			return err
+		}
+		if err := fn(&s); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// statsWithinScope narrows stats rows to those of the videos the scope sees
+func statsWithinScope(query *gorm.DB, scope *models.VideoScope) *gorm.DB {
+	if scope == nil {
+		return query
+	}
+	return withinScope(query.Joins("JOIN videos ON videos.id = video_stats.video_id AND videos.deleted_at IS NULL"), scope)
+}
+
+// analyticsDimensions are the expressions analytics queries group by
+var analyticsDimensions = map[models.AnalyticsDimension]string{
+	models.AnalyticsPlatform:    "video_stats.platform",
+	models.AnalyticsVideo:       "video_stats.video_id",
+	models.AnalyticsCampaign:    "videos.campaign_id",
+	models.AnalyticsWorkspace:   "videos.workspace_id",
+	models.AnalyticsVideoStatus: "videos.status",
+	models.AnalyticsMonth:       "DATE_FORMAT(videos.created_at, '%Y-%m')",
+}
+
+// analyticsMeasures are the aggregates analytics queries compute; the
+// engagement rate is weighted by views rather than averaged over rows
+var analyticsMeasures = map[models.AnalyticsMeasure]string{
+	models.AnalyticsViews:       "SUM(video_stats.views)",
+	models.AnalyticsLikes:       "SUM(video_stats.likes)",
+	models.AnalyticsComments:    "SUM(video_stats.comments)",
+	models.AnalyticsShares:      "SUM(video_stats.shares)",
+	models.AnalyticsWatchTime:   "SUM(video_stats.watch_time)",
+	models.AnalyticsImpressions: "SUM(video_stats.impressions)",
+	models.AnalyticsRevenue:     "SUM(video_stats.revenue)",
+	models.AnalyticsVideos:      "COUNT(DISTINCT video_stats.video_id)",
+	models.AnalyticsEngagement:  "COALESCE(SUM(video_stats.likes + video_stats.comments + video_stats.shares) * 100 / NULLIF(SUM(video_stats.views), 0), 0)",
+}
+
+// Query builds the statement from the whitelisted expressions only; the
+// values of filters are bound, never written into the statement
+func (r *videoStatsRepository) Query(ctx context.Context, tenantID string, q *models.AnalyticsQuery) (*models.AnalyticsResult, error) {
+	selects := make([]string, 0, len(q.Dimensions)+len(q.Measures))
+	groups := make([]string, 0, len(q.Dimensions))
+	for _, dimension := range q.Dimensions {
+		selects = append(selects, fmt.Sprintf("%s AS `%s`", analyticsDimensions[dimension], dimension))
+		groups = append(groups, analyticsDimensions[dimension])
+	}
+	for _, measure := range q.Measures {
+		selects = append(selects, fmt.Sprintf("%s AS `%s`", analyticsMeasures[measure], measure))
+	}
+
+	query := forTenant(ctx, r.db, tenantID).Model(&models.VideoStats{}).
+		Select(strings.Join(selects, ", ")).
+		Joins("JOIN videos ON videos.id = video_stats.video_id AND videos.deleted_at IS NULL")
+	for _, filter := range q.Filters {
+		operator := "IN"
+		if filter.Operator == "not_in" {
+			operator = "NOT IN"
+		}
+		query = query.Where(fmt.Sprintf("COALESCE(%s, '') %s ?", analyticsDimensions[filter.Dimension], operator), filter.Values)
+	}
+	if len(groups) > 0 {
+		query = query.Group(strings.Join(groups, ", "))
+	}
+	for _, order := range q.OrderBy {
+		direction := "ASC"
+		if order.Desc {
+			direction = "DESC"
+		}
+		query = query.Order(fmt.Sprintf("`%s` %s", order.Field, direction))
+	}
+
+	rows, err := query.Limit(q.Limit).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &models.AnalyticsResult{Columns: q.Columns(), Rows: [][]interface{}{}}
+	for rows.Next() {
+		dimensions := make([]sql.NullString, len(q.Dimensions))
+		measures := make([]sql.NullFloat64, len(q.Measures))
+		dest := make([]interface{}, 0, len(result.Columns))
+		for i := range dimensions {
+			dest = append(dest, &dimensions[i])
+		}
+		for i := range measures {
+			dest = append(dest, &measures[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := make([]interface{}, 0, len(result.Columns))
+		for _, dimension := range dimensions {
+			if dimension.Valid {
+				row = append(row, dimension.String)
+			} else {
+				row = append(row, nil)
+			}
+		}
+		for i, measure := range measures {
+			switch q.Measures[i] {
+			case models.AnalyticsRevenue, models.AnalyticsEngagement:
+				row = append(row, measure.Float64)
+			default:
+				row = append(row, int64(measure.Float64))
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
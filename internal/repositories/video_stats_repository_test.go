@@ -0,0 +1,486 @@
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
+)
+
+func TestVideoStatsRepository_GetAggregatedStatsForVideos(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	columns := []string{"video_id", "total_views", "total_likes", "total_comments", "total_shares", "total_revenue", "platform_count"}
+	mock.ExpectQuery("SELECT video_id, SUM\\(views\\) .* FROM `video_stats` WHERE video_id IN \\(\\?,\\?\\) AND `video_stats`.`tenant_id` = \\? AND `video_stats`.`deleted_at` IS NULL GROUP BY `video_id`").
+		WithArgs("video-1", "video-2", "tenant-1").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("video-1", 1500, 120, 30, 12, 4.5, 3).
+			AddRow("video-2", 80, 4, 1, 0, 0, 1))
+
+	aggs, err := repo.GetAggregatedStatsForVideos(context.Background(), "tenant-1", []string{"video-1", "video-2"})
+	require.NoError(t, err)
+	require.Len(t, aggs, 2)
+	assert.Equal(t, "video-1", aggs[0].VideoID)
+	assert.Equal(t, int64(1500), aggs[0].TotalViews)
+	assert.Equal(t, 3, aggs[0].PlatformCount)
+	assert.Equal(t, int64(80), aggs[1].TotalViews)
+
+	aggs, err = repo.GetAggregatedStatsForVideos(context.Background(), "tenant-1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, aggs, "no ids issues no query")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_GetPlatformTotals(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	columns := []string{"platform", "total_videos", "total_views", "total_likes", "total_comments", "total_shares", "total_revenue"}
+	mock.ExpectQuery("SELECT platform, COUNT\\(DISTINCT video_id\\) as total_videos, .* FROM `video_stats` WHERE `video_stats`.`tenant_id` = \\? AND `video_stats`.`deleted_at` IS NULL GROUP BY `platform` ORDER BY platform").
+		WithArgs("tenant-1").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("tiktok", 2, 30000, 2800, 320, 1200, 0).
+			AddRow("youtube", 3, 50000, 3500, 450, 800, 120.5))
+
+	totals, err := repo.GetPlatformTotals(context.Background(), "tenant-1")
+	require.NoError(t, err)
+	require.Len(t, totals, 2)
+	assert.Equal(t, &models.PlatformTotals{Platform: "tiktok", TotalVideos: 2, TotalViews: 30000, TotalLikes: 2800, TotalComments: 320, TotalShares: 1200}, totals[0])
+	assert.Equal(t, 120.5, totals[1].TotalRevenue)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_ListTrafficSources(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	from := time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 30)
+	mock.ExpectQuery("SELECT video_stats.video_id, video_stats.platform, video_stats.views, video_stats.traffic_sources FROM `video_stats` JOIN videos ON videos.id = video_stats.video_id AND videos.deleted_at IS NULL WHERE \\(videos.created_at >= \\? AND videos.created_at < \\? AND video_stats.traffic_sources IS NOT NULL\\) AND `video_stats`.`tenant_id` = \\? AND `video_stats`.`deleted_at` IS NULL ORDER BY video_stats.video_id, video_stats.platform").
+		WithArgs(from, to, "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"video_id", "platform", "views", "traffic_sources"}).
+			AddRow("video-1", "youtube", 1000, `{"search":0.25,"suggested":0.75}`))
+
+	stats, err := repo.ListTrafficSources(context.Background(), "tenant-1", from, to)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, models.TrafficSources{"search": 0.25, "suggested": 0.75}, stats[0].TrafficSources)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_StreamWalksCursor(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	rows := sqlmock.NewRows([]string{"id", "tenant_id", "video_id", "platform", "views"}).
+		AddRow("stats-1", "tenant-1", "video-1", "tiktok", 10).
+		AddRow("stats-2", "tenant-1", "video-2", "tiktok", 20).
+		AddRow("stats-3", "tenant-1", "video-3", "tiktok", 30)
+	mock.ExpectQuery("SELECT video_stats.\\* FROM `video_stats` WHERE video_stats.platform = \\? AND `video_stats`.`tenant_id` = \\? AND `video_stats`.`deleted_at` IS NULL ORDER BY video_stats.id").
+		WithArgs("tiktok", "tenant-1").
+		WillReturnRows(rows)
+
+	var views []int64
+	stop := errors.New("client went away")
+	err := repo.Stream(context.Background(), "tenant-1", nil, "tiktok", func(s *models.VideoStats) error {
+		views = append(views, s.Views)
+		if len(views) == 2 {
+			return stop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, stop, "the callback error stops the walk")
+	assert.Equal(t, []int64{10, 20}, views)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_StreamWithinScope(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	mock.ExpectQuery("SELECT video_stats.\\* FROM `video_stats` JOIN videos ON videos.id = video_stats.video_id AND videos.deleted_at IS NULL "+
+		"WHERE \\(videos.visibility = \\? OR videos.user_id = \\?\\) AND `video_stats`.`tenant_id` = \\? AND `video_stats`.`deleted_at` IS NULL ORDER BY video_stats.id").
+		WithArgs(models.VisibilityTenant, "user-1", "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "video_id", "platform", "views"}).
+			AddRow("stats-1", "tenant-1", "video-1", "tiktok", 10))
+
+	var views []int64
+	err := repo.Stream(context.Background(), "tenant-1", &models.VideoScope{UserID: "user-1"}, "", func(s *models.VideoStats) error {
+		views = append(views, s.Views)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{10}, views)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_StreamStopsWhenContextCancelled(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	rows := sqlmock.NewRows([]string{"id", "tenant_id", "video_id", "platform", "views"}).
+		AddRow("stats-1", "tenant-1", "video-1", "tiktok", 10).
+		AddRow("stats-2", "tenant-1", "video-2", "tiktok", 20)
+	mock.ExpectQuery("SELECT video_stats.\\* FROM `video_stats`").WillReturnRows(rows)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var views []int64
+	err := repo.Stream(ctx, "tenant-1", nil, "tiktok", func(s *models.VideoStats) error {
+		views = append(views, s.Views)
+		cancel() // the client disconnects after the first row
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int64{10}, views, "no row is read after the disconnect")
+}
+
+func TestVideoStatsRepository_UpsertBatch(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	stats := []*models.VideoStats{
+		{VideoID: "video-1", Platform: "youtube", Views: 10},
+		{VideoID: "video-1", Platform: "tiktok", Views: 20},
+		{VideoID: "video-2", Platform: "youtube", Views: 30},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `video_stats` .* VALUES \\(.*\\),\\(.*\\) ON DUPLICATE KEY UPDATE `external_id`=VALUES\\(`external_id`\\),`views`=VALUES\\(`views`\\).*`deleted_at`=VALUES\\(`deleted_at`\\)$").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO `video_stats` .* VALUES \\([^)]*\\) ON DUPLICATE KEY UPDATE").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectSummaryRefresh(mock, "tenant-1", "video-1", "video-2")
+
+	require.NoError(t, repo.UpsertBatch(context.Background(), "tenant-1", stats, 2))
+	for _, s := range stats {
+		assert.Equal(t, "tenant-1", s.TenantID)
+		assert.NotEmpty(t, s.ID)
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_UpsertBatchRejectsForeignRows(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	err := repo.UpsertBatch(context.Background(), "tenant-1", []*models.VideoStats{
+		{VideoID: "video-1", Platform: "youtube"},
+		{TenantID: "tenant-2", VideoID: "video-9", Platform: "youtube"},
+	}, 0)
+	assert.ErrorIs(t, err, tenancy.ErrTenantMismatch)
+	require.NoError(t, mock.ExpectationsWereMet(), "nothing is written")
+}
+
+// expectSummaryRefresh expects the summaries of videoIDs to be rebuilt
+func expectSummaryRefresh(mock sqlmock.Sqlmock, tenantID string, videoIDs ...string) {
+	args := []driver.Value{tenantID}
+	for _, id := range videoIDs {
+		args = append(args, id)
+	}
+	in := strings.TrimSuffix(strings.Repeat("\\?,", len(videoIDs)), ",")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM video_stats_summaries WHERE tenant_id = \\? AND id IN \\(" + in + "\\)").
+		WithArgs(args...).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO video_stats_summaries .* FROM video_stats\\s+WHERE tenant_id = \\? AND deleted_at IS NULL AND video_id IN \\(" + in + "\\)\\s+GROUP BY tenant_id, video_id").
+		WithArgs(append([]driver.Value{sqlmock.AnyArg()}, args...)...).
+		WillReturnResult(sqlmock.NewResult(0, int64(len(videoIDs))))
+	mock.ExpectCommit()
+}
+
+func TestVideoStatsRepository_CreateReplacesBreakdowns(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `video_stats`").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `video_stats_devices` WHERE \\(video_id, platform\\) IN \\(\\(\\?,\\?\\)\\) AND `video_stats_devices`.`tenant_id` = \\?").
+		WithArgs("video-1", "youtube", "tenant-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO `video_stats_devices`").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectSummaryRefresh(mock, "tenant-1", "video-1")
+
+	require.NoError(t, repo.Create(context.Background(), &models.VideoStats{
+		ID: "stats-1", TenantID: "tenant-1", VideoID: "video-1", Platform: "youtube",
+		DeviceTypes: map[string]int64{models.DeviceMobile: 100},
+	}))
+	require.NoError(t, mock.ExpectationsWereMet(), "locations are left alone without a map")
+}
+
+func TestVideoStatsRepository_SumViewsByDevice(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	from := time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 30)
+	mock.ExpectQuery("SELECT video_stats_devices.device AS `key`, SUM\\(video_stats_devices.views\\) AS views FROM `video_stats_devices` JOIN video_stats ON .* AND video_stats.deleted_at IS NULL JOIN videos ON videos.id = video_stats_devices.video_id AND videos.deleted_at IS NULL WHERE \\(videos.created_at >= \\? AND videos.created_at < \\?\\) AND video_stats_devices.platform = \\? AND `video_stats_devices`.`tenant_id` = \\? GROUP BY `video_stats_devices`.`device` ORDER BY views DESC, `key`").
+		WithArgs(from, to, "youtube", "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "views"}).AddRow("mobile", 700).AddRow("desktop", 300))
+
+	views, err := repo.SumViewsByDevice(context.Background(), "tenant-1", models.BreakdownFilter{Platform: "youtube", From: from, To: to})
+	require.NoError(t, err)
+	require.Len(t, views, 2)
+	assert.Equal(t, &models.BreakdownViews{Key: "mobile", Views: 700}, views[0])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_WritesRefreshSummaries(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `video_stats`").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectSummaryRefresh(mock, "tenant-1", "video-1")
+
+	mock.ExpectQuery("SELECT `video_id` FROM `video_stats` WHERE id = \\? AND `video_stats`.`tenant_id` = \\?").
+		WithArgs("stats-1", "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"video_id"}).AddRow("video-1"))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `video_stats` SET `deleted_at`=\\?").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectSummaryRefresh(mock, "tenant-1", "video-1")
+
+	require.NoError(t, repo.Create(context.Background(), &models.VideoStats{ID: "stats-1", TenantID: "tenant-1", VideoID: "video-1", Platform: "youtube"}))
+	require.NoError(t, repo.Delete(context.Background(), "tenant-1", "stats-1"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_RefreshSummariesForTenant(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM video_stats_summaries WHERE tenant_id = \\?$").
+		WithArgs("tenant-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO video_stats_summaries .* WHERE tenant_id = \\? AND deleted_at IS NULL\\s+GROUP BY").
+		WithArgs(sqlmock.AnyArg(), "tenant-1").
+		WillReturnResult(sqlmock.NewResult(0, 12))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.RefreshSummaries(context.Background(), "tenant-1", nil))
+	assert.ErrorIs(t, repo.RefreshSummaries(context.Background(), "", nil), tenancy.ErrMissingTenant)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_GetTopPerforming(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `video_stats_summaries` WHERE `video_stats_summaries`.`tenant_id` = \\? ORDER BY `engagement_rate` DESC,id LIMIT \\?").
+		WithArgs("tenant-1", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "engagement_rate"}).
+			AddRow("video-2", "tenant-1", 0.31).
+			AddRow("video-1", "tenant-1", 0.12))
+
+	top, err := repo.GetTopPerforming(context.Background(), "tenant-1", "engagement_rate", 2)
+	require.NoError(t, err)
+	require.Len(t, top, 2)
+	assert.Equal(t, "video-2", top[0].ID)
+
+	_, err = repo.GetTopPerforming(context.Background(), "tenant-1", "views; DROP TABLE videos", 2)
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "metrics are never interpolated unchecked")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_GetHistoryBoundsPartitionColumn(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	from := time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT video_stats_snapshots.\\*, video_stats.platform FROM `video_stats_snapshots` JOIN video_stats ON video_stats.id = video_stats_snapshots.stats_id "+
+		"WHERE \\(video_stats.tenant_id = \\? AND video_stats.video_id = \\? AND video_stats.deleted_at IS NULL\\) "+
+		"AND \\(video_stats_snapshots.created_at >= \\? AND video_stats_snapshots.created_at < \\?\\) ORDER BY video_stats_snapshots.created_at").
+		WithArgs("tenant-1", "video-1", from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stats_id", "views", "created_at", "platform"}).
+			AddRow("snap-1", "stats-1", 100, from.Add(time.Hour), "youtube"))
+
+	history, err := repo.GetHistory(context.Background(), "tenant-1", "video-1", from, to)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "youtube", history[0].Platform)
+	assert.Equal(t, int64(100), history[0].Views)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_GetSyncStatusByTenant(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+	last := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	oldest := time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT tenant_id, COUNT\\(\\*\\) AS stats, MAX\\(last_sync_at\\) AS last_sync_at, MIN\\(last_sync_at\\) AS oldest_sync_at " +
+		"FROM `video_stats` WHERE `video_stats`.`deleted_at` IS NULL GROUP BY `tenant_id` ORDER BY last_sync_at LIMIT \\?").
+		WithArgs(50).
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "stats", "last_sync_at", "oldest_sync_at"}).
+			AddRow("tenant-1", 120, last, oldest))
+
+	statuses, err := repo.GetSyncStatusByTenant(context.Background(), 50)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, int64(120), statuses[0].Stats)
+	assert.Equal(t, last, statuses[0].LastSyncAt)
+	assert.Equal(t, oldest, statuses[0].OldestSyncAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_GetLastSyncByPlatform(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+	last := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT tenant_id, platform, MAX\\(last_sync_at\\) AS last_sync_at FROM `video_stats` " +
+		"WHERE `video_stats`.`deleted_at` IS NULL GROUP BY tenant_id, platform ORDER BY tenant_id, platform").
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "platform", "last_sync_at"}).
+			AddRow("tenant-1", "youtube", last))
+
+	syncs, err := repo.GetLastSyncByPlatform(context.Background())
+	require.NoError(t, err)
+	require.Len(t, syncs, 1)
+	assert.Equal(t, models.PlatformStatsSync{TenantID: "tenant-1", Platform: "youtube", LastSyncAt: last}, *syncs[0])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_ReplaceSnapshots(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `video_stats_snapshots` WHERE stats_id = \\? AND created_at >= \\? AND created_at < \\?").
+		WithArgs("stats-1", from, to).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO `video_stats_snapshots` .* VALUES \\(.*\\),\\(.*\\)").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	snapshots := []*models.VideoStatsSnapshot{
+		{Views: 10, CreatedAt: from.Add(24*time.Hour - time.Second)},
+		{Views: 25, CreatedAt: from.Add(48*time.Hour - time.Second)},
+	}
+	require.NoError(t, repo.ReplaceSnapshots(context.Background(), "stats-1", from, to, snapshots))
+	for _, snapshot := range snapshots {
+		assert.Equal(t, "stats-1", snapshot.StatsID)
+		assert.NotEmpty(t, snapshot.ID)
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_GetFirstSnapshotAt(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+	first := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	query := "SELECT MIN\\(created_at\\) FROM `video_stats_snapshots` WHERE stats_id = \\?"
+	mock.ExpectQuery(query).WithArgs("stats-1").
+		WillReturnRows(sqlmock.NewRows([]string{"MIN(created_at)"}).AddRow(first))
+	mock.ExpectQuery(query).WithArgs("stats-2").
+		WillReturnRows(sqlmock.NewRows([]string{"MIN(created_at)"}).AddRow(nil))
+
+	at, err := repo.GetFirstSnapshotAt(context.Background(), "stats-1")
+	require.NoError(t, err)
+	require.NotNil(t, at)
+	assert.Equal(t, first, *at)
+
+	at, err = repo.GetFirstSnapshotAt(context.Background(), "stats-2")
+	require.NoError(t, err)
+	assert.Nil(t, at, "no snapshot yet")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_CompactSnapshots(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	mock.ExpectExec("DELETE s FROM video_stats_snapshots s\\s+JOIN video_stats v ON v.id = s.stats_id\\s+"+
+		"JOIN video_stats_snapshots later ON later.stats_id = s.stats_id .*"+
+		"WHERE v.tenant_id = \\? AND s.created_at >= \\? AND s.created_at < \\?").
+		WithArgs(from, to, "tenant-1", from, to).
+		WillReturnResult(sqlmock.NewResult(0, 46))
+
+	deleted, err := repo.CompactSnapshots(context.Background(), "tenant-1", from, to)
+	require.NoError(t, err)
+	assert.Equal(t, int64(46), deleted)
+
+	_, err = repo.CompactSnapshots(context.Background(), "", from, to)
+	assert.ErrorIs(t, err, tenancy.ErrMissingTenant)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_ListScored(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+	minScore := 50.0
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `video_stats` LEFT JOIN video_stats_scores ON video_stats_scores.id = video_stats.id "+
+		"WHERE video_stats.platform = \\? AND video_stats_scores.score >= \\? AND `video_stats`.`tenant_id` = \\? AND `video_stats`.`deleted_at` IS NULL").
+		WithArgs("youtube", minScore, "tenant-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery("SELECT video_stats.\\*, video_stats_scores.score, video_stats_scores.computed_at AS scored_at FROM `video_stats` .* "+
+		"ORDER BY video_stats_scores.score IS NULL, video_stats_scores.score DESC, video_stats.id LIMIT \\? OFFSET \\?").
+		WithArgs("youtube", minScore, "tenant-1", 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "video_id", "platform", "views", "score"}).
+			AddRow("stats-3", "video-3", "youtube", 120, 51.5))
+
+	filter := models.StatsListFilter{Platform: "youtube", MinScore: &minScore, Sort: "score", Desc: true}
+	stats, total, err := repo.ListScored(context.Background(), "tenant-1", nil, filter, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, stats, 1)
+	require.NotNil(t, stats[0].Score)
+	assert.Equal(t, 51.5, *stats[0].Score)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoStatsRepository_Query(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoStatsRepository(gormDB)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT video_stats.platform AS `platform`, SUM(video_stats.views) AS `views`, "+
+		"COALESCE(SUM(video_stats.likes + video_stats.comments + video_stats.shares) * 100 / NULLIF(SUM(video_stats.views), 0), 0) AS `engagement_rate` "+
+		"FROM `video_stats` JOIN videos ON videos.id = video_stats.video_id AND videos.deleted_at IS NULL "+
+		"WHERE COALESCE(videos.status, '') NOT IN (?) AND `video_stats`.`tenant_id` = ? AND `video_stats`.`deleted_at` IS NULL "+
+		"GROUP BY `video_stats`.`platform` ORDER BY `views` DESC LIMIT ?")).
+		WithArgs("archived", "acme", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"platform", "views", "engagement_rate"}).
+			AddRow("youtube", 1200, 4.5).
+			AddRow(nil, 30, 0))
+
+	query := &models.AnalyticsQuery{
+		Dimensions: []models.AnalyticsDimension{models.AnalyticsPlatform},
+		Measures:   []models.AnalyticsMeasure{models.AnalyticsViews, models.AnalyticsEngagement},
+		Filters:    []models.AnalyticsFilter{{Dimension: models.AnalyticsVideoStatus, Operator: "not_in", Values: []string{"archived"}}},
+		OrderBy:    []models.AnalyticsOrder{{Field: "views", Desc: true}},
+		Limit:      10,
+	}
+	result, err := repo.Query(context.Background(), "acme", query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform", "views", "engagement_rate"}, result.Columns)
+	assert.Equal(t, [][]interface{}{
+		{"youtube", int64(1200), 4.5},
+		{nil, int64(30), 0.0},
+	}, result.Rows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// videoSummaryRepository implements models.VideoSummaryRepository.
+type videoSummaryRepository struct {
+	db *gorm.DB
+}
+
+var _ models.VideoSummaryRepository = (*videoSummaryRepository)(nil)
+
+// NewVideoSummaryRepository creates a new repository instance.
+func NewVideoSummaryRepository(db *gorm.DB) models.VideoSummaryRepository {
+	return &videoSummaryRepository{db: db}
+}
+
+func (r *videoSummaryRepository) Get(ctx context.Context, tenantID, videoID, language string) (*models.VideoSummary, error) {
+	var summary models.VideoSummary
+	err := forTenant(ctx, r.db, tenantID).Where("video_id = ? AND language = ?", videoID, language).First(&summary).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrVideoSummaryNotFound
+	}
+	return &summary, err
+}
+
+func (r *videoSummaryRepository) ListByVideos(ctx context.Context, tenantID string, videoIDs []string) ([]*models.VideoSummary, error) {
+	var summaries []*models.VideoSummary
+	if len(videoIDs) == 0 {
+		return summaries, nil
+	}
+	err := forTenant(ctx, r.db, tenantID).Where("video_id IN ?", videoIDs).Order("updated_at DESC").Find(&summaries).Error
+	return summaries, err
+}
+
+// Upsert replaces the summaries of an existing summary in the language
+func (r *videoSummaryRepository) Upsert(ctx context.Context, summary *models.VideoSummary) error {
+	if summary.ID == "" {
+		summary.ID = id.New()
+	}
+	return forTenant(ctx, r.db, summary.TenantID).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "video_id"}, {Name: "language"}},
+		DoUpdates: clause.AssignmentColumns([]string{"transcript_hash", "short", "medium", "long", "takeaways", "model", "updated_at"}),
+	}).Create(summary).Error
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestVideoSummaryRepository_UpsertReplacesLanguageSummary(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoSummaryRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `video_summaries` .* ON DUPLICATE KEY UPDATE " +
+		"`transcript_hash`=VALUES\\(`transcript_hash`\\),`short`=VALUES\\(`short`\\),`medium`=VALUES\\(`medium`\\)," +
+		"`long`=VALUES\\(`long`\\),`takeaways`=VALUES\\(`takeaways`\\),`model`=VALUES\\(`model`\\),`updated_at`=VALUES\\(`updated_at`\\)").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	summary := &models.VideoSummary{TenantID: "acme", VideoID: "video-1", Language: "en", TranscriptHash: "abc", Takeaways: []string{"One"}}
+	require.NoError(t, repo.Upsert(context.Background(), summary))
+	assert.NotEmpty(t, summary.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoSummaryRepository_GetAndList(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoSummaryRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `video_summaries` WHERE \\(video_id = \\? AND language = \\?\\) AND `video_summaries`.`tenant_id` = \\?").
+		WithArgs("video-1", "en", "acme", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT \\* FROM `video_summaries` WHERE video_id IN \\(\\?,\\?\\) AND `video_summaries`.`tenant_id` = \\? ORDER BY updated_at DESC").
+		WithArgs("video-1", "video-2", "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "video_id", "takeaways"}).AddRow("summary-1", "video-2", `["One","Two"]`))
+
+	_, err := repo.Get(context.Background(), "acme", "video-1", "en")
+	assert.ErrorIs(t, err, models.ErrVideoSummaryNotFound)
+
+	summaries, err := repo.ListByVideos(context.Background(), "acme", []string{"video-1", "video-2"})
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, []string{"One", "Two"}, summaries[0].Takeaways)
+
+	summaries, err = repo.ListByVideos(context.Background(), "acme", nil)
+	require.NoError(t, err)
+	assert.Empty(t, summaries, "no query without videos")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
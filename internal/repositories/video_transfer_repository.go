@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// videoTransferRepository implements models.VideoTransferRepository.
+type videoTransferRepository struct {
+	db *gorm.DB
+}
+
+var _ models.VideoTransferRepository = (*videoTransferRepository)(nil)
+
+// NewVideoTransferRepository creates a new repository instance.
+func NewVideoTransferRepository(db *gorm.DB) models.VideoTransferRepository {
+	return &videoTransferRepository{db: db}
+}
+
+func (r *videoTransferRepository) Create(ctx context.Context, transfer *models.VideoTransfer) error {
+	if transfer.ID == "" {
+		transfer.ID = id.New()
+	}
+	return forTenant(ctx, r.db, transfer.TenantID).Create(transfer).Error
+}
+
+func (r *videoTransferRepository) Get(ctx context.Context, tenantID, id string) (*models.VideoTransfer, error) {
+	return r.first(forTenant(ctx, r.db, tenantID).Where("id = ?", id))
+}
+
+// GetIncoming reads across tenants, since the row belongs to the sender; the
+// target filter keeps the recipient to the transfers sent to it
+func (r *videoTransferRepository) GetIncoming(ctx context.Context, targetTenantID, id string) (*models.VideoTransfer, error) {
+	return r.first(allTenants(ctx, r.db).Where("id = ? AND target_tenant_id = ?", id, targetTenantID))
+}
+
+func (r *videoTransferRepository) GetPendingForVideo(ctx context.Context, tenantID, videoID string) (*models.VideoTransfer, error) {
+	return r.first(forTenant(ctx, r.db, tenantID).Where("video_id = ? AND status = ?", videoID, models.TransferPending))
+}
+
+func (r *videoTransferRepository) first(query *gorm.DB) (*models.VideoTransfer, error) {
+	var transfer models.VideoTransfer
+	err := query.First(&transfer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *videoTransferRepository) ListOutgoing(ctx context.Context, tenantID string, status models.TransferStatus, limit, offset int) ([]*models.VideoTransfer, error) {
+	return r.list(forTenant(ctx, r.db, tenantID), status, limit, offset)
+}
+
+func (r *videoTransferRepository) ListIncoming(ctx context.Context, targetTenantID string, status models.TransferStatus, limit, offset int) ([]*models.VideoTransfer, error) {
+	return r.list(allTenants(ctx, r.db).Where("target_tenant_id = ?", targetTenantID), status, limit, offset)
+}
+
+func (r *videoTransferRepository) list(query *gorm.DB, status models.TransferStatus, limit, offset int) ([]*models.VideoTransfer, error) {
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var transfers []*models.VideoTransfer
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&transfers).Error
+	return transfers, err
+}
+
+// Update saves the transfer within the sender's tenant, whichever side
+// responded to it
+func (r *videoTransferRepository) Update(ctx context.Context, transfer *models.VideoTransfer) error {
+	return saveForTenant(ctx, r.db, transfer.TenantID, transfer)
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestVideoTransferRepository_GetIncomingFiltersByTarget(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoTransferRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `video_transfers` WHERE id = \\? AND target_tenant_id = \\? "+
+		"ORDER BY `video_transfers`.`id` LIMIT \\?").
+		WithArgs("transfer-1", "client", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "target_tenant_id", "status"}).
+			AddRow("transfer-1", "agency", "client", "pending"))
+
+	transfer, err := repo.GetIncoming(context.Background(), "client", "transfer-1")
+	require.NoError(t, err)
+	assert.Equal(t, "agency", transfer.TenantID, "the sender owns the row")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoTransferRepository_GetNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoTransferRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `video_transfers` WHERE id = \\? AND `video_transfers`.`tenant_id` = \\?").
+		WithArgs("transfer-1", "client", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}))
+
+	_, err := repo.Get(context.Background(), "client", "transfer-1")
+	assert.ErrorIs(t, err, models.ErrTransferNotFound, "recipients only read transfers through GetIncoming")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoTransferRepository_ListIncomingByStatus(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoTransferRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `video_transfers` WHERE target_tenant_id = \\? AND status = \\? "+
+		"ORDER BY created_at DESC LIMIT \\?").
+		WithArgs("client", models.TransferPending, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "target_tenant_id"}).
+			AddRow("transfer-1", "agency", "client"))
+
+	transfers, err := repo.ListIncoming(context.Background(), "client", models.TransferPending, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// videoUploadRepository implements models.VideoUploadRepository.
+type videoUploadRepository struct {
+	db *gorm.DB
+}
+
+var _ models.VideoUploadRepository = (*videoUploadRepository)(nil)
+
+// NewVideoUploadRepository creates a new repository instance.
+func NewVideoUploadRepository(db *gorm.DB) models.VideoUploadRepository {
+	return &videoUploadRepository{db: db}
+}
+
+func (r *videoUploadRepository) Create(ctx context.Context, upload *models.VideoUpload) error {
+	if upload.ID == "" {
+		upload.ID = id.New()
+	}
+	return forTenant(ctx, r.db, upload.TenantID).Create(upload).Error
+}
+
+func (r *videoUploadRepository) GetActive(ctx context.Context, tenantID, videoID string) (*models.VideoUpload, error) {
+	var upload models.VideoUpload
+	err := forTenant(ctx, r.db, tenantID).
+		Where("video_id = ? AND status = ?", videoID, models.UploadActive).
+		Order("created_at DESC").First(&upload).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrVideoUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// SetStatus only moves active uploads, so two requests cannot both complete
+// or abort the same upload
+func (r *videoUploadRepository) SetStatus(ctx context.Context, tenantID, id string, status models.VideoUploadStatus) error {
+	res := forTenant(ctx, r.db, tenantID).Model(&models.VideoUpload{}).
+		Where("id = ? AND status = ?", id, models.UploadActive).
+		Update("status", status)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrVideoUploadNotFound
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestVideoUploadRepository_GetActiveNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoUploadRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `video_uploads` WHERE \\(video_id = \\? AND status = \\?\\) AND `video_uploads`.`tenant_id` = \\? ORDER BY created_at DESC").
+		WithArgs("video-1", models.UploadActive, "tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetActive(context.Background(), "tenant-1", "video-1")
+	assert.ErrorIs(t, err, models.ErrVideoUploadNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVideoUploadRepository_SetStatusOnlyActive(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewVideoUploadRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `video_uploads` SET `status`=\\?,`updated_at`=\\? WHERE \\(id = \\? AND status = \\?\\) AND `video_uploads`.`tenant_id` = \\?").
+		WithArgs(models.UploadCompleted, sqlmock.AnyArg(), "upload-1", models.UploadActive, "tenant-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := repo.SetStatus(context.Background(), "tenant-1", "upload-1", models.UploadCompleted)
+	assert.ErrorIs(t, err, models.ErrVideoUploadNotFound, "an upload completed or aborted by another request is not ended again")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// watermarkRepository implements models.WatermarkRepository.
+type watermarkRepository struct {
+	db *gorm.DB
+}
+
+var _ models.WatermarkRepository = (*watermarkRepository)(nil)
+
+// NewWatermarkRepository creates a new repository instance.
+func NewWatermarkRepository(db *gorm.DB) models.WatermarkRepository {
+	return &watermarkRepository{db: db}
+}
+
+func (r *watermarkRepository) Get(ctx context.Context, tenantID string) (*models.Watermark, error) {
+	var watermark models.Watermark
+	err := forTenant(ctx, r.db, tenantID).First(&watermark).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrNotFound
+	}
+	return &watermark, err
+}
+
+// Upsert saves the tenant's only watermark, replacing the image and settings
+// of an existing one
+func (r *watermarkRepository) Upsert(ctx context.Context, watermark *models.Watermark) error {
+	if watermark.ID == "" {
+		watermark.ID = id.New()
+	}
+	return forTenant(ctx, r.db, watermark.TenantID).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"image", "content_type", "width", "height",
+			"enabled", "position", "opacity", "platforms", "updated_at"}),
+	}).Create(watermark).Error
+}
+
+func (r *watermarkRepository) Delete(ctx context.Context, tenantID string) error {
+	result := forTenant(ctx, r.db, tenantID).Delete(&models.Watermark{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+func TestWatermarkRepository_UpsertReplacesTenantWatermark(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewWatermarkRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `watermarks` .* ON DUPLICATE KEY UPDATE " +
+		"`image`=VALUES\\(`image`\\),`content_type`=VALUES\\(`content_type`\\),`width`=VALUES\\(`width`\\)," +
+		"`height`=VALUES\\(`height`\\),`enabled`=VALUES\\(`enabled`\\),`position`=VALUES\\(`position`\\)," +
+		"`opacity`=VALUES\\(`opacity`\\),`platforms`=VALUES\\(`platforms`\\),`updated_at`=VALUES\\(`updated_at`\\)").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	watermark := &models.Watermark{TenantID: "tenant-1", Image: []byte("png"), ContentType: "image/png",
+		Width: 120, Height: 40, Enabled: true, Position: transcode.BottomRight, Opacity: 0.8}
+	require.NoError(t, repo.Upsert(context.Background(), watermark))
+	assert.NotEmpty(t, watermark.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWatermarkRepository_DeleteNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewWatermarkRepository(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `watermarks` WHERE `watermarks`.`tenant_id` = \\?").
+		WithArgs("tenant-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	assert.ErrorIs(t, repo.Delete(context.Background(), "tenant-1"), models.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
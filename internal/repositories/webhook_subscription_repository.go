@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+// webhookSubscriptionRepository implements models.WebhookSubscriptionRepository.
+type webhookSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+var _ models.WebhookSubscriptionRepository = (*webhookSubscriptionRepository)(nil)
+
+// NewWebhookSubscriptionRepository creates a new repository instance.
+func NewWebhookSubscriptionRepository(db *gorm.DB) models.WebhookSubscriptionRepository {
+	return &webhookSubscriptionRepository{db: db}
+}
+
+func (r *webhookSubscriptionRepository) Create(ctx context.Context, subscription *models.WebhookSubscription) error {
+	if subscription.ID == "" {
+		subscription.ID = id.New()
+	}
+	return forTenant(ctx, r.db, subscription.TenantID).Create(subscription).Error
+}
+
+func (r *webhookSubscriptionRepository) Get(ctx context.Context, tenantID, workspaceID, platform string) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	err := forTenant(ctx, r.db, tenantID).
+		Where("workspace_id = ? AND platform = ?", workspaceID, platform).
+		First(&subscription).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrSubscriptionNotFound
+	}
+	return &subscription, err
+}
+
+func (r *webhookSubscriptionRepository) List(ctx context.Context, tenantID string) ([]*models.WebhookSubscription, error) {
+	var subscriptions []*models.WebhookSubscription
+	err := forTenant(ctx, r.db, tenantID).Order("workspace_id, platform").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *webhookSubscriptionRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.WebhookSubscription, error) {
+	var subscriptions []*models.WebhookSubscription
+	err := allTenants(ctx, r.db).Where("renew_at <= ?", now).Order("renew_at").Limit(limit).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *webhookSubscriptionRepository) ListByTopic(ctx context.Context, platform, topic string) ([]*models.WebhookSubscription, error) {
+	var subscriptions []*models.WebhookSubscription
+	err := allTenants(ctx, r.db).Where("platform = ? AND topic = ?", platform, topic).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *webhookSubscriptionRepository) Update(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return saveForTenant(ctx, r.db, subscription.TenantID, subscription)
+}
+
+func (r *webhookSubscriptionRepository) Delete(ctx context.Context, tenantID, id string) error {
+	return forTenant(ctx, r.db, tenantID).Where("id = ?", id).Delete(&models.WebhookSubscription{}).Error
+}
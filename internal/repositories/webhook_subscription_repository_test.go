@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestWebhookSubscriptionRepository_ListDue(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewWebhookSubscriptionRepository(gormDB)
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT \\* FROM `webhook_subscriptions` WHERE renew_at <= \\? ORDER BY renew_at LIMIT \\?").
+		WithArgs(now, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "workspace_id", "platform", "status"}).
+			AddRow("sub-1", "tenant-1", "ws-1", "youtube", models.SubscriptionActive))
+
+	due, err := repo.ListDue(context.Background(), now, 50)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "tenant-1", due[0].TenantID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookSubscriptionRepository_GetNotFound(t *testing.T) {
+	gormDB, mock := newMockDB(t)
+	repo := NewWebhookSubscriptionRepository(gormDB)
+
+	mock.ExpectQuery("SELECT \\* FROM `webhook_subscriptions` WHERE \\(workspace_id = \\? AND platform = \\?\\) AND `webhook_subscriptions`.`tenant_id` = \\?").
+		WithArgs("ws-1", "youtube", "tenant-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.Get(context.Background(), "tenant-1", "ws-1", "youtube")
+	assert.ErrorIs(t, err, models.ErrSubscriptionNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,49 +1,52 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
 )
 
 type workspaceRepository struct {
 	db *gorm.DB
 }
 
+var _ models.WorkspaceRepository = (*workspaceRepository)(nil)
+
 // NewWorkspaceRepository creates a workspace repository.
 func NewWorkspaceRepository(db *gorm.DB) models.WorkspaceRepository {
 	return &workspaceRepository{db: db}
 }
 
-func (r *workspaceRepository) Create(w *models.Workspace) error {
+func (r *workspaceRepository) Create(ctx context.Context, w *models.Workspace) error {
 	if w.ID == "" {
-		w.ID = uuid.New().String()
+		w.ID = id.New()
 	}
-	return r.db.Create(w).Error
+	return forTenant(ctx, r.db, w.TenantID).Create(w).Error
 }
 
-func (r *workspaceRepository) GetByID(tenantID, id string) (*models.Workspace, error) {
+func (r *workspaceRepository) GetByID(ctx context.Context, tenantID, id string) (*models.Workspace, error) {
 	var w models.Workspace
-	err := r.db.Where("tenant_id = ? AND id = ?", tenantID, id).First(&w).Error
+	err := forTenant(ctx, r.db, tenantID).Where("id = ?", id).First(&w).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, models.ErrNotFound
 	}
 	return &w, err
 }
 
-func (r *workspaceRepository) ListByUser(tenantID, userID string) ([]*models.Workspace, error) {
+func (r *workspaceRepository) ListByUser(ctx context.Context, tenantID, userID string) ([]*models.Workspace, error) {
 	var ws []*models.Workspace
-	err := r.db.Where("tenant_id = ? AND user_id = ?", tenantID, userID).Find(&ws).Error
+	err := forTenant(ctx, r.db, tenantID).Where("user_id = ?", userID).Find(&ws).Error
 	return ws, err
 }
 
-func (r *workspaceRepository) Update(w *models.Workspace) error {
-	return r.db.Save(w).Error
+func (r *workspaceRepository) Update(ctx context.Context, w *models.Workspace) error {
+	return saveForTenant(ctx, r.db, w.TenantID, w)
 }
 
-func (r *workspaceRepository) Delete(tenantID, id string) error {
-	return r.db.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&models.Workspace{}).Error
+func (r *workspaceRepository) Delete(ctx context.Context, tenantID, id string) error {
+	return forTenant(ctx, r.db, tenantID).Where("id = ?", id).Delete(&models.Workspace{}).Error
 }
@@ -4,14 +4,11 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/app"
 	"github.com/jibe0123/mysteryfactory/internal/handlers"
 	"github.com/jibe0123/mysteryfactory/internal/middleware"
-	"github.com/jibe0123/mysteryfactory/internal/services"
-	"github.com/jibe0123/mysteryfactory/pkg/aws"
-	"github.com/jibe0123/mysteryfactory/pkg/db"
-	"github.com/jibe0123/mysteryfactory/pkg/logger"
-	"github.com/jibe0123/mysteryfactory/pkg/metrics"
+	"github.com/jibe0123/mysteryfactory/pkg/callback"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	swaggerFiles "github.com/swaggo/files"
@@ -19,8 +16,11 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-// New creates a new Gin router with all routes and middleware configured
-func New(cfg *config.Config, logger *logger.Logger, db *db.DB, metrics *metrics.Metrics) *gin.Engine {
+// New creates a new Gin router with all routes and middleware configured.
+// Services and clients come from deps so tests and workers can wire their own.
+func New(deps *app.Dependencies) *gin.Engine {
+	cfg, logger, db, metrics := deps.Config, deps.Logger, deps.DB, deps.Metrics
+
 	// Set Gin mode based on environment
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -33,46 +33,86 @@ func New(cfg *config.Config, logger *logger.Logger, db *db.DB, metrics *metrics.
 
 	// Global middleware
 	r.Use(gin.Recovery())
-	r.Use(middleware.CORS(cfg.CORSAllowedOrigins))
+	r.Use(middleware.CORS(cfg.CORSAllowedOrigins, cfg.CORSAllowedHeaders, cfg.CORSAllowCredentials))
+	r.Use(middleware.SecurityHeaders(middleware.SecurityHeadersConfig{
+		ContentSecurityPolicy:     cfg.ContentSecurityPolicy,
+		DocsContentSecurityPolicy: cfg.DocsContentSecurityPolicy,
+		DocsPaths:                 "/swagger/",
+		ExemptPaths:               cfg.SecurityHeadersExemptPaths,
+		HSTSMaxAge:                cfg.HSTSMaxAge,
+		HSTSIncludeSubdomains:     cfg.HSTSIncludeSubdomains,
+		HSTSPreload:               cfg.HSTSPreload,
+	}))
 	r.Use(middleware.RequestID())
+	r.Use(middleware.Locale())
 	r.Use(middleware.Logger(logger))
 	r.Use(otelgin.Middleware(cfg.ServiceName))
 	r.Use(middleware.RateLimiter())
 	r.Use(metrics.HTTPMiddleware())
+	r.Use(middleware.SLO(deps.SLO))
 
 	// Health check endpoint (no auth required)
 	r.GET("/health", handlers.HealthCheck(db))
-	r.GET("/ready", handlers.ReadinessCheck(db))
+	r.GET("/ready", handlers.ReadinessCheck(db, deps.Supervisor))
+	r.GET("/health/bedrock", handlers.BedrockHealth(deps.BedrockClient))
 
-	// Metrics endpoint for Prometheus
-	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	// Metrics endpoint for Prometheus, in OpenMetrics when the scraper asks
+	// for it so latency histograms carry their trace exemplars
+	r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})))
+	r.GET("/internal/slo", handlers.SLOReport(deps.SLO))
 
 	// Swagger documentation (only in non-production)
 	if cfg.Environment != "production" {
 		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
 
-	// Initialize services
-	promptService, err := services.NewPromptService("prompts/catalog.yaml", logger)
-	if err != nil {
-		logger.Error("Failed to initialize prompt service", "error", err)
-		panic(err)
-	}
-
-	bedrockClient, err := aws.NewBedrockClient(nil, logger)
-	if err != nil {
-		logger.Error("Failed to initialize Bedrock client", "error", err)
-		panic(err)
-	}
-
-	aiService := services.NewAIService(promptService, bedrockClient, logger, metrics)
-
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(cfg, logger, db)
-	videoHandler := handlers.NewVideoHandler(cfg, logger, db)
-	platformHandler := handlers.NewPlatformHandler(cfg, logger, db)
-	statsHandler := handlers.NewStatsHandler(cfg, logger, db)
-	aiHandler := handlers.NewAIHandler(aiService, logger)
+	authHandler := handlers.NewAuthHandler(cfg, logger, db, deps.LoginService)
+	callbackHandler := handlers.NewCallbackHandler(cfg, logger, db, deps.VideoService)
+	videoHandler := handlers.NewVideoHandler(cfg, logger, db, deps.VideoService)
+	uploadHandler := handlers.NewUploadHandler(cfg, logger, db, deps.UploadService)
+	webhookSecrets := app.NewWebhookSecrets(cfg)
+	platformHandler := handlers.NewPlatformHandler(cfg, logger, db, deps.WebhookService, webhookSecrets, deps.QuotaService, deps.WebhookSubscriptions, deps.ConnectionService)
+	webhookSubscriptionHandler := handlers.NewWebhookSubscriptionHandler(cfg, logger, db, deps.WebhookSubscriptions)
+	statsHandler := handlers.NewStatsHandler(cfg, logger, db, deps.AnalyticsService, deps.PreferencesService)
+	backfillHandler := handlers.NewStatsBackfillHandler(cfg, logger, db, deps.StatsBackfill)
+	retentionHandler := handlers.NewRetentionHandler(cfg, logger, db, deps.RetentionService)
+	scoreHandler := handlers.NewStatsScoreHandler(cfg, logger, db, deps.StatsScores)
+	alertHandler := handlers.NewAlertHandler(cfg, logger, db, deps.AlertService)
+	smartListHandler := handlers.NewSmartListHandler(cfg, logger, db, deps.SmartLists)
+	videoExportHandler := handlers.NewVideoExportHandler(cfg, logger, db, deps.VideoExports)
+	analyticsReaderHandler := handlers.NewAnalyticsReaderHandler(cfg, logger, db, deps.AnalyticsReaders)
+	previewHandler := handlers.NewPublishPreviewHandler(cfg, logger, db, deps.PublishPreview)
+	publicationHandler := handlers.NewPublicationHandler(cfg, logger, db, deps.PublicationService)
+	rightsHandler := handlers.NewRightsHandler(cfg, logger, db, deps.RightsService)
+	watermarkHandler := handlers.NewWatermarkHandler(cfg, logger, db, deps.WatermarkService)
+	renditionHandler := handlers.NewRenditionHandler(cfg, logger, db, deps.RenditionService)
+	audioTrackHandler := handlers.NewAudioTrackHandler(cfg, logger, db, deps.DubbingService)
+	frameHandler := handlers.NewFrameHandler(cfg, logger, db, deps.FrameService)
+	thumbnailVariantHandler := handlers.NewThumbnailVariantHandler(cfg, logger, db, deps.CompositorService)
+	brandHandler := handlers.NewBrandHandler(cfg, logger, db, deps.BrandService, deps.NotificationService)
+	mediaInfoHandler := handlers.NewMediaInfoHandler(cfg, logger, db, deps.MediaInfoService)
+	qcHandler := handlers.NewQCHandler(cfg, logger, db, deps.QCService)
+	activityHandler := handlers.NewActivityHandler(cfg, logger, db, deps.ActivityService)
+	campaignHandler := handlers.NewCampaignHandler(cfg, logger, db, deps.CampaignService)
+	transferHandler := handlers.NewTransferHandler(cfg, logger, db, deps.TransferService)
+	jobHandler := handlers.NewJobHandler(cfg, logger, db, deps.JobService)
+	preferencesHandler := handlers.NewPreferencesHandler(cfg, logger, db, deps.PreferencesService, deps.NotificationService)
+	transcriptHandler := handlers.NewTranscriptHandler(cfg, logger, db, deps.TranscriptService)
+	summaryHandler := handlers.NewSummaryHandler(cfg, logger, db, deps.SummaryService)
+	archiveHandler := handlers.NewArchiveHandler(cfg, logger, db, deps.ArchiveService)
+	residencyHandler := handlers.NewResidencyHandler(cfg, logger, db, deps.ResidencyService)
+	suspensionHandler := handlers.NewSuspensionHandler(cfg, logger, db, deps.SuspensionService)
+	approvalHandler := handlers.NewApprovalHandler(cfg, logger, db, deps.ApprovalService)
+	blackoutHandler := handlers.NewBlackoutHandler(cfg, logger, db, deps.BlackoutService)
+	seriesHandler := handlers.NewSeriesHandler(cfg, logger, db, deps.SeriesService)
+	assetHandler := handlers.NewAssetHandler(cfg, logger, db, deps.AssetService)
+	encodingPresetHandler := handlers.NewEncodingPresetHandler(cfg, logger, db, deps.EncodingPresets)
+	adminHandler := handlers.NewAdminHandler(cfg, logger, db, deps.ImpersonationService, deps.AuditService)
+	opsHandler := handlers.NewOpsHandler(cfg, logger, db, deps.OpsService)
+	debugCaptureHandler := handlers.NewDebugCaptureHandler(cfg, logger, db, deps.DebugCaptureService)
+	aiHandler := handlers.NewAIHandler(deps.AIService, deps.PromptService, deps.ChatService, logger)
+	magicBrushBatchHandler := handlers.NewMagicBrushBatchHandler(cfg, logger, db, deps.MagicBrushBatches)
 
 	// API v1 routes
 	v1 := r.Group("/api/v1")
@@ -81,101 +121,390 @@ func New(cfg *config.Config, logger *logger.Logger, db *db.DB, metrics *metrics.
 		auth := v1.Group("/auth")
 		{
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/login/verify", authHandler.VerifyLogin)
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/refresh", authHandler.RefreshToken)
-			auth.POST("/logout", middleware.JWTAuth(cfg.JWTSecret), authHandler.Logout)
-			auth.GET("/me", middleware.JWTAuth(cfg.JWTSecret), authHandler.GetProfile)
-			auth.PUT("/me", middleware.JWTAuth(cfg.JWTSecret), authHandler.UpdateProfile)
-			auth.POST("/change-password", middleware.JWTAuth(cfg.JWTSecret), authHandler.ChangePassword)
+
+			// Token routes refuse suspended users and tenants like the others
+			authenticated := auth.Group("")
+			authenticated.Use(middleware.JWTAuth(cfg.JWTSecret))
+			if deps.IdentityService != nil {
+				authenticated.Use(middleware.CurrentUser(deps.IdentityService.Load))
+			}
+			{
+				authenticated.POST("/logout", authHandler.Logout)
+				authenticated.GET("/me", authHandler.GetProfile)
+				authenticated.PUT("/me", middleware.DenyImpersonation(), authHandler.UpdateProfile)
+				authenticated.POST("/change-password", middleware.DenyImpersonation(), authHandler.ChangePassword)
+				authenticated.GET("/me/preferences", preferencesHandler.GetPreferences)
+				authenticated.PUT("/me/preferences", middleware.DenyImpersonation(), preferencesHandler.UpdatePreferences)
+				authenticated.GET("/me/notifications", preferencesHandler.ListNotifications)
+				authenticated.GET("/me/logins", authHandler.ListLogins)
+			}
+		}
+
+		// Analytics routes of BI tools, authenticated with an analytics reader
+		// token instead of a user's; nothing else accepts these tokens
+		if deps.AnalyticsReaders != nil {
+			analytics := v1.Group("/analytics")
+			analytics.Use(middleware.AnalyticsReaderAuth(deps.AnalyticsReaders.Authenticate))
+			if deps.ResidencyService != nil {
+				analytics.Use(middleware.Residency(deps.ResidencyService.ContextForTenant))
+			}
+			analytics.POST("/query", analyticsReaderHandler.QueryAnalytics)
 		}
 
 		// Protected routes (require authentication)
 		protected := v1.Group("/")
 		protected.Use(middleware.JWTAuth(cfg.JWTSecret))
 		protected.Use(middleware.TenantResolver())
+		if deps.IdentityService != nil {
+			protected.Use(middleware.CurrentUser(deps.IdentityService.Load))
+		}
+		if deps.ResidencyService != nil {
+			protected.Use(middleware.Residency(deps.ResidencyService.ContextForTenant))
+		}
+		if deps.AuditService != nil {
+			protected.Use(middleware.Audit(deps.AuditService.Record))
+		}
+		if deps.DebugCaptureService != nil {
+			protected.Use(middleware.DebugCapture(deps.DebugCaptureService.ActiveSession, deps.DebugCaptureService.Record, cfg.DebugCaptureMaxBodyBytes))
+		}
+		protected.Use(middleware.RestrictImpersonation())
 		{
 			// Video management routes
 			videos := protected.Group("/videos")
 			{
 				videos.GET("", videoHandler.ListVideos)
 				videos.POST("", videoHandler.CreateVideo)
+				videos.GET("/export", videoExportHandler.ExportVideoMetadata)
 				videos.GET("/:id", videoHandler.GetVideo)
 				videos.PUT("/:id", videoHandler.UpdateVideo)
+				videos.PUT("/:id/owner", videoHandler.TransferOwnership)
 				videos.DELETE("/:id", videoHandler.DeleteVideo)
 				videos.POST("/:id/upload", videoHandler.UploadVideo)
+				videos.POST("/:id/upload/multipart", uploadHandler.StartUpload)
+				videos.GET("/:id/upload/multipart", uploadHandler.GetUpload)
+				videos.DELETE("/:id/upload/multipart", uploadHandler.AbortUpload)
+				videos.POST("/:id/upload/multipart/parts", uploadHandler.PresignParts)
+				videos.PUT("/:id/upload/multipart/parts/:number", uploadHandler.UploadPart)
+				videos.POST("/:id/upload/multipart/complete", uploadHandler.CompleteUpload)
 				videos.GET("/:id/stats", statsHandler.GetVideoStats)
+				videos.GET("/:id/retention", retentionHandler.GetRetention)
+				videos.POST("/:id/retention/sync", retentionHandler.SyncRetention)
+
+				// Transcript routes
+				videos.GET("/:id/transcript", transcriptHandler.GetTranscript)
+				videos.PUT("/:id/transcript", transcriptHandler.SaveTranscript)
+				videos.POST("/:id/summarize", summaryHandler.SummarizeVideo)
+
+				// Cold storage routes
+				videos.POST("/:id/restore", archiveHandler.RestoreVideo)
+				videos.GET("/:id/archive", archiveHandler.GetArchiveStatus)
 
 				// Publication routes
-				videos.POST("/:id/publish", videoHandler.PublishVideo)
-				videos.GET("/:id/publications", videoHandler.GetVideoPublications)
-				videos.PUT("/:id/publications/:pub_id", videoHandler.UpdatePublication)
-				videos.DELETE("/:id/publications/:pub_id", videoHandler.CancelPublication)
+				videos.POST("/:id/publish", approvalHandler.Publish)
+				videos.GET("/:id/publish-preview", previewHandler.GetPublishPreview)
+				videos.GET("/:id/renditions", renditionHandler.ListRenditions)
+				videos.GET("/:id/audio-tracks", audioTrackHandler.ListAudioTracks)
+				videos.POST("/:id/audio-tracks", audioTrackHandler.DubVideo)
+				videos.DELETE("/:id/audio-tracks/:language", audioTrackHandler.DeleteAudioTrack)
+				videos.GET("/:id/frames", frameHandler.GetFrames)
+				videos.GET("/:id/thumbnails", thumbnailVariantHandler.ListThumbnailVariants)
+				videos.POST("/:id/thumbnails", thumbnailVariantHandler.ComposeThumbnails)
+				videos.POST("/:id/thumbnails/:variant_id/select", thumbnailVariantHandler.SelectThumbnailVariant)
+				videos.GET("/:id/media-info", mediaInfoHandler.GetMediaInfo)
+				videos.POST("/:id/qc/override", middleware.RequireRole("admin"), middleware.DenyImpersonation(), qcHandler.OverrideQC)
+				videos.GET("/:id/activity", activityHandler.GetVideoActivity)
+				videos.POST("/:id/transfers", middleware.RequireRole("admin"), middleware.DenyImpersonation(), transferHandler.RequestTransfer)
+				videos.GET("/:id/publications", publicationHandler.ListPublications)
+				videos.DELETE("/:id/publications/:pub_id/unpublish", middleware.RequireRole("admin"), middleware.DenyImpersonation(), publicationHandler.Unpublish)
+			}
+
+			// Publication approval and blackout window routes
+			publications := protected.Group("/publications")
+			{
+				publications.GET("/awaiting-approval", middleware.PaginationMiddleware(), approvalHandler.ListAwaitingApproval)
+				publications.POST("/:id/approve", middleware.DenyImpersonation(), approvalHandler.Approve)
+				publications.POST("/:id/reject", middleware.DenyImpersonation(), approvalHandler.Reject)
+				publications.GET("/approval-policy", approvalHandler.GetPolicy)
+				publications.PUT("/approval-policy", middleware.RequireRole("admin"), middleware.DenyImpersonation(), approvalHandler.UpdatePolicy)
+				publications.GET("/blackout-windows", blackoutHandler.ListBlackoutWindows)
+				publications.POST("/blackout-windows", middleware.RequireRole("admin"), middleware.DenyImpersonation(), blackoutHandler.CreateBlackoutWindow)
+				publications.DELETE("/blackout-windows/:id", middleware.RequireRole("admin"), middleware.DenyImpersonation(), blackoutHandler.DeleteBlackoutWindow)
+			}
+
+			// Campaign routes
+			campaigns := protected.Group("/campaigns")
+			{
+				campaigns.GET("/:id/activity", activityHandler.GetCampaignActivity)
+				campaigns.GET("/:id/history", campaignHandler.GetCampaignHistory)
+				campaigns.GET("/:id/report", summaryHandler.GetCampaignReport)
+			}
+
+			// Video transfer routes, between the sending and the receiving tenant
+			transfers := protected.Group("/transfers")
+			{
+				transfers.GET("", transferHandler.ListTransfers)
+				transfers.GET("/:id", transferHandler.GetTransfer)
+				transfers.POST("/:id/accept", middleware.RequireRole("admin"), middleware.DenyImpersonation(), transferHandler.AcceptTransfer)
+				transfers.POST("/:id/decline", middleware.RequireRole("admin"), middleware.DenyImpersonation(), transferHandler.DeclineTransfer)
+				transfers.DELETE("/:id", middleware.RequireRole("admin"), middleware.DenyImpersonation(), transferHandler.CancelTransfer)
+			}
+
+			// Alert rules of the current user, delivered as notifications
+			alertRules := protected.Group("/alert-rules")
+			{
+				alertRules.POST("", alertHandler.CreateAlertRule)
+				alertRules.GET("", middleware.PaginationMiddleware(), alertHandler.ListAlertRules)
+				alertRules.GET("/:id", alertHandler.GetAlertRule)
+				alertRules.PUT("/:id", alertHandler.UpdateAlertRule)
+				alertRules.DELETE("/:id", alertHandler.DeleteAlertRule)
+			}
+
+			// Saved filters of the current user over the video library
+			smartLists := protected.Group("/smart-lists")
+			{
+				smartLists.POST("", smartListHandler.CreateSmartList)
+				smartLists.GET("", middleware.PaginationMiddleware(), smartListHandler.ListSmartLists)
+				smartLists.GET("/:id", smartListHandler.GetSmartList)
+				smartLists.PUT("/:id", smartListHandler.UpdateSmartList)
+				smartLists.DELETE("/:id", smartListHandler.DeleteSmartList)
+				smartLists.GET("/:id/videos", middleware.PaginationMiddleware(), smartListHandler.ListSmartListVideos)
+			}
+
+			// Read-only credentials of the tenant's BI tools
+			analyticsReaders := protected.Group("/analytics-readers")
+			analyticsReaders.Use(middleware.RequireRole("admin"), middleware.DenyImpersonation())
+			{
+				analyticsReaders.POST("", analyticsReaderHandler.CreateAnalyticsReader)
+				analyticsReaders.GET("", middleware.PaginationMiddleware(), analyticsReaderHandler.ListAnalyticsReaders)
+				analyticsReaders.DELETE("/:id", analyticsReaderHandler.RevokeAnalyticsReader)
+			}
+
+			// Series and their numbered episodes
+			series := protected.Group("/series")
+			{
+				series.GET("", seriesHandler.ListSeries)
+				series.POST("", seriesHandler.CreateSeries)
+				series.GET("/:id", seriesHandler.GetSeries)
+				series.PUT("/:id", seriesHandler.UpdateSeries)
+				series.DELETE("/:id", seriesHandler.DeleteSeries)
+				series.GET("/:id/episodes", seriesHandler.ListEpisodes)
+				series.POST("/:id/episodes", seriesHandler.AddEpisode)
+			}
+
+			// Settings the tenant's renditions are transcoded with
+			encodingPresets := protected.Group("/encoding-presets")
+			{
+				encodingPresets.GET("", encodingPresetHandler.ListEncodingPresets)
+				encodingPresets.GET("/:profile", encodingPresetHandler.GetEncodingPreset)
+				encodingPresets.PUT("/:profile", middleware.RequireRole("admin"), middleware.DenyImpersonation(), encodingPresetHandler.PutEncodingPreset)
+				encodingPresets.DELETE("/:profile", middleware.RequireRole("admin"), middleware.DenyImpersonation(), encodingPresetHandler.DeleteEncodingPreset)
+			}
+
+			// Intros, outros, logos and music, and the intro and outro
+			// stitched onto the tenant's renditions
+			assets := protected.Group("/assets")
+			{
+				assets.GET("", assetHandler.ListAssets)
+				assets.POST("", assetHandler.CreateAsset)
+				assets.GET("/defaults", assetHandler.GetAssetDefaults)
+				assets.PUT("/defaults", middleware.RequireRole("admin"), middleware.DenyImpersonation(), assetHandler.PutAssetDefaults)
+				assets.GET("/:id", assetHandler.GetAsset)
+				assets.PUT("/:id", assetHandler.UpdateAsset)
+				assets.DELETE("/:id", middleware.RequireRole("admin"), middleware.DenyImpersonation(), assetHandler.DeleteAsset)
+				assets.POST("/:id/complete", assetHandler.CompleteAsset)
+			}
+
+			// Transcript search routes
+			transcripts := protected.Group("/transcripts")
+			{
+				transcripts.GET("/search", transcriptHandler.SearchTranscripts)
 			}
 
 			// Platform webhook routes (special auth handling)
 			platforms := protected.Group("/platforms")
 			{
-				platforms.POST("/webhook/:platform", platformHandler.HandleWebhook)
+				platforms.POST("/webhook/:platform",
+					middleware.WebhookRateLimit(cfg.WebhookRateLimit, cfg.WebhookRateBurst),
+					middleware.BodyLimit(cfg.WebhookMaxBodyBytes),
+					platformHandler.HandleWebhook)
 				platforms.GET("/webhook/:platform/verify", platformHandler.VerifyWebhook)
+				platforms.GET("/connections", platformHandler.ListConnections)
+				platforms.GET("/webhook-subscriptions", webhookSubscriptionHandler.GetSubscriptionHealth)
+				platforms.POST("/webhook-subscriptions", webhookSubscriptionHandler.Subscribe)
 				platforms.GET("/:platform/auth", platformHandler.InitiatePlatformAuth)
 				platforms.POST("/:platform/auth/callback", platformHandler.HandleAuthCallback)
 				platforms.DELETE("/:platform/auth", platformHandler.RevokePlatformAuth)
+				platforms.POST("/:platform/reconnect", middleware.DenyImpersonation(), platformHandler.Reconnect)
 			}
 
 			// Statistics and analytics routes
 			stats := protected.Group("/stats")
 			{
-				stats.GET("/videos", statsHandler.GetVideosStats)
+				stats.GET("/videos", middleware.PaginationMiddleware(), statsHandler.GetVideosStats)
 				stats.GET("/videos/:id", statsHandler.GetVideoStats)
 				stats.GET("/videos/:id/history", statsHandler.GetVideoStatsHistory)
 				stats.GET("/dashboard", statsHandler.GetDashboardStats)
 				stats.GET("/performance", statsHandler.GetPerformanceStats)
+				stats.GET("/top", statsHandler.GetTopPerforming)
 				stats.POST("/sync", statsHandler.SyncStats)
+				stats.GET("/export", statsHandler.ExportVideoStats)
+				stats.POST("/scores/recompute", middleware.RequireRole("admin"), scoreHandler.RecomputeScores)
+
+				// Imports of past daily stats, started by admins
+				stats.POST("/backfills", middleware.RequireRole("admin"), backfillHandler.StartBackfill)
+				stats.GET("/backfills", middleware.PaginationMiddleware(), backfillHandler.ListBackfills)
+				stats.GET("/backfills/:id", backfillHandler.GetBackfill)
 
 				// Enhanced analytics - ROI and engagement tracking
 				stats.GET("/roi", statsHandler.GetROIAnalytics)
 				stats.GET("/engagement", statsHandler.GetEngagementAnalytics)
+				stats.GET("/traffic-sources", statsHandler.GetTrafficSources)
+				stats.GET("/devices", statsHandler.GetDeviceBreakdown)
+				stats.GET("/geography", statsHandler.GetGeographyBreakdown)
 			}
 
+			// Status of the asynchronous operations of every subsystem
+			jobs := protected.Group("/jobs")
+			{
+				jobs.GET("", middleware.PaginationMiddleware(), jobHandler.ListJobs)
+				jobs.GET("/:id", jobHandler.GetJob)
+			}
+
+			// Retention rules (changes are admin only)
+			archive := protected.Group("/archive")
+			{
+				archive.GET("/policy", archiveHandler.GetRetentionPolicy)
+				archive.PUT("/policy", middleware.RequireRole("admin"), middleware.DenyImpersonation(), archiveHandler.UpdateRetentionPolicy)
+			}
+
+			// Rights to the videos
+			rights := protected.Group("/rights")
+			{
+				rights.GET("/expiring", rightsHandler.GetExpiringRights)
+			}
+
+			// Watermark burnt into transcoded videos (changes are admin only)
+			watermark := protected.Group("/watermark")
+			{
+				watermark.GET("", watermarkHandler.GetWatermark)
+				watermark.GET("/image", watermarkHandler.GetWatermarkImage)
+				watermark.PUT("", middleware.RequireRole("admin"), middleware.DenyImpersonation(), watermarkHandler.UpdateWatermark)
+				watermark.PUT("/image", middleware.RequireRole("admin"), middleware.DenyImpersonation(), watermarkHandler.UploadWatermark)
+				watermark.DELETE("", middleware.RequireRole("admin"), middleware.DenyImpersonation(), watermarkHandler.DeleteWatermark)
+			}
+
+			// Brand of composed thumbnails (changes are admin only)
+			protected.GET("/brand-profile", brandHandler.GetBrandProfile)
+			protected.PUT("/brand-profile", middleware.RequireRole("admin"), middleware.DenyImpersonation(), brandHandler.UpdateBrandProfile)
+			protected.GET("/brand-profile/email-preview", brandHandler.PreviewEmail)
+
+			// Data residency (changes are admin only)
+			protected.GET("/residency", residencyHandler.GetResidency)
+			protected.PUT("/residency", middleware.RequireRole("admin"), middleware.DenyImpersonation(), residencyHandler.UpdateResidency)
+
 			// AI processing routes
 			ai := protected.Group("/ai")
 			{
 				// Magic Brush - real-time AI content generation
 				ai.POST("/magic-brush", aiHandler.GenerateMagicBrush)
+				ai.POST("/magic-brush/bulk", magicBrushBatchHandler.StartMagicBrushBatch)
+				ai.GET("/magic-brush/bulk/:id", magicBrushBatchHandler.GetMagicBrushBatch)
 
 				// Prompt management
 				ai.GET("/prompts", aiHandler.GetPrompts)
+				ai.POST("/prompts/validate-catalog", aiHandler.ValidateCatalog)
+				// Every tenant runs the one catalog: only support promotes it
+				ai.POST("/prompts/promote", middleware.RequireRole("admin"), middleware.RequireSupportTenant(cfg.SupportTenantID), middleware.DenyImpersonation(), aiHandler.PromoteCatalog)
 				ai.POST("/test-prompt", aiHandler.TestPrompt)
+
+				// Multi-turn assistant conversations
+				ai.POST("/chat", aiHandler.Chat)
+				ai.GET("/chat/:id", aiHandler.GetConversation)
+				ai.DELETE("/chat/:id", aiHandler.DeleteConversation)
 			}
 
 			// User management routes (admin only)
 			users := protected.Group("/users")
 			users.Use(middleware.RequireRole("admin"))
+			users.Use(middleware.DenyImpersonation())
 			{
 				users.GET("", authHandler.ListUsers)
 				users.POST("", authHandler.CreateUser)
 				users.GET("/:id", authHandler.GetUser)
 				users.PUT("/:id", authHandler.UpdateUser)
 				users.DELETE("/:id", authHandler.DeleteUser)
+				users.POST("/:id/suspend", suspensionHandler.SuspendUser)
+				users.POST("/:id/reactivate", suspensionHandler.ReactivateUser)
+			}
+
+			// Support and audit routes (admin only, never with an impersonation token)
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireRole("admin"))
+			admin.Use(middleware.DenyImpersonation())
+			{
+				admin.POST("/impersonate", adminHandler.Impersonate)
+				admin.GET("/audit-logs", middleware.PaginationMiddleware(), adminHandler.ListAuditLogs)
+
+				// Debug capture of the tenant's requests (admin routes are never captured)
+				admin.POST("/debug-capture", debugCaptureHandler.EnableDebugCapture)
+				admin.GET("/debug-capture", debugCaptureHandler.GetDebugCapture)
+				admin.DELETE("/debug-capture", debugCaptureHandler.DisableDebugCapture)
+				admin.GET("/debug-captures", middleware.PaginationMiddleware(), debugCaptureHandler.ListDebugCaptures)
+				admin.GET("/debug-captures/:id", debugCaptureHandler.GetDebugCaptureByID)
+
+				// Cross-tenant operations views (support tenant only)
+				ops := admin.Group("/ops")
+				ops.Use(middleware.RequireSupportTenant(cfg.SupportTenantID))
+				{
+					ops.GET("/failed-publications", opsHandler.FailedPublications)
+					ops.GET("/dlq", opsHandler.DeadLetters)
+					ops.GET("/ai-spend", opsHandler.AISpend)
+					ops.GET("/webhooks", opsHandler.WebhookStats)
+					ops.GET("/webhooks/quarantine", middleware.PaginationMiddleware(), opsHandler.ListQuarantinedWebhooks)
+					ops.GET("/webhooks/quarantine/:id", opsHandler.GetQuarantinedWebhook)
+					ops.GET("/stats-sync", opsHandler.StatsSync)
+				}
 			}
 
 			// Tenant management routes (admin only)
 			tenants := protected.Group("/tenants")
 			tenants.Use(middleware.RequireRole("admin"))
+			tenants.Use(middleware.DenyImpersonation())
 			{
 				tenants.GET("", authHandler.ListTenants)
 				tenants.POST("", authHandler.CreateTenant)
 				tenants.GET("/:id", authHandler.GetTenant)
 				tenants.PUT("/:id", authHandler.UpdateTenant)
 				tenants.DELETE("/:id", authHandler.DeleteTenant)
+				tenants.POST("/:id/suspend", middleware.RequireSupportTenant(cfg.SupportTenantID), suspensionHandler.SuspendTenant)
+				tenants.POST("/:id/reactivate", middleware.RequireSupportTenant(cfg.SupportTenantID), suspensionHandler.ReactivateTenant)
 			}
 		}
 	}
 
-	// Webhook routes (special handling, no standard auth)
+	// Webhook routes (signed by the platforms, no standard auth). Rate limits and
+	// the body cap apply before the signature is computed.
 	webhooks := r.Group("/webhooks")
 	{
-		webhooks.POST("/:platform", middleware.WebhookAuth(), platformHandler.HandleWebhook)
+		webhooks.GET("/:platform", platformHandler.VerifyWebhook)
+		webhooks.POST("/:platform",
+			middleware.WebhookRateLimit(cfg.WebhookRateLimit, cfg.WebhookRateBurst),
+			middleware.BodyLimit(cfg.WebhookMaxBodyBytes),
+			middleware.WebhookAuth(webhookSecrets),
+			platformHandler.HandleWebhook)
+	}
+
+	// Callbacks of the processing workers, signed with the callback secret
+	// instead of a user token
+	callbacks := r.Group("/internal/callbacks")
+	callbacks.Use(middleware.BodyLimit(callback.MaxBodyBytes), middleware.CallbackAuth(cfg.CallbackSecret))
+	{
+		callbacks.POST("/tenants/:tenant_id/videos/:id/processing/progress", callbackHandler.ProcessingProgress)
+		callbacks.POST("/tenants/:tenant_id/videos/:id/processing/complete", callbackHandler.ProcessingComplete)
+		callbacks.POST("/tenants/:tenant_id/videos/:id/processing/failed", callbackHandler.ProcessingFailed)
 	}
 
 	// 404 handler
@@ -201,7 +530,7 @@ func New(cfg *config.Config, logger *logger.Logger, db *db.DB, metrics *metrics.
 }
 
 // SetupRoutes is an alternative function for setting up routes with more granular control
-func SetupRoutes(r *gin.Engine, cfg *config.Config, logger *logger.Logger, db *db.DB) {
+func SetupRoutes(r *gin.Engine, deps *app.Dependencies) {
 	// This function can be used if you need more control over route setup
 	// Currently, the New function handles everything, but this provides flexibility
 }
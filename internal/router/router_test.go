@@ -0,0 +1,372 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jibe0123/mysteryfactory/internal/app"
+	"github.com/jibe0123/mysteryfactory/internal/config"
+	"github.com/jibe0123/mysteryfactory/internal/middleware"
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMetrics is shared by the routers under test since metrics register globally
+var testMetrics = metrics.New(metrics.LabelPolicy{})
+
+func TestNew_WithInjectedDependencies(t *testing.T) {
+	deps := &app.Dependencies{
+		Config: &config.Config{
+			Environment:         "production",
+			ServiceName:         "mysteryfactory-test",
+			JWTSecret:           "test-secret",
+			CORSAllowedOrigins:  "http://localhost:3000",
+			WebhookMaxBodyBytes: 64,
+			WebhookRateLimit:    600,
+			WebhookRateBurst:    60,
+		},
+		Logger:  logger.New("error", "test"),
+		Metrics: testMetrics,
+	}
+
+	r := New(deps)
+
+	registered := make(map[string]bool)
+	for _, route := range GetRouteInfo(r) {
+		registered[route.Method+" "+route.Path] = true
+	}
+	for _, route := range []string{
+		"POST /api/v1/auth/login",
+		"GET /api/v1/videos/:id/transcript",
+		"POST /api/v1/ai/magic-brush",
+		"POST /api/v1/ai/chat",
+		"PUT /api/v1/residency",
+		"POST /webhooks/:platform",
+		"GET /webhooks/:platform",
+	} {
+		assert.True(t, registered[route], "route %s should be registered", route)
+	}
+	assert.False(t, registered["GET /swagger/*any"], "swagger must be disabled in production")
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "protected route requires auth", method: "GET", path: "/api/v1/videos", expectedStatus: http.StatusUnauthorized},
+		{name: "bedrock health without failover", method: "GET", path: "/health/bedrock", expectedStatus: http.StatusOK},
+		{name: "unknown route", method: "GET", path: "/api/v1/unknown", expectedStatus: http.StatusNotFound},
+		{name: "webhook of unknown platform", method: "POST", path: "/webhooks/myspace", expectedStatus: http.StatusBadRequest},
+		{name: "webhook body over the cap", method: "POST", path: "/webhooks/youtube", body: strings.Repeat("x", 65), expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "webhook of unconfigured platform", method: "POST", path: "/webhooks/youtube", body: "{}", expectedStatus: http.StatusNotFound},
+		{name: "webhook challenge of unconfigured platform", method: "GET", path: "/webhooks/youtube", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestNew_WebhookRateLimit(t *testing.T) {
+	deps := &app.Dependencies{
+		Config: &config.Config{
+			Environment:         "production",
+			ServiceName:         "mysteryfactory-test",
+			JWTSecret:           "test-secret",
+			WebhookMaxBodyBytes: 1024,
+			WebhookRateLimit:    1,
+			WebhookRateBurst:    2,
+		},
+		Logger:  logger.New("error", "test"),
+		Metrics: testMetrics,
+	}
+	r := New(deps)
+
+	post := func(platform string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/webhooks/"+platform, strings.NewReader("{}")))
+		return w
+	}
+
+	// Unsigned webhooks are rejected but still count against the limit
+	assert.Equal(t, http.StatusNotFound, post("tiktok").Code)
+	assert.Equal(t, http.StatusNotFound, post("tiktok").Code)
+	limited := post("tiktok")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.NotEmpty(t, limited.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusNotFound, post("youtube").Code, "each platform has its own limit")
+}
+
+func TestNew_CORS(t *testing.T) {
+	deps := &app.Dependencies{
+		Config: &config.Config{
+			Environment:          "production",
+			ServiceName:          "mysteryfactory-test",
+			JWTSecret:            "test-secret",
+			CORSAllowedOrigins:   "https://app.mysteryfactory.io, https://*.preview.mysteryfactory.io",
+			CORSAllowedHeaders:   "Authorization,Content-Type",
+			CORSAllowCredentials: true,
+			WebhookMaxBodyBytes:  64,
+			WebhookRateLimit:     600,
+			WebhookRateBurst:     60,
+		},
+		Logger:  logger.New("error", "test"),
+		Metrics: testMetrics,
+	}
+	r := New(deps)
+
+	preflight := func(origin, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("OPTIONS", "/api/v1/videos", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", header)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := preflight("https://app.mysteryfactory.io", "authorization")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.mysteryfactory.io", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = preflight("https://pr-42.preview.mysteryfactory.io", "content-type")
+	assert.Equal(t, "https://pr-42.preview.mysteryfactory.io", w.Header().Get("Access-Control-Allow-Origin"), "subdomain patterns match")
+
+	assert.Empty(t, preflight("https://evil.example.com", "authorization").Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, preflight("https://app.mysteryfactory.io", "x-tenant-id").Header().Get("Access-Control-Allow-Origin"),
+		"headers outside the configured list are refused")
+}
+
+func TestNew_SecurityHeaders(t *testing.T) {
+	deps := &app.Dependencies{
+		Config: &config.Config{
+			Environment:                "staging",
+			ServiceName:                "mysteryfactory-test",
+			JWTSecret:                  "test-secret",
+			ContentSecurityPolicy:      "default-src 'none'",
+			DocsContentSecurityPolicy:  "default-src 'self'; script-src 'self' 'unsafe-inline'",
+			SecurityHeadersExemptPaths: "/metrics",
+			HSTSMaxAge:                 63072000,
+			HSTSIncludeSubdomains:      true,
+			HSTSPreload:                true,
+			WebhookMaxBodyBytes:        64,
+			WebhookRateLimit:           600,
+			WebhookRateBurst:           60,
+		},
+		Logger:  logger.New("error", "test"),
+		Metrics: testMetrics,
+	}
+	r := New(deps)
+
+	get := func(path string) http.Header {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Header()
+	}
+
+	api := get("/api/v1/videos")
+	assert.Equal(t, "default-src 'none'", api.Get("Content-Security-Policy"))
+	assert.Equal(t, "max-age=63072000; includeSubDomains; preload", api.Get("Strict-Transport-Security"))
+	assert.Equal(t, "nosniff", api.Get("X-Content-Type-Options"))
+
+	assert.Equal(t, "default-src 'self'; script-src 'self' 'unsafe-inline'", get("/swagger/index.html").Get("Content-Security-Policy"),
+		"the Swagger UI gets the docs policy")
+
+	metrics := get("/metrics")
+	assert.Empty(t, metrics.Get("Content-Security-Policy"))
+	assert.Empty(t, metrics.Get("Strict-Transport-Security"))
+}
+
+// recordingAuditService keeps audit entries in memory
+type recordingAuditService struct {
+	services.AuditService
+	entries []*models.AuditLog
+}
+
+func (s *recordingAuditService) Record(ctx context.Context, entry *models.AuditLog) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func TestNew_ImpersonationRestrictions(t *testing.T) {
+	audit := &recordingAuditService{}
+	deps := &app.Dependencies{
+		Config: &config.Config{
+			Environment: "production",
+			ServiceName: "mysteryfactory-test",
+			JWTSecret:   "test-secret",
+		},
+		Logger:       logger.New("error", "test"),
+		Metrics:      testMetrics,
+		AuditService: audit,
+	}
+	r := New(deps)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.JWTClaims{
+		UserID:          "editor-1",
+		TenantID:        "acme",
+		Role:            "admin",
+		ImpersonatorID:  "support-admin",
+		ImpersonationID: "imp-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "deletion", method: "DELETE", path: "/api/v1/videos/video-1"},
+		{name: "user management", method: "GET", path: "/api/v1/users"},
+		{name: "nested impersonation", method: "POST", path: "/api/v1/admin/impersonate"},
+		{name: "password change", method: "POST", path: "/api/v1/auth/change-password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+		})
+	}
+
+	// Every request of the session is audited, refused ones included
+	require.Len(t, audit.entries, 3, "protected routes are audited; the auth routes are outside the group")
+	entry := audit.entries[0]
+	assert.Equal(t, "DELETE /api/v1/videos/:id", entry.Action)
+	assert.Equal(t, http.StatusForbidden, entry.Status)
+	assert.True(t, entry.Impersonated)
+	assert.Equal(t, "support-admin", entry.ImpersonatorID)
+	assert.Equal(t, "imp-1", entry.ImpersonationID)
+	assert.Equal(t, "GET /api/v1/users", audit.entries[1].Action, "reads are audited while impersonating")
+}
+
+// staticOpsService reports an empty webhook queue
+type staticOpsService struct {
+	services.OpsService
+}
+
+func (s *staticOpsService) WebhookStats(ctx context.Context) *services.WebhookStats {
+	return &services.WebhookStats{QueueSize: 10}
+}
+
+func TestNew_OpsRequiresSupportTenant(t *testing.T) {
+	deps := &app.Dependencies{
+		Config: &config.Config{
+			Environment:     "production",
+			ServiceName:     "mysteryfactory-test",
+			JWTSecret:       "test-secret",
+			SupportTenantID: "support",
+		},
+		Logger:     logger.New("error", "test"),
+		Metrics:    testMetrics,
+		OpsService: &staticOpsService{},
+	}
+	r := New(deps)
+
+	get := func(tenantID, role string) int {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.JWTClaims{
+			UserID:   "user-1",
+			TenantID: tenantID,
+			Role:     role,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}).SignedString([]byte("test-secret"))
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/api/v1/admin/ops/webhooks", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get("support", "admin"))
+	assert.Equal(t, http.StatusForbidden, get("acme", "admin"), "tenant admins only see their own tenant")
+	assert.Equal(t, http.StatusForbidden, get("support", "editor"))
+}
+
+// recordingDebugCaptureService captures the tenant "acme" in session "session-1"
+type recordingDebugCaptureService struct {
+	services.DebugCaptureService
+	captures []*models.DebugCapture
+}
+
+func (s *recordingDebugCaptureService) ActiveSession(ctx context.Context, tenantID string) (string, bool) {
+	return "session-1", tenantID == "acme"
+}
+
+func (s *recordingDebugCaptureService) Record(ctx context.Context, capture *models.DebugCapture) error {
+	s.captures = append(s.captures, capture)
+	return nil
+}
+
+func TestNew_DebugCapture(t *testing.T) {
+	capture := &recordingDebugCaptureService{}
+	deps := &app.Dependencies{
+		Config: &config.Config{
+			Environment:              "production",
+			ServiceName:              "mysteryfactory-test",
+			JWTSecret:                "test-secret",
+			SupportTenantID:          "support",
+			DebugCaptureMaxBodyBytes: 1024,
+		},
+		Logger:              logger.New("error", "test"),
+		Metrics:             testMetrics,
+		DebugCaptureService: capture,
+	}
+	r := New(deps)
+
+	request := func(tenantID, role, path string) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.JWTClaims{
+			UserID:   "user-1",
+			TenantID: tenantID,
+			Role:     role,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}).SignedString([]byte("test-secret"))
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	request("acme", "editor", "/api/v1/users?access_token=abc")
+	request("other", "editor", "/api/v1/users")
+	request("acme", "admin", "/api/v1/admin/ops/webhooks")
+
+	require.Len(t, capture.captures, 1, "only tenants with a session are captured, and never on admin routes")
+	captured := capture.captures[0]
+	assert.Equal(t, "session-1", captured.SessionID)
+	assert.Equal(t, "GET", captured.Method)
+	assert.Equal(t, "/api/v1/users", captured.Route)
+	assert.Equal(t, http.StatusForbidden, captured.Status)
+	assert.NotContains(t, captured.RequestHeaders, "Bearer", "credentials are redacted")
+	assert.NotContains(t, captured.Query, "abc")
+	assert.Contains(t, captured.ResponseBody, "error")
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// Bounds of an activity feed page
+const (
+	defaultActivityLimit = 50
+	maxActivityLimit     = 200
+)
+
+// viewMilestones are the total views across platforms reported in the feeds
+var viewMilestones = []int64{1_000, 10_000, 100_000, 1_000_000, 10_000_000}
+
+// activityService implements the ActivityService interface
+type activityService struct {
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	audit      models.AuditLogRepository
+	usage      models.AIUsageRepository
+	jobs       models.PublicationJobRepository
+	stats      models.VideoStatsRepository
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ ActivityService = (*activityService)(nil)
+
+// NewActivityService creates a new activity service
+func NewActivityService(videos models.VideoRepository, workspaces models.WorkspaceRepository, audit models.AuditLogRepository, usage models.AIUsageRepository, jobs models.PublicationJobRepository, stats models.VideoStatsRepository, clock clock.Clock, logger *logger.Logger) ActivityService {
+	return &activityService{
+		videos:     videos,
+		workspaces: workspaces,
+		audit:      audit,
+		usage:      usage,
+		jobs:       jobs,
+		stats:      stats,
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+// VideoActivity returns the feed of a single video
+func (s *activityService) VideoActivity(ctx context.Context, viewer *models.User, videoID string, before time.Time, limit int) (*ActivityFeed, error) {
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	return s.feed(ctx, viewer.TenantID, []*models.Video{video}, "", before, limit)
+}
+
+// CampaignActivity returns the feed of the campaign and of its latest
+// models.MaxActivityVideos videos. Campaigns without videos still have the
+// events of the campaign itself.
+func (s *activityService) CampaignActivity(ctx context.Context, tenantID, campaignID string, before time.Time, limit int) (*ActivityFeed, error) {
+	videos, err := s.videos.ListByCampaign(ctx, tenantID, campaignID, models.MaxActivityVideos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list campaign videos: %w", err)
+	}
+	return s.feed(ctx, tenantID, videos, campaignID, before, limit)
+}
+
+// feed merges the events of every source into a page. Each source reads up
+// to limit records, enough to fill the page on its own.
+func (s *activityService) feed(ctx context.Context, tenantID string, videos []*models.Video, campaignID string, before time.Time, limit int) (*ActivityFeed, error) {
+	if limit <= 0 {
+		limit = defaultActivityLimit
+	}
+	if limit > maxActivityLimit {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "limit must be at most %d", maxActivityLimit)
+	}
+	if before.IsZero() {
+		before = s.clock.Now()
+	}
+	scope := &models.ActivityScope{CampaignID: campaignID, Before: before, Limit: limit}
+	for _, video := range videos {
+		scope.VideoIDs = append(scope.VideoIDs, video.ID)
+	}
+
+	entries, err := s.audit.ListActivity(ctx, tenantID, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	usage, err := s.usage.ListActivity(ctx, tenantID, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AI usage: %w", err)
+	}
+	jobs, err := s.jobs.ListActivity(ctx, tenantID, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list publications: %w", err)
+	}
+
+	events := make([]*ActivityEvent, 0, len(entries)+len(usage)+len(jobs))
+	for _, entry := range entries {
+		events = append(events, auditEvent(entry))
+	}
+	for _, u := range usage {
+		events = append(events, aiGenerationEvent(u))
+	}
+	for _, job := range jobs {
+		events = append(events, publicationEvents(job)...)
+	}
+	for _, video := range videos {
+		milestones, err := s.milestones(ctx, tenantID, video, before)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, milestones...)
+	}
+
+	// Publications bring their later events along; they belong to other pages
+	page := events[:0]
+	for _, event := range events {
+		if event.OccurredAt.Before(before) {
+			page = append(page, event)
+		}
+	}
+	sort.SliceStable(page, func(i, j int) bool {
+		return page[i].OccurredAt.After(page[j].OccurredAt)
+	})
+
+	feed := &ActivityFeed{Events: page}
+	if len(page) >= limit {
+		feed.Events = page[:limit]
+		next := feed.Events[limit-1].OccurredAt
+		feed.NextBefore = &next
+	}
+	return feed, nil
+}
+
+// milestones replays the video's stats history, summing the latest views of
+// each platform, and reports when the total first reached each milestone
+func (s *activityService) milestones(ctx context.Context, tenantID string, video *models.Video, before time.Time) ([]*ActivityEvent, error) {
+	snapshots, err := s.stats.GetHistory(ctx, tenantID, video.ID, video.CreatedAt, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stats history: %w", err)
+	}
+
+	var events []*ActivityEvent
+	views := make(map[string]int64)
+	var total int64
+	next := 0
+	for _, snapshot := range snapshots {
+		total += snapshot.Views - views[snapshot.StatsID]
+		views[snapshot.StatsID] = snapshot.Views
+		for next < len(viewMilestones) && total >= viewMilestones[next] {
+			events = append(events, &ActivityEvent{
+				Type:       ActivityStatMilestone,
+				Action:     "views_milestone",
+				OccurredAt: snapshot.CreatedAt,
+				VideoID:    video.ID,
+				Summary:    fmt.Sprintf("Reached %d views across platforms", viewMilestones[next]),
+				Details:    map[string]interface{}{"metric": "views", "milestone": viewMilestones[next], "total": total},
+			})
+			next++
+		}
+	}
+	return events, nil
+}
+
+// auditEvent describes a request made on a video or campaign
+func auditEvent(entry *models.AuditLog) *ActivityEvent {
+	summary := entry.Detail
+	if summary == "" {
+		summary = entry.Action
+	}
+	var videoID string
+	if path, ok := strings.CutPrefix(entry.Resource, models.VideoPath("")); ok {
+		videoID, _, _ = strings.Cut(path, "/")
+	}
+	return &ActivityEvent{
+		Type:           ActivityAudit,
+		Action:         entry.Action,
+		OccurredAt:     entry.CreatedAt,
+		UserID:         entry.UserID,
+		VideoID:        videoID,
+		Summary:        summary,
+		Impersonated:   entry.Impersonated,
+		ImpersonatorID: entry.ImpersonatorID,
+		Details:        map[string]interface{}{"resource": entry.Resource, "status": entry.Status, "request_id": entry.RequestID},
+	}
+}
+
+// aiGenerationEvent describes content generated with Bedrock
+func aiGenerationEvent(usage *models.AIUsage) *ActivityEvent {
+	return &ActivityEvent{
+		Type:       ActivityAIGeneration,
+		Action:     usage.Feature,
+		OccurredAt: usage.CreatedAt,
+		UserID:     usage.UserID,
+		VideoID:    usage.VideoID,
+		Summary:    fmt.Sprintf("Generated %s with %s", usage.Feature, usage.Model),
+		Details: map[string]interface{}{
+			"model":         usage.Model,
+			"input_tokens":  usage.InputTokens,
+			"output_tokens": usage.OutputTokens,
+			"cost":          usage.Cost,
+		},
+	}
+}
+
+// publicationEvents describes what happened to a publication: its request,
+// then its release or failure, then its takedown
+func publicationEvents(job *models.PublicationJob) []*ActivityEvent {
+	event := func(action string, at time.Time, userID, summary string) *ActivityEvent {
+		return &ActivityEvent{
+			Type:       ActivityPublication,
+			Action:     action,
+			OccurredAt: at,
+			UserID:     userID,
+			VideoID:    job.VideoID,
+			Platform:   job.Platform,
+			Summary:    summary,
+			Details:    map[string]interface{}{"publication_id": job.ID},
+		}
+	}
+
+	requested := event("publication.requested", job.CreatedAt, job.UserID, fmt.Sprintf("Requested publication on %s", job.Platform))
+	if job.ScheduledAt.Valid {
+		requested.Summary = fmt.Sprintf("Scheduled publication on %s for %s", job.Platform, job.ScheduledAt.Time.Format(time.RFC3339))
+	}
+	events := []*ActivityEvent{requested}
+
+	switch {
+	case job.CompletedAt.Valid:
+		published := event("publication.published", job.CompletedAt.Time, "", fmt.Sprintf("Published on %s", job.Platform))
+		if job.ExternalURL != "" {
+			published.Details["external_url"] = job.ExternalURL
+		}
+		events = append(events, published)
+	case job.Status == string(models.PublicationFailed):
+		failed := event("publication.failed", job.UpdatedAt, "", fmt.Sprintf("Publication on %s failed", job.Platform))
+		failed.Details["error"] = job.ErrorMsg
+		if job.FailureReason != "" {
+			failed.Details["failure_reason"] = job.FailureReason
+			failed.Details["remediation"] = job.Remediation
+		}
+		events = append(events, failed)
+	}
+
+	if job.UnpublishedAt.Valid {
+		unpublished := event("publication.unpublished", job.UnpublishedAt.Time, job.UnpublishedBy, fmt.Sprintf("Taken down from %s", job.Platform))
+		unpublished.Details["reason"] = job.UnpublishReason
+		events = append(events, unpublished)
+	}
+	return events
+}
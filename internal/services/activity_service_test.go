@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+type activityVideoRepo struct {
+	models.VideoRepository
+	videos []*models.Video
+}
+
+func (r *activityVideoRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Video, error) {
+	for _, v := range r.videos {
+		if v.ID == id {
+			return v, nil
+		}
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+func (r *activityVideoRepo) ListByCampaign(ctx context.Context, tenantID, campaignID string, limit int) ([]*models.Video, error) {
+	var videos []*models.Video
+	for _, v := range r.videos {
+		if v.CampaignID == campaignID {
+			videos = append(videos, v)
+		}
+	}
+	return videos, nil
+}
+
+type activityUsageRepo struct {
+	models.AIUsageRepository
+	usage []*models.AIUsage
+}
+
+func (r *activityUsageRepo) ListActivity(ctx context.Context, tenantID string, scope *models.ActivityScope) ([]*models.AIUsage, error) {
+	var usage []*models.AIUsage
+	for _, u := range r.usage {
+		if (u.CampaignID != "" && u.CampaignID == scope.CampaignID) || contains(scope.VideoIDs, u.VideoID) {
+			usage = append(usage, u)
+		}
+	}
+	return usage, nil
+}
+
+type activityJobRepo struct {
+	models.PublicationJobRepository
+	jobs []*models.PublicationJob
+}
+
+func (r *activityJobRepo) ListActivity(ctx context.Context, tenantID string, scope *models.ActivityScope) ([]*models.PublicationJob, error) {
+	var jobs []*models.PublicationJob
+	for _, job := range r.jobs {
+		if contains(scope.VideoIDs, job.VideoID) && job.CreatedAt.Before(scope.Before) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+type activityStatsRepo struct {
+	models.VideoStatsRepository
+	snapshots map[string][]*models.VideoStatsSnapshot
+}
+
+func (r *activityStatsRepo) GetHistory(ctx context.Context, tenantID, videoID string, from, to time.Time) ([]*models.VideoStatsSnapshot, error) {
+	return r.snapshots[videoID], nil
+}
+
+func TestActivityService(t *testing.T) {
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return day.Add(time.Duration(hours) * time.Hour) }
+
+	videos := &activityVideoRepo{videos: []*models.Video{
+		{ID: "launch", TenantID: "acme", CampaignID: "fall", CreatedAt: day},
+		{ID: "teaser", TenantID: "acme", CampaignID: "fall", CreatedAt: day},
+		{ID: "draft", TenantID: "acme", UserID: "marketer", Visibility: models.VisibilityOwner, CreatedAt: day},
+	}}
+	audit := &memoryAuditRepo{entries: []*models.AuditLog{
+		{TenantID: "acme", UserID: "editor", Action: "PUT /api/v1/videos/:id", Resource: "/api/v1/videos/launch", Status: 200, CreatedAt: at(1)},
+		{TenantID: "acme", UserID: "editor", Action: "POST /api/v1/videos/:id/publish", Resource: "/api/v1/videos/launch/publish", Status: 201, CreatedAt: at(3)},
+	}}
+	usage := &activityUsageRepo{usage: []*models.AIUsage{
+		{TenantID: "acme", UserID: "editor", VideoID: "launch", Feature: "magic_brush/title_gen", Model: "claude-sonnet", CreatedAt: at(2)},
+		{TenantID: "acme", UserID: "marketer", CampaignID: "fall", Feature: "campaign/ideation", Model: "claude-sonnet", CreatedAt: at(0)},
+	}}
+	jobs := &activityJobRepo{jobs: []*models.PublicationJob{
+		{ID: "pub-1", VideoID: "launch", UserID: "editor", Platform: "youtube", Status: string(models.PublicationCompleted),
+			CreatedAt: at(3), CompletedAt: sql.NullTime{Time: at(4), Valid: true}, ExternalURL: "https://youtu.be/abc"},
+		{ID: "pub-2", VideoID: "teaser", UserID: "editor", Platform: "tiktok", Status: string(models.PublicationFailed),
+			CreatedAt: at(5), UpdatedAt: at(6), ErrorMsg: "quota exceeded"},
+	}}
+	stats := &activityStatsRepo{snapshots: map[string][]*models.VideoStatsSnapshot{
+		"launch": {
+			{StatsID: "yt", Views: 600, CreatedAt: at(10)},
+			{StatsID: "ig", Views: 300, CreatedAt: at(11)},
+			{StatsID: "yt", Views: 800, CreatedAt: at(12)}, // 800 + 300
+			{StatsID: "yt", Views: 9_500, CreatedAt: at(13)},
+		},
+	}}
+	svc := NewActivityService(videos, nil, audit, usage, jobs, stats, clock.NewFake(at(24)), logger.New("error", "test"))
+	ctx := context.Background()
+	editor := &models.User{ID: "editor", TenantID: "acme", Role: string(models.RoleEditor)}
+
+	t.Run("video feed", func(t *testing.T) {
+		feed, err := svc.VideoActivity(ctx, editor, "launch", time.Time{}, 0)
+		require.NoError(t, err)
+
+		var actions []string
+		for _, e := range feed.Events {
+			actions = append(actions, e.Action)
+		}
+		assert.Equal(t, []string{
+			"views_milestone",
+			"publication.published",
+			"POST /api/v1/videos/:id/publish",
+			"publication.requested",
+			"magic_brush/title_gen",
+			"PUT /api/v1/videos/:id",
+		}, actions, "newest first, without the campaign's or the other video's events")
+		assert.Equal(t, at(12), feed.Events[0].OccurredAt, "1,000 views are reached once both platforms are summed")
+		assert.Equal(t, int64(1_000), feed.Events[0].Details["milestone"])
+		assert.Equal(t, "https://youtu.be/abc", feed.Events[1].Details["external_url"])
+		assert.Equal(t, "editor", feed.Events[2].UserID)
+		assert.Equal(t, "launch", feed.Events[2].VideoID)
+		assert.Nil(t, feed.NextBefore)
+	})
+
+	t.Run("campaign feed pages", func(t *testing.T) {
+		first, err := svc.CampaignActivity(ctx, "acme", "fall", at(24), 3)
+		require.NoError(t, err)
+		require.Len(t, first.Events, 3)
+		assert.Equal(t, "publication.failed", first.Events[1].Action)
+		assert.Equal(t, "quota exceeded", first.Events[1].Details["error"])
+		require.NotNil(t, first.NextBefore)
+
+		rest, err := svc.CampaignActivity(ctx, "acme", "fall", *first.NextBefore, 10)
+		require.NoError(t, err)
+		require.Len(t, rest.Events, 6)
+		assert.Equal(t, "campaign/ideation", rest.Events[5].Action, "generations for the campaign itself are included")
+		assert.Equal(t, "marketer", rest.Events[5].UserID)
+		assert.Nil(t, rest.NextBefore)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := svc.VideoActivity(ctx, editor, "missing", at(24), 0)
+		assert.ErrorIs(t, err, models.ErrVideoNotFound)
+
+		_, err = svc.VideoActivity(ctx, editor, "draft", at(24), 0)
+		assert.ErrorIs(t, err, models.ErrVideoNotFound, "videos the editor cannot see are not found")
+
+		_, err = svc.VideoActivity(ctx, editor, "launch", at(24), maxActivityLimit+1)
+		assert.ErrorIs(t, err, models.ErrInvalidInput)
+	})
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// Guardrail rules reported in GuardrailViolation.Rule
+const (
+	GuardrailMaxLength = "max_length"
+	GuardrailLanguage  = "language"
+	GuardrailPII       = "pii"
+)
+
+// guardrailMaxLengths are the longest outputs accepted per brush type, the
+// limits of the strictest platform: YouTube's 100 characters per title,
+// 5000 per description and 500 for all tags. Thumbnail hooks are kept short
+// enough to read over a thumbnail.
+var guardrailMaxLengths = map[string]int{
+	"title":       100,
+	"description": 5000,
+	"tags":        500,
+	"thumbnail":   models.MaxThumbnailTextLength,
+}
+
+// listedBrushes are the brush types whose prompts ask for several
+// suggestions, one per line, checked line by line
+var listedBrushes = map[string]bool{"title": true, "thumbnail": true}
+
+// piiRedaction replaces personal data found in generated content
+const piiRedaction = "[redacted]"
+
+// piiPatterns find personal data a model may copy from its context or make
+// up: email addresses, phone numbers and payment card numbers
+var piiPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+	valid   func(match string) bool
+}{
+	{name: "email address", pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{name: "card number", pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), valid: luhnValid},
+	{name: "phone number", pattern: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{1,4}\)[ .-]?)?\d{2,4}(?:[ .-]\d{2,4}){2,4}\b`), valid: phoneLike},
+}
+
+// languageStopwords are frequent words telling the languages the brushes
+// are asked for apart. Other languages are not checked.
+var languageStopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "you", "your", "this", "with", "for", "of", "to", "in", "how", "why", "what"},
+	"fr": {"le", "la", "les", "et", "est", "vous", "votre", "ce", "cette", "avec", "pour", "des", "du", "un", "une", "dans", "comment", "pourquoi"},
+	"es": {"el", "la", "los", "las", "y", "es", "usted", "tu", "este", "esta", "con", "para", "del", "un", "una", "en", "cómo", "por", "qué"},
+	"de": {"der", "die", "das", "und", "ist", "sie", "ihr", "dieses", "mit", "für", "von", "zu", "ein", "eine", "im", "wie", "warum"},
+	"it": {"il", "lo", "gli", "e", "è", "sono", "questo", "questa", "con", "per", "del", "della", "un", "una", "nel", "come", "perché"},
+	"pt": {"o", "os", "as", "e", "é", "você", "seu", "este", "esta", "com", "para", "do", "da", "um", "uma", "no", "na", "como", "por"},
+}
+
+// minLanguageEvidence is the number of stopwords needed before the language
+// of a text is judged; short outputs such as tags are not
+const minLanguageEvidence = 3
+
+// checkGuardrails returns the rules the generated content breaks. maxLength
+// lowers the brush type's limit when it is set.
+func checkGuardrails(brushType, language string, maxLength int, content string) []GuardrailViolation {
+	var violations []GuardrailViolation
+
+	if limit := brushLengthLimit(brushType, maxLength); limit > 0 {
+		for _, part := range lengthParts(brushType, content) {
+			if n := len([]rune(part)); n > limit {
+				violations = append(violations, GuardrailViolation{
+					Rule:    GuardrailMaxLength,
+					Message: fmt.Sprintf("%s is %d characters long, over the limit of %d", brushType, n, limit),
+				})
+				break
+			}
+		}
+	}
+
+	if language != "" {
+		if detected, ok := detectLanguage(content); ok && detected != language {
+			violations = append(violations, GuardrailViolation{
+				Rule:    GuardrailLanguage,
+				Message: fmt.Sprintf("content is written in %q instead of %q", detected, language),
+			})
+		}
+	}
+
+	if _, found, _ := redactPII(content); len(found) > 0 {
+		violations = append(violations, GuardrailViolation{
+			Rule:    GuardrailPII,
+			Message: "content contains personal data: " + strings.Join(found, ", "),
+		})
+	}
+	return violations
+}
+
+// correctionPrompt asks the model to fix the violations of its last answer
+func correctionPrompt(violations []GuardrailViolation) string {
+	var b strings.Builder
+	b.WriteString("\n\nYour previous answer was rejected for these reasons:\n")
+	for _, violation := range violations {
+		b.WriteString("- " + violation.Message + "\n")
+	}
+	b.WriteString("Answer again following every requirement above, without any personal data such as email addresses, phone numbers or card numbers.")
+	return b.String()
+}
+
+// enforceGuardrails fixes what can be fixed without the model: personal
+// data is redacted and content over the length limit is cut at a word
+// boundary. It returns the fixed content and the number of redactions.
+func enforceGuardrails(brushType string, maxLength int, content string) (string, int) {
+	content, _, redacted := redactPII(content)
+
+	limit := brushLengthLimit(brushType, maxLength)
+	if limit <= 0 {
+		return content, redacted
+	}
+	if listedBrushes[brushType] {
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			lines[i] = truncateWords(line, limit)
+		}
+		return strings.Join(lines, "\n"), redacted
+	}
+	return truncateWords(content, limit), redacted
+}
+
+// brushLengthLimit is the brush type's limit, or maxLength when lower
+func brushLengthLimit(brushType string, maxLength int) int {
+	limit := guardrailMaxLengths[brushType]
+	if maxLength > 0 && (limit == 0 || maxLength < limit) {
+		return maxLength
+	}
+	return limit
+}
+
+// lengthParts splits content into the parts the length limit applies to,
+// ignoring the numbering of listed suggestions
+func lengthParts(brushType, content string) []string {
+	if !listedBrushes[brushType] {
+		return []string{content}
+	}
+	var parts []string
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			parts = append(parts, strings.TrimLeft(line, "0123456789.-) "))
+		}
+	}
+	return parts
+}
+
+// truncateWords cuts s to at most limit characters, at the last space when
+// there is one
+func truncateWords(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	cut := string(runes[:limit])
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRightFunc(cut, unicode.IsSpace)
+}
+
+// redactPII replaces the personal data in content, naming the kinds it
+// found and counting the replacements
+func redactPII(content string) (string, []string, int) {
+	var found []string
+	redacted := 0
+	for _, p := range piiPatterns {
+		matched := false
+		content = p.pattern.ReplaceAllStringFunc(content, func(match string) string {
+			if p.valid != nil && !p.valid(match) {
+				return match
+			}
+			matched = true
+			redacted++
+			return piiRedaction
+		})
+		if matched {
+			found = append(found, p.name)
+		}
+	}
+	return content, found, redacted
+}
+
+// detectLanguage returns the language whose stopwords the text uses most,
+// false when there is too little evidence or two languages are as likely
+func detectLanguage(text string) (string, bool) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	scores := make(map[string]int, len(languageStopwords))
+	for language, stopwords := range languageStopwords {
+		for _, word := range words {
+			for _, stopword := range stopwords {
+				if word == stopword {
+					scores[language]++
+					break
+				}
+			}
+		}
+	}
+
+	best, bestScore, runnerUp := "", 0, 0
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, runnerUp = language, score, bestScore
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+	if bestScore < minLanguageEvidence || bestScore == runnerUp {
+		return "", false
+	}
+	return best, true
+}
+
+// luhnValid tells card numbers from other long digit runs
+func luhnValid(match string) bool {
+	var digits []int
+	for _, r := range match {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		if (len(digits)-1-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// phoneLike keeps numbers with the digits of a phone number, not years or
+// counts separated by spaces
+func phoneLike(match string) bool {
+	digits := 0
+	for _, r := range match {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= 9 && digits <= 15
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedBedrockClient answers conversations with its replies in order,
+// keeping the prompts it was sent
+type scriptedBedrockClient struct {
+	aws.BedrockClient
+	replies []string
+	prompts []string
+}
+
+func (c *scriptedBedrockClient) InvokeConversation(ctx context.Context, req *aws.ConversationRequest) (*aws.InvokeModelResponse, error) {
+	c.prompts = append(c.prompts, req.Conversation.Messages[0].Content[0].Text)
+	reply := c.replies[0]
+	c.replies = c.replies[1:]
+	return &aws.InvokeModelResponse{Content: reply, InputTokens: 10, OutputTokens: 20, ProcessedAt: time.Now()}, nil
+}
+
+type guardrailUsageRepo struct {
+	models.AIUsageRepository
+	usage []*models.AIUsage
+}
+
+func (r *guardrailUsageRepo) Create(ctx context.Context, usage *models.AIUsage) error {
+	r.usage = append(r.usage, usage)
+	return nil
+}
+
+func newGuardrailTestService(t *testing.T, replies ...string) (AIService, *scriptedBedrockClient, *guardrailUsageRepo) {
+	t.Helper()
+	prompts, err := NewPromptService(NewFilePromptCatalogSource("../../prompts/catalog.yaml"), time.Minute, logger.New("error", "test"))
+	require.NoError(t, err)
+	policy, err := NewModelPolicy("claude-sonnet", "", "claude-sonnet")
+	require.NoError(t, err)
+	client := &scriptedBedrockClient{replies: replies}
+	usage := &guardrailUsageRepo{}
+	return NewAIService(prompts, client, policy, false, usage, logger.New("error", "test"), testMetrics), client, usage
+}
+
+func TestCheckGuardrails(t *testing.T) {
+	tests := []struct {
+		name      string
+		brushType string
+		language  string
+		maxLength int
+		content   string
+		rules     []string
+	}{
+		{
+			name:      "clean titles",
+			brushType: "title",
+			language:  "en",
+			content:   "1. How to learn Go in a weekend\n2. Why your tests are slow and what to do",
+		},
+		{
+			name:      "title over the platform limit",
+			brushType: "title",
+			content:   "1. " + strings.Repeat("go ", 40),
+			rules:     []string{GuardrailMaxLength},
+		},
+		{
+			name:      "description over the requested length",
+			brushType: "description",
+			maxLength: 20,
+			content:   "A description longer than twenty characters",
+			rules:     []string{GuardrailMaxLength},
+		},
+		{
+			name:      "wrong language",
+			brushType: "description",
+			language:  "en",
+			content:   "Dans cette vidéo, nous verrons comment écrire des tests avec le langage Go et pourquoi",
+			rules:     []string{GuardrailLanguage},
+		},
+		{
+			name:      "tags are too short to judge the language",
+			brushType: "tags",
+			language:  "fr",
+			content:   "#golang #programming #tutorial",
+		},
+		{
+			name:      "personal data",
+			brushType: "description",
+			content:   "Write to jane.doe@example.com or call +33 6 12 34 56 78",
+			rules:     []string{GuardrailPII},
+		},
+		{
+			name:      "years and counts are not phone numbers",
+			brushType: "description",
+			content:   "Top 10 tips of 2024, tested on 3 500 projects",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, violation := range checkGuardrails(tt.brushType, tt.language, tt.maxLength, tt.content) {
+				rules = append(rules, violation.Rule)
+			}
+			assert.Equal(t, tt.rules, rules)
+		})
+	}
+}
+
+func TestEnforceGuardrails(t *testing.T) {
+	content, redacted := enforceGuardrails("description", 0, "Pay with 4111 1111 1111 1111, questions to help@example.com")
+	assert.Equal(t, "Pay with [redacted], questions to [redacted]", content)
+	assert.Equal(t, 2, redacted)
+
+	content, redacted = enforceGuardrails("description", 25, "Learn how to write tests in Go")
+	assert.Equal(t, "Learn how to write tests", content)
+	assert.Zero(t, redacted)
+
+	content, _ = enforceGuardrails("title", 0, "1. Short title\n2. "+strings.Repeat("word ", 30))
+	for _, line := range strings.Split(content, "\n") {
+		assert.LessOrEqual(t, len(line), 100)
+	}
+
+	content, _ = enforceGuardrails("thumbnail", 0, "1. Who did it?\n2. The butler was never in the library that night")
+	assert.Equal(t, "1. Who did it?\n2. The butler was never in the library", content)
+}
+
+func TestGenerateMagicBrush_Guardrails(t *testing.T) {
+	ctx := context.Background()
+	req := &MagicBrushRequest{VideoID: "video-1", BrushType: "description", Language: "en", Context: map[string]interface{}{"title": "Go testing", "topic": "testing"}}
+
+	t.Run("clean content is returned as is", func(t *testing.T) {
+		svc, client, usage := newGuardrailTestService(t, "Learn how to test your Go code with the standard library")
+		resp, err := svc.GenerateMagicBrush(ctx, "acme", "editor", req)
+		require.NoError(t, err)
+		assert.Nil(t, resp.Guardrails)
+		assert.Len(t, client.prompts, 1)
+		assert.Len(t, usage.usage, 1)
+	})
+
+	t.Run("a violation is retried once", func(t *testing.T) {
+		svc, client, usage := newGuardrailTestService(t,
+			"Learn how to test your Go code, and mail me at jane.doe@example.com",
+			"Learn how to test your Go code with the standard library")
+		resp, err := svc.GenerateMagicBrush(ctx, "acme", "editor", req)
+		require.NoError(t, err)
+		assert.Equal(t, "Learn how to test your Go code with the standard library", resp.Result)
+		require.NotNil(t, resp.Guardrails)
+		assert.True(t, resp.Guardrails.Retried)
+		assert.Empty(t, resp.Guardrails.Violations)
+		require.Len(t, client.prompts, 2)
+		assert.Contains(t, client.prompts[1], "content contains personal data: email address")
+		assert.Len(t, usage.usage, 2, "the retry is paid for too")
+	})
+
+	t.Run("what the retry still breaks is fixed", func(t *testing.T) {
+		svc, _, _ := newGuardrailTestService(t,
+			"Mail me at jane.doe@example.com",
+			"Learn how to test your Go code, or mail me at jane.doe@example.com")
+		resp, err := svc.GenerateMagicBrush(ctx, "acme", "editor", req)
+		require.NoError(t, err)
+		assert.Equal(t, "Learn how to test your Go code, or mail me at [redacted]", resp.Result)
+		require.Len(t, resp.Guardrails.Violations, 1)
+		assert.Equal(t, GuardrailPII, resp.Guardrails.Violations[0].Rule)
+		assert.Equal(t, 1, resp.Guardrails.Redacted)
+	})
+}
@@ -5,53 +5,76 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jibe0123/mysteryfactory/internal/models"
 	"github.com/jibe0123/mysteryfactory/pkg/aws"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
 	"github.com/jibe0123/mysteryfactory/pkg/metrics"
+	"github.com/jibe0123/mysteryfactory/pkg/tokenizer"
 )
 
 // aiService implements the AIService interface
 type aiService struct {
 	promptService PromptService
 	bedrockClient aws.BedrockClient
+	modelPolicy   *ModelPolicy
+	deterministic bool
+	usage         models.AIUsageRepository
+	tokenizer     tokenizer.Tokenizer
 	logger        *logger.Logger
 	metrics       *metrics.Metrics
 }
 
-// NewAIService creates a new AI service instance
-func NewAIService(promptService PromptService, bedrockClient aws.BedrockClient, logger *logger.Logger, metrics *metrics.Metrics) AIService {
+var _ AIService = (*aiService)(nil)
+
+// BrushPrompts are the catalog prompts of the magic brush types
+var BrushPrompts = map[string]string{
+	"title":       "magic_brush/title_gen",
+	"description": "magic_brush/description_gen",
+	"tags":        "magic_brush/tags_gen",
+	"thumbnail":   "magic_brush/thumbnail_hook",
+}
+
+// NewAIService creates a new AI service instance. In deterministic mode every
+// request is sent with temperature 0 so prompt CI runs produce stable output.
+// The tokens and estimated cost of tenant requests are stored in usage.
+func NewAIService(promptService PromptService, bedrockClient aws.BedrockClient, modelPolicy *ModelPolicy, deterministic bool, usage models.AIUsageRepository, logger *logger.Logger, metrics *metrics.Metrics) AIService {
 	return &aiService{
 		promptService: promptService,
 		bedrockClient: bedrockClient,
+		modelPolicy:   modelPolicy,
+		deterministic: deterministic,
+		usage:         usage,
+		tokenizer:     tokenizer.NewBPE(),
 		logger:        logger,
 		metrics:       metrics,
 	}
 }
 
 // GenerateMagicBrush generates content using AI magic brush
-func (s *aiService) GenerateMagicBrush(ctx context.Context, tenantID string, req *MagicBrushRequest) (*MagicBrushResponse, error) {
+func (s *aiService) GenerateMagicBrush(ctx context.Context, tenantID, userID string, req *MagicBrushRequest) (*MagicBrushResponse, error) {
 	start := time.Now()
-	s.logger.Info("Generating magic brush content", "tenant_id", tenantID, "video_id", req.VideoID, "brush_type", req.BrushType)
+	s.logger.Info("Generating magic brush content", "tenant_id", tenantID, "video_id", req.VideoID, "brush_type", req.BrushType, "model", req.Model)
 
 	// Track in-flight AI requests
 	s.metrics.IncrementAIInFlight()
 	defer s.metrics.DecrementAIInFlight()
 
 	// Determine prompt key based on brush type
-	var promptKey string
-	switch req.BrushType {
-	case "title":
-		promptKey = "magic_brush/title_gen"
-	case "description":
-		promptKey = "magic_brush/description_gen"
-	case "tags":
-		promptKey = "magic_brush/tags_gen"
-	default:
+	promptKey, ok := BrushPrompts[req.BrushType]
+	if !ok {
 		s.metrics.RecordMagicBrush(req.BrushType, "error", tenantID)
 		s.metrics.RecordError("unsupported_brush_type", "ai_service", tenantID)
 		return nil, fmt.Errorf("unsupported brush type: %s", req.BrushType)
 	}
 
+	// Resolve the model fallback chain allowed for this tenant
+	chain, err := s.modelPolicy.Resolve(tenantID, req.Model)
+	if err != nil {
+		s.metrics.RecordMagicBrush(req.BrushType, "error", tenantID)
+		s.metrics.RecordError("model_not_allowed", "ai_service", tenantID)
+		return nil, err
+	}
+
 	// Prepare prompt data from request context
 	promptData := make(map[string]interface{})
 	if req.Context != nil {
@@ -81,20 +104,40 @@ func (s *aiService) GenerateMagicBrush(ctx context.Context, tenantID string, req
 	}
 
 	// Process with Bedrock using the prompt
-	result, err := s.ProcessWithBedrock(ctx, promptKey, promptData)
+	result, err := s.processWithModels(ctx, promptKey, promptData, chain, "")
 	if err != nil {
 		s.logger.Error("Failed to process magic brush with Bedrock", "error", err, "prompt_key", promptKey)
 		s.metrics.RecordMagicBrush(req.BrushType, "error", tenantID)
 		s.metrics.RecordError("bedrock_processing_failed", "ai_service", tenantID)
 		return nil, fmt.Errorf("failed to generate content: %w", err)
 	}
+	usage := s.recordBrushRequest(ctx, tenantID, userID, req, promptKey, chain, result, time.Since(start))
+	generatedContent := brushContent(req.BrushType, result)
+
+	// Content breaking a guardrail is asked for once more with the reasons
+	// it was rejected; what the second answer still breaks is fixed here
+	var guardrails *GuardrailReport
+	if violations := checkGuardrails(req.BrushType, req.Language, req.MaxLength, generatedContent); len(violations) > 0 {
+		s.logger.Warn("Magic brush content broke guardrails, retrying", "tenant_id", tenantID, "video_id", req.VideoID, "brush_type", req.BrushType, "violations", len(violations))
+		s.metrics.RecordError("guardrail_violation", "ai_service", tenantID)
+		guardrails = &GuardrailReport{Retried: true}
+
+		retryStart := time.Now()
+		retry, err := s.processWithModels(ctx, promptKey, promptData, chain, correctionPrompt(violations))
+		if err != nil {
+			s.logger.Warn("Magic brush retry failed, keeping the first answer", "error", err, "prompt_key", promptKey)
+			guardrails.Violations = violations
+		} else {
+			usage.Add(s.recordBrushRequest(ctx, tenantID, userID, req, promptKey, chain, retry, time.Since(retryStart)))
+			result = retry
+			generatedContent = brushContent(req.BrushType, result)
+			guardrails.Violations = checkGuardrails(req.BrushType, req.Language, req.MaxLength, generatedContent)
+		}
 
-	// Extract result from Bedrock response
-	var generatedContent string
-	if resultStr, ok := result["result"].(string); ok {
-		generatedContent = resultStr
-	} else {
-		generatedContent = fmt.Sprintf("Generated %s content", req.BrushType)
+		generatedContent, guardrails.Redacted = enforceGuardrails(req.BrushType, req.MaxLength, generatedContent)
+		if len(guardrails.Violations) > 0 {
+			s.metrics.RecordError("guardrail_violation_after_retry", "ai_service", tenantID)
+		}
 	}
 
 	response := &MagicBrushResponse{
@@ -103,13 +146,30 @@ func (s *aiService) GenerateMagicBrush(ctx context.Context, tenantID string, req
 		Result:      generatedContent,
 		Confidence:  0.85,
 		Metadata:    result,
+		Guardrails:  guardrails,
+		Usage:       usage,
 		ProcessedAt: time.Now(),
 	}
 
 	// Record successful magic brush request
-	duration := time.Since(start)
 	s.metrics.RecordMagicBrush(req.BrushType, "success", tenantID)
 
+	s.logger.Info("Magic brush content generated", "tenant_id", tenantID, "video_id", req.VideoID, "brush_type", req.BrushType)
+	return response, nil
+}
+
+// brushContent extracts the generated content from a Bedrock result
+func brushContent(brushType string, result map[string]interface{}) string {
+	if content, ok := result["result"].(string); ok {
+		return content
+	}
+	return fmt.Sprintf("Generated %s content", brushType)
+}
+
+// recordBrushRequest records the metrics and usage of one Bedrock request
+// made for a magic brush, retries being paid for like first answers, and
+// returns the usage
+func (s *aiService) recordBrushRequest(ctx context.Context, tenantID, userID string, req *MagicBrushRequest, promptKey string, chain []aws.FoundationModel, result map[string]interface{}, duration time.Duration) models.AIUsageTotal {
 	// Extract tokens used from result metadata if available
 	tokensUsed := 0
 	if tokens, ok := result["tokens_used"].(int); ok {
@@ -117,19 +177,43 @@ func (s *aiService) GenerateMagicBrush(ctx context.Context, tenantID string, req
 	}
 
 	// Record AI request metrics
-	model := "claude-3-sonnet"
+	model := string(chain[0])
 	if modelStr, ok := result["model"].(string); ok {
 		model = modelStr
 	}
-	s.metrics.RecordAIRequest(model, promptKey, req.BrushType, "success", tenantID, duration, tokensUsed)
+	s.metrics.RecordAIRequest(ctx, model, promptKey, req.BrushType, "success", tenantID, duration, tokensUsed)
 
-	s.logger.Info("Magic brush content generated", "tenant_id", tenantID, "video_id", req.VideoID, "brush_type", req.BrushType)
-	return response, nil
+	inputTokens, _ := result["input_tokens"].(int)
+	outputTokens, _ := result["output_tokens"].(int)
+	cost, _ := result["cost"].(float64)
+	recordUsage(ctx, s.usage, s.logger, &models.AIUsage{
+		TenantID:     tenantID,
+		UserID:       userID,
+		VideoID:      req.VideoID,
+		CampaignID:   req.CampaignID,
+		Model:        model,
+		Feature:      promptKey,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		Cost:         cost,
+	})
+	return models.AIUsageTotal{InputTokens: inputTokens, OutputTokens: outputTokens, Cost: cost}
 }
 
 // ProcessWithBedrock processes input using AWS Bedrock
 func (s *aiService) ProcessWithBedrock(ctx context.Context, promptKey string, input map[string]interface{}) (map[string]interface{}, error) {
-	s.logger.Info("Processing with Bedrock", "prompt_key", promptKey)
+	chain, err := s.modelPolicy.Resolve("", "")
+	if err != nil {
+		return nil, err
+	}
+	return s.processWithModels(ctx, promptKey, input, chain, "")
+}
+
+// processWithModels renders the prompt and invokes the models of the chain in
+// order, moving to the next one only when the current model throttles or times out.
+// A non-empty correction is appended to the rendered prompt.
+func (s *aiService) processWithModels(ctx context.Context, promptKey string, input map[string]interface{}, chain []aws.FoundationModel, correction string) (map[string]interface{}, error) {
+	s.logger.Info("Processing with Bedrock", "prompt_key", promptKey, "models", len(chain))
 
 	// Render the prompt using the prompt service
 	renderedPrompt, err := s.promptService.RenderPrompt(ctx, promptKey, input)
@@ -137,32 +221,72 @@ func (s *aiService) ProcessWithBedrock(ctx context.Context, promptKey string, in
 		s.logger.Error("Failed to render prompt", "error", err, "prompt_key", promptKey)
 		return nil, fmt.Errorf("failed to render prompt: %w", err)
 	}
+	renderedPrompt += correction
+
+	promptTokens := s.tokenizer.Count(renderedPrompt)
+	s.logger.Debug("Prompt rendered", "prompt_key", promptKey, "length", len(renderedPrompt), "estimated_tokens", promptTokens)
 
-	s.logger.Debug("Prompt rendered", "prompt_key", promptKey, "length", len(renderedPrompt))
+	attempted := make([]string, 0, len(chain))
+	var lastErr error
+	for i, model := range chain {
+		attempted = append(attempted, string(model))
+
+		result, err := s.invokeModel(ctx, model, promptKey, renderedPrompt, promptTokens, len(input))
+		if err == nil {
+			result["requested_model"] = string(chain[0])
+			result["fallback_used"] = i > 0
+			result["attempted_models"] = attempted
+			return result, nil
+		}
+		lastErr = err
+
+		if !aws.ShouldFallback(err) || ctx.Err() != nil || i == len(chain)-1 {
+			break
+		}
+		s.logger.Warn("Model unavailable, falling back", "prompt_key", promptKey, "model", model, "next_model", chain[i+1], "error", err)
+		s.metrics.RecordError("model_fallback", "ai_service", "")
+	}
+
+	return nil, lastErr
+}
+
+// invokeModel sends a rendered prompt to a single Bedrock model
+func (s *aiService) invokeModel(ctx context.Context, model aws.FoundationModel, promptKey, renderedPrompt string, promptTokens, inputVariables int) (map[string]interface{}, error) {
+	// Pre-flight token estimation against the model context window
+	modelConfig := aws.GetModelConfig(model)
+	if promptTokens >= modelConfig.ContextWindow {
+		return nil, fmt.Errorf("prompt too large: %d tokens exceeds context window of %d", promptTokens, modelConfig.ContextWindow)
+	}
+	maxTokens := modelConfig.MaxTokens
+	if remaining := modelConfig.ContextWindow - promptTokens; maxTokens > remaining {
+		maxTokens = remaining
+	}
 
 	// Create conversation with the rendered prompt
 	conversation := aws.NewConversation(
 		aws.NewUserMessage(renderedPrompt),
 	)
 
-	// Create Bedrock conversation request using Claude 4
-	bedrockReq := aws.NewConversationRequest(aws.ModelClaude4Sonnet, conversation).
-		WithMaxTokens(8192).
+	bedrockReq := aws.NewConversationRequest(model, conversation).
+		WithMaxTokens(maxTokens).
 		WithTemperature(0.7).
 		WithTopP(0.9)
+	if s.deterministic {
+		bedrockReq.WithDeterministic()
+	}
 
 	// Add metadata
 	bedrockReq.Metadata = map[string]interface{}{
 		"prompt_key":      promptKey,
-		"input_variables": len(input),
+		"input_variables": inputVariables,
 	}
 
 	// Invoke Bedrock model with conversation
 	startTime := time.Now()
 	bedrockResp, err := s.bedrockClient.InvokeConversation(ctx, bedrockReq)
 	if err != nil {
-		s.logger.Error("Failed to invoke Bedrock model", "error", err, "prompt_key", promptKey)
-		return nil, fmt.Errorf("failed to invoke Bedrock model: %w", err)
+		s.logger.Error("Failed to invoke Bedrock model", "error", err, "prompt_key", promptKey, "model", model)
+		return nil, fmt.Errorf("failed to invoke Bedrock model %s: %w", model, err)
 	}
 	processingTime := time.Since(startTime)
 
@@ -173,23 +297,39 @@ func (s *aiService) ProcessWithBedrock(ctx context.Context, promptKey string, in
 		"result":          bedrockResp.Content,
 		"confidence":      0.85, // Default confidence, could be enhanced based on model response
 		"tokens_used":     bedrockResp.TokensUsed,
+		"input_tokens":    bedrockResp.InputTokens,
+		"output_tokens":   bedrockResp.OutputTokens,
+		"cost":            modelConfig.EstimateCost(bedrockResp.InputTokens, bedrockResp.OutputTokens),
 		"processing_time": processingTime.String(),
-		"model":           bedrockReq.Model,
+		"model":           string(bedrockReq.Model),
+		"model_alias":     aws.ModelAlias(bedrockReq.Model),
 		"timestamp":       bedrockResp.ProcessedAt,
 		"finish_reason":   bedrockResp.FinishReason,
+		"deterministic":   bedrockReq.Deterministic,
 		"metadata": map[string]interface{}{
 			"prompt_length":    len(renderedPrompt),
+			"estimated_tokens": promptTokens,
 			"response_length":  len(bedrockResp.Content),
-			"input_variables":  len(input),
+			"input_variables":  inputVariables,
 			"bedrock_metadata": bedrockResp.Metadata,
 		},
 	}
 
 	s.logger.Info("Bedrock processing completed",
 		"prompt_key", promptKey,
+		"model", model,
 		"tokens_used", bedrockResp.TokensUsed,
 		"processing_time", processingTime,
 		"content_length", len(bedrockResp.Content))
 
 	return result, nil
 }
+
+// recordUsage stores the usage of a tenant's Bedrock request. Spend reporting
+// is not worth failing a request that already succeeded, so errors are logged.
+// The tokens are paid for even if the client has gone, so it is still stored.
+func recordUsage(ctx context.Context, usage models.AIUsageRepository, logger *logger.Logger, entry *models.AIUsage) {
+	if err := usage.Create(context.WithoutCancel(ctx), entry); err != nil {
+		logger.Error("Failed to record AI usage", "error", err, "tenant_id", entry.TenantID, "model", entry.Model, "feature", entry.Feature)
+	}
+}
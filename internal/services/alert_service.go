@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+const (
+	// maxAlertRulesPerUser bounds the rules evaluated against every stats row
+	maxAlertRulesPerUser = 50
+	// alertTenantPage is how many tenants an evaluation loads at a time
+	alertTenantPage = 100
+	// alertEngagementMinViews is the views from which engagement rules apply,
+	// so new videos do not alert about a rate they have not had time to earn
+	alertEngagementMinViews = 100
+)
+
+// alertService implements the AlertService interface
+type alertService struct {
+	rules   models.AlertRuleRepository
+	tenants models.TenantRepository
+	videos  models.VideoRepository
+	stats   models.VideoStatsRepository
+	notify  NotificationService
+	clock   clock.Clock
+	logger  *logger.Logger
+}
+
+var _ AlertService = (*alertService)(nil)
+
+// NewAlertService creates an alert service delivering alerts through the
+// notification service
+func NewAlertService(rules models.AlertRuleRepository, tenants models.TenantRepository, videos models.VideoRepository, stats models.VideoStatsRepository, notify NotificationService, clock clock.Clock, logger *logger.Logger) AlertService {
+	return &alertService{
+		rules:   rules,
+		tenants: tenants,
+		videos:  videos,
+		stats:   stats,
+		notify:  notify,
+		clock:   clock,
+		logger:  logger,
+	}
+}
+
+// CreateRule sets up a rule notifying the user
+func (s *alertService) CreateRule(ctx context.Context, tenantID, userID string, req *models.AlertRuleRequest) (*models.AlertRule, error) {
+	if err := s.validate(ctx, tenantID, req); err != nil {
+		return nil, err
+	}
+	count, err := s.rules.CountByUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count alert rules: %w", err)
+	}
+	if count >= maxAlertRulesPerUser {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "at most %d alert rules per user", maxAlertRulesPerUser)
+	}
+
+	rule := &models.AlertRule{TenantID: tenantID, UserID: userID}
+	applyAlertRule(rule, req)
+	if err := s.rules.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (s *alertService) GetRule(ctx context.Context, tenantID, userID, id string) (*models.AlertRule, error) {
+	return s.rules.Get(ctx, tenantID, userID, id)
+}
+
+func (s *alertService) ListRules(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.AlertRule, error) {
+	rules, err := s.rules.List(ctx, tenantID, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	return rules, nil
+}
+
+// UpdateRule replaces the rule's settings. Where it fired is forgotten, so
+// the changed rule notifies again about what still meets it.
+func (s *alertService) UpdateRule(ctx context.Context, tenantID, userID, id string, req *models.AlertRuleRequest) (*models.AlertRule, error) {
+	rule, err := s.rules.Get(ctx, tenantID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(ctx, tenantID, req); err != nil {
+		return nil, err
+	}
+	applyAlertRule(rule, req)
+	if err := s.rules.Update(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (s *alertService) DeleteRule(ctx context.Context, tenantID, userID, id string) error {
+	return s.rules.Delete(ctx, tenantID, userID, id)
+}
+
+// validate checks the rule makes sense for its metric
+func (s *alertService) validate(ctx context.Context, tenantID string, req *models.AlertRuleRequest) error {
+	if !req.Metric.Valid() {
+		return i18n.Errorf(models.ErrInvalidInput, "metric must be one of %v", append(models.AlertStatsMetrics, models.AlertPublicationFailures))
+	}
+	if !req.Condition.Valid() {
+		return i18n.Errorf(models.ErrInvalidInput, "condition must be above or below")
+	}
+	switch {
+	case req.Threshold < 0:
+		return i18n.Errorf(models.ErrInvalidInput, "threshold must not be negative")
+	case req.Metric == models.AlertEngagementRate && req.Threshold > 100:
+		return i18n.Errorf(models.ErrInvalidInput, "an engagement rate threshold is a percentage, at most 100")
+	case req.Metric == models.AlertPublicationFailures && (req.Condition != models.AlertAbove || req.Threshold < 1 || req.Threshold != math.Trunc(req.Threshold)):
+		return i18n.Errorf(models.ErrInvalidInput, "publication failures alert above a whole number of failures")
+	}
+	if req.Platform != "" && !models.Platform(req.Platform).Valid() {
+		return fmt.Errorf("%w: %s", models.ErrInvalidPlatform, req.Platform)
+	}
+	if req.VideoID != "" {
+		if _, err := s.videos.GetByID(ctx, tenantID, req.VideoID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyAlertRule copies the request onto the rule; rules are enabled unless told otherwise
+func applyAlertRule(rule *models.AlertRule, req *models.AlertRuleRequest) {
+	rule.Name = req.Name
+	rule.Metric = string(req.Metric)
+	rule.Condition = string(req.Condition)
+	rule.Threshold = req.Threshold
+	rule.Platform = req.Platform
+	rule.VideoID = req.VideoID
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+}
+
+// EvaluateStats checks the stats rules of every tenant. A failing tenant is
+// logged and counted, and does not stop the others.
+func (s *alertService) EvaluateStats(ctx context.Context) (*AlertReport, error) {
+	report := &AlertReport{}
+	for offset := 0; ; offset += alertTenantPage {
+		tenants, err := s.tenants.List(ctx, alertTenantPage, offset)
+		if err != nil {
+			return report, fmt.Errorf("failed to list tenants: %w", err)
+		}
+		for _, tenant := range tenants {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			if err := s.evaluateTenant(ctx, tenant.ID, report); err != nil {
+				if ctx.Err() != nil {
+					return report, ctx.Err()
+				}
+				report.Failed++
+				s.logger.Error("Failed to evaluate alert rules", "error", err, "tenant_id", tenant.ID)
+			}
+		}
+		if len(tenants) < alertTenantPage {
+			break
+		}
+	}
+	if report.Fired > 0 || report.Resolved > 0 {
+		s.logger.Info("Alert rules evaluated", "tenants", report.Tenants, "fired", report.Fired, "resolved", report.Resolved)
+	}
+	return report, nil
+}
+
+// evaluateTenant reads the tenant's stats once for all its rules. A rule
+// fires for a stats row when its condition starts to hold and is resolved
+// when it stops, or when the row is gone.
+func (s *alertService) evaluateTenant(ctx context.Context, tenantID string, report *AlertReport) error {
+	rules, err := s.rules.ListEnabled(ctx, tenantID, models.AlertStatsMetrics)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	report.Tenants++
+	report.Rules += len(rules)
+
+	ruleIDs := make([]string, len(rules))
+	for i, rule := range rules {
+		ruleIDs[i] = rule.ID
+	}
+	firings, err := s.rules.ListFirings(ctx, tenantID, ruleIDs)
+	if err != nil {
+		return fmt.Errorf("failed to list alert firings: %w", err)
+	}
+	fired := make(map[string]*models.AlertFiring, len(firings))
+	for _, firing := range firings {
+		fired[firing.RuleID+"/"+firing.SubjectID] = firing
+	}
+
+	// Notifications are sent once the cursor is closed
+	type hit struct {
+		rule  *models.AlertRule
+		stats *models.VideoStats
+	}
+	var hits []hit
+	holding := map[string]bool{}
+	err = s.stats.Stream(ctx, tenantID, nil, "", func(stats *models.VideoStats) error {
+		for _, rule := range rules {
+			metric := models.AlertMetric(rule.Metric)
+			if !rule.Applies(stats.VideoID, stats.Platform) ||
+				(metric == models.AlertEngagementRate && stats.Views < alertEngagementMinViews) ||
+				!rule.Holds(stats.AlertValue(metric)) {
+				continue
+			}
+			key := rule.ID + "/" + stats.ID
+			holding[key] = true
+			if fired[key] == nil {
+				hits = append(hits, hit{rule: rule, stats: stats})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read stats: %w", err)
+	}
+
+	var resolved []string
+	for key, firing := range fired {
+		if !holding[key] {
+			resolved = append(resolved, firing.ID)
+		}
+	}
+	if err := s.rules.Resolve(ctx, tenantID, resolved); err != nil {
+		return fmt.Errorf("failed to resolve alert firings: %w", err)
+	}
+	report.Resolved += len(resolved)
+
+	for _, h := range hits {
+		metric := models.AlertMetric(h.rule.Metric)
+		message := i18n.M("%s is now %s for video %s on %s", metric, formatAlertValue(metric, h.stats.AlertValue(metric)), h.stats.VideoID, h.stats.Platform)
+		if s.fire(ctx, h.rule, h.stats.ID, message) {
+			report.Fired++
+		}
+	}
+	return nil
+}
+
+// PublicationFailed checks the publication rules of the job's tenant after a
+// failed attempt. Errors are logged: the failure itself is already recorded.
+func (s *alertService) PublicationFailed(ctx context.Context, job *models.PublicationJob) {
+	rules, err := s.rules.ListEnabled(ctx, job.TenantID, []models.AlertMetric{models.AlertPublicationFailures})
+	if err != nil {
+		s.logger.Error("Failed to list publication alert rules", "error", err, "tenant_id", job.TenantID, "publication_id", job.ID)
+		return
+	}
+	for _, rule := range rules {
+		if !rule.Applies(job.VideoID, job.Platform) || !rule.Holds(float64(job.RetryCount)) {
+			continue
+		}
+		s.fire(ctx, rule, job.ID, i18n.M("Publication %s of video %s on %s failed %d times: %s",
+			job.ID, job.VideoID, job.Platform, job.RetryCount, job.ErrorMsg))
+	}
+}
+
+// fire notifies the rule's user unless the rule already fired for the
+// subject, reporting whether it did
+func (s *alertService) fire(ctx context.Context, rule *models.AlertRule, subjectID string, message i18n.Message) bool {
+	firing := &models.AlertFiring{TenantID: rule.TenantID, RuleID: rule.ID, SubjectID: subjectID, FiredAt: s.clock.Now()}
+	created, err := s.rules.Fire(ctx, firing)
+	if err != nil {
+		s.logger.Error("Failed to record alert", "error", err, "tenant_id", rule.TenantID, "rule_id", rule.ID, "subject_id", subjectID)
+		return false
+	}
+	if !created {
+		return false
+	}
+	if err := s.notify.Notify(ctx, rule.TenantID, rule.UserID, i18n.M("Alert: %s", rule.Name), message); err != nil {
+		s.logger.Error("Failed to deliver alert", "error", err, "tenant_id", rule.TenantID, "rule_id", rule.ID, "user_id", rule.UserID)
+	}
+	return true
+}
+
+// formatAlertValue writes counts as integers and rates with two decimals
+func formatAlertValue(metric models.AlertMetric, value float64) string {
+	if metric == models.AlertEngagementRate {
+		return strconv.FormatFloat(value, 'f', 2, 64) + "%"
+	}
+	return strconv.FormatFloat(value, 'f', 0, 64)
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memoryAlertRuleRepo stores rules and their firings
+type memoryAlertRuleRepo struct {
+	rules   []*models.AlertRule
+	firings []*models.AlertFiring
+}
+
+func (r *memoryAlertRuleRepo) Create(ctx context.Context, rule *models.AlertRule) error {
+	rule.ID = fmt.Sprintf("rule-%d", len(r.rules)+1)
+	r.rules = append(r.rules, rule)
+	return nil
+}
+
+func (r *memoryAlertRuleRepo) Get(ctx context.Context, tenantID, userID, id string) (*models.AlertRule, error) {
+	for _, rule := range r.rules {
+		if rule.TenantID == tenantID && rule.UserID == userID && rule.ID == id {
+			copied := *rule
+			return &copied, nil
+		}
+	}
+	return nil, models.ErrAlertRuleNotFound
+}
+
+func (r *memoryAlertRuleRepo) List(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.AlertRule, error) {
+	var rules []*models.AlertRule
+	for _, rule := range r.rules {
+		if rule.TenantID == tenantID && rule.UserID == userID {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (r *memoryAlertRuleRepo) CountByUser(ctx context.Context, tenantID, userID string) (int64, error) {
+	rules, _ := r.List(ctx, tenantID, userID, 0, 0)
+	return int64(len(rules)), nil
+}
+
+func (r *memoryAlertRuleRepo) Update(ctx context.Context, rule *models.AlertRule) error {
+	for i, stored := range r.rules {
+		if stored.ID == rule.ID {
+			r.rules[i] = rule
+		}
+	}
+	r.resolveRule(rule.ID)
+	return nil
+}
+
+func (r *memoryAlertRuleRepo) Delete(ctx context.Context, tenantID, userID, id string) error {
+	return nil
+}
+
+func (r *memoryAlertRuleRepo) ListEnabled(ctx context.Context, tenantID string, metrics []models.AlertMetric) ([]*models.AlertRule, error) {
+	var rules []*models.AlertRule
+	for _, rule := range r.rules {
+		for _, metric := range metrics {
+			if rule.TenantID == tenantID && rule.Enabled && rule.Metric == string(metric) {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules, nil
+}
+
+func (r *memoryAlertRuleRepo) ListFirings(ctx context.Context, tenantID string, ruleIDs []string) ([]*models.AlertFiring, error) {
+	var firings []*models.AlertFiring
+	for _, firing := range r.firings {
+		for _, ruleID := range ruleIDs {
+			if firing.TenantID == tenantID && firing.RuleID == ruleID {
+				firings = append(firings, firing)
+			}
+		}
+	}
+	return firings, nil
+}
+
+func (r *memoryAlertRuleRepo) Fire(ctx context.Context, firing *models.AlertFiring) (bool, error) {
+	for _, fired := range r.firings {
+		if fired.RuleID == firing.RuleID && fired.SubjectID == firing.SubjectID {
+			return false, nil
+		}
+	}
+	firing.ID = fmt.Sprintf("firing-%d", len(r.firings)+1)
+	r.firings = append(r.firings, firing)
+	return true, nil
+}
+
+func (r *memoryAlertRuleRepo) Resolve(ctx context.Context, tenantID string, ids []string) error {
+	for _, id := range ids {
+		for i, firing := range r.firings {
+			if firing.ID == id {
+				r.firings = append(r.firings[:i], r.firings[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (r *memoryAlertRuleRepo) resolveRule(ruleID string) {
+	var kept []*models.AlertFiring
+	for _, firing := range r.firings {
+		if firing.RuleID != ruleID {
+			kept = append(kept, firing)
+		}
+	}
+	r.firings = kept
+}
+
+// alertMessages records the messages notified to each user
+type alertMessages struct {
+	NotificationService
+	messages map[string][]string
+}
+
+func (n *alertMessages) Notify(ctx context.Context, tenantID, userID string, subject, message i18n.Message) error {
+	n.messages[userID] = append(n.messages[userID], subject.In(i18n.Default)+" - "+message.In(i18n.Default))
+	return nil
+}
+
+type alertFixture struct {
+	svc           AlertService
+	rules         *memoryAlertRuleRepo
+	stats         *scoreStore
+	notifications *alertMessages
+}
+
+func newAlertFixture() *alertFixture {
+	f := &alertFixture{
+		rules:         &memoryAlertRuleRepo{},
+		stats:         &scoreStore{stats: map[string][]*models.VideoStats{}},
+		notifications: &alertMessages{messages: map[string][]string{}},
+	}
+	videos := &publicationVideoRepo{videos: map[string]*models.Video{"video-1": {ID: "video-1", TenantID: "acme"}}}
+	tenants := &tenantList{tenants: []*models.Tenant{{ID: "acme"}}}
+	f.svc = NewAlertService(f.rules, tenants, videos, f.stats, f.notifications,
+		clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)), logger.New("error", "test"))
+	return f
+}
+
+func TestAlertService_CreateRuleValidates(t *testing.T) {
+	f := newAlertFixture()
+	ctx := context.Background()
+
+	invalid := []*models.AlertRuleRequest{
+		{Name: "x", Metric: "watch_time", Condition: models.AlertAbove, Threshold: 1},
+		{Name: "x", Metric: models.AlertViews, Condition: "equals", Threshold: 1},
+		{Name: "x", Metric: models.AlertViews, Condition: models.AlertAbove, Threshold: -1},
+		{Name: "x", Metric: models.AlertEngagementRate, Condition: models.AlertBelow, Threshold: 150},
+		{Name: "x", Metric: models.AlertPublicationFailures, Condition: models.AlertBelow, Threshold: 2},
+		{Name: "x", Metric: models.AlertPublicationFailures, Condition: models.AlertAbove, Threshold: 1.5},
+	}
+	for _, req := range invalid {
+		_, err := f.svc.CreateRule(ctx, "acme", "user-1", req)
+		assert.ErrorIs(t, err, models.ErrInvalidInput, "%+v", req)
+	}
+	_, err := f.svc.CreateRule(ctx, "acme", "user-1", &models.AlertRuleRequest{Name: "x", Metric: models.AlertViews, Condition: models.AlertAbove, Platform: "myspace"})
+	assert.ErrorIs(t, err, models.ErrInvalidPlatform)
+	_, err = f.svc.CreateRule(ctx, "acme", "user-1", &models.AlertRuleRequest{Name: "x", Metric: models.AlertViews, Condition: models.AlertAbove, VideoID: "video-2"})
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+
+	disabled := false
+	rule, err := f.svc.CreateRule(ctx, "acme", "user-1", &models.AlertRuleRequest{Name: "x", Metric: models.AlertViews, Condition: models.AlertAbove, Threshold: 100000, VideoID: "video-1", Enabled: &disabled})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", rule.UserID)
+	assert.False(t, rule.Enabled)
+}
+
+func TestAlertService_EvaluateStatsFiresOncePerCrossing(t *testing.T) {
+	f := newAlertFixture()
+	ctx := context.Background()
+	_, err := f.svc.CreateRule(ctx, "acme", "user-1", &models.AlertRuleRequest{Name: "100k views", Metric: models.AlertViews, Condition: models.AlertAbove, Threshold: 100000})
+	require.NoError(t, err)
+	_, err = f.svc.CreateRule(ctx, "acme", "user-2", &models.AlertRuleRequest{Name: "Low engagement", Metric: models.AlertEngagementRate, Condition: models.AlertBelow, Threshold: 2, Platform: "tiktok"})
+	require.NoError(t, err)
+
+	hit := &models.VideoStats{ID: "stats-1", VideoID: "video-1", Platform: "youtube", Views: 120000, Likes: 1000}
+	f.stats.stats["acme"] = []*models.VideoStats{
+		hit,
+		{ID: "stats-2", VideoID: "video-2", Platform: "youtube", Views: 5000},
+		{ID: "stats-3", VideoID: "video-1", Platform: "tiktok", Views: 1000, Likes: 10},
+		{ID: "stats-4", VideoID: "video-3", Platform: "tiktok", Views: 50},
+	}
+
+	report, err := f.svc.EvaluateStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &AlertReport{Tenants: 1, Rules: 2, Fired: 2}, report)
+	assert.Equal(t, []string{"Alert: 100k views - views is now 120000 for video video-1 on youtube"}, f.notifications.messages["user-1"])
+	assert.Equal(t, []string{"Alert: Low engagement - engagement_rate is now 1.00% for video video-1 on tiktok"}, f.notifications.messages["user-2"],
+		"videos with few views are not held to an engagement rate")
+
+	report, err = f.svc.EvaluateStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Fired, "a rule notifies once while its condition holds")
+
+	// Views do not go down, but a corrected count can
+	hit.Views = 90000
+	report, err = f.svc.EvaluateStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Resolved)
+	hit.Views = 130000
+	report, err = f.svc.EvaluateStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Fired)
+	assert.Len(t, f.notifications.messages["user-1"], 2)
+}
+
+func TestAlertService_PublicationFailed(t *testing.T) {
+	f := newAlertFixture()
+	ctx := context.Background()
+	_, err := f.svc.CreateRule(ctx, "acme", "user-1", &models.AlertRuleRequest{Name: "Failing twice", Metric: models.AlertPublicationFailures, Condition: models.AlertAbove, Threshold: 2})
+	require.NoError(t, err)
+
+	job := &models.PublicationJob{ID: "pub-1", TenantID: "acme", VideoID: "video-1", Platform: "youtube", ErrorMsg: "release: backend error"}
+	for attempt := 1; attempt <= 3; attempt++ {
+		job.RetryCount = attempt
+		f.svc.PublicationFailed(ctx, job)
+	}
+	assert.Equal(t, []string{"Alert: Failing twice - Publication pub-1 of video video-1 on youtube failed 2 times: release: backend error"},
+		f.notifications.messages["user-1"])
+}
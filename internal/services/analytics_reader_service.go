@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// analyticsReaderService implements the AnalyticsReaderService interface
+type analyticsReaderService struct {
+	readers models.AnalyticsReaderRepository
+	tenants models.TenantRepository
+	stats   models.VideoStatsRepository
+	sink    AnalyticsSink
+	clock   clock.Clock
+	logger  *logger.Logger
+}
+
+var _ AnalyticsReaderService = (*analyticsReaderService)(nil)
+
+// NewAnalyticsReaderService creates an analytics reader service querying the
+// sink when one is configured and answers the query, MySQL otherwise
+func NewAnalyticsReaderService(readers models.AnalyticsReaderRepository, tenants models.TenantRepository, stats models.VideoStatsRepository, sink AnalyticsSink, clock clock.Clock, logger *logger.Logger) AnalyticsReaderService {
+	return &analyticsReaderService{readers: readers, tenants: tenants, stats: stats, sink: sink, clock: clock, logger: logger}
+}
+
+// CreateReader draws a random token and only keeps its hash
+func (s *analyticsReaderService) CreateReader(ctx context.Context, tenantID, userID string, req *models.AnalyticsReaderRequest) (*models.AnalyticsReader, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate analytics token: %w", err)
+	}
+	token := models.AnalyticsReaderPrefix + hex.EncodeToString(secret)
+
+	reader := &models.AnalyticsReader{
+		TenantID:    tenantID,
+		Name:        req.Name,
+		TokenHash:   models.HashAnalyticsToken(token),
+		TokenSuffix: token[len(token)-4:],
+		CreatedBy:   userID,
+	}
+	if err := s.readers.Create(ctx, reader); err != nil {
+		return nil, fmt.Errorf("failed to create analytics reader: %w", err)
+	}
+	s.logger.Info("Analytics reader created", "tenant_id", tenantID, "reader_id", reader.ID, "user_id", userID)
+
+	reader.Token = token
+	return reader, nil
+}
+
+func (s *analyticsReaderService) ListReaders(ctx context.Context, tenantID string, limit, offset int) ([]*models.AnalyticsReader, error) {
+	readers, err := s.readers.List(ctx, tenantID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list analytics readers: %w", err)
+	}
+	return readers, nil
+}
+
+func (s *analyticsReaderService) RevokeReader(ctx context.Context, tenantID, id string) error {
+	if err := s.readers.Revoke(ctx, tenantID, id, s.clock.Now()); err != nil {
+		return err
+	}
+	s.logger.Info("Analytics reader revoked", "tenant_id", tenantID, "reader_id", id)
+	return nil
+}
+
+// Authenticate refuses the readers of tenants no longer active, as their
+// users are refused
+func (s *analyticsReaderService) Authenticate(ctx context.Context, token string) (*models.AnalyticsReader, error) {
+	if !models.IsAnalyticsToken(token) {
+		return nil, models.ErrAnalyticsReaderNotFound
+	}
+	reader, err := s.readers.FindByTokenHash(ctx, models.HashAnalyticsToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.tenants.GetByID(ctx, reader.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	if tenant.Status != "" && tenant.Status != models.TenantStatusActive {
+		s.logger.Info("Rejected analytics reader of inactive tenant", "tenant_id", reader.TenantID, "reader_id", reader.ID, "status", tenant.Status)
+		if tenant.Status == models.TenantStatusSuspended {
+			return nil, models.ErrTenantSuspended
+		}
+		return nil, models.ErrTenantInactive
+	}
+
+	// Tracking use is best effort, it never fails a query
+	if err := s.readers.MarkUsed(ctx, reader.TenantID, reader.ID, s.clock.Now()); err != nil {
+		s.logger.Warn("Failed to mark analytics reader used", "error", err, "reader_id", reader.ID)
+	}
+	return reader, nil
+}
+
+// Query falls back to MySQL when the sink fails, so BI tools get an answer
+// while ClickHouse is down
+func (s *analyticsReaderService) Query(ctx context.Context, tenantID string, query *models.AnalyticsQuery) (*models.AnalyticsResult, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if s.sink != nil && s.sink.Routes(tenantID, query) {
+		result, err := s.sink.Query(ctx, tenantID, query)
+		if err == nil {
+			return result, nil
+		}
+		s.logger.Warn("Analytics sink query failed, falling back to MySQL", "error", err, "tenant_id", tenantID)
+	}
+	result, err := s.stats.Query(ctx, tenantID, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analytics: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memoryAnalyticsReaderRepo stores analytics readers in memory
+type memoryAnalyticsReaderRepo struct {
+	models.AnalyticsReaderRepository
+	readers []*models.AnalyticsReader
+}
+
+func (r *memoryAnalyticsReaderRepo) Create(ctx context.Context, reader *models.AnalyticsReader) error {
+	reader.ID = "reader-1"
+	copied := *reader
+	r.readers = append(r.readers, &copied)
+	return nil
+}
+
+func (r *memoryAnalyticsReaderRepo) FindByTokenHash(ctx context.Context, hash string) (*models.AnalyticsReader, error) {
+	for _, reader := range r.readers {
+		if reader.TokenHash == hash && reader.RevokedAt == nil {
+			return reader, nil
+		}
+	}
+	return nil, models.ErrAnalyticsReaderNotFound
+}
+
+func (r *memoryAnalyticsReaderRepo) Revoke(ctx context.Context, tenantID, id string, at time.Time) error {
+	for _, reader := range r.readers {
+		if reader.TenantID == tenantID && reader.ID == id {
+			reader.RevokedAt = &at
+			return nil
+		}
+	}
+	return models.ErrAnalyticsReaderNotFound
+}
+
+func (r *memoryAnalyticsReaderRepo) MarkUsed(ctx context.Context, tenantID, id string, at time.Time) error {
+	for _, reader := range r.readers {
+		if reader.ID == id {
+			reader.LastUsedAt = &at
+		}
+	}
+	return nil
+}
+
+// queriedStatsRepo records the tenant and query it runs
+type queriedStatsRepo struct {
+	models.VideoStatsRepository
+	tenantID string
+	query    *models.AnalyticsQuery
+}
+
+func (r *queriedStatsRepo) Query(ctx context.Context, tenantID string, query *models.AnalyticsQuery) (*models.AnalyticsResult, error) {
+	r.tenantID, r.query = tenantID, query
+	return &models.AnalyticsResult{Columns: query.Columns()}, nil
+}
+
+func TestAnalyticsReaderService(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	readers := &memoryAnalyticsReaderRepo{}
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{
+		"acme": {ID: "acme", Status: models.TenantStatusActive},
+	}}
+	stats := &queriedStatsRepo{}
+	svc := NewAnalyticsReaderService(readers, tenants, stats, nil, clock.NewFake(now), logger.New("error", "test"))
+	ctx := context.Background()
+
+	reader, err := svc.CreateReader(ctx, "acme", "admin-1", &models.AnalyticsReaderRequest{Name: "Looker"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(reader.Token, models.AnalyticsReaderPrefix))
+	assert.Equal(t, reader.Token[len(reader.Token)-4:], reader.TokenSuffix)
+	assert.Empty(t, readers.readers[0].Token, "only the hash is stored")
+	assert.Equal(t, models.HashAnalyticsToken(reader.Token), readers.readers[0].TokenHash)
+
+	got, err := svc.Authenticate(ctx, reader.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", got.TenantID)
+	assert.Equal(t, now, *readers.readers[0].LastUsedAt)
+
+	_, err = svc.Authenticate(ctx, "mfar_unknown")
+	assert.ErrorIs(t, err, models.ErrAnalyticsReaderNotFound)
+	_, err = svc.Authenticate(ctx, strings.TrimPrefix(reader.Token, models.AnalyticsReaderPrefix))
+	assert.ErrorIs(t, err, models.ErrAnalyticsReaderNotFound, "user tokens are not reader tokens")
+
+	_, err = svc.Query(ctx, "acme", &models.AnalyticsQuery{Measures: []models.AnalyticsMeasure{"password_hash"}})
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "only whitelisted measures")
+	_, err = svc.Query(ctx, "acme", &models.AnalyticsQuery{
+		Measures: []models.AnalyticsMeasure{models.AnalyticsViews},
+		OrderBy:  []models.AnalyticsOrder{{Field: "likes"}},
+	})
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "rows only sort by selected columns")
+	result, err := svc.Query(ctx, "acme", &models.AnalyticsQuery{
+		Dimensions: []models.AnalyticsDimension{models.AnalyticsMonth},
+		Measures:   []models.AnalyticsMeasure{models.AnalyticsViews},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"created_month", "views"}, result.Columns)
+	assert.Equal(t, "acme", stats.tenantID)
+	assert.Equal(t, models.MaxAnalyticsRows, stats.query.Limit)
+
+	tenants.tenants["acme"].Status = models.TenantStatusSuspended
+	_, err = svc.Authenticate(ctx, reader.Token)
+	assert.ErrorIs(t, err, models.ErrTenantSuspended)
+	tenants.tenants["acme"].Status = models.TenantStatusActive
+
+	require.NoError(t, svc.RevokeReader(ctx, "acme", reader.ID))
+	_, err = svc.Authenticate(ctx, reader.Token)
+	assert.ErrorIs(t, err, models.ErrAnalyticsReaderNotFound, "revoked readers are refused")
+	assert.ErrorIs(t, svc.RevokeReader(ctx, "other", reader.ID), models.ErrAnalyticsReaderNotFound)
+}
@@ -3,118 +3,354 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
 )
 
+// Leaderboard limits
+const (
+	defaultLeaderboardSize = 10
+	maxLeaderboardSize     = 100
+
+	// leaderboardMaxAge bounds drift from stats written outside the
+	// repository (seeding, manual fixes): older summaries trigger a rebuild
+	leaderboardMaxAge = time.Hour
+)
+
+// maxStatsHistoryDays caps a history request to about 13 monthly partitions
+const maxStatsHistoryDays = 366
+
+// trafficTopVideos is how many videos traffic source analytics detail
+const trafficTopVideos = 10
+
 // analyticsService implements the AnalyticsService interface
 type analyticsService struct {
-	videoRepo models.VideoRepository
-	logger    *logger.Logger
+	videoRepo  models.VideoRepository
+	workspaces models.WorkspaceRepository
+	statsRepo  models.VideoStatsRepository
+	tenants    models.TenantRepository
+	benchmarks *Benchmarks
+	logger     *logger.Logger
 }
 
-// NewAnalyticsService creates a new analytics service instance
-func NewAnalyticsService(videoRepo models.VideoRepository, logger *logger.Logger) AnalyticsService {
+var _ AnalyticsService = (*analyticsService)(nil)
+
+// NewAnalyticsService creates a new analytics service instance comparing
+// engagement with the benchmarks of the tenant's vertical
+func NewAnalyticsService(videoRepo models.VideoRepository, workspaces models.WorkspaceRepository, statsRepo models.VideoStatsRepository, tenants models.TenantRepository, benchmarks *Benchmarks, logger *logger.Logger) AnalyticsService {
 	return &analyticsService{
-		videoRepo: videoRepo,
-		logger:    logger,
+		videoRepo:  videoRepo,
+		workspaces: workspaces,
+		statsRepo:  statsRepo,
+		tenants:    tenants,
+		benchmarks: benchmarks,
+		logger:     logger,
 	}
 }
 
-// GetVideoStats retrieves statistics for a specific video
-func (s *analyticsService) GetVideoStats(ctx context.Context, tenantID, videoID string) (*models.VideoStats, error) {
+// GetVideoStats retrieves statistics for a specific video, summed across
+// platforms, with its audience and traffic sources across platforms
+func (s *analyticsService) GetVideoStats(ctx context.Context, viewer *models.User, videoID string) (*models.VideoStats, error) {
+	tenantID := viewer.TenantID
 	s.logger.Debug("Getting video stats", "video_id", videoID, "tenant_id", tenantID)
 
-	// First verify the video exists and belongs to the tenant
-	_, err := s.videoRepo.GetByID(tenantID, videoID)
+	// First verify the video exists and the viewer sees it
+	_, err := visibleVideo(ctx, s.videoRepo, s.workspaces, viewer, videoID)
 	if err != nil {
 		s.logger.Error("Failed to get video for stats", "error", err, "video_id", videoID, "tenant_id", tenantID)
 		return nil, fmt.Errorf("failed to get video: %w", err)
 	}
 
-	// TODO: Implement actual stats retrieval from analytics database/service
-	// For now, return mock stats
-	stats := &models.VideoStats{
-		ID:          generateStatsID(),
-		VideoID:     videoID,
-		TenantID:    tenantID,
-		Platform:    "aggregate", // Aggregated stats across platforms
-		Views:       1250,
-		Likes:       89,
-		Shares:      23,
-		Comments:    15,
-		Engagement:  calculateEngagementRate(1250, 89, 23, 15),
-		Revenue:     125.50,
-		Impressions: 5000,
-		UpdatedAt:   time.Now(),
-		CreatedAt:   time.Now(),
-		LastSyncAt:  time.Now(),
+	agg, err := s.statsRepo.GetAggregatedStats(ctx, tenantID, videoID)
+	if err != nil {
+		s.logger.Error("Failed to aggregate video stats", "error", err, "video_id", videoID, "tenant_id", tenantID)
+		return nil, fmt.Errorf("failed to aggregate stats: %w", err)
 	}
 
+	rows, err := s.statsRepo.GetByVideoID(ctx, tenantID, videoID)
+	if err != nil {
+		s.logger.Error("Failed to get video stats rows", "error", err, "video_id", videoID, "tenant_id", tenantID)
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	stats := aggregatedVideoStats(tenantID, videoID, agg)
+	stats.Demographics = models.CombineDemographics(rows)
+	stats.TrafficSources = models.CombineTrafficSources(rows)
 	s.logger.Debug("Video stats retrieved", "video_id", videoID, "tenant_id", tenantID, "views", stats.Views)
 	return stats, nil
 }
 
-// GetVideosStats retrieves statistics for multiple videos
-func (s *analyticsService) GetVideosStats(ctx context.Context, tenantID string, videoIDs []string) ([]*models.VideoStats, error) {
+// GetVideosStats retrieves statistics for multiple videos with one query for
+// the videos and one for their stats. Unknown videos, and those the viewer
+// does not see, are logged and skipped.
+func (s *analyticsService) GetVideosStats(ctx context.Context, viewer *models.User, videoIDs []string) ([]*models.VideoStats, error) {
+	tenantID := viewer.TenantID
 	s.logger.Debug("Getting stats for multiple videos", "tenant_id", tenantID, "video_count", len(videoIDs))
+	if len(videoIDs) == 0 {
+		return nil, nil
+	}
+
+	videos, err := s.videoRepo.GetByIDs(ctx, tenantID, videoIDs)
+	if err != nil {
+		s.logger.Error("Failed to get videos for stats", "error", err, "tenant_id", tenantID, "video_count", len(videoIDs))
+		return nil, fmt.Errorf("failed to get videos: %w", err)
+	}
+	var workspaceIDs []string
+	if models.UserRole(viewer.Role) != models.RoleAdmin {
+		if workspaceIDs, err = managedWorkspaceIDs(ctx, s.workspaces, viewer); err != nil {
+			return nil, err
+		}
+	}
+	found := make(map[string]bool, len(videos))
+	for _, video := range videos {
+		found[video.ID] = video.VisibleTo(viewer, workspaceIDs)
+	}
+
+	aggs, err := s.statsRepo.GetAggregatedStatsForVideos(ctx, tenantID, videoIDs)
+	if err != nil {
+		s.logger.Error("Failed to aggregate videos stats", "error", err, "tenant_id", tenantID, "video_count", len(videoIDs))
+		return nil, fmt.Errorf("failed to aggregate stats: %w", err)
+	}
+	byVideo := make(map[string]*models.StatsAggregation, len(aggs))
+	for _, agg := range aggs {
+		byVideo[agg.VideoID] = agg
+	}
 
 	var stats []*models.VideoStats
 	for _, videoID := range videoIDs {
-		videoStats, err := s.GetVideoStats(ctx, tenantID, videoID)
-		if err != nil {
-			s.logger.Warn("Failed to get stats for video", "video_id", videoID, "error", err)
+		if !found[videoID] {
+			s.logger.Warn("Failed to get stats for video", "video_id", videoID, "error", models.ErrVideoNotFound)
 			continue // Skip failed videos but continue with others
 		}
-		stats = append(stats, videoStats)
+		stats = append(stats, aggregatedVideoStats(tenantID, videoID, byVideo[videoID]))
 	}
 
 	s.logger.Debug("Retrieved stats for videos", "tenant_id", tenantID, "successful_count", len(stats))
 	return stats, nil
 }
 
-// GetVideoStatsHistory retrieves historical statistics for a video
-func (s *analyticsService) GetVideoStatsHistory(ctx context.Context, tenantID, videoID string, from, to time.Time) ([]*models.VideoStats, error) {
+// aggregatedVideoStats presents a cross-platform aggregation as VideoStats;
+// a nil or empty aggregation means the video has no stats yet
+func aggregatedVideoStats(tenantID, videoID string, agg *models.StatsAggregation) *models.VideoStats {
+	stats := &models.VideoStats{
+		VideoID:  videoID,
+		TenantID: tenantID,
+		Platform: "aggregate", // Aggregated stats across platforms
+	}
+	if agg == nil {
+		return stats
+	}
+	stats.Views = agg.TotalViews
+	stats.Likes = agg.TotalLikes
+	stats.Comments = agg.TotalComments
+	stats.Shares = agg.TotalShares
+	stats.Revenue = agg.TotalRevenue
+	stats.Engagement = calculateEngagementRate(agg.TotalViews, agg.TotalLikes, agg.TotalShares, agg.TotalComments)
+	return stats
+}
+
+// GetVideoStatsHistory returns the stats snapshots of a video taken between
+// from and to, oldest first. Ranges longer than maxStatsHistoryDays are cut to
+// the most recent days, which bounds the monthly partitions a request reads.
+func (s *analyticsService) GetVideoStatsHistory(ctx context.Context, viewer *models.User, videoID string, from, to time.Time) ([]*models.VideoStatsSnapshot, error) {
+	tenantID := viewer.TenantID
+	if !from.Before(to) {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "history range must end after it starts")
+	}
+	if earliest := to.AddDate(0, 0, -maxStatsHistoryDays); from.Before(earliest) {
+		from = earliest
+	}
+
 	s.logger.Debug("Getting video stats history", "video_id", videoID, "tenant_id", tenantID, "from", from, "to", to)
 
-	// First verify the video exists
-	_, err := s.videoRepo.GetByID(tenantID, videoID)
+	// First verify the video exists and the viewer sees it
+	_, err := visibleVideo(ctx, s.videoRepo, s.workspaces, viewer, videoID)
 	if err != nil {
 		s.logger.Error("Failed to get video for stats history", "error", err, "video_id", videoID, "tenant_id", tenantID)
 		return nil, fmt.Errorf("failed to get video: %w", err)
 	}
 
-	// TODO: Implement actual historical stats retrieval
-	// For now, return mock historical data
-	var history []*models.VideoStats
-	current := from
-	for current.Before(to) {
-		stats := &models.VideoStats{
-			VideoID:    videoID,
-			TenantID:   tenantID,
-			Views:      int64(900 + current.Day()*10), // Mock increasing views
-			Likes:      int64(50 + current.Day()*2),
-			Shares:     int64(10 + current.Day()),
-			Comments:   int64(5 + current.Day()/2),
-			Engagement: 0.08 + float64(current.Day())*0.001,
-			UpdatedAt:  current,
-		}
-		history = append(history, stats)
-		current = current.AddDate(0, 0, 1) // Add one day
+	history, err := s.statsRepo.GetHistory(ctx, tenantID, videoID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats history: %w", err)
 	}
 
 	s.logger.Debug("Video stats history retrieved", "video_id", videoID, "tenant_id", tenantID, "records", len(history))
 	return history, nil
 }
 
+// GetVideoStatsDaily buckets the history into the days of loc between from
+// and to. A day holds the latest snapshot of each platform taken by its end,
+// so platforms not synced that day carry their previous totals. Days before
+// the first snapshot are left out.
+func (s *analyticsService) GetVideoStatsDaily(ctx context.Context, viewer *models.User, videoID string, from, to time.Time, loc *time.Location) ([]*DailyStats, error) {
+	history, err := s.GetVideoStatsHistory(ctx, viewer, videoID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return bucketDaily(history, loc), nil
+}
+
+// bucketDaily sums, at the end of each day of loc, the latest snapshot of
+// every stats row. history is oldest first.
+func bucketDaily(history []*models.VideoStatsSnapshot, loc *time.Location) []*DailyStats {
+	var days []*DailyStats
+	latest := make(map[string]*models.VideoStatsSnapshot)
+	var day time.Time
+
+	closeDay := func() {
+		d := &DailyStats{Date: day.Format("2006-01-02")}
+		for _, snapshot := range latest {
+			d.Views += snapshot.Views
+			d.Likes += snapshot.Likes
+			d.Comments += snapshot.Comments
+			d.Shares += snapshot.Shares
+			d.Revenue += snapshot.Revenue
+		}
+		days = append(days, d)
+	}
+
+	for _, snapshot := range history {
+		local := snapshot.CreatedAt.In(loc)
+		start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		if day.IsZero() {
+			day = start
+		}
+		for day.Before(start) {
+			closeDay()
+			day = day.AddDate(0, 0, 1)
+		}
+		latest[snapshot.StatsID] = snapshot
+	}
+	if !day.IsZero() {
+		closeDay()
+	}
+	return days
+}
+
+// ExportVideoStats streams every stats row of the videos the viewer sees to
+// fn without loading the export into memory
+func (s *analyticsService) ExportVideoStats(ctx context.Context, viewer *models.User, platform string, fn func(*models.VideoStats) error) error {
+	tenantID := viewer.TenantID
+	s.logger.Info("Exporting video stats", "tenant_id", tenantID, "platform", platform)
+
+	scope, err := videoScope(ctx, s.workspaces, viewer)
+	if err != nil {
+		return err
+	}
+	rows := 0
+	err = s.statsRepo.Stream(ctx, tenantID, scope, platform, func(stats *models.VideoStats) error {
+		rows++
+		return fn(stats)
+	})
+	if err != nil {
+		s.logger.Error("Video stats export stopped", "error", err, "tenant_id", tenantID, "rows", rows)
+		return fmt.Errorf("failed to export stats: %w", err)
+	}
+
+	s.logger.Info("Video stats exported", "tenant_id", tenantID, "rows", rows)
+	return nil
+}
+
+// ListVideoStats lists the stats rows of the videos the viewer sees, by
+// score unless told otherwise. Scores are those of the last recomputation.
+func (s *analyticsService) ListVideoStats(ctx context.Context, viewer *models.User, filter models.StatsListFilter, limit, offset int) ([]*models.VideoStats, int64, error) {
+	if filter.Sort == "" {
+		filter.Sort = "score"
+	}
+	if _, ok := models.StatsSortColumns[filter.Sort]; !ok {
+		return nil, 0, i18n.Errorf(models.ErrInvalidInput, "unknown sort %q", filter.Sort)
+	}
+	for _, bound := range []*float64{filter.MinScore, filter.MaxScore} {
+		if bound != nil && (*bound < 0 || *bound > 100) {
+			return nil, 0, i18n.Errorf(models.ErrInvalidInput, "score bounds must be between 0 and 100")
+		}
+	}
+	if filter.MinScore != nil && filter.MaxScore != nil && *filter.MinScore > *filter.MaxScore {
+		return nil, 0, i18n.Errorf(models.ErrInvalidInput, "min_score must not exceed max_score")
+	}
+
+	scope, err := videoScope(ctx, s.workspaces, viewer)
+	if err != nil {
+		return nil, 0, err
+	}
+	stats, total, err := s.statsRepo.ListScored(ctx, viewer.TenantID, scope, filter, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list stats: %w", err)
+	}
+	return stats, total, nil
+}
+
+// GetTopPerforming ranks videos from the summary table, which stats writes
+// keep current. An empty or old leaderboard is rebuilt for the whole tenant
+// first; if that fails the old entries are served and marked stale.
+func (s *analyticsService) GetTopPerforming(ctx context.Context, tenantID, metric string, limit int) (*Leaderboard, error) {
+	if !models.LeaderboardMetrics[metric] {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "unknown metric %q", metric)
+	}
+	if limit <= 0 {
+		limit = defaultLeaderboardSize
+	}
+	if limit > maxLeaderboardSize {
+		limit = maxLeaderboardSize
+	}
+
+	entries, err := s.statsRepo.GetTopPerforming(ctx, tenantID, metric, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+
+	now := time.Now()
+	board := newLeaderboard(metric, entries, now)
+	if len(entries) > 0 && !board.Stale {
+		return board, nil
+	}
+
+	s.logger.Info("Rebuilding stats summaries", "tenant_id", tenantID, "metric", metric, "entries", len(entries), "age_seconds", board.AgeSeconds)
+	if err := s.statsRepo.RefreshSummaries(ctx, tenantID, nil); err != nil {
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("failed to rebuild leaderboard: %w", err)
+		}
+		s.logger.Warn("Serving stale leaderboard", "tenant_id", tenantID, "metric", metric, "error", err)
+		return board, nil
+	}
+
+	entries, err = s.statsRepo.GetTopPerforming(ctx, tenantID, metric, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+	return newLeaderboard(metric, entries, now), nil
+}
+
+// newLeaderboard computes the staleness metadata of entries
+func newLeaderboard(metric string, entries []*models.VideoStatsSummary, now time.Time) *Leaderboard {
+	if entries == nil {
+		entries = []*models.VideoStatsSummary{}
+	}
+	board := &Leaderboard{Metric: metric, Entries: entries}
+	for _, entry := range entries {
+		if board.RefreshedAt.IsZero() || entry.RefreshedAt.Before(board.RefreshedAt) {
+			board.RefreshedAt = entry.RefreshedAt
+		}
+	}
+	if !board.RefreshedAt.IsZero() {
+		age := now.Sub(board.RefreshedAt)
+		board.AgeSeconds = int64(age / time.Second)
+		board.Stale = age > leaderboardMaxAge
+	}
+	return board
+}
+
 // GetDashboardStats retrieves dashboard statistics for a tenant
 func (s *analyticsService) GetDashboardStats(ctx context.Context, tenantID string) (*DashboardStats, error) {
 	s.logger.Debug("Getting dashboard stats", "tenant_id", tenantID)
 
 	// Get total videos count
-	videos, err := s.videoRepo.List(tenantID, 1000, 0) // Get up to 1000 videos for counting
+	videos, err := s.videoRepo.List(ctx, tenantID, 1000, 0) // Get up to 1000 videos for counting
 	if err != nil {
 		s.logger.Error("Failed to get videos for dashboard stats", "error", err, "tenant_id", tenantID)
 		return nil, fmt.Errorf("failed to get videos: %w", err)
@@ -142,14 +378,18 @@ func (s *analyticsService) GetPerformanceStats(ctx context.Context, tenantID str
 	s.logger.Debug("Getting performance stats", "tenant_id", tenantID, "from", from, "to", to)
 
 	// Get videos for the tenant
-	videos, err := s.videoRepo.List(tenantID, 1000, 0)
+	videos, err := s.videoRepo.List(ctx, tenantID, 1000, 0)
 	if err != nil {
 		s.logger.Error("Failed to get videos for performance stats", "error", err, "tenant_id", tenantID)
 		return nil, fmt.Errorf("failed to get videos: %w", err)
 	}
+	totals, vertical, err := s.platformTotals(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
 
-	// TODO: Implement actual performance stats calculation
-	// For now, return mock stats
+	// TODO: Implement actual video metrics and trends
+	// For now, only platform metrics are computed
 	stats := &PerformanceStats{
 		Period: fmt.Sprintf("%s to %s", from.Format("2006-01-02"), to.Format("2006-01-02")),
 		VideoMetrics: &VideoPerformanceMetrics{
@@ -160,30 +400,22 @@ func (s *analyticsService) GetPerformanceStats(ctx context.Context, tenantID str
 			AverageComments: 15.3,
 			CompletionRate:  0.78,
 		},
-		PlatformMetrics: map[string]*PlatformMetrics{
-			"youtube": {
-				Platform:       "youtube",
-				TotalVideos:    int64(len(videos) / 2),
-				TotalViews:     50000,
-				TotalLikes:     3500,
-				TotalShares:    800,
-				TotalComments:  450,
-				EngagementRate: 0.085,
-				ROI:            4.2,
-			},
-			"tiktok": {
-				Platform:       "tiktok",
-				TotalVideos:    int64(len(videos) / 3),
-				TotalViews:     30000,
-				TotalLikes:     2800,
-				TotalShares:    1200,
-				TotalComments:  320,
-				EngagementRate: 0.12,
-				ROI:            3.8,
-			},
-		},
+		PlatformMetrics:  make(map[string]*PlatformMetrics, len(totals)),
 		EngagementTrends: generateMockEngagementTrends(from, to),
 	}
+	for _, t := range totals {
+		rate := calculateEngagementRate(t.TotalViews, t.TotalLikes, t.TotalShares, t.TotalComments)
+		stats.PlatformMetrics[t.Platform] = &PlatformMetrics{
+			Platform:       t.Platform,
+			TotalVideos:    t.TotalVideos,
+			TotalViews:     t.TotalViews,
+			TotalLikes:     t.TotalLikes,
+			TotalShares:    t.TotalShares,
+			TotalComments:  t.TotalComments,
+			EngagementRate: rate,
+			Benchmark:      s.benchmarks.Compare(t.Platform, vertical, rate),
+		}
+	}
 
 	s.logger.Debug("Performance stats retrieved", "tenant_id", tenantID, "total_videos", stats.VideoMetrics.TotalVideos)
 	return stats, nil
@@ -225,57 +457,151 @@ func (s *analyticsService) GetEngagementAnalytics(ctx context.Context, tenantID
 	s.logger.Debug("Getting engagement analytics", "tenant_id", tenantID, "from", from, "to", to)
 
 	// Get videos for the tenant
-	videos, err := s.videoRepo.List(tenantID, 100, 0) // Get top 100 videos
+	videos, err := s.videoRepo.List(ctx, tenantID, 100, 0) // Get top 100 videos
 	if err != nil {
 		s.logger.Error("Failed to get videos for engagement analytics", "error", err, "tenant_id", tenantID)
 		return nil, fmt.Errorf("failed to get videos: %w", err)
 	}
 
-	// TODO: Implement actual engagement analytics calculation
-	// For now, return mock analytics
+	totals, vertical, err := s.platformTotals(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: Implement actual top content and trends
+	// For now, only engagement totals are computed
 	analytics := &EngagementAnalytics{
-		Period:            fmt.Sprintf("%s to %s", from.Format("2006-01-02"), to.Format("2006-01-02")),
-		TotalEngagement:   125000,
-		AverageEngagement: 1250.0,
-		EngagementRate:    0.085,
-		TopContent:        generateMockTopContent(videos),
-		EngagementByPlatform: map[string]*PlatformEngagement{
-			"youtube": {
-				Platform:       "youtube",
-				TotalViews:     50000,
-				TotalLikes:     3500,
-				TotalShares:    800,
-				TotalComments:  450,
-				EngagementRate: 0.085,
-			},
-			"tiktok": {
-				Platform:       "tiktok",
-				TotalViews:     30000,
-				TotalLikes:     2800,
-				TotalShares:    1200,
-				TotalComments:  320,
-				EngagementRate: 0.12,
-			},
-		},
-		EngagementTrends: generateMockEngagementTrends(from, to),
+		Period:               fmt.Sprintf("%s to %s", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		TopContent:           generateMockTopContent(videos),
+		EngagementByPlatform: make(map[string]*PlatformEngagement, len(totals)),
+		EngagementTrends:     generateMockEngagementTrends(from, to),
+	}
+	var views, rows int64
+	for _, t := range totals {
+		rate := calculateEngagementRate(t.TotalViews, t.TotalLikes, t.TotalShares, t.TotalComments)
+		analytics.EngagementByPlatform[t.Platform] = &PlatformEngagement{
+			Platform:       t.Platform,
+			TotalViews:     t.TotalViews,
+			TotalLikes:     t.TotalLikes,
+			TotalShares:    t.TotalShares,
+			TotalComments:  t.TotalComments,
+			EngagementRate: rate,
+			Benchmark:      s.benchmarks.Compare(t.Platform, vertical, rate),
+		}
+		analytics.TotalEngagement += t.TotalLikes + t.TotalShares + t.TotalComments
+		views += t.TotalViews
+		rows += t.TotalVideos
+	}
+	if rows > 0 {
+		analytics.AverageEngagement = float64(analytics.TotalEngagement) / float64(rows)
+	}
+	if views > 0 {
+		analytics.EngagementRate = float64(analytics.TotalEngagement) / float64(views)
+		analytics.Benchmark = s.benchmarks.CompareOverall(vertical, totals, analytics.EngagementRate)
 	}
 
 	s.logger.Debug("Engagement analytics retrieved", "tenant_id", tenantID, "engagement_rate", analytics.EngagementRate)
 	return analytics, nil
 }
 
-// SyncStats synchronizes statistics data for a tenant
-func (s *analyticsService) SyncStats(ctx context.Context, tenantID string) error {
-	s.logger.Info("Syncing stats", "tenant_id", tenantID)
+// GetTrafficSources splits the views of the videos uploaded in the period by
+// traffic source. Platforms report sources over a video's whole life, so the
+// period picks videos rather than views.
+func (s *analyticsService) GetTrafficSources(ctx context.Context, tenantID string, from, to time.Time) (*TrafficSourceAnalytics, error) {
+	s.logger.Debug("Getting traffic sources", "tenant_id", tenantID, "from", from, "to", to)
 
-	// TODO: Implement actual stats synchronization with external platforms
-	// This would typically involve:
-	// 1. Fetching latest stats from YouTube, TikTok, etc.
-	// 2. Updating local analytics database
-	// 3. Calculating derived metrics
+	rows, err := s.statsRepo.ListTrafficSources(ctx, tenantID, from, to)
+	if err != nil {
+		s.logger.Error("Failed to list traffic sources", "error", err, "tenant_id", tenantID)
+		return nil, fmt.Errorf("failed to list traffic sources: %w", err)
+	}
 
-	s.logger.Info("Stats sync completed", "tenant_id", tenantID)
-	return nil
+	byPlatform := map[string][]*models.VideoStats{}
+	byVideo := map[string][]*models.VideoStats{}
+	for _, row := range rows {
+		byPlatform[row.Platform] = append(byPlatform[row.Platform], row)
+		byVideo[row.VideoID] = append(byVideo[row.VideoID], row)
+	}
+
+	analytics := &TrafficSourceAnalytics{
+		Period:     fmt.Sprintf("%s to %s", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		Overall:    models.SumTrafficSources(rows),
+		ByPlatform: make(map[string]*models.TrafficSourceViews, len(byPlatform)),
+		TopVideos:  make([]*VideoTrafficSources, 0, len(byVideo)),
+	}
+	for platform, stats := range byPlatform {
+		analytics.ByPlatform[platform] = models.SumTrafficSources(stats)
+	}
+	for videoID, stats := range byVideo {
+		analytics.TopVideos = append(analytics.TopVideos, &VideoTrafficSources{VideoID: videoID, TrafficSourceViews: models.SumTrafficSources(stats)})
+	}
+	sort.Slice(analytics.TopVideos, func(i, j int) bool {
+		a, b := analytics.TopVideos[i], analytics.TopVideos[j]
+		if a.Views != b.Views {
+			return a.Views > b.Views
+		}
+		return a.VideoID < b.VideoID
+	})
+	if len(analytics.TopVideos) > trafficTopVideos {
+		analytics.TopVideos = analytics.TopVideos[:trafficTopVideos]
+	}
+
+	s.logger.Debug("Traffic sources retrieved", "tenant_id", tenantID, "videos", len(byVideo))
+	return analytics, nil
+}
+
+func (s *analyticsService) GetDeviceBreakdown(ctx context.Context, tenantID string, filter models.BreakdownFilter) (*ViewsBreakdown, error) {
+	views, err := s.statsRepo.SumViewsByDevice(ctx, tenantID, filter)
+	if err != nil {
+		s.logger.Error("Failed to sum views by device", "error", err, "tenant_id", tenantID)
+		return nil, fmt.Errorf("failed to sum views by device: %w", err)
+	}
+	return newViewsBreakdown(filter, views), nil
+}
+
+func (s *analyticsService) GetGeographyBreakdown(ctx context.Context, tenantID string, filter models.BreakdownFilter) (*ViewsBreakdown, error) {
+	views, err := s.statsRepo.SumViewsByCountry(ctx, tenantID, filter)
+	if err != nil {
+		s.logger.Error("Failed to sum views by country", "error", err, "tenant_id", tenantID)
+		return nil, fmt.Errorf("failed to sum views by country: %w", err)
+	}
+	return newViewsBreakdown(filter, views), nil
+}
+
+// newViewsBreakdown totals the summed views and sets each one's share
+func newViewsBreakdown(filter models.BreakdownFilter, views []*models.BreakdownViews) *ViewsBreakdown {
+	breakdown := &ViewsBreakdown{
+		Period:    fmt.Sprintf("%s to %s", filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02")),
+		Platform:  filter.Platform,
+		Breakdown: views,
+	}
+	if breakdown.Breakdown == nil {
+		breakdown.Breakdown = []*models.BreakdownViews{}
+	}
+	for _, v := range views {
+		breakdown.Views += v.Views
+	}
+	if breakdown.Views > 0 {
+		for _, v := range views {
+			v.Share = float64(v.Views) / float64(breakdown.Views)
+		}
+	}
+	return breakdown
+}
+
+// platformTotals returns the tenant's stats summed per platform, with the
+// vertical its benchmarks are picked for
+func (s *analyticsService) platformTotals(ctx context.Context, tenantID string) ([]*models.PlatformTotals, string, error) {
+	tenant, err := s.tenants.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get tenant: %w", err)
+	}
+	totals, err := s.statsRepo.GetPlatformTotals(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to sum platform stats", "error", err, "tenant_id", tenantID)
+		return nil, "", fmt.Errorf("failed to sum platform stats: %w", err)
+	}
+	return totals, tenant.Vertical, nil
 }
 
 // Helper functions
@@ -337,9 +663,3 @@ func generateMockTopContent(videos []*models.Video) []*ContentEngagement {
 	}
 	return content
 }
-
-// generateStatsID generates a unique ID for stats entities
-// TODO: Replace with proper UUID generation
-func generateStatsID() string {
-	return fmt.Sprintf("stats_%d", time.Now().UnixNano())
-}
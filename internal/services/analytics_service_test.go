@@ -0,0 +1,473 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// batchVideoRepo serves GetByIDs from memory and counts lookups
+type batchVideoRepo struct {
+	models.VideoRepository
+	videos  map[string]*models.Video
+	batches int
+	singles int
+}
+
+func (r *batchVideoRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Video, error) {
+	r.singles++
+	if v, ok := r.videos[id]; ok {
+		return v, nil
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+func (r *batchVideoRepo) List(ctx context.Context, tenantID string, limit, offset int) ([]*models.Video, error) {
+	var videos []*models.Video
+	for _, v := range r.videos {
+		videos = append(videos, v)
+	}
+	return videos, nil
+}
+
+func (r *batchVideoRepo) GetByIDs(ctx context.Context, tenantID string, ids []string) ([]*models.Video, error) {
+	r.batches++
+	var videos []*models.Video
+	for _, id := range ids {
+		if v, ok := r.videos[id]; ok {
+			videos = append(videos, v)
+		}
+	}
+	return videos, nil
+}
+
+// batchStatsRepo serves aggregations from memory and counts queries
+type batchStatsRepo struct {
+	models.VideoStatsRepository
+	aggs    map[string]*models.StatsAggregation
+	err     error
+	batches int
+}
+
+func (r *batchStatsRepo) GetAggregatedStatsForVideos(ctx context.Context, tenantID string, videoIDs []string) ([]*models.StatsAggregation, error) {
+	r.batches++
+	if r.err != nil {
+		return nil, r.err
+	}
+	var aggs []*models.StatsAggregation
+	for _, id := range videoIDs {
+		if agg, ok := r.aggs[id]; ok {
+			aggs = append(aggs, agg)
+		}
+	}
+	return aggs, nil
+}
+
+func TestAnalyticsService_GetVideosStatsBatchesQueries(t *testing.T) {
+	videos := &batchVideoRepo{videos: map[string]*models.Video{
+		"video-1": {ID: "video-1", TenantID: "tenant-1"},
+		"video-2": {ID: "video-2", TenantID: "tenant-1"},
+		"video-3": {ID: "video-3", TenantID: "tenant-1"},
+		"private": {ID: "private", TenantID: "tenant-1", UserID: "other", Visibility: models.VisibilityOwner},
+	}}
+	stats := &batchStatsRepo{aggs: map[string]*models.StatsAggregation{
+		"video-1": {VideoID: "video-1", TotalViews: 1000, TotalLikes: 80, TotalComments: 10, TotalShares: 10, TotalRevenue: 12.5},
+		"video-3": {VideoID: "video-3", TotalViews: 50},
+	}}
+	svc := NewAnalyticsService(videos, &visibilityWorkspaceRepo{}, stats, nil, nil, logger.New("error", "test"))
+	viewer := &models.User{ID: "editor", TenantID: "tenant-1"}
+
+	result, err := svc.GetVideosStats(context.Background(), viewer, []string{"video-3", "missing", "private", "video-1", "video-2"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, videos.batches)
+	assert.Equal(t, 0, videos.singles, "videos are not looked up one by one")
+	assert.Equal(t, 1, stats.batches)
+
+	require.Len(t, result, 3, "unknown videos and those the viewer does not see are skipped")
+	assert.Equal(t, "video-3", result[0].VideoID, "request order is kept")
+	assert.Equal(t, int64(50), result[0].Views)
+	assert.Equal(t, "video-1", result[1].VideoID)
+	assert.Equal(t, int64(1000), result[1].Views)
+	assert.InDelta(t, 0.1, result[1].Engagement, 1e-9)
+	assert.Equal(t, "video-2", result[2].VideoID)
+	assert.Zero(t, result[2].Views, "videos without stats report zeros")
+	assert.Equal(t, "aggregate", result[2].Platform)
+}
+
+func TestAnalyticsService_GetVideosStatsAggregationError(t *testing.T) {
+	videos := &batchVideoRepo{videos: map[string]*models.Video{"video-1": {ID: "video-1", TenantID: "tenant-1"}}}
+	stats := &batchStatsRepo{err: errors.New("connection reset")}
+	svc := NewAnalyticsService(videos, nil, stats, nil, nil, logger.New("error", "test"))
+
+	admin := &models.User{ID: "admin", TenantID: "tenant-1", Role: string(models.RoleAdmin)}
+
+	_, err := svc.GetVideosStats(context.Background(), admin, []string{"video-1"})
+	assert.ErrorContains(t, err, "connection reset")
+
+	result, err := svc.GetVideosStats(context.Background(), admin, nil)
+	require.NoError(t, err)
+	assert.Empty(t, result)
+	assert.Equal(t, 1, videos.batches, "an empty page issues no query")
+}
+
+// scopedStatsRepo records the scope stats rows are listed within
+type scopedStatsRepo struct {
+	models.VideoStatsRepository
+	scopes []*models.VideoScope
+}
+
+func (r *scopedStatsRepo) ListScored(ctx context.Context, tenantID string, scope *models.VideoScope, filter models.StatsListFilter, limit, offset int) ([]*models.VideoStats, int64, error) {
+	r.scopes = append(r.scopes, scope)
+	return nil, 0, nil
+}
+
+func (r *scopedStatsRepo) Stream(ctx context.Context, tenantID string, scope *models.VideoScope, platform string, fn func(*models.VideoStats) error) error {
+	r.scopes = append(r.scopes, scope)
+	return nil
+}
+
+func TestAnalyticsService_StatsListsKeepToVisibleVideos(t *testing.T) {
+	stats := &scopedStatsRepo{}
+	workspaces := &visibilityWorkspaceRepo{workspaces: []*models.Workspace{{ID: "ws-1", TenantID: "tenant-1", UserID: "editor"}}}
+	svc := NewAnalyticsService(&batchVideoRepo{}, workspaces, stats, nil, nil, logger.New("error", "test"))
+	ctx := context.Background()
+	editor := &models.User{ID: "editor", TenantID: "tenant-1"}
+	admin := &models.User{ID: "admin", TenantID: "tenant-1", Role: string(models.RoleAdmin)}
+	noop := func(*models.VideoStats) error { return nil }
+
+	_, _, err := svc.ListVideoStats(ctx, editor, models.StatsListFilter{}, 20, 0)
+	require.NoError(t, err)
+	require.NoError(t, svc.ExportVideoStats(ctx, editor, "", noop))
+	_, _, err = svc.ListVideoStats(ctx, admin, models.StatsListFilter{}, 20, 0)
+	require.NoError(t, err)
+	require.NoError(t, svc.ExportVideoStats(ctx, admin, "", noop))
+
+	scope := &models.VideoScope{UserID: "editor", WorkspaceIDs: []string{"ws-1"}}
+	assert.Equal(t, []*models.VideoScope{scope, scope, nil, nil}, stats.scopes, "admins see every video")
+}
+
+// leaderboardRepo serves summaries and records rebuilds
+type leaderboardRepo struct {
+	models.VideoStatsRepository
+	summaries  []*models.VideoStatsSummary
+	rebuilt    []*models.VideoStatsSummary
+	rebuildErr error
+	rebuilds   int
+}
+
+func (r *leaderboardRepo) GetTopPerforming(ctx context.Context, tenantID, metric string, limit int) ([]*models.VideoStatsSummary, error) {
+	if len(r.summaries) > limit {
+		return r.summaries[:limit], nil
+	}
+	return r.summaries, nil
+}
+
+func (r *leaderboardRepo) RefreshSummaries(ctx context.Context, tenantID string, videoIDs []string) error {
+	r.rebuilds++
+	if r.rebuildErr != nil {
+		return r.rebuildErr
+	}
+	r.summaries = r.rebuilt
+	return nil
+}
+
+func TestAnalyticsService_GetTopPerforming(t *testing.T) {
+	fresh := time.Now().Add(-time.Minute)
+	old := time.Now().Add(-2 * leaderboardMaxAge)
+	summaries := func(refreshed ...time.Time) []*models.VideoStatsSummary {
+		var out []*models.VideoStatsSummary
+		for i, at := range refreshed {
+			out = append(out, &models.VideoStatsSummary{ID: fmt.Sprintf("video-%d", i), Views: int64(100 - i), RefreshedAt: at})
+		}
+		return out
+	}
+
+	tests := []struct {
+		name         string
+		repo         *leaderboardRepo
+		wantRebuilds int
+		wantEntries  int
+		wantStale    bool
+		wantErr      bool
+	}{
+		{name: "fresh summaries", repo: &leaderboardRepo{summaries: summaries(fresh, fresh)}, wantEntries: 2},
+		{name: "empty summaries are built", repo: &leaderboardRepo{rebuilt: summaries(fresh)}, wantRebuilds: 1, wantEntries: 1},
+		{name: "old summaries are rebuilt", repo: &leaderboardRepo{summaries: summaries(fresh, old), rebuilt: summaries(fresh, fresh)}, wantRebuilds: 1, wantEntries: 2},
+		{name: "failed rebuild serves stale entries", repo: &leaderboardRepo{summaries: summaries(old), rebuildErr: errors.New("lock wait timeout")}, wantRebuilds: 1, wantEntries: 1, wantStale: true},
+		{name: "failed first build", repo: &leaderboardRepo{rebuildErr: errors.New("lock wait timeout")}, wantRebuilds: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewAnalyticsService(&batchVideoRepo{}, nil, tt.repo, nil, nil, logger.New("error", "test"))
+
+			board, err := svc.GetTopPerforming(context.Background(), "tenant-1", "views", 0)
+			assert.Equal(t, tt.wantRebuilds, tt.repo.rebuilds)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "views", board.Metric)
+			assert.Len(t, board.Entries, tt.wantEntries)
+			assert.Equal(t, tt.wantStale, board.Stale)
+			assert.Equal(t, board.Entries[len(board.Entries)-1].RefreshedAt, board.RefreshedAt, "the oldest refresh is reported")
+		})
+	}
+}
+
+func TestAnalyticsService_GetTopPerformingRejectsUnknownMetric(t *testing.T) {
+	repo := &leaderboardRepo{}
+	svc := NewAnalyticsService(&batchVideoRepo{}, nil, repo, nil, nil, logger.New("error", "test"))
+
+	_, err := svc.GetTopPerforming(context.Background(), "tenant-1", "dislikes", 10)
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	assert.Zero(t, repo.rebuilds)
+}
+
+// historyRepo records the range history is read for
+type historyRepo struct {
+	models.VideoStatsRepository
+	from, to time.Time
+}
+
+func (r *historyRepo) GetHistory(ctx context.Context, tenantID, videoID string, from, to time.Time) ([]*models.VideoStatsSnapshot, error) {
+	r.from, r.to = from, to
+	return []*models.VideoStatsSnapshot{{StatsID: "stats-1", Views: 10, CreatedAt: from}}, nil
+}
+
+func TestAnalyticsService_GetVideoStatsHistory(t *testing.T) {
+	videos := &batchVideoRepo{videos: map[string]*models.Video{"video-1": {ID: "video-1", TenantID: "tenant-1"}}}
+	stats := &historyRepo{}
+	svc := NewAnalyticsService(videos, nil, stats, nil, nil, logger.New("error", "test"))
+	ctx := context.Background()
+	viewer := &models.User{ID: "user-1", TenantID: "tenant-1"}
+	to := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	history, err := svc.GetVideoStatsHistory(ctx, viewer, "video-1", to.AddDate(0, 0, -30), to)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.Equal(t, to.AddDate(0, 0, -30), stats.from)
+
+	_, err = svc.GetVideoStatsHistory(ctx, viewer, "video-1", to.AddDate(-5, 0, 0), to)
+	require.NoError(t, err)
+	assert.Equal(t, to.AddDate(0, 0, -maxStatsHistoryDays), stats.from, "long ranges are cut to the most recent days")
+
+	_, err = svc.GetVideoStatsHistory(ctx, viewer, "video-1", to, to)
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	_, err = svc.GetVideoStatsHistory(ctx, viewer, "missing", to.AddDate(0, 0, -1), to)
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+}
+
+func TestBucketDaily(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	at := func(day, hour int) time.Time { return time.Date(2026, 10, day, hour, 0, 0, 0, time.UTC) }
+	history := []*models.VideoStatsSnapshot{
+		{StatsID: "youtube", Views: 100, CreatedAt: at(10, 12)},
+		{StatsID: "tiktok", Views: 50, CreatedAt: at(10, 21)},
+		{StatsID: "youtube", Views: 180, CreatedAt: at(10, 23)}, // Past midnight in Paris
+		{StatsID: "youtube", Views: 300, CreatedAt: at(13, 9)},
+	}
+
+	utc := bucketDaily(history, time.UTC)
+	require.Len(t, utc, 4)
+	assert.Equal(t, "2026-10-10", utc[0].Date)
+	assert.Equal(t, int64(230), utc[0].Views)
+	assert.Equal(t, int64(230), utc[2].Views, "days without a snapshot carry the totals")
+	assert.Equal(t, int64(350), utc[3].Views)
+
+	local := bucketDaily(history, paris)
+	require.Len(t, local, 4)
+	assert.Equal(t, int64(150), local[0].Views)
+	assert.Equal(t, "2026-10-11", local[1].Date)
+	assert.Equal(t, int64(230), local[1].Views)
+
+	assert.Empty(t, bucketDaily(nil, paris))
+}
+
+// platformTotalsRepo serves fixed per-platform totals
+type platformTotalsRepo struct {
+	models.VideoStatsRepository
+	totals []*models.PlatformTotals
+}
+
+func (r *platformTotalsRepo) GetPlatformTotals(ctx context.Context, tenantID string) ([]*models.PlatformTotals, error) {
+	return r.totals, nil
+}
+
+func TestAnalyticsService_EngagementComparedWithBenchmarks(t *testing.T) {
+	benchmarks, err := NewBenchmarks("youtube=0.04;tiktok/music=0.07")
+	require.NoError(t, err)
+	stats := &platformTotalsRepo{totals: []*models.PlatformTotals{
+		{Platform: "tiktok", TotalVideos: 2, TotalViews: 10000, TotalLikes: 500, TotalComments: 100, TotalShares: 100},
+		{Platform: "youtube", TotalVideos: 3, TotalViews: 30000, TotalLikes: 900, TotalComments: 150, TotalShares: 150},
+	}}
+	tenants := &tenantList{tenants: []*models.Tenant{{ID: "tenant-1", Vertical: "music"}}}
+	svc := NewAnalyticsService(&batchVideoRepo{videos: map[string]*models.Video{}}, nil, stats, tenants, benchmarks, logger.New("error", "test"))
+	ctx := context.Background()
+	to := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	analytics, err := svc.GetEngagementAnalytics(ctx, "tenant-1", to.AddDate(0, 0, -7), to)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1900), analytics.TotalEngagement)
+	assert.InDelta(t, 0.0475, analytics.EngagementRate, 1e-9)
+
+	tiktok := analytics.EngagementByPlatform["tiktok"]
+	assert.InDelta(t, 0.07, tiktok.EngagementRate, 1e-9)
+	assert.Equal(t, &BenchmarkComparison{Vertical: "music", EngagementRate: 0.07, Difference: 0, Index: 100}, tiktok.Benchmark)
+	assert.Equal(t, &BenchmarkComparison{EngagementRate: 0.04, Difference: 0, Index: 100}, analytics.EngagementByPlatform["youtube"].Benchmark,
+		"a configured platform benchmark applies to every vertical without its own")
+	// (0.07*10000 + 0.04*30000) / 40000
+	assert.Equal(t, &BenchmarkComparison{Vertical: "music", EngagementRate: 0.0475, Difference: 0, Index: 100}, analytics.Benchmark)
+
+	performance, err := svc.GetPerformanceStats(ctx, "tenant-1", to.AddDate(0, 0, -7), to)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), performance.PlatformMetrics["youtube"].TotalVideos)
+	assert.Equal(t, tiktok.Benchmark, performance.PlatformMetrics["tiktok"].Benchmark)
+
+	_, err = svc.GetEngagementAnalytics(ctx, "tenant-2", to, to)
+	assert.ErrorIs(t, err, models.ErrTenantNotFound)
+}
+
+// videoRowsRepo serves a video's stats rows and their sum
+type videoRowsRepo struct {
+	models.VideoStatsRepository
+	rows []*models.VideoStats
+}
+
+func (r *videoRowsRepo) GetAggregatedStats(ctx context.Context, tenantID, videoID string) (*models.StatsAggregation, error) {
+	agg := &models.StatsAggregation{VideoID: videoID}
+	for _, row := range r.rows {
+		agg.TotalViews += row.Views
+	}
+	return agg, nil
+}
+
+func (r *videoRowsRepo) GetByVideoID(ctx context.Context, tenantID, videoID string) ([]*models.VideoStats, error) {
+	return r.rows, nil
+}
+
+func TestAnalyticsService_GetVideoStatsCombinesDemographics(t *testing.T) {
+	videos := &batchVideoRepo{videos: map[string]*models.Video{"video-1": {ID: "video-1", TenantID: "tenant-1"}}}
+	stats := &videoRowsRepo{rows: []*models.VideoStats{
+		{Platform: "youtube", Views: 3000, Demographics: &models.Demographics{
+			Gender:    map[string]float64{models.GenderFemale: 0.4, models.GenderMale: 0.6},
+			Countries: map[string]float64{"US": 1},
+		}, TrafficSources: models.TrafficSources{models.TrafficSearch: 1}},
+		{Platform: "tiktok", Views: 1000, Demographics: &models.Demographics{
+			Age:    map[string]float64{"18-24": 0.8},
+			Gender: map[string]float64{models.GenderFemale: 0.8, models.GenderMale: 0.2},
+		}},
+		{Platform: "twitter", Views: 6000},
+	}}
+	svc := NewAnalyticsService(videos, nil, stats, nil, nil, logger.New("error", "test"))
+	viewer := &models.User{ID: "user-1", TenantID: "tenant-1"}
+
+	result, err := svc.GetVideoStats(context.Background(), viewer, "video-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10000), result.Views)
+	require.NotNil(t, result.Demographics)
+	// Weighted by the 4000 views of the platforms reporting an audience
+	assert.InDeltaMapValues(t, map[string]float64{models.GenderFemale: 0.5, models.GenderMale: 0.5}, result.Demographics.Gender, 1e-9)
+	assert.InDeltaMapValues(t, map[string]float64{"18-24": 0.2}, result.Demographics.Age, 1e-9)
+	assert.InDeltaMapValues(t, map[string]float64{"US": 0.75}, result.Demographics.Countries, 1e-9)
+	assert.Equal(t, models.TrafficSources{models.TrafficSearch: 1}, result.TrafficSources)
+
+	stats.rows = stats.rows[2:]
+	result, err = svc.GetVideoStats(context.Background(), viewer, "video-1")
+	require.NoError(t, err)
+	assert.Nil(t, result.Demographics)
+	assert.Nil(t, result.TrafficSources)
+}
+
+// trafficRowsRepo serves the stats rows reporting traffic sources
+type trafficRowsRepo struct {
+	models.VideoStatsRepository
+	rows []*models.VideoStats
+}
+
+func (r *trafficRowsRepo) ListTrafficSources(ctx context.Context, tenantID string, from, to time.Time) ([]*models.VideoStats, error) {
+	return r.rows, nil
+}
+
+func TestAnalyticsService_GetTrafficSources(t *testing.T) {
+	stats := &trafficRowsRepo{rows: []*models.VideoStats{
+		{VideoID: "video-1", Platform: "youtube", Views: 3000, TrafficSources: models.TrafficSources{models.TrafficSearch: 0.5, models.TrafficSuggested: 0.5}},
+		{VideoID: "video-1", Platform: "tiktok", Views: 1000, TrafficSources: models.TrafficSources{models.TrafficSuggested: 1}},
+		{VideoID: "video-2", Platform: "youtube", Views: 1000, TrafficSources: models.TrafficSources{models.TrafficExternal: 1}},
+	}}
+	svc := NewAnalyticsService(nil, nil, stats, nil, nil, logger.New("error", "test"))
+	to := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	analytics, err := svc.GetTrafficSources(context.Background(), "tenant-1", to.AddDate(0, 0, -30), to)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-09-15 to 2026-10-15", analytics.Period)
+	assert.Equal(t, int64(5000), analytics.Overall.Views)
+	assert.Equal(t, map[string]int64{models.TrafficSearch: 1500, models.TrafficSuggested: 2500, models.TrafficExternal: 1000}, analytics.Overall.Sources)
+	assert.InDeltaMapValues(t, models.TrafficSources{models.TrafficSearch: 0.3, models.TrafficSuggested: 0.5, models.TrafficExternal: 0.2}, analytics.Overall.Shares, 1e-9)
+
+	youtube := analytics.ByPlatform["youtube"]
+	assert.Equal(t, int64(4000), youtube.Views)
+	assert.InDelta(t, 0.25, youtube.Shares[models.TrafficExternal], 1e-9)
+
+	require.Len(t, analytics.TopVideos, 2)
+	assert.Equal(t, "video-1", analytics.TopVideos[0].VideoID)
+	assert.InDeltaMapValues(t, models.TrafficSources{models.TrafficSearch: 0.375, models.TrafficSuggested: 0.625}, analytics.TopVideos[0].Shares, 1e-9)
+
+	stats.rows = nil
+	analytics, err = svc.GetTrafficSources(context.Background(), "tenant-1", to.AddDate(0, 0, -30), to)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), analytics.Overall.Views)
+	assert.Empty(t, analytics.TopVideos)
+}
+
+// breakdownRepo serves summed views by device and country, recording the
+// filter it was asked for
+type breakdownRepo struct {
+	models.VideoStatsRepository
+	devices, countries []*models.BreakdownViews
+	filter             models.BreakdownFilter
+}
+
+func (r *breakdownRepo) SumViewsByDevice(ctx context.Context, tenantID string, filter models.BreakdownFilter) ([]*models.BreakdownViews, error) {
+	r.filter = filter
+	return r.devices, nil
+}
+
+func (r *breakdownRepo) SumViewsByCountry(ctx context.Context, tenantID string, filter models.BreakdownFilter) ([]*models.BreakdownViews, error) {
+	r.filter = filter
+	return r.countries, nil
+}
+
+func TestAnalyticsService_GetBreakdowns(t *testing.T) {
+	stats := &breakdownRepo{
+		devices: []*models.BreakdownViews{{Key: models.DeviceMobile, Views: 750}, {Key: models.DeviceDesktop, Views: 250}},
+	}
+	svc := NewAnalyticsService(nil, nil, stats, nil, nil, logger.New("error", "test"))
+	to := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	filter := models.BreakdownFilter{Platform: "youtube", From: to.AddDate(0, 0, -30), To: to}
+
+	devices, err := svc.GetDeviceBreakdown(context.Background(), "tenant-1", filter)
+	require.NoError(t, err)
+	assert.Equal(t, filter, stats.filter)
+	assert.Equal(t, "2026-09-15 to 2026-10-15", devices.Period)
+	assert.Equal(t, "youtube", devices.Platform)
+	assert.Equal(t, int64(1000), devices.Views)
+	require.Len(t, devices.Breakdown, 2)
+	assert.InDelta(t, 0.75, devices.Breakdown[0].Share, 1e-9)
+
+	countries, err := svc.GetGeographyBreakdown(context.Background(), "tenant-1", filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), countries.Views)
+	assert.NotNil(t, countries.Breakdown, "an empty breakdown is a list")
+}
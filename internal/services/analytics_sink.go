@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clickhouse"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// Batching of the rows written to the sink
+const (
+	sinkBatchSize     = 1000
+	sinkFlushInterval = 5 * time.Second
+)
+
+// sinkSchema is the sink's copy of video_stats: the latest row of each video
+// on each platform wins when ClickHouse merges, and FINAL reads only it
+const sinkSchema = `CREATE TABLE IF NOT EXISTS video_stats (
+	tenant_id String,
+	video_id String,
+	platform LowCardinality(String),
+	views Int64,
+	likes Int64,
+	comments Int64,
+	shares Int64,
+	watch_time Int64,
+	impressions Int64,
+	revenue Float64,
+	synced_at DateTime64(3)
+) ENGINE = ReplacingMergeTree(synced_at)
+ORDER BY (tenant_id, platform, video_id)`
+
+// sinkDimensions are the dimensions the sink holds; queries using another
+// one, which needs the videos table, stay on MySQL
+var sinkDimensions = map[models.AnalyticsDimension]string{
+	models.AnalyticsPlatform: "platform",
+	models.AnalyticsVideo:    "video_id",
+}
+
+// sinkMeasures are the aggregates of the sink, matching those of MySQL
+var sinkMeasures = map[models.AnalyticsMeasure]string{
+	models.AnalyticsViews:       "sum(views)",
+	models.AnalyticsLikes:       "sum(likes)",
+	models.AnalyticsComments:    "sum(comments)",
+	models.AnalyticsShares:      "sum(shares)",
+	models.AnalyticsWatchTime:   "sum(watch_time)",
+	models.AnalyticsImpressions: "sum(impressions)",
+	models.AnalyticsRevenue:     "sum(revenue)",
+	models.AnalyticsVideos:      "uniqExact(video_id)",
+	models.AnalyticsEngagement:  "if(sum(views) = 0, 0, sum(likes + comments + shares) * 100 / sum(views))",
+}
+
+// sinkRow is a stats row as the sink stores it
+type sinkRow struct {
+	TenantID    string  `json:"tenant_id"`
+	VideoID     string  `json:"video_id"`
+	Platform    string  `json:"platform"`
+	Views       int64   `json:"views"`
+	Likes       int64   `json:"likes"`
+	Comments    int64   `json:"comments"`
+	Shares      int64   `json:"shares"`
+	WatchTime   int64   `json:"watch_time"`
+	Impressions int64   `json:"impressions"`
+	Revenue     float64 `json:"revenue"`
+	SyncedAt    string  `json:"synced_at"`
+}
+
+// analyticsSink implements the AnalyticsSink interface over ClickHouse.
+// Rows wait in a bounded in-memory queue, like webhooks, so stats syncs never
+// wait for the sink.
+type analyticsSink struct {
+	client     *clickhouse.Client
+	tenants    map[string]bool
+	allTenants bool
+	queue      chan *sinkRow
+	logger     *logger.Logger
+
+	mu      sync.RWMutex
+	stopped bool
+}
+
+var _ AnalyticsSink = (*analyticsSink)(nil)
+
+// NewAnalyticsSink creates a sink answering the queries of the tenants, or of
+// every tenant when they include "*", and holding up to queueSize rows; Run
+// must be called once to start writing them
+func NewAnalyticsSink(client *clickhouse.Client, tenants []string, queueSize int, logger *logger.Logger) AnalyticsSink {
+	s := &analyticsSink{
+		client:  client,
+		tenants: make(map[string]bool, len(tenants)),
+		queue:   make(chan *sinkRow, queueSize),
+		logger:  logger,
+	}
+	for _, tenant := range tenants {
+		if tenant == "*" {
+			s.allTenants = true
+		}
+		s.tenants[tenant] = true
+	}
+	return s
+}
+
+// Record queues the rows of every tenant, so a tenant's sink is already
+// filled when its queries are routed there
+func (s *analyticsSink) Record(tenantID string, stats []*models.VideoStats) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.stopped {
+		return
+	}
+
+	for _, st := range stats {
+		row := &sinkRow{
+			TenantID:    tenantID,
+			VideoID:     st.VideoID,
+			Platform:    st.Platform,
+			Views:       st.Views,
+			Likes:       st.Likes,
+			Comments:    st.Comments,
+			Shares:      st.Shares,
+			WatchTime:   st.WatchTime,
+			Impressions: st.Impressions,
+			Revenue:     st.Revenue,
+			SyncedAt:    st.LastSyncAt.UTC().Format("2006-01-02 15:04:05.000"),
+		}
+		select {
+		case s.queue <- row:
+		default:
+			s.logger.Warn("Analytics sink queue full, dropping stats rows", "tenant_id", tenantID, "rows", len(stats))
+			return
+		}
+	}
+}
+
+func (s *analyticsSink) Routes(tenantID string, query *models.AnalyticsQuery) bool {
+	if !s.allTenants && !s.tenants[tenantID] {
+		return false
+	}
+	for _, dimension := range query.Dimensions {
+		if _, ok := sinkDimensions[dimension]; !ok {
+			return false
+		}
+	}
+	for _, filter := range query.Filters {
+		if _, ok := sinkDimensions[filter.Dimension]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Query builds the statement from the whitelisted expressions only; the
+// tenant and the values of filters are parameters
+func (s *analyticsSink) Query(ctx context.Context, tenantID string, q *models.AnalyticsQuery) (*models.AnalyticsResult, error) {
+	// Columns are aliased apart from the stored columns, which aggregates
+	// of other measures still read
+	aliases := make(map[string]string, len(q.Dimensions)+len(q.Measures))
+	selects := make([]string, 0, len(q.Dimensions)+len(q.Measures))
+	groups := make([]string, 0, len(q.Dimensions))
+	for i, dimension := range q.Dimensions {
+		alias := fmt.Sprintf("d%d", i)
+		aliases[string(dimension)] = alias
+		selects = append(selects, fmt.Sprintf("%s AS %s", sinkDimensions[dimension], alias))
+		groups = append(groups, alias)
+	}
+	for i, measure := range q.Measures {
+		alias := fmt.Sprintf("m%d", i)
+		aliases[string(measure)] = alias
+		selects = append(selects, fmt.Sprintf("%s AS %s", sinkMeasures[measure], alias))
+	}
+
+	params := map[string]string{"tenant": tenantID}
+	where := []string{"tenant_id = {tenant:String}"}
+	for i, filter := range q.Filters {
+		operator := "IN"
+		if filter.Operator == "not_in" {
+			operator = "NOT IN"
+		}
+		name := fmt.Sprintf("f%d", i)
+		params[name] = clickhouse.Array(filter.Values)
+		where = append(where, fmt.Sprintf("%s %s {%s:Array(String)}", sinkDimensions[filter.Dimension], operator, name))
+	}
+
+	statement := fmt.Sprintf("SELECT %s FROM video_stats FINAL WHERE %s", strings.Join(selects, ", "), strings.Join(where, " AND "))
+	if len(groups) > 0 {
+		statement += " GROUP BY " + strings.Join(groups, ", ")
+	}
+	if len(q.OrderBy) > 0 {
+		orders := make([]string, len(q.OrderBy))
+		for i, order := range q.OrderBy {
+			orders[i] = aliases[order.Field]
+			if order.Desc {
+				orders[i] += " DESC"
+			}
+		}
+		statement += " ORDER BY " + strings.Join(orders, ", ")
+	}
+	statement += fmt.Sprintf(" LIMIT %d", q.Limit)
+
+	answer, err := s.client.Query(ctx, statement, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.AnalyticsResult{Columns: q.Columns(), Rows: make([][]interface{}, 0, len(answer.Rows))}
+	for _, values := range answer.Rows {
+		if len(values) != len(result.Columns) {
+			return nil, fmt.Errorf("analytics sink returned %d columns, expected %d", len(values), len(result.Columns))
+		}
+		row := make([]interface{}, len(values))
+		copy(row, values[:len(q.Dimensions)])
+		for i, measure := range q.Measures {
+			number, _ := values[len(q.Dimensions)+i].(json.Number)
+			switch measure {
+			case models.AnalyticsRevenue, models.AnalyticsEngagement:
+				row[len(q.Dimensions)+i], _ = number.Float64()
+			default:
+				row[len(q.Dimensions)+i], _ = number.Int64()
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
+
+// Run writes the queued rows once sinkBatchSize are waiting or every
+// sinkFlushInterval, whichever comes first
+func (s *analyticsSink) Run(ctx context.Context) {
+	// Rows still queued at shutdown are written, not dropped
+	workCtx := context.WithoutCancel(ctx)
+
+	if err := s.client.Exec(workCtx, sinkSchema); err != nil {
+		s.logger.Error("Failed to create analytics sink table", "error", err)
+	}
+
+	ticker := time.NewTicker(sinkFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]interface{}, 0, sinkBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.client.Insert(workCtx, "video_stats", batch); err != nil {
+			s.logger.Error("Failed to write stats rows to analytics sink", "error", err, "rows", len(batch))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case row := <-s.queue:
+			batch = append(batch, row)
+			if len(batch) >= sinkBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.stopped = true
+			close(s.queue)
+			s.mu.Unlock()
+
+			s.logger.Info("Draining analytics sink queue", "rows", len(s.queue)+len(batch))
+			for row := range s.queue {
+				batch = append(batch, row)
+				if len(batch) >= sinkBatchSize {
+					flush()
+				}
+			}
+			flush()
+			return
+		}
+	}
+}
+
+// sinkTap records the stats rows written through a repository in the sink
+type sinkTap struct {
+	models.VideoStatsRepository
+	sink AnalyticsSink
+}
+
+// TapStats returns the repository, recording every stats row written to it
+// in the sink once written to MySQL
+func TapStats(repo models.VideoStatsRepository, sink AnalyticsSink) models.VideoStatsRepository {
+	return &sinkTap{VideoStatsRepository: repo, sink: sink}
+}
+
+func (t *sinkTap) Create(ctx context.Context, stats *models.VideoStats) error {
+	if err := t.VideoStatsRepository.Create(ctx, stats); err != nil {
+		return err
+	}
+	t.sink.Record(stats.TenantID, []*models.VideoStats{stats})
+	return nil
+}
+
+func (t *sinkTap) Update(ctx context.Context, stats *models.VideoStats) error {
+	if err := t.VideoStatsRepository.Update(ctx, stats); err != nil {
+		return err
+	}
+	t.sink.Record(stats.TenantID, []*models.VideoStats{stats})
+	return nil
+}
+
+func (t *sinkTap) UpsertBatch(ctx context.Context, tenantID string, stats []*models.VideoStats, batchSize int) error {
+	if err := t.VideoStatsRepository.UpsertBatch(ctx, tenantID, stats, batchSize); err != nil {
+		return err
+	}
+	t.sink.Record(tenantID, stats)
+	return nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clickhouse"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// fakeClickHouse records the statements and inserted rows it receives and
+// answers queries with a fixed result
+type fakeClickHouse struct {
+	mu         sync.Mutex
+	statements []string
+	params     map[string]string
+	inserted   []map[string]interface{}
+	fail       bool
+}
+
+func (f *fakeClickHouse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if strings.HasPrefix(r.URL.Query().Get("query"), "INSERT") {
+		dec := json.NewDecoder(r.Body)
+		for dec.More() {
+			var row map[string]interface{}
+			_ = dec.Decode(&row)
+			f.inserted = append(f.inserted, row)
+		}
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	f.statements = append(f.statements, string(body))
+	f.params = map[string]string{}
+	for name := range r.URL.Query() {
+		f.params[name] = r.URL.Query().Get(name)
+	}
+	_, _ = io.WriteString(w, `{"meta":[{"name":"d0"},{"name":"m0"},{"name":"m1"}],"data":[["youtube",1200,4.5]]}`)
+}
+
+func newTestSink(t *testing.T, fake *fakeClickHouse, tenants ...string) AnalyticsSink {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client, err := clickhouse.New(server.URL)
+	require.NoError(t, err)
+	return NewAnalyticsSink(client, tenants, 10, logger.New("error", "test"))
+}
+
+func TestAnalyticsSink_Query(t *testing.T) {
+	fake := &fakeClickHouse{}
+	sink := newTestSink(t, fake, "acme")
+
+	query := &models.AnalyticsQuery{
+		Dimensions: []models.AnalyticsDimension{models.AnalyticsPlatform},
+		Measures:   []models.AnalyticsMeasure{models.AnalyticsViews, models.AnalyticsEngagement},
+		Filters:    []models.AnalyticsFilter{{Dimension: models.AnalyticsPlatform, Operator: "not_in", Values: []string{"x'; DROP TABLE video_stats"}}},
+		OrderBy:    []models.AnalyticsOrder{{Field: "views", Desc: true}},
+		Limit:      50,
+	}
+	assert.True(t, sink.Routes("acme", query))
+	assert.False(t, sink.Routes("globex", query), "only the configured tenants")
+	assert.False(t, sink.Routes("acme", &models.AnalyticsQuery{
+		Dimensions: []models.AnalyticsDimension{models.AnalyticsCampaign},
+		Measures:   []models.AnalyticsMeasure{models.AnalyticsViews},
+	}), "campaigns are on the videos table, in MySQL only")
+
+	result, err := sink.Query(context.Background(), "acme", query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform", "views", "engagement_rate"}, result.Columns)
+	assert.Equal(t, [][]interface{}{{"youtube", int64(1200), 4.5}}, result.Rows)
+
+	assert.Equal(t, "SELECT platform AS d0, sum(views) AS m0, "+
+		"if(sum(views) = 0, 0, sum(likes + comments + shares) * 100 / sum(views)) AS m1 "+
+		"FROM video_stats FINAL WHERE tenant_id = {tenant:String} AND platform NOT IN {f0:Array(String)} "+
+		"GROUP BY d0 ORDER BY m0 DESC LIMIT 50 FORMAT JSONCompact", fake.statements[0])
+	assert.Equal(t, "acme", fake.params["param_tenant"])
+	assert.Equal(t, `['x\'; DROP TABLE video_stats']`, fake.params["param_f0"], "values are parameters")
+}
+
+func TestAnalyticsSink_RecordAndRun(t *testing.T) {
+	fake := &fakeClickHouse{}
+	sink := newTestSink(t, fake, "*")
+	stats := TapStats(&syncStatsRepo{}, sink)
+
+	syncedAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, stats.UpsertBatch(context.Background(), "acme", []*models.VideoStats{
+		{VideoID: "v1", Platform: "youtube", Views: 100, LastSyncAt: syncedAt},
+		{VideoID: "v2", Platform: "tiktok", Views: 50, LastSyncAt: syncedAt},
+	}, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sink.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	require.Len(t, fake.statements, 1)
+	assert.Contains(t, fake.statements[0], "CREATE TABLE IF NOT EXISTS video_stats")
+	require.Len(t, fake.inserted, 2, "queued rows are written on shutdown")
+	assert.Equal(t, "acme", fake.inserted[0]["tenant_id"])
+	assert.Equal(t, "2026-10-15 12:00:00.000", fake.inserted[0]["synced_at"])
+
+	sink.Record("acme", []*models.VideoStats{{VideoID: "v3"}})
+	assert.Len(t, fake.inserted, 2, "nothing is queued once stopped")
+}
+
+func TestAnalyticsReaderService_SinkFallback(t *testing.T) {
+	fake := &fakeClickHouse{fail: true}
+	sink := newTestSink(t, fake, "acme")
+	stats := &queriedStatsRepo{}
+	svc := NewAnalyticsReaderService(&memoryAnalyticsReaderRepo{}, &memoryTenantRepo{}, stats, sink,
+		clock.NewFake(time.Now()), logger.New("error", "test"))
+
+	_, err := svc.Query(context.Background(), "acme", &models.AnalyticsQuery{Measures: []models.AnalyticsMeasure{models.AnalyticsViews}})
+	require.NoError(t, err)
+	assert.Equal(t, "acme", stats.tenantID, "MySQL answers while the sink is down")
+
+	fake.fail = false
+	stats.tenantID = ""
+	result, err := svc.Query(context.Background(), "acme", &models.AnalyticsQuery{
+		Dimensions: []models.AnalyticsDimension{models.AnalyticsPlatform},
+		Measures:   []models.AnalyticsMeasure{models.AnalyticsViews, models.AnalyticsEngagement},
+	})
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, 1)
+	assert.Empty(t, stats.tenantID, "the sink answered")
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// approverPageSize is how many users are read at a time to find the approvers
+const approverPageSize = 100
+
+// approvalService implements the ApprovalService interface
+type approvalService struct {
+	jobs       models.PublicationJobRepository
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	tenants    models.TenantRepository
+	users      models.UserRepository
+	notify     NotificationService
+	audit      AuditService
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ ApprovalService = (*approvalService)(nil)
+
+// NewApprovalService creates a new approval service instance
+func NewApprovalService(jobs models.PublicationJobRepository, videos models.VideoRepository, workspaces models.WorkspaceRepository, tenants models.TenantRepository, users models.UserRepository, notify NotificationService, audit AuditService, clock clock.Clock, logger *logger.Logger) ApprovalService {
+	return &approvalService{
+		jobs:       jobs,
+		videos:     videos,
+		workspaces: workspaces,
+		tenants:    tenants,
+		users:      users,
+		notify:     notify,
+		audit:      audit,
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+// Publish creates the publication job of the video
+func (s *approvalService) Publish(ctx context.Context, user *models.User, videoID string, req *models.CreatePublicationJobRequest) (*models.PublicationJob, error) {
+	platform := models.Platform(req.Platform)
+	if !platform.Valid() {
+		return nil, fmt.Errorf("%w: %s", models.ErrInvalidPlatform, req.Platform)
+	}
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, user, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if video.IsArchived() {
+		return nil, i18n.Errorf(models.ErrVideoArchived, "restore it before publishing")
+	}
+	if video.Status != string(models.StatusReady) {
+		return nil, i18n.Errorf(models.ErrConflict, "the video is not ready for publishing")
+	}
+	now := s.clock.Now()
+	scheduledAt := now
+	if req.ScheduledAt != nil && req.ScheduledAt.After(now) {
+		scheduledAt = *req.ScheduledAt
+	}
+	if err := video.Rights.Allow(platform, scheduledAt); err != nil {
+		return nil, err
+	}
+	if req.WorkspaceID == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "a workspace_id is required")
+	}
+	ws, err := s.workspaces.GetByID(ctx, user.TenantID, req.WorkspaceID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "workspace %s not found", req.WorkspaceID)
+		}
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	policy, err := s.Policy(ctx, user.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	maxRetries := req.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+	job := &models.PublicationJob{
+		TenantID:    user.TenantID,
+		VideoID:     video.ID,
+		UserID:      user.ID,
+		WorkspaceID: req.WorkspaceID,
+		Platform:    string(platform),
+		Status:      string(models.PublicationScheduled),
+		Config:      req.Config,
+		MaxRetries:  maxRetries,
+		ScheduledAt: sql.NullTime{Time: scheduledAt, Valid: true},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := job.WatermarkOverride(); err != nil {
+		return nil, err
+	}
+	switch {
+	case policy.ApproverRole != "":
+		job.Status = string(models.PublicationAwaitingApproval)
+	case ws.ReconnectNeeded(platform):
+		// Queued behind the publications held until the workspace is reconnected
+		job.Status = string(models.PublicationAwaitingReconnect)
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create publication: %w", err)
+	}
+
+	s.logger.Info("Publication requested",
+		"tenant_id", job.TenantID,
+		"user_id", user.ID,
+		"video_id", job.VideoID,
+		"publication_id", job.ID,
+		"platform", job.Platform,
+		"status", job.Status)
+	if policy.ApproverRole != "" {
+		s.notifyApprovers(ctx, job, video, policy.ApproverRole)
+	}
+	return job, nil
+}
+
+// Approve schedules a publication awaiting approval, or releases it
+// where it stopped when a blackout window held it
+func (s *approvalService) Approve(ctx context.Context, approver *models.User, publicationID, comment string) (*models.PublicationJob, error) {
+	job, err := s.reviewable(ctx, approver, publicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if !job.ScheduledAt.Valid || job.ScheduledAt.Time.Before(now) {
+		job.ScheduledAt = sql.NullTime{Time: now, Valid: true}
+	}
+	status := models.PublicationScheduled
+	if job.StagedAt.Valid {
+		// Held by a blackout window after it was staged
+		status = models.PublicationStaged
+	}
+	if err := s.review(ctx, approver, job, status, comment); err != nil {
+		return nil, err
+	}
+	s.record(ctx, approver, job, models.AuditActionPublicationApprove, comment)
+	s.notifyRequester(ctx, job, i18n.M("Publication approved"),
+		i18n.M("Your publication of video %s on %s was approved and is scheduled for %s", job.VideoID, job.Platform, job.ScheduledAt.Time.UTC().Format("2006-01-02 15:04 MST")))
+	return job, nil
+}
+
+// Reject refuses a publication awaiting approval
+func (s *approvalService) Reject(ctx context.Context, approver *models.User, publicationID, comment string) (*models.PublicationJob, error) {
+	if comment == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "a comment is required to reject a publication")
+	}
+	job, err := s.reviewable(ctx, approver, publicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.review(ctx, approver, job, models.PublicationRejected, comment); err != nil {
+		return nil, err
+	}
+	s.record(ctx, approver, job, models.AuditActionPublicationReject, comment)
+	s.notifyRequester(ctx, job, i18n.M("Publication rejected"),
+		i18n.M("Your publication of video %s on %s was rejected: %s", job.VideoID, job.Platform, comment))
+	return job, nil
+}
+
+// AwaitingApproval lists the tenant's publications awaiting approval
+func (s *approvalService) AwaitingApproval(ctx context.Context, tenantID string, limit, offset int) ([]*models.PublicationJob, error) {
+	jobs, err := s.jobs.GetByStatus(ctx, tenantID, models.PublicationAwaitingApproval, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list publications awaiting approval: %w", err)
+	}
+	return jobs, nil
+}
+
+// Policy returns the tenant's approval policy; tenants without a row
+// publish without approval
+func (s *approvalService) Policy(ctx context.Context, tenantID string) (*models.ApprovalPolicy, error) {
+	tenant, err := s.tenants.GetByID(ctx, tenantID)
+	if errors.Is(err, models.ErrTenantNotFound) {
+		return &models.ApprovalPolicy{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return &models.ApprovalPolicy{ApproverRole: tenant.PublishApproverRole}, nil
+}
+
+// SetPolicy changes the tenant's approval policy. Publications already
+// awaiting approval still need it.
+func (s *approvalService) SetPolicy(ctx context.Context, tenantID string, policy *models.ApprovalPolicy) (*models.ApprovalPolicy, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	tenant, err := s.tenants.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	tenant.PublishApproverRole = policy.ApproverRole
+	if err := s.tenants.Update(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	s.logger.Info("Publication approval policy changed", "tenant_id", tenantID, "approver_role", policy.ApproverRole)
+	return &models.ApprovalPolicy{ApproverRole: tenant.PublishApproverRole}, nil
+}
+
+// reviewable loads a publication awaiting approval the approver may review
+func (s *approvalService) reviewable(ctx context.Context, approver *models.User, publicationID string) (*models.PublicationJob, error) {
+	job, err := s.jobs.GetByID(ctx, approver.TenantID, publicationID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != string(models.PublicationAwaitingApproval) {
+		return nil, i18n.Errorf(models.ErrConflict, "the publication is not awaiting approval")
+	}
+	if job.UserID == approver.ID {
+		return nil, i18n.Errorf(models.ErrForbidden, "publications are approved by someone other than who requested them")
+	}
+	policy, err := s.Policy(ctx, approver.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if models.UserRole(approver.Role) != models.RoleAdmin && (policy.ApproverRole == "" || approver.Role != policy.ApproverRole) {
+		return nil, i18n.Errorf(models.ErrForbidden, "only users with the approver role can review publications")
+	}
+	return job, nil
+}
+
+// review saves the approver's decision on the publication
+func (s *approvalService) review(ctx context.Context, approver *models.User, job *models.PublicationJob, status models.PublicationStatus, comment string) error {
+	now := s.clock.Now()
+	job.Status = string(status)
+	job.ReviewedBy = approver.ID
+	job.ReviewedAt = sql.NullTime{Time: now, Valid: true}
+	job.ReviewComment = comment
+	job.UpdatedAt = now
+	if err := s.jobs.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to update publication: %w", err)
+	}
+
+	s.logger.Info("Publication reviewed",
+		"tenant_id", job.TenantID,
+		"publication_id", job.ID,
+		"requested_by", job.UserID,
+		"reviewed_by", approver.ID,
+		"status", job.Status)
+	return nil
+}
+
+// record writes the decision to the video's audit trail. Failures are
+// logged: the decision has already been saved.
+func (s *approvalService) record(ctx context.Context, approver *models.User, job *models.PublicationJob, action, comment string) {
+	err := s.audit.Record(ctx, &models.AuditLog{
+		TenantID: job.TenantID,
+		UserID:   approver.ID,
+		Action:   action,
+		Resource: models.VideoPath(job.VideoID) + "/publications/" + job.ID,
+		Detail:   comment,
+	})
+	if err != nil {
+		s.logger.Error("Failed to record publication review in audit log", "error", err, "publication_id", job.ID, "action", action)
+	}
+}
+
+// notifyApprovers tells the users who can approve the publication that it
+// awaits them. Failures are logged, the request stands.
+func (s *approvalService) notifyApprovers(ctx context.Context, job *models.PublicationJob, video *models.Video, role string) {
+	subject := i18n.M("Publication awaiting approval")
+	message := i18n.M("%s is waiting for approval to be published on %s", video.Title, job.Platform)
+	for offset := 0; ; offset += approverPageSize {
+		users, err := s.users.List(ctx, job.TenantID, approverPageSize, offset)
+		if err != nil {
+			s.logger.Error("Failed to list approvers", "error", err, "tenant_id", job.TenantID, "publication_id", job.ID)
+			return
+		}
+		for _, user := range users {
+			if user.ID == job.UserID || !user.IsActive() || (user.Role != role && models.UserRole(user.Role) != models.RoleAdmin) {
+				continue
+			}
+			if err := s.notify.Notify(ctx, job.TenantID, user.ID, subject, message); err != nil {
+				s.logger.Error("Failed to notify approver", "error", err, "publication_id", job.ID, "user_id", user.ID)
+			}
+		}
+		if len(users) < approverPageSize {
+			return
+		}
+	}
+}
+
+// notifyRequester tells the user who requested the publication how it was
+// reviewed. Failures are logged, like those of the audit log.
+func (s *approvalService) notifyRequester(ctx context.Context, job *models.PublicationJob, subject, message i18n.Message) {
+	if err := s.notify.Notify(ctx, job.TenantID, job.UserID, subject, message); err != nil {
+		s.logger.Error("Failed to notify publication requester", "error", err, "publication_id", job.ID, "user_id", job.UserID)
+	}
+}
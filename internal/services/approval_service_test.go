@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// approvalJobRepo stores publication jobs in the order they were created
+type approvalJobRepo struct {
+	models.PublicationJobRepository
+	jobs []*models.PublicationJob
+}
+
+func (r *approvalJobRepo) Create(ctx context.Context, job *models.PublicationJob) error {
+	job.ID = fmt.Sprintf("pub-%d", len(r.jobs)+1)
+	r.jobs = append(r.jobs, job)
+	return nil
+}
+
+func (r *approvalJobRepo) GetByID(ctx context.Context, tenantID, id string) (*models.PublicationJob, error) {
+	for _, job := range r.jobs {
+		if job.ID == id && job.TenantID == tenantID {
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, models.ErrPublicationNotFound
+}
+
+func (r *approvalJobRepo) Update(ctx context.Context, job *models.PublicationJob) error {
+	for i, existing := range r.jobs {
+		if existing.ID == job.ID {
+			copied := *job
+			r.jobs[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (r *approvalJobRepo) GetByStatus(ctx context.Context, tenantID string, status models.PublicationStatus, limit, offset int) ([]*models.PublicationJob, error) {
+	var jobs []*models.PublicationJob
+	for _, job := range r.jobs {
+		if job.TenantID == tenantID && job.Status == string(status) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// approvalUserRepo lists the users of identityUserRepo by ID
+type approvalUserRepo struct {
+	identityUserRepo
+}
+
+func (r *approvalUserRepo) List(ctx context.Context, tenantID string, limit, offset int) ([]*models.User, error) {
+	var users []*models.User
+	for _, u := range r.users {
+		if u.TenantID == tenantID {
+			users = append(users, u)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	if offset >= len(users) {
+		return nil, nil
+	}
+	return users[offset:min(offset+limit, len(users))], nil
+}
+
+type approvalFixture struct {
+	jobs     *approvalJobRepo
+	tenants  *memoryTenantRepo
+	audit    *memoryAuditRepo
+	notified *recordingNotifications
+	clock    *clock.Fake
+	svc      ApprovalService
+}
+
+func newApprovalFixture(t *testing.T) *approvalFixture {
+	t.Helper()
+	now := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	f := &approvalFixture{
+		jobs: &approvalJobRepo{},
+		tenants: &memoryTenantRepo{tenants: map[string]*models.Tenant{
+			"acme": {ID: "acme", Status: "active"},
+		}},
+		audit:    &memoryAuditRepo{},
+		notified: &recordingNotifications{subjects: map[string][]string{}},
+		clock:    clock.NewFake(now),
+	}
+	videos := &visibilityVideoRepo{videos: map[string]*models.Video{
+		"video-1": {ID: "video-1", TenantID: "acme", Title: "Launch", Status: string(models.StatusReady)},
+		"video-2": {ID: "video-2", TenantID: "acme", Title: "Draft", Status: string(models.StatusProcessing)},
+		"private": {ID: "private", TenantID: "acme", UserID: "publisher", Title: "Teaser", Status: string(models.StatusReady), Visibility: models.VisibilityOwner},
+	}}
+	workspaces := &visibilityWorkspaceRepo{workspaces: []*models.Workspace{{ID: "ws-1", TenantID: "acme"}}}
+	users := &approvalUserRepo{identityUserRepo{users: map[string]*models.User{
+		"editor":    {ID: "editor", TenantID: "acme", Role: "editor", Status: string(models.StatusActive)},
+		"publisher": {ID: "publisher", TenantID: "acme", Role: "publisher", Status: string(models.StatusActive)},
+		"away":      {ID: "away", TenantID: "acme", Role: "publisher", Status: string(models.StatusSuspended)},
+		"admin":     {ID: "admin", TenantID: "acme", Role: "admin", Status: string(models.StatusActive)},
+		"viewer":    {ID: "viewer", TenantID: "acme", Role: "viewer", Status: string(models.StatusActive)},
+	}}}
+	audit := NewAuditService(f.audit, logger.New("error", "test"))
+	f.svc = NewApprovalService(f.jobs, videos, workspaces, f.tenants, users, f.notified, audit, f.clock, logger.New("error", "test"))
+	return f
+}
+
+func TestApprovalService_WithoutPolicy(t *testing.T) {
+	f := newApprovalFixture(t)
+	ctx := context.Background()
+	editor := &models.User{ID: "editor", TenantID: "acme", Role: "editor"}
+
+	_, err := f.svc.Publish(ctx, editor, "video-1", &models.CreatePublicationJobRequest{Platform: "myspace", WorkspaceID: "ws-1"})
+	assert.ErrorIs(t, err, models.ErrInvalidPlatform)
+	_, err = f.svc.Publish(ctx, editor, "video-2", &models.CreatePublicationJobRequest{Platform: "youtube", WorkspaceID: "ws-1"})
+	assert.ErrorIs(t, err, models.ErrConflict)
+	_, err = f.svc.Publish(ctx, editor, "video-1", &models.CreatePublicationJobRequest{Platform: "youtube"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = f.svc.Publish(ctx, editor, "video-1", &models.CreatePublicationJobRequest{Platform: "youtube", WorkspaceID: "ws-2"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = f.svc.Publish(ctx, editor, "private", &models.CreatePublicationJobRequest{Platform: "youtube", WorkspaceID: "ws-1"})
+	assert.ErrorIs(t, err, models.ErrVideoNotFound, "videos the user does not see are not found")
+
+	job, err := f.svc.Publish(ctx, editor, "video-1", &models.CreatePublicationJobRequest{Platform: "youtube", WorkspaceID: "ws-1"})
+	require.NoError(t, err)
+	assert.Equal(t, string(models.PublicationScheduled), job.Status)
+	assert.Equal(t, f.clock.Now(), job.ScheduledAt.Time)
+	assert.Empty(t, f.notified.subjects)
+
+	_, err = f.svc.Approve(ctx, &models.User{ID: "admin", TenantID: "acme", Role: "admin"}, job.ID, "")
+	assert.ErrorIs(t, err, models.ErrConflict)
+}
+
+func TestApprovalService_TwoPersonRule(t *testing.T) {
+	f := newApprovalFixture(t)
+	ctx := context.Background()
+	editor := &models.User{ID: "editor", TenantID: "acme", Role: "editor"}
+	publisher := &models.User{ID: "publisher", TenantID: "acme", Role: "publisher"}
+	admin := &models.User{ID: "admin", TenantID: "acme", Role: "admin"}
+
+	_, err := f.svc.SetPolicy(ctx, "acme", &models.ApprovalPolicy{ApproverRole: "viewer"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = f.svc.SetPolicy(ctx, "acme", &models.ApprovalPolicy{ApproverRole: "publisher"})
+	require.NoError(t, err)
+	policy, err := f.svc.Policy(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, "publisher", policy.ApproverRole)
+
+	// Active approvers other than the requester are notified
+	later := f.clock.Now().Add(2 * time.Hour)
+	job, err := f.svc.Publish(ctx, editor, "video-1", &models.CreatePublicationJobRequest{Platform: "youtube", WorkspaceID: "ws-1", ScheduledAt: &later})
+	require.NoError(t, err)
+	assert.Equal(t, string(models.PublicationAwaitingApproval), job.Status)
+	assert.Equal(t, map[string][]string{
+		"publisher": {"Publication awaiting approval"},
+		"admin":     {"Publication awaiting approval"},
+	}, f.notified.subjects)
+	awaiting, err := f.svc.AwaitingApproval(ctx, "acme", 20, 0)
+	require.NoError(t, err)
+	assert.Len(t, awaiting, 1)
+
+	// Neither the requester nor users without the role may review
+	_, err = f.svc.Approve(ctx, editor, job.ID, "")
+	assert.ErrorIs(t, err, models.ErrForbidden)
+	_, err = f.svc.Approve(ctx, &models.User{ID: "viewer", TenantID: "acme", Role: "viewer"}, job.ID, "")
+	assert.ErrorIs(t, err, models.ErrForbidden)
+	_, err = f.svc.Reject(ctx, publisher, job.ID, "")
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	approved, err := f.svc.Approve(ctx, publisher, job.ID, "good to go")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.PublicationScheduled), approved.Status)
+	assert.Equal(t, later, approved.ScheduledAt.Time, "the requested time is kept")
+	assert.Equal(t, "publisher", approved.ReviewedBy)
+	assert.Equal(t, []string{"Publication approved"}, f.notified.subjects["editor"])
+	_, err = f.svc.Reject(ctx, admin, job.ID, "too late")
+	assert.ErrorIs(t, err, models.ErrConflict)
+
+	// Admins may review too; a publication approved late is scheduled now
+	job, err = f.svc.Publish(ctx, publisher, "video-1", &models.CreatePublicationJobRequest{Platform: "tiktok", WorkspaceID: "ws-1"})
+	require.NoError(t, err)
+	f.clock.Advance(time.Hour)
+	rejected, err := f.svc.Reject(ctx, admin, job.ID, "wrong thumbnail")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.PublicationRejected), rejected.Status)
+	assert.Equal(t, "wrong thumbnail", rejected.ReviewComment)
+
+	job, err = f.svc.Publish(ctx, editor, "video-1", &models.CreatePublicationJobRequest{Platform: "instagram", WorkspaceID: "ws-1"})
+	require.NoError(t, err)
+	f.clock.Advance(time.Hour)
+	approved, err = f.svc.Approve(ctx, admin, job.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, f.clock.Now(), approved.ScheduledAt.Time)
+
+	require.Len(t, f.audit.entries, 3)
+	assert.Equal(t, models.AuditActionPublicationApprove, f.audit.entries[0].Action)
+	assert.Equal(t, "/api/v1/videos/video-1/publications/pub-1", f.audit.entries[0].Resource)
+	assert.Equal(t, "good to go", f.audit.entries[0].Detail)
+	assert.Equal(t, models.AuditActionPublicationReject, f.audit.entries[1].Action)
+	assert.Equal(t, "wrong thumbnail", f.audit.entries[1].Detail)
+}
+
+func TestApprovalService_ApproveHeldByBlackout(t *testing.T) {
+	f := newApprovalFixture(t)
+	// Staged, then held when a blackout window covered its release
+	f.jobs.jobs = []*models.PublicationJob{{ID: "held", TenantID: "acme", UserID: "editor", VideoID: "video-1", Platform: "youtube",
+		Status: string(models.PublicationAwaitingApproval), BlackoutWindowID: "holiday",
+		StagedAt: sql.NullTime{Time: f.clock.Now().Add(-time.Hour), Valid: true}}}
+
+	approved, err := f.svc.Approve(context.Background(), &models.User{ID: "admin", TenantID: "acme", Role: "admin"}, "held", "")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.PublicationStaged), approved.Status, "released without uploading again")
+	assert.Equal(t, f.clock.Now(), approved.ScheduledAt.Time)
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+const (
+	// archiveBatchSize caps the videos archived per tenant in one lifecycle
+	// run, so one large tenant cannot hold the run for hours
+	archiveBatchSize = 500
+
+	// restoreBatchSize caps the pending restores checked in one lifecycle run
+	restoreBatchSize = 500
+)
+
+// archiveService implements the ArchiveService interface
+type archiveService struct {
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	policies   models.RetentionPolicyRepository
+	storage    aws.ArchiveStorage
+	residency  ResidencyService
+	jobs       JobService
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ ArchiveService = (*archiveService)(nil)
+
+// NewArchiveService creates a new archive service instance. Videos that do not
+// record their own bucket are looked up in the bucket of their tenant's residency.
+func NewArchiveService(videos models.VideoRepository, workspaces models.WorkspaceRepository, policies models.RetentionPolicyRepository, storage aws.ArchiveStorage, residency ResidencyService, jobs JobService, clock clock.Clock, logger *logger.Logger) ArchiveService {
+	return &archiveService{
+		videos:     videos,
+		workspaces: workspaces,
+		policies:   policies,
+		storage:    storage,
+		residency:  residency,
+		jobs:       jobs,
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+// GetRetentionPolicy returns the tenant's retention rules, or the default ones
+func (s *archiveService) GetRetentionPolicy(ctx context.Context, tenantID string) (*models.RetentionPolicy, error) {
+	policy, err := s.policies.Get(ctx, tenantID)
+	if errors.Is(err, models.ErrNotFound) {
+		return models.DefaultRetentionPolicy(tenantID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention policy: %w", err)
+	}
+	return policy, nil
+}
+
+// UpdateRetentionPolicy changes the fields set in req and saves the policy
+func (s *archiveService) UpdateRetentionPolicy(ctx context.Context, tenantID string, req *models.UpdateRetentionPolicyRequest) (*models.RetentionPolicy, error) {
+	policy, err := s.GetRetentionPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ArchiveAfterMonths != nil {
+		policy.ArchiveAfterMonths = *req.ArchiveAfterMonths
+	}
+	if req.RestoreDays != nil {
+		policy.RestoreDays = *req.RestoreDays
+	}
+	if req.RestoreTier != nil {
+		policy.RestoreTier = *req.RestoreTier
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.policies.Upsert(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to save retention policy: %w", err)
+	}
+
+	s.logger.Info("Retention policy updated",
+		"tenant_id", tenantID,
+		"archive_after_months", policy.ArchiveAfterMonths,
+		"restore_days", policy.RestoreDays,
+		"restore_tier", policy.RestoreTier)
+	return s.GetRetentionPolicy(ctx, tenantID)
+}
+
+// RestoreVideo starts the Glacier retrieval of an archived video. The video
+// becomes ready again once a lifecycle run or status check sees the restore
+// finish; asking again while a restore is running changes nothing.
+func (s *archiveService) RestoreVideo(ctx context.Context, viewer *models.User, videoID string) (*ArchiveStatus, error) {
+	tenantID := viewer.TenantID
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if !video.IsArchived() {
+		return nil, models.ErrVideoNotArchived
+	}
+	if video.RestoreStatus == models.RestoreInProgress {
+		return s.restoreStatus(ctx, video), nil
+	}
+
+	policy, err := s.GetRetentionPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := s.bucket(ctx, video)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.Restore(ctx, bucket, video.S3Key, policy.RestoreDays, policy.RestoreTier); err != nil {
+		return nil, fmt.Errorf("failed to request restore: %w", err)
+	}
+
+	now := s.clock.Now()
+	video.RestoreStatus = models.RestoreInProgress
+	video.RestoreRequestedAt = &now
+	if err := s.videos.Update(ctx, video); err != nil {
+		return nil, fmt.Errorf("failed to record restore request: %w", err)
+	}
+
+	s.logger.Info("Video restore requested", "tenant_id", tenantID, "video_id", videoID, "tier", policy.RestoreTier)
+	return s.restoreStatus(ctx, video), nil
+}
+
+// restoreStatus is the archive status of a restoring video, with the job
+// tracking the restore
+func (s *archiveService) restoreStatus(ctx context.Context, video *models.Video) *ArchiveStatus {
+	status := newArchiveStatus(video)
+	if job := s.jobs.Start(ctx, video.TenantID, "", models.RestoreJob(video.ID)); job != nil {
+		status.JobID = job.ID
+	}
+	return status
+}
+
+// GetArchiveStatus reports the archive state of a video, first completing a
+// restore that S3 has finished since the last lifecycle run
+func (s *archiveService) GetArchiveStatus(ctx context.Context, viewer *models.User, videoID string) (*ArchiveStatus, error) {
+	tenantID := viewer.TenantID
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if video.IsArchived() && video.RestoreStatus == models.RestoreInProgress {
+		if _, err := s.completeRestore(ctx, video); err != nil {
+			// The stored status is still accurate, only behind
+			s.logger.Warn("Failed to check video restore", "tenant_id", tenantID, "video_id", videoID, "error", err)
+		}
+	}
+	return newArchiveStatus(video), nil
+}
+
+// RunLifecycle archives the eligible videos of every tenant with archiving
+// enabled, then completes the restores S3 has finished. Failures on single
+// videos are logged and counted; the run goes on with the next one.
+func (s *archiveService) RunLifecycle(ctx context.Context) (*LifecycleReport, error) {
+	report := &LifecycleReport{}
+
+	policies, err := s.policies.ListEnabled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention policies: %w", err)
+	}
+
+	now := s.clock.Now()
+	for _, policy := range policies {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		report.Tenants++
+
+		videos, err := s.videos.ListArchivable(ctx, policy.TenantID, policy.ArchiveCutoff(now), archiveBatchSize)
+		if err != nil {
+			return report, fmt.Errorf("failed to list archivable videos for tenant %s: %w", policy.TenantID, err)
+		}
+		for _, video := range videos {
+			if err := s.archive(ctx, video); err != nil {
+				if ctx.Err() != nil {
+					return report, ctx.Err()
+				}
+				s.logger.Error("Failed to archive video", "tenant_id", video.TenantID, "video_id", video.ID, "error", err)
+				report.Failed++
+				continue
+			}
+			report.Archived++
+		}
+	}
+
+	restoring, err := s.videos.ListRestoring(ctx, restoreBatchSize)
+	if err != nil {
+		return report, fmt.Errorf("failed to list restoring videos: %w", err)
+	}
+	for _, video := range restoring {
+		done, err := s.completeRestore(ctx, video)
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			s.logger.Error("Failed to complete video restore", "tenant_id", video.TenantID, "video_id", video.ID, "error", err)
+			report.Failed++
+			continue
+		}
+		if done {
+			report.Restored++
+		}
+	}
+
+	s.logger.Info("Archive lifecycle run finished",
+		"tenants", report.Tenants,
+		"archived", report.Archived,
+		"restored", report.Restored,
+		"failed", report.Failed)
+	return report, nil
+}
+
+// archive moves the video file to Glacier and marks the video archived
+func (s *archiveService) archive(ctx context.Context, video *models.Video) error {
+	bucket, err := s.bucket(ctx, video)
+	if err != nil {
+		return err
+	}
+	if err := s.storage.Archive(ctx, bucket, video.S3Key); err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	video.Status = string(models.StatusArchived)
+	video.ArchivedAt = &now
+	video.RestoreStatus = ""
+	video.RestoreRequestedAt = nil
+	if err := s.videos.Update(ctx, video); err != nil {
+		return fmt.Errorf("file archived but video not updated: %w", err)
+	}
+
+	s.logger.Info("Video archived", "tenant_id", video.TenantID, "video_id", video.ID, "s3_key", video.S3Key)
+	return nil
+}
+
+// completeRestore copies a restored file back to Standard storage and marks
+// the video ready; it reports false while the retrieval is still running
+func (s *archiveService) completeRestore(ctx context.Context, video *models.Video) (bool, error) {
+	bucket, err := s.bucket(ctx, video)
+	if err != nil {
+		return false, err
+	}
+	status, err := s.storage.Status(ctx, bucket, video.S3Key)
+	if err != nil {
+		return false, err
+	}
+	if status.StorageClass == aws.StorageClassGlacier && !status.Restored {
+		return false, nil
+	}
+
+	// The restored copy is temporary; a Standard copy keeps the file readable
+	if status.StorageClass == aws.StorageClassGlacier {
+		if err := s.storage.Unarchive(ctx, bucket, video.S3Key); err != nil {
+			return false, err
+		}
+	}
+
+	video.Status = string(models.StatusReady)
+	video.ArchivedAt = nil
+	video.RestoreStatus = models.RestoreCompleted
+	if err := s.videos.Update(ctx, video); err != nil {
+		return false, fmt.Errorf("file restored but video not updated: %w", err)
+	}
+
+	s.jobs.Succeed(ctx, video.TenantID, models.RestoreJob(video.ID))
+	s.logger.Info("Video restored", "tenant_id", video.TenantID, "video_id", video.ID)
+	return true, nil
+}
+
+func (s *archiveService) bucket(ctx context.Context, video *models.Video) (string, error) {
+	if video.S3Bucket != "" {
+		return video.S3Bucket, nil
+	}
+	tr, err := s.residency.GetResidency(ctx, video.TenantID)
+	if err != nil {
+		return "", err
+	}
+	if tr.Placement.S3Bucket == "" {
+		return "", fmt.Errorf("no S3 bucket configured for residency %s", tr.Residency)
+	}
+	return tr.Placement.S3Bucket, nil
+}
+
+func newArchiveStatus(video *models.Video) *ArchiveStatus {
+	storageClass := aws.StorageClassStandard
+	if video.IsArchived() {
+		storageClass = aws.StorageClassGlacier
+	}
+	return &ArchiveStatus{
+		VideoID:            video.ID,
+		Status:             video.Status,
+		StorageClass:       storageClass,
+		ArchivedAt:         video.ArchivedAt,
+		RestoreStatus:      video.RestoreStatus,
+		RestoreRequestedAt: video.RestoreRequestedAt,
+	}
+}
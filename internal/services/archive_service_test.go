@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// archiveVideoRepo keeps videos in memory and applies the lifecycle filters
+type archiveVideoRepo struct {
+	clock clock.Clock
+	models.VideoRepository
+	videos map[string]*models.Video
+}
+
+func (r *archiveVideoRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Video, error) {
+	if v, ok := r.videos[id]; ok && v.TenantID == tenantID {
+		return v, nil
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+// Update stamps updated_at like the autoUpdateTime column
+func (r *archiveVideoRepo) Update(ctx context.Context, video *models.Video) error {
+	video.UpdatedAt = r.clock.Now()
+	r.videos[video.ID] = video
+	return nil
+}
+
+func (r *archiveVideoRepo) ListArchivable(ctx context.Context, tenantID string, updatedBefore time.Time, limit int) ([]*models.Video, error) {
+	var videos []*models.Video
+	for _, v := range r.videos {
+		if v.TenantID == tenantID && v.Status == string(models.StatusReady) && v.UpdatedAt.Before(updatedBefore) &&
+			v.S3Key != "" && !v.IsPublished() {
+			videos = append(videos, v)
+		}
+	}
+	return videos, nil
+}
+
+func (r *archiveVideoRepo) ListRestoring(ctx context.Context, limit int) ([]*models.Video, error) {
+	var videos []*models.Video
+	for _, v := range r.videos {
+		if v.IsArchived() && v.RestoreStatus == models.RestoreInProgress {
+			videos = append(videos, v)
+		}
+	}
+	return videos, nil
+}
+
+// memoryPolicyRepo stores policies by tenant
+type memoryPolicyRepo struct {
+	policies map[string]*models.RetentionPolicy
+}
+
+func (r *memoryPolicyRepo) Get(ctx context.Context, tenantID string) (*models.RetentionPolicy, error) {
+	if p, ok := r.policies[tenantID]; ok {
+		copied := *p
+		return &copied, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *memoryPolicyRepo) Upsert(ctx context.Context, policy *models.RetentionPolicy) error {
+	copied := *policy
+	r.policies[policy.TenantID] = &copied
+	return nil
+}
+
+func (r *memoryPolicyRepo) ListEnabled(ctx context.Context) ([]*models.RetentionPolicy, error) {
+	var policies []*models.RetentionPolicy
+	for _, p := range r.policies {
+		if p.Enabled() {
+			policies = append(policies, p)
+		}
+	}
+	return policies, nil
+}
+
+// failingArchiveStorage fails to archive one key
+type failingArchiveStorage struct {
+	aws.ArchiveStorage
+	failKey string
+}
+
+func (s *failingArchiveStorage) Archive(ctx context.Context, bucket, key string) error {
+	if key == s.failKey {
+		return errors.New("access denied")
+	}
+	return s.ArchiveStorage.Archive(ctx, bucket, key)
+}
+
+func newArchiveFixture(t *testing.T, storage aws.ArchiveStorage) (*archiveVideoRepo, *memoryPolicyRepo, ArchiveService, *clock.Fake) {
+	now := clock.NewFake(time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC))
+	old := now.Now().AddDate(0, -13, 0)
+	videos := &archiveVideoRepo{clock: now, videos: map[string]*models.Video{
+		"old":       {ID: "old", TenantID: "tenant-1", Status: string(models.StatusReady), S3Key: "tenant-1/old.mp4", UpdatedAt: old},
+		"recent":    {ID: "recent", TenantID: "tenant-1", Status: string(models.StatusReady), S3Key: "tenant-1/recent.mp4", UpdatedAt: now.Now()},
+		"published": {ID: "published", TenantID: "tenant-1", Status: string(models.StatusReady), S3Key: "tenant-1/published.mp4", UpdatedAt: old, YouTubeID: "yt-1"},
+		"other":     {ID: "other", TenantID: "tenant-2", Status: string(models.StatusReady), S3Key: "tenant-2/other.mp4", UpdatedAt: old},
+		"private":   {ID: "private", TenantID: "tenant-1", UserID: "owner", Visibility: models.VisibilityOwner, Status: string(models.StatusReady), UpdatedAt: old},
+	}}
+	policies := &memoryPolicyRepo{policies: map[string]*models.RetentionPolicy{
+		"tenant-1": {TenantID: "tenant-1", ArchiveAfterMonths: 12, RestoreDays: 3, RestoreTier: "Bulk"},
+	}}
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{}}
+	residencies := NewResidencyService(tenants, newTestPlacements(t), logger.New("error", "test"))
+	_, jobs := newTestJobs(now)
+	svc := NewArchiveService(videos, nil, policies, storage, residencies, jobs, now, logger.New("error", "test"))
+	return videos, policies, svc, now
+}
+
+func TestArchiveService_RunLifecycleArchivesEligibleVideos(t *testing.T) {
+	storage := aws.NewFakeArchiveStorage(0, logger.New("error", "test"))
+	videos, _, svc, _ := newArchiveFixture(t, storage)
+	ctx := context.Background()
+
+	report, err := svc.RunLifecycle(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &LifecycleReport{Tenants: 1, Archived: 1}, report)
+
+	assert.True(t, videos.videos["old"].IsArchived())
+	assert.NotNil(t, videos.videos["old"].ArchivedAt)
+	assert.False(t, videos.videos["recent"].IsArchived(), "updated within the retention period")
+	assert.False(t, videos.videos["published"].IsArchived(), "published videos are kept")
+	assert.False(t, videos.videos["other"].IsArchived(), "tenant-2 has no policy")
+
+	status, err := storage.Status(ctx, "videos-bucket", "tenant-1/old.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, aws.StorageClassGlacier, status.StorageClass)
+}
+
+func TestArchiveService_RunLifecycleArchivesOnceRetentionPasses(t *testing.T) {
+	videos, _, svc, now := newArchiveFixture(t, aws.NewFakeArchiveStorage(0, logger.New("error", "test")))
+	ctx := context.Background()
+
+	now.Advance(11 * 30 * 24 * time.Hour)
+	_, err := svc.RunLifecycle(ctx)
+	require.NoError(t, err)
+	assert.False(t, videos.videos["recent"].IsArchived(), "eleven months is within the twelve month retention")
+
+	now.Advance(60 * 24 * time.Hour)
+	report, err := svc.RunLifecycle(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Archived)
+	assert.True(t, videos.videos["recent"].IsArchived())
+	assert.Equal(t, now.Now(), *videos.videos["recent"].ArchivedAt)
+}
+
+func TestArchiveService_RunLifecycleCountsFailures(t *testing.T) {
+	storage := &failingArchiveStorage{ArchiveStorage: aws.NewFakeArchiveStorage(0, logger.New("error", "test")), failKey: "tenant-1/old.mp4"}
+	videos, _, svc, _ := newArchiveFixture(t, storage)
+
+	report, err := svc.RunLifecycle(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 0, report.Archived)
+	assert.Equal(t, string(models.StatusReady), videos.videos["old"].Status, "a failed archive leaves the video untouched")
+}
+
+func TestArchiveService_RestoreVideo(t *testing.T) {
+	storage := aws.NewFakeArchiveStorage(time.Hour, logger.New("error", "test"))
+	videos, _, svc, _ := newArchiveFixture(t, storage)
+	ctx := context.Background()
+	editor := &models.User{ID: "editor", TenantID: "tenant-1"}
+
+	_, err := svc.RestoreVideo(ctx, editor, "recent")
+	assert.ErrorIs(t, err, models.ErrVideoNotArchived)
+	_, err = svc.RestoreVideo(ctx, &models.User{ID: "editor", TenantID: "tenant-2"}, "old")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound, "videos of another tenant are not visible")
+
+	_, err = svc.RunLifecycle(ctx)
+	require.NoError(t, err)
+
+	status, err := svc.RestoreVideo(ctx, editor, "old")
+	require.NoError(t, err)
+	assert.Equal(t, models.RestoreInProgress, status.RestoreStatus)
+	assert.Equal(t, aws.StorageClassGlacier, status.StorageClass)
+	require.NotNil(t, status.RestoreRequestedAt)
+
+	again, err := svc.RestoreVideo(ctx, editor, "old")
+	require.NoError(t, err)
+	assert.Equal(t, status.RestoreRequestedAt, again.RestoreRequestedAt, "a running restore is not requested twice")
+
+	status, err = svc.GetArchiveStatus(ctx, editor, "old")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.StatusArchived), status.Status, "the retrieval takes an hour")
+	assert.Equal(t, models.RestoreInProgress, videos.videos["old"].RestoreStatus)
+
+	_, err = svc.RestoreVideo(ctx, editor, "private")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound, "videos the user does not see are not found")
+	_, err = svc.GetArchiveStatus(ctx, editor, "private")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+}
+
+func TestArchiveService_RestoreCompletes(t *testing.T) {
+	storage := aws.NewFakeArchiveStorage(0, logger.New("error", "test"))
+	videos, _, svc, _ := newArchiveFixture(t, storage)
+	ctx := context.Background()
+	editor := &models.User{ID: "editor", TenantID: "tenant-1"}
+
+	_, err := svc.RunLifecycle(ctx)
+	require.NoError(t, err)
+	_, err = svc.RestoreVideo(ctx, editor, "old")
+	require.NoError(t, err)
+
+	report, err := svc.RunLifecycle(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Restored)
+
+	video := videos.videos["old"]
+	assert.Equal(t, string(models.StatusReady), video.Status)
+	assert.Equal(t, models.RestoreCompleted, video.RestoreStatus)
+	assert.Nil(t, video.ArchivedAt)
+
+	status, err := storage.Status(ctx, "videos-bucket", "tenant-1/old.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, aws.StorageClassStandard, status.StorageClass)
+
+	report, err = svc.RunLifecycle(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Archived, "a restored video counts as touched")
+}
+
+func TestArchiveService_RetentionPolicy(t *testing.T) {
+	_, policies, svc, _ := newArchiveFixture(t, aws.NewFakeArchiveStorage(0, logger.New("error", "test")))
+	ctx := context.Background()
+
+	policy, err := svc.GetRetentionPolicy(ctx, "tenant-2")
+	require.NoError(t, err)
+	assert.False(t, policy.Enabled(), "archiving is opt-in")
+	assert.Equal(t, models.DefaultRestoreDays, policy.RestoreDays)
+
+	months, tier := 6, "Expedited"
+	policy, err = svc.UpdateRetentionPolicy(ctx, "tenant-2", &models.UpdateRetentionPolicyRequest{ArchiveAfterMonths: &months, RestoreTier: &tier})
+	require.NoError(t, err)
+	assert.Equal(t, 6, policy.ArchiveAfterMonths)
+	assert.Equal(t, "Expedited", policy.RestoreTier)
+	assert.Equal(t, models.DefaultRestoreDays, policy.RestoreDays)
+	assert.Equal(t, 6, policies.policies["tenant-2"].ArchiveAfterMonths)
+
+	invalid := "Glacial"
+	_, err = svc.UpdateRetentionPolicy(ctx, "tenant-2", &models.UpdateRetentionPolicyRequest{RestoreTier: &invalid})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	assert.Equal(t, "Expedited", policies.policies["tenant-2"].RestoreTier, "an invalid update is not saved")
+}
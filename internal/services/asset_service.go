@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// maxAssetSize is the largest part S3 accepts, assets being uploaded in one
+const maxAssetSize = 5 << 30
+
+// assetService implements the AssetService interface
+type assetService struct {
+	assets    models.AssetRepository
+	storage   aws.MultipartStorage
+	residency ResidencyService
+	clock     clock.Clock
+	logger    *logger.Logger
+}
+
+var _ AssetService = (*assetService)(nil)
+
+// NewAssetService creates a new asset library service, storing files in the
+// bucket of each tenant's residency
+func NewAssetService(assets models.AssetRepository, storage aws.MultipartStorage, residency ResidencyService, clock clock.Clock, logger *logger.Logger) AssetService {
+	return &assetService{assets: assets, storage: storage, residency: residency, clock: clock, logger: logger}
+}
+
+// Create adds an asset and starts the upload of its file as the single part
+// of a multipart upload, whose URL is presigned
+func (s *assetService) Create(ctx context.Context, tenantID, userID string, req *models.CreateAssetRequest) (*AssetUpload, error) {
+	if !req.Kind.Valid() {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "asset kind must be one of %v", models.AssetKinds)
+	}
+	if !req.Kind.Accepts(req.ContentType) {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "content type %s is not accepted for %s assets", req.ContentType, req.Kind)
+	}
+	if req.Size > maxAssetSize {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the file must be at most %d bytes", int64(maxAssetSize))
+	}
+	filename := path.Base(req.Filename)
+	if filename == "." || filename == "/" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "invalid filename")
+	}
+
+	tr, err := s.residency.GetResidency(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tr.Placement.S3Bucket == "" {
+		return nil, fmt.Errorf("no S3 bucket configured for residency %s", tr.Residency)
+	}
+
+	asset := &models.Asset{
+		ID:          id.New(),
+		TenantID:    tenantID,
+		Kind:        req.Kind,
+		Name:        req.Name,
+		Status:      models.AssetUploading,
+		Filename:    filename,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		License:     req.License,
+		S3Bucket:    tr.Placement.S3Bucket,
+		CreatedBy:   userID,
+	}
+	asset.S3Key = fmt.Sprintf("%s/assets/%s/%s", tenantID, asset.ID, filename)
+	asset.S3UploadID, err = s.storage.CreateMultipartUpload(ctx, asset.S3Bucket, asset.S3Key, asset.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start asset upload: %w", err)
+	}
+	url, err := s.storage.PresignUploadPart(ctx, asset.S3Bucket, asset.S3Key, asset.S3UploadID, 1, partURLExpiry)
+	if err == nil {
+		err = s.assets.Create(ctx, asset)
+	}
+	if err != nil {
+		s.abort(ctx, asset)
+		return nil, fmt.Errorf("failed to create asset: %w", err)
+	}
+
+	s.logger.Info("Asset upload started", "asset_id", asset.ID, "tenant_id", tenantID, "kind", asset.Kind, "size", asset.Size)
+	return &AssetUpload{Asset: asset, UploadURL: url, ExpiresAt: s.clock.Now().Add(partURLExpiry)}, nil
+}
+
+// Complete assembles the uploaded file. Completing a ready asset returns it
+// as it is.
+func (s *assetService) Complete(ctx context.Context, tenantID, id string) (*models.Asset, error) {
+	asset, err := s.assets.Get(ctx, tenantID, id)
+	if err != nil || asset.Status == models.AssetReady {
+		return asset, err
+	}
+
+	parts, err := s.storage.ListParts(ctx, asset.S3Bucket, asset.S3Key, asset.S3UploadID)
+	if errors.Is(err, aws.ErrObjectNotFound) {
+		return nil, i18n.Errorf(models.ErrConflict, "the upload expired; add the asset again")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uploaded parts: %w", err)
+	}
+	if len(parts) == 0 {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the file was not uploaded")
+	}
+	if len(parts) > 1 || parts[0].PartNumber != 1 || parts[0].Size != asset.Size {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the file must be uploaded in one part of %d bytes", asset.Size)
+	}
+	if err := s.storage.CompleteMultipartUpload(ctx, asset.S3Bucket, asset.S3Key, asset.S3UploadID, parts); err != nil {
+		return nil, fmt.Errorf("failed to complete asset upload: %w", err)
+	}
+
+	now := s.clock.Now()
+	asset.Status = models.AssetReady
+	asset.S3UploadID = ""
+	asset.UploadedAt = &now
+	if err := s.assets.Update(ctx, asset); err != nil {
+		return nil, fmt.Errorf("failed to update asset: %w", err)
+	}
+	s.logger.Info("Asset uploaded", "asset_id", asset.ID, "tenant_id", tenantID, "kind", asset.Kind, "size", asset.Size)
+	return asset, nil
+}
+
+// List returns the tenant's assets, newest first
+func (s *assetService) List(ctx context.Context, tenantID string, kind models.AssetKind) ([]*models.Asset, error) {
+	if kind != "" && !kind.Valid() {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "asset kind must be one of %v", models.AssetKinds)
+	}
+	assets, err := s.assets.List(ctx, tenantID, kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assets: %w", err)
+	}
+	return assets, nil
+}
+
+// Get returns an asset of the tenant
+func (s *assetService) Get(ctx context.Context, tenantID, id string) (*models.Asset, error) {
+	return s.assets.Get(ctx, tenantID, id)
+}
+
+// Update renames an asset or replaces its license
+func (s *assetService) Update(ctx context.Context, tenantID, id string, req *models.UpdateAssetRequest) (*models.Asset, error) {
+	asset, err := s.assets.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		asset.Name = *req.Name
+	}
+	if req.License != nil {
+		asset.License = *req.License
+	}
+	if err := s.assets.Update(ctx, asset); err != nil {
+		return nil, fmt.Errorf("failed to update asset: %w", err)
+	}
+	return asset, nil
+}
+
+// Delete removes an asset and discards its upload in progress. Its file is
+// kept, for the renditions already planned with it.
+func (s *assetService) Delete(ctx context.Context, tenantID, id string) error {
+	asset, err := s.assets.Get(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	defaults, err := s.Defaults(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if defaults.Uses(id) {
+		return i18n.Errorf(models.ErrConflict, "the asset is the tenant's intro or outro; choose another one first")
+	}
+	if err := s.assets.Delete(ctx, tenantID, id); err != nil {
+		return err
+	}
+	if asset.Status == models.AssetUploading {
+		s.abort(ctx, asset)
+	}
+	s.logger.Info("Asset deleted", "asset_id", id, "tenant_id", tenantID, "kind", asset.Kind)
+	return nil
+}
+
+// Defaults returns the tenant's defaults, empty when it chose none
+func (s *assetService) Defaults(ctx context.Context, tenantID string) (*models.AssetDefaults, error) {
+	defaults, err := s.assets.GetDefaults(ctx, tenantID)
+	if errors.Is(err, models.ErrNotFound) {
+		return &models.AssetDefaults{TenantID: tenantID, Profiles: []string{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset defaults: %w", err)
+	}
+	return defaults, nil
+}
+
+// PutDefaults replaces the tenant's intro, outro and profiles. They apply
+// to the renditions planned afterwards.
+func (s *assetService) PutDefaults(ctx context.Context, tenantID, userID string, req *models.AssetDefaultsRequest) (*models.AssetDefaults, error) {
+	if err := s.checkDefault(ctx, tenantID, req.IntroAssetID, models.AssetIntro); err != nil {
+		return nil, err
+	}
+	if err := s.checkDefault(ctx, tenantID, req.OutroAssetID, models.AssetOutro); err != nil {
+		return nil, err
+	}
+	for _, profile := range req.Profiles {
+		if _, ok := transcode.Profiles[profile]; !ok {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "unknown rendition profile %q", profile)
+		}
+	}
+
+	defaults, err := s.Defaults(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defaults.IntroAssetID = req.IntroAssetID
+	defaults.OutroAssetID = req.OutroAssetID
+	defaults.Profiles = append([]string{}, req.Profiles...)
+	defaults.UpdatedBy = userID
+	if err := s.assets.PutDefaults(ctx, defaults); err != nil {
+		return nil, fmt.Errorf("failed to save asset defaults: %w", err)
+	}
+	s.logger.Info("Asset defaults updated", "tenant_id", tenantID, "user_id", userID,
+		"intro_asset_id", req.IntroAssetID, "outro_asset_id", req.OutroAssetID)
+	return defaults, nil
+}
+
+// checkDefault checks the asset, if any, can be stitched as the kind
+func (s *assetService) checkDefault(ctx context.Context, tenantID, id string, kind models.AssetKind) error {
+	if id == "" {
+		return nil
+	}
+	asset, err := s.assets.Get(ctx, tenantID, id)
+	if errors.Is(err, models.ErrAssetNotFound) {
+		return i18n.Errorf(models.ErrInvalidInput, "asset %s not found", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get asset: %w", err)
+	}
+	switch {
+	case asset.Kind != kind:
+		return i18n.Errorf(models.ErrInvalidInput, "asset %s is not an %s clip", id, kind)
+	case asset.Status != models.AssetReady:
+		return i18n.Errorf(models.ErrInvalidInput, "asset %s is not uploaded yet", id)
+	case !asset.Usable(s.clock.Now()):
+		return i18n.Errorf(models.ErrInvalidInput, "the license of asset %s has expired", id)
+	}
+	return nil
+}
+
+// Stitching returns the tenant's intro and outro when they apply to the
+// profile. An asset deleted or whose license expired since it was chosen is
+// left out.
+func (s *assetService) Stitching(ctx context.Context, tenantID, profile string) (*models.Asset, *models.Asset, error) {
+	defaults, err := s.Defaults(ctx, tenantID)
+	if err != nil || !defaults.Stitches(profile) {
+		return nil, nil, err
+	}
+	intro, err := s.stitched(ctx, tenantID, defaults.IntroAssetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	outro, err := s.stitched(ctx, tenantID, defaults.OutroAssetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return intro, outro, nil
+}
+
+// stitched returns the asset if it can still be stitched
+func (s *assetService) stitched(ctx context.Context, tenantID, id string) (*models.Asset, error) {
+	if id == "" {
+		return nil, nil
+	}
+	asset, err := s.assets.Get(ctx, tenantID, id)
+	if errors.Is(err, models.ErrAssetNotFound) {
+		s.logger.Warn("Default asset not found", "asset_id", id, "tenant_id", tenantID)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset: %w", err)
+	}
+	if !asset.Usable(s.clock.Now()) {
+		s.logger.Warn("Default asset not stitched, its license expired", "asset_id", id, "tenant_id", tenantID)
+		return nil, nil
+	}
+	return asset, nil
+}
+
+// abort discards the asset's upload. The asset is gone either way, so a
+// failure is logged.
+func (s *assetService) abort(ctx context.Context, asset *models.Asset) {
+	if err := s.storage.AbortMultipartUpload(ctx, asset.S3Bucket, asset.S3Key, asset.S3UploadID); err != nil {
+		s.logger.Error("Failed to abort asset upload", "error", err, "asset_id", asset.ID, "tenant_id", asset.TenantID)
+	}
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// memoryAssetRepo stores assets by ID and the defaults of one tenant
+type memoryAssetRepo struct {
+	assets   map[string]*models.Asset
+	defaults *models.AssetDefaults
+}
+
+func (r *memoryAssetRepo) Create(ctx context.Context, asset *models.Asset) error {
+	r.assets[asset.ID] = asset
+	return nil
+}
+
+func (r *memoryAssetRepo) Get(ctx context.Context, tenantID, id string) (*models.Asset, error) {
+	asset, ok := r.assets[id]
+	if !ok || asset.TenantID != tenantID {
+		return nil, models.ErrAssetNotFound
+	}
+	copied := *asset
+	return &copied, nil
+}
+
+func (r *memoryAssetRepo) List(ctx context.Context, tenantID string, kind models.AssetKind) ([]*models.Asset, error) {
+	var assets []*models.Asset
+	for _, asset := range r.assets {
+		if asset.TenantID == tenantID && (kind == "" || asset.Kind == kind) {
+			assets = append(assets, asset)
+		}
+	}
+	return assets, nil
+}
+
+func (r *memoryAssetRepo) Update(ctx context.Context, asset *models.Asset) error {
+	r.assets[asset.ID] = asset
+	return nil
+}
+
+func (r *memoryAssetRepo) Delete(ctx context.Context, tenantID, id string) error {
+	if _, err := r.Get(ctx, tenantID, id); err != nil {
+		return err
+	}
+	delete(r.assets, id)
+	return nil
+}
+
+func (r *memoryAssetRepo) GetDefaults(ctx context.Context, tenantID string) (*models.AssetDefaults, error) {
+	if r.defaults == nil || r.defaults.TenantID != tenantID {
+		return nil, models.ErrNotFound
+	}
+	copied := *r.defaults
+	return &copied, nil
+}
+
+func (r *memoryAssetRepo) PutDefaults(ctx context.Context, defaults *models.AssetDefaults) error {
+	r.defaults = defaults
+	return nil
+}
+
+// memoryMultipartStorage keeps the parts of its uploads in memory
+type memoryMultipartStorage struct {
+	parts     map[string]map[int][]byte
+	completed map[string][]byte
+}
+
+func newMemoryMultipartStorage() *memoryMultipartStorage {
+	return &memoryMultipartStorage{parts: map[string]map[int][]byte{}, completed: map[string][]byte{}}
+}
+
+func (s *memoryMultipartStorage) CreateMultipartUpload(ctx context.Context, bucket, key, contentType string) (string, error) {
+	uploadID := fmt.Sprintf("s3-upload-%d", len(s.parts)+1)
+	s.parts[uploadID] = map[int][]byte{}
+	return uploadID, nil
+}
+
+func (s *memoryMultipartStorage) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, body []byte) (string, error) {
+	parts, ok := s.parts[uploadID]
+	if !ok {
+		return "", aws.ErrObjectNotFound
+	}
+	parts[partNumber] = body
+	return fmt.Sprintf(`"etag-%d"`, partNumber), nil
+}
+
+func (s *memoryMultipartStorage) PresignUploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, expires time.Duration) (string, error) {
+	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s?partNumber=%d&uploadId=%s", bucket, key, partNumber, uploadID), nil
+}
+
+func (s *memoryMultipartStorage) ListParts(ctx context.Context, bucket, key, uploadID string) ([]aws.UploadedPart, error) {
+	parts, ok := s.parts[uploadID]
+	if !ok {
+		return nil, aws.ErrObjectNotFound
+	}
+	var uploaded []aws.UploadedPart
+	for number, body := range parts {
+		uploaded = append(uploaded, aws.UploadedPart{PartNumber: number, ETag: fmt.Sprintf(`"etag-%d"`, number), Size: int64(len(body))})
+	}
+	return uploaded, nil
+}
+
+func (s *memoryMultipartStorage) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []aws.UploadedPart) error {
+	var object []byte
+	for _, part := range parts {
+		object = append(object, s.parts[uploadID][part.PartNumber]...)
+	}
+	s.completed[bucket+"/"+key] = object
+	delete(s.parts, uploadID)
+	return nil
+}
+
+func (s *memoryMultipartStorage) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	delete(s.parts, uploadID)
+	return nil
+}
+
+// newTestAssets returns an asset service over an in-memory library, for the
+// subsystems stitching the tenant's intro and outro
+func newTestAssets(t *testing.T, c clock.Clock) (*memoryAssetRepo, *memoryMultipartStorage, AssetService) {
+	repo := &memoryAssetRepo{assets: map[string]*models.Asset{}}
+	storage := newMemoryMultipartStorage()
+	residencies := NewResidencyService(&memoryTenantRepo{tenants: map[string]*models.Tenant{}}, newTestPlacements(t), logger.New("error", "test"))
+	return repo, storage, NewAssetService(repo, storage, residencies, c, logger.New("error", "test"))
+}
+
+func TestAssetService_Upload(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	repo, storage, svc := newTestAssets(t, now)
+	ctx := context.Background()
+	license := models.AssetLicense{LicenseType: models.LicenseRoyaltyFree, Source: "Stock Library"}
+
+	upload, err := svc.Create(ctx, "tenant-1", "user-1", &models.CreateAssetRequest{
+		Kind: models.AssetIntro, Name: "Spooky intro", Filename: "../intro.mp4", ContentType: "video/mp4", Size: 5, License: license,
+	})
+	require.NoError(t, err)
+	asset := upload.Asset
+	assert.Equal(t, models.AssetUploading, asset.Status)
+	assert.Equal(t, "videos-bucket", asset.S3Bucket)
+	assert.Equal(t, "tenant-1/assets/"+asset.ID+"/intro.mp4", asset.S3Key)
+	assert.Contains(t, upload.UploadURL, "partNumber=1")
+	assert.Equal(t, time.Date(2026, 10, 15, 13, 0, 0, 0, time.UTC), upload.ExpiresAt)
+
+	_, err = svc.Complete(ctx, "tenant-1", asset.ID)
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "the file was not uploaded")
+	_, err = storage.UploadPart(ctx, asset.S3Bucket, asset.S3Key, asset.S3UploadID, 1, []byte("clip"))
+	require.NoError(t, err)
+	_, err = svc.Complete(ctx, "tenant-1", asset.ID)
+	assert.ErrorContains(t, err, "one part of 5 bytes")
+	_, err = storage.UploadPart(ctx, asset.S3Bucket, asset.S3Key, asset.S3UploadID, 1, []byte("clip!"))
+	require.NoError(t, err)
+
+	ready, err := svc.Complete(ctx, "tenant-1", asset.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.AssetReady, ready.Status)
+	assert.Equal(t, "clip!", string(storage.completed["videos-bucket/"+asset.S3Key]))
+	again, err := svc.Complete(ctx, "tenant-1", asset.ID)
+	require.NoError(t, err, "completing a ready asset returns it")
+	assert.Equal(t, ready.UploadedAt, again.UploadedAt)
+
+	for _, req := range []*models.CreateAssetRequest{
+		{Kind: "sticker", Filename: "a.png", ContentType: "image/png", Size: 1},
+		{Kind: models.AssetLogo, Filename: "logo.mp4", ContentType: "video/mp4", Size: 1},
+		{Kind: models.AssetMusic, Filename: "theme.mp3", ContentType: "audio/mpeg", Size: maxAssetSize + 1},
+	} {
+		_, err := svc.Create(ctx, "tenant-1", "user-1", req)
+		assert.ErrorIs(t, err, models.ErrInvalidInput, req.Kind)
+	}
+
+	pending, err := svc.Create(ctx, "tenant-1", "user-1", &models.CreateAssetRequest{
+		Kind: models.AssetMusic, Name: "Theme", Filename: "theme.mp3", ContentType: "audio/mpeg", Size: 10, License: license,
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.Delete(ctx, "tenant-1", pending.Asset.ID))
+	assert.NotContains(t, storage.parts, pending.Asset.S3UploadID, "the upload is aborted")
+	assert.Len(t, repo.assets, 1)
+}
+
+func TestAssetService_Defaults(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	repo, _, svc := newTestAssets(t, now)
+	ctx := context.Background()
+	expires := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	repo.assets = map[string]*models.Asset{
+		"intro-1":  {ID: "intro-1", TenantID: "tenant-1", Kind: models.AssetIntro, Status: models.AssetReady},
+		"outro-1":  {ID: "outro-1", TenantID: "tenant-1", Kind: models.AssetOutro, Status: models.AssetReady, License: models.AssetLicense{ExpiresAt: &expires}},
+		"outro-2":  {ID: "outro-2", TenantID: "tenant-1", Kind: models.AssetOutro, Status: models.AssetUploading},
+		"intro-gb": {ID: "intro-gb", TenantID: "tenant-2", Kind: models.AssetIntro, Status: models.AssetReady},
+	}
+
+	defaults, err := svc.Defaults(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Empty(t, defaults.IntroAssetID)
+	intro, outro, err := svc.Stitching(ctx, "tenant-1", transcode.Vertical1080p)
+	require.NoError(t, err)
+	assert.Nil(t, intro)
+	assert.Nil(t, outro)
+
+	for _, req := range []*models.AssetDefaultsRequest{
+		{IntroAssetID: "outro-1"},
+		{OutroAssetID: "outro-2"},
+		{IntroAssetID: "intro-gb"},
+		{IntroAssetID: "intro-1", Profiles: []string{"square_1080p"}},
+	} {
+		_, err := svc.PutDefaults(ctx, "tenant-1", "admin-1", req)
+		assert.ErrorIs(t, err, models.ErrInvalidInput)
+	}
+
+	_, err = svc.PutDefaults(ctx, "tenant-1", "admin-1", &models.AssetDefaultsRequest{IntroAssetID: "intro-1", OutroAssetID: "outro-1", Profiles: []string{transcode.Vertical1080p}})
+	require.NoError(t, err)
+	intro, outro, err = svc.Stitching(ctx, "tenant-1", transcode.Vertical1080p)
+	require.NoError(t, err)
+	assert.Equal(t, "intro-1", intro.ID)
+	assert.Equal(t, "outro-1", outro.ID)
+	intro, _, err = svc.Stitching(ctx, "tenant-1", transcode.Landscape1080p)
+	require.NoError(t, err)
+	assert.Nil(t, intro, "only the chosen profiles are stitched")
+
+	assert.ErrorIs(t, svc.Delete(ctx, "tenant-1", "intro-1"), models.ErrConflict)
+
+	now.Advance(30 * 24 * time.Hour)
+	intro, outro, err = svc.Stitching(ctx, "tenant-1", transcode.Vertical1080p)
+	require.NoError(t, err)
+	assert.NotNil(t, intro)
+	assert.Nil(t, outro, "assets whose license expired are left out")
+}
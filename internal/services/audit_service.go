@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// defaultImpersonationDuration is the session length when an admin does not ask for one
+const defaultImpersonationDuration = 30 * time.Minute
+
+// auditService implements the AuditService interface
+type auditService struct {
+	logs   models.AuditLogRepository
+	logger *logger.Logger
+}
+
+var _ AuditService = (*auditService)(nil)
+
+// NewAuditService creates a new audit service instance
+func NewAuditService(logs models.AuditLogRepository, logger *logger.Logger) AuditService {
+	return &auditService{
+		logs:   logs,
+		logger: logger,
+	}
+}
+
+// Record stores an audit entry
+func (s *auditService) Record(ctx context.Context, entry *models.AuditLog) error {
+	if err := s.logs.Create(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit log",
+			"error", err,
+			"tenant_id", entry.TenantID,
+			"action", entry.Action,
+			"impersonation_id", entry.ImpersonationID)
+		return fmt.Errorf("failed to record audit log: %w", err)
+	}
+	return nil
+}
+
+// List returns the tenant's audit entries, newest first
+func (s *auditService) List(ctx context.Context, tenantID string, impersonatedOnly bool, limit, offset int) ([]*models.AuditLog, error) {
+	return s.logs.List(ctx, tenantID, impersonatedOnly, limit, offset)
+}
+
+// impersonationService implements the ImpersonationService interface
+type impersonationService struct {
+	users           models.UserRepository
+	audit           AuditService
+	supportTenantID string
+	maxDuration     time.Duration
+	clock           clock.Clock
+	logger          *logger.Logger
+}
+
+var _ ImpersonationService = (*impersonationService)(nil)
+
+// NewImpersonationService creates a new impersonation service instance. Admins
+// of supportTenantID may impersonate users of any tenant, other admins only
+// users of their own tenant; an empty supportTenantID disables cross-tenant
+// impersonation.
+func NewImpersonationService(users models.UserRepository, audit AuditService, supportTenantID string, maxDuration time.Duration, clock clock.Clock, logger *logger.Logger) ImpersonationService {
+	return &impersonationService{
+		users:           users,
+		audit:           audit,
+		supportTenantID: supportTenantID,
+		maxDuration:     maxDuration,
+		clock:           clock,
+		logger:          logger,
+	}
+}
+
+// Start grants an impersonation session. It fails when the session cannot be
+// recorded, so no session exists without its audit entry.
+func (s *impersonationService) Start(ctx context.Context, admin *models.User, req *models.ImpersonateRequest, requestID string) (*Impersonation, error) {
+	if models.UserRole(admin.Role) != models.RoleAdmin {
+		return nil, i18n.Errorf(models.ErrForbidden, "only admins can impersonate users")
+	}
+	if req.TenantID != admin.TenantID && (s.supportTenantID == "" || admin.TenantID != s.supportTenantID) {
+		return nil, i18n.Errorf(models.ErrForbidden, "only support admins can impersonate users of another tenant")
+	}
+	if req.TenantID == admin.TenantID && req.UserID == admin.ID {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "admins cannot impersonate themselves")
+	}
+
+	duration := defaultImpersonationDuration
+	if req.DurationMinutes > 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	if duration > s.maxDuration {
+		if req.DurationMinutes > 0 {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "impersonation cannot last more than %s", s.maxDuration)
+		}
+		duration = s.maxDuration
+	}
+
+	user, err := s.users.GetByID(ctx, req.TenantID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if models.UserStatus(user.Status) != models.StatusActive {
+		return nil, models.ErrUserInactive
+	}
+
+	impersonation := &Impersonation{
+		ID:        id.New(),
+		Admin:     admin,
+		User:      user,
+		Reason:    req.Reason,
+		ExpiresAt: s.clock.Now().Add(duration),
+	}
+	err = s.audit.Record(ctx, &models.AuditLog{
+		TenantID:        user.TenantID,
+		UserID:          user.ID,
+		Action:          models.AuditActionImpersonationStart,
+		RequestID:       requestID,
+		Detail:          req.Reason,
+		Impersonated:    true,
+		ImpersonatorID:  admin.ID,
+		ImpersonationID: impersonation.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Warn("Admin impersonation started",
+		"impersonation_id", impersonation.ID,
+		"admin_id", admin.ID,
+		"admin_tenant_id", admin.TenantID,
+		"tenant_id", user.TenantID,
+		"user_id", user.ID,
+		"expires_at", impersonation.ExpiresAt)
+	return impersonation, nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memoryUserRepo serves users by tenant and ID
+type memoryUserRepo struct {
+	models.UserRepository
+	users []*models.User
+}
+
+func (r *memoryUserRepo) GetByID(ctx context.Context, tenantID, id string) (*models.User, error) {
+	for _, u := range r.users {
+		if u.TenantID == tenantID && u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, models.ErrUserNotFound
+}
+
+// memoryAuditRepo keeps audit entries in memory
+type memoryAuditRepo struct {
+	entries []*models.AuditLog
+}
+
+func (r *memoryAuditRepo) Create(ctx context.Context, entry *models.AuditLog) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *memoryAuditRepo) List(ctx context.Context, tenantID string, impersonatedOnly bool, limit, offset int) ([]*models.AuditLog, error) {
+	return r.entries, nil
+}
+
+func (r *memoryAuditRepo) ListActivity(ctx context.Context, tenantID string, scope *models.ActivityScope) ([]*models.AuditLog, error) {
+	var entries []*models.AuditLog
+	for _, entry := range r.entries {
+		for _, videoID := range scope.VideoIDs {
+			if strings.HasPrefix(entry.Resource, models.VideoPath(videoID)) && entry.CreatedAt.Before(scope.Before) {
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	return entries, nil
+}
+
+// impersonationNow is when the impersonation tests start sessions
+var impersonationNow = time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+func newTestImpersonationService(supportTenantID string) (ImpersonationService, *memoryAuditRepo) {
+	log := logger.New("error", "test")
+	users := &memoryUserRepo{users: []*models.User{
+		{ID: "support-admin", TenantID: "support", Role: "admin", Status: "active"},
+		{ID: "acme-admin", TenantID: "acme", Role: "admin", Status: "active"},
+		{ID: "acme-editor", TenantID: "acme", Role: "editor", Status: "active"},
+		{ID: "acme-former", TenantID: "acme", Role: "editor", Status: "inactive"},
+		{ID: "globex-editor", TenantID: "globex", Role: "editor", Status: "active"},
+	}}
+	audit := &memoryAuditRepo{}
+	return NewImpersonationService(users, NewAuditService(audit, log), supportTenantID, time.Hour, clock.NewFake(impersonationNow), log), audit
+}
+
+func TestImpersonationService_Start(t *testing.T) {
+	svc, audit := newTestImpersonationService("support")
+	supportAdmin := &models.User{ID: "support-admin", TenantID: "support", Role: "admin"}
+
+	session, err := svc.Start(context.Background(), supportAdmin, &models.ImpersonateRequest{
+		TenantID: "acme",
+		UserID:   "acme-editor",
+		Reason:   "Ticket 4521: publish button fails",
+	}, "req-1")
+	require.NoError(t, err)
+	assert.Equal(t, "acme-editor", session.User.ID)
+	assert.Equal(t, impersonationNow.Add(defaultImpersonationDuration), session.ExpiresAt)
+
+	require.Len(t, audit.entries, 1, "the session starts with an entry in the tenant's audit log")
+	entry := audit.entries[0]
+	assert.Equal(t, "acme", entry.TenantID)
+	assert.Equal(t, models.AuditActionImpersonationStart, entry.Action)
+	assert.True(t, entry.Impersonated)
+	assert.Equal(t, "support-admin", entry.ImpersonatorID)
+	assert.Equal(t, session.ID, entry.ImpersonationID)
+	assert.Equal(t, "Ticket 4521: publish button fails", entry.Detail)
+}
+
+func TestImpersonationService_StartRejected(t *testing.T) {
+	svc, audit := newTestImpersonationService("support")
+	supportAdmin := &models.User{ID: "support-admin", TenantID: "support", Role: "admin"}
+	acmeAdmin := &models.User{ID: "acme-admin", TenantID: "acme", Role: "admin"}
+
+	tests := []struct {
+		name  string
+		admin *models.User
+		req   models.ImpersonateRequest
+		err   error
+	}{
+		{name: "not an admin", admin: &models.User{ID: "acme-editor", TenantID: "acme", Role: "editor"}, req: models.ImpersonateRequest{TenantID: "acme", UserID: "acme-admin"}, err: models.ErrForbidden},
+		{name: "tenant admin in another tenant", admin: acmeAdmin, req: models.ImpersonateRequest{TenantID: "globex", UserID: "globex-editor"}, err: models.ErrForbidden},
+		{name: "themselves", admin: acmeAdmin, req: models.ImpersonateRequest{TenantID: "acme", UserID: "acme-admin"}, err: models.ErrInvalidInput},
+		{name: "longer than the maximum", admin: supportAdmin, req: models.ImpersonateRequest{TenantID: "acme", UserID: "acme-editor", DurationMinutes: 61}, err: models.ErrInvalidInput},
+		{name: "unknown user", admin: supportAdmin, req: models.ImpersonateRequest{TenantID: "acme", UserID: "globex-editor"}, err: models.ErrUserNotFound},
+		{name: "inactive user", admin: supportAdmin, req: models.ImpersonateRequest{TenantID: "acme", UserID: "acme-former"}, err: models.ErrUserInactive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Start(context.Background(), tt.admin, &tt.req, "")
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+	assert.Empty(t, audit.entries)
+
+	_, err := svc.Start(context.Background(), acmeAdmin, &models.ImpersonateRequest{TenantID: "acme", UserID: "acme-editor"}, "")
+	assert.NoError(t, err, "tenant admins impersonate users of their own tenant")
+
+	noSupport, _ := newTestImpersonationService("")
+	_, err = noSupport.Start(context.Background(), supportAdmin, &models.ImpersonateRequest{TenantID: "acme", UserID: "acme-editor"}, "")
+	assert.ErrorIs(t, err, models.ErrForbidden, "cross-tenant impersonation is off without a support tenant")
+}
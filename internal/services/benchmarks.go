@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// defaultBenchmarks are typical engagement rates, likes, comments and shares
+// over views, from public industry reports. Keys are a platform or a
+// platform/vertical pair.
+var defaultBenchmarks = map[string]float64{
+	"youtube":               0.035,
+	"youtube/gaming":        0.045,
+	"youtube/education":     0.03,
+	"youtube/entertainment": 0.04,
+	"tiktok":                0.06,
+	"tiktok/gaming":         0.07,
+	"tiktok/education":      0.05,
+	"tiktok/entertainment":  0.065,
+	"instagram":             0.025,
+	"instagram/gaming":      0.03,
+	"facebook":              0.01,
+	"twitter":               0.005,
+	"linkedin":              0.02,
+	"snapchat":              0.015,
+}
+
+// Benchmarks holds the engagement rate analytics are compared with, per
+// platform and optionally per vertical on a platform
+type Benchmarks struct {
+	rates map[string]float64
+}
+
+// NewBenchmarks builds the benchmarks from the defaults and configuration.
+//
+// overrides sets rates as "platform=rate" or "platform/vertical=rate", e.g.
+// "youtube=0.04;tiktok/gaming=0.08". A rate is a fraction of views.
+func NewBenchmarks(overrides string) (*Benchmarks, error) {
+	b := &Benchmarks{rates: make(map[string]float64, len(defaultBenchmarks))}
+	for key, rate := range defaultBenchmarks {
+		b.rates[key] = rate
+	}
+
+	for _, entry := range strings.Split(overrides, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		platform, vertical, _ := strings.Cut(strings.TrimSpace(key), "/")
+		if !ok || !models.Platform(platform).Valid() {
+			return nil, fmt.Errorf("invalid engagement benchmark entry: %q", entry)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid engagement benchmark entry: %q", entry)
+		}
+		b.rates[benchmarkKey(platform, strings.TrimSpace(vertical))] = rate
+	}
+	return b, nil
+}
+
+// rate returns the benchmark of the platform for the vertical, falling back
+// to the platform's, and the vertical it is specific to
+func (b *Benchmarks) rate(platform, vertical string) (float64, string, bool) {
+	if vertical != "" {
+		if rate, ok := b.rates[benchmarkKey(platform, vertical)]; ok {
+			return rate, vertical, true
+		}
+	}
+	rate, ok := b.rates[platform]
+	return rate, "", ok
+}
+
+// Compare puts an engagement rate next to the platform's benchmark, nil when
+// the platform has none
+func (b *Benchmarks) Compare(platform, vertical string, rate float64) *BenchmarkComparison {
+	benchmark, matched, ok := b.rate(platform, vertical)
+	if !ok {
+		return nil
+	}
+	return newBenchmarkComparison(matched, benchmark, rate)
+}
+
+// CompareOverall puts the engagement rate of all platforms next to their
+// benchmarks weighted by the views on each, nil without views on a
+// benchmarked platform
+func (b *Benchmarks) CompareOverall(vertical string, totals []*models.PlatformTotals, rate float64) *BenchmarkComparison {
+	var weighted float64
+	var views int64
+	matched := ""
+	for _, t := range totals {
+		benchmark, v, ok := b.rate(t.Platform, vertical)
+		if !ok || t.TotalViews == 0 {
+			continue
+		}
+		weighted += benchmark * float64(t.TotalViews)
+		views += t.TotalViews
+		if v != "" {
+			matched = v
+		}
+	}
+	if views == 0 {
+		return nil
+	}
+	return newBenchmarkComparison(matched, weighted/float64(views), rate)
+}
+
+func newBenchmarkComparison(vertical string, benchmark, rate float64) *BenchmarkComparison {
+	return &BenchmarkComparison{
+		Vertical:       vertical,
+		EngagementRate: math.Round(benchmark*10000) / 10000,
+		Difference:     math.Round((rate-benchmark)*10000) / 10000,
+		Index:          math.Round(rate/benchmark*1000) / 10,
+	}
+}
+
+func benchmarkKey(platform, vertical string) string {
+	if vertical == "" {
+		return platform
+	}
+	return platform + "/" + strings.ToLower(vertical)
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestNewBenchmarks(t *testing.T) {
+	b, err := NewBenchmarks(" youtube = 0.05 ; tiktok/Cooking=0.08;")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.05, b.Compare("youtube", "", 0.05).EngagementRate)
+	assert.Equal(t, &BenchmarkComparison{Vertical: "cooking", EngagementRate: 0.08, Difference: 0.02, Index: 125},
+		b.Compare("tiktok", "cooking", 0.1), "verticals match regardless of case")
+	assert.Equal(t, &BenchmarkComparison{EngagementRate: 0.06, Difference: -0.03, Index: 50},
+		b.Compare("tiktok", "sports", 0.03), "an unknown vertical falls back to the platform")
+	assert.Equal(t, 0.045, b.Compare("youtube", "gaming", 0).EngagementRate, "defaults are kept")
+	assert.Nil(t, b.Compare("myspace", "", 0.1))
+
+	for _, overrides := range []string{"youtube", "myspace=0.1", "youtube=high", "youtube=0", "youtube=1.5"} {
+		_, err := NewBenchmarks(overrides)
+		assert.Error(t, err, overrides)
+	}
+}
+
+func TestBenchmarks_CompareOverallWeighsViews(t *testing.T) {
+	b, err := NewBenchmarks("")
+	require.NoError(t, err)
+
+	totals := []*models.PlatformTotals{
+		{Platform: "youtube", TotalViews: 3000},
+		{Platform: "tiktok", TotalViews: 1000},
+		{Platform: "instagram"},
+	}
+	// (0.035*3000 + 0.06*1000) / 4000
+	assert.Equal(t, 0.0413, b.CompareOverall("", totals, 0.05).EngagementRate)
+	assert.Nil(t, b.CompareOverall("", totals[2:], 0), "no views, no comparison")
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// blackoutService implements the BlackoutService interface
+type blackoutService struct {
+	windows models.BlackoutWindowRepository
+	jobs    models.PublicationJobRepository
+	notify  NotificationService
+	clock   clock.Clock
+	logger  *logger.Logger
+}
+
+var _ BlackoutService = (*blackoutService)(nil)
+
+// NewBlackoutService creates a new blackout service instance
+func NewBlackoutService(windows models.BlackoutWindowRepository, jobs models.PublicationJobRepository, notify NotificationService, clock clock.Clock, logger *logger.Logger) BlackoutService {
+	return &blackoutService{
+		windows: windows,
+		jobs:    jobs,
+		notify:  notify,
+		clock:   clock,
+		logger:  logger,
+	}
+}
+
+// List returns the tenant's blackout windows
+func (s *blackoutService) List(ctx context.Context, tenantID string) ([]*models.BlackoutWindow, error) {
+	windows, err := s.windows.List(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blackout windows: %w", err)
+	}
+	return windows, nil
+}
+
+// Create adds a blackout window to the tenant
+func (s *blackoutService) Create(ctx context.Context, tenantID, userID string, req *models.BlackoutWindowRequest) (*models.BlackoutWindow, error) {
+	action := req.Action
+	if action == "" {
+		action = models.BlackoutShift
+	}
+	window := &models.BlackoutWindow{
+		TenantID:  tenantID,
+		Name:      req.Name,
+		StartsAt:  req.StartsAt.UTC(),
+		EndsAt:    req.EndsAt.UTC(),
+		Yearly:    req.Yearly,
+		Action:    string(action),
+		CreatedBy: userID,
+	}
+	if err := window.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.windows.Create(ctx, window); err != nil {
+		return nil, fmt.Errorf("failed to create blackout window: %w", err)
+	}
+
+	s.logger.Info("Blackout window created", "tenant_id", tenantID, "user_id", userID, "blackout_window_id", window.ID,
+		"starts_at", window.StartsAt, "ends_at", window.EndsAt, "yearly", window.Yearly, "action", window.Action)
+	return window, nil
+}
+
+// Delete removes a blackout window of the tenant
+func (s *blackoutService) Delete(ctx context.Context, tenantID, id string) error {
+	if err := s.windows.Delete(ctx, tenantID, id); err != nil {
+		return err
+	}
+	s.logger.Info("Blackout window deleted", "tenant_id", tenantID, "blackout_window_id", id)
+	return nil
+}
+
+// Apply moves a publication out of the window covering at. A window ending
+// inside another is followed to the end of that one, and the publication is
+// held if any of them holds.
+func (s *blackoutService) Apply(ctx context.Context, job *models.PublicationJob, at time.Time) (bool, error) {
+	if job.BlackoutWindowID != "" {
+		return false, nil
+	}
+	windows, err := s.windows.List(ctx, job.TenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to list blackout windows: %w", err)
+	}
+
+	var first *models.BlackoutWindow
+	action := models.BlackoutShift
+	until := at
+	// Each window is passed at most once
+	for range windows {
+		window, endsAt := coveringWindow(windows, until)
+		if window == nil {
+			break
+		}
+		if first == nil {
+			first = window
+		}
+		if models.BlackoutAction(window.Action) == models.BlackoutHold {
+			action = models.BlackoutHold
+		}
+		until = endsAt
+	}
+	if first == nil {
+		return false, nil
+	}
+
+	now := s.clock.Now()
+	job.BlackoutWindowID = first.ID
+	job.UpdatedAt = now
+	if action == models.BlackoutHold {
+		job.Status = string(models.PublicationAwaitingApproval)
+	} else {
+		job.ScheduledAt.Time = until
+	}
+	if err := s.jobs.Update(context.WithoutCancel(ctx), job); err != nil {
+		return false, fmt.Errorf("failed to save publication moved by blackout window: %w", err)
+	}
+
+	s.logger.Info("Publication in blackout window", "tenant_id", job.TenantID, "publication_id", job.ID,
+		"blackout_window_id", first.ID, "action", action, "scheduled_at", job.ScheduledAt.Time)
+	subject := i18n.M("Publication shifted")
+	message := i18n.M("Your publication of video %s on %s falls in the blackout window %s and was moved to %s",
+		job.VideoID, job.Platform, first.Name, until.UTC().Format("2006-01-02 15:04 MST"))
+	if action == models.BlackoutHold {
+		subject = i18n.M("Publication held")
+		message = i18n.M("Your publication of video %s on %s falls in the blackout window %s and awaits approval",
+			job.VideoID, job.Platform, first.Name)
+	}
+	if err := s.notify.Notify(ctx, job.TenantID, job.UserID, subject, message); err != nil {
+		s.logger.Error("Failed to notify publication requester", "error", err, "publication_id", job.ID, "user_id", job.UserID)
+	}
+	return true, nil
+}
+
+// coveringWindow returns the first window covering t, with when it ends
+func coveringWindow(windows []*models.BlackoutWindow, t time.Time) (*models.BlackoutWindow, time.Time) {
+	for _, window := range windows {
+		if endsAt, ok := window.Covers(t); ok {
+			return window, endsAt
+		}
+	}
+	return nil, time.Time{}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memoryBlackoutRepo keeps blackout windows in creation order
+type memoryBlackoutRepo struct {
+	windows []*models.BlackoutWindow
+}
+
+func (r *memoryBlackoutRepo) Create(ctx context.Context, window *models.BlackoutWindow) error {
+	window.ID = window.Name
+	r.windows = append(r.windows, window)
+	return nil
+}
+
+func (r *memoryBlackoutRepo) List(ctx context.Context, tenantID string) ([]*models.BlackoutWindow, error) {
+	var windows []*models.BlackoutWindow
+	for _, window := range r.windows {
+		if window.TenantID == tenantID {
+			windows = append(windows, window)
+		}
+	}
+	return windows, nil
+}
+
+func (r *memoryBlackoutRepo) Delete(ctx context.Context, tenantID, id string) error {
+	return models.ErrBlackoutWindowNotFound
+}
+
+func newTestBlackoutService(now time.Time) (BlackoutService, *memoryBlackoutRepo, *recordingNotifications) {
+	windows := &memoryBlackoutRepo{}
+	notified := &recordingNotifications{subjects: map[string][]string{}}
+	svc := NewBlackoutService(windows, &memoryPublicationRepo{}, notified, clock.NewFake(now), logger.New("error", "test"))
+	return svc, windows, notified
+}
+
+func TestBlackoutService_Create(t *testing.T) {
+	svc, _, _ := newTestBlackoutService(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	christmas := time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC)
+
+	window, err := svc.Create(ctx, "acme", "admin", &models.BlackoutWindowRequest{
+		Name: "Christmas", StartsAt: christmas, EndsAt: christmas.AddDate(0, 0, 1), Yearly: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, string(models.BlackoutShift), window.Action, "shifted by default")
+
+	for name, req := range map[string]*models.BlackoutWindowRequest{
+		"backwards":      {Name: "x", StartsAt: christmas, EndsAt: christmas.Add(-time.Hour)},
+		"unknown action": {Name: "x", StartsAt: christmas, EndsAt: christmas.Add(time.Hour), Action: "skip"},
+		"over a year":    {Name: "x", StartsAt: christmas, EndsAt: christmas.AddDate(1, 0, 1), Yearly: true},
+	} {
+		_, err := svc.Create(ctx, "acme", "admin", req)
+		assert.ErrorIs(t, err, models.ErrInvalidInput, name)
+	}
+}
+
+func TestBlackoutService_Apply(t *testing.T) {
+	now := time.Date(2027, 1, 1, 9, 0, 0, 0, time.UTC)
+	svc, windows, notified := newTestBlackoutService(now)
+	ctx := context.Background()
+	// Set up in 2025, over New Year and into a one-off embargo
+	windows.windows = []*models.BlackoutWindow{
+		{ID: "new-year", TenantID: "acme", Name: "New Year", Yearly: true, Action: "shift",
+			StartsAt: time.Date(2025, 12, 31, 18, 0, 0, 0, time.UTC), EndsAt: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{ID: "embargo", TenantID: "acme", Name: "Embargo", Action: "shift",
+			StartsAt: time.Date(2027, 1, 1, 11, 0, 0, 0, time.UTC), EndsAt: time.Date(2027, 1, 1, 15, 0, 0, 0, time.UTC)},
+	}
+	job := &models.PublicationJob{ID: "pub-1", TenantID: "acme", UserID: "editor", VideoID: "video-1", Platform: "youtube",
+		Status: string(models.PublicationScheduled), ScheduledAt: sql.NullTime{Time: now, Valid: true}}
+
+	moved, err := svc.Apply(ctx, job, now)
+	require.NoError(t, err)
+	assert.True(t, moved)
+	assert.Equal(t, time.Date(2027, 1, 1, 15, 0, 0, 0, time.UTC), job.ScheduledAt.Time, "past both windows")
+	assert.Equal(t, "new-year", job.BlackoutWindowID)
+	assert.Equal(t, []string{"Publication shifted"}, notified.subjects["editor"])
+
+	moved, err = svc.Apply(ctx, job, now)
+	require.NoError(t, err)
+	assert.False(t, moved, "not moved twice")
+
+	// A window holding anywhere along the way holds the publication
+	windows.windows[1].Action = "hold"
+	job = &models.PublicationJob{ID: "pub-2", TenantID: "acme", UserID: "editor", Status: string(models.PublicationStaged),
+		ScheduledAt: sql.NullTime{Time: now, Valid: true}}
+	moved, err = svc.Apply(ctx, job, now)
+	require.NoError(t, err)
+	assert.True(t, moved)
+	assert.Equal(t, string(models.PublicationAwaitingApproval), job.Status)
+	assert.Equal(t, now, job.ScheduledAt.Time)
+
+	moved, err = svc.Apply(ctx, &models.PublicationJob{TenantID: "acme"}, now.Add(6*time.Hour))
+	require.NoError(t, err)
+	assert.False(t, moved)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/notify"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// brandService implements the BrandService interface
+type brandService struct {
+	profiles models.BrandProfileRepository
+	tenants  models.TenantRepository
+	logger   *logger.Logger
+}
+
+var _ BrandService = (*brandService)(nil)
+
+// NewBrandService creates a new brand service
+func NewBrandService(profiles models.BrandProfileRepository, tenants models.TenantRepository, logger *logger.Logger) BrandService {
+	return &brandService{profiles: profiles, tenants: tenants, logger: logger}
+}
+
+// Get returns the tenant's profile, or the default brand marked as such
+func (s *brandService) Get(ctx context.Context, tenantID string) (*models.BrandProfile, error) {
+	profile, err := s.profiles.Get(ctx, tenantID)
+	if errors.Is(err, models.ErrBrandProfileNotFound) {
+		return &models.BrandProfile{
+			TenantID:    tenantID,
+			Font:        models.DefaultBrand.Font,
+			TextColor:   models.DefaultBrand.TextColor,
+			AccentColor: models.DefaultBrand.AccentColor,
+			Default:     true,
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get brand profile: %w", err)
+	}
+	return profile, nil
+}
+
+// Put sets the tenant's profile, used by the thumbnails composed and the
+// emails sent from then on
+func (s *brandService) Put(ctx context.Context, tenantID, userID string, req *models.BrandProfileRequest) (*models.BrandProfile, error) {
+	profile := &models.BrandProfile{
+		TenantID:    tenantID,
+		Font:        req.Font,
+		TextColor:   req.TextColor,
+		AccentColor: req.AccentColor,
+		LogoURL:     req.LogoURL,
+		EmailFooter: strings.TrimSpace(req.EmailFooter),
+		UpdatedBy:   userID,
+	}
+	if err := profile.Brand().Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidInput, err)
+	}
+	// Mail clients block images served over plain HTTP
+	if profile.LogoURL != "" && !strings.HasPrefix(profile.LogoURL, "https://") {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the logo URL must start with https://")
+	}
+	if err := s.profiles.Upsert(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to save brand profile: %w", err)
+	}
+	s.logger.Info("Brand profile updated", "tenant_id", tenantID, "user_id", userID, "font", profile.Font)
+	return profile, nil
+}
+
+// Brand returns what the tenant's thumbnails are rendered with
+func (s *brandService) Brand(ctx context.Context, tenantID string) (transcode.Brand, error) {
+	profile, err := s.Get(ctx, tenantID)
+	if err != nil {
+		return transcode.Brand{}, err
+	}
+	return profile.Brand(), nil
+}
+
+// EmailBranding returns what the tenant's emails are styled with, named after
+// the tenant
+func (s *brandService) EmailBranding(ctx context.Context, tenantID string) (notify.Branding, error) {
+	profile, err := s.Get(ctx, tenantID)
+	if err != nil {
+		return notify.Branding{}, err
+	}
+	tenant, err := s.tenants.GetByID(ctx, tenantID)
+	if err != nil {
+		return notify.Branding{}, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return notify.Branding{
+		Name:        tenant.Name,
+		LogoURL:     profile.LogoURL,
+		AccentColor: profile.AccentColor,
+		TextColor:   profile.TextColor,
+		Footer:      profile.EmailFooter,
+	}, nil
+}
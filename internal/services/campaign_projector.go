@@ -0,0 +1,101 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// projectCampaign rebuilds a campaign by applying its events in order
+func projectCampaign(events []*models.CampaignEvent) (*models.Campaign, error) {
+	campaign := &models.Campaign{}
+	for _, event := range events {
+		if err := applyCampaignEvent(campaign, event); err != nil {
+			return nil, err
+		}
+	}
+	return campaign, nil
+}
+
+// applyCampaignEvent changes the campaign as the event says. It is the only
+// place campaign state changes, so the stored campaign and the replay of its
+// events agree.
+func applyCampaignEvent(campaign *models.Campaign, event *models.CampaignEvent) error {
+	if event.Sequence != campaign.Version+1 {
+		return fmt.Errorf("campaign event %d follows version %d", event.Sequence, campaign.Version)
+	}
+	if (event.Type == models.CampaignEventCreated) != (campaign.ID == "") {
+		return fmt.Errorf("campaign event %d (%s) does not fit the campaign's history", event.Sequence, event.Type)
+	}
+
+	switch event.Type {
+	case models.CampaignEventCreated:
+		if event.Data.Campaign == nil {
+			return fmt.Errorf("campaign event %d (%s) has no campaign", event.Sequence, event.Type)
+		}
+		*campaign = *event.Data.Campaign
+		campaign.ID = event.CampaignID
+		campaign.TenantID = event.TenantID
+		campaign.CreatedAt = event.OccurredAt
+	case models.CampaignEventUpdated:
+		definition := event.Data.Campaign
+		if definition == nil {
+			return fmt.Errorf("campaign event %d (%s) has no campaign", event.Sequence, event.Type)
+		}
+		campaign.Name = definition.Name
+		campaign.Goal = definition.Goal
+		campaign.Context = definition.Context
+		campaign.Theme = definition.Theme
+		campaign.Platforms = definition.Platforms
+		campaign.Language = definition.Language
+		campaign.Schedule = definition.Schedule
+		campaign.Budget = definition.Budget
+		campaign.MaxVideos = definition.MaxVideos
+	case models.CampaignEventScheduled:
+		campaign.Schedule = event.Data.Schedule
+		campaign.Status = models.CampaignStatusScheduled
+	case models.CampaignEventStarted:
+		campaign.Status = models.CampaignStatusRunning
+		startedAt := event.OccurredAt
+		campaign.StartedAt = &startedAt
+	case models.CampaignEventStepCompleted:
+		switch event.Data.Step {
+		case models.CampaignStepResearch:
+			campaign.Progress.ResearchDone = true
+			campaign.Progress.CurrentStep = models.CampaignStepIdeation
+		case models.CampaignStepIdeation:
+			campaign.Progress.IdeationDone = true
+			campaign.Progress.CurrentStep = models.CampaignStepValidation
+		case models.CampaignStepValidation:
+			campaign.Progress.ValidationDone = true
+			campaign.Progress.CurrentStep = models.CampaignStepExecution
+		default:
+			return fmt.Errorf("campaign event %d completes unknown step %q", event.Sequence, event.Data.Step)
+		}
+		campaign.Progress.TotalCost += event.Data.Cost
+	case models.CampaignEventBudgetExceeded, models.CampaignEventPaused:
+		campaign.Status = models.CampaignStatusPaused
+	case models.CampaignEventResumed:
+		campaign.Status = models.CampaignStatusRunning
+	case models.CampaignEventStopped:
+		campaign.Status = models.CampaignStatusCompleted
+		completedAt := event.OccurredAt
+		campaign.CompletedAt = &completedAt
+	case models.CampaignEventRunRecorded:
+		if campaign.Schedule != nil {
+			schedule := *campaign.Schedule
+			ranAt := event.OccurredAt
+			schedule.RunCount++
+			schedule.LastRunAt = &ranAt
+			schedule.NextRunAt = event.Data.NextRunAt
+			campaign.Schedule = &schedule
+		}
+	case models.CampaignEventDeleted:
+	default:
+		return fmt.Errorf("campaign event %d has unknown type %q", event.Sequence, event.Type)
+	}
+
+	campaign.Version = event.Sequence
+	campaign.UpdatedAt = event.OccurredAt
+	return nil
+}
@@ -5,23 +5,35 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
 )
 
 // campaignService implements the CampaignService interface
 type campaignService struct {
-	logger *logger.Logger
+	campaigns models.CampaignRepository
+	ai        AIService
+	clock     clock.Clock
+	logger    *logger.Logger
 }
 
+var _ CampaignService = (*campaignService)(nil)
+
 // NewCampaignService creates a new campaign service instance
-func NewCampaignService(logger *logger.Logger) CampaignService {
+func NewCampaignService(campaigns models.CampaignRepository, ai AIService, clock clock.Clock, logger *logger.Logger) CampaignService {
 	return &campaignService{
-		logger: logger,
+		campaigns: campaigns,
+		ai:        ai,
+		clock:     clock,
+		logger:    logger,
 	}
 }
 
 // CreateCampaign creates a new AI campaign
-func (s *campaignService) CreateCampaign(ctx context.Context, tenantID, userID string, req *CreateCampaignRequest) (*Campaign, error) {
+func (s *campaignService) CreateCampaign(ctx context.Context, tenantID, userID string, req *CreateCampaignRequest) (*models.Campaign, error) {
 	s.logger.Info("Creating campaign", "tenant_id", tenantID, "user_id", userID, "name", req.Name)
 
 	// Validate request
@@ -38,9 +50,9 @@ func (s *campaignService) CreateCampaign(ctx context.Context, tenantID, userID s
 		return nil, fmt.Errorf("language is required")
 	}
 
-	// Create campaign entity
-	campaign := &Campaign{
-		ID:        generateCampaignID(),
+	// The created event carries the campaign's initial state
+	initial := &models.Campaign{
+		ID:        id.New(),
 		TenantID:  tenantID,
 		UserID:    userID,
 		Name:      req.Name,
@@ -49,11 +61,11 @@ func (s *campaignService) CreateCampaign(ctx context.Context, tenantID, userID s
 		Theme:     req.Theme,
 		Platforms: req.Platforms,
 		Language:  req.Language,
-		Status:    CampaignStatusDraft,
+		Status:    models.CampaignStatusDraft,
 		Budget:    req.Budget,
 		MaxVideos: req.MaxVideos,
-		Progress: CampaignProgress{
-			CurrentStep:     CampaignStepResearch,
+		Progress: models.CampaignProgress{
+			CurrentStep:     models.CampaignStepResearch,
 			ResearchDone:    false,
 			IdeationDone:    false,
 			ValidationDone:  false,
@@ -61,63 +73,48 @@ func (s *campaignService) CreateCampaign(ctx context.Context, tenantID, userID s
 			VideosPublished: 0,
 			TotalCost:       0,
 		},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: s.clock.Now(),
+		UpdatedAt: s.clock.Now(),
 	}
 
 	// Set defaults
-	if campaign.MaxVideos == 0 {
-		campaign.MaxVideos = 10 // Default max videos
+	if initial.MaxVideos == 0 {
+		initial.MaxVideos = 10 // Default max videos
 	}
 
 	// Set scheduling if provided
 	if req.Schedule != nil {
-		campaign.Schedule = req.Schedule
-		campaign.Status = CampaignStatusScheduled
+		initial.Schedule = req.Schedule
+		initial.Status = models.CampaignStatusScheduled
 
 		// Calculate next run time
-		nextRun := calculateNextRunTime(req.Schedule)
+		nextRun := calculateNextRunTime(req.Schedule, s.clock.Now())
 		if nextRun != nil {
-			campaign.Schedule.NextRunAt = nextRun
+			initial.Schedule.NextRunAt = nextRun
 		}
 	}
 
-	// TODO: Save to repository
-	// For now, just log the creation
+	campaign := &models.Campaign{}
+	event, err := s.newEvent(campaign, initial.TenantID, initial.ID, models.CampaignEventCreated, models.CampaignEventData{Campaign: initial})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.campaigns.Create(ctx, campaign, event); err != nil {
+		s.logger.Error("Failed to create campaign", "error", err, "tenant_id", tenantID)
+		return nil, fmt.Errorf("failed to create campaign: %w", err)
+	}
+
 	s.logger.Info("Campaign created successfully", "campaign_id", campaign.ID, "tenant_id", tenantID)
 	return campaign, nil
 }
 
 // GetCampaign retrieves a campaign by ID
-func (s *campaignService) GetCampaign(ctx context.Context, tenantID, campaignID string) (*Campaign, error) {
+func (s *campaignService) GetCampaign(ctx context.Context, tenantID, campaignID string) (*models.Campaign, error) {
 	s.logger.Debug("Getting campaign", "campaign_id", campaignID, "tenant_id", tenantID)
 
-	// TODO: Implement actual campaign retrieval from repository
-	// For now, return mock campaign
-	campaign := &Campaign{
-		ID:        campaignID,
-		TenantID:  tenantID,
-		UserID:    "user_123",
-		Name:      "Mock Campaign",
-		Goal:      "Generate engaging video content for social media",
-		Context:   map[string]interface{}{"industry": "technology", "target_audience": "developers"},
-		Theme:     "Educational Tech Content",
-		Platforms: []string{"youtube", "tiktok"},
-		Language:  "en",
-		Status:    CampaignStatusDraft,
-		Budget:    1000.0,
-		MaxVideos: 10,
-		Progress: CampaignProgress{
-			CurrentStep:     CampaignStepResearch,
-			ResearchDone:    false,
-			IdeationDone:    false,
-			ValidationDone:  false,
-			VideosCreated:   0,
-			VideosPublished: 0,
-			TotalCost:       0,
-		},
-		CreatedAt: time.Now().Add(-24 * time.Hour), // Created yesterday
-		UpdatedAt: time.Now(),
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get campaign: %w", err)
 	}
 
 	s.logger.Debug("Campaign retrieved", "campaign_id", campaignID, "tenant_id", tenantID)
@@ -125,51 +122,54 @@ func (s *campaignService) GetCampaign(ctx context.Context, tenantID, campaignID
 }
 
 // UpdateCampaign updates an existing campaign
-func (s *campaignService) UpdateCampaign(ctx context.Context, tenantID, campaignID string, req *UpdateCampaignRequest) (*Campaign, error) {
+func (s *campaignService) UpdateCampaign(ctx context.Context, tenantID, campaignID string, req *UpdateCampaignRequest) (*models.Campaign, error) {
 	s.logger.Info("Updating campaign", "campaign_id", campaignID, "tenant_id", tenantID)
 
 	// Get existing campaign
-	campaign, err := s.GetCampaign(ctx, tenantID, campaignID)
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get campaign: %w", err)
 	}
 
-	// Update fields
+	// Update fields of the definition the updated event sets
+	definition := *campaign
 	if req.Name != nil {
-		campaign.Name = *req.Name
+		definition.Name = *req.Name
 	}
 	if req.Goal != nil {
-		campaign.Goal = *req.Goal
+		definition.Goal = *req.Goal
 	}
 	if req.Context != nil {
-		campaign.Context = req.Context
+		definition.Context = req.Context
 	}
 	if req.Theme != nil {
-		campaign.Theme = *req.Theme
+		definition.Theme = *req.Theme
 	}
 	if req.Platforms != nil {
-		campaign.Platforms = req.Platforms
+		definition.Platforms = req.Platforms
 	}
 	if req.Language != nil {
-		campaign.Language = *req.Language
+		definition.Language = *req.Language
 	}
 	if req.Schedule != nil {
-		campaign.Schedule = req.Schedule
+		definition.Schedule = req.Schedule
 		// Recalculate next run time
-		nextRun := calculateNextRunTime(req.Schedule)
+		nextRun := calculateNextRunTime(req.Schedule, s.clock.Now())
 		if nextRun != nil {
-			campaign.Schedule.NextRunAt = nextRun
+			definition.Schedule.NextRunAt = nextRun
 		}
 	}
 	if req.Budget != nil {
-		campaign.Budget = *req.Budget
+		definition.Budget = *req.Budget
 	}
 	if req.MaxVideos != nil {
-		campaign.MaxVideos = *req.MaxVideos
+		definition.MaxVideos = *req.MaxVideos
+	}
+
+	if err := s.record(ctx, campaign, models.CampaignEventUpdated, models.CampaignEventData{Campaign: &definition}); err != nil {
+		return nil, err
 	}
-	campaign.UpdatedAt = time.Now()
 
-	// TODO: Save changes to repository
 	s.logger.Info("Campaign updated successfully", "campaign_id", campaignID, "tenant_id", tenantID)
 	return campaign, nil
 }
@@ -178,76 +178,144 @@ func (s *campaignService) UpdateCampaign(ctx context.Context, tenantID, campaign
 func (s *campaignService) DeleteCampaign(ctx context.Context, tenantID, campaignID string) error {
 	s.logger.Info("Deleting campaign", "campaign_id", campaignID, "tenant_id", tenantID)
 
-	// TODO: Implement actual campaign deletion from repository
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
+	if err != nil {
+		return fmt.Errorf("failed to get campaign: %w", err)
+	}
+	event, err := s.newEvent(campaign, tenantID, campaignID, models.CampaignEventDeleted, models.CampaignEventData{})
+	if err != nil {
+		return err
+	}
+	if err := s.campaigns.Delete(ctx, tenantID, campaignID, event); err != nil {
+		s.logger.Error("Failed to delete campaign", "error", err, "campaign_id", campaignID, "tenant_id", tenantID)
+		return fmt.Errorf("failed to delete campaign: %w", err)
+	}
+
 	s.logger.Info("Campaign deleted successfully", "campaign_id", campaignID, "tenant_id", tenantID)
 	return nil
 }
 
 // ListCampaigns lists campaigns for a tenant
-func (s *campaignService) ListCampaigns(ctx context.Context, tenantID string, limit, offset int) ([]*Campaign, error) {
+func (s *campaignService) ListCampaigns(ctx context.Context, tenantID string, limit, offset int) ([]*models.Campaign, error) {
 	s.logger.Debug("Listing campaigns", "tenant_id", tenantID, "limit", limit, "offset", offset)
 
-	// TODO: Implement actual campaign listing from repository
-	// For now, return mock campaigns
-	campaigns := []*Campaign{
-		{
-			ID:        "campaign_1",
-			TenantID:  tenantID,
-			UserID:    "user_123",
-			Name:      "Tech Tutorial Campaign",
-			Goal:      "Create educational content about programming",
-			Status:    CampaignStatusRunning,
-			Platforms: []string{"youtube", "tiktok"},
-			Language:  "en",
-			CreatedAt: time.Now().Add(-48 * time.Hour),
-			UpdatedAt: time.Now().Add(-1 * time.Hour),
-		},
-		{
-			ID:        "campaign_2",
-			TenantID:  tenantID,
-			UserID:    "user_123",
-			Name:      "Product Review Series",
-			Goal:      "Review latest tech products",
-			Status:    CampaignStatusScheduled,
-			Platforms: []string{"youtube"},
-			Language:  "en",
-			CreatedAt: time.Now().Add(-24 * time.Hour),
-			UpdatedAt: time.Now(),
-		},
+	campaigns, err := s.campaigns.List(ctx, tenantID, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to list campaigns", "error", err, "tenant_id", tenantID)
+		return nil, fmt.Errorf("failed to list campaigns: %w", err)
 	}
 
 	s.logger.Debug("Campaigns listed", "tenant_id", tenantID, "count", len(campaigns))
 	return campaigns, nil
 }
 
-// StartCampaign starts a campaign
-func (s *campaignService) StartCampaign(ctx context.Context, tenantID, campaignID string) error {
-	s.logger.Info("Starting campaign", "campaign_id", campaignID, "tenant_id", tenantID)
+// StartCampaign starts a campaign. With simulate, the research, ideation and
+// validation steps run against the AI but the campaign is left as it was and
+// nothing is produced: the projection is returned instead.
+func (s *campaignService) StartCampaign(ctx context.Context, tenantID, campaignID string, simulate bool) (*CampaignSimulation, error) {
+	s.logger.Info("Starting campaign", "campaign_id", campaignID, "tenant_id", tenantID, "simulate", simulate)
 
 	// Get campaign to verify it exists and can be started
-	campaign, err := s.GetCampaign(ctx, tenantID, campaignID)
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
 	if err != nil {
-		return fmt.Errorf("failed to get campaign: %w", err)
+		return nil, fmt.Errorf("failed to get campaign: %w", err)
 	}
 
-	if campaign.Status != CampaignStatusDraft && campaign.Status != CampaignStatusScheduled {
-		return fmt.Errorf("campaign cannot be started, current status: %s", campaign.Status)
+	if campaign.Status != models.CampaignStatusDraft && campaign.Status != models.CampaignStatusScheduled {
+		return nil, fmt.Errorf("campaign cannot be started, current status: %s", campaign.Status)
 	}
 
-	// Update campaign status
-	campaign.Status = CampaignStatusRunning
-	campaign.StartedAt = &time.Time{}
-	*campaign.StartedAt = time.Now()
-	campaign.UpdatedAt = time.Now()
+	if simulate {
+		return s.simulate(ctx, campaign)
+	}
+
+	if err := s.record(ctx, campaign, models.CampaignEventStarted, models.CampaignEventData{}); err != nil {
+		return nil, err
+	}
 
 	// Start with research step
 	if err := s.ExecuteResearchStep(ctx, tenantID, campaignID); err != nil {
 		s.logger.Error("Failed to execute research step", "error", err, "campaign_id", campaignID)
-		return fmt.Errorf("failed to execute research step: %w", err)
+		return nil, fmt.Errorf("failed to execute research step: %w", err)
 	}
 
 	s.logger.Info("Campaign started successfully", "campaign_id", campaignID, "tenant_id", tenantID)
-	return nil
+	return nil, nil
+}
+
+// simulate runs the AI steps of the campaign, each fed the output of the
+// previous one, and projects the videos and publications the campaign would
+// create
+func (s *campaignService) simulate(ctx context.Context, campaign *models.Campaign) (*CampaignSimulation, error) {
+	audience := contextString(campaign.Context, "target_audience", "general audience")
+	simulation := &CampaignSimulation{
+		CampaignID:            campaign.ID,
+		ProjectedVideos:       campaign.MaxVideos,
+		ProjectedPublications: campaign.MaxVideos * len(campaign.Platforms),
+		Budget:                campaign.Budget,
+	}
+
+	research, err := s.simulateStep(ctx, simulation, models.CampaignStepResearch, "campaign/research", map[string]interface{}{
+		"goal":      campaign.Goal,
+		"industry":  contextString(campaign.Context, "industry", campaign.Theme),
+		"platforms": campaign.Platforms,
+		"audience":  audience,
+		"language":  campaign.Language,
+		"budget":    fmt.Sprintf("%.2f", campaign.Budget),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ideas, err := s.simulateStep(ctx, simulation, models.CampaignStepIdeation, "campaign/ideation", map[string]interface{}{
+		"goal":          campaign.Goal,
+		"research_data": research,
+		"platforms":     campaign.Platforms,
+		"themes":        campaign.Theme,
+		"audience":      audience,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.simulateStep(ctx, simulation, models.CampaignStepValidation, "campaign/validation", map[string]interface{}{
+		"goal":          campaign.Goal,
+		"content_ideas": ideas,
+		"platforms":     campaign.Platforms,
+		"budget":        fmt.Sprintf("%.2f", campaign.Budget),
+	}); err != nil {
+		return nil, err
+	}
+
+	simulation.WithinBudget = campaign.Budget == 0 || simulation.AICost <= campaign.Budget
+	s.logger.Info("Campaign simulated",
+		"campaign_id", campaign.ID,
+		"tenant_id", campaign.TenantID,
+		"ai_cost", simulation.AICost,
+		"projected_videos", simulation.ProjectedVideos,
+		"projected_publications", simulation.ProjectedPublications)
+	return simulation, nil
+}
+
+// simulateStep runs one AI step of a dry run, adds it to the simulation and
+// returns its output
+func (s *campaignService) simulateStep(ctx context.Context, simulation *CampaignSimulation, step models.CampaignStep, promptKey string, input map[string]interface{}) (string, error) {
+	result, err := s.ai.ProcessWithBedrock(ctx, promptKey, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to simulate %s step: %w", step, err)
+	}
+
+	output, _ := result["result"].(string)
+	model, _ := result["model"].(string)
+	tokens, _ := result["tokens_used"].(int)
+	cost, _ := result["cost"].(float64)
+	simulation.Steps = append(simulation.Steps, &CampaignSimulationStep{
+		Step:       step,
+		Output:     output,
+		Model:      model,
+		TokensUsed: tokens,
+		Cost:       cost,
+	})
+	simulation.AICost += cost
+	return output, nil
 }
 
 // StopCampaign stops a campaign
@@ -255,20 +323,18 @@ func (s *campaignService) StopCampaign(ctx context.Context, tenantID, campaignID
 	s.logger.Info("Stopping campaign", "campaign_id", campaignID, "tenant_id", tenantID)
 
 	// Get campaign to verify it exists
-	campaign, err := s.GetCampaign(ctx, tenantID, campaignID)
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
 	if err != nil {
 		return fmt.Errorf("failed to get campaign: %w", err)
 	}
 
-	if campaign.Status != CampaignStatusRunning && campaign.Status != CampaignStatusPaused {
+	if campaign.Status != models.CampaignStatusRunning && campaign.Status != models.CampaignStatusPaused {
 		return fmt.Errorf("campaign cannot be stopped, current status: %s", campaign.Status)
 	}
 
-	// Update campaign status
-	campaign.Status = CampaignStatusCompleted
-	campaign.CompletedAt = &time.Time{}
-	*campaign.CompletedAt = time.Now()
-	campaign.UpdatedAt = time.Now()
+	if err := s.record(ctx, campaign, models.CampaignEventStopped, models.CampaignEventData{}); err != nil {
+		return err
+	}
 
 	s.logger.Info("Campaign stopped successfully", "campaign_id", campaignID, "tenant_id", tenantID)
 	return nil
@@ -279,18 +345,18 @@ func (s *campaignService) PauseCampaign(ctx context.Context, tenantID, campaignI
 	s.logger.Info("Pausing campaign", "campaign_id", campaignID, "tenant_id", tenantID)
 
 	// Get campaign to verify it exists
-	campaign, err := s.GetCampaign(ctx, tenantID, campaignID)
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
 	if err != nil {
 		return fmt.Errorf("failed to get campaign: %w", err)
 	}
 
-	if campaign.Status != CampaignStatusRunning {
+	if campaign.Status != models.CampaignStatusRunning {
 		return fmt.Errorf("campaign cannot be paused, current status: %s", campaign.Status)
 	}
 
-	// Update campaign status
-	campaign.Status = CampaignStatusPaused
-	campaign.UpdatedAt = time.Now()
+	if err := s.record(ctx, campaign, models.CampaignEventPaused, models.CampaignEventData{}); err != nil {
+		return err
+	}
 
 	s.logger.Info("Campaign paused successfully", "campaign_id", campaignID, "tenant_id", tenantID)
 	return nil
@@ -301,18 +367,18 @@ func (s *campaignService) ResumeCampaign(ctx context.Context, tenantID, campaign
 	s.logger.Info("Resuming campaign", "campaign_id", campaignID, "tenant_id", tenantID)
 
 	// Get campaign to verify it exists
-	campaign, err := s.GetCampaign(ctx, tenantID, campaignID)
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
 	if err != nil {
 		return fmt.Errorf("failed to get campaign: %w", err)
 	}
 
-	if campaign.Status != CampaignStatusPaused {
+	if campaign.Status != models.CampaignStatusPaused {
 		return fmt.Errorf("campaign cannot be resumed, current status: %s", campaign.Status)
 	}
 
-	// Update campaign status
-	campaign.Status = CampaignStatusRunning
-	campaign.UpdatedAt = time.Now()
+	if err := s.record(ctx, campaign, models.CampaignEventResumed, models.CampaignEventData{}); err != nil {
+		return err
+	}
 
 	s.logger.Info("Campaign resumed successfully", "campaign_id", campaignID, "tenant_id", tenantID)
 	return nil
@@ -323,7 +389,7 @@ func (s *campaignService) ExecuteResearchStep(ctx context.Context, tenantID, cam
 	s.logger.Info("Executing research step", "campaign_id", campaignID, "tenant_id", tenantID)
 
 	// Get campaign
-	campaign, err := s.GetCampaign(ctx, tenantID, campaignID)
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
 	if err != nil {
 		return fmt.Errorf("failed to get campaign: %w", err)
 	}
@@ -335,12 +401,15 @@ func (s *campaignService) ExecuteResearchStep(ctx context.Context, tenantID, cam
 	// 3. Gathering competitor analysis
 	// 4. Identifying target audience preferences
 
-	// Update campaign progress
-	campaign.Progress.ResearchDone = true
-	campaign.Progress.CurrentStep = CampaignStepIdeation
-	campaign.UpdatedAt = time.Now()
+	// Update campaign progress; no AI call is made yet, so the step is free
+	if err := s.completeStep(ctx, campaign, models.CampaignStepResearch, 0); err != nil {
+		return err
+	}
 
 	s.logger.Info("Research step completed", "campaign_id", campaignID, "tenant_id", tenantID)
+	if campaign.Status != models.CampaignStatusRunning {
+		return nil
+	}
 
 	// Automatically proceed to ideation step
 	return s.ExecuteIdeationStep(ctx, tenantID, campaignID)
@@ -351,7 +420,7 @@ func (s *campaignService) ExecuteIdeationStep(ctx context.Context, tenantID, cam
 	s.logger.Info("Executing ideation step", "campaign_id", campaignID, "tenant_id", tenantID)
 
 	// Get campaign
-	campaign, err := s.GetCampaign(ctx, tenantID, campaignID)
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
 	if err != nil {
 		return fmt.Errorf("failed to get campaign: %w", err)
 	}
@@ -368,11 +437,14 @@ func (s *campaignService) ExecuteIdeationStep(ctx context.Context, tenantID, cam
 	// 4. Generating titles, descriptions, and tags
 
 	// Update campaign progress
-	campaign.Progress.IdeationDone = true
-	campaign.Progress.CurrentStep = CampaignStepValidation
-	campaign.UpdatedAt = time.Now()
+	if err := s.completeStep(ctx, campaign, models.CampaignStepIdeation, 0); err != nil {
+		return err
+	}
 
 	s.logger.Info("Ideation step completed", "campaign_id", campaignID, "tenant_id", tenantID)
+	if campaign.Status != models.CampaignStatusRunning {
+		return nil
+	}
 
 	// Automatically proceed to validation step
 	return s.ExecuteValidationStep(ctx, tenantID, campaignID)
@@ -383,7 +455,7 @@ func (s *campaignService) ExecuteValidationStep(ctx context.Context, tenantID, c
 	s.logger.Info("Executing validation step", "campaign_id", campaignID, "tenant_id", tenantID)
 
 	// Get campaign
-	campaign, err := s.GetCampaign(ctx, tenantID, campaignID)
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
 	if err != nil {
 		return fmt.Errorf("failed to get campaign: %w", err)
 	}
@@ -400,20 +472,20 @@ func (s *campaignService) ExecuteValidationStep(ctx context.Context, tenantID, c
 	// 4. Ensuring content aligns with campaign goals
 
 	// Update campaign progress
-	campaign.Progress.ValidationDone = true
-	campaign.Progress.CurrentStep = CampaignStepExecution
-	campaign.UpdatedAt = time.Now()
+	if err := s.completeStep(ctx, campaign, models.CampaignStepValidation, 0); err != nil {
+		return err
+	}
 
 	s.logger.Info("Validation step completed", "campaign_id", campaignID, "tenant_id", tenantID)
 	return nil
 }
 
 // ScheduleCampaign schedules a campaign with the given schedule
-func (s *campaignService) ScheduleCampaign(ctx context.Context, tenantID, campaignID string, schedule *CampaignSchedule) error {
+func (s *campaignService) ScheduleCampaign(ctx context.Context, tenantID, campaignID string, schedule *models.CampaignSchedule) error {
 	s.logger.Info("Scheduling campaign", "campaign_id", campaignID, "tenant_id", tenantID, "schedule_type", schedule.Type)
 
 	// Get campaign
-	campaign, err := s.GetCampaign(ctx, tenantID, campaignID)
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
 	if err != nil {
 		return fmt.Errorf("failed to get campaign: %w", err)
 	}
@@ -423,40 +495,27 @@ func (s *campaignService) ScheduleCampaign(ctx context.Context, tenantID, campai
 		return fmt.Errorf("invalid schedule: %w", err)
 	}
 
-	// Update campaign with schedule
-	campaign.Schedule = schedule
-	campaign.Status = CampaignStatusScheduled
-
 	// Calculate next run time
-	nextRun := calculateNextRunTime(schedule)
+	nextRun := calculateNextRunTime(schedule, s.clock.Now())
 	if nextRun != nil {
-		campaign.Schedule.NextRunAt = nextRun
+		schedule.NextRunAt = nextRun
 	}
 
-	campaign.UpdatedAt = time.Now()
+	if err := s.record(ctx, campaign, models.CampaignEventScheduled, models.CampaignEventData{Schedule: schedule}); err != nil {
+		return err
+	}
 
 	s.logger.Info("Campaign scheduled successfully", "campaign_id", campaignID, "tenant_id", tenantID, "next_run", campaign.Schedule.NextRunAt)
 	return nil
 }
 
 // GetScheduledCampaigns retrieves campaigns scheduled to run before the given time
-func (s *campaignService) GetScheduledCampaigns(ctx context.Context, before time.Time, limit int) ([]*Campaign, error) {
+func (s *campaignService) GetScheduledCampaigns(ctx context.Context, before time.Time, limit int) ([]*models.Campaign, error) {
 	s.logger.Debug("Getting scheduled campaigns", "before", before, "limit", limit)
 
-	// TODO: Implement actual scheduled campaign retrieval from repository
-	// For now, return mock scheduled campaigns
-	campaigns := []*Campaign{
-		{
-			ID:       "scheduled_campaign_1",
-			TenantID: "tenant_123",
-			Name:     "Daily Content Campaign",
-			Status:   CampaignStatusScheduled,
-			Schedule: &CampaignSchedule{
-				Type:      ScheduleTypeDaily,
-				StartTime: time.Now().Add(-1 * time.Hour),
-				NextRunAt: &before,
-			},
-		},
+	campaigns, err := s.campaigns.ListDue(ctx, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due campaigns: %w", err)
 	}
 
 	s.logger.Debug("Scheduled campaigns retrieved", "count", len(campaigns))
@@ -468,7 +527,7 @@ func (s *campaignService) ProcessScheduledCampaigns(ctx context.Context) error {
 	s.logger.Info("Processing scheduled campaigns")
 
 	// Get campaigns scheduled to run now
-	now := time.Now()
+	now := s.clock.Now()
 	campaigns, err := s.GetScheduledCampaigns(ctx, now, 100)
 	if err != nil {
 		s.logger.Error("Failed to get scheduled campaigns", "error", err)
@@ -478,17 +537,22 @@ func (s *campaignService) ProcessScheduledCampaigns(ctx context.Context) error {
 	processed := 0
 	for _, campaign := range campaigns {
 		if campaign.Schedule != nil && campaign.Schedule.NextRunAt != nil && campaign.Schedule.NextRunAt.Before(now) {
-			if err := s.StartCampaign(ctx, campaign.TenantID, campaign.ID); err != nil {
+			if _, err := s.StartCampaign(ctx, campaign.TenantID, campaign.ID, false); err != nil {
 				s.logger.Error("Failed to start scheduled campaign", "error", err, "campaign_id", campaign.ID)
 				continue
 			}
 
-			// Update next run time
-			nextRun := calculateNextRunTime(campaign.Schedule)
-			if nextRun != nil {
-				campaign.Schedule.NextRunAt = nextRun
-				campaign.Schedule.RunCount++
-				campaign.Schedule.LastRunAt = &now
+			// Record the run on the started campaign and move its next run
+			started, err := s.campaigns.Get(ctx, campaign.TenantID, campaign.ID)
+			if err != nil || started.Schedule == nil {
+				s.logger.Error("Failed to reload started campaign", "error", err, "campaign_id", campaign.ID)
+				continue
+			}
+			next := *started.Schedule
+			next.RunCount++
+			if err := s.record(ctx, started, models.CampaignEventRunRecorded, models.CampaignEventData{NextRunAt: calculateNextRunTime(&next, now)}); err != nil {
+				s.logger.Error("Failed to update campaign schedule", "error", err, "campaign_id", campaign.ID)
+				continue
 			}
 
 			processed++
@@ -499,46 +563,114 @@ func (s *campaignService) ProcessScheduledCampaigns(ctx context.Context) error {
 	return nil
 }
 
-// Helper functions
+// GetCampaignHistory replays a campaign's events, so its state at any point
+// can be inspected
+func (s *campaignService) GetCampaignHistory(ctx context.Context, tenantID, campaignID string, until int64) (*CampaignHistory, error) {
+	if until < 0 {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "until must not be negative")
+	}
+
+	events, err := s.campaigns.ListEvents(ctx, tenantID, campaignID, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list campaign events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, models.ErrCampaignNotFound
+	}
+
+	campaign, err := projectCampaign(events)
+	if err != nil {
+		s.logger.Error("Failed to replay campaign", "error", err, "campaign_id", campaignID, "tenant_id", tenantID)
+		return nil, fmt.Errorf("failed to replay campaign: %w", err)
+	}
+	return &CampaignHistory{Campaign: campaign, Events: events}, nil
+}
+
+// newEvent applies an event of the type to the campaign and returns it, to be
+// stored along with the campaign
+func (s *campaignService) newEvent(campaign *models.Campaign, tenantID, campaignID string, eventType models.CampaignEventType, data models.CampaignEventData) (*models.CampaignEvent, error) {
+	event := &models.CampaignEvent{
+		TenantID:   tenantID,
+		CampaignID: campaignID,
+		Sequence:   campaign.Version + 1,
+		Type:       eventType,
+		Data:       data,
+		OccurredAt: s.clock.Now(),
+	}
+	if err := applyCampaignEvent(campaign, event); err != nil {
+		return nil, fmt.Errorf("failed to apply campaign event: %w", err)
+	}
+	return event, nil
+}
+
+// record applies an event of the type to the campaign and saves both
+func (s *campaignService) record(ctx context.Context, campaign *models.Campaign, eventType models.CampaignEventType, data models.CampaignEventData) error {
+	event, err := s.newEvent(campaign, campaign.TenantID, campaign.ID, eventType, data)
+	if err != nil {
+		return err
+	}
+	if err := s.campaigns.Update(ctx, campaign, event); err != nil {
+		return fmt.Errorf("failed to update campaign: %w", err)
+	}
+	return nil
+}
+
+// completeStep records that the step is done and what it cost, pausing a
+// running campaign whose total cost went over its budget
+func (s *campaignService) completeStep(ctx context.Context, campaign *models.Campaign, step models.CampaignStep, cost float64) error {
+	completed, err := s.newEvent(campaign, campaign.TenantID, campaign.ID, models.CampaignEventStepCompleted, models.CampaignEventData{Step: step, Cost: cost})
+	if err != nil {
+		return err
+	}
+	events := []*models.CampaignEvent{completed}
+	if campaign.Budget > 0 && campaign.Progress.TotalCost > campaign.Budget && campaign.Status == models.CampaignStatusRunning {
+		exceeded, err := s.newEvent(campaign, campaign.TenantID, campaign.ID, models.CampaignEventBudgetExceeded, models.CampaignEventData{Cost: campaign.Progress.TotalCost})
+		if err != nil {
+			return err
+		}
+		events = append(events, exceeded)
+		s.logger.Warn("Campaign paused over budget", "campaign_id", campaign.ID, "tenant_id", campaign.TenantID, "total_cost", campaign.Progress.TotalCost, "budget", campaign.Budget)
+	}
 
-// generateCampaignID generates a unique ID for campaigns
-// TODO: Replace with proper UUID generation
-func generateCampaignID() string {
-	return fmt.Sprintf("campaign_%d", time.Now().UnixNano())
+	if err := s.campaigns.Update(ctx, campaign, events...); err != nil {
+		return fmt.Errorf("failed to update campaign: %w", err)
+	}
+	return nil
 }
 
-// calculateNextRunTime calculates the next run time for a campaign schedule
-func calculateNextRunTime(schedule *CampaignSchedule) *time.Time {
+// Helper functions
+
+// calculateNextRunTime calculates the next run time of a campaign schedule after now
+func calculateNextRunTime(schedule *models.CampaignSchedule, now time.Time) *time.Time {
 	if schedule == nil {
 		return nil
 	}
 
-	now := time.Now()
 	var nextRun time.Time
 
 	switch schedule.Type {
-	case ScheduleTypeOnce:
+	case models.ScheduleTypeOnce:
 		if schedule.StartTime.After(now) {
 			nextRun = schedule.StartTime
 		} else {
 			return nil // One-time schedule already passed
 		}
-	case ScheduleTypeDaily:
+	case models.ScheduleTypeDaily:
 		nextRun = schedule.StartTime
 		for nextRun.Before(now) {
 			nextRun = nextRun.AddDate(0, 0, 1)
 		}
-	case ScheduleTypeWeekly:
+	case models.ScheduleTypeWeekly:
 		nextRun = schedule.StartTime
 		for nextRun.Before(now) {
 			nextRun = nextRun.AddDate(0, 0, 7)
 		}
-	case ScheduleTypeMonthly:
+	case models.ScheduleTypeMonthly:
 		nextRun = schedule.StartTime
 		for nextRun.Before(now) {
 			nextRun = nextRun.AddDate(0, 1, 0)
 		}
-	case ScheduleTypeCron:
+	case models.ScheduleTypeCron:
 		// TODO: Implement cron expression parsing
 		// For now, default to daily
 		nextRun = now.AddDate(0, 0, 1)
@@ -559,8 +691,17 @@ func calculateNextRunTime(schedule *CampaignSchedule) *time.Time {
 	return &nextRun
 }
 
+// contextString returns the non-empty string under key in a campaign's
+// context, or fallback
+func contextString(values map[string]interface{}, key, fallback string) string {
+	if value, ok := values[key].(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
 // validateSchedule validates a campaign schedule
-func validateSchedule(schedule *CampaignSchedule) error {
+func validateSchedule(schedule *models.CampaignSchedule) error {
 	if schedule == nil {
 		return fmt.Errorf("schedule cannot be nil")
 	}
@@ -573,7 +714,7 @@ func validateSchedule(schedule *CampaignSchedule) error {
 		return fmt.Errorf("end time cannot be before start time")
 	}
 
-	if schedule.Type == ScheduleTypeCron && schedule.CronExpr == "" {
+	if schedule.Type == models.ScheduleTypeCron && schedule.CronExpr == "" {
 		return fmt.Errorf("cron expression is required for cron schedule type")
 	}
 
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memoryCampaignRepo stores campaigns and their events in memory, copying
+// campaigns in and out as the database would
+type memoryCampaignRepo struct {
+	models.CampaignRepository
+	campaigns map[string]models.Campaign
+	events    []*models.CampaignEvent
+}
+
+func newMemoryCampaignRepo(campaigns ...*models.Campaign) *memoryCampaignRepo {
+	r := &memoryCampaignRepo{campaigns: make(map[string]models.Campaign)}
+	for _, campaign := range campaigns {
+		r.campaigns[campaign.ID] = *campaign
+	}
+	return r
+}
+
+func (r *memoryCampaignRepo) Create(ctx context.Context, campaign *models.Campaign, events ...*models.CampaignEvent) error {
+	r.campaigns[campaign.ID] = *campaign
+	r.events = append(r.events, events...)
+	return nil
+}
+
+func (r *memoryCampaignRepo) Get(ctx context.Context, tenantID, id string) (*models.Campaign, error) {
+	campaign, ok := r.campaigns[id]
+	if !ok || campaign.TenantID != tenantID {
+		return nil, models.ErrCampaignNotFound
+	}
+	if campaign.Schedule != nil {
+		schedule := *campaign.Schedule
+		campaign.Schedule = &schedule
+	}
+	return &campaign, nil
+}
+
+func (r *memoryCampaignRepo) Update(ctx context.Context, campaign *models.Campaign, events ...*models.CampaignEvent) error {
+	stored, ok := r.campaigns[campaign.ID]
+	if !ok {
+		return models.ErrCampaignNotFound
+	}
+	if stored.Version != campaign.Version-int64(len(events)) {
+		return models.ErrConflict
+	}
+	r.campaigns[campaign.ID] = *campaign
+	r.events = append(r.events, events...)
+	return nil
+}
+
+func (r *memoryCampaignRepo) Delete(ctx context.Context, tenantID, id string, events ...*models.CampaignEvent) error {
+	delete(r.campaigns, id)
+	r.events = append(r.events, events...)
+	return nil
+}
+
+func (r *memoryCampaignRepo) ListEvents(ctx context.Context, tenantID, campaignID string, until int64) ([]*models.CampaignEvent, error) {
+	var events []*models.CampaignEvent
+	for _, event := range r.events {
+		if event.TenantID == tenantID && event.CampaignID == campaignID && (until == 0 || event.Sequence <= until) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *memoryCampaignRepo) ListDue(ctx context.Context, before time.Time, limit int) ([]*models.Campaign, error) {
+	var due []*models.Campaign
+	for id, campaign := range r.campaigns {
+		if campaign.Status == models.CampaignStatusScheduled && campaign.Schedule != nil &&
+			campaign.Schedule.NextRunAt != nil && !campaign.Schedule.NextRunAt.After(before) {
+			stored, _ := r.Get(ctx, campaign.TenantID, id)
+			due = append(due, stored)
+		}
+	}
+	return due, nil
+}
+
+func TestCalculateNextRunTime(t *testing.T) {
+	now := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule *models.CampaignSchedule
+		want     *time.Time
+	}{
+		{name: "daily", schedule: &models.CampaignSchedule{Type: models.ScheduleTypeDaily, StartTime: start}, want: timePtr(time.Date(2026, 4, 11, 9, 0, 0, 0, time.UTC))},
+		{name: "weekly", schedule: &models.CampaignSchedule{Type: models.ScheduleTypeWeekly, StartTime: start}, want: timePtr(time.Date(2026, 4, 15, 9, 0, 0, 0, time.UTC))},
+		{name: "monthly", schedule: &models.CampaignSchedule{Type: models.ScheduleTypeMonthly, StartTime: start}, want: timePtr(time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC))},
+		{name: "once in the future", schedule: &models.CampaignSchedule{Type: models.ScheduleTypeOnce, StartTime: end}, want: timePtr(end)},
+		{name: "once in the past", schedule: &models.CampaignSchedule{Type: models.ScheduleTypeOnce, StartTime: start}},
+		{name: "past the end time", schedule: &models.CampaignSchedule{Type: models.ScheduleTypeWeekly, StartTime: start, EndTime: &end}},
+		{name: "out of runs", schedule: &models.CampaignSchedule{Type: models.ScheduleTypeDaily, StartTime: start, MaxRuns: 3, RunCount: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, calculateNextRunTime(tt.schedule, now))
+		})
+	}
+}
+
+func TestCampaignService_CreateScheduledCampaign(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC))
+	campaigns := newMemoryCampaignRepo()
+	svc := NewCampaignService(campaigns, nil, now, logger.New("error", "test"))
+
+	campaign, err := svc.CreateCampaign(context.Background(), "tenant-1", "user-1", &CreateCampaignRequest{
+		Name:      "Daily shorts",
+		Goal:      "Grow the channel",
+		Platforms: []string{"youtube"},
+		Language:  "en",
+		Schedule:  &models.CampaignSchedule{Type: models.ScheduleTypeDaily, StartTime: time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.CampaignStatusScheduled, campaign.Status)
+	assert.Equal(t, now.Now(), campaign.CreatedAt)
+	require.NotNil(t, campaign.Schedule.NextRunAt)
+	assert.Equal(t, time.Date(2026, 4, 11, 9, 0, 0, 0, time.UTC), *campaign.Schedule.NextRunAt)
+
+	stored, err := svc.GetCampaign(context.Background(), "tenant-1", campaign.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Daily shorts", stored.Name)
+	_, err = svc.GetCampaign(context.Background(), "tenant-2", campaign.ID)
+	assert.ErrorIs(t, err, models.ErrCampaignNotFound, "campaigns are scoped to their tenant")
+}
+
+func TestCampaignService_ProcessScheduledCampaigns(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 4, 11, 9, 30, 0, 0, time.UTC))
+	due := time.Date(2026, 4, 11, 9, 0, 0, 0, time.UTC)
+	later := time.Date(2026, 4, 12, 9, 0, 0, 0, time.UTC)
+	campaigns := newMemoryCampaignRepo(
+		&models.Campaign{ID: "due", TenantID: "tenant-1", Status: models.CampaignStatusScheduled,
+			Schedule: &models.CampaignSchedule{Type: models.ScheduleTypeDaily, StartTime: due, NextRunAt: &due}},
+		&models.Campaign{ID: "later", TenantID: "tenant-1", Status: models.CampaignStatusScheduled,
+			Schedule: &models.CampaignSchedule{Type: models.ScheduleTypeDaily, StartTime: later, NextRunAt: &later}},
+	)
+	svc := NewCampaignService(campaigns, nil, now, logger.New("error", "test"))
+
+	require.NoError(t, svc.ProcessScheduledCampaigns(context.Background()))
+
+	started := campaigns.campaigns["due"]
+	assert.Equal(t, models.CampaignStatusRunning, started.Status)
+	assert.True(t, started.Progress.ValidationDone, "the steps' progress is saved")
+	assert.Equal(t, 1, started.Schedule.RunCount)
+	assert.Equal(t, now.Now(), *started.Schedule.LastRunAt)
+	assert.Equal(t, time.Date(2026, 4, 12, 9, 0, 0, 0, time.UTC), *started.Schedule.NextRunAt)
+	assert.Equal(t, models.CampaignStatusScheduled, campaigns.campaigns["later"].Status)
+}
+
+func TestCampaignService_History(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC))
+	campaigns := newMemoryCampaignRepo()
+	svc := NewCampaignService(campaigns, nil, now, logger.New("error", "test"))
+	ctx := context.Background()
+
+	campaign, err := svc.CreateCampaign(ctx, "tenant-1", "user-1", &CreateCampaignRequest{
+		Name: "Launch", Goal: "Grow the channel", Platforms: []string{"youtube"}, Language: "en",
+	})
+	require.NoError(t, err)
+	now.Advance(time.Hour)
+	name := "Spring launch"
+	_, err = svc.UpdateCampaign(ctx, "tenant-1", campaign.ID, &UpdateCampaignRequest{Name: &name})
+	require.NoError(t, err)
+	now.Advance(time.Hour)
+	_, err = svc.StartCampaign(ctx, "tenant-1", campaign.ID, false)
+	require.NoError(t, err)
+	require.NoError(t, svc.PauseCampaign(ctx, "tenant-1", campaign.ID))
+
+	history, err := svc.GetCampaignHistory(ctx, "tenant-1", campaign.ID, 0)
+	require.NoError(t, err)
+	var types []models.CampaignEventType
+	for _, event := range history.Events {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []models.CampaignEventType{
+		models.CampaignEventCreated, models.CampaignEventUpdated, models.CampaignEventStarted,
+		models.CampaignEventStepCompleted, models.CampaignEventStepCompleted, models.CampaignEventStepCompleted,
+		models.CampaignEventPaused,
+	}, types)
+	stored := campaigns.campaigns[campaign.ID]
+	assert.Equal(t, &stored, history.Campaign, "the replay rebuilds the stored campaign")
+
+	history, err = svc.GetCampaignHistory(ctx, "tenant-1", campaign.ID, 2)
+	require.NoError(t, err)
+	assert.Len(t, history.Events, 2)
+	assert.Equal(t, "Spring launch", history.Campaign.Name)
+	assert.Equal(t, models.CampaignStatusDraft, history.Campaign.Status, "the replay stops at the sequence")
+
+	require.NoError(t, svc.DeleteCampaign(ctx, "tenant-1", campaign.ID))
+	history, err = svc.GetCampaignHistory(ctx, "tenant-1", campaign.ID, 0)
+	require.NoError(t, err, "deleted campaigns keep their history")
+	assert.Equal(t, models.CampaignEventDeleted, history.Events[len(history.Events)-1].Type)
+
+	_, err = svc.GetCampaignHistory(ctx, "tenant-2", campaign.ID, 0)
+	assert.ErrorIs(t, err, models.ErrCampaignNotFound)
+	_, err = svc.GetCampaignHistory(ctx, "tenant-1", campaign.ID, -1)
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+}
+
+func TestApplyCampaignEvent_BudgetAndOrder(t *testing.T) {
+	at := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	events := []*models.CampaignEvent{
+		{CampaignID: "campaign-1", TenantID: "tenant-1", Sequence: 1, Type: models.CampaignEventCreated, OccurredAt: at,
+			Data: models.CampaignEventData{Campaign: &models.Campaign{Status: models.CampaignStatusDraft, Budget: 1}}},
+		{CampaignID: "campaign-1", TenantID: "tenant-1", Sequence: 2, Type: models.CampaignEventStarted, OccurredAt: at},
+		{CampaignID: "campaign-1", TenantID: "tenant-1", Sequence: 3, Type: models.CampaignEventStepCompleted, OccurredAt: at,
+			Data: models.CampaignEventData{Step: models.CampaignStepResearch, Cost: 1.5}},
+		{CampaignID: "campaign-1", TenantID: "tenant-1", Sequence: 4, Type: models.CampaignEventBudgetExceeded, OccurredAt: at},
+	}
+	campaign, err := projectCampaign(events)
+	require.NoError(t, err)
+	assert.Equal(t, models.CampaignStatusPaused, campaign.Status)
+	assert.Equal(t, models.CampaignStepIdeation, campaign.Progress.CurrentStep)
+	assert.InDelta(t, 1.5, campaign.Progress.TotalCost, 1e-9)
+	assert.Equal(t, int64(4), campaign.Version)
+
+	_, err = projectCampaign(events[1:])
+	assert.Error(t, err, "a history starts with its creation")
+	_, err = projectCampaign([]*models.CampaignEvent{events[0], events[2]})
+	assert.Error(t, err, "no event may be missing")
+}
+
+// scriptedAI answers each prompt with its key and records the inputs
+type scriptedAI struct {
+	AIService
+	inputs map[string]map[string]interface{}
+}
+
+func (a *scriptedAI) ProcessWithBedrock(ctx context.Context, promptKey string, input map[string]interface{}) (map[string]interface{}, error) {
+	a.inputs[promptKey] = input
+	return map[string]interface{}{
+		"result":      "output of " + promptKey,
+		"model":       "claude-haiku",
+		"tokens_used": 1200,
+		"cost":        0.25,
+	}, nil
+}
+
+func TestCampaignService_SimulateCampaign(t *testing.T) {
+	ai := &scriptedAI{inputs: map[string]map[string]interface{}{}}
+	campaigns := newMemoryCampaignRepo(&models.Campaign{
+		ID:        "campaign-1",
+		TenantID:  "tenant-1",
+		Goal:      "Grow the channel",
+		Context:   map[string]interface{}{"industry": "technology"},
+		Platforms: []string{"youtube", "tiktok"},
+		Status:    models.CampaignStatusDraft,
+		Budget:    100,
+		MaxVideos: 10,
+	})
+	svc := NewCampaignService(campaigns, ai, clock.NewFake(time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)), logger.New("error", "test"))
+
+	simulation, err := svc.StartCampaign(context.Background(), "tenant-1", "campaign-1", true)
+	require.NoError(t, err)
+	require.Len(t, simulation.Steps, 3)
+	assert.Equal(t, models.CampaignStepResearch, simulation.Steps[0].Step)
+	assert.Equal(t, models.CampaignStepValidation, simulation.Steps[2].Step)
+	assert.Equal(t, "technology", ai.inputs["campaign/research"]["industry"])
+	assert.Equal(t, "output of campaign/research", ai.inputs["campaign/ideation"]["research_data"])
+	assert.Equal(t, "output of campaign/ideation", ai.inputs["campaign/validation"]["content_ideas"])
+
+	assert.Equal(t, 10, simulation.ProjectedVideos)
+	assert.Equal(t, 20, simulation.ProjectedPublications)
+	assert.InDelta(t, 0.75, simulation.AICost, 1e-9)
+	assert.True(t, simulation.WithinBudget)
+	assert.Equal(t, models.CampaignStatusDraft, campaigns.campaigns["campaign-1"].Status, "a simulation leaves the campaign as it was")
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/metrics"
+	"github.com/jibe0123/mysteryfactory/pkg/tokenizer"
+)
+
+// chatSystemPrompts holds the assistant instructions for each conversation purpose
+var chatSystemPrompts = map[string]string{
+	"title":       "You are an expert content creator helping refine video titles. Propose concise, engaging, SEO-friendly titles and iterate on the user's feedback.",
+	"description": "You are an expert content creator helping refine video descriptions. Write clear, well-structured descriptions with a strong hook and iterate on the user's feedback.",
+	"tags":        "You are an expert content creator helping refine video tags and hashtags. Suggest relevant, platform-appropriate tags and iterate on the user's feedback.",
+	"general":     "You are a helpful assistant for video content creators.",
+}
+
+// chatService implements the ChatService interface
+type chatService struct {
+	conversations models.ConversationRepository
+	bedrockClient aws.BedrockClient
+	modelPolicy   *ModelPolicy
+	usage         models.AIUsageRepository
+	tokenizer     tokenizer.Tokenizer
+	ttl           time.Duration
+	clock         clock.Clock
+	logger        *logger.Logger
+	metrics       *metrics.Metrics
+}
+
+var _ ChatService = (*chatService)(nil)
+
+// NewChatService creates a new chat service instance
+func NewChatService(conversations models.ConversationRepository, bedrockClient aws.BedrockClient, modelPolicy *ModelPolicy, usage models.AIUsageRepository, ttl time.Duration, clock clock.Clock, logger *logger.Logger, metrics *metrics.Metrics) ChatService {
+	return &chatService{
+		conversations: conversations,
+		bedrockClient: bedrockClient,
+		modelPolicy:   modelPolicy,
+		usage:         usage,
+		tokenizer:     tokenizer.NewBPE(),
+		ttl:           ttl,
+		clock:         clock,
+		logger:        logger,
+		metrics:       metrics,
+	}
+}
+
+// Chat sends a message to a conversation, replaying its full history to Bedrock
+func (s *chatService) Chat(ctx context.Context, tenantID, userID string, req *ChatRequest) (*ChatResponse, error) {
+	start := time.Now()
+	s.logger.Info("Processing chat message", "tenant_id", tenantID, "user_id", userID, "conversation_id", req.ConversationID)
+
+	s.metrics.IncrementAIInFlight()
+	defer s.metrics.DecrementAIInFlight()
+
+	// Chat replies with the first model of the chain allowed for this tenant
+	chain, err := s.modelPolicy.Resolve(tenantID, req.Model)
+	if err != nil {
+		s.metrics.RecordError("model_not_allowed", "chat_service", tenantID)
+		return nil, err
+	}
+	model := chain[0]
+
+	conversation, isNew, err := s.loadOrStartConversation(ctx, tenantID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rebuild the Bedrock conversation from the stored history
+	history := aws.NewConversation()
+	for _, msg := range conversation.Messages {
+		history.AddMessage(aws.NewTextMessage(aws.MessageRole(msg.Role), msg.Content))
+	}
+	userContent := s.buildUserContent(req)
+	history.AddUserMessage(userContent)
+
+	// Pre-flight token check against the model context window
+	modelConfig := aws.GetModelConfig(model)
+	promptTokens := s.countPromptTokens(conversation.Messages, userContent)
+	if promptTokens >= modelConfig.ContextWindow {
+		return nil, fmt.Errorf("conversation too long: %d tokens exceeds context window of %d", promptTokens, modelConfig.ContextWindow)
+	}
+	maxTokens := modelConfig.MaxTokens
+	if remaining := modelConfig.ContextWindow - promptTokens; maxTokens > remaining {
+		maxTokens = remaining
+	}
+
+	bedrockReq := aws.NewConversationRequest(model, history).
+		WithMaxTokens(maxTokens).
+		WithTemperature(0.7).
+		WithTopP(0.9)
+
+	bedrockResp, err := s.bedrockClient.InvokeConversation(ctx, bedrockReq)
+	if err != nil {
+		s.logger.Error("Failed to invoke Bedrock for chat", "error", err, "conversation_id", conversation.ID)
+		s.metrics.RecordAIRequest(ctx, string(bedrockReq.Model), "chat/"+conversation.Purpose, conversation.Purpose, "error", tenantID, time.Since(start), 0)
+		return nil, fmt.Errorf("failed to invoke Bedrock model: %w", err)
+	}
+
+	// Persist the new turn and extend the conversation TTL
+	expiresAt := s.clock.Now().Add(s.ttl)
+	turn := []*models.ConversationMessage{
+		{Role: string(aws.RoleUser), Content: userContent, TokensUsed: bedrockResp.InputTokens},
+		{Role: string(aws.RoleAssistant), Content: bedrockResp.Content, TokensUsed: bedrockResp.OutputTokens},
+	}
+
+	if isNew {
+		conversation.Messages = append(conversation.Messages, turn...)
+		conversation.ExpiresAt = expiresAt
+		if err := s.conversations.Create(ctx, conversation); err != nil {
+			return nil, fmt.Errorf("failed to create conversation: %w", err)
+		}
+	} else {
+		if err := s.conversations.AppendMessages(ctx, tenantID, conversation.ID, turn, expiresAt); err != nil {
+			return nil, fmt.Errorf("failed to store conversation messages: %w", err)
+		}
+		conversation.Messages = append(conversation.Messages, turn...)
+	}
+
+	s.metrics.RecordAIRequest(ctx, string(bedrockReq.Model), "chat/"+conversation.Purpose, conversation.Purpose, "success", tenantID, time.Since(start), bedrockResp.TokensUsed)
+	recordUsage(ctx, s.usage, s.logger, &models.AIUsage{
+		TenantID:     tenantID,
+		UserID:       userID,
+		Model:        string(bedrockReq.Model),
+		Feature:      "chat/" + conversation.Purpose,
+		InputTokens:  bedrockResp.InputTokens,
+		OutputTokens: bedrockResp.OutputTokens,
+		Cost:         modelConfig.EstimateCost(bedrockResp.InputTokens, bedrockResp.OutputTokens),
+	})
+
+	s.logger.Info("Chat message processed",
+		"tenant_id", tenantID,
+		"conversation_id", conversation.ID,
+		"messages", len(conversation.Messages),
+		"tokens_used", bedrockResp.TokensUsed)
+
+	return &ChatResponse{
+		ConversationID: conversation.ID,
+		Reply:          bedrockResp.Content,
+		MessageCount:   len(conversation.Messages),
+		TokensUsed:     bedrockResp.TokensUsed,
+		ExpiresAt:      expiresAt,
+		ProcessedAt:    s.clock.Now(),
+	}, nil
+}
+
+// GetConversation retrieves a conversation with its message history
+func (s *chatService) GetConversation(ctx context.Context, tenantID, userID, conversationID string) (*models.Conversation, error) {
+	conversation, err := s.conversations.GetByID(ctx, tenantID, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.IsExpired(s.clock.Now()) {
+		return nil, models.ErrConversationExpired
+	}
+	return conversation, nil
+}
+
+// DeleteConversation deletes a conversation and its messages
+func (s *chatService) DeleteConversation(ctx context.Context, tenantID, userID, conversationID string) error {
+	s.logger.Info("Deleting conversation", "tenant_id", tenantID, "user_id", userID, "conversation_id", conversationID)
+	return s.conversations.Delete(ctx, tenantID, userID, conversationID)
+}
+
+// PurgeExpiredConversations removes conversations whose TTL has elapsed
+func (s *chatService) PurgeExpiredConversations(ctx context.Context) (int64, error) {
+	deleted, err := s.conversations.DeleteExpired(ctx, s.clock.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired conversations: %w", err)
+	}
+	s.logger.Info("Expired conversations purged", "count", deleted)
+	return deleted, nil
+}
+
+// loadOrStartConversation returns the requested conversation or a new unsaved one
+func (s *chatService) loadOrStartConversation(ctx context.Context, tenantID, userID string, req *ChatRequest) (*models.Conversation, bool, error) {
+	if req.ConversationID != "" {
+		conversation, err := s.GetConversation(ctx, tenantID, userID, req.ConversationID)
+		if err != nil {
+			if errors.Is(err, models.ErrConversationExpired) {
+				// Drop expired history eagerly; the client has to start over
+				_ = s.conversations.Delete(ctx, tenantID, userID, req.ConversationID)
+			}
+			return nil, false, err
+		}
+		return conversation, false, nil
+	}
+
+	purpose := req.Purpose
+	if purpose == "" {
+		purpose = "general"
+	}
+	systemPrompt, ok := chatSystemPrompts[purpose]
+	if !ok {
+		return nil, false, i18n.Errorf(models.ErrInvalidInput, "unsupported chat purpose: %s", purpose)
+	}
+
+	title := req.Message
+	if runes := []rune(title); len(runes) > 80 {
+		title = string(runes[:80])
+	}
+
+	return &models.Conversation{
+		TenantID: tenantID,
+		UserID:   userID,
+		VideoID:  req.VideoID,
+		Purpose:  purpose,
+		Title:    title,
+		Messages: []*models.ConversationMessage{
+			{Role: string(aws.RoleSystem), Content: systemPrompt},
+		},
+	}, true, nil
+}
+
+// buildUserContent prefixes the user message with any structured context
+func (s *chatService) buildUserContent(req *ChatRequest) string {
+	if len(req.Context) == 0 {
+		return req.Message
+	}
+
+	keys := make([]string, 0, len(req.Context))
+	for key := range req.Context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("Context:\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "- %s: %v\n", key, req.Context[key])
+	}
+	b.WriteString("\n")
+	b.WriteString(req.Message)
+	return b.String()
+}
+
+// countPromptTokens sizes the prompt a new turn sends. The input and output
+// usage Bedrock reported for the previous turn already covers the stored
+// history, so only the new message is counted locally; history without
+// reported usage is counted message by message.
+func (s *chatService) countPromptTokens(history []*models.ConversationMessage, userContent string) int {
+	promptTokens := s.tokenizer.Count(userContent)
+	if n := len(history); n >= 2 {
+		prompt, reply := history[n-2], history[n-1]
+		if prompt.Role == string(aws.RoleUser) && reply.Role == string(aws.RoleAssistant) && prompt.TokensUsed > 0 {
+			return promptTokens + prompt.TokensUsed + reply.TokensUsed
+		}
+	}
+	for _, msg := range history {
+		promptTokens += s.tokenizer.Count(msg.Content)
+	}
+	return promptTokens
+}
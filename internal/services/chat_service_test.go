@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryConversationRepo keeps conversations in memory, scoped to their owner
+// like the MySQL repository
+type memoryConversationRepo struct {
+	models.ConversationRepository
+	conversations map[string]*models.Conversation
+}
+
+func newMemoryConversationRepo() *memoryConversationRepo {
+	return &memoryConversationRepo{conversations: map[string]*models.Conversation{}}
+}
+
+func (r *memoryConversationRepo) Create(ctx context.Context, c *models.Conversation) error {
+	c.ID = "conversation-" + c.UserID
+	stored := *c
+	stored.Messages = append([]*models.ConversationMessage(nil), c.Messages...)
+	r.conversations[c.ID] = &stored
+	return nil
+}
+
+func (r *memoryConversationRepo) GetByID(ctx context.Context, tenantID, userID, id string) (*models.Conversation, error) {
+	c, ok := r.conversations[id]
+	if !ok || c.TenantID != tenantID || c.UserID != userID {
+		return nil, models.ErrConversationNotFound
+	}
+	found := *c
+	found.Messages = append([]*models.ConversationMessage(nil), c.Messages...)
+	return &found, nil
+}
+
+func (r *memoryConversationRepo) AppendMessages(ctx context.Context, tenantID, id string, messages []*models.ConversationMessage, expiresAt time.Time) error {
+	c, ok := r.conversations[id]
+	if !ok || c.TenantID != tenantID {
+		return models.ErrConversationNotFound
+	}
+	c.Messages = append(c.Messages, messages...)
+	c.ExpiresAt = expiresAt
+	return nil
+}
+
+func (r *memoryConversationRepo) Delete(ctx context.Context, tenantID, userID, id string) error {
+	if _, err := r.GetByID(ctx, tenantID, userID, id); err != nil {
+		return err
+	}
+	delete(r.conversations, id)
+	return nil
+}
+
+// recordingChatClient answers every conversation with the same reply, keeping
+// the requests it was sent
+type recordingChatClient struct {
+	aws.BedrockClient
+	requests []*aws.ConversationRequest
+}
+
+func (c *recordingChatClient) InvokeConversation(ctx context.Context, req *aws.ConversationRequest) (*aws.InvokeModelResponse, error) {
+	c.requests = append(c.requests, req)
+	return &aws.InvokeModelResponse{Content: "reply", InputTokens: 10, OutputTokens: 5, TokensUsed: 15, ProcessedAt: time.Now()}, nil
+}
+
+func newChatTestService(t *testing.T, fake *clock.Fake) (ChatService, *memoryConversationRepo, *recordingChatClient) {
+	t.Helper()
+	policy, err := NewModelPolicy("claude-sonnet,claude-haiku", "", "claude-sonnet")
+	require.NoError(t, err)
+	conversations := newMemoryConversationRepo()
+	client := &recordingChatClient{}
+	svc := NewChatService(conversations, client, policy, &guardrailUsageRepo{}, time.Hour, fake, logger.New("error", "test"), testMetrics)
+	return svc, conversations, client
+}
+
+func TestChatService_AppendsTurnsInOrder(t *testing.T) {
+	ctx := context.Background()
+	svc, _, client := newChatTestService(t, clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)))
+
+	first, err := svc.Chat(ctx, "tenant-1", "user-1", &ChatRequest{Message: "Suggest a title", Purpose: "title"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, first.MessageCount)
+
+	second, err := svc.Chat(ctx, "tenant-1", "user-1", &ChatRequest{ConversationID: first.ConversationID, Message: "Shorter please", Model: "claude-haiku"})
+	require.NoError(t, err)
+	assert.Equal(t, 5, second.MessageCount)
+
+	conversation, err := svc.GetConversation(ctx, "tenant-1", "user-1", first.ConversationID)
+	require.NoError(t, err)
+	var roles, contents []string
+	for _, msg := range conversation.Messages {
+		roles = append(roles, msg.Role)
+		contents = append(contents, msg.Content)
+	}
+	assert.Equal(t, []string{"system", "user", "assistant", "user", "assistant"}, roles)
+	assert.Equal(t, []string{chatSystemPrompts["title"], "Suggest a title", "reply", "Shorter please", "reply"}, contents)
+
+	// The whole history is replayed, on the model the request selected
+	require.Len(t, client.requests, 2)
+	assert.Equal(t, aws.ModelClaude4Sonnet, client.requests[0].Model)
+	assert.Equal(t, aws.ModelClaude4Haiku, client.requests[1].Model)
+	replayed := client.requests[1].Conversation.Messages
+	require.Len(t, replayed, 4)
+	assert.Equal(t, aws.RoleSystem, replayed[0].Role)
+	assert.Equal(t, "Shorter please", replayed[3].Content[0].Text)
+}
+
+func TestChatService_SizesHistoryFromReportedUsage(t *testing.T) {
+	ctx := context.Background()
+	svc, conversations, client := newChatTestService(t, clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)))
+
+	started, err := svc.Chat(ctx, "tenant-1", "user-1", &ChatRequest{Message: "Suggest a title"})
+	require.NoError(t, err)
+
+	// Bedrock reported the last turn's prompt nearly filling the context window
+	stored := conversations.conversations[started.ConversationID].Messages
+	stored[len(stored)-2].TokensUsed = 199000
+	stored[len(stored)-1].TokensUsed = 500
+
+	_, err = svc.Chat(ctx, "tenant-1", "user-1", &ChatRequest{ConversationID: started.ConversationID, Message: "hello world"})
+	require.NoError(t, err)
+	require.Len(t, client.requests, 2)
+	assert.Equal(t, 200000-199000-500-2, client.requests[1].MaxTokens, "the reply budget is what the reported usage and the new message leave")
+
+	stored = conversations.conversations[started.ConversationID].Messages
+	stored[len(stored)-2].TokensUsed = 200000
+	_, err = svc.Chat(ctx, "tenant-1", "user-1", &ChatRequest{ConversationID: started.ConversationID, Message: "hello world"})
+	assert.ErrorContains(t, err, "conversation too long")
+}
+
+func TestChatService_ExpiredConversation(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	svc, conversations, client := newChatTestService(t, fake)
+
+	started, err := svc.Chat(ctx, "tenant-1", "user-1", &ChatRequest{Message: "Hello"})
+	require.NoError(t, err)
+	assert.Equal(t, fake.Now().Add(time.Hour), started.ExpiresAt)
+
+	// A reply extends the TTL from the time of the last turn
+	fake.Advance(45 * time.Minute)
+	_, err = svc.Chat(ctx, "tenant-1", "user-1", &ChatRequest{ConversationID: started.ConversationID, Message: "Again"})
+	require.NoError(t, err)
+
+	fake.Advance(time.Hour + time.Second)
+	_, err = svc.GetConversation(ctx, "tenant-1", "user-1", started.ConversationID)
+	assert.ErrorIs(t, err, models.ErrConversationExpired)
+
+	_, err = svc.Chat(ctx, "tenant-1", "user-1", &ChatRequest{ConversationID: started.ConversationID, Message: "Still there?"})
+	assert.ErrorIs(t, err, models.ErrConversationExpired)
+	assert.Empty(t, conversations.conversations, "expired conversations are dropped")
+	assert.Len(t, client.requests, 2)
+}
+
+func TestChatService_OtherOwnerConversationNotFound(t *testing.T) {
+	ctx := context.Background()
+	svc, _, client := newChatTestService(t, clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)))
+
+	started, err := svc.Chat(ctx, "tenant-1", "user-1", &ChatRequest{Message: "Hello"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		tenantID string
+		userID   string
+	}{
+		{name: "other user", tenantID: "tenant-1", userID: "user-2"},
+		{name: "other tenant", tenantID: "tenant-2", userID: "user-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetConversation(ctx, tt.tenantID, tt.userID, started.ConversationID)
+			assert.ErrorIs(t, err, models.ErrConversationNotFound)
+
+			_, err = svc.Chat(ctx, tt.tenantID, tt.userID, &ChatRequest{ConversationID: started.ConversationID, Message: "Hi"})
+			assert.ErrorIs(t, err, models.ErrConversationNotFound)
+
+			assert.ErrorIs(t, svc.DeleteConversation(ctx, tt.tenantID, tt.userID, started.ConversationID), models.ErrConversationNotFound)
+		})
+	}
+	assert.Len(t, client.requests, 1)
+}
+
+func TestChatService_ModelNotAllowed(t *testing.T) {
+	svc, _, client := newChatTestService(t, clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)))
+
+	_, err := svc.Chat(context.Background(), "tenant-1", "user-1", &ChatRequest{Message: "Hello", Model: "claude-opus"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	assert.Empty(t, client.requests)
+}
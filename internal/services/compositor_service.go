@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// compositorService implements the CompositorService interface
+type compositorService struct {
+	variants   models.ThumbnailVariantRepository
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	brands     BrandService
+	jobs       JobService
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ CompositorService = (*compositorService)(nil)
+
+// NewCompositorService creates a new compositor service
+func NewCompositorService(variants models.ThumbnailVariantRepository, videos models.VideoRepository, workspaces models.WorkspaceRepository, brands BrandService, jobs JobService, clock clock.Clock, logger *logger.Logger) CompositorService {
+	return &compositorService{variants: variants, videos: videos, workspaces: workspaces, brands: brands, jobs: jobs, clock: clock, logger: logger}
+}
+
+// Compose creates a pending variant per layout, rendered with the tenant's
+// brand as it is now so later changes to it leave them alone
+func (s *compositorService) Compose(ctx context.Context, viewer *models.User, videoID string, req *models.ComposeThumbnailsRequest) ([]*models.ThumbnailVariant, error) {
+	tenantID, userID := viewer.TenantID, viewer.ID
+	text := strings.TrimSpace(req.Text)
+	if text == "" || utf8.RuneCountInString(text) > models.MaxThumbnailTextLength {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "thumbnail text must be between 1 and %d characters", models.MaxThumbnailTextLength)
+	}
+	layouts := req.Layouts
+	if len(layouts) == 0 {
+		layouts = transcode.ThumbnailLayouts
+	}
+	seen := make(map[transcode.ThumbnailLayout]bool, len(layouts))
+	for _, layout := range layouts {
+		if !layout.Valid() {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "unknown thumbnail layout %q", layout)
+		}
+		if seen[layout] {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "thumbnail layout %q is listed twice", layout)
+		}
+		seen[layout] = true
+	}
+
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	duration := float64(video.Duration)
+	if video.MediaInfo != nil && video.MediaInfo.DurationSeconds > 0 {
+		duration = video.MediaInfo.DurationSeconds
+	}
+	var frame float64
+	switch {
+	case req.FrameSeconds != nil:
+		frame = *req.FrameSeconds
+		if frame < 0 || (duration > 0 && frame >= duration) {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "the frame must be within the video's %.1f seconds", duration)
+		}
+	case duration > 0:
+		frame = duration / 3
+	default:
+		return nil, fmt.Errorf("%w: pick the frame of the thumbnail until the video's duration is known", models.ErrMediaNotProbed)
+	}
+
+	brand, err := s.brands.Brand(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	variants := make([]*models.ThumbnailVariant, 0, len(layouts))
+	for _, layout := range layouts {
+		variants = append(variants, &models.ThumbnailVariant{
+			TenantID:     tenantID,
+			VideoID:      videoID,
+			FrameSeconds: frame,
+			Text:         text,
+			Layout:       layout,
+			Font:         brand.Font,
+			TextColor:    brand.TextColor,
+			AccentColor:  brand.AccentColor,
+			Status:       string(models.RenditionPending),
+			CreatedBy:    userID,
+		})
+	}
+	if err := s.variants.Create(ctx, variants); err != nil {
+		return nil, fmt.Errorf("failed to create thumbnail variants: %w", err)
+	}
+	for _, variant := range variants {
+		s.jobs.Start(ctx, tenantID, userID, models.ThumbnailJob(variant))
+	}
+	s.logger.Info("Thumbnail variants composed", "tenant_id", tenantID, "video_id", videoID, "variants", len(variants), "frame_seconds", frame)
+	return variants, nil
+}
+
+// Complete records where the worker uploaded a rendered variant
+func (s *compositorService) Complete(ctx context.Context, tenantID, videoID, variantID, imageURL string) (*models.ThumbnailVariant, error) {
+	if imageURL == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "a rendered thumbnail needs an image URL")
+	}
+	variant, err := s.variants.Get(ctx, tenantID, videoID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	variant.Status = string(models.RenditionReady)
+	variant.ImageURL = imageURL
+	variant.ErrorMsg = ""
+	variant.RenderedAt = &now
+	if err := s.variants.Update(ctx, variant); err != nil {
+		return nil, fmt.Errorf("failed to save thumbnail variant: %w", err)
+	}
+	s.jobs.Succeed(ctx, tenantID, models.ThumbnailJob(variant))
+	s.logger.Info("Thumbnail variant rendered", "tenant_id", tenantID, "video_id", videoID, "variant_id", variantID, "layout", variant.Layout)
+	return variant, nil
+}
+
+// Fail records why a variant could not be rendered
+func (s *compositorService) Fail(ctx context.Context, tenantID, videoID, variantID, reason string) error {
+	variant, err := s.variants.Get(ctx, tenantID, videoID, variantID)
+	if err != nil {
+		return err
+	}
+	variant.Status = string(models.RenditionFailed)
+	variant.ErrorMsg = reason
+	if err := s.variants.Update(ctx, variant); err != nil {
+		return fmt.Errorf("failed to save thumbnail variant: %w", err)
+	}
+	s.jobs.Fail(ctx, tenantID, models.ThumbnailJob(variant), reason)
+	s.logger.Error("Thumbnail variant rendering failed", "tenant_id", tenantID, "video_id", videoID, "variant_id", variantID, "reason", reason)
+	return nil
+}
+
+// List returns the video's variants, newest first
+func (s *compositorService) List(ctx context.Context, viewer *models.User, videoID string) ([]*models.ThumbnailVariant, error) {
+	if _, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID); err != nil {
+		return nil, err
+	}
+	variants, err := s.variants.ListByVideo(ctx, viewer.TenantID, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list thumbnail variants: %w", err)
+	}
+	return variants, nil
+}
+
+// Select makes a rendered variant the video's thumbnail
+func (s *compositorService) Select(ctx context.Context, viewer *models.User, videoID, variantID string) (*models.Video, error) {
+	tenantID := viewer.TenantID
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := s.variants.Get(ctx, tenantID, videoID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if !variant.Ready() {
+		return nil, i18n.Errorf(models.ErrConflict, "the thumbnail is not rendered yet")
+	}
+	if err := s.variants.Select(ctx, tenantID, videoID, variantID); err != nil {
+		return nil, err
+	}
+	video.ThumbnailURL = variant.ImageURL
+	if err := s.videos.Update(ctx, video); err != nil {
+		return nil, fmt.Errorf("failed to save video thumbnail: %w", err)
+	}
+	s.logger.Info("Thumbnail variant selected", "tenant_id", tenantID, "video_id", videoID, "variant_id", variantID)
+	return video, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/notify"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+type memoryBrandProfileRepo struct {
+	profiles map[string]*models.BrandProfile
+}
+
+func (r *memoryBrandProfileRepo) Get(ctx context.Context, tenantID string) (*models.BrandProfile, error) {
+	if profile, ok := r.profiles[tenantID]; ok {
+		return profile, nil
+	}
+	return nil, models.ErrBrandProfileNotFound
+}
+
+func (r *memoryBrandProfileRepo) Upsert(ctx context.Context, profile *models.BrandProfile) error {
+	r.profiles[profile.TenantID] = profile
+	return nil
+}
+
+type memoryThumbnailVariantRepo struct {
+	models.ThumbnailVariantRepository
+	variants []*models.ThumbnailVariant
+}
+
+func (r *memoryThumbnailVariantRepo) Create(ctx context.Context, variants []*models.ThumbnailVariant) error {
+	for _, variant := range variants {
+		variant.ID = fmt.Sprintf("variant-%d", len(r.variants)+1)
+		r.variants = append(r.variants, variant)
+	}
+	return nil
+}
+
+func (r *memoryThumbnailVariantRepo) Get(ctx context.Context, tenantID, videoID, id string) (*models.ThumbnailVariant, error) {
+	for _, variant := range r.variants {
+		if variant.TenantID == tenantID && variant.VideoID == videoID && variant.ID == id {
+			return variant, nil
+		}
+	}
+	return nil, models.ErrThumbnailVariantNotFound
+}
+
+func (r *memoryThumbnailVariantRepo) Update(ctx context.Context, variant *models.ThumbnailVariant) error {
+	return nil
+}
+
+func (r *memoryThumbnailVariantRepo) Select(ctx context.Context, tenantID, videoID, id string) error {
+	for _, variant := range r.variants {
+		if variant.TenantID == tenantID && variant.VideoID == videoID {
+			variant.Selected = variant.ID == id
+		}
+	}
+	return nil
+}
+
+func TestBrandService(t *testing.T) {
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{"acme": {ID: "acme", Name: "Acme Studios"}}}
+	svc := NewBrandService(&memoryBrandProfileRepo{profiles: map[string]*models.BrandProfile{}}, tenants, logger.New("error", "test"))
+	ctx := context.Background()
+
+	profile, err := svc.Get(ctx, "acme")
+	require.NoError(t, err)
+	assert.True(t, profile.Default)
+	assert.Equal(t, models.DefaultBrand, profile.Brand())
+
+	_, err = svc.Put(ctx, "acme", "user-1", &models.BrandProfileRequest{Font: "Impact", TextColor: "yellow", AccentColor: "#000000"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Put(ctx, "acme", "user-1", &models.BrandProfileRequest{Font: "Impact'", TextColor: "#FFD700", AccentColor: "#000000"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "a quote would end the filter's font option")
+
+	_, err = svc.Put(ctx, "acme", "user-1", &models.BrandProfileRequest{Font: "Impact", TextColor: "#FFD700", AccentColor: "#000000", LogoURL: "http://cdn.acme.test/logo.png"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "mail clients block plain HTTP images")
+
+	_, err = svc.Put(ctx, "acme", "user-1", &models.BrandProfileRequest{Font: "Impact", TextColor: "#FFD700", AccentColor: "#000000",
+		LogoURL: "https://cdn.acme.test/logo.png", EmailFooter: " Acme Studios, 1 rue de la Paix, Paris \n"})
+	require.NoError(t, err)
+	brand, err := svc.Brand(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, transcode.Brand{Font: "Impact", TextColor: "#FFD700", AccentColor: "#000000"}, brand)
+
+	branding, err := svc.EmailBranding(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, notify.Branding{Name: "Acme Studios", LogoURL: "https://cdn.acme.test/logo.png", AccentColor: "#000000", TextColor: "#FFD700",
+		Footer: "Acme Studios, 1 rue de la Paix, Paris"}, branding)
+}
+
+func TestCompositorService(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	videos := &publicationVideoRepo{videos: map[string]*models.Video{
+		"probed":    {ID: "probed", TenantID: "acme", Duration: 200, MediaInfo: &transcode.MediaInfo{DurationSeconds: 90}},
+		"uploading": {ID: "uploading", TenantID: "acme"},
+	}}
+	brands := &memoryBrandProfileRepo{profiles: map[string]*models.BrandProfile{
+		"acme": {TenantID: "acme", Font: "Impact", TextColor: "#FFD700", AccentColor: "#000000"},
+	}}
+	variants := &memoryThumbnailVariantRepo{}
+	jobRepo, jobs := newTestJobs(clock.NewFake(now))
+	log := logger.New("error", "test")
+	svc := NewCompositorService(variants, videos, nil, NewBrandService(brands, &memoryTenantRepo{}, log), jobs, clock.NewFake(now), log)
+	ctx := context.Background()
+	viewer := &models.User{ID: "user-1", TenantID: "acme"}
+
+	_, err := svc.Compose(ctx, viewer, "probed", &models.ComposeThumbnailsRequest{Text: "This hook is far too long to read on a thumbnail"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Compose(ctx, viewer, "probed", &models.ComposeThumbnailsRequest{Text: "Who did it?", Layouts: []transcode.ThumbnailLayout{"poster"}})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	late := 120.0
+	_, err = svc.Compose(ctx, viewer, "probed", &models.ComposeThumbnailsRequest{Text: "Who did it?", FrameSeconds: &late})
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "the frame is past the probed duration")
+	_, err = svc.Compose(ctx, viewer, "uploading", &models.ComposeThumbnailsRequest{Text: "Who did it?"})
+	assert.ErrorIs(t, err, models.ErrMediaNotProbed)
+
+	composed, err := svc.Compose(ctx, viewer, "probed", &models.ComposeThumbnailsRequest{Text: "  Who did it?  "})
+	require.NoError(t, err)
+	require.Len(t, composed, len(transcode.ThumbnailLayouts))
+	assert.Equal(t, "Who did it?", composed[0].Text)
+	assert.Equal(t, 30.0, composed[0].FrameSeconds, "a third into the video")
+	assert.Equal(t, "Impact", composed[0].Font)
+	assert.Equal(t, string(models.RenditionPending), composed[0].Status)
+	require.Len(t, jobRepo.jobs, len(composed))
+	assert.Equal(t, string(models.JobThumbnail), jobRepo.jobs[0].Type)
+
+	brands.profiles["acme"].Font = "Anton"
+	assert.Equal(t, "Impact", composed[0].Font, "variants keep the brand they were composed with")
+
+	_, err = svc.Select(ctx, viewer, "probed", composed[0].ID)
+	assert.ErrorIs(t, err, models.ErrConflict, "only rendered variants can be selected")
+
+	_, err = svc.Complete(ctx, "acme", "probed", composed[0].ID, "https://cdn/thumb-banner.jpg")
+	require.NoError(t, err)
+	_, err = svc.Complete(ctx, "acme", "probed", composed[1].ID, "https://cdn/thumb-outline.jpg")
+	require.NoError(t, err)
+	require.NoError(t, svc.Fail(ctx, "acme", "probed", composed[2].ID, "font not installed"))
+	assert.Equal(t, string(models.RenditionFailed), composed[2].Status)
+	assert.Equal(t, string(models.JobFailed), jobRepo.jobs[2].State)
+
+	video, err := svc.Select(ctx, viewer, "probed", composed[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn/thumb-banner.jpg", video.ThumbnailURL)
+	video, err = svc.Select(ctx, viewer, "probed", composed[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn/thumb-outline.jpg", video.ThumbnailURL)
+	assert.False(t, composed[0].Selected)
+	assert.True(t, composed[1].Selected)
+
+	_, err = svc.Select(ctx, viewer, "probed", "variant-9")
+	assert.ErrorIs(t, err, models.ErrThumbnailVariantNotFound)
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// adminPageSize is how many users are read at a time to find the admins
+const adminPageSize = 100
+
+// heldPublicationStatuses are the statuses of the publications held while
+// their workspace must be reconnected
+var heldPublicationStatuses = []models.PublicationStatus{models.PublicationScheduled, models.PublicationStaged}
+
+// connectionService implements the ConnectionService interface
+type connectionService struct {
+	workspaces models.WorkspaceRepository
+	jobs       models.PublicationJobRepository
+	users      models.UserRepository
+	platforms  *partners.Service
+	notify     NotificationService
+	baseURL    string
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ ConnectionService = (*connectionService)(nil)
+
+// NewConnectionService creates a connection service. Reconnect links in
+// notifications start with baseURL, the public URL of the API, and are
+// relative when it is empty.
+func NewConnectionService(workspaces models.WorkspaceRepository, jobs models.PublicationJobRepository, users models.UserRepository, platforms *partners.Service, notify NotificationService, baseURL string, clock clock.Clock, logger *logger.Logger) ConnectionService {
+	return &connectionService{
+		workspaces: workspaces,
+		jobs:       jobs,
+		users:      users,
+		platforms:  platforms,
+		notify:     notify,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+// NeedsReconnect marks the workspace's connection to the platform, holds its
+// queued publications and notifies the workspace's owner and the tenant's
+// admins. A connection already marked is left as it is.
+func (s *connectionService) NeedsReconnect(ctx context.Context, tenantID, workspaceID string, platform models.Platform) error {
+	ctx = context.WithoutCancel(ctx)
+	ws, err := s.workspaces.GetByID(ctx, tenantID, workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if !ws.MarkReconnectNeeded(platform) {
+		return nil
+	}
+	if err := s.workspaces.Update(ctx, ws); err != nil {
+		return fmt.Errorf("failed to mark workspace for reconnection: %w", err)
+	}
+
+	jobs, err := s.jobs.GetByWorkspace(ctx, tenantID, workspaceID, platform, heldPublicationStatuses)
+	if err != nil {
+		return fmt.Errorf("failed to list queued publications: %w", err)
+	}
+	for _, job := range jobs {
+		job.Status = string(models.PublicationAwaitingReconnect)
+		job.UpdatedAt = s.clock.Now()
+		if err := s.jobs.Update(ctx, job); err != nil {
+			return fmt.Errorf("failed to hold publication %s: %w", job.ID, err)
+		}
+	}
+	s.logger.Warn("Workspace must be reconnected", "tenant_id", tenantID, "workspace_id", workspaceID,
+		"platform", platform, "held", len(jobs))
+	s.notifyReconnect(ctx, ws, platform)
+	return nil
+}
+
+// Reconnected checks the platform accepts the workspace's credentials again,
+// then clears the mark and resumes the held publications where they stopped
+func (s *connectionService) Reconnected(ctx context.Context, user *models.User, workspaceID string, platform models.Platform) (int, error) {
+	if !platform.Valid() {
+		return 0, fmt.Errorf("%w: %s", models.ErrInvalidPlatform, platform)
+	}
+	ws, err := s.workspaces.GetByID(ctx, user.TenantID, workspaceID)
+	if err != nil {
+		return 0, err
+	}
+	if ws.UserID != user.ID && models.UserRole(user.Role) != models.RoleAdmin {
+		return 0, i18n.Errorf(models.ErrForbidden, "only the workspace's owner or an admin can reconnect it")
+	}
+	if !ws.Connected(platform) {
+		return 0, i18n.Errorf(models.ErrConflict, "the workspace holds no %s credentials", platform)
+	}
+	if err := s.platforms.Authenticate(ctx, ws, platform); err != nil {
+		return 0, fmt.Errorf("failed to authenticate with %s: %w", platform, err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	ws.ClearReconnectNeeded(platform)
+	if err := s.workspaces.Update(ctx, ws); err != nil {
+		return 0, fmt.Errorf("failed to save reconnected workspace: %w", err)
+	}
+	jobs, err := s.jobs.GetByWorkspace(ctx, user.TenantID, workspaceID, platform,
+		[]models.PublicationStatus{models.PublicationAwaitingReconnect})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list held publications: %w", err)
+	}
+	for _, job := range jobs {
+		job.Status = string(models.PublicationScheduled)
+		if job.StagedAt.Valid {
+			job.Status = string(models.PublicationStaged)
+		}
+		job.ClearFailure()
+		job.UpdatedAt = s.clock.Now()
+		if err := s.jobs.Update(ctx, job); err != nil {
+			return 0, fmt.Errorf("failed to resume publication %s: %w", job.ID, err)
+		}
+	}
+	s.logger.Info("Workspace reconnected", "tenant_id", user.TenantID, "user_id", user.ID, "workspace_id", workspaceID,
+		"platform", platform, "resumed", len(jobs))
+	return len(jobs), nil
+}
+
+// notifyReconnect sends the reconnect link to the workspace's owner and the
+// tenant's active admins. Failures are logged, the connection stays marked.
+func (s *connectionService) notifyReconnect(ctx context.Context, ws *models.Workspace, platform models.Platform) {
+	link := fmt.Sprintf("%s/api/v1/platforms/%s/auth?workspace_id=%s", s.baseURL, platform, ws.ID)
+	subject := i18n.M("Reconnect %s", platform)
+	message := i18n.M("%s rejected the authorization of workspace %s. Its publications there are on hold until it is reconnected: %s",
+		platform, ws.Name, link)
+	notified := map[string]bool{ws.UserID: true}
+	send := func(userID string) {
+		if err := s.notify.Notify(ctx, ws.TenantID, userID, subject, message); err != nil {
+			s.logger.Error("Failed to notify reconnect", "error", err, "workspace_id", ws.ID, "user_id", userID)
+		}
+	}
+	send(ws.UserID)
+	for offset := 0; ; offset += adminPageSize {
+		users, err := s.users.List(ctx, ws.TenantID, adminPageSize, offset)
+		if err != nil {
+			s.logger.Error("Failed to list admins to notify", "error", err, "tenant_id", ws.TenantID, "workspace_id", ws.ID)
+			return
+		}
+		for _, user := range users {
+			if notified[user.ID] || !user.IsActive() || models.UserRole(user.Role) != models.RoleAdmin {
+				continue
+			}
+			notified[user.ID] = true
+			send(user.ID)
+		}
+		if len(users) < adminPageSize {
+			return
+		}
+	}
+}
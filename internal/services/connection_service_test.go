@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	pkgpartners "github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// reconnectWorkspaceRepo counts the workspaces saved
+type reconnectWorkspaceRepo struct {
+	visibilityWorkspaceRepo
+	updates int
+}
+
+func (r *reconnectWorkspaceRepo) Update(ctx context.Context, workspace *models.Workspace) error {
+	r.updates++
+	return nil
+}
+
+// reconnectJobRepo finds the jobs of approvalJobRepo by workspace
+type reconnectJobRepo struct {
+	approvalJobRepo
+}
+
+func (r *reconnectJobRepo) GetByWorkspace(ctx context.Context, tenantID, workspaceID string, platform models.Platform, statuses []models.PublicationStatus) ([]*models.PublicationJob, error) {
+	var jobs []*models.PublicationJob
+	for _, job := range r.jobs {
+		for _, status := range statuses {
+			if job.TenantID == tenantID && job.WorkspaceID == workspaceID && job.Platform == string(platform) && job.Status == string(status) {
+				copied := *job
+				jobs = append(jobs, &copied)
+			}
+		}
+	}
+	return jobs, nil
+}
+
+// authClient accepts the workspace's credentials unless authErr is set
+type authClient struct {
+	pkgpartners.Client
+	authErr error
+}
+
+func (c *authClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
+	return c.authErr
+}
+
+type connectionFixture struct {
+	svc        ConnectionService
+	workspace  *models.Workspace
+	workspaces *reconnectWorkspaceRepo
+	jobs       *reconnectJobRepo
+	notified   *recordingNotifications
+	client     *authClient
+}
+
+func newConnectionFixture(t *testing.T) *connectionFixture {
+	t.Helper()
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	f := &connectionFixture{
+		workspace: &models.Workspace{ID: "ws-1", TenantID: "acme", UserID: "editor", Name: "Main channel",
+			CredentialsPath: "/secrets/yt.json", TokenDir: "/tokens/ws-1"},
+		jobs:     &reconnectJobRepo{},
+		notified: &recordingNotifications{subjects: map[string][]string{}},
+		client:   &authClient{},
+	}
+	f.workspaces = &reconnectWorkspaceRepo{visibilityWorkspaceRepo: visibilityWorkspaceRepo{workspaces: []*models.Workspace{f.workspace}}}
+	f.jobs.jobs = []*models.PublicationJob{
+		{ID: "scheduled", TenantID: "acme", WorkspaceID: "ws-1", Platform: "youtube", Status: string(models.PublicationScheduled)},
+		{ID: "staged", TenantID: "acme", WorkspaceID: "ws-1", Platform: "youtube", Status: string(models.PublicationStaged),
+			StagedAt: sql.NullTime{Time: now.Add(-time.Hour), Valid: true}},
+		{ID: "tiktok", TenantID: "acme", WorkspaceID: "ws-1", Platform: "tiktok", Status: string(models.PublicationScheduled)},
+		{ID: "done", TenantID: "acme", WorkspaceID: "ws-1", Platform: "youtube", Status: string(models.PublicationCompleted)},
+	}
+	users := &approvalUserRepo{identityUserRepo{users: map[string]*models.User{
+		"editor": {ID: "editor", TenantID: "acme", Role: "editor", Status: string(models.StatusActive)},
+		"admin":  {ID: "admin", TenantID: "acme", Role: "admin", Status: string(models.StatusActive)},
+		"away":   {ID: "away", TenantID: "acme", Role: "admin", Status: string(models.StatusSuspended)},
+		"viewer": {ID: "viewer", TenantID: "acme", Role: "viewer", Status: string(models.StatusActive)},
+	}}}
+	platforms := partners.NewService(func(string) (pkgpartners.Client, error) { return f.client, nil })
+	f.svc = NewConnectionService(f.workspaces, f.jobs, users, platforms, f.notified, "https://api.example.com/",
+		clock.NewFake(now), logger.New("error", "test"))
+	return f
+}
+
+func (f *connectionFixture) statuses() map[string]string {
+	statuses := map[string]string{}
+	for _, job := range f.jobs.jobs {
+		statuses[job.ID] = job.Status
+	}
+	return statuses
+}
+
+func TestConnectionService_NeedsReconnect(t *testing.T) {
+	f := newConnectionFixture(t)
+	ctx := context.Background()
+
+	require.NoError(t, f.svc.NeedsReconnect(ctx, "acme", "ws-1", models.PlatformYouTube))
+	assert.True(t, f.workspace.ReconnectNeeded(models.PlatformYouTube))
+	assert.Equal(t, map[string]string{
+		"scheduled": "awaiting_reconnect",
+		"staged":    "awaiting_reconnect",
+		"tiktok":    "scheduled",
+		"done":      "completed",
+	}, f.statuses())
+	assert.Equal(t, map[string][]string{"editor": {"Reconnect youtube"}, "admin": {"Reconnect youtube"}}, f.notified.subjects)
+
+	// Told once, however many publications hit the rejected token
+	require.NoError(t, f.svc.NeedsReconnect(ctx, "acme", "ws-1", models.PlatformYouTube))
+	assert.Equal(t, 1, f.workspaces.updates)
+	assert.Len(t, f.notified.subjects["editor"], 1)
+}
+
+func TestConnectionService_Reconnected(t *testing.T) {
+	f := newConnectionFixture(t)
+	ctx := context.Background()
+	editor := &models.User{ID: "editor", TenantID: "acme", Role: "editor"}
+	require.NoError(t, f.svc.NeedsReconnect(ctx, "acme", "ws-1", models.PlatformYouTube))
+
+	f.client.authErr = fmt.Errorf("youtube: %w", pkgpartners.ErrInvalidToken)
+	_, err := f.svc.Reconnected(ctx, editor, "ws-1", models.PlatformYouTube)
+	assert.ErrorIs(t, err, pkgpartners.ErrInvalidToken)
+	assert.True(t, f.workspace.ReconnectNeeded(models.PlatformYouTube), "still rejected")
+
+	_, err = f.svc.Reconnected(ctx, &models.User{ID: "viewer", TenantID: "acme", Role: "viewer"}, "ws-1", models.PlatformYouTube)
+	assert.ErrorIs(t, err, models.ErrForbidden)
+	_, err = f.svc.Reconnected(ctx, editor, "ws-1", models.PlatformTikTok)
+	assert.ErrorIs(t, err, models.ErrConflict, "no TikTok credentials")
+
+	f.client.authErr = nil
+	resumed, err := f.svc.Reconnected(ctx, editor, "ws-1", models.PlatformYouTube)
+	require.NoError(t, err)
+	assert.Equal(t, 2, resumed)
+	assert.False(t, f.workspace.ReconnectNeeded(models.PlatformYouTube))
+	assert.Equal(t, "scheduled", f.statuses()["scheduled"])
+	assert.Equal(t, "staged", f.statuses()["staged"], "resumed where it stopped")
+}
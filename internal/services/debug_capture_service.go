@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+const (
+	// defaultDebugCaptureDuration is the capture window when an admin does not ask for one
+	defaultDebugCaptureDuration = time.Hour
+
+	// debugCaptureCacheTTL is how long a tenant's capture state is cached.
+	// Other replicas notice a session enabled or disabled within this delay.
+	debugCaptureCacheTTL = 15 * time.Second
+)
+
+// debugCaptureService implements the DebugCaptureService interface
+type debugCaptureService struct {
+	captures  models.DebugCaptureRepository
+	maxWindow time.Duration
+	retention time.Duration
+	clock     clock.Clock
+	logger    *logger.Logger
+
+	mu       sync.Mutex
+	sessions map[string]cachedCaptureSession
+}
+
+// cachedCaptureSession is a tenant's capture state as last read
+type cachedCaptureSession struct {
+	id        string // Empty when the tenant is not capturing
+	expiresAt time.Time
+	readAt    time.Time
+}
+
+var _ DebugCaptureService = (*debugCaptureService)(nil)
+
+// NewDebugCaptureService creates a new debug capture service instance.
+// Sessions last at most maxWindow and captures are kept for retention.
+func NewDebugCaptureService(captures models.DebugCaptureRepository, maxWindow, retention time.Duration, clock clock.Clock, logger *logger.Logger) DebugCaptureService {
+	return &debugCaptureService{
+		captures:  captures,
+		maxWindow: maxWindow,
+		retention: retention,
+		clock:     clock,
+		logger:    logger,
+		sessions:  make(map[string]cachedCaptureSession),
+	}
+}
+
+// Enable starts capturing the admin's tenant
+func (s *debugCaptureService) Enable(ctx context.Context, admin *models.User, req *models.EnableDebugCaptureRequest) (*models.DebugCaptureSession, error) {
+	duration := defaultDebugCaptureDuration
+	if req.DurationMinutes > 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	if duration > s.maxWindow {
+		if req.DurationMinutes > 0 {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "debug capture cannot last more than %s", s.maxWindow)
+		}
+		duration = s.maxWindow
+	}
+
+	now := s.clock.Now()
+	if err := s.captures.EndSessions(ctx, admin.TenantID, now); err != nil {
+		return nil, fmt.Errorf("failed to end debug capture: %w", err)
+	}
+	session := &models.DebugCaptureSession{
+		TenantID:  admin.TenantID,
+		UserID:    admin.ID,
+		Reason:    req.Reason,
+		ExpiresAt: now.Add(duration),
+	}
+	if err := s.captures.CreateSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to start debug capture: %w", err)
+	}
+	s.cache(admin.TenantID, cachedCaptureSession{id: session.ID, expiresAt: session.ExpiresAt, readAt: now})
+
+	s.logger.Warn("Debug capture enabled",
+		"tenant_id", admin.TenantID,
+		"user_id", admin.ID,
+		"session_id", session.ID,
+		"expires_at", session.ExpiresAt)
+	return session, nil
+}
+
+// Disable stops capturing the tenant
+func (s *debugCaptureService) Disable(ctx context.Context, tenantID string) error {
+	now := s.clock.Now()
+	if err := s.captures.EndSessions(ctx, tenantID, now); err != nil {
+		return fmt.Errorf("failed to end debug capture: %w", err)
+	}
+	s.cache(tenantID, cachedCaptureSession{readAt: now})
+
+	s.logger.Info("Debug capture disabled", "tenant_id", tenantID)
+	return nil
+}
+
+// Status returns the tenant's running session
+func (s *debugCaptureService) Status(ctx context.Context, tenantID string) (*models.DebugCaptureSession, error) {
+	return s.captures.GetActiveSession(ctx, tenantID, s.clock.Now())
+}
+
+// ActiveSession returns the ID of the tenant's running session, if any. A
+// failed lookup counts as not capturing; it is cached too so a struggling
+// database is not asked again on every request.
+func (s *debugCaptureService) ActiveSession(ctx context.Context, tenantID string) (string, bool) {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	cached, found := s.sessions[tenantID]
+	s.mu.Unlock()
+	if !found || now.Sub(cached.readAt) >= debugCaptureCacheTTL {
+		cached = cachedCaptureSession{readAt: now}
+		session, err := s.captures.GetActiveSession(ctx, tenantID, now)
+		switch {
+		case err == nil:
+			cached.id, cached.expiresAt = session.ID, session.ExpiresAt
+		case !errors.Is(err, models.ErrDebugCaptureInactive):
+			s.logger.Error("Failed to read debug capture session", "error", err, "tenant_id", tenantID)
+		}
+		s.cache(tenantID, cached)
+	}
+
+	if cached.id == "" || !now.Before(cached.expiresAt) {
+		return "", false
+	}
+	return cached.id, true
+}
+
+// Record stores a capture, to be deleted once the retention has passed
+func (s *debugCaptureService) Record(ctx context.Context, capture *models.DebugCapture) error {
+	capture.ExpiresAt = s.clock.Now().Add(s.retention)
+	if err := s.captures.Create(ctx, capture); err != nil {
+		s.logger.Error("Failed to record debug capture",
+			"error", err,
+			"tenant_id", capture.TenantID,
+			"session_id", capture.SessionID,
+			"request_id", capture.RequestID)
+		return fmt.Errorf("failed to record debug capture: %w", err)
+	}
+	return nil
+}
+
+// List returns the tenant's captures, newest first
+func (s *debugCaptureService) List(ctx context.Context, tenantID, sessionID string, limit, offset int) ([]*models.DebugCapture, error) {
+	return s.captures.List(ctx, tenantID, sessionID, limit, offset)
+}
+
+// Get returns a capture with its headers and bodies
+func (s *debugCaptureService) Get(ctx context.Context, tenantID, id string) (*models.DebugCapture, error) {
+	return s.captures.GetByID(ctx, tenantID, id)
+}
+
+// PurgeExpired deletes captures past their retention, and sessions that
+// ended long enough ago for all their captures to be gone
+func (s *debugCaptureService) PurgeExpired(ctx context.Context) (int64, error) {
+	now := s.clock.Now()
+	deleted, err := s.captures.DeleteExpired(ctx, now, now.Add(-s.retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired debug captures: %w", err)
+	}
+	s.logger.Info("Expired debug captures purged", "count", deleted)
+	return deleted, nil
+}
+
+func (s *debugCaptureService) cache(tenantID string, session cachedCaptureSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[tenantID] = session
+}
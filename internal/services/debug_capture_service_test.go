@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memoryCaptureRepo keeps debug capture sessions and captures in memory
+type memoryCaptureRepo struct {
+	models.DebugCaptureRepository
+	sessions []*models.DebugCaptureSession
+	captures []*models.DebugCapture
+	lookups  int
+}
+
+func (r *memoryCaptureRepo) CreateSession(ctx context.Context, session *models.DebugCaptureSession) error {
+	session.ID = "session-" + string(rune('a'+len(r.sessions)))
+	r.sessions = append(r.sessions, session)
+	return nil
+}
+
+func (r *memoryCaptureRepo) GetActiveSession(ctx context.Context, tenantID string, at time.Time) (*models.DebugCaptureSession, error) {
+	r.lookups++
+	for _, s := range r.sessions {
+		if s.TenantID == tenantID && s.EndedAt == nil && s.ExpiresAt.After(at) {
+			return s, nil
+		}
+	}
+	return nil, models.ErrDebugCaptureInactive
+}
+
+func (r *memoryCaptureRepo) EndSessions(ctx context.Context, tenantID string, at time.Time) error {
+	for _, s := range r.sessions {
+		if s.TenantID == tenantID && s.EndedAt == nil {
+			s.EndedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *memoryCaptureRepo) Create(ctx context.Context, capture *models.DebugCapture) error {
+	r.captures = append(r.captures, capture)
+	return nil
+}
+
+// captureNow is when the debug capture tests run
+var captureNow = time.Date(2026, 5, 4, 15, 0, 0, 0, time.UTC)
+
+func TestDebugCaptureService_Enable(t *testing.T) {
+	repo := &memoryCaptureRepo{}
+	svc := NewDebugCaptureService(repo, 24*time.Hour, 7*24*time.Hour, clock.NewFake(captureNow), logger.New("error", "test"))
+	admin := &models.User{ID: "admin-1", TenantID: "acme", Role: "admin"}
+
+	first, err := svc.Enable(context.Background(), admin, &models.EnableDebugCaptureRequest{Reason: "Ticket 88"})
+	require.NoError(t, err)
+	assert.Equal(t, "acme", first.TenantID)
+	assert.Equal(t, captureNow.Add(defaultDebugCaptureDuration), first.ExpiresAt)
+
+	second, err := svc.Enable(context.Background(), admin, &models.EnableDebugCaptureRequest{Reason: "Ticket 88", DurationMinutes: 120})
+	require.NoError(t, err)
+	assert.NotNil(t, first.EndedAt, "a new session replaces the running one")
+	assert.Nil(t, second.EndedAt)
+
+	_, err = svc.Enable(context.Background(), admin, &models.EnableDebugCaptureRequest{Reason: "Ticket 88", DurationMinutes: 25 * 60})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+}
+
+func TestDebugCaptureService_ActiveSessionIsCached(t *testing.T) {
+	repo := &memoryCaptureRepo{}
+	now := clock.NewFake(captureNow)
+	svc := NewDebugCaptureService(repo, 24*time.Hour, 7*24*time.Hour, now, logger.New("error", "test"))
+
+	for i := 0; i < 3; i++ {
+		_, ok := svc.ActiveSession(context.Background(), "acme")
+		assert.False(t, ok)
+	}
+	assert.Equal(t, 1, repo.lookups, "the tenant's state is read once per cache period")
+
+	session, err := svc.Enable(context.Background(), &models.User{ID: "admin-1", TenantID: "acme"}, &models.EnableDebugCaptureRequest{Reason: "Ticket 88"})
+	require.NoError(t, err)
+	id, ok := svc.ActiveSession(context.Background(), "acme")
+	assert.True(t, ok, "enabling takes effect at once on this instance")
+	assert.Equal(t, session.ID, id)
+
+	require.NoError(t, svc.Disable(context.Background(), "acme"))
+	_, ok = svc.ActiveSession(context.Background(), "acme")
+	assert.False(t, ok)
+	assert.Equal(t, 1, repo.lookups)
+
+	now.Advance(debugCaptureCacheTTL + time.Second)
+	_, ok = svc.ActiveSession(context.Background(), "acme")
+	assert.False(t, ok)
+	assert.Equal(t, 2, repo.lookups, "the state is read again once the cache period is over")
+}
+
+func TestDebugCaptureService_RecordSetsExpiry(t *testing.T) {
+	repo := &memoryCaptureRepo{}
+	svc := NewDebugCaptureService(repo, 24*time.Hour, 48*time.Hour, clock.NewFake(captureNow), logger.New("error", "test"))
+
+	require.NoError(t, svc.Record(context.Background(), &models.DebugCapture{TenantID: "acme", SessionID: "session-a"}))
+	require.Len(t, repo.captures, 1)
+	assert.Equal(t, captureNow.Add(48*time.Hour), repo.captures[0].ExpiresAt)
+}
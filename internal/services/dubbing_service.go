@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// dubScriptPrompt translates the timed lines of a transcript
+const dubScriptPrompt = "localization/dub_script"
+
+// dubScriptLine is a numbered line of a translated script
+var dubScriptLine = regexp.MustCompile(`^\s*(\d+)\s*[:.)]\s*(.*)$`)
+
+// dubbingService implements the DubbingService interface
+type dubbingService struct {
+	tracks      models.AudioTrackRepository
+	transcripts models.TranscriptRepository
+	videos      models.VideoRepository
+	workspaces  models.WorkspaceRepository
+	ai          AIService
+	renditions  RenditionService
+	jobs        JobService
+	clock       clock.Clock
+	logger      *logger.Logger
+}
+
+var _ DubbingService = (*dubbingService)(nil)
+
+// NewDubbingService creates a new dubbing service, translating transcripts
+// with the AI and muxing the synthesized tracks into the renditions
+func NewDubbingService(tracks models.AudioTrackRepository, transcripts models.TranscriptRepository, videos models.VideoRepository, workspaces models.WorkspaceRepository, ai AIService, renditions RenditionService, jobs JobService, clock clock.Clock, logger *logger.Logger) DubbingService {
+	return &dubbingService{
+		tracks:      tracks,
+		transcripts: transcripts,
+		videos:      videos,
+		workspaces:  workspaces,
+		ai:          ai,
+		renditions:  renditions,
+		jobs:        jobs,
+		clock:       clock,
+		logger:      logger,
+	}
+}
+
+// Dub plans a track in the language, scripted from the video's transcript in
+// it when one was uploaded, or translated from its original transcript
+func (s *dubbingService) Dub(ctx context.Context, viewer *models.User, videoID string, req *models.DubRequest) (*models.AudioTrack, error) {
+	tenantID, userID := viewer.TenantID, viewer.ID
+	language := strings.ToLower(strings.TrimSpace(req.Language))
+	if transcode.LanguageCode(language) == "und" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "unknown language %s", req.Language)
+	}
+	if _, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID); err != nil {
+		return nil, err
+	}
+	source, err := s.transcripts.GetByVideoID(ctx, tenantID, videoID, strings.ToLower(req.SourceLanguage))
+	if errors.Is(err, models.ErrTranscriptNotFound) {
+		return nil, i18n.Errorf(models.ErrConflict, "the video needs a transcript to be dubbed")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcript: %w", err)
+	}
+	if source.Language == language {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the video is already in %s", language)
+	}
+
+	script, err := s.transcripts.GetByVideoID(ctx, tenantID, videoID, language)
+	switch {
+	case errors.Is(err, models.ErrTranscriptNotFound) || err == nil && script.Source == models.TranscriptSourceTranslated:
+		if err := s.translate(ctx, source, language); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get transcript: %w", err)
+	}
+
+	track, err := s.tracks.Get(ctx, tenantID, videoID, language)
+	if errors.Is(err, models.ErrAudioTrackNotFound) {
+		track = &models.AudioTrack{TenantID: tenantID, VideoID: videoID, Language: language}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get audio track: %w", err)
+	}
+	*track = models.AudioTrack{
+		ID:             track.ID,
+		TenantID:       tenantID,
+		VideoID:        videoID,
+		Language:       language,
+		SourceLanguage: source.Language,
+		Voice:          req.Voice,
+		Status:         string(models.RenditionPending),
+		CreatedBy:      userID,
+		CreatedAt:      track.CreatedAt,
+	}
+	if err := s.tracks.Upsert(ctx, track); err != nil {
+		return nil, fmt.Errorf("failed to save audio track: %w", err)
+	}
+	s.jobs.Start(ctx, tenantID, userID, models.DubbingJob(track))
+
+	s.logger.Info("Video dubbing planned", "tenant_id", tenantID, "user_id", userID, "video_id", videoID,
+		"language", language, "source_language", source.Language)
+	return track, nil
+}
+
+// translate stores the transcript translated into the language, keeping the
+// timing of its segments for the voice to follow
+func (s *dubbingService) translate(ctx context.Context, source *models.Transcript, language string) error {
+	segments := source.GetSegments()
+	if len(segments) == 0 {
+		return i18n.Errorf(models.ErrConflict, "the video needs a transcript to be dubbed")
+	}
+	lines := make([]string, len(segments))
+	for i, segment := range segments {
+		lines[i] = fmt.Sprintf("%d [%.1fs] %s", i+1, segment.End-segment.Start, strings.TrimSpace(segment.Text))
+	}
+
+	result, err := s.ai.ProcessWithBedrock(ctx, dubScriptPrompt, map[string]interface{}{
+		"lines":           strings.Join(lines, "\n"),
+		"source_language": source.Language,
+		"target_language": language,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to translate transcript: %w", err)
+	}
+	output, _ := result["result"].(string)
+	translated, err := parseDubScript(output, segments)
+	if err != nil {
+		return fmt.Errorf("failed to translate transcript: %w", err)
+	}
+
+	script := &models.Transcript{
+		TenantID: source.TenantID,
+		VideoID:  source.VideoID,
+		Language: language,
+		Source:   models.TranscriptSourceTranslated,
+	}
+	if err := script.SetSegments(translated); err != nil {
+		return err
+	}
+	if err := s.transcripts.Upsert(ctx, script); err != nil {
+		return fmt.Errorf("failed to save translated transcript: %w", err)
+	}
+	return nil
+}
+
+// parseDubScript returns the segments with the text of the script's numbered
+// lines, failing when the model left one out
+func parseDubScript(output string, segments []models.TranscriptSegment) ([]models.TranscriptSegment, error) {
+	texts := make(map[int]string, len(segments))
+	for _, line := range strings.Split(output, "\n") {
+		match := dubScriptLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(match[1])
+		texts[n] = strings.TrimSpace(match[2])
+	}
+
+	translated := make([]models.TranscriptSegment, len(segments))
+	for i, segment := range segments {
+		text := texts[i+1]
+		if text == "" {
+			return nil, fmt.Errorf("the translation left out line %d", i+1)
+		}
+		segment.Text = text
+		translated[i] = segment
+	}
+	return translated, nil
+}
+
+// Complete records where the worker stored a synthesized track and plans
+// the renditions carrying dubs again, for the worker to mux it in
+func (s *dubbingService) Complete(ctx context.Context, tenantID, videoID, language string, out *models.RenditionOutput) (*models.AudioTrack, error) {
+	if out.FilePath == "" && out.FileURL == "" && out.S3Key == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "an audio track needs a file path, URL or S3 key")
+	}
+	track, err := s.tracks.Get(ctx, tenantID, videoID, language)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	track.Status = string(models.RenditionReady)
+	track.FilePath = out.FilePath
+	track.FileURL = out.FileURL
+	track.S3Key = out.S3Key
+	track.FileSize = out.FileSize
+	track.ErrorMsg = ""
+	track.SynthesizedAt = &now
+	if err := s.tracks.Upsert(ctx, track); err != nil {
+		return nil, fmt.Errorf("failed to save audio track: %w", err)
+	}
+	s.jobs.Succeed(ctx, tenantID, models.DubbingJob(track))
+	s.logger.Info("Audio track synthesized", "tenant_id", tenantID, "video_id", videoID, "language", language, "file_size", out.FileSize)
+
+	s.remux(ctx, tenantID, videoID)
+	return track, nil
+}
+
+// Fail records why a track could not be synthesized
+func (s *dubbingService) Fail(ctx context.Context, tenantID, videoID, language, reason string) error {
+	track, err := s.tracks.Get(ctx, tenantID, videoID, language)
+	if err != nil {
+		return err
+	}
+	track.Status = string(models.RenditionFailed)
+	track.ErrorMsg = reason
+	if err := s.tracks.Upsert(ctx, track); err != nil {
+		return fmt.Errorf("failed to save audio track: %w", err)
+	}
+	s.jobs.Fail(ctx, tenantID, models.DubbingJob(track), reason)
+	s.logger.Error("Audio track synthesis failed", "tenant_id", tenantID, "video_id", videoID, "language", language, "reason", reason)
+	return nil
+}
+
+// List returns the video's audio tracks
+func (s *dubbingService) List(ctx context.Context, viewer *models.User, videoID string) ([]*models.AudioTrack, error) {
+	if _, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID); err != nil {
+		return nil, err
+	}
+	tracks, err := s.tracks.ListByVideo(ctx, viewer.TenantID, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audio tracks: %w", err)
+	}
+	return tracks, nil
+}
+
+// Delete removes a track, muxing the renditions again without it. Its
+// translated transcript is kept.
+func (s *dubbingService) Delete(ctx context.Context, viewer *models.User, videoID, language string) error {
+	tenantID := viewer.TenantID
+	if _, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID); err != nil {
+		return err
+	}
+	track, err := s.tracks.Get(ctx, tenantID, videoID, language)
+	if err != nil {
+		return err
+	}
+	if err := s.tracks.Delete(ctx, tenantID, videoID, language); err != nil {
+		return err
+	}
+	s.logger.Info("Audio track deleted", "tenant_id", tenantID, "video_id", videoID, "language", language)
+	if track.Ready() {
+		s.remux(ctx, tenantID, videoID)
+	}
+	return nil
+}
+
+// Dubs returns the ready tracks to mux into the video's rendition of the
+// profile, none when no platform taking it offers a choice of audio tracks
+func (s *dubbingService) Dubs(ctx context.Context, tenantID, videoID, profile string) ([]*models.AudioTrack, error) {
+	if !partners.MultiAudio(profile) {
+		return nil, nil
+	}
+	tracks, err := s.tracks.ListByVideo(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audio tracks: %w", err)
+	}
+	ready := make([]*models.AudioTrack, 0, len(tracks))
+	for _, track := range tracks {
+		if track.Ready() {
+			ready = append(ready, track)
+		}
+	}
+	return ready, nil
+}
+
+// remux plans the renditions carrying dubs again. The track is saved either
+// way, so a failure is logged.
+func (s *dubbingService) remux(ctx context.Context, tenantID, videoID string) {
+	if _, err := s.renditions.PlanDubbed(ctx, tenantID, videoID); err != nil {
+		s.logger.Error("Failed to plan dubbed renditions", "error", err, "tenant_id", tenantID, "video_id", videoID)
+	}
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// memoryTranscriptRepo keeps one transcript per video and language
+type memoryTranscriptRepo struct {
+	models.TranscriptRepository
+	transcripts map[string]*models.Transcript
+}
+
+func (r *memoryTranscriptRepo) GetByVideoID(ctx context.Context, tenantID, videoID, language string) (*models.Transcript, error) {
+	if language == "" {
+		language = "en"
+	}
+	transcript, ok := r.transcripts[videoID+"/"+language]
+	if !ok {
+		return nil, models.ErrTranscriptNotFound
+	}
+	return transcript, nil
+}
+
+func (r *memoryTranscriptRepo) Upsert(ctx context.Context, transcript *models.Transcript) error {
+	r.transcripts[transcript.VideoID+"/"+transcript.Language] = transcript
+	return nil
+}
+
+// memoryAudioTrackRepo keeps one track per video and language
+type memoryAudioTrackRepo struct {
+	models.AudioTrackRepository
+	tracks map[string]models.AudioTrack
+}
+
+func (r *memoryAudioTrackRepo) Get(ctx context.Context, tenantID, videoID, language string) (*models.AudioTrack, error) {
+	track, ok := r.tracks[videoID+"/"+language]
+	if !ok {
+		return nil, models.ErrAudioTrackNotFound
+	}
+	return &track, nil
+}
+
+func (r *memoryAudioTrackRepo) ListByVideo(ctx context.Context, tenantID, videoID string) ([]*models.AudioTrack, error) {
+	var tracks []*models.AudioTrack
+	for _, track := range r.tracks {
+		if track.VideoID == videoID {
+			tracks = append(tracks, &track)
+		}
+	}
+	return tracks, nil
+}
+
+func (r *memoryAudioTrackRepo) Upsert(ctx context.Context, track *models.AudioTrack) error {
+	if track.ID == "" {
+		track.ID = "track-" + track.Language
+	}
+	r.tracks[track.VideoID+"/"+track.Language] = *track
+	return nil
+}
+
+func (r *memoryAudioTrackRepo) Delete(ctx context.Context, tenantID, videoID, language string) error {
+	delete(r.tracks, videoID+"/"+language)
+	return nil
+}
+
+// translatingAI answers dubbing prompts with a fixed script
+type translatingAI struct {
+	AIService
+	script  string
+	prompts int
+}
+
+func (a *translatingAI) ProcessWithBedrock(ctx context.Context, promptKey string, input map[string]interface{}) (map[string]interface{}, error) {
+	a.prompts++
+	return map[string]interface{}{"result": a.script}, nil
+}
+
+type dubbingFixture struct {
+	svc         DubbingService
+	tracks      *memoryAudioTrackRepo
+	transcripts *memoryTranscriptRepo
+	renditions  *memoryRenditionRepo
+	ai          *translatingAI
+	jobs        *memoryJobRepo
+}
+
+func newDubbingFixture(t *testing.T) *dubbingFixture {
+	t.Helper()
+	fake := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	log := logger.New("error", "test")
+	original := &models.Transcript{TenantID: "acme", VideoID: "video-1", Language: "en", Source: models.TranscriptSourceUploaded}
+	require.NoError(t, original.SetSegments([]models.TranscriptSegment{
+		{Start: 0, End: 2.5, Text: "The house was empty."},
+		{Start: 3, End: 5, Text: "Or so they thought."},
+	}))
+	f := &dubbingFixture{
+		tracks:      &memoryAudioTrackRepo{tracks: map[string]models.AudioTrack{}},
+		transcripts: &memoryTranscriptRepo{transcripts: map[string]*models.Transcript{"video-1/en": original}},
+		renditions:  &memoryRenditionRepo{renditions: map[string]models.VideoRendition{}},
+		ai:          &translatingAI{script: "1: La maison était vide.\n2. Du moins le croyaient-ils."},
+	}
+	videos := &publicationVideoRepo{videos: map[string]*models.Video{"video-1": {ID: "video-1", TenantID: "acme"}}}
+	var jobs JobService
+	f.jobs, jobs = newTestJobs(fake)
+	presets := NewEncodingPresetService(&memoryPresetRepo{presets: map[string]*models.EncodingPreset{}}, fake, log)
+	_, _, assets := newTestAssets(t, fake)
+	renditions := NewRenditionService(f.renditions, presets, assets, videos, nil, jobs, fake, log)
+	f.svc = NewDubbingService(f.tracks, f.transcripts, videos, nil, f.ai, renditions, jobs, fake, log)
+	return f
+}
+
+func TestDubbingService_Dub(t *testing.T) {
+	f := newDubbingFixture(t)
+	ctx := context.Background()
+	viewer := &models.User{ID: "user-1", TenantID: "acme"}
+
+	track, err := f.svc.Dub(ctx, viewer, "video-1", &models.DubRequest{Language: "FR", Voice: "Lea"})
+	require.NoError(t, err)
+	assert.Equal(t, "fr", track.Language)
+	assert.Equal(t, "en", track.SourceLanguage)
+	assert.Equal(t, string(models.RenditionPending), track.Status)
+
+	script := f.transcripts.transcripts["video-1/fr"]
+	require.NotNil(t, script)
+	assert.Equal(t, models.TranscriptSourceTranslated, script.Source)
+	assert.Equal(t, []models.TranscriptSegment{
+		{Start: 0, End: 2.5, Text: "La maison était vide."},
+		{Start: 3, End: 5, Text: "Du moins le croyaient-ils."},
+	}, script.GetSegments(), "the voice keeps the original timing")
+
+	require.Len(t, f.jobs.jobs, 1)
+	assert.Equal(t, string(models.JobDubbing), f.jobs.jobs[0].Type)
+	assert.Equal(t, track.ID, f.jobs.jobs[0].ResourceID)
+
+	// An uploaded script is read as it is
+	uploaded := &models.Transcript{TenantID: "acme", VideoID: "video-1", Language: "es", Source: models.TranscriptSourceUploaded}
+	require.NoError(t, uploaded.SetSegments([]models.TranscriptSegment{{Start: 0, End: 5, Text: "La casa estaba vacía."}}))
+	f.transcripts.transcripts["video-1/es"] = uploaded
+	_, err = f.svc.Dub(ctx, viewer, "video-1", &models.DubRequest{Language: "es"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.ai.prompts)
+	assert.Same(t, uploaded, f.transcripts.transcripts["video-1/es"])
+}
+
+func TestDubbingService_DubRejects(t *testing.T) {
+	f := newDubbingFixture(t)
+	ctx := context.Background()
+	viewer := &models.User{ID: "user-1", TenantID: "acme"}
+
+	_, err := f.svc.Dub(ctx, viewer, "video-1", &models.DubRequest{Language: "tlh"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = f.svc.Dub(ctx, viewer, "video-1", &models.DubRequest{Language: "en"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = f.svc.Dub(ctx, viewer, "video-1", &models.DubRequest{Language: "de", SourceLanguage: "it"})
+	assert.ErrorIs(t, err, models.ErrConflict)
+	_, err = f.svc.Dub(ctx, viewer, "video-2", &models.DubRequest{Language: "de"})
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+
+	f.ai.script = "1: Das Haus war leer."
+	_, err = f.svc.Dub(ctx, viewer, "video-1", &models.DubRequest{Language: "de"})
+	assert.ErrorContains(t, err, "left out line 2")
+	assert.Empty(t, f.tracks.tracks)
+}
+
+func TestDubbingService_CompleteMuxesTheDubs(t *testing.T) {
+	f := newDubbingFixture(t)
+	ctx := context.Background()
+	viewer := &models.User{ID: "user-1", TenantID: "acme"}
+	for _, profile := range []string{transcode.Landscape1080p, transcode.Vertical1080p} {
+		f.renditions.renditions["video-1/"+profile] = models.VideoRendition{VideoID: "video-1", Profile: profile, Status: string(models.RenditionReady)}
+	}
+	_, err := f.svc.Dub(ctx, viewer, "video-1", &models.DubRequest{Language: "fr"})
+	require.NoError(t, err)
+
+	dubs, err := f.svc.Dubs(ctx, "acme", "video-1", transcode.Landscape1080p)
+	require.NoError(t, err)
+	assert.Empty(t, dubs, "the track is not synthesized yet")
+
+	_, err = f.svc.Complete(ctx, "acme", "video-1", "fr", &models.RenditionOutput{})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	track, err := f.svc.Complete(ctx, "acme", "video-1", "fr", &models.RenditionOutput{S3Key: "acme/video-1/fr.m4a", FileSize: 42})
+	require.NoError(t, err)
+	assert.True(t, track.Ready())
+	assert.Equal(t, string(models.JobSucceeded), f.jobs.jobs[0].State)
+	assert.Equal(t, string(models.RenditionPending), f.renditions.renditions["video-1/"+transcode.Landscape1080p].Status)
+	assert.Equal(t, string(models.RenditionReady), f.renditions.renditions["video-1/"+transcode.Vertical1080p].Status)
+
+	dubs, err = f.svc.Dubs(ctx, "acme", "video-1", transcode.Landscape1080p)
+	require.NoError(t, err)
+	require.Len(t, dubs, 1)
+	assert.Equal(t, "acme/video-1/fr.m4a", dubs[0].S3Key)
+	dubs, err = f.svc.Dubs(ctx, "acme", "video-1", transcode.Vertical1080p)
+	require.NoError(t, err)
+	assert.Empty(t, dubs)
+
+	require.NoError(t, f.svc.Fail(ctx, "acme", "video-1", "fr", "voice Lea does not speak fr"))
+	assert.Equal(t, string(models.RenditionFailed), f.tracks.tracks["video-1/fr"].Status)
+	assert.ErrorIs(t, f.svc.Fail(ctx, "acme", "video-1", "de", "nope"), models.ErrAudioTrackNotFound)
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// Loudness targets ffmpeg's loudnorm filter accepts, in LUFS
+const (
+	minLoudnessLUFS = -70
+	maxLoudnessLUFS = -5
+)
+
+// encodingPresetService implements the EncodingPresetService interface
+type encodingPresetService struct {
+	presets models.EncodingPresetRepository
+	clock   clock.Clock
+	logger  *logger.Logger
+}
+
+var _ EncodingPresetService = (*encodingPresetService)(nil)
+
+// NewEncodingPresetService creates a new encoding preset service instance
+func NewEncodingPresetService(presets models.EncodingPresetRepository, clock clock.Clock, logger *logger.Logger) EncodingPresetService {
+	return &encodingPresetService{presets: presets, clock: clock, logger: logger}
+}
+
+// List returns the settings of every profile, tuned or built in
+func (s *encodingPresetService) List(ctx context.Context, tenantID string) ([]*models.EncodingPreset, error) {
+	tuned, err := s.presets.List(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list encoding presets: %w", err)
+	}
+	byProfile := make(map[string]*models.EncodingPreset, len(tuned))
+	for _, preset := range tuned {
+		byProfile[preset.Profile] = preset
+	}
+
+	names := make([]string, 0, len(transcode.Profiles))
+	for name := range transcode.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	presets := make([]*models.EncodingPreset, 0, len(names))
+	for _, name := range names {
+		preset, ok := byProfile[name]
+		if !ok {
+			preset = builtInPreset(tenantID, transcode.Profiles[name])
+		}
+		presets = append(presets, preset)
+	}
+	return presets, nil
+}
+
+// Get returns the settings of a profile, tuned or built in
+func (s *encodingPresetService) Get(ctx context.Context, tenantID, profile string) (*models.EncodingPreset, error) {
+	builtIn, ok := transcode.Profiles[profile]
+	if !ok {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "unknown rendition profile %q", profile)
+	}
+	preset, err := s.presets.Get(ctx, tenantID, profile)
+	if errors.Is(err, models.ErrEncodingPresetNotFound) {
+		return builtInPreset(tenantID, builtIn), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encoding preset: %w", err)
+	}
+	return preset, nil
+}
+
+// Put tunes a profile for the tenant
+func (s *encodingPresetService) Put(ctx context.Context, tenantID, userID, profile string, req *models.EncodingPresetRequest) (*models.EncodingPreset, error) {
+	builtIn, ok := transcode.Profiles[profile]
+	if !ok {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "unknown rendition profile %q", profile)
+	}
+	preset := &models.EncodingPreset{
+		TenantID:         tenantID,
+		Profile:          profile,
+		Width:            req.Width,
+		Height:           req.Height,
+		VideoBitrateKbps: req.VideoBitrateKbps,
+		AudioBitrateKbps: req.AudioBitrateKbps,
+		FrameRate:        req.FrameRate,
+		VideoCodec:       req.VideoCodec,
+		AudioCodec:       req.AudioCodec,
+		LoudnessLUFS:     req.LoudnessLUFS,
+		UpdatedBy:        userID,
+		UpdatedAt:        s.clock.Now(),
+	}
+	if preset.VideoCodec == "" {
+		preset.VideoCodec = transcode.CodecH264
+	}
+	if preset.AudioCodec == "" {
+		preset.AudioCodec = transcode.CodecAAC
+	}
+	if err := validatePreset(builtIn, presetProfile(preset)); err != nil {
+		return nil, err
+	}
+	if err := s.presets.Upsert(ctx, preset); err != nil {
+		return nil, fmt.Errorf("failed to save encoding preset: %w", err)
+	}
+
+	s.logger.Info("Encoding preset saved", "tenant_id", tenantID, "user_id", userID, "profile", profile,
+		"resolution", fmt.Sprintf("%dx%d", preset.Width, preset.Height), "video_codec", preset.VideoCodec, "loudness_lufs", preset.LoudnessLUFS)
+	return preset, nil
+}
+
+// Delete returns a profile to its built-in settings
+func (s *encodingPresetService) Delete(ctx context.Context, tenantID, profile string) error {
+	if err := s.presets.Delete(ctx, tenantID, profile); err != nil {
+		return err
+	}
+	s.logger.Info("Encoding preset deleted", "tenant_id", tenantID, "profile", profile)
+	return nil
+}
+
+// Profile returns the profile the tenant's renditions are transcoded with
+func (s *encodingPresetService) Profile(ctx context.Context, tenantID, name string) (transcode.Profile, error) {
+	preset, err := s.Get(ctx, tenantID, name)
+	if err != nil {
+		return transcode.Profile{}, err
+	}
+	return presetProfile(preset), nil
+}
+
+// builtInPreset returns the built-in settings of a profile as a preset
+func builtInPreset(tenantID string, profile transcode.Profile) *models.EncodingPreset {
+	return &models.EncodingPreset{
+		TenantID:         tenantID,
+		Profile:          profile.Name,
+		Width:            profile.Width,
+		Height:           profile.Height,
+		VideoBitrateKbps: profile.VideoBitrateKbps,
+		AudioBitrateKbps: profile.AudioBitrateKbps,
+		FrameRate:        profile.FrameRate,
+		VideoCodec:       profile.VideoCodec,
+		AudioCodec:       profile.AudioCodec,
+		LoudnessLUFS:     profile.LoudnessLUFS,
+		Default:          true,
+	}
+}
+
+// presetProfile returns the profile a preset transcodes with
+func presetProfile(preset *models.EncodingPreset) transcode.Profile {
+	profile := transcode.Profiles[preset.Profile]
+	profile.Width = preset.Width
+	profile.Height = preset.Height
+	profile.VideoBitrateKbps = preset.VideoBitrateKbps
+	profile.AudioBitrateKbps = preset.AudioBitrateKbps
+	profile.FrameRate = preset.FrameRate
+	profile.VideoCodec = preset.VideoCodec
+	profile.AudioCodec = preset.AudioCodec
+	profile.LoudnessLUFS = preset.LoudnessLUFS
+	return profile
+}
+
+// validatePreset checks a tuned profile keeps the built-in one's aspect ratio
+// and stays within the limits of every platform taking it
+func validatePreset(builtIn, profile transcode.Profile) error {
+	switch {
+	case profile.Width <= 0 || profile.Height <= 0 || profile.Width%2 != 0 || profile.Height%2 != 0:
+		return i18n.Errorf(models.ErrInvalidInput, "width and height must be even numbers of pixels")
+	case profile.Width*builtIn.Height != profile.Height*builtIn.Width:
+		return i18n.Errorf(models.ErrInvalidInput, "a %s preset must keep the %s aspect ratio", builtIn.Name, builtIn.AspectRatio)
+	case profile.VideoBitrateKbps <= 0 || profile.AudioBitrateKbps <= 0 || profile.FrameRate <= 0:
+		return i18n.Errorf(models.ErrInvalidInput, "bitrates and frame rate must be positive")
+	case transcode.VideoEncoders[profile.VideoCodec] == "":
+		return i18n.Errorf(models.ErrInvalidInput, "unknown video codec %s", profile.VideoCodec)
+	case transcode.AudioEncoders[profile.AudioCodec] == "":
+		return i18n.Errorf(models.ErrInvalidInput, "unknown audio codec %s", profile.AudioCodec)
+	case profile.LoudnessLUFS != 0 && (profile.LoudnessLUFS < minLoudnessLUFS || profile.LoudnessLUFS > maxLoudnessLUFS):
+		return i18n.Errorf(models.ErrInvalidInput, "loudness_lufs must be between %d and %d", minLoudnessLUFS, maxLoudnessLUFS)
+	}
+
+	for _, platform := range partners.ProfilePlatforms(builtIn.Name) {
+		limits := partners.PlatformEncodingLimits[platform]
+		switch {
+		case !slices.Contains(limits.VideoCodecs, profile.VideoCodec):
+			return i18n.Errorf(models.ErrInvalidInput, "%s does not accept %s video", platform, profile.VideoCodec)
+		case !slices.Contains(limits.AudioCodecs, profile.AudioCodec):
+			return i18n.Errorf(models.ErrInvalidInput, "%s does not accept %s audio", platform, profile.AudioCodec)
+		case max(profile.Width, profile.Height) > limits.MaxLongSide:
+			return i18n.Errorf(models.ErrInvalidInput, "%s accepts videos up to %d pixels on their long side", platform, limits.MaxLongSide)
+		case limits.MaxVideoBitrateKbps > 0 && profile.VideoBitrateKbps > limits.MaxVideoBitrateKbps:
+			return i18n.Errorf(models.ErrInvalidInput, "%s accepts video bitrates up to %d kbps", platform, limits.MaxVideoBitrateKbps)
+		case limits.MaxAudioBitrateKbps > 0 && profile.AudioBitrateKbps > limits.MaxAudioBitrateKbps:
+			return i18n.Errorf(models.ErrInvalidInput, "%s accepts audio bitrates up to %d kbps", platform, limits.MaxAudioBitrateKbps)
+		case profile.FrameRate < limits.MinFrameRate || profile.FrameRate > limits.MaxFrameRate:
+			return i18n.Errorf(models.ErrInvalidInput, "%s accepts frame rates from %d to %d fps", platform, max(limits.MinFrameRate, 1), limits.MaxFrameRate)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// memoryPresetRepo holds one tenant's presets by profile
+type memoryPresetRepo struct {
+	presets map[string]*models.EncodingPreset
+}
+
+func (r *memoryPresetRepo) Get(ctx context.Context, tenantID, profile string) (*models.EncodingPreset, error) {
+	if preset, ok := r.presets[profile]; ok {
+		return preset, nil
+	}
+	return nil, models.ErrEncodingPresetNotFound
+}
+
+func (r *memoryPresetRepo) List(ctx context.Context, tenantID string) ([]*models.EncodingPreset, error) {
+	var presets []*models.EncodingPreset
+	for _, preset := range r.presets {
+		presets = append(presets, preset)
+	}
+	return presets, nil
+}
+
+func (r *memoryPresetRepo) Upsert(ctx context.Context, preset *models.EncodingPreset) error {
+	r.presets[preset.Profile] = preset
+	return nil
+}
+
+func (r *memoryPresetRepo) Delete(ctx context.Context, tenantID, profile string) error {
+	if _, ok := r.presets[profile]; !ok {
+		return models.ErrEncodingPresetNotFound
+	}
+	delete(r.presets, profile)
+	return nil
+}
+
+func TestEncodingPresetService_Put(t *testing.T) {
+	repo := &memoryPresetRepo{presets: map[string]*models.EncodingPreset{}}
+	svc := NewEncodingPresetService(repo, clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)), logger.New("error", "test"))
+	ctx := context.Background()
+	vertical := func() *models.EncodingPresetRequest {
+		return &models.EncodingPresetRequest{Width: 1080, Height: 1920, VideoBitrateKbps: 10000, AudioBitrateKbps: 128, FrameRate: 30}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*models.EncodingPresetRequest)
+	}{
+		{"odd width", func(r *models.EncodingPresetRequest) { r.Width = 1081 }},
+		{"other aspect ratio", func(r *models.EncodingPresetRequest) { r.Width, r.Height = 1920, 1080 }},
+		{"unknown codec", func(r *models.EncodingPresetRequest) { r.VideoCodec = "av1" }},
+		{"codec a platform rejects", func(r *models.EncodingPresetRequest) { r.VideoCodec = transcode.CodecVP9 }},
+		{"too large for Instagram", func(r *models.EncodingPresetRequest) { r.Width, r.Height = 2160, 3840 }},
+		{"audio bitrate over Instagram's", func(r *models.EncodingPresetRequest) { r.AudioBitrateKbps = 192 }},
+		{"frame rate under TikTok's", func(r *models.EncodingPresetRequest) { r.FrameRate = 15 }},
+		{"loudness out of range", func(r *models.EncodingPresetRequest) { r.LoudnessLUFS = -2 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := vertical()
+			tt.modify(req)
+			_, err := svc.Put(ctx, "acme", "admin", transcode.Vertical1080p, req)
+			assert.ErrorIs(t, err, models.ErrInvalidInput)
+		})
+	}
+	_, err := svc.Put(ctx, "acme", "admin", "square_1080p", vertical())
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	req := vertical()
+	req.VideoCodec = transcode.CodecHEVC
+	_, err = svc.Put(ctx, "acme", "admin", transcode.Vertical1080p, req)
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "Snapchat shares the profile")
+
+	req = vertical()
+	req.FrameRate, req.LoudnessLUFS = 60, -14
+	preset, err := svc.Put(ctx, "acme", "admin", transcode.Vertical1080p, req)
+	require.NoError(t, err)
+	assert.Equal(t, transcode.CodecAAC, preset.AudioCodec, "defaulted")
+
+	profile, err := svc.Profile(ctx, "acme", transcode.Vertical1080p)
+	require.NoError(t, err)
+	assert.Equal(t, "9:16", profile.AspectRatio)
+	assert.Contains(t, profile.OutputArgs(), "loudnorm=I=-14:TP=-1.5:LRA=11")
+
+	// YouTube takes VP9, 4K and 60 fps
+	_, err = svc.Put(ctx, "acme", "admin", transcode.Landscape1080p, &models.EncodingPresetRequest{Width: 3840, Height: 2160,
+		VideoBitrateKbps: 45000, AudioBitrateKbps: 192, FrameRate: 60, VideoCodec: transcode.CodecVP9})
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "but Facebook and LinkedIn share the profile")
+
+	presets, err := svc.List(ctx, "acme")
+	require.NoError(t, err)
+	require.Len(t, presets, 3)
+	assert.True(t, presets[0].Default)
+	assert.False(t, presets[2].Default)
+	assert.Equal(t, 60, presets[2].FrameRate)
+
+	require.NoError(t, svc.Delete(ctx, "acme", transcode.Vertical1080p))
+	profile, err = svc.Profile(ctx, "acme", transcode.Vertical1080p)
+	require.NoError(t, err)
+	assert.Equal(t, transcode.Profiles[transcode.Vertical1080p], profile)
+	assert.ErrorIs(t, svc.Delete(ctx, "acme", transcode.Vertical1080p), models.ErrEncodingPresetNotFound)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// Frames returned per request: at most maxFrameTimestamps asked for, and
+// candidateFrames spread over videos without scene changes
+const (
+	maxFrameTimestamps = 50
+	candidateFrames    = 12
+)
+
+// frameService implements the FrameService interface
+type frameService struct {
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ FrameService = (*frameService)(nil)
+
+// NewFrameService creates a new frame service
+func NewFrameService(videos models.VideoRepository, workspaces models.WorkspaceRepository, clock clock.Clock, logger *logger.Logger) FrameService {
+	return &frameService{videos: videos, workspaces: workspaces, clock: clock, logger: logger}
+}
+
+// Plan plans the frames from the probed duration, falling back on the
+// duration set when the video was processed
+func (s *frameService) Plan(ctx context.Context, tenantID, videoID string) (*transcode.FramePlan, error) {
+	video, err := s.videos.GetByID(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	duration := float64(video.Duration)
+	if video.MediaInfo != nil && video.MediaInfo.DurationSeconds > 0 {
+		duration = video.MediaInfo.DurationSeconds
+	}
+	plan, err := transcode.PlanFrames(duration)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrMediaNotProbed, err)
+	}
+	return plan, nil
+}
+
+// Record sets the video's frames, replacing earlier ones
+func (s *frameService) Record(ctx context.Context, tenantID, videoID string, plan *transcode.FramePlan, baseURL string, sceneLog []byte) (*models.VideoFrames, error) {
+	if u, err := url.Parse(baseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "frames need the absolute URL they are stored under")
+	}
+	video, err := s.videos.GetByID(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	scenes := transcode.ParseSceneLog(sceneLog)
+	if len(scenes) > plan.MaxScenes {
+		scenes = scenes[:plan.MaxScenes]
+	}
+	now := s.clock.Now()
+	video.Frames = models.VideoFrames{
+		BaseURL:         baseURL,
+		IntervalSeconds: plan.IntervalSeconds,
+		Count:           plan.Frames,
+		SceneSeconds:    scenes,
+		ExtractedAt:     &now,
+	}
+	if err := s.videos.Update(ctx, video); err != nil {
+		return nil, fmt.Errorf("failed to save frames: %w", err)
+	}
+	s.logger.Info("Frames extracted", "tenant_id", tenantID, "video_id", videoID, "frames", plan.Frames, "scenes", len(scenes))
+	return &video.Frames, nil
+}
+
+// Frames returns the extracted frame nearest each timestamp, in the order
+// asked. Without timestamps, it returns the scene-change frames, or frames
+// spread over the video when it has no scene changes.
+func (s *frameService) Frames(ctx context.Context, viewer *models.User, videoID string, timestamps []float64) ([]models.Frame, error) {
+	if len(timestamps) > maxFrameTimestamps {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "at most %d timestamps can be asked for at once", maxFrameTimestamps)
+	}
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if !video.Frames.Extracted() {
+		return nil, models.ErrFramesNotExtracted
+	}
+
+	if len(timestamps) == 0 {
+		if scenes := video.Frames.Scenes(); len(scenes) > 0 {
+			return scenes, nil
+		}
+		return video.Frames.Spread(candidateFrames), nil
+	}
+	frames := make([]models.Frame, len(timestamps))
+	for i, at := range timestamps {
+		if at < 0 {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "timestamps must not be negative")
+		}
+		frames[i] = video.Frames.Nearest(at)
+	}
+	return frames, nil
+}
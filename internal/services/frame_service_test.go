@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+func TestFrameService(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	videos := &publicationVideoRepo{videos: map[string]*models.Video{
+		"probed":    {ID: "probed", TenantID: "acme", Duration: 60, MediaInfo: &transcode.MediaInfo{DurationSeconds: 700}},
+		"static":    {ID: "static", TenantID: "acme", Duration: 30},
+		"uploading": {ID: "uploading", TenantID: "acme"},
+	}}
+	svc := NewFrameService(videos, nil, clock.NewFake(now), logger.New("error", "test"))
+	ctx := context.Background()
+	viewer := &models.User{ID: "user-1", TenantID: "acme"}
+
+	_, err := svc.Plan(ctx, "acme", "uploading")
+	assert.ErrorIs(t, err, models.ErrMediaNotProbed)
+	_, err = svc.Frames(ctx, viewer, "probed", nil)
+	assert.ErrorIs(t, err, models.ErrFramesNotExtracted)
+
+	plan, err := svc.Plan(ctx, "acme", "probed")
+	require.NoError(t, err)
+	assert.Equal(t, 3, plan.IntervalSeconds, "the probed duration wins")
+	assert.Equal(t, 234, plan.Frames)
+
+	_, err = svc.Record(ctx, "acme", "probed", plan, "frames/probed", nil)
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	log := []byte("[Parsed_showinfo_1 @ 0x1] n:   0 pts: 1 pts_time:10.5 duration: 1\n[Parsed_showinfo_1 @ 0x1] n:   1 pts: 2 pts_time:301.2 duration: 1\n")
+	recorded, err := svc.Record(ctx, "acme", "probed", plan, "https://cdn/frames/probed/", log)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{10.5, 301.2}, recorded.SceneSeconds)
+	assert.Equal(t, now, *recorded.ExtractedAt)
+
+	candidates, err := svc.Frames(ctx, viewer, "probed", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Frame{
+		{Seconds: 10.5, URL: "https://cdn/frames/probed/scene-001.jpg", SceneChange: true},
+		{Seconds: 301.2, URL: "https://cdn/frames/probed/scene-002.jpg", SceneChange: true},
+	}, candidates)
+
+	frames, err := svc.Frames(ctx, viewer, "probed", []float64{0, 10, 100, 5000})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Frame{
+		{Seconds: 0, URL: "https://cdn/frames/probed/frame-0001.jpg"},
+		{Seconds: 10.5, URL: "https://cdn/frames/probed/scene-001.jpg", SceneChange: true},
+		{Seconds: 99, URL: "https://cdn/frames/probed/frame-0034.jpg"},
+		{Seconds: 699, URL: "https://cdn/frames/probed/frame-0234.jpg"},
+	}, frames, "timestamps past the end get the last frame")
+
+	_, err = svc.Frames(ctx, viewer, "probed", []float64{-1})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	plan, err = svc.Plan(ctx, "acme", "static")
+	require.NoError(t, err)
+	_, err = svc.Record(ctx, "acme", "static", plan, "https://cdn/frames/static", []byte("no scene changes"))
+	require.NoError(t, err)
+	spread, err := svc.Frames(ctx, viewer, "static", nil)
+	require.NoError(t, err)
+	require.Len(t, spread, 12)
+	assert.Equal(t, "https://cdn/frames/static/frame-0001.jpg", spread[0].URL)
+	assert.Equal(t, 27.0, spread[11].Seconds)
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// identityService implements the IdentityService interface
+type identityService struct {
+	users   models.UserRepository
+	tenants models.TenantRepository
+	ttl     time.Duration
+	clock   clock.Clock
+	logger  *logger.Logger
+
+	mu          sync.Mutex
+	userCache   map[string]cachedUser
+	tenantCache map[string]cachedTenant
+	sweptAt     time.Time
+}
+
+// cachedUser is a user as last read
+type cachedUser struct {
+	user   *models.User
+	readAt time.Time
+}
+
+// cachedTenant is a tenant as last read; tenant is nil when the tenant has
+// no row, as for tenants created before the tenants table
+type cachedTenant struct {
+	tenant *models.Tenant
+	readAt time.Time
+}
+
+var _ IdentityService = (*identityService)(nil)
+
+// NewIdentityService creates a new identity service instance. Users and
+// tenants are cached for ttl, so a deactivation is honored within ttl on
+// every replica. Expired entries are dropped when read and swept once per ttl.
+func NewIdentityService(users models.UserRepository, tenants models.TenantRepository, ttl time.Duration, clock clock.Clock, logger *logger.Logger) IdentityService {
+	return &identityService{
+		users:       users,
+		tenants:     tenants,
+		ttl:         ttl,
+		clock:       clock,
+		logger:      logger,
+		userCache:   make(map[string]cachedUser),
+		tenantCache: make(map[string]cachedTenant),
+	}
+}
+
+// Load returns ctx carrying the current user and tenant of a request, and
+// the user. Loading again with the returned ctx reads neither cache nor
+// database.
+func (s *identityService) Load(ctx context.Context, tenantID, userID string) (context.Context, *models.User, error) {
+	if identity, ok := IdentityFrom(ctx); ok && identity.User.ID == userID && identity.User.TenantID == tenantID {
+		return ctx, identity.User, nil
+	}
+
+	user, err := s.user(ctx, tenantID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.IsActive() {
+		s.logger.Info("Rejected request of inactive user", "tenant_id", tenantID, "user_id", userID, "status", user.Status)
+		if models.UserStatus(user.Status) == models.StatusSuspended {
+			return nil, nil, models.ErrUserSuspended
+		}
+		return nil, nil, models.ErrUserInactive
+	}
+	tenant, err := s.tenant(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tenant != nil && tenant.Status != "" && tenant.Status != models.TenantStatusActive {
+		s.logger.Info("Rejected request of inactive tenant", "tenant_id", tenantID, "user_id", userID, "status", tenant.Status)
+		if tenant.Status == models.TenantStatusSuspended {
+			return nil, nil, models.ErrTenantSuspended
+		}
+		return nil, nil, models.ErrTenantInactive
+	}
+
+	return WithIdentity(ctx, &Identity{User: user, Tenant: tenant}), user, nil
+}
+
+// Invalidate forgets the cached user, or the cached tenant when userID is
+// empty, so the change is seen at once on this replica
+func (s *identityService) Invalidate(tenantID, userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if userID == "" {
+		delete(s.tenantCache, tenantID)
+		return
+	}
+	delete(s.userCache, tenantID+"/"+userID)
+}
+
+func (s *identityService) user(ctx context.Context, tenantID, userID string) (*models.User, error) {
+	key := tenantID + "/" + userID
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	cached, found := s.userCache[key]
+	if found && now.Sub(cached.readAt) >= s.ttl {
+		delete(s.userCache, key)
+		found = false
+	}
+	s.mu.Unlock()
+	// Callers may change the user they get, so each gets its own copy
+	if found {
+		copied := *cached.user
+		return &copied, nil
+	}
+
+	user, err := s.users.GetByID(ctx, tenantID, userID)
+	if errors.Is(err, models.ErrUserNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	stored := *user
+	s.mu.Lock()
+	s.userCache[key] = cachedUser{user: &stored, readAt: now}
+	s.sweep(now)
+	s.mu.Unlock()
+	return user, nil
+}
+
+func (s *identityService) tenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	cached, found := s.tenantCache[tenantID]
+	if found && now.Sub(cached.readAt) >= s.ttl {
+		delete(s.tenantCache, tenantID)
+		found = false
+	}
+	s.mu.Unlock()
+	if found {
+		return cached.tenant, nil
+	}
+
+	tenant, err := s.tenants.GetByID(ctx, tenantID)
+	if errors.Is(err, models.ErrTenantNotFound) {
+		tenant, err = nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	s.mu.Lock()
+	s.tenantCache[tenantID] = cachedTenant{tenant: tenant, readAt: now}
+	s.sweep(now)
+	s.mu.Unlock()
+	return tenant, nil
+}
+
+// sweep drops the expired users and tenants at most once per ttl, so entries
+// no request reads again do not pile up. The caller holds mu.
+func (s *identityService) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) < s.ttl {
+		return
+	}
+	s.sweptAt = now
+	for key, cached := range s.userCache {
+		if now.Sub(cached.readAt) >= s.ttl {
+			delete(s.userCache, key)
+		}
+	}
+	for key, cached := range s.tenantCache {
+		if now.Sub(cached.readAt) >= s.ttl {
+			delete(s.tenantCache, key)
+		}
+	}
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a context carrying the request's user and tenant
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFrom returns the user and tenant loaded for the request of ctx
+func IdentityFrom(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
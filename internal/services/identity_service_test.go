@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/residency"
+)
+
+// identityUserRepo stores users by ID and counts lookups
+type identityUserRepo struct {
+	models.UserRepository
+	users   map[string]*models.User
+	lookups int
+}
+
+func (r *identityUserRepo) GetByID(ctx context.Context, tenantID, id string) (*models.User, error) {
+	r.lookups++
+	if u, ok := r.users[id]; ok && u.TenantID == tenantID {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, models.ErrUserNotFound
+}
+
+func (r *identityUserRepo) Update(ctx context.Context, user *models.User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func TestIdentityService_Load(t *testing.T) {
+	now := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(now)
+	users := &identityUserRepo{users: map[string]*models.User{
+		"user-1": {ID: "user-1", TenantID: "acme", Role: "user", Status: string(models.StatusActive)},
+		"user-2": {ID: "user-2", TenantID: "closed", Role: "user", Status: string(models.StatusActive)},
+	}}
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{
+		"acme":   {ID: "acme", Status: "active"},
+		"closed": {ID: "closed", Status: "suspended"},
+	}}
+	svc := NewIdentityService(users, tenants, 30*time.Second, clk, logger.New("error", "test"))
+	ctx := context.Background()
+
+	loaded, user, err := svc.Load(ctx, "acme", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user", user.Role)
+	identity, ok := IdentityFrom(loaded)
+	require.True(t, ok)
+	assert.Equal(t, "acme", identity.Tenant.ID)
+
+	// The request's context and the shared cache spare the database
+	_, _, err = svc.Load(loaded, "acme", "user-1")
+	require.NoError(t, err)
+	_, _, err = svc.Load(ctx, "acme", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, users.lookups)
+
+	// Changes are seen once the cached user expires, or at once when invalidated
+	users.users["user-1"].Role = "admin"
+	clk.Advance(31 * time.Second)
+	_, user, err = svc.Load(ctx, "acme", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", user.Role)
+
+	users.users["user-1"].Status = string(models.StatusSuspended)
+	svc.Invalidate("acme", "user-1")
+	_, _, err = svc.Load(ctx, "acme", "user-1")
+	assert.ErrorIs(t, err, models.ErrUserSuspended)
+
+	users.users["user-1"].Status = string(models.StatusInactive)
+	svc.Invalidate("acme", "user-1")
+	_, _, err = svc.Load(ctx, "acme", "user-1")
+	assert.ErrorIs(t, err, models.ErrUserInactive)
+
+	_, _, err = svc.Load(ctx, "acme", "deleted")
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+	_, _, err = svc.Load(ctx, "closed", "user-2")
+	assert.ErrorIs(t, err, models.ErrTenantSuspended)
+}
+
+func TestIdentityService_CacheHandsOutCopies(t *testing.T) {
+	users := &identityUserRepo{users: map[string]*models.User{
+		"user-1": {ID: "user-1", TenantID: "acme", Role: "user", Status: string(models.StatusActive)},
+	}}
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{"acme": {ID: "acme", Status: "active"}}}
+	svc := NewIdentityService(users, tenants, 30*time.Second, clock.NewFake(time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)), logger.New("error", "test"))
+	ctx := context.Background()
+
+	_, first, err := svc.Load(ctx, "acme", "user-1")
+	require.NoError(t, err)
+	first.Role = "admin"
+
+	_, second, err := svc.Load(ctx, "acme", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, users.lookups)
+	assert.Equal(t, "user", second.Role, "a caller's change does not reach the cache")
+	second.Role = "admin"
+
+	_, third, err := svc.Load(ctx, "acme", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user", third.Role)
+}
+
+func TestIdentityService_EvictsExpiredEntries(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC))
+	users := &identityUserRepo{users: map[string]*models.User{
+		"user-1": {ID: "user-1", TenantID: "acme", Status: string(models.StatusActive)},
+		"user-2": {ID: "user-2", TenantID: "acme", Status: string(models.StatusActive)},
+		"user-3": {ID: "user-3", TenantID: "globex", Status: string(models.StatusActive)},
+	}}
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{
+		"acme":   {ID: "acme", Status: "active"},
+		"globex": {ID: "globex", Status: "active"},
+	}}
+	svc := NewIdentityService(users, tenants, 30*time.Second, clk, logger.New("error", "test")).(*identityService)
+	ctx := context.Background()
+
+	_, _, err := svc.Load(ctx, "acme", "user-1")
+	require.NoError(t, err)
+	_, _, err = svc.Load(ctx, "acme", "user-2")
+	require.NoError(t, err)
+
+	// A user deleted since is dropped when read after it expired
+	delete(users.users, "user-2")
+	clk.Advance(31 * time.Second)
+	_, _, err = svc.Load(ctx, "acme", "user-2")
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+	assert.NotContains(t, svc.userCache, "acme/user-2")
+
+	// Entries no request reads again are swept with the next read
+	_, _, err = svc.Load(ctx, "globex", "user-3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"globex/user-3"}, slices.Collect(maps.Keys(svc.userCache)))
+	assert.Equal(t, []string{"globex"}, slices.Collect(maps.Keys(svc.tenantCache)))
+}
+
+func TestResidencyService_ContextForTenant_UsesLoadedTenant(t *testing.T) {
+	tenants, svc := newResidencyFixture(t)
+	ctx := WithIdentity(context.Background(), &Identity{
+		User:   &models.User{ID: "user-1", TenantID: "tenant-eu"},
+		Tenant: &models.Tenant{ID: "tenant-eu", Residency: "eu", ResidencyPinned: true},
+	})
+	delete(tenants.tenants, "tenant-eu")
+
+	ctx, err := svc.ContextForTenant(ctx, "tenant-eu")
+	require.NoError(t, err)
+	scope, _ := residency.FromContext(ctx)
+	assert.Equal(t, residency.Scope{Residency: residency.EU, Pinned: true}, scope)
+}
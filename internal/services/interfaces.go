@@ -2,50 +2,135 @@ package services
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/notify"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/residency"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
 )
 
 // VideoService defines the interface for video-related business logic
 type VideoService interface {
-	// Video CRUD operations
+	// Video CRUD operations. Reads and updates are limited to the videos the
+	// viewer sees, see models.Video.VisibleTo; the others are not found.
 	CreateVideo(ctx context.Context, tenantID, userID string, req *models.CreateVideoRequest) (*models.Video, error)
-	GetVideo(ctx context.Context, tenantID, videoID string) (*models.Video, error)
-	UpdateVideo(ctx context.Context, tenantID, videoID string, req *models.UpdateVideoRequest) (*models.Video, error)
+	GetVideo(ctx context.Context, viewer *models.User, videoID string) (*models.Video, error)
+	UpdateVideo(ctx context.Context, viewer *models.User, videoID string, req *models.UpdateVideoRequest) (*models.Video, error)
 	DeleteVideo(ctx context.Context, tenantID, videoID string) error
-	ListVideos(ctx context.Context, tenantID string, limit, offset int) ([]*models.Video, error)
+	// ListVideos lists the videos the viewer sees matching the filter, by
+	// creation date unless told otherwise, and how many match
+	ListVideos(ctx context.Context, viewer *models.User, filter models.VideoListFilter, limit, offset int) ([]*models.Video, int64, error)
+	// ListMatching lists the videos the viewer sees meeting all the
+	// conditions of a smart list filter, newest first
+	ListMatching(ctx context.Context, viewer *models.User, conditions []models.SmartListCondition, limit, offset int) ([]*models.Video, error)
 	GetUserVideos(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.Video, error)
+	// TransferOwnership hands the video over to another active user of the
+	// tenant; only its owner and admins may
+	TransferOwnership(ctx context.Context, viewer *models.User, videoID, userID string) (*models.Video, error)
 
 	// Video processing operations
 	UploadVideo(ctx context.Context, tenantID, videoID string, fileData []byte, filename string) error
 	UpdateVideoStatus(ctx context.Context, tenantID, videoID string, status models.VideoStatus) error
 	GetVideosByStatus(ctx context.Context, tenantID string, status models.VideoStatus, limit, offset int) ([]*models.Video, error)
 	SetProcessingComplete(ctx context.Context, tenantID, videoID string, duration int, resolution, thumbnailURL, s3Key, s3Bucket string) error
-	SetProcessingFailed(ctx context.Context, tenantID, videoID string) error
+	SetProcessingProgress(ctx context.Context, tenantID, videoID string, percent int) error
+	SetProcessingFailed(ctx context.Context, tenantID, videoID, reason string) error
+}
 
-	// Video publishing operations
-	PublishVideo(ctx context.Context, tenantID, videoID string, platforms []string) error
-	GetVideoPublications(ctx context.Context, tenantID, videoID string) ([]*models.PublicationJob, error)
-	UpdatePublication(ctx context.Context, tenantID, publicationID string, status string) error
-	CancelPublication(ctx context.Context, tenantID, publicationID string) error
+// UploadService uploads video files to S3 in parts, for files too large for
+// a single request. Parts go through the API or straight to S3 with presigned
+// URLs, and an interrupted upload resumes from the parts S3 already holds.
+type UploadService interface {
+	Start(ctx context.Context, viewer *models.User, videoID string, req *models.StartUploadRequest) (*UploadProgress, error)
+	// Get returns the video's active upload with its uploaded parts, or
+	// models.ErrVideoUploadNotFound
+	Get(ctx context.Context, viewer *models.User, videoID string) (*UploadProgress, error)
+	PresignParts(ctx context.Context, viewer *models.User, videoID string, partNumbers []int) ([]*PresignedPart, error)
+	UploadPart(ctx context.Context, viewer *models.User, videoID string, partNumber int, body io.Reader) (*aws.UploadedPart, error)
+	// Complete assembles the file once every part is uploaded and starts
+	// processing the video
+	Complete(ctx context.Context, viewer *models.User, videoID string) (*models.Video, error)
+	Abort(ctx context.Context, viewer *models.User, videoID string) error
 }
 
 // AIService defines the interface for AI-related business logic
 type AIService interface {
 	// Magic Brush operations (real-time AI generation)
-	GenerateMagicBrush(ctx context.Context, tenantID string, req *MagicBrushRequest) (*MagicBrushResponse, error)
+	GenerateMagicBrush(ctx context.Context, tenantID, userID string, req *MagicBrushRequest) (*MagicBrushResponse, error)
 
 	// AI processing operations
 	ProcessWithBedrock(ctx context.Context, promptKey string, input map[string]interface{}) (map[string]interface{}, error)
 }
 
+// MagicBrushBatchService defines the interface for applying a magic brush
+// to a selection of videos in the background
+type MagicBrushBatchService interface {
+	// Start queues the batch, tracking each video with a magic_brush job
+	Start(ctx context.Context, tenantID, userID string, req *models.BulkMagicBrushRequest) (*models.MagicBrushBatch, error)
+	// Get returns the batch with the result of each video and the AI usage
+	// summed over them
+	Get(ctx context.Context, tenantID, id string) (*models.MagicBrushBatch, error)
+	// Run generates the queued videos of the unfinished batches
+	Run(ctx context.Context) (*MagicBrushBatchRunReport, error)
+}
+
+// ChatService defines the interface for multi-turn AI assistant conversations
+type ChatService interface {
+	Chat(ctx context.Context, tenantID, userID string, req *ChatRequest) (*ChatResponse, error)
+	GetConversation(ctx context.Context, tenantID, userID, conversationID string) (*models.Conversation, error)
+	DeleteConversation(ctx context.Context, tenantID, userID, conversationID string) error
+	PurgeExpiredConversations(ctx context.Context) (int64, error)
+}
+
+// TranscriptService defines the interface for video transcript storage and search
+type TranscriptService interface {
+	SaveTranscript(ctx context.Context, viewer *models.User, videoID string, req *models.SaveTranscriptRequest) (*models.Transcript, error)
+	GetTranscript(ctx context.Context, viewer *models.User, videoID, language string) (*models.Transcript, error)
+	SearchTranscripts(ctx context.Context, viewer *models.User, query string, limit, offset int) ([]*models.TranscriptSearchResult, error)
+}
+
+// ArchiveService defines the interface for the cold storage lifecycle of videos
+type ArchiveService interface {
+	GetRetentionPolicy(ctx context.Context, tenantID string) (*models.RetentionPolicy, error)
+	UpdateRetentionPolicy(ctx context.Context, tenantID string, req *models.UpdateRetentionPolicyRequest) (*models.RetentionPolicy, error)
+
+	// RestoreVideo starts the asynchronous retrieval of an archived video
+	RestoreVideo(ctx context.Context, viewer *models.User, videoID string) (*ArchiveStatus, error)
+	GetArchiveStatus(ctx context.Context, viewer *models.User, videoID string) (*ArchiveStatus, error)
+
+	// RunLifecycle archives eligible videos of every tenant and completes finished restores
+	RunLifecycle(ctx context.Context) (*LifecycleReport, error)
+}
+
+// ResidencyService defines the interface for tenant data residency
+type ResidencyService interface {
+	GetResidency(ctx context.Context, tenantID string) (*TenantResidency, error)
+	UpdateResidency(ctx context.Context, tenantID string, req *models.UpdateResidencyRequest) (*TenantResidency, error)
+
+	// ContextForTenant returns ctx carrying the tenant's residency scope, which
+	// regional clients use to pick where the call goes
+	ContextForTenant(ctx context.Context, tenantID string) (context.Context, error)
+}
+
 // AnalyticsService defines the interface for analytics and statistics business logic
 type AnalyticsService interface {
 	// Video statistics
-	GetVideoStats(ctx context.Context, tenantID, videoID string) (*models.VideoStats, error)
-	GetVideosStats(ctx context.Context, tenantID string, videoIDs []string) ([]*models.VideoStats, error)
-	GetVideoStatsHistory(ctx context.Context, tenantID, videoID string, from, to time.Time) ([]*models.VideoStats, error)
+	GetVideoStats(ctx context.Context, viewer *models.User, videoID string) (*models.VideoStats, error)
+	GetVideosStats(ctx context.Context, viewer *models.User, videoIDs []string) ([]*models.VideoStats, error)
+	GetVideoStatsHistory(ctx context.Context, viewer *models.User, videoID string, from, to time.Time) ([]*models.VideoStatsSnapshot, error)
+	// GetVideoStatsDaily is the history bucketed into days starting at
+	// midnight in loc, the timezone of the user asking
+	GetVideoStatsDaily(ctx context.Context, viewer *models.User, videoID string, from, to time.Time, loc *time.Location) ([]*DailyStats, error)
+	ExportVideoStats(ctx context.Context, viewer *models.User, platform string, fn func(*models.VideoStats) error) error
+	// ListVideoStats pages through the stats rows of the videos the viewer sees with their
+	// materialized scores, returning the page and the total matching rows
+	ListVideoStats(ctx context.Context, viewer *models.User, filter models.StatsListFilter, limit, offset int) ([]*models.VideoStats, int64, error)
+	GetTopPerforming(ctx context.Context, tenantID, metric string, limit int) (*Leaderboard, error)
 
 	// Dashboard analytics
 	GetDashboardStats(ctx context.Context, tenantID string) (*DashboardStats, error)
@@ -54,22 +139,28 @@ type AnalyticsService interface {
 	// Advanced analytics
 	GetROIAnalytics(ctx context.Context, tenantID string, from, to time.Time) (*ROIAnalytics, error)
 	GetEngagementAnalytics(ctx context.Context, tenantID string, from, to time.Time) (*EngagementAnalytics, error)
-
-	// Data synchronization
-	SyncStats(ctx context.Context, tenantID string) error
+	// GetTrafficSources splits the views of the videos uploaded in [from, to)
+	// by traffic source, overall, per platform and for the most viewed
+	GetTrafficSources(ctx context.Context, tenantID string, from, to time.Time) (*TrafficSourceAnalytics, error)
+	// GetDeviceBreakdown and GetGeographyBreakdown split the views of the
+	// videos matching the filter by device type and by country
+	GetDeviceBreakdown(ctx context.Context, tenantID string, filter models.BreakdownFilter) (*ViewsBreakdown, error)
+	GetGeographyBreakdown(ctx context.Context, tenantID string, filter models.BreakdownFilter) (*ViewsBreakdown, error)
 }
 
 // CampaignService defines the interface for AI campaign management
 type CampaignService interface {
 	// Campaign CRUD operations
-	CreateCampaign(ctx context.Context, tenantID, userID string, req *CreateCampaignRequest) (*Campaign, error)
-	GetCampaign(ctx context.Context, tenantID, campaignID string) (*Campaign, error)
-	UpdateCampaign(ctx context.Context, tenantID, campaignID string, req *UpdateCampaignRequest) (*Campaign, error)
+	CreateCampaign(ctx context.Context, tenantID, userID string, req *CreateCampaignRequest) (*models.Campaign, error)
+	GetCampaign(ctx context.Context, tenantID, campaignID string) (*models.Campaign, error)
+	UpdateCampaign(ctx context.Context, tenantID, campaignID string, req *UpdateCampaignRequest) (*models.Campaign, error)
 	DeleteCampaign(ctx context.Context, tenantID, campaignID string) error
-	ListCampaigns(ctx context.Context, tenantID string, limit, offset int) ([]*Campaign, error)
+	ListCampaigns(ctx context.Context, tenantID string, limit, offset int) ([]*models.Campaign, error)
 
 	// Campaign execution operations
-	StartCampaign(ctx context.Context, tenantID, campaignID string) error
+	// StartCampaign starts a campaign; with simulate it only projects what
+	// the campaign would produce and cost, and returns the projection
+	StartCampaign(ctx context.Context, tenantID, campaignID string, simulate bool) (*CampaignSimulation, error)
 	StopCampaign(ctx context.Context, tenantID, campaignID string) error
 	PauseCampaign(ctx context.Context, tenantID, campaignID string) error
 	ResumeCampaign(ctx context.Context, tenantID, campaignID string) error
@@ -80,9 +171,557 @@ type CampaignService interface {
 	ExecuteValidationStep(ctx context.Context, tenantID, campaignID string) error
 
 	// Campaign scheduling operations
-	ScheduleCampaign(ctx context.Context, tenantID, campaignID string, schedule *CampaignSchedule) error
-	GetScheduledCampaigns(ctx context.Context, before time.Time, limit int) ([]*Campaign, error)
+	ScheduleCampaign(ctx context.Context, tenantID, campaignID string, schedule *models.CampaignSchedule) error
+	GetScheduledCampaigns(ctx context.Context, before time.Time, limit int) ([]*models.Campaign, error)
 	ProcessScheduledCampaigns(ctx context.Context) error
+
+	// GetCampaignHistory replays a campaign's events up to the sequence, all
+	// of them when it is 0, even for a deleted campaign
+	GetCampaignHistory(ctx context.Context, tenantID, campaignID string, until int64) (*CampaignHistory, error)
+}
+
+// AuditService defines the interface for the tenant audit trail
+type AuditService interface {
+	Record(ctx context.Context, entry *models.AuditLog) error
+	List(ctx context.Context, tenantID string, impersonatedOnly bool, limit, offset int) ([]*models.AuditLog, error)
+}
+
+// ImpersonationService defines the interface for admins acting as tenant users
+type ImpersonationService interface {
+	// Start checks that admin may act as the requested user and records the
+	// start of the session in the audit log of the user's tenant; the caller
+	// issues the session token
+	Start(ctx context.Context, admin *models.User, req *models.ImpersonateRequest, requestID string) (*Impersonation, error)
+}
+
+// WebhookService defines the interface for asynchronous platform webhook processing
+type WebhookService interface {
+	// Enqueue accepts a verified event for processing by a worker; it returns
+	// models.ErrWebhookQueueFull instead of blocking when the queue is full
+	// or the service is stopping. A body that does not match its platform's
+	// payload is quarantined and a *partners.PayloadError returned.
+	Enqueue(ctx context.Context, event *WebhookEvent) error
+
+	// Run processes queued events until ctx is cancelled, then drains the queue
+	Run(ctx context.Context)
+
+	// Stats counts the events this server instance has handled since it started
+	Stats() *WebhookStats
+}
+
+// DebugCaptureService defines the interface for capturing a tenant's API
+// requests to debug support reports
+type DebugCaptureService interface {
+	// Enable starts capturing the admin's tenant, replacing a running session
+	Enable(ctx context.Context, admin *models.User, req *models.EnableDebugCaptureRequest) (*models.DebugCaptureSession, error)
+	// Disable stops capturing the tenant; captures taken are kept until they expire
+	Disable(ctx context.Context, tenantID string) error
+	// Status returns the tenant's running session, or models.ErrDebugCaptureInactive
+	Status(ctx context.Context, tenantID string) (*models.DebugCaptureSession, error)
+	// ActiveSession returns the ID of the tenant's running session, if any.
+	// It is asked on every request, so answers are cached for a few seconds.
+	ActiveSession(ctx context.Context, tenantID string) (string, bool)
+	// Record stores a sanitized capture, setting its expiry
+	Record(ctx context.Context, capture *models.DebugCapture) error
+	List(ctx context.Context, tenantID, sessionID string, limit, offset int) ([]*models.DebugCapture, error)
+	Get(ctx context.Context, tenantID, id string) (*models.DebugCapture, error)
+	// PurgeExpired deletes captures past their retention
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// IdentityService defines the interface for loading the current user and
+// tenant of authenticated requests, whose tokens may outlive a deactivation
+// or a role change. Lookups are cached for a few seconds across requests.
+type IdentityService interface {
+	// Load returns ctx carrying the request's Identity, and its user. A user
+	// that no longer exists is models.ErrUserNotFound, a suspended one
+	// models.ErrUserSuspended and another that is not active
+	// models.ErrUserInactive; tenants likewise.
+	Load(ctx context.Context, tenantID, userID string) (context.Context, *models.User, error)
+	// Invalidate forgets the cached user, or the cached tenant when userID is
+	// empty, so a change made through this replica is seen at once
+	Invalidate(tenantID, userID string)
+}
+
+// SuspensionService defines the interface for suspending and reactivating
+// users and tenants. Every change is recorded with its reason in the audit
+// log of the tenant concerned before it is made.
+type SuspensionService interface {
+	// SuspendUser suspends a user of the admin's tenant
+	SuspendUser(ctx context.Context, admin *models.User, userID, reason, requestID string) (*models.User, error)
+	// ReactivateUser makes a suspended or inactive user of the admin's tenant active
+	ReactivateUser(ctx context.Context, admin *models.User, userID, reason, requestID string) (*models.User, error)
+	// SuspendTenant suspends another tenant; only support admins may
+	SuspendTenant(ctx context.Context, admin *models.User, tenantID, reason, requestID string) (*models.Tenant, error)
+	// ReactivateTenant makes a suspended tenant active; only support admins may
+	ReactivateTenant(ctx context.Context, admin *models.User, tenantID, reason, requestID string) (*models.Tenant, error)
+}
+
+// Identity is the current user and tenant of a request. They are shared by
+// every request of the cache period and must not be modified. Tenant is nil
+// when the tenant has no row.
+type Identity struct {
+	User   *models.User
+	Tenant *models.Tenant
+}
+
+// StatsFreshnessService defines the interface for the dead man's switch of the
+// stats sync, which tells admins when stats stop being synced
+type StatsFreshnessService interface {
+	// Check exports the latest sync of every tenant and platform and alerts
+	// the admins about stats older than the threshold: once when they turn
+	// stale and once when they are synced again
+	Check(ctx context.Context) (*StatsFreshnessReport, error)
+}
+
+// StatsBackfillService defines the interface for importing the past daily
+// stats of a platform, which the regular sync only records from the day it
+// starts
+type StatsBackfillService interface {
+	// Start queues a backfill of the tenant's stats on the platform, read
+	// with the workspace's credentials. It fails with
+	// models.ErrBackfillInProgress while another one of the platform runs.
+	Start(ctx context.Context, tenantID, userID, platform, workspaceID string) (*models.StatsBackfill, error)
+	Get(ctx context.Context, tenantID, id string) (*models.StatsBackfill, error)
+	List(ctx context.Context, tenantID string, limit, offset int) ([]*models.StatsBackfill, error)
+	// Run advances the due backfills of every tenant, making at most the
+	// configured number of platform requests
+	Run(ctx context.Context) (*StatsBackfillRunReport, error)
+}
+
+// StatsSyncService defines the interface for syncing the tenants' stats
+// from the platforms
+type StatsSyncService interface {
+	// Sync refreshes the stats rows of the tenant on every platform. Each
+	// platform resumes from its checkpoint when its last sync stopped
+	// halfway, so large accounts complete over several runs.
+	Sync(ctx context.Context, tenantID string) (*StatsSyncRunReport, error)
+}
+
+// WebhookSubscriptionService defines the interface for registering our
+// webhook URLs with the platforms that push notifications, per connected
+// channel, and keeping their leases alive
+type WebhookSubscriptionService interface {
+	// Subscribe registers the callback for the workspace on each platform it
+	// is connected to whose client implements partners.WebhookSubscriber. A
+	// platform refusing is recorded as a failed subscription, not returned.
+	Subscribe(ctx context.Context, tenantID, workspaceID string) ([]*models.WebhookSubscription, error)
+	// Health returns the tenant's subscriptions with counts by state
+	Health(ctx context.Context, tenantID string) (*WebhookSubscriptionHealth, error)
+	// ConfirmLease records the lease a platform granted when it verified
+	// the callback of a topic. Topics no tenant subscribed to are ignored.
+	ConfirmLease(ctx context.Context, platform, topic string, lease time.Duration) error
+	// RenewDue requests again the subscriptions of every tenant whose lease
+	// ends soon, whose verification is late or that were last refused
+	RenewDue(ctx context.Context) (*WebhookSubscriptionReport, error)
+}
+
+// StatsScoreService defines the interface for materializing the performance
+// scores stats listings are sorted and filtered by
+type StatsScoreService interface {
+	// Recompute scores every stats row of the tenant
+	Recompute(ctx context.Context, tenantID string) (*StatsScoreReport, error)
+	// RecomputeDue recomputes the scores of the tenants not scored since the
+	// last daily scoring hour
+	RecomputeDue(ctx context.Context) (*StatsScoreReport, error)
+}
+
+// StatsCompactionService defines the interface for keeping the stats
+// snapshots of each tenant to what its plan retains
+type StatsCompactionService interface {
+	// Run collapses the snapshots of the days old enough into one per stats
+	// row and day, and deletes the snapshots past retention, for every
+	// tenant. An interrupted run resumes from the last day it compacted.
+	Run(ctx context.Context) (*StatsCompactionReport, error)
+}
+
+// PublishPreviewService defines the interface for previewing what publishing
+// a video would send to a platform
+type PublishPreviewService interface {
+	// Preview renders the video's metadata for the platform as its client
+	// would, failing with models.ErrInvalidPlatform for unknown platforms
+	Preview(ctx context.Context, viewer *models.User, videoID, platform string) (*PublishPreview, error)
+}
+
+// QuotaService defines the interface for budgeting the daily API quotas of
+// the platforms across a tenant's publications, comments and stats syncs
+type QuotaService interface {
+	partners.Budget
+	// Connections lists every platform with the user's workspaces holding
+	// its credentials and, for platforms with a daily quota, the tenant's
+	// usage of it
+	Connections(ctx context.Context, tenantID, userID string) ([]*models.PlatformConnection, error)
+}
+
+// PublicationService defines the interface for the two-phase publishing of
+// scheduled publication jobs: videos are uploaded hidden ahead of time on
+// platforms that allow it, then made public at the scheduled moment
+type PublicationService interface {
+	// Stage uploads the transcoded videos of publications scheduled within
+	// the staging lead, unlisted or private
+	Stage(ctx context.Context) (*PublicationRunReport, error)
+	// Release publishes the due publications, uploading those that could not
+	// be staged first
+	Release(ctx context.Context) (*PublicationRunReport, error)
+	// Unpublish takes a publication of the video down from its platform where
+	// the API allows, recording who did it and why. Publications that never
+	// reached the platform are only marked unpublished.
+	Unpublish(ctx context.Context, tenantID, userID, videoID, publicationID, reason string) (*models.PublicationJob, error)
+	// List returns the publications of a video the viewer sees
+	List(ctx context.Context, viewer *models.User, videoID string) ([]*models.PublicationJob, error)
+}
+
+// ConnectionService defines the interface for the platform connections of
+// workspaces whose token a platform rejected, which must be reconnected
+// before their publications go on
+type ConnectionService interface {
+	// NeedsReconnect marks the workspace's connection to the platform, holds
+	// its scheduled and staged publications and sends a reconnect link to the
+	// workspace's owner and the tenant's admins
+	NeedsReconnect(ctx context.Context, tenantID, workspaceID string, platform models.Platform) error
+	// Reconnected clears the mark once the platform accepts the workspace's
+	// credentials again and resumes the held publications, returning how many
+	Reconnected(ctx context.Context, user *models.User, workspaceID string, platform models.Platform) (int, error)
+}
+
+// BlackoutService defines the interface for the windows tenants publish
+// nothing in
+type BlackoutService interface {
+	// List returns the tenant's blackout windows, earliest first
+	List(ctx context.Context, tenantID string) ([]*models.BlackoutWindow, error)
+	Create(ctx context.Context, tenantID, userID string, req *models.BlackoutWindowRequest) (*models.BlackoutWindow, error)
+	// Delete removes a window; publications it shifted or held stay so
+	Delete(ctx context.Context, tenantID, id string) error
+	// Apply shifts the publication past the tenant's window covering at, or
+	// holds it for approval, saving it and telling its requester. It reports
+	// whether the publication was moved. Publications a window already moved
+	// are left as they are.
+	Apply(ctx context.Context, job *models.PublicationJob, at time.Time) (bool, error)
+}
+
+// ApprovalService defines the interface for requesting publications, which
+// tenants may require a second user to approve before they go out
+type ApprovalService interface {
+	// Publish requests the publication of the video on a platform. It awaits
+	// approval when the tenant's policy requires one, is held while the
+	// workspace must be reconnected to the platform, and is otherwise
+	// scheduled, for now unless req.ScheduledAt is set.
+	Publish(ctx context.Context, user *models.User, videoID string, req *models.CreatePublicationJobRequest) (*models.PublicationJob, error)
+	// Approve schedules a publication awaiting approval, including one a
+	// blackout window held. The approver has the policy's role or is an
+	// admin, and is not who requested it.
+	Approve(ctx context.Context, approver *models.User, publicationID, comment string) (*models.PublicationJob, error)
+	// Reject refuses a publication awaiting approval, explaining why in comment
+	Reject(ctx context.Context, approver *models.User, publicationID, comment string) (*models.PublicationJob, error)
+	// AwaitingApproval lists the tenant's publications awaiting approval
+	AwaitingApproval(ctx context.Context, tenantID string, limit, offset int) ([]*models.PublicationJob, error)
+	Policy(ctx context.Context, tenantID string) (*models.ApprovalPolicy, error)
+	SetPolicy(ctx context.Context, tenantID string, policy *models.ApprovalPolicy) (*models.ApprovalPolicy, error)
+}
+
+// RightsService defines the interface for following the rights to videos
+type RightsService interface {
+	// Expiring lists the tenant's videos whose rights expire within the next
+	// days, or expired within the last ones, soonest first
+	Expiring(ctx context.Context, tenantID string, days int) (*RightsReport, error)
+}
+
+// WatermarkService defines the interface for the logo tenants burn into
+// their transcoded videos
+type WatermarkService interface {
+	// Get returns the tenant's watermark with its image, or
+	// ErrWatermarkNotFound when none was uploaded
+	Get(ctx context.Context, tenantID string) (*models.Watermark, error)
+	// Upload replaces the tenant's watermark image, keeping its settings
+	Upload(ctx context.Context, tenantID string, image []byte) (*models.Watermark, error)
+	Update(ctx context.Context, tenantID string, req *models.UpdateWatermarkRequest) (*models.Watermark, error)
+	Delete(ctx context.Context, tenantID string) error
+	// Resolve returns how the tenant's watermark is burnt into the video
+	// transcoded for the platform, or nil when it is not
+	Resolve(ctx context.Context, tenantID string, platform models.Platform, override *models.WatermarkOverride) (*transcode.Watermark, error)
+}
+
+// RenditionService defines the interface for the per-platform renditions
+// the transcoding worker makes of a video
+type RenditionService interface {
+	// Plan marks a rendition of every profile the platforms take pending,
+	// for the worker to transcode
+	Plan(ctx context.Context, tenantID, videoID string) ([]*models.VideoRendition, error)
+	// PlanDubbed marks the renditions carrying dubs pending again, for the
+	// worker to mux the video's audio tracks in
+	PlanDubbed(ctx context.Context, tenantID, videoID string) ([]*models.VideoRendition, error)
+	Complete(ctx context.Context, tenantID, videoID, profile string, out *models.RenditionOutput) (*models.VideoRendition, error)
+	Fail(ctx context.Context, tenantID, videoID, profile, reason string) error
+	List(ctx context.Context, viewer *models.User, videoID string) ([]*models.VideoRendition, error)
+	// ForPlatform returns the ready rendition to upload to the platform, nil
+	// when the video has none for it, or ErrRenditionNotReady
+	ForPlatform(ctx context.Context, tenantID, videoID string, platform models.Platform) (*models.VideoRendition, error)
+}
+
+// DubbingService defines the interface for the audio tracks of a video in
+// other languages than the original
+type DubbingService interface {
+	// Dub plans a track in the language for the worker to synthesize from
+	// the video's transcript in it, translated from the original one unless
+	// it was uploaded
+	Dub(ctx context.Context, viewer *models.User, videoID string, req *models.DubRequest) (*models.AudioTrack, error)
+	// Complete records where the worker stored a synthesized track, and
+	// plans the renditions carrying dubs again to mux it in
+	Complete(ctx context.Context, tenantID, videoID, language string, out *models.RenditionOutput) (*models.AudioTrack, error)
+	Fail(ctx context.Context, tenantID, videoID, language, reason string) error
+	List(ctx context.Context, viewer *models.User, videoID string) ([]*models.AudioTrack, error)
+	Delete(ctx context.Context, viewer *models.User, videoID, language string) error
+	// Dubs returns the ready tracks the worker muxes into the video's
+	// rendition of the profile
+	Dubs(ctx context.Context, tenantID, videoID, profile string) ([]*models.AudioTrack, error)
+}
+
+// MediaInfoService defines the interface for what the processing worker
+// finds out about uploaded video files
+type MediaInfoService interface {
+	// Record stores the ffprobe output of the video's file
+	Record(ctx context.Context, tenantID, videoID string, probe []byte) (*MediaInfoReport, error)
+	// Get returns what was recorded with the warnings it raises, or
+	// ErrMediaNotProbed before the video is processed
+	Get(ctx context.Context, viewer *models.User, videoID string) (*MediaInfoReport, error)
+}
+
+// QCService defines the interface for the quality control of transcoded
+// videos
+type QCService interface {
+	// Record checks the log of ffmpeg run with transcode.QCArgs on the
+	// video's transcoded file. A ready video failing it turns qc_failed with
+	// the reasons, and its owner is told.
+	Record(ctx context.Context, tenantID, videoID string, log []byte) (*models.Video, error)
+	// Override releases a video that failed quality control, recording who
+	// did and why
+	Override(ctx context.Context, tenantID, userID, videoID, reason string) (*models.Video, error)
+}
+
+// ThumbnailService defines the interface for the hover previews the
+// processing worker generates
+type ThumbnailService interface {
+	// Plan returns how to generate the video's sprites and animation, or
+	// ErrMediaNotProbed while its duration is unknown
+	Plan(ctx context.Context, tenantID, videoID string) (*transcode.PreviewPlan, error)
+	// Record stores where the worker uploaded the previews generated with plan
+	Record(ctx context.Context, tenantID, videoID string, plan *transcode.PreviewPlan, spriteURLs []string, animationURL string) (*models.HoverPreview, error)
+}
+
+// FrameService defines the interface for the frames the processing worker
+// extracts for users to pick thumbnails from
+type FrameService interface {
+	// Plan returns how to extract the video's frames, or ErrMediaNotProbed
+	// while its duration is unknown
+	Plan(ctx context.Context, tenantID, videoID string) (*transcode.FramePlan, error)
+	// Record stores where the worker uploaded the frames extracted with plan,
+	// with the log of the scene-change extraction giving their times
+	Record(ctx context.Context, tenantID, videoID string, plan *transcode.FramePlan, baseURL string, sceneLog []byte) (*models.VideoFrames, error)
+	// Frames returns the frames nearest the timestamps, or the scene-change
+	// candidates without any, or ErrFramesNotExtracted
+	Frames(ctx context.Context, viewer *models.User, videoID string, timestamps []float64) ([]models.Frame, error)
+}
+
+// BrandService defines the interface for the brand profile of tenants
+type BrandService interface {
+	// Get returns the tenant's profile, or DefaultBrand marked as the default
+	Get(ctx context.Context, tenantID string) (*models.BrandProfile, error)
+	Put(ctx context.Context, tenantID, userID string, req *models.BrandProfileRequest) (*models.BrandProfile, error)
+	// Brand returns what the tenant's thumbnails are rendered with
+	Brand(ctx context.Context, tenantID string) (transcode.Brand, error)
+	// EmailBranding returns what the tenant's notification emails are styled with
+	EmailBranding(ctx context.Context, tenantID string) (notify.Branding, error)
+}
+
+// CompositorService defines the interface for the branded thumbnail
+// variants laying a hook text over a frame of a video, which the processing
+// worker renders
+type CompositorService interface {
+	// Compose asks for a variant of the text per layout, with the tenant's
+	// brand, rendered by the worker with ThumbnailVariant.Args
+	Compose(ctx context.Context, viewer *models.User, videoID string, req *models.ComposeThumbnailsRequest) ([]*models.ThumbnailVariant, error)
+	// Complete records where the worker uploaded a rendered variant
+	Complete(ctx context.Context, tenantID, videoID, variantID, imageURL string) (*models.ThumbnailVariant, error)
+	// Fail records why the worker could not render a variant
+	Fail(ctx context.Context, tenantID, videoID, variantID, reason string) error
+	List(ctx context.Context, viewer *models.User, videoID string) ([]*models.ThumbnailVariant, error)
+	// Select makes a rendered variant the video's thumbnail
+	Select(ctx context.Context, viewer *models.User, videoID, variantID string) (*models.Video, error)
+}
+
+// ActivityService defines the interface for the activity feeds of videos and
+// campaigns
+type ActivityService interface {
+	// VideoActivity returns the video's events that occurred before the given
+	// time, or until now when it is zero, newest first
+	VideoActivity(ctx context.Context, viewer *models.User, videoID string, before time.Time, limit int) (*ActivityFeed, error)
+	// CampaignActivity returns the events of the campaign and of its videos
+	CampaignActivity(ctx context.Context, tenantID, campaignID string, before time.Time, limit int) (*ActivityFeed, error)
+}
+
+// TransferService defines the interface for handing videos to other
+// tenants, such as from an agency to its client
+type TransferService interface {
+	// Request offers the sender's video to the target tenant
+	Request(ctx context.Context, tenantID, userID, videoID string, req *models.CreateTransferRequest) (*models.VideoTransfer, error)
+	// Accept copies the video, its file and, when asked, its stats into the
+	// recipient's tenant, then deletes the sender's video for a move
+	Accept(ctx context.Context, tenantID, userID, transferID string) (*models.VideoTransfer, error)
+	// Decline is the recipient turning the transfer down
+	Decline(ctx context.Context, tenantID, userID, transferID string) (*models.VideoTransfer, error)
+	// Cancel is the sender withdrawing the transfer
+	Cancel(ctx context.Context, tenantID, userID, transferID string) (*models.VideoTransfer, error)
+	// Get returns a transfer the tenant sent or received
+	Get(ctx context.Context, tenantID, transferID string) (*models.VideoTransfer, error)
+	List(ctx context.Context, tenantID string, incoming bool, status models.TransferStatus, limit, offset int) ([]*models.VideoTransfer, error)
+}
+
+// PreferencesService defines the interface for the preferences of users
+type PreferencesService interface {
+	Get(ctx context.Context, tenantID, userID string) (*models.UserPreferences, error)
+	Update(ctx context.Context, tenantID, userID string, req *models.UpdatePreferencesRequest) (*models.UserPreferences, error)
+	// Location returns the user's timezone, used to bucket analytics by day
+	Location(ctx context.Context, tenantID, userID string) *time.Location
+}
+
+// NotificationService defines the interface for notifying users on the
+// channels and at the frequency they chose
+type NotificationService interface {
+	Notify(ctx context.Context, tenantID, userID string, subject, message i18n.Message) error
+	ListInApp(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.UserNotification, error)
+	// SendDigests emails the digests due, returning how many were sent
+	SendDigests(ctx context.Context) (int, error)
+	// PreviewEmail renders a sample email of the template, one of
+	// notify.Templates, as the user would receive it
+	PreviewEmail(ctx context.Context, tenantID, userID, template string) (*notify.Email, error)
+}
+
+// AlertService defines the interface for the alert rules users set on the
+// tenant's stats and publications, delivered as notifications
+type AlertService interface {
+	CreateRule(ctx context.Context, tenantID, userID string, req *models.AlertRuleRequest) (*models.AlertRule, error)
+	GetRule(ctx context.Context, tenantID, userID, id string) (*models.AlertRule, error)
+	ListRules(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.AlertRule, error)
+	UpdateRule(ctx context.Context, tenantID, userID, id string, req *models.AlertRuleRequest) (*models.AlertRule, error)
+	DeleteRule(ctx context.Context, tenantID, userID, id string) error
+	// EvaluateStats checks the stats rules of every tenant against the
+	// current stats
+	EvaluateStats(ctx context.Context) (*AlertReport, error)
+	// PublicationFailed checks the publication rules after a failed attempt
+	// of the job
+	PublicationFailed(ctx context.Context, job *models.PublicationJob)
+}
+
+// SmartListService defines the interface for the filters users save over
+// the video library, whose videos are the ones matching when used
+type SmartListService interface {
+	CreateList(ctx context.Context, tenantID, userID string, req *models.SmartListRequest) (*models.SmartList, error)
+	GetList(ctx context.Context, tenantID, userID, id string) (*models.SmartList, error)
+	ListLists(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.SmartList, error)
+	UpdateList(ctx context.Context, tenantID, userID, id string, req *models.SmartListRequest) (*models.SmartList, error)
+	DeleteList(ctx context.Context, tenantID, userID, id string) error
+	// ListVideos returns the videos the viewer sees matching their list,
+	// newest first
+	ListVideos(ctx context.Context, viewer *models.User, id string, limit, offset int) ([]*models.Video, error)
+	// VideoIDs returns up to limit videos matching the user's list, for the
+	// operations targeting a list rather than a selection
+	VideoIDs(ctx context.Context, tenantID, userID, id string, limit int) ([]string, error)
+}
+
+// VideoExportService defines the interface for exporting the metadata of
+// the video library to the systems it is distributed through without an
+// integration
+type VideoExportService interface {
+	// Export passes the videos the viewer sees, or those matching one of
+	// their smart lists, to fn newest first, up to 10000
+	Export(ctx context.Context, viewer *models.User, smartListID string, fn func(*models.Video) error) error
+}
+
+// AnalyticsReaderService defines the interface for the read-only credentials
+// BI tools query a tenant's stats with
+type AnalyticsReaderService interface {
+	// CreateReader returns the reader with its token, which is never shown again
+	CreateReader(ctx context.Context, tenantID, userID string, req *models.AnalyticsReaderRequest) (*models.AnalyticsReader, error)
+	ListReaders(ctx context.Context, tenantID string, limit, offset int) ([]*models.AnalyticsReader, error)
+	RevokeReader(ctx context.Context, tenantID, id string) error
+	// Authenticate returns the reader of the token, or
+	// ErrAnalyticsReaderNotFound when it is unknown or revoked
+	Authenticate(ctx context.Context, token string) (*models.AnalyticsReader, error)
+	// Query runs the query over the tenant's stats once it validates
+	Query(ctx context.Context, tenantID string, query *models.AnalyticsQuery) (*models.AnalyticsResult, error)
+}
+
+// AnalyticsSink defines the interface for the columnar store the analytics
+// queries of large tenants are answered from, MySQL remaining the fallback
+type AnalyticsSink interface {
+	// Record queues stats rows written to MySQL for the sink without
+	// blocking; rows are dropped when the queue is full
+	Record(tenantID string, stats []*models.VideoStats)
+	// Routes reports whether the sink answers the tenant's query
+	Routes(tenantID string, query *models.AnalyticsQuery) bool
+	// Query runs a validated query over the tenant's rows in the sink
+	Query(ctx context.Context, tenantID string, query *models.AnalyticsQuery) (*models.AnalyticsResult, error)
+	// Run creates the sink's table, then writes queued rows in batches until
+	// ctx is cancelled and the queue is drained
+	Run(ctx context.Context)
+}
+
+// RetentionService defines the interface for the audience retention curves of
+// videos and the drop-offs found in them
+type RetentionService interface {
+	// GetRetention returns the video's stored curves with their drop-offs
+	GetRetention(ctx context.Context, viewer *models.User, videoID string) (*models.VideoRetention, error)
+	// SyncRetention fetches the curves of the video from the platforms it is
+	// published on that report them, with the workspace's credentials
+	SyncRetention(ctx context.Context, viewer *models.User, videoID, workspaceID string) (*models.VideoRetention, error)
+}
+
+// LoginService defines the interface for signing users in and watching their
+// logins for anomalies
+type LoginService interface {
+	// Login checks the user's password. A suspicious login gets a challenge
+	// instead of the user: Verify grants it once the user confirms it with
+	// the code emailed to them.
+	Login(ctx context.Context, email, password string, client models.LoginClient) (*LoginResult, error)
+	// Verify grants a challenged login, from the device that started it
+	Verify(ctx context.Context, req *models.VerifyLoginRequest, client models.LoginClient) (*models.User, error)
+	// History returns the user's login attempts, newest first
+	History(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.LoginAttempt, error)
+}
+
+// JobService tracks the asynchronous operations of every subsystem as jobs.
+// Subsystems report on the job of a resource; the first report starts it
+// when Start was not called.
+type JobService interface {
+	Start(ctx context.Context, tenantID, userID string, ref models.JobRef) *models.Job
+	Progress(ctx context.Context, tenantID string, ref models.JobRef, percent int)
+	Succeed(ctx context.Context, tenantID string, ref models.JobRef)
+	Fail(ctx context.Context, tenantID string, ref models.JobRef, reason string)
+	Get(ctx context.Context, tenantID, id string) (*models.Job, error)
+	List(ctx context.Context, tenantID string, filter models.JobFilter, limit, offset int) ([]*models.Job, error)
+	PurgeFinished(ctx context.Context) (int64, error)
+}
+
+// OpsService defines the interface for the cross-tenant operational views of
+// support staff
+type OpsService interface {
+	// FailedPublications counts the publication jobs that failed since the given time
+	FailedPublications(ctx context.Context, since time.Time) ([]*models.PlatformCount, error)
+	// DeadLetters counts the failed publication jobs with no retries left
+	DeadLetters(ctx context.Context) (*DeadLetterReport, error)
+	// AISpend sums AI usage by tenant since the given time, highest cost first
+	AISpend(ctx context.Context, since time.Time, limit int) ([]*models.TenantAISpend, error)
+	// WebhookStats reports the webhook counters of this server instance
+	WebhookStats(ctx context.Context) *WebhookStats
+	// QuarantinedWebhooks lists the webhooks that failed validation, newest
+	// first and without their bodies, optionally of one platform
+	QuarantinedWebhooks(ctx context.Context, platform string, limit, offset int) ([]*models.QuarantinedWebhook, error)
+	// QuarantinedWebhook returns a quarantined webhook with its body, or
+	// models.ErrQuarantinedWebhookNotFound
+	QuarantinedWebhook(ctx context.Context, id string) (*models.QuarantinedWebhook, error)
+	// StatsSync reports how long ago each tenant's stats were synced,
+	// least recently synced first
+	StatsSync(ctx context.Context, limit int) (*StatsSyncReport, error)
 }
 
 // PromptService defines the interface for prompt catalog management
@@ -102,19 +741,29 @@ type PromptService interface {
 
 	// Prompt validation operations
 	ValidatePrompt(ctx context.Context, prompt *Prompt) error
-	TestPrompt(ctx context.Context, key string, testData map[string]interface{}) (*PromptTestResult, error)
+	ValidateCatalog(ctx context.Context) (*CatalogValidationReport, error)
+	TestPrompt(ctx context.Context, key string, model aws.FoundationModel, testData map[string]interface{}) (*PromptTestResult, error)
+
+	// Channel operations. Every other operation applies to the channel set on
+	// ctx with WithPromptChannel, production by default.
+	PromoteCatalog(ctx context.Context, from PromptChannel) (*PromptPromotion, error)
+
+	// Run reloads the catalog whenever its source changes, until ctx is cancelled
+	Run(ctx context.Context)
 }
 
 // Request/Response types for services
 
 // MagicBrushRequest represents a request for magic brush generation
 type MagicBrushRequest struct {
-	VideoID   string                 `json:"video_id" validate:"required"`
-	BrushType string                 `json:"brush_type" validate:"required,oneof=title description tags thumbnail"`
-	Context   map[string]interface{} `json:"context,omitempty"`
-	Language  string                 `json:"language,omitempty" validate:"omitempty,len=2"`
-	Tone      string                 `json:"tone,omitempty" validate:"omitempty,oneof=professional casual creative formal"`
-	MaxLength int                    `json:"max_length,omitempty" validate:"omitempty,min=1,max=1000"`
+	VideoID    string                 `json:"video_id" validate:"required"`
+	CampaignID string                 `json:"campaign_id,omitempty"` // Campaign the content is generated for
+	BrushType  string                 `json:"brush_type" validate:"required,oneof=title description tags thumbnail"`
+	Context    map[string]interface{} `json:"context,omitempty"`
+	Language   string                 `json:"language,omitempty" validate:"omitempty,len=2"`
+	Tone       string                 `json:"tone,omitempty" validate:"omitempty,oneof=professional casual creative formal"`
+	MaxLength  int                    `json:"max_length,omitempty" validate:"omitempty,min=1,max=1000"`
+	Model      string                 `json:"model,omitempty"` // Model alias, e.g. claude-sonnet or claude-haiku
 }
 
 // MagicBrushResponse represents the response from magic brush generation
@@ -124,115 +773,114 @@ type MagicBrushResponse struct {
 	Result      string                 `json:"result"`
 	Confidence  float64                `json:"confidence"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Guardrails  *GuardrailReport       `json:"guardrails,omitempty"`
+	Usage       models.AIUsageTotal    `json:"usage"` // Summed over the requests made, a guardrail retry included
 	ProcessedAt time.Time              `json:"processed_at"`
 }
 
+// GuardrailReport describes how generated content that broke a guardrail
+// was handled; Violations are those the returned answer still had before
+// redaction and truncation
+type GuardrailReport struct {
+	Retried    bool                 `json:"retried"`
+	Violations []GuardrailViolation `json:"violations,omitempty"`
+	Redacted   int                  `json:"redacted"`
+}
+
+// GuardrailViolation is a rule generated content breaks
+type GuardrailViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ChatRequest represents a message sent to an AI assistant conversation
+type ChatRequest struct {
+	ConversationID string                 `json:"conversation_id,omitempty"`
+	Message        string                 `json:"message" binding:"required" validate:"required,max=10000"`
+	Purpose        string                 `json:"purpose,omitempty" validate:"omitempty,oneof=title description tags general"`
+	VideoID        string                 `json:"video_id,omitempty"`
+	Context        map[string]interface{} `json:"context,omitempty"`
+	Model          string                 `json:"model,omitempty"` // Model alias, e.g. claude-sonnet or claude-haiku
+}
+
+// ChatResponse represents the assistant reply in a conversation
+type ChatResponse struct {
+	ConversationID string    `json:"conversation_id"`
+	Reply          string    `json:"reply"`
+	MessageCount   int       `json:"message_count"`
+	TokensUsed     int       `json:"tokens_used"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	ProcessedAt    time.Time `json:"processed_at"`
+}
+
 // CreateCampaignRequest represents a request to create a new campaign
 type CreateCampaignRequest struct {
-	Name      string                 `json:"name" validate:"required,max=255"`
-	Goal      string                 `json:"goal" validate:"required,max=1000"`
-	Context   map[string]interface{} `json:"context" validate:"required"`
-	Theme     string                 `json:"theme,omitempty" validate:"max=255"`
-	Platforms []string               `json:"platforms" validate:"required,min=1"`
-	Language  string                 `json:"language" validate:"required,len=2"`
-	Schedule  *CampaignSchedule      `json:"schedule,omitempty"`
-	Budget    float64                `json:"budget,omitempty" validate:"omitempty,min=0"`
-	MaxVideos int                    `json:"max_videos,omitempty" validate:"omitempty,min=1,max=100"`
+	Name      string                   `json:"name" validate:"required,max=255"`
+	Goal      string                   `json:"goal" validate:"required,max=1000"`
+	Context   map[string]interface{}   `json:"context" validate:"required"`
+	Theme     string                   `json:"theme,omitempty" validate:"max=255"`
+	Platforms []string                 `json:"platforms" validate:"required,min=1"`
+	Language  string                   `json:"language" validate:"required,len=2"`
+	Schedule  *models.CampaignSchedule `json:"schedule,omitempty"`
+	Budget    float64                  `json:"budget,omitempty" validate:"omitempty,min=0"`
+	MaxVideos int                      `json:"max_videos,omitempty" validate:"omitempty,min=1,max=100"`
 }
 
 // UpdateCampaignRequest represents a request to update an existing campaign
 type UpdateCampaignRequest struct {
-	Name      *string                `json:"name,omitempty" validate:"omitempty,max=255"`
-	Goal      *string                `json:"goal,omitempty" validate:"omitempty,max=1000"`
-	Context   map[string]interface{} `json:"context,omitempty"`
-	Theme     *string                `json:"theme,omitempty" validate:"omitempty,max=255"`
-	Platforms []string               `json:"platforms,omitempty" validate:"omitempty,min=1"`
-	Language  *string                `json:"language,omitempty" validate:"omitempty,len=2"`
-	Schedule  *CampaignSchedule      `json:"schedule,omitempty"`
-	Budget    *float64               `json:"budget,omitempty" validate:"omitempty,min=0"`
-	MaxVideos *int                   `json:"max_videos,omitempty" validate:"omitempty,min=1,max=100"`
-}
-
-// Campaign represents an AI campaign
-type Campaign struct {
-	ID          string                 `json:"id" db:"id"`
-	TenantID    string                 `json:"tenant_id" db:"tenant_id"`
-	UserID      string                 `json:"user_id" db:"user_id"`
-	Name        string                 `json:"name" db:"name"`
-	Goal        string                 `json:"goal" db:"goal"`
-	Context     map[string]interface{} `json:"context" db:"context"`
-	Theme       string                 `json:"theme" db:"theme"`
-	Platforms   []string               `json:"platforms" db:"platforms"`
-	Language    string                 `json:"language" db:"language"`
-	Status      CampaignStatus         `json:"status" db:"status"`
-	Schedule    *CampaignSchedule      `json:"schedule,omitempty" db:"schedule"`
-	Budget      float64                `json:"budget" db:"budget"`
-	MaxVideos   int                    `json:"max_videos" db:"max_videos"`
-	Progress    CampaignProgress       `json:"progress" db:"progress"`
-	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time              `json:"updated_at" db:"updated_at"`
-	StartedAt   *time.Time             `json:"started_at,omitempty" db:"started_at"`
-	CompletedAt *time.Time             `json:"completed_at,omitempty" db:"completed_at"`
-}
-
-// CampaignStatus represents the status of a campaign
-type CampaignStatus string
-
-const (
-	CampaignStatusDraft     CampaignStatus = "draft"
-	CampaignStatusScheduled CampaignStatus = "scheduled"
-	CampaignStatusRunning   CampaignStatus = "running"
-	CampaignStatusPaused    CampaignStatus = "paused"
-	CampaignStatusCompleted CampaignStatus = "completed"
-	CampaignStatusFailed    CampaignStatus = "failed"
-	CampaignStatusCancelled CampaignStatus = "cancelled"
-)
-
-// CampaignSchedule represents the scheduling configuration for a campaign
-type CampaignSchedule struct {
-	Type      ScheduleType `json:"type" validate:"required,oneof=once daily weekly monthly cron"`
-	StartTime time.Time    `json:"start_time" validate:"required"`
-	EndTime   *time.Time   `json:"end_time,omitempty"`
-	CronExpr  string       `json:"cron_expr,omitempty" validate:"required_if=Type cron"`
-	Timezone  string       `json:"timezone" validate:"required"`
-	MaxRuns   int          `json:"max_runs,omitempty" validate:"omitempty,min=1"`
-	RunCount  int          `json:"run_count" db:"run_count"`
-	LastRunAt *time.Time   `json:"last_run_at,omitempty" db:"last_run_at"`
-	NextRunAt *time.Time   `json:"next_run_at,omitempty" db:"next_run_at"`
+	Name      *string                  `json:"name,omitempty" validate:"omitempty,max=255"`
+	Goal      *string                  `json:"goal,omitempty" validate:"omitempty,max=1000"`
+	Context   map[string]interface{}   `json:"context,omitempty"`
+	Theme     *string                  `json:"theme,omitempty" validate:"omitempty,max=255"`
+	Platforms []string                 `json:"platforms,omitempty" validate:"omitempty,min=1"`
+	Language  *string                  `json:"language,omitempty" validate:"omitempty,len=2"`
+	Schedule  *models.CampaignSchedule `json:"schedule,omitempty"`
+	Budget    *float64                 `json:"budget,omitempty" validate:"omitempty,min=0"`
+	MaxVideos *int                     `json:"max_videos,omitempty" validate:"omitempty,min=1,max=100"`
 }
 
-// ScheduleType represents the type of campaign schedule
-type ScheduleType string
+// CampaignSimulation is what a dry run projects a campaign to produce and
+// cost. The AI steps really run, so AICost is spent; no video or
+// publication is created.
+type CampaignSimulation struct {
+	CampaignID            string                    `json:"campaign_id"`
+	Steps                 []*CampaignSimulationStep `json:"steps"`
+	ProjectedVideos       int                       `json:"projected_videos"`
+	ProjectedPublications int                       `json:"projected_publications"`
+	AICost                float64                   `json:"ai_cost"`
+	Budget                float64                   `json:"budget"`
+	WithinBudget          bool                      `json:"within_budget"`
+}
 
-const (
-	ScheduleTypeOnce    ScheduleType = "once"
-	ScheduleTypeDaily   ScheduleType = "daily"
-	ScheduleTypeWeekly  ScheduleType = "weekly"
-	ScheduleTypeMonthly ScheduleType = "monthly"
-	ScheduleTypeCron    ScheduleType = "cron"
-)
+// UploadProgress is a multipart upload with the parts S3 holds
+type UploadProgress struct {
+	Upload        *models.VideoUpload `json:"upload"`
+	Parts         []aws.UploadedPart  `json:"parts"`
+	MissingParts  []int               `json:"missing_parts"`
+	UploadedBytes int64               `json:"uploaded_bytes"`
+}
 
-// CampaignProgress represents the progress of a campaign
-type CampaignProgress struct {
-	CurrentStep     CampaignStep `json:"current_step" db:"current_step"`
-	ResearchDone    bool         `json:"research_done" db:"research_done"`
-	IdeationDone    bool         `json:"ideation_done" db:"ideation_done"`
-	ValidationDone  bool         `json:"validation_done" db:"validation_done"`
-	VideosCreated   int          `json:"videos_created" db:"videos_created"`
-	VideosPublished int          `json:"videos_published" db:"videos_published"`
-	TotalCost       float64      `json:"total_cost" db:"total_cost"`
+// PresignedPart is a URL to PUT one part of an upload to, straight to S3
+type PresignedPart struct {
+	PartNumber int       `json:"part_number"`
+	URL        string    `json:"url"`
+	ExpiresAt  time.Time `json:"expires_at"`
 }
 
-// CampaignStep represents the current step in a campaign workflow
-type CampaignStep string
+// CampaignHistory is a campaign's events and the state replaying them built
+type CampaignHistory struct {
+	Campaign *models.Campaign        `json:"campaign"`
+	Events   []*models.CampaignEvent `json:"events"`
+}
 
-const (
-	CampaignStepResearch   CampaignStep = "research"
-	CampaignStepIdeation   CampaignStep = "ideation"
-	CampaignStepValidation CampaignStep = "validation"
-	CampaignStepExecution  CampaignStep = "execution"
-	CampaignStepCompleted  CampaignStep = "completed"
-)
+// CampaignSimulationStep is the output of one AI step of a dry run
+type CampaignSimulationStep struct {
+	Step       models.CampaignStep `json:"step"`
+	Output     string              `json:"output"`
+	Model      string              `json:"model"`
+	TokensUsed int                 `json:"tokens_used"`
+	Cost       float64             `json:"cost"`
+}
 
 // Analytics response types
 
@@ -248,6 +896,45 @@ type DashboardStats struct {
 	TopPerformingTags []string `json:"top_performing_tags"`
 }
 
+// Leaderboard ranks a tenant's videos by one metric. RefreshedAt is the
+// oldest summary refresh among the entries: every entry reflects the stats
+// as of that time or later.
+type Leaderboard struct {
+	Metric      string                      `json:"metric"`
+	Entries     []*models.VideoStatsSummary `json:"entries"`
+	RefreshedAt time.Time                   `json:"refreshed_at"`
+	AgeSeconds  int64                       `json:"age_seconds"`
+	Stale       bool                        `json:"stale"`
+}
+
+// ArchiveStatus describes where a video file is stored and the progress of its restore
+type ArchiveStatus struct {
+	VideoID            string     `json:"video_id"`
+	Status             string     `json:"status"`
+	StorageClass       string     `json:"storage_class"`
+	ArchivedAt         *time.Time `json:"archived_at,omitempty"`
+	RestoreStatus      string     `json:"restore_status,omitempty"`
+	RestoreRequestedAt *time.Time `json:"restore_requested_at,omitempty"`
+	// JobID is the job tracking the restore, when one was requested
+	JobID string `json:"job_id,omitempty"`
+}
+
+// LifecycleReport counts what one lifecycle run changed
+type LifecycleReport struct {
+	Tenants  int `json:"tenants"`
+	Archived int `json:"archived"`
+	Restored int `json:"restored"`
+	Failed   int `json:"failed"`
+}
+
+// TenantResidency is where a tenant's data is stored and processed
+type TenantResidency struct {
+	TenantID  string              `json:"tenant_id"`
+	Residency residency.Residency `json:"residency"`
+	Pinned    bool                `json:"pinned"`
+	Placement residency.Placement `json:"placement"`
+}
+
 // PerformanceStats represents performance statistics
 type PerformanceStats struct {
 	Period           string                      `json:"period"`
@@ -276,6 +963,8 @@ type PlatformMetrics struct {
 	TotalComments  int64   `json:"total_comments"`
 	EngagementRate float64 `json:"engagement_rate"`
 	ROI            float64 `json:"roi"`
+	// Benchmark compares EngagementRate with the platform's norm
+	Benchmark *BenchmarkComparison `json:"benchmark,omitempty"`
 }
 
 // EngagementTrend represents engagement trend data
@@ -324,6 +1013,9 @@ type EngagementAnalytics struct {
 	TopContent           []*ContentEngagement           `json:"top_content"`
 	EngagementByPlatform map[string]*PlatformEngagement `json:"engagement_by_platform"`
 	EngagementTrends     []*EngagementTrend             `json:"engagement_trends"`
+	// Benchmark compares EngagementRate with the norms of the platforms,
+	// weighted by the views on each
+	Benchmark *BenchmarkComparison `json:"benchmark,omitempty"`
 }
 
 // ContentEngagement represents content engagement data
@@ -337,14 +1029,47 @@ type ContentEngagement struct {
 	EngagementRate float64 `json:"engagement_rate"`
 }
 
+// TrafficSourceAnalytics is where the views of a tenant's videos come from
+type TrafficSourceAnalytics struct {
+	Period     string                                `json:"period"`
+	Overall    *models.TrafficSourceViews            `json:"overall"`
+	ByPlatform map[string]*models.TrafficSourceViews `json:"by_platform"`
+	TopVideos  []*VideoTrafficSources                `json:"top_videos"` // Most viewed first
+}
+
+// VideoTrafficSources is where the views of a video come from, across platforms
+type VideoTrafficSources struct {
+	VideoID string `json:"video_id"`
+	*models.TrafficSourceViews
+}
+
+// ViewsBreakdown is the views of a tenant's videos split by device type or
+// country, most viewed first
+type ViewsBreakdown struct {
+	Period    string                   `json:"period"`
+	Platform  string                   `json:"platform,omitempty"` // Every platform when empty
+	Views     int64                    `json:"views"`
+	Breakdown []*models.BreakdownViews `json:"breakdown"`
+}
+
 // PlatformEngagement represents platform engagement data
 type PlatformEngagement struct {
-	Platform       string  `json:"platform"`
-	TotalViews     int64   `json:"total_views"`
-	TotalLikes     int64   `json:"total_likes"`
-	TotalShares    int64   `json:"total_shares"`
-	TotalComments  int64   `json:"total_comments"`
-	EngagementRate float64 `json:"engagement_rate"`
+	Platform       string               `json:"platform"`
+	TotalViews     int64                `json:"total_views"`
+	TotalLikes     int64                `json:"total_likes"`
+	TotalShares    int64                `json:"total_shares"`
+	TotalComments  int64                `json:"total_comments"`
+	EngagementRate float64              `json:"engagement_rate"`
+	Benchmark      *BenchmarkComparison `json:"benchmark,omitempty"`
+}
+
+// BenchmarkComparison puts an engagement rate next to the typical rate of
+// the platform. Rates are fractions of views.
+type BenchmarkComparison struct {
+	Vertical       string  `json:"vertical,omitempty"` // Set when the benchmark is specific to the tenant's vertical
+	EngagementRate float64 `json:"engagement_rate"`    // The benchmark
+	Difference     float64 `json:"difference"`         // Rate minus the benchmark
+	Index          float64 `json:"index"`              // Rate as a percent of the benchmark; 100 is on par
 }
 
 // Prompt catalog types
@@ -405,3 +1130,376 @@ type PromptTestResult struct {
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 	TestedAt   time.Time              `json:"tested_at"`
 }
+
+// CatalogValidationReport represents the result of linting the whole prompt catalog
+type CatalogValidationReport struct {
+	Valid        bool                     `json:"valid"`
+	CatalogPath  string                   `json:"catalog_path,omitempty"`
+	PromptsCount int                      `json:"prompts_count"`
+	Errors       int                      `json:"errors"`
+	Warnings     int                      `json:"warnings"`
+	Issues       []CatalogValidationIssue `json:"issues"`
+	ValidatedAt  time.Time                `json:"validated_at"`
+}
+
+// CatalogValidationIssue represents a single problem found in the prompt catalog
+type CatalogValidationIssue struct {
+	PromptKey string `json:"prompt_key,omitempty"`
+	Severity  string `json:"severity"`
+	Rule      string `json:"rule"`
+	Message   string `json:"message"`
+}
+
+// PromptPromotion is the outcome of promoting a channel's prompt catalog.
+// PromotedAt is zero when the catalog had errors and was not promoted.
+type PromptPromotion struct {
+	From       PromptChannel            `json:"from"`
+	To         PromptChannel            `json:"to"`
+	Report     *CatalogValidationReport `json:"report"`
+	PromotedAt time.Time                `json:"promoted_at,omitempty"`
+}
+
+// Impersonation is a granted impersonation session
+type Impersonation struct {
+	ID        string       `json:"impersonation_id"`
+	Admin     *models.User `json:"-"`
+	User      *models.User `json:"user"`
+	Reason    string       `json:"reason"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// WebhookEvent is a platform webhook accepted for asynchronous processing
+type WebhookEvent struct {
+	Platform    string
+	TenantID    string // Set when the webhook came through an authenticated route
+	ContentType string
+	Body        []byte
+	ReceivedAt  time.Time
+}
+
+// WebhookStats counts the webhook events of one server instance; every
+// replica has its own queue and counters
+type WebhookStats struct {
+	Since      time.Time               `json:"since"`
+	QueueDepth int                     `json:"queue_depth"`
+	QueueSize  int                     `json:"queue_size"`
+	Platforms  []*WebhookPlatformStats `json:"platforms"`
+}
+
+// WebhookPlatformStats counts a platform's webhook events. Malformed events
+// failed validation and were quarantined; rejected ones found the queue full;
+// failed ones errored or panicked while processed.
+type WebhookPlatformStats struct {
+	Platform  string  `json:"platform"`
+	Received  int64   `json:"received"`
+	Malformed int64   `json:"malformed"`
+	Rejected  int64   `json:"rejected"`
+	Failed    int64   `json:"failed"`
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// DeadLetterReport counts the publication jobs that exhausted their retries.
+// They stay failed until someone retries or cancels them.
+type DeadLetterReport struct {
+	Depth     int64                   `json:"depth"`
+	Platforms []*models.PlatformCount `json:"platforms"`
+}
+
+// RightsReport lists the videos whose rights expire around now
+type RightsReport struct {
+	Days   int               `json:"days"`
+	From   time.Time         `json:"from"`
+	To     time.Time         `json:"to"`
+	Videos []*ExpiringRights `json:"videos"`
+}
+
+// ExpiringRights are the rights to a video and where it is published
+type ExpiringRights struct {
+	VideoID     string             `json:"video_id"`
+	Title       string             `json:"title"`
+	Rights      models.VideoRights `json:"rights"`
+	Expired     bool               `json:"expired"`
+	DaysLeft    int                `json:"days_left"` // Negative once expired
+	PublishedOn []models.Platform  `json:"published_on"`
+}
+
+// PublicationRunReport summarizes a stage or release run
+type PublicationRunReport struct {
+	Jobs   int `json:"jobs"`
+	Done   int `json:"done"`
+	Failed int `json:"failed"`
+}
+
+// StatsSyncReport describes how current the stats of each tenant are. A
+// tenant is stale when its last sync is older than two sync intervals.
+type StatsSyncReport struct {
+	IntervalSeconds int64              `json:"interval_seconds"`
+	StaleTenants    int                `json:"stale_tenants"`
+	Tenants         []*TenantStatsSync `json:"tenants"`
+}
+
+// StatsFreshnessReport lists the stats of each tenant and platform last
+// synced longer ago than the threshold
+type StatsFreshnessReport struct {
+	ThresholdSeconds int64             `json:"threshold_seconds"`
+	Checked          int               `json:"checked"`
+	Stale            []*StaleStatsSync `json:"stale"`
+}
+
+// MagicBrushBatchRunReport sums up what a bulk magic brush run did
+type MagicBrushBatchRunReport struct {
+	Batches   int `json:"batches"`
+	Videos    int `json:"videos"`
+	Failed    int `json:"failed"`
+	Completed int `json:"completed"` // Batches
+}
+
+// StatsBackfillRunReport sums up what a backfill run did
+type StatsBackfillRunReport struct {
+	Backfills    int `json:"backfills"`
+	Requests     int `json:"requests"`
+	DaysImported int `json:"days_imported"`
+	Completed    int `json:"completed"`
+	Throttled    int `json:"throttled"`
+	Failed       int `json:"failed"`
+}
+
+// StatsSyncRunReport counts what one sync of a tenant did
+type StatsSyncRunReport struct {
+	Synced  int `json:"synced"`
+	Skipped int `json:"skipped"` // Rows whose video is gone, unpublished or failed to fetch
+	Resumed int `json:"resumed"` // Platforms resumed from a checkpoint
+}
+
+// WebhookSubscriptionHealth tells whether the platforms still push a
+// tenant's notifications
+type WebhookSubscriptionHealth struct {
+	Healthy       bool                          `json:"healthy"` // No subscription failed or expired
+	Active        int                           `json:"active"`
+	Pending       int                           `json:"pending"`
+	Failed        int                           `json:"failed"`
+	Expired       int                           `json:"expired"` // Active, but the lease ended without renewal
+	Subscriptions []*models.WebhookSubscription `json:"subscriptions"`
+}
+
+// WebhookSubscriptionReport sums up what a renewal run did
+type WebhookSubscriptionReport struct {
+	Due     int `json:"due"`
+	Renewed int `json:"renewed"`
+	Failed  int `json:"failed"`
+	Removed int `json:"removed"` // Their workspace was deleted or disconnected
+}
+
+// AlertReport sums up what an evaluation of the stats rules did
+type AlertReport struct {
+	Tenants  int `json:"tenants"` // Tenants with rules
+	Rules    int `json:"rules"`
+	Fired    int `json:"fired"`
+	Resolved int `json:"resolved"`
+	Failed   int `json:"failed"`
+}
+
+// StatsScoreReport sums up what a score computation did
+type StatsScoreReport struct {
+	Tenants int `json:"tenants"`
+	Scored  int `json:"scored"` // Stats rows scored
+	Failed  int `json:"failed"`
+}
+
+// StatsCompactionReport sums up what a compaction run did
+type StatsCompactionReport struct {
+	Tenants       int   `json:"tenants"`
+	DaysCompacted int   `json:"days_compacted"`
+	Compacted     int64 `json:"compacted"` // Intra-day snapshots deleted
+	Expired       int64 `json:"expired"`   // Snapshots deleted past retention
+	Failed        int   `json:"failed"`
+}
+
+// PublishPreview is a video's metadata as it would be published on a
+// platform, with the changes made to fit the platform's limits
+type PublishPreview struct {
+	VideoID      string                `json:"video_id"`
+	Platform     models.Platform       `json:"platform"`
+	Metadata     *partners.Metadata    `json:"metadata"`
+	Adjustments  []partners.Adjustment `json:"adjustments"`
+	Capabilities partners.Capabilities `json:"capabilities"`
+	// Watermark is how the tenant's watermark is burnt into the video, nil
+	// when it is not; publications may override it
+	Watermark *transcode.Watermark `json:"watermark"`
+}
+
+// MediaInfoReport is what ffprobe reported of a video's file, with what in
+// it platforms may reject
+type MediaInfoReport struct {
+	VideoID  string               `json:"video_id"`
+	ProbedAt time.Time            `json:"probed_at"`
+	Media    *transcode.MediaInfo `json:"media"`
+	Warnings []transcode.Warning  `json:"warnings"`
+}
+
+// Activity event types
+const (
+	ActivityAudit         = "audit"
+	ActivityAIGeneration  = "ai_generation"
+	ActivityPublication   = "publication"
+	ActivityStatMilestone = "stat_milestone"
+)
+
+// ActivityEvent is an entry of an activity feed: who did what, and when
+type ActivityEvent struct {
+	Type       string    `json:"type"`
+	Action     string    `json:"action"` // Route, prompt key or what happened to a publication or the stats
+	OccurredAt time.Time `json:"occurred_at"`
+	UserID     string    `json:"user_id,omitempty"` // Empty for what the platform or the workers did
+	VideoID    string    `json:"video_id,omitempty"`
+	Platform   string    `json:"platform,omitempty"`
+	Summary    string    `json:"summary"`
+	// Impersonated events were made by ImpersonatorID acting as UserID
+	Impersonated   bool                   `json:"impersonated,omitempty"`
+	ImpersonatorID string                 `json:"impersonator_id,omitempty"`
+	Details        map[string]interface{} `json:"details,omitempty"`
+}
+
+// ActivityFeed is a page of activity events, newest first
+type ActivityFeed struct {
+	Events []*ActivityEvent `json:"events"`
+	// NextBefore is the before parameter of the next page, unset on the last one
+	NextBefore *time.Time `json:"next_before,omitempty"`
+}
+
+// DailyStats is a video's stats at the end of a day, summed across platforms
+type DailyStats struct {
+	Date     string  `json:"date"` // YYYY-MM-DD in the user's timezone
+	Views    int64   `json:"views"`
+	Likes    int64   `json:"likes"`
+	Comments int64   `json:"comments"`
+	Shares   int64   `json:"shares"`
+	Revenue  float64 `json:"revenue"`
+}
+
+// LoginResult is a granted login, or the challenge of a suspicious one
+type LoginResult struct {
+	User      *models.User
+	Challenge *models.LoginChallenge
+}
+
+// StaleStatsSync is a tenant's platform whose stats are stale
+type StaleStatsSync struct {
+	*models.PlatformStatsSync
+	AgeSeconds int64 `json:"age_seconds"`
+}
+
+// TenantStatsSync is a tenant's sync status with its age at report time
+type TenantStatsSync struct {
+	*models.TenantStatsSync
+	AgeSeconds int64 `json:"age_seconds"`
+	Stale      bool  `json:"stale"`
+}
+
+// SeriesService defines the interface for series and their numbered episodes
+type SeriesService interface {
+	// List returns the tenant's series by name
+	List(ctx context.Context, tenantID string) ([]*models.Series, error)
+	Get(ctx context.Context, tenantID, id string) (*models.Series, error)
+	Create(ctx context.Context, tenantID, userID string, req *models.SeriesRequest) (*models.Series, error)
+	// Update replaces a series' settings; its workspace cannot change once
+	// its playlist exists
+	Update(ctx context.Context, tenantID, id string, req *models.SeriesRequest) (*models.Series, error)
+	// Delete removes a series and takes its videos out of it
+	Delete(ctx context.Context, tenantID, id string) error
+	// AddEpisode numbers a video after the last episode of the season
+	AddEpisode(ctx context.Context, tenantID, seriesID string, req *models.AddEpisodeRequest) (*models.Video, error)
+	// Episodes returns the videos of a series by season and number
+	Episodes(ctx context.Context, tenantID, seriesID string) ([]*models.Video, error)
+	// Prepare renders the title an episode is uploaded with
+	Prepare(ctx context.Context, video *models.Video) error
+	// Released adds an episode released on YouTube to its series' playlist,
+	// at its place among the episodes already there
+	Released(ctx context.Context, job *models.PublicationJob, video *models.Video, ws *models.Workspace)
+}
+
+// EncodingPresetService defines the interface for the settings tenants
+// transcode their renditions with
+type EncodingPresetService interface {
+	// List returns the settings of every rendition profile by name, the
+	// tenant's presets or the built-in ones
+	List(ctx context.Context, tenantID string) ([]*models.EncodingPreset, error)
+	Get(ctx context.Context, tenantID, profile string) (*models.EncodingPreset, error)
+	// Put tunes a profile for the tenant, keeping its aspect ratio and the
+	// limits of the platforms taking it
+	Put(ctx context.Context, tenantID, userID, profile string, req *models.EncodingPresetRequest) (*models.EncodingPreset, error)
+	// Delete returns a profile to its built-in settings
+	Delete(ctx context.Context, tenantID, profile string) error
+	// Profile returns the profile the tenant's renditions are transcoded with
+	Profile(ctx context.Context, tenantID, name string) (transcode.Profile, error)
+}
+
+// SummaryService defines the interface for the AI summaries of videos
+type SummaryService interface {
+	// Summarize returns the short, medium and long summaries and the key
+	// takeaways of the video's transcript, generated again only once the
+	// transcript changed, and can prefill the video's empty description
+	Summarize(ctx context.Context, viewer *models.User, videoID string, req *models.SummarizeRequest) (*SummaryResult, error)
+	// CampaignReport returns the campaign's latest videos with their
+	// summaries
+	CampaignReport(ctx context.Context, tenantID, campaignID string) (*CampaignReport, error)
+}
+
+// SummaryResult is the summary of a video
+type SummaryResult struct {
+	Summary *models.VideoSummary `json:"summary"`
+	// Cached is set when the transcript did not change since the summary
+	// was generated
+	Cached               bool `json:"cached"`
+	DescriptionPrefilled bool `json:"description_prefilled"`
+}
+
+// CampaignReport lists the videos of a campaign with their summaries
+type CampaignReport struct {
+	CampaignID string                 `json:"campaign_id"`
+	Name       string                 `json:"name"`
+	Videos     []*CampaignReportVideo `json:"videos"`
+	// Summarized counts the videos with a summary
+	Summarized int `json:"summarized"`
+}
+
+// CampaignReportVideo is a video of a campaign report, without a summary
+// until it is summarized
+type CampaignReportVideo struct {
+	VideoID string               `json:"video_id"`
+	Title   string               `json:"title"`
+	Status  string               `json:"status"`
+	Summary *models.VideoSummary `json:"summary,omitempty"`
+}
+
+// AssetService defines the interface for the tenant's library of files
+// reused across videos, and the intro and outro stitched onto renditions
+type AssetService interface {
+	// Create adds an asset waiting for its file, with the URL to PUT it to
+	// straight to S3
+	Create(ctx context.Context, tenantID, userID string, req *models.CreateAssetRequest) (*AssetUpload, error)
+	// Complete makes an asset ready once its file is uploaded
+	Complete(ctx context.Context, tenantID, id string) (*models.Asset, error)
+	// List returns the tenant's assets of the kind, of every kind when empty
+	List(ctx context.Context, tenantID string, kind models.AssetKind) ([]*models.Asset, error)
+	Get(ctx context.Context, tenantID, id string) (*models.Asset, error)
+	// Update renames an asset or changes its license
+	Update(ctx context.Context, tenantID, id string, req *models.UpdateAssetRequest) (*models.Asset, error)
+	// Delete removes an asset that is not the tenant's intro or outro
+	Delete(ctx context.Context, tenantID, id string) error
+	// Defaults returns the tenant's intro and outro, none when not chosen
+	Defaults(ctx context.Context, tenantID string) (*models.AssetDefaults, error)
+	// PutDefaults chooses the tenant's intro and outro and the profiles
+	// they are stitched onto
+	PutDefaults(ctx context.Context, tenantID, userID string, req *models.AssetDefaultsRequest) (*models.AssetDefaults, error)
+	// Stitching returns the intro and outro to stitch onto the tenant's
+	// renditions of the profile, nil when there is none
+	Stitching(ctx context.Context, tenantID, profile string) (intro, outro *models.Asset, err error)
+}
+
+// AssetUpload is an asset with the URL its file is uploaded to
+type AssetUpload struct {
+	Asset     *models.Asset `json:"asset"`
+	UploadURL string        `json:"upload_url"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// jobService implements the JobService interface. Tracking never fails the
+// operation tracked: errors saving a job are logged.
+type jobService struct {
+	jobs      models.JobRepository
+	retention time.Duration
+	clock     clock.Clock
+	logger    *logger.Logger
+}
+
+var _ JobService = (*jobService)(nil)
+
+// NewJobService creates a job service keeping finished jobs for retention
+func NewJobService(jobs models.JobRepository, retention time.Duration, clock clock.Clock, logger *logger.Logger) JobService {
+	return &jobService{jobs: jobs, retention: retention, clock: clock, logger: logger}
+}
+
+// Start queues a job on the resource and returns it, or the job already at
+// work on it. It returns nil when the job could not be saved.
+func (s *jobService) Start(ctx context.Context, tenantID, userID string, ref models.JobRef) *models.Job {
+	ctx = context.WithoutCancel(ctx)
+	if job, err := s.jobs.GetUnfinished(ctx, tenantID, ref.Type, ref.ResourceID); err == nil {
+		return job
+	} else if !errors.Is(err, models.ErrJobNotFound) {
+		s.logger.Error("Failed to load job", "error", err, "tenant_id", tenantID, "type", ref.Type, "resource_id", ref.ResourceID)
+		return nil
+	}
+
+	job := &models.Job{
+		TenantID:   tenantID,
+		UserID:     userID,
+		Type:       string(ref.Type),
+		ResourceID: ref.ResourceID,
+		State:      string(models.JobQueued),
+		Links:      ref.Links,
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		s.logger.Error("Failed to record job", "error", err, "tenant_id", tenantID, "type", ref.Type, "resource_id", ref.ResourceID)
+		return nil
+	}
+	return job
+}
+
+// Progress marks the resource's job running at percent. A job is only 100%
+// complete once it succeeded.
+func (s *jobService) Progress(ctx context.Context, tenantID string, ref models.JobRef, percent int) {
+	s.update(ctx, tenantID, ref, func(job *models.Job) {
+		job.State = string(models.JobRunning)
+		job.Percent = min(max(percent, 0), 99)
+	})
+}
+
+// Succeed finishes the resource's job
+func (s *jobService) Succeed(ctx context.Context, tenantID string, ref models.JobRef) {
+	s.update(ctx, tenantID, ref, func(job *models.Job) {
+		job.State = string(models.JobSucceeded)
+		job.Percent = 100
+		job.Error = ""
+	})
+}
+
+// Fail finishes the resource's job with the reason it failed
+func (s *jobService) Fail(ctx context.Context, tenantID string, ref models.JobRef, reason string) {
+	s.update(ctx, tenantID, ref, func(job *models.Job) {
+		job.State = string(models.JobFailed)
+		job.Error = reason
+	})
+}
+
+// update changes the resource's unfinished job. Subsystems that queue their
+// work before it is tracked, such as scheduled publications, get their job
+// on its first update.
+func (s *jobService) update(ctx context.Context, tenantID string, ref models.JobRef, change func(*models.Job)) {
+	ctx = context.WithoutCancel(ctx)
+	job, err := s.jobs.GetUnfinished(ctx, tenantID, ref.Type, ref.ResourceID)
+	found := err == nil
+	switch {
+	case errors.Is(err, models.ErrJobNotFound):
+		job = &models.Job{TenantID: tenantID, Type: string(ref.Type), ResourceID: ref.ResourceID}
+	case err != nil:
+		s.logger.Error("Failed to load job", "error", err, "tenant_id", tenantID, "type", ref.Type, "resource_id", ref.ResourceID)
+		return
+	}
+
+	now := s.clock.Now()
+	if job.StartedAt == nil {
+		job.StartedAt = &now
+	}
+	if job.Links == nil {
+		job.Links = models.JobLinks{}
+	}
+	for name, path := range ref.Links {
+		job.Links[name] = path
+	}
+	change(job)
+	if job.Finished() {
+		job.FinishedAt = &now
+	}
+
+	if found {
+		err = s.jobs.Update(ctx, job)
+	} else {
+		err = s.jobs.Create(ctx, job)
+	}
+	if err != nil {
+		s.logger.Error("Failed to record job", "error", err, "tenant_id", tenantID, "type", ref.Type, "resource_id", ref.ResourceID, "state", job.State)
+	}
+}
+
+// Get returns a job of the tenant
+func (s *jobService) Get(ctx context.Context, tenantID, id string) (*models.Job, error) {
+	return s.jobs.GetByID(ctx, tenantID, id)
+}
+
+// List returns the tenant's jobs matching the filter, newest first
+func (s *jobService) List(ctx context.Context, tenantID string, filter models.JobFilter, limit, offset int) ([]*models.Job, error) {
+	if filter.Type != "" && !filter.Type.Valid() {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "type must be one of %v", models.JobTypes)
+	}
+	switch filter.State {
+	case "", models.JobQueued, models.JobRunning, models.JobSucceeded, models.JobFailed:
+	default:
+		return nil, i18n.Errorf(models.ErrInvalidInput, "state must be one of %v", []models.JobState{models.JobQueued, models.JobRunning, models.JobSucceeded, models.JobFailed})
+	}
+	return s.jobs.List(ctx, tenantID, filter, limit, offset)
+}
+
+// PurgeFinished deletes the jobs finished longer than the retention ago
+func (s *jobService) PurgeFinished(ctx context.Context) (int64, error) {
+	deleted, err := s.jobs.DeleteFinishedBefore(ctx, s.clock.Now().Add(-s.retention))
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		s.logger.Info("Finished jobs purged", "count", deleted)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryJobRepo stores jobs in creation order
+type memoryJobRepo struct {
+	jobs []*models.Job
+}
+
+func (r *memoryJobRepo) Create(ctx context.Context, job *models.Job) error {
+	job.ID = fmt.Sprintf("job-%d", len(r.jobs)+1)
+	copied := *job
+	r.jobs = append(r.jobs, &copied)
+	return nil
+}
+
+func (r *memoryJobRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Job, error) {
+	for _, job := range r.jobs {
+		if job.TenantID == tenantID && job.ID == id {
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, models.ErrJobNotFound
+}
+
+func (r *memoryJobRepo) GetUnfinished(ctx context.Context, tenantID string, jobType models.JobType, resourceID string) (*models.Job, error) {
+	for i := len(r.jobs) - 1; i >= 0; i-- {
+		job := r.jobs[i]
+		if job.TenantID == tenantID && job.Type == string(jobType) && job.ResourceID == resourceID && !job.Finished() {
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, models.ErrJobNotFound
+}
+
+func (r *memoryJobRepo) Update(ctx context.Context, job *models.Job) error {
+	for i, stored := range r.jobs {
+		if stored.ID == job.ID {
+			copied := *job
+			r.jobs[i] = &copied
+			return nil
+		}
+	}
+	return models.ErrJobNotFound
+}
+
+func (r *memoryJobRepo) List(ctx context.Context, tenantID string, filter models.JobFilter, limit, offset int) ([]*models.Job, error) {
+	var jobs []*models.Job
+	for i := len(r.jobs) - 1; i >= 0; i-- {
+		job := r.jobs[i]
+		if job.TenantID == tenantID && (filter.Type == "" || job.Type == string(filter.Type)) &&
+			(filter.State == "" || job.State == string(filter.State)) &&
+			(filter.ResourceID == "" || job.ResourceID == filter.ResourceID) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (r *memoryJobRepo) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var kept []*models.Job
+	for _, job := range r.jobs {
+		if job.FinishedAt == nil || !job.FinishedAt.Before(before) {
+			kept = append(kept, job)
+		}
+	}
+	deleted := int64(len(r.jobs) - len(kept))
+	r.jobs = kept
+	return deleted, nil
+}
+
+// newTestJobs returns a job service over an in-memory repository, for the
+// subsystems whose jobs a test checks
+func newTestJobs(c clock.Clock) (*memoryJobRepo, JobService) {
+	repo := &memoryJobRepo{}
+	return repo, NewJobService(repo, 24*time.Hour, c, logger.New("error", "test"))
+}
+
+func TestJobService_StartReturnsTheUnfinishedJob(t *testing.T) {
+	repo, svc := newTestJobs(clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)))
+	ctx := context.Background()
+
+	first := svc.Start(ctx, "acme", "user-1", models.TranscodeJob("video-1"))
+	require.NotNil(t, first)
+	assert.Equal(t, string(models.JobQueued), first.State)
+	assert.Equal(t, "user-1", first.UserID)
+	assert.Equal(t, "/api/v1/videos/video-1", first.Links["video"])
+
+	again := svc.Start(ctx, "acme", "user-2", models.TranscodeJob("video-1"))
+	require.NotNil(t, again)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Len(t, repo.jobs, 1)
+
+	svc.Succeed(ctx, "acme", models.TranscodeJob("video-1"))
+	next := svc.Start(ctx, "acme", "user-1", models.TranscodeJob("video-1"))
+	require.NotNil(t, next)
+	assert.NotEqual(t, first.ID, next.ID, "a finished job is not reused")
+}
+
+func TestJobService_ProgressAndFinish(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	repo, svc := newTestJobs(now)
+	ctx := context.Background()
+	ref := models.TranscodeJob("video-1")
+
+	job := svc.Start(ctx, "acme", "", ref)
+	require.NotNil(t, job)
+
+	svc.Progress(ctx, "acme", ref, 100)
+	stored, err := svc.Get(ctx, "acme", job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.JobRunning), stored.State)
+	assert.Equal(t, 99, stored.Percent, "only a succeeded job is complete")
+	require.NotNil(t, stored.StartedAt)
+	assert.Nil(t, stored.FinishedAt)
+
+	now.Advance(time.Minute)
+	svc.Fail(ctx, "acme", ref, "unsupported codec")
+	stored, err = svc.Get(ctx, "acme", job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.JobFailed), stored.State)
+	assert.Equal(t, "unsupported codec", stored.Error)
+	require.NotNil(t, stored.FinishedAt)
+	assert.Equal(t, now.Now(), *stored.FinishedAt)
+	assert.Len(t, repo.jobs, 1)
+}
+
+func TestJobService_UpdateCreatesUntrackedJobs(t *testing.T) {
+	repo, svc := newTestJobs(clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)))
+	ctx := context.Background()
+
+	svc.Succeed(ctx, "acme", models.StatsSyncJob("acme"))
+
+	require.Len(t, repo.jobs, 1)
+	job := repo.jobs[0]
+	assert.Equal(t, string(models.JobStatsSync), job.Type)
+	assert.Equal(t, string(models.JobSucceeded), job.State)
+	assert.Equal(t, 100, job.Percent)
+	assert.NotNil(t, job.StartedAt)
+	assert.NotNil(t, job.FinishedAt)
+}
+
+func TestJobService_ListValidatesFilter(t *testing.T) {
+	_, svc := newTestJobs(clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)))
+	ctx := context.Background()
+
+	_, err := svc.List(ctx, "acme", models.JobFilter{Type: "encode"}, 20, 0)
+	assert.True(t, errors.Is(err, models.ErrInvalidInput))
+	_, err = svc.List(ctx, "acme", models.JobFilter{State: "done"}, 20, 0)
+	assert.True(t, errors.Is(err, models.ErrInvalidInput))
+
+	svc.Start(ctx, "acme", "", models.TranscodeJob("video-1"))
+	svc.Start(ctx, "acme", "", models.RestoreJob("video-2"))
+	jobs, err := svc.List(ctx, "acme", models.JobFilter{Type: models.JobRestore}, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "video-2", jobs[0].ResourceID)
+}
+
+func TestJobService_PurgeFinished(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	repo, svc := newTestJobs(now)
+	ctx := context.Background()
+
+	svc.Succeed(ctx, "acme", models.TranscodeJob("video-1"))
+	now.Advance(48 * time.Hour)
+	svc.Start(ctx, "acme", "", models.TranscodeJob("video-2"))
+
+	deleted, err := svc.PurgeFinished(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	require.Len(t, repo.jobs, 1)
+	assert.Equal(t, "video-2", repo.jobs[0].ResourceID)
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/notify"
+)
+
+const (
+	// loginChallengeTTL is how long the emailed code confirms a login
+	loginChallengeTTL = 15 * time.Minute
+	// maxChallengeAttempts is how many wrong codes void a challenge
+	maxChallengeAttempts = 5
+)
+
+// unknownUserHash is compared against the password of emails without a
+// user, so their logins take as long as those of wrong passwords
+const unknownUserHash = "$2a$10$AHbjcq3AZFuRS.wm3KbJEO9DDmtkmX4Rh1BFxj/RVp4KqRyn80i9u"
+
+// loginService implements the LoginService interface
+type loginService struct {
+	users         models.UserRepository
+	attempts      models.LoginAttemptRepository
+	notify        NotificationService
+	prefs         PreferencesService
+	mailer        notify.Mailer
+	maxFailures   int
+	failureWindow time.Duration
+	clock         clock.Clock
+	logger        *logger.Logger
+}
+
+var _ LoginService = (*loginService)(nil)
+
+// NewLoginService creates a new login service. Logins are challenged once
+// maxFailures attempts failed within failureWindow since the last login, or
+// when they come from a new device in a new country.
+func NewLoginService(users models.UserRepository, attempts models.LoginAttemptRepository, notifications NotificationService, prefs PreferencesService, mailer notify.Mailer, maxFailures int, failureWindow time.Duration, clock clock.Clock, logger *logger.Logger) LoginService {
+	return &loginService{
+		users:         users,
+		attempts:      attempts,
+		notify:        notifications,
+		prefs:         prefs,
+		mailer:        mailer,
+		maxFailures:   maxFailures,
+		failureWindow: failureWindow,
+		clock:         clock,
+		logger:        logger,
+	}
+}
+
+// Login grants the login, challenges it, or fails it with
+// ErrInvalidCredentials without telling unknown emails from wrong passwords.
+// Once the user is locked out every password gets a challenge, so guessing
+// on tells nothing.
+func (s *loginService) Login(ctx context.Context, email, password string, client models.LoginClient) (*LoginResult, error) {
+	user, err := s.users.FindByEmail(ctx, email)
+	if errors.Is(err, models.ErrUserNotFound) {
+		_ = bcrypt.CompareHashAndPassword([]byte(unknownUserHash), []byte(password))
+		s.logger.Info("Login for unknown email", "ip", client.IP)
+		return nil, models.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	attempt := &models.LoginAttempt{
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		IP:        client.IP,
+		Country:   client.Country,
+		DeviceID:  models.DeviceFingerprint(client.UserAgent),
+		UserAgent: client.UserAgent,
+		CreatedAt: s.clock.Now(),
+	}
+	footprint, err := s.attempts.Footprint(ctx, user.TenantID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read login history: %w", err)
+	}
+	// A first login has nothing to compare with
+	if footprint.LastSucceededAt != nil {
+		attempt.NewDevice = !slices.Contains(footprint.Devices, attempt.DeviceID)
+		attempt.NewCountry = client.Country != "" && !slices.Contains(footprint.Countries, client.Country)
+	}
+
+	since := attempt.CreatedAt.Add(-s.failureWindow)
+	if footprint.LastSucceededAt != nil && footprint.LastSucceededAt.After(since) {
+		since = *footprint.LastSucceededAt
+	}
+	failed, err := s.attempts.CountFailed(ctx, user.TenantID, user.ID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count failed logins: %w", err)
+	}
+	locked := failed >= int64(s.maxFailures)
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		attempt.Outcome = string(models.LoginFailed)
+		s.record(ctx, attempt)
+		if locked {
+			return s.decoy(attempt), nil
+		}
+		return nil, models.ErrInvalidCredentials
+	}
+	if locked {
+		return s.challenge(ctx, user, attempt, "too many failed sign-in attempts")
+	}
+	if models.UserStatus(user.Status) != models.StatusActive {
+		return nil, models.ErrUserInactive
+	}
+
+	switch {
+	case attempt.NewDevice && attempt.NewCountry:
+		return s.challenge(ctx, user, attempt, "sign-in from a new device in a new country")
+	}
+
+	attempt.Outcome = string(models.LoginSucceeded)
+	s.grant(ctx, user, attempt)
+	if attempt.NewDevice || attempt.NewCountry {
+		s.alert(ctx, user, attempt)
+	}
+	return &LoginResult{User: user}, nil
+}
+
+// challenge withholds the login until the user confirms it with the code
+// emailed to them. The reason is only logged: the challenge is the same
+// whatever it is.
+func (s *loginService) challenge(ctx context.Context, user *models.User, attempt *models.LoginAttempt, reason string) (*LoginResult, error) {
+	code, err := verificationCode()
+	if err != nil {
+		return nil, err
+	}
+	challenge := &models.LoginChallenge{
+		ID:        id.New(),
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		IP:        attempt.IP,
+		Country:   attempt.Country,
+		DeviceID:  attempt.DeviceID,
+		UserAgent: attempt.UserAgent,
+		Reason:    reason,
+		ExpiresAt: attempt.CreatedAt.Add(loginChallengeTTL),
+	}
+	challenge.CodeHash = hashCode(challenge.ID, code)
+	if err := s.attempts.CreateChallenge(ctx, challenge); err != nil {
+		return nil, fmt.Errorf("failed to create login challenge: %w", err)
+	}
+
+	lang := s.language(ctx, user)
+	body := i18n.M("Enter %s to confirm the sign-in from %s with %s. The code expires in %d minutes. If it was not you, change your password.",
+		code, where(attempt), attempt.UserAgent, int(loginChallengeTTL.Minutes()))
+	if err := s.mailer.Send(ctx, user.Email, i18n.T(lang, "Confirm your sign-in"), body.In(lang)); err != nil {
+		return nil, fmt.Errorf("failed to send verification code: %w", err)
+	}
+
+	attempt.Outcome = string(models.LoginChallenged)
+	s.record(ctx, attempt)
+	s.logger.Warn("Suspicious login challenged", "tenant_id", user.TenantID, "user_id", user.ID, "ip", attempt.IP, "country", attempt.Country, "reason", reason)
+	return &LoginResult{Challenge: challenge}, nil
+}
+
+// decoy answers a wrong password to a locked-out user as challenge answers
+// the right one. Nothing is stored or emailed, so no code confirms it.
+func (s *loginService) decoy(attempt *models.LoginAttempt) *LoginResult {
+	return &LoginResult{Challenge: &models.LoginChallenge{
+		ID:        id.New(),
+		ExpiresAt: attempt.CreatedAt.Add(loginChallengeTTL),
+	}}
+}
+
+// Verify grants the challenged login when the code is right
+func (s *loginService) Verify(ctx context.Context, req *models.VerifyLoginRequest, client models.LoginClient) (*models.User, error) {
+	challenge, err := s.attempts.GetChallenge(ctx, req.ChallengeID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	if challenge.VerifiedAt != nil || !now.Before(challenge.ExpiresAt) || challenge.Attempts >= maxChallengeAttempts ||
+		challenge.DeviceID != models.DeviceFingerprint(client.UserAgent) {
+		return nil, models.ErrLoginChallengeInvalid
+	}
+	if subtle.ConstantTimeCompare([]byte(hashCode(challenge.ID, req.Code)), []byte(challenge.CodeHash)) != 1 {
+		challenge.Attempts++
+		if err := s.attempts.UpdateChallenge(ctx, challenge); err != nil {
+			return nil, fmt.Errorf("failed to update login challenge: %w", err)
+		}
+		return nil, models.ErrLoginChallengeInvalid
+	}
+
+	challenge.VerifiedAt = &now
+	if err := s.attempts.UpdateChallenge(ctx, challenge); err != nil {
+		return nil, fmt.Errorf("failed to update login challenge: %w", err)
+	}
+	user, err := s.users.GetByID(ctx, challenge.TenantID, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if models.UserStatus(user.Status) != models.StatusActive {
+		return nil, models.ErrUserInactive
+	}
+
+	s.grant(ctx, user, &models.LoginAttempt{
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		Outcome:   string(models.LoginVerified),
+		IP:        client.IP,
+		Country:   challenge.Country,
+		DeviceID:  challenge.DeviceID,
+		UserAgent: challenge.UserAgent,
+		CreatedAt: now,
+	})
+	return user, nil
+}
+
+// History returns the user's login attempts
+func (s *loginService) History(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.LoginAttempt, error) {
+	return s.attempts.List(ctx, tenantID, userID, limit, offset)
+}
+
+// grant records the login the user gets a token for
+func (s *loginService) grant(ctx context.Context, user *models.User, attempt *models.LoginAttempt) {
+	s.record(ctx, attempt)
+	if err := s.users.UpdateLastLogin(ctx, user.TenantID, user.ID); err != nil {
+		s.logger.Error("Failed to update last login", "error", err, "tenant_id", user.TenantID, "user_id", user.ID)
+	}
+}
+
+// record stores the attempt. Failures are logged: the login has already
+// been decided.
+func (s *loginService) record(ctx context.Context, attempt *models.LoginAttempt) {
+	if err := s.attempts.Create(ctx, attempt); err != nil {
+		s.logger.Error("Failed to record login attempt", "error", err, "tenant_id", attempt.TenantID, "user_id", attempt.UserID, "outcome", attempt.Outcome)
+	}
+}
+
+// alert tells the user about a login from a new device or country
+func (s *loginService) alert(ctx context.Context, user *models.User, attempt *models.LoginAttempt) {
+	message := i18n.M("Signed in from %s with %s. If it was not you, change your password.", where(attempt), attempt.UserAgent)
+	if err := s.notify.Notify(ctx, user.TenantID, user.ID, i18n.M("New sign-in to your account"), message); err != nil {
+		s.logger.Error("Failed to alert user of new login", "error", err, "tenant_id", user.TenantID, "user_id", user.ID)
+	}
+}
+
+// language is the user's language, the default when it cannot be read
+func (s *loginService) language(ctx context.Context, user *models.User) string {
+	prefs, err := s.prefs.Get(ctx, user.TenantID, user.ID)
+	if err != nil {
+		s.logger.Warn("Failed to read user language", "error", err, "tenant_id", user.TenantID, "user_id", user.ID)
+		return i18n.Default
+	}
+	return prefs.Language
+}
+
+// where names the network of the attempt, with its country when known
+func where(attempt *models.LoginAttempt) string {
+	if attempt.Country == "" {
+		return attempt.IP
+	}
+	return attempt.IP + " (" + attempt.Country + ")"
+}
+
+// verificationCode returns a random six-digit code
+func verificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashCode hashes a challenge's code, salted with the challenge ID
+func hashCode(challengeID, code string) string {
+	sum := sha256.Sum256([]byte(challengeID + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
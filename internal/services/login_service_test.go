@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// loginUserRepo finds users by email and counts their logins
+type loginUserRepo struct {
+	memoryUserRepo
+	lastLogins int
+}
+
+func (r *loginUserRepo) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, models.ErrUserNotFound
+}
+
+func (r *loginUserRepo) UpdateLastLogin(ctx context.Context, tenantID, id string) error {
+	r.lastLogins++
+	return nil
+}
+
+// memoryLoginAttemptRepo keeps login attempts and challenges in memory
+type memoryLoginAttemptRepo struct {
+	attempts   []*models.LoginAttempt
+	challenges map[string]*models.LoginChallenge
+}
+
+func (r *memoryLoginAttemptRepo) Create(ctx context.Context, attempt *models.LoginAttempt) error {
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+func (r *memoryLoginAttemptRepo) List(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.LoginAttempt, error) {
+	var out []*models.LoginAttempt
+	for i := len(r.attempts) - 1; i >= 0; i-- {
+		if a := r.attempts[i]; a.TenantID == tenantID && a.UserID == userID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryLoginAttemptRepo) Footprint(ctx context.Context, tenantID, userID string) (*models.LoginFootprint, error) {
+	var footprint models.LoginFootprint
+	for _, a := range r.attempts {
+		if a.TenantID != tenantID || a.UserID != userID || !a.Granted() {
+			continue
+		}
+		footprint.Devices = append(footprint.Devices, a.DeviceID)
+		if a.Country != "" {
+			footprint.Countries = append(footprint.Countries, a.Country)
+		}
+		at := a.CreatedAt
+		footprint.LastSucceededAt = &at
+	}
+	return &footprint, nil
+}
+
+func (r *memoryLoginAttemptRepo) CountFailed(ctx context.Context, tenantID, userID string, since time.Time) (int64, error) {
+	var count int64
+	for _, a := range r.attempts {
+		if a.TenantID == tenantID && a.UserID == userID && a.Outcome == string(models.LoginFailed) && a.CreatedAt.After(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryLoginAttemptRepo) CreateChallenge(ctx context.Context, challenge *models.LoginChallenge) error {
+	r.challenges[challenge.ID] = challenge
+	return nil
+}
+
+func (r *memoryLoginAttemptRepo) GetChallenge(ctx context.Context, id string) (*models.LoginChallenge, error) {
+	if c, ok := r.challenges[id]; ok {
+		copied := *c
+		return &copied, nil
+	}
+	return nil, models.ErrLoginChallengeInvalid
+}
+
+func (r *memoryLoginAttemptRepo) UpdateChallenge(ctx context.Context, challenge *models.LoginChallenge) error {
+	r.challenges[challenge.ID] = challenge
+	return nil
+}
+
+type loginFixture struct {
+	svc      LoginService
+	users    *loginUserRepo
+	attempts *memoryLoginAttemptRepo
+	mailer   *recordingMailer
+	notified *recordingNotifications
+	clock    *clock.Fake
+}
+
+func newLoginFixture(t *testing.T) *loginFixture {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret-pass"), bcrypt.MinCost)
+	require.NoError(t, err)
+	log := logger.New("error", "test")
+	f := &loginFixture{
+		users: &loginUserRepo{memoryUserRepo: memoryUserRepo{users: []*models.User{
+			{ID: "ana", TenantID: "acme", Email: "ana@acme.test", Password: string(hash), Status: "active"},
+		}}},
+		attempts: &memoryLoginAttemptRepo{challenges: map[string]*models.LoginChallenge{}},
+		mailer:   &recordingMailer{},
+		notified: &recordingNotifications{subjects: map[string][]string{}},
+		clock:    clock.NewFake(time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)),
+	}
+	prefs := NewPreferencesService(&memoryPreferencesRepo{prefs: map[string]*models.UserPreferences{
+		"ana": {UserID: "ana", TenantID: "acme", Language: "fr", Timezone: "UTC"},
+	}}, log)
+	f.svc = NewLoginService(f.users, f.attempts, f.notified, prefs, f.mailer, 3, 15*time.Minute, f.clock, log)
+	return f
+}
+
+var (
+	laptop = models.LoginClient{IP: "203.0.113.7", Country: "FR", UserAgent: "Firefox on Linux"}
+	phone  = models.LoginClient{IP: "198.51.100.2", Country: "FR", UserAgent: "Safari on iPhone"}
+	abroad = models.LoginClient{IP: "192.0.2.9", Country: "BR", UserAgent: "Chrome on Windows"}
+)
+
+func TestLoginService_AlertsOnNewDevice(t *testing.T) {
+	f := newLoginFixture(t)
+	ctx := context.Background()
+
+	_, err := f.svc.Login(ctx, "nobody@acme.test", "s3cret-pass", laptop)
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	_, err = f.svc.Login(ctx, "ana@acme.test", "wrong", laptop)
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+
+	result, err := f.svc.Login(ctx, "ana@acme.test", "s3cret-pass", laptop)
+	require.NoError(t, err)
+	assert.Equal(t, "ana", result.User.ID)
+	assert.Empty(t, f.notified.subjects["ana"], "a first login has nothing to compare with")
+
+	f.clock.Advance(time.Hour)
+	result, err = f.svc.Login(ctx, "ana@acme.test", "s3cret-pass", phone)
+	require.NoError(t, err)
+	require.NotNil(t, result.User)
+	assert.Equal(t, []string{"New sign-in to your account"}, f.notified.subjects["ana"])
+
+	history, err := f.svc.History(ctx, "acme", "ana", 20, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 3, "unknown emails are not recorded")
+	assert.Equal(t, string(models.LoginSucceeded), history[0].Outcome)
+	assert.True(t, history[0].NewDevice)
+	assert.False(t, history[0].NewCountry)
+	assert.Equal(t, string(models.LoginFailed), history[2].Outcome)
+	assert.Equal(t, 2, f.users.lastLogins)
+}
+
+func TestLoginService_ChallengesNewDeviceInNewCountry(t *testing.T) {
+	f := newLoginFixture(t)
+	ctx := context.Background()
+	_, err := f.svc.Login(ctx, "ana@acme.test", "s3cret-pass", laptop)
+	require.NoError(t, err)
+
+	result, err := f.svc.Login(ctx, "ana@acme.test", "s3cret-pass", abroad)
+	require.NoError(t, err)
+	assert.Nil(t, result.User, "no token before the user confirms")
+	require.NotNil(t, result.Challenge)
+	require.Len(t, f.mailer.sent, 1)
+	assert.Equal(t, "ana@acme.test", f.mailer.sent[0].to)
+	assert.Equal(t, "Confirmez votre connexion", f.mailer.sent[0].subject, "in the user's language")
+	code := regexp.MustCompile(`\d{6}`).FindString(f.mailer.sent[0].body)
+	require.NotEmpty(t, code)
+
+	req := &models.VerifyLoginRequest{ChallengeID: result.Challenge.ID, Code: code}
+	_, err = f.svc.Verify(ctx, req, laptop)
+	assert.ErrorIs(t, err, models.ErrLoginChallengeInvalid, "only the device that signed in can confirm")
+	_, err = f.svc.Verify(ctx, &models.VerifyLoginRequest{ChallengeID: result.Challenge.ID, Code: "000000x"}, abroad)
+	assert.ErrorIs(t, err, models.ErrLoginChallengeInvalid)
+
+	user, err := f.svc.Verify(ctx, req, abroad)
+	require.NoError(t, err)
+	assert.Equal(t, "ana", user.ID)
+	_, err = f.svc.Verify(ctx, req, abroad)
+	assert.ErrorIs(t, err, models.ErrLoginChallengeInvalid, "a code is used once")
+
+	result, err = f.svc.Login(ctx, "ana@acme.test", "s3cret-pass", abroad)
+	require.NoError(t, err)
+	assert.NotNil(t, result.User, "the confirmed device is trusted")
+}
+
+func TestLoginService_LocksOutAfterFailures(t *testing.T) {
+	f := newLoginFixture(t)
+	ctx := context.Background()
+	_, err := f.svc.Login(ctx, "ana@acme.test", "s3cret-pass", laptop)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		f.clock.Advance(time.Minute)
+		_, err = f.svc.Login(ctx, "ana@acme.test", "guess", abroad)
+		assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	}
+	result, err := f.svc.Login(ctx, "ana@acme.test", "s3cret-pass", laptop)
+	require.NoError(t, err)
+	require.NotNil(t, result.Challenge, "even the usual device confirms by email once locked")
+
+	f.clock.Advance(16 * time.Minute)
+	_, err = f.svc.Verify(ctx, &models.VerifyLoginRequest{ChallengeID: result.Challenge.ID, Code: "123456"}, laptop)
+	assert.ErrorIs(t, err, models.ErrLoginChallengeInvalid, "codes expire")
+
+	result, err = f.svc.Login(ctx, "ana@acme.test", "s3cret-pass", laptop)
+	require.NoError(t, err)
+	assert.NotNil(t, result.User, "failures older than the window are forgiven")
+}
+
+func TestLoginService_LockedOutPasswordsLookAlike(t *testing.T) {
+	f := newLoginFixture(t)
+	ctx := context.Background()
+	_, err := f.svc.Login(ctx, "ana@acme.test", "s3cret-pass", laptop)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		f.clock.Advance(time.Minute)
+		_, err = f.svc.Login(ctx, "ana@acme.test", "guess", abroad)
+		assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	}
+
+	wrong, err := f.svc.Login(ctx, "ana@acme.test", "guess", laptop)
+	require.NoError(t, err, "a wrong password is not told apart once locked")
+	right, err := f.svc.Login(ctx, "ana@acme.test", "s3cret-pass", laptop)
+	require.NoError(t, err)
+	for _, result := range []*LoginResult{wrong, right} {
+		assert.Nil(t, result.User)
+		require.NotNil(t, result.Challenge)
+		assert.Equal(t, f.clock.Now().Add(loginChallengeTTL), result.Challenge.ExpiresAt)
+	}
+	wrongJSON, err := json.Marshal(wrong.Challenge)
+	require.NoError(t, err)
+	rightJSON, err := json.Marshal(right.Challenge)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Replace(string(wrongJSON), wrong.Challenge.ID, right.Challenge.ID, 1), string(rightJSON))
+
+	require.Len(t, f.mailer.sent, 1, "only the right password gets a code")
+	code := regexp.MustCompile(`\d{6}`).FindString(f.mailer.sent[0].body)
+	_, err = f.svc.Verify(ctx, &models.VerifyLoginRequest{ChallengeID: wrong.Challenge.ID, Code: code}, laptop)
+	assert.ErrorIs(t, err, models.ErrLoginChallengeInvalid)
+	history, err := f.svc.History(ctx, "acme", "ana", 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.LoginFailed), history[1].Outcome, "the guess still counts as a failure")
+}
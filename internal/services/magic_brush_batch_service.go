@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+const (
+	// maxUnfinishedBatches bounds the batches one run looks at
+	maxUnfinishedBatches = 20
+	// magicBrushVideosPerRun bounds the videos one run generates for, so a
+	// run holds its lease for minutes rather than hours
+	magicBrushVideosPerRun = 50
+)
+
+// magicBrushBatchService implements the MagicBrushBatchService interface
+type magicBrushBatchService struct {
+	batches    models.MagicBrushBatchRepository
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	users      models.UserRepository
+	lists      SmartListService
+	ai         AIService
+	jobs       JobService
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ MagicBrushBatchService = (*magicBrushBatchService)(nil)
+
+// NewMagicBrushBatchService creates a new bulk magic brush service
+func NewMagicBrushBatchService(batches models.MagicBrushBatchRepository, videos models.VideoRepository, workspaces models.WorkspaceRepository, users models.UserRepository, lists SmartListService, ai AIService, jobs JobService, clock clock.Clock, logger *logger.Logger) MagicBrushBatchService {
+	return &magicBrushBatchService{batches: batches, videos: videos, workspaces: workspaces, users: users, lists: lists, ai: ai, jobs: jobs, clock: clock, logger: logger}
+}
+
+// Start checks the brush type and videos, then queues the batch with a job
+// per video for the next run. Videos the user does not see are not found.
+// A smart list is matched once, here: videos matching it later are not
+// brushed.
+func (s *magicBrushBatchService) Start(ctx context.Context, tenantID, userID string, req *models.BulkMagicBrushRequest) (*models.MagicBrushBatch, error) {
+	if _, ok := BrushPrompts[req.BrushType]; !ok {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "unknown brush type %q", req.BrushType)
+	}
+	videoIDs, err := s.targets(ctx, tenantID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.users.GetByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	seen := make(map[string]bool, len(videoIDs))
+	results := make([]*models.MagicBrushResult, 0, len(videoIDs))
+	for _, videoID := range videoIDs {
+		if seen[videoID] {
+			continue
+		}
+		seen[videoID] = true
+		if _, err := visibleVideo(ctx, s.videos, s.workspaces, user, videoID); err != nil {
+			if errors.Is(err, models.ErrVideoNotFound) {
+				return nil, i18n.Errorf(models.ErrInvalidInput, "video %s not found", videoID)
+			}
+			return nil, err
+		}
+		results = append(results, &models.MagicBrushResult{
+			TenantID: tenantID,
+			VideoID:  videoID,
+			Position: len(results),
+			Status:   string(models.JobQueued),
+		})
+	}
+
+	batch := &models.MagicBrushBatch{
+		TenantID:    tenantID,
+		UserID:      userID,
+		BrushType:   req.BrushType,
+		Model:       req.Model,
+		Language:    req.Language,
+		Tone:        req.Tone,
+		MaxLength:   req.MaxLength,
+		Context:     req.Context,
+		Status:      models.MagicBrushBatchQueued,
+		VideosTotal: len(results),
+	}
+	if err := s.batches.Create(ctx, batch, results); err != nil {
+		return nil, fmt.Errorf("failed to create magic brush batch: %w", err)
+	}
+	for _, result := range results {
+		if job := s.jobs.Start(ctx, tenantID, userID, models.MagicBrushJob(result)); job != nil {
+			result.JobID = job.ID
+		}
+	}
+	batch.Results = results
+	s.logger.Info("Magic brush batch queued", "tenant_id", tenantID, "batch_id", batch.ID, "brush_type", batch.BrushType, "videos", len(results))
+	return batch, nil
+}
+
+// targets returns the selected videos, or those matching the smart list
+func (s *magicBrushBatchService) targets(ctx context.Context, tenantID, userID string, req *models.BulkMagicBrushRequest) ([]string, error) {
+	if req.SmartListID == "" {
+		if len(req.VideoIDs) == 0 || len(req.VideoIDs) > models.MaxMagicBrushBatchVideos {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "select between 1 and %d videos", models.MaxMagicBrushBatchVideos)
+		}
+		return req.VideoIDs, nil
+	}
+	if len(req.VideoIDs) > 0 {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "select videos or a smart list, not both")
+	}
+
+	videoIDs, err := s.lists.VideoIDs(ctx, tenantID, userID, req.SmartListID, models.MaxMagicBrushBatchVideos+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(videoIDs) == 0 || len(videoIDs) > models.MaxMagicBrushBatchVideos {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the smart list must match between 1 and %d videos, it matches %d", models.MaxMagicBrushBatchVideos, len(videoIDs))
+	}
+	return videoIDs, nil
+}
+
+// Get returns the batch with its results
+func (s *magicBrushBatchService) Get(ctx context.Context, tenantID, id string) (*models.MagicBrushBatch, error) {
+	batch, err := s.batches.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Results, err = s.batches.ListResults(ctx, tenantID, id); err != nil {
+		return nil, fmt.Errorf("failed to list magic brush results: %w", err)
+	}
+	return batch, nil
+}
+
+// Run generates the queued results of the unfinished batches, oldest batch
+// first, up to the videos of one run
+func (s *magicBrushBatchService) Run(ctx context.Context) (*MagicBrushBatchRunReport, error) {
+	batches, err := s.batches.ListUnfinished(ctx, maxUnfinishedBatches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list magic brush batches: %w", err)
+	}
+
+	report := &MagicBrushBatchRunReport{}
+	budget := magicBrushVideosPerRun
+	for _, batch := range batches {
+		if budget <= 0 || ctx.Err() != nil {
+			break
+		}
+		results, err := s.batches.ListResults(ctx, batch.TenantID, batch.ID)
+		if err != nil {
+			s.logger.Error("Failed to list magic brush results", "error", err, "tenant_id", batch.TenantID, "batch_id", batch.ID)
+			continue
+		}
+		report.Batches++
+		budget -= s.advance(ctx, batch, results, budget, report)
+	}
+	return report, nil
+}
+
+// advance generates up to budget of the batch's queued results, saving each
+// as it goes, and returns how many it generated
+func (s *magicBrushBatchService) advance(ctx context.Context, batch *models.MagicBrushBatch, results []*models.MagicBrushResult, budget int, report *MagicBrushBatchRunReport) int {
+	batch.Status = models.MagicBrushBatchRunning
+	done := 0
+	for _, result := range results {
+		if result.Status != string(models.JobQueued) {
+			continue
+		}
+		if done == budget || ctx.Err() != nil {
+			break
+		}
+		if !s.generate(ctx, batch, result) {
+			break
+		}
+		done++
+		report.Videos++
+		if result.Status == string(models.JobFailed) {
+			batch.VideosFailed++
+			report.Failed++
+		} else {
+			batch.VideosSucceeded++
+		}
+		batch.Usage.Add(result.Usage)
+	}
+
+	if batch.VideosSucceeded+batch.VideosFailed >= batch.VideosTotal {
+		now := s.clock.Now()
+		batch.Status = models.MagicBrushBatchCompleted
+		batch.CompletedAt = &now
+		report.Completed++
+		s.logger.Info("Magic brush batch completed", "tenant_id", batch.TenantID, "batch_id", batch.ID,
+			"succeeded", batch.VideosSucceeded, "failed", batch.VideosFailed, "cost", batch.Usage.Cost)
+	}
+	// Progress is saved even when the run is being stopped
+	if err := s.batches.Update(context.WithoutCancel(ctx), batch); err != nil {
+		s.logger.Error("Failed to save magic brush batch progress", "error", err, "tenant_id", batch.TenantID, "batch_id", batch.ID)
+	}
+	return done
+}
+
+// generate applies the batch's brush to the result's video and records the
+// outcome on the result and its job. A video interrupted by shutdown stays
+// queued for the next run, and generate reports it was not generated.
+func (s *magicBrushBatchService) generate(ctx context.Context, batch *models.MagicBrushBatch, result *models.MagicBrushResult) bool {
+	job := models.MagicBrushJob(result)
+	s.jobs.Progress(ctx, batch.TenantID, job, 0)
+
+	resp, err := s.brush(ctx, batch, result.VideoID)
+	if err != nil && ctx.Err() != nil {
+		return false
+	}
+	now := s.clock.Now()
+	result.ProcessedAt = &now
+	if err != nil {
+		result.Status = string(models.JobFailed)
+		result.Error = err.Error()
+		s.logger.Warn("Magic brush batch video failed", "error", err, "tenant_id", batch.TenantID, "batch_id", batch.ID, "video_id", result.VideoID)
+	} else {
+		result.Status = string(models.JobSucceeded)
+		result.Content = resp.Result
+		result.Usage = resp.Usage
+	}
+	if err := s.batches.UpdateResult(context.WithoutCancel(ctx), result); err != nil {
+		s.logger.Error("Failed to save magic brush result", "error", err, "tenant_id", batch.TenantID, "batch_id", batch.ID, "video_id", result.VideoID)
+	}
+
+	if err != nil {
+		s.jobs.Fail(ctx, batch.TenantID, job, result.Error)
+	} else {
+		s.jobs.Succeed(ctx, batch.TenantID, job)
+	}
+	return true
+}
+
+// brush generates the content of one video, its title, description and
+// duration filling the prompt over the batch's shared context
+func (s *magicBrushBatchService) brush(ctx context.Context, batch *models.MagicBrushBatch, videoID string) (*MagicBrushResponse, error) {
+	video, err := s.videos.GetByID(ctx, batch.TenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	promptData := make(map[string]interface{}, len(batch.Context)+4)
+	for key, value := range batch.Context {
+		promptData[key] = value
+	}
+	promptData["title"] = video.Title
+	promptData["topic"] = video.Title
+	if video.Description != "" {
+		promptData["topic"] = video.Description
+		promptData["description"] = video.Description
+	}
+	if video.Duration > 0 {
+		promptData["duration"] = (video.Duration + 59) / 60
+	}
+
+	return s.ai.GenerateMagicBrush(ctx, batch.TenantID, batch.UserID, &MagicBrushRequest{
+		VideoID:   videoID,
+		BrushType: batch.BrushType,
+		Context:   promptData,
+		Language:  batch.Language,
+		Tone:      batch.Tone,
+		MaxLength: batch.MaxLength,
+		Model:     batch.Model,
+	})
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+type memoryMagicBrushBatchRepo struct {
+	batches map[string]*models.MagicBrushBatch
+	results map[string][]*models.MagicBrushResult
+}
+
+func (r *memoryMagicBrushBatchRepo) Create(ctx context.Context, batch *models.MagicBrushBatch, results []*models.MagicBrushResult) error {
+	batch.ID = fmt.Sprintf("batch-%d", len(r.batches)+1)
+	for i, result := range results {
+		result.ID = fmt.Sprintf("%s-result-%d", batch.ID, i+1)
+		result.BatchID = batch.ID
+	}
+	r.batches[batch.ID] = batch
+	r.results[batch.ID] = results
+	return nil
+}
+
+func (r *memoryMagicBrushBatchRepo) Get(ctx context.Context, tenantID, id string) (*models.MagicBrushBatch, error) {
+	if batch, ok := r.batches[id]; ok && batch.TenantID == tenantID {
+		return batch, nil
+	}
+	return nil, models.ErrMagicBrushBatchNotFound
+}
+
+func (r *memoryMagicBrushBatchRepo) ListUnfinished(ctx context.Context, limit int) ([]*models.MagicBrushBatch, error) {
+	var batches []*models.MagicBrushBatch
+	for i := 1; i <= len(r.batches); i++ {
+		if batch := r.batches[fmt.Sprintf("batch-%d", i)]; !batch.Finished() {
+			batches = append(batches, batch)
+		}
+	}
+	return batches, nil
+}
+
+func (r *memoryMagicBrushBatchRepo) Update(ctx context.Context, batch *models.MagicBrushBatch) error {
+	return nil
+}
+
+func (r *memoryMagicBrushBatchRepo) ListResults(ctx context.Context, tenantID, batchID string) ([]*models.MagicBrushResult, error) {
+	return r.results[batchID], nil
+}
+
+func (r *memoryMagicBrushBatchRepo) UpdateResult(ctx context.Context, result *models.MagicBrushResult) error {
+	return nil
+}
+
+// brushingAI titles videos after their topic, failing on "flaky"
+type brushingAI struct {
+	AIService
+	requests []*MagicBrushRequest
+}
+
+func (a *brushingAI) GenerateMagicBrush(ctx context.Context, tenantID, userID string, req *MagicBrushRequest) (*MagicBrushResponse, error) {
+	a.requests = append(a.requests, req)
+	if req.VideoID == "flaky" {
+		return nil, errors.New("failed to generate content: throttled")
+	}
+	return &MagicBrushResponse{
+		VideoID:   req.VideoID,
+		BrushType: req.BrushType,
+		Result:    fmt.Sprintf("The truth about %v", req.Context["topic"]),
+		Usage:     models.AIUsageTotal{InputTokens: 300, OutputTokens: 40, Cost: 0.0015},
+	}, nil
+}
+
+// listedVideos serves the videos of the smart list "list-1"
+type listedVideos struct {
+	SmartListService
+	videoIDs []string
+}
+
+func (s *listedVideos) VideoIDs(ctx context.Context, tenantID, userID, id string, limit int) ([]string, error) {
+	if id != "list-1" {
+		return nil, models.ErrSmartListNotFound
+	}
+	return s.videoIDs, nil
+}
+
+func TestMagicBrushBatchService(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	videos := &publicationVideoRepo{videos: map[string]*models.Video{
+		"heist": {ID: "heist", TenantID: "acme", Title: "The heist", Description: "A museum heist in 1990", Duration: 610},
+		"flaky": {ID: "flaky", TenantID: "acme", Title: "Flaky"},
+		"manor": {ID: "manor", TenantID: "acme", Title: "The manor"},
+		"draft": {ID: "draft", TenantID: "acme", UserID: "user-2", Title: "Draft", Visibility: models.VisibilityOwner},
+	}}
+	users := &identityUserRepo{users: map[string]*models.User{"user-1": {ID: "user-1", TenantID: "acme", Role: "editor"}}}
+	repo := &memoryMagicBrushBatchRepo{batches: map[string]*models.MagicBrushBatch{}, results: map[string][]*models.MagicBrushResult{}}
+	ai := &brushingAI{}
+	jobRepo, jobs := newTestJobs(clock.NewFake(now))
+	lists := &listedVideos{}
+	svc := NewMagicBrushBatchService(repo, videos, nil, users, lists, ai, jobs, clock.NewFake(now), logger.New("error", "test"))
+	ctx := context.Background()
+
+	_, err := svc.Start(ctx, "acme", "user-1", &models.BulkMagicBrushRequest{VideoIDs: []string{"heist"}, BrushType: "summary"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Start(ctx, "acme", "user-1", &models.BulkMagicBrushRequest{VideoIDs: []string{"heist", "deleted"}, BrushType: "title"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Start(ctx, "acme", "user-1", &models.BulkMagicBrushRequest{VideoIDs: []string{"heist", "draft"}, BrushType: "title"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "videos the user does not see are not found")
+	assert.Empty(t, repo.batches)
+	_, err = svc.Start(ctx, "acme", "user-1", &models.BulkMagicBrushRequest{BrushType: "title"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	batch, err := svc.Start(ctx, "acme", "user-1", &models.BulkMagicBrushRequest{
+		VideoIDs:  []string{"heist", "flaky", "heist", "manor"},
+		BrushType: "title",
+		Language:  "fr",
+		Context:   map[string]interface{}{"audience": "true crime fans", "title": "ignored"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.MagicBrushBatchQueued, batch.Status)
+	assert.Equal(t, 3, batch.VideosTotal, "duplicates are brushed once")
+	require.Len(t, jobRepo.jobs, 3)
+	assert.Equal(t, string(models.JobMagicBrush), jobRepo.jobs[0].Type)
+	assert.Equal(t, "/api/v1/ai/magic-brush/bulk/"+batch.ID, jobRepo.jobs[0].Links["batch"])
+	assert.Equal(t, jobRepo.jobs[0].ID, batch.Results[0].JobID)
+
+	report, err := svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &MagicBrushBatchRunReport{Batches: 1, Videos: 3, Failed: 1, Completed: 1}, report)
+	require.Len(t, ai.requests, 3)
+	assert.Equal(t, "The heist", ai.requests[0].Context["title"], "the video's fields win over the shared context")
+	assert.Equal(t, "true crime fans", ai.requests[0].Context["audience"])
+	assert.Equal(t, 11, ai.requests[0].Context["duration"])
+	assert.Equal(t, "fr", ai.requests[0].Language)
+	assert.Equal(t, "user-1", batch.UserID)
+
+	got, err := svc.Get(ctx, "acme", batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.MagicBrushBatchCompleted, got.Status)
+	assert.Equal(t, now, *got.CompletedAt)
+	assert.Equal(t, 2, got.VideosSucceeded)
+	assert.Equal(t, 1, got.VideosFailed)
+	assert.Equal(t, models.AIUsageTotal{InputTokens: 600, OutputTokens: 80, Cost: 0.003}, got.Usage)
+	require.Len(t, got.Results, 3)
+	assert.Equal(t, "The truth about A museum heist in 1990", got.Results[0].Content)
+	assert.Equal(t, string(models.JobFailed), got.Results[1].Status)
+	assert.Contains(t, got.Results[1].Error, "throttled")
+	assert.Equal(t, "The truth about The manor", got.Results[2].Content)
+	assert.Equal(t, string(models.JobSucceeded), jobRepo.jobs[0].State)
+	assert.Equal(t, string(models.JobFailed), jobRepo.jobs[1].State)
+
+	report, err = svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, report.Batches, "completed batches are left alone")
+
+	_, err = svc.Get(ctx, "rival", batch.ID)
+	assert.ErrorIs(t, err, models.ErrMagicBrushBatchNotFound)
+
+	_, err = svc.Start(ctx, "acme", "user-1", &models.BulkMagicBrushRequest{SmartListID: "list-2", BrushType: "title"})
+	assert.ErrorIs(t, err, models.ErrSmartListNotFound)
+	_, err = svc.Start(ctx, "acme", "user-1", &models.BulkMagicBrushRequest{SmartListID: "list-1", BrushType: "title"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "an empty list brushes nothing")
+	_, err = svc.Start(ctx, "acme", "user-1", &models.BulkMagicBrushRequest{SmartListID: "list-1", VideoIDs: []string{"heist"}, BrushType: "title"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	lists.videoIDs = []string{"manor", "heist"}
+	listed, err := svc.Start(ctx, "acme", "user-1", &models.BulkMagicBrushRequest{SmartListID: "list-1", BrushType: "title"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, listed.VideosTotal)
+	assert.Equal(t, "manor", listed.Results[0].VideoID)
+}
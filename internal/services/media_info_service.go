@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// mediaInfoService implements the MediaInfoService interface
+type mediaInfoService struct {
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ MediaInfoService = (*mediaInfoService)(nil)
+
+// NewMediaInfoService creates a new media info service
+func NewMediaInfoService(videos models.VideoRepository, workspaces models.WorkspaceRepository, clock clock.Clock, logger *logger.Logger) MediaInfoService {
+	return &mediaInfoService{videos: videos, workspaces: workspaces, clock: clock, logger: logger}
+}
+
+// Record parses the ffprobe output and stores it on the video
+func (s *mediaInfoService) Record(ctx context.Context, tenantID, videoID string, probe []byte) (*MediaInfoReport, error) {
+	info, err := transcode.ParseProbe(probe)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidInput, err)
+	}
+	video, err := s.videos.GetByID(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	video.MediaInfo = info
+	video.ProbedAt = &now
+	if info.Video != nil {
+		video.Resolution = fmt.Sprintf("%dx%d", info.Video.Width, info.Video.Height)
+	}
+	if err := s.videos.Update(ctx, video); err != nil {
+		return nil, fmt.Errorf("failed to save media info: %w", err)
+	}
+
+	report := newMediaInfoReport(video)
+	if len(report.Warnings) > 0 {
+		codes := make([]string, 0, len(report.Warnings))
+		for _, w := range report.Warnings {
+			codes = append(codes, w.Code)
+		}
+		s.logger.Warn("Uploaded video may be rejected by platforms", "tenant_id", tenantID, "video_id", videoID, "warnings", codes)
+	}
+	return report, nil
+}
+
+// Get returns the media info recorded when the video was processed
+func (s *mediaInfoService) Get(ctx context.Context, viewer *models.User, videoID string) (*MediaInfoReport, error) {
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if video.MediaInfo == nil || video.ProbedAt == nil {
+		return nil, models.ErrMediaNotProbed
+	}
+	return newMediaInfoReport(video), nil
+}
+
+// newMediaInfoReport reports the media info of a probed video. Warnings are
+// worked out when reported, so they follow the current platform rules.
+func newMediaInfoReport(video *models.Video) *MediaInfoReport {
+	return &MediaInfoReport{
+		VideoID:  video.ID,
+		ProbedAt: *video.ProbedAt,
+		Media:    video.MediaInfo,
+		Warnings: video.MediaInfo.Warnings(),
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+const screenRecordingProbe = `{
+	"streams": [
+		{"codec_type": "video", "codec_name": "h264", "width": 2560, "height": 1440, "pix_fmt": "yuv420p",
+		 "color_space": "bt709", "r_frame_rate": "60/1", "avg_frame_rate": "2089/50", "bit_rate": "12000000"}
+	],
+	"format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "94.2", "bit_rate": "12100000"}
+}`
+
+func TestMediaInfoService(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	videos := &publicationVideoRepo{videos: map[string]*models.Video{"video-1": {ID: "video-1", TenantID: "acme"}}}
+	svc := NewMediaInfoService(videos, nil, clock.NewFake(now), logger.New("error", "test"))
+	ctx := context.Background()
+	viewer := &models.User{ID: "user-1", TenantID: "acme"}
+
+	_, err := svc.Get(ctx, viewer, "video-1")
+	assert.ErrorIs(t, err, models.ErrMediaNotProbed)
+
+	_, err = svc.Record(ctx, "acme", "video-1", []byte("ffprobe: not found"))
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Record(ctx, "acme", "video-2", []byte(screenRecordingProbe))
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+
+	_, err = svc.Record(ctx, "acme", "video-1", []byte(screenRecordingProbe))
+	require.NoError(t, err)
+	assert.Equal(t, "2560x1440", videos.videos["video-1"].Resolution)
+
+	report, err := svc.Get(ctx, viewer, "video-1")
+	require.NoError(t, err)
+	assert.Equal(t, now, report.ProbedAt)
+	assert.Equal(t, 41.78, report.Media.Video.AverageFrameRate)
+	var codes []string
+	for _, w := range report.Warnings {
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []string{transcode.WarnVariableFrameRate, transcode.WarnNoAudio}, codes)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+)
+
+// ModelPolicy decides which models a tenant may request and how requests fall back
+type ModelPolicy struct {
+	allowed       []aws.FoundationModel
+	tenantAllowed map[string][]aws.FoundationModel
+	fallbackChain []aws.FoundationModel
+}
+
+// NewModelPolicy builds a model policy from configuration strings.
+//
+// allowed and fallbackChain are comma-separated model names, e.g.
+// "claude-sonnet,claude-haiku". tenantAllowed overrides the allowlist per
+// tenant as "tenant-a=claude-haiku;tenant-b=claude-sonnet|claude-haiku".
+func NewModelPolicy(allowed, tenantAllowed, fallbackChain string) (*ModelPolicy, error) {
+	policy := &ModelPolicy{tenantAllowed: make(map[string][]aws.FoundationModel)}
+
+	var err error
+	if policy.allowed, err = parseModelList(allowed, ","); err != nil {
+		return nil, fmt.Errorf("invalid allowed models: %w", err)
+	}
+	if policy.fallbackChain, err = parseModelList(fallbackChain, ","); err != nil {
+		return nil, fmt.Errorf("invalid fallback chain: %w", err)
+	}
+	if len(policy.fallbackChain) == 0 {
+		return nil, fmt.Errorf("fallback chain must contain at least one model")
+	}
+
+	for _, entry := range strings.Split(tenantAllowed, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tenantID, list, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(tenantID) == "" {
+			return nil, fmt.Errorf("invalid tenant model allowlist entry: %q", entry)
+		}
+		tenantModels, err := parseModelList(list, "|")
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed models for tenant %s: %w", tenantID, err)
+		}
+		policy.tenantAllowed[strings.TrimSpace(tenantID)] = tenantModels
+	}
+
+	return policy, nil
+}
+
+// Resolve returns the ordered list of models to try for a tenant request.
+// Without an explicit model the configured fallback chain is used; an explicit
+// model is tried first and only falls back to the models after it in the chain,
+// so a request never escalates to a more expensive model.
+func (p *ModelPolicy) Resolve(tenantID, requested string) ([]aws.FoundationModel, error) {
+	allowed := p.allowedFor(tenantID)
+
+	if requested == "" {
+		chain := p.filter(p.fallbackChain, allowed)
+		if len(chain) == 0 {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "no model is allowed for this tenant")
+		}
+		return chain, nil
+	}
+
+	model, err := aws.ParseFoundationModel(requested)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidInput, err)
+	}
+	if !containsModel(allowed, model) {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "model %s is not allowed for this tenant", requested)
+	}
+
+	chain := []aws.FoundationModel{model}
+	for i, m := range p.fallbackChain {
+		if m == model {
+			chain = append(chain, p.filter(p.fallbackChain[i+1:], allowed)...)
+			break
+		}
+	}
+	return chain, nil
+}
+
+// AllowedModels returns the model aliases a tenant may request
+func (p *ModelPolicy) AllowedModels(tenantID string) []string {
+	allowed := p.allowedFor(tenantID)
+	names := make([]string, 0, len(allowed))
+	for _, model := range allowed {
+		names = append(names, aws.ModelAlias(model))
+	}
+	return names
+}
+
+// allowedFor returns the tenant allowlist, falling back to the global one
+func (p *ModelPolicy) allowedFor(tenantID string) []aws.FoundationModel {
+	if tenantModels, ok := p.tenantAllowed[tenantID]; ok {
+		return tenantModels
+	}
+	return p.allowed
+}
+
+// filter keeps the models of chain present in allowed, preserving order
+func (p *ModelPolicy) filter(chain, allowed []aws.FoundationModel) []aws.FoundationModel {
+	var result []aws.FoundationModel
+	for _, model := range chain {
+		if containsModel(allowed, model) {
+			result = append(result, model)
+		}
+	}
+	return result
+}
+
+// parseModelList parses a separated list of model names
+func parseModelList(list, sep string) ([]aws.FoundationModel, error) {
+	var result []aws.FoundationModel
+	for _, name := range strings.Split(list, sep) {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		model, err := aws.ParseFoundationModel(name)
+		if err != nil {
+			return nil, err
+		}
+		if !containsModel(result, model) {
+			result = append(result, model)
+		}
+	}
+	return result, nil
+}
+
+func containsModel(list []aws.FoundationModel, model aws.FoundationModel) bool {
+	for _, m := range list {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelPolicy_Resolve(t *testing.T) {
+	policy, err := NewModelPolicy(
+		"claude-sonnet,claude-haiku",
+		"starter=claude-haiku;legacy=claude-3-sonnet|claude-haiku",
+		"claude-sonnet,claude-haiku",
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		tenantID  string
+		requested string
+		expected  []aws.FoundationModel
+		wantErr   bool
+	}{
+		{
+			name:     "default chain",
+			tenantID: "acme",
+			expected: []aws.FoundationModel{aws.ModelClaude4Sonnet, aws.ModelClaude4Haiku},
+		},
+		{
+			name:      "explicit primary keeps its fallbacks",
+			tenantID:  "acme",
+			requested: "claude-sonnet",
+			expected:  []aws.FoundationModel{aws.ModelClaude4Sonnet, aws.ModelClaude4Haiku},
+		},
+		{
+			name:      "explicit cheaper model never escalates",
+			tenantID:  "acme",
+			requested: "claude-haiku",
+			expected:  []aws.FoundationModel{aws.ModelClaude4Haiku},
+		},
+		{
+			name:      "full model id accepted",
+			tenantID:  "acme",
+			requested: string(aws.ModelClaude4Haiku),
+			expected:  []aws.FoundationModel{aws.ModelClaude4Haiku},
+		},
+		{
+			name:     "tenant override filters default chain",
+			tenantID: "starter",
+			expected: []aws.FoundationModel{aws.ModelClaude4Haiku},
+		},
+		{
+			name:      "model outside tenant allowlist",
+			tenantID:  "starter",
+			requested: "claude-sonnet",
+			wantErr:   true,
+		},
+		{
+			name:      "model outside chain has no fallback",
+			tenantID:  "legacy",
+			requested: "claude-3-sonnet",
+			expected:  []aws.FoundationModel{aws.ModelClaude3Sonnet},
+		},
+		{
+			name:      "unknown model",
+			tenantID:  "acme",
+			requested: "gpt-4",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := policy.Resolve(tt.tenantID, tt.requested)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, models.ErrInvalidInput)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, chain)
+		})
+	}
+}
+
+func TestNewModelPolicy_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowed       string
+		tenantAllowed string
+		fallback      string
+	}{
+		{name: "unknown allowed model", allowed: "claude-opus", fallback: "claude-sonnet"},
+		{name: "empty fallback chain", allowed: "claude-sonnet", fallback: ""},
+		{name: "malformed tenant entry", allowed: "claude-sonnet", tenantAllowed: "acme", fallback: "claude-sonnet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewModelPolicy(tt.allowed, tt.tenantAllowed, tt.fallback)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/notify"
+)
+
+// digestPageSize is how many users the digest job loads at a time
+const digestPageSize = 100
+
+// digestSubjects are the digest email subjects, translated to the user's language
+var digestSubjects = map[models.DigestFrequency]string{
+	models.DigestDaily:  "Your daily digest",
+	models.DigestWeekly: "Your weekly digest",
+}
+
+// notificationService implements the NotificationService interface
+type notificationService struct {
+	notifications models.UserNotificationRepository
+	prefs         PreferencesService
+	prefsRepo     models.UserPreferencesRepository
+	users         models.UserRepository
+	brands        BrandService
+	mailer        notify.Mailer
+	clock         clock.Clock
+	logger        *logger.Logger
+}
+
+var _ NotificationService = (*notificationService)(nil)
+
+// NewNotificationService creates a new notification service delivering
+// emails with mailer, styled with the tenants' branding
+func NewNotificationService(notifications models.UserNotificationRepository, prefs PreferencesService, prefsRepo models.UserPreferencesRepository, users models.UserRepository, brands BrandService, mailer notify.Mailer, clock clock.Clock, logger *logger.Logger) NotificationService {
+	return &notificationService{
+		notifications: notifications,
+		prefs:         prefs,
+		prefsRepo:     prefsRepo,
+		users:         users,
+		brands:        brands,
+		mailer:        mailer,
+		clock:         clock,
+		logger:        logger,
+	}
+}
+
+// Notify delivers the notification on the user's channels, in the user's
+// language: it is listed in the dashboard, and emailed now or held for the
+// user's next digest
+func (s *notificationService) Notify(ctx context.Context, tenantID, userID string, subject, message i18n.Message) error {
+	prefs, err := s.prefs.Get(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	email := prefs.Wants(models.NotificationEmail)
+	digest := email && prefs.DigestFrequency != string(models.DigestNone)
+
+	if prefs.Wants(models.NotificationInApp) || digest {
+		notification := &models.UserNotification{
+			TenantID:      tenantID,
+			UserID:        userID,
+			Subject:       subject.In(prefs.Language),
+			Message:       message.In(prefs.Language),
+			InApp:         prefs.Wants(models.NotificationInApp),
+			PendingDigest: digest,
+			CreatedAt:     s.clock.Now(),
+		}
+		if err := s.notifications.Create(ctx, notification); err != nil {
+			return fmt.Errorf("failed to store notification: %w", err)
+		}
+	}
+	if email && !digest {
+		return s.email(ctx, tenantID, userID, notify.TemplateAlert, alertEmail(prefs.Language, subject.In(prefs.Language), message.In(prefs.Language)))
+	}
+	return nil
+}
+
+// ListInApp returns the user's dashboard notifications, newest first
+func (s *notificationService) ListInApp(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.UserNotification, error) {
+	notifications, err := s.notifications.ListInApp(ctx, tenantID, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+// SendDigests emails the digests due, each at the digest hour of the user's
+// timezone. A failing user is logged and skipped; its digest is retried on
+// the next run.
+func (s *notificationService) SendDigests(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+	sent := 0
+	for after := ""; ; {
+		page, err := s.prefsRepo.ListDigests(ctx, after, digestPageSize)
+		if err != nil {
+			return sent, fmt.Errorf("failed to list digest preferences: %w", err)
+		}
+		for _, prefs := range page {
+			if err := ctx.Err(); err != nil {
+				return sent, err
+			}
+			after = prefs.UserID
+			if !prefs.DigestDue(now) {
+				continue
+			}
+			delivered, err := s.sendDigest(ctx, prefs)
+			if err != nil {
+				s.logger.Error("Failed to send digest", "error", err, "tenant_id", prefs.TenantID, "user_id", prefs.UserID)
+				continue
+			}
+			prefs.LastDigestAt = &now
+			if err := s.prefsRepo.Upsert(ctx, prefs); err != nil {
+				s.logger.Error("Failed to record digest", "error", err, "tenant_id", prefs.TenantID, "user_id", prefs.UserID)
+			}
+			if delivered {
+				sent++
+			}
+		}
+		if len(page) < digestPageSize {
+			break
+		}
+	}
+	if sent > 0 {
+		s.logger.Info("Digests sent", "count", sent)
+	}
+	return sent, nil
+}
+
+// sendDigest emails the user's held notifications, reporting whether there
+// were any. Their times are written in the user's timezone.
+func (s *notificationService) sendDigest(ctx context.Context, prefs *models.UserPreferences) (bool, error) {
+	pending, err := s.notifications.ListPendingDigest(ctx, prefs.TenantID, prefs.UserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to list held notifications: %w", err)
+	}
+	if len(pending) == 0 {
+		return false, nil
+	}
+
+	ids := make([]string, 0, len(pending))
+	for _, n := range pending {
+		ids = append(ids, n.ID)
+	}
+	if err := s.email(ctx, prefs.TenantID, prefs.UserID, notify.TemplateDigest, digestEmail(prefs, pending)); err != nil {
+		return false, err
+	}
+	if err := s.notifications.MarkDigested(ctx, prefs.TenantID, ids); err != nil {
+		return true, fmt.Errorf("failed to mark notifications digested: %w", err)
+	}
+	return true, nil
+}
+
+// PreviewEmail renders sample notifications with the tenant's branding, in
+// the user's language and timezone
+func (s *notificationService) PreviewEmail(ctx context.Context, tenantID, userID, template string) (*notify.Email, error) {
+	prefs, err := s.prefs.Get(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	samples := previewNotifications(prefs.Language, s.clock.Now())
+
+	var data *notify.EmailData
+	switch template {
+	case notify.TemplateAlert:
+		data = alertEmail(prefs.Language, samples[0].Subject, samples[0].Message)
+	case notify.TemplateDigest:
+		data = digestEmail(prefs, samples)
+	default:
+		return nil, i18n.Errorf(models.ErrInvalidInput, "template must be one of %v", notify.Templates)
+	}
+	if data.Brand, err = s.brands.EmailBranding(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	return notify.Render(template, data)
+}
+
+// email renders the template with the tenant's branding and sends it to the
+// user's address, with the HTML part when the mailer sends HTML. Emails of a
+// tenant whose branding cannot be read go out with the default brand rather
+// than not at all.
+func (s *notificationService) email(ctx context.Context, tenantID, userID, template string, data *notify.EmailData) error {
+	user, err := s.users.GetByID(ctx, tenantID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	data.Brand, err = s.brands.EmailBranding(ctx, tenantID)
+	if err != nil {
+		s.logger.Warn("Failed to get email branding, using the default brand", "error", err, "tenant_id", tenantID)
+		data.Brand = notify.Branding{AccentColor: models.DefaultBrand.AccentColor, TextColor: models.DefaultBrand.TextColor}
+	}
+	email, err := notify.Render(template, data)
+	if err != nil {
+		return err
+	}
+
+	if mailer, ok := s.mailer.(notify.HTMLMailer); ok {
+		err = mailer.SendHTML(ctx, user.Email, email)
+	} else {
+		err = s.mailer.Send(ctx, user.Email, email.Subject, email.Text)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// alertEmail lays out a notification emailed as it happens
+func alertEmail(lang, subject, message string) *notify.EmailData {
+	return &notify.EmailData{
+		Language: lang,
+		Subject:  subject,
+		Items:    []notify.EmailItem{{Subject: subject, Message: message}},
+	}
+}
+
+// digestEmail lays out the held notifications, their times written in the
+// user's timezone
+func digestEmail(prefs *models.UserPreferences, pending []*models.UserNotification) *notify.EmailData {
+	subject, ok := digestSubjects[models.DigestFrequency(prefs.DigestFrequency)]
+	if !ok {
+		// Previews of users without digests
+		subject = digestSubjects[models.DigestDaily]
+	}
+	loc := prefs.Location()
+	data := &notify.EmailData{Language: prefs.Language, Subject: i18n.T(prefs.Language, subject)}
+	for _, n := range pending {
+		data.Items = append(data.Items, notify.EmailItem{Time: n.CreatedAt.In(loc).Format("Jan 2 15:04"), Subject: n.Subject, Message: n.Message})
+	}
+	return data
+}
+
+// previewNotifications are sample notifications of the last hours, newest
+// first, translated to lang
+func previewNotifications(lang string, now time.Time) []*models.UserNotification {
+	samples := []struct {
+		age              time.Duration
+		subject, message i18n.Message
+	}{
+		{20 * time.Minute, i18n.M("Alert: %s", "10K views"), i18n.M("%s is now %s for video %s on %s", "views", "10000", "Launch teaser", "youtube")},
+		{3 * time.Hour, i18n.M("Publication approved"), i18n.M("Your publication of video %s on %s was approved and is scheduled for %s",
+			"Launch teaser", "tiktok", now.Add(24*time.Hour).UTC().Format("2006-01-02 15:04 MST"))},
+		{7 * time.Hour, i18n.M("Video failed quality control"), i18n.M("Your video %s is held from publishing: %s", "Behind the scenes", models.QCSilentAudio)},
+	}
+	notifications := make([]*models.UserNotification, len(samples))
+	for i, sample := range samples {
+		notifications[i] = &models.UserNotification{
+			Subject:   sample.subject.In(lang),
+			Message:   sample.message.In(lang),
+			CreatedAt: now.Add(-sample.age),
+		}
+	}
+	return notifications
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/notify"
+)
+
+// memoryPreferencesRepo stores preferences by user ID
+type memoryPreferencesRepo struct {
+	prefs map[string]*models.UserPreferences
+}
+
+func (r *memoryPreferencesRepo) Get(ctx context.Context, tenantID, userID string) (*models.UserPreferences, error) {
+	if p, ok := r.prefs[userID]; ok && p.TenantID == tenantID {
+		copied := *p
+		return &copied, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *memoryPreferencesRepo) Upsert(ctx context.Context, prefs *models.UserPreferences) error {
+	copied := *prefs
+	r.prefs[prefs.UserID] = &copied
+	return nil
+}
+
+func (r *memoryPreferencesRepo) ListDigests(ctx context.Context, afterUserID string, limit int) ([]*models.UserPreferences, error) {
+	var page []*models.UserPreferences
+	for _, p := range r.prefs {
+		if p.DigestFrequency != string(models.DigestNone) && p.UserID > afterUserID {
+			copied := *p
+			page = append(page, &copied)
+		}
+	}
+	return page, nil
+}
+
+// memoryNotificationRepo keeps notifications in memory
+type memoryNotificationRepo struct {
+	notifications []*models.UserNotification
+}
+
+func (r *memoryNotificationRepo) Create(ctx context.Context, n *models.UserNotification) error {
+	n.ID = id.New()
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+func (r *memoryNotificationRepo) ListInApp(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.UserNotification, error) {
+	var list []*models.UserNotification
+	for _, n := range r.notifications {
+		if n.UserID == userID && n.InApp {
+			list = append(list, n)
+		}
+	}
+	return list, nil
+}
+
+func (r *memoryNotificationRepo) ListPendingDigest(ctx context.Context, tenantID, userID string) ([]*models.UserNotification, error) {
+	var list []*models.UserNotification
+	for _, n := range r.notifications {
+		if n.UserID == userID && n.PendingDigest {
+			list = append(list, n)
+		}
+	}
+	return list, nil
+}
+
+func (r *memoryNotificationRepo) MarkDigested(ctx context.Context, tenantID string, ids []string) error {
+	for _, n := range r.notifications {
+		for _, id := range ids {
+			if n.ID == id {
+				n.PendingDigest = false
+			}
+		}
+	}
+	return nil
+}
+
+// sentEmail is an email captured by recordingMailer
+type sentEmail struct {
+	to, subject, body, html string
+}
+
+type recordingMailer struct {
+	sent []sentEmail
+}
+
+func (m *recordingMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.sent = append(m.sent, sentEmail{to: to, subject: subject, body: body})
+	return nil
+}
+
+func (m *recordingMailer) SendHTML(ctx context.Context, to string, email *notify.Email) error {
+	m.sent = append(m.sent, sentEmail{to: to, subject: email.Subject, body: email.Text, html: email.HTML})
+	return nil
+}
+
+// newTestBrands brands the emails of acme
+func newTestBrands() BrandService {
+	profiles := &memoryBrandProfileRepo{profiles: map[string]*models.BrandProfile{
+		"acme": {TenantID: "acme", Font: "Impact", TextColor: "#FFD700", AccentColor: "#1A2B3C", LogoURL: "https://cdn.acme.test/logo.png", EmailFooter: "Acme Studios, Paris"},
+	}}
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{"acme": {ID: "acme", Name: "Acme Studios"}}}
+	return NewBrandService(profiles, tenants, logger.New("error", "test"))
+}
+
+func TestPreferencesService_Update(t *testing.T) {
+	repo := &memoryPreferencesRepo{prefs: map[string]*models.UserPreferences{}}
+	svc := NewPreferencesService(repo, logger.New("error", "test"))
+	ctx := context.Background()
+
+	prefs, err := svc.Get(ctx, "acme", "ana")
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", prefs.Timezone, "users start with the defaults")
+
+	str := func(s string) *string { return &s }
+	_, err = svc.Update(ctx, "acme", "ana", &models.UpdatePreferencesRequest{Language: str("xx")})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Update(ctx, "acme", "ana", &models.UpdatePreferencesRequest{Timezone: str("Mars/Olympus")})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Update(ctx, "acme", "ana", &models.UpdatePreferencesRequest{NotificationChannels: []string{"pager"}})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Update(ctx, "acme", "ana", &models.UpdatePreferencesRequest{DigestFrequency: str("hourly")})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	prefs, err = svc.Update(ctx, "acme", "ana", &models.UpdatePreferencesRequest{
+		Language:             str("fr"),
+		Timezone:             str("Europe/Paris"),
+		NotificationChannels: []string{"email", "email"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"email"}, prefs.NotificationChannels)
+	assert.Equal(t, string(models.DigestNone), prefs.DigestFrequency, "omitted fields are unchanged")
+	assert.Equal(t, "Europe/Paris", svc.Location(ctx, "acme", "ana").String())
+}
+
+func TestNotificationService(t *testing.T) {
+	// Monday 2026-10-12, 05:00 UTC: 07:00 in Paris, 08:00 in Istanbul
+	now := time.Date(2026, 10, 12, 5, 0, 0, 0, time.UTC)
+	log := logger.New("error", "test")
+	prefsRepo := &memoryPreferencesRepo{prefs: map[string]*models.UserPreferences{
+		"ana":  {UserID: "ana", TenantID: "acme", Language: "fr", Timezone: "Europe/Paris", NotificationChannels: []string{"email", "in_app"}, DigestFrequency: "daily"},
+		"bo":   {UserID: "bo", TenantID: "acme", Language: "en", Timezone: "Europe/Istanbul", NotificationChannels: []string{"email"}, DigestFrequency: "weekly"},
+		"cruz": {UserID: "cruz", TenantID: "acme", Timezone: "UTC", NotificationChannels: []string{}, DigestFrequency: "none"},
+	}}
+	users := &memoryUserRepo{users: []*models.User{
+		{ID: "ana", TenantID: "acme", Email: "ana@acme.test"},
+		{ID: "bo", TenantID: "acme", Email: "bo@acme.test"},
+		{ID: "dee", TenantID: "acme", Email: "dee@acme.test"},
+	}}
+	notifications := &memoryNotificationRepo{}
+	mailer := &recordingMailer{}
+	fake := clock.NewFake(now)
+	svc := NewNotificationService(notifications, NewPreferencesService(prefsRepo, log), prefsRepo, users, newTestBrands(), mailer, fake, log)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Notify(ctx, "acme", "dee", i18n.M("Transfer accepted"), i18n.M("Launch was accepted")))
+	require.Len(t, mailer.sent, 1, "users without preferences are emailed right away")
+	assert.Equal(t, "dee@acme.test", mailer.sent[0].to)
+	assert.Equal(t, "Launch was accepted\n\n--\nAcme Studios, Paris", mailer.sent[0].body)
+	assert.Contains(t, mailer.sent[0].html, `src="https://cdn.acme.test/logo.png"`, "emails carry the tenant's branding")
+	assert.Contains(t, mailer.sent[0].html, "background:#1A2B3C")
+
+	require.NoError(t, svc.Notify(ctx, "acme", "ana", i18n.M("Transfer accepted"), i18n.Message{}))
+	require.NoError(t, svc.Notify(ctx, "acme", "bo", i18n.M("Transfer declined"), i18n.Message{}))
+	require.NoError(t, svc.Notify(ctx, "acme", "cruz", i18n.M("Transfer declined"), i18n.Message{}))
+	assert.Len(t, mailer.sent, 1, "digest users wait for their digest")
+	inApp, err := svc.ListInApp(ctx, "acme", "ana", 20, 0)
+	require.NoError(t, err)
+	assert.Len(t, inApp, 1)
+
+	sent, err := svc.SendDigests(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent, "it is 08:00 on Monday in Istanbul but not yet in Paris")
+	assert.Equal(t, "bo@acme.test", mailer.sent[1].to)
+	assert.Equal(t, "Your weekly digest", mailer.sent[1].subject)
+	assert.Contains(t, mailer.sent[1].body, "Oct 12 08:00  Transfer declined", "times are in the user's timezone")
+
+	fake.Advance(time.Hour)
+	sent, err = svc.SendDigests(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, "Votre résumé du jour", mailer.sent[2].subject)
+
+	sent, err = svc.SendDigests(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, sent, "one digest per period")
+}
+
+func TestNotificationService_PreviewEmail(t *testing.T) {
+	now := time.Date(2026, 10, 12, 5, 0, 0, 0, time.UTC)
+	log := logger.New("error", "test")
+	prefsRepo := &memoryPreferencesRepo{prefs: map[string]*models.UserPreferences{
+		"ana": {UserID: "ana", TenantID: "acme", Language: "fr", Timezone: "Europe/Paris", NotificationChannels: []string{"email"}, DigestFrequency: "none"},
+	}}
+	notifications := &memoryNotificationRepo{}
+	mailer := &recordingMailer{}
+	svc := NewNotificationService(notifications, NewPreferencesService(prefsRepo, log), prefsRepo, &memoryUserRepo{}, newTestBrands(), mailer, clock.NewFake(now), log)
+	ctx := context.Background()
+
+	email, err := svc.PreviewEmail(ctx, "acme", "ana", notify.TemplateDigest)
+	require.NoError(t, err)
+	assert.Equal(t, "Votre résumé du jour", email.Subject, "users without digests preview the daily one")
+	assert.Contains(t, email.Text, "Oct 12 06:40  Alerte : 10K views", "in the user's language and timezone")
+	assert.Contains(t, email.HTML, `<html lang="fr">`)
+	assert.Contains(t, email.HTML, "Acme Studios, Paris")
+
+	email, err = svc.PreviewEmail(ctx, "acme", "ana", notify.TemplateAlert)
+	require.NoError(t, err)
+	assert.Equal(t, "Alerte : 10K views", email.Subject)
+	assert.Empty(t, mailer.sent, "previews are not sent")
+	assert.Empty(t, notifications.notifications)
+
+	_, err = svc.PreviewEmail(ctx, "acme", "ana", "newsletter")
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+}
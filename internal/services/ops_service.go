@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// opsService implements the OpsService interface
+type opsService struct {
+	publications models.PublicationJobRepository
+	stats        models.VideoStatsRepository
+	usage        models.AIUsageRepository
+	webhooks     WebhookService
+	quarantine   models.QuarantinedWebhookRepository
+	syncInterval time.Duration
+	logger       *logger.Logger
+}
+
+var _ OpsService = (*opsService)(nil)
+
+// NewOpsService creates a new ops service instance. syncInterval is how often
+// the stats sync job runs, from which stale tenants are told apart.
+func NewOpsService(publications models.PublicationJobRepository, stats models.VideoStatsRepository, usage models.AIUsageRepository, webhooks WebhookService, quarantine models.QuarantinedWebhookRepository, syncInterval time.Duration, logger *logger.Logger) OpsService {
+	return &opsService{
+		publications: publications,
+		stats:        stats,
+		usage:        usage,
+		webhooks:     webhooks,
+		quarantine:   quarantine,
+		syncInterval: syncInterval,
+		logger:       logger,
+	}
+}
+
+// FailedPublications counts the publication jobs that failed since the given time
+func (s *opsService) FailedPublications(ctx context.Context, since time.Time) ([]*models.PlatformCount, error) {
+	counts, err := s.publications.CountFailedByPlatform(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count failed publications: %w", err)
+	}
+	return counts, nil
+}
+
+// DeadLetters counts the failed publication jobs with no retries left
+func (s *opsService) DeadLetters(ctx context.Context) (*DeadLetterReport, error) {
+	counts, err := s.publications.CountExhaustedByPlatform(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count exhausted publications: %w", err)
+	}
+
+	report := &DeadLetterReport{Platforms: counts}
+	for _, c := range counts {
+		report.Depth += c.Count
+	}
+	return report, nil
+}
+
+// AISpend sums AI usage by tenant since the given time
+func (s *opsService) AISpend(ctx context.Context, since time.Time, limit int) ([]*models.TenantAISpend, error) {
+	spend, err := s.usage.SpendByTenant(ctx, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum AI spend: %w", err)
+	}
+	return spend, nil
+}
+
+// WebhookStats reports the webhook counters of this server instance
+func (s *opsService) WebhookStats(ctx context.Context) *WebhookStats {
+	return s.webhooks.Stats()
+}
+
+// QuarantinedWebhooks lists the webhooks that failed validation, newest first
+func (s *opsService) QuarantinedWebhooks(ctx context.Context, platform string, limit, offset int) ([]*models.QuarantinedWebhook, error) {
+	webhooks, err := s.quarantine.List(ctx, platform, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quarantined webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// QuarantinedWebhook returns a quarantined webhook with its body
+func (s *opsService) QuarantinedWebhook(ctx context.Context, id string) (*models.QuarantinedWebhook, error) {
+	return s.quarantine.GetByID(ctx, id)
+}
+
+// StatsSync reports how long ago each tenant's stats were synced
+func (s *opsService) StatsSync(ctx context.Context, limit int) (*StatsSyncReport, error) {
+	statuses, err := s.stats.GetSyncStatusByTenant(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stats sync status: %w", err)
+	}
+
+	now := time.Now()
+	report := &StatsSyncReport{
+		IntervalSeconds: int64(s.syncInterval / time.Second),
+		Tenants:         make([]*TenantStatsSync, 0, len(statuses)),
+	}
+	for _, status := range statuses {
+		age := now.Sub(status.LastSyncAt)
+		tenant := &TenantStatsSync{
+			TenantStatsSync: status,
+			AgeSeconds:      int64(age / time.Second),
+			Stale:           age > 2*s.syncInterval,
+		}
+		if tenant.Stale {
+			report.StaleTenants++
+		}
+		report.Tenants = append(report.Tenants, tenant)
+	}
+	return report, nil
+}
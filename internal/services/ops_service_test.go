@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// exhaustedPublicationRepo serves fixed counts of exhausted jobs
+type exhaustedPublicationRepo struct {
+	models.PublicationJobRepository
+	counts []*models.PlatformCount
+}
+
+func (r *exhaustedPublicationRepo) CountExhaustedByPlatform(ctx context.Context) ([]*models.PlatformCount, error) {
+	return r.counts, nil
+}
+
+// syncStatusRepo serves fixed stats sync statuses
+type syncStatusRepo struct {
+	models.VideoStatsRepository
+	statuses []*models.TenantStatsSync
+}
+
+func (r *syncStatusRepo) GetSyncStatusByTenant(ctx context.Context, limit int) ([]*models.TenantStatsSync, error) {
+	return r.statuses, nil
+}
+
+func TestOpsService_DeadLetters(t *testing.T) {
+	publications := &exhaustedPublicationRepo{counts: []*models.PlatformCount{
+		{Platform: "tiktok", Count: 5},
+		{Platform: "youtube", Count: 2},
+	}}
+	svc := NewOpsService(publications, nil, nil, nil, nil, time.Hour, logger.New("error", "test"))
+
+	report, err := svc.DeadLetters(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), report.Depth)
+	assert.Len(t, report.Platforms, 2)
+}
+
+func TestOpsService_StatsSync(t *testing.T) {
+	now := time.Now()
+	stats := &syncStatusRepo{statuses: []*models.TenantStatsSync{
+		{TenantID: "acme", Stats: 40, LastSyncAt: now.Add(-3 * time.Hour)},
+		{TenantID: "globex", Stats: 12, LastSyncAt: now.Add(-90 * time.Minute)},
+	}}
+	svc := NewOpsService(nil, stats, nil, nil, nil, time.Hour, logger.New("error", "test"))
+
+	report, err := svc.StatsSync(context.Background(), 50)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3600), report.IntervalSeconds)
+	assert.Equal(t, 1, report.StaleTenants)
+	require.Len(t, report.Tenants, 2)
+	assert.True(t, report.Tenants[0].Stale, "synced more than two intervals ago")
+	assert.InDelta(t, 3*3600, report.Tenants[0].AgeSeconds, 2)
+	assert.False(t, report.Tenants[1].Stale, "one missed run is not stale yet")
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// preferencesService implements the PreferencesService interface
+type preferencesService struct {
+	prefs  models.UserPreferencesRepository
+	logger *logger.Logger
+}
+
+var _ PreferencesService = (*preferencesService)(nil)
+
+// NewPreferencesService creates a new preferences service
+func NewPreferencesService(prefs models.UserPreferencesRepository, logger *logger.Logger) PreferencesService {
+	return &preferencesService{prefs: prefs, logger: logger}
+}
+
+// Get returns the user's preferences, the defaults when they were never set
+func (s *preferencesService) Get(ctx context.Context, tenantID, userID string) (*models.UserPreferences, error) {
+	prefs, err := s.prefs.Get(ctx, tenantID, userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return models.DefaultUserPreferences(tenantID, userID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// Update validates and saves the fields set in req
+func (s *preferencesService) Update(ctx context.Context, tenantID, userID string, req *models.UpdatePreferencesRequest) (*models.UserPreferences, error) {
+	prefs, err := s.Get(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Language != nil {
+		if !slices.Contains(models.UILanguages, *req.Language) {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "language must be one of %v", models.UILanguages)
+		}
+		prefs.Language = *req.Language
+	}
+	if req.Timezone != nil {
+		if *req.Timezone == "" {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "timezone is required")
+		}
+		if _, err := time.LoadLocation(*req.Timezone); err != nil {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "unknown timezone %q", *req.Timezone)
+		}
+		prefs.Timezone = *req.Timezone
+	}
+	if req.NotificationChannels != nil {
+		channels := []string{}
+		for _, channel := range req.NotificationChannels {
+			if !models.NotificationChannel(channel).Valid() {
+				return nil, i18n.Errorf(models.ErrInvalidInput, "unknown notification channel %q", channel)
+			}
+			if !slices.Contains(channels, channel) {
+				channels = append(channels, channel)
+			}
+		}
+		prefs.NotificationChannels = channels
+	}
+	if req.DigestFrequency != nil {
+		if !models.DigestFrequency(*req.DigestFrequency).Valid() {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "digest_frequency must be none, daily or weekly")
+		}
+		prefs.DigestFrequency = *req.DigestFrequency
+	}
+
+	if err := s.prefs.Upsert(ctx, prefs); err != nil {
+		return nil, fmt.Errorf("failed to save preferences: %w", err)
+	}
+	s.logger.Info("User preferences updated", "tenant_id", tenantID, "user_id", userID)
+	return prefs, nil
+}
+
+// Location returns the user's timezone. Failing to read the preferences
+// falls back to UTC, so analytics still answer.
+func (s *preferencesService) Location(ctx context.Context, tenantID, userID string) *time.Location {
+	prefs, err := s.Get(ctx, tenantID, userID)
+	if err != nil {
+		s.logger.Warn("Failed to get user timezone, using UTC", "error", err, "tenant_id", tenantID, "user_id", userID)
+		return time.UTC
+	}
+	return prefs.Location()
+}
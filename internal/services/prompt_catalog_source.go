@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+)
+
+var (
+	// ErrCatalogNotModified is returned by a PromptCatalogSource when the
+	// catalog still has the version the caller already loaded
+	ErrCatalogNotModified = errors.New("prompt catalog not modified")
+	// ErrCatalogNotFound is returned by a PromptCatalogSource for a channel
+	// that has no catalog of its own
+	ErrCatalogNotFound = errors.New("prompt catalog not found")
+)
+
+// PromptCatalogSource is where the prompt service reads the YAML catalog of
+// each channel from
+type PromptCatalogSource interface {
+	// Fetch returns the channel's catalog and its version, or
+	// ErrCatalogNotModified when its version is still version
+	Fetch(ctx context.Context, channel PromptChannel, version string) (data []byte, newVersion string, err error)
+	// Promote replaces the catalog of to with the one of from at once
+	Promote(ctx context.Context, from, to PromptChannel) error
+	// Location identifies the channel's catalog in logs and validation reports
+	Location(channel PromptChannel) string
+}
+
+// channelLocation is the production catalog's path or key for production,
+// with the channel before the extension otherwise: prompts/catalog.yaml is
+// prompts/catalog.staging.yaml for staging
+func channelLocation(production string, channel PromptChannel) string {
+	if channel == PromptChannelProduction {
+		return production
+	}
+	ext := filepath.Ext(production)
+	return strings.TrimSuffix(production, ext) + "." + string(channel) + ext
+}
+
+// filePromptCatalogSource reads the catalogs from disk, versioned by their
+// modification time and size
+type filePromptCatalogSource struct {
+	path string
+}
+
+// NewFilePromptCatalogSource creates a catalog source reading the production
+// catalog from the file at path, and the other channels from its siblings
+func NewFilePromptCatalogSource(path string) PromptCatalogSource {
+	return &filePromptCatalogSource{path: path}
+}
+
+func (s *filePromptCatalogSource) Fetch(ctx context.Context, channel PromptChannel, version string) ([]byte, string, error) {
+	path := s.Location(channel)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", ErrCatalogNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read catalog file: %w", err)
+	}
+	current := strconv.FormatInt(info.ModTime().UnixNano(), 10) + "-" + strconv.FormatInt(info.Size(), 10)
+	if current == version {
+		return nil, "", ErrCatalogNotModified
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read catalog file: %w", err)
+	}
+	return data, current, nil
+}
+
+// Promote writes a copy next to the target and renames it over the target,
+// so readers see the old catalog or the new one, never a partial file
+func (s *filePromptCatalogSource) Promote(ctx context.Context, from, to PromptChannel) error {
+	data, err := os.ReadFile(s.Location(from))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrCatalogNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read catalog file: %w", err)
+	}
+
+	target := s.Location(to)
+	tmp, err := os.CreateTemp(filepath.Dir(target), filepath.Base(target)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create catalog file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write catalog file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write catalog file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to replace catalog file: %w", err)
+	}
+	return nil
+}
+
+func (s *filePromptCatalogSource) Location(channel PromptChannel) string {
+	return channelLocation(s.path, channel)
+}
+
+// s3PromptCatalogSource reads the catalogs from S3 objects, versioned by
+// their ETag so an unchanged catalog is not downloaded again
+type s3PromptCatalogSource struct {
+	store  aws.ObjectStore
+	bucket string
+	key    string
+}
+
+// NewS3PromptCatalogSource creates a catalog source reading the production
+// catalog from the object key of bucket, and the other channels from its
+// siblings
+func NewS3PromptCatalogSource(store aws.ObjectStore, bucket, key string) PromptCatalogSource {
+	return &s3PromptCatalogSource{store: store, bucket: bucket, key: key}
+}
+
+func (s *s3PromptCatalogSource) Fetch(ctx context.Context, channel PromptChannel, version string) ([]byte, string, error) {
+	object, err := s.store.GetObject(ctx, s.bucket, channelLocation(s.key, channel), version)
+	switch {
+	case errors.Is(err, aws.ErrObjectNotModified):
+		return nil, "", ErrCatalogNotModified
+	case errors.Is(err, aws.ErrObjectNotFound):
+		return nil, "", ErrCatalogNotFound
+	case err != nil:
+		return nil, "", fmt.Errorf("failed to read catalog object: %w", err)
+	}
+	return object.Body, object.ETag, nil
+}
+
+// Promote copies the object server-side; S3 replaces the target atomically
+func (s *s3PromptCatalogSource) Promote(ctx context.Context, from, to PromptChannel) error {
+	err := s.store.Copy(ctx, s.bucket, channelLocation(s.key, from), s.bucket, channelLocation(s.key, to))
+	switch {
+	case errors.Is(err, aws.ErrObjectNotFound):
+		return ErrCatalogNotFound
+	case err != nil:
+		return fmt.Errorf("failed to copy catalog object: %w", err)
+	}
+	return nil
+}
+
+func (s *s3PromptCatalogSource) Location(channel PromptChannel) string {
+	return "s3://" + s.bucket + "/" + channelLocation(s.key, channel)
+}
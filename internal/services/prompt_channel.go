@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+)
+
+// PromptChannel is one of the catalogs prompts move through on their way to
+// production
+type PromptChannel string
+
+// Prompt channels, from the least to the most stable
+const (
+	PromptChannelDraft      PromptChannel = "draft"
+	PromptChannelStaging    PromptChannel = "staging"
+	PromptChannelProduction PromptChannel = "production"
+)
+
+// PromptChannels lists the channels in promotion order
+var PromptChannels = []PromptChannel{PromptChannelDraft, PromptChannelStaging, PromptChannelProduction}
+
+// ParsePromptChannel parses a channel name; an empty one is production
+func ParsePromptChannel(name string) (PromptChannel, error) {
+	if name == "" {
+		return PromptChannelProduction, nil
+	}
+	for _, channel := range PromptChannels {
+		if string(channel) == name {
+			return channel, nil
+		}
+	}
+	return "", i18n.Errorf(models.ErrInvalidInput, "prompt channel must be draft, staging or production")
+}
+
+// Next returns the channel a catalog is promoted to, false for production
+func (c PromptChannel) Next() (PromptChannel, bool) {
+	for i, channel := range PromptChannels[:len(PromptChannels)-1] {
+		if channel == c {
+			return PromptChannels[i+1], true
+		}
+	}
+	return "", false
+}
+
+type promptChannelKey struct{}
+
+// WithPromptChannel returns a context whose prompts are read from channel
+func WithPromptChannel(ctx context.Context, channel PromptChannel) context.Context {
+	return context.WithValue(ctx, promptChannelKey{}, channel)
+}
+
+// PromptChannelFrom returns the channel prompts are read from in ctx,
+// production unless WithPromptChannel set another
+func PromptChannelFrom(ctx context.Context) PromptChannel {
+	if channel, ok := ctx.Value(promptChannelKey{}).(PromptChannel); ok {
+		return channel
+	}
+	return PromptChannelProduction
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/tokenizer"
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog validation severities
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+)
+
+// LoadPromptCatalog reads and parses a YAML prompt catalog from disk
+func LoadPromptCatalog(path string) (*PromptCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog file: %w", err)
+	}
+	return ParsePromptCatalog(data)
+}
+
+// ParsePromptCatalog parses a YAML prompt catalog
+func ParsePromptCatalog(data []byte) (*PromptCatalog, error) {
+	var catalog PromptCatalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog YAML: %w", err)
+	}
+
+	for key, prompt := range catalog.Prompts {
+		if prompt == nil {
+			return nil, fmt.Errorf("prompt '%s' has no definition", key)
+		}
+		prompt.Key = key
+	}
+
+	return &catalog, nil
+}
+
+// LintCatalog validates every prompt of a catalog and collects all issues
+// instead of stopping at the first one. Token budgets are checked against the
+// default model unless a prompt sets a "model" or "token_budget" metadata entry.
+func LintCatalog(catalog *PromptCatalog, tok tokenizer.Tokenizer) *CatalogValidationReport {
+	report := &CatalogValidationReport{
+		PromptsCount: len(catalog.Prompts),
+		Issues:       []CatalogValidationIssue{},
+		ValidatedAt:  time.Now(),
+	}
+
+	if catalog.Version == "" {
+		report.add("", LintSeverityWarning, "catalog_version", "catalog version is not set")
+	}
+
+	keys := make([]string, 0, len(catalog.Prompts))
+	for key := range catalog.Prompts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		lintPrompt(report, catalog.Prompts[key], catalog.Partials, tok)
+	}
+
+	report.Valid = report.Errors == 0
+	return report
+}
+
+// lintPrompt appends the issues found in a single prompt to the report
+func lintPrompt(report *CatalogValidationReport, prompt *Prompt, partials map[string]string, tok tokenizer.Tokenizer) {
+	key := prompt.Key
+
+	// Required metadata
+	if prompt.Name == "" {
+		report.add(key, LintSeverityError, "required_metadata", "name is required")
+	}
+	if prompt.Description == "" {
+		report.add(key, LintSeverityError, "required_metadata", "description is required")
+	}
+	if prompt.Category == "" {
+		report.add(key, LintSeverityError, "required_metadata", "category is required")
+	}
+	if prompt.Version == "" {
+		report.add(key, LintSeverityWarning, "required_metadata", "version is not set")
+	}
+	if !strings.Contains(key, "/") {
+		report.add(key, LintSeverityError, "key_format", "key should be in format 'category/name'")
+	} else if prompt.Category != "" && !strings.HasPrefix(key, prompt.Category+"/") {
+		report.add(key, LintSeverityWarning, "key_format", fmt.Sprintf("key prefix does not match category '%s'", prompt.Category))
+	}
+	if strings.TrimSpace(prompt.Template) == "" {
+		report.add(key, LintSeverityError, "template_syntax", "template is empty")
+		return
+	}
+
+	// Variable definitions
+	declared := make([]string, 0, len(prompt.Variables))
+	validTypes := []string{"string", "integer", "float", "boolean", "array", "object"}
+	for _, variable := range prompt.Variables {
+		switch {
+		case variable.Name == "":
+			report.add(key, LintSeverityError, "variable_definition", "variable name is required")
+			continue
+		case contains(declared, variable.Name):
+			report.add(key, LintSeverityError, "variable_definition", fmt.Sprintf("variable '%s' is declared twice", variable.Name))
+		case !contains(validTypes, variable.Type):
+			report.add(key, LintSeverityError, "variable_definition", fmt.Sprintf("variable '%s' has invalid type '%s'", variable.Name, variable.Type))
+		}
+		if variable.Required && variable.Default != nil {
+			report.add(key, LintSeverityWarning, "variable_definition", fmt.Sprintf("required variable '%s' has a default that is never used", variable.Name))
+		}
+		declared = append(declared, variable.Name)
+	}
+
+	// Template syntax
+	tmpl, err := parsePromptTemplate(key, prompt.Template, partials)
+	if err != nil {
+		report.add(key, LintSeverityError, "template_syntax", err.Error())
+		return
+	}
+
+	// Missing and unused variables
+	used := extractTemplateVariables(tmpl)
+	for _, name := range used {
+		if !contains(declared, name) {
+			report.add(key, LintSeverityError, "missing_variable", fmt.Sprintf("template variable '%s' not defined in variables", name))
+		}
+	}
+	for _, name := range declared {
+		if !contains(used, name) {
+			report.add(key, LintSeverityWarning, "unused_variable", fmt.Sprintf("variable '%s' is defined but not used in template", name))
+		}
+	}
+
+	// Render with sample data to catch execution errors and measure size
+	sample := make(map[string]interface{})
+	for _, variable := range prompt.Variables {
+		if variable.Required {
+			sample[variable.Name] = getTestValue(variable.Type)
+		}
+	}
+	for _, name := range used {
+		if !contains(declared, name) {
+			sample[name] = "test_value"
+		}
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, buildTemplateData(prompt, sample)); err != nil {
+		report.add(key, LintSeverityError, "template_execution", err.Error())
+		return
+	}
+
+	// Token budget for the target model
+	model := aws.ModelClaude4Sonnet
+	if m, ok := prompt.Metadata["model"].(string); ok && m != "" {
+		model = aws.FoundationModel(m)
+	}
+	modelConfig := aws.GetModelConfig(model)
+
+	tokens := tok.Count(buf.String())
+	if budget := modelConfig.ContextWindow - modelConfig.MaxTokens; tokens > budget {
+		report.add(key, LintSeverityError, "token_budget",
+			fmt.Sprintf("rendered prompt uses %d tokens, exceeding the %d token input budget of %s", tokens, budget, modelConfig.ModelID))
+	}
+	if budget, ok := prompt.Metadata["token_budget"].(int); ok && tokens > budget {
+		report.add(key, LintSeverityWarning, "token_budget",
+			fmt.Sprintf("rendered prompt uses %d tokens, exceeding its token_budget of %d", tokens, budget))
+	}
+}
+
+// add records an issue and updates the report counters
+func (r *CatalogValidationReport) add(promptKey, severity, rule, message string) {
+	r.Issues = append(r.Issues, CatalogValidationIssue{
+		PromptKey: promptKey,
+		Severity:  severity,
+		Rule:      rule,
+		Message:   message,
+	})
+	if severity == LintSeverityError {
+		r.Errors++
+	} else {
+		r.Warnings++
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/jibe0123/mysteryfactory/pkg/tokenizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintCatalog(t *testing.T) {
+	tests := []struct {
+		name          string
+		prompt        *Prompt
+		expectValid   bool
+		expectedRules []string
+	}{
+		{
+			name: "Valid prompt",
+			prompt: &Prompt{
+				Key: "magic_brush/test", Name: "Test", Description: "Test prompt", Category: "magic_brush", Version: "1.0",
+				Template:  "Topic: {{topic}}",
+				Variables: []PromptVariable{{Name: "topic", Type: "string", Required: true}},
+			},
+			expectValid: true,
+		},
+		{
+			name: "Missing metadata and undefined variable",
+			prompt: &Prompt{
+				Key:      "magic_brush/test",
+				Template: "Topic: {{topic}}",
+			},
+			expectValid:   false,
+			expectedRules: []string{"required_metadata", "missing_variable"},
+		},
+		{
+			name: "Unused variable",
+			prompt: &Prompt{
+				Key: "magic_brush/test", Name: "Test", Description: "Test prompt", Category: "magic_brush", Version: "1.0",
+				Template:  "Static prompt",
+				Variables: []PromptVariable{{Name: "topic", Type: "string"}},
+			},
+			expectValid:   true,
+			expectedRules: []string{"unused_variable"},
+		},
+		{
+			name: "Broken template syntax",
+			prompt: &Prompt{
+				Key: "magic_brush/test", Name: "Test", Description: "Test prompt", Category: "magic_brush", Version: "1.0",
+				Template: "Topic: {{if .topic}}",
+			},
+			expectValid:   false,
+			expectedRules: []string{"template_syntax"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := &PromptCatalog{
+				Version: "1.0",
+				Prompts: map[string]*Prompt{tt.prompt.Key: tt.prompt},
+			}
+
+			report := LintCatalog(catalog, tokenizer.NewBPE())
+			assert.Equal(t, tt.expectValid, report.Valid)
+
+			rules := make([]string, 0, len(report.Issues))
+			for _, issue := range report.Issues {
+				rules = append(rules, issue.Rule)
+			}
+			for _, rule := range tt.expectedRules {
+				assert.Contains(t, rules, rule)
+			}
+		})
+	}
+}
@@ -2,22 +2,41 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
-	"text/template"
+	"sync"
 	"time"
 
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
-	"gopkg.in/yaml.v3"
+	"github.com/jibe0123/mysteryfactory/pkg/tokenizer"
 )
 
-// promptService implements the PromptService interface
+// promptService implements the PromptService interface. Each channel's
+// catalog is replaced as a whole, under mu, when it is reloaded or changed,
+// so a request never sees half of two catalogs.
 type promptService struct {
-	prompts     map[string]*Prompt
-	catalogPath string
-	logger      *logger.Logger
-	lastLoaded  time.Time
+	mu           sync.RWMutex
+	catalogs     map[PromptChannel]*loadedCatalog // Channels with a catalog of their own
+	source       PromptCatalogSource
+	pollInterval time.Duration
+	tokenizer    tokenizer.Tokenizer
+	logger       *logger.Logger
+}
+
+var _ PromptService = (*promptService)(nil)
+
+// loadedCatalog is the catalog of one channel. It is never changed once
+// published in promptService.catalogs.
+type loadedCatalog struct {
+	prompts  map[string]*Prompt
+	partials map[string]string
+	version  string // As reported by the source
+	loadedAt time.Time
 }
 
 // PromptCatalog represents the structure of the YAML prompt catalog
@@ -25,35 +44,40 @@ type PromptCatalog struct {
 	Version     string             `yaml:"version"`
 	Description string             `yaml:"description"`
 	UpdatedAt   string             `yaml:"updated_at"`
+	Partials    map[string]string  `yaml:"partials"`
 	Prompts     map[string]*Prompt `yaml:"prompts"`
 }
 
-// NewPromptService creates a new prompt service instance
-func NewPromptService(catalogPath string, logger *logger.Logger) (PromptService, error) {
+// NewPromptService creates a new prompt service instance, loading the
+// catalog of each channel from source. Only the production catalog is
+// required; Run checks the source for new catalogs every pollInterval.
+func NewPromptService(source PromptCatalogSource, pollInterval time.Duration, logger *logger.Logger) (PromptService, error) {
 	service := &promptService{
-		prompts:     make(map[string]*Prompt),
-		catalogPath: catalogPath,
-		logger:      logger,
+		catalogs:     make(map[PromptChannel]*loadedCatalog),
+		source:       source,
+		pollInterval: pollInterval,
+		tokenizer:    tokenizer.NewBPE(),
+		logger:       logger,
 	}
 
 	// Load prompts from catalog
-	if err := service.loadCatalog(); err != nil {
+	if err := service.reload(context.Background(), PromptChannelProduction); err != nil {
 		return nil, fmt.Errorf("failed to load prompt catalog: %w", err)
 	}
+	for _, channel := range PromptChannels[:len(PromptChannels)-1] {
+		if err := service.reload(context.Background(), channel); err != nil {
+			logger.Warn("Failed to load prompt catalog", "error", err, "channel", channel)
+		}
+	}
 
 	return service, nil
 }
 
-// GetPrompt retrieves a prompt by key
+// GetPrompt retrieves a prompt by key from the context's channel
 func (s *promptService) GetPrompt(ctx context.Context, key string) (*Prompt, error) {
 	s.logger.Debug("Getting prompt", "key", key)
 
-	// Check if catalog needs reloading (for development)
-	if err := s.reloadIfNeeded(); err != nil {
-		s.logger.Warn("Failed to reload catalog", "error", err)
-	}
-
-	prompt, exists := s.prompts[key]
+	prompt, exists := s.catalog(ctx).prompts[key]
 	if !exists {
 		s.logger.Error("Prompt not found", "key", key)
 		return nil, fmt.Errorf("prompt not found: %s", key)
@@ -67,13 +91,9 @@ func (s *promptService) GetPrompt(ctx context.Context, key string) (*Prompt, err
 func (s *promptService) ListPrompts(ctx context.Context) ([]*Prompt, error) {
 	s.logger.Debug("Listing all prompts")
 
-	// Check if catalog needs reloading
-	if err := s.reloadIfNeeded(); err != nil {
-		s.logger.Warn("Failed to reload catalog", "error", err)
-	}
-
-	prompts := make([]*Prompt, 0, len(s.prompts))
-	for _, prompt := range s.prompts {
+	catalog := s.catalog(ctx)
+	prompts := make([]*Prompt, 0, len(catalog.prompts))
+	for _, prompt := range catalog.prompts {
 		prompts = append(prompts, prompt)
 	}
 
@@ -85,13 +105,8 @@ func (s *promptService) ListPrompts(ctx context.Context) ([]*Prompt, error) {
 func (s *promptService) GetPromptsByCategory(ctx context.Context, category string) ([]*Prompt, error) {
 	s.logger.Debug("Getting prompts by category", "category", category)
 
-	// Check if catalog needs reloading
-	if err := s.reloadIfNeeded(); err != nil {
-		s.logger.Warn("Failed to reload catalog", "error", err)
-	}
-
 	var prompts []*Prompt
-	for _, prompt := range s.prompts {
+	for _, prompt := range s.catalog(ctx).prompts {
 		if prompt.Category == category {
 			prompts = append(prompts, prompt)
 		}
@@ -101,7 +116,8 @@ func (s *promptService) GetPromptsByCategory(ctx context.Context, category strin
 	return prompts, nil
 }
 
-// CreatePrompt creates a new prompt (runtime creation)
+// CreatePrompt creates a new prompt (runtime creation) in the context's
+// channel
 func (s *promptService) CreatePrompt(ctx context.Context, req *CreatePromptRequest) (*Prompt, error) {
 	s.logger.Info("Creating prompt", "key", req.Key, "name", req.Name)
 
@@ -110,11 +126,6 @@ func (s *promptService) CreatePrompt(ctx context.Context, req *CreatePromptReque
 		return nil, fmt.Errorf("invalid prompt request: %w", err)
 	}
 
-	// Check if prompt already exists
-	if _, exists := s.prompts[req.Key]; exists {
-		return nil, fmt.Errorf("prompt already exists: %s", req.Key)
-	}
-
 	// Create prompt
 	prompt := &Prompt{
 		Key:         req.Key,
@@ -135,21 +146,32 @@ func (s *promptService) CreatePrompt(ctx context.Context, req *CreatePromptReque
 	}
 
 	// Store in memory (in production, this would also persist to storage)
-	s.prompts[req.Key] = prompt
+	err := s.edit(ctx, func(prompts map[string]*Prompt) error {
+		if _, exists := prompts[req.Key]; exists {
+			return fmt.Errorf("prompt already exists: %s", req.Key)
+		}
+		prompts[req.Key] = prompt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	s.logger.Info("Prompt created successfully", "key", req.Key, "name", req.Name)
 	return prompt, nil
 }
 
-// UpdatePrompt updates an existing prompt
+// UpdatePrompt updates an existing prompt of the context's channel
 func (s *promptService) UpdatePrompt(ctx context.Context, key string, req *UpdatePromptRequest) (*Prompt, error) {
 	s.logger.Info("Updating prompt", "key", key)
 
-	// Get existing prompt
-	prompt, exists := s.prompts[key]
-	if !exists {
-		return nil, fmt.Errorf("prompt not found: %s", key)
+	// Get existing prompt; the update is made on a copy, as requests may be
+	// reading it
+	existing, err := s.GetPrompt(ctx, key)
+	if err != nil {
+		return nil, err
 	}
+	prompt := *existing
 
 	// Update fields
 	if req.Name != nil {
@@ -173,24 +195,40 @@ func (s *promptService) UpdatePrompt(ctx context.Context, key string, req *Updat
 	prompt.UpdatedAt = time.Now()
 
 	// Validate the updated template
-	if err := s.ValidatePrompt(ctx, prompt); err != nil {
+	if err := s.ValidatePrompt(ctx, &prompt); err != nil {
 		return nil, fmt.Errorf("prompt validation failed: %w", err)
 	}
 
+	err = s.edit(ctx, func(prompts map[string]*Prompt) error {
+		if _, exists := prompts[key]; !exists {
+			return fmt.Errorf("prompt not found: %s", key)
+		}
+		prompts[key] = &prompt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	s.logger.Info("Prompt updated successfully", "key", key)
-	return prompt, nil
+	return &prompt, nil
 }
 
-// DeletePrompt deletes a prompt
+// DeletePrompt deletes a prompt of the context's channel
 func (s *promptService) DeletePrompt(ctx context.Context, key string) error {
 	s.logger.Info("Deleting prompt", "key", key)
 
-	if _, exists := s.prompts[key]; !exists {
-		return fmt.Errorf("prompt not found: %s", key)
+	err := s.edit(ctx, func(prompts map[string]*Prompt) error {
+		if _, exists := prompts[key]; !exists {
+			return fmt.Errorf("prompt not found: %s", key)
+		}
+		delete(prompts, key)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	delete(s.prompts, key)
-
 	s.logger.Info("Prompt deleted successfully", "key", key)
 	return nil
 }
@@ -200,13 +238,13 @@ func (s *promptService) ValidatePrompt(ctx context.Context, prompt *Prompt) erro
 	s.logger.Debug("Validating prompt", "key", prompt.Key)
 
 	// Validate template syntax
-	tmpl, err := template.New(prompt.Key).Parse(prompt.Template)
+	tmpl, err := parsePromptTemplate(prompt.Key, prompt.Template, s.catalogPartials(ctx))
 	if err != nil {
 		return fmt.Errorf("invalid template syntax: %w", err)
 	}
 
 	// Extract template variables
-	templateVars := extractTemplateVariables(prompt.Template)
+	templateVars := extractTemplateVariables(tmpl)
 
 	// Check if all template variables have corresponding variable definitions
 	for _, templateVar := range templateVars {
@@ -248,7 +286,7 @@ func (s *promptService) ValidatePrompt(ctx context.Context, prompt *Prompt) erro
 	}
 
 	var buf strings.Builder
-	if err := tmpl.Execute(&buf, testData); err != nil {
+	if err := tmpl.Execute(&buf, buildTemplateData(prompt, testData)); err != nil {
 		return fmt.Errorf("template execution failed: %w", err)
 	}
 
@@ -256,9 +294,69 @@ func (s *promptService) ValidatePrompt(ctx context.Context, prompt *Prompt) erro
 	return nil
 }
 
-// TestPrompt tests a prompt with provided data
-func (s *promptService) TestPrompt(ctx context.Context, key string, testData map[string]interface{}) (*PromptTestResult, error) {
-	s.logger.Debug("Testing prompt", "key", key)
+// ValidateCatalog lints the catalog of the context's channel currently in
+// the source and reports every issue found
+func (s *promptService) ValidateCatalog(ctx context.Context) (*CatalogValidationReport, error) {
+	return s.lintSource(ctx, PromptChannelFrom(ctx)), nil
+}
+
+// PromoteCatalog lints the catalog of from and, when it has no errors,
+// makes it the catalog of the next channel. The promoted catalog serves on
+// this replica at once, on the others at their next check.
+func (s *promptService) PromoteCatalog(ctx context.Context, from PromptChannel) (*PromptPromotion, error) {
+	to, ok := from.Next()
+	if !ok {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the production catalog cannot be promoted")
+	}
+
+	promotion := &PromptPromotion{From: from, To: to, Report: s.lintSource(ctx, from)}
+	if !promotion.Report.Valid {
+		return promotion, models.ErrPromptCatalogInvalid
+	}
+	if err := s.source.Promote(ctx, from, to); err != nil {
+		return nil, err
+	}
+	if err := s.reload(ctx, to); err != nil {
+		s.logger.Warn("Failed to load promoted prompt catalog", "error", err, "channel", to)
+	}
+
+	promotion.PromotedAt = time.Now()
+	s.logger.Info("Prompt catalog promoted", "from", from, "to", to, "prompts_count", promotion.Report.PromptsCount)
+	return promotion, nil
+}
+
+// lintSource lints the channel's catalog as it is in the source, reporting
+// a catalog that cannot be read or parsed as a syntax error
+func (s *promptService) lintSource(ctx context.Context, channel PromptChannel) *CatalogValidationReport {
+	location := s.source.Location(channel)
+	s.logger.Info("Validating prompt catalog", "path", location)
+
+	data, _, err := s.source.Fetch(ctx, channel, "")
+	var catalog *PromptCatalog
+	if err == nil {
+		catalog, err = ParsePromptCatalog(data)
+	}
+	if err != nil {
+		report := &CatalogValidationReport{
+			CatalogPath: location,
+			Issues:      []CatalogValidationIssue{},
+			ValidatedAt: time.Now(),
+		}
+		report.add("", LintSeverityError, "catalog_syntax", err.Error())
+		return report
+	}
+
+	report := LintCatalog(catalog, s.tokenizer)
+	report.CatalogPath = location
+
+	s.logger.Info("Prompt catalog validated", "path", location, "valid", report.Valid, "errors", report.Errors, "warnings", report.Warnings)
+	return report
+}
+
+// TestPrompt tests a prompt with provided data, estimating its cost on the
+// given model
+func (s *promptService) TestPrompt(ctx context.Context, key string, model aws.FoundationModel, testData map[string]interface{}) (*PromptTestResult, error) {
+	s.logger.Debug("Testing prompt", "key", key, "model", model)
 
 	startTime := time.Now()
 
@@ -284,7 +382,7 @@ func (s *promptService) TestPrompt(ctx context.Context, key string, testData map
 	}
 
 	// Execute template
-	tmpl, err := template.New(key).Parse(prompt.Template)
+	tmpl, err := parsePromptTemplate(key, prompt.Template, s.catalogPartials(ctx))
 	if err != nil {
 		return &PromptTestResult{
 			Success:  false,
@@ -295,7 +393,7 @@ func (s *promptService) TestPrompt(ctx context.Context, key string, testData map
 	}
 
 	var buf strings.Builder
-	if err := tmpl.Execute(&buf, testData); err != nil {
+	if err := tmpl.Execute(&buf, buildTemplateData(prompt, testData)); err != nil {
 		return &PromptTestResult{
 			Success:  false,
 			Error:    fmt.Sprintf("template execution error: %v", err),
@@ -307,8 +405,9 @@ func (s *promptService) TestPrompt(ctx context.Context, key string, testData map
 	result := buf.String()
 	duration := time.Since(startTime)
 
-	// Calculate estimated token usage (rough approximation)
-	tokenCount := len(strings.Fields(result))
+	// Estimate input token usage and cost for the selected model
+	tokenCount := s.tokenizer.Count(result)
+	modelConfig := aws.GetModelConfig(model)
 
 	s.logger.Debug("Prompt test completed", "key", key, "duration", duration, "tokens", tokenCount)
 
@@ -317,10 +416,12 @@ func (s *promptService) TestPrompt(ctx context.Context, key string, testData map
 		Result:     result,
 		Duration:   duration,
 		TokensUsed: tokenCount,
-		Cost:       float64(tokenCount) * 0.0001, // Mock cost calculation
+		Cost:       modelConfig.EstimateCost(tokenCount, 0),
 		Metadata: map[string]interface{}{
 			"template_variables": len(prompt.Variables),
 			"result_length":      len(result),
+			"model":              modelConfig.ModelID,
+			"context_window":     modelConfig.ContextWindow,
 		},
 		TestedAt: time.Now(),
 	}, nil
@@ -342,13 +443,13 @@ func (s *promptService) RenderPrompt(ctx context.Context, key string, data map[s
 	}
 
 	// Parse and execute template
-	tmpl, err := template.New(key).Parse(prompt.Template)
+	tmpl, err := parsePromptTemplate(key, prompt.Template, s.catalogPartials(ctx))
 	if err != nil {
 		return "", fmt.Errorf("template parse error: %w", err)
 	}
 
 	var buf strings.Builder
-	if err := tmpl.Execute(&buf, data); err != nil {
+	if err := tmpl.Execute(&buf, buildTemplateData(prompt, data)); err != nil {
 		return "", fmt.Errorf("template execution error: %w", err)
 	}
 
@@ -357,48 +458,133 @@ func (s *promptService) RenderPrompt(ctx context.Context, key string, data map[s
 
 // Private methods
 
-// loadCatalog loads prompts from the YAML catalog file
-func (s *promptService) loadCatalog() error {
-	s.logger.Info("Loading prompt catalog", "path", s.catalogPath)
+// reload loads the channel's catalog from the source when its version
+// changed. A catalog that does not parse or fails the lint is rejected,
+// keeping the one loaded; otherwise it replaces it at once, dropping prompts
+// created at runtime. A channel whose catalog was removed falls back to the
+// next one, except production which keeps its last catalog.
+func (s *promptService) reload(ctx context.Context, channel PromptChannel) error {
+	s.mu.RLock()
+	var version string
+	if current := s.catalogs[channel]; current != nil {
+		version = current.version
+	}
+	s.mu.RUnlock()
+
+	data, newVersion, err := s.source.Fetch(ctx, channel, version)
+	switch {
+	case errors.Is(err, ErrCatalogNotModified):
+		return nil
+	case errors.Is(err, ErrCatalogNotFound) && channel != PromptChannelProduction:
+		s.mu.Lock()
+		delete(s.catalogs, channel)
+		s.mu.Unlock()
+		return nil
+	case err != nil:
+		return err
+	}
+	location := s.source.Location(channel)
+	s.logger.Info("Loading prompt catalog", "path", location, "channel", channel, "version", newVersion)
 
-	// Read catalog file
-	data, err := os.ReadFile(s.catalogPath)
+	catalog, err := ParsePromptCatalog(data)
 	if err != nil {
-		return fmt.Errorf("failed to read catalog file: %w", err)
+		return err
 	}
-
-	// Parse YAML
-	var catalog PromptCatalog
-	if err := yaml.Unmarshal(data, &catalog); err != nil {
-		return fmt.Errorf("failed to parse catalog YAML: %w", err)
+	if report := LintCatalog(catalog, s.tokenizer); !report.Valid {
+		for _, issue := range report.Issues {
+			if issue.Severity == LintSeverityError {
+				s.logger.Error("Prompt catalog error", "path", location, "prompt", issue.PromptKey, "rule", issue.Rule, "message", issue.Message)
+			}
+		}
+		return fmt.Errorf("prompt catalog %s has %d errors", location, report.Errors)
 	}
 
-	// Load prompts
-	s.prompts = make(map[string]*Prompt)
+	loaded := &loadedCatalog{
+		prompts:  make(map[string]*Prompt, len(catalog.Prompts)),
+		partials: make(map[string]string, len(catalog.Partials)),
+		version:  newVersion,
+		loadedAt: time.Now(),
+	}
 	for key, prompt := range catalog.Prompts {
-		prompt.Key = key // Ensure key is set
-		s.prompts[key] = prompt
+		loaded.prompts[key] = prompt
+	}
+	for name, partial := range catalog.Partials {
+		loaded.partials[name] = partial
 	}
 
-	s.lastLoaded = time.Now()
-	s.logger.Info("Prompt catalog loaded successfully", "prompts_count", len(s.prompts), "version", catalog.Version)
+	s.mu.Lock()
+	s.catalogs[channel] = loaded
+	s.mu.Unlock()
 
+	s.logger.Info("Prompt catalog loaded successfully", "channel", channel, "prompts_count", len(loaded.prompts), "partials_count", len(loaded.partials), "version", catalog.Version)
 	return nil
 }
 
-// reloadIfNeeded reloads the catalog if the file has been modified
-func (s *promptService) reloadIfNeeded() error {
-	// Check file modification time
-	info, err := os.Stat(s.catalogPath)
-	if err != nil {
-		return err
+// Run checks the source for new catalogs every poll interval until ctx is
+// cancelled. A catalog that cannot be loaded is logged and retried at the
+// next check, while the current one keeps serving.
+func (s *promptService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, channel := range PromptChannels {
+				if err := s.reload(ctx, channel); err != nil {
+					s.logger.Warn("Failed to reload prompt catalog", "error", err, "channel", channel)
+				}
+			}
+		}
 	}
+}
+
+// catalog returns the catalog serving the context's channel: its own, or
+// that of the next channel towards production
+func (s *promptService) catalog(ctx context.Context) *loadedCatalog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	if info.ModTime().After(s.lastLoaded) {
-		s.logger.Info("Catalog file modified, reloading", "path", s.catalogPath)
-		return s.loadCatalog()
+	channel := PromptChannelFrom(ctx)
+	for {
+		if catalog := s.catalogs[channel]; catalog != nil {
+			return catalog
+		}
+		next, ok := channel.Next()
+		if !ok {
+			return s.catalogs[PromptChannelProduction]
+		}
+		channel = next
 	}
+}
+
+// catalogPartials returns the partials of the catalog serving the context's
+// channel
+func (s *promptService) catalogPartials(ctx context.Context) map[string]string {
+	return s.catalog(ctx).partials
+}
 
+// edit replaces the catalog of the context's channel with a copy changed by
+// change. A channel serving another channel's catalog cannot be edited.
+func (s *promptService) edit(ctx context.Context, change func(prompts map[string]*Prompt) error) error {
+	channel := PromptChannelFrom(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := s.catalogs[channel]
+	if current == nil {
+		return fmt.Errorf("prompt channel %s has no catalog of its own", channel)
+	}
+	prompts := make(map[string]*Prompt, len(current.prompts))
+	for key, prompt := range current.prompts {
+		prompts[key] = prompt
+	}
+	if err := change(prompts); err != nil {
+		return err
+	}
+	s.catalogs[channel] = &loadedCatalog{prompts: prompts, partials: current.partials, version: current.version, loadedAt: current.loadedAt}
 	return nil
 }
 
@@ -517,24 +703,6 @@ func (s *promptService) validateDataType(value interface{}, expectedType string)
 
 // Helper functions
 
-// extractTemplateVariables extracts variable names from a template string
-func extractTemplateVariables(templateStr string) []string {
-	var variables []string
-
-	// Simple regex-like extraction for {{variable}} patterns
-	parts := strings.Split(templateStr, "{{")
-	for i := 1; i < len(parts); i++ {
-		if closingIndex := strings.Index(parts[i], "}}"); closingIndex != -1 {
-			variable := strings.TrimSpace(parts[i][:closingIndex])
-			if variable != "" && !contains(variables, variable) {
-				variables = append(variables, variable)
-			}
-		}
-	}
-
-	return variables
-}
-
 // contains checks if a slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
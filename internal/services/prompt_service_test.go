@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryObjectStore keeps objects by key, each version getting a new ETag
+type memoryObjectStore struct {
+	objects map[string]*aws.S3Object
+	reads   int
+	puts    int
+}
+
+func (s *memoryObjectStore) put(key string, body []byte) {
+	s.puts++
+	s.objects[key] = &aws.S3Object{Body: body, ETag: `"v` + strconv.Itoa(s.puts) + `"`}
+}
+
+func (s *memoryObjectStore) GetObject(ctx context.Context, bucket, key, etag string) (*aws.S3Object, error) {
+	object, ok := s.objects[key]
+	switch {
+	case !ok:
+		return nil, aws.ErrObjectNotFound
+	case etag == object.ETag:
+		return nil, aws.ErrObjectNotModified
+	}
+	s.reads++
+	return object, nil
+}
+
+func (s *memoryObjectStore) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	object, ok := s.objects[srcKey]
+	if !ok {
+		return aws.ErrObjectNotFound
+	}
+	s.put(dstKey, object.Body)
+	return nil
+}
+
+func testCatalog(name string) []byte {
+	return []byte(`version: "1.0"
+prompts:
+  test/greeting:
+    name: "` + name + `"
+    description: "Greets"
+    category: "test"
+    template: "Hello {{name}}"
+    variables:
+      - name: "name"
+        type: "string"
+        required: true
+`)
+}
+
+func newTestS3PromptService(t *testing.T) (*promptService, *memoryObjectStore) {
+	t.Helper()
+	store := &memoryObjectStore{objects: map[string]*aws.S3Object{}}
+	store.put("prompts/catalog.yaml", testCatalog("Greeting"))
+	svc, err := NewPromptService(NewS3PromptCatalogSource(store, "config", "prompts/catalog.yaml"), time.Minute, logger.New("error", "test"))
+	require.NoError(t, err)
+	return svc.(*promptService), store
+}
+
+func promptName(t *testing.T, svc PromptService, ctx context.Context) string {
+	t.Helper()
+	prompt, err := svc.GetPrompt(ctx, "test/greeting")
+	require.NoError(t, err)
+	return prompt.Name
+}
+
+func TestPromptService_ReloadsFromS3(t *testing.T) {
+	svc, store := newTestS3PromptService(t)
+	ctx := context.Background()
+
+	// An unchanged catalog is not downloaded again
+	require.NoError(t, svc.reload(ctx, PromptChannelProduction))
+	assert.Equal(t, 1, store.reads)
+
+	store.put("prompts/catalog.yaml", testCatalog("Warm greeting"))
+	require.NoError(t, svc.reload(ctx, PromptChannelProduction))
+	assert.Equal(t, "Warm greeting", promptName(t, svc, ctx))
+
+	// A catalog failing the lint is rejected and the loaded one kept
+	store.put("prompts/catalog.yaml", testCatalog(""))
+	assert.Error(t, svc.reload(ctx, PromptChannelProduction))
+	assert.Equal(t, "Warm greeting", promptName(t, svc, ctx))
+
+	report, err := svc.ValidateCatalog(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "s3://config/prompts/catalog.yaml", report.CatalogPath)
+	assert.False(t, report.Valid)
+}
+
+func TestPromptService_Channels(t *testing.T) {
+	svc, store := newTestS3PromptService(t)
+	production := context.Background()
+	staging := WithPromptChannel(production, PromptChannelStaging)
+	draft := WithPromptChannel(production, PromptChannelDraft)
+
+	// Channels without a catalog serve the next one's
+	assert.Equal(t, "Greeting", promptName(t, svc, draft))
+	_, err := svc.CreatePrompt(staging, &CreatePromptRequest{Key: "test/bye", Name: "Bye", Description: "Says bye", Category: "test", Template: "Bye"})
+	assert.Error(t, err, "staging has no catalog of its own to edit")
+
+	store.put("prompts/catalog.staging.yaml", testCatalog("Staged greeting"))
+	require.NoError(t, svc.reload(production, PromptChannelStaging))
+	assert.Equal(t, "Staged greeting", promptName(t, svc, staging))
+	assert.Equal(t, "Staged greeting", promptName(t, svc, draft))
+	assert.Equal(t, "Greeting", promptName(t, svc, production))
+
+	// A staged catalog with errors is not promoted
+	store.put("prompts/catalog.staging.yaml", testCatalog(""))
+	promotion, err := svc.PromoteCatalog(production, PromptChannelStaging)
+	assert.ErrorIs(t, err, models.ErrPromptCatalogInvalid)
+	assert.Equal(t, "s3://config/prompts/catalog.staging.yaml", promotion.Report.CatalogPath)
+	assert.Equal(t, "Greeting", promptName(t, svc, production))
+
+	store.put("prompts/catalog.staging.yaml", testCatalog("Staged greeting"))
+	promotion, err = svc.PromoteCatalog(production, PromptChannelStaging)
+	require.NoError(t, err)
+	assert.Equal(t, PromptChannelProduction, promotion.To)
+	assert.False(t, promotion.PromotedAt.IsZero())
+	assert.Equal(t, "Staged greeting", promptName(t, svc, production), "served at once on this replica")
+
+	_, err = svc.PromoteCatalog(production, PromptChannelProduction)
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.PromoteCatalog(production, PromptChannelDraft)
+	assert.ErrorIs(t, err, models.ErrPromptCatalogInvalid, "draft has no catalog to promote")
+
+	// Removing the staged catalog falls back to production again
+	delete(store.objects, "prompts/catalog.staging.yaml")
+	require.NoError(t, svc.reload(production, PromptChannelStaging))
+	_, exists := svc.catalogs[PromptChannelStaging]
+	assert.False(t, exists)
+}
+
+func TestFilePromptCatalogSource_Promote(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "catalog.yaml")
+	require.NoError(t, os.WriteFile(path, testCatalog("Greeting"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog.staging.yaml"), testCatalog("Staged greeting"), 0o644))
+	source := NewFilePromptCatalogSource(path)
+	ctx := context.Background()
+
+	_, _, err := source.Fetch(ctx, PromptChannelDraft, "")
+	assert.ErrorIs(t, err, ErrCatalogNotFound)
+	_, version, err := source.Fetch(ctx, PromptChannelProduction, "")
+	require.NoError(t, err)
+	_, _, err = source.Fetch(ctx, PromptChannelProduction, version)
+	assert.ErrorIs(t, err, ErrCatalogNotModified)
+
+	require.NoError(t, source.Promote(ctx, PromptChannelStaging, PromptChannelProduction))
+	data, _, err := source.Fetch(ctx, PromptChannelProduction, "")
+	require.NoError(t, err)
+	assert.Equal(t, testCatalog("Staged greeting"), data)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no temporary file is left behind")
+}
+
+func TestParsePromptChannel(t *testing.T) {
+	channel, err := ParsePromptChannel("")
+	require.NoError(t, err)
+	assert.Equal(t, PromptChannelProduction, channel)
+	channel, err = ParsePromptChannel("staging")
+	require.NoError(t, err)
+	assert.Equal(t, PromptChannelStaging, channel)
+	_, err = ParsePromptChannel("canary")
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+}
+
+func TestNewPromptService_RejectsInvalidCatalog(t *testing.T) {
+	store := &memoryObjectStore{objects: map[string]*aws.S3Object{}}
+	store.put("prompts/catalog.yaml", []byte("prompts: ["))
+	_, err := NewPromptService(NewS3PromptCatalogSource(store, "config", "prompts/catalog.yaml"), time.Minute, logger.New("error", "test"))
+	assert.Error(t, err)
+}
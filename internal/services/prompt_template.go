@@ -0,0 +1,182 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// bareVariablePattern matches the catalog shorthand {{name}} for a top-level variable
+var bareVariablePattern = regexp.MustCompile(`\{\{(-?\s*)([A-Za-z_][A-Za-z0-9_]*)(\s*-?)\}\}`)
+
+// templateKeywords are identifiers that must never be rewritten as variables
+var templateKeywords = map[string]bool{
+	"else": true, "end": true, "break": true, "continue": true,
+	"nil": true, "true": true, "false": true,
+}
+
+// parsePromptTemplate parses a prompt template together with the catalog partials.
+// Templates get the sprig function library, an include function for partials and
+// strict missing-key handling so references to undefined variables fail.
+func parsePromptTemplate(name, text string, partials map[string]string) (*template.Template, error) {
+	tmpl := template.New(name)
+
+	funcs := sprig.TxtFuncMap()
+	funcs["include"] = func(partial string, data interface{}) (string, error) {
+		var buf strings.Builder
+		if err := tmpl.ExecuteTemplate(&buf, partial, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	tmpl.Funcs(funcs).Option("missingkey=error")
+
+	// Parse partials in a stable order so errors are deterministic
+	names := make([]string, 0, len(partials))
+	for partialName := range partials {
+		names = append(names, partialName)
+	}
+	sort.Strings(names)
+
+	for _, partialName := range names {
+		if partialName == name {
+			return nil, fmt.Errorf("partial '%s' conflicts with prompt key", partialName)
+		}
+		if _, err := tmpl.New(partialName).Parse(normalizeTemplate(partials[partialName], funcs)); err != nil {
+			return nil, fmt.Errorf("invalid partial '%s': %w", partialName, err)
+		}
+	}
+
+	if _, err := tmpl.Parse(normalizeTemplate(text, funcs)); err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// normalizeTemplate rewrites the {{name}} shorthand into the {{.name}} field
+// syntax understood by text/template. Keywords and niladic functions such as
+// now are left untouched; functions that need arguments cannot be called bare,
+// so a variable named like one (e.g. title) still resolves to the variable.
+func normalizeTemplate(text string, funcs template.FuncMap) string {
+	return bareVariablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := bareVariablePattern.FindStringSubmatch(match)
+		ident := parts[2]
+		if templateKeywords[ident] {
+			return match
+		}
+		if fn, isFunc := funcs[ident]; isFunc && reflect.TypeOf(fn).NumIn() == 0 {
+			return match
+		}
+		return "{{" + parts[1] + "." + ident + parts[3] + "}}"
+	})
+}
+
+// extractTemplateVariables returns the top-level variables referenced by a
+// parsed template, following partials pulled in via template or include
+func extractTemplateVariables(tmpl *template.Template) []string {
+	var variables []string
+	visited := make(map[string]bool)
+
+	addVariable := func(name string) {
+		if !contains(variables, name) {
+			variables = append(variables, name)
+		}
+	}
+
+	var walkTemplate func(name string)
+	var walk func(node parse.Node, atRoot bool)
+
+	walkTemplate = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		if t := tmpl.Lookup(name); t != nil && t.Tree != nil {
+			walk(t.Tree.Root, true)
+		}
+	}
+
+	// atRoot reports whether dot still refers to the template data; inside
+	// range and with blocks fields belong to the current element instead
+	walk = func(node parse.Node, atRoot bool) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child, atRoot)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, atRoot)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd, atRoot)
+			}
+		case *parse.CommandNode:
+			for i, arg := range n.Args {
+				if ident, ok := arg.(*parse.IdentifierNode); ok && ident.Ident == "include" && i+1 < len(n.Args) {
+					if partial, ok := n.Args[i+1].(*parse.StringNode); ok {
+						walkTemplate(partial.Text)
+					}
+				}
+				walk(arg, atRoot)
+			}
+		case *parse.FieldNode:
+			if atRoot && len(n.Ident) > 0 {
+				addVariable(n.Ident[0])
+			}
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				addVariable(n.Ident[1])
+			}
+		case *parse.ChainNode:
+			walk(n.Node, atRoot)
+		case *parse.IfNode:
+			walk(n.Pipe, atRoot)
+			walk(n.List, atRoot)
+			walk(n.ElseList, atRoot)
+		case *parse.RangeNode:
+			walk(n.Pipe, atRoot)
+			walk(n.List, false)
+			walk(n.ElseList, atRoot)
+		case *parse.WithNode:
+			walk(n.Pipe, atRoot)
+			walk(n.List, false)
+			walk(n.ElseList, atRoot)
+		case *parse.TemplateNode:
+			walk(n.Pipe, atRoot)
+			walkTemplate(n.Name)
+		}
+	}
+
+	walkTemplate(tmpl.Name())
+	return variables
+}
+
+// buildTemplateData merges caller data with variable defaults so optional
+// variables resolve under strict missing-key handling
+func buildTemplateData(prompt *Prompt, data map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(prompt.Variables)+len(data))
+	for _, variable := range prompt.Variables {
+		if variable.Default != nil {
+			merged[variable.Name] = variable.Default
+		} else if !variable.Required {
+			merged[variable.Name] = ""
+		}
+	}
+	for key, value := range data {
+		merged[key] = value
+	}
+	return merged
+}
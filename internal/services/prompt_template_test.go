@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePromptTemplate(t *testing.T) {
+	partials := map[string]string{
+		"platform_hint": `{{if eq .platform "tiktok"}}short{{else}}long{{end}}`,
+	}
+
+	tests := []struct {
+		name        string
+		template    string
+		data        map[string]interface{}
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "Bare variable shorthand",
+			template: "Topic: {{topic}}",
+			data:     map[string]interface{}{"topic": "cats"},
+			expected: "Topic: cats",
+		},
+		{
+			name:     "Variable named like a sprig function",
+			template: "Title: {{title}}",
+			data:     map[string]interface{}{"title": "Hello"},
+			expected: "Title: Hello",
+		},
+		{
+			name:     "Sprig functions",
+			template: `{{ .topic | upper | trunc 3 }}`,
+			data:     map[string]interface{}{"topic": "cats"},
+			expected: "CAT",
+		},
+		{
+			name:     "Include partial",
+			template: `Format: {{ include "platform_hint" . }}`,
+			data:     map[string]interface{}{"platform": "tiktok"},
+			expected: "Format: short",
+		},
+		{
+			name:        "Missing key fails",
+			template:    "Topic: {{topic}}",
+			data:        map[string]interface{}{},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parsePromptTemplate("test/prompt", tt.template, partials)
+			require.NoError(t, err)
+
+			var buf strings.Builder
+			err = tmpl.Execute(&buf, tt.data)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestExtractTemplateVariables(t *testing.T) {
+	partials := map[string]string{
+		"hint": `{{ .language }}`,
+	}
+
+	tmpl, err := parsePromptTemplate("test/prompt",
+		`{{topic}} {{ include "hint" . }}{{ range .items }}{{ .name }}{{ end }}{{ if .tone }}{{ $.audience }}{{ end }}`,
+		partials)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"topic", "language", "items", "tone", "audience"}, extractTemplateVariables(tmpl))
+}
+
+func TestBuildTemplateData(t *testing.T) {
+	prompt := &Prompt{
+		Variables: []PromptVariable{
+			{Name: "topic", Type: "string", Required: true},
+			{Name: "tone", Type: "string", Default: "engaging"},
+			{Name: "themes", Type: "string"},
+		},
+	}
+
+	data := buildTemplateData(prompt, map[string]interface{}{"topic": "cats", "tone": "casual"})
+
+	assert.Equal(t, "cats", data["topic"])
+	assert.Equal(t, "casual", data["tone"])
+	assert.Equal(t, "", data["themes"])
+}
+
+func TestPromptCatalog_Validates(t *testing.T) {
+	svc, err := NewPromptService(NewFilePromptCatalogSource("../../prompts/catalog.yaml"), time.Minute, logger.New("error", "test"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	prompts, err := svc.ListPrompts(ctx)
+	require.NoError(t, err)
+
+	for _, prompt := range prompts {
+		assert.NoError(t, svc.ValidatePrompt(ctx, prompt), "prompt %s should validate", prompt.Key)
+	}
+}
@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	pkgpartners "github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// maxPublicationsPerRun bounds the jobs a stage or release run handles, so a
+// backlog is worked through over several runs
+const maxPublicationsPerRun = 50
+
+var (
+	// errNoWorkspace fails publications that do not say whose credentials to use
+	errNoWorkspace = errors.New("publication has no workspace")
+	// errNotTranscoded holds publications back until their video is transcoded
+	// for the platform
+	errNotTranscoded = errors.New("video is not transcoded for the platform")
+	// errReconnectNeeded holds publications whose workspace's token the
+	// platform rejected until it is reconnected
+	errReconnectNeeded = fmt.Errorf("workspace must be reconnected: %w", pkgpartners.ErrInvalidToken)
+)
+
+// publicationService implements the PublicationService interface
+type publicationService struct {
+	jobs        models.PublicationJobRepository
+	videos      models.VideoRepository
+	workspaces  models.WorkspaceRepository
+	renditions  RenditionService
+	platforms   *partners.Service
+	tracker     JobService
+	alerts      AlertService
+	connections ConnectionService
+	blackouts   BlackoutService
+	series      SeriesService
+	lead        time.Duration
+	clock       clock.Clock
+	logger      *logger.Logger
+}
+
+var _ PublicationService = (*publicationService)(nil)
+
+// NewPublicationService creates a publication service staging uploads up to
+// lead before their release. Failed attempts are checked against the
+// tenant's alert rules; those the platform refused the workspace's token for
+// are held until connections learns it was reconnected. Publications due in
+// one of the tenant's blackout windows are moved out of it first, and
+// episodes of a series are titled and playlisted through series.
+func NewPublicationService(jobs models.PublicationJobRepository, videos models.VideoRepository, workspaces models.WorkspaceRepository, renditions RenditionService, platforms *partners.Service, tracker JobService, alerts AlertService, connections ConnectionService, blackouts BlackoutService, series SeriesService, lead time.Duration, clock clock.Clock, logger *logger.Logger) PublicationService {
+	return &publicationService{
+		jobs:        jobs,
+		videos:      videos,
+		workspaces:  workspaces,
+		renditions:  renditions,
+		platforms:   platforms,
+		tracker:     tracker,
+		alerts:      alerts,
+		connections: connections,
+		blackouts:   blackouts,
+		series:      series,
+		lead:        lead,
+		clock:       clock,
+		logger:      logger,
+	}
+}
+
+// Stage uploads the videos of upcoming publications hidden
+func (s *publicationService) Stage(ctx context.Context) (*PublicationRunReport, error) {
+	report := &PublicationRunReport{}
+	now := s.clock.Now()
+	for _, platform := range models.Platforms {
+		caps := pkgpartners.PlatformCapabilities[platform]
+		if !caps.Staging {
+			continue
+		}
+		lead := s.lead
+		if window := time.Duration(caps.StagingWindowHours) * time.Hour; window > 0 && window < lead {
+			lead = window
+		}
+
+		jobs, err := s.jobs.GetStageableJobs(ctx, string(platform), now.Add(lead), maxPublicationsPerRun)
+		if err != nil {
+			return report, fmt.Errorf("failed to list %s publications to stage: %w", platform, err)
+		}
+		for _, job := range jobs {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			s.stage(ctx, job, report)
+		}
+	}
+	return report, nil
+}
+
+// stage uploads the platform's rendition of the video of one publication once
+// it is transcoded
+func (s *publicationService) stage(ctx context.Context, job *models.PublicationJob, report *PublicationRunReport) {
+	if s.blackedOut(ctx, job, job.ScheduledAt.Time) {
+		return
+	}
+	video, ws, err := s.load(ctx, job)
+	var rendition *models.VideoRendition
+	if err == nil {
+		rendition, err = s.rendition(ctx, job, video)
+	}
+	if errors.Is(err, errNotTranscoded) {
+		return // Staged once transcoded
+	}
+	if errors.Is(err, errReconnectNeeded) {
+		s.hold(ctx, job)
+		return
+	}
+	report.Jobs++
+	if err == nil {
+		// Not uploaded when it could not be released
+		err = video.Rights.Allow(models.Platform(job.Platform), job.ScheduledAt.Time)
+	}
+	if err == nil {
+		var visibility pkgpartners.Visibility
+		visibility, err = pkgpartners.ParseVisibility(configString(job, "visibility"))
+		if err == nil {
+			err = s.series.Prepare(ctx, video)
+		}
+		if err == nil {
+			err = s.platforms.UploadRendition(pkgpartners.WithStagedVisibility(ctx, visibility), ws, video, rendition, models.Platform(job.Platform))
+		}
+	}
+	if err != nil {
+		if errors.Is(err, pkgpartners.ErrQuotaExceeded) || errors.Is(err, pkgpartners.ErrQuotaDeferred) {
+			// Tried again on the next run, or uploaded at release
+			s.logger.Warn("Publication staging waits for platform quota", "tenant_id", job.TenantID, "publication_id", job.ID, "platform", job.Platform)
+			return
+		}
+		report.Failed++
+		s.fail(ctx, job, "staging", err)
+		return
+	}
+
+	now := s.clock.Now()
+	job.Status = string(models.PublicationStaged)
+	job.StagedAt = sql.NullTime{Time: now, Valid: true}
+	job.ExternalID = platformID(video, models.Platform(job.Platform))
+	job.ClearFailure()
+	job.UpdatedAt = now
+	if err := s.save(ctx, job, video); err != nil {
+		s.logger.Error("Failed to save staged publication", "error", err, "tenant_id", job.TenantID, "publication_id", job.ID)
+		return
+	}
+	report.Done++
+	// Staged publications only wait for their release
+	s.tracker.Progress(ctx, job.TenantID, models.PublishJob(job), 50)
+	s.logger.Info("Publication staged", "tenant_id", job.TenantID, "publication_id", job.ID, "platform", job.Platform,
+		"external_id", job.ExternalID, "scheduled_at", job.ScheduledAt.Time)
+}
+
+// Release publishes the due publications
+func (s *publicationService) Release(ctx context.Context) (*PublicationRunReport, error) {
+	report := &PublicationRunReport{}
+	jobs, err := s.jobs.GetDueJobs(ctx, s.clock.Now(), maxPublicationsPerRun)
+	if err != nil {
+		return report, fmt.Errorf("failed to list due publications: %w", err)
+	}
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		s.release(ctx, job, report)
+	}
+	return report, nil
+}
+
+// release flips the visibility of a staged publication, or uploads and
+// publishes one that could not be staged once its video is transcoded
+func (s *publicationService) release(ctx context.Context, job *models.PublicationJob, report *PublicationRunReport) {
+	platform := models.Platform(job.Platform)
+	staged := job.Status == string(models.PublicationStaged)
+	if s.blackedOut(ctx, job, s.clock.Now()) {
+		return
+	}
+	video, ws, err := s.load(ctx, job)
+	var rendition *models.VideoRendition
+	if err == nil && !staged {
+		rendition, err = s.rendition(ctx, job, video)
+	}
+	if errors.Is(err, errNotTranscoded) {
+		return // Released late, as soon as it is transcoded
+	}
+	if errors.Is(err, errReconnectNeeded) {
+		s.hold(ctx, job)
+		return
+	}
+	report.Jobs++
+	if err == nil {
+		err = video.Rights.Allow(platform, s.clock.Now())
+	}
+	if err == nil && !staged {
+		err = s.series.Prepare(ctx, video)
+	}
+	if err == nil && !staged {
+		if err = s.platforms.UploadRendition(ctx, ws, video, rendition, platform); err == nil {
+			// A failed publish is retried without uploading again
+			now := s.clock.Now()
+			job.Status = string(models.PublicationStaged)
+			job.StagedAt = sql.NullTime{Time: now, Valid: true}
+			job.ExternalID = platformID(video, platform)
+			job.UpdatedAt = now
+			err = s.save(ctx, job, video)
+		}
+	}
+	if err == nil {
+		err = s.platforms.Release(ctx, ws, video, platform)
+	}
+	if err != nil {
+		report.Failed++
+		s.fail(ctx, job, "release", err)
+		return
+	}
+
+	now := s.clock.Now()
+	job.Status = string(models.PublicationCompleted)
+	job.CompletedAt = sql.NullTime{Time: now, Valid: true}
+	job.ClearFailure()
+	job.UpdatedAt = now
+	if err := s.jobs.Update(context.WithoutCancel(ctx), job); err != nil {
+		s.logger.Error("Failed to save released publication", "error", err, "tenant_id", job.TenantID, "publication_id", job.ID)
+	}
+	s.tracker.Succeed(ctx, job.TenantID, models.PublishJob(job))
+	s.series.Released(ctx, job, video, ws)
+	s.logger.Info("Publication released", "tenant_id", job.TenantID, "publication_id", job.ID, "platform", job.Platform,
+		"external_id", job.ExternalID, "delay", now.Sub(job.ScheduledAt.Time))
+	report.Done++
+}
+
+// Unpublish takes a publication down and records why
+func (s *publicationService) Unpublish(ctx context.Context, tenantID, userID, videoID, publicationID, reason string) (*models.PublicationJob, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "a reason is required")
+	}
+	job, err := s.jobs.GetByID(ctx, tenantID, publicationID)
+	if err != nil {
+		return nil, err
+	}
+	if job.VideoID != videoID {
+		return nil, models.ErrPublicationNotFound
+	}
+	if job.Status == string(models.PublicationUnpublished) {
+		return nil, models.ErrAlreadyUnpublished
+	}
+
+	platform := models.Platform(job.Platform)
+	takedown := models.TakedownNone
+	if job.ExternalID != "" {
+		video, ws, err := s.load(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+		err = s.platforms.Unpublish(ctx, ws, video, platform, job.ExternalID)
+		switch {
+		case err == nil:
+			takedown = string(pkgpartners.PlatformCapabilities[platform].Takedown)
+		case errors.Is(err, pkgpartners.ErrUnpublishUnsupported):
+			takedown = models.TakedownManual
+			s.logger.Warn("Publication must be taken down by hand", "tenant_id", tenantID, "publication_id", job.ID,
+				"platform", job.Platform, "external_id", job.ExternalID)
+		default:
+			return nil, fmt.Errorf("failed to take down %s video: %w", platform, err)
+		}
+	}
+
+	now := s.clock.Now()
+	job.Status = string(models.PublicationUnpublished)
+	job.UnpublishedAt = sql.NullTime{Time: now, Valid: true}
+	job.UnpublishedBy = userID
+	job.UnpublishReason = reason
+	job.Takedown = takedown
+	job.UpdatedAt = now
+	// The platform has taken it down, so the record must follow
+	if err := s.jobs.Update(context.WithoutCancel(ctx), job); err != nil {
+		return nil, fmt.Errorf("failed to save unpublished publication: %w", err)
+	}
+	s.logger.Info("Publication unpublished", "tenant_id", tenantID, "user_id", userID, "publication_id", job.ID,
+		"platform", job.Platform, "takedown", takedown, "reason", reason)
+	return job, nil
+}
+
+// List returns the publications of a video the viewer sees
+func (s *publicationService) List(ctx context.Context, viewer *models.User, videoID string) ([]*models.PublicationJob, error) {
+	if _, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID); err != nil {
+		return nil, err
+	}
+	jobs, err := s.jobs.GetByVideoID(ctx, viewer.TenantID, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publications: %w", err)
+	}
+	return jobs, nil
+}
+
+// load returns the video of a publication and the workspace it is published with
+func (s *publicationService) load(ctx context.Context, job *models.PublicationJob) (*models.Video, *models.Workspace, error) {
+	if job.WorkspaceID == "" {
+		return nil, nil, errNoWorkspace
+	}
+	video, err := s.videos.GetByID(ctx, job.TenantID, job.VideoID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get video: %w", err)
+	}
+	ws, err := s.workspaces.GetByID(ctx, job.TenantID, job.WorkspaceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if ws.ReconnectNeeded(models.Platform(job.Platform)) {
+		return nil, nil, errReconnectNeeded
+	}
+	return video, ws, nil
+}
+
+// rendition returns the rendition of the video to upload to the publication's
+// platform, nil for the video's own file, or errNotTranscoded
+func (s *publicationService) rendition(ctx context.Context, job *models.PublicationJob, video *models.Video) (*models.VideoRendition, error) {
+	if video.Status != string(models.StatusReady) {
+		return nil, errNotTranscoded
+	}
+	rendition, err := s.renditions.ForPlatform(ctx, job.TenantID, job.VideoID, models.Platform(job.Platform))
+	if errors.Is(err, models.ErrRenditionNotReady) {
+		return nil, errNotTranscoded
+	}
+	return rendition, err
+}
+
+// save stores the publication and the platform ID set on its video
+func (s *publicationService) save(ctx context.Context, job *models.PublicationJob, video *models.Video) error {
+	ctx = context.WithoutCancel(ctx)
+	if err := s.videos.Update(ctx, video); err != nil {
+		return err
+	}
+	return s.jobs.Update(ctx, job)
+}
+
+// fail records a failed step, failing the publication when it cannot succeed
+// or has no retries left
+func (s *publicationService) fail(ctx context.Context, job *models.PublicationJob, step string, err error) {
+	failure := publicationFailure(err)
+	job.SetFailure(failure, fmt.Sprintf("%s: %v", step, err))
+	if failure == models.FailureTokenExpired && job.WorkspaceID != "" {
+		// Not an attempt of its own: tried again once reconnected
+		s.hold(ctx, job)
+		if err := s.connections.NeedsReconnect(ctx, job.TenantID, job.WorkspaceID, models.Platform(job.Platform)); err != nil {
+			s.logger.Error("Failed to mark workspace for reconnection", "error", err, "tenant_id", job.TenantID,
+				"workspace_id", job.WorkspaceID, "platform", job.Platform)
+		}
+		return
+	}
+	job.RetryCount++
+	job.UpdatedAt = s.clock.Now()
+	permanent := errors.Is(err, errNoWorkspace) || errors.Is(err, models.ErrVideoNotFound) ||
+		errors.Is(err, models.ErrNotFound) || failure == models.FailureTokenExpired ||
+		failure == models.FailureCopyright || failure == models.FailureInvalidMetadata
+	if permanent || (step == "release" && job.RetryCount >= job.MaxRetries) {
+		job.Status = string(models.PublicationFailed)
+	}
+	if err := s.jobs.Update(context.WithoutCancel(ctx), job); err != nil {
+		s.logger.Error("Failed to save publication failure", "error", err, "tenant_id", job.TenantID, "publication_id", job.ID)
+	}
+	if job.Status == string(models.PublicationFailed) {
+		s.tracker.Fail(ctx, job.TenantID, models.PublishJob(job), job.FailureMessage)
+	}
+	s.alerts.PublicationFailed(context.WithoutCancel(ctx), job)
+	s.logger.Error("Publication "+step+" failed", "error", err, "tenant_id", job.TenantID, "publication_id", job.ID,
+		"platform", job.Platform, "status", job.Status, "failure_reason", failure, "retry_count", job.RetryCount)
+}
+
+// hold keeps a publication from the platform until its workspace is
+// reconnected
+func (s *publicationService) hold(ctx context.Context, job *models.PublicationJob) {
+	job.Status = string(models.PublicationAwaitingReconnect)
+	job.UpdatedAt = s.clock.Now()
+	if err := s.jobs.Update(context.WithoutCancel(ctx), job); err != nil {
+		s.logger.Error("Failed to hold publication", "error", err, "tenant_id", job.TenantID, "publication_id", job.ID)
+		return
+	}
+	s.logger.Warn("Publication held until its workspace is reconnected", "tenant_id", job.TenantID, "publication_id", job.ID,
+		"workspace_id", job.WorkspaceID, "platform", job.Platform)
+}
+
+// blackedOut moves the publication out of the blackout window covering at,
+// reporting whether it must wait. It waits for the next run when the windows
+// cannot be checked.
+func (s *publicationService) blackedOut(ctx context.Context, job *models.PublicationJob, at time.Time) bool {
+	moved, err := s.blackouts.Apply(ctx, job, at)
+	if err != nil {
+		s.logger.Error("Failed to check blackout windows", "error", err, "tenant_id", job.TenantID, "publication_id", job.ID)
+		return true
+	}
+	return moved
+}
+
+// publicationFailure classifies the error a publication step failed with
+func publicationFailure(err error) models.PublicationFailure {
+	switch {
+	case errors.Is(err, pkgpartners.ErrQuotaExceeded), errors.Is(err, pkgpartners.ErrQuotaDeferred):
+		return models.FailureQuota
+	case errors.Is(err, pkgpartners.ErrInvalidMetadata):
+		return models.FailureInvalidMetadata
+	case errors.Is(err, models.ErrRightsExpired), errors.Is(err, models.ErrPlatformNotLicensed):
+		return models.FailureCopyright
+	case errors.Is(err, pkgpartners.ErrInvalidToken):
+		return models.FailureTokenExpired
+	default:
+		return models.FailureUnknown
+	}
+}
+
+// configString reads a string setting of the publication's platform config
+func configString(job *models.PublicationJob, key string) string {
+	s, _ := job.GetPlatformConfig()[key].(string)
+	return s
+}
+
+// platformID returns the ID the platform gave the video
+func platformID(video *models.Video, platform models.Platform) string {
+	switch platform {
+	case models.PlatformYouTube:
+		return video.YouTubeID
+	case models.PlatformTikTok:
+		return video.TikTokID
+	case models.PlatformInstagram:
+		return video.InstagramID
+	case models.PlatformFacebook:
+		return video.FacebookID
+	case models.PlatformTwitter:
+		if video.TwitterMediaID != 0 {
+			return strconv.FormatInt(video.TwitterMediaID, 10)
+		}
+	case models.PlatformSnapchat:
+		return video.SnapchatMediaID
+	}
+	return ""
+}
@@ -0,0 +1,532 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	pkgpartners "github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// memoryPublicationRepo filters its jobs like the stage and release queries
+type memoryPublicationRepo struct {
+	models.PublicationJobRepository
+	jobs []*models.PublicationJob
+}
+
+func (r *memoryPublicationRepo) GetStageableJobs(ctx context.Context, platform string, before time.Time, limit int) ([]*models.PublicationJob, error) {
+	var out []*models.PublicationJob
+	for _, job := range r.jobs {
+		if job.Status == string(models.PublicationScheduled) && job.Platform == platform &&
+			!job.ScheduledAt.Time.After(before) && job.RetryCount < job.MaxRetries {
+			out = append(out, job)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryPublicationRepo) GetDueJobs(ctx context.Context, before time.Time, limit int) ([]*models.PublicationJob, error) {
+	var out []*models.PublicationJob
+	for _, job := range r.jobs {
+		staged := job.Status == string(models.PublicationStaged)
+		if (staged || job.Status == string(models.PublicationScheduled)) && !job.ScheduledAt.Time.After(before) {
+			out = append(out, job)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryPublicationRepo) GetByID(ctx context.Context, tenantID, id string) (*models.PublicationJob, error) {
+	for _, job := range r.jobs {
+		if job.ID == id {
+			return job, nil
+		}
+	}
+	return nil, models.ErrPublicationNotFound
+}
+
+func (r *memoryPublicationRepo) GetByVideoID(ctx context.Context, tenantID, videoID string) ([]*models.PublicationJob, error) {
+	var out []*models.PublicationJob
+	for _, job := range r.jobs {
+		if job.TenantID == tenantID && job.VideoID == videoID {
+			out = append(out, job)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryPublicationRepo) Update(ctx context.Context, job *models.PublicationJob) error {
+	return nil
+}
+
+type publicationVideoRepo struct {
+	models.VideoRepository
+	videos map[string]*models.Video
+}
+
+func (r *publicationVideoRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Video, error) {
+	if v, ok := r.videos[id]; ok {
+		return v, nil
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+func (r *publicationVideoRepo) Update(ctx context.Context, video *models.Video) error {
+	return nil
+}
+
+// publicationWorkspaceRepo knows every workspace, connected again to all
+// platforms but those in reconnect
+type publicationWorkspaceRepo struct {
+	models.WorkspaceRepository
+	reconnect []string
+}
+
+func (r *publicationWorkspaceRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Workspace, error) {
+	return &models.Workspace{ID: id, TenantID: tenantID, NeedsReconnect: r.reconnect}, nil
+}
+
+// reconnectConnections marks the platforms to reconnect on the workspace repo
+type reconnectConnections struct {
+	ConnectionService
+	workspaces *publicationWorkspaceRepo
+}
+
+func (c *reconnectConnections) NeedsReconnect(ctx context.Context, tenantID, workspaceID string, platform models.Platform) error {
+	c.workspaces.reconnect = append(c.workspaces.reconnect, string(platform))
+	return nil
+}
+
+// publicationRenditions holds renditions by video ID and platform
+type publicationRenditions struct {
+	RenditionService
+	renditions map[string]*models.VideoRendition
+}
+
+func (r *publicationRenditions) ForPlatform(ctx context.Context, tenantID, videoID string, platform models.Platform) (*models.VideoRendition, error) {
+	rendition, ok := r.renditions[videoID+"/"+string(platform)]
+	if !ok {
+		return nil, nil
+	}
+	if !rendition.Ready() {
+		return nil, models.ErrRenditionNotReady
+	}
+	return rendition, nil
+}
+
+// stagingClient records the calls reaching the platform
+type stagingClient struct {
+	pkgpartners.Client
+	calls      []string
+	files      []string
+	titles     []string
+	uploadErr  error
+	publishErr error
+}
+
+func (c *stagingClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
+	return nil
+}
+
+func (c *stagingClient) Upload(ctx context.Context, v *models.Video) (string, error) {
+	c.calls = append(c.calls, "upload "+v.ID)
+	c.files = append(c.files, v.FilePath)
+	c.titles = append(c.titles, v.PublishTitle())
+	if c.uploadErr != nil {
+		return "", c.uploadErr
+	}
+	return "yt-" + v.ID, nil
+}
+
+func (c *stagingClient) Publish(ctx context.Context, v *models.Video, ws *models.Workspace) error {
+	c.calls = append(c.calls, "publish "+v.ID)
+	return c.publishErr
+}
+
+func (c *stagingClient) Unpublish(ctx context.Context, v *models.Video) error {
+	c.calls = append(c.calls, "unpublish "+v.YouTubeID+v.TikTokID)
+	if v.TikTokID != "" {
+		return pkgpartners.ErrUnpublishUnsupported
+	}
+	return nil
+}
+
+// failureAlerts records the retry counts of the failed attempts checked
+// against the alert rules
+type failureAlerts struct {
+	AlertService
+	attempts []int
+}
+
+func (a *failureAlerts) PublicationFailed(ctx context.Context, job *models.PublicationJob) {
+	a.attempts = append(a.attempts, job.RetryCount)
+}
+
+type publicationFixture struct {
+	svc        PublicationService
+	jobs       *memoryPublicationRepo
+	alerts     *failureAlerts
+	videos     *publicationVideoRepo
+	workspaces *publicationWorkspaceRepo
+	renditions *publicationRenditions
+	blackouts  *memoryBlackoutRepo
+	series     *memorySeriesRepo
+	client     *stagingClient
+	clock      *clock.Fake
+}
+
+func newPublicationFixture(t *testing.T, jobs ...*models.PublicationJob) *publicationFixture {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	f := &publicationFixture{
+		jobs:       &memoryPublicationRepo{jobs: jobs},
+		renditions: &publicationRenditions{renditions: map[string]*models.VideoRendition{}},
+		client:     &stagingClient{},
+		alerts:     &failureAlerts{},
+		workspaces: &publicationWorkspaceRepo{},
+		blackouts:  &memoryBlackoutRepo{},
+		series:     &memorySeriesRepo{},
+		clock:      clock.NewFake(now),
+	}
+	f.videos = &publicationVideoRepo{videos: map[string]*models.Video{
+		"ready":      {ID: "ready", TenantID: "acme", Status: string(models.StatusReady)},
+		"processing": {ID: "processing", TenantID: "acme", Status: string(models.StatusProcessing)},
+	}}
+	platforms := partners.NewService(func(string) (pkgpartners.Client, error) { return f.client, nil })
+	_, tracker := newTestJobs(f.clock)
+	connections := &reconnectConnections{workspaces: f.workspaces}
+	blackouts := NewBlackoutService(f.blackouts, f.jobs, &recordingNotifications{subjects: map[string][]string{}}, f.clock, logger.New("error", "test"))
+	f.series.videos = f.videos
+	series := NewSeriesService(f.series, f.videos, f.workspaces, func(string) (pkgpartners.Client, error) { return f.client, nil },
+		f.clock, logger.New("error", "test"))
+	f.svc = NewPublicationService(f.jobs, f.videos, f.workspaces, f.renditions, platforms, tracker, f.alerts, connections, blackouts, series,
+		6*time.Hour, f.clock, logger.New("error", "test"))
+	return f
+}
+
+func newPublication(id, videoID string, platform models.Platform, scheduledAt time.Time) *models.PublicationJob {
+	return &models.PublicationJob{
+		ID:          id,
+		TenantID:    "acme",
+		VideoID:     videoID,
+		WorkspaceID: "ws-1",
+		Platform:    string(platform),
+		Status:      string(models.PublicationScheduled),
+		ScheduledAt: sql.NullTime{Time: scheduledAt, Valid: true},
+		MaxRetries:  3,
+	}
+}
+
+func TestPublicationService_StageUploadsWithinLead(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	soon := newPublication("soon", "ready", models.PlatformYouTube, now.Add(5*time.Hour))
+	later := newPublication("later", "ready", models.PlatformYouTube, now.Add(7*time.Hour))
+	transcoding := newPublication("transcoding", "processing", models.PlatformYouTube, now.Add(time.Hour))
+	tiktok := newPublication("tiktok", "ready", models.PlatformTikTok, now.Add(time.Hour))
+	f := newPublicationFixture(t, soon, later, transcoding, tiktok)
+
+	report, err := f.svc.Stage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &PublicationRunReport{Jobs: 1, Done: 1}, report)
+	assert.Equal(t, []string{"upload ready"}, f.client.calls)
+
+	assert.Equal(t, string(models.PublicationStaged), soon.Status)
+	assert.Equal(t, "yt-ready", soon.ExternalID)
+	assert.Equal(t, now, soon.StagedAt.Time)
+	for _, job := range []*models.PublicationJob{later, transcoding, tiktok} {
+		assert.Equal(t, string(models.PublicationScheduled), job.Status, job.ID)
+	}
+}
+
+func TestPublicationService_StageFailures(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	missing := newPublication("missing", "deleted", models.PlatformYouTube, now.Add(time.Hour))
+	flaky := newPublication("flaky", "ready", models.PlatformYouTube, now.Add(time.Hour))
+	f := newPublicationFixture(t, missing, flaky)
+	f.client.uploadErr = errors.New("connection reset")
+
+	report, err := f.svc.Stage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &PublicationRunReport{Jobs: 2, Failed: 2}, report)
+	assert.Equal(t, string(models.PublicationFailed), missing.Status, "a deleted video cannot be published")
+	assert.Equal(t, string(models.PublicationScheduled), flaky.Status, "left for the release to upload")
+	assert.Equal(t, 1, flaky.RetryCount)
+
+	// Deferred uploads wait without using up the job's retries
+	f.client.uploadErr = pkgpartners.ErrQuotaDeferred
+	report, err = f.svc.Stage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &PublicationRunReport{Jobs: 1}, report)
+	assert.Equal(t, 1, flaky.RetryCount)
+}
+
+func TestPublicationService_ReleaseFlipsStagedAndPublishesOthers(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	staged := newPublication("staged", "ready", models.PlatformYouTube, now.Add(-time.Second))
+	staged.Status = string(models.PublicationStaged)
+	unstaged := newPublication("unstaged", "ready", models.PlatformTikTok, now)
+	transcoding := newPublication("transcoding", "processing", models.PlatformTikTok, now)
+	upcoming := newPublication("upcoming", "ready", models.PlatformYouTube, now.Add(time.Minute))
+	upcoming.Status = string(models.PublicationStaged)
+	f := newPublicationFixture(t, staged, unstaged, transcoding, upcoming)
+
+	report, err := f.svc.Release(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &PublicationRunReport{Jobs: 2, Done: 2}, report)
+	assert.Equal(t, []string{"publish ready", "upload ready", "publish ready"}, f.client.calls)
+
+	for _, job := range []*models.PublicationJob{staged, unstaged} {
+		assert.Equal(t, string(models.PublicationCompleted), job.Status, job.ID)
+		assert.Equal(t, now, job.CompletedAt.Time, job.ID)
+	}
+	assert.Equal(t, string(models.PublicationScheduled), transcoding.Status, "released once transcoded")
+	assert.Equal(t, string(models.PublicationStaged), upcoming.Status)
+}
+
+func TestPublicationService_ReleaseRetriesUntilMaxRetries(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	job := newPublication("job", "ready", models.PlatformYouTube, now)
+	f := newPublicationFixture(t, job)
+	f.client.publishErr = errors.New("backend error")
+
+	for i := 1; i <= 3; i++ {
+		report, err := f.svc.Release(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Failed)
+	}
+	assert.Equal(t, string(models.PublicationFailed), job.Status)
+	assert.Equal(t, 3, job.RetryCount)
+	assert.Equal(t, []int{1, 2, 3}, f.alerts.attempts, "every failed attempt is checked against the alert rules")
+	assert.Equal(t, models.FailureUnknown, job.FailureReason)
+	assert.Equal(t, "release: backend error", job.ErrorMsg)
+	// Uploaded once, then only the publish was retried
+	assert.Equal(t, []string{"upload ready", "publish ready", "publish ready", "publish ready"}, f.client.calls)
+}
+
+func TestPublicationService_ReleaseClassifiesFailures(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		err       error
+		failure   models.PublicationFailure
+		permanent bool
+	}{
+		{"quota", fmt.Errorf("youtube: %w", pkgpartners.ErrQuotaExceeded), models.FailureQuota, false},
+		{"metadata", fmt.Errorf("youtube: %w", pkgpartners.ErrInvalidMetadata), models.FailureInvalidMetadata, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := newPublication("job", "ready", models.PlatformYouTube, now)
+			f := newPublicationFixture(t, job)
+			f.client.publishErr = tt.err
+
+			_, err := f.svc.Release(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.failure, job.FailureReason)
+			assert.NotEmpty(t, job.FailureMessage)
+			assert.NotEmpty(t, job.Remediation)
+			assert.Equal(t, tt.permanent, job.Status == string(models.PublicationFailed))
+
+			// A successful retry forgets the failure
+			if !tt.permanent {
+				f.client.publishErr = nil
+				_, err = f.svc.Release(context.Background())
+				require.NoError(t, err)
+				assert.Equal(t, string(models.PublicationCompleted), job.Status)
+				assert.Empty(t, job.FailureReason)
+				assert.Empty(t, job.Remediation)
+			}
+		})
+	}
+}
+
+func TestPublicationService_HoldsPublicationsUntilReconnected(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	staged := newPublication("staged", "ready", models.PlatformYouTube, now.Add(-time.Minute))
+	staged.Status = string(models.PublicationStaged)
+	scheduled := newPublication("scheduled", "ready", models.PlatformYouTube, now)
+	f := newPublicationFixture(t, staged, scheduled)
+	f.client.publishErr = fmt.Errorf("youtube: %w", pkgpartners.ErrInvalidToken)
+
+	_, err := f.svc.Release(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"youtube"}, f.workspaces.reconnect)
+	assert.Equal(t, []string{"publish ready"}, f.client.calls, "nothing else reaches the platform with the rejected token")
+	for _, job := range []*models.PublicationJob{staged, scheduled} {
+		assert.Equal(t, string(models.PublicationAwaitingReconnect), job.Status, job.ID)
+		assert.Zero(t, job.RetryCount, job.ID)
+	}
+	assert.Equal(t, models.FailureTokenExpired, staged.FailureReason)
+	assert.Empty(t, f.alerts.attempts)
+}
+
+func TestPublicationService_MovesPublicationsOutOfBlackoutWindows(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	shifted := newPublication("shifted", "ready", models.PlatformYouTube, now.Add(time.Hour))
+	held := newPublication("held", "ready", models.PlatformTikTok, now)
+	f := newPublicationFixture(t, shifted, held)
+	f.blackouts.windows = []*models.BlackoutWindow{
+		{ID: "embargo", TenantID: "acme", Name: "Embargo", StartsAt: now.Add(30 * time.Minute), EndsAt: now.Add(3 * time.Hour), Action: "shift"},
+		{ID: "holiday", TenantID: "acme", Name: "Holiday", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Minute), Action: "hold"},
+	}
+	ctx := context.Background()
+
+	_, err := f.svc.Stage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(3*time.Hour), shifted.ScheduledAt.Time, "moved to the end of the window before staging")
+	assert.Equal(t, "embargo", shifted.BlackoutWindowID)
+	assert.Equal(t, string(models.PublicationScheduled), shifted.Status)
+
+	_, err = f.svc.Release(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.PublicationAwaitingApproval), held.Status)
+	assert.Equal(t, "holiday", held.BlackoutWindowID)
+	assert.Empty(t, f.client.calls, "nothing reaches the platform in a window")
+
+	// Once moved, publications go out when they are due again
+	f.clock.Advance(3 * time.Hour)
+	_, err = f.svc.Stage(ctx)
+	require.NoError(t, err)
+	_, err = f.svc.Release(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.PublicationCompleted), shifted.Status)
+}
+
+func TestPublicationService_TitlesSeriesEpisodes(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	f := newPublicationFixture(t, newPublication("episode", "ready", models.PlatformYouTube, now.Add(time.Hour)))
+	f.series.series = []*models.Series{{ID: "cold-cases", TenantID: "acme", Name: "Cold cases", TitlePattern: "S{{season:2}}E{{episode:2}}: {{title}}", Season: 1}}
+	video := f.videos.videos["ready"]
+	video.Title = "The lighthouse"
+	video.Episode = models.VideoEpisode{SeriesID: "cold-cases", Season: 1, Number: 3}
+
+	_, err := f.svc.Stage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"S01E03: The lighthouse"}, f.client.titles)
+	assert.Equal(t, "S01E03: The lighthouse", video.Episode.Title, "kept with the video")
+	assert.Equal(t, "The lighthouse", video.Title)
+}
+
+func TestPublicationService_Unpublish(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	live := newPublication("live", "ready", models.PlatformYouTube, now.Add(-time.Hour))
+	live.Status = string(models.PublicationCompleted)
+	live.ExternalID = "yt-first"
+	tiktok := newPublication("tiktok", "ready", models.PlatformTikTok, now.Add(-time.Hour))
+	tiktok.Status = string(models.PublicationCompleted)
+	tiktok.ExternalID = "tt-1"
+	upcoming := newPublication("upcoming", "ready", models.PlatformYouTube, now.Add(time.Hour))
+	f := newPublicationFixture(t, live, tiktok, upcoming)
+	ctx := context.Background()
+
+	job, err := f.svc.Unpublish(ctx, "acme", "user-1", "ready", "live", " DMCA notice 4711 ")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.PublicationUnpublished), job.Status)
+	assert.Equal(t, "private", job.Takedown)
+	assert.Equal(t, "DMCA notice 4711", job.UnpublishReason)
+	assert.Equal(t, "user-1", job.UnpublishedBy)
+	assert.Equal(t, now, job.UnpublishedAt.Time)
+
+	job, err = f.svc.Unpublish(ctx, "acme", "user-1", "ready", "tiktok", "court order")
+	require.NoError(t, err)
+	assert.Equal(t, models.TakedownManual, job.Takedown, "TikTok posts are removed by hand")
+
+	job, err = f.svc.Unpublish(ctx, "acme", "user-1", "ready", "upcoming", "court order")
+	require.NoError(t, err)
+	assert.Equal(t, models.TakedownNone, job.Takedown)
+	assert.Equal(t, []string{"unpublish yt-first", "unpublish tt-1"}, f.client.calls, "only uploaded videos reach the platform")
+
+	_, err = f.svc.Unpublish(ctx, "acme", "user-1", "ready", "live", "again")
+	assert.ErrorIs(t, err, models.ErrAlreadyUnpublished)
+	_, err = f.svc.Unpublish(ctx, "acme", "user-1", "other-video", "live", "court order")
+	assert.ErrorIs(t, err, models.ErrPublicationNotFound)
+	_, err = f.svc.Unpublish(ctx, "acme", "user-1", "ready", "live", "  ")
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+}
+
+func TestPublicationService_RightsBlockPublishing(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	expiring := newPublication("expiring", "licensed", models.PlatformYouTube, now.Add(2*time.Hour))
+	due := newPublication("due", "licensed", models.PlatformYouTube, now)
+	due.Status = string(models.PublicationStaged)
+	unlicensed := newPublication("unlicensed", "licensed", models.PlatformTikTok, now)
+	f := newPublicationFixture(t, expiring, due, unlicensed)
+	expires := now.Add(time.Hour)
+	f.videos.videos["licensed"] = &models.Video{ID: "licensed", Status: string(models.StatusReady),
+		Rights: models.VideoRights{Platforms: []string{"youtube"}, ExpiresAt: &expires}}
+
+	// Not uploaded since its rights expire before its release
+	_, err := f.svc.Stage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, string(models.PublicationFailed), expiring.Status)
+	assert.Contains(t, expiring.ErrorMsg, "video rights expired")
+
+	report, err := f.svc.Release(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &PublicationRunReport{Jobs: 2, Done: 1, Failed: 1}, report)
+	assert.Equal(t, string(models.PublicationCompleted), due.Status)
+	assert.Equal(t, string(models.PublicationFailed), unlicensed.Status)
+	assert.Contains(t, unlicensed.ErrorMsg, "not licensed for the platform")
+	assert.Equal(t, models.FailureCopyright, unlicensed.FailureReason)
+	assert.Equal(t, []string{"publish licensed"}, f.client.calls)
+}
+
+func TestPublicationService_UploadsPlatformRendition(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	youtube := newPublication("youtube", "ready", models.PlatformYouTube, now.Add(time.Hour))
+	tiktok := newPublication("tiktok", "ready", models.PlatformTikTok, now.Add(-time.Minute))
+	f := newPublicationFixture(t, youtube, tiktok)
+	f.videos.videos["ready"].FilePath = "/videos/ready.mov"
+	vertical := &models.VideoRendition{Profile: "vertical_1080p", Status: string(models.RenditionPending), FilePath: "/videos/ready-vertical.mp4"}
+	f.renditions.renditions["ready/tiktok"] = vertical
+
+	// YouTube has no rendition yet and gets the source, TikTok waits for its own
+	_, err := f.svc.Stage(context.Background())
+	require.NoError(t, err)
+	report, err := f.svc.Release(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &PublicationRunReport{}, report)
+	assert.Equal(t, []string{"/videos/ready.mov"}, f.client.files)
+	assert.Equal(t, string(models.PublicationScheduled), tiktok.Status)
+
+	vertical.Status = string(models.RenditionReady)
+	report, err = f.svc.Release(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &PublicationRunReport{Jobs: 1, Done: 1}, report)
+	assert.Equal(t, []string{"/videos/ready.mov", "/videos/ready-vertical.mp4"}, f.client.files)
+	assert.Equal(t, string(models.PublicationCompleted), tiktok.Status)
+}
+
+func TestPublicationService_ListKeepsToVisibleVideos(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	f := newPublicationFixture(t,
+		newPublication("youtube", "ready", models.PlatformYouTube, now),
+		newPublication("tiktok", "ready", models.PlatformTikTok, now),
+		newPublication("hidden", "private", models.PlatformYouTube, now))
+	f.videos.videos["private"] = &models.Video{ID: "private", TenantID: "acme", UserID: "owner", Visibility: models.VisibilityOwner}
+	editor := &models.User{ID: "editor", TenantID: "acme", Role: string(models.RoleEditor)}
+
+	jobs, err := f.svc.List(context.Background(), editor, "ready")
+	require.NoError(t, err)
+	var ids []string
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	assert.Equal(t, []string{"youtube", "tiktok"}, ids)
+
+	_, err = f.svc.List(context.Background(), editor, "private")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+
+	jobs, err = f.svc.List(context.Background(), &models.User{ID: "owner", TenantID: "acme", Role: string(models.RoleEditor)}, "private")
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
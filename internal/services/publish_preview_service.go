@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// publishPreviewService implements the PublishPreviewService interface
+type publishPreviewService struct {
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	watermarks WatermarkService
+	logger     *logger.Logger
+}
+
+var _ PublishPreviewService = (*publishPreviewService)(nil)
+
+// NewPublishPreviewService creates a new publish preview service
+func NewPublishPreviewService(videos models.VideoRepository, workspaces models.WorkspaceRepository, watermarks WatermarkService, logger *logger.Logger) PublishPreviewService {
+	return &publishPreviewService{videos: videos, workspaces: workspaces, watermarks: watermarks, logger: logger}
+}
+
+// Preview renders the video's metadata through the platform's template and
+// shows the tenant's watermark settings for the platform
+func (s *publishPreviewService) Preview(ctx context.Context, viewer *models.User, videoID, platform string) (*PublishPreview, error) {
+	p := models.Platform(platform)
+	if !p.Valid() {
+		return nil, fmt.Errorf("%w: %s", models.ErrInvalidPlatform, platform)
+	}
+
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, adjustments, err := partners.RenderMetadata(p, video)
+	if err != nil {
+		return nil, err
+	}
+	watermark, err := s.watermarks.Resolve(ctx, viewer.TenantID, p, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PublishPreview{
+		VideoID:      video.ID,
+		Platform:     p,
+		Metadata:     meta,
+		Adjustments:  adjustments,
+		Capabilities: partners.PlatformCapabilities[p],
+		Watermark:    watermark,
+	}, nil
+}
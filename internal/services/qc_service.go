@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// Quality control thresholds
+const (
+	// qcMaxBlackStart is how long a video may open on black, as fades in do
+	qcMaxBlackStart = 2.0
+	// qcMaxSilence is the share of the video that may be silent
+	qcMaxSilence = 0.9
+	// Integrated loudness a video may have, in LUFS, around the -14 LUFS the
+	// platforms play videos back at
+	qcMinLoudness = -24.0
+	qcMaxLoudness = -9.0
+	// The transcoded file may differ in length from the source by
+	// qcDurationTolerance of it, and by at least qcMinDurationGap seconds
+	qcDurationTolerance = 0.02
+	qcMinDurationGap    = 1.0
+)
+
+// qcService implements the QCService interface
+type qcService struct {
+	videos models.VideoRepository
+	notify NotificationService
+	clock  clock.Clock
+	logger *logger.Logger
+}
+
+var _ QCService = (*qcService)(nil)
+
+// NewQCService creates a new quality control service, telling the owners of
+// the videos failing it
+func NewQCService(videos models.VideoRepository, notify NotificationService, clock clock.Clock, logger *logger.Logger) QCService {
+	return &qcService{videos: videos, notify: notify, clock: clock, logger: logger}
+}
+
+// Record checks the measures ffmpeg logged of the video's transcoded file
+func (s *qcService) Record(ctx context.Context, tenantID, videoID string, log []byte) (*models.Video, error) {
+	measures, err := transcode.ParseQC(log)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidInput, err)
+	}
+	video, err := s.videos.GetByID(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	reasons := qcReasons(video, measures)
+	video.QC = models.VideoQC{CheckedAt: &now, Reasons: reasons}
+	switch {
+	case len(reasons) > 0 && video.Status == string(models.StatusReady):
+		video.Status = string(models.StatusQCFailed)
+	case len(reasons) == 0 && video.Status == string(models.StatusQCFailed):
+		video.Status = string(models.StatusReady) // Fixed and processed again
+	}
+	video.UpdatedAt = now
+	if err := s.videos.Update(ctx, video); err != nil {
+		return nil, fmt.Errorf("failed to save quality control: %w", err)
+	}
+	if len(reasons) == 0 {
+		s.logger.Info("Video passed quality control", "tenant_id", tenantID, "video_id", videoID)
+		return video, nil
+	}
+
+	codes := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		codes = append(codes, reason.Code)
+	}
+	s.logger.Warn("Video failed quality control", "tenant_id", tenantID, "video_id", videoID, "reasons", codes)
+	err = s.notify.Notify(ctx, tenantID, video.UserID, i18n.M("Video failed quality control"),
+		i18n.M("Your video %s is held from publishing: %s", video.Title, strings.Join(codes, ", ")))
+	if err != nil {
+		s.logger.Error("Failed to notify video owner", "error", err, "video_id", videoID, "user_id", video.UserID)
+	}
+	return video, nil
+}
+
+// Override releases a video that failed quality control
+func (s *qcService) Override(ctx context.Context, tenantID, userID, videoID, reason string) (*models.Video, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "a reason is required")
+	}
+	video, err := s.videos.GetByID(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if video.Status != string(models.StatusQCFailed) {
+		return nil, i18n.Errorf(models.ErrConflict, "the video did not fail quality control")
+	}
+
+	now := s.clock.Now()
+	video.Status = string(models.StatusReady)
+	video.QC.OverriddenBy = userID
+	video.QC.OverriddenAt = &now
+	video.QC.OverrideReason = reason
+	video.UpdatedAt = now
+	if err := s.videos.Update(ctx, video); err != nil {
+		return nil, fmt.Errorf("failed to save quality control override: %w", err)
+	}
+	s.logger.Info("Quality control overridden", "tenant_id", tenantID, "video_id", videoID, "user_id", userID, "reason", reason)
+	return video, nil
+}
+
+// qcReasons checks the measures of a video's transcoded file
+func qcReasons(video *models.Video, m *transcode.QCMeasures) []models.QCReason {
+	var reasons []models.QCReason
+	add := func(code string, measured, limit float64, format string, args ...interface{}) {
+		reasons = append(reasons, models.QCReason{Code: code, Message: fmt.Sprintf(format, args...), Measured: measured, Limit: limit})
+	}
+
+	for _, black := range m.Black {
+		if black.Start < 0.1 && black.End-black.Start > qcMaxBlackStart {
+			add(models.QCBlackStart, black.End-black.Start, qcMaxBlackStart,
+				"Opens on %.1f seconds of black frames", black.End-black.Start)
+		}
+	}
+
+	silent := 0.0
+	if m.DurationSeconds > 0 {
+		silent = transcode.Total(m.Silence) / m.DurationSeconds
+	}
+	switch {
+	case silent > qcMaxSilence:
+		add(models.QCSilentAudio, silent, qcMaxSilence, "Audio is silent for %.0f%% of the video", silent*100)
+	case m.IntegratedLUFS != nil && (*m.IntegratedLUFS < qcMinLoudness || *m.IntegratedLUFS > qcMaxLoudness):
+		limit := qcMinLoudness
+		if *m.IntegratedLUFS > qcMaxLoudness {
+			limit = qcMaxLoudness
+		}
+		add(models.QCLoudness, *m.IntegratedLUFS, limit,
+			"Integrated loudness of %.1f LUFS, outside %.0f to %.0f LUFS", *m.IntegratedLUFS, qcMinLoudness, qcMaxLoudness)
+	}
+
+	// The source's length, as probed or reported by the worker
+	source := float64(video.Duration)
+	if video.MediaInfo != nil && video.MediaInfo.DurationSeconds > 0 {
+		source = video.MediaInfo.DurationSeconds
+	}
+	if source > 0 {
+		gap := math.Max(qcMinDurationGap, source*qcDurationTolerance)
+		if math.Abs(m.DurationSeconds-source) > gap {
+			add(models.QCDurationMismatch, m.DurationSeconds, source,
+				"Lasts %.1f seconds where the source lasts %.1f", m.DurationSeconds, source)
+		}
+	}
+	return reasons
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// qcLog renders the ffmpeg log of a file lasting duration, with the given
+// loudness and filter lines
+func qcLog(duration string, lufs float64, lines ...string) []byte {
+	log := "  Duration: " + duration + ", start: 0.000000, bitrate: 8123 kb/s\n" +
+		"  Stream #0:0(und): Video: h264, yuv420p, 1920x1080\n  Stream #0:1(und): Audio: aac (LC), 48000 Hz, stereo\n"
+	for _, line := range lines {
+		log += line + "\n"
+	}
+	return []byte(log + fmt.Sprintf("[Parsed_ebur128_1 @ 0x1] Summary:\n\n  Integrated loudness:\n    I:         %.1f LUFS\n", lufs))
+}
+
+func TestQCService_Record(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		log     []byte
+		reasons []string
+	}{
+		{"clean", qcLog("00:01:00.00", -14,
+			"[blackdetect @ 0x1] black_start:0 black_end:1.5 black_duration:1.5"), nil},
+		{"black start", qcLog("00:01:00.00", -14,
+			"[blackdetect @ 0x1] black_start:0 black_end:4 black_duration:4"), []string{models.QCBlackStart}},
+		{"silent", qcLog("00:01:00.00", -70,
+			"[silencedetect @ 0x1] silence_start: 0"), []string{models.QCSilentAudio}},
+		{"too quiet", qcLog("00:01:00.00", -31.2), []string{models.QCLoudness}},
+		{"cut short", qcLog("00:00:52.00", -14), []string{models.QCDurationMismatch}},
+		{"within tolerance", qcLog("00:01:00.90", -14), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			videos := &publicationVideoRepo{videos: map[string]*models.Video{
+				"video-1": {ID: "video-1", TenantID: "acme", UserID: "editor", Status: string(models.StatusReady),
+					MediaInfo: &transcode.MediaInfo{DurationSeconds: 60}},
+			}}
+			notified := &recordingNotifications{subjects: map[string][]string{}}
+			svc := NewQCService(videos, notified, clock.NewFake(now), logger.New("error", "test"))
+
+			video, err := svc.Record(context.Background(), "acme", "video-1", tt.log)
+			require.NoError(t, err)
+			var codes []string
+			for _, reason := range video.QC.Reasons {
+				codes = append(codes, reason.Code)
+			}
+			assert.Equal(t, tt.reasons, codes)
+			assert.Equal(t, now, *video.QC.CheckedAt)
+			if tt.reasons == nil {
+				assert.Equal(t, string(models.StatusReady), video.Status)
+				assert.Empty(t, notified.subjects)
+			} else {
+				assert.Equal(t, string(models.StatusQCFailed), video.Status)
+				assert.Equal(t, []string{"Video failed quality control"}, notified.subjects["editor"])
+			}
+		})
+	}
+}
+
+func TestQCService_Override(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	videos := &publicationVideoRepo{videos: map[string]*models.Video{
+		"video-1": {ID: "video-1", TenantID: "acme", UserID: "editor", Status: string(models.StatusReady), Duration: 60},
+	}}
+	svc := NewQCService(videos, &recordingNotifications{subjects: map[string][]string{}}, clock.NewFake(now), logger.New("error", "test"))
+	ctx := context.Background()
+
+	_, err := svc.Override(ctx, "acme", "admin", "video-1", "Intentional silence")
+	assert.ErrorIs(t, err, models.ErrConflict, "nothing to override")
+
+	_, err = svc.Record(ctx, "acme", "video-1", qcLog("00:01:00.00", -70, "[silencedetect @ 0x1] silence_start: 0"))
+	require.NoError(t, err)
+	_, err = svc.Override(ctx, "acme", "admin", "video-1", "  ")
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	video, err := svc.Override(ctx, "acme", "admin", "video-1", "Intentional silence")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.StatusReady), video.Status)
+	assert.Equal(t, "admin", video.QC.OverriddenBy)
+	assert.Len(t, video.QC.Reasons, 1, "kept for the record")
+
+	// A file processed again is checked afresh
+	video, err = svc.Record(ctx, "acme", "video-1", qcLog("00:01:00.00", -14))
+	require.NoError(t, err)
+	assert.Empty(t, video.QC.OverriddenBy)
+	assert.Equal(t, string(models.StatusReady), video.Status)
+}
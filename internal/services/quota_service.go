@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// quotaService implements the QuotaService interface. Usage is counted in
+// the database so every replica charges the same daily budget.
+type quotaService struct {
+	usage          models.PlatformQuotaRepository
+	workspaces     models.WorkspaceRepository
+	limits         map[string]int64 // Daily units by platform
+	reservePercent int64
+	clock          clock.Clock
+	logger         *logger.Logger
+}
+
+var _ QuotaService = (*quotaService)(nil)
+
+// NewQuotaService creates a quota service budgeting the daily limits of the
+// platforms. Platforms without a limit, or without a partners.QuotaPolicy,
+// are not budgeted. reservePercent of each limit is kept for publishing.
+func NewQuotaService(usage models.PlatformQuotaRepository, workspaces models.WorkspaceRepository, limits map[string]int64, reservePercent int, clock clock.Clock, logger *logger.Logger) QuotaService {
+	return &quotaService{
+		usage:          usage,
+		workspaces:     workspaces,
+		limits:         limits,
+		reservePercent: int64(reservePercent),
+		clock:          clock,
+		logger:         logger,
+	}
+}
+
+// Reserve charges the operation's cost to the tenant's quota of the day
+func (s *quotaService) Reserve(ctx context.Context, tenantID, platform string, op partners.Operation) error {
+	policy, limit, ok := s.budgeted(platform)
+	if !ok {
+		return nil
+	}
+
+	day := policy.Day(s.clock.Now())
+	cost := policy.Costs[op]
+	reserved, err := s.usage.Reserve(ctx, tenantID, platform, day, cost, s.ceiling(limit, op.Priority()))
+	if err != nil {
+		return fmt.Errorf("failed to reserve %s quota: %w", platform, err)
+	}
+	if reserved {
+		return nil
+	}
+
+	// Refused: tell a spent quota from one kept for higher priorities
+	usage, err := s.usage.Get(ctx, tenantID, platform, day)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return fmt.Errorf("failed to read %s quota: %w", platform, err)
+	}
+	if usage != nil && (usage.Exhausted || usage.Units+cost > limit) {
+		return fmt.Errorf("%s %s: %w", platform, op, partners.ErrQuotaExceeded)
+	}
+	return fmt.Errorf("%s %s: %w", platform, op, partners.ErrQuotaDeferred)
+}
+
+// Exhausted marks the tenant's quota of the day spent
+func (s *quotaService) Exhausted(ctx context.Context, tenantID, platform string) error {
+	policy, _, ok := s.budgeted(platform)
+	if !ok {
+		return nil
+	}
+	if err := s.usage.Exhaust(ctx, tenantID, platform, policy.Day(s.clock.Now())); err != nil {
+		s.logger.Error("Failed to record exhausted platform quota", "error", err, "tenant_id", tenantID, "platform", platform)
+		return err
+	}
+	s.logger.Warn("Platform quota exhausted", "tenant_id", tenantID, "platform", platform)
+	return nil
+}
+
+// Connections lists the platforms with the user's connected workspaces and
+// the tenant's quota usage
+func (s *quotaService) Connections(ctx context.Context, tenantID, userID string) ([]*models.PlatformConnection, error) {
+	workspaces, err := s.workspaces.ListByUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	connections := make([]*models.PlatformConnection, 0, len(models.Platforms))
+	for _, platform := range models.Platforms {
+		conn := &models.PlatformConnection{Platform: platform, Workspaces: []string{}, NeedsReconnect: []string{}}
+		for _, ws := range workspaces {
+			if ws.Connected(platform) {
+				conn.Workspaces = append(conn.Workspaces, ws.ID)
+				if ws.ReconnectNeeded(platform) {
+					conn.NeedsReconnect = append(conn.NeedsReconnect, ws.ID)
+				}
+			}
+		}
+		conn.Connected = len(conn.Workspaces) > 0
+
+		if conn.Quota, err = s.state(ctx, tenantID, string(platform)); err != nil {
+			return nil, err
+		}
+		connections = append(connections, conn)
+	}
+	return connections, nil
+}
+
+// state returns the tenant's usage of the platform's quota today, or nil
+// when the platform is not budgeted
+func (s *quotaService) state(ctx context.Context, tenantID, platform string) (*models.PlatformQuotaState, error) {
+	policy, limit, ok := s.budgeted(platform)
+	if !ok {
+		return nil, nil
+	}
+
+	now := s.clock.Now()
+	state := &models.PlatformQuotaState{Limit: limit, ResetAt: policy.ResetAt(now), Deferred: []string{}}
+	usage, err := s.usage.Get(ctx, tenantID, platform, policy.Day(now))
+	switch {
+	case err == nil:
+		state.Used = usage.Units
+		state.Exhausted = usage.Exhausted
+	case !errors.Is(err, models.ErrNotFound):
+		return nil, fmt.Errorf("failed to read %s quota: %w", platform, err)
+	}
+
+	if !state.Exhausted {
+		state.Remaining = max(limit-state.Used, 0)
+	}
+	for _, op := range partners.Operations {
+		if state.Exhausted || state.Used+policy.Costs[op] > s.ceiling(limit, op.Priority()) {
+			state.Deferred = append(state.Deferred, string(op))
+		}
+	}
+	return state, nil
+}
+
+// budgeted returns the platform's quota policy and daily limit, if it has both
+func (s *quotaService) budgeted(platform string) (partners.QuotaPolicy, int64, bool) {
+	policy, ok := partners.QuotaPolicies[platform]
+	limit := s.limits[platform]
+	return policy, limit, ok && limit > 0
+}
+
+// ceiling returns the usage up to which operations of the priority may run:
+// the whole limit for publishing, half the reserve for comments and none of
+// it for syncs
+func (s *quotaService) ceiling(limit int64, priority partners.Priority) int64 {
+	reserve := limit * s.reservePercent / 100
+	switch priority {
+	case partners.PriorityHigh:
+		return limit
+	case partners.PriorityNormal:
+		return limit - reserve/2
+	default:
+		return limit - reserve
+	}
+}
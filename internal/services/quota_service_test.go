@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// memoryQuotaRepo keeps quota usage in memory, by tenant/platform/day
+type memoryQuotaRepo struct {
+	usage map[string]*models.PlatformQuotaUsage
+}
+
+func quotaKey(tenantID, platform string, day time.Time) string {
+	return tenantID + "/" + platform + "/" + day.Format(time.DateOnly)
+}
+
+func (r *memoryQuotaRepo) day(tenantID, platform string, day time.Time) *models.PlatformQuotaUsage {
+	key := quotaKey(tenantID, platform, day)
+	if r.usage[key] == nil {
+		r.usage[key] = &models.PlatformQuotaUsage{TenantID: tenantID, Platform: platform, Day: day}
+	}
+	return r.usage[key]
+}
+
+func (r *memoryQuotaRepo) Reserve(ctx context.Context, tenantID, platform string, day time.Time, units, ceiling int64) (bool, error) {
+	usage := r.day(tenantID, platform, day)
+	if usage.Exhausted || usage.Units+units > ceiling {
+		return false, nil
+	}
+	usage.Units += units
+	return true, nil
+}
+
+func (r *memoryQuotaRepo) Exhaust(ctx context.Context, tenantID, platform string, day time.Time) error {
+	r.day(tenantID, platform, day).Exhausted = true
+	return nil
+}
+
+func (r *memoryQuotaRepo) Get(ctx context.Context, tenantID, platform string, day time.Time) (*models.PlatformQuotaUsage, error) {
+	usage, ok := r.usage[quotaKey(tenantID, platform, day)]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return usage, nil
+}
+
+type connectionWorkspaceRepo struct {
+	models.WorkspaceRepository
+	workspaces []*models.Workspace
+}
+
+func (r *connectionWorkspaceRepo) ListByUser(ctx context.Context, tenantID, userID string) ([]*models.Workspace, error) {
+	return r.workspaces, nil
+}
+
+func newTestQuotaService() (QuotaService, *memoryQuotaRepo) {
+	repo := &memoryQuotaRepo{usage: map[string]*models.PlatformQuotaUsage{}}
+	workspaces := &connectionWorkspaceRepo{workspaces: []*models.Workspace{
+		{ID: "ws-1", CredentialsPath: "/creds/ws-1.json", TokenDir: "/tokens/ws-1"},
+		{ID: "ws-2", TikTokAppID: "app", TikTokSecret: "secret", NeedsReconnect: []string{"tiktok"}},
+	}}
+	// 22:00 on October 14 in Pacific time, YouTube's quota day
+	now := time.Date(2026, 10, 15, 5, 0, 0, 0, time.UTC)
+	svc := NewQuotaService(repo, workspaces, map[string]int64{"youtube": 10000}, 20, clock.NewFake(now), logger.New("error", "test"))
+	return svc, repo
+}
+
+func TestQuotaService_ReserveDefersLowPriorityWork(t *testing.T) {
+	svc, repo := newTestQuotaService()
+	ctx := context.Background()
+	day := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	repo.day("acme", "youtube", day).Units = 7999
+
+	// Syncs stop at the 20% reserve, comments halfway through it
+	require.NoError(t, svc.Reserve(ctx, "acme", "youtube", partners.OpSync))
+	assert.ErrorIs(t, svc.Reserve(ctx, "acme", "youtube", partners.OpSync), partners.ErrQuotaDeferred)
+	require.NoError(t, svc.Reserve(ctx, "acme", "youtube", partners.OpComment))
+	require.NoError(t, svc.Reserve(ctx, "acme", "youtube", partners.OpPublish))
+	assert.Equal(t, int64(8100), repo.day("acme", "youtube", day).Units)
+
+	repo.day("acme", "youtube", day).Units = 9990
+	assert.ErrorIs(t, svc.Reserve(ctx, "acme", "youtube", partners.OpPublish), partners.ErrQuotaExceeded)
+
+	// Other tenants and unbudgeted platforms are not affected
+	require.NoError(t, svc.Reserve(ctx, "globex", "youtube", partners.OpSync))
+	require.NoError(t, svc.Reserve(ctx, "acme", "tiktok", partners.OpSync))
+}
+
+func TestQuotaService_ExhaustedRefusesEverything(t *testing.T) {
+	svc, _ := newTestQuotaService()
+	ctx := context.Background()
+
+	require.NoError(t, svc.Exhausted(ctx, "acme", "youtube"))
+	assert.ErrorIs(t, svc.Reserve(ctx, "acme", "youtube", partners.OpUpload), partners.ErrQuotaExceeded)
+	assert.ErrorIs(t, svc.Reserve(ctx, "acme", "youtube", partners.OpSync), partners.ErrQuotaExceeded)
+}
+
+func TestQuotaService_Connections(t *testing.T) {
+	svc, repo := newTestQuotaService()
+	repo.day("acme", "youtube", time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)).Units = 8500
+
+	connections, err := svc.Connections(context.Background(), "acme", "user-1")
+	require.NoError(t, err)
+	require.Len(t, connections, len(models.Platforms))
+
+	youtube := connections[0]
+	assert.Equal(t, models.PlatformYouTube, youtube.Platform)
+	assert.True(t, youtube.Connected)
+	assert.Equal(t, []string{"ws-1"}, youtube.Workspaces)
+	require.NotNil(t, youtube.Quota)
+	assert.Equal(t, int64(8500), youtube.Quota.Used)
+	assert.Equal(t, int64(1500), youtube.Quota.Remaining)
+	assert.Equal(t, time.Date(2026, 10, 15, 7, 0, 0, 0, time.UTC), youtube.Quota.ResetAt.UTC())
+	assert.Equal(t, []string{"upload", "sync"}, youtube.Quota.Deferred)
+
+	tiktok := connections[1]
+	assert.True(t, tiktok.Connected)
+	assert.Nil(t, tiktok.Quota)
+	assert.Empty(t, youtube.NeedsReconnect)
+	assert.Equal(t, []string{"ws-2"}, tiktok.NeedsReconnect)
+	assert.False(t, connections[2].Connected)
+}
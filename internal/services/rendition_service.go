@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// renditionService implements the RenditionService interface
+type renditionService struct {
+	renditions models.VideoRenditionRepository
+	presets    EncodingPresetService
+	assets     AssetService
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	jobs       JobService
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ RenditionService = (*renditionService)(nil)
+
+// NewRenditionService creates a new rendition service planning renditions
+// with the tenant's encoding presets, intro and outro
+func NewRenditionService(renditions models.VideoRenditionRepository, presets EncodingPresetService, assets AssetService, videos models.VideoRepository, workspaces models.WorkspaceRepository, jobs JobService, clock clock.Clock, logger *logger.Logger) RenditionService {
+	return &renditionService{renditions: renditions, presets: presets, assets: assets, videos: videos, workspaces: workspaces, jobs: jobs, clock: clock, logger: logger}
+}
+
+// Plan marks a rendition of every profile a platform takes pending, sized
+// as the tenant tuned the profile and stitched with its intro and outro
+func (s *renditionService) Plan(ctx context.Context, tenantID, videoID string) ([]*models.VideoRendition, error) {
+	if _, err := s.videos.GetByID(ctx, tenantID, videoID); err != nil {
+		return nil, err
+	}
+	return s.plan(ctx, tenantID, videoID, platformProfiles())
+}
+
+// PlanDubbed marks the video's renditions carrying dubs pending again, for
+// the worker to mux its current audio tracks in. Videos without renditions
+// are left as they are.
+func (s *renditionService) PlanDubbed(ctx context.Context, tenantID, videoID string) ([]*models.VideoRendition, error) {
+	renditions, err := s.renditions.ListByVideo(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list renditions: %w", err)
+	}
+	var names []string
+	for _, rendition := range renditions {
+		if partners.MultiAudio(rendition.Profile) {
+			names = append(names, rendition.Profile)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	return s.plan(ctx, tenantID, videoID, names)
+}
+
+// plan marks the video's renditions of the profiles pending and starts its
+// rendition job
+func (s *renditionService) plan(ctx context.Context, tenantID, videoID string, names []string) ([]*models.VideoRendition, error) {
+	planned := make([]*models.VideoRendition, 0, len(names))
+	for _, name := range names {
+		profile, err := s.presets.Profile(ctx, tenantID, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s encoding preset: %w", name, err)
+		}
+		intro, outro, err := s.assets.Stitching(ctx, tenantID, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s intro and outro: %w", name, err)
+		}
+		rendition := &models.VideoRendition{
+			TenantID:         tenantID,
+			VideoID:          videoID,
+			Profile:          name,
+			Status:           string(models.RenditionPending),
+			Width:            profile.Width,
+			Height:           profile.Height,
+			VideoBitrateKbps: profile.VideoBitrateKbps,
+		}
+		if intro != nil {
+			rendition.IntroAssetID = intro.ID
+		}
+		if outro != nil {
+			rendition.OutroAssetID = outro.ID
+		}
+		if err := s.renditions.Upsert(ctx, rendition); err != nil {
+			return nil, fmt.Errorf("failed to plan %s rendition: %w", name, err)
+		}
+		planned = append(planned, rendition)
+	}
+	s.jobs.Start(ctx, tenantID, "", models.RenditionJob(videoID))
+	s.logger.Info("Video renditions planned", "tenant_id", tenantID, "video_id", videoID, "count", len(planned))
+	return planned, nil
+}
+
+// Complete records where the worker stored a transcoded rendition
+func (s *renditionService) Complete(ctx context.Context, tenantID, videoID, profile string, out *models.RenditionOutput) (*models.VideoRendition, error) {
+	if out.FilePath == "" && out.FileURL == "" && out.S3Key == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "a rendition needs a file path, URL or S3 key")
+	}
+	rendition, err := s.get(ctx, tenantID, videoID, profile)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	rendition.Status = string(models.RenditionReady)
+	rendition.FilePath = out.FilePath
+	rendition.FileURL = out.FileURL
+	rendition.S3Key = out.S3Key
+	rendition.FileSize = out.FileSize
+	rendition.ErrorMsg = ""
+	rendition.AudioLanguages = out.AudioLanguages
+	rendition.TranscodedAt = &now
+	if err := s.renditions.Upsert(ctx, rendition); err != nil {
+		return nil, fmt.Errorf("failed to save rendition: %w", err)
+	}
+	s.logger.Info("Video rendition transcoded", "tenant_id", tenantID, "video_id", videoID, "profile", profile, "file_size", out.FileSize)
+	s.trackProgress(ctx, tenantID, videoID)
+	return rendition, nil
+}
+
+// trackProgress reports the share of the video's renditions transcoded, and
+// the end of its rendition job once they all are
+func (s *renditionService) trackProgress(ctx context.Context, tenantID, videoID string) {
+	renditions, err := s.renditions.ListByVideo(ctx, tenantID, videoID)
+	if err != nil {
+		s.logger.Warn("Failed to count transcoded renditions", "error", err, "tenant_id", tenantID, "video_id", videoID)
+		return
+	}
+	ready := 0
+	for _, rendition := range renditions {
+		switch {
+		case rendition.Ready():
+			ready++
+		case rendition.Status == string(models.RenditionFailed):
+			return // The job failed with it
+		}
+	}
+	if ready == len(renditions) {
+		s.jobs.Succeed(ctx, tenantID, models.RenditionJob(videoID))
+		return
+	}
+	s.jobs.Progress(ctx, tenantID, models.RenditionJob(videoID), ready*100/len(renditions))
+}
+
+// Fail records why a rendition could not be transcoded. Publications to the
+// platforms taking it wait until it is planned and transcoded again.
+func (s *renditionService) Fail(ctx context.Context, tenantID, videoID, profile, reason string) error {
+	rendition, err := s.get(ctx, tenantID, videoID, profile)
+	if err != nil {
+		return err
+	}
+	rendition.Status = string(models.RenditionFailed)
+	rendition.ErrorMsg = reason
+	if err := s.renditions.Upsert(ctx, rendition); err != nil {
+		return fmt.Errorf("failed to save rendition: %w", err)
+	}
+	s.jobs.Fail(ctx, tenantID, models.RenditionJob(videoID), profile+": "+reason)
+	s.logger.Error("Video rendition failed", "tenant_id", tenantID, "video_id", videoID, "profile", profile, "reason", reason)
+	return nil
+}
+
+// List returns the video's renditions
+func (s *renditionService) List(ctx context.Context, viewer *models.User, videoID string) ([]*models.VideoRendition, error) {
+	if _, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID); err != nil {
+		return nil, err
+	}
+	renditions, err := s.renditions.ListByVideo(ctx, viewer.TenantID, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list renditions: %w", err)
+	}
+	return renditions, nil
+}
+
+// ForPlatform returns the rendition of the profile the platform takes. Videos
+// transcoded before the profile existed have none and are uploaded as they are.
+func (s *renditionService) ForPlatform(ctx context.Context, tenantID, videoID string, platform models.Platform) (*models.VideoRendition, error) {
+	profile := partners.PlatformCapabilities[platform].Rendition
+	rendition, err := s.renditions.Get(ctx, tenantID, videoID, profile)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s rendition: %w", profile, err)
+	}
+	if !rendition.Ready() {
+		return nil, fmt.Errorf("%w: %s is %s", models.ErrRenditionNotReady, profile, rendition.Status)
+	}
+	return rendition, nil
+}
+
+// get returns a planned rendition of a known profile
+func (s *renditionService) get(ctx context.Context, tenantID, videoID, profile string) (*models.VideoRendition, error) {
+	if _, ok := transcode.Profiles[profile]; !ok {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "unknown rendition profile %q", profile)
+	}
+	rendition, err := s.renditions.Get(ctx, tenantID, videoID, profile)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s rendition was not planned", models.ErrNotFound, profile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rendition: %w", err)
+	}
+	return rendition, nil
+}
+
+// platformProfiles returns the profiles the platforms take, once each
+func platformProfiles() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, platform := range models.Platforms {
+		name := partners.PlatformCapabilities[platform].Rendition
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// memoryRenditionRepo keeps one rendition per video and profile
+type memoryRenditionRepo struct {
+	models.VideoRenditionRepository
+	renditions map[string]models.VideoRendition
+}
+
+func (r *memoryRenditionRepo) Get(ctx context.Context, tenantID, videoID, profile string) (*models.VideoRendition, error) {
+	rendition, ok := r.renditions[videoID+"/"+profile]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &rendition, nil
+}
+
+func (r *memoryRenditionRepo) Upsert(ctx context.Context, rendition *models.VideoRendition) error {
+	r.renditions[rendition.VideoID+"/"+rendition.Profile] = *rendition
+	return nil
+}
+
+func (r *memoryRenditionRepo) ListByVideo(ctx context.Context, tenantID, videoID string) ([]*models.VideoRendition, error) {
+	var renditions []*models.VideoRendition
+	for _, rendition := range r.renditions {
+		if rendition.VideoID == videoID {
+			renditions = append(renditions, &rendition)
+		}
+	}
+	return renditions, nil
+}
+
+func TestRenditionService(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	repo := &memoryRenditionRepo{renditions: map[string]models.VideoRendition{}}
+	videos := &publicationVideoRepo{videos: map[string]*models.Video{"video-1": {ID: "video-1", TenantID: "acme"}}}
+	fake := clock.NewFake(now)
+	tracked, jobs := newTestJobs(fake)
+	presets := &memoryPresetRepo{presets: map[string]*models.EncodingPreset{
+		transcode.Landscape720p: {TenantID: "acme", Profile: transcode.Landscape720p, Width: 1920, Height: 1080, VideoBitrateKbps: 12000,
+			AudioBitrateKbps: 192, FrameRate: 60, VideoCodec: transcode.CodecH264, AudioCodec: transcode.CodecAAC},
+	}}
+	assetRepo, _, assets := newTestAssets(t, fake)
+	assetRepo.assets["intro-1"] = &models.Asset{ID: "intro-1", TenantID: "acme", Kind: models.AssetIntro, Status: models.AssetReady}
+	assetRepo.defaults = &models.AssetDefaults{TenantID: "acme", IntroAssetID: "intro-1", Profiles: []string{transcode.Vertical1080p}}
+	svc := NewRenditionService(repo, NewEncodingPresetService(presets, fake, logger.New("error", "test")), assets, videos, nil, jobs, fake, logger.New("error", "test"))
+	ctx := context.Background()
+
+	rendition, err := svc.ForPlatform(ctx, "acme", "video-1", models.PlatformTikTok)
+	require.NoError(t, err)
+	assert.Nil(t, rendition, "videos transcoded before the profiles are uploaded as they are")
+
+	planned, err := svc.Plan(ctx, "acme", "video-1")
+	require.NoError(t, err)
+	var profiles []string
+	for _, r := range planned {
+		profiles = append(profiles, r.Profile)
+		assert.Equal(t, string(models.RenditionPending), r.Status)
+	}
+	assert.Equal(t, []string{transcode.Landscape1080p, transcode.Landscape720p, transcode.Vertical1080p}, profiles)
+	assert.Equal(t, 1920, repo.renditions["video-1/"+transcode.Vertical1080p].Height)
+	assert.Equal(t, 12000, repo.renditions["video-1/"+transcode.Landscape720p].VideoBitrateKbps, "as the tenant tuned it")
+	assert.Equal(t, "intro-1", repo.renditions["video-1/"+transcode.Vertical1080p].IntroAssetID)
+	assert.Empty(t, repo.renditions["video-1/"+transcode.Landscape720p].IntroAssetID, "the intro is stitched onto the chosen profiles")
+
+	_, err = svc.ForPlatform(ctx, "acme", "video-1", models.PlatformTikTok)
+	assert.ErrorIs(t, err, models.ErrRenditionNotReady)
+
+	_, err = svc.Complete(ctx, "acme", "video-1", "square", &models.RenditionOutput{S3Key: "k"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Complete(ctx, "acme", "video-1", transcode.Vertical1080p, &models.RenditionOutput{})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Complete(ctx, "acme", "video-2", transcode.Vertical1080p, &models.RenditionOutput{S3Key: "k"})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = svc.Complete(ctx, "acme", "video-1", transcode.Vertical1080p,
+		&models.RenditionOutput{S3Key: "acme/video-1/vertical_1080p.mp4", FileSize: 42})
+	require.NoError(t, err)
+	rendition, err = svc.ForPlatform(ctx, "acme", "video-1", models.PlatformInstagram)
+	require.NoError(t, err)
+	assert.Equal(t, "acme/video-1/vertical_1080p.mp4", rendition.S3Key)
+	assert.Equal(t, now, *rendition.TranscodedAt)
+	require.Len(t, tracked.jobs, 1)
+	assert.Equal(t, string(models.JobRendition), tracked.jobs[0].Type)
+	assert.Equal(t, string(models.JobRunning), tracked.jobs[0].State)
+	assert.Equal(t, 33, tracked.jobs[0].Percent)
+
+	require.NoError(t, svc.Fail(ctx, "acme", "video-1", transcode.Landscape1080p, "ffmpeg exited with status 1"))
+	_, err = svc.ForPlatform(ctx, "acme", "video-1", models.PlatformYouTube)
+	assert.ErrorIs(t, err, models.ErrRenditionNotReady)
+	assert.Equal(t, string(models.JobFailed), tracked.jobs[0].State)
+	assert.Equal(t, transcode.Landscape1080p+": ffmpeg exited with status 1", tracked.jobs[0].Error)
+
+	_, err = svc.Plan(ctx, "acme", "video-2")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+}
+
+func TestRenditionService_PlanDubbed(t *testing.T) {
+	repo := &memoryRenditionRepo{renditions: map[string]models.VideoRendition{}}
+	videos := &publicationVideoRepo{videos: map[string]*models.Video{"video-1": {ID: "video-1", TenantID: "acme"}}}
+	fake := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	_, jobs := newTestJobs(fake)
+	presets := NewEncodingPresetService(&memoryPresetRepo{presets: map[string]*models.EncodingPreset{}}, fake, logger.New("error", "test"))
+	_, _, assets := newTestAssets(t, fake)
+	svc := NewRenditionService(repo, presets, assets, videos, nil, jobs, fake, logger.New("error", "test"))
+	ctx := context.Background()
+
+	planned, err := svc.PlanDubbed(ctx, "acme", "video-1")
+	require.NoError(t, err)
+	assert.Empty(t, planned, "videos not transcoded yet get the dubs with their first renditions")
+
+	_, err = svc.Plan(ctx, "acme", "video-1")
+	require.NoError(t, err)
+	for _, profile := range []string{transcode.Landscape1080p, transcode.Landscape720p, transcode.Vertical1080p} {
+		_, err := svc.Complete(ctx, "acme", "video-1", profile, &models.RenditionOutput{S3Key: profile + ".mp4"})
+		require.NoError(t, err)
+	}
+
+	planned, err = svc.PlanDubbed(ctx, "acme", "video-1")
+	require.NoError(t, err)
+	require.Len(t, planned, 1)
+	assert.Equal(t, transcode.Landscape1080p, planned[0].Profile, "YouTube takes it and offers a choice of audio tracks")
+	assert.Equal(t, string(models.RenditionPending), repo.renditions["video-1/"+transcode.Landscape1080p].Status)
+	assert.Equal(t, string(models.RenditionReady), repo.renditions["video-1/"+transcode.Vertical1080p].Status)
+
+	rendition, err := svc.Complete(ctx, "acme", "video-1", transcode.Landscape1080p,
+		&models.RenditionOutput{S3Key: "landscape_1080p.mp4", AudioLanguages: []string{"es", "fr"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"es", "fr"}, rendition.AudioLanguages)
+}
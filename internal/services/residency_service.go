@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/residency"
+)
+
+// residencyService implements the ResidencyService interface
+type residencyService struct {
+	tenants    models.TenantRepository
+	placements *residency.Placements
+	logger     *logger.Logger
+}
+
+var _ ResidencyService = (*residencyService)(nil)
+
+// NewResidencyService creates a new residency service instance
+func NewResidencyService(tenants models.TenantRepository, placements *residency.Placements, logger *logger.Logger) ResidencyService {
+	return &residencyService{
+		tenants:    tenants,
+		placements: placements,
+		logger:     logger,
+	}
+}
+
+// GetResidency returns the tenant's residency and placement. Tenants without
+// a tenant record use the default residency, unpinned.
+func (s *residencyService) GetResidency(ctx context.Context, tenantID string) (*TenantResidency, error) {
+	tenant, err := s.tenants.GetByID(ctx, tenantID)
+	if errors.Is(err, models.ErrTenantNotFound) {
+		tenant = &models.Tenant{ID: tenantID}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return s.resolve(tenant)
+}
+
+// UpdateResidency changes the tenant's residency or pin. Existing files are
+// not migrated: videos keep the bucket they were stored in. A pinned tenant
+// cannot change residency, since its data would then be written elsewhere;
+// it has to be unpinned first.
+func (s *residencyService) UpdateResidency(ctx context.Context, tenantID string, req *models.UpdateResidencyRequest) (*TenantResidency, error) {
+	tenant, err := s.tenants.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	current := residency.Residency(tenant.Residency)
+	if current == "" {
+		current = s.placements.Default()
+	}
+
+	if req.Residency != nil {
+		r, err := residency.Parse(*req.Residency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", models.ErrInvalidInput, err)
+		}
+		if _, err := s.placements.Get(r); err != nil {
+			return nil, fmt.Errorf("%w: %v", models.ErrInvalidInput, err)
+		}
+		if r != current && tenant.ResidencyPinned {
+			return nil, i18n.Errorf(residency.ErrCrossRegion, "tenant is pinned to %s", current)
+		}
+		tenant.Residency = string(r)
+	}
+	if req.Pinned != nil {
+		tenant.ResidencyPinned = *req.Pinned
+	}
+
+	if err := s.tenants.Update(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("failed to save tenant residency: %w", err)
+	}
+
+	s.logger.Info("Tenant residency updated",
+		"tenant_id", tenantID,
+		"residency", tenant.Residency,
+		"pinned", tenant.ResidencyPinned)
+	return s.resolve(tenant)
+}
+
+// ContextForTenant returns ctx carrying the tenant's residency scope. The
+// scope is set even when the residency is not configured here, so regional
+// clients refuse a pinned tenant instead of the whole request failing.
+func (s *residencyService) ContextForTenant(ctx context.Context, tenantID string) (context.Context, error) {
+	tenant, err := s.requestTenant(ctx, tenantID)
+	if errors.Is(err, models.ErrTenantNotFound) {
+		return residency.WithScope(ctx, residency.Scope{Residency: s.placements.Default()}), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	r := residency.Residency(tenant.Residency)
+	if r == "" {
+		r = s.placements.Default()
+	}
+	return residency.WithScope(ctx, residency.Scope{Residency: r, Pinned: tenant.ResidencyPinned}), nil
+}
+
+// requestTenant reads the tenant, from the request's Identity when it was
+// loaded already
+func (s *residencyService) requestTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	if identity, ok := IdentityFrom(ctx); ok && identity.User.TenantID == tenantID {
+		if identity.Tenant == nil {
+			return nil, models.ErrTenantNotFound
+		}
+		return identity.Tenant, nil
+	}
+	return s.tenants.GetByID(ctx, tenantID)
+}
+
+// resolve looks up the placement of the tenant's residency. A pinned tenant
+// whose residency is no longer configured is an error rather than a silent
+// move to the default.
+func (s *residencyService) resolve(tenant *models.Tenant) (*TenantResidency, error) {
+	r := residency.Residency(tenant.Residency)
+	if r == "" {
+		r = s.placements.Default()
+	}
+
+	placement, err := s.placements.Get(r)
+	if err != nil {
+		if tenant.ResidencyPinned {
+			return nil, fmt.Errorf("%w: tenant %s: %v", residency.ErrCrossRegion, tenant.ID, err)
+		}
+		s.logger.Warn("Tenant residency not configured, using default", "tenant_id", tenant.ID, "residency", r)
+		r = s.placements.Default()
+		placement, err = s.placements.Get(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &TenantResidency{
+		TenantID:  tenant.ID,
+		Residency: r,
+		Pinned:    tenant.ResidencyPinned,
+		Placement: placement,
+	}, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/residency"
+)
+
+// memoryTenantRepo stores tenants by ID
+type memoryTenantRepo struct {
+	models.TenantRepository
+	tenants map[string]*models.Tenant
+}
+
+func (r *memoryTenantRepo) GetByID(ctx context.Context, id string) (*models.Tenant, error) {
+	if t, ok := r.tenants[id]; ok {
+		copied := *t
+		return &copied, nil
+	}
+	return nil, models.ErrTenantNotFound
+}
+
+func (r *memoryTenantRepo) Update(ctx context.Context, tenant *models.Tenant) error {
+	copied := *tenant
+	r.tenants[tenant.ID] = &copied
+	return nil
+}
+
+func newTestPlacements(t *testing.T, placements ...residency.Placement) *residency.Placements {
+	t.Helper()
+	if len(placements) == 0 {
+		placements = []residency.Placement{
+			{Residency: residency.US, AWSRegion: "us-east-1", S3Bucket: "videos-bucket"},
+			{Residency: residency.EU, AWSRegion: "eu-west-1", S3Bucket: "videos-eu"},
+		}
+	}
+	p, err := residency.NewPlacements(residency.US, placements...)
+	require.NoError(t, err)
+	return p
+}
+
+func newResidencyFixture(t *testing.T) (*memoryTenantRepo, ResidencyService) {
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{
+		"tenant-1":  {ID: "tenant-1"},
+		"tenant-eu": {ID: "tenant-eu", Residency: "eu", ResidencyPinned: true},
+	}}
+	return tenants, NewResidencyService(tenants, newTestPlacements(t), logger.New("error", "test"))
+}
+
+func TestResidencyService_GetResidency(t *testing.T) {
+	_, svc := newResidencyFixture(t)
+	ctx := context.Background()
+
+	tr, err := svc.GetResidency(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, residency.US, tr.Residency)
+	assert.Equal(t, "videos-bucket", tr.Placement.S3Bucket)
+
+	tr, err = svc.GetResidency(ctx, "tenant-eu")
+	require.NoError(t, err)
+	assert.Equal(t, residency.EU, tr.Residency)
+	assert.True(t, tr.Pinned)
+	assert.Equal(t, "eu-west-1", tr.Placement.BedrockRegion)
+
+	tr, err = svc.GetResidency(ctx, "no-record")
+	require.NoError(t, err)
+	assert.Equal(t, residency.US, tr.Residency, "tenants without a record use the default")
+}
+
+func TestResidencyService_UpdateResidency(t *testing.T) {
+	tenants, svc := newResidencyFixture(t)
+	ctx := context.Background()
+	eu, us, pin, unpin := "eu", "us", true, false
+
+	tr, err := svc.UpdateResidency(ctx, "tenant-1", &models.UpdateResidencyRequest{Residency: &eu, Pinned: &pin})
+	require.NoError(t, err)
+	assert.Equal(t, residency.EU, tr.Residency)
+	assert.Equal(t, "eu", tenants.tenants["tenant-1"].Residency)
+	assert.True(t, tenants.tenants["tenant-1"].ResidencyPinned)
+
+	_, err = svc.UpdateResidency(ctx, "tenant-eu", &models.UpdateResidencyRequest{Residency: &us, Pinned: &unpin})
+	assert.ErrorIs(t, err, residency.ErrCrossRegion, "unpinning and moving at once is refused")
+	assert.Equal(t, "eu", tenants.tenants["tenant-eu"].Residency)
+
+	_, err = svc.UpdateResidency(ctx, "tenant-eu", &models.UpdateResidencyRequest{Pinned: &unpin})
+	require.NoError(t, err)
+	tr, err = svc.UpdateResidency(ctx, "tenant-eu", &models.UpdateResidencyRequest{Residency: &us})
+	require.NoError(t, err)
+	assert.Equal(t, residency.US, tr.Residency)
+
+	apac := "apac"
+	_, err = svc.UpdateResidency(ctx, "tenant-1", &models.UpdateResidencyRequest{Residency: &apac})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	_, err = svc.UpdateResidency(ctx, "no-record", &models.UpdateResidencyRequest{Residency: &eu})
+	assert.ErrorIs(t, err, models.ErrTenantNotFound)
+}
+
+func TestResidencyService_UpdateRejectsUnconfiguredResidency(t *testing.T) {
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{"tenant-1": {ID: "tenant-1"}}}
+	svc := NewResidencyService(tenants, newTestPlacements(t, residency.Placement{Residency: residency.US, AWSRegion: "us-east-1"}), logger.New("error", "test"))
+
+	eu := "eu"
+	_, err := svc.UpdateResidency(context.Background(), "tenant-1", &models.UpdateResidencyRequest{Residency: &eu})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+}
+
+func TestResidencyService_ContextForTenant(t *testing.T) {
+	_, svc := newResidencyFixture(t)
+
+	ctx, err := svc.ContextForTenant(context.Background(), "tenant-eu")
+	require.NoError(t, err)
+	scope, ok := residency.FromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, residency.Scope{Residency: residency.EU, Pinned: true}, scope)
+
+	ctx, err = svc.ContextForTenant(context.Background(), "no-record")
+	require.NoError(t, err)
+	scope, _ = residency.FromContext(ctx)
+	assert.Equal(t, residency.Scope{Residency: residency.US}, scope)
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// Drop-off detection
+const (
+	// dropOffWindow is the stretch of the video, as a fraction of its length,
+	// over which the audience lost is measured
+	dropOffWindow = 0.05
+	// dropOffMinDrop is the share of the audience a window must lose to be a
+	// drop-off; a steady decline loses about a third over a whole video, so
+	// under 2% per window
+	dropOffMinDrop = 0.05
+	// maxDropOffs keeps the steepest drop-offs of a curve
+	maxDropOffs = 5
+)
+
+// retentionService implements the RetentionService interface
+type retentionService struct {
+	curves     models.RetentionCurveRepository
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	clients    func(string) (partners.Client, error)
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ RetentionService = (*retentionService)(nil)
+
+// NewRetentionService creates a retention service fetching curves with the
+// platform clients
+func NewRetentionService(curves models.RetentionCurveRepository, videos models.VideoRepository, workspaces models.WorkspaceRepository, clients func(string) (partners.Client, error), clock clock.Clock, logger *logger.Logger) RetentionService {
+	return &retentionService{
+		curves:     curves,
+		videos:     videos,
+		workspaces: workspaces,
+		clients:    clients,
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+func (s *retentionService) GetRetention(ctx context.Context, viewer *models.User, videoID string) (*models.VideoRetention, error) {
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	curves, err := s.curves.ListByVideo(ctx, viewer.TenantID, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention curves: %w", err)
+	}
+	return videoRetention(video, curves), nil
+}
+
+// SyncRetention replaces the stored curve of each platform the video is
+// published on whose client reports retention. A platform failing stops the
+// sync; the curves fetched before it are kept.
+func (s *retentionService) SyncRetention(ctx context.Context, viewer *models.User, videoID, workspaceID string) (*models.VideoRetention, error) {
+	tenantID := viewer.TenantID
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := s.workspaces.GetByID(ctx, tenantID, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	synced := 0
+	for _, platform := range video.PublishedPlatforms() {
+		client, err := s.clients(string(platform))
+		if err != nil {
+			return nil, err
+		}
+		retention, ok := partners.Capability[partners.RetentionClient](client)
+		if !ok {
+			continue
+		}
+		if err := client.Authenticate(ctx, workspace); err != nil {
+			return nil, fmt.Errorf("failed to authenticate on %s: %w", platform, err)
+		}
+		points, err := retention.FetchRetention(ctx, video)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s retention: %w", platform, err)
+		}
+		curve := &models.RetentionCurve{TenantID: tenantID, VideoID: videoID, Platform: string(platform), Points: points, FetchedAt: s.clock.Now()}
+		if err := s.curves.Upsert(ctx, curve); err != nil {
+			return nil, fmt.Errorf("failed to save %s retention: %w", platform, err)
+		}
+		synced++
+	}
+	if synced == 0 {
+		return nil, models.ErrRetentionUnsupported
+	}
+	s.logger.Info("Retention synced", "tenant_id", tenantID, "video_id", videoID, "platforms", synced)
+	return s.GetRetention(ctx, viewer, videoID)
+}
+
+// videoRetention attaches its drop-offs to each curve
+func videoRetention(video *models.Video, curves []*models.RetentionCurve) *models.VideoRetention {
+	retention := &models.VideoRetention{VideoID: video.ID, Duration: video.Duration, Curves: make([]*models.PlatformRetention, 0, len(curves))}
+	for _, curve := range curves {
+		retention.Curves = append(retention.Curves, &models.PlatformRetention{
+			Platform:  curve.Platform,
+			Points:    curve.Points,
+			DropOffs:  detectDropOffs(curve.Points, video.Duration),
+			FetchedAt: curve.FetchedAt,
+		})
+	}
+	return retention
+}
+
+// detectDropOffs finds where the audience leaves fastest: windows of
+// dropOffWindow of the video losing at least dropOffMinDrop of it, each run
+// of consecutive such windows making one drop-off. The steepest maxDropOffs
+// are returned in the order they happen; seconds are set from the duration
+// when it is known.
+func detectDropOffs(points []models.RetentionPoint, duration int) []models.RetentionDropOff {
+	drops := []models.RetentionDropOff{}
+	inRun := false
+	for i := range points {
+		j := i + 1
+		for j < len(points) && points[j].Elapsed-points[i].Elapsed < dropOffWindow {
+			j++
+		}
+		if j == len(points) {
+			break
+		}
+		if points[i].Watching-points[j].Watching < dropOffMinDrop {
+			inRun = false
+			continue
+		}
+		if inRun {
+			last := &drops[len(drops)-1]
+			last.End = points[j].Elapsed
+			last.WatchingAfter = points[j].Watching
+			last.Drop = last.WatchingBefore - last.WatchingAfter
+			continue
+		}
+		inRun = true
+		drops = append(drops, models.RetentionDropOff{
+			Start:          points[i].Elapsed,
+			End:            points[j].Elapsed,
+			WatchingBefore: points[i].Watching,
+			WatchingAfter:  points[j].Watching,
+			Drop:           points[i].Watching - points[j].Watching,
+		})
+	}
+
+	if len(drops) > maxDropOffs {
+		sort.SliceStable(drops, func(a, b int) bool { return drops[a].Drop > drops[b].Drop })
+		drops = drops[:maxDropOffs]
+		sort.Slice(drops, func(a, b int) bool { return drops[a].Start < drops[b].Start })
+	}
+	for i := range drops {
+		drops[i].Drop = math.Round(drops[i].Drop*10000) / 10000
+		if duration > 0 {
+			start := int(math.Round(drops[i].Start * float64(duration)))
+			end := int(math.Round(drops[i].End * float64(duration)))
+			drops[i].StartSecond, drops[i].EndSecond = &start, &end
+		}
+	}
+	return drops
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// memoryCurveRepo keeps retention curves in memory by video and platform
+type memoryCurveRepo struct {
+	curves map[[2]string]*models.RetentionCurve
+}
+
+func (r *memoryCurveRepo) Upsert(ctx context.Context, curve *models.RetentionCurve) error {
+	r.curves[[2]string{curve.VideoID, curve.Platform}] = curve
+	return nil
+}
+
+func (r *memoryCurveRepo) ListByVideo(ctx context.Context, tenantID, videoID string) ([]*models.RetentionCurve, error) {
+	var curves []*models.RetentionCurve
+	for key, curve := range r.curves {
+		if key[0] == videoID {
+			curves = append(curves, curve)
+		}
+	}
+	return curves, nil
+}
+
+// retentionClient reports the same curve for every video
+type retentionClient struct {
+	partners.Client
+	points []models.RetentionPoint
+}
+
+func (c *retentionClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
+	return nil
+}
+
+func (c *retentionClient) FetchRetention(ctx context.Context, video *models.Video) ([]models.RetentionPoint, error) {
+	return c.points, nil
+}
+
+// retentionCurve declines steadily from 1 to 0.6, losing a further 0.2
+// between from and to
+func retentionCurve(from, to float64) []models.RetentionPoint {
+	var points []models.RetentionPoint
+	for i := 0; i <= 100; i++ {
+		elapsed := float64(i) / 100
+		watching := 1 - 0.4*elapsed
+		switch {
+		case elapsed >= to:
+			watching -= 0.2
+		case elapsed > from:
+			watching -= 0.2 * (elapsed - from) / (to - from)
+		}
+		points = append(points, models.RetentionPoint{Elapsed: elapsed, Watching: watching})
+	}
+	return points
+}
+
+func TestDetectDropOffs(t *testing.T) {
+	drops := detectDropOffs(retentionCurve(0.30, 0.33), 200)
+	require.Len(t, drops, 1)
+	// The windows catching part of the drop widen it
+	assert.True(t, drops[0].Start < 0.3 && drops[0].End > 0.33, "%+v", drops[0])
+	assert.InDelta(t, 0.25, drops[0].Drop, 0.01)
+	require.NotNil(t, drops[0].StartSecond)
+	assert.Equal(t, int(drops[0].Start*200+0.5), *drops[0].StartSecond)
+
+	// The steady decline alone is no drop-off, and seconds need the duration
+	assert.Empty(t, detectDropOffs(retentionCurve(2, 2), 0))
+	drops = detectDropOffs(retentionCurve(0.5, 0.52), 0)
+	require.Len(t, drops, 1)
+	assert.Nil(t, drops[0].StartSecond)
+}
+
+func TestDetectDropOffs_KeepsSteepestInOrder(t *testing.T) {
+	var points []models.RetentionPoint
+	watching := 1.0
+	// A step every tenth of the video, the fourth the smallest
+	steps := map[int]float64{10: 0.1, 20: 0.1, 30: 0.1, 40: 0.06, 50: 0.1, 60: 0.1, 70: 0.1}
+	for i := 0; i <= 100; i++ {
+		watching -= steps[i]
+		points = append(points, models.RetentionPoint{Elapsed: float64(i) / 100, Watching: watching})
+	}
+
+	drops := detectDropOffs(points, 0)
+	require.Len(t, drops, maxDropOffs)
+	for i := 1; i < len(drops); i++ {
+		assert.Less(t, drops[i-1].Start, drops[i].Start)
+		assert.GreaterOrEqual(t, drops[i].Drop, 0.1)
+	}
+}
+
+func TestRetentionService_SyncRetention(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	curves := &memoryCurveRepo{curves: map[[2]string]*models.RetentionCurve{}}
+	videos := &visibilityVideoRepo{videos: map[string]*models.Video{
+		"video-1": {ID: "video-1", TenantID: "acme", YouTubeID: "yt-1", TikTokID: "tt-1", Duration: 120},
+		"video-2": {ID: "video-2", TenantID: "acme", TikTokID: "tt-2"},
+		"private": {ID: "private", TenantID: "acme", UserID: "other", YouTubeID: "yt-3", Visibility: models.VisibilityOwner},
+	}}
+	youtube := &retentionClient{points: retentionCurve(0.1, 0.12)}
+	clients := func(platform string) (partners.Client, error) {
+		if platform == string(models.PlatformYouTube) {
+			return youtube, nil
+		}
+		return &historyClient{}, nil
+	}
+	svc := NewRetentionService(curves, videos, &backfillWorkspaceRepo{}, clients, clock.NewFake(now), logger.New("error", "test"))
+	ctx := context.Background()
+	viewer := &models.User{ID: "editor", TenantID: "acme"}
+
+	retention, err := svc.SyncRetention(ctx, viewer, "video-1", "ws-1")
+	require.NoError(t, err)
+	assert.Equal(t, 120, retention.Duration)
+	require.Len(t, retention.Curves, 1)
+	assert.Equal(t, "youtube", retention.Curves[0].Platform)
+	assert.Equal(t, now, retention.Curves[0].FetchedAt)
+	assert.Len(t, retention.Curves[0].Points, 101)
+	require.Len(t, retention.Curves[0].DropOffs, 1)
+	assert.NotNil(t, retention.Curves[0].DropOffs[0].EndSecond)
+
+	stored, err := svc.GetRetention(ctx, viewer, "video-1")
+	require.NoError(t, err)
+	assert.Equal(t, retention, stored)
+
+	_, err = svc.SyncRetention(ctx, viewer, "video-2", "ws-1")
+	assert.ErrorIs(t, err, models.ErrRetentionUnsupported)
+	_, err = svc.SyncRetention(ctx, viewer, "video-1", "ws-2")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	_, err = svc.GetRetention(ctx, viewer, "video-gone")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+	_, err = svc.GetRetention(ctx, viewer, "private")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound, "videos the viewer does not see are not found")
+	_, err = svc.SyncRetention(ctx, viewer, "private", "ws-1")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+const (
+	// maxRightsReportDays bounds the window of the expiring rights report
+	maxRightsReportDays = 365
+	// maxRightsReportVideos bounds the videos listed by the report
+	maxRightsReportVideos = 500
+)
+
+// rightsService implements the RightsService interface
+type rightsService struct {
+	videos models.VideoRepository
+	clock  clock.Clock
+	logger *logger.Logger
+}
+
+var _ RightsService = (*rightsService)(nil)
+
+// NewRightsService creates a new rights service
+func NewRightsService(videos models.VideoRepository, clock clock.Clock, logger *logger.Logger) RightsService {
+	return &rightsService{videos: videos, clock: clock, logger: logger}
+}
+
+// Expiring lists the videos whose rights expire within days of now, either way
+func (s *rightsService) Expiring(ctx context.Context, tenantID string, days int) (*RightsReport, error) {
+	if days < 1 || days > maxRightsReportDays {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "days must be between 1 and %d", maxRightsReportDays)
+	}
+	now := s.clock.Now()
+	window := time.Duration(days) * 24 * time.Hour
+	report := &RightsReport{Days: days, From: now.Add(-window), To: now.Add(window), Videos: []*ExpiringRights{}}
+
+	videos, err := s.videos.ListRightsExpiring(ctx, tenantID, report.From, report.To, maxRightsReportVideos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos with expiring rights: %w", err)
+	}
+	for _, video := range videos {
+		left := video.Rights.ExpiresAt.Sub(now)
+		report.Videos = append(report.Videos, &ExpiringRights{
+			VideoID:     video.ID,
+			Title:       video.Title,
+			Rights:      video.Rights,
+			Expired:     left <= 0,
+			DaysLeft:    int(math.Floor(left.Hours() / 24)),
+			PublishedOn: video.PublishedPlatforms(),
+		})
+	}
+	return report, nil
+}
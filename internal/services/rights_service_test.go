@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// rightsVideoRepo returns its videos whose rights expire in the window
+type rightsVideoRepo struct {
+	models.VideoRepository
+	videos []*models.Video
+}
+
+func (r *rightsVideoRepo) ListRightsExpiring(ctx context.Context, tenantID string, from, to time.Time, limit int) ([]*models.Video, error) {
+	var out []*models.Video
+	for _, v := range r.videos {
+		if at := v.Rights.ExpiresAt; at != nil && !at.Before(from) && at.Before(to) {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func TestRightsService_Expiring(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { t := now.Add(d); return &t }
+	repo := &rightsVideoRepo{videos: []*models.Video{
+		{ID: "lapsed", Title: "Flannan Isles", YouTubeID: "yt-1", FacebookID: "fb-1",
+			Rights: models.VideoRights{Owner: "Archive Films Ltd", LicenseType: models.LicenseExclusive, ExpiresAt: at(-36 * time.Hour)}},
+		{ID: "soon", Title: "Dyatlov Pass", Rights: models.VideoRights{ExpiresAt: at(10*24*time.Hour + time.Hour)}},
+		{ID: "later", Rights: models.VideoRights{ExpiresAt: at(90 * 24 * time.Hour)}},
+		{ID: "perpetual"},
+	}}
+	svc := NewRightsService(repo, clock.NewFake(now), logger.New("error", "test"))
+
+	report, err := svc.Expiring(context.Background(), "acme", 30)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 30), report.To)
+	require.Len(t, report.Videos, 2)
+
+	lapsed := report.Videos[0]
+	assert.True(t, lapsed.Expired)
+	assert.Equal(t, -2, lapsed.DaysLeft)
+	assert.Equal(t, []models.Platform{models.PlatformFacebook, models.PlatformYouTube}, lapsed.PublishedOn)
+	assert.Equal(t, "Archive Films Ltd", lapsed.Rights.Owner)
+
+	soon := report.Videos[1]
+	assert.False(t, soon.Expired)
+	assert.Equal(t, 10, soon.DaysLeft)
+	assert.Empty(t, soon.PublishedOn)
+
+	_, err = svc.Expiring(context.Background(), "acme", 0)
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+}
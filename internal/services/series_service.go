@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	pkgpartners "github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// seriesService implements the SeriesService interface
+type seriesService struct {
+	series     models.SeriesRepository
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	clients    func(string) (pkgpartners.Client, error)
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ SeriesService = (*seriesService)(nil)
+
+// NewSeriesService creates a new series service instance, creating the
+// series' playlists with the given platform clients
+func NewSeriesService(series models.SeriesRepository, videos models.VideoRepository, workspaces models.WorkspaceRepository, clients func(string) (pkgpartners.Client, error), clock clock.Clock, logger *logger.Logger) SeriesService {
+	return &seriesService{
+		series:     series,
+		videos:     videos,
+		workspaces: workspaces,
+		clients:    clients,
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+// List returns the tenant's series
+func (s *seriesService) List(ctx context.Context, tenantID string) ([]*models.Series, error) {
+	series, err := s.series.List(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list series: %w", err)
+	}
+	return series, nil
+}
+
+// Get returns a series of the tenant
+func (s *seriesService) Get(ctx context.Context, tenantID, id string) (*models.Series, error) {
+	return s.series.GetByID(ctx, tenantID, id)
+}
+
+// Create adds a series to the tenant
+func (s *seriesService) Create(ctx context.Context, tenantID, userID string, req *models.SeriesRequest) (*models.Series, error) {
+	series := &models.Series{TenantID: tenantID, CreatedBy: userID}
+	if err := s.apply(ctx, series, req); err != nil {
+		return nil, err
+	}
+	if err := s.series.Create(ctx, series); err != nil {
+		return nil, fmt.Errorf("failed to create series: %w", err)
+	}
+
+	s.logger.Info("Series created", "tenant_id", tenantID, "user_id", userID, "series_id", series.ID)
+	return series, nil
+}
+
+// Update replaces the settings of a series. Episodes keep their numbers;
+// the pattern applies to the next publications.
+func (s *seriesService) Update(ctx context.Context, tenantID, id string, req *models.SeriesRequest) (*models.Series, error) {
+	series, err := s.series.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, series, req); err != nil {
+		return nil, err
+	}
+	series.UpdatedAt = s.clock.Now()
+	if err := s.series.Update(ctx, series); err != nil {
+		return nil, fmt.Errorf("failed to update series: %w", err)
+	}
+
+	s.logger.Info("Series updated", "tenant_id", tenantID, "series_id", id)
+	return series, nil
+}
+
+// apply sets the request on the series and validates it
+func (s *seriesService) apply(ctx context.Context, series *models.Series, req *models.SeriesRequest) error {
+	if series.PlaylistID != "" && req.WorkspaceID != series.WorkspaceID {
+		return i18n.Errorf(models.ErrConflict, "the series' playlist is already on the channel of workspace %s", series.WorkspaceID)
+	}
+	if req.WorkspaceID != "" && req.WorkspaceID != series.WorkspaceID {
+		if _, err := s.workspaces.GetByID(ctx, series.TenantID, req.WorkspaceID); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return i18n.Errorf(models.ErrInvalidInput, "workspace %s not found", req.WorkspaceID)
+			}
+			return fmt.Errorf("failed to get workspace: %w", err)
+		}
+	}
+
+	series.Name = req.Name
+	series.TitlePattern = req.TitlePattern
+	series.WorkspaceID = req.WorkspaceID
+	series.Season = req.Season
+	if series.Season == 0 {
+		series.Season = 1
+	}
+	return series.Validate()
+}
+
+// Delete removes a series, leaving its videos and playlist as they are
+func (s *seriesService) Delete(ctx context.Context, tenantID, id string) error {
+	if err := s.series.Delete(ctx, tenantID, id); err != nil {
+		return err
+	}
+	s.logger.Info("Series deleted", "tenant_id", tenantID, "series_id", id)
+	return nil
+}
+
+// AddEpisode numbers a video after the last episode of the season
+func (s *seriesService) AddEpisode(ctx context.Context, tenantID, seriesID string, req *models.AddEpisodeRequest) (*models.Video, error) {
+	series, err := s.series.GetByID(ctx, tenantID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	video, err := s.videos.GetByID(ctx, tenantID, req.VideoID)
+	if err != nil {
+		return nil, err
+	}
+	if video.Episode.SeriesID != "" {
+		return nil, i18n.Errorf(models.ErrConflict, "the video is already an episode of a series")
+	}
+	season := req.Season
+	if season == 0 {
+		season = series.Season
+	}
+	if season < 1 {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "season must be 1 or more")
+	}
+
+	number, err := s.series.AddEpisode(ctx, tenantID, seriesID, video.ID, season)
+	if err != nil {
+		return nil, err
+	}
+	video.Episode = models.VideoEpisode{SeriesID: seriesID, Season: season, Number: number}
+
+	s.logger.Info("Episode added", "tenant_id", tenantID, "series_id", seriesID, "video_id", video.ID, "season", season, "episode", number)
+	return video, nil
+}
+
+// Episodes returns the videos of a series in order
+func (s *seriesService) Episodes(ctx context.Context, tenantID, seriesID string) ([]*models.Video, error) {
+	if _, err := s.series.GetByID(ctx, tenantID, seriesID); err != nil {
+		return nil, err
+	}
+	videos, err := s.series.ListEpisodes(ctx, tenantID, seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list episodes: %w", err)
+	}
+	return videos, nil
+}
+
+// Prepare renders the title of an episode from the current pattern of its
+// series
+func (s *seriesService) Prepare(ctx context.Context, video *models.Video) error {
+	if video.Episode.SeriesID == "" {
+		return nil
+	}
+	series, err := s.series.GetByID(ctx, video.TenantID, video.Episode.SeriesID)
+	if err != nil {
+		return fmt.Errorf("failed to get series: %w", err)
+	}
+	video.Episode.Title = series.EpisodeTitle(video)
+	return nil
+}
+
+// Released adds an episode released on YouTube to the playlist of its
+// series. A failure is logged: the publication itself went through.
+func (s *seriesService) Released(ctx context.Context, job *models.PublicationJob, video *models.Video, ws *models.Workspace) {
+	if video.Episode.SeriesID == "" || video.Episode.Playlisted || models.Platform(job.Platform) != models.PlatformYouTube {
+		return
+	}
+	if err := s.playlist(ctx, video, ws); err != nil {
+		s.logger.Error("Failed to add episode to series playlist", "error", err, "tenant_id", video.TenantID,
+			"series_id", video.Episode.SeriesID, "video_id", video.ID, "workspace_id", ws.ID)
+	}
+}
+
+// playlist inserts the video among the episodes already in the playlist,
+// creating the playlist on the workspace's channel with the first of them
+func (s *seriesService) playlist(ctx context.Context, video *models.Video, ws *models.Workspace) error {
+	ctx = context.WithoutCancel(ctx)
+	series, err := s.series.GetByID(ctx, video.TenantID, video.Episode.SeriesID)
+	if err != nil {
+		return fmt.Errorf("failed to get series: %w", err)
+	}
+	if series.WorkspaceID != "" && series.WorkspaceID != ws.ID {
+		return nil // Released on another channel than the playlist's
+	}
+	client, err := s.clients(string(models.PlatformYouTube))
+	if err != nil {
+		return err
+	}
+	playlists, ok := pkgpartners.Capability[pkgpartners.PlaylistClient](client)
+	if !ok {
+		return nil
+	}
+	if err := client.Authenticate(ctx, ws); err != nil {
+		return fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	if series.PlaylistID == "" {
+		series.PlaylistID, err = playlists.CreatePlaylist(ctx, series.Name, "")
+		if err != nil {
+			return fmt.Errorf("failed to create playlist: %w", err)
+		}
+		series.WorkspaceID = ws.ID
+		series.UpdatedAt = s.clock.Now()
+		if err := s.series.Update(ctx, series); err != nil {
+			return fmt.Errorf("failed to save series playlist: %w", err)
+		}
+		s.logger.Info("Series playlist created", "tenant_id", series.TenantID, "series_id", series.ID, "playlist_id", series.PlaylistID)
+	}
+
+	// Episodes released out of order go between those around them
+	episodes, err := s.series.ListEpisodes(ctx, video.TenantID, series.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list episodes: %w", err)
+	}
+	position := 0
+	for _, episode := range episodes {
+		if episode.Episode.Playlisted && episode.Episode.Before(video.Episode) {
+			position++
+		}
+	}
+	if err := playlists.InsertPlaylistItem(ctx, series.PlaylistID, video.YouTubeID, position); err != nil {
+		return fmt.Errorf("failed to insert playlist item: %w", err)
+	}
+	video.Episode.Playlisted = true
+	if err := s.videos.Update(ctx, video); err != nil {
+		return fmt.Errorf("failed to save playlisted episode: %w", err)
+	}
+	return nil
+}
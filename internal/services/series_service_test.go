@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	pkgpartners "github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// memorySeriesRepo keeps series in memory and finds their episodes among the
+// videos of a publicationVideoRepo
+type memorySeriesRepo struct {
+	models.SeriesRepository
+	series []*models.Series
+	videos *publicationVideoRepo
+}
+
+func (r *memorySeriesRepo) Create(ctx context.Context, series *models.Series) error {
+	series.ID = fmt.Sprintf("series-%d", len(r.series)+1)
+	r.series = append(r.series, series)
+	return nil
+}
+
+func (r *memorySeriesRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Series, error) {
+	for _, series := range r.series {
+		if series.TenantID == tenantID && series.ID == id {
+			return series, nil
+		}
+	}
+	return nil, models.ErrSeriesNotFound
+}
+
+func (r *memorySeriesRepo) Update(ctx context.Context, series *models.Series) error {
+	return nil
+}
+
+func (r *memorySeriesRepo) AddEpisode(ctx context.Context, tenantID, seriesID, videoID string, season int) (int, error) {
+	number := 1
+	for _, video := range r.videos.videos {
+		if video.Episode.SeriesID == seriesID && video.Episode.Season == season && video.Episode.Number >= number {
+			number = video.Episode.Number + 1
+		}
+	}
+	r.videos.videos[videoID].Episode = models.VideoEpisode{SeriesID: seriesID, Season: season, Number: number}
+	return number, nil
+}
+
+func (r *memorySeriesRepo) ListEpisodes(ctx context.Context, tenantID, seriesID string) ([]*models.Video, error) {
+	var videos []*models.Video
+	for _, video := range r.videos.videos {
+		if video.Episode.SeriesID == seriesID {
+			videos = append(videos, video)
+		}
+	}
+	return videos, nil
+}
+
+// playlistClient records the playlists and the positions videos are
+// inserted at
+type playlistClient struct {
+	pkgpartners.Client
+	playlists []string
+	inserts   []string
+}
+
+func (c *playlistClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
+	return nil
+}
+
+func (c *playlistClient) CreatePlaylist(ctx context.Context, title, description string) (string, error) {
+	c.playlists = append(c.playlists, title)
+	return "PL-1", nil
+}
+
+func (c *playlistClient) InsertPlaylistItem(ctx context.Context, playlistID, videoID string, position int) error {
+	c.inserts = append(c.inserts, fmt.Sprintf("%s %s@%d", playlistID, videoID, position))
+	return nil
+}
+
+func newSeriesFixture() (SeriesService, *memorySeriesRepo, *playlistClient) {
+	videos := &publicationVideoRepo{videos: map[string]*models.Video{}}
+	for _, id := range []string{"pilot", "second", "third", "other"} {
+		videos.videos[id] = &models.Video{ID: id, TenantID: "acme", Title: id, YouTubeID: "yt-" + id}
+	}
+	repo := &memorySeriesRepo{videos: videos}
+	client := &playlistClient{}
+	svc := NewSeriesService(repo, videos, &publicationWorkspaceRepo{}, func(string) (pkgpartners.Client, error) { return client, nil },
+		clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)), logger.New("error", "test"))
+	return svc, repo, client
+}
+
+func TestSeriesService_CreateAndAddEpisodes(t *testing.T) {
+	svc, _, _ := newSeriesFixture()
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, "acme", "editor", &models.SeriesRequest{Name: "Cold cases", TitlePattern: "{{episode}} - {{plot}}"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	series, err := svc.Create(ctx, "acme", "editor", &models.SeriesRequest{Name: "Cold cases", TitlePattern: "S{{season}}E{{episode:2}}: {{title}}"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, series.Season)
+
+	pilot, err := svc.AddEpisode(ctx, "acme", series.ID, &models.AddEpisodeRequest{VideoID: "pilot"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, pilot.Episode.Number)
+	second, err := svc.AddEpisode(ctx, "acme", series.ID, &models.AddEpisodeRequest{VideoID: "second"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Episode.Number)
+	next, err := svc.AddEpisode(ctx, "acme", series.ID, &models.AddEpisodeRequest{VideoID: "third", Season: 2})
+	require.NoError(t, err)
+	assert.Equal(t, models.VideoEpisode{SeriesID: series.ID, Season: 2, Number: 1}, next.Episode, "numbered again each season")
+
+	_, err = svc.AddEpisode(ctx, "acme", series.ID, &models.AddEpisodeRequest{VideoID: "pilot"})
+	assert.ErrorIs(t, err, models.ErrConflict)
+	_, err = svc.AddEpisode(ctx, "acme", "missing", &models.AddEpisodeRequest{VideoID: "other"})
+	assert.ErrorIs(t, err, models.ErrSeriesNotFound)
+
+	require.NoError(t, svc.Prepare(ctx, second))
+	assert.Equal(t, "S1E02: second", second.PublishTitle())
+}
+
+func TestSeriesService_ReleasedKeepsPlaylistInOrder(t *testing.T) {
+	svc, repo, client := newSeriesFixture()
+	ctx := context.Background()
+	repo.series = []*models.Series{{ID: "cold-cases", TenantID: "acme", Name: "Cold cases", Season: 1}}
+	for number, id := range []string{"pilot", "second", "third"} {
+		repo.videos.videos[id].Episode = models.VideoEpisode{SeriesID: "cold-cases", Season: 1, Number: number + 1}
+	}
+	ws := &models.Workspace{ID: "ws-1", TenantID: "acme"}
+	release := func(id string, platform models.Platform) {
+		svc.Released(ctx, &models.PublicationJob{TenantID: "acme", Platform: string(platform)}, repo.videos.videos[id], ws)
+	}
+
+	release("third", models.PlatformYouTube)
+	release("pilot", models.PlatformYouTube)
+	release("second", models.PlatformTikTok)
+	release("second", models.PlatformYouTube)
+	release("second", models.PlatformYouTube)
+
+	assert.Equal(t, []string{"Cold cases"}, client.playlists, "created with the first episode")
+	assert.Equal(t, []string{"PL-1 yt-third@0", "PL-1 yt-pilot@0", "PL-1 yt-second@1"}, client.inserts)
+	assert.Equal(t, "ws-1", repo.series[0].WorkspaceID)
+
+	// Episodes released on another channel stay out of the playlist
+	repo.videos.videos["other"].Episode = models.VideoEpisode{SeriesID: "cold-cases", Season: 1, Number: 4}
+	svc.Released(ctx, &models.PublicationJob{TenantID: "acme", Platform: "youtube"}, repo.videos.videos["other"], &models.Workspace{ID: "ws-2", TenantID: "acme"})
+	assert.Len(t, client.inserts, 3)
+	assert.False(t, repo.videos.videos["other"].Episode.Playlisted)
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// maxSmartListsPerUser bounds the lists a user saves
+const maxSmartListsPerUser = 100
+
+// smartListService implements the SmartListService interface
+type smartListService struct {
+	lists  models.SmartListRepository
+	users  models.UserRepository
+	videos VideoService
+	logger *logger.Logger
+}
+
+var _ SmartListService = (*smartListService)(nil)
+
+// NewSmartListService creates a smart list service matching videos through
+// the video service, so lists only hold what their user sees
+func NewSmartListService(lists models.SmartListRepository, users models.UserRepository, videos VideoService, logger *logger.Logger) SmartListService {
+	return &smartListService{lists: lists, users: users, videos: videos, logger: logger}
+}
+
+// CreateList saves the filter under a name once it parses
+func (s *smartListService) CreateList(ctx context.Context, tenantID, userID string, req *models.SmartListRequest) (*models.SmartList, error) {
+	if _, err := models.ParseSmartListFilter(req.Filter); err != nil {
+		return nil, err
+	}
+	count, err := s.lists.CountByUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count smart lists: %w", err)
+	}
+	if count >= maxSmartListsPerUser {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "at most %d smart lists per user", maxSmartListsPerUser)
+	}
+
+	list := &models.SmartList{TenantID: tenantID, UserID: userID, Name: req.Name, Filter: req.Filter}
+	if err := s.lists.Create(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to create smart list: %w", err)
+	}
+	return list, nil
+}
+
+func (s *smartListService) GetList(ctx context.Context, tenantID, userID, id string) (*models.SmartList, error) {
+	return s.lists.Get(ctx, tenantID, userID, id)
+}
+
+func (s *smartListService) ListLists(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.SmartList, error) {
+	lists, err := s.lists.List(ctx, tenantID, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list smart lists: %w", err)
+	}
+	return lists, nil
+}
+
+// UpdateList replaces the name and filter of the list
+func (s *smartListService) UpdateList(ctx context.Context, tenantID, userID, id string, req *models.SmartListRequest) (*models.SmartList, error) {
+	list, err := s.lists.Get(ctx, tenantID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := models.ParseSmartListFilter(req.Filter); err != nil {
+		return nil, err
+	}
+	list.Name = req.Name
+	list.Filter = req.Filter
+	if err := s.lists.Update(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to update smart list: %w", err)
+	}
+	return list, nil
+}
+
+func (s *smartListService) DeleteList(ctx context.Context, tenantID, userID, id string) error {
+	return s.lists.Delete(ctx, tenantID, userID, id)
+}
+
+// ListVideos matches the viewer's list against the videos they see now
+func (s *smartListService) ListVideos(ctx context.Context, viewer *models.User, id string, limit, offset int) ([]*models.Video, error) {
+	list, err := s.lists.Get(ctx, viewer.TenantID, viewer.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	// Lists saved before a field or value was dropped no longer parse
+	conditions, err := models.ParseSmartListFilter(list.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.videos.ListMatching(ctx, viewer, conditions, limit, offset)
+}
+
+// VideoIDs loads the list's user so background operations match what they
+// see, as when they list the videos themselves
+func (s *smartListService) VideoIDs(ctx context.Context, tenantID, userID, id string, limit int) ([]string, error) {
+	user, err := s.users.GetByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	videos, err := s.ListVideos(ctx, user, id, limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(videos))
+	for i, video := range videos {
+		ids[i] = video.ID
+	}
+	return ids, nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memorySmartListRepo stores smart lists in memory
+type memorySmartListRepo struct {
+	lists []*models.SmartList
+}
+
+func (r *memorySmartListRepo) Create(ctx context.Context, list *models.SmartList) error {
+	list.ID = fmt.Sprintf("list-%d", len(r.lists)+1)
+	r.lists = append(r.lists, list)
+	return nil
+}
+
+func (r *memorySmartListRepo) Get(ctx context.Context, tenantID, userID, id string) (*models.SmartList, error) {
+	for _, list := range r.lists {
+		if list.TenantID == tenantID && list.UserID == userID && list.ID == id {
+			copied := *list
+			return &copied, nil
+		}
+	}
+	return nil, models.ErrSmartListNotFound
+}
+
+func (r *memorySmartListRepo) List(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.SmartList, error) {
+	var lists []*models.SmartList
+	for _, list := range r.lists {
+		if list.TenantID == tenantID && list.UserID == userID {
+			lists = append(lists, list)
+		}
+	}
+	return lists, nil
+}
+
+func (r *memorySmartListRepo) CountByUser(ctx context.Context, tenantID, userID string) (int64, error) {
+	lists, _ := r.List(ctx, tenantID, userID, 0, 0)
+	return int64(len(lists)), nil
+}
+
+func (r *memorySmartListRepo) Update(ctx context.Context, list *models.SmartList) error {
+	for i, stored := range r.lists {
+		if stored.ID == list.ID {
+			r.lists[i] = list
+		}
+	}
+	return nil
+}
+
+func (r *memorySmartListRepo) Delete(ctx context.Context, tenantID, userID, id string) error {
+	return nil
+}
+
+// matchingVideoService records the conditions it is asked to match and the
+// viewer asking
+type matchingVideoService struct {
+	VideoService
+	viewer     *models.User
+	conditions []models.SmartListCondition
+}
+
+func (s *matchingVideoService) ListMatching(ctx context.Context, viewer *models.User, conditions []models.SmartListCondition, limit, offset int) ([]*models.Video, error) {
+	s.viewer, s.conditions = viewer, conditions
+	return []*models.Video{{ID: "heist"}, {ID: "manor"}}, nil
+}
+
+func TestSmartListService(t *testing.T) {
+	user := &models.User{ID: "user-1", TenantID: "acme", Role: string(models.RoleEditor)}
+	repo := &memorySmartListRepo{}
+	videos := &matchingVideoService{}
+	svc := NewSmartListService(repo, &memoryUserRepo{users: []*models.User{user}}, videos, logger.New("error", "test"))
+	ctx := context.Background()
+
+	_, err := svc.CreateList(ctx, "acme", "user-1", &models.SmartListRequest{Name: "Bad", Filter: "status=ready AND color=red"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.CreateList(ctx, "acme", "user-1", &models.SmartListRequest{Name: "Bad", Filter: "platform>tiktok"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "only numeric fields compare with >")
+
+	list, err := svc.CreateList(ctx, "acme", "user-1", &models.SmartListRequest{
+		Name:   "TikTok hits",
+		Filter: "status=ready AND platform=tiktok AND engagement>5%",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", list.UserID)
+
+	got, err := svc.ListVideos(ctx, user, list.ID, 20, 0)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Same(t, user, videos.viewer)
+	assert.Equal(t, []models.SmartListCondition{
+		{Field: models.SmartListStatus, Operator: "=", Value: "ready"},
+		{Field: models.SmartListPlatform, Operator: "=", Value: "tiktok"},
+		{Field: models.SmartListEngagement, Operator: ">", Value: "5%", Number: 5},
+	}, videos.conditions)
+
+	ids, err := svc.VideoIDs(ctx, "acme", "user-1", list.ID, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"heist", "manor"}, ids)
+	assert.Equal(t, "user-1", videos.viewer.ID, "background operations match what the list's user sees")
+
+	_, err = svc.UpdateList(ctx, "acme", "user-1", list.ID, &models.SmartListRequest{Name: "Hits", Filter: "views >= "})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	updated, err := svc.UpdateList(ctx, "acme", "user-1", list.ID, &models.SmartListRequest{Name: "Hits", Filter: "views >= 100000"})
+	require.NoError(t, err)
+	assert.Equal(t, "views >= 100000", updated.Filter)
+
+	_, err = svc.ListVideos(ctx, &models.User{ID: "user-2", TenantID: "acme"}, list.ID, 20, 0)
+	assert.ErrorIs(t, err, models.ErrSmartListNotFound, "lists are their user's own")
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+const (
+	// backfillWindowDays is the span of one daily stats request; long
+	// windows cost fewer requests of the platform quota
+	backfillWindowDays = 365
+	// maxDueBackfills bounds the backfills one run looks at
+	maxDueBackfills = 20
+	// maxBackfillErrors is how many runs in a row may fail before a
+	// backfill is given up
+	maxBackfillErrors = 5
+)
+
+// statsBackfillService implements the StatsBackfillService interface. Each
+// video's history is rebuilt from its first day, so the snapshots hold the
+// same running totals as the ones the sync takes.
+type statsBackfillService struct {
+	backfills  models.StatsBackfillRepository
+	stats      models.VideoStatsRepository
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	clients    func(string) (partners.Client, error)
+	jobs       JobService
+
+	requestsPerRun  int
+	quotaBackoff    time.Duration
+	retentionMonths int
+	clock           clock.Clock
+	logger          *logger.Logger
+}
+
+var _ StatsBackfillService = (*statsBackfillService)(nil)
+
+// NewStatsBackfillService creates a stats backfill service. A run makes at
+// most requestsPerRun platform requests, and a backfill the platform refuses
+// for quota waits quotaBackoff. Days older than the snapshot retention of
+// retentionMonths are not imported; 0 imports all of them.
+func NewStatsBackfillService(
+	backfills models.StatsBackfillRepository,
+	stats models.VideoStatsRepository,
+	videos models.VideoRepository,
+	workspaces models.WorkspaceRepository,
+	clients func(string) (partners.Client, error),
+	jobs JobService,
+	requestsPerRun int,
+	quotaBackoff time.Duration,
+	retentionMonths int,
+	clock clock.Clock,
+	logger *logger.Logger,
+) StatsBackfillService {
+	return &statsBackfillService{
+		backfills:       backfills,
+		stats:           stats,
+		videos:          videos,
+		workspaces:      workspaces,
+		clients:         clients,
+		jobs:            jobs,
+		requestsPerRun:  requestsPerRun,
+		quotaBackoff:    quotaBackoff,
+		retentionMonths: retentionMonths,
+		clock:           clock,
+		logger:          logger,
+	}
+}
+
+// Start checks the platform and workspace, then queues the backfill for the next run
+func (s *statsBackfillService) Start(ctx context.Context, tenantID, userID, platform, workspaceID string) (*models.StatsBackfill, error) {
+	if !models.Platform(platform).Valid() {
+		return nil, fmt.Errorf("%w: %s", models.ErrInvalidPlatform, platform)
+	}
+	if !partners.SupportsHistory(platform) {
+		return nil, fmt.Errorf("%w: %s", models.ErrBackfillUnsupported, platform)
+	}
+	if _, err := s.workspaces.GetByID(ctx, tenantID, workspaceID); err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	if _, err := s.backfills.GetActive(ctx, tenantID, platform); err == nil {
+		return nil, models.ErrBackfillInProgress
+	} else if !errors.Is(err, models.ErrBackfillNotFound) {
+		return nil, fmt.Errorf("failed to check running backfills: %w", err)
+	}
+
+	now := s.clock.Now()
+	backfill := &models.StatsBackfill{
+		TenantID:    tenantID,
+		Platform:    platform,
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Status:      models.BackfillPending,
+		Until:       startOfDay(now),
+		NextRunAt:   now,
+	}
+	if err := s.backfills.Create(ctx, backfill); err != nil {
+		return nil, fmt.Errorf("failed to create backfill: %w", err)
+	}
+	if job := s.jobs.Start(ctx, tenantID, userID, models.StatsImportJob(backfill.ID)); job != nil {
+		backfill.JobID = job.ID
+	}
+
+	s.logger.Info("Stats backfill queued", "tenant_id", tenantID, "backfill_id", backfill.ID, "platform", platform)
+	return backfill, nil
+}
+
+// Get returns a backfill of the tenant
+func (s *statsBackfillService) Get(ctx context.Context, tenantID, id string) (*models.StatsBackfill, error) {
+	return s.backfills.GetByID(ctx, tenantID, id)
+}
+
+// List returns the tenant's backfills, newest first
+func (s *statsBackfillService) List(ctx context.Context, tenantID string, limit, offset int) ([]*models.StatsBackfill, error) {
+	return s.backfills.List(ctx, tenantID, limit, offset)
+}
+
+// Run shares the request budget between the due backfills, longest waiting first
+func (s *statsBackfillService) Run(ctx context.Context) (*StatsBackfillRunReport, error) {
+	due, err := s.backfills.ListDue(ctx, s.clock.Now(), maxDueBackfills)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due backfills: %w", err)
+	}
+
+	report := &StatsBackfillRunReport{}
+	budget := s.requestsPerRun
+	for _, backfill := range due {
+		if budget <= 0 || ctx.Err() != nil {
+			break
+		}
+		requests, days := backfill.Requests, backfill.DaysImported
+		s.advance(ctx, backfill, budget, report)
+		budget -= backfill.Requests - requests
+		report.Backfills++
+		report.Requests += backfill.Requests - requests
+		report.DaysImported += backfill.DaysImported - days
+	}
+	return report, nil
+}
+
+// advance imports days of the backfill and records its progress, also when
+// the run is stopped halfway
+func (s *statsBackfillService) advance(ctx context.Context, backfill *models.StatsBackfill, budget int, report *StatsBackfillRunReport) {
+	err := s.importDays(ctx, backfill, budget)
+
+	now := s.clock.Now()
+	switch {
+	case err == nil:
+		backfill.Errors = 0
+		backfill.LastError = ""
+		backfill.NextRunAt = now
+		if backfill.Status == models.BackfillCompleted {
+			// Videos synced since the start are backfilled too
+			backfill.VideosTotal = max(backfill.VideosTotal, backfill.VideosDone)
+			backfill.EndedAt = &now
+			report.Completed++
+			s.logger.Info("Stats backfill completed",
+				"tenant_id", backfill.TenantID, "backfill_id", backfill.ID, "videos", backfill.VideosDone, "days", backfill.DaysImported)
+		}
+	case ctx.Err() != nil:
+		// Stopped by shutdown; the next run resumes from the cursor
+	case errors.Is(err, partners.ErrQuotaExceeded):
+		backfill.Status = models.BackfillThrottled
+		backfill.NextRunAt = now.Add(s.quotaBackoff)
+		backfill.LastError = err.Error()
+		report.Throttled++
+		s.logger.Warn("Stats backfill paused by platform quota",
+			"tenant_id", backfill.TenantID, "backfill_id", backfill.ID, "resume_at", backfill.NextRunAt)
+	default:
+		backfill.Errors++
+		backfill.LastError = err.Error()
+		if backfill.Errors >= maxBackfillErrors || errors.Is(err, partners.ErrInvalidToken) ||
+			errors.Is(err, models.ErrBackfillUnsupported) || errors.Is(err, models.ErrNotFound) {
+			backfill.Status = models.BackfillFailed
+			backfill.EndedAt = &now
+			report.Failed++
+		}
+		s.logger.Error("Stats backfill failed", "error", err,
+			"tenant_id", backfill.TenantID, "backfill_id", backfill.ID, "status", backfill.Status)
+	}
+
+	// Progress is saved even when the run is being stopped
+	if err := s.backfills.Update(context.WithoutCancel(ctx), backfill); err != nil {
+		s.logger.Error("Failed to save stats backfill progress", "error", err, "tenant_id", backfill.TenantID, "backfill_id", backfill.ID)
+	}
+
+	job := models.StatsImportJob(backfill.ID)
+	switch {
+	case backfill.Status == models.BackfillCompleted:
+		s.jobs.Succeed(ctx, backfill.TenantID, job)
+	case backfill.Status == models.BackfillFailed:
+		s.jobs.Fail(ctx, backfill.TenantID, job, backfill.LastError)
+	case backfill.VideosTotal > 0:
+		s.jobs.Progress(ctx, backfill.TenantID, job, backfill.VideosDone*100/backfill.VideosTotal)
+	default:
+		s.jobs.Progress(ctx, backfill.TenantID, job, 0)
+	}
+}
+
+// importDays fetches daily stats video after video until the backfill
+// completes or budget requests were made
+func (s *statsBackfillService) importDays(ctx context.Context, backfill *models.StatsBackfill, budget int) error {
+	if backfill.Status == models.BackfillPending {
+		total, err := s.stats.CountByPlatform(ctx, backfill.TenantID, backfill.Platform)
+		if err != nil {
+			return fmt.Errorf("failed to count videos: %w", err)
+		}
+		now := s.clock.Now()
+		backfill.VideosTotal = int(total)
+		backfill.StartedAt = &now
+	}
+	backfill.Status = models.BackfillRunning
+
+	workspace, err := s.workspaces.GetByID(ctx, backfill.TenantID, backfill.WorkspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace: %w", err)
+	}
+	client, err := s.clients(backfill.Platform)
+	if err != nil {
+		return err
+	}
+	history, ok := client.(partners.HistoryClient)
+	if !ok {
+		return fmt.Errorf("%w: %s", models.ErrBackfillUnsupported, backfill.Platform)
+	}
+	if err := client.Authenticate(ctx, workspace); err != nil {
+		return fmt.Errorf("failed to authenticate on %s: %w", backfill.Platform, err)
+	}
+
+	var video *models.Video
+	for requests := 0; ; {
+		// Moving the cursor costs no request, so a backfill whose last
+		// window used up the budget still completes in this run
+		if backfill.NextDay == nil {
+			video, err = s.nextVideo(ctx, backfill)
+			if err != nil || backfill.Status == models.BackfillCompleted {
+				return err
+			}
+			continue
+		}
+		if requests >= budget {
+			return nil
+		}
+		if video == nil {
+			if video, err = s.currentVideo(ctx, backfill); err != nil {
+				return err
+			}
+		}
+
+		from := *backfill.NextDay
+		to := from.AddDate(0, 0, backfillWindowDays)
+		if to.After(*backfill.EndDay) {
+			to = *backfill.EndDay
+		}
+		days, err := history.FetchDailyStats(ctx, video, from, to)
+		requests++
+		backfill.Requests++
+		if err != nil {
+			return fmt.Errorf("failed to fetch daily stats of video %s: %w", video.ID, err)
+		}
+
+		snapshots := s.snapshots(backfill, days, from, to)
+		if err := s.stats.ReplaceSnapshots(ctx, backfill.StatsID, from, to, snapshots); err != nil {
+			return fmt.Errorf("failed to write snapshots of video %s: %w", video.ID, err)
+		}
+		backfill.DaysImported += len(snapshots)
+
+		if to.Before(*backfill.EndDay) {
+			backfill.NextDay = &to
+			continue
+		}
+		backfill.VideosDone++
+		backfill.NextDay, backfill.EndDay = nil, nil
+		backfill.Carry = models.BackfillTotals{}
+	}
+}
+
+// nextVideo moves the cursor to the next stats row and returns its video,
+// or marks the backfill completed when there is none left. Videos with no
+// day to import are counted done at once.
+func (s *statsBackfillService) nextVideo(ctx context.Context, backfill *models.StatsBackfill) (*models.Video, error) {
+	rows, err := s.stats.ListByPlatformAfter(ctx, backfill.TenantID, backfill.Platform, backfill.StatsID, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stats: %w", err)
+	}
+	if len(rows) == 0 {
+		backfill.Status = models.BackfillCompleted
+		return nil, nil
+	}
+	stats := rows[0]
+	backfill.StatsID = stats.ID
+
+	video, err := s.videos.GetByID(ctx, backfill.TenantID, stats.VideoID)
+	if errors.Is(err, models.ErrVideoNotFound) {
+		backfill.VideosDone++
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video %s: %w", stats.VideoID, err)
+	}
+
+	// History ends where the sync's snapshots begin
+	end := backfill.Until
+	first, err := s.stats.GetFirstSnapshotAt(ctx, stats.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots of video %s: %w", video.ID, err)
+	}
+	if first != nil && first.Before(end) {
+		end = startOfDay(*first)
+	}
+
+	start := startOfDay(video.CreatedAt)
+	if !start.Before(end) {
+		backfill.VideosDone++
+		return nil, nil
+	}
+	backfill.NextDay, backfill.EndDay = &start, &end
+	backfill.Carry = models.BackfillTotals{}
+	return video, nil
+}
+
+// currentVideo returns the video of the stats row under the cursor
+func (s *statsBackfillService) currentVideo(ctx context.Context, backfill *models.StatsBackfill) (*models.Video, error) {
+	stats, err := s.stats.GetByID(ctx, backfill.TenantID, backfill.StatsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats %s: %w", backfill.StatsID, err)
+	}
+	video, err := s.videos.GetByID(ctx, backfill.TenantID, stats.VideoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video %s: %w", stats.VideoID, err)
+	}
+	return video, nil
+}
+
+// snapshots adds up the daily gains of [from, to) into a snapshot at the
+// end of each day, carrying the totals over to the next window. Days before
+// the snapshot retention only count towards the totals.
+func (s *statsBackfillService) snapshots(backfill *models.StatsBackfill, days []*partners.DailyStats, from, to time.Time) []*models.VideoStatsSnapshot {
+	gains := make(map[time.Time]*partners.DailyStats, len(days))
+	for _, d := range days {
+		gains[startOfDay(d.Day)] = d
+	}
+
+	keepFrom := time.Time{}
+	if s.retentionMonths > 0 {
+		now := s.clock.Now().UTC()
+		keepFrom = time.Date(now.Year(), now.Month()-time.Month(s.retentionMonths), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	var snapshots []*models.VideoStatsSnapshot
+	totals := &backfill.Carry
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if gain, ok := gains[day]; ok {
+			totals.Views += gain.Views
+			totals.Likes += gain.Likes
+			totals.Comments += gain.Comments
+			totals.Shares += gain.Shares
+		}
+		if day.Before(keepFrom) {
+			continue
+		}
+		snapshots = append(snapshots, &models.VideoStatsSnapshot{
+			StatsID:   backfill.StatsID,
+			Views:     totals.Views,
+			Likes:     totals.Likes,
+			Comments:  totals.Comments,
+			Shares:    totals.Shares,
+			CreatedAt: day.Add(24*time.Hour - time.Second),
+		})
+	}
+	return snapshots
+}
+
+// startOfDay truncates t to midnight UTC, the day boundary of platform reports
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
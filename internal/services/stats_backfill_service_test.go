@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// memoryBackfillRepo keeps backfills in memory
+type memoryBackfillRepo struct {
+	models.StatsBackfillRepository
+	backfills map[string]*models.StatsBackfill
+}
+
+func (r *memoryBackfillRepo) Create(ctx context.Context, b *models.StatsBackfill) error {
+	b.ID = fmt.Sprintf("backfill-%d", len(r.backfills)+1)
+	r.backfills[b.ID] = b
+	return nil
+}
+
+func (r *memoryBackfillRepo) GetActive(ctx context.Context, tenantID, platform string) (*models.StatsBackfill, error) {
+	for _, b := range r.backfills {
+		if b.TenantID == tenantID && b.Platform == platform && b.Active() {
+			return b, nil
+		}
+	}
+	return nil, models.ErrBackfillNotFound
+}
+
+func (r *memoryBackfillRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.StatsBackfill, error) {
+	var due []*models.StatsBackfill
+	for _, b := range r.backfills {
+		if b.Active() && !b.NextRunAt.After(now) {
+			due = append(due, b)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryBackfillRepo) Update(ctx context.Context, b *models.StatsBackfill) error {
+	r.backfills[b.ID] = b
+	return nil
+}
+
+// backfillStatsRepo serves stats rows and keeps the snapshots written
+type backfillStatsRepo struct {
+	models.VideoStatsRepository
+	rows      []*models.VideoStats // In ID order
+	first     map[string]time.Time
+	snapshots map[string][]*models.VideoStatsSnapshot
+}
+
+func (r *backfillStatsRepo) CountByPlatform(ctx context.Context, tenantID, platform string) (int64, error) {
+	return int64(len(r.rows)), nil
+}
+
+func (r *backfillStatsRepo) ListByPlatformAfter(ctx context.Context, tenantID, platform, afterID string, limit int) ([]*models.VideoStats, error) {
+	for _, row := range r.rows {
+		if row.ID > afterID {
+			return []*models.VideoStats{row}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *backfillStatsRepo) GetByID(ctx context.Context, tenantID, id string) (*models.VideoStats, error) {
+	for _, row := range r.rows {
+		if row.ID == id {
+			return row, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *backfillStatsRepo) GetFirstSnapshotAt(ctx context.Context, statsID string) (*time.Time, error) {
+	if first, ok := r.first[statsID]; ok {
+		return &first, nil
+	}
+	return nil, nil
+}
+
+func (r *backfillStatsRepo) ReplaceSnapshots(ctx context.Context, statsID string, from, to time.Time, snapshots []*models.VideoStatsSnapshot) error {
+	kept := r.snapshots[statsID][:0]
+	for _, s := range r.snapshots[statsID] {
+		if s.CreatedAt.Before(from) || !s.CreatedAt.Before(to) {
+			kept = append(kept, s)
+		}
+	}
+	r.snapshots[statsID] = append(kept, snapshots...)
+	return nil
+}
+
+// backfillVideoRepo serves videos by ID
+type backfillVideoRepo struct {
+	models.VideoRepository
+	videos map[string]*models.Video
+}
+
+func (r *backfillVideoRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Video, error) {
+	if v, ok := r.videos[id]; ok {
+		return v, nil
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+// backfillWorkspaceRepo knows a single workspace
+type backfillWorkspaceRepo struct {
+	models.WorkspaceRepository
+}
+
+func (r *backfillWorkspaceRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Workspace, error) {
+	if id != "ws-1" {
+		return nil, models.ErrNotFound
+	}
+	return &models.Workspace{ID: id, TenantID: tenantID}, nil
+}
+
+// historyClient reports one view a day and fails with quotaErr once
+// quotaAfter requests were made
+type historyClient struct {
+	partners.Client
+	requests   [][2]time.Time
+	quotaAfter int
+}
+
+func (c *historyClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
+	return nil
+}
+
+func (c *historyClient) FetchDailyStats(ctx context.Context, video *models.Video, from, to time.Time) ([]*partners.DailyStats, error) {
+	if c.quotaAfter > 0 && len(c.requests) >= c.quotaAfter {
+		return nil, fmt.Errorf("youtube: %w", partners.ErrQuotaExceeded)
+	}
+	c.requests = append(c.requests, [2]time.Time{from, to})
+	var days []*partners.DailyStats
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, &partners.DailyStats{Day: day, Views: 1, Likes: 1})
+	}
+	return days, nil
+}
+
+type backfillFixture struct {
+	svc    StatsBackfillService
+	repo   *memoryBackfillRepo
+	stats  *backfillStatsRepo
+	client *historyClient
+	clock  *clock.Fake
+}
+
+func newBackfillFixture(t *testing.T, requestsPerRun, retentionMonths int) *backfillFixture {
+	now := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	f := &backfillFixture{
+		repo: &memoryBackfillRepo{backfills: map[string]*models.StatsBackfill{}},
+		stats: &backfillStatsRepo{
+			rows: []*models.VideoStats{
+				{ID: "stats-1", VideoID: "video-1", Platform: "youtube"},
+				{ID: "stats-2", VideoID: "video-gone", Platform: "youtube"},
+				{ID: "stats-3", VideoID: "video-3", Platform: "youtube"},
+			},
+			// The sync started on October 10
+			first:     map[string]time.Time{"stats-1": time.Date(2026, 10, 10, 6, 0, 0, 0, time.UTC)},
+			snapshots: map[string][]*models.VideoStatsSnapshot{},
+		},
+		client: &historyClient{},
+		clock:  clock.NewFake(now),
+	}
+	videos := &backfillVideoRepo{videos: map[string]*models.Video{
+		"video-1": {ID: "video-1", YouTubeID: "yt-1", CreatedAt: time.Date(2024, 10, 1, 15, 0, 0, 0, time.UTC)},
+		"video-3": {ID: "video-3", YouTubeID: "yt-3", CreatedAt: time.Date(2026, 10, 13, 8, 0, 0, 0, time.UTC)},
+	}}
+	clients := func(string) (partners.Client, error) { return f.client, nil }
+	_, jobs := newTestJobs(f.clock)
+	f.svc = NewStatsBackfillService(f.repo, f.stats, videos, &backfillWorkspaceRepo{}, clients, jobs,
+		requestsPerRun, time.Hour, retentionMonths, f.clock, logger.New("error", "test"))
+	return f
+}
+
+func TestStatsBackfillService_Start(t *testing.T) {
+	f := newBackfillFixture(t, 10, 0)
+	ctx := context.Background()
+
+	backfill, err := f.svc.Start(ctx, "acme", "user-1", "youtube", "ws-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.BackfillPending, backfill.Status)
+	assert.Equal(t, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), backfill.Until)
+
+	_, err = f.svc.Start(ctx, "acme", "user-1", "youtube", "ws-1")
+	assert.ErrorIs(t, err, models.ErrBackfillInProgress)
+	_, err = f.svc.Start(ctx, "acme", "user-1", "tiktok", "ws-1")
+	assert.ErrorIs(t, err, models.ErrBackfillUnsupported)
+	_, err = f.svc.Start(ctx, "acme", "user-1", "myspace", "ws-1")
+	assert.ErrorIs(t, err, models.ErrInvalidPlatform)
+	_, err = f.svc.Start(ctx, "other", "user-2", "youtube", "ws-2")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestStatsBackfillService_RunImportsHistoryAcrossRuns(t *testing.T) {
+	f := newBackfillFixture(t, 2, 0)
+	ctx := context.Background()
+	backfill, err := f.svc.Start(ctx, "acme", "", "youtube", "ws-1")
+	require.NoError(t, err)
+
+	// October 1, 2024 to October 9, 2026 takes three one-year windows
+	report, err := f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Requests, "a run stays within its request budget")
+	assert.Equal(t, models.BackfillRunning, backfill.Status)
+	assert.Equal(t, 3, backfill.VideosTotal)
+	assert.Equal(t, 0, backfill.VideosDone)
+
+	report, err = f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Requests)
+	assert.Equal(t, models.BackfillCompleted, backfill.Status)
+	assert.Equal(t, 1, report.Completed)
+	assert.Equal(t, 3, backfill.VideosDone, "the deleted video is skipped")
+
+	// History of video 1 stops where its sync snapshots begin
+	snapshots := f.stats.snapshots["stats-1"]
+	days := int(time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC).Sub(time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	require.Len(t, snapshots, days)
+	assert.Equal(t, time.Date(2024, 10, 1, 23, 59, 59, 0, time.UTC), snapshots[0].CreatedAt)
+	assert.Equal(t, int64(1), snapshots[0].Views)
+	last := snapshots[len(snapshots)-1]
+	assert.Equal(t, time.Date(2026, 10, 9, 23, 59, 59, 0, time.UTC), last.CreatedAt)
+	assert.Equal(t, int64(days), last.Views, "snapshots hold running totals across windows")
+
+	// Video 3 has no snapshot yet, so it is backfilled up to the day the backfill started
+	require.Len(t, f.stats.snapshots["stats-3"], 2)
+	assert.Equal(t, int64(2), f.stats.snapshots["stats-3"][1].Likes)
+	assert.Equal(t, days+2, backfill.DaysImported)
+	assert.Len(t, f.client.requests, 4)
+}
+
+func TestStatsBackfillService_RunWaitsForQuota(t *testing.T) {
+	f := newBackfillFixture(t, 10, 0)
+	f.client.quotaAfter = 1
+	ctx := context.Background()
+	backfill, err := f.svc.Start(ctx, "acme", "", "youtube", "ws-1")
+	require.NoError(t, err)
+
+	report, err := f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Throttled)
+	assert.Equal(t, models.BackfillThrottled, backfill.Status)
+	assert.Equal(t, f.clock.Now().Add(time.Hour), backfill.NextRunAt)
+	assert.Contains(t, backfill.LastError, "quota")
+	assert.Equal(t, 365, backfill.DaysImported, "the window fetched before the quota ran out is kept")
+
+	report, err = f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, report.Backfills, "nothing runs before the quota resets")
+
+	f.client.quotaAfter = 0
+	f.clock.Set(f.clock.Now().Add(time.Hour))
+	_, err = f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.BackfillCompleted, backfill.Status)
+	assert.Empty(t, backfill.LastError)
+	assert.Equal(t, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), f.client.requests[1][0], "the next run resumes from the cursor")
+}
+
+func TestStatsBackfillService_RunSkipsDaysPastRetention(t *testing.T) {
+	f := newBackfillFixture(t, 10, 2)
+	ctx := context.Background()
+	_, err := f.svc.Start(ctx, "acme", "", "youtube", "ws-1")
+	require.NoError(t, err)
+
+	_, err = f.svc.Run(ctx)
+	require.NoError(t, err)
+
+	// Two whole months before October are kept
+	snapshots := f.stats.snapshots["stats-1"]
+	require.NotEmpty(t, snapshots)
+	assert.Equal(t, time.Date(2026, 8, 1, 23, 59, 59, 0, time.UTC), snapshots[0].CreatedAt)
+	days := int(time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC).Sub(time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)).Hours()/24) + 1
+	assert.Equal(t, int64(days), snapshots[0].Views, "older days still count towards the totals")
+}
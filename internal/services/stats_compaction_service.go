@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+const (
+	// compactionTenantPage is how many tenants a compaction run loads at a time
+	compactionTenantPage = 100
+	// maxCompactionDaysPerRun bounds the days compacted per tenant and run, so
+	// the first run over a long history is spread over several
+	maxCompactionDaysPerRun = 31
+)
+
+// StatsCompactionPlans holds the snapshot policy of each tenant plan
+type StatsCompactionPlans struct {
+	defaultPolicy models.StatsCompactionPolicy
+	plans         map[string]models.StatsCompactionPolicy
+}
+
+// NewStatsCompactionPlans builds the plan policies from configuration.
+//
+// plans overrides the default per plan as "plan=compact_after_days:retention_days",
+// e.g. "free=2:90;enterprise=30:0".
+func NewStatsCompactionPlans(defaultPolicy models.StatsCompactionPolicy, plans string) (*StatsCompactionPlans, error) {
+	if err := validateCompactionPolicy(defaultPolicy); err != nil {
+		return nil, fmt.Errorf("invalid default stats compaction: %w", err)
+	}
+	p := &StatsCompactionPlans{defaultPolicy: defaultPolicy, plans: make(map[string]models.StatsCompactionPolicy)}
+
+	for _, entry := range strings.Split(plans, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		plan, days, ok := strings.Cut(entry, "=")
+		after, retention, ok2 := strings.Cut(days, ":")
+		if !ok || !ok2 || strings.TrimSpace(plan) == "" {
+			return nil, fmt.Errorf("invalid stats compaction plan entry: %q", entry)
+		}
+		var policy models.StatsCompactionPolicy
+		var err error
+		if policy.CompactAfterDays, err = strconv.Atoi(strings.TrimSpace(after)); err != nil {
+			return nil, fmt.Errorf("invalid stats compaction plan entry: %q", entry)
+		}
+		if policy.RetentionDays, err = strconv.Atoi(strings.TrimSpace(retention)); err != nil {
+			return nil, fmt.Errorf("invalid stats compaction plan entry: %q", entry)
+		}
+		if err := validateCompactionPolicy(policy); err != nil {
+			return nil, fmt.Errorf("invalid stats compaction of plan %s: %w", plan, err)
+		}
+		p.plans[strings.TrimSpace(plan)] = policy
+	}
+	return p, nil
+}
+
+// For returns the policy of a plan, the default one for plans not configured
+func (p *StatsCompactionPlans) For(plan string) models.StatsCompactionPolicy {
+	if policy, ok := p.plans[plan]; ok {
+		return policy
+	}
+	return p.defaultPolicy
+}
+
+func validateCompactionPolicy(policy models.StatsCompactionPolicy) error {
+	switch {
+	case policy.CompactAfterDays < 1:
+		return fmt.Errorf("snapshots are compacted after %d days (must be 1 or more)", policy.CompactAfterDays)
+	case policy.RetentionDays < 0:
+		return fmt.Errorf("snapshots are kept %d days (must be 0 or more)", policy.RetentionDays)
+	case policy.RetentionDays > 0 && policy.RetentionDays <= policy.CompactAfterDays:
+		return fmt.Errorf("snapshots kept %d days are deleted before they are compacted after %d", policy.RetentionDays, policy.CompactAfterDays)
+	}
+	return nil
+}
+
+// statsCompactionService implements the StatsCompactionService interface.
+// Compacting a day is one statement that deletes nothing when repeated, and
+// the tenant's progress is saved after each day, so a run stopped at any
+// point loses no data and the next one carries on.
+type statsCompactionService struct {
+	tenants     models.TenantRepository
+	stats       models.VideoStatsRepository
+	compactions models.StatsCompactionRepository
+	plans       *StatsCompactionPlans
+	clock       clock.Clock
+	logger      *logger.Logger
+}
+
+var _ StatsCompactionService = (*statsCompactionService)(nil)
+
+// NewStatsCompactionService creates a stats compaction service
+func NewStatsCompactionService(
+	tenants models.TenantRepository,
+	stats models.VideoStatsRepository,
+	compactions models.StatsCompactionRepository,
+	plans *StatsCompactionPlans,
+	clock clock.Clock,
+	logger *logger.Logger,
+) StatsCompactionService {
+	return &statsCompactionService{
+		tenants:     tenants,
+		stats:       stats,
+		compactions: compactions,
+		plans:       plans,
+		clock:       clock,
+		logger:      logger,
+	}
+}
+
+// Run compacts the snapshots of every tenant. A failing tenant is logged and
+// counted, and does not stop the others.
+func (s *statsCompactionService) Run(ctx context.Context) (*StatsCompactionReport, error) {
+	report := &StatsCompactionReport{}
+	for offset := 0; ; offset += compactionTenantPage {
+		tenants, err := s.tenants.List(ctx, compactionTenantPage, offset)
+		if err != nil {
+			return report, fmt.Errorf("failed to list tenants: %w", err)
+		}
+		for _, tenant := range tenants {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			report.Tenants++
+			if err := s.compact(ctx, tenant, report); err != nil {
+				if ctx.Err() != nil {
+					return report, ctx.Err()
+				}
+				report.Failed++
+				s.logger.Error("Failed to compact stats snapshots", "error", err, "tenant_id", tenant.ID, "plan", tenant.Plan)
+			}
+		}
+		if len(tenants) < compactionTenantPage {
+			break
+		}
+	}
+	if report.Compacted > 0 || report.Expired > 0 {
+		s.logger.Info("Stats snapshots compacted", "tenants", report.Tenants, "days", report.DaysCompacted,
+			"compacted", report.Compacted, "expired", report.Expired, "failed", report.Failed)
+	}
+	return report, nil
+}
+
+// compact deletes the tenant's expired snapshots, then compacts its days from
+// where the last run stopped up to the plan's threshold
+func (s *statsCompactionService) compact(ctx context.Context, tenant *models.Tenant, report *StatsCompactionReport) error {
+	policy := s.plans.For(tenant.Plan)
+	today := startOfDay(s.clock.Now())
+	until := today.AddDate(0, 0, -policy.CompactAfterDays)
+
+	var expiry time.Time
+	if policy.RetentionDays > 0 {
+		expiry = today.AddDate(0, 0, -policy.RetentionDays)
+		expired, err := s.stats.DeleteSnapshotsBefore(ctx, tenant.ID, expiry)
+		report.Expired += expired
+		if err != nil {
+			return fmt.Errorf("failed to delete expired snapshots: %w", err)
+		}
+	}
+
+	day, err := s.compactedBefore(ctx, tenant.ID)
+	if err != nil || day.IsZero() {
+		return err
+	}
+	if day.Before(expiry) {
+		day = expiry
+	}
+	for days := 0; day.Before(until) && days < maxCompactionDaysPerRun; days++ {
+		next := day.AddDate(0, 0, 1)
+		compacted, err := s.stats.CompactSnapshots(ctx, tenant.ID, day, next)
+		if err != nil {
+			return fmt.Errorf("failed to compact snapshots of %s: %w", day.Format(time.DateOnly), err)
+		}
+		report.Compacted += compacted
+		report.DaysCompacted++
+		if err := s.compactions.Upsert(ctx, &models.StatsCompaction{TenantID: tenant.ID, CompactedBefore: next}); err != nil {
+			return fmt.Errorf("failed to save compaction progress: %w", err)
+		}
+		day = next
+	}
+	return nil
+}
+
+// compactedBefore returns the first day left to compact: where the last run
+// stopped, or the day of the tenant's oldest snapshot. It is zero when the
+// tenant has no snapshots.
+func (s *statsCompactionService) compactedBefore(ctx context.Context, tenantID string) (time.Time, error) {
+	compaction, err := s.compactions.Get(ctx, tenantID)
+	switch {
+	case err == nil:
+		return compaction.CompactedBefore, nil
+	case !errors.Is(err, models.ErrNotFound):
+		return time.Time{}, fmt.Errorf("failed to load compaction progress: %w", err)
+	}
+	oldest, err := s.stats.GetOldestSnapshotAt(ctx, tenantID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to find the oldest snapshot: %w", err)
+	}
+	if oldest == nil {
+		return time.Time{}, nil
+	}
+	return startOfDay(*oldest), nil
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// snapshotStore holds the snapshots of one tenant's stats rows, and fails to
+// compact failDay when it is set
+type snapshotStore struct {
+	models.VideoStatsRepository
+	snapshots []*models.VideoStatsSnapshot
+	failDay   time.Time
+}
+
+func (s *snapshotStore) GetOldestSnapshotAt(ctx context.Context, tenantID string) (*time.Time, error) {
+	var oldest *time.Time
+	for _, snapshot := range s.snapshots {
+		if oldest == nil || snapshot.CreatedAt.Before(*oldest) {
+			oldest = &snapshot.CreatedAt
+		}
+	}
+	return oldest, nil
+}
+
+func (s *snapshotStore) CompactSnapshots(ctx context.Context, tenantID string, from, to time.Time) (int64, error) {
+	if from.Equal(s.failDay) {
+		return 0, errors.New("lock wait timeout exceeded")
+	}
+	last := map[string]*models.VideoStatsSnapshot{}
+	for _, snapshot := range s.snapshots {
+		if snapshot.CreatedAt.Before(from) || !snapshot.CreatedAt.Before(to) {
+			continue
+		}
+		key := snapshot.StatsID + startOfDay(snapshot.CreatedAt).String()
+		if kept, ok := last[key]; !ok || snapshot.CreatedAt.After(kept.CreatedAt) {
+			last[key] = snapshot
+		}
+	}
+	return s.keep(func(snapshot *models.VideoStatsSnapshot) bool {
+		return snapshot.CreatedAt.Before(from) || !snapshot.CreatedAt.Before(to) ||
+			last[snapshot.StatsID+startOfDay(snapshot.CreatedAt).String()] == snapshot
+	}), nil
+}
+
+func (s *snapshotStore) DeleteSnapshotsBefore(ctx context.Context, tenantID string, before time.Time) (int64, error) {
+	return s.keep(func(snapshot *models.VideoStatsSnapshot) bool { return !snapshot.CreatedAt.Before(before) }), nil
+}
+
+func (s *snapshotStore) keep(keep func(*models.VideoStatsSnapshot) bool) int64 {
+	var kept []*models.VideoStatsSnapshot
+	for _, snapshot := range s.snapshots {
+		if keep(snapshot) {
+			kept = append(kept, snapshot)
+		}
+	}
+	deleted := int64(len(s.snapshots) - len(kept))
+	s.snapshots = kept
+	return deleted
+}
+
+// memoryCompactionRepo stores the compaction progress by tenant
+type memoryCompactionRepo struct {
+	progress map[string]time.Time
+}
+
+func (r *memoryCompactionRepo) Get(ctx context.Context, tenantID string) (*models.StatsCompaction, error) {
+	before, ok := r.progress[tenantID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &models.StatsCompaction{TenantID: tenantID, CompactedBefore: before}, nil
+}
+
+func (r *memoryCompactionRepo) Upsert(ctx context.Context, compaction *models.StatsCompaction) error {
+	r.progress[compaction.TenantID] = compaction.CompactedBefore
+	return nil
+}
+
+// tenantList lists a fixed set of tenants
+type tenantList struct {
+	models.TenantRepository
+	tenants []*models.Tenant
+}
+
+func (r *tenantList) List(ctx context.Context, limit, offset int) ([]*models.Tenant, error) {
+	if offset >= len(r.tenants) {
+		return nil, nil
+	}
+	return r.tenants[offset:min(offset+limit, len(r.tenants))], nil
+}
+
+func (r *tenantList) GetByID(ctx context.Context, id string) (*models.Tenant, error) {
+	for _, tenant := range r.tenants {
+		if tenant.ID == id {
+			return tenant, nil
+		}
+	}
+	return nil, models.ErrTenantNotFound
+}
+
+// newCompactionFixture takes 4 snapshots a day of one stats row over the 10
+// days before 2026-10-15
+func newCompactionFixture(t *testing.T, plan, plans string) (*snapshotStore, *memoryCompactionRepo, StatsCompactionService) {
+	now := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	store := &snapshotStore{}
+	for day := 10; day >= 1; day-- {
+		for hour := 0; hour < 24; hour += 6 {
+			store.snapshots = append(store.snapshots, &models.VideoStatsSnapshot{
+				StatsID:   "stats-1",
+				Views:     int64(100*(10-day) + hour),
+				CreatedAt: time.Date(2026, 10, 15-day, hour, 0, 0, 0, time.UTC),
+			})
+		}
+	}
+	compactions := &memoryCompactionRepo{progress: map[string]time.Time{}}
+	policies, err := NewStatsCompactionPlans(models.StatsCompactionPolicy{CompactAfterDays: 7}, plans)
+	require.NoError(t, err)
+	tenants := &tenantList{tenants: []*models.Tenant{{ID: "acme", Plan: plan}}}
+	return store, compactions, NewStatsCompactionService(tenants, store, compactions, policies, now, logger.New("error", "test"))
+}
+
+// snapshotsOn counts the snapshots taken on the day of 2026-10
+func snapshotsOn(store *snapshotStore, day int) int {
+	count := 0
+	for _, snapshot := range store.snapshots {
+		if snapshot.CreatedAt.Day() == day {
+			count++
+		}
+	}
+	return count
+}
+
+func TestStatsCompactionService_CollapsesOldDays(t *testing.T) {
+	store, compactions, svc := newCompactionFixture(t, "", "")
+	ctx := context.Background()
+
+	report, err := svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &StatsCompactionReport{Tenants: 1, DaysCompacted: 3, Compacted: 9}, report)
+	for day := 5; day <= 7; day++ {
+		require.Equal(t, 1, snapshotsOn(store, day), "day %d", day)
+	}
+	for day := 8; day <= 14; day++ {
+		assert.Equal(t, 4, snapshotsOn(store, day), "the last 7 days keep every snapshot")
+	}
+	for _, snapshot := range store.snapshots {
+		if snapshot.CreatedAt.Day() == 5 {
+			assert.Equal(t, 18, snapshot.CreatedAt.Hour(), "a day keeps its last snapshot")
+		}
+	}
+	assert.Equal(t, time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC), compactions.progress["acme"])
+
+	report, err = svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &StatsCompactionReport{Tenants: 1}, report, "compacted days are not read again")
+}
+
+func TestStatsCompactionService_PlanRetention(t *testing.T) {
+	store, compactions, svc := newCompactionFixture(t, "free", "free=2:8;enterprise=30:0")
+
+	report, err := svc.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &StatsCompactionReport{Tenants: 1, DaysCompacted: 6, Compacted: 18, Expired: 8}, report)
+	assert.Equal(t, 0, snapshotsOn(store, 6), "snapshots older than 8 days are deleted")
+	for day := 7; day <= 12; day++ {
+		assert.Equal(t, 1, snapshotsOn(store, day))
+	}
+	assert.Equal(t, 4, snapshotsOn(store, 13))
+	assert.Equal(t, time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC), compactions.progress["acme"])
+}
+
+func TestStatsCompactionService_ResumesAfterFailure(t *testing.T) {
+	store, compactions, svc := newCompactionFixture(t, "", "")
+	store.failDay = time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	report, err := svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &StatsCompactionReport{Tenants: 1, DaysCompacted: 1, Compacted: 3, Failed: 1}, report)
+	assert.Equal(t, store.failDay, compactions.progress["acme"], "progress stops before the failed day")
+
+	store.failDay = time.Time{}
+	report, err = svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &StatsCompactionReport{Tenants: 1, DaysCompacted: 2, Compacted: 6}, report)
+	assert.Equal(t, 1, snapshotsOn(store, 6))
+}
+
+func TestNewStatsCompactionPlans(t *testing.T) {
+	defaults := models.StatsCompactionPolicy{CompactAfterDays: 7}
+
+	plans, err := NewStatsCompactionPlans(defaults, " free = 2:90 ; enterprise=30:0 ")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatsCompactionPolicy{CompactAfterDays: 2, RetentionDays: 90}, plans.For("free"))
+	assert.Equal(t, models.StatsCompactionPolicy{CompactAfterDays: 30}, plans.For("enterprise"))
+	assert.Equal(t, defaults, plans.For(""))
+	assert.Equal(t, defaults, plans.For("pro"))
+
+	for _, invalid := range []string{"free", "free=2", "=2:90", "free=two:90", "free=0:90", "free=7:7", "free=2:-1"} {
+		_, err := NewStatsCompactionPlans(defaults, invalid)
+		assert.Error(t, err, invalid)
+	}
+	_, err = NewStatsCompactionPlans(models.StatsCompactionPolicy{}, "")
+	assert.Error(t, err)
+}
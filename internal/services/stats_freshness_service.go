@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/metrics"
+	"github.com/jibe0123/mysteryfactory/pkg/notify"
+)
+
+// statsFreshnessService implements the StatsFreshnessService interface. The
+// stats it has alerted about are remembered in memory, so a replica taking
+// over the job alerts again about stats that are still stale.
+type statsFreshnessService struct {
+	stats     models.VideoStatsRepository
+	notifier  notify.Notifier
+	threshold time.Duration
+	clock     clock.Clock
+	logger    *logger.Logger
+	metrics   *metrics.Metrics
+
+	mu    sync.Mutex
+	stale map[string]bool // By tenant/platform
+}
+
+var _ StatsFreshnessService = (*statsFreshnessService)(nil)
+
+// NewStatsFreshnessService creates a stats freshness service alerting when
+// stats were last synced more than threshold ago
+func NewStatsFreshnessService(stats models.VideoStatsRepository, notifier notify.Notifier, threshold time.Duration, clock clock.Clock, logger *logger.Logger, metrics *metrics.Metrics) StatsFreshnessService {
+	return &statsFreshnessService{
+		stats:     stats,
+		notifier:  notifier,
+		threshold: threshold,
+		clock:     clock,
+		logger:    logger,
+		metrics:   metrics,
+		stale:     make(map[string]bool),
+	}
+}
+
+// Check exports the latest syncs and alerts about stats turning stale or fresh
+func (s *statsFreshnessService) Check(ctx context.Context) (*StatsFreshnessReport, error) {
+	syncs, err := s.stats.GetLastSyncByPlatform(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last stats syncs: %w", err)
+	}
+
+	now := s.clock.Now()
+	report := &StatsFreshnessReport{
+		ThresholdSeconds: int64(s.threshold / time.Second),
+		Checked:          len(syncs),
+		Stale:            []*StaleStatsSync{},
+	}
+	stale := make(map[string]bool)
+	for _, last := range syncs {
+		s.metrics.RecordStatsLastSync(last.TenantID, last.Platform, last.LastSyncAt)
+		if age := now.Sub(last.LastSyncAt); age > s.threshold {
+			stale[last.TenantID+"/"+last.Platform] = true
+			report.Stale = append(report.Stale, &StaleStatsSync{PlatformStatsSync: last, AgeSeconds: int64(age / time.Second)})
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var turnedStale, lines []string
+	for _, st := range report.Stale {
+		key := st.TenantID + "/" + st.Platform
+		if !s.stale[key] {
+			turnedStale = append(turnedStale, key)
+			lines = append(lines, fmt.Sprintf("%s: last synced %s ago", key, time.Duration(st.AgeSeconds)*time.Second))
+		}
+	}
+	if len(turnedStale) > 0 {
+		s.logger.Warn("Stats are stale", "count", len(turnedStale), "threshold", s.threshold.String())
+		if !s.alert(ctx, fmt.Sprintf("Stats not synced for over %s", s.threshold), lines) {
+			// Alert again at the next check
+			for _, key := range turnedStale {
+				delete(stale, key)
+			}
+		}
+	}
+
+	var recovered []string
+	for key := range s.stale {
+		if !stale[key] {
+			recovered = append(recovered, key)
+		}
+	}
+	if len(recovered) > 0 {
+		sort.Strings(recovered)
+		if !s.alert(ctx, "Stats synced again", recovered) {
+			for _, key := range recovered {
+				stale[key] = true
+			}
+		}
+	}
+
+	s.stale = stale
+	return report, nil
+}
+
+// alert notifies the admins, reporting whether the alert was delivered
+func (s *statsFreshnessService) alert(ctx context.Context, subject string, lines []string) bool {
+	if err := s.notifier.Notify(ctx, subject, strings.Join(lines, "\n")); err != nil {
+		s.logger.Error("Failed to send stats freshness alert", "error", err, "subject", subject)
+		return false
+	}
+	return true
+}
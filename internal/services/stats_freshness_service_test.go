@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/metrics"
+)
+
+// testMetrics is shared by the tests of this package since metrics register globally
+var testMetrics = metrics.New(metrics.LabelPolicy{})
+
+// lastSyncRepo returns the syncs it holds
+type lastSyncRepo struct {
+	models.VideoStatsRepository
+	syncs []*models.PlatformStatsSync
+}
+
+func (r *lastSyncRepo) GetLastSyncByPlatform(ctx context.Context) ([]*models.PlatformStatsSync, error) {
+	return r.syncs, nil
+}
+
+// recordingNotifier keeps the subjects of the alerts it sends, failing while err is set
+type recordingNotifier struct {
+	subjects []string
+	messages []string
+	err      error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, subject, message string) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.subjects = append(n.subjects, subject)
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func TestStatsFreshnessService_Check(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	repo := &lastSyncRepo{syncs: []*models.PlatformStatsSync{
+		{TenantID: "acme", Platform: "youtube", LastSyncAt: now.Add(-10 * time.Minute)},
+		{TenantID: "acme", Platform: "tiktok", LastSyncAt: now.Add(-3 * time.Hour)},
+	}}
+	notifier := &recordingNotifier{}
+	svc := NewStatsFreshnessService(repo, notifier, time.Hour, clock.NewFake(now), logger.New("error", "test"), testMetrics)
+
+	report, err := svc.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Checked)
+	require.Len(t, report.Stale, 1)
+	assert.Equal(t, "tiktok", report.Stale[0].Platform)
+	assert.Equal(t, int64(3*3600), report.Stale[0].AgeSeconds)
+	assert.Equal(t, float64(now.Add(-3*time.Hour).Unix()),
+		testutil.ToFloat64(testMetrics.StatsLastSync.WithLabelValues("acme", "tiktok")))
+
+	require.Equal(t, []string{"Stats not synced for over 1h0m0s"}, notifier.subjects)
+	assert.Equal(t, "acme/tiktok: last synced 3h0m0s ago", notifier.messages[0])
+
+	_, err = svc.Check(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, notifier.subjects, 1, "stale stats are alerted about once")
+
+	repo.syncs[1].LastSyncAt = now
+	_, err = svc.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Stats not synced for over 1h0m0s", "Stats synced again"}, notifier.subjects)
+	assert.Equal(t, "acme/tiktok", notifier.messages[1])
+}
+
+func TestStatsFreshnessService_RetriesFailedAlerts(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	repo := &lastSyncRepo{syncs: []*models.PlatformStatsSync{
+		{TenantID: "acme", Platform: "youtube", LastSyncAt: now.Add(-2 * time.Hour)},
+	}}
+	notifier := &recordingNotifier{err: errors.New("chat is down")}
+	svc := NewStatsFreshnessService(repo, notifier, time.Hour, clock.NewFake(now), logger.New("error", "test"), testMetrics)
+
+	_, err := svc.Check(context.Background())
+	require.NoError(t, err, "a failed alert does not fail the check")
+	assert.Empty(t, notifier.subjects)
+
+	notifier.err = nil
+	_, err = svc.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Stats not synced for over 1h0m0s"}, notifier.subjects)
+}
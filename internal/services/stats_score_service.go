@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// scoreTenantPage is how many tenants a scoring run loads at a time
+const scoreTenantPage = 100
+
+// statsScoreService implements the StatsScoreService interface
+type statsScoreService struct {
+	tenants models.TenantRepository
+	stats   models.VideoStatsRepository
+	// scoreHour is the UTC hour from which tenants are scored each day
+	scoreHour int
+	clock     clock.Clock
+	logger    *logger.Logger
+}
+
+var _ StatsScoreService = (*statsScoreService)(nil)
+
+// NewStatsScoreService creates a stats score service scoring every tenant
+// once a day from scoreHour UTC
+func NewStatsScoreService(tenants models.TenantRepository, stats models.VideoStatsRepository, scoreHour int, clock clock.Clock, logger *logger.Logger) StatsScoreService {
+	return &statsScoreService{tenants: tenants, stats: stats, scoreHour: scoreHour, clock: clock, logger: logger}
+}
+
+// Recompute reads the tenant's stats rows over a cursor, ranks them per
+// platform and swaps the tenant's scores for the new ones in one transaction
+func (s *statsScoreService) Recompute(ctx context.Context, tenantID string) (*StatsScoreReport, error) {
+	now := s.clock.Now()
+	var scores []*models.VideoStatsScore
+	err := s.stats.Stream(ctx, tenantID, nil, "", func(stats *models.VideoStats) error {
+		scores = append(scores, &models.VideoStatsScore{
+			ID:               stats.ID,
+			TenantID:         tenantID,
+			VideoID:          stats.VideoID,
+			Platform:         stats.Platform,
+			EngagementRate:   math.Round(stats.CalculateEngagementRate()*10000) / 10000,
+			PerformanceScore: stats.GetPerformanceScore(),
+			ComputedAt:       now,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+
+	rankScores(scores)
+	if err := s.stats.ReplaceScores(ctx, tenantID, scores); err != nil {
+		return nil, fmt.Errorf("failed to save scores: %w", err)
+	}
+	s.logger.Info("Stats scores recomputed", "tenant_id", tenantID, "scored", len(scores))
+	return &StatsScoreReport{Tenants: 1, Scored: len(scores)}, nil
+}
+
+// RecomputeDue scores the tenants not scored since the last scoring hour. A
+// failing tenant is logged and counted, and does not stop the others.
+func (s *statsScoreService) RecomputeDue(ctx context.Context) (*StatsScoreReport, error) {
+	due := s.lastScoringTime()
+	report := &StatsScoreReport{}
+	for offset := 0; ; offset += scoreTenantPage {
+		tenants, err := s.tenants.List(ctx, scoreTenantPage, offset)
+		if err != nil {
+			return report, fmt.Errorf("failed to list tenants: %w", err)
+		}
+		for _, tenant := range tenants {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			scoredAt, err := s.stats.GetScoredAt(ctx, tenant.ID)
+			if err == nil && scoredAt != nil && !scoredAt.Before(due) {
+				continue
+			}
+			report.Tenants++
+			var tenantReport *StatsScoreReport
+			if err == nil {
+				tenantReport, err = s.Recompute(ctx, tenant.ID)
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return report, ctx.Err()
+				}
+				report.Failed++
+				s.logger.Error("Failed to recompute stats scores", "error", err, "tenant_id", tenant.ID)
+				continue
+			}
+			report.Scored += tenantReport.Scored
+		}
+		if len(tenants) < scoreTenantPage {
+			return report, nil
+		}
+	}
+}
+
+// lastScoringTime is the latest scoring hour reached, today or yesterday
+func (s *statsScoreService) lastScoringTime() time.Time {
+	now := s.clock.Now().UTC()
+	scoring := time.Date(now.Year(), now.Month(), now.Day(), s.scoreHour, 0, 0, 0, time.UTC)
+	if now.Before(scoring) {
+		scoring = scoring.AddDate(0, 0, -1)
+	}
+	return scoring
+}
+
+// rankScores sets each score to the percentile of its performance score
+// among those of its platform: the share of them it beats, counting ties as
+// half. A platform's only video scores 50.
+func rankScores(scores []*models.VideoStatsScore) {
+	byPlatform := map[string][]*models.VideoStatsScore{}
+	for _, score := range scores {
+		byPlatform[score.Platform] = append(byPlatform[score.Platform], score)
+	}
+	for _, platform := range byPlatform {
+		sort.Slice(platform, func(i, j int) bool { return platform[i].PerformanceScore < platform[j].PerformanceScore })
+		n := float64(len(platform))
+		for start := 0; start < len(platform); {
+			end := start
+			for end < len(platform) && platform[end].PerformanceScore == platform[start].PerformanceScore {
+				end++
+			}
+			percentile := (float64(start) + float64(end-start)/2) / n * 100
+			for _, score := range platform[start:end] {
+				score.Score = math.Round(percentile*100) / 100
+			}
+			start = end
+		}
+	}
+}
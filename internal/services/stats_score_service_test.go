@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// scoreStore holds stats rows and their scores by tenant
+type scoreStore struct {
+	models.VideoStatsRepository
+	stats  map[string][]*models.VideoStats
+	scores map[string][]*models.VideoStatsScore
+}
+
+func (s *scoreStore) Stream(ctx context.Context, tenantID string, scope *models.VideoScope, platform string, fn func(*models.VideoStats) error) error {
+	for _, stats := range s.stats[tenantID] {
+		if err := fn(stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *scoreStore) ReplaceScores(ctx context.Context, tenantID string, scores []*models.VideoStatsScore) error {
+	s.scores[tenantID] = scores
+	return nil
+}
+
+func (s *scoreStore) GetScoredAt(ctx context.Context, tenantID string) (*time.Time, error) {
+	var scoredAt *time.Time
+	for _, score := range s.scores[tenantID] {
+		if scoredAt == nil || score.ComputedAt.After(*scoredAt) {
+			scoredAt = &score.ComputedAt
+		}
+	}
+	return scoredAt, nil
+}
+
+// byStatsID indexes the tenant's scores by stats row
+func (s *scoreStore) byStatsID(tenantID string) map[string]*models.VideoStatsScore {
+	scores := map[string]*models.VideoStatsScore{}
+	for _, score := range s.scores[tenantID] {
+		scores[score.ID] = score
+	}
+	return scores
+}
+
+func TestStatsScoreService_RanksPerPlatform(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	store := &scoreStore{stats: map[string][]*models.VideoStats{"acme": {
+		{ID: "yt-1", VideoID: "video-1", Platform: "youtube", Views: 100, Likes: 1},
+		{ID: "yt-2", VideoID: "video-2", Platform: "youtube", Views: 200},
+		{ID: "yt-3", VideoID: "video-3", Platform: "youtube", Views: 200},
+		{ID: "yt-4", VideoID: "video-4", Platform: "youtube", Views: 400},
+		{ID: "tt-1", VideoID: "video-1", Platform: "tiktok", Views: 5},
+	}}, scores: map[string][]*models.VideoStatsScore{}}
+	svc := NewStatsScoreService(&tenantList{}, store, 3, now, logger.New("error", "test"))
+
+	report, err := svc.Recompute(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, &StatsScoreReport{Tenants: 1, Scored: 5}, report)
+
+	scores := store.byStatsID("acme")
+	assert.Equal(t, 12.5, scores["yt-1"].Score)
+	assert.Equal(t, 50.0, scores["yt-2"].Score, "ties share the midpoint of their ranks")
+	assert.Equal(t, 50.0, scores["yt-3"].Score)
+	assert.Equal(t, 87.5, scores["yt-4"].Score)
+	assert.Equal(t, 50.0, scores["tt-1"].Score, "platforms are ranked apart")
+	assert.Equal(t, 1.0, scores["yt-1"].EngagementRate)
+	assert.Equal(t, "video-1", scores["yt-1"].VideoID)
+	assert.Equal(t, now.Now(), scores["yt-1"].ComputedAt)
+}
+
+func TestStatsScoreService_RecomputeDue(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 10, 15, 4, 0, 0, 0, time.UTC))
+	rows := []*models.VideoStats{{ID: "yt-1", Platform: "youtube", Views: 100}}
+	store := &scoreStore{
+		stats: map[string][]*models.VideoStats{"acme": rows, "globex": rows, "initech": rows},
+		scores: map[string][]*models.VideoStatsScore{
+			"acme":   {{ID: "yt-1", ComputedAt: time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)}},
+			"globex": {{ID: "yt-1", ComputedAt: time.Date(2026, 10, 15, 3, 30, 0, 0, time.UTC)}},
+		},
+	}
+	tenants := &tenantList{tenants: []*models.Tenant{{ID: "acme"}, {ID: "globex"}, {ID: "initech"}}}
+	svc := NewStatsScoreService(tenants, store, 3, now, logger.New("error", "test"))
+
+	report, err := svc.RecomputeDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &StatsScoreReport{Tenants: 2, Scored: 2}, report, "globex was scored after 03:00")
+	assert.Equal(t, now.Now(), store.scores["acme"][0].ComputedAt)
+	assert.Equal(t, now.Now(), store.scores["initech"][0].ComputedAt)
+
+	now.Advance(22 * time.Hour)
+	report, err = svc.RecomputeDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &StatsScoreReport{}, report, "the next scoring is at 03:00 tomorrow")
+}
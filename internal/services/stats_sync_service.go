@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	pkgpartners "github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// statsSyncPageSize is how many stats rows are fetched before they are
+// written and the checkpoint moves past them
+const statsSyncPageSize = 50
+
+// statsSyncService implements the StatsSyncService interface
+type statsSyncService struct {
+	stats        models.VideoStatsRepository
+	batchSize    int
+	checkpoints  models.StatsSyncCheckpointRepository
+	videos       models.VideoRepository
+	publications models.PublicationJobRepository
+	workspaces   models.WorkspaceRepository
+	platforms    *partners.Service
+	clock        clock.Clock
+	logger       *logger.Logger
+}
+
+var _ StatsSyncService = (*statsSyncService)(nil)
+
+// NewStatsSyncService creates a stats sync service. Each stats row is read
+// with the credentials of the workspace its video was published with, and
+// rows are written batchSize to a statement.
+func NewStatsSyncService(
+	stats models.VideoStatsRepository,
+	batchSize int,
+	checkpoints models.StatsSyncCheckpointRepository,
+	videos models.VideoRepository,
+	publications models.PublicationJobRepository,
+	workspaces models.WorkspaceRepository,
+	platforms *partners.Service,
+	clock clock.Clock,
+	logger *logger.Logger,
+) StatsSyncService {
+	return &statsSyncService{
+		stats:        stats,
+		batchSize:    batchSize,
+		checkpoints:  checkpoints,
+		videos:       videos,
+		publications: publications,
+		workspaces:   workspaces,
+		platforms:    platforms,
+		clock:        clock,
+		logger:       logger,
+	}
+}
+
+// Sync syncs the tenant's platforms one after the other. A platform that
+// fails does not hold up the others; the first error is returned once they
+// were all tried.
+func (s *statsSyncService) Sync(ctx context.Context, tenantID string) (*StatsSyncRunReport, error) {
+	report := &StatsSyncRunReport{}
+	var firstErr error
+	for _, platform := range models.Platforms {
+		err := s.syncPlatform(ctx, tenantID, string(platform), report)
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		if err != nil {
+			s.logger.Error("Failed to sync platform stats", "error", err, "tenant_id", tenantID, "platform", platform)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return report, firstErr
+}
+
+// syncPlatform goes through the platform's stats rows from the checkpoint,
+// moving it after each page written
+func (s *statsSyncService) syncPlatform(ctx context.Context, tenantID, platform string, report *StatsSyncRunReport) error {
+	checkpoint, err := s.checkpoints.Get(ctx, tenantID, platform)
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		checkpoint = &models.StatsSyncCheckpoint{TenantID: tenantID, Platform: platform}
+	case err != nil:
+		return fmt.Errorf("failed to get stats sync checkpoint: %w", err)
+	}
+	if checkpoint.Completed() || checkpoint.RunStartedAt.IsZero() {
+		checkpoint.StatsID, checkpoint.ExternalID = "", ""
+		checkpoint.RunStartedAt = s.clock.Now()
+		checkpoint.Synced = 0
+		checkpoint.CompletedAt = nil
+	} else {
+		report.Resumed++
+		s.logger.Info("Resuming stats sync", "tenant_id", tenantID, "platform", platform,
+			"after_stats_id", checkpoint.StatsID, "synced", checkpoint.Synced, "run_started_at", checkpoint.RunStartedAt)
+	}
+
+	workspaces := make(map[string]*models.Workspace)
+	for {
+		rows, err := s.stats.ListByPlatformAfter(ctx, tenantID, platform, checkpoint.StatsID, statsSyncPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to list stats: %w", err)
+		}
+		if len(rows) == 0 {
+			now := s.clock.Now()
+			checkpoint.CompletedAt = &now
+			return s.saveCheckpoint(ctx, checkpoint)
+		}
+
+		page, syncErr := s.syncPage(ctx, checkpoint, rows, workspaces, report)
+		if err := s.writePage(ctx, tenantID, page); err != nil {
+			return err
+		}
+		if err := s.saveCheckpoint(ctx, checkpoint); err != nil {
+			return err
+		}
+		if syncErr != nil {
+			return syncErr
+		}
+	}
+}
+
+// syncPage fetches the rows in order, moving the checkpoint past each one
+// handled. It stops at the first error that would fail the following rows
+// too, leaving the checkpoint before the row that failed.
+func (s *statsSyncService) syncPage(ctx context.Context, checkpoint *models.StatsSyncCheckpoint, rows []*models.VideoStats, workspaces map[string]*models.Workspace, report *StatsSyncRunReport) ([]*models.VideoStats, error) {
+	var page []*models.VideoStats
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return page, err
+		}
+		// Rows written before the run stopped were not checkpointed yet
+		if row.LastSyncAt.Before(checkpoint.RunStartedAt) {
+			fetched, err := s.fetch(ctx, row, workspaces)
+			switch {
+			case errors.Is(err, pkgpartners.ErrQuotaExceeded), errors.Is(err, pkgpartners.ErrInvalidToken), ctx.Err() != nil:
+				return page, fmt.Errorf("failed to sync stats %s: %w", row.ID, err)
+			case err != nil:
+				report.Skipped++
+				s.logger.Warn("Skipped stats row", "error", err, "tenant_id", row.TenantID, "platform", row.Platform, "stats_id", row.ID)
+			case fetched != nil:
+				page = append(page, fetched)
+				report.Synced++
+			default:
+				report.Skipped++
+			}
+		}
+		checkpoint.StatsID, checkpoint.ExternalID = row.ID, row.ExternalID
+		checkpoint.Synced++
+	}
+	return page, nil
+}
+
+// fetch reads the row's latest stats from its platform, or returns nil when
+// the video is gone or was not published with a workspace
+func (s *statsSyncService) fetch(ctx context.Context, row *models.VideoStats, workspaces map[string]*models.Workspace) (*models.VideoStats, error) {
+	video, err := s.videos.GetByID(ctx, row.TenantID, row.VideoID)
+	if errors.Is(err, models.ErrVideoNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video %s: %w", row.VideoID, err)
+	}
+	workspace, err := s.workspace(ctx, row, workspaces)
+	if err != nil || workspace == nil {
+		return nil, err
+	}
+
+	fetched, err := s.platforms.SyncStats(ctx, workspace, video, models.Platform(row.Platform))
+	if err != nil {
+		return nil, err
+	}
+	fetched.ID = row.ID
+	fetched.TenantID = row.TenantID
+	fetched.VideoID = row.VideoID
+	fetched.Platform = row.Platform
+	if fetched.ExternalID == "" {
+		fetched.ExternalID = row.ExternalID
+	}
+	fetched.LastSyncAt = s.clock.Now()
+	return fetched, nil
+}
+
+// workspace returns the workspace the row's video was published with on its
+// platform, preferring the publication of the row's external ID
+func (s *statsSyncService) workspace(ctx context.Context, row *models.VideoStats, workspaces map[string]*models.Workspace) (*models.Workspace, error) {
+	jobs, err := s.publications.GetByVideoID(ctx, row.TenantID, row.VideoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publications of video %s: %w", row.VideoID, err)
+	}
+	var workspaceID string
+	for _, job := range jobs {
+		if job.Platform != row.Platform || job.WorkspaceID == "" {
+			continue
+		}
+		if workspaceID == "" || (row.ExternalID != "" && job.ExternalID == row.ExternalID) {
+			workspaceID = job.WorkspaceID
+		}
+	}
+	if workspaceID == "" {
+		return nil, nil
+	}
+
+	if workspace, ok := workspaces[workspaceID]; ok {
+		return workspace, nil
+	}
+	workspace, err := s.workspaces.GetByID(ctx, row.TenantID, workspaceID)
+	if errors.Is(err, models.ErrNotFound) {
+		workspace, err = nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace %s: %w", workspaceID, err)
+	}
+	workspaces[workspaceID] = workspace
+	return workspace, nil
+}
+
+// writePage saves the synced rows with a snapshot each. Rows are written
+// before the checkpoint moves past them, so a stop in between syncs them
+// again at most.
+func (s *statsSyncService) writePage(ctx context.Context, tenantID string, page []*models.VideoStats) error {
+	if len(page) == 0 {
+		return nil
+	}
+	ctx = context.WithoutCancel(ctx)
+	if err := s.stats.UpsertBatch(ctx, tenantID, page, s.batchSize); err != nil {
+		return fmt.Errorf("failed to write stats: %w", err)
+	}
+	for _, stats := range page {
+		err := s.stats.CreateSnapshot(ctx, &models.VideoStatsSnapshot{
+			StatsID:  stats.ID,
+			Views:    stats.Views,
+			Likes:    stats.Likes,
+			Comments: stats.Comments,
+			Shares:   stats.Shares,
+			Revenue:  stats.Revenue,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to snapshot stats %s: %w", stats.ID, err)
+		}
+	}
+	return nil
+}
+
+// saveCheckpoint records the progress, also when the sync is being stopped
+func (s *statsSyncService) saveCheckpoint(ctx context.Context, checkpoint *models.StatsSyncCheckpoint) error {
+	if err := s.checkpoints.Upsert(context.WithoutCancel(ctx), checkpoint); err != nil {
+		return fmt.Errorf("failed to save stats sync checkpoint: %w", err)
+	}
+	return nil
+}
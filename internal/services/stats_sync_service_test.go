@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/partners"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	pkgpartners "github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// syncStatsRepo serves stats rows by platform and keeps what is written
+type syncStatsRepo struct {
+	models.VideoStatsRepository
+	rows      []*models.VideoStats // In ID order
+	snapshots []*models.VideoStatsSnapshot
+}
+
+func (r *syncStatsRepo) ListByPlatformAfter(ctx context.Context, tenantID, platform, afterID string, limit int) ([]*models.VideoStats, error) {
+	var rows []*models.VideoStats
+	for _, row := range r.rows {
+		if row.Platform == platform && row.ID > afterID && len(rows) < limit {
+			copied := *row
+			rows = append(rows, &copied)
+		}
+	}
+	return rows, nil
+}
+
+func (r *syncStatsRepo) UpsertBatch(ctx context.Context, tenantID string, stats []*models.VideoStats, batchSize int) error {
+	for _, s := range stats {
+		for i, row := range r.rows {
+			if row.ID == s.ID {
+				r.rows[i] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (r *syncStatsRepo) CreateSnapshot(ctx context.Context, snapshot *models.VideoStatsSnapshot) error {
+	r.snapshots = append(r.snapshots, snapshot)
+	return nil
+}
+
+// memoryCheckpointRepo keeps a copy of each checkpoint saved
+type memoryCheckpointRepo struct {
+	checkpoints map[string]models.StatsSyncCheckpoint
+}
+
+func (r *memoryCheckpointRepo) Get(ctx context.Context, tenantID, platform string) (*models.StatsSyncCheckpoint, error) {
+	checkpoint, ok := r.checkpoints[tenantID+"/"+platform]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &checkpoint, nil
+}
+
+func (r *memoryCheckpointRepo) Upsert(ctx context.Context, checkpoint *models.StatsSyncCheckpoint) error {
+	r.checkpoints[checkpoint.TenantID+"/"+checkpoint.Platform] = *checkpoint
+	return nil
+}
+
+// videoPublicationRepo returns the jobs of a video
+type videoPublicationRepo struct {
+	models.PublicationJobRepository
+	jobs []*models.PublicationJob
+}
+
+func (r *videoPublicationRepo) GetByVideoID(ctx context.Context, tenantID, videoID string) ([]*models.PublicationJob, error) {
+	var jobs []*models.PublicationJob
+	for _, job := range r.jobs {
+		if job.VideoID == videoID {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// syncClient reports a hundred views per video and fails with a quota error
+// once quotaAfter requests were made
+type syncClient struct {
+	pkgpartners.Client
+	fetched    []string
+	quotaAfter int
+}
+
+func (c *syncClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
+	return nil
+}
+
+func (c *syncClient) FetchStats(ctx context.Context, video *models.Video) (*models.VideoStats, error) {
+	if c.quotaAfter > 0 && len(c.fetched) >= c.quotaAfter {
+		return nil, fmt.Errorf("youtube: %w", pkgpartners.ErrQuotaExceeded)
+	}
+	c.fetched = append(c.fetched, video.ID)
+	return &models.VideoStats{Views: 100}, nil
+}
+
+type statsSyncFixture struct {
+	svc         StatsSyncService
+	stats       *syncStatsRepo
+	checkpoints *memoryCheckpointRepo
+	client      *syncClient
+	clock       *clock.Fake
+}
+
+func newStatsSyncFixture(t *testing.T) *statsSyncFixture {
+	f := &statsSyncFixture{
+		stats: &syncStatsRepo{rows: []*models.VideoStats{
+			{ID: "stats-1", TenantID: "acme", VideoID: "video-1", Platform: "youtube", ExternalID: "yt-1"},
+			{ID: "stats-2", TenantID: "acme", VideoID: "video-gone", Platform: "youtube", ExternalID: "yt-2"},
+			{ID: "stats-3", TenantID: "acme", VideoID: "video-3", Platform: "youtube", ExternalID: "yt-3"},
+		}},
+		checkpoints: &memoryCheckpointRepo{checkpoints: map[string]models.StatsSyncCheckpoint{}},
+		client:      &syncClient{},
+		clock:       clock.NewFake(time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)),
+	}
+	videos := &backfillVideoRepo{videos: map[string]*models.Video{
+		"video-1": {ID: "video-1", YouTubeID: "yt-1"},
+		"video-3": {ID: "video-3", YouTubeID: "yt-3"},
+	}}
+	publications := &videoPublicationRepo{jobs: []*models.PublicationJob{
+		{ID: "pub-1", VideoID: "video-1", Platform: "youtube", WorkspaceID: "ws-1", ExternalID: "yt-1"},
+		{ID: "pub-3", VideoID: "video-3", Platform: "youtube", WorkspaceID: "ws-1", ExternalID: "yt-3"},
+	}}
+	platforms := partners.NewService(func(string) (pkgpartners.Client, error) { return f.client, nil })
+	f.svc = NewStatsSyncService(f.stats, models.DefaultStatsBatchSize, f.checkpoints, videos, publications, &backfillWorkspaceRepo{},
+		platforms, f.clock, logger.New("error", "test"))
+	return f
+}
+
+func TestStatsSyncService_Sync(t *testing.T) {
+	f := newStatsSyncFixture(t)
+
+	report, err := f.svc.Sync(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, &StatsSyncRunReport{Synced: 2, Skipped: 1}, report)
+	assert.Equal(t, []string{"video-1", "video-3"}, f.client.fetched)
+	assert.Len(t, f.stats.snapshots, 2)
+	assert.Equal(t, int64(100), f.stats.rows[0].Views)
+
+	checkpoint, err := f.checkpoints.Get(context.Background(), "acme", "youtube")
+	require.NoError(t, err)
+	assert.True(t, checkpoint.Completed())
+	assert.Equal(t, "stats-3", checkpoint.StatsID)
+	assert.Equal(t, "yt-3", checkpoint.ExternalID)
+	assert.Equal(t, 3, checkpoint.Synced)
+
+	// A completed sync starts over on the next run
+	f.clock.Advance(time.Hour)
+	report, err = f.svc.Sync(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Zero(t, report.Resumed)
+	assert.Equal(t, 2, report.Synced)
+	assert.Len(t, f.client.fetched, 4)
+}
+
+func TestStatsSyncService_SyncResumesAfterQuota(t *testing.T) {
+	f := newStatsSyncFixture(t)
+	f.client.quotaAfter = 1
+
+	report, err := f.svc.Sync(context.Background(), "acme")
+	assert.ErrorIs(t, err, pkgpartners.ErrQuotaExceeded)
+	assert.Equal(t, 1, report.Synced)
+
+	checkpoint, err := f.checkpoints.Get(context.Background(), "acme", "youtube")
+	require.NoError(t, err)
+	assert.False(t, checkpoint.Completed())
+	assert.Equal(t, "stats-2", checkpoint.StatsID)
+	runStartedAt := checkpoint.RunStartedAt
+
+	// The next run picks up at the row the quota stopped
+	f.clock.Advance(time.Hour)
+	f.client.quotaAfter = 0
+	report, err = f.svc.Sync(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, &StatsSyncRunReport{Synced: 1, Resumed: 1}, report)
+	assert.Equal(t, []string{"video-1", "video-3"}, f.client.fetched)
+
+	checkpoint, err = f.checkpoints.Get(context.Background(), "acme", "youtube")
+	require.NoError(t, err)
+	assert.True(t, checkpoint.Completed())
+	assert.Equal(t, runStartedAt, checkpoint.RunStartedAt)
+	assert.Equal(t, 3, checkpoint.Synced)
+}
+
+func TestStatsSyncService_SyncSkipsRowsAlreadySynced(t *testing.T) {
+	f := newStatsSyncFixture(t)
+	// The run stopped after writing stats-1 but before moving the checkpoint
+	runStartedAt := f.clock.Now().Add(-time.Minute)
+	f.checkpoints.checkpoints["acme/youtube"] = models.StatsSyncCheckpoint{
+		TenantID: "acme", Platform: "youtube", RunStartedAt: runStartedAt,
+	}
+	f.stats.rows[0].LastSyncAt = runStartedAt.Add(time.Second)
+
+	report, err := f.svc.Sync(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, &StatsSyncRunReport{Synced: 1, Skipped: 1, Resumed: 1}, report)
+	assert.Equal(t, []string{"video-3"}, f.client.fetched)
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+const (
+	// videoSummaryPrompt summarizes a transcript at three lengths with its
+	// key takeaways
+	videoSummaryPrompt = "analysis/video_summary"
+	// maxSummaryTranscript caps the characters of transcript sent to the
+	// AI; the rest of longer transcripts is left out
+	maxSummaryTranscript = 60000
+	// maxDescription is the length of the descriptions videos accept
+	maxDescription = 1000
+	// maxReportVideos caps the videos listed in a campaign report
+	maxReportVideos = 100
+)
+
+// summarySection is the header of a section of the summaries
+var summarySection = regexp.MustCompile(`(?m)^\s*\**(SHORT|MEDIUM|LONG|TAKEAWAYS)\**\s*:\**[ \t]*`)
+
+// summaryTakeaway is a point of the takeaways list
+var summaryTakeaway = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])\s*(.+)$`)
+
+// summaryService implements the SummaryService interface
+type summaryService struct {
+	summaries   models.VideoSummaryRepository
+	transcripts models.TranscriptRepository
+	videos      models.VideoRepository
+	workspaces  models.WorkspaceRepository
+	campaigns   models.CampaignRepository
+	ai          AIService
+	logger      *logger.Logger
+}
+
+var _ SummaryService = (*summaryService)(nil)
+
+// NewSummaryService creates a new summary service, summarizing transcripts
+// with the AI
+func NewSummaryService(summaries models.VideoSummaryRepository, transcripts models.TranscriptRepository, videos models.VideoRepository, workspaces models.WorkspaceRepository, campaigns models.CampaignRepository, ai AIService, logger *logger.Logger) SummaryService {
+	return &summaryService{
+		summaries:   summaries,
+		transcripts: transcripts,
+		videos:      videos,
+		workspaces:  workspaces,
+		campaigns:   campaigns,
+		ai:          ai,
+		logger:      logger,
+	}
+}
+
+// Summarize returns the summaries of the video's transcript, generating
+// them only when the transcript changed since they were
+func (s *summaryService) Summarize(ctx context.Context, viewer *models.User, videoID string, req *models.SummarizeRequest) (*SummaryResult, error) {
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := viewer.TenantID
+	transcript, err := s.transcripts.GetByVideoID(ctx, tenantID, videoID, strings.ToLower(strings.TrimSpace(req.Language)))
+	if errors.Is(err, models.ErrTranscriptNotFound) || err == nil && strings.TrimSpace(transcript.Text) == "" {
+		return nil, i18n.Errorf(models.ErrConflict, "the video needs a transcript to be summarized")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcript: %w", err)
+	}
+
+	hash := transcriptHash(transcript.Text)
+	result := &SummaryResult{}
+	result.Summary, err = s.summaries.Get(ctx, tenantID, videoID, transcript.Language)
+	switch {
+	case err == nil && result.Summary.TranscriptHash == hash && !req.Refresh:
+		result.Cached = true
+	case err == nil || errors.Is(err, models.ErrVideoSummaryNotFound):
+		if result.Summary, err = s.generate(ctx, video, transcript, hash, result.Summary); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("failed to get summary: %w", err)
+	}
+
+	if req.PrefillDescription && strings.TrimSpace(video.Description) == "" {
+		video.Description = truncateRunes(result.Summary.Medium, maxDescription)
+		if err := s.videos.Update(ctx, video); err != nil {
+			return nil, fmt.Errorf("failed to prefill description: %w", err)
+		}
+		result.DescriptionPrefilled = true
+	}
+	return result, nil
+}
+
+// generate summarizes the transcript and saves the summaries over the
+// previous ones, if any
+func (s *summaryService) generate(ctx context.Context, video *models.Video, transcript *models.Transcript, hash string, previous *models.VideoSummary) (*models.VideoSummary, error) {
+	output, err := s.ai.ProcessWithBedrock(ctx, videoSummaryPrompt, map[string]interface{}{
+		"transcript": truncateRunes(transcript.Text, maxSummaryTranscript),
+		"title":      video.Title,
+		"language":   transcript.Language,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize transcript: %w", err)
+	}
+	text, _ := output["result"].(string)
+	summary, err := parseVideoSummary(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize transcript: %w", err)
+	}
+
+	summary.TenantID = video.TenantID
+	summary.VideoID = video.ID
+	summary.Language = transcript.Language
+	summary.TranscriptHash = hash
+	summary.Model, _ = output["model"].(string)
+	if previous != nil {
+		summary.ID = previous.ID
+		summary.CreatedAt = previous.CreatedAt
+	}
+	if err := s.summaries.Upsert(ctx, summary); err != nil {
+		return nil, fmt.Errorf("failed to save summary: %w", err)
+	}
+	s.logger.Info("Video summarized", "tenant_id", video.TenantID, "video_id", video.ID, "language", transcript.Language)
+	return summary, nil
+}
+
+// CampaignReport returns the campaign's latest videos with their summaries,
+// in the campaign's language when there is one in it
+func (s *summaryService) CampaignReport(ctx context.Context, tenantID, campaignID string) (*CampaignReport, error) {
+	campaign, err := s.campaigns.Get(ctx, tenantID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	videos, err := s.videos.ListByCampaign(ctx, tenantID, campaignID, maxReportVideos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list campaign videos: %w", err)
+	}
+	ids := make([]string, len(videos))
+	for i, video := range videos {
+		ids[i] = video.ID
+	}
+	summaries, err := s.summaries.ListByVideos(ctx, tenantID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list summaries: %w", err)
+	}
+
+	// Summaries come newest first: keep the latest, unless one is in the
+	// campaign's language
+	byVideo := make(map[string]*models.VideoSummary, len(summaries))
+	for _, summary := range summaries {
+		kept, ok := byVideo[summary.VideoID]
+		if !ok || summary.Language == campaign.Language && kept.Language != campaign.Language {
+			byVideo[summary.VideoID] = summary
+		}
+	}
+
+	report := &CampaignReport{CampaignID: campaign.ID, Name: campaign.Name, Videos: make([]*CampaignReportVideo, len(videos))}
+	for i, video := range videos {
+		report.Videos[i] = &CampaignReportVideo{VideoID: video.ID, Title: video.Title, Status: video.Status, Summary: byVideo[video.ID]}
+		if report.Videos[i].Summary != nil {
+			report.Summarized++
+		}
+	}
+	return report, nil
+}
+
+// parseVideoSummary reads the sections of the summaries, failing when the
+// model left one out
+func parseVideoSummary(output string) (*models.VideoSummary, error) {
+	sections := make(map[string]string)
+	headers := summarySection.FindAllStringSubmatchIndex(output, -1)
+	for i, header := range headers {
+		end := len(output)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		sections[output[header[2]:header[3]]] = strings.TrimSpace(output[header[1]:end])
+	}
+
+	summary := &models.VideoSummary{Short: sections["SHORT"], Medium: sections["MEDIUM"], Long: sections["LONG"]}
+	for _, line := range strings.Split(sections["TAKEAWAYS"], "\n") {
+		if match := summaryTakeaway.FindStringSubmatch(line); match != nil {
+			summary.Takeaways = append(summary.Takeaways, strings.TrimSpace(match[1]))
+		}
+	}
+	for i, text := range []string{summary.Short, summary.Medium, summary.Long} {
+		if text == "" {
+			return nil, fmt.Errorf("the summaries left out the %s one", [...]string{"short", "medium", "long"}[i])
+		}
+	}
+	if len(summary.Takeaways) == 0 {
+		return nil, errors.New("the summaries left out the takeaways")
+	}
+	return summary, nil
+}
+
+// transcriptHash identifies the text of a transcript
+func transcriptHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncateRunes cuts s to at most n characters
+func truncateRunes(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
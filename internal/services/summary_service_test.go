@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memorySummaryRepo keeps one summary per video and language, in the order
+// they were saved
+type memorySummaryRepo struct {
+	summaries []*models.VideoSummary
+}
+
+func (r *memorySummaryRepo) Get(ctx context.Context, tenantID, videoID, language string) (*models.VideoSummary, error) {
+	for _, summary := range r.summaries {
+		if summary.VideoID == videoID && summary.Language == language {
+			return summary, nil
+		}
+	}
+	return nil, models.ErrVideoSummaryNotFound
+}
+
+func (r *memorySummaryRepo) ListByVideos(ctx context.Context, tenantID string, videoIDs []string) ([]*models.VideoSummary, error) {
+	var summaries []*models.VideoSummary
+	for i := len(r.summaries) - 1; i >= 0; i-- {
+		for _, id := range videoIDs {
+			if r.summaries[i].VideoID == id {
+				summaries = append(summaries, r.summaries[i])
+			}
+		}
+	}
+	return summaries, nil
+}
+
+func (r *memorySummaryRepo) Upsert(ctx context.Context, summary *models.VideoSummary) error {
+	for i, stored := range r.summaries {
+		if stored.VideoID == summary.VideoID && stored.Language == summary.Language {
+			r.summaries = append(r.summaries[:i], r.summaries[i+1:]...)
+			break
+		}
+	}
+	if summary.ID == "" {
+		summary.ID = "summary-" + summary.VideoID + "-" + summary.Language
+	}
+	r.summaries = append(r.summaries, summary)
+	return nil
+}
+
+// summaryVideoRepo serves fixed videos and counts the ones saved
+type summaryVideoRepo struct {
+	models.VideoRepository
+	videos  []*models.Video
+	updates int
+}
+
+func (r *summaryVideoRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Video, error) {
+	for _, video := range r.videos {
+		if video.ID == id {
+			return video, nil
+		}
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+func (r *summaryVideoRepo) ListByCampaign(ctx context.Context, tenantID, campaignID string, limit int) ([]*models.Video, error) {
+	var videos []*models.Video
+	for _, video := range r.videos {
+		if video.CampaignID == campaignID {
+			videos = append(videos, video)
+		}
+	}
+	return videos, nil
+}
+
+func (r *summaryVideoRepo) Update(ctx context.Context, video *models.Video) error {
+	r.updates++
+	return nil
+}
+
+// summaryTranscriptRepo serves transcripts keyed by video ID and language
+type summaryTranscriptRepo struct {
+	models.TranscriptRepository
+	transcripts map[string]*models.Transcript
+}
+
+func (r *summaryTranscriptRepo) GetByVideoID(ctx context.Context, tenantID, videoID, language string) (*models.Transcript, error) {
+	if language == "" {
+		language = "en"
+	}
+	if transcript, ok := r.transcripts[videoID+"/"+language]; ok {
+		return transcript, nil
+	}
+	return nil, models.ErrTranscriptNotFound
+}
+
+// summaryAI answers every prompt with a fixed script
+type summaryAI struct {
+	AIService
+	script  string
+	prompts int
+}
+
+func (a *summaryAI) ProcessWithBedrock(ctx context.Context, promptKey string, input map[string]interface{}) (map[string]interface{}, error) {
+	a.prompts++
+	return map[string]interface{}{"result": a.script, "model": "test-model"}, nil
+}
+
+const testSummaryOutput = `SHORT: A family finds their new house is not empty.
+MEDIUM: The Martins move into an old house and hear steps at night.
+LONG: The Martins move into an old house.
+
+At night they hear steps in the attic.
+TAKEAWAYS:
+- Old houses keep secrets
+- Listen to the children`
+
+type summaryFixture struct {
+	svc         SummaryService
+	summaries   *memorySummaryRepo
+	transcripts *summaryTranscriptRepo
+	videos      *summaryVideoRepo
+	ai          *summaryAI
+}
+
+func newSummaryFixture(t *testing.T) *summaryFixture {
+	t.Helper()
+	transcript := &models.Transcript{TenantID: "acme", VideoID: "video-1", Language: "en"}
+	require.NoError(t, transcript.SetSegments([]models.TranscriptSegment{{Start: 0, End: 2, Text: "The house was empty."}}))
+	f := &summaryFixture{
+		summaries:   &memorySummaryRepo{},
+		transcripts: &summaryTranscriptRepo{transcripts: map[string]*models.Transcript{"video-1/en": transcript}},
+		videos: &summaryVideoRepo{videos: []*models.Video{
+			{ID: "video-1", TenantID: "acme", Title: "The House", CampaignID: "campaign-1", Status: "ready"},
+			{ID: "video-2", TenantID: "acme", Title: "The Attic", CampaignID: "campaign-1", Status: "processing"},
+			{ID: "video-4", TenantID: "acme", UserID: "user-2", Title: "The Cellar", Visibility: models.VisibilityOwner, Status: "ready"},
+		}},
+		ai: &summaryAI{script: testSummaryOutput},
+	}
+	campaigns := newMemoryCampaignRepo(&models.Campaign{ID: "campaign-1", TenantID: "acme", Name: "Haunted", Language: "fr"})
+	f.svc = NewSummaryService(f.summaries, f.transcripts, f.videos, nil, campaigns, f.ai, logger.New("error", "test"))
+	return f
+}
+
+func TestSummaryService_Summarize(t *testing.T) {
+	f := newSummaryFixture(t)
+	ctx := context.Background()
+	viewer := &models.User{ID: "user-1", TenantID: "acme"}
+
+	result, err := f.svc.Summarize(ctx, viewer, "video-1", &models.SummarizeRequest{})
+	require.NoError(t, err)
+	assert.False(t, result.Cached)
+	assert.Equal(t, "A family finds their new house is not empty.", result.Summary.Short)
+	assert.Equal(t, "The Martins move into an old house.\n\nAt night they hear steps in the attic.", result.Summary.Long)
+	assert.Equal(t, []string{"Old houses keep secrets", "Listen to the children"}, result.Summary.Takeaways)
+	assert.Equal(t, "en", result.Summary.Language)
+	assert.Equal(t, "test-model", result.Summary.Model)
+	assert.Len(t, result.Summary.TranscriptHash, 64)
+
+	result, err = f.svc.Summarize(ctx, viewer, "video-1", &models.SummarizeRequest{PrefillDescription: true})
+	require.NoError(t, err)
+	assert.True(t, result.Cached, "the transcript did not change")
+	assert.Equal(t, 1, f.ai.prompts)
+	assert.True(t, result.DescriptionPrefilled)
+	assert.Equal(t, "The Martins move into an old house and hear steps at night.", f.videos.videos[0].Description)
+
+	result, err = f.svc.Summarize(ctx, viewer, "video-1", &models.SummarizeRequest{PrefillDescription: true, Refresh: true})
+	require.NoError(t, err)
+	assert.False(t, result.Cached)
+	assert.False(t, result.DescriptionPrefilled, "descriptions are not overwritten")
+	assert.Equal(t, 2, f.ai.prompts)
+	assert.Equal(t, 1, f.videos.updates)
+
+	require.NoError(t, f.transcripts.transcripts["video-1/en"].SetSegments([]models.TranscriptSegment{{Start: 0, End: 2, Text: "The house was full."}}))
+	_, err = f.svc.Summarize(ctx, viewer, "video-1", &models.SummarizeRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, f.ai.prompts, "a changed transcript is summarized again")
+	assert.Len(t, f.summaries.summaries, 1)
+
+	_, err = f.svc.Summarize(ctx, viewer, "video-2", &models.SummarizeRequest{})
+	assert.ErrorIs(t, err, models.ErrConflict)
+	_, err = f.svc.Summarize(ctx, viewer, "video-3", &models.SummarizeRequest{})
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+	_, err = f.svc.Summarize(ctx, viewer, "video-4", &models.SummarizeRequest{PrefillDescription: true})
+	assert.ErrorIs(t, err, models.ErrVideoNotFound, "videos the user cannot see are not found")
+
+	f.ai.script = "SHORT: Too short.\nTAKEAWAYS:\n- One"
+	_, err = f.svc.Summarize(ctx, viewer, "video-1", &models.SummarizeRequest{Refresh: true})
+	assert.ErrorContains(t, err, "left out the medium one")
+}
+
+func TestSummaryService_CampaignReport(t *testing.T) {
+	f := newSummaryFixture(t)
+	ctx := context.Background()
+
+	f.summaries.summaries = []*models.VideoSummary{
+		{VideoID: "video-1", Language: "fr", Short: "Une maison hantée."},
+		{VideoID: "video-1", Language: "en", Short: "A haunted house."},
+	}
+	report, err := f.svc.CampaignReport(ctx, "acme", "campaign-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Haunted", report.Name)
+	require.Len(t, report.Videos, 2)
+	assert.Equal(t, "Une maison hantée.", report.Videos[0].Summary.Short, "the campaign's language wins over the latest")
+	assert.Nil(t, report.Videos[1].Summary)
+	assert.Equal(t, 1, report.Summarized)
+
+	_, err = f.svc.CampaignReport(ctx, "acme", "campaign-2")
+	assert.ErrorIs(t, err, models.ErrCampaignNotFound)
+}
+
+func TestParseVideoSummary(t *testing.T) {
+	summary, err := parseVideoSummary("Here are the summaries.\n**SHORT:** One.\n**MEDIUM:** Two.\n**LONG:** Three.\n**TAKEAWAYS:**\n1. First\n2) Second\nnot a point")
+	require.NoError(t, err)
+	assert.Equal(t, "One.", summary.Short)
+	assert.Equal(t, "Three.", summary.Long)
+	assert.Equal(t, []string{"First", "Second"}, summary.Takeaways)
+
+	_, err = parseVideoSummary("SHORT: One.\nMEDIUM: Two.\nLONG: Three.")
+	assert.ErrorContains(t, err, "takeaways")
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// suspensionService implements the SuspensionService interface
+type suspensionService struct {
+	users           models.UserRepository
+	tenants         models.TenantRepository
+	audit           AuditService
+	identities      IdentityService
+	supportTenantID string
+	logger          *logger.Logger
+}
+
+var _ SuspensionService = (*suspensionService)(nil)
+
+// NewSuspensionService creates a new suspension service instance. Admins of
+// supportTenantID may suspend other tenants; an empty supportTenantID
+// disables tenant suspension. Changes are seen at once on this replica and
+// within the identity cache period on the others.
+func NewSuspensionService(users models.UserRepository, tenants models.TenantRepository, audit AuditService, identities IdentityService, supportTenantID string, logger *logger.Logger) SuspensionService {
+	return &suspensionService{
+		users:           users,
+		tenants:         tenants,
+		audit:           audit,
+		identities:      identities,
+		supportTenantID: supportTenantID,
+		logger:          logger,
+	}
+}
+
+// SuspendUser suspends an active user of the admin's tenant
+func (s *suspensionService) SuspendUser(ctx context.Context, admin *models.User, userID, reason, requestID string) (*models.User, error) {
+	if userID == admin.ID {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "admins cannot suspend themselves")
+	}
+	user, err := s.users.GetByID(ctx, admin.TenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if models.UserStatus(user.Status) != models.StatusActive {
+		return nil, i18n.Errorf(models.ErrConflict, "user is not active")
+	}
+	return s.setUserStatus(ctx, admin, user, models.StatusSuspended, models.AuditActionUserSuspend, reason, requestID)
+}
+
+// ReactivateUser makes a suspended or inactive user of the admin's tenant active
+func (s *suspensionService) ReactivateUser(ctx context.Context, admin *models.User, userID, reason, requestID string) (*models.User, error) {
+	user, err := s.users.GetByID(ctx, admin.TenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if models.UserStatus(user.Status) == models.StatusActive {
+		return nil, i18n.Errorf(models.ErrConflict, "user is already active")
+	}
+	return s.setUserStatus(ctx, admin, user, models.StatusActive, models.AuditActionUserReactivate, reason, requestID)
+}
+
+func (s *suspensionService) setUserStatus(ctx context.Context, admin, user *models.User, status models.UserStatus, action, reason, requestID string) (*models.User, error) {
+	err := s.audit.Record(ctx, &models.AuditLog{
+		TenantID:  user.TenantID,
+		UserID:    admin.ID,
+		Action:    action,
+		Resource:  "users/" + user.ID,
+		RequestID: requestID,
+		Detail:    reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user.Status = string(status)
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	s.identities.Invalidate(user.TenantID, user.ID)
+
+	s.logger.Warn("User status changed",
+		"tenant_id", user.TenantID,
+		"user_id", user.ID,
+		"admin_id", admin.ID,
+		"status", status)
+	return user, nil
+}
+
+// SuspendTenant suspends an active tenant other than the support tenant
+func (s *suspensionService) SuspendTenant(ctx context.Context, admin *models.User, tenantID, reason, requestID string) (*models.Tenant, error) {
+	if err := s.checkSupportAdmin(admin); err != nil {
+		return nil, err
+	}
+	if tenantID == admin.TenantID {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the support tenant cannot be suspended")
+	}
+	tenant, err := s.tenants.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.Status == models.TenantStatusSuspended {
+		return nil, i18n.Errorf(models.ErrConflict, "tenant is already suspended")
+	}
+	return s.setTenantStatus(ctx, admin, tenant, models.TenantStatusSuspended, models.AuditActionTenantSuspend, reason, requestID)
+}
+
+// ReactivateTenant makes a suspended tenant active
+func (s *suspensionService) ReactivateTenant(ctx context.Context, admin *models.User, tenantID, reason, requestID string) (*models.Tenant, error) {
+	if err := s.checkSupportAdmin(admin); err != nil {
+		return nil, err
+	}
+	tenant, err := s.tenants.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.Status == "" || tenant.Status == models.TenantStatusActive {
+		return nil, i18n.Errorf(models.ErrConflict, "tenant is already active")
+	}
+	return s.setTenantStatus(ctx, admin, tenant, models.TenantStatusActive, models.AuditActionTenantReactivate, reason, requestID)
+}
+
+func (s *suspensionService) checkSupportAdmin(admin *models.User) error {
+	if models.UserRole(admin.Role) != models.RoleAdmin || s.supportTenantID == "" || admin.TenantID != s.supportTenantID {
+		return i18n.Errorf(models.ErrForbidden, "only support admins can suspend or reactivate tenants")
+	}
+	return nil
+}
+
+func (s *suspensionService) setTenantStatus(ctx context.Context, admin *models.User, tenant *models.Tenant, status, action, reason, requestID string) (*models.Tenant, error) {
+	err := s.audit.Record(ctx, &models.AuditLog{
+		TenantID:  tenant.ID,
+		UserID:    admin.ID,
+		Action:    action,
+		Resource:  "tenants/" + tenant.ID,
+		RequestID: requestID,
+		Detail:    reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tenant.Status = status
+	if err := s.tenants.Update(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+	s.identities.Invalidate(tenant.ID, "")
+
+	s.logger.Warn("Tenant status changed",
+		"tenant_id", tenant.ID,
+		"admin_id", admin.ID,
+		"admin_tenant_id", admin.TenantID,
+		"status", status)
+	return tenant, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+func newTestSuspensionService() (SuspensionService, IdentityService, *memoryAuditRepo) {
+	log := logger.New("error", "test")
+	users := &identityUserRepo{users: map[string]*models.User{
+		"support-admin": {ID: "support-admin", TenantID: "support", Role: "admin", Status: "active"},
+		"acme-admin":    {ID: "acme-admin", TenantID: "acme", Role: "admin", Status: "active"},
+		"acme-editor":   {ID: "acme-editor", TenantID: "acme", Role: "editor", Status: "active"},
+	}}
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{
+		"support": {ID: "support", Status: models.TenantStatusActive},
+		"acme":    {ID: "acme", Status: models.TenantStatusActive},
+	}}
+	audit := &memoryAuditRepo{}
+	identities := NewIdentityService(users, tenants, time.Minute, clock.NewFake(time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)), log)
+	return NewSuspensionService(users, tenants, NewAuditService(audit, log), identities, "support", log), identities, audit
+}
+
+func TestSuspensionService_User(t *testing.T) {
+	svc, identities, audit := newTestSuspensionService()
+	ctx := context.Background()
+	admin := &models.User{ID: "acme-admin", TenantID: "acme", Role: "admin"}
+
+	// A cached user is refused as soon as it is suspended
+	_, _, err := identities.Load(ctx, "acme", "acme-editor")
+	require.NoError(t, err)
+
+	user, err := svc.SuspendUser(ctx, admin, "acme-editor", "Shared credentials", "req-1")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.StatusSuspended), user.Status)
+	_, _, err = identities.Load(ctx, "acme", "acme-editor")
+	assert.ErrorIs(t, err, models.ErrUserSuspended)
+
+	require.Len(t, audit.entries, 1)
+	assert.Equal(t, models.AuditActionUserSuspend, audit.entries[0].Action)
+	assert.Equal(t, "acme-admin", audit.entries[0].UserID)
+	assert.Equal(t, "users/acme-editor", audit.entries[0].Resource)
+	assert.Equal(t, "Shared credentials", audit.entries[0].Detail)
+
+	_, err = svc.SuspendUser(ctx, admin, "acme-editor", "Again", "req-2")
+	assert.ErrorIs(t, err, models.ErrConflict)
+	_, err = svc.SuspendUser(ctx, admin, "acme-admin", "Oops", "req-3")
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.SuspendUser(ctx, admin, "support-admin", "Other tenant", "req-4")
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+
+	user, err = svc.ReactivateUser(ctx, admin, "acme-editor", "Credentials rotated", "req-5")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.StatusActive), user.Status)
+	_, _, err = identities.Load(ctx, "acme", "acme-editor")
+	assert.NoError(t, err)
+	assert.Equal(t, models.AuditActionUserReactivate, audit.entries[len(audit.entries)-1].Action)
+
+	_, err = svc.ReactivateUser(ctx, admin, "acme-editor", "Again", "req-6")
+	assert.ErrorIs(t, err, models.ErrConflict)
+}
+
+func TestSuspensionService_Tenant(t *testing.T) {
+	svc, identities, audit := newTestSuspensionService()
+	ctx := context.Background()
+	supportAdmin := &models.User{ID: "support-admin", TenantID: "support", Role: "admin"}
+	acmeAdmin := &models.User{ID: "acme-admin", TenantID: "acme", Role: "admin"}
+
+	_, err := svc.SuspendTenant(ctx, acmeAdmin, "acme", "Unpaid", "req-1")
+	assert.ErrorIs(t, err, models.ErrForbidden)
+	_, err = svc.SuspendTenant(ctx, supportAdmin, "support", "Oops", "req-2")
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.SuspendTenant(ctx, supportAdmin, "missing", "Unpaid", "req-3")
+	assert.ErrorIs(t, err, models.ErrTenantNotFound)
+	assert.Empty(t, audit.entries)
+
+	_, _, err = identities.Load(ctx, "acme", "acme-editor")
+	require.NoError(t, err)
+
+	tenant, err := svc.SuspendTenant(ctx, supportAdmin, "acme", "Unpaid", "req-4")
+	require.NoError(t, err)
+	assert.Equal(t, models.TenantStatusSuspended, tenant.Status)
+	_, _, err = identities.Load(ctx, "acme", "acme-editor")
+	assert.ErrorIs(t, err, models.ErrTenantSuspended)
+
+	require.Len(t, audit.entries, 1)
+	assert.Equal(t, "acme", audit.entries[0].TenantID)
+	assert.Equal(t, models.AuditActionTenantSuspend, audit.entries[0].Action)
+	assert.Equal(t, "tenants/acme", audit.entries[0].Resource)
+	assert.Equal(t, "Unpaid", audit.entries[0].Detail)
+
+	_, err = svc.SuspendTenant(ctx, supportAdmin, "acme", "Again", "req-5")
+	assert.ErrorIs(t, err, models.ErrConflict)
+
+	tenant, err = svc.ReactivateTenant(ctx, supportAdmin, "acme", "Paid", "req-6")
+	require.NoError(t, err)
+	assert.Equal(t, models.TenantStatusActive, tenant.Status)
+	_, _, err = identities.Load(ctx, "acme", "acme-editor")
+	assert.NoError(t, err)
+
+	_, err = svc.ReactivateTenant(ctx, supportAdmin, "acme", "Again", "req-7")
+	assert.ErrorIs(t, err, models.ErrConflict)
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// thumbnailService implements the ThumbnailService interface
+type thumbnailService struct {
+	videos models.VideoRepository
+	clock  clock.Clock
+	logger *logger.Logger
+}
+
+var _ ThumbnailService = (*thumbnailService)(nil)
+
+// NewThumbnailService creates a new thumbnail service
+func NewThumbnailService(videos models.VideoRepository, clock clock.Clock, logger *logger.Logger) ThumbnailService {
+	return &thumbnailService{videos: videos, clock: clock, logger: logger}
+}
+
+// Plan plans the previews from the probed duration, falling back on the
+// duration set when the video was processed
+func (s *thumbnailService) Plan(ctx context.Context, tenantID, videoID string) (*transcode.PreviewPlan, error) {
+	video, err := s.videos.GetByID(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	duration := float64(video.Duration)
+	if video.MediaInfo != nil && video.MediaInfo.DurationSeconds > 0 {
+		duration = video.MediaInfo.DurationSeconds
+	}
+	plan, err := transcode.PlanPreview(duration)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrMediaNotProbed, err)
+	}
+	return plan, nil
+}
+
+// Record sets the video's hover previews, replacing earlier ones
+func (s *thumbnailService) Record(ctx context.Context, tenantID, videoID string, plan *transcode.PreviewPlan, spriteURLs []string, animationURL string) (*models.HoverPreview, error) {
+	if len(spriteURLs) != plan.Sheets {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "expected %d sprite sheets, got %d", plan.Sheets, len(spriteURLs))
+	}
+	if animationURL == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "an animation URL is required")
+	}
+	video, err := s.videos.GetByID(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	video.HoverPreview = models.HoverPreview{
+		SpriteURLs:     spriteURLs,
+		SpriteInterval: plan.IntervalSeconds,
+		SpriteFrames:   plan.Frames,
+		SpriteColumns:  plan.Columns,
+		SpriteRows:     plan.Rows,
+		TileWidth:      plan.TileWidth,
+		TileHeight:     plan.TileHeight,
+		AnimationURL:   animationURL,
+		GeneratedAt:    &now,
+	}
+	if err := s.videos.Update(ctx, video); err != nil {
+		return nil, fmt.Errorf("failed to save hover previews: %w", err)
+	}
+	s.logger.Info("Hover previews generated", "tenant_id", tenantID, "video_id", videoID, "sheets", plan.Sheets, "frames", plan.Frames)
+	return &video.HoverPreview, nil
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+func TestThumbnailService(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	videos := &publicationVideoRepo{videos: map[string]*models.Video{
+		"probed":    {ID: "probed", TenantID: "acme", Duration: 200, MediaInfo: &transcode.MediaInfo{DurationSeconds: 254.3}},
+		"processed": {ID: "processed", TenantID: "acme", Duration: 90},
+		"uploading": {ID: "uploading", TenantID: "acme"},
+	}}
+	svc := NewThumbnailService(videos, clock.NewFake(now), logger.New("error", "test"))
+	ctx := context.Background()
+
+	plan, err := svc.Plan(ctx, "acme", "probed")
+	require.NoError(t, err)
+	assert.Equal(t, 255, plan.Frames, "the probed duration wins")
+	assert.Equal(t, 3, plan.Sheets)
+
+	fallback, err := svc.Plan(ctx, "acme", "processed")
+	require.NoError(t, err)
+	assert.Equal(t, 90, fallback.Frames)
+
+	_, err = svc.Plan(ctx, "acme", "uploading")
+	assert.ErrorIs(t, err, models.ErrMediaNotProbed)
+
+	_, err = svc.Record(ctx, "acme", "probed", plan, []string{"https://cdn/sprite-001.jpg"}, "https://cdn/preview.webp")
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "every sheet is needed to map the tiles")
+
+	sprites := []string{"https://cdn/sprite-001.jpg", "https://cdn/sprite-002.jpg", "https://cdn/sprite-003.jpg"}
+	preview, err := svc.Record(ctx, "acme", "probed", plan, sprites, "https://cdn/preview.webp")
+	require.NoError(t, err)
+	assert.Equal(t, sprites, videos.videos["probed"].HoverPreview.SpriteURLs)
+	assert.Equal(t, "https://cdn/preview.webp", preview.AnimationURL)
+	assert.Equal(t, 1, preview.SpriteInterval)
+	assert.Equal(t, now, *preview.GeneratedAt)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// transcriptService implements the TranscriptService interface
+type transcriptService struct {
+	transcripts models.TranscriptRepository
+	videos      models.VideoRepository
+	workspaces  models.WorkspaceRepository
+	logger      *logger.Logger
+}
+
+var _ TranscriptService = (*transcriptService)(nil)
+
+// NewTranscriptService creates a new transcript service instance
+func NewTranscriptService(transcripts models.TranscriptRepository, videos models.VideoRepository, workspaces models.WorkspaceRepository, logger *logger.Logger) TranscriptService {
+	return &transcriptService{
+		transcripts: transcripts,
+		videos:      videos,
+		workspaces:  workspaces,
+		logger:      logger,
+	}
+}
+
+// SaveTranscript stores the transcript of a video, replacing any existing one for the language
+func (s *transcriptService) SaveTranscript(ctx context.Context, viewer *models.User, videoID string, req *models.SaveTranscriptRequest) (*models.Transcript, error) {
+	tenantID := viewer.TenantID
+	s.logger.Info("Saving transcript", "tenant_id", tenantID, "video_id", videoID, "language", req.Language, "segments", len(req.Segments))
+
+	if _, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID); err != nil {
+		return nil, err
+	}
+
+	if err := models.ValidateSegments(req.Segments); err != nil {
+		return nil, err
+	}
+
+	source := req.Source
+	if source == "" {
+		source = models.TranscriptSourceGenerated
+	}
+
+	transcript := &models.Transcript{
+		TenantID: tenantID,
+		VideoID:  videoID,
+		Language: strings.ToLower(req.Language),
+		Source:   source,
+	}
+	if err := transcript.SetSegments(req.Segments); err != nil {
+		return nil, err
+	}
+
+	if err := s.transcripts.Upsert(ctx, transcript); err != nil {
+		return nil, fmt.Errorf("failed to save transcript: %w", err)
+	}
+
+	s.logger.Info("Transcript saved", "tenant_id", tenantID, "video_id", videoID, "language", transcript.Language)
+	return transcript, nil
+}
+
+// GetTranscript retrieves a video transcript; an empty language returns the first one stored
+func (s *transcriptService) GetTranscript(ctx context.Context, viewer *models.User, videoID, language string) (*models.Transcript, error) {
+	if _, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID); err != nil {
+		return nil, err
+	}
+	return s.transcripts.GetByVideoID(ctx, viewer.TenantID, videoID, strings.ToLower(language))
+}
+
+// SearchTranscripts runs a full-text search over the transcripts of the
+// videos the viewer sees
+func (s *transcriptService) SearchTranscripts(ctx context.Context, viewer *models.User, query string, limit, offset int) ([]*models.TranscriptSearchResult, error) {
+	tenantID := viewer.TenantID
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "search query is required")
+	}
+
+	scope, err := videoScope(ctx, s.workspaces, viewer)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.transcripts.Search(ctx, tenantID, scope, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transcripts: %w", err)
+	}
+
+	s.logger.Debug("Transcripts searched", "tenant_id", tenantID, "query", query, "results", len(results))
+	return results, nil
+}
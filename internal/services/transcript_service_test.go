@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// scopedTranscriptRepo records the scope transcripts are searched within
+type scopedTranscriptRepo struct {
+	models.TranscriptRepository
+	scopes []*models.VideoScope
+}
+
+func (r *scopedTranscriptRepo) Search(ctx context.Context, tenantID string, scope *models.VideoScope, query string, limit, offset int) ([]*models.TranscriptSearchResult, error) {
+	r.scopes = append(r.scopes, scope)
+	return nil, nil
+}
+
+func TestTranscriptService_SearchKeepsToVisibleVideos(t *testing.T) {
+	transcripts := &scopedTranscriptRepo{}
+	workspaces := &visibilityWorkspaceRepo{workspaces: []*models.Workspace{{ID: "ws-1", TenantID: "acme", UserID: "editor"}}}
+	svc := NewTranscriptService(transcripts, &visibilityVideoRepo{}, workspaces, logger.New("error", "test"))
+	ctx := context.Background()
+
+	_, err := svc.SearchTranscripts(ctx, &models.User{ID: "editor", TenantID: "acme"}, "launch", 20, 0)
+	require.NoError(t, err)
+	_, err = svc.SearchTranscripts(ctx, &models.User{ID: "admin", TenantID: "acme", Role: string(models.RoleAdmin)}, "launch", 20, 0)
+	require.NoError(t, err)
+	_, err = svc.SearchTranscripts(ctx, &models.User{ID: "editor", TenantID: "acme"}, "  ", 20, 0)
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	assert.Equal(t, []*models.VideoScope{{UserID: "editor", WorkspaceIDs: []string{"ws-1"}}, nil}, transcripts.scopes, "admins search every transcript")
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// transferService implements the TransferService interface
+type transferService struct {
+	transfers models.VideoTransferRepository
+	videos    models.VideoRepository
+	stats     models.VideoStatsRepository
+	batchSize int
+	tenants   models.TenantRepository
+	storage   aws.ArchiveStorage
+	residency ResidencyService
+	audit     AuditService
+	notify    NotificationService
+	clock     clock.Clock
+	logger    *logger.Logger
+}
+
+var _ TransferService = (*transferService)(nil)
+
+// NewTransferService creates a new transfer service. Files are copied with
+// storage into the bucket of the recipient's residency, stats are copied
+// batchSize rows to a statement, and the requester is notified of the answer.
+func NewTransferService(transfers models.VideoTransferRepository, videos models.VideoRepository, stats models.VideoStatsRepository, batchSize int, tenants models.TenantRepository, storage aws.ArchiveStorage, residency ResidencyService, audit AuditService, notify NotificationService, clock clock.Clock, logger *logger.Logger) TransferService {
+	return &transferService{
+		transfers: transfers,
+		videos:    videos,
+		stats:     stats,
+		batchSize: batchSize,
+		tenants:   tenants,
+		storage:   storage,
+		residency: residency,
+		audit:     audit,
+		notify:    notify,
+		clock:     clock,
+		logger:    logger,
+	}
+}
+
+// Request checks the recipient can receive the video and that it is not
+// being handed over already
+func (s *transferService) Request(ctx context.Context, tenantID, userID, videoID string, req *models.CreateTransferRequest) (*models.VideoTransfer, error) {
+	mode := req.Mode
+	if mode == "" {
+		mode = models.TransferCopy
+	}
+	if !mode.Valid() {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "mode must be copy or move")
+	}
+	if req.TargetTenantID == tenantID {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "a video cannot be transferred to its own tenant")
+	}
+	target, err := s.tenants.GetByID(ctx, req.TargetTenantID)
+	if err != nil {
+		return nil, err
+	}
+	if target.Status != "active" {
+		return nil, models.ErrTenantInactive
+	}
+
+	video, err := s.videos.GetByID(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := transferable(video); err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	pending, err := s.transfers.GetPendingForVideo(ctx, tenantID, videoID)
+	switch {
+	case errors.Is(err, models.ErrTransferNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("failed to check pending transfers: %w", err)
+	case pending.Pending(now):
+		return nil, models.ErrTransferInProgress
+	default:
+		if err := s.expire(ctx, pending); err != nil {
+			return nil, err
+		}
+	}
+
+	transfer := &models.VideoTransfer{
+		ID:             id.New(),
+		TenantID:       tenantID,
+		TargetTenantID: target.ID,
+		VideoID:        videoID,
+		Mode:           string(mode),
+		IncludeStats:   req.IncludeStats,
+		Status:         string(models.TransferPending),
+		Message:        req.Message,
+		RequestedBy:    userID,
+		ExpiresAt:      now.Add(models.VideoTransferTTL),
+	}
+	if err := s.transfers.Create(ctx, transfer); err != nil {
+		return nil, fmt.Errorf("failed to create transfer: %w", err)
+	}
+	s.record(ctx, transfer, userID, models.AuditActionTransferRequest,
+		fmt.Sprintf("%s of %q offered by tenant %s to tenant %s", transfer.Mode, video.Title, tenantID, target.ID))
+
+	s.logger.Info("Video transfer requested", "transfer_id", transfer.ID, "tenant_id", tenantID, "target_tenant_id", target.ID, "video_id", videoID, "mode", transfer.Mode)
+	return transfer, nil
+}
+
+// Accept copies the video into the recipient's tenant. The transfer stays
+// pending when a step fails, so the recipient can accept it again.
+func (s *transferService) Accept(ctx context.Context, tenantID, userID, transferID string) (*models.VideoTransfer, error) {
+	transfer, err := s.pending(ctx, tenantID, transferID, true)
+	if err != nil {
+		return nil, err
+	}
+	video, err := s.videos.GetByID(ctx, transfer.TenantID, transfer.VideoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := transferable(video); err != nil {
+		return nil, err
+	}
+
+	received := video.CopyFor(tenantID, userID, id.New())
+	if video.S3Key != "" {
+		placement, err := s.residency.GetResidency(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		received.S3Bucket = placement.Placement.S3Bucket
+		received.S3Key = fmt.Sprintf("%s/videos/%s/%s", tenantID, received.ID, path.Base(video.S3Key))
+		if err := s.storage.Copy(ctx, video.S3Bucket, video.S3Key, received.S3Bucket, received.S3Key); err != nil {
+			return nil, fmt.Errorf("failed to copy video file: %w", err)
+		}
+	}
+	if err := s.videos.Create(ctx, received); err != nil {
+		return nil, fmt.Errorf("failed to create received video: %w", err)
+	}
+
+	if transfer.IncludeStats {
+		if err := s.copyStats(ctx, transfer, received.ID); err != nil {
+			return nil, err
+		}
+	}
+	if transfer.Mode == string(models.TransferMove) {
+		if err := s.videos.Delete(ctx, transfer.TenantID, video.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete moved video: %w", err)
+		}
+	}
+
+	s.respond(transfer, models.TransferCompleted, userID)
+	transfer.TargetVideoID = received.ID
+	if err := s.transfers.Update(ctx, transfer); err != nil {
+		return nil, fmt.Errorf("failed to update transfer: %w", err)
+	}
+	s.record(ctx, transfer, userID, models.AuditActionTransferAccept,
+		fmt.Sprintf("Accepted by tenant %s as video %s", tenantID, received.ID))
+	s.notifyRequester(ctx, transfer, i18n.M("Video transfer accepted"),
+		i18n.M("%q was accepted by tenant %s", video.Title, tenantID))
+
+	s.logger.Info("Video transfer accepted", "transfer_id", transfer.ID, "tenant_id", transfer.TenantID, "target_tenant_id", tenantID, "video_id", video.ID, "target_video_id", received.ID)
+	return transfer, nil
+}
+
+// copyStats copies the sender's stats totals per platform. Snapshots are not
+// copied: the recipient's history starts at the handoff.
+func (s *transferService) copyStats(ctx context.Context, transfer *models.VideoTransfer, videoID string) error {
+	stats, err := s.stats.GetByVideoID(ctx, transfer.TenantID, transfer.VideoID)
+	if err != nil {
+		return fmt.Errorf("failed to get video stats: %w", err)
+	}
+	copies := make([]*models.VideoStats, 0, len(stats))
+	for _, st := range stats {
+		c := *st
+		c.ID = ""
+		c.TenantID = transfer.TargetTenantID
+		c.VideoID = videoID
+		copies = append(copies, &c)
+	}
+	if err := s.stats.UpsertBatch(ctx, transfer.TargetTenantID, copies, s.batchSize); err != nil {
+		return fmt.Errorf("failed to copy video stats: %w", err)
+	}
+	return nil
+}
+
+// Decline closes the transfer without copying anything
+func (s *transferService) Decline(ctx context.Context, tenantID, userID, transferID string) (*models.VideoTransfer, error) {
+	transfer, err := s.pending(ctx, tenantID, transferID, true)
+	if err != nil {
+		return nil, err
+	}
+	s.respond(transfer, models.TransferDeclined, userID)
+	if err := s.transfers.Update(ctx, transfer); err != nil {
+		return nil, fmt.Errorf("failed to update transfer: %w", err)
+	}
+	s.record(ctx, transfer, userID, models.AuditActionTransferDecline, fmt.Sprintf("Declined by tenant %s", tenantID))
+	s.notifyRequester(ctx, transfer, i18n.M("Video transfer declined"),
+		i18n.M("The transfer of video %s was declined by tenant %s", transfer.VideoID, tenantID))
+	return transfer, nil
+}
+
+// Cancel withdraws a transfer the recipient has not answered
+func (s *transferService) Cancel(ctx context.Context, tenantID, userID, transferID string) (*models.VideoTransfer, error) {
+	transfer, err := s.pending(ctx, tenantID, transferID, false)
+	if err != nil {
+		return nil, err
+	}
+	s.respond(transfer, models.TransferCancelled, userID)
+	if err := s.transfers.Update(ctx, transfer); err != nil {
+		return nil, fmt.Errorf("failed to update transfer: %w", err)
+	}
+	s.record(ctx, transfer, userID, models.AuditActionTransferCancel, fmt.Sprintf("Cancelled by tenant %s", tenantID))
+	return transfer, nil
+}
+
+// Get looks the transfer up among those sent, then those received
+func (s *transferService) Get(ctx context.Context, tenantID, transferID string) (*models.VideoTransfer, error) {
+	transfer, err := s.transfers.Get(ctx, tenantID, transferID)
+	if errors.Is(err, models.ErrTransferNotFound) {
+		transfer, err = s.transfers.GetIncoming(ctx, tenantID, transferID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.markExpired(transfer)
+	return transfer, nil
+}
+
+// List returns the transfers received, or sent, by the tenant
+func (s *transferService) List(ctx context.Context, tenantID string, incoming bool, status models.TransferStatus, limit, offset int) ([]*models.VideoTransfer, error) {
+	var transfers []*models.VideoTransfer
+	var err error
+	if incoming {
+		transfers, err = s.transfers.ListIncoming(ctx, tenantID, status, limit, offset)
+	} else {
+		transfers, err = s.transfers.ListOutgoing(ctx, tenantID, status, limit, offset)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+	for _, transfer := range transfers {
+		s.markExpired(transfer)
+	}
+	return transfers, nil
+}
+
+// pending returns the tenant's transfer, received or sent, when it can still
+// be answered. Transfers found expired are saved as such.
+func (s *transferService) pending(ctx context.Context, tenantID, transferID string, incoming bool) (*models.VideoTransfer, error) {
+	var transfer *models.VideoTransfer
+	var err error
+	if incoming {
+		transfer, err = s.transfers.GetIncoming(ctx, tenantID, transferID)
+	} else {
+		transfer, err = s.transfers.Get(ctx, tenantID, transferID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Pending(s.clock.Now()) {
+		return transfer, nil
+	}
+	if transfer.Status == string(models.TransferPending) {
+		if err := s.expire(ctx, transfer); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: it is %s", models.ErrTransferNotPending, transfer.Status)
+}
+
+// respond closes the transfer with the given status
+func (s *transferService) respond(transfer *models.VideoTransfer, status models.TransferStatus, userID string) {
+	now := s.clock.Now()
+	transfer.Status = string(status)
+	transfer.RespondedBy = userID
+	transfer.RespondedAt = &now
+}
+
+// expire saves a pending transfer past its expiry as expired
+func (s *transferService) expire(ctx context.Context, transfer *models.VideoTransfer) error {
+	transfer.Status = string(models.TransferExpired)
+	if err := s.transfers.Update(ctx, transfer); err != nil {
+		return fmt.Errorf("failed to expire transfer: %w", err)
+	}
+	return nil
+}
+
+// markExpired reports a pending transfer past its expiry as expired without
+// saving it; it is saved as such when answered
+func (s *transferService) markExpired(transfer *models.VideoTransfer) {
+	if transfer.Status == string(models.TransferPending) && !transfer.Pending(s.clock.Now()) {
+		transfer.Status = string(models.TransferExpired)
+	}
+}
+
+// record writes the step to the audit logs of both tenants. The sender's
+// entry is about its video, the recipient's about its copy once there is one.
+// Failures are logged: the step has already happened.
+func (s *transferService) record(ctx context.Context, transfer *models.VideoTransfer, userID, action, detail string) {
+	received := "/api/v1/transfers/" + transfer.ID
+	if transfer.TargetVideoID != "" {
+		received = models.VideoPath(transfer.TargetVideoID)
+	}
+	entries := []*models.AuditLog{
+		{TenantID: transfer.TenantID, UserID: userID, Action: action, Resource: models.VideoPath(transfer.VideoID), Detail: detail},
+		{TenantID: transfer.TargetTenantID, UserID: userID, Action: action, Resource: received, Detail: detail},
+	}
+	for _, entry := range entries {
+		if err := s.audit.Record(ctx, entry); err != nil {
+			s.logger.Error("Failed to record transfer in audit log", "error", err, "transfer_id", transfer.ID, "tenant_id", entry.TenantID, "action", action)
+		}
+	}
+}
+
+// notifyRequester tells the user who requested the transfer how it was
+// answered. Failures are logged, like those of the audit log.
+func (s *transferService) notifyRequester(ctx context.Context, transfer *models.VideoTransfer, subject, message i18n.Message) {
+	if err := s.notify.Notify(ctx, transfer.TenantID, transfer.RequestedBy, subject, message); err != nil {
+		s.logger.Error("Failed to notify transfer requester", "error", err, "transfer_id", transfer.ID, "user_id", transfer.RequestedBy)
+	}
+}
+
+// transferable checks the video's file can be copied
+func transferable(video *models.Video) error {
+	switch {
+	case video.ArchivedAt != nil:
+		return models.ErrVideoArchived
+	case video.Status == string(models.StatusUploading) || video.Status == string(models.StatusProcessing):
+		return models.ErrVideoProcessing
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memoryTransferRepo keeps transfers by ID
+type memoryTransferRepo struct {
+	models.VideoTransferRepository
+	transfers map[string]*models.VideoTransfer
+}
+
+func (r *memoryTransferRepo) Create(ctx context.Context, transfer *models.VideoTransfer) error {
+	copied := *transfer
+	r.transfers[transfer.ID] = &copied
+	return nil
+}
+
+func (r *memoryTransferRepo) find(match func(*models.VideoTransfer) bool) (*models.VideoTransfer, error) {
+	for _, transfer := range r.transfers {
+		if match(transfer) {
+			copied := *transfer
+			return &copied, nil
+		}
+	}
+	return nil, models.ErrTransferNotFound
+}
+
+func (r *memoryTransferRepo) Get(ctx context.Context, tenantID, id string) (*models.VideoTransfer, error) {
+	return r.find(func(t *models.VideoTransfer) bool { return t.ID == id && t.TenantID == tenantID })
+}
+
+func (r *memoryTransferRepo) GetIncoming(ctx context.Context, targetTenantID, id string) (*models.VideoTransfer, error) {
+	return r.find(func(t *models.VideoTransfer) bool { return t.ID == id && t.TargetTenantID == targetTenantID })
+}
+
+func (r *memoryTransferRepo) GetPendingForVideo(ctx context.Context, tenantID, videoID string) (*models.VideoTransfer, error) {
+	return r.find(func(t *models.VideoTransfer) bool {
+		return t.TenantID == tenantID && t.VideoID == videoID && t.Status == string(models.TransferPending)
+	})
+}
+
+func (r *memoryTransferRepo) Update(ctx context.Context, transfer *models.VideoTransfer) error {
+	copied := *transfer
+	r.transfers[transfer.ID] = &copied
+	return nil
+}
+
+// transferVideoRepo stores videos by tenant and ID
+type transferVideoRepo struct {
+	models.VideoRepository
+	videos map[string]*models.Video
+}
+
+func (r *transferVideoRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Video, error) {
+	if v, ok := r.videos[tenantID+"/"+id]; ok {
+		return v, nil
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+func (r *transferVideoRepo) Create(ctx context.Context, video *models.Video) error {
+	r.videos[video.TenantID+"/"+video.ID] = video
+	return nil
+}
+
+func (r *transferVideoRepo) Delete(ctx context.Context, tenantID, id string) error {
+	delete(r.videos, tenantID+"/"+id)
+	return nil
+}
+
+// transferStatsRepo holds the stats of videos and records the upserts
+type transferStatsRepo struct {
+	models.VideoStatsRepository
+	stats    []*models.VideoStats
+	upserted []*models.VideoStats
+}
+
+func (r *transferStatsRepo) GetByVideoID(ctx context.Context, tenantID, videoID string) ([]*models.VideoStats, error) {
+	var stats []*models.VideoStats
+	for _, st := range r.stats {
+		if st.TenantID == tenantID && st.VideoID == videoID {
+			stats = append(stats, st)
+		}
+	}
+	return stats, nil
+}
+
+func (r *transferStatsRepo) UpsertBatch(ctx context.Context, tenantID string, stats []*models.VideoStats, batchSize int) error {
+	r.upserted = append(r.upserted, stats...)
+	return nil
+}
+
+// recordingNotifications records the subjects notified to each user
+type recordingNotifications struct {
+	NotificationService
+	subjects map[string][]string
+}
+
+func (n *recordingNotifications) Notify(ctx context.Context, tenantID, userID string, subject, message i18n.Message) error {
+	n.subjects[userID] = append(n.subjects[userID], subject.In(i18n.Default))
+	return nil
+}
+
+type transferFixture struct {
+	transfers *memoryTransferRepo
+	videos    *transferVideoRepo
+	stats     *transferStatsRepo
+	audit     *memoryAuditRepo
+	notified  *recordingNotifications
+	storage   aws.ArchiveStorage
+	clock     *clock.Fake
+	svc       TransferService
+}
+
+func newTransferFixture(t *testing.T) *transferFixture {
+	log := logger.New("error", "test")
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{
+		"agency": {ID: "agency", Status: "active"},
+		"client": {ID: "client", Status: "active", Residency: "eu", ResidencyPinned: true},
+		"former": {ID: "former", Status: "suspended"},
+	}}
+	archivedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := &transferFixture{
+		transfers: &memoryTransferRepo{transfers: map[string]*models.VideoTransfer{}},
+		videos: &transferVideoRepo{videos: map[string]*models.Video{
+			"agency/launch":   {ID: "launch", TenantID: "agency", Title: "Launch", Status: string(models.StatusReady), S3Bucket: "videos-bucket", S3Key: "agency/videos/launch/master.mp4", YouTubeID: "yt-1"},
+			"agency/archived": {ID: "archived", TenantID: "agency", Status: string(models.StatusArchived), ArchivedAt: &archivedAt},
+			"agency/raw":      {ID: "raw", TenantID: "agency", Status: string(models.StatusProcessing)},
+		}},
+		stats: &transferStatsRepo{stats: []*models.VideoStats{
+			{ID: "st-1", TenantID: "agency", VideoID: "launch", Platform: "youtube", Views: 12000},
+		}},
+		audit:    &memoryAuditRepo{},
+		notified: &recordingNotifications{subjects: map[string][]string{}},
+		storage:  aws.NewFakeArchiveStorage(0, log),
+		clock:    clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)),
+	}
+	residency := NewResidencyService(tenants, newTestPlacements(t), log)
+	f.svc = NewTransferService(f.transfers, f.videos, f.stats, models.DefaultStatsBatchSize, tenants, f.storage, residency, NewAuditService(f.audit, log), f.notified, f.clock, log)
+	return f
+}
+
+func TestTransferService_Request(t *testing.T) {
+	f := newTransferFixture(t)
+	ctx := context.Background()
+
+	_, err := f.svc.Request(ctx, "agency", "u-1", "launch", &models.CreateTransferRequest{TargetTenantID: "agency"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = f.svc.Request(ctx, "agency", "u-1", "launch", &models.CreateTransferRequest{TargetTenantID: "client", Mode: "share"})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = f.svc.Request(ctx, "agency", "u-1", "launch", &models.CreateTransferRequest{TargetTenantID: "nobody"})
+	assert.ErrorIs(t, err, models.ErrTenantNotFound)
+	_, err = f.svc.Request(ctx, "agency", "u-1", "launch", &models.CreateTransferRequest{TargetTenantID: "former"})
+	assert.ErrorIs(t, err, models.ErrTenantInactive)
+	_, err = f.svc.Request(ctx, "agency", "u-1", "archived", &models.CreateTransferRequest{TargetTenantID: "client"})
+	assert.ErrorIs(t, err, models.ErrVideoArchived)
+	_, err = f.svc.Request(ctx, "agency", "u-1", "raw", &models.CreateTransferRequest{TargetTenantID: "client"})
+	assert.ErrorIs(t, err, models.ErrVideoProcessing)
+
+	transfer, err := f.svc.Request(ctx, "agency", "u-1", "launch", &models.CreateTransferRequest{TargetTenantID: "client", Message: "Final cut"})
+	require.NoError(t, err)
+	assert.Equal(t, string(models.TransferCopy), transfer.Mode)
+	assert.Equal(t, string(models.TransferPending), transfer.Status)
+	assert.Equal(t, f.clock.Now().Add(models.VideoTransferTTL), transfer.ExpiresAt)
+
+	_, err = f.svc.Request(ctx, "agency", "u-1", "launch", &models.CreateTransferRequest{TargetTenantID: "client"})
+	assert.ErrorIs(t, err, models.ErrTransferInProgress)
+
+	f.clock.Advance(models.VideoTransferTTL)
+	again, err := f.svc.Request(ctx, "agency", "u-1", "launch", &models.CreateTransferRequest{TargetTenantID: "client"})
+	require.NoError(t, err, "an expired transfer does not block a new one")
+	assert.Equal(t, string(models.TransferExpired), f.transfers.transfers[transfer.ID].Status)
+
+	_, err = f.svc.Accept(ctx, "client", "u-2", transfer.ID)
+	assert.ErrorIs(t, err, models.ErrTransferNotPending)
+	_, err = f.svc.Accept(ctx, "agency", "u-1", again.ID)
+	assert.ErrorIs(t, err, models.ErrTransferNotFound, "only the recipient accepts")
+
+	require.Len(t, f.audit.entries, 4)
+	assert.Equal(t, models.AuditActionTransferRequest, f.audit.entries[0].Action)
+	assert.Equal(t, "agency", f.audit.entries[0].TenantID)
+	assert.Equal(t, models.VideoPath("launch"), f.audit.entries[0].Resource)
+	assert.Equal(t, "client", f.audit.entries[1].TenantID)
+}
+
+func TestTransferService_AcceptMove(t *testing.T) {
+	f := newTransferFixture(t)
+	ctx := context.Background()
+
+	transfer, err := f.svc.Request(ctx, "agency", "u-1", "launch", &models.CreateTransferRequest{TargetTenantID: "client", Mode: models.TransferMove, IncludeStats: true})
+	require.NoError(t, err)
+
+	accepted, err := f.svc.Accept(ctx, "client", "u-2", transfer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.TransferCompleted), accepted.Status)
+	assert.Equal(t, "u-2", accepted.RespondedBy)
+
+	received, err := f.videos.GetByID(ctx, "client", accepted.TargetVideoID)
+	require.NoError(t, err)
+	assert.Equal(t, "Launch", received.Title)
+	assert.Equal(t, "u-2", received.UserID)
+	assert.Empty(t, received.YouTubeID, "publications stay with the agency")
+	assert.Equal(t, "videos-eu", received.S3Bucket, "the file lands in the client's residency")
+	assert.Equal(t, "client/videos/"+received.ID+"/master.mp4", received.S3Key)
+	status, err := f.storage.Status(ctx, received.S3Bucket, received.S3Key)
+	require.NoError(t, err)
+	assert.Equal(t, aws.StorageClassStandard, status.StorageClass)
+
+	require.Len(t, f.stats.upserted, 1)
+	assert.Equal(t, "client", f.stats.upserted[0].TenantID)
+	assert.Equal(t, received.ID, f.stats.upserted[0].VideoID)
+	assert.Empty(t, f.stats.upserted[0].ID)
+	assert.Equal(t, int64(12000), f.stats.upserted[0].Views)
+
+	_, err = f.videos.GetByID(ctx, "agency", "launch")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound, "a move removes the agency's video")
+
+	_, err = f.svc.Decline(ctx, "client", "u-2", transfer.ID)
+	assert.ErrorIs(t, err, models.ErrTransferNotPending)
+
+	accept := f.audit.entries[len(f.audit.entries)-1]
+	assert.Equal(t, models.AuditActionTransferAccept, accept.Action)
+	assert.Equal(t, "client", accept.TenantID)
+	assert.Equal(t, models.VideoPath(received.ID), accept.Resource)
+	assert.Equal(t, []string{"Video transfer accepted"}, f.notified.subjects["u-1"])
+}
+
+func TestTransferService_DeclineAndCancel(t *testing.T) {
+	f := newTransferFixture(t)
+	ctx := context.Background()
+
+	declined, err := f.svc.Request(ctx, "agency", "u-1", "launch", &models.CreateTransferRequest{TargetTenantID: "client"})
+	require.NoError(t, err)
+	_, err = f.svc.Decline(ctx, "agency", "u-1", declined.ID)
+	assert.ErrorIs(t, err, models.ErrTransferNotFound, "only the recipient declines")
+	declined, err = f.svc.Decline(ctx, "client", "u-2", declined.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.TransferDeclined), declined.Status)
+	assert.Equal(t, []string{"Video transfer declined"}, f.notified.subjects["u-1"])
+
+	cancelled, err := f.svc.Request(ctx, "agency", "u-1", "launch", &models.CreateTransferRequest{TargetTenantID: "client"})
+	require.NoError(t, err)
+	_, err = f.svc.Cancel(ctx, "client", "u-2", cancelled.ID)
+	assert.ErrorIs(t, err, models.ErrTransferNotFound, "only the sender cancels")
+	cancelled, err = f.svc.Cancel(ctx, "agency", "u-1", cancelled.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.TransferCancelled), cancelled.Status)
+
+	got, err := f.svc.Get(ctx, "client", cancelled.ID)
+	require.NoError(t, err, "the recipient sees the transfers sent to it")
+	assert.Equal(t, string(models.TransferCancelled), got.Status)
+	assert.Len(t, f.videos.videos, 3, "nothing was copied")
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+const (
+	// defaultPartSize is the size of the parts of uploads small enough to
+	// need at most aws.MaxParts of them; larger uploads get larger parts
+	defaultPartSize = 64 << 20
+	// maxUploadSize is the largest object S3 stores, 5 TiB
+	maxUploadSize = 5 << 40
+	// partURLExpiry is how long a presigned part URL can be used
+	partURLExpiry = time.Hour
+)
+
+// uploadService implements the UploadService interface
+type uploadService struct {
+	// partSize is defaultPartSize but in tests
+	partSize   int64
+	uploads    models.VideoUploadRepository
+	videos     models.VideoRepository
+	workspaces models.WorkspaceRepository
+	storage    aws.MultipartStorage
+	residency  ResidencyService
+	jobs       JobService
+	clock      clock.Clock
+	logger     *logger.Logger
+}
+
+var _ UploadService = (*uploadService)(nil)
+
+// NewUploadService creates a new multipart upload service
+func NewUploadService(uploads models.VideoUploadRepository, videos models.VideoRepository, workspaces models.WorkspaceRepository, storage aws.MultipartStorage, residency ResidencyService, jobs JobService, clock clock.Clock, logger *logger.Logger) UploadService {
+	return &uploadService{
+		partSize:   defaultPartSize,
+		uploads:    uploads,
+		videos:     videos,
+		workspaces: workspaces,
+		storage:    storage,
+		residency:  residency,
+		jobs:       jobs,
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+// Start starts a multipart upload of the video's file to the bucket of the
+// tenant's residency
+func (s *uploadService) Start(ctx context.Context, viewer *models.User, videoID string, req *models.StartUploadRequest) (*UploadProgress, error) {
+	tenantID, userID := viewer.TenantID, viewer.ID
+	if req.Size > maxUploadSize {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the file must be at most %d bytes", int64(maxUploadSize))
+	}
+	filename := path.Base(req.Filename)
+	if filename == "." || filename == "/" {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "invalid filename")
+	}
+
+	if _, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID); err != nil {
+		return nil, err
+	}
+	if _, err := s.uploads.GetActive(ctx, tenantID, videoID); err == nil {
+		return nil, i18n.Errorf(models.ErrConflict, "the video already has an upload in progress; resume or abort it")
+	} else if !errors.Is(err, models.ErrVideoUploadNotFound) {
+		return nil, fmt.Errorf("failed to get active upload: %w", err)
+	}
+
+	tr, err := s.residency.GetResidency(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tr.Placement.S3Bucket == "" {
+		return nil, fmt.Errorf("no S3 bucket configured for residency %s", tr.Residency)
+	}
+
+	partSize := uploadPartSize(req.Size, s.partSize)
+	upload := &models.VideoUpload{
+		TenantID:    tenantID,
+		VideoID:     videoID,
+		UserID:      userID,
+		Status:      models.UploadActive,
+		Filename:    filename,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		PartSize:    partSize,
+		PartCount:   int((req.Size + partSize - 1) / partSize),
+		S3Bucket:    tr.Placement.S3Bucket,
+		S3Key:       fmt.Sprintf("%s/videos/%s/%s", tenantID, videoID, filename),
+	}
+	upload.S3UploadID, err = s.storage.CreateMultipartUpload(ctx, upload.S3Bucket, upload.S3Key, upload.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+	if err := s.uploads.Create(ctx, upload); err != nil {
+		// Do not leave parts nobody can complete in the bucket
+		if abortErr := s.storage.AbortMultipartUpload(ctx, upload.S3Bucket, upload.S3Key, upload.S3UploadID); abortErr != nil {
+			s.logger.Error("Failed to abort orphaned multipart upload", "error", abortErr, "video_id", videoID, "tenant_id", tenantID)
+		}
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+
+	s.logger.Info("Multipart upload started", "upload_id", upload.ID, "video_id", videoID, "tenant_id", tenantID, "size", upload.Size, "parts", upload.PartCount)
+	return newUploadProgress(upload, nil), nil
+}
+
+// Get returns the video's active upload with the parts S3 holds, to resume it
+func (s *uploadService) Get(ctx context.Context, viewer *models.User, videoID string) (*UploadProgress, error) {
+	if _, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID); err != nil {
+		return nil, err
+	}
+	upload, parts, err := s.activeParts(ctx, viewer.TenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	return newUploadProgress(upload, parts), nil
+}
+
+// PresignParts returns URLs the client PUTs the parts to, straight to S3
+func (s *uploadService) PresignParts(ctx context.Context, viewer *models.User, videoID string, partNumbers []int) ([]*PresignedPart, error) {
+	upload, err := s.activeUpload(ctx, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := s.clock.Now().Add(partURLExpiry)
+	presigned := make([]*PresignedPart, 0, len(partNumbers))
+	for _, number := range partNumbers {
+		if err := checkPartNumber(upload, number); err != nil {
+			return nil, err
+		}
+		url, err := s.storage.PresignUploadPart(ctx, upload.S3Bucket, upload.S3Key, upload.S3UploadID, number, partURLExpiry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to presign part %d: %w", number, err)
+		}
+		presigned = append(presigned, &PresignedPart{PartNumber: number, URL: url, ExpiresAt: expiresAt})
+	}
+	return presigned, nil
+}
+
+// UploadPart sends one part through the API. Only one part is held in
+// memory, and it must have the size the upload planned for it.
+func (s *uploadService) UploadPart(ctx context.Context, viewer *models.User, videoID string, partNumber int, body io.Reader) (*aws.UploadedPart, error) {
+	upload, err := s.activeUpload(ctx, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPartNumber(upload, partNumber); err != nil {
+		return nil, err
+	}
+
+	want := partLength(upload, partNumber)
+	data, err := io.ReadAll(io.LimitReader(body, want+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read part: %w", err)
+	}
+	if int64(len(data)) != want {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "part %d must be %d bytes", partNumber, want)
+	}
+
+	etag, err := s.storage.UploadPart(ctx, upload.S3Bucket, upload.S3Key, upload.S3UploadID, partNumber, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+	return &aws.UploadedPart{PartNumber: partNumber, ETag: etag, Size: want}, nil
+}
+
+// Complete assembles the parts into the video's file once S3 holds all of
+// them, and starts processing the video
+func (s *uploadService) Complete(ctx context.Context, viewer *models.User, videoID string) (*models.Video, error) {
+	tenantID := viewer.TenantID
+	video, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID)
+	if err != nil {
+		return nil, err
+	}
+	upload, parts, err := s.activeParts(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if missing := missingParts(upload, parts); len(missing) > 0 {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "%d parts are missing, starting with part %d", len(missing), missing[0])
+	}
+	for _, part := range parts {
+		if part.Size != partLength(upload, part.PartNumber) {
+			return nil, i18n.Errorf(models.ErrInvalidInput, "part %d must be %d bytes", part.PartNumber, partLength(upload, part.PartNumber))
+		}
+	}
+
+	if err := s.storage.CompleteMultipartUpload(ctx, upload.S3Bucket, upload.S3Key, upload.S3UploadID, parts); err != nil {
+		return nil, fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	if err := s.uploads.SetStatus(ctx, tenantID, upload.ID, models.UploadCompleted); err != nil {
+		return nil, fmt.Errorf("failed to complete upload: %w", err)
+	}
+
+	video.S3Bucket = upload.S3Bucket
+	video.S3Key = upload.S3Key
+	video.Status = string(models.StatusProcessing)
+	video.UpdatedAt = s.clock.Now()
+	if err := s.videos.Update(ctx, video); err != nil {
+		s.logger.Error("Failed to update uploaded video", "error", err, "video_id", videoID, "tenant_id", tenantID)
+		return nil, fmt.Errorf("failed to update video: %w", err)
+	}
+	s.jobs.Start(ctx, tenantID, video.UserID, models.TranscodeJob(videoID))
+
+	s.logger.Info("Multipart upload completed", "upload_id", upload.ID, "video_id", videoID, "tenant_id", tenantID, "size", upload.Size)
+	return video, nil
+}
+
+// Abort discards the video's active upload and the parts S3 holds
+func (s *uploadService) Abort(ctx context.Context, viewer *models.User, videoID string) error {
+	tenantID := viewer.TenantID
+	upload, err := s.activeUpload(ctx, viewer, videoID)
+	if err != nil {
+		return err
+	}
+	if err := s.storage.AbortMultipartUpload(ctx, upload.S3Bucket, upload.S3Key, upload.S3UploadID); err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	if err := s.uploads.SetStatus(ctx, tenantID, upload.ID, models.UploadAborted); err != nil {
+		return err
+	}
+
+	s.logger.Info("Multipart upload aborted", "upload_id", upload.ID, "video_id", videoID, "tenant_id", tenantID)
+	return nil
+}
+
+// activeUpload returns the active upload of a video the viewer sees
+func (s *uploadService) activeUpload(ctx context.Context, viewer *models.User, videoID string) (*models.VideoUpload, error) {
+	if _, err := visibleVideo(ctx, s.videos, s.workspaces, viewer, videoID); err != nil {
+		return nil, err
+	}
+	return s.uploads.GetActive(ctx, viewer.TenantID, videoID)
+}
+
+// activeParts returns the video's active upload with the parts S3 holds. An
+// upload S3 no longer knows, such as one a bucket lifecycle rule aborted, is
+// marked aborted.
+func (s *uploadService) activeParts(ctx context.Context, tenantID, videoID string) (*models.VideoUpload, []aws.UploadedPart, error) {
+	upload, err := s.uploads.GetActive(ctx, tenantID, videoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	parts, err := s.storage.ListParts(ctx, upload.S3Bucket, upload.S3Key, upload.S3UploadID)
+	if errors.Is(err, aws.ErrObjectNotFound) {
+		if err := s.uploads.SetStatus(ctx, tenantID, upload.ID, models.UploadAborted); err != nil && !errors.Is(err, models.ErrVideoUploadNotFound) {
+			return nil, nil, err
+		}
+		return nil, nil, models.ErrVideoUploadNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list uploaded parts: %w", err)
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return upload, parts, nil
+}
+
+// uploadPartSize is partSize, or the smallest whole number of MiB splitting
+// size into at most aws.MaxParts parts
+func uploadPartSize(size, partSize int64) int64 {
+	if size > partSize*aws.MaxParts {
+		const mib = 1 << 20
+		partSize = ((size+aws.MaxParts-1)/aws.MaxParts + mib - 1) / mib * mib
+	}
+	return partSize
+}
+
+// partLength is the size of the part: PartSize, but for the last part which
+// holds the rest
+func partLength(upload *models.VideoUpload, partNumber int) int64 {
+	if partNumber == upload.PartCount {
+		return upload.Size - int64(upload.PartCount-1)*upload.PartSize
+	}
+	return upload.PartSize
+}
+
+func checkPartNumber(upload *models.VideoUpload, partNumber int) error {
+	if partNumber < 1 || partNumber > upload.PartCount {
+		return i18n.Errorf(models.ErrInvalidInput, "part number %d is not between 1 and %d", partNumber, upload.PartCount)
+	}
+	return nil
+}
+
+// missingParts returns the numbers of the parts S3 does not hold yet
+func missingParts(upload *models.VideoUpload, parts []aws.UploadedPart) []int {
+	uploaded := make(map[int]bool, len(parts))
+	for _, part := range parts {
+		uploaded[part.PartNumber] = true
+	}
+	missing := []int{}
+	for number := 1; number <= upload.PartCount; number++ {
+		if !uploaded[number] {
+			missing = append(missing, number)
+		}
+	}
+	return missing
+}
+
+func newUploadProgress(upload *models.VideoUpload, parts []aws.UploadedPart) *UploadProgress {
+	progress := &UploadProgress{Upload: upload, Parts: parts, MissingParts: missingParts(upload, parts)}
+	if progress.Parts == nil {
+		progress.Parts = []aws.UploadedPart{}
+	}
+	for _, part := range parts {
+		progress.UploadedBytes += part.Size
+	}
+	return progress
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memoryUploadRepo stores uploads by ID
+type memoryUploadRepo struct {
+	uploads map[string]*models.VideoUpload
+}
+
+func (r *memoryUploadRepo) Create(ctx context.Context, upload *models.VideoUpload) error {
+	upload.ID = fmt.Sprintf("upload-%d", len(r.uploads)+1)
+	r.uploads[upload.ID] = upload
+	return nil
+}
+
+func (r *memoryUploadRepo) GetActive(ctx context.Context, tenantID, videoID string) (*models.VideoUpload, error) {
+	for _, u := range r.uploads {
+		if u.TenantID == tenantID && u.VideoID == videoID && u.Status == models.UploadActive {
+			return u, nil
+		}
+	}
+	return nil, models.ErrVideoUploadNotFound
+}
+
+func (r *memoryUploadRepo) SetStatus(ctx context.Context, tenantID, id string, status models.VideoUploadStatus) error {
+	u, ok := r.uploads[id]
+	if !ok || u.TenantID != tenantID || u.Status != models.UploadActive {
+		return models.ErrVideoUploadNotFound
+	}
+	u.Status = status
+	return nil
+}
+
+type uploadFixture struct {
+	uploads *memoryUploadRepo
+	videos  *archiveVideoRepo
+	storage *memoryMultipartStorage
+	jobs    *memoryJobRepo
+	viewer  *models.User
+	svc     *uploadService
+}
+
+func newUploadFixture(t *testing.T) *uploadFixture {
+	now := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	f := &uploadFixture{
+		uploads: &memoryUploadRepo{uploads: map[string]*models.VideoUpload{}},
+		videos: &archiveVideoRepo{clock: now, videos: map[string]*models.Video{
+			"video-1": {ID: "video-1", TenantID: "tenant-1", UserID: "user-1", Status: string(models.StatusUploading)},
+		}},
+		storage: newMemoryMultipartStorage(),
+		viewer:  &models.User{ID: "user-1", TenantID: "tenant-1"},
+	}
+	tenants := &memoryTenantRepo{tenants: map[string]*models.Tenant{}}
+	residencies := NewResidencyService(tenants, newTestPlacements(t), logger.New("error", "test"))
+	var jobs JobService
+	f.jobs, jobs = newTestJobs(now)
+	f.svc = NewUploadService(f.uploads, f.videos, nil, f.storage, residencies, jobs, now, logger.New("error", "test")).(*uploadService)
+	f.svc.partSize = aws.MinPartSize
+	return f
+}
+
+// uploadPartBody returns the content of the part of a file of size bytes
+// whose parts are partSize bytes
+func uploadPartBody(number int, partSize, size int64) []byte {
+	start := int64(number-1) * partSize
+	return bytes.Repeat([]byte{byte('a' + number - 1)}, int(min(partSize, size-start)))
+}
+
+func TestUploadService_Flow(t *testing.T) {
+	f := newUploadFixture(t)
+	ctx := context.Background()
+	size := int64(2*aws.MinPartSize + 10)
+
+	progress, err := f.svc.Start(ctx, f.viewer, "video-1", &models.StartUploadRequest{Filename: "../clips/trailer.mp4", ContentType: "video/mp4", Size: size})
+	require.NoError(t, err)
+	upload := progress.Upload
+	assert.Equal(t, "trailer.mp4", upload.Filename, "directories are dropped")
+	assert.Equal(t, "videos-bucket", upload.S3Bucket)
+	assert.Equal(t, "tenant-1/videos/video-1/trailer.mp4", upload.S3Key)
+	assert.Equal(t, int64(aws.MinPartSize), upload.PartSize)
+	assert.Equal(t, 3, upload.PartCount)
+	assert.Equal(t, []int{1, 2, 3}, progress.MissingParts)
+
+	_, err = f.svc.Start(ctx, f.viewer, "video-1", &models.StartUploadRequest{Filename: "trailer.mp4", Size: size})
+	assert.ErrorIs(t, err, models.ErrConflict, "one upload at a time")
+
+	presigned, err := f.svc.PresignParts(ctx, f.viewer, "video-1", []int{1, 3})
+	require.NoError(t, err)
+	require.Len(t, presigned, 2)
+	assert.Equal(t, 3, presigned[1].PartNumber)
+	assert.Contains(t, presigned[1].URL, "partNumber=3")
+	assert.Equal(t, time.Date(2026, 10, 15, 13, 0, 0, 0, time.UTC), presigned[0].ExpiresAt)
+	_, err = f.svc.PresignParts(ctx, f.viewer, "video-1", []int{4})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+
+	for number := 1; number <= 3; number++ {
+		uploaded, err := f.svc.UploadPart(ctx, f.viewer, "video-1", number, bytes.NewReader(uploadPartBody(number, aws.MinPartSize, size)))
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf(`"etag-%d"`, number), uploaded.ETag)
+	}
+
+	video, err := f.svc.Complete(ctx, f.viewer, "video-1")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.StatusProcessing), video.Status)
+	assert.Equal(t, "tenant-1/videos/video-1/trailer.mp4", video.S3Key)
+	assert.Equal(t, models.UploadCompleted, f.uploads.uploads[upload.ID].Status)
+	object := f.storage.completed["videos-bucket/tenant-1/videos/video-1/trailer.mp4"]
+	assert.Len(t, object, int(size))
+	assert.Equal(t, byte('c'), object[size-1], "parts are assembled in order")
+	require.Len(t, f.jobs.jobs, 1)
+	assert.Equal(t, string(models.JobTranscode), f.jobs.jobs[0].Type)
+
+	_, err = f.svc.Get(ctx, f.viewer, "video-1")
+	assert.ErrorIs(t, err, models.ErrVideoUploadNotFound)
+}
+
+func TestUploadService_Resume(t *testing.T) {
+	f := newUploadFixture(t)
+	ctx := context.Background()
+	size := int64(3 * aws.MinPartSize)
+
+	_, err := f.svc.Start(ctx, f.viewer, "video-1", &models.StartUploadRequest{Filename: "clip.mp4", Size: size})
+	require.NoError(t, err)
+	_, err = f.svc.UploadPart(ctx, f.viewer, "video-1", 2, bytes.NewReader(uploadPartBody(2, aws.MinPartSize, size)))
+	require.NoError(t, err)
+
+	progress, err := f.svc.Get(ctx, f.viewer, "video-1")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, progress.MissingParts)
+	assert.Equal(t, int64(aws.MinPartSize), progress.UploadedBytes)
+
+	_, err = f.svc.Complete(ctx, f.viewer, "video-1")
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "2 parts are missing, starting with part 1")
+	assert.Equal(t, string(models.StatusUploading), f.videos.videos["video-1"].Status)
+
+	_, err = f.svc.UploadPart(ctx, f.viewer, "video-1", 1, strings.NewReader("too short"))
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "parts have the planned size")
+}
+
+func TestUploadService_AbortAndExpiredUploads(t *testing.T) {
+	f := newUploadFixture(t)
+	ctx := context.Background()
+
+	_, err := f.svc.Start(ctx, f.viewer, "video-1", &models.StartUploadRequest{Filename: "clip.mp4", Size: 10})
+	require.NoError(t, err)
+	require.NoError(t, f.svc.Abort(ctx, f.viewer, "video-1"))
+	assert.Empty(t, f.storage.parts)
+	assert.ErrorIs(t, f.svc.Abort(ctx, f.viewer, "video-1"), models.ErrVideoUploadNotFound)
+
+	progress, err := f.svc.Start(ctx, f.viewer, "video-1", &models.StartUploadRequest{Filename: "clip.mp4", Size: 10})
+	require.NoError(t, err, "an aborted upload can be started again")
+	// A lifecycle rule aborts the upload in S3
+	f.storage.parts = map[string]map[int][]byte{}
+	_, err = f.svc.Get(ctx, f.viewer, "video-1")
+	assert.ErrorIs(t, err, models.ErrVideoUploadNotFound)
+	assert.Equal(t, models.UploadAborted, f.uploads.uploads[progress.Upload.ID].Status)
+
+	_, err = f.svc.Start(ctx, f.viewer, "missing", &models.StartUploadRequest{Filename: "clip.mp4", Size: 10})
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+	_, err = f.svc.Start(ctx, f.viewer, "video-1", &models.StartUploadRequest{Filename: "clip.mp4", Size: maxUploadSize + 1})
+	assert.True(t, errors.Is(err, models.ErrInvalidInput))
+}
+
+func TestUploadPartSize(t *testing.T) {
+	assert.Equal(t, int64(defaultPartSize), uploadPartSize(1, defaultPartSize))
+	assert.Equal(t, int64(defaultPartSize), uploadPartSize(defaultPartSize*aws.MaxParts, defaultPartSize))
+	large := uploadPartSize(maxUploadSize, defaultPartSize)
+	assert.LessOrEqual(t, (maxUploadSize+large-1)/large, int64(aws.MaxParts))
+	assert.Zero(t, large%(1<<20))
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+const (
+	// exportPageSize is how many videos an export loads at a time
+	exportPageSize = 200
+	// maxExportVideos bounds the videos of one export, newest first
+	maxExportVideos = 10000
+)
+
+// videoExportService implements the VideoExportService interface
+type videoExportService struct {
+	videos VideoService
+	lists  SmartListService
+	logger *logger.Logger
+}
+
+var _ VideoExportService = (*videoExportService)(nil)
+
+// NewVideoExportService creates a video export service reading through the
+// video and smart list services, so exports hold what the viewer sees
+func NewVideoExportService(videos VideoService, lists SmartListService, logger *logger.Logger) VideoExportService {
+	return &videoExportService{videos: videos, lists: lists, logger: logger}
+}
+
+// Export pages through the videos newest first until fn fails or the last
+// page is read
+func (s *videoExportService) Export(ctx context.Context, viewer *models.User, smartListID string, fn func(*models.Video) error) error {
+	for offset := 0; offset < maxExportVideos; offset += exportPageSize {
+		limit := min(exportPageSize, maxExportVideos-offset)
+		var videos []*models.Video
+		var err error
+		if smartListID != "" {
+			videos, err = s.lists.ListVideos(ctx, viewer, smartListID, limit, offset)
+		} else {
+			videos, err = s.videos.ListMatching(ctx, viewer, nil, limit, offset)
+		}
+		if err != nil {
+			return err
+		}
+		for _, video := range videos {
+			if err := fn(video); err != nil {
+				return err
+			}
+		}
+		if len(videos) < limit {
+			return nil
+		}
+	}
+	s.logger.Warn("Video export truncated", "tenant_id", viewer.TenantID, "smart_list_id", smartListID, "videos", maxExportVideos)
+	return nil
+}
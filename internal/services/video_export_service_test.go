@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// pagedVideoService serves count videos by page, recording the pages asked
+type pagedVideoService struct {
+	VideoService
+	count int
+	pages []int
+}
+
+func (s *pagedVideoService) ListMatching(ctx context.Context, viewer *models.User, conditions []models.SmartListCondition, limit, offset int) ([]*models.Video, error) {
+	s.pages = append(s.pages, offset)
+	var videos []*models.Video
+	for i := offset; i < min(offset+limit, s.count); i++ {
+		videos = append(videos, &models.Video{ID: fmt.Sprintf("video-%d", i)})
+	}
+	return videos, nil
+}
+
+// exportedList serves one video for the smart list "list-1"
+type exportedList struct {
+	SmartListService
+}
+
+func (s *exportedList) ListVideos(ctx context.Context, viewer *models.User, id string, limit, offset int) ([]*models.Video, error) {
+	if id != "list-1" {
+		return nil, models.ErrSmartListNotFound
+	}
+	return []*models.Video{{ID: "heist"}}, nil
+}
+
+func TestVideoExportService(t *testing.T) {
+	viewer := &models.User{ID: "user-1", TenantID: "acme"}
+	videos := &pagedVideoService{count: exportPageSize + 1}
+	svc := NewVideoExportService(videos, &exportedList{}, logger.New("error", "test"))
+	ctx := context.Background()
+
+	var exported []string
+	err := svc.Export(ctx, viewer, "", func(video *models.Video) error {
+		exported = append(exported, video.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, exported, exportPageSize+1)
+	assert.Equal(t, []int{0, exportPageSize}, videos.pages, "a short page ends the export")
+
+	videos.count, videos.pages = maxExportVideos+5, nil
+	exported = nil
+	require.NoError(t, svc.Export(ctx, viewer, "", func(video *models.Video) error {
+		exported = append(exported, video.ID)
+		return nil
+	}))
+	assert.Len(t, exported, maxExportVideos)
+
+	stop := errors.New("client gone")
+	videos.pages = nil
+	err = svc.Export(ctx, viewer, "", func(video *models.Video) error { return stop })
+	assert.ErrorIs(t, err, stop)
+	assert.Len(t, videos.pages, 1)
+
+	exported = nil
+	require.NoError(t, svc.Export(ctx, viewer, "list-1", func(video *models.Video) error {
+		exported = append(exported, video.ID)
+		return nil
+	}))
+	assert.Equal(t, []string{"heist"}, exported)
+	assert.ErrorIs(t, svc.Export(ctx, viewer, "list-2", func(*models.Video) error { return nil }), models.ErrSmartListNotFound)
+}
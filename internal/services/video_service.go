@@ -2,25 +2,37 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"time"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
 	"github.com/jibe0123/mysteryfactory/pkg/logger"
 )
 
 // videoService implements the VideoService interface
 type videoService struct {
-	repo   models.VideoRepository
-	logger *logger.Logger
+	repo       models.VideoRepository
+	users      models.UserRepository
+	workspaces models.WorkspaceRepository
+	jobs       JobService
+	clock      clock.Clock
+	logger     *logger.Logger
 }
 
+var _ VideoService = (*videoService)(nil)
+
 // NewVideoService creates a new video service instance
-func NewVideoService(repo models.VideoRepository, logger *logger.Logger) VideoService {
+func NewVideoService(repo models.VideoRepository, users models.UserRepository, workspaces models.WorkspaceRepository, jobs JobService, clock clock.Clock, logger *logger.Logger) VideoService {
 	return &videoService{
-		repo:   repo,
-		logger: logger,
+		repo:       repo,
+		users:      users,
+		workspaces: workspaces,
+		jobs:       jobs,
+		clock:      clock,
+		logger:     logger,
 	}
 }
 
@@ -35,10 +47,22 @@ func (s *videoService) CreateVideo(ctx context.Context, tenantID, userID string,
 	if req.FileName == "" {
 		return nil, fmt.Errorf("file name is required")
 	}
+	if req.Rights != nil {
+		if err := req.Rights.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = models.VisibilityTenant
+	}
+	if err := s.checkVisibility(ctx, tenantID, visibility, req.WorkspaceID); err != nil {
+		return nil, err
+	}
 
 	// Create video entity
 	video := &models.Video{
-		ID:          generateID(),
+		ID:          id.New(),
 		TenantID:    tenantID,
 		UserID:      userID,
 		Title:       req.Title,
@@ -46,18 +70,24 @@ func (s *videoService) CreateVideo(ctx context.Context, tenantID, userID string,
 		FileName:    req.FileName,
 		FileSize:    req.FileSize,
 		Format:      req.Format,
+		CampaignID:  req.CampaignID,
+		Visibility:  visibility,
+		WorkspaceID: req.WorkspaceID,
 		Status:      string(models.StatusUploading),
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		CreatedAt:   s.clock.Now(),
+		UpdatedAt:   s.clock.Now(),
 	}
 
 	// Convert tags to JSON if provided
 	if len(req.Tags) > 0 {
-		video.Tags = convertTagsToJSON(req.Tags)
+		video.Tags = req.Tags
+	}
+	if req.Rights != nil {
+		video.Rights = *req.Rights
 	}
 
 	// Save to repository
-	if err := s.repo.Create(video); err != nil {
+	if err := s.repo.Create(ctx, video); err != nil {
 		s.logger.Error("Failed to create video", "error", err, "tenant_id", tenantID)
 		return nil, fmt.Errorf("failed to create video: %w", err)
 	}
@@ -67,12 +97,12 @@ func (s *videoService) CreateVideo(ctx context.Context, tenantID, userID string,
 }
 
 // GetVideo retrieves a video by ID
-func (s *videoService) GetVideo(ctx context.Context, tenantID, videoID string) (*models.Video, error) {
-	s.logger.Debug("Getting video", "video_id", videoID, "tenant_id", tenantID)
+func (s *videoService) GetVideo(ctx context.Context, viewer *models.User, videoID string) (*models.Video, error) {
+	s.logger.Debug("Getting video", "video_id", videoID, "tenant_id", viewer.TenantID)
 
-	video, err := s.repo.GetByID(tenantID, videoID)
+	video, err := s.visibleVideo(ctx, viewer, videoID)
 	if err != nil {
-		s.logger.Error("Failed to get video", "error", err, "video_id", videoID, "tenant_id", tenantID)
+		s.logger.Error("Failed to get video", "error", err, "video_id", videoID, "tenant_id", viewer.TenantID)
 		return nil, fmt.Errorf("failed to get video: %w", err)
 	}
 
@@ -80,11 +110,11 @@ func (s *videoService) GetVideo(ctx context.Context, tenantID, videoID string) (
 }
 
 // UpdateVideo updates an existing video
-func (s *videoService) UpdateVideo(ctx context.Context, tenantID, videoID string, req *models.UpdateVideoRequest) (*models.Video, error) {
-	s.logger.Info("Updating video", "video_id", videoID, "tenant_id", tenantID)
+func (s *videoService) UpdateVideo(ctx context.Context, viewer *models.User, videoID string, req *models.UpdateVideoRequest) (*models.Video, error) {
+	s.logger.Info("Updating video", "video_id", videoID, "tenant_id", viewer.TenantID)
 
 	// Get existing video
-	video, err := s.repo.GetByID(tenantID, videoID)
+	video, err := s.visibleVideo(ctx, viewer, videoID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get video: %w", err)
 	}
@@ -97,25 +127,145 @@ func (s *videoService) UpdateVideo(ctx context.Context, tenantID, videoID string
 		video.Description = *req.Description
 	}
 	if len(req.Tags) > 0 {
-		video.Tags = convertTagsToJSON(req.Tags)
+		video.Tags = req.Tags
+	}
+	if req.Rights != nil {
+		if err := req.Rights.Validate(); err != nil {
+			return nil, err
+		}
+		video.Rights = *req.Rights
+	}
+	if req.CampaignID != nil {
+		video.CampaignID = *req.CampaignID
 	}
-	video.UpdatedAt = time.Now()
+	if req.Visibility != nil || req.WorkspaceID != nil {
+		if !ownsVideo(viewer, video) {
+			return nil, i18n.Errorf(models.ErrForbidden, "only the owner or an admin can change who sees the video")
+		}
+		if req.Visibility != nil {
+			video.Visibility = *req.Visibility
+		}
+		if req.WorkspaceID != nil {
+			video.WorkspaceID = *req.WorkspaceID
+		}
+		if err := s.checkVisibility(ctx, viewer.TenantID, video.Visibility, video.WorkspaceID); err != nil {
+			return nil, err
+		}
+	}
+	video.UpdatedAt = s.clock.Now()
 
 	// Save changes
-	if err := s.repo.Update(video); err != nil {
-		s.logger.Error("Failed to update video", "error", err, "video_id", videoID, "tenant_id", tenantID)
+	if err := s.repo.Update(ctx, video); err != nil {
+		s.logger.Error("Failed to update video", "error", err, "video_id", videoID, "tenant_id", viewer.TenantID)
 		return nil, fmt.Errorf("failed to update video: %w", err)
 	}
 
-	s.logger.Info("Video updated successfully", "video_id", videoID, "tenant_id", tenantID)
+	s.logger.Info("Video updated successfully", "video_id", videoID, "tenant_id", viewer.TenantID)
+	return video, nil
+}
+
+// TransferOwnership hands a video over to another user of the tenant
+func (s *videoService) TransferOwnership(ctx context.Context, viewer *models.User, videoID, userID string) (*models.Video, error) {
+	video, err := s.visibleVideo(ctx, viewer, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+	if !ownsVideo(viewer, video) {
+		return nil, i18n.Errorf(models.ErrForbidden, "only the owner or an admin can transfer the video")
+	}
+	if userID == video.UserID {
+		return nil, i18n.Errorf(models.ErrConflict, "the user already owns the video")
+	}
+	owner, err := s.users.GetByID(ctx, viewer.TenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !owner.IsActive() {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "the new owner is not active")
+	}
+
+	previous := video.UserID
+	video.UserID = owner.ID
+	video.UpdatedAt = s.clock.Now()
+	if err := s.repo.Update(ctx, video); err != nil {
+		return nil, fmt.Errorf("failed to update video: %w", err)
+	}
+
+	s.logger.Info("Video ownership transferred",
+		"video_id", videoID,
+		"tenant_id", viewer.TenantID,
+		"from_user_id", previous,
+		"to_user_id", owner.ID,
+		"by_user_id", viewer.ID)
+	return video, nil
+}
+
+// visibleVideo loads a video of the viewer's tenant, not found unless the
+// viewer sees it
+func (s *videoService) visibleVideo(ctx context.Context, viewer *models.User, videoID string) (*models.Video, error) {
+	return visibleVideo(ctx, s.repo, s.workspaces, viewer, videoID)
+}
+
+// visibleVideo loads a video of the viewer's tenant, not found unless the
+// viewer sees it. Services reading or changing a video or its sub-resources
+// for a user look it up through it.
+func visibleVideo(ctx context.Context, videos models.VideoRepository, workspaces models.WorkspaceRepository, viewer *models.User, videoID string) (*models.Video, error) {
+	video, err := videos.GetByID(ctx, viewer.TenantID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	var workspaceIDs []string
+	if video.Visibility == models.VisibilityWorkspace && !ownsVideo(viewer, video) {
+		if workspaceIDs, err = managedWorkspaceIDs(ctx, workspaces, viewer); err != nil {
+			return nil, err
+		}
+	}
+	if !video.VisibleTo(viewer, workspaceIDs) {
+		return nil, models.ErrVideoNotFound
+	}
 	return video, nil
 }
 
+// managedWorkspaceIDs lists the workspaces the viewer manages
+func managedWorkspaceIDs(ctx context.Context, workspaces models.WorkspaceRepository, viewer *models.User) ([]string, error) {
+	managed, err := workspaces.ListByUser(ctx, viewer.TenantID, viewer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	ids := make([]string, len(managed))
+	for i, workspace := range managed {
+		ids[i] = workspace.ID
+	}
+	return ids, nil
+}
+
+// checkVisibility validates a visibility and that its workspace is the tenant's
+func (s *videoService) checkVisibility(ctx context.Context, tenantID, visibility, workspaceID string) error {
+	if err := models.ValidateVisibility(visibility, workspaceID); err != nil {
+		return err
+	}
+	if workspaceID == "" {
+		return nil
+	}
+	if _, err := s.workspaces.GetByID(ctx, tenantID, workspaceID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return i18n.Errorf(models.ErrInvalidInput, "workspace %s not found", workspaceID)
+		}
+		return fmt.Errorf("failed to get workspace: %w", err)
+	}
+	return nil
+}
+
+// ownsVideo reports whether the user may change who sees the video and who owns it
+func ownsVideo(user *models.User, video *models.Video) bool {
+	return user.ID == video.UserID || models.UserRole(user.Role) == models.RoleAdmin
+}
+
 // DeleteVideo deletes a video
 func (s *videoService) DeleteVideo(ctx context.Context, tenantID, videoID string) error {
 	s.logger.Info("Deleting video", "video_id", videoID, "tenant_id", tenantID)
 
-	if err := s.repo.Delete(tenantID, videoID); err != nil {
+	if err := s.repo.Delete(ctx, tenantID, videoID); err != nil {
 		s.logger.Error("Failed to delete video", "error", err, "video_id", videoID, "tenant_id", tenantID)
 		return fmt.Errorf("failed to delete video: %w", err)
 	}
@@ -124,16 +274,59 @@ func (s *videoService) DeleteVideo(ctx context.Context, tenantID, videoID string
 	return nil
 }
 
-// ListVideos lists videos for a tenant
-func (s *videoService) ListVideos(ctx context.Context, tenantID string, limit, offset int) ([]*models.Video, error) {
-	s.logger.Debug("Listing videos", "tenant_id", tenantID, "limit", limit, "offset", offset)
+// ListVideos lists the videos of the viewer's tenant it sees
+func (s *videoService) ListVideos(ctx context.Context, viewer *models.User, filter models.VideoListFilter, limit, offset int) ([]*models.Video, int64, error) {
+	s.logger.Debug("Listing videos", "tenant_id", viewer.TenantID, "limit", limit, "offset", offset)
+
+	if filter.Sort == "" {
+		filter.Sort = "created_at"
+	}
+	if _, ok := models.VideoSortColumns[filter.Sort]; !ok {
+		return nil, 0, i18n.Errorf(models.ErrInvalidInput, "unknown sort %q", filter.Sort)
+	}
+	if filter.Status != "" && !filter.Status.Valid() {
+		return nil, 0, i18n.Errorf(models.ErrInvalidInput, "unknown status %q", filter.Status)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedBefore != nil && !filter.CreatedFrom.Before(*filter.CreatedBefore) {
+		return nil, 0, i18n.Errorf(models.ErrInvalidInput, "created_from must be before created_to")
+	}
 
-	videos, err := s.repo.List(tenantID, limit, offset)
+	scope, err := videoScope(ctx, s.workspaces, viewer)
 	if err != nil {
-		s.logger.Error("Failed to list videos", "error", err, "tenant_id", tenantID)
-		return nil, fmt.Errorf("failed to list videos: %w", err)
+		return nil, 0, err
 	}
+	videos, total, err := s.repo.Search(ctx, viewer.TenantID, scope, filter, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to list videos", "error", err, "tenant_id", viewer.TenantID)
+		return nil, 0, fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	return videos, total, nil
+}
 
+// videoScope is what the viewer sees of the tenant's videos, nil for admins
+// who see them all
+func videoScope(ctx context.Context, workspaces models.WorkspaceRepository, viewer *models.User) (*models.VideoScope, error) {
+	if models.UserRole(viewer.Role) == models.RoleAdmin {
+		return nil, nil
+	}
+	workspaceIDs, err := managedWorkspaceIDs(ctx, workspaces, viewer)
+	if err != nil {
+		return nil, err
+	}
+	return &models.VideoScope{UserID: viewer.ID, WorkspaceIDs: workspaceIDs}, nil
+}
+
+func (s *videoService) ListMatching(ctx context.Context, viewer *models.User, conditions []models.SmartListCondition, limit, offset int) ([]*models.Video, error) {
+	scope, err := videoScope(ctx, s.workspaces, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	videos, err := s.repo.ListMatching(ctx, viewer.TenantID, scope, conditions, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list matching videos: %w", err)
+	}
 	return videos, nil
 }
 
@@ -141,7 +334,7 @@ func (s *videoService) ListVideos(ctx context.Context, tenantID string, limit, o
 func (s *videoService) GetUserVideos(ctx context.Context, tenantID, userID string, limit, offset int) ([]*models.Video, error) {
 	s.logger.Debug("Getting user videos", "tenant_id", tenantID, "user_id", userID, "limit", limit, "offset", offset)
 
-	videos, err := s.repo.GetByUserID(tenantID, userID, limit, offset)
+	videos, err := s.repo.GetByUserID(ctx, tenantID, userID, limit, offset)
 	if err != nil {
 		s.logger.Error("Failed to get user videos", "error", err, "tenant_id", tenantID, "user_id", userID)
 		return nil, fmt.Errorf("failed to get user videos: %w", err)
@@ -155,7 +348,7 @@ func (s *videoService) UploadVideo(ctx context.Context, tenantID, videoID string
 	s.logger.Info("Uploading video file", "video_id", videoID, "tenant_id", tenantID, "filename", filename)
 
 	// Get video to ensure it exists
-	video, err := s.repo.GetByID(tenantID, videoID)
+	video, err := s.repo.GetByID(ctx, tenantID, videoID)
 	if err != nil {
 		return fmt.Errorf("failed to get video: %w", err)
 	}
@@ -163,12 +356,13 @@ func (s *videoService) UploadVideo(ctx context.Context, tenantID, videoID string
 	// TODO: Implement S3 upload logic here
 	// For now, just update the status
 	video.Status = string(models.StatusProcessing)
-	video.UpdatedAt = time.Now()
+	video.UpdatedAt = s.clock.Now()
 
-	if err := s.repo.Update(video); err != nil {
+	if err := s.repo.Update(ctx, video); err != nil {
 		s.logger.Error("Failed to update video status", "error", err, "video_id", videoID, "tenant_id", tenantID)
 		return fmt.Errorf("failed to update video status: %w", err)
 	}
+	s.jobs.Start(ctx, tenantID, video.UserID, models.TranscodeJob(videoID))
 
 	s.logger.Info("Video file uploaded successfully", "video_id", videoID, "tenant_id", tenantID)
 	return nil
@@ -178,7 +372,7 @@ func (s *videoService) UploadVideo(ctx context.Context, tenantID, videoID string
 func (s *videoService) UpdateVideoStatus(ctx context.Context, tenantID, videoID string, status models.VideoStatus) error {
 	s.logger.Info("Updating video status", "video_id", videoID, "tenant_id", tenantID, "status", status)
 
-	if err := s.repo.UpdateStatus(tenantID, videoID, status); err != nil {
+	if err := s.repo.UpdateStatus(ctx, tenantID, videoID, status); err != nil {
 		s.logger.Error("Failed to update video status", "error", err, "video_id", videoID, "tenant_id", tenantID)
 		return fmt.Errorf("failed to update video status: %w", err)
 	}
@@ -191,7 +385,7 @@ func (s *videoService) UpdateVideoStatus(ctx context.Context, tenantID, videoID
 func (s *videoService) GetVideosByStatus(ctx context.Context, tenantID string, status models.VideoStatus, limit, offset int) ([]*models.Video, error) {
 	s.logger.Debug("Getting videos by status", "tenant_id", tenantID, "status", status, "limit", limit, "offset", offset)
 
-	videos, err := s.repo.GetByStatus(tenantID, status, limit, offset)
+	videos, err := s.repo.GetByStatus(ctx, tenantID, status, limit, offset)
 	if err != nil {
 		s.logger.Error("Failed to get videos by status", "error", err, "tenant_id", tenantID, "status", status)
 		return nil, fmt.Errorf("failed to get videos by status: %w", err)
@@ -204,7 +398,7 @@ func (s *videoService) GetVideosByStatus(ctx context.Context, tenantID string, s
 func (s *videoService) SetProcessingComplete(ctx context.Context, tenantID, videoID string, duration int, resolution, thumbnailURL, s3Key, s3Bucket string) error {
 	s.logger.Info("Setting video processing complete", "video_id", videoID, "tenant_id", tenantID)
 
-	video, err := s.repo.GetByID(tenantID, videoID)
+	video, err := s.repo.GetByID(ctx, tenantID, videoID)
 	if err != nil {
 		return fmt.Errorf("failed to get video: %w", err)
 	}
@@ -215,97 +409,47 @@ func (s *videoService) SetProcessingComplete(ctx context.Context, tenantID, vide
 	video.ThumbnailURL = thumbnailURL
 	video.S3Key = s3Key
 	video.S3Bucket = s3Bucket
-	video.UpdatedAt = time.Now()
+	video.UpdatedAt = s.clock.Now()
 
-	if err := s.repo.Update(video); err != nil {
+	if err := s.repo.Update(ctx, video); err != nil {
 		s.logger.Error("Failed to update video processing status", "error", err, "video_id", videoID, "tenant_id", tenantID)
 		return fmt.Errorf("failed to update video processing status: %w", err)
 	}
 
+	s.jobs.Succeed(ctx, tenantID, models.TranscodeJob(videoID))
+
 	s.logger.Info("Video processing completed successfully", "video_id", videoID, "tenant_id", tenantID)
 	return nil
 }
 
-// SetProcessingFailed marks video processing as failed
-func (s *videoService) SetProcessingFailed(ctx context.Context, tenantID, videoID string) error {
-	s.logger.Info("Setting video processing failed", "video_id", videoID, "tenant_id", tenantID)
-
-	video, err := s.repo.GetByID(tenantID, videoID)
-	if err != nil {
+// SetProcessingProgress records how far the processing of the video is
+func (s *videoService) SetProcessingProgress(ctx context.Context, tenantID, videoID string, percent int) error {
+	if _, err := s.repo.GetByID(ctx, tenantID, videoID); err != nil {
 		return fmt.Errorf("failed to get video: %w", err)
 	}
-
-	video.Status = string(models.StatusFailed)
-	video.UpdatedAt = time.Now()
-
-	if err := s.repo.Update(video); err != nil {
-		s.logger.Error("Failed to update video processing status", "error", err, "video_id", videoID, "tenant_id", tenantID)
-		return fmt.Errorf("failed to update video processing status: %w", err)
-	}
-
-	s.logger.Info("Video processing marked as failed", "video_id", videoID, "tenant_id", tenantID)
+	s.jobs.Progress(ctx, tenantID, models.TranscodeJob(videoID), percent)
 	return nil
 }
 
-// PublishVideo publishes a video to specified platforms
-func (s *videoService) PublishVideo(ctx context.Context, tenantID, videoID string, platforms []string) error {
-	s.logger.Info("Publishing video", "video_id", videoID, "tenant_id", tenantID, "platforms", platforms)
+// SetProcessingFailed marks video processing as failed
+func (s *videoService) SetProcessingFailed(ctx context.Context, tenantID, videoID, reason string) error {
+	s.logger.Info("Setting video processing failed", "video_id", videoID, "tenant_id", tenantID, "reason", reason)
 
-	// Get video to ensure it exists and is ready
-	video, err := s.repo.GetByID(tenantID, videoID)
+	video, err := s.repo.GetByID(ctx, tenantID, videoID)
 	if err != nil {
 		return fmt.Errorf("failed to get video: %w", err)
 	}
 
-	if video.Status != string(models.StatusReady) {
-		return fmt.Errorf("video is not ready for publishing, current status: %s", video.Status)
-	}
-
-	// TODO: Implement publication job creation logic
-	// For now, just log the action
-	s.logger.Info("Video publication initiated", "video_id", videoID, "tenant_id", tenantID, "platforms", platforms)
-	return nil
-}
-
-// GetVideoPublications retrieves publication jobs for a video
-func (s *videoService) GetVideoPublications(ctx context.Context, tenantID, videoID string) ([]*models.PublicationJob, error) {
-	s.logger.Debug("Getting video publications", "video_id", videoID, "tenant_id", tenantID)
-
-	// TODO: Implement publication job retrieval
-	// For now, return empty slice
-	return []*models.PublicationJob{}, nil
-}
-
-// UpdatePublication updates a publication job status
-func (s *videoService) UpdatePublication(ctx context.Context, tenantID, publicationID string, status string) error {
-	s.logger.Info("Updating publication", "publication_id", publicationID, "tenant_id", tenantID, "status", status)
+	video.Status = string(models.StatusFailed)
+	video.UpdatedAt = s.clock.Now()
 
-	// TODO: Implement publication job update logic
-	return nil
-}
+	if err := s.repo.Update(ctx, video); err != nil {
+		s.logger.Error("Failed to update video processing status", "error", err, "video_id", videoID, "tenant_id", tenantID)
+		return fmt.Errorf("failed to update video processing status: %w", err)
+	}
 
-// CancelPublication cancels a publication job
-func (s *videoService) CancelPublication(ctx context.Context, tenantID, publicationID string) error {
-	s.logger.Info("Cancelling publication", "publication_id", publicationID, "tenant_id", tenantID)
+	s.jobs.Fail(ctx, tenantID, models.TranscodeJob(videoID), reason)
 
-	// TODO: Implement publication job cancellation logic
+	s.logger.Info("Video processing marked as failed", "video_id", videoID, "tenant_id", tenantID)
 	return nil
 }
-
-// convertTagsToJSON converts a slice of tags to JSON string
-func convertTagsToJSON(tags []string) string {
-	if len(tags) == 0 {
-		return ""
-	}
-	jsonBytes, err := json.Marshal(tags)
-	if err != nil {
-		return ""
-	}
-	return string(jsonBytes)
-}
-
-// generateID generates a unique ID for entities
-// TODO: Replace with proper UUID generation
-func generateID() string {
-	return fmt.Sprintf("video_%d", time.Now().UnixNano())
-}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// visibilityVideoRepo stores videos by ID and filters them like the database
+type visibilityVideoRepo struct {
+	models.VideoRepository
+	videos map[string]*models.Video
+}
+
+func (r *visibilityVideoRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Video, error) {
+	if v, ok := r.videos[id]; ok && v.TenantID == tenantID {
+		copied := *v
+		return &copied, nil
+	}
+	return nil, models.ErrVideoNotFound
+}
+
+func (r *visibilityVideoRepo) List(ctx context.Context, tenantID string, limit, offset int) ([]*models.Video, error) {
+	return r.ListVisible(ctx, tenantID, "", nil, limit, offset)
+}
+
+func (r *visibilityVideoRepo) ListVisible(ctx context.Context, tenantID, userID string, workspaceIDs []string, limit, offset int) ([]*models.Video, error) {
+	var videos []*models.Video
+	for _, v := range r.videos {
+		visible := userID == "" || v.Visibility == models.VisibilityTenant || v.UserID == userID ||
+			(v.Visibility == models.VisibilityWorkspace && slices.Contains(workspaceIDs, v.WorkspaceID))
+		if v.TenantID == tenantID && visible {
+			videos = append(videos, v)
+		}
+	}
+	slices.SortFunc(videos, func(a, b *models.Video) int { return strings.Compare(a.ID, b.ID) })
+	return videos, nil
+}
+
+func (r *visibilityVideoRepo) Search(ctx context.Context, tenantID string, scope *models.VideoScope, filter models.VideoListFilter, limit, offset int) ([]*models.Video, int64, error) {
+	userID, workspaceIDs := "", []string(nil)
+	if scope != nil {
+		userID, workspaceIDs = scope.UserID, scope.WorkspaceIDs
+	}
+	visible, _ := r.ListVisible(ctx, tenantID, userID, workspaceIDs, 0, 0)
+	var videos []*models.Video
+	for _, v := range visible {
+		if (filter.Status == "" || v.Status == string(filter.Status)) &&
+			(filter.Tag == "" || slices.Contains(v.Tags, filter.Tag)) &&
+			(filter.CreatedFrom == nil || !v.CreatedAt.Before(*filter.CreatedFrom)) &&
+			(filter.CreatedBefore == nil || v.CreatedAt.Before(*filter.CreatedBefore)) {
+			videos = append(videos, v)
+		}
+	}
+	if filter.Sort == "title" {
+		slices.SortStableFunc(videos, func(a, b *models.Video) int { return strings.Compare(a.Title, b.Title) })
+	}
+	return videos, int64(len(videos)), nil
+}
+
+func (r *visibilityVideoRepo) Update(ctx context.Context, video *models.Video) error {
+	copied := *video
+	r.videos[video.ID] = &copied
+	return nil
+}
+
+// visibilityWorkspaceRepo stores workspaces by ID
+type visibilityWorkspaceRepo struct {
+	models.WorkspaceRepository
+	workspaces []*models.Workspace
+}
+
+func (r *visibilityWorkspaceRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Workspace, error) {
+	for _, w := range r.workspaces {
+		if w.TenantID == tenantID && w.ID == id {
+			return w, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *visibilityWorkspaceRepo) ListByUser(ctx context.Context, tenantID, userID string) ([]*models.Workspace, error) {
+	var workspaces []*models.Workspace
+	for _, w := range r.workspaces {
+		if w.TenantID == tenantID && w.UserID == userID {
+			workspaces = append(workspaces, w)
+		}
+	}
+	return workspaces, nil
+}
+
+func newTestVideoService() (VideoService, *visibilityVideoRepo) {
+	videos := &visibilityVideoRepo{videos: map[string]*models.Video{
+		"draft-ana":  {ID: "draft-ana", TenantID: "acme", UserID: "ana", Visibility: models.VisibilityOwner},
+		"draft-ben":  {ID: "draft-ben", TenantID: "acme", UserID: "ben", Visibility: models.VisibilityOwner},
+		"for-client": {ID: "for-client", TenantID: "acme", UserID: "ben", Visibility: models.VisibilityWorkspace, WorkspaceID: "ws-client"},
+		"shared":     {ID: "shared", TenantID: "acme", UserID: "ben", Visibility: models.VisibilityTenant},
+	}}
+	users := &identityUserRepo{users: map[string]*models.User{
+		"ana":    {ID: "ana", TenantID: "acme", Role: "editor", Status: string(models.StatusActive)},
+		"ben":    {ID: "ben", TenantID: "acme", Role: "editor", Status: string(models.StatusActive)},
+		"former": {ID: "former", TenantID: "acme", Role: "editor", Status: string(models.StatusInactive)},
+	}}
+	workspaces := &visibilityWorkspaceRepo{workspaces: []*models.Workspace{
+		{ID: "ws-client", TenantID: "acme", UserID: "carla"},
+	}}
+	clk := clock.NewFake(time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC))
+	return NewVideoService(videos, users, workspaces, nil, clk, logger.New("error", "test")), videos
+}
+
+func videoIDs(videos []*models.Video) []string {
+	ids := make([]string, len(videos))
+	for i, v := range videos {
+		ids[i] = v.ID
+	}
+	return ids
+}
+
+func TestVideoService_Visibility(t *testing.T) {
+	svc, _ := newTestVideoService()
+	ctx := context.Background()
+	ana := &models.User{ID: "ana", TenantID: "acme", Role: "editor"}
+	carla := &models.User{ID: "carla", TenantID: "acme", Role: "editor"}
+	admin := &models.User{ID: "root", TenantID: "acme", Role: "admin"}
+
+	videos, _, err := svc.ListVideos(ctx, ana, models.VideoListFilter{}, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"draft-ana", "shared"}, videoIDs(videos))
+
+	videos, _, err = svc.ListVideos(ctx, carla, models.VideoListFilter{}, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"for-client", "shared"}, videoIDs(videos), "the workspace's user sees the videos made for it")
+
+	videos, _, err = svc.ListVideos(ctx, admin, models.VideoListFilter{}, 20, 0)
+	require.NoError(t, err)
+	assert.Len(t, videos, 4)
+
+	_, err = svc.GetVideo(ctx, ana, "draft-ben")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+	_, err = svc.GetVideo(ctx, ana, "for-client")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+	_, err = svc.GetVideo(ctx, carla, "for-client")
+	assert.NoError(t, err)
+
+	title := "Renamed"
+	_, err = svc.UpdateVideo(ctx, ana, "draft-ben", &models.UpdateVideoRequest{Title: &title})
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+
+	// Users who see a video may edit it, but only its owner shares it
+	video, err := svc.UpdateVideo(ctx, ana, "shared", &models.UpdateVideoRequest{Title: &title})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", video.Title)
+	owner := models.VisibilityOwner
+	_, err = svc.UpdateVideo(ctx, ana, "shared", &models.UpdateVideoRequest{Visibility: &owner})
+	assert.ErrorIs(t, err, models.ErrForbidden)
+
+	workspace := models.VisibilityWorkspace
+	_, err = svc.UpdateVideo(ctx, ana, "draft-ana", &models.UpdateVideoRequest{Visibility: &workspace})
+	assert.ErrorIs(t, err, models.ErrInvalidInput, "workspace visibility needs a workspace")
+	unknown := "ws-gone"
+	_, err = svc.UpdateVideo(ctx, ana, "draft-ana", &models.UpdateVideoRequest{Visibility: &workspace, WorkspaceID: &unknown})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	client := "ws-client"
+	video, err = svc.UpdateVideo(ctx, ana, "draft-ana", &models.UpdateVideoRequest{Visibility: &workspace, WorkspaceID: &client})
+	require.NoError(t, err)
+	assert.Equal(t, models.VisibilityWorkspace, video.Visibility)
+	_, err = svc.GetVideo(ctx, carla, "draft-ana")
+	assert.NoError(t, err)
+}
+
+func TestVideoService_ListVideosFilter(t *testing.T) {
+	svc, repo := newTestVideoService()
+	ctx := context.Background()
+	ana := &models.User{ID: "ana", TenantID: "acme", Role: "editor"}
+	repo.videos["draft-ana"].Status = string(models.StatusReady)
+	repo.videos["draft-ana"].Tags = []string{"teaser"}
+	repo.videos["draft-ben"].Tags = []string{"teaser"}
+	repo.videos["shared"].Status = string(models.StatusReady)
+
+	videos, total, err := svc.ListVideos(ctx, ana, models.VideoListFilter{Status: models.StatusReady, Tag: "teaser"}, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"draft-ana"}, videoIDs(videos), "filters apply within what the viewer sees")
+	assert.Equal(t, int64(1), total)
+
+	_, _, err = svc.ListVideos(ctx, ana, models.VideoListFilter{Sort: "duration"}, 20, 0)
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, _, err = svc.ListVideos(ctx, ana, models.VideoListFilter{Status: "published"}, 20, 0)
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	_, _, err = svc.ListVideos(ctx, ana, models.VideoListFilter{CreatedFrom: &from, CreatedBefore: &from}, 20, 0)
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+}
+
+func TestVideoService_TransferOwnership(t *testing.T) {
+	svc, videos := newTestVideoService()
+	ctx := context.Background()
+	ana := &models.User{ID: "ana", TenantID: "acme", Role: "editor"}
+	ben := &models.User{ID: "ben", TenantID: "acme", Role: "editor"}
+
+	_, err := svc.TransferOwnership(ctx, ana, "shared", "ana")
+	assert.ErrorIs(t, err, models.ErrForbidden, "seeing a video is not owning it")
+	_, err = svc.TransferOwnership(ctx, ana, "draft-ana", "ana")
+	assert.ErrorIs(t, err, models.ErrConflict)
+	_, err = svc.TransferOwnership(ctx, ana, "draft-ana", "former")
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.TransferOwnership(ctx, ana, "draft-ana", "nobody")
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+
+	video, err := svc.TransferOwnership(ctx, ana, "draft-ana", "ben")
+	require.NoError(t, err)
+	assert.Equal(t, "ben", video.UserID)
+	assert.Equal(t, "ben", videos.videos["draft-ana"].UserID)
+
+	// The private draft follows its new owner
+	_, err = svc.GetVideo(ctx, ana, "draft-ana")
+	assert.ErrorIs(t, err, models.ErrVideoNotFound)
+	_, err = svc.GetVideo(ctx, ben, "draft-ana")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Watermarks may be JPEG
+	_ "image/png"  // or PNG, whose transparency most logos need
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/i18n"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// maxWatermarkSide bounds the width and height of a watermark in pixels, so
+// it never covers a 1080p frame
+const maxWatermarkSide = 1080
+
+// watermarkService implements the WatermarkService interface
+type watermarkService struct {
+	watermarks models.WatermarkRepository
+	logger     *logger.Logger
+}
+
+var _ WatermarkService = (*watermarkService)(nil)
+
+// NewWatermarkService creates a new watermark service
+func NewWatermarkService(watermarks models.WatermarkRepository, logger *logger.Logger) WatermarkService {
+	return &watermarkService{watermarks: watermarks, logger: logger}
+}
+
+// Get returns the tenant's watermark
+func (s *watermarkService) Get(ctx context.Context, tenantID string) (*models.Watermark, error) {
+	watermark, err := s.watermarks.Get(ctx, tenantID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, models.ErrWatermarkNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermark: %w", err)
+	}
+	return watermark, nil
+}
+
+// Upload checks the image is a PNG or JPEG of a sensible size and saves it,
+// burnt in at the bottom right of every video until the settings change
+func (s *watermarkService) Upload(ctx context.Context, tenantID string, img []byte) (*models.Watermark, error) {
+	if len(img) == 0 || len(img) > models.MaxWatermarkBytes {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "watermark must be between 1 byte and %d bytes", models.MaxWatermarkBytes)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(img))
+	if err != nil {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "watermark must be a PNG or JPEG image")
+	}
+	if cfg.Width > maxWatermarkSide || cfg.Height > maxWatermarkSide {
+		return nil, i18n.Errorf(models.ErrInvalidInput, "watermark must be at most %dx%d pixels", maxWatermarkSide, maxWatermarkSide)
+	}
+
+	watermark, err := s.Get(ctx, tenantID)
+	if errors.Is(err, models.ErrWatermarkNotFound) {
+		watermark = &models.Watermark{
+			TenantID: tenantID,
+			Enabled:  true,
+			Position: models.DefaultWatermarkPosition,
+			Opacity:  models.DefaultWatermarkOpacity,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	watermark.Image = img
+	watermark.ContentType = "image/" + format
+	watermark.Width = cfg.Width
+	watermark.Height = cfg.Height
+
+	if err := s.watermarks.Upsert(ctx, watermark); err != nil {
+		return nil, fmt.Errorf("failed to save watermark: %w", err)
+	}
+	s.logger.Info("Watermark uploaded", "tenant_id", tenantID, "content_type", watermark.ContentType,
+		"width", watermark.Width, "height", watermark.Height)
+	return watermark, nil
+}
+
+// Update changes the settings set in req and saves the watermark
+func (s *watermarkService) Update(ctx context.Context, tenantID string, req *models.UpdateWatermarkRequest) (*models.Watermark, error) {
+	watermark, err := s.Get(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Enabled != nil {
+		watermark.Enabled = *req.Enabled
+	}
+	if req.Position != nil {
+		watermark.Position = transcode.Position(*req.Position)
+	}
+	if req.Opacity != nil {
+		watermark.Opacity = *req.Opacity
+	}
+	if req.Platforms != nil {
+		watermark.Platforms = req.Platforms
+	}
+	if err := watermark.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.watermarks.Upsert(ctx, watermark); err != nil {
+		return nil, fmt.Errorf("failed to save watermark: %w", err)
+	}
+	s.logger.Info("Watermark settings updated", "tenant_id", tenantID, "enabled", watermark.Enabled,
+		"position", watermark.Position, "opacity", watermark.Opacity, "platforms", watermark.Platforms)
+	return watermark, nil
+}
+
+// Delete removes the tenant's watermark; videos transcoded afterwards have none
+func (s *watermarkService) Delete(ctx context.Context, tenantID string) error {
+	err := s.watermarks.Delete(ctx, tenantID)
+	if errors.Is(err, models.ErrNotFound) {
+		return models.ErrWatermarkNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete watermark: %w", err)
+	}
+	s.logger.Info("Watermark deleted", "tenant_id", tenantID)
+	return nil
+}
+
+// Resolve applies the override to the tenant's watermark settings for the platform
+func (s *watermarkService) Resolve(ctx context.Context, tenantID string, platform models.Platform, override *models.WatermarkOverride) (*transcode.Watermark, error) {
+	watermark, err := s.Get(ctx, tenantID)
+	if errors.Is(err, models.ErrWatermarkNotFound) {
+		return nil, nil // Nothing to burn in, whatever the publication asks
+	}
+	if err != nil {
+		return nil, err
+	}
+	return watermark.For(platform, override), nil
+}
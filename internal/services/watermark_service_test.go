@@ -0,0 +1,154 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// memoryWatermarkRepo keeps one watermark per tenant
+type memoryWatermarkRepo struct {
+	models.WatermarkRepository
+	byTenant map[string]models.Watermark
+}
+
+func (r *memoryWatermarkRepo) Get(ctx context.Context, tenantID string) (*models.Watermark, error) {
+	w, ok := r.byTenant[tenantID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &w, nil
+}
+
+func (r *memoryWatermarkRepo) Upsert(ctx context.Context, w *models.Watermark) error {
+	r.byTenant[w.TenantID] = *w
+	return nil
+}
+
+func (r *memoryWatermarkRepo) Delete(ctx context.Context, tenantID string) error {
+	if _, ok := r.byTenant[tenantID]; !ok {
+		return models.ErrNotFound
+	}
+	delete(r.byTenant, tenantID)
+	return nil
+}
+
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func newWatermarkService() (WatermarkService, *memoryWatermarkRepo) {
+	repo := &memoryWatermarkRepo{byTenant: map[string]models.Watermark{}}
+	return NewWatermarkService(repo, logger.New("error", "test")), repo
+}
+
+func TestWatermarkService_Upload(t *testing.T) {
+	svc, repo := newWatermarkService()
+	ctx := context.Background()
+
+	watermark, err := svc.Upload(ctx, "tenant-1", pngImage(t, 120, 40))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", watermark.ContentType)
+	assert.Equal(t, 120, watermark.Width)
+	assert.Equal(t, 40, watermark.Height)
+	assert.True(t, watermark.Enabled, "a first upload is burnt in")
+	assert.Equal(t, transcode.BottomRight, watermark.Position)
+	assert.Equal(t, models.DefaultWatermarkOpacity, watermark.Opacity)
+
+	// A new image keeps the settings
+	_, err = svc.Update(ctx, "tenant-1", &models.UpdateWatermarkRequest{Position: ptr("top_left")})
+	require.NoError(t, err)
+	watermark, err = svc.Upload(ctx, "tenant-1", pngImage(t, 60, 60))
+	require.NoError(t, err)
+	assert.Equal(t, transcode.TopLeft, watermark.Position)
+	assert.Equal(t, 60, repo.byTenant["tenant-1"].Width)
+
+	_, err = svc.Upload(ctx, "tenant-1", []byte("GIF89a"))
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Upload(ctx, "tenant-1", pngImage(t, 2000, 40))
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Upload(ctx, "tenant-1", nil)
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+}
+
+func TestWatermarkService_Update(t *testing.T) {
+	svc, _ := newWatermarkService()
+	ctx := context.Background()
+
+	_, err := svc.Update(ctx, "tenant-1", &models.UpdateWatermarkRequest{Enabled: ptr(false)})
+	assert.ErrorIs(t, err, models.ErrWatermarkNotFound)
+
+	_, err = svc.Upload(ctx, "tenant-1", pngImage(t, 120, 40))
+	require.NoError(t, err)
+
+	_, err = svc.Update(ctx, "tenant-1", &models.UpdateWatermarkRequest{Position: ptr("middle")})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Update(ctx, "tenant-1", &models.UpdateWatermarkRequest{Opacity: ptr(1.5)})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+	_, err = svc.Update(ctx, "tenant-1", &models.UpdateWatermarkRequest{Platforms: []string{"myspace"}})
+	assert.ErrorIs(t, err, models.ErrInvalidPlatform)
+
+	watermark, err := svc.Update(ctx, "tenant-1", &models.UpdateWatermarkRequest{Opacity: ptr(0.5), Platforms: []string{"youtube"}})
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, watermark.Opacity)
+	assert.Equal(t, []string{"youtube"}, watermark.Platforms)
+	assert.Equal(t, transcode.BottomRight, watermark.Position, "unset fields are kept")
+}
+
+func TestWatermarkService_Resolve(t *testing.T) {
+	svc, _ := newWatermarkService()
+	ctx := context.Background()
+
+	resolved, err := svc.Resolve(ctx, "tenant-1", models.PlatformYouTube, &models.WatermarkOverride{Enabled: ptr(true)})
+	require.NoError(t, err)
+	assert.Nil(t, resolved, "nothing is burnt in without a watermark")
+
+	_, err = svc.Upload(ctx, "tenant-1", pngImage(t, 120, 40))
+	require.NoError(t, err)
+	_, err = svc.Update(ctx, "tenant-1", &models.UpdateWatermarkRequest{Platforms: []string{"youtube"}})
+	require.NoError(t, err)
+
+	resolved, err = svc.Resolve(ctx, "tenant-1", models.PlatformYouTube, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &transcode.Watermark{Position: transcode.BottomRight, Opacity: 0.8}, resolved)
+
+	resolved, err = svc.Resolve(ctx, "tenant-1", models.PlatformTikTok, nil)
+	require.NoError(t, err)
+	assert.Nil(t, resolved, "off on the platforms left out")
+
+	resolved, err = svc.Resolve(ctx, "tenant-1", models.PlatformTikTok,
+		&models.WatermarkOverride{Enabled: ptr(true), Position: transcode.TopRight, Opacity: 0.3})
+	require.NoError(t, err)
+	assert.Equal(t, &transcode.Watermark{Position: transcode.TopRight, Opacity: 0.3}, resolved, "the publication wins")
+
+	resolved, err = svc.Resolve(ctx, "tenant-1", models.PlatformYouTube, &models.WatermarkOverride{Enabled: ptr(false)})
+	require.NoError(t, err)
+	assert.Nil(t, resolved)
+}
+
+func TestWatermarkService_Delete(t *testing.T) {
+	svc, repo := newWatermarkService()
+	ctx := context.Background()
+
+	assert.ErrorIs(t, svc.Delete(ctx, "tenant-1"), models.ErrWatermarkNotFound)
+	_, err := svc.Upload(ctx, "tenant-1", pngImage(t, 120, 40))
+	require.NoError(t, err)
+	require.NoError(t, svc.Delete(ctx, "tenant-1"))
+	assert.Empty(t, repo.byTenant)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// webhookService implements the WebhookService interface. Events wait in a
+// bounded in-memory queue for a fixed pool of workers, so platforms get their
+// response without waiting for processing and a flood cannot grow memory.
+// Payloads are validated before they are queued; those that fail are kept in
+// quarantine rather than processed.
+type webhookService struct {
+	queue      chan *WebhookEvent
+	workers    int
+	handle     func(ctx context.Context, event *WebhookEvent) error
+	quarantine models.QuarantinedWebhookRepository
+	logger     *logger.Logger
+
+	mu      sync.RWMutex
+	stopped bool
+
+	statsMu sync.Mutex
+	since   time.Time
+	counts  map[string]*WebhookPlatformStats
+}
+
+var _ WebhookService = (*webhookService)(nil)
+
+// NewWebhookService creates a webhook service holding up to queueSize events;
+// Run must be called once to start its workers
+func NewWebhookService(queueSize, workers int, quarantine models.QuarantinedWebhookRepository, logger *logger.Logger) WebhookService {
+	s := &webhookService{
+		queue:      make(chan *WebhookEvent, queueSize),
+		workers:    workers,
+		quarantine: quarantine,
+		logger:     logger,
+		since:      time.Now(),
+		counts:     make(map[string]*WebhookPlatformStats),
+	}
+	s.handle = s.process
+	return s
+}
+
+// Enqueue validates an event and queues it without blocking
+func (s *webhookService) Enqueue(ctx context.Context, event *WebhookEvent) error {
+	s.count(event.Platform, func(c *WebhookPlatformStats) { c.Received++ })
+	if err := partners.ValidateWebhookPayload(event.Platform, event.Body); err != nil {
+		s.count(event.Platform, func(c *WebhookPlatformStats) { c.Malformed++ })
+		s.quarantineEvent(ctx, event, err)
+		return err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.stopped {
+		s.count(event.Platform, func(c *WebhookPlatformStats) { c.Rejected++ })
+		return models.ErrWebhookQueueFull
+	}
+	select {
+	case s.queue <- event:
+		return nil
+	default:
+		s.logger.Warn("Webhook queue full, rejecting event", "platform", event.Platform, "tenant_id", event.TenantID)
+		s.count(event.Platform, func(c *WebhookPlatformStats) { c.Rejected++ })
+		return models.ErrWebhookQueueFull
+	}
+}
+
+// Stats returns a copy of the counters, by platform name
+func (s *webhookService) Stats() *WebhookStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	stats := &WebhookStats{
+		Since:      s.since,
+		QueueDepth: len(s.queue),
+		QueueSize:  cap(s.queue),
+		Platforms:  make([]*WebhookPlatformStats, 0, len(s.counts)),
+	}
+	for _, c := range s.counts {
+		platform := *c
+		if platform.Received > 0 {
+			platform.ErrorRate = float64(platform.Malformed+platform.Rejected+platform.Failed) / float64(platform.Received)
+		}
+		stats.Platforms = append(stats.Platforms, &platform)
+	}
+	sort.Slice(stats.Platforms, func(i, j int) bool { return stats.Platforms[i].Platform < stats.Platforms[j].Platform })
+	return stats
+}
+
+// quarantineEvent keeps an event that failed validation for inspection. The
+// platform is answered whether or not it could be stored.
+func (s *webhookService) quarantineEvent(ctx context.Context, event *WebhookEvent, validationErr error) {
+	webhook := &models.QuarantinedWebhook{
+		TenantID:    event.TenantID,
+		Platform:    event.Platform,
+		Reason:      models.WebhookMalformed,
+		ContentType: event.ContentType,
+		BodySize:    len(event.Body),
+		Body:        string(event.Body),
+		ReceivedAt:  event.ReceivedAt,
+	}
+	var payloadErr *partners.PayloadError
+	if errors.As(validationErr, &payloadErr) {
+		webhook.Reason = payloadErr.Reason
+		webhook.Errors = payloadErr.Fields
+	}
+
+	s.logger.Warn("Quarantining invalid webhook",
+		"platform", event.Platform,
+		"tenant_id", event.TenantID,
+		"reason", webhook.Reason,
+		"error", validationErr)
+	if err := s.quarantine.Create(context.WithoutCancel(ctx), webhook); err != nil {
+		s.logger.Error("Failed to quarantine webhook", "platform", event.Platform, "error", err)
+	}
+}
+
+// count updates the counters of a platform
+func (s *webhookService) count(platform string, update func(c *WebhookPlatformStats)) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	c, ok := s.counts[platform]
+	if !ok {
+		c = &WebhookPlatformStats{Platform: platform}
+		s.counts[platform] = c
+	}
+	update(c)
+}
+
+// Run starts the workers and blocks until ctx is cancelled and every queued
+// event has been processed
+func (s *webhookService) Run(ctx context.Context) {
+	// Events still queued at shutdown are processed, not dropped
+	workCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for event := range s.queue {
+				s.processEvent(workCtx, event)
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	s.mu.Lock()
+	s.stopped = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	s.logger.Info("Draining webhook queue", "events", len(s.queue))
+	wg.Wait()
+}
+
+// processEvent handles one event, keeping the worker alive when it fails
+func (s *webhookService) processEvent(ctx context.Context, event *WebhookEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Webhook processing panicked", "platform", event.Platform, "panic", fmt.Sprint(r))
+			s.count(event.Platform, func(c *WebhookPlatformStats) { c.Failed++ })
+		}
+	}()
+
+	if err := s.handle(ctx, event); err != nil {
+		s.count(event.Platform, func(c *WebhookPlatformStats) { c.Failed++ })
+		s.logger.Error("Failed to process webhook",
+			"platform", event.Platform,
+			"tenant_id", event.TenantID,
+			"error", err)
+	}
+}
+
+// process handles an event of any supported platform
+func (s *webhookService) process(ctx context.Context, event *WebhookEvent) error {
+	if !models.Platform(event.Platform).Valid() {
+		return fmt.Errorf("%w: %s", models.ErrInvalidPlatform, event.Platform)
+	}
+
+	// TODO: Act on platform payloads, validated on Enqueue: video status updates, analytics updates, etc.
+	s.logger.Info("Processing webhook",
+		"platform", event.Platform,
+		"tenant_id", event.TenantID,
+		"body_size", len(event.Body),
+		"queued_for", time.Since(event.ReceivedAt).String())
+	return nil
+}
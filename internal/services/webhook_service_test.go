@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// webhookBodies are minimal valid payloads of each platform
+var webhookBodies = map[string]string{
+	"youtube": `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:yt="http://www.youtube.com/xml/schemas/2015">` +
+		`<entry><yt:videoId>v1</yt:videoId><yt:channelId>c1</yt:channelId></entry></feed>`,
+	"tiktok":  `{"client_key":"k","event":"post.publish.complete","create_time":1760519564,"user_openid":"u"}`,
+	"twitter": `{"for_user_id":"1","tweet_create_events":[]}`,
+}
+
+// webhookEvent returns a valid event of the platform
+func webhookEvent(platform string) *WebhookEvent {
+	return &WebhookEvent{Platform: platform, Body: []byte(webhookBodies[platform]), ReceivedAt: time.Now()}
+}
+
+// recordingQuarantine keeps the webhooks it is asked to store
+type recordingQuarantine struct {
+	models.QuarantinedWebhookRepository
+	webhooks []*models.QuarantinedWebhook
+}
+
+func (r *recordingQuarantine) Create(ctx context.Context, webhook *models.QuarantinedWebhook) error {
+	r.webhooks = append(r.webhooks, webhook)
+	return nil
+}
+
+// newRecordingWebhookService returns a service whose handler records events
+// once release is closed
+func newRecordingWebhookService(queueSize int, release <-chan struct{}) (*webhookService, func() []string) {
+	s := NewWebhookService(queueSize, 1, &recordingQuarantine{}, logger.New("error", "test")).(*webhookService)
+
+	var mu sync.Mutex
+	var handled []string
+	s.handle = func(ctx context.Context, event *WebhookEvent) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, event.Platform)
+		return nil
+	}
+	return s, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), handled...)
+	}
+}
+
+func TestWebhookService_RejectsWhenQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	s, _ := newRecordingWebhookService(1, release)
+
+	require.NoError(t, s.Enqueue(context.Background(), webhookEvent("youtube")))
+	err := s.Enqueue(context.Background(), webhookEvent("tiktok"))
+	assert.ErrorIs(t, err, models.ErrWebhookQueueFull)
+}
+
+func TestWebhookService_DrainsQueueOnShutdown(t *testing.T) {
+	release := make(chan struct{})
+	s, handled := newRecordingWebhookService(10, release)
+
+	for _, platform := range []string{"youtube", "tiktok", "twitter"} {
+		require.NoError(t, s.Enqueue(context.Background(), webhookEvent(platform)))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after draining the queue")
+	}
+	assert.ElementsMatch(t, []string{"youtube", "tiktok", "twitter"}, handled())
+
+	err := s.Enqueue(context.Background(), webhookEvent("youtube"))
+	assert.ErrorIs(t, err, models.ErrWebhookQueueFull, "a stopped service accepts nothing")
+}
+
+func TestWebhookService_Process(t *testing.T) {
+	s := NewWebhookService(1, 1, &recordingQuarantine{}, logger.New("error", "test")).(*webhookService)
+
+	assert.NoError(t, s.process(context.Background(), &WebhookEvent{Platform: "instagram", Body: []byte(`{}`)}))
+	assert.ErrorIs(t, s.process(context.Background(), &WebhookEvent{Platform: "myspace"}), models.ErrInvalidPlatform)
+}
+
+func TestWebhookService_Stats(t *testing.T) {
+	s := NewWebhookService(2, 1, &recordingQuarantine{}, logger.New("error", "test")).(*webhookService)
+	s.handle = func(ctx context.Context, event *WebhookEvent) error {
+		if event.Platform == "tiktok" {
+			return errors.New("unknown video")
+		}
+		return nil
+	}
+
+	require.NoError(t, s.Enqueue(context.Background(), webhookEvent("youtube")))
+	require.NoError(t, s.Enqueue(context.Background(), webhookEvent("tiktok")))
+	assert.ErrorIs(t, s.Enqueue(context.Background(), webhookEvent("youtube")), models.ErrWebhookQueueFull)
+	assert.Error(t, s.Enqueue(context.Background(), &WebhookEvent{Platform: "tiktok", Body: []byte(`{}`)}))
+
+	stats := s.Stats()
+	assert.Equal(t, 2, stats.QueueDepth)
+	assert.Equal(t, 2, stats.QueueSize)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Run(ctx)
+
+	stats = s.Stats()
+	assert.Equal(t, 0, stats.QueueDepth)
+	require.Len(t, stats.Platforms, 2)
+	assert.Equal(t, WebhookPlatformStats{Platform: "tiktok", Received: 2, Malformed: 1, Failed: 1, ErrorRate: 1}, *stats.Platforms[0])
+	assert.Equal(t, WebhookPlatformStats{Platform: "youtube", Received: 2, Rejected: 1, ErrorRate: 0.5}, *stats.Platforms[1])
+}
+
+func TestWebhookService_QuarantinesInvalidPayloads(t *testing.T) {
+	quarantine := &recordingQuarantine{}
+	s := NewWebhookService(10, 1, quarantine, logger.New("error", "test")).(*webhookService)
+
+	err := s.Enqueue(context.Background(), &WebhookEvent{
+		Platform:    "tiktok",
+		TenantID:    "tenant-1",
+		ContentType: "application/json",
+		Body:        []byte(`{"event":"post.publish.complete"}`),
+	})
+	var payloadErr *partners.PayloadError
+	require.ErrorAs(t, err, &payloadErr)
+	assert.Equal(t, models.WebhookMalformed, payloadErr.Reason)
+
+	assert.Error(t, s.Enqueue(context.Background(), &WebhookEvent{Platform: "twitter", Body: []byte(`{"for_user_id":`)}))
+	require.NoError(t, s.Enqueue(context.Background(), webhookEvent("twitter")))
+	assert.Len(t, s.queue, 1, "only the valid event is queued")
+
+	require.Len(t, quarantine.webhooks, 2)
+	assert.Equal(t, "tenant-1", quarantine.webhooks[0].TenantID)
+	assert.Equal(t, models.WebhookMalformed, quarantine.webhooks[0].Reason)
+	assert.Equal(t, payloadErr.Fields, quarantine.webhooks[0].Errors)
+	assert.Equal(t, `{"event":"post.publish.complete"}`, quarantine.webhooks[0].Body)
+	assert.Equal(t, models.WebhookUnparseable, quarantine.webhooks[1].Reason)
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+const (
+	// maxDueSubscriptions bounds the subscriptions one renewal run requests
+	maxDueSubscriptions = 50
+	// subscriptionVerifyTimeout is how long a platform has to verify a
+	// callback before the subscription is requested again
+	subscriptionVerifyTimeout = time.Hour
+	// subscriptionRecheck is how often subscriptions that do not expire are
+	// requested again, which catches revoked access
+	subscriptionRecheck = 7 * 24 * time.Hour
+	// maxSubscriptionRetry caps the wait before a refused subscription is
+	// requested again; the wait doubles from an hour with each refusal
+	maxSubscriptionRetry = 24 * time.Hour
+)
+
+// webhookSubscriptionService implements the WebhookSubscriptionService
+// interface
+type webhookSubscriptionService struct {
+	subscriptions models.WebhookSubscriptionRepository
+	workspaces    models.WorkspaceRepository
+	clients       func(string) (partners.Client, error)
+
+	callbackBaseURL string
+	secrets         partners.WebhookSecrets
+	lease           time.Duration
+	renewBefore     time.Duration
+	clock           clock.Clock
+	logger          *logger.Logger
+}
+
+var _ WebhookSubscriptionService = (*webhookSubscriptionService)(nil)
+
+// NewWebhookSubscriptionService creates a webhook subscription service.
+// Platforms push to callbackBaseURL/webhooks/<platform>; nothing is
+// subscribed while it is empty. Leases of the given length are asked for and
+// renewed renewBefore they end.
+func NewWebhookSubscriptionService(
+	subscriptions models.WebhookSubscriptionRepository,
+	workspaces models.WorkspaceRepository,
+	clients func(string) (partners.Client, error),
+	callbackBaseURL string,
+	secrets partners.WebhookSecrets,
+	lease, renewBefore time.Duration,
+	clock clock.Clock,
+	logger *logger.Logger,
+) WebhookSubscriptionService {
+	return &webhookSubscriptionService{
+		subscriptions:   subscriptions,
+		workspaces:      workspaces,
+		clients:         clients,
+		callbackBaseURL: strings.TrimRight(callbackBaseURL, "/"),
+		secrets:         secrets,
+		lease:           lease,
+		renewBefore:     renewBefore,
+		clock:           clock,
+		logger:          logger,
+	}
+}
+
+func (s *webhookSubscriptionService) Subscribe(ctx context.Context, tenantID, workspaceID string) ([]*models.WebhookSubscription, error) {
+	if s.callbackBaseURL == "" {
+		return nil, models.ErrWebhookCallbackNotSet
+	}
+	ws, err := s.workspaces.GetByID(ctx, tenantID, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	var subscribed []*models.WebhookSubscription
+	for _, platform := range models.Platforms {
+		if !ws.Connected(platform) {
+			continue
+		}
+		subscriber, err := s.subscriber(platform)
+		if err != nil {
+			return nil, err
+		}
+		if subscriber == nil {
+			continue
+		}
+
+		subscription, err := s.subscriptions.Get(ctx, tenantID, workspaceID, string(platform))
+		isNew := errors.Is(err, models.ErrSubscriptionNotFound)
+		switch {
+		case isNew:
+			subscription = &models.WebhookSubscription{TenantID: tenantID, WorkspaceID: workspaceID, Platform: string(platform)}
+		case err != nil:
+			return nil, fmt.Errorf("failed to get subscription: %w", err)
+		}
+
+		s.request(ctx, subscriber, ws, subscription)
+		if isNew {
+			err = s.subscriptions.Create(ctx, subscription)
+		} else {
+			err = s.subscriptions.Update(ctx, subscription)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save subscription: %w", err)
+		}
+		subscribed = append(subscribed, subscription)
+	}
+	if len(subscribed) == 0 {
+		return nil, models.ErrSubscriptionUnsupported
+	}
+	return subscribed, nil
+}
+
+func (s *webhookSubscriptionService) Health(ctx context.Context, tenantID string) (*WebhookSubscriptionHealth, error) {
+	subscriptions, err := s.subscriptions.List(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	now := s.clock.Now()
+	health := &WebhookSubscriptionHealth{Subscriptions: subscriptions}
+	if health.Subscriptions == nil {
+		health.Subscriptions = []*models.WebhookSubscription{}
+	}
+	for _, subscription := range subscriptions {
+		switch {
+		case subscription.Status == models.SubscriptionFailed:
+			health.Failed++
+		case subscription.Expired(now):
+			health.Expired++
+		case subscription.Status == models.SubscriptionPending:
+			health.Pending++
+		default:
+			health.Active++
+		}
+	}
+	health.Healthy = health.Failed == 0 && health.Expired == 0
+	return health, nil
+}
+
+func (s *webhookSubscriptionService) ConfirmLease(ctx context.Context, platform, topic string, lease time.Duration) error {
+	subscriptions, err := s.subscriptions.ListByTopic(ctx, platform, topic)
+	if err != nil {
+		return fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	now := s.clock.Now()
+	expiresAt := now.Add(lease)
+	for _, subscription := range subscriptions {
+		subscription.Status = models.SubscriptionActive
+		subscription.VerifiedAt = &now
+		subscription.ExpiresAt = &expiresAt
+		subscription.RenewAt = s.renewAt(now, expiresAt)
+		if err := s.subscriptions.Update(ctx, subscription); err != nil {
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+		s.logger.Info("Webhook subscription verified",
+			"tenant_id", subscription.TenantID, "workspace_id", subscription.WorkspaceID, "platform", platform, "expires_at", expiresAt)
+	}
+	return nil
+}
+
+func (s *webhookSubscriptionService) RenewDue(ctx context.Context) (*WebhookSubscriptionReport, error) {
+	report := &WebhookSubscriptionReport{}
+	if s.callbackBaseURL == "" {
+		return report, nil
+	}
+	due, err := s.subscriptions.ListDue(ctx, s.clock.Now(), maxDueSubscriptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due subscriptions: %w", err)
+	}
+
+	for _, subscription := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		report.Due++
+
+		platform := models.Platform(subscription.Platform)
+		ws, err := s.workspaces.GetByID(ctx, subscription.TenantID, subscription.WorkspaceID)
+		if errors.Is(err, models.ErrNotFound) || (err == nil && !ws.Connected(platform)) {
+			if err := s.subscriptions.Delete(ctx, subscription.TenantID, subscription.ID); err != nil {
+				return report, fmt.Errorf("failed to delete subscription: %w", err)
+			}
+			report.Removed++
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to get workspace: %w", err)
+		}
+		subscriber, err := s.subscriber(platform)
+		if err != nil || subscriber == nil {
+			s.logger.Error("No webhook subscriber for platform", "error", err, "platform", platform)
+			continue
+		}
+
+		s.request(ctx, subscriber, ws, subscription)
+		if err := s.subscriptions.Update(ctx, subscription); err != nil {
+			return report, fmt.Errorf("failed to update subscription: %w", err)
+		}
+		if subscription.Status == models.SubscriptionFailed {
+			report.Failed++
+		} else {
+			report.Renewed++
+		}
+	}
+	return report, nil
+}
+
+// subscriber returns the platform's client when it can subscribe webhooks,
+// nil otherwise
+func (s *webhookSubscriptionService) subscriber(platform models.Platform) (partners.WebhookSubscriber, error) {
+	client, err := s.clients(string(platform))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s client: %w", platform, err)
+	}
+	subscriber, ok := partners.Capability[partners.WebhookSubscriber](client)
+	if !ok {
+		return nil, nil
+	}
+	return subscriber, nil
+}
+
+// request asks the platform for the subscription and records the outcome on
+// it, including when the next request is due
+func (s *webhookSubscriptionService) request(ctx context.Context, subscriber partners.WebhookSubscriber, ws *models.Workspace, subscription *models.WebhookSubscription) {
+	subscription.CallbackURL = s.callbackBaseURL + "/webhooks/" + subscription.Platform
+
+	var lease *partners.WebhookLease
+	err := subscriber.Authenticate(ctx, ws)
+	if err == nil {
+		lease, err = subscriber.SubscribeWebhook(ctx, ws, partners.WebhookCallback{URL: subscription.CallbackURL, Secrets: s.secrets, Lease: s.lease})
+	}
+
+	now := s.clock.Now()
+	if err != nil {
+		subscription.Status = models.SubscriptionFailed
+		subscription.Failures++
+		subscription.LastError = err.Error()
+		subscription.RenewAt = now.Add(min(time.Hour<<min(subscription.Failures-1, 5), maxSubscriptionRetry))
+		s.logger.Warn("Webhook subscription refused",
+			"error", err, "tenant_id", subscription.TenantID, "workspace_id", subscription.WorkspaceID, "platform", subscription.Platform)
+		return
+	}
+
+	subscription.Topic = lease.Topic
+	subscription.Failures = 0
+	subscription.LastError = ""
+	switch {
+	case !lease.Verified:
+		// An active subscription stays so until its lease ends, while the
+		// platform verifies the renewal
+		if subscription.Status != models.SubscriptionActive {
+			subscription.Status = models.SubscriptionPending
+		}
+		subscription.RenewAt = now.Add(subscriptionVerifyTimeout)
+	case lease.ExpiresAt == nil:
+		subscription.Status = models.SubscriptionActive
+		subscription.VerifiedAt = &now
+		subscription.ExpiresAt = nil
+		subscription.RenewAt = now.Add(subscriptionRecheck)
+	default:
+		subscription.Status = models.SubscriptionActive
+		subscription.VerifiedAt = &now
+		subscription.ExpiresAt = lease.ExpiresAt
+		subscription.RenewAt = s.renewAt(now, *lease.ExpiresAt)
+	}
+}
+
+// renewAt is renewBefore the lease ends, or halfway through leases shorter
+// than that
+func (s *webhookSubscriptionService) renewAt(now, expiresAt time.Time) time.Time {
+	if renewAt := expiresAt.Add(-s.renewBefore); renewAt.After(now) {
+		return renewAt
+	}
+	return now.Add(expiresAt.Sub(now) / 2)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// memorySubscriptionRepo keeps subscriptions by ID
+type memorySubscriptionRepo struct {
+	subscriptions map[string]*models.WebhookSubscription
+}
+
+func (r *memorySubscriptionRepo) Create(ctx context.Context, subscription *models.WebhookSubscription) error {
+	subscription.ID = subscription.WorkspaceID + "/" + subscription.Platform
+	r.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Get(ctx context.Context, tenantID, workspaceID, platform string) (*models.WebhookSubscription, error) {
+	if subscription, ok := r.subscriptions[workspaceID+"/"+platform]; ok {
+		return subscription, nil
+	}
+	return nil, models.ErrSubscriptionNotFound
+}
+
+func (r *memorySubscriptionRepo) List(ctx context.Context, tenantID string) ([]*models.WebhookSubscription, error) {
+	var subscriptions []*models.WebhookSubscription
+	for _, subscription := range r.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, nil
+}
+
+func (r *memorySubscriptionRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.WebhookSubscription, error) {
+	var due []*models.WebhookSubscription
+	for _, subscription := range r.subscriptions {
+		if !subscription.RenewAt.After(now) {
+			due = append(due, subscription)
+		}
+	}
+	return due, nil
+}
+
+func (r *memorySubscriptionRepo) ListByTopic(ctx context.Context, platform, topic string) ([]*models.WebhookSubscription, error) {
+	var subscriptions []*models.WebhookSubscription
+	for _, subscription := range r.subscriptions {
+		if subscription.Platform == platform && subscription.Topic == topic {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (r *memorySubscriptionRepo) Update(ctx context.Context, subscription *models.WebhookSubscription) error {
+	r.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Delete(ctx context.Context, tenantID, id string) error {
+	delete(r.subscriptions, id)
+	return nil
+}
+
+// channelWorkspaceRepo knows "ws-1", connected to YouTube, Facebook and TikTok
+type channelWorkspaceRepo struct {
+	models.WorkspaceRepository
+	workspaces map[string]*models.Workspace
+}
+
+func (r *channelWorkspaceRepo) GetByID(ctx context.Context, tenantID, id string) (*models.Workspace, error) {
+	if ws, ok := r.workspaces[id]; ok {
+		return ws, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+// subscriberClient grants leases like the YouTube hub, verified later, or
+// like Meta, lasting until removed; refuse makes it fail instead
+type subscriberClient struct {
+	partners.Client
+	expires   bool
+	refuse    error
+	callbacks []partners.WebhookCallback
+}
+
+func (c *subscriberClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
+	return nil
+}
+
+func (c *subscriberClient) SubscribeWebhook(ctx context.Context, ws *models.Workspace, callback partners.WebhookCallback) (*partners.WebhookLease, error) {
+	c.callbacks = append(c.callbacks, callback)
+	if c.refuse != nil {
+		return nil, c.refuse
+	}
+	if c.expires {
+		expiresAt := time.Now().Add(callback.Lease)
+		return &partners.WebhookLease{Topic: "feed-" + ws.ID, ExpiresAt: &expiresAt}, nil
+	}
+	return &partners.WebhookLease{Topic: "page-" + ws.ID, Verified: true}, nil
+}
+
+// noSubscribeClient is a platform client that cannot subscribe webhooks
+type noSubscribeClient struct {
+	partners.Client
+}
+
+func newTestSubscriptionService(t *testing.T, baseURL string) (WebhookSubscriptionService, *memorySubscriptionRepo, *channelWorkspaceRepo, map[string]*subscriberClient, *clock.Fake) {
+	t.Helper()
+	repo := &memorySubscriptionRepo{subscriptions: map[string]*models.WebhookSubscription{}}
+	workspaces := &channelWorkspaceRepo{workspaces: map[string]*models.Workspace{
+		"ws-1": {ID: "ws-1", CredentialsPath: "creds.json", TokenDir: "tokens", FacebookPageID: "page-1", FacebookPageToken: "token",
+			TikTokAppID: "app", TikTokSecret: "secret"},
+	}}
+	clients := map[string]*subscriberClient{"youtube": {expires: true}, "facebook": {}}
+	clk := clock.NewFake(time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC))
+	svc := NewWebhookSubscriptionService(repo, workspaces, func(platform string) (partners.Client, error) {
+		if client, ok := clients[platform]; ok {
+			return client, nil
+		}
+		return &noSubscribeClient{}, nil
+	}, baseURL, partners.WebhookSecrets{YouTube: "hub-secret"}, 5*24*time.Hour, 24*time.Hour, clk, logger.New("error", "test"))
+	return svc, repo, workspaces, clients, clk
+}
+
+func TestWebhookSubscriptionService_Subscribe(t *testing.T) {
+	svc, repo, _, clients, clk := newTestSubscriptionService(t, "https://api.example.com/")
+	ctx := context.Background()
+
+	subscribed, err := svc.Subscribe(ctx, "tenant-1", "ws-1")
+	require.NoError(t, err)
+	require.Len(t, subscribed, 2, "TikTok pushes nothing to subscribe to")
+	assert.Equal(t, "https://api.example.com/webhooks/youtube", clients["youtube"].callbacks[0].URL)
+	assert.Equal(t, "hub-secret", clients["youtube"].callbacks[0].Secrets.YouTube)
+
+	youtube := repo.subscriptions["ws-1/youtube"]
+	assert.Equal(t, models.SubscriptionPending, youtube.Status, "until the hub verifies the callback")
+	assert.Equal(t, clk.Now().Add(subscriptionVerifyTimeout), youtube.RenewAt)
+	facebook := repo.subscriptions["ws-1/facebook"]
+	assert.Equal(t, models.SubscriptionActive, facebook.Status)
+	assert.Nil(t, facebook.ExpiresAt)
+	assert.Equal(t, clk.Now().Add(subscriptionRecheck), facebook.RenewAt)
+
+	// The hub verifies the callback, granting a shorter lease
+	require.NoError(t, svc.ConfirmLease(ctx, "youtube", "feed-ws-1", 2*24*time.Hour))
+	assert.Equal(t, models.SubscriptionActive, youtube.Status)
+	assert.Equal(t, clk.Now().Add(2*24*time.Hour), *youtube.ExpiresAt)
+	assert.Equal(t, clk.Now().Add(24*time.Hour), youtube.RenewAt)
+	require.NoError(t, svc.ConfirmLease(ctx, "youtube", "feed-unknown", time.Hour), "unknown topics are ignored")
+
+	health, err := svc.Health(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.True(t, health.Healthy)
+	assert.Equal(t, 2, health.Active)
+
+	_, err = svc.Subscribe(ctx, "tenant-1", "ws-9")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestWebhookSubscriptionService_SubscribeNeedsCallback(t *testing.T) {
+	svc, _, _, _, _ := newTestSubscriptionService(t, "")
+
+	_, err := svc.Subscribe(context.Background(), "tenant-1", "ws-1")
+	assert.ErrorIs(t, err, models.ErrWebhookCallbackNotSet)
+	report, err := svc.RenewDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, report.Due)
+}
+
+func TestWebhookSubscriptionService_RenewDue(t *testing.T) {
+	svc, repo, workspaces, clients, clk := newTestSubscriptionService(t, "https://api.example.com")
+	ctx := context.Background()
+	_, err := svc.Subscribe(ctx, "tenant-1", "ws-1")
+	require.NoError(t, err)
+	require.NoError(t, svc.ConfirmLease(ctx, "youtube", "feed-ws-1", 5*24*time.Hour))
+
+	// Nothing is due before renewBefore the lease ends
+	clk.Advance(3 * 24 * time.Hour)
+	report, err := svc.RenewDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, report.Due)
+
+	// The hub refuses the renewal: retried after an hour, then two
+	clk.Advance(24 * time.Hour)
+	clients["youtube"].refuse = errors.New("hub is down")
+	report, err = svc.RenewDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &WebhookSubscriptionReport{Due: 1, Failed: 1}, report)
+	youtube := repo.subscriptions["ws-1/youtube"]
+	assert.Equal(t, models.SubscriptionFailed, youtube.Status)
+	assert.Equal(t, "hub is down", youtube.LastError)
+	assert.Equal(t, clk.Now().Add(time.Hour), youtube.RenewAt)
+	clk.Advance(time.Hour)
+	_, err = svc.RenewDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, youtube.Failures)
+	assert.Equal(t, clk.Now().Add(2*time.Hour), youtube.RenewAt)
+
+	health, err := svc.Health(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.False(t, health.Healthy)
+	assert.Equal(t, 1, health.Failed)
+
+	// Back up, and the disconnected page's subscription is removed
+	clients["youtube"].refuse = nil
+	workspaces.workspaces["ws-1"].FacebookPageToken = ""
+	clk.Advance(7 * 24 * time.Hour)
+	report, err = svc.RenewDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &WebhookSubscriptionReport{Due: 2, Renewed: 1, Removed: 1}, report)
+	assert.Zero(t, youtube.Failures)
+	assert.NotContains(t, repo.subscriptions, "ws-1/facebook")
+}
+
+func TestWebhookSubscriptionService_HealthCountsExpiredLeases(t *testing.T) {
+	svc, repo, _, _, clk := newTestSubscriptionService(t, "https://api.example.com")
+	ended := clk.Now().Add(-time.Minute)
+	repo.subscriptions["ws-1/youtube"] = &models.WebhookSubscription{ID: "ws-1/youtube", Platform: "youtube", Status: models.SubscriptionActive, ExpiresAt: &ended}
+
+	health, err := svc.Health(context.Background(), "tenant-1")
+	require.NoError(t, err)
+	assert.False(t, health.Healthy)
+	assert.Equal(t, 1, health.Expired)
+	assert.Zero(t, health.Active)
+}
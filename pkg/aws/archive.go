@@ -0,0 +1,312 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// S3 storage classes used by the archive lifecycle
+const (
+	StorageClassStandard = "STANDARD"
+	StorageClassGlacier  = "GLACIER"
+)
+
+// Glacier retrieval tiers, fastest and most expensive first
+const (
+	RestoreTierExpedited = "Expedited"
+	RestoreTierStandard  = "Standard"
+	RestoreTierBulk      = "Bulk"
+)
+
+// ErrObjectNotFound is returned when the archived object does not exist
+var ErrObjectNotFound = errors.New("object not found")
+
+// ArchiveObjectStatus describes where an object is stored and whether a
+// temporary restored copy is available
+type ArchiveObjectStatus struct {
+	StorageClass   string
+	RestoreOngoing bool
+	// Restored is true when a readable copy of an archived object exists
+	Restored      bool
+	RestoreExpiry time.Time
+}
+
+// ArchiveStorage moves objects between S3 Standard and Glacier
+type ArchiveStorage interface {
+	// Archive rewrites the object in place with the Glacier storage class
+	Archive(ctx context.Context, bucket, key string) error
+	// Restore starts an asynchronous retrieval keeping a readable copy for days;
+	// requesting a restore that is already running is not an error
+	Restore(ctx context.Context, bucket, key string, days int, tier string) error
+	// Status reports the storage class and restore progress of the object
+	Status(ctx context.Context, bucket, key string) (*ArchiveObjectStatus, error)
+	// Unarchive copies a restored object back to the Standard storage class
+	Unarchive(ctx context.Context, bucket, key string) error
+	// Copy copies an object to another key, possibly in another bucket, in
+	// the Standard storage class. Archived objects must be restored first.
+	Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error
+}
+
+// S3Error is an error response returned by S3
+type S3Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *S3Error) Error() string {
+	return fmt.Sprintf("s3: %s (%d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// s3ArchiveStorage calls the S3 REST API directly with SigV4-signed requests;
+// only a few object operations are needed, which does not justify the full SDK
+type s3ArchiveStorage struct {
+	httpClient  *http.Client
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	region      string
+	endpoint    string
+	logger      *logger.Logger
+}
+
+var _ ArchiveStorage = (*s3ArchiveStorage)(nil)
+
+// NewS3ArchiveStorage creates an archive client using the default AWS
+// credential chain. A non-empty endpoint (e.g. LocalStack or MinIO) switches
+// to path-style addressing.
+func NewS3ArchiveStorage(region, endpoint string, logger *logger.Logger) (ArchiveStorage, error) {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return newS3ArchiveStorage(&http.Client{Timeout: 30 * time.Second}, awsConfig.Credentials, region, endpoint, logger), nil
+}
+
+func newS3ArchiveStorage(httpClient *http.Client, credentials aws.CredentialsProvider, region, endpoint string, logger *logger.Logger) *s3ArchiveStorage {
+	return &s3ArchiveStorage{
+		httpClient:  httpClient,
+		credentials: credentials,
+		// S3 signs the path exactly as sent rather than escaping it twice
+		signer:   v4.NewSigner(func(o *v4.SignerOptions) { o.DisableURIPathEscaping = true }),
+		region:   region,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		logger:   logger,
+	}
+}
+
+// Archive rewrites the object in place with the Glacier storage class.
+// CopyObject is limited to 5 GB; larger objects fail with an S3 error.
+func (s *s3ArchiveStorage) Archive(ctx context.Context, bucket, key string) error {
+	return s.copyInPlace(ctx, bucket, key, StorageClassGlacier)
+}
+
+// Unarchive copies a restored object back to the Standard storage class
+func (s *s3ArchiveStorage) Unarchive(ctx context.Context, bucket, key string) error {
+	return s.copyInPlace(ctx, bucket, key, StorageClassStandard)
+}
+
+// Copy copies the object with CopyObject, signed for the destination bucket;
+// like Archive, it is limited to objects of 5 GB
+func (s *s3ArchiveStorage) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	if err := s.copyObject(ctx, srcBucket, srcKey, dstBucket, dstKey, StorageClassStandard); err != nil {
+		return err
+	}
+	s.logger.Info("Copied S3 object", "src_bucket", srcBucket, "src_key", srcKey, "dst_bucket", dstBucket, "dst_key", dstKey)
+	return nil
+}
+
+func (s *s3ArchiveStorage) copyInPlace(ctx context.Context, bucket, key, storageClass string) error {
+	if err := s.copyObject(ctx, bucket, key, bucket, key, storageClass); err != nil {
+		return err
+	}
+	s.logger.Info("Changed S3 object storage class", "bucket", bucket, "key", key, "storage_class", storageClass)
+	return nil
+}
+
+func (s *s3ArchiveStorage) copyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey, storageClass string) error {
+	headers := http.Header{}
+	headers.Set("x-amz-copy-source", "/"+srcBucket+"/"+escapeKey(srcKey))
+	headers.Set("x-amz-storage-class", storageClass)
+	headers.Set("x-amz-metadata-directive", "COPY")
+
+	resp, body, err := s.do(ctx, http.MethodPut, dstBucket, dstKey, nil, headers, nil)
+	if err != nil {
+		return err
+	}
+	if err := checkS3Response(resp, body); err != nil {
+		return err
+	}
+	// CopyObject can fail after answering 200, with the error in the body
+	if bytes.Contains(body, []byte("<Error>")) {
+		return parseS3Error(http.StatusInternalServerError, body)
+	}
+	return nil
+}
+
+// restoreRequest is the RestoreObject request body
+type restoreRequest struct {
+	XMLName              xml.Name `xml:"RestoreRequest"`
+	Days                 int      `xml:"Days"`
+	GlacierJobParameters struct {
+		Tier string `xml:"Tier"`
+	} `xml:"GlacierJobParameters"`
+}
+
+// Restore starts an asynchronous Glacier retrieval
+func (s *s3ArchiveStorage) Restore(ctx context.Context, bucket, key string, days int, tier string) error {
+	req := restoreRequest{Days: days}
+	req.GlacierJobParameters.Tier = tier
+	payload, err := xml.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal restore request: %w", err)
+	}
+
+	query := url.Values{"restore": {""}}
+	resp, body, err := s.do(ctx, http.MethodPost, bucket, key, query, http.Header{"Content-Type": {"application/xml"}}, payload)
+	if err != nil {
+		return err
+	}
+	if err := checkS3Response(resp, body); err != nil {
+		var s3Err *S3Error
+		if errors.As(err, &s3Err) && s3Err.Code == "RestoreAlreadyInProgress" {
+			return nil
+		}
+		return err
+	}
+
+	s.logger.Info("Requested S3 object restore", "bucket", bucket, "key", key, "days", days, "tier", tier)
+	return nil
+}
+
+// restoreHeaderPattern parses the x-amz-restore header, e.g.
+// ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"
+var restoreHeaderPattern = regexp.MustCompile(`ongoing-request="(true|false)"(?:,\s*expiry-date="([^"]+)")?`)
+
+// Status reports the storage class and restore progress of the object
+func (s *s3ArchiveStorage) Status(ctx context.Context, bucket, key string) (*ArchiveObjectStatus, error) {
+	resp, body, err := s.do(ctx, http.MethodHead, bucket, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkS3Response(resp, body); err != nil {
+		return nil, err
+	}
+
+	status := &ArchiveObjectStatus{StorageClass: resp.Header.Get("x-amz-storage-class")}
+	if status.StorageClass == "" {
+		// S3 omits the header for Standard objects
+		status.StorageClass = StorageClassStandard
+	}
+
+	if m := restoreHeaderPattern.FindStringSubmatch(resp.Header.Get("x-amz-restore")); m != nil {
+		status.RestoreOngoing = m[1] == "true"
+		status.Restored = m[1] == "false"
+		if m[2] != "" {
+			if expiry, err := http.ParseTime(m[2]); err == nil {
+				status.RestoreExpiry = expiry
+			}
+		}
+	}
+	return status, nil
+}
+
+// objectURL builds the virtual-hosted URL, or the path-style one for a custom endpoint
+func (s *s3ArchiveStorage) objectURL(bucket, key string, query url.Values) string {
+	var u string
+	if s.endpoint != "" {
+		u = fmt.Sprintf("%s/%s/%s", s.endpoint, bucket, escapeKey(key))
+	} else {
+		u = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, s.region, escapeKey(key))
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// do signs and sends one request, returning the response with its body read
+func (s *s3ArchiveStorage) do(ctx context.Context, method, bucket, key string, query url.Values, headers http.Header, payload []byte) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(bucket, key, query), bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build S3 request: %w", err)
+	}
+	for name, values := range headers {
+		req.Header[http.CanonicalHeaderKey(name)] = values
+	}
+
+	sum := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	if err := s.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", s.region, time.Now()); err != nil {
+		return nil, nil, fmt.Errorf("failed to sign S3 request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("S3 %s %s failed: %w", method, key, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read S3 response: %w", err)
+	}
+	return resp, body, nil
+}
+
+// checkS3Response maps a non-2xx response to ErrObjectNotFound or an *S3Error
+func checkS3Response(resp *http.Response, body []byte) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrObjectNotFound
+	}
+	return parseS3Error(resp.StatusCode, body)
+}
+
+func parseS3Error(statusCode int, body []byte) error {
+	s3Err := &S3Error{StatusCode: statusCode}
+	if err := xml.Unmarshal(body, s3Err); err != nil || s3Err.Code == "" {
+		// HEAD responses carry no body
+		s3Err.Code = http.StatusText(statusCode)
+	}
+	return s3Err
+}
+
+// escapeKey percent-encodes an object key the way SigV4 expects: every byte
+// except unreserved characters and the "/" separators
+func escapeKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package aws
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// fakeArchiveObject is the in-memory state of one object
+type fakeArchiveObject struct {
+	storageClass    string
+	restoreStarted  time.Time
+	restoreDays     int
+	restoreComplete bool
+}
+
+// fakeArchiveStorage implements ArchiveStorage in memory. Unknown keys are
+// treated as Standard objects, and a restore completes once restoreDelay has
+// elapsed, mimicking the asynchronous Glacier retrieval.
+type fakeArchiveStorage struct {
+	mu           sync.Mutex
+	objects      map[string]*fakeArchiveObject
+	restoreDelay time.Duration
+	now          func() time.Time
+	logger       *logger.Logger
+}
+
+var _ ArchiveStorage = (*fakeArchiveStorage)(nil)
+
+// NewFakeArchiveStorage creates an archive client that keeps storage classes in
+// memory, so the lifecycle can run offline in development and tests
+func NewFakeArchiveStorage(restoreDelay time.Duration, logger *logger.Logger) ArchiveStorage {
+	return &fakeArchiveStorage{
+		objects:      make(map[string]*fakeArchiveObject),
+		restoreDelay: restoreDelay,
+		now:          time.Now,
+		logger:       logger,
+	}
+}
+
+func (f *fakeArchiveStorage) object(bucket, key string) *fakeArchiveObject {
+	id := bucket + "/" + key
+	obj, ok := f.objects[id]
+	if !ok {
+		obj = &fakeArchiveObject{storageClass: StorageClassStandard}
+		f.objects[id] = obj
+	}
+	return obj
+}
+
+// Archive moves the object to the Glacier storage class
+func (f *fakeArchiveStorage) Archive(ctx context.Context, bucket, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	*f.object(bucket, key) = fakeArchiveObject{storageClass: StorageClassGlacier}
+	f.logger.Debug("Fake archived S3 object", "bucket", bucket, "key", key)
+	return nil
+}
+
+// Restore starts a simulated retrieval; a running restore is left untouched
+func (f *fakeArchiveStorage) Restore(ctx context.Context, bucket, key string, days int, tier string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	obj := f.object(bucket, key)
+	if obj.storageClass != StorageClassGlacier {
+		return &S3Error{StatusCode: 403, Code: "InvalidObjectState", Message: "object is not archived"}
+	}
+	if obj.restoreStarted.IsZero() {
+		obj.restoreStarted = f.now()
+		obj.restoreDays = days
+	}
+	return nil
+}
+
+// Status reports the simulated storage class and restore progress
+func (f *fakeArchiveStorage) Status(ctx context.Context, bucket, key string) (*ArchiveObjectStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	obj := f.object(bucket, key)
+	status := &ArchiveObjectStatus{StorageClass: obj.storageClass}
+	if obj.restoreStarted.IsZero() {
+		return status, nil
+	}
+
+	if f.now().Sub(obj.restoreStarted) >= f.restoreDelay {
+		obj.restoreComplete = true
+	}
+	status.RestoreOngoing = !obj.restoreComplete
+	status.Restored = obj.restoreComplete
+	if obj.restoreComplete {
+		status.RestoreExpiry = obj.restoreStarted.Add(f.restoreDelay + time.Duration(obj.restoreDays)*24*time.Hour)
+	}
+	return status, nil
+}
+
+// Unarchive moves a restored object back to the Standard storage class
+func (f *fakeArchiveStorage) Unarchive(ctx context.Context, bucket, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	obj := f.object(bucket, key)
+	if obj.storageClass == StorageClassGlacier && !obj.restoreComplete {
+		return &S3Error{StatusCode: 403, Code: "InvalidObjectState", Message: "object is archived and not restored"}
+	}
+	*obj = fakeArchiveObject{storageClass: StorageClassStandard}
+	return nil
+}
+
+// Copy copies the simulated object to the Standard storage class
+func (f *fakeArchiveStorage) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	src := f.object(srcBucket, srcKey)
+	if src.storageClass == StorageClassGlacier && !src.restoreComplete {
+		return &S3Error{StatusCode: 403, Code: "InvalidObjectState", Message: "object is archived and not restored"}
+	}
+	*f.object(dstBucket, dstKey) = fakeArchiveObject{storageClass: StorageClassStandard}
+	f.logger.Debug("Fake copied S3 object", "src_bucket", srcBucket, "src_key", srcKey, "dst_bucket", dstBucket, "dst_key", dstKey)
+	return nil
+}
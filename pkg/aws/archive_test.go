@@ -0,0 +1,243 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedS3Request is what the test server saw for one call
+type recordedS3Request struct {
+	method string
+	path   string
+	query  string
+	header http.Header
+	body   string
+}
+
+func newTestArchiveStorage(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*s3ArchiveStorage, *[]recordedS3Request) {
+	t.Helper()
+	var requests []recordedS3Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, recordedS3Request{
+			method: r.Method,
+			path:   r.URL.EscapedPath(),
+			query:  r.URL.RawQuery,
+			header: r.Header.Clone(),
+			body:   string(body),
+		})
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	creds := aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKIDTEST", SecretAccessKey: "secret"}, nil
+	})
+	return newS3ArchiveStorage(server.Client(), creds, "eu-west-1", server.URL, logger.New("error", "test")), &requests
+}
+
+func TestS3ArchiveStorage_Archive(t *testing.T) {
+	storage, requests := newTestArchiveStorage(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "<CopyObjectResult><ETag>\"abc\"</ETag></CopyObjectResult>")
+	})
+
+	require.NoError(t, storage.Archive(context.Background(), "videos", "tenant-1/my clip.mp4"))
+
+	require.Len(t, *requests, 1)
+	req := (*requests)[0]
+	assert.Equal(t, http.MethodPut, req.method)
+	assert.Equal(t, "/videos/tenant-1/my%20clip.mp4", req.path)
+	assert.Equal(t, "/videos/tenant-1/my%20clip.mp4", req.header.Get("X-Amz-Copy-Source"))
+	assert.Equal(t, StorageClassGlacier, req.header.Get("X-Amz-Storage-Class"))
+	assert.Equal(t, "COPY", req.header.Get("X-Amz-Metadata-Directive"))
+	assert.Contains(t, req.header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDTEST/")
+	assert.Contains(t, req.header.Get("Authorization"), "/eu-west-1/s3/aws4_request")
+	assert.NotEmpty(t, req.header.Get("X-Amz-Content-Sha256"))
+}
+
+func TestS3ArchiveStorage_Copy(t *testing.T) {
+	storage, requests := newTestArchiveStorage(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "<CopyObjectResult><ETag>\"abc\"</ETag></CopyObjectResult>")
+	})
+
+	require.NoError(t, storage.Copy(context.Background(), "videos-eu", "agency/clip.mp4", "videos-us", "client/clip.mp4"))
+
+	require.Len(t, *requests, 1)
+	req := (*requests)[0]
+	assert.Equal(t, http.MethodPut, req.method)
+	assert.Equal(t, "/videos-us/client/clip.mp4", req.path)
+	assert.Equal(t, "/videos-eu/agency/clip.mp4", req.header.Get("X-Amz-Copy-Source"))
+	assert.Equal(t, StorageClassStandard, req.header.Get("X-Amz-Storage-Class"))
+}
+
+func TestS3ArchiveStorage_ArchiveErrorInSuccessfulResponse(t *testing.T) {
+	storage, _ := newTestArchiveStorage(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "<Error><Code>InternalError</Code><Message>We encountered an internal error.</Message></Error>")
+	})
+
+	err := storage.Archive(context.Background(), "videos", "clip.mp4")
+	var s3Err *S3Error
+	require.ErrorAs(t, err, &s3Err)
+	assert.Equal(t, "InternalError", s3Err.Code)
+}
+
+func TestS3ArchiveStorage_Restore(t *testing.T) {
+	t.Run("accepted", func(t *testing.T) {
+		storage, requests := newTestArchiveStorage(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		require.NoError(t, storage.Restore(context.Background(), "videos", "clip.mp4", 7, RestoreTierBulk))
+
+		req := (*requests)[0]
+		assert.Equal(t, http.MethodPost, req.method)
+		assert.Equal(t, "restore=", req.query)
+		assert.Equal(t, "<RestoreRequest><Days>7</Days><GlacierJobParameters><Tier>Bulk</Tier></GlacierJobParameters></RestoreRequest>", req.body)
+	})
+
+	t.Run("already in progress", func(t *testing.T) {
+		storage, _ := newTestArchiveStorage(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = io.WriteString(w, "<Error><Code>RestoreAlreadyInProgress</Code><Message>Object restore is already in progress</Message></Error>")
+		})
+
+		assert.NoError(t, storage.Restore(context.Background(), "videos", "clip.mp4", 7, RestoreTierStandard))
+	})
+
+	t.Run("not archived", func(t *testing.T) {
+		storage, _ := newTestArchiveStorage(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, "<Error><Code>InvalidObjectState</Code><Message>Restore is not allowed for the object's current storage class</Message></Error>")
+		})
+
+		err := storage.Restore(context.Background(), "videos", "clip.mp4", 7, RestoreTierStandard)
+		var s3Err *S3Error
+		require.ErrorAs(t, err, &s3Err)
+		assert.Equal(t, http.StatusForbidden, s3Err.StatusCode)
+		assert.Equal(t, "InvalidObjectState", s3Err.Code)
+	})
+}
+
+func TestS3ArchiveStorage_Status(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		status   int
+		expected *ArchiveObjectStatus
+		err      error
+	}{
+		{
+			name:     "standard object",
+			status:   http.StatusOK,
+			expected: &ArchiveObjectStatus{StorageClass: StorageClassStandard},
+		},
+		{
+			name:     "archived",
+			headers:  map[string]string{"x-amz-storage-class": "GLACIER"},
+			status:   http.StatusOK,
+			expected: &ArchiveObjectStatus{StorageClass: StorageClassGlacier},
+		},
+		{
+			name:     "restore ongoing",
+			headers:  map[string]string{"x-amz-storage-class": "GLACIER", "x-amz-restore": `ongoing-request="true"`},
+			status:   http.StatusOK,
+			expected: &ArchiveObjectStatus{StorageClass: StorageClassGlacier, RestoreOngoing: true},
+		},
+		{
+			name:    "restored",
+			headers: map[string]string{"x-amz-storage-class": "GLACIER", "x-amz-restore": `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`},
+			status:  http.StatusOK,
+			expected: &ArchiveObjectStatus{
+				StorageClass:  StorageClassGlacier,
+				Restored:      true,
+				RestoreExpiry: time.Date(2012, 12, 21, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:   "missing object",
+			status: http.StatusNotFound,
+			err:    ErrObjectNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, requests := newTestArchiveStorage(t, func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+			})
+
+			status, err := storage.Status(context.Background(), "videos", "clip.mp4")
+			assert.Equal(t, http.MethodHead, (*requests)[0].method)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, status)
+		})
+	}
+}
+
+func TestS3ArchiveStorage_VirtualHostedURL(t *testing.T) {
+	storage := newS3ArchiveStorage(http.DefaultClient, nil, "us-east-1", "", logger.New("error", "test"))
+	assert.Equal(t, "https://videos.s3.us-east-1.amazonaws.com/a/b%2Bc.mp4?restore=",
+		storage.objectURL("videos", "a/b+c.mp4", map[string][]string{"restore": {""}}))
+}
+
+func TestFakeArchiveStorage_Lifecycle(t *testing.T) {
+	storage := NewFakeArchiveStorage(time.Hour, logger.New("error", "test")).(*fakeArchiveStorage)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	storage.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, storage.Archive(ctx, "videos", "clip.mp4"))
+	assert.Error(t, storage.Unarchive(ctx, "videos", "clip.mp4"), "an archived object must be restored first")
+
+	require.NoError(t, storage.Restore(ctx, "videos", "clip.mp4", 3, RestoreTierStandard))
+	status, err := storage.Status(ctx, "videos", "clip.mp4")
+	require.NoError(t, err)
+	assert.True(t, status.RestoreOngoing)
+
+	now = now.Add(time.Hour)
+	status, err = storage.Status(ctx, "videos", "clip.mp4")
+	require.NoError(t, err)
+	assert.True(t, status.Restored)
+
+	require.NoError(t, storage.Unarchive(ctx, "videos", "clip.mp4"))
+	status, err = storage.Status(ctx, "videos", "clip.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, StorageClassStandard, status.StorageClass)
+}
+
+func TestS3ArchiveStorage_GetObject(t *testing.T) {
+	storage, requests := newTestArchiveStorage(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v2"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v2"`)
+		_, _ = io.WriteString(w, "version: 2")
+	})
+	ctx := context.Background()
+
+	object, err := storage.GetObject(ctx, "config", "prompts/catalog.yaml", `"v1"`)
+	require.NoError(t, err)
+	assert.Equal(t, &S3Object{Body: []byte("version: 2"), ETag: `"v2"`}, object)
+	assert.Equal(t, http.MethodGet, (*requests)[0].method)
+	assert.Equal(t, "/config/prompts/catalog.yaml", (*requests)[0].path)
+
+	_, err = storage.GetObject(ctx, "config", "prompts/catalog.yaml", `"v2"`)
+	assert.ErrorIs(t, err, ErrObjectNotModified)
+}
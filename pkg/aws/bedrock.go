@@ -22,10 +22,23 @@ const (
 	RoleAssistant MessageRole = "assistant"
 )
 
+// Content block types
+const (
+	ContentTypeText       = "text"
+	ContentTypeToolUse    = "tool_use"
+	ContentTypeToolResult = "tool_result"
+)
+
 // MessageContent represents the content of a message
 type MessageContent struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type      string                 `json:"type"`
+	Text      string                 `json:"text,omitempty"`
+	ID        string                 `json:"id,omitempty"`
+	Name      string                 `json:"name,omitempty"`
+	Input     map[string]interface{} `json:"input,omitempty"`
+	ToolUseID string                 `json:"tool_use_id,omitempty"`
+	Content   string                 `json:"content,omitempty"`
+	IsError   bool                   `json:"is_error,omitempty"`
 }
 
 // Message represents a single message in a conversation
@@ -57,6 +70,9 @@ type ModelConfig struct {
 	AnthropicVersion   string  `json:"anthropic_version,omitempty"`
 	SupportsStreaming  bool    `json:"supports_streaming"`
 	SupportsSystemRole bool    `json:"supports_system_role"`
+	ContextWindow      int     `json:"context_window"`
+	InputCostPerMTok   float64 `json:"input_cost_per_mtok"`
+	OutputCostPerMTok  float64 `json:"output_cost_per_mtok"`
 }
 
 // Helper functions for creating messages and conversations
@@ -136,6 +152,12 @@ func (r *ConversationRequest) WithTemperature(temperature float64) *Conversation
 	return r
 }
 
+// WithDeterministic requests greedy decoding (temperature 0) so repeated calls return stable output
+func (r *ConversationRequest) WithDeterministic() *ConversationRequest {
+	r.Deterministic = true
+	return r
+}
+
 // WithTopP sets the top-p for the conversation request
 func (r *ConversationRequest) WithTopP(topP float64) *ConversationRequest {
 	r.TopP = topP
@@ -160,6 +182,9 @@ func GetModelConfig(model FoundationModel) ModelConfig {
 			AnthropicVersion:   "bedrock-2023-05-31",
 			SupportsStreaming:  true,
 			SupportsSystemRole: true,
+			ContextWindow:      200000,
+			InputCostPerMTok:   3.0,
+			OutputCostPerMTok:  15.0,
 		}
 	case ModelClaude4Haiku:
 		return ModelConfig{
@@ -170,6 +195,9 @@ func GetModelConfig(model FoundationModel) ModelConfig {
 			AnthropicVersion:   "bedrock-2023-05-31",
 			SupportsStreaming:  true,
 			SupportsSystemRole: true,
+			ContextWindow:      200000,
+			InputCostPerMTok:   0.8,
+			OutputCostPerMTok:  4.0,
 		}
 	case ModelClaude3Sonnet:
 		return ModelConfig{
@@ -180,6 +208,9 @@ func GetModelConfig(model FoundationModel) ModelConfig {
 			AnthropicVersion:   "bedrock-2023-05-31",
 			SupportsStreaming:  true,
 			SupportsSystemRole: true,
+			ContextWindow:      200000,
+			InputCostPerMTok:   3.0,
+			OutputCostPerMTok:  15.0,
 		}
 	default:
 		// Default to Claude 4 Sonnet
@@ -187,6 +218,11 @@ func GetModelConfig(model FoundationModel) ModelConfig {
 	}
 }
 
+// EstimateCost returns the USD cost of a request given its input and output token counts
+func (m ModelConfig) EstimateCost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*m.InputCostPerMTok + float64(outputTokens)*m.OutputCostPerMTok) / 1_000_000
+}
+
 // BedrockClient defines the interface for AWS Bedrock operations
 type BedrockClient interface {
 	InvokeModel(ctx context.Context, req *InvokeModelRequest) (*InvokeModelResponse, error)
@@ -210,7 +246,10 @@ type InvokeModelRequest struct {
 // InvokeModelResponse represents a response from Bedrock model invocation
 type InvokeModelResponse struct {
 	Content      string                 `json:"content"`
+	ToolCalls    []ToolCall             `json:"tool_calls,omitempty"`
 	TokensUsed   int                    `json:"tokens_used"`
+	InputTokens  int                    `json:"input_tokens"`
+	OutputTokens int                    `json:"output_tokens"`
 	FinishReason string                 `json:"finish_reason"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 	ProcessedAt  time.Time              `json:"processed_at"`
@@ -234,13 +273,17 @@ type StreamChunk struct {
 
 // ConversationRequest represents a request to invoke a Bedrock model with conversation
 type ConversationRequest struct {
-	Model        FoundationModel        `json:"model"`
-	Conversation Conversation           `json:"conversation"`
-	MaxTokens    int                    `json:"max_tokens,omitempty"`
-	Temperature  float64                `json:"temperature,omitempty"`
-	TopP         float64                `json:"top_p,omitempty"`
-	StopWords    []string               `json:"stop_words,omitempty"`
-	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Model        FoundationModel  `json:"model"`
+	Conversation Conversation     `json:"conversation"`
+	MaxTokens    int              `json:"max_tokens,omitempty"`
+	Temperature  float64          `json:"temperature,omitempty"`
+	TopP         float64          `json:"top_p,omitempty"`
+	StopWords    []string         `json:"stop_words,omitempty"`
+	Tools        []ToolDefinition `json:"tools,omitempty"`
+	ToolChoice   *ToolChoice      `json:"tool_choice,omitempty"`
+	// Deterministic forces temperature 0; Claude models take no sampling seed
+	Deterministic bool                   `json:"deterministic,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // bedrockClient implements the BedrockClient interface
@@ -250,6 +293,8 @@ type bedrockClient struct {
 	config *BedrockConfig
 }
 
+var _ BedrockClient = (*bedrockClient)(nil)
+
 // BedrockConfig holds configuration for Bedrock client
 type BedrockConfig struct {
 	Region           string
@@ -260,17 +305,22 @@ type BedrockConfig struct {
 	DefaultMaxTokens int
 }
 
+// DefaultBedrockConfig returns the client settings used when none are given
+func DefaultBedrockConfig() *BedrockConfig {
+	return &BedrockConfig{
+		Region:           "us-east-1",
+		MaxRetries:       3,
+		RetryDelay:       time.Second,
+		RequestTimeout:   30 * time.Second,
+		DefaultModelID:   string(ModelClaude4Sonnet),
+		DefaultMaxTokens: 8192,
+	}
+}
+
 // NewBedrockClient creates a new Bedrock client
 func NewBedrockClient(cfg *BedrockConfig, logger *logger.Logger) (BedrockClient, error) {
 	if cfg == nil {
-		cfg = &BedrockConfig{
-			Region:           "us-east-1",
-			MaxRetries:       3,
-			RetryDelay:       time.Second,
-			RequestTimeout:   30 * time.Second,
-			DefaultModelID:   string(ModelClaude4Sonnet),
-			DefaultMaxTokens: 8192,
-		}
+		cfg = DefaultBedrockConfig()
 	}
 
 	// Load AWS configuration
@@ -393,6 +443,8 @@ func (c *bedrockClient) InvokeModel(ctx context.Context, req *InvokeModelRequest
 	result := &InvokeModelResponse{
 		Content:      content,
 		TokensUsed:   claudeResponse.Usage.InputTokens + claudeResponse.Usage.OutputTokens,
+		InputTokens:  claudeResponse.Usage.InputTokens,
+		OutputTokens: claudeResponse.Usage.OutputTokens,
 		FinishReason: claudeResponse.StopReason,
 		Metadata: map[string]interface{}{
 			"input_tokens":  claudeResponse.Usage.InputTokens,
@@ -544,7 +596,7 @@ func (c *bedrockClient) Health(ctx context.Context) error {
 
 // InvokeConversation invokes a Bedrock model with a conversation
 func (c *bedrockClient) InvokeConversation(ctx context.Context, req *ConversationRequest) (*InvokeModelResponse, error) {
-	c.logger.Info("Invoking Bedrock model with conversation", "model", req.Model, "message_count", len(req.Conversation.Messages))
+	c.logger.Info("Invoking Bedrock model with conversation", "model", req.Model, "message_count", len(req.Conversation.Messages), "tools", len(req.Tools))
 
 	// Get model configuration
 	modelConfig := GetModelConfig(req.Model)
@@ -559,6 +611,9 @@ func (c *bedrockClient) InvokeConversation(ctx context.Context, req *Conversatio
 	if req.Temperature > 0 {
 		temperature = req.Temperature
 	}
+	if req.Deterministic {
+		temperature = 0
+	}
 
 	topP := modelConfig.TopP
 	if req.TopP > 0 {
@@ -566,33 +621,7 @@ func (c *bedrockClient) InvokeConversation(ctx context.Context, req *Conversatio
 	}
 
 	// Convert conversation messages to Claude format
-	messages := make([]map[string]interface{}, 0, len(req.Conversation.Messages))
-	var systemMessage string
-
-	for _, msg := range req.Conversation.Messages {
-		if msg.Role == RoleSystem {
-			// Extract system message content
-			for _, content := range msg.Content {
-				if content.Type == "text" {
-					systemMessage += content.Text
-				}
-			}
-			continue
-		}
-
-		// Convert message content
-		var contentStr string
-		for _, content := range msg.Content {
-			if content.Type == "text" {
-				contentStr += content.Text
-			}
-		}
-
-		messages = append(messages, map[string]interface{}{
-			"role":    string(msg.Role),
-			"content": contentStr,
-		})
-	}
+	messages, systemMessage := toClaudeMessages(req.Conversation)
 
 	// Prepare Claude request body
 	claudeRequest := map[string]interface{}{
@@ -613,6 +642,14 @@ func (c *bedrockClient) InvokeConversation(ctx context.Context, req *Conversatio
 		claudeRequest["stop_sequences"] = req.StopWords
 	}
 
+	// Add tool definitions if provided
+	if len(req.Tools) > 0 {
+		claudeRequest["tools"] = req.Tools
+		if req.ToolChoice != nil {
+			claudeRequest["tool_choice"] = req.ToolChoice
+		}
+	}
+
 	requestBody, err := json.Marshal(claudeRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
@@ -652,11 +689,8 @@ func (c *bedrockClient) InvokeConversation(ctx context.Context, req *Conversatio
 
 	// Parse Claude response
 	var claudeResponse struct {
-		Content []struct {
-			Text string `json:"text"`
-			Type string `json:"type"`
-		} `json:"content"`
-		Usage struct {
+		Content []MessageContent `json:"content"`
+		Usage   struct {
 			InputTokens  int `json:"input_tokens"`
 			OutputTokens int `json:"output_tokens"`
 		} `json:"usage"`
@@ -667,17 +701,24 @@ func (c *bedrockClient) InvokeConversation(ctx context.Context, req *Conversatio
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// Extract content
+	// Extract text content and tool calls
 	var content string
+	var toolCalls []ToolCall
 	for _, c := range claudeResponse.Content {
-		if c.Type == "text" {
+		switch c.Type {
+		case ContentTypeText:
 			content += c.Text
+		case ContentTypeToolUse:
+			toolCalls = append(toolCalls, ToolCall{ID: c.ID, Name: c.Name, Input: c.Input})
 		}
 	}
 
 	result := &InvokeModelResponse{
 		Content:      content,
+		ToolCalls:    toolCalls,
 		TokensUsed:   claudeResponse.Usage.InputTokens + claudeResponse.Usage.OutputTokens,
+		InputTokens:  claudeResponse.Usage.InputTokens,
+		OutputTokens: claudeResponse.Usage.OutputTokens,
 		FinishReason: claudeResponse.StopReason,
 		Metadata: map[string]interface{}{
 			"input_tokens":  claudeResponse.Usage.InputTokens,
@@ -691,6 +732,7 @@ func (c *bedrockClient) InvokeConversation(ctx context.Context, req *Conversatio
 	c.logger.Info("Bedrock conversation completed successfully",
 		"model", req.Model,
 		"tokens_used", result.TokensUsed,
+		"tool_calls", len(result.ToolCalls),
 		"content_length", len(result.Content))
 
 	return result, nil
@@ -713,6 +755,9 @@ func (c *bedrockClient) InvokeConversationWithStreaming(ctx context.Context, req
 	if req.Temperature > 0 {
 		temperature = req.Temperature
 	}
+	if req.Deterministic {
+		temperature = 0
+	}
 
 	topP := modelConfig.TopP
 	if req.TopP > 0 {
@@ -720,33 +765,7 @@ func (c *bedrockClient) InvokeConversationWithStreaming(ctx context.Context, req
 	}
 
 	// Convert conversation messages to Claude format
-	messages := make([]map[string]interface{}, 0, len(req.Conversation.Messages))
-	var systemMessage string
-
-	for _, msg := range req.Conversation.Messages {
-		if msg.Role == RoleSystem {
-			// Extract system message content
-			for _, content := range msg.Content {
-				if content.Type == "text" {
-					systemMessage += content.Text
-				}
-			}
-			continue
-		}
-
-		// Convert message content
-		var contentStr string
-		for _, content := range msg.Content {
-			if content.Type == "text" {
-				contentStr += content.Text
-			}
-		}
-
-		messages = append(messages, map[string]interface{}{
-			"role":    string(msg.Role),
-			"content": contentStr,
-		})
-	}
+	messages, systemMessage := toClaudeMessages(req.Conversation)
 
 	// Prepare Claude request body
 	claudeRequest := map[string]interface{}{
@@ -767,6 +786,14 @@ func (c *bedrockClient) InvokeConversationWithStreaming(ctx context.Context, req
 		claudeRequest["stop_sequences"] = req.StopWords
 	}
 
+	// Add tool definitions if provided
+	if len(req.Tools) > 0 {
+		claudeRequest["tools"] = req.Tools
+		if req.ToolChoice != nil {
+			claudeRequest["tool_choice"] = req.ToolChoice
+		}
+	}
+
 	requestBody, err := json.Marshal(claudeRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
@@ -0,0 +1,243 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// CassetteMode controls whether Bedrock calls are recorded or replayed
+type CassetteMode string
+
+const (
+	CassetteOff    CassetteMode = "off"
+	CassetteRecord CassetteMode = "record"
+	CassetteReplay CassetteMode = "replay"
+)
+
+// ErrCassetteMiss is returned in replay mode when no response was recorded for a request
+var ErrCassetteMiss = errors.New("no recorded Bedrock response for request")
+
+// CassetteInteraction is a recorded request/response pair
+type CassetteInteraction struct {
+	Request  json.RawMessage      `json:"request"`
+	Response *InvokeModelResponse `json:"response"`
+}
+
+// Cassette holds recorded Bedrock interactions keyed by request fingerprint
+type Cassette struct {
+	Interactions map[string]*CassetteInteraction `json:"interactions"`
+}
+
+// cassetteClient records or replays the calls of a wrapped BedrockClient
+type cassetteClient struct {
+	inner    BedrockClient
+	path     string
+	mode     CassetteMode
+	logger   *logger.Logger
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+var _ BedrockClient = (*cassetteClient)(nil)
+
+// ParseCassetteMode parses a cassette mode, treating an empty value as off
+func ParseCassetteMode(mode string) (CassetteMode, error) {
+	switch CassetteMode(mode) {
+	case "", CassetteOff:
+		return CassetteOff, nil
+	case CassetteRecord, CassetteReplay:
+		return CassetteMode(mode), nil
+	default:
+		return "", fmt.Errorf("invalid cassette mode: %s (must be one of: off, record, replay)", mode)
+	}
+}
+
+// NewCassetteClient wraps a Bedrock client with a record/replay layer backed by
+// a JSON file. In replay mode inner may be nil and no call ever reaches AWS;
+// in record mode every successful call is forwarded to inner and saved.
+// Streaming calls are recorded and replayed as a single chunk.
+func NewCassetteClient(inner BedrockClient, path string, mode CassetteMode, logger *logger.Logger) (BedrockClient, error) {
+	if mode == CassetteOff {
+		return inner, nil
+	}
+	if mode == CassetteRecord && inner == nil {
+		return nil, fmt.Errorf("cassette record mode requires a Bedrock client")
+	}
+
+	cassette, err := loadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Bedrock cassette enabled", "mode", mode, "path", path, "interactions", len(cassette.Interactions))
+
+	return &cassetteClient{
+		inner:    inner,
+		path:     path,
+		mode:     mode,
+		logger:   logger,
+		cassette: cassette,
+	}, nil
+}
+
+// InvokeModel replays or records a prompt invocation
+func (c *cassetteClient) InvokeModel(ctx context.Context, req *InvokeModelRequest) (*InvokeModelResponse, error) {
+	return c.do(map[string]interface{}{
+		"kind":        "prompt",
+		"model_id":    req.ModelID,
+		"prompt":      req.Prompt,
+		"max_tokens":  req.MaxTokens,
+		"temperature": req.Temperature,
+		"top_p":       req.TopP,
+		"stop_words":  req.StopWords,
+	}, func() (*InvokeModelResponse, error) {
+		return c.inner.InvokeModel(ctx, req)
+	})
+}
+
+// InvokeModelWithStreaming replays or records a prompt invocation as a single-chunk stream
+func (c *cassetteClient) InvokeModelWithStreaming(ctx context.Context, req *InvokeModelRequest) (*StreamingResponse, error) {
+	resp, err := c.InvokeModel(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return replayStream(resp), nil
+}
+
+// InvokeConversation replays or records a conversation invocation
+func (c *cassetteClient) InvokeConversation(ctx context.Context, req *ConversationRequest) (*InvokeModelResponse, error) {
+	return c.do(map[string]interface{}{
+		"kind":          "conversation",
+		"model":         req.Model,
+		"messages":      req.Conversation.Messages,
+		"max_tokens":    req.MaxTokens,
+		"temperature":   req.Temperature,
+		"top_p":         req.TopP,
+		"stop_words":    req.StopWords,
+		"tools":         req.Tools,
+		"tool_choice":   req.ToolChoice,
+		"deterministic": req.Deterministic,
+	}, func() (*InvokeModelResponse, error) {
+		return c.inner.InvokeConversation(ctx, req)
+	})
+}
+
+// InvokeConversationWithStreaming replays or records a conversation as a single-chunk stream
+func (c *cassetteClient) InvokeConversationWithStreaming(ctx context.Context, req *ConversationRequest) (*StreamingResponse, error) {
+	resp, err := c.InvokeConversation(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return replayStream(resp), nil
+}
+
+// Health reports healthy in replay mode and delegates otherwise
+func (c *cassetteClient) Health(ctx context.Context) error {
+	if c.mode == CassetteReplay {
+		return nil
+	}
+	return c.inner.Health(ctx)
+}
+
+// do looks up the request fingerprint, invoking and recording on a miss in record mode
+func (c *cassetteClient) do(request map[string]interface{}, invoke func() (*InvokeModelResponse, error)) (*InvokeModelResponse, error) {
+	raw, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cassette request: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	key := hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	interaction, ok := c.cassette.Interactions[key]
+	c.mu.Unlock()
+	if ok {
+		c.logger.Debug("Replaying recorded Bedrock response", "key", key)
+		resp := *interaction.Response
+		return &resp, nil
+	}
+
+	if c.mode == CassetteReplay {
+		return nil, fmt.Errorf("%w (key %s)", ErrCassetteMiss, key)
+	}
+
+	resp, err := invoke()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cassette.Interactions[key] = &CassetteInteraction{Request: raw, Response: resp}
+	if err := c.save(); err != nil {
+		c.logger.Error("Failed to save Bedrock cassette", "error", err, "path", c.path)
+	}
+	c.logger.Info("Recorded Bedrock response", "key", key, "path", c.path)
+
+	return resp, nil
+}
+
+// save writes the cassette atomically; callers must hold c.mu
+func (c *cassetteClient) save() error {
+	data, err := json.MarshalIndent(c.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// loadCassette reads a cassette file, returning an empty one if it does not exist
+func loadCassette(path string) (*Cassette, error) {
+	cassette := &Cassette{Interactions: make(map[string]*CassetteInteraction)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cassette, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	if cassette.Interactions == nil {
+		cassette.Interactions = make(map[string]*CassetteInteraction)
+	}
+	return cassette, nil
+}
+
+// replayStream emits a recorded response as a single complete chunk
+func replayStream(resp *InvokeModelResponse) *StreamingResponse {
+	stream := &StreamingResponse{
+		Stream: make(chan StreamChunk, 2),
+		Error:  make(chan error, 1),
+		Done:   make(chan bool, 1),
+	}
+
+	stream.Stream <- StreamChunk{Content: resp.Content, TokensUsed: resp.TokensUsed, Metadata: resp.Metadata, ProcessedAt: time.Now()}
+	stream.Stream <- StreamChunk{IsComplete: true, ProcessedAt: time.Now()}
+	stream.Done <- true
+
+	close(stream.Stream)
+	close(stream.Error)
+	close(stream.Done)
+	return stream
+}
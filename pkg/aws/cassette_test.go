@@ -0,0 +1,85 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassetteClient_RecordThenReplay(t *testing.T) {
+	ctx := context.Background()
+	log := logger.New("error", "test")
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	request := func(prompt string) *ConversationRequest {
+		return NewConversationRequest(ModelClaude4Haiku, NewConversation(NewUserMessage(prompt))).WithDeterministic()
+	}
+
+	inner := &scriptedClient{
+		responses: []*InvokeModelResponse{{Content: "Recorded title", FinishReason: "end_turn", InputTokens: 12, OutputTokens: 3}},
+	}
+
+	recorder, err := NewCassetteClient(inner, path, CassetteRecord, log)
+	require.NoError(t, err)
+
+	resp, err := recorder.InvokeConversation(ctx, request("Write a title"))
+	require.NoError(t, err)
+	assert.Equal(t, "Recorded title", resp.Content)
+
+	// Already recorded requests are served from the cassette
+	_, err = recorder.InvokeConversation(ctx, request("Write a title"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+
+	// Replay needs no underlying client
+	player, err := NewCassetteClient(nil, path, CassetteReplay, log)
+	require.NoError(t, err)
+
+	resp, err = player.InvokeConversation(ctx, request("Write a title"))
+	require.NoError(t, err)
+	assert.Equal(t, "Recorded title", resp.Content)
+	assert.Equal(t, 12, resp.InputTokens)
+
+	stream, err := player.InvokeConversationWithStreaming(ctx, request("Write a title"))
+	require.NoError(t, err)
+	var content string
+	for chunk := range stream.Stream {
+		content += chunk.Content
+	}
+	assert.Equal(t, "Recorded title", content)
+
+	_, err = player.InvokeConversation(ctx, request("Write a description"))
+	assert.ErrorIs(t, err, ErrCassetteMiss)
+
+	assert.NoError(t, player.Health(ctx))
+}
+
+func TestParseCassetteMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected CassetteMode
+		wantErr  bool
+	}{
+		{input: "", expected: CassetteOff},
+		{input: "off", expected: CassetteOff},
+		{input: "record", expected: CassetteRecord},
+		{input: "replay", expected: CassetteReplay},
+		{input: "rewind", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			mode, err := ParseCassetteMode(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mode)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// FailoverConfig configures failover between two Bedrock regions
+type FailoverConfig struct {
+	// Name identifies the region pair in logs, metrics and health, e.g. the residency
+	Name            string
+	PrimaryRegion   string
+	SecondaryRegion string
+	// FailureThreshold is the number of consecutive retryable failures in the
+	// primary region after which calls go to the secondary region first
+	FailureThreshold int
+	// Cooldown is how long calls stay on the secondary region before the
+	// primary region is tried again
+	Cooldown time.Duration
+}
+
+// FailoverObserver is told about regional calls and failovers, to export metrics
+type FailoverObserver interface {
+	// RecordBedrockRegionCall counts a call to a region; status is success,
+	// retryable (throttling, capacity, timeout) or error
+	RecordBedrockRegionCall(region, status string)
+	// RecordBedrockFailover counts a switch of the preferred region of name
+	RecordBedrockFailover(name, from, to string, failedOver bool)
+}
+
+// FailoverStatus is the health of one region pair
+type FailoverStatus struct {
+	Name                string     `json:"name"`
+	PrimaryRegion       string     `json:"primary_region"`
+	SecondaryRegion     string     `json:"secondary_region"`
+	ActiveRegion        string     `json:"active_region"`
+	FailedOver          bool       `json:"failed_over"`
+	FailedOverAt        *time.Time `json:"failed_over_at,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// FailoverReporter is implemented by Bedrock clients that fail over between
+// regions
+type FailoverReporter interface {
+	FailoverStatus() []FailoverStatus
+}
+
+// failoverBedrockClient calls the primary region and retries calls that fail
+// with throttling, capacity or timeout errors in the secondary region. After
+// FailureThreshold such failures in a row it calls the secondary region first
+// until Cooldown has passed, then gives the primary region another chance.
+type failoverBedrockClient struct {
+	primary   BedrockClient
+	secondary BedrockClient
+	cfg       FailoverConfig
+	observer  FailoverObserver
+	logger    *logger.Logger
+	now       func() time.Time
+
+	mu           sync.Mutex
+	failures     int
+	failedOverAt time.Time
+}
+
+var (
+	_ BedrockClient    = (*failoverBedrockClient)(nil)
+	_ FailoverReporter = (*failoverBedrockClient)(nil)
+)
+
+// NewFailoverBedrockClient wraps the clients of a primary and a secondary
+// region; observer may be nil
+func NewFailoverBedrockClient(primary, secondary BedrockClient, cfg FailoverConfig, observer FailoverObserver, logger *logger.Logger) (BedrockClient, error) {
+	if primary == nil || secondary == nil {
+		return nil, fmt.Errorf("bedrock failover %s: primary and secondary clients are required", cfg.Name)
+	}
+	if cfg.PrimaryRegion == cfg.SecondaryRegion {
+		return nil, fmt.Errorf("bedrock failover %s: secondary region must differ from %s", cfg.Name, cfg.PrimaryRegion)
+	}
+	if cfg.FailureThreshold <= 0 || cfg.Cooldown <= 0 {
+		return nil, fmt.Errorf("bedrock failover %s: failure threshold and cooldown must be positive", cfg.Name)
+	}
+	return &failoverBedrockClient{
+		primary:   primary,
+		secondary: secondary,
+		cfg:       cfg,
+		observer:  observer,
+		logger:    logger,
+		now:       time.Now,
+	}, nil
+}
+
+// regionClient pairs a client with its region for routing
+type regionClient struct {
+	region string
+	client BedrockClient
+}
+
+// order returns the regions to try, preferred region first
+func (c *failoverBedrockClient) order() []regionClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	primary := regionClient{region: c.cfg.PrimaryRegion, client: c.primary}
+	secondary := regionClient{region: c.cfg.SecondaryRegion, client: c.secondary}
+	if !c.failedOverAt.IsZero() && c.now().Sub(c.failedOverAt) < c.cfg.Cooldown {
+		return []regionClient{secondary, primary}
+	}
+	return []regionClient{primary, secondary}
+}
+
+// record updates the failure count of the primary region after a call
+func (c *failoverBedrockClient) record(region string, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+		if ShouldFallback(err) {
+			status = "retryable"
+		}
+	}
+	if c.observer != nil {
+		c.observer.RecordBedrockRegionCall(region, status)
+	}
+	if region != c.cfg.PrimaryRegion {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch status {
+	case "success":
+		c.failures = 0
+		if !c.failedOverAt.IsZero() {
+			c.failedOverAt = time.Time{}
+			c.logger.Info("Bedrock primary region recovered", "name", c.cfg.Name, "region", c.cfg.PrimaryRegion)
+			c.notify(c.cfg.SecondaryRegion, c.cfg.PrimaryRegion, false)
+		}
+	case "retryable":
+		c.failures++
+		if c.failures < c.cfg.FailureThreshold {
+			return
+		}
+		// Failing again after the cooldown restarts it
+		wasFailedOver := !c.failedOverAt.IsZero()
+		c.failedOverAt = c.now()
+		if !wasFailedOver {
+			c.logger.Warn("Bedrock failing over to secondary region",
+				"name", c.cfg.Name, "from", c.cfg.PrimaryRegion, "to", c.cfg.SecondaryRegion, "failures", c.failures)
+			c.notify(c.cfg.PrimaryRegion, c.cfg.SecondaryRegion, true)
+		}
+	}
+}
+
+func (c *failoverBedrockClient) notify(from, to string, failedOver bool) {
+	if c.observer != nil {
+		c.observer.RecordBedrockFailover(c.cfg.Name, from, to, failedOver)
+	}
+}
+
+// invoke runs call in the preferred region and, when it fails with a
+// retryable error, in the other one. Other errors, such as invalid requests,
+// would fail in any region and are returned as is.
+func invoke[T any](ctx context.Context, c *failoverBedrockClient, call func(BedrockClient) (T, error)) (T, error) {
+	var zero T
+	var errs []error
+	for i, rc := range c.order() {
+		if i > 0 && ctx.Err() != nil {
+			break
+		}
+		result, err := call(rc.client)
+		c.record(rc.region, err)
+		if err == nil {
+			if i > 0 {
+				c.logger.Warn("Bedrock call served by fallback region", "name", c.cfg.Name, "region", rc.region)
+			}
+			return result, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", rc.region, err))
+		if !ShouldFallback(err) {
+			break
+		}
+	}
+	return zero, errors.Join(errs...)
+}
+
+// InvokeModel invokes the model, failing over between regions
+func (c *failoverBedrockClient) InvokeModel(ctx context.Context, req *InvokeModelRequest) (*InvokeModelResponse, error) {
+	return invoke(ctx, c, func(client BedrockClient) (*InvokeModelResponse, error) {
+		return client.InvokeModel(ctx, req)
+	})
+}
+
+// InvokeModelWithStreaming starts the stream, failing over between regions;
+// a stream that fails after it started is not moved
+func (c *failoverBedrockClient) InvokeModelWithStreaming(ctx context.Context, req *InvokeModelRequest) (*StreamingResponse, error) {
+	return invoke(ctx, c, func(client BedrockClient) (*StreamingResponse, error) {
+		return client.InvokeModelWithStreaming(ctx, req)
+	})
+}
+
+// InvokeConversation invokes the conversation, failing over between regions
+func (c *failoverBedrockClient) InvokeConversation(ctx context.Context, req *ConversationRequest) (*InvokeModelResponse, error) {
+	return invoke(ctx, c, func(client BedrockClient) (*InvokeModelResponse, error) {
+		return client.InvokeConversation(ctx, req)
+	})
+}
+
+// InvokeConversationWithStreaming starts the conversation stream, failing
+// over between regions
+func (c *failoverBedrockClient) InvokeConversationWithStreaming(ctx context.Context, req *ConversationRequest) (*StreamingResponse, error) {
+	return invoke(ctx, c, func(client BedrockClient) (*StreamingResponse, error) {
+		return client.InvokeConversationWithStreaming(ctx, req)
+	})
+}
+
+// Health reports an error only when neither region is healthy
+func (c *failoverBedrockClient) Health(ctx context.Context) error {
+	var errs []error
+	for _, rc := range c.order() {
+		err := rc.client.Health(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", rc.region, err))
+	}
+	return errors.Join(errs...)
+}
+
+// FailoverStatus reports the active region of the pair
+func (c *failoverBedrockClient) FailoverStatus() []FailoverStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := FailoverStatus{
+		Name:                c.cfg.Name,
+		PrimaryRegion:       c.cfg.PrimaryRegion,
+		SecondaryRegion:     c.cfg.SecondaryRegion,
+		ActiveRegion:        c.cfg.PrimaryRegion,
+		ConsecutiveFailures: c.failures,
+	}
+	if !c.failedOverAt.IsZero() {
+		at := c.failedOverAt
+		status.FailedOverAt = &at
+		if c.now().Sub(c.failedOverAt) < c.cfg.Cooldown {
+			status.FailedOver = true
+			status.ActiveRegion = c.cfg.SecondaryRegion
+		}
+	}
+	return []FailoverStatus{status}
+}
@@ -0,0 +1,181 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/residency"
+)
+
+// flakyRegionClient answers with its region, or with err while it is set
+type flakyRegionClient struct {
+	BedrockClient
+	region string
+	err    error
+	calls  int
+}
+
+func (c *flakyRegionClient) InvokeModel(ctx context.Context, req *InvokeModelRequest) (*InvokeModelResponse, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &InvokeModelResponse{Content: c.region}, nil
+}
+
+func (c *flakyRegionClient) Health(ctx context.Context) error {
+	return c.err
+}
+
+// recordingObserver keeps the failover events
+type recordingObserver struct {
+	calls     map[string]int
+	failovers []string
+}
+
+func (o *recordingObserver) RecordBedrockRegionCall(region, status string) {
+	o.calls[region+"/"+status]++
+}
+
+func (o *recordingObserver) RecordBedrockFailover(name, from, to string, failedOver bool) {
+	o.failovers = append(o.failovers, from+"->"+to)
+}
+
+type failoverFixture struct {
+	client    *failoverBedrockClient
+	primary   *flakyRegionClient
+	secondary *flakyRegionClient
+	observer  *recordingObserver
+	now       time.Time
+}
+
+func newFailoverFixture(t *testing.T) *failoverFixture {
+	f := &failoverFixture{
+		primary:   &flakyRegionClient{region: "us-east-1"},
+		secondary: &flakyRegionClient{region: "us-west-2"},
+		observer:  &recordingObserver{calls: make(map[string]int)},
+		now:       time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC),
+	}
+	client, err := NewFailoverBedrockClient(f.primary, f.secondary, FailoverConfig{
+		Name:             "us",
+		PrimaryRegion:    "us-east-1",
+		SecondaryRegion:  "us-west-2",
+		FailureThreshold: 3,
+		Cooldown:         time.Minute,
+	}, f.observer, logger.New("error", "test"))
+	require.NoError(t, err)
+	f.client = client.(*failoverBedrockClient)
+	f.client.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *failoverFixture) invoke(t *testing.T) string {
+	t.Helper()
+	resp, err := f.client.InvokeModel(context.Background(), &InvokeModelRequest{})
+	require.NoError(t, err)
+	return resp.Content
+}
+
+func TestFailoverBedrockClient_RetriesThrottledCallInSecondary(t *testing.T) {
+	f := newFailoverFixture(t)
+	assert.Equal(t, "us-east-1", f.invoke(t))
+
+	f.primary.err = &types.ThrottlingException{}
+	assert.Equal(t, "us-west-2", f.invoke(t), "a throttled call is served by the secondary region")
+	assert.False(t, f.client.FailoverStatus()[0].FailedOver, "one failure is not sustained")
+	assert.Equal(t, 1, f.observer.calls["us-east-1/retryable"])
+}
+
+func TestFailoverBedrockClient_FailsOverAfterSustainedFailures(t *testing.T) {
+	f := newFailoverFixture(t)
+	f.primary.err = &types.ThrottlingException{}
+
+	for i := 0; i < 3; i++ {
+		f.invoke(t)
+	}
+	status := f.client.FailoverStatus()[0]
+	assert.True(t, status.FailedOver)
+	assert.Equal(t, "us-west-2", status.ActiveRegion)
+	assert.Equal(t, []string{"us-east-1->us-west-2"}, f.observer.failovers)
+
+	// While failed over the primary region is not called at all
+	calls := f.primary.calls
+	assert.Equal(t, "us-west-2", f.invoke(t))
+	assert.Equal(t, calls, f.primary.calls)
+
+	// After the cooldown the recovered primary region takes the traffic back
+	f.primary.err = nil
+	f.now = f.now.Add(time.Minute)
+	assert.Equal(t, "us-east-1", f.invoke(t))
+	assert.False(t, f.client.FailoverStatus()[0].FailedOver)
+	assert.Equal(t, []string{"us-east-1->us-west-2", "us-west-2->us-east-1"}, f.observer.failovers)
+}
+
+func TestFailoverBedrockClient_FailingPrimaryRestartsCooldown(t *testing.T) {
+	f := newFailoverFixture(t)
+	f.primary.err = &types.ThrottlingException{}
+	for i := 0; i < 3; i++ {
+		f.invoke(t)
+	}
+
+	f.now = f.now.Add(time.Minute)
+	assert.Equal(t, "us-west-2", f.invoke(t), "the primary region is tried again and still throttles")
+	assert.True(t, f.client.FailoverStatus()[0].FailedOver)
+	assert.Len(t, f.observer.failovers, 1)
+}
+
+func TestFailoverBedrockClient_DoesNotRetryInvalidRequests(t *testing.T) {
+	f := newFailoverFixture(t)
+	f.primary.err = &types.ValidationException{}
+
+	_, err := f.client.InvokeModel(context.Background(), &InvokeModelRequest{})
+	var validation *types.ValidationException
+	assert.ErrorAs(t, err, &validation)
+	assert.Zero(t, f.secondary.calls)
+	assert.Zero(t, f.client.FailoverStatus()[0].ConsecutiveFailures)
+}
+
+func TestFailoverBedrockClient_BothRegionsFailing(t *testing.T) {
+	f := newFailoverFixture(t)
+	f.primary.err = &types.ThrottlingException{}
+	f.secondary.err = &types.ModelTimeoutException{}
+
+	_, err := f.client.InvokeModel(context.Background(), &InvokeModelRequest{})
+	require.Error(t, err)
+	assert.True(t, ShouldFallback(err), "the model fallback chain can still try another model")
+	assert.Error(t, f.client.Health(context.Background()))
+
+	f.secondary.err = nil
+	assert.NoError(t, f.client.Health(context.Background()), "one healthy region is enough")
+}
+
+func TestNewFailoverBedrockClient_Validation(t *testing.T) {
+	log := logger.New("error", "test")
+	us := &flakyRegionClient{region: "us-east-1"}
+
+	_, err := NewFailoverBedrockClient(us, us, FailoverConfig{PrimaryRegion: "us-east-1", SecondaryRegion: "us-east-1", FailureThreshold: 1, Cooldown: time.Second}, nil, log)
+	assert.Error(t, err)
+	_, err = NewFailoverBedrockClient(us, nil, FailoverConfig{PrimaryRegion: "us-east-1", SecondaryRegion: "us-west-2", FailureThreshold: 1, Cooldown: time.Second}, nil, log)
+	assert.Error(t, err)
+	_, err = NewFailoverBedrockClient(us, us, FailoverConfig{PrimaryRegion: "us-east-1", SecondaryRegion: "us-west-2"}, nil, log)
+	assert.Error(t, err)
+}
+
+func TestResidencyBedrockClient_FailoverStatus(t *testing.T) {
+	f := newFailoverFixture(t)
+	client, err := NewResidencyBedrockClient(map[residency.Residency]BedrockClient{
+		residency.US: f.client,
+		residency.EU: &regionStubClient{region: "eu-west-1"},
+	}, residency.US)
+	require.NoError(t, err)
+
+	statuses := client.(FailoverReporter).FailoverStatus()
+	require.Len(t, statuses, 1, "only regions with a secondary report a status")
+	assert.Equal(t, "us", statuses[0].Name)
+}
@@ -0,0 +1,150 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// fakeCannedResponses maps a keyword found in the prompt instruction to a canned reply
+var fakeCannedResponses = []struct {
+	keyword  string
+	response string
+}{
+	{"title", "1. Mastering Go in 10 Minutes\n2. The Go Tricks Nobody Told You\n3. Why Everyone Is Switching to Go"},
+	{"description", "In this video we walk through the essentials step by step, with practical examples you can reuse right away. Don't forget to like and subscribe for more!"},
+	{"tags", "#golang #programming #tutorial #coding #developer"},
+	{"summar", "This video introduces the topic, demonstrates the key ideas with examples and closes with a short recap."},
+}
+
+// fakeDefaultResponse is returned when no canned response matches
+const fakeDefaultResponse = "This is a canned response from the fake Bedrock client."
+
+// fakeBedrockClient implements BedrockClient with canned responses and no AWS calls
+type fakeBedrockClient struct {
+	logger *logger.Logger
+}
+
+var _ BedrockClient = (*fakeBedrockClient)(nil)
+
+// NewFakeBedrockClient creates a Bedrock client that returns canned responses,
+// so the API can run fully offline in development and tests
+func NewFakeBedrockClient(logger *logger.Logger) BedrockClient {
+	return &fakeBedrockClient{logger: logger}
+}
+
+// InvokeModel returns a canned response for the prompt
+func (c *fakeBedrockClient) InvokeModel(ctx context.Context, req *InvokeModelRequest) (*InvokeModelResponse, error) {
+	modelID := req.ModelID
+	if modelID == "" {
+		modelID = string(ModelClaude4Sonnet)
+	}
+	return c.respond(ctx, modelID, req.Prompt)
+}
+
+// InvokeModelWithStreaming streams a canned response for the prompt word by word
+func (c *fakeBedrockClient) InvokeModelWithStreaming(ctx context.Context, req *InvokeModelRequest) (*StreamingResponse, error) {
+	resp, err := c.InvokeModel(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return fakeStream(resp), nil
+}
+
+// InvokeConversation returns a canned response for the last user message
+func (c *fakeBedrockClient) InvokeConversation(ctx context.Context, req *ConversationRequest) (*InvokeModelResponse, error) {
+	var prompt string
+	for _, msg := range req.Conversation.Messages {
+		if msg.Role != RoleUser {
+			continue
+		}
+		var text strings.Builder
+		for _, content := range msg.Content {
+			text.WriteString(content.Text)
+			text.WriteString(content.Content)
+		}
+		prompt = text.String()
+	}
+	return c.respond(ctx, string(req.Model), prompt)
+}
+
+// InvokeConversationWithStreaming streams a canned response word by word
+func (c *fakeBedrockClient) InvokeConversationWithStreaming(ctx context.Context, req *ConversationRequest) (*StreamingResponse, error) {
+	resp, err := c.InvokeConversation(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return fakeStream(resp), nil
+}
+
+// Health always reports the fake client as healthy
+func (c *fakeBedrockClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// respond picks the canned response matching the prompt
+func (c *fakeBedrockClient) respond(ctx context.Context, modelID, prompt string) (*InvokeModelResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Catalog prompts state their task on the first line
+	content := fakeDefaultResponse
+	lower := strings.ToLower(firstLine(prompt))
+	for _, canned := range fakeCannedResponses {
+		if strings.Contains(lower, canned.keyword) {
+			content = canned.response
+			break
+		}
+	}
+
+	// Rough token estimates keep cost and usage reporting populated
+	inputTokens := len(prompt)/4 + 1
+	outputTokens := len(content)/4 + 1
+
+	c.logger.Debug("Fake Bedrock response", "model_id", modelID, "prompt_length", len(prompt))
+
+	return &InvokeModelResponse{
+		Content:      content,
+		TokensUsed:   inputTokens + outputTokens,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		FinishReason: "end_turn",
+		Metadata: map[string]interface{}{
+			"input_tokens":  inputTokens,
+			"output_tokens": outputTokens,
+			"model_id":      modelID,
+			"fake":          true,
+		},
+		ProcessedAt: time.Now(),
+	}, nil
+}
+
+// firstLine returns the first non-empty line of the prompt
+func firstLine(prompt string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(prompt), "\n")
+	return line
+}
+
+// fakeStream emits a response one word per chunk
+func fakeStream(resp *InvokeModelResponse) *StreamingResponse {
+	words := strings.SplitAfter(resp.Content, " ")
+	stream := &StreamingResponse{
+		Stream: make(chan StreamChunk, len(words)+1),
+		Error:  make(chan error, 1),
+		Done:   make(chan bool, 1),
+	}
+
+	for _, word := range words {
+		stream.Stream <- StreamChunk{Content: word, ProcessedAt: time.Now()}
+	}
+	stream.Stream <- StreamChunk{IsComplete: true, TokensUsed: resp.TokensUsed, ProcessedAt: time.Now()}
+	stream.Done <- true
+
+	close(stream.Stream)
+	close(stream.Error)
+	close(stream.Done)
+	return stream
+}
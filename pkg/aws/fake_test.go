@@ -0,0 +1,55 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeBedrockClient_InvokeConversation(t *testing.T) {
+	client := NewFakeBedrockClient(logger.New("error", "test"))
+
+	tests := []struct {
+		name     string
+		prompt   string
+		expected string
+	}{
+		{name: "title prompt", prompt: "Generate 5 compelling video titles.\nTopic: Go", expected: fakeCannedResponses[0].response},
+		{name: "tags prompt", prompt: "Generate relevant tags and hashtags for a video", expected: fakeCannedResponses[2].response},
+		{name: "unknown prompt", prompt: "Hello", expected: fakeDefaultResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := NewConversationRequest(ModelClaude4Sonnet, NewConversation(
+				NewSystemMessage("You write titles."),
+				NewUserMessage(tt.prompt),
+			))
+
+			resp, err := client.InvokeConversation(context.Background(), req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.Content)
+			assert.Positive(t, resp.InputTokens)
+			assert.Equal(t, resp.InputTokens+resp.OutputTokens, resp.TokensUsed)
+		})
+	}
+}
+
+func TestFakeBedrockClient_Streaming(t *testing.T) {
+	client := NewFakeBedrockClient(logger.New("error", "test"))
+
+	stream, err := client.InvokeModelWithStreaming(context.Background(), &InvokeModelRequest{Prompt: "Write a description"})
+	require.NoError(t, err)
+
+	var content string
+	complete := false
+	for chunk := range stream.Stream {
+		content += chunk.Content
+		complete = complete || chunk.IsComplete
+	}
+	assert.Equal(t, fakeCannedResponses[1].response, content)
+	assert.True(t, complete)
+}
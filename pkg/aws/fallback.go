@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// modelAliases maps the short model names accepted by the API to Bedrock model IDs
+var modelAliases = map[string]FoundationModel{
+	"claude-sonnet":   ModelClaude4Sonnet,
+	"claude-haiku":    ModelClaude4Haiku,
+	"claude-3-sonnet": ModelClaude3Sonnet,
+}
+
+// ParseFoundationModel resolves a model alias or full Bedrock model ID
+func ParseFoundationModel(name string) (FoundationModel, error) {
+	name = strings.TrimSpace(name)
+	if model, ok := modelAliases[strings.ToLower(name)]; ok {
+		return model, nil
+	}
+	for _, model := range modelAliases {
+		if string(model) == name {
+			return model, nil
+		}
+	}
+	return "", fmt.Errorf("unknown model: %s", name)
+}
+
+// ModelAlias returns the short name of a foundation model, or its ID if it has none
+func ModelAlias(model FoundationModel) string {
+	for alias, m := range modelAliases {
+		if m == model {
+			return alias
+		}
+	}
+	return string(model)
+}
+
+// ShouldFallback reports whether an invocation error means the next model in a
+// fallback chain should be tried: throttling, capacity and timeout failures.
+func ShouldFallback(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var throttling *types.ThrottlingException
+	var quota *types.ServiceQuotaExceededException
+	var timeout *types.ModelTimeoutException
+	var notReady *types.ModelNotReadyException
+	var internal *types.InternalServerException
+
+	return errors.As(err, &throttling) ||
+		errors.As(err, &quota) ||
+		errors.As(err, &timeout) ||
+		errors.As(err, &notReady) ||
+		errors.As(err, &internal) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "throttling", err: fmt.Errorf("failed after 4 attempts: %w", &types.ThrottlingException{}), expected: true},
+		{name: "model timeout", err: &types.ModelTimeoutException{}, expected: true},
+		{name: "deadline exceeded", err: fmt.Errorf("invoke: %w", context.DeadlineExceeded), expected: true},
+		{name: "validation", err: &types.ValidationException{}, expected: false},
+		{name: "access denied", err: &types.AccessDeniedException{}, expected: false},
+		{name: "other", err: errors.New("boom"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ShouldFallback(tt.err))
+		})
+	}
+}
+
+func TestParseFoundationModel(t *testing.T) {
+	model, err := ParseFoundationModel("Claude-Haiku")
+	assert.NoError(t, err)
+	assert.Equal(t, ModelClaude4Haiku, model)
+
+	model, err = ParseFoundationModel(string(ModelClaude3Sonnet))
+	assert.NoError(t, err)
+	assert.Equal(t, ModelClaude3Sonnet, model)
+
+	_, err = ParseFoundationModel("unknown")
+	assert.Error(t, err)
+}
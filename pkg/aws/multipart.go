@@ -0,0 +1,219 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// S3 multipart limits: every part but the last is at least MinPartSize, and
+// an upload has at most MaxParts parts
+const (
+	MinPartSize = 5 << 20
+	MaxParts    = 10000
+)
+
+// unsignedPayload is the payload hash of presigned requests, whose body is
+// not known when signing
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// UploadedPart is a part S3 holds for a multipart upload
+type UploadedPart struct {
+	PartNumber int    `json:"part_number" xml:"PartNumber"`
+	ETag       string `json:"etag" xml:"ETag"`
+	Size       int64  `json:"size" xml:"Size"`
+}
+
+// MultipartStorage uploads large objects in parts. Parts can be sent through
+// the API or straight to S3 with presigned URLs, and an upload can be resumed
+// from the parts S3 already holds.
+type MultipartStorage interface {
+	// CreateMultipartUpload starts an upload and returns its S3 upload ID
+	CreateMultipartUpload(ctx context.Context, bucket, key, contentType string) (string, error)
+	// UploadPart uploads one part, numbered from 1, and returns its ETag
+	UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, body []byte) (string, error)
+	// PresignUploadPart returns a URL the client PUTs the part to until it
+	// expires
+	PresignUploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, expires time.Duration) (string, error)
+	// ListParts returns the parts uploaded so far by part number, or
+	// ErrObjectNotFound once the upload was completed or aborted
+	ListParts(ctx context.Context, bucket, key, uploadID string) ([]UploadedPart, error)
+	// CompleteMultipartUpload assembles the parts into the object
+	CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []UploadedPart) error
+	// AbortMultipartUpload discards the upload and its parts; aborting an
+	// upload that no longer exists is not an error
+	AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error
+}
+
+var _ MultipartStorage = (*s3ArchiveStorage)(nil)
+
+// NewS3MultipartStorage creates an S3 multipart upload client using the
+// default AWS credential chain. A non-empty endpoint switches to path-style
+// addressing.
+func NewS3MultipartStorage(region, endpoint string, logger *logger.Logger) (MultipartStorage, error) {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	// Parts are up to hundreds of MB, so requests get longer than the 30 s
+	// other object calls do
+	return newS3ArchiveStorage(&http.Client{Timeout: 10 * time.Minute}, awsConfig.Credentials, region, endpoint, logger), nil
+}
+
+// CreateMultipartUpload starts an upload with CreateMultipartUpload
+func (s *s3ArchiveStorage) CreateMultipartUpload(ctx context.Context, bucket, key, contentType string) (string, error) {
+	headers := http.Header{}
+	if contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+
+	resp, body, err := s.do(ctx, http.MethodPost, bucket, key, url.Values{"uploads": {""}}, headers, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := checkS3Response(resp, body); err != nil {
+		return "", err
+	}
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.Unmarshal(body, &result); err != nil || result.UploadID == "" {
+		return "", fmt.Errorf("invalid CreateMultipartUpload response: %s", body)
+	}
+	s.logger.Info("Started S3 multipart upload", "bucket", bucket, "key", key, "upload_id", result.UploadID)
+	return result.UploadID, nil
+}
+
+// UploadPart uploads one part with UploadPart
+func (s *s3ArchiveStorage) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, body []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+	resp, answer, err := s.do(ctx, http.MethodPut, bucket, key, query, nil, body)
+	if err != nil {
+		return "", err
+	}
+	if err := checkS3Response(resp, answer); err != nil {
+		return "", err
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// PresignUploadPart presigns an UploadPart request with an unsigned payload
+func (s *s3ArchiveStorage) PresignUploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, expires time.Duration) (string, error) {
+	query := url.Values{
+		"partNumber":    {strconv.Itoa(partNumber)},
+		"uploadId":      {uploadID},
+		"X-Amz-Expires": {strconv.Itoa(int(expires.Seconds()))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(bucket, key, query), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build S3 request: %w", err)
+	}
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	signed, _, err := s.signer.PresignHTTP(ctx, creds, req, unsignedPayload, "s3", s.region, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to presign S3 request: %w", err)
+	}
+	return signed, nil
+}
+
+// listPartsResult is one page of the ListParts response
+type listPartsResult struct {
+	Parts                []UploadedPart `xml:"Part"`
+	IsTruncated          bool           `xml:"IsTruncated"`
+	NextPartNumberMarker int            `xml:"NextPartNumberMarker"`
+}
+
+// ListParts reads every page of ListParts
+func (s *s3ArchiveStorage) ListParts(ctx context.Context, bucket, key, uploadID string) ([]UploadedPart, error) {
+	var parts []UploadedPart
+	marker := 0
+	for {
+		query := url.Values{"uploadId": {uploadID}}
+		if marker > 0 {
+			query.Set("part-number-marker", strconv.Itoa(marker))
+		}
+		resp, body, err := s.do(ctx, http.MethodGet, bucket, key, query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkS3Response(resp, body); err != nil {
+			return nil, err
+		}
+
+		var page listPartsResult
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("invalid ListParts response: %w", err)
+		}
+		parts = append(parts, page.Parts...)
+		if !page.IsTruncated || page.NextPartNumberMarker <= marker {
+			return parts, nil
+		}
+		marker = page.NextPartNumberMarker
+	}
+}
+
+// completeMultipartUpload is the CompleteMultipartUpload request body
+type completeMultipartUpload struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []completePart `xml:"Part"`
+}
+
+type completePart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// CompleteMultipartUpload assembles the parts with CompleteMultipartUpload
+func (s *s3ArchiveStorage) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []UploadedPart) error {
+	req := completeMultipartUpload{Parts: make([]completePart, len(parts))}
+	for i, part := range parts {
+		req.Parts[i] = completePart{PartNumber: part.PartNumber, ETag: part.ETag}
+	}
+	payload, err := xml.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal complete request: %w", err)
+	}
+
+	query := url.Values{"uploadId": {uploadID}}
+	resp, body, err := s.do(ctx, http.MethodPost, bucket, key, query, http.Header{"Content-Type": {"application/xml"}}, payload)
+	if err != nil {
+		return err
+	}
+	if err := checkS3Response(resp, body); err != nil {
+		return err
+	}
+	// Like CopyObject, CompleteMultipartUpload can fail after answering 200
+	if bytes.Contains(body, []byte("<Error>")) {
+		return parseS3Error(http.StatusInternalServerError, body)
+	}
+
+	s.logger.Info("Completed S3 multipart upload", "bucket", bucket, "key", key, "upload_id", uploadID, "parts", len(parts))
+	return nil
+}
+
+// AbortMultipartUpload discards the upload with AbortMultipartUpload
+func (s *s3ArchiveStorage) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	resp, body, err := s.do(ctx, http.MethodDelete, bucket, key, url.Values{"uploadId": {uploadID}}, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := checkS3Response(resp, body); err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return err
+	}
+	s.logger.Info("Aborted S3 multipart upload", "bucket", bucket, "key", key, "upload_id", uploadID)
+	return nil
+}
@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3MultipartStorage_Upload(t *testing.T) {
+	storage, requests := newTestArchiveStorage(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPost && query.Has("uploads"):
+			_, _ = io.WriteString(w, "<InitiateMultipartUploadResult><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>")
+		case r.Method == http.MethodPut:
+			w.Header().Set("ETag", `"etag-`+query.Get("partNumber")+`"`)
+		case r.Method == http.MethodGet && query.Get("part-number-marker") == "":
+			_, _ = io.WriteString(w, `<ListPartsResult><Part><PartNumber>1</PartNumber><ETag>"etag-1"</ETag><Size>5242880</Size></Part>`+
+				`<IsTruncated>true</IsTruncated><NextPartNumberMarker>1</NextPartNumberMarker></ListPartsResult>`)
+		case r.Method == http.MethodGet:
+			_, _ = io.WriteString(w, `<ListPartsResult><Part><PartNumber>2</PartNumber><ETag>"etag-2"</ETag><Size>12</Size></Part>`+
+				`<IsTruncated>false</IsTruncated></ListPartsResult>`)
+		case r.Method == http.MethodPost:
+			_, _ = io.WriteString(w, "<CompleteMultipartUploadResult><ETag>\"final\"</ETag></CompleteMultipartUploadResult>")
+		}
+	})
+	ctx := context.Background()
+
+	uploadID, err := storage.CreateMultipartUpload(ctx, "videos", "tenant-1/videos/v1/clip.mp4", "video/mp4")
+	require.NoError(t, err)
+	assert.Equal(t, "upload-1", uploadID)
+	assert.Equal(t, "video/mp4", (*requests)[0].header.Get("Content-Type"))
+
+	etag, err := storage.UploadPart(ctx, "videos", "tenant-1/videos/v1/clip.mp4", uploadID, 2, []byte("last 12 byte"))
+	require.NoError(t, err)
+	assert.Equal(t, `"etag-2"`, etag)
+	assert.Equal(t, "partNumber=2&uploadId=upload-1", (*requests)[1].query)
+	assert.Equal(t, "last 12 byte", (*requests)[1].body)
+
+	parts, err := storage.ListParts(ctx, "videos", "tenant-1/videos/v1/clip.mp4", uploadID)
+	require.NoError(t, err)
+	assert.Equal(t, []UploadedPart{{PartNumber: 1, ETag: `"etag-1"`, Size: 5242880}, {PartNumber: 2, ETag: `"etag-2"`, Size: 12}}, parts)
+	assert.Equal(t, "part-number-marker=1&uploadId=upload-1", (*requests)[3].query, "every page is read")
+
+	require.NoError(t, storage.CompleteMultipartUpload(ctx, "videos", "tenant-1/videos/v1/clip.mp4", uploadID, parts))
+	complete := (*requests)[4]
+	assert.Equal(t, "uploadId=upload-1", complete.query)
+	assert.Equal(t, `<CompleteMultipartUpload><Part><PartNumber>1</PartNumber><ETag>&#34;etag-1&#34;</ETag></Part>`+
+		`<Part><PartNumber>2</PartNumber><ETag>&#34;etag-2&#34;</ETag></Part></CompleteMultipartUpload>`, complete.body)
+}
+
+func TestS3MultipartStorage_CompleteAndAbortErrors(t *testing.T) {
+	storage, _ := newTestArchiveStorage(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, "<Error><Code>NoSuchUpload</Code></Error>")
+			return
+		}
+		_, _ = io.WriteString(w, "<Error><Code>InvalidPart</Code><Message>One or more of the specified parts could not be found.</Message></Error>")
+	})
+	ctx := context.Background()
+
+	err := storage.CompleteMultipartUpload(ctx, "videos", "clip.mp4", "upload-1", []UploadedPart{{PartNumber: 1, ETag: `"etag-1"`}})
+	var s3Err *S3Error
+	require.ErrorAs(t, err, &s3Err, "an error answered with 200 is still an error")
+	assert.Equal(t, "InvalidPart", s3Err.Code)
+
+	assert.NoError(t, storage.AbortMultipartUpload(ctx, "videos", "clip.mp4", "upload-1"), "an upload already gone is aborted")
+}
+
+func TestS3MultipartStorage_PresignUploadPart(t *testing.T) {
+	storage, requests := newTestArchiveStorage(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	signed, err := storage.PresignUploadPart(context.Background(), "videos", "tenant-1/my clip.mp4", "upload-1", 3, 15*time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, *requests, "presigning sends nothing")
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/videos/tenant-1/my%20clip.mp4", u.EscapedPath())
+	query := u.Query()
+	assert.Equal(t, "3", query.Get("partNumber"))
+	assert.Equal(t, "upload-1", query.Get("uploadId"))
+	assert.Equal(t, "900", query.Get("X-Amz-Expires"))
+	assert.True(t, strings.HasPrefix(query.Get("X-Amz-Credential"), "AKIDTEST/"))
+	assert.Contains(t, query.Get("X-Amz-Credential"), "/eu-west-1/s3/aws4_request")
+	assert.NotEmpty(t, query.Get("X-Amz-Signature"))
+}
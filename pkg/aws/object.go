@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// ErrObjectNotModified is returned by GetObject when the object still has
+// the ETag the caller already read
+var ErrObjectNotModified = errors.New("object not modified")
+
+// S3Object is the content of an object with the ETag of that version
+type S3Object struct {
+	Body []byte
+	ETag string
+}
+
+// ObjectStore reads and copies whole objects, such as configuration files,
+// in S3
+type ObjectStore interface {
+	// GetObject reads the object. With a non-empty etag it is a conditional
+	// read returning ErrObjectNotModified while the object keeps that ETag.
+	GetObject(ctx context.Context, bucket, key, etag string) (*S3Object, error)
+	// Copy replaces the destination object with the source one at once
+	Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error
+}
+
+var _ ObjectStore = (*s3ArchiveStorage)(nil)
+
+// NewS3ObjectStore creates an S3 object client using the default AWS
+// credential chain. A non-empty endpoint switches to path-style addressing.
+func NewS3ObjectStore(region, endpoint string, logger *logger.Logger) (ObjectStore, error) {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return newS3ArchiveStorage(&http.Client{Timeout: 30 * time.Second}, awsConfig.Credentials, region, endpoint, logger), nil
+}
+
+// GetObject reads the object with GetObject, conditional on If-None-Match
+func (s *s3ArchiveStorage) GetObject(ctx context.Context, bucket, key, etag string) (*S3Object, error) {
+	headers := http.Header{}
+	if etag != "" {
+		headers.Set("If-None-Match", etag)
+	}
+
+	resp, body, err := s.do(ctx, http.MethodGet, bucket, key, nil, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrObjectNotModified
+	}
+	if err := checkS3Response(resp, body); err != nil {
+		return nil, err
+	}
+	return &S3Object{Body: body, ETag: resp.Header.Get("ETag")}, nil
+}
@@ -0,0 +1,201 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/residency"
+)
+
+// residencyBedrockClient sends each call to the Bedrock client of the
+// residency carried by the context
+type residencyBedrockClient struct {
+	clients    map[residency.Residency]BedrockClient
+	defaultRes residency.Residency
+}
+
+var (
+	_ BedrockClient    = (*residencyBedrockClient)(nil)
+	_ FailoverReporter = (*residencyBedrockClient)(nil)
+)
+
+// NewResidencyBedrockClient routes calls by the residency scope of their
+// context. Calls without a scope, or whose residency has no client, use the
+// default residency, except for pinned tenants which get residency.ErrCrossRegion.
+func NewResidencyBedrockClient(clients map[residency.Residency]BedrockClient, defaultResidency residency.Residency) (BedrockClient, error) {
+	if _, ok := clients[defaultResidency]; !ok {
+		return nil, fmt.Errorf("no Bedrock client for default residency %s", defaultResidency)
+	}
+	return &residencyBedrockClient{clients: clients, defaultRes: defaultResidency}, nil
+}
+
+func (c *residencyBedrockClient) client(ctx context.Context) (BedrockClient, error) {
+	scope, ok := residency.FromContext(ctx)
+	if !ok || scope.Residency == "" {
+		return c.clients[c.defaultRes], nil
+	}
+	if client, ok := c.clients[scope.Residency]; ok {
+		return client, nil
+	}
+	if scope.Pinned {
+		return nil, fmt.Errorf("%w: no Bedrock region for residency %s", residency.ErrCrossRegion, scope.Residency)
+	}
+	return c.clients[c.defaultRes], nil
+}
+
+// InvokeModel invokes the model in the residency's region
+func (c *residencyBedrockClient) InvokeModel(ctx context.Context, req *InvokeModelRequest) (*InvokeModelResponse, error) {
+	client, err := c.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.InvokeModel(ctx, req)
+}
+
+// InvokeModelWithStreaming streams from the model in the residency's region
+func (c *residencyBedrockClient) InvokeModelWithStreaming(ctx context.Context, req *InvokeModelRequest) (*StreamingResponse, error) {
+	client, err := c.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.InvokeModelWithStreaming(ctx, req)
+}
+
+// InvokeConversation invokes the conversation in the residency's region
+func (c *residencyBedrockClient) InvokeConversation(ctx context.Context, req *ConversationRequest) (*InvokeModelResponse, error) {
+	client, err := c.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.InvokeConversation(ctx, req)
+}
+
+// InvokeConversationWithStreaming streams the conversation in the residency's region
+func (c *residencyBedrockClient) InvokeConversationWithStreaming(ctx context.Context, req *ConversationRequest) (*StreamingResponse, error) {
+	client, err := c.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.InvokeConversationWithStreaming(ctx, req)
+}
+
+// Health checks every regional client
+func (c *residencyBedrockClient) Health(ctx context.Context) error {
+	for r, client := range c.clients {
+		if err := client.Health(ctx); err != nil {
+			return fmt.Errorf("bedrock %s: %w", r, err)
+		}
+	}
+	return nil
+}
+
+// FailoverStatus collects the failover status of the regional clients
+func (c *residencyBedrockClient) FailoverStatus() []FailoverStatus {
+	var statuses []FailoverStatus
+	for _, r := range residency.All {
+		if reporter, ok := c.clients[r].(FailoverReporter); ok {
+			statuses = append(statuses, reporter.FailoverStatus()...)
+		}
+	}
+	return statuses
+}
+
+// bucketArchiveStorage sends each call to the client of the bucket's region
+type bucketArchiveStorage struct {
+	clients  map[string]ArchiveStorage
+	fallback ArchiveStorage
+}
+
+var _ ArchiveStorage = (*bucketArchiveStorage)(nil)
+
+// NewBucketArchiveStorage routes calls by bucket, since S3 requests must be
+// signed for the bucket's region; unknown buckets go to fallback
+func NewBucketArchiveStorage(clients map[string]ArchiveStorage, fallback ArchiveStorage) ArchiveStorage {
+	return &bucketArchiveStorage{clients: clients, fallback: fallback}
+}
+
+func (s *bucketArchiveStorage) client(bucket string) ArchiveStorage {
+	if client, ok := s.clients[bucket]; ok {
+		return client
+	}
+	return s.fallback
+}
+
+// Archive archives the object with the client of its bucket
+func (s *bucketArchiveStorage) Archive(ctx context.Context, bucket, key string) error {
+	return s.client(bucket).Archive(ctx, bucket, key)
+}
+
+// Restore restores the object with the client of its bucket
+func (s *bucketArchiveStorage) Restore(ctx context.Context, bucket, key string, days int, tier string) error {
+	return s.client(bucket).Restore(ctx, bucket, key, days, tier)
+}
+
+// Status reads the object status with the client of its bucket
+func (s *bucketArchiveStorage) Status(ctx context.Context, bucket, key string) (*ArchiveObjectStatus, error) {
+	return s.client(bucket).Status(ctx, bucket, key)
+}
+
+// Unarchive unarchives the object with the client of its bucket
+func (s *bucketArchiveStorage) Unarchive(ctx context.Context, bucket, key string) error {
+	return s.client(bucket).Unarchive(ctx, bucket, key)
+}
+
+// Copy copies the object with the client of the destination bucket, which
+// CopyObject requests are signed for
+func (s *bucketArchiveStorage) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	return s.client(dstBucket).Copy(ctx, srcBucket, srcKey, dstBucket, dstKey)
+}
+
+// bucketMultipartStorage sends each call to the client of the bucket's region
+type bucketMultipartStorage struct {
+	clients  map[string]MultipartStorage
+	fallback MultipartStorage
+}
+
+var _ MultipartStorage = (*bucketMultipartStorage)(nil)
+
+// NewBucketMultipartStorage routes calls by bucket like
+// NewBucketArchiveStorage; presigned URLs are only valid in the bucket's
+// region too
+func NewBucketMultipartStorage(clients map[string]MultipartStorage, fallback MultipartStorage) MultipartStorage {
+	return &bucketMultipartStorage{clients: clients, fallback: fallback}
+}
+
+func (s *bucketMultipartStorage) client(bucket string) MultipartStorage {
+	if client, ok := s.clients[bucket]; ok {
+		return client
+	}
+	return s.fallback
+}
+
+// CreateMultipartUpload starts the upload with the client of its bucket
+func (s *bucketMultipartStorage) CreateMultipartUpload(ctx context.Context, bucket, key, contentType string) (string, error) {
+	return s.client(bucket).CreateMultipartUpload(ctx, bucket, key, contentType)
+}
+
+// UploadPart uploads the part with the client of its bucket
+func (s *bucketMultipartStorage) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, body []byte) (string, error) {
+	return s.client(bucket).UploadPart(ctx, bucket, key, uploadID, partNumber, body)
+}
+
+// PresignUploadPart presigns the part with the client of its bucket
+func (s *bucketMultipartStorage) PresignUploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, expires time.Duration) (string, error) {
+	return s.client(bucket).PresignUploadPart(ctx, bucket, key, uploadID, partNumber, expires)
+}
+
+// ListParts lists the parts with the client of their bucket
+func (s *bucketMultipartStorage) ListParts(ctx context.Context, bucket, key, uploadID string) ([]UploadedPart, error) {
+	return s.client(bucket).ListParts(ctx, bucket, key, uploadID)
+}
+
+// CompleteMultipartUpload completes the upload with the client of its bucket
+func (s *bucketMultipartStorage) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []UploadedPart) error {
+	return s.client(bucket).CompleteMultipartUpload(ctx, bucket, key, uploadID, parts)
+}
+
+// AbortMultipartUpload aborts the upload with the client of its bucket
+func (s *bucketMultipartStorage) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	return s.client(bucket).AbortMultipartUpload(ctx, bucket, key, uploadID)
+}
@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/residency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// regionStubClient answers with its region so the test can see the routing
+type regionStubClient struct {
+	BedrockClient
+	region string
+}
+
+func (c *regionStubClient) InvokeConversation(ctx context.Context, req *ConversationRequest) (*InvokeModelResponse, error) {
+	return &InvokeModelResponse{Content: c.region}, nil
+}
+
+func TestResidencyBedrockClient_Routing(t *testing.T) {
+	client, err := NewResidencyBedrockClient(map[residency.Residency]BedrockClient{
+		residency.US: &regionStubClient{region: "us-east-1"},
+	}, residency.US)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+		err      error
+	}{
+		{name: "no scope", ctx: context.Background(), expected: "us-east-1"},
+		{name: "configured residency", ctx: residency.WithScope(context.Background(), residency.Scope{Residency: residency.US, Pinned: true}), expected: "us-east-1"},
+		{name: "unconfigured residency falls back", ctx: residency.WithScope(context.Background(), residency.Scope{Residency: residency.EU}), expected: "us-east-1"},
+		{name: "pinned tenant never leaves its residency", ctx: residency.WithScope(context.Background(), residency.Scope{Residency: residency.EU, Pinned: true}), err: residency.ErrCrossRegion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.InvokeConversation(tt.ctx, &ConversationRequest{})
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.Content)
+		})
+	}
+
+	_, err = NewResidencyBedrockClient(map[residency.Residency]BedrockClient{}, residency.US)
+	assert.Error(t, err, "the default residency needs a client")
+}
+
+func TestBucketArchiveStorage_RoutesByBucket(t *testing.T) {
+	ctx := context.Background()
+	us := NewFakeArchiveStorage(0, logger.New("error", "test"))
+	eu := NewFakeArchiveStorage(0, logger.New("error", "test"))
+	storage := NewBucketArchiveStorage(map[string]ArchiveStorage{"videos-eu": eu}, us)
+
+	require.NoError(t, storage.Archive(ctx, "videos-eu", "a.mp4"))
+	require.NoError(t, storage.Archive(ctx, "videos-us", "b.mp4"))
+
+	status, err := eu.Status(ctx, "videos-eu", "a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, StorageClassGlacier, status.StorageClass)
+	status, err = us.Status(ctx, "videos-us", "b.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, StorageClassGlacier, status.StorageClass, "unknown buckets use the fallback")
+
+	status, err = us.Status(ctx, "videos-eu", "a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, StorageClassStandard, status.StorageClass, "the EU object never reached the US client")
+}
@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/slowlog"
+)
+
+// timedBedrockClient reports the calls of a regional client slower than the
+// Bedrock threshold
+type timedBedrockClient struct {
+	inner    BedrockClient
+	region   string
+	observer *slowlog.Observer
+}
+
+var _ BedrockClient = (*timedBedrockClient)(nil)
+
+// NewTimedBedrockClient wraps the client of region to report its slow calls.
+// Streaming calls are timed until the stream opens.
+func NewTimedBedrockClient(inner BedrockClient, region string, observer *slowlog.Observer) BedrockClient {
+	return &timedBedrockClient{inner: inner, region: region, observer: observer}
+}
+
+// InvokeModel invokes the model and reports the call when slow
+func (c *timedBedrockClient) InvokeModel(ctx context.Context, req *InvokeModelRequest) (*InvokeModelResponse, error) {
+	start := time.Now()
+	resp, err := c.inner.InvokeModel(ctx, req)
+	c.observe(ctx, "invoke_model", req.ModelID, start, err, resp)
+	return resp, err
+}
+
+// InvokeModelWithStreaming opens a model stream and reports the call when slow
+func (c *timedBedrockClient) InvokeModelWithStreaming(ctx context.Context, req *InvokeModelRequest) (*StreamingResponse, error) {
+	start := time.Now()
+	resp, err := c.inner.InvokeModelWithStreaming(ctx, req)
+	c.observe(ctx, "invoke_model_stream", req.ModelID, start, err, nil)
+	return resp, err
+}
+
+// InvokeConversation invokes the conversation and reports the call when slow
+func (c *timedBedrockClient) InvokeConversation(ctx context.Context, req *ConversationRequest) (*InvokeModelResponse, error) {
+	start := time.Now()
+	resp, err := c.inner.InvokeConversation(ctx, req)
+	c.observe(ctx, "invoke_conversation", string(req.Model), start, err, resp)
+	return resp, err
+}
+
+// InvokeConversationWithStreaming opens a conversation stream and reports the call when slow
+func (c *timedBedrockClient) InvokeConversationWithStreaming(ctx context.Context, req *ConversationRequest) (*StreamingResponse, error) {
+	start := time.Now()
+	resp, err := c.inner.InvokeConversationWithStreaming(ctx, req)
+	c.observe(ctx, "invoke_conversation_stream", string(req.Model), start, err, nil)
+	return resp, err
+}
+
+// Health checks the region
+func (c *timedBedrockClient) Health(ctx context.Context) error {
+	return c.inner.Health(ctx)
+}
+
+func (c *timedBedrockClient) observe(ctx context.Context, operation, model string, start time.Time, err error, resp *InvokeModelResponse) {
+	if model == "" {
+		model = "default"
+	}
+	fields := []interface{}{"operation", operation, "region", c.region}
+	if resp != nil {
+		fields = append(fields, "input_tokens", resp.InputTokens, "output_tokens", resp.OutputTokens)
+	}
+	c.observer.Observe(ctx, slowlog.Bedrock, model, start, err, fields...)
+}
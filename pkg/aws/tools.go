@@ -0,0 +1,202 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+)
+
+// FinishReasonToolUse is the stop reason returned when the model requests tool calls
+const FinishReasonToolUse = "tool_use"
+
+// DefaultMaxToolIterations bounds the number of model round-trips in a tool loop
+const DefaultMaxToolIterations = 5
+
+// ToolDefinition describes a tool the model may call
+type ToolDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// ToolChoice controls how the model selects tools ("auto", "any" or "tool")
+type ToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// ToolCall represents a tool invocation requested by the model
+type ToolCall struct {
+	ID    string                 `json:"id"`
+	Name  string                 `json:"name"`
+	Input map[string]interface{} `json:"input"`
+}
+
+// ToolHandler executes a tool call and returns its result as text
+type ToolHandler func(ctx context.Context, input map[string]interface{}) (string, error)
+
+// WithTools sets the tool definitions for the conversation request
+func (r *ConversationRequest) WithTools(tools ...ToolDefinition) *ConversationRequest {
+	r.Tools = tools
+	return r
+}
+
+// WithToolChoice sets how the model should choose among the available tools
+func (r *ConversationRequest) WithToolChoice(choice *ToolChoice) *ConversationRequest {
+	r.ToolChoice = choice
+	return r
+}
+
+// NewToolUseMessage creates an assistant message replaying the tool calls of a response
+func NewToolUseMessage(text string, calls []ToolCall) Message {
+	content := make([]MessageContent, 0, len(calls)+1)
+	if text != "" {
+		content = append(content, MessageContent{Type: ContentTypeText, Text: text})
+	}
+	for _, call := range calls {
+		content = append(content, MessageContent{
+			Type:  ContentTypeToolUse,
+			ID:    call.ID,
+			Name:  call.Name,
+			Input: call.Input,
+		})
+	}
+	return Message{Role: RoleAssistant, Content: content}
+}
+
+// NewToolResultContent creates a tool_result content block for a tool call
+func NewToolResultContent(toolUseID, result string, isError bool) MessageContent {
+	return MessageContent{
+		Type:      ContentTypeToolResult,
+		ToolUseID: toolUseID,
+		Content:   result,
+		IsError:   isError,
+	}
+}
+
+// RunToolLoop invokes the model and resolves its tool calls with the given
+// handlers until it produces a final answer. Tool failures are reported back
+// to the model as error results so it can recover. The request conversation
+// is extended with the full exchange, and token usage is summed across turns.
+func RunToolLoop(ctx context.Context, client BedrockClient, req *ConversationRequest, handlers map[string]ToolHandler, maxIterations int) (*InvokeModelResponse, error) {
+	if maxIterations <= 0 {
+		maxIterations = DefaultMaxToolIterations
+	}
+
+	var inputTokens, outputTokens int
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		resp, err := client.InvokeConversation(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		inputTokens += resp.InputTokens
+		outputTokens += resp.OutputTokens
+
+		if resp.FinishReason != FinishReasonToolUse || len(resp.ToolCalls) == 0 {
+			resp.InputTokens = inputTokens
+			resp.OutputTokens = outputTokens
+			resp.TokensUsed = inputTokens + outputTokens
+			req.Conversation.AddAssistantMessage(resp.Content)
+			return resp, nil
+		}
+
+		req.Conversation.AddMessage(NewToolUseMessage(resp.Content, resp.ToolCalls))
+
+		results := make([]MessageContent, 0, len(resp.ToolCalls))
+		for _, call := range resp.ToolCalls {
+			handler, ok := handlers[call.Name]
+			if !ok {
+				results = append(results, NewToolResultContent(call.ID, fmt.Sprintf("unknown tool: %s", call.Name), true))
+				continue
+			}
+
+			output, err := handler(ctx, call.Input)
+			if err != nil {
+				results = append(results, NewToolResultContent(call.ID, err.Error(), true))
+				continue
+			}
+			results = append(results, NewToolResultContent(call.ID, output, false))
+		}
+
+		req.Conversation.AddMessage(Message{Role: RoleUser, Content: results})
+	}
+
+	return nil, fmt.Errorf("tool loop did not finish after %d iterations", maxIterations)
+}
+
+// toClaudeMessages converts a conversation to the Claude Messages API format.
+// System messages are returned separately; plain text messages are sent as a
+// string while messages holding tool blocks are sent as content arrays.
+func toClaudeMessages(conversation Conversation) ([]map[string]interface{}, string) {
+	messages := make([]map[string]interface{}, 0, len(conversation.Messages))
+	var systemMessage string
+
+	for _, msg := range conversation.Messages {
+		if msg.Role == RoleSystem {
+			for _, content := range msg.Content {
+				if content.Type == ContentTypeText {
+					systemMessage += content.Text
+				}
+			}
+			continue
+		}
+
+		textOnly := true
+		for _, content := range msg.Content {
+			if content.Type != ContentTypeText {
+				textOnly = false
+				break
+			}
+		}
+
+		if textOnly {
+			var contentStr string
+			for _, content := range msg.Content {
+				contentStr += content.Text
+			}
+			messages = append(messages, map[string]interface{}{
+				"role":    string(msg.Role),
+				"content": contentStr,
+			})
+			continue
+		}
+
+		blocks := make([]map[string]interface{}, 0, len(msg.Content))
+		for _, content := range msg.Content {
+			switch content.Type {
+			case ContentTypeText:
+				blocks = append(blocks, map[string]interface{}{
+					"type": ContentTypeText,
+					"text": content.Text,
+				})
+			case ContentTypeToolUse:
+				input := content.Input
+				if input == nil {
+					input = map[string]interface{}{}
+				}
+				blocks = append(blocks, map[string]interface{}{
+					"type":  ContentTypeToolUse,
+					"id":    content.ID,
+					"name":  content.Name,
+					"input": input,
+				})
+			case ContentTypeToolResult:
+				block := map[string]interface{}{
+					"type":        ContentTypeToolResult,
+					"tool_use_id": content.ToolUseID,
+					"content":     content.Content,
+				}
+				if content.IsError {
+					block["is_error"] = true
+				}
+				blocks = append(blocks, block)
+			}
+		}
+
+		messages = append(messages, map[string]interface{}{
+			"role":    string(msg.Role),
+			"content": blocks,
+		})
+	}
+
+	return messages, systemMessage
+}
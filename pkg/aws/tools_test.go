@@ -0,0 +1,138 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedClient returns canned responses in order and records the requests it saw
+type scriptedClient struct {
+	responses []*InvokeModelResponse
+	calls     int
+}
+
+func (c *scriptedClient) InvokeModel(ctx context.Context, req *InvokeModelRequest) (*InvokeModelResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *scriptedClient) InvokeModelWithStreaming(ctx context.Context, req *InvokeModelRequest) (*StreamingResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *scriptedClient) InvokeConversation(ctx context.Context, req *ConversationRequest) (*InvokeModelResponse, error) {
+	resp := c.responses[c.calls]
+	c.calls++
+	return resp, nil
+}
+
+func (c *scriptedClient) InvokeConversationWithStreaming(ctx context.Context, req *ConversationRequest) (*StreamingResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *scriptedClient) Health(ctx context.Context) error {
+	return nil
+}
+
+func TestRunToolLoop(t *testing.T) {
+	client := &scriptedClient{
+		responses: []*InvokeModelResponse{
+			{
+				FinishReason: FinishReasonToolUse,
+				ToolCalls:    []ToolCall{{ID: "toolu_1", Name: "get_trends", Input: map[string]interface{}{"platform": "tiktok"}}},
+				InputTokens:  10,
+				OutputTokens: 5,
+			},
+			{
+				Content:      "Cats are trending",
+				FinishReason: "end_turn",
+				InputTokens:  20,
+				OutputTokens: 7,
+			},
+		},
+	}
+
+	var received map[string]interface{}
+	handlers := map[string]ToolHandler{
+		"get_trends": func(ctx context.Context, input map[string]interface{}) (string, error) {
+			received = input
+			return `["cats"]`, nil
+		},
+	}
+
+	req := NewConversationRequest(ModelClaude4Sonnet, NewConversation(NewUserMessage("What is trending?"))).
+		WithTools(ToolDefinition{Name: "get_trends", Description: "Trending topics", InputSchema: map[string]interface{}{"type": "object"}})
+
+	resp, err := RunToolLoop(context.Background(), client, req, handlers, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Cats are trending", resp.Content)
+	assert.Equal(t, 42, resp.TokensUsed)
+	assert.Equal(t, "tiktok", received["platform"])
+
+	// user, assistant tool_use, user tool_result, assistant answer
+	require.Len(t, req.Conversation.Messages, 4)
+	result := req.Conversation.Messages[2].Content[0]
+	assert.Equal(t, ContentTypeToolResult, result.Type)
+	assert.Equal(t, "toolu_1", result.ToolUseID)
+	assert.False(t, result.IsError)
+}
+
+func TestRunToolLoop_UnknownToolAndIterationLimit(t *testing.T) {
+	toolUse := &InvokeModelResponse{
+		FinishReason: FinishReasonToolUse,
+		ToolCalls:    []ToolCall{{ID: "toolu_1", Name: "missing"}},
+	}
+	client := &scriptedClient{responses: []*InvokeModelResponse{toolUse, toolUse}}
+
+	req := NewConversationRequest(ModelClaude4Sonnet, NewConversation(NewUserMessage("Hi")))
+
+	_, err := RunToolLoop(context.Background(), client, req, nil, 2)
+	assert.Error(t, err)
+	assert.True(t, req.Conversation.Messages[2].Content[0].IsError)
+}
+
+func TestToClaudeMessages(t *testing.T) {
+	conversation := NewConversation(
+		NewSystemMessage("Be brief"),
+		NewUserMessage("Hello"),
+		NewToolUseMessage("", []ToolCall{{ID: "toolu_1", Name: "get_trends"}}),
+	)
+
+	messages, system := toClaudeMessages(conversation)
+
+	assert.Equal(t, "Be brief", system)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "Hello", messages[0]["content"])
+
+	blocks, ok := messages[1]["content"].([]map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, ContentTypeToolUse, blocks[0]["type"])
+	assert.Equal(t, map[string]interface{}{}, blocks[0]["input"])
+}
+
+func TestToClaudeMessages_ReplayedHistory(t *testing.T) {
+	// A chat conversation replays its stored system prompt as the first message
+	conversation := NewConversation(
+		NewTextMessage(RoleSystem, "Refine titles"),
+		NewTextMessage(RoleUser, "Suggest a title"),
+		NewTextMessage(RoleAssistant, "Go in a weekend"),
+		NewTextMessage(RoleUser, "Shorter please"),
+	)
+
+	messages, system := toClaudeMessages(conversation)
+
+	assert.Equal(t, "Refine titles", system)
+	require.Len(t, messages, 3)
+	for i, want := range []struct{ role, content string }{
+		{"user", "Suggest a title"},
+		{"assistant", "Go in a weekend"},
+		{"user", "Shorter please"},
+	} {
+		assert.Equal(t, want.role, messages[i]["role"])
+		assert.Equal(t, want.content, messages[i]["content"])
+	}
+}
@@ -0,0 +1,168 @@
+// Package callback signs the requests workers send back to the API, such as
+// the end of a video's processing, and verifies them on the API side. A
+// callback carries an HMAC-SHA256 of its timestamp, method, path and body
+// made with a secret shared by the API and its workers, so it needs no user
+// token and cannot be replayed against another video or long after.
+package callback
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TimestampHeader carries the Unix time the callback was signed at
+	TimestampHeader = "X-Callback-Timestamp"
+	// SignatureHeader carries "sha256=<hex HMAC>"
+	SignatureHeader = "X-Callback-Signature"
+
+	// Tolerance bounds the age of a signed callback, and the clock skew
+	// between the API and its workers
+	Tolerance = 5 * time.Minute
+	// MaxBodyBytes caps callback bodies
+	MaxBodyBytes = 64 << 10
+)
+
+var (
+	// ErrNotConfigured is returned when no callback secret is set
+	ErrNotConfigured = errors.New("callbacks are not configured")
+	// ErrInvalidSignature is returned when a callback signature does not verify
+	ErrInvalidSignature = errors.New("invalid callback signature")
+)
+
+// Sign returns the signature of a callback made at timestamp
+func Sign(secret string, timestamp time.Time, method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d\n%s\n%s\n", timestamp.Unix(), method, path)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a callback received at now
+func Verify(secret string, header http.Header, method, path string, body []byte, now time.Time) error {
+	if secret == "" {
+		return ErrNotConfigured
+	}
+	seconds, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	timestamp := time.Unix(seconds, 0)
+	if age := now.Sub(timestamp); age > Tolerance || age < -Tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(header.Get(SignatureHeader)), []byte(Sign(secret, timestamp, method, path, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ProcessingResult is what a worker reports of a video it processed
+type ProcessingResult struct {
+	Duration     int    `json:"duration" binding:"min=0"` // Seconds
+	Resolution   string `json:"resolution"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	S3Key        string `json:"s3_key" binding:"required"`
+	S3Bucket     string `json:"s3_bucket" binding:"required"`
+}
+
+// ProcessingProgress is how far a worker is in processing a video
+type ProcessingProgress struct {
+	Percent int `json:"percent" binding:"min=0,max=100"`
+}
+
+// ProcessingFailure is what a worker reports of a video it failed to process
+type ProcessingFailure struct {
+	Reason string `json:"reason"`
+}
+
+// ProcessingCompletePath is the route reporting a processed video, relative
+// to the API's base URL
+func ProcessingCompletePath(tenantID, videoID string) string {
+	return "/internal/callbacks/tenants/" + tenantID + "/videos/" + videoID + "/processing/complete"
+}
+
+// ProcessingProgressPath is the route reporting how far a video's processing is
+func ProcessingProgressPath(tenantID, videoID string) string {
+	return "/internal/callbacks/tenants/" + tenantID + "/videos/" + videoID + "/processing/progress"
+}
+
+// ProcessingFailedPath is the route reporting a video that failed to process
+func ProcessingFailedPath(tenantID, videoID string) string {
+	return "/internal/callbacks/tenants/" + tenantID + "/videos/" + videoID + "/processing/failed"
+}
+
+// clientTimeout bounds a callback, so a slow API cannot hold up a worker
+const clientTimeout = 10 * time.Second
+
+// Client sends signed callbacks to the API
+type Client struct {
+	baseURL string
+	secret  string
+	client  *http.Client
+	now     func() time.Time
+}
+
+// NewClient creates a client calling the API at baseURL
+func NewClient(baseURL, secret string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  secret,
+		client:  &http.Client{Timeout: clientTimeout},
+		now:     time.Now,
+	}
+}
+
+// ProcessingComplete reports that the video is processed and ready
+func (c *Client) ProcessingComplete(ctx context.Context, tenantID, videoID string, result ProcessingResult) error {
+	return c.post(ctx, ProcessingCompletePath(tenantID, videoID), result)
+}
+
+// ProcessingProgress reports the percent complete of the video's processing
+func (c *Client) ProcessingProgress(ctx context.Context, tenantID, videoID string, percent int) error {
+	return c.post(ctx, ProcessingProgressPath(tenantID, videoID), ProcessingProgress{Percent: percent})
+}
+
+// ProcessingFailed reports that the video could not be processed
+func (c *Client) ProcessingFailed(ctx context.Context, tenantID, videoID, reason string) error {
+	return c.post(ctx, ProcessingFailedPath(tenantID, videoID), ProcessingFailure{Reason: reason})
+}
+
+// post signs and sends the callback, failing on any non-2xx answer
+func (c *Client) post(ctx context.Context, path string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build callback request: %w", err)
+	}
+	now := c.now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(c.secret, now, http.MethodPost, path, body))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send callback: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback %s answered %d", path, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package callback
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	signedAt := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	body := []byte(`{"reason":"corrupt upload"}`)
+	path := ProcessingFailedPath("acme", "v-1")
+	header := http.Header{}
+	header.Set(TimestampHeader, strconv.FormatInt(signedAt.Unix(), 10))
+	header.Set(SignatureHeader, Sign("s3cret", signedAt, http.MethodPost, path, body))
+
+	assert.NoError(t, Verify("s3cret", header, http.MethodPost, path, body, signedAt.Add(time.Minute)))
+	assert.ErrorIs(t, Verify("", header, http.MethodPost, path, body, signedAt), ErrNotConfigured)
+	assert.ErrorIs(t, Verify("other", header, http.MethodPost, path, body, signedAt), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("s3cret", header, http.MethodPost, ProcessingFailedPath("acme", "v-2"), body, signedAt),
+		ErrInvalidSignature, "a signature is bound to its video")
+	assert.ErrorIs(t, Verify("s3cret", header, http.MethodPost, ProcessingCompletePath("acme", "v-1"), body, signedAt),
+		ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("s3cret", header, http.MethodPost, path, []byte(`{}`), signedAt), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("s3cret", header, http.MethodPost, path, body, signedAt.Add(Tolerance+time.Second)),
+		ErrInvalidSignature, "old callbacks cannot be replayed")
+
+	header.Del(TimestampHeader)
+	assert.ErrorIs(t, Verify("s3cret", header, http.MethodPost, path, body, signedAt), ErrInvalidSignature)
+}
+
+func TestClient_SignsCallbacks(t *testing.T) {
+	var verified error
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		verified = Verify("s3cret", r.Header, r.Method, r.URL.Path, body, time.Now())
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "s3cret")
+	require.NoError(t, client.ProcessingComplete(context.Background(), "acme", "v-1", ProcessingResult{Duration: 42, S3Key: "k", S3Bucket: "b"}))
+	assert.NoError(t, verified)
+
+	status = http.StatusNotFound
+	assert.Error(t, client.ProcessingFailed(context.Background(), "acme", "v-1", "corrupt upload"))
+	assert.NoError(t, verified)
+}
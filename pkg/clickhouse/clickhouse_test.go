@@ -0,0 +1,69 @@
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Query(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "SELECT platform, sum(views) AS views FROM video_stats WHERE tenant_id = {tenant:String} GROUP BY platform FORMAT JSONCompact", string(body))
+		assert.Equal(t, "acme", r.URL.Query().Get("param_tenant"))
+		assert.Equal(t, "analytics", r.URL.Query().Get("database"), "the DSN's settings are kept")
+		assert.Equal(t, "reader", r.Header.Get("X-ClickHouse-User"))
+		assert.Equal(t, "secret", r.Header.Get("X-ClickHouse-Key"))
+		_, _ = io.WriteString(w, `{"meta":[{"name":"platform","type":"String"},{"name":"views","type":"UInt64"}],"data":[["youtube",1200]],"rows":1}`)
+	}))
+	defer server.Close()
+
+	client, err := New("http://reader:secret@" + server.Listener.Addr().String() + "/?database=analytics")
+	require.NoError(t, err)
+	result, err := client.Query(context.Background(),
+		"SELECT platform, sum(views) AS views FROM video_stats WHERE tenant_id = {tenant:String} GROUP BY platform",
+		map[string]string{"tenant": "acme"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform", "views"}, result.Columns)
+	assert.Equal(t, [][]interface{}{{"youtube", json.Number("1200")}}, result.Rows)
+}
+
+func TestClient_InsertAndErrors(t *testing.T) {
+	var lines []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("query") != "INSERT INTO video_stats FORMAT JSONEachRow" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, "Code: 62. DB::Exception: Syntax error")
+			return
+		}
+		dec := json.NewDecoder(r.Body)
+		for dec.More() {
+			var line map[string]interface{}
+			require.NoError(t, dec.Decode(&line))
+			lines = append(lines, line)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(server.URL)
+	require.NoError(t, err)
+	rows := []interface{}{map[string]interface{}{"video_id": "v1"}, map[string]interface{}{"video_id": "v2"}}
+	require.NoError(t, client.Insert(context.Background(), "video_stats", rows))
+	assert.Len(t, lines, 2)
+
+	err = client.Exec(context.Background(), "CREATE TABL video_stats")
+	assert.ErrorContains(t, err, "ClickHouse answered 400: Code: 62")
+
+	_, err = New("clickhouse://localhost:9000")
+	assert.Error(t, err, "only the HTTP interface is supported")
+}
+
+func TestArray(t *testing.T) {
+	assert.Equal(t, `['youtube','it\'s','a\\b']`, Array([]string{"youtube", "it's", `a\b`}))
+}
@@ -6,10 +6,8 @@ import (
 	"time"
 
 	backoff "github.com/cenkalti/backoff/v4"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/mysql"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -26,6 +24,8 @@ func New(dsn string) (*DB, error) {
 	config := &gorm.Config{
 		Logger:  logger.Default.LogMode(logger.Info),
 		NowFunc: func() time.Time { return time.Now().UTC() },
+		// Cache prepared statements; dashboard and stats queries repeat the same SQL
+		PrepareStmt: true,
 	}
 
 	var gormDB *gorm.DB
@@ -43,6 +43,10 @@ func New(dsn string) (*DB, error) {
 		return nil, fmt.Errorf("failed to enable tracing: %w", err)
 	}
 
+	if err := gormDB.Use(tenancy.NewPlugin()); err != nil {
+		return nil, fmt.Errorf("failed to enable tenancy guard: %w", err)
+	}
+
 	sqlDB, err := gormDB.DB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
@@ -70,23 +74,103 @@ func (db *DB) Close() error {
 	return sqlDB.Close()
 }
 
-// AutoMigrate runs GORM auto-migrations for all models
-func (db *DB) AutoMigrate() error {
-	err := db.DB.AutoMigrate(
+// Models returns every GORM model managed by auto-migrations. AutoMigrate
+// owns their tables; the SQL migrations creating some of them must stay
+// idempotent
+func Models() []interface{} {
+	return []interface{}{
 		&models.User{},
 		&models.Video{},
+		&models.VideoUpload{},
 		&models.VideoStats{},
 		&models.VideoStatsSnapshot{},
+		&models.VideoStatsSummary{},
+		&models.VideoStatsScore{},
+		&models.VideoStatsDevice{},
+		&models.VideoStatsLocation{},
 		&models.PublicationJob{},
 		&models.Tenant{},
 		&models.Workspace{},
-	)
+		&models.Conversation{},
+		&models.ConversationMessage{},
+		&models.Transcript{},
+		&models.VideoSummary{},
+		&models.RetentionPolicy{},
+		&models.JobLease{},
+		&models.AuditLog{},
+		&models.AIUsage{},
+		&models.MagicBrushBatch{},
+		&models.MagicBrushResult{},
+		&models.DebugCaptureSession{},
+		&models.DebugCapture{},
+		&models.QuarantinedWebhook{},
+		&models.WebhookSubscription{},
+		&models.StatsBackfill{},
+		&models.StatsCompaction{},
+		&models.StatsSyncCheckpoint{},
+		&models.PlatformQuotaUsage{},
+		&models.Watermark{},
+		&models.VideoRendition{},
+		&models.Asset{},
+		&models.AssetDefaults{},
+		&models.AudioTrack{},
+		&models.BrandProfile{},
+		&models.ThumbnailVariant{},
+		&models.VideoTransfer{},
+		&models.AlertRule{},
+		&models.AlertFiring{},
+		&models.SmartList{},
+		&models.Campaign{},
+		&models.CampaignEvent{},
+		&models.AnalyticsReader{},
+		&models.BlackoutWindow{},
+		&models.Series{},
+		&models.EncodingPreset{},
+		&models.RetentionCurve{},
+		&models.UserPreferences{},
+		&models.UserNotification{},
+		&models.LoginAttempt{},
+		&models.LoginChallenge{},
+		&models.Job{},
+	}
+}
+
+// AutoMigrate runs GORM auto-migrations for all models
+func (db *DB) AutoMigrate() error {
+	if err := db.normalizeJSONColumns(); err != nil {
+		return fmt.Errorf("failed to normalize JSON columns: %w", err)
+	}
+
+	err := db.DB.WithContext(tenancy.WithAllTenants(context.Background())).AutoMigrate(Models()...)
 	if err != nil {
 		return fmt.Errorf("failed to run auto-migrations: %w", err)
 	}
 	return nil
 }
 
+// normalizeJSONColumns rewrites legacy video tags and publication job configs
+// that are empty or not valid JSON, so they can be decoded by the GORM JSON
+// serializer and the config column can be converted to the JSON type
+func (db *DB) normalizeJSONColumns() error {
+	migrator := db.DB.Migrator()
+
+	if migrator.HasTable(&models.Video{}) {
+		err := db.DB.Exec("UPDATE videos SET tags = JSON_ARRAY() WHERE tags IS NULL OR JSON_TYPE(tags) <> 'ARRAY'").Error
+		if err != nil {
+			return fmt.Errorf("videos.tags: %w", err)
+		}
+	}
+
+	if migrator.HasTable(&models.PublicationJob{}) {
+		err := db.DB.Exec("UPDATE publication_jobs SET config = '{}' WHERE config IS NULL OR config = '' OR JSON_VALID(config) = 0").Error
+		if err != nil {
+			return fmt.Errorf("publication_jobs.config: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // Transaction executes a function within a database transaction
 func (db *DB) Transaction(fn func(*gorm.DB) error) error {
 	return db.DB.Transaction(fn)
@@ -120,9 +204,9 @@ func NewRepository(db *gorm.DB) *Repository {
 	return &Repository{db: db}
 }
 
-// WithTenant adds tenant filtering to queries
+// WithTenant scopes queries to a tenant through the tenancy plugin
 func (r *Repository) WithTenant(tenantID string) *gorm.DB {
-	return r.db.Where("tenant_id = ?", tenantID)
+	return r.db.WithContext(tenancy.WithTenant(r.db.Statement.Context, tenantID))
 }
 
 // WithSoftDelete includes soft-deleted records in queries
@@ -143,18 +227,3 @@ func (r *Repository) Paginate(limit, offset int) *gorm.DB {
 	}
 	return r.db.Limit(limit).Offset(offset)
 }
-
-// RunMigrations applies database migrations from the specified directory.
-func RunMigrations(dsn string) error {
-	m, err := migrate.New(
-		"file://db/migrations",
-		"mysql://"+dsn,
-	)
-	if err != nil {
-		return err
-	}
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return err
-	}
-	return nil
-}
@@ -1,12 +1,17 @@
 package db
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
 )
 
 func TestNew(t *testing.T) {
@@ -25,3 +30,71 @@ func TestNew(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestModels_IDsArePrimaryKeys(t *testing.T) {
+	// Every entity ID is a 36-character UUIDv7 string enforced unique by the primary key
+	for _, model := range Models() {
+		s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		require.NoError(t, err)
+
+		require.Len(t, s.PrimaryFields, 1, "%s must have a single-column primary key", s.Name)
+		pk := s.PrioritizedPrimaryField
+		assert.Equal(t, "ID", pk.Name, "%s primary key", s.Name)
+		assert.Equal(t, "varchar(36)", string(pk.DataType), "%s primary key type", s.Name)
+		assert.False(t, pk.AutoIncrement, "%s primary key must not auto-increment", s.Name)
+	}
+}
+
+func TestModels_StatsIndexes(t *testing.T) {
+	indexColumns := func(model interface{}, name string) []string {
+		s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		require.NoError(t, err)
+		idx := s.LookIndex(name)
+		require.NotNil(t, idx, "%s index on %s", name, s.Table)
+		var columns []string
+		for _, field := range idx.Fields {
+			columns = append(columns, field.DBName)
+		}
+		return columns
+	}
+
+	// Per-video totals are answered from the index without reading rows
+	assert.Equal(t,
+		[]string{"tenant_id", "video_id", "deleted_at", "views", "likes", "comments", "shares", "revenue", "platform"},
+		indexColumns(&models.VideoStats{}, "idx_stats_totals"))
+	// Snapshot history is read newest first per stats row
+	assert.Equal(t, []string{"stats_id", "created_at"}, indexColumns(&models.VideoStatsSnapshot{}, "idx_stats_created"))
+}
+
+func TestNormalizeJSONColumns(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	for _, table := range []string{"videos", "publication_jobs"} {
+		mock.ExpectQuery("SELECT DATABASE()").
+			WillReturnRows(sqlmock.NewRows([]string{"DATABASE()"}).AddRow("mysteryfactory"))
+		mock.ExpectQuery("SELECT SCHEMA_NAME from Information_schema.SCHEMATA").
+			WillReturnRows(sqlmock.NewRows([]string{"SCHEMA_NAME"}).AddRow("mysteryfactory"))
+		mock.ExpectQuery("SELECT count\\(\\*\\) FROM information_schema.tables").
+			WithArgs("mysteryfactory", table, "BASE TABLE").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		if table == "videos" {
+			mock.ExpectExec("UPDATE videos SET tags = JSON_ARRAY\\(\\)").
+				WillReturnResult(sqlmock.NewResult(0, 2))
+		} else {
+			mock.ExpectExec("UPDATE publication_jobs SET config = '\\{\\}'").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+	}
+
+	db := &DB{DB: gormDB}
+	require.NoError(t, db.normalizeJSONColumns())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
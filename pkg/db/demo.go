@@ -0,0 +1,321 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
+)
+
+// DemoPassword is the password of every demo user
+const DemoPassword = "demo1234"
+
+// DemoOptions configures the demo data set
+type DemoOptions struct {
+	TenantID    string
+	Videos      int
+	HistoryDays int   // Days of stats snapshots per platform
+	Seed        int64 // Seed for generated stats, so every run produces the same numbers
+}
+
+// DemoSummary reports what SeedDemo created
+type DemoSummary struct {
+	TenantID     string
+	Users        []string // Emails of the demo users
+	Videos       int
+	Stats        int
+	Snapshots    int
+	Publications int
+}
+
+// demoUser describes a demo account
+type demoUser struct {
+	email     string
+	firstName string
+	lastName  string
+	role      models.UserRole
+}
+
+var demoUsers = []demoUser{
+	{"admin@demo.local", "Ada", "Admin", models.RoleAdmin},
+	{"editor@demo.local", "Eddie", "Editor", models.RoleEditor},
+	{"publisher@demo.local", "Pat", "Publisher", models.RolePublisher},
+	{"viewer@demo.local", "Vic", "Viewer", models.RoleViewer},
+}
+
+var demoTopics = []struct {
+	title string
+	tags  []string
+}{
+	{"The Lighthouse Keeper Who Vanished", []string{"mystery", "unsolved", "history"}},
+	{"Five Ciphers Nobody Has Cracked", []string{"cipher", "puzzle", "unsolved"}},
+	{"The Hotel Room That Does Not Exist", []string{"urban-legend", "mystery"}},
+	{"Inside the Dyatlov Pass Incident", []string{"history", "investigation"}},
+	{"The Radio Station That Never Stops", []string{"radio", "mystery", "cold-war"}},
+	{"A Town Erased From Every Map", []string{"history", "maps"}},
+	{"The Painting That Changes at Night", []string{"art", "urban-legend"}},
+	{"Voices on the Abandoned Subway Line", []string{"urban-legend", "city"}},
+}
+
+var demoPlatforms = []string{"youtube", "tiktok", "instagram"}
+
+// SeedDemo creates a demo tenant with users, a workspace, videos, stats
+// history and publication jobs for local development. Users that already
+// exist are kept, and videos are only generated for a tenant without any.
+func SeedDemo(gdb *gorm.DB, opts DemoOptions) (*DemoSummary, error) {
+	if opts.TenantID == "" {
+		opts.TenantID = "demo"
+	}
+	gdb = gdb.WithContext(tenancy.WithTenant(context.Background(), opts.TenantID))
+	summary := &DemoSummary{TenantID: opts.TenantID}
+
+	var count int64
+	if err := gdb.Model(&models.Tenant{}).Where("id = ?", opts.TenantID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		tenant := &models.Tenant{ID: opts.TenantID, Name: "Demo Studio", Domain: opts.TenantID, Status: "active"}
+		if err := gdb.Create(tenant).Error; err != nil {
+			return nil, fmt.Errorf("failed to create demo tenant: %w", err)
+		}
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(DemoPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	var owner *models.User
+	for _, du := range demoUsers {
+		user := &models.User{}
+		err := gdb.Where("email = ?", du.email).First(user).Error
+		if err == gorm.ErrRecordNotFound {
+			user = &models.User{
+				ID:        id.New(),
+				TenantID:  opts.TenantID,
+				Email:     du.email,
+				Password:  string(hashed),
+				FirstName: du.firstName,
+				LastName:  du.lastName,
+				Role:      string(du.role),
+				Status:    string(models.StatusActive),
+			}
+			err = gdb.Create(user).Error
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to seed user %s: %w", du.email, err)
+		}
+		if owner == nil {
+			owner = user
+		}
+		summary.Users = append(summary.Users, du.email)
+	}
+
+	if err := gdb.Model(&models.Workspace{}).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		ws := &models.Workspace{ID: id.New(), TenantID: opts.TenantID, UserID: owner.ID, Name: "Demo Channel"}
+		if err := gdb.Create(ws).Error; err != nil {
+			return nil, fmt.Errorf("failed to create demo workspace: %w", err)
+		}
+	}
+
+	if err := gdb.Model(&models.Video{}).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return summary, nil
+	}
+
+	err = gdb.Transaction(func(tx *gorm.DB) error {
+		return seedDemoVideos(tx, owner, opts, summary)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// seedDemoVideos creates videos with per-platform stats, their snapshot
+// history and completed publication jobs
+func seedDemoVideos(tx *gorm.DB, owner *models.User, opts DemoOptions, summary *DemoSummary) error {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	now := time.Now().UTC().Truncate(time.Hour)
+
+	for i := 0; i < opts.Videos; i++ {
+		topic := demoTopics[i%len(demoTopics)]
+		title := topic.title
+		if i >= len(demoTopics) {
+			title = fmt.Sprintf("%s (Part %d)", topic.title, i/len(demoTopics)+1)
+		}
+
+		video := &models.Video{
+			ID:          id.New(),
+			TenantID:    opts.TenantID,
+			UserID:      owner.ID,
+			Title:       title,
+			Description: "Demo video generated for local development.",
+			FileName:    fmt.Sprintf("demo-%02d.mp4", i+1),
+			FileSize:    int64(50+rng.Intn(450)) * 1024 * 1024,
+			Duration:    30 + rng.Intn(570),
+			Format:      "mp4",
+			Resolution:  "1920x1080",
+			Status:      string(models.StatusReady),
+			Metadata:    "{}",
+			Tags:        topic.tags,
+		}
+		// The newest video is still being processed and has no stats yet
+		if i == opts.Videos-1 && opts.Videos > 1 {
+			video.Status = string(models.StatusProcessing)
+		}
+		if err := tx.Create(video).Error; err != nil {
+			return fmt.Errorf("failed to create demo video: %w", err)
+		}
+		summary.Videos++
+		if video.Status != string(models.StatusReady) {
+			continue
+		}
+
+		for _, platform := range demoPlatforms {
+			externalID := fmt.Sprintf("demo-%s-%02d", platform, i+1)
+			history := demoStatsHistory(rng, opts.HistoryDays, now)
+			latest := history[len(history)-1]
+
+			stats := &models.VideoStats{
+				ID:           id.New(),
+				TenantID:     opts.TenantID,
+				VideoID:      video.ID,
+				Platform:     platform,
+				ExternalID:   externalID,
+				Views:        latest.Views,
+				Likes:        latest.Likes,
+				Comments:     latest.Comments,
+				Shares:       latest.Shares,
+				Revenue:      latest.Revenue,
+				Impressions:  latest.Views * int64(3+rng.Intn(5)),
+				WatchTime:    latest.Views * int64(video.Duration) / 2,
+				AvgWatchTime: float64(video.Duration) / 2,
+				Engagement:   engagementRate(latest),
+				LastSyncAt:   now,
+			}
+			if err := tx.Create(stats).Error; err != nil {
+				return fmt.Errorf("failed to create demo stats: %w", err)
+			}
+			summary.Stats++
+
+			devices, locations := demoBreakdowns(rng, stats)
+			if err := tx.Create(&devices).Error; err != nil {
+				return fmt.Errorf("failed to create demo device views: %w", err)
+			}
+			if err := tx.Create(&locations).Error; err != nil {
+				return fmt.Errorf("failed to create demo country views: %w", err)
+			}
+
+			for _, snapshot := range history {
+				snapshot.ID = id.New()
+				snapshot.StatsID = stats.ID
+			}
+			if err := tx.Create(&history).Error; err != nil {
+				return fmt.Errorf("failed to create demo stats history: %w", err)
+			}
+			summary.Snapshots += len(history)
+
+			job := &models.PublicationJob{
+				ID:          id.New(),
+				TenantID:    opts.TenantID,
+				VideoID:     video.ID,
+				UserID:      owner.ID,
+				Platform:    platform,
+				Status:      string(models.PublicationCompleted),
+				Config:      map[string]interface{}{},
+				ExternalID:  externalID,
+				ExternalURL: fmt.Sprintf("https://example.com/%s/%s", platform, externalID),
+				MaxRetries:  3,
+			}
+			if err := tx.Create(job).Error; err != nil {
+				return fmt.Errorf("failed to create demo publication job: %w", err)
+			}
+			summary.Publications++
+		}
+	}
+	return nil
+}
+
+// demoStatsHistory generates one cumulative snapshot per day ending at now,
+// growing fast after publication and levelling off
+func demoStatsHistory(rng *rand.Rand, days int, now time.Time) []*models.VideoStatsSnapshot {
+	if days < 1 {
+		days = 1
+	}
+	history := make([]*models.VideoStatsSnapshot, 0, days)
+	peak := 500 + rng.Intn(20000)
+
+	var views, likes, comments, shares int64
+	var revenue float64
+	for day := 0; day < days; day++ {
+		daily := int64(peak/(day+1) + rng.Intn(peak/10+1))
+		views += daily
+		likes += daily * int64(2+rng.Intn(6)) / 100
+		comments += daily * int64(rng.Intn(2)+1) / 200
+		shares += daily * int64(rng.Intn(3)+1) / 300
+		revenue += float64(daily) * 0.002
+
+		history = append(history, &models.VideoStatsSnapshot{
+			Views:     views,
+			Likes:     likes,
+			Comments:  comments,
+			Shares:    shares,
+			Revenue:   float64(int64(revenue*100)) / 100,
+			CreatedAt: now.AddDate(0, 0, day-days+1),
+		})
+	}
+	return history
+}
+
+// demoCountries are the countries demo views come from
+var demoCountries = []string{"US", "GB", "FR", "DE", "CA", "BR", "IN", "AU"}
+
+// demoBreakdowns splits the views of stats over device types and countries,
+// with random weights
+func demoBreakdowns(rng *rand.Rand, stats *models.VideoStats) ([]*models.VideoStatsDevice, []*models.VideoStatsLocation) {
+	split := func(keys []string) map[string]int64 {
+		weights := make(map[string]float64, len(keys))
+		var total float64
+		for _, key := range keys {
+			weights[key] = rng.Float64()
+			total += weights[key]
+		}
+		for key := range weights {
+			weights[key] /= total
+		}
+		return models.SplitViews(stats.Views, weights)
+	}
+
+	deviceViews := split(models.DeviceTypes)
+	devices := make([]*models.VideoStatsDevice, 0, len(models.DeviceTypes))
+	for _, device := range models.DeviceTypes {
+		devices = append(devices, &models.VideoStatsDevice{ID: id.New(), TenantID: stats.TenantID, VideoID: stats.VideoID, Platform: stats.Platform, Device: device, Views: deviceViews[device]})
+	}
+	countryViews := split(demoCountries)
+	locations := make([]*models.VideoStatsLocation, 0, len(demoCountries))
+	for _, country := range demoCountries {
+		locations = append(locations, &models.VideoStatsLocation{ID: id.New(), TenantID: stats.TenantID, VideoID: stats.VideoID, Platform: stats.Platform, Country: country, Views: countryViews[country]})
+	}
+	return devices, locations
+}
+
+// engagementRate returns interactions per view as a fraction
+func engagementRate(s *models.VideoStatsSnapshot) float64 {
+	if s.Views == 0 {
+		return 0
+	}
+	return float64(s.Likes+s.Comments+s.Shares) / float64(s.Views)
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestDemoStatsHistory(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	history := demoStatsHistory(rand.New(rand.NewSource(1)), 30, now)
+	require.Len(t, history, 30)
+	assert.Equal(t, now.AddDate(0, 0, -29), history[0].CreatedAt)
+	assert.Equal(t, now, history[29].CreatedAt, "the last snapshot is today")
+
+	for i := 1; i < len(history); i++ {
+		prev, cur := history[i-1], history[i]
+		assert.GreaterOrEqual(t, cur.Views, prev.Views, "views are cumulative")
+		assert.GreaterOrEqual(t, cur.Likes, prev.Likes)
+		assert.GreaterOrEqual(t, cur.Revenue, prev.Revenue)
+		assert.True(t, cur.CreatedAt.After(prev.CreatedAt))
+	}
+	assert.Greater(t, history[29].Views, int64(0))
+
+	again := demoStatsHistory(rand.New(rand.NewSource(1)), 30, now)
+	assert.Equal(t, history, again, "the same seed produces the same history")
+}
+
+func TestDemoStatsHistoryHasAtLeastOneSnapshot(t *testing.T) {
+	history := demoStatsHistory(rand.New(rand.NewSource(1)), 0, time.Now())
+	assert.Len(t, history, 1)
+}
+
+func TestDemoBreakdowns(t *testing.T) {
+	stats := &models.VideoStats{TenantID: "tenant-1", VideoID: "video-1", Platform: "youtube", Views: 10000}
+	devices, locations := demoBreakdowns(rand.New(rand.NewSource(1)), stats)
+
+	require.Len(t, devices, len(models.DeviceTypes))
+	var views int64
+	for _, d := range devices {
+		assert.Equal(t, "video-1", d.VideoID)
+		views += d.Views
+	}
+	assert.InDelta(t, 10000, views, float64(len(devices)), "device views add up to the stats' views, give or take rounding")
+
+	require.Len(t, locations, len(demoCountries))
+	views = 0
+	for _, l := range locations {
+		views += l.Views
+	}
+	assert.InDelta(t, 10000, views, float64(len(locations)))
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// acquireLeaseSQL takes the lease when it is free, expired or already held by
+// holder, and extends it. MySQL applies the assignments in order, so the
+// expiry is only moved when holder is set by the first one. Expiry uses the
+// database clock, which all instances share.
+const acquireLeaseSQL = `INSERT INTO job_leases (id, holder, expires_at, updated_at)
+VALUES (?, ?, NOW(3) + INTERVAL ? MICROSECOND, NOW(3))
+ON DUPLICATE KEY UPDATE
+	holder = IF(expires_at <= NOW(3) OR holder = VALUES(holder), VALUES(holder), holder),
+	expires_at = IF(holder = VALUES(holder), VALUES(expires_at), expires_at),
+	updated_at = IF(holder = VALUES(holder), VALUES(updated_at), updated_at)`
+
+// AcquireLease takes or renews the lease on name for ttl and reports whether
+// holder now holds it
+func (db *DB) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	tx := db.DB.WithContext(ctx)
+	if err := tx.Exec(acquireLeaseSQL, name, holder, ttl.Microseconds()).Error; err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", name, err)
+	}
+
+	var current string
+	if err := tx.Raw("SELECT holder FROM job_leases WHERE id = ?", name).Row().Scan(&current); err != nil {
+		return false, fmt.Errorf("failed to read lease %s: %w", name, err)
+	}
+	return current == holder, nil
+}
+
+// ReleaseLease gives up the lease on name if holder holds it, so another
+// instance can take over without waiting for it to expire
+func (db *DB) ReleaseLease(ctx context.Context, name, holder string) error {
+	err := db.DB.WithContext(ctx).
+		Exec("UPDATE job_leases SET expires_at = NOW(3) WHERE id = ? AND holder = ?", name, holder).Error
+	if err != nil {
+		return fmt.Errorf("failed to release lease %s: %w", name, err)
+	}
+	return nil
+}
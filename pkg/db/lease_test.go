@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireLease(t *testing.T) {
+	db, mock := newMockDB(t)
+
+	mock.ExpectExec("INSERT INTO job_leases").
+		WithArgs("stats-sync", "pod-a", int64(30*time.Minute/time.Microsecond)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT holder FROM job_leases WHERE id = ?")).
+		WithArgs("stats-sync").
+		WillReturnRows(sqlmock.NewRows([]string{"holder"}).AddRow("pod-a"))
+
+	held, err := db.AcquireLease(context.Background(), "stats-sync", "pod-a", 30*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	mock.ExpectExec("INSERT INTO job_leases").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT holder FROM job_leases WHERE id = ?")).
+		WillReturnRows(sqlmock.NewRows([]string{"holder"}).AddRow("pod-a"))
+
+	held, err = db.AcquireLease(context.Background(), "stats-sync", "pod-b", 30*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held, "a lease held by another instance is not taken")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcquireLease_Error(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectExec("INSERT INTO job_leases").WillReturnError(errors.New("connection refused"))
+
+	held, err := db.AcquireLease(context.Background(), "stats-sync", "pod-a", time.Minute)
+	assert.Error(t, err)
+	assert.False(t, held)
+}
+
+func TestReleaseLease(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE job_leases SET expires_at = NOW(3) WHERE id = ? AND holder = ?")).
+		WithArgs("stats-sync", "pod-a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, db.ReleaseLease(context.Background(), "stats-sync", "pod-a"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
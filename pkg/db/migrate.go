@@ -0,0 +1,280 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jibe0123/mysteryfactory/db/migrations"
+)
+
+// ErrDestructiveMigration is returned when a migration about to run drops or
+// deletes data and destructive migrations are not allowed
+var ErrDestructiveMigration = errors.New("destructive migration refused")
+
+var (
+	// destructiveStatement matches SQL statements that lose data when applied
+	destructiveStatement = regexp.MustCompile(`(?i)\b(DROP\s+(TABLE|DATABASE|SCHEMA|COLUMN|INDEX)|TRUNCATE|DELETE\s+FROM|ALTER\s+TABLE\s+\S+\s+DROP)\b`)
+	// sqlLineComment matches -- comments so commented-out statements are ignored
+	sqlLineComment = regexp.MustCompile(`--[^\n]*`)
+)
+
+// MigrationStatus describes one embedded migration
+type MigrationStatus struct {
+	Version     uint
+	Name        string
+	Applied     bool
+	Destructive bool // The up migration drops or deletes data
+}
+
+// Migrator runs the SQL migrations embedded in the binary
+type Migrator struct {
+	migrate *migrate.Migrate
+	source  source.Driver
+
+	// AllowDestructive lets Up and Down run migrations that drop or delete data
+	AllowDestructive bool
+}
+
+// NewMigrator creates a migrator applying the embedded migrations to dsn
+func NewMigrator(dsn string) (*Migrator, error) {
+	src, err := newMigrationSource(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, "mysql://"+dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize migrations: %w", err)
+	}
+	return &Migrator{migrate: m, source: src}, nil
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up() error {
+	current, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+	pending, err := pendingVersions(m.source, current)
+	if err != nil {
+		return err
+	}
+	if err := m.checkDestructive(pending, false); err != nil {
+		return err
+	}
+	return ignoreNoChange(m.migrate.Up())
+}
+
+// Down reverts the last steps applied migrations, or all of them when steps <= 0
+func (m *Migrator) Down(steps int) error {
+	current, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+	applied, err := appliedVersions(m.source, current)
+	if err != nil {
+		return err
+	}
+	if steps > 0 && steps < len(applied) {
+		applied = applied[len(applied)-steps:]
+	}
+	if err := m.checkDestructive(applied, true); err != nil {
+		return err
+	}
+	if steps <= 0 {
+		return ignoreNoChange(m.migrate.Down())
+	}
+	return ignoreNoChange(m.migrate.Steps(-steps))
+}
+
+// Force sets the recorded version and clears the dirty flag without running
+// any migration, to recover from a migration that failed halfway
+func (m *Migrator) Force(version int) error {
+	return m.migrate.Force(version)
+}
+
+// Version returns the applied version and whether the last migration failed
+// halfway; version is 0 when no migration has been applied
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Status lists the embedded migrations and whether each has been applied
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	current, _, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+	return migrationStatus(m.source, current)
+}
+
+// Close releases the source and database connections
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.migrate.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// RunMigrations applies the embedded migrations, refusing destructive ones
+// unless allowDestructive is set
+func RunMigrations(dsn string, allowDestructive bool) error {
+	m, err := NewMigrator(dsn)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	m.AllowDestructive = allowDestructive
+	return m.Up()
+}
+
+// IsDestructive reports whether a migration drops or deletes data
+func IsDestructive(sql string) bool {
+	return destructiveStatement.MatchString(sqlLineComment.ReplaceAllString(sql, ""))
+}
+
+// checkDestructive refuses versions whose up or down migration is destructive
+func (m *Migrator) checkDestructive(versions []uint, down bool) error {
+	if m.AllowDestructive {
+		return nil
+	}
+	for _, version := range versions {
+		destructive, err := isDestructiveMigration(m.source, version, down)
+		if err != nil {
+			return err
+		}
+		if destructive {
+			return fmt.Errorf("%w: version %d", ErrDestructiveMigration, version)
+		}
+	}
+	return nil
+}
+
+// newMigrationSource opens a migration source over fsys
+func newMigrationSource(fsys fs.FS) (source.Driver, error) {
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded migrations: %w", err)
+	}
+	return src, nil
+}
+
+// listVersions returns every migration version in src in ascending order
+func listVersions(src source.Driver) ([]uint, error) {
+	version, err := src.First()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []uint{version}
+	for {
+		version, err = src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+}
+
+// pendingVersions returns the versions after current
+func pendingVersions(src source.Driver, current uint) ([]uint, error) {
+	versions, err := listVersions(src)
+	if err != nil {
+		return nil, err
+	}
+	var pending []uint
+	for _, version := range versions {
+		if version > current {
+			pending = append(pending, version)
+		}
+	}
+	return pending, nil
+}
+
+// appliedVersions returns the versions up to and including current
+func appliedVersions(src source.Driver, current uint) ([]uint, error) {
+	versions, err := listVersions(src)
+	if err != nil {
+		return nil, err
+	}
+	var applied []uint
+	for _, version := range versions {
+		if version <= current {
+			applied = append(applied, version)
+		}
+	}
+	return applied, nil
+}
+
+// migrationStatus describes every migration in src relative to current
+func migrationStatus(src source.Driver, current uint) ([]MigrationStatus, error) {
+	versions, err := listVersions(src)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(versions))
+	for _, version := range versions {
+		body, name, err := src.ReadUp(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %d: %w", version, err)
+		}
+		sql, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %d: %w", version, err)
+		}
+		statuses = append(statuses, MigrationStatus{
+			Version:     version,
+			Name:        name,
+			Applied:     version <= current,
+			Destructive: IsDestructive(string(sql)),
+		})
+	}
+	return statuses, nil
+}
+
+// isDestructiveMigration reports whether the up or down migration of version
+// drops or deletes data; a missing down migration cannot be destructive
+func isDestructiveMigration(src source.Driver, version uint, down bool) (bool, error) {
+	read := src.ReadUp
+	if down {
+		read = src.ReadDown
+	}
+	body, _, err := read(version)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read migration %d: %w", version, err)
+	}
+	defer body.Close()
+
+	sql, err := io.ReadAll(body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read migration %d: %w", version, err)
+	}
+	return IsDestructive(string(sql)), nil
+}
+
+// ignoreNoChange treats an up-to-date schema as success
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
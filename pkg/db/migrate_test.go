@@ -0,0 +1,172 @@
+package db
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/schema"
+
+	"github.com/jibe0123/mysteryfactory/db/migrations"
+)
+
+func testMigrationSource(t *testing.T) *Migrator {
+	src, err := newMigrationSource(fstest.MapFS{
+		"001_create_users.up.sql":     {Data: []byte("CREATE TABLE users (id VARCHAR(36));")},
+		"001_create_users.down.sql":   {Data: []byte("DROP TABLE users;")},
+		"002_add_nickname.up.sql":     {Data: []byte("ALTER TABLE users ADD COLUMN nickname VARCHAR(64);")},
+		"002_add_nickname.down.sql":   {Data: []byte("ALTER TABLE users DROP COLUMN nickname;")},
+		"003_purge_guests.up.sql":     {Data: []byte("DELETE FROM users WHERE role = 'guest';")},
+		"004_index_nickname.up.sql":   {Data: []byte("-- DROP TABLE users; kept for reference\nCREATE INDEX idx_nickname ON users (nickname);")},
+		"004_index_nickname.down.sql": {Data: []byte("DROP INDEX idx_nickname ON users;")},
+	})
+	require.NoError(t, err)
+	return &Migrator{source: src}
+}
+
+func TestEmbeddedMigrationsArePaired(t *testing.T) {
+	src, err := newMigrationSource(migrations.FS)
+	require.NoError(t, err)
+
+	versions, err := listVersions(src)
+	require.NoError(t, err)
+	require.NotEmpty(t, versions)
+
+	for _, version := range versions {
+		_, _, err := src.ReadUp(version)
+		assert.NoError(t, err, "version %d has an up migration", version)
+		_, _, err = src.ReadDown(version)
+		assert.NoError(t, err, "version %d has a down migration", version)
+	}
+}
+
+// createTable matches the CREATE TABLE statements of a migration with the
+// table name and column definitions
+var createTable = regexp.MustCompile(`(?is)CREATE\s+TABLE\s+(IF\s+NOT\s+EXISTS\s+)?(\w+)\s*\((.*?)\n\);`)
+
+// addColumn matches the statements adding a column
+var addColumn = regexp.MustCompile(`(?i)\bADD\s+COLUMN\b`)
+
+// baselineVersion is the migration applied before AutoMigrate owned the model
+// tables. Applied migrations are never edited, so it keeps its plain CREATE TABLE.
+const baselineVersion = 1
+
+func TestEmbeddedMigrationsMatchModels(t *testing.T) {
+	// AutoMigrate owns the model tables and creates those no migration does, so
+	// migrations creating one must not fail once it has run, nor add columns it
+	// does not know
+	tables := make(map[string]*schema.Schema)
+	for _, model := range Models() {
+		s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		require.NoError(t, err)
+		tables[s.Table] = s
+	}
+
+	src, err := newMigrationSource(migrations.FS)
+	require.NoError(t, err)
+	versions, err := listVersions(src)
+	require.NoError(t, err)
+
+	for _, version := range versions {
+		body, name, err := src.ReadUp(version)
+		require.NoError(t, err)
+		sql, err := io.ReadAll(body)
+		body.Close()
+		require.NoError(t, err)
+
+		if addColumn.Match(sql) {
+			assert.Contains(t, strings.ToLower(string(sql)), "information_schema.columns", "%s adds columns only when missing", name)
+		}
+		for _, match := range createTable.FindAllStringSubmatch(string(sql), -1) {
+			table := match[2]
+			if version != baselineVersion {
+				assert.NotEmpty(t, match[1], "%s creates %s with IF NOT EXISTS", name, table)
+			}
+			s, ok := tables[table]
+			if !assert.True(t, ok, "%s creates %s, a table of no model", name, table) {
+				continue
+			}
+			for _, line := range strings.Split(match[3], "\n") {
+				fields := strings.Fields(line)
+				if len(fields) == 0 {
+					continue
+				}
+				column := strings.Trim(fields[0], "`")
+				switch strings.ToUpper(column) {
+				case "INDEX", "UNIQUE", "PRIMARY", "KEY":
+					continue
+				}
+				assert.NotNil(t, s.LookUpField(column), "%s: column %s.%s has no model field", name, table, column)
+			}
+		}
+	}
+}
+
+func TestIsDestructive(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"CREATE TABLE users (id VARCHAR(36));", false},
+		{"ALTER TABLE users ADD COLUMN nickname VARCHAR(64);", false},
+		{"UPDATE users SET status = 'active';", false},
+		{"CREATE TABLE dropped_items (id INT);", false},
+		{"DROP TABLE IF EXISTS users;", true},
+		{"drop table users;", true},
+		{"ALTER TABLE users DROP COLUMN nickname;", true},
+		{"ALTER TABLE users DROP nickname;", true},
+		{"TRUNCATE TABLE users;", true},
+		{"DELETE FROM users WHERE role = 'guest';", true},
+		{"-- DROP TABLE users;\nSELECT 1;", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsDestructive(tt.sql), tt.sql)
+	}
+}
+
+func TestMigrationStatus(t *testing.T) {
+	m := testMigrationSource(t)
+
+	statuses, err := migrationStatus(m.source, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []MigrationStatus{
+		{Version: 1, Name: "create_users", Applied: true},
+		{Version: 2, Name: "add_nickname", Applied: true},
+		{Version: 3, Name: "purge_guests", Destructive: true},
+		{Version: 4, Name: "index_nickname"},
+	}, statuses)
+}
+
+func TestPendingAndAppliedVersions(t *testing.T) {
+	m := testMigrationSource(t)
+
+	pending, err := pendingVersions(m.source, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{3, 4}, pending)
+
+	pending, err = pendingVersions(m.source, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1, 2, 3, 4}, pending)
+
+	applied, err := appliedVersions(m.source, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1, 2}, applied)
+}
+
+func TestCheckDestructive(t *testing.T) {
+	m := testMigrationSource(t)
+
+	assert.NoError(t, m.checkDestructive([]uint{1, 2, 4}, false), "additive up migrations run")
+	assert.ErrorIs(t, m.checkDestructive([]uint{3, 4}, false), ErrDestructiveMigration)
+	assert.ErrorIs(t, m.checkDestructive([]uint{2}, true), ErrDestructiveMigration)
+	assert.NoError(t, m.checkDestructive([]uint{3}, true), "a missing down migration drops nothing")
+
+	m.AllowDestructive = true
+	assert.NoError(t, m.checkDestructive([]uint{3, 4}, false))
+	assert.NoError(t, m.checkDestructive([]uint{1, 2}, true))
+}
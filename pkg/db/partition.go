@@ -0,0 +1,269 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxValuePartition catches rows beyond the last monthly partition, so an
+// insert never fails when maintenance has not run for a while
+const maxValuePartition = "pmax"
+
+// PartitionSpec describes a table kept range-partitioned by month
+type PartitionSpec struct {
+	Table string
+	// Column is the DATETIME column rows are partitioned on; MySQL requires it
+	// in the primary key, which becomes (id, Column)
+	Column string
+	// AheadMonths is how many future months always have their own partition
+	AheadMonths int
+	// RetentionMonths is how many whole months before the current one are
+	// kept; older partitions are dropped. 0 keeps every partition.
+	RetentionMonths int
+}
+
+// StatsSnapshotPartitions is the partitioning of the stats history table
+func StatsSnapshotPartitions(retentionMonths int) PartitionSpec {
+	return PartitionSpec{
+		Table:           "video_stats_snapshots",
+		Column:          "created_at",
+		AheadMonths:     3,
+		RetentionMonths: retentionMonths,
+	}
+}
+
+// PartitionReport lists what one maintenance run changed
+type PartitionReport struct {
+	Converted bool     `json:"converted"`
+	Created   []string `json:"created"`
+	Dropped   []string `json:"dropped"`
+}
+
+// partitionBound is one range partition; a zero Before is MAXVALUE
+type partitionBound struct {
+	Name   string
+	Before time.Time
+}
+
+// MaintainPartitions partitions the table by month on first run, then keeps
+// partitions created AheadMonths ahead of now and drops those past retention.
+// Dropping a partition deletes its rows without scanning them.
+func (db *DB) MaintainPartitions(ctx context.Context, spec PartitionSpec, now time.Time) (*PartitionReport, error) {
+	existing, err := db.partitions(ctx, spec.Table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read partitions of %s: %w", spec.Table, err)
+	}
+
+	report := &PartitionReport{}
+	if len(existing) == 0 {
+		oldest, err := db.oldestRow(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		months := plannedMonths(spec, nil, oldest, now)
+		if err := db.convert(ctx, spec, months); err != nil {
+			return nil, fmt.Errorf("failed to partition %s: %w", spec.Table, err)
+		}
+		report.Converted = true
+		report.Created = partitionNames(months)
+		existing = boundsFor(months)
+	} else if months := plannedMonths(spec, existing, time.Time{}, now); len(months) > 0 {
+		if err := db.addPartitions(ctx, spec, existing, months); err != nil {
+			return nil, fmt.Errorf("failed to add partitions to %s: %w", spec.Table, err)
+		}
+		report.Created = partitionNames(months)
+		existing = append(existing, boundsFor(months)...)
+	}
+
+	if drop := expiredPartitions(spec, existing, now); len(drop) > 0 {
+		stmt := fmt.Sprintf("ALTER TABLE %s DROP PARTITION %s", spec.Table, strings.Join(drop, ", "))
+		if err := db.DB.WithContext(ctx).Exec(stmt).Error; err != nil {
+			return nil, fmt.Errorf("failed to drop partitions of %s: %w", spec.Table, err)
+		}
+		report.Dropped = drop
+	}
+	return report, nil
+}
+
+// partitions lists the table's range partitions in order; none means the
+// table is not partitioned
+func (db *DB) partitions(ctx context.Context, table string) ([]partitionBound, error) {
+	rows, err := db.DB.WithContext(ctx).Raw(`SELECT PARTITION_NAME, PARTITION_DESCRIPTION FROM information_schema.PARTITIONS
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND PARTITION_NAME IS NOT NULL
+ORDER BY PARTITION_ORDINAL_POSITION`, table).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bounds []partitionBound
+	for rows.Next() {
+		var name string
+		var description sql.NullString
+		if err := rows.Scan(&name, &description); err != nil {
+			return nil, err
+		}
+		before, err := parsePartitionBound(description.String)
+		if err != nil {
+			return nil, fmt.Errorf("partition %s: %w", name, err)
+		}
+		bounds = append(bounds, partitionBound{Name: name, Before: before})
+	}
+	return bounds, rows.Err()
+}
+
+// oldestRow returns the smallest partitioning value, or zero for an empty table
+func (db *DB) oldestRow(ctx context.Context, spec PartitionSpec) (time.Time, error) {
+	var oldest sql.NullTime
+	err := db.DB.WithContext(ctx).Raw(fmt.Sprintf("SELECT MIN(%s) FROM %s", spec.Column, spec.Table)).Row().Scan(&oldest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read oldest row of %s: %w", spec.Table, err)
+	}
+	return oldest.Time, nil
+}
+
+// convert partitions an existing table. MySQL requires the partitioning
+// column in the primary key; IDs are still generated unique on their own.
+func (db *DB) convert(ctx context.Context, spec PartitionSpec, months []time.Time) error {
+	tx := db.DB.WithContext(ctx)
+	pk := fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY, ADD PRIMARY KEY (id, %s)", spec.Table, spec.Column)
+	if err := tx.Exec(pk).Error; err != nil {
+		return err
+	}
+	definitions := append(partitionDefinitions(months), maxValueDefinition())
+	stmt := fmt.Sprintf("ALTER TABLE %s PARTITION BY RANGE COLUMNS(%s) (%s)",
+		spec.Table, spec.Column, strings.Join(definitions, ", "))
+	return tx.Exec(stmt).Error
+}
+
+// addPartitions splits the new months out of the MAXVALUE partition, or
+// appends them when the table has none
+func (db *DB) addPartitions(ctx context.Context, spec PartitionSpec, existing []partitionBound, months []time.Time) error {
+	definitions := partitionDefinitions(months)
+	var stmt string
+	if hasMaxValue(existing) {
+		definitions = append(definitions, maxValueDefinition())
+		stmt = fmt.Sprintf("ALTER TABLE %s REORGANIZE PARTITION %s INTO (%s)",
+			spec.Table, maxValuePartition, strings.Join(definitions, ", "))
+	} else {
+		stmt = fmt.Sprintf("ALTER TABLE %s ADD PARTITION (%s)", spec.Table, strings.Join(definitions, ", "))
+	}
+	return db.DB.WithContext(ctx).Exec(stmt).Error
+}
+
+// plannedMonths returns the months that need a partition: every month from
+// the oldest row (or the current month) when the table is not partitioned
+// yet, otherwise the months after the last bounded partition, up to
+// AheadMonths after now
+func plannedMonths(spec PartitionSpec, existing []partitionBound, oldest, now time.Time) []time.Time {
+	current := monthStart(now)
+	last := current.AddDate(0, spec.AheadMonths, 0)
+
+	from := current
+	if len(existing) == 0 {
+		if !oldest.IsZero() && oldest.Before(current) {
+			from = monthStart(oldest)
+		}
+	} else {
+		var highest time.Time
+		for _, p := range existing {
+			if p.Before.After(highest) {
+				highest = p.Before
+			}
+		}
+		if !highest.IsZero() {
+			from = highest
+		}
+	}
+
+	var months []time.Time
+	for m := from; !m.After(last); m = m.AddDate(0, 1, 0) {
+		months = append(months, m)
+	}
+	return months
+}
+
+// expiredPartitions returns the bounded partitions whose rows are all older
+// than the retention period. The current month is always kept.
+func expiredPartitions(spec PartitionSpec, existing []partitionBound, now time.Time) []string {
+	if spec.RetentionMonths <= 0 {
+		return nil
+	}
+	cutoff := monthStart(now).AddDate(0, -spec.RetentionMonths, 0)
+
+	var drop []string
+	for _, p := range existing {
+		if !p.Before.IsZero() && !p.Before.After(cutoff) {
+			drop = append(drop, p.Name)
+		}
+	}
+	sort.Strings(drop)
+	return drop
+}
+
+// parsePartitionBound reads a RANGE COLUMNS description such as
+// '2026-11-01 00:00:00' or MAXVALUE
+func parsePartitionBound(description string) (time.Time, error) {
+	value := strings.Trim(description, "'")
+	if value == "" || strings.EqualFold(value, "MAXVALUE") {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unexpected partition bound %q", description)
+}
+
+func hasMaxValue(existing []partitionBound) bool {
+	for _, p := range existing {
+		if p.Before.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// partitionName names the partition holding month's rows, e.g. p202610
+func partitionName(month time.Time) string {
+	return "p" + month.Format("200601")
+}
+
+func partitionNames(months []time.Time) []string {
+	names := make([]string, len(months))
+	for i, m := range months {
+		names[i] = partitionName(m)
+	}
+	return names
+}
+
+func partitionDefinitions(months []time.Time) []string {
+	definitions := make([]string, len(months))
+	for i, m := range months {
+		definitions[i] = fmt.Sprintf("PARTITION %s VALUES LESS THAN ('%s')",
+			partitionName(m), m.AddDate(0, 1, 0).Format("2006-01-02"))
+	}
+	return definitions
+}
+
+func maxValueDefinition() string {
+	return fmt.Sprintf("PARTITION %s VALUES LESS THAN (MAXVALUE)", maxValuePartition)
+}
+
+func boundsFor(months []time.Time) []partitionBound {
+	bounds := make([]partitionBound, len(months))
+	for i, m := range months {
+		bounds[i] = partitionBound{Name: partitionName(m), Before: m.AddDate(0, 1, 0)}
+	}
+	return bounds
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestPlannedMonths(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	spec := StatsSnapshotPartitions(0)
+
+	months := plannedMonths(spec, nil, time.Time{}, now)
+	assert.Equal(t, []string{"p202610", "p202611", "p202612", "p202701"}, partitionNames(months), "an empty table starts at the current month")
+
+	months = plannedMonths(spec, nil, time.Date(2026, 8, 3, 0, 0, 0, 0, time.UTC), now)
+	assert.Equal(t, "p202608", partitionNames(months)[0], "existing rows get a partition from their month")
+
+	existing := []partitionBound{
+		{Name: "p202610", Before: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "p202611", Before: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: maxValuePartition},
+	}
+	months = plannedMonths(spec, existing, time.Time{}, now)
+	assert.Equal(t, []string{"p202612", "p202701"}, partitionNames(months))
+
+	assert.Empty(t, plannedMonths(spec, boundsFor(months), time.Time{}, now.AddDate(0, -1, 0)), "partitions already reach far enough")
+}
+
+func TestExpiredPartitions(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	existing := append(boundsFor([]time.Time{
+		time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}), partitionBound{Name: maxValuePartition})
+
+	assert.Nil(t, expiredPartitions(StatsSnapshotPartitions(0), existing, now), "0 keeps every partition")
+	// Rows newer than the retention period are never dropped: with 2 months,
+	// August stays since part of it is less than 2 months old
+	assert.Equal(t, []string{"p202607"}, expiredPartitions(StatsSnapshotPartitions(2), existing, now))
+	assert.Equal(t, []string{"p202607", "p202608"}, expiredPartitions(StatsSnapshotPartitions(1), existing, now))
+}
+
+func TestParsePartitionBound(t *testing.T) {
+	before, err := parsePartitionBound("'2026-11-01 00:00:00'")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), before)
+
+	before, err = parsePartitionBound("MAXVALUE")
+	require.NoError(t, err)
+	assert.True(t, before.IsZero())
+
+	_, err = parsePartitionBound("42")
+	assert.Error(t, err)
+}
+
+func newMockDB(t *testing.T) (*DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+	return &DB{DB: gormDB}, mock
+}
+
+func TestMaintainPartitions_ConvertsUnpartitionedTable(t *testing.T) {
+	db, mock := newMockDB(t)
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT PARTITION_NAME, PARTITION_DESCRIPTION FROM information_schema.PARTITIONS").
+		WithArgs("video_stats_snapshots").
+		WillReturnRows(sqlmock.NewRows([]string{"PARTITION_NAME", "PARTITION_DESCRIPTION"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT MIN(created_at) FROM video_stats_snapshots")).
+		WillReturnRows(sqlmock.NewRows([]string{"MIN(created_at)"}).AddRow(time.Date(2026, 9, 20, 0, 0, 0, 0, time.UTC)))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE video_stats_snapshots DROP PRIMARY KEY, ADD PRIMARY KEY (id, created_at)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE video_stats_snapshots PARTITION BY RANGE COLUMNS(created_at) (" +
+		"PARTITION p202609 VALUES LESS THAN ('2026-10-01'), PARTITION p202610 VALUES LESS THAN ('2026-11-01'), " +
+		"PARTITION p202611 VALUES LESS THAN ('2026-12-01'), PARTITION p202612 VALUES LESS THAN ('2027-01-01'), " +
+		"PARTITION p202701 VALUES LESS THAN ('2027-02-01'), PARTITION pmax VALUES LESS THAN (MAXVALUE))")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	report, err := db.MaintainPartitions(context.Background(), StatsSnapshotPartitions(0), now)
+	require.NoError(t, err)
+	assert.True(t, report.Converted)
+	assert.Len(t, report.Created, 5)
+	assert.Empty(t, report.Dropped)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMaintainPartitions_AddsAndDrops(t *testing.T) {
+	db, mock := newMockDB(t)
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT PARTITION_NAME, PARTITION_DESCRIPTION FROM information_schema.PARTITIONS").
+		WithArgs("video_stats_snapshots").
+		WillReturnRows(sqlmock.NewRows([]string{"PARTITION_NAME", "PARTITION_DESCRIPTION"}).
+			AddRow("p202608", "'2026-09-01 00:00:00'").
+			AddRow("p202609", "'2026-10-01 00:00:00'").
+			AddRow("p202610", "'2026-11-01 00:00:00'").
+			AddRow("p202611", "'2026-12-01 00:00:00'").
+			AddRow("p202612", "'2027-01-01 00:00:00'").
+			AddRow("pmax", "MAXVALUE"))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE video_stats_snapshots REORGANIZE PARTITION pmax INTO (" +
+		"PARTITION p202701 VALUES LESS THAN ('2027-02-01'), PARTITION pmax VALUES LESS THAN (MAXVALUE))")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE video_stats_snapshots DROP PARTITION p202608")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	report, err := db.MaintainPartitions(context.Background(), StatsSnapshotPartitions(1), now)
+	require.NoError(t, err)
+	assert.False(t, report.Converted)
+	assert.Equal(t, []string{"p202701"}, report.Created)
+	assert.Equal(t, []string{"p202608"}, report.Dropped)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
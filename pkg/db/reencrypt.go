@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/jibe0123/mysteryfactory/pkg/encryption"
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
+)
+
+// Reencrypt rewrites every encrypted column of every model with the active
+// data key of the installed keyring, including soft-deleted rows. Rows are
+// processed in primary key order, batchSize at a time; the number of rows
+// rewritten is returned per table.
+func (db *DB) Reencrypt(ctx context.Context, batchSize int) (map[string]int64, error) {
+	if encryption.CurrentKeyring() == nil {
+		return nil, fmt.Errorf("no encryption keyring configured")
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	tx := db.DB.Unscoped().WithContext(tenancy.WithAllTenants(ctx))
+	rewritten := make(map[string]int64)
+
+	for _, model := range Models() {
+		stmt := tx.Model(model).Statement
+		if err := stmt.Parse(model); err != nil {
+			return rewritten, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+
+		var columns []string
+		for _, field := range stmt.Schema.Fields {
+			if field.TagSettings["SERIALIZER"] == encryption.SerializerName {
+				columns = append(columns, field.DBName)
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+
+		table := stmt.Schema.Table
+		pk := stmt.Schema.PrioritizedPrimaryField
+		lastID := ""
+		for {
+			batch := reflect.New(reflect.SliceOf(reflect.TypeOf(model)))
+			err := tx.Where(pk.DBName+" > ?", lastID).Order(pk.DBName).Limit(batchSize).Find(batch.Interface()).Error
+			if err != nil {
+				return rewritten, fmt.Errorf("failed to load %s: %w", table, err)
+			}
+
+			rows := batch.Elem()
+			if rows.Len() == 0 {
+				break
+			}
+			for i := 0; i < rows.Len(); i++ {
+				record := rows.Index(i).Interface()
+				// Loading decrypted with any known key; writing encrypts with the active one
+				if err := tx.Model(record).Select(columns).UpdateColumns(record).Error; err != nil {
+					return rewritten, fmt.Errorf("failed to re-encrypt %s: %w", table, err)
+				}
+				rewritten[table]++
+			}
+
+			id, _ := pk.ValueOf(ctx, rows.Index(rows.Len()-1).Elem())
+			lastID = fmt.Sprint(id)
+		}
+	}
+
+	return rewritten, nil
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/pkg/encryption"
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
+)
+
+// activeKeyArg matches a value encrypted with the given key that decrypts to plaintext
+type activeKeyArg struct {
+	keyID     string
+	plaintext string
+}
+
+func (a activeKeyArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "enc:v1:"+a.keyID+":") {
+		return false
+	}
+	plaintext, err := encryption.CurrentKeyring().Decrypt(s)
+	return err == nil && plaintext == a.plaintext
+}
+
+func TestReencrypt(t *testing.T) {
+	ctx := context.Background()
+	provider, err := encryption.NewLocalProvider(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	wrapped := make(map[string][]byte)
+	for _, id := range []string{"old", "new"} {
+		_, blob, err := provider.GenerateDataKey(ctx)
+		require.NoError(t, err)
+		wrapped[id] = blob
+	}
+	oldKeyring, err := encryption.NewKeyring(ctx, provider, wrapped, "old")
+	require.NoError(t, err)
+	oldName, err := oldKeyring.Encrypt("Ada")
+	require.NoError(t, err)
+
+	keyring, err := encryption.NewKeyring(ctx, provider, wrapped, "new")
+	require.NoError(t, err)
+	encryption.SetKeyring(keyring)
+	t.Cleanup(func() { encryption.SetKeyring(nil) })
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gormDB.Use(tenancy.NewPlugin()))
+
+	// One user with an old-key name and a legacy plaintext name, no workspaces,
+	// debug captures or quarantined webhooks
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE id > \\? ORDER BY id LIMIT \\?").
+		WithArgs("", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "first_name", "last_name"}).
+			AddRow("user-1", "tenant-1", []byte(oldName), []byte("Lovelace")))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `users` SET `first_name`=\\?,`last_name`=\\? WHERE `id` = \\?").
+		WithArgs(activeKeyArg{"new", "Ada"}, activeKeyArg{"new", "Lovelace"}, "user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE id > \\? ORDER BY id LIMIT \\?").
+		WithArgs("user-1", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT \\* FROM `workspaces` WHERE id > \\? ORDER BY id LIMIT \\?").
+		WithArgs("", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT \\* FROM `debug_captures` WHERE id > \\? ORDER BY id LIMIT \\?").
+		WithArgs("", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT \\* FROM `quarantined_webhooks` WHERE id > \\? ORDER BY id LIMIT \\?").
+		WithArgs("", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	db := &DB{DB: gormDB}
+	rewritten, err := db.Reencrypt(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"users": 1}, rewritten)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
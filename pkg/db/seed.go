@@ -1,14 +1,16 @@
 package db
 
 import (
+	"context"
 	"time"
 
-	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
 	"github.com/jibe0123/mysteryfactory/internal/config"
 	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
 )
 
 // Seed inserts initial data if it does not already exist.
@@ -17,6 +19,7 @@ func Seed(gdb *gorm.DB, cfg *config.Config) error {
 	if tenantID == "" {
 		tenantID = "default"
 	}
+	gdb = gdb.WithContext(tenancy.WithTenant(context.Background(), tenantID))
 
 	var count int64
 	if err := gdb.Model(&models.Tenant{}).Where("id = ?", tenantID).Count(&count).Error; err != nil {
@@ -43,7 +46,7 @@ func Seed(gdb *gorm.DB, cfg *config.Config) error {
 	if count == 0 {
 		hashed, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
 		u := &models.User{
-			ID:        uuid.New().String(),
+			ID:        id.New(),
 			TenantID:  tenantID,
 			Email:     superEmail,
 			Password:  string(hashed),
@@ -58,7 +61,7 @@ func Seed(gdb *gorm.DB, cfg *config.Config) error {
 			return err
 		}
 		ws := &models.Workspace{
-			ID:        uuid.New().String(),
+			ID:        id.New(),
 			TenantID:  tenantID,
 			UserID:    u.ID,
 			Name:      "Default Workspace",
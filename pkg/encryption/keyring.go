@@ -0,0 +1,168 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ciphertextPrefix marks encrypted values: enc:v1:<key id>:<base64(nonce|ciphertext)>
+const ciphertextPrefix = "enc:v1:"
+
+var (
+	// ErrUnknownKey is returned when a value was encrypted with a data key missing from the keyring
+	ErrUnknownKey = errors.New("unknown encryption key")
+	// ErrMalformedCiphertext is returned when an encrypted value cannot be parsed
+	ErrMalformedCiphertext = errors.New("malformed ciphertext")
+)
+
+// Keyring holds the unwrapped data keys used to encrypt and decrypt column
+// values. New values are encrypted with the active key; older keys stay
+// available for decryption until every row has been re-encrypted.
+type Keyring struct {
+	activeID string
+	keys     map[string]cipher.AEAD
+}
+
+// NewKeyring unwraps the given data keys with the provider. wrapped maps key
+// IDs to data keys as returned by KeyProvider.GenerateDataKey.
+func NewKeyring(ctx context.Context, provider KeyProvider, wrapped map[string][]byte, activeID string) (*Keyring, error) {
+	if _, ok := wrapped[activeID]; !ok {
+		return nil, fmt.Errorf("active encryption key %q is not configured", activeID)
+	}
+
+	keys := make(map[string]cipher.AEAD, len(wrapped))
+	for id, blob := range wrapped {
+		key, err := provider.DecryptDataKey(ctx, blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unwrap data key %q: %w", id, err)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data key %q: %w", id, err)
+		}
+		keys[id] = aead
+	}
+
+	return &Keyring{activeID: activeID, keys: keys}, nil
+}
+
+// ActiveKeyID returns the ID of the key used for new values
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+// Encrypt encrypts a value with the active data key
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	aead := k.keys[k.activeID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// The key ID is authenticated so a value cannot be relabelled to another key
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.activeID))
+	return ciphertextPrefix + k.activeID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value; values without the ciphertext prefix are legacy
+// plaintext and are returned unchanged
+func (k *Keyring) Decrypt(value string) (string, error) {
+	keyID, sealed, ok, err := parseCiphertext(value)
+	if err != nil || !ok {
+		return value, err
+	}
+
+	aead, found := k.keys[keyID]
+	if !found {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", ErrMalformedCiphertext
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a stored value is plaintext or encrypted with
+// a key other than the active one
+func (k *Keyring) NeedsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+	keyID, _, ok, err := parseCiphertext(value)
+	return err != nil || !ok || keyID != k.activeID
+}
+
+// IsEncrypted reports whether a stored value carries the ciphertext prefix
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ciphertextPrefix)
+}
+
+// ParseDataKeys parses a comma-separated list of id:base64 wrapped data keys
+func ParseDataKeys(value string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, found := strings.Cut(entry, ":")
+		if !found || id == "" || encoded == "" {
+			return nil, fmt.Errorf("invalid data key entry %q (expected id:base64)", entry)
+		}
+		blob, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data key %q: %w", id, err)
+		}
+		if _, exists := keys[id]; exists {
+			return nil, fmt.Errorf("duplicate data key %q", id)
+		}
+		keys[id] = blob
+	}
+	return keys, nil
+}
+
+// FormatDataKey formats a wrapped data key as an id:base64 entry for ParseDataKeys
+func FormatDataKey(id string, wrapped []byte) string {
+	return id + ":" + base64.StdEncoding.EncodeToString(wrapped)
+}
+
+// parseCiphertext splits an encrypted value into key ID and sealed bytes,
+// reporting false for plaintext values
+func parseCiphertext(value string) (string, []byte, bool, error) {
+	if !IsEncrypted(value) {
+		return "", nil, false, nil
+	}
+	keyID, encoded, found := strings.Cut(strings.TrimPrefix(value, ciphertextPrefix), ":")
+	if !found || keyID == "" {
+		return "", nil, true, ErrMalformedCiphertext
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, true, fmt.Errorf("%w: %v", ErrMalformedCiphertext, err)
+	}
+	return keyID, sealed, true, nil
+}
+
+// newAEAD creates an AES-256-GCM cipher from a 32-byte key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,170 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(t *testing.T) KeyProvider {
+	provider, err := NewLocalProvider(bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+	return provider
+}
+
+// newTestKeyring creates a keyring with freshly generated keys, the last one active
+func newTestKeyring(t *testing.T, provider KeyProvider, ids ...string) (*Keyring, map[string][]byte) {
+	wrapped := make(map[string][]byte)
+	for _, id := range ids {
+		_, blob, err := provider.GenerateDataKey(context.Background())
+		require.NoError(t, err)
+		wrapped[id] = blob
+	}
+	keyring, err := NewKeyring(context.Background(), provider, wrapped, ids[len(ids)-1])
+	require.NoError(t, err)
+	return keyring, wrapped
+}
+
+func TestKeyring_RoundTrip(t *testing.T) {
+	keyring, _ := newTestKeyring(t, newTestProvider(t), "k1")
+
+	ciphertext, err := keyring.Encrypt("ya29.secret-token")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ciphertext, "enc:v1:k1:"))
+	assert.NotContains(t, ciphertext, "secret-token")
+
+	again, err := keyring.Encrypt("ya29.secret-token")
+	require.NoError(t, err)
+	assert.NotEqual(t, ciphertext, again, "every value gets a fresh nonce")
+
+	plaintext, err := keyring.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "ya29.secret-token", plaintext)
+}
+
+func TestKeyring_LegacyPlaintext(t *testing.T) {
+	keyring, _ := newTestKeyring(t, newTestProvider(t), "k1")
+
+	plaintext, err := keyring.Decrypt("legacy-token")
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-token", plaintext)
+	assert.True(t, keyring.NeedsRotation("legacy-token"))
+	assert.False(t, keyring.NeedsRotation(""))
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	provider := newTestProvider(t)
+	oldKeyring, wrapped := newTestKeyring(t, provider, "k1")
+	oldValue, err := oldKeyring.Encrypt("secret")
+	require.NoError(t, err)
+
+	_, blob, err := provider.GenerateDataKey(context.Background())
+	require.NoError(t, err)
+	wrapped["k2"] = blob
+	keyring, err := NewKeyring(context.Background(), provider, wrapped, "k2")
+	require.NoError(t, err)
+
+	// Values under the old key stay readable and are flagged for re-encryption
+	plaintext, err := keyring.Decrypt(oldValue)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plaintext)
+	assert.True(t, keyring.NeedsRotation(oldValue))
+
+	newValue, err := keyring.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(newValue, "enc:v1:k2:"))
+	assert.False(t, keyring.NeedsRotation(newValue))
+}
+
+func TestKeyring_Errors(t *testing.T) {
+	provider := newTestProvider(t)
+	keyring, wrapped := newTestKeyring(t, provider, "k1")
+	value, err := keyring.Encrypt("secret")
+	require.NoError(t, err)
+
+	other, _ := newTestKeyring(t, provider, "k2")
+	_, err = other.Decrypt(value)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// Relabelling the value to another key fails authentication
+	relabelled := strings.Replace(value, "enc:v1:k1:", "enc:v1:k2:", 1)
+	wrapped["k2"] = wrapped["k1"]
+	both, err := NewKeyring(context.Background(), provider, wrapped, "k1")
+	require.NoError(t, err)
+	_, err = both.Decrypt(relabelled)
+	assert.Error(t, err)
+
+	_, err = keyring.Decrypt("enc:v1:k1:not-base64!")
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+
+	_, err = NewKeyring(context.Background(), provider, wrapped, "missing")
+	assert.Error(t, err)
+}
+
+func TestParseDataKeys(t *testing.T) {
+	keys, err := ParseDataKeys(FormatDataKey("k1", []byte("blob-1")) + ", " + FormatDataKey("k2", []byte("blob-2")))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"k1": []byte("blob-1"), "k2": []byte("blob-2")}, keys)
+
+	keys, err = ParseDataKeys("")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	for _, invalid := range []string{"k1", "k1:", ":YmxvYg==", "k1:***", "k1:YQ==,k1:Yg=="} {
+		_, err := ParseDataKeys(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestLocalProvider(t *testing.T) {
+	_, err := NewLocalProvider([]byte("short"))
+	assert.Error(t, err)
+
+	provider := newTestProvider(t)
+	key, wrapped, err := provider.GenerateDataKey(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, key, 32)
+	assert.NotContains(t, string(wrapped), string(key))
+
+	unwrapped, err := provider.DecryptDataKey(context.Background(), wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	otherProvider, err := NewLocalProvider(bytes.Repeat([]byte{8}, 32))
+	require.NoError(t, err)
+	_, err = otherProvider.DecryptDataKey(context.Background(), wrapped)
+	assert.Error(t, err, "a data key only unwraps with its own master key")
+}
+
+type fakeKMS struct {
+	keyID string
+}
+
+func (f *fakeKMS) GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error) {
+	f.keyID = *params.KeyId
+	key := bytes.Repeat([]byte{3}, 32)
+	return &kms.GenerateDataKeyOutput{Plaintext: key, CiphertextBlob: append([]byte("wrapped:"), key...)}, nil
+}
+
+func (f *fakeKMS) Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	return &kms.DecryptOutput{Plaintext: bytes.TrimPrefix(params.CiphertextBlob, []byte("wrapped:"))}, nil
+}
+
+func TestKMSProvider(t *testing.T) {
+	client := &fakeKMS{}
+	provider := &kmsProvider{client: client, keyID: "alias/mysteryfactory"}
+
+	keyring, _ := newTestKeyring(t, provider, "kms-1")
+	assert.Equal(t, "alias/mysteryfactory", client.keyID)
+
+	value, err := keyring.Encrypt("secret")
+	require.NoError(t, err)
+	plaintext, err := keyring.Decrypt(value)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plaintext)
+}
@@ -0,0 +1,124 @@
+package encryption
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// KeyProvider generates and unwraps the data keys held by a Keyring
+type KeyProvider interface {
+	// GenerateDataKey returns a new 32-byte data key and its wrapped form
+	GenerateDataKey(ctx context.Context) (plaintext, wrapped []byte, err error)
+	// DecryptDataKey unwraps a data key returned by GenerateDataKey
+	DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// kmsAPI is the subset of the KMS client used by the KMS provider
+type kmsAPI interface {
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// kmsProvider wraps data keys with an AWS KMS key
+type kmsProvider struct {
+	client kmsAPI
+	keyID  string
+}
+
+var _ KeyProvider = (*kmsProvider)(nil)
+
+// NewKMSProvider creates a provider wrapping data keys with the given KMS key
+func NewKMSProvider(ctx context.Context, region, keyID string) (KeyProvider, error) {
+	if keyID == "" {
+		return nil, errors.New("KMS key ID is required")
+	}
+	awsConfig, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return &kmsProvider{client: kms.NewFromConfig(awsConfig), keyID: keyID}, nil
+}
+
+// GenerateDataKey asks KMS for a new AES-256 data key
+func (p *kmsProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	out, err := p.client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(p.keyID),
+		KeySpec: types.DataKeySpecAes256,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate KMS data key: %w", err)
+	}
+	return out.Plaintext, out.CiphertextBlob, nil
+}
+
+// DecryptDataKey unwraps a data key with KMS
+func (p *kmsProvider) DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	out, err := p.client.Decrypt(ctx, &kms.DecryptInput{
+		CiphertextBlob: wrapped,
+		KeyId:          aws.String(p.keyID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt KMS data key: %w", err)
+	}
+	return out.Plaintext, nil
+}
+
+// localProvider wraps data keys with a static master key, for development and
+// tests where KMS is not available
+type localProvider struct {
+	master *Keyring
+}
+
+var _ KeyProvider = (*localProvider)(nil)
+
+// NewLocalProvider creates a provider wrapping data keys with a 32-byte master key
+func NewLocalProvider(masterKey []byte) (KeyProvider, error) {
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key: %w", err)
+	}
+	return &localProvider{master: &Keyring{activeID: "master", keys: map[string]cipher.AEAD{"master": aead}}}, nil
+}
+
+// ParseMasterKey decodes a base64 master key
+func ParseMasterKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key encoding: %w", err)
+	}
+	return key, nil
+}
+
+// GenerateDataKey creates a random data key wrapped with the master key
+func (p *localProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrapped, err := p.master.Encrypt(string(key))
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, []byte(wrapped), nil
+}
+
+// DecryptDataKey unwraps a data key with the master key
+func (p *localProvider) DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	if !IsEncrypted(string(wrapped)) {
+		return nil, ErrMalformedCiphertext
+	}
+	key, err := p.master.Decrypt(string(wrapped))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(key), nil
+}
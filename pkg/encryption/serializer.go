@@ -0,0 +1,84 @@
+package encryption
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName is the GORM serializer tag for encrypted columns: `gorm:"serializer:encrypted"`
+const SerializerName = "encrypted"
+
+// ErrNoKeyring is returned when an encrypted value is read before a keyring is configured
+var ErrNoKeyring = errors.New("encrypted value read without a configured keyring")
+
+// activeKeyring is the keyring used by the serializer; nil disables encryption
+var activeKeyring atomic.Pointer[Keyring]
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// SetKeyring sets the keyring used for encrypted columns. With a nil keyring
+// values are written in plaintext, and only plaintext values can be read.
+func SetKeyring(k *Keyring) {
+	activeKeyring.Store(k)
+}
+
+// CurrentKeyring returns the keyring used for encrypted columns, or nil
+func CurrentKeyring() *Keyring {
+	return activeKeyring.Load()
+}
+
+// Serializer transparently encrypts string columns on write and decrypts
+// them on read. Empty strings are stored as is.
+type Serializer struct{}
+
+var _ schema.SerializerInterface = Serializer{}
+
+// Scan decrypts a column value into the field
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	default:
+		return fmt.Errorf("unsupported encrypted column value %T for %s", dbValue, field.Name)
+	}
+
+	plaintext := stored
+	if IsEncrypted(stored) {
+		keyring := activeKeyring.Load()
+		if keyring == nil {
+			return fmt.Errorf("%w: %s", ErrNoKeyring, field.Name)
+		}
+		var err error
+		if plaintext, err = keyring.Decrypt(stored); err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", field.Name, err)
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+// Value encrypts the field with the active data key
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("encrypted field %s must be a string, got %T", field.Name, fieldValue)
+	}
+
+	keyring := activeKeyring.Load()
+	if plaintext == "" || keyring == nil {
+		return plaintext, nil
+	}
+	return keyring.Encrypt(plaintext)
+}
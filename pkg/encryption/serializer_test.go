@@ -0,0 +1,93 @@
+package encryption
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type credential struct {
+	ID    string `gorm:"primaryKey"`
+	Token string `gorm:"serializer:encrypted"`
+}
+
+// ciphertextArg matches an encrypted value and captures it
+type ciphertextArg struct {
+	value *string
+}
+
+func (a ciphertextArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	*a.value = s
+	return ok && strings.HasPrefix(s, "enc:v1:k1:")
+}
+
+func newSerializerDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+	return gormDB, mock
+}
+
+func TestSerializer_EncryptsOnWriteAndDecryptsOnRead(t *testing.T) {
+	keyring, _ := newTestKeyring(t, newTestProvider(t), "k1")
+	SetKeyring(keyring)
+	t.Cleanup(func() { SetKeyring(nil) })
+
+	db, mock := newSerializerDB(t)
+	var stored string
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `credentials`").
+		WithArgs("cred-1", ciphertextArg{value: &stored}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, db.Create(&credential{ID: "cred-1", Token: "oauth-token"}).Error)
+	assert.NotContains(t, stored, "oauth-token")
+
+	mock.ExpectQuery("SELECT \\* FROM `credentials`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "token"}).
+			AddRow("cred-1", []byte(stored)).
+			AddRow("cred-2", []byte("legacy-plaintext")).
+			AddRow("cred-3", nil))
+
+	var creds []credential
+	require.NoError(t, db.Order("id").Find(&creds).Error)
+	require.Len(t, creds, 3)
+	assert.Equal(t, "oauth-token", creds[0].Token)
+	assert.Equal(t, "legacy-plaintext", creds[1].Token)
+	assert.Equal(t, "", creds[2].Token)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSerializer_WithoutKeyring(t *testing.T) {
+	SetKeyring(nil)
+
+	db, mock := newSerializerDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `credentials`").
+		WithArgs("cred-1", "plain").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	require.NoError(t, db.Create(&credential{ID: "cred-1", Token: "plain"}).Error)
+
+	// Encrypted values cannot be read back without the keyring
+	mock.ExpectQuery("SELECT \\* FROM `credentials`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "token"}).AddRow("cred-1", []byte("enc:v1:k1:AAAA")))
+	var cred credential
+	err := db.First(&cred).Error
+	assert.ErrorIs(t, err, ErrNoKeyring)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,147 @@
+// Package i18n translates the user-facing messages of the API. The catalog
+// is keyed by the English message, so a message without a translation is
+// shown in English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the language of the messages in the code
+const Default = "en"
+
+// Languages are the languages messages are translated to, Default first
+var Languages = []string{Default, "fr", "es", "de"}
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalog maps each language to its translations, keyed by the English message
+var catalog = mustLoad()
+
+func mustLoad() map[string]map[string]string {
+	c := make(map[string]map[string]string)
+	for _, lang := range Languages[1:] {
+		raw, err := locales.ReadFile(path.Join("locales", lang+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", lang, err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", lang, err))
+		}
+		c[lang] = messages
+	}
+	return c
+}
+
+// T translates message to lang. Error messages chain their causes with
+// ": ", so a message without a translation of its own is translated part by
+// part, leaving unknown parts in English.
+func T(lang, message string) string {
+	messages, ok := catalog[lang]
+	if !ok || message == "" {
+		return message
+	}
+	if translated, ok := messages[message]; ok {
+		return translated
+	}
+	parts := strings.Split(message, ": ")
+	if len(parts) == 1 {
+		return message
+	}
+	for i, part := range parts {
+		if translated, ok := messages[part]; ok {
+			parts[i] = translated
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
+// Message is a message translated once its reader's language is known, such
+// as a notification rendered in the language of its recipient
+type Message struct {
+	Format string
+	Args   []interface{}
+}
+
+// M returns the message formatting args with format
+func M(format string, args ...interface{}) Message {
+	return Message{Format: format, Args: args}
+}
+
+// In renders the message in lang
+func (m Message) In(lang string) string {
+	return fmt.Sprintf(T(lang, m.Format), m.Args...)
+}
+
+// Error is an error whose detail is translated with its cause
+type Error struct {
+	cause  error
+	detail Message
+}
+
+// Errorf returns cause detailed by format, such as ErrInvalidInput with the
+// rule the input broke. Its message reads like fmt.Errorf("%w: "+format).
+func Errorf(cause error, format string, args ...interface{}) error {
+	return &Error{cause: cause, detail: M(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.cause.Error() + ": " + e.detail.In(Default)
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// ErrorMessage returns the message of err in lang
+func ErrorMessage(lang string, err error) string {
+	var detailed *Error
+	if errors.As(err, &detailed) && detailed.Error() == err.Error() {
+		return T(lang, detailed.cause.Error()) + ": " + detailed.detail.In(lang)
+	}
+	return T(lang, err.Error())
+}
+
+// Negotiate returns the language of Languages the Accept-Language header
+// prefers, Default when it accepts none of them. Regional variants match
+// their language: fr-CA is served French.
+func Negotiate(header string) string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var accepted []weighted
+	for _, entry := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(entry), ";")
+		lang := strings.ToLower(strings.TrimSpace(fields[0]))
+		if base, _, ok := strings.Cut(lang, "-"); ok {
+			lang = base
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 && slices.Contains(Languages, lang) {
+			accepted = append(accepted, weighted{lang, q})
+		}
+	}
+	if len(accepted) == 0 {
+		return Default
+	}
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].q > accepted[j].q })
+	return accepted[0].lang
+}
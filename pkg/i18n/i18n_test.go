@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"fr", "fr"},
+		{"fr-CA,fr;q=0.9,en;q=0.8", "fr"},
+		{"ja,de;q=0.5", "de"},
+		{"en;q=0.2, es;q=0.7", "es"},
+		{"DE-at", "de"},
+		{"it, pt", "en"},
+		{"fr;q=0, es", "es"},
+		{"*", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, Negotiate(tt.header))
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Vidéo introuvable", T("fr", "Video not found"))
+	assert.Equal(t, "Video not found", T("en", "Video not found"))
+	assert.Equal(t, "Video not found", T("it", "Video not found"), "unsupported languages read English")
+	assert.Equal(t, "Nothing like this", T("de", "Nothing like this"), "untranslated messages read English")
+	assert.Equal(t, "entrada no válida: Summer", T("es", "invalid input: Summer"), "chained errors are translated part by part")
+}
+
+func TestMessageIn(t *testing.T) {
+	m := M("%q was accepted by tenant %s", "Launch", "acme")
+	assert.Equal(t, `"Launch" was accepted by tenant acme`, m.In("en"))
+	assert.Equal(t, `"Launch" wurde von Mandant acme angenommen`, m.In("de"))
+	assert.Empty(t, Message{}.In("fr"))
+}
+
+func TestErrorf(t *testing.T) {
+	invalid := errors.New("invalid input")
+	err := Errorf(invalid, "limit must be at most %d", 200)
+
+	assert.ErrorIs(t, err, invalid)
+	assert.EqualError(t, err, "invalid input: limit must be at most 200")
+	assert.Equal(t, "saisie invalide: limit doit être au plus 200", ErrorMessage("fr", err))
+
+	wrapped := fmt.Errorf("failed to list: %w", err)
+	assert.Equal(t, "failed to list: saisie invalide: limit must be at most 200", ErrorMessage("fr", wrapped),
+		"a wrapped error keeps its untranslated context")
+	assert.Equal(t, "recurso no encontrado", ErrorMessage("es", errors.New("resource not found")))
+}
+
+var verb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// TestCatalog_KeepsFormatVerbs guards the translations of formats: one losing
+// or reordering a verb would render garbage
+func TestCatalog_KeepsFormatVerbs(t *testing.T) {
+	require.Len(t, catalog, len(Languages)-1)
+	for lang, messages := range catalog {
+		assert.Len(t, messages, len(catalog["fr"]), "%s translates as many messages as fr", lang)
+		for key, translated := range messages {
+			assert.NotEmpty(t, translated, "%s: %q", lang, key)
+			assert.Equal(t, verb.FindAllString(key, -1), verb.FindAllString(translated, -1), "%s: %q", lang, key)
+		}
+		for key := range catalog["fr"] {
+			assert.Contains(t, messages, key, "%s translates every message", lang)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+// Package id generates entity identifiers.
+//
+// IDs are UUIDv7: 36-character strings whose leading 48 bits are a millisecond
+// Unix timestamp, so they sort by creation time and keep InnoDB primary key
+// inserts append-mostly, while the random tail keeps them unique under concurrency.
+package id
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// New returns a new UUIDv7 string
+func New() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// Time returns the creation time encoded in a UUIDv7 string
+func Time(s string) (time.Time, error) {
+	u, err := uuid.Parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid id %q: %w", s, err)
+	}
+	if u.Version() != 7 {
+		return time.Time{}, fmt.Errorf("id %q is UUID version %d, not 7", s, u.Version())
+	}
+	sec, nsec := u.Time().UnixTime()
+	return time.Unix(sec, nsec), nil
+}
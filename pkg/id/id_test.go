@@ -0,0 +1,67 @@
+package id
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_UniqueUnderConcurrency(t *testing.T) {
+	const workers, perWorker = 16, 2000
+
+	var mu sync.Mutex
+	seen := make(map[string]struct{}, workers*perWorker)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids := make([]string, perWorker)
+			for i := range ids {
+				ids[i] = New()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range ids {
+				seen[id] = struct{}{}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, seen, workers*perWorker)
+}
+
+func TestNew_SortableAndVersion7(t *testing.T) {
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = New()
+	}
+
+	assert.True(t, sort.StringsAreSorted(ids), "ids generated in sequence must sort in creation order")
+
+	u, err := uuid.Parse(ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), u.Version())
+	assert.Len(t, ids[0], 36)
+}
+
+func TestTime(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	created, err := Time(New())
+	require.NoError(t, err)
+	assert.False(t, created.Before(before))
+	assert.WithinDuration(t, time.Now(), created, time.Second)
+
+	_, err = Time(uuid.New().String())
+	assert.Error(t, err, "v4 ids carry no timestamp")
+
+	_, err = Time("video_123")
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// Tenant label modes
+const (
+	// TenantLabelRaw labels series with tenant IDs as they are
+	TenantLabelRaw = "raw"
+	// TenantLabelHash labels series with a hash of the tenant ID, keeping a
+	// series per tenant without exposing IDs
+	TenantLabelHash = "hash"
+	// TenantLabelBucket spreads tenants over a fixed number of buckets
+	TenantLabelBucket = "bucket"
+	// TenantLabelNone labels every series with the same value
+	TenantLabelNone = "none"
+)
+
+// Label values standing in for the ones that are missing or dropped
+const (
+	UnknownLabel   = "unknown"   // No tenant, such as unauthenticated requests
+	OtherLabel     = "other"     // Beyond the cap of distinct tenants
+	UnmatchedRoute = "unmatched" // Requests no route matched, whatever their path
+	allTenants     = "all"
+)
+
+// LabelPolicy decides the tenant_id label of every metric. The zero value
+// keeps raw tenant IDs without a cap.
+type LabelPolicy struct {
+	TenantLabel   string // raw, hash, bucket or none
+	TenantBuckets int    // Buckets of the bucket mode
+	MaxTenants    int    // Distinct tenant values before the others are labelled other; 0 for no cap
+}
+
+// tenantLabeler turns tenant IDs into label values as the policy says
+type tenantLabeler struct {
+	policy LabelPolicy
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func newTenantLabeler(policy LabelPolicy) *tenantLabeler {
+	return &tenantLabeler{policy: policy, seen: make(map[string]struct{})}
+}
+
+// label returns the label value of the tenant; once MaxTenants values were
+// seen, new ones are labelled other
+func (l *tenantLabeler) label(tenantID string) string {
+	if tenantID == "" {
+		return UnknownLabel
+	}
+
+	value := tenantID
+	switch l.policy.TenantLabel {
+	case TenantLabelNone:
+		return allTenants
+	case TenantLabelHash:
+		sum := sha256.Sum256([]byte(tenantID))
+		value = hex.EncodeToString(sum[:6])
+	case TenantLabelBucket:
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(tenantID))
+		return fmt.Sprintf("bucket-%d", h.Sum32()%uint32(l.policy.TenantBuckets))
+	}
+
+	if l.policy.MaxTenants <= 0 {
+		return value
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[value]; ok {
+		return value
+	}
+	if len(l.seen) >= l.policy.MaxTenants {
+		return OtherLabel
+	}
+	l.seen[value] = struct{}{}
+	return value
+}
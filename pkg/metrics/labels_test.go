@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantLabeler(t *testing.T) {
+	raw := newTenantLabeler(LabelPolicy{MaxTenants: 2})
+	assert.Equal(t, "acme", raw.label("acme"))
+	assert.Equal(t, "globex", raw.label("globex"))
+	assert.Equal(t, OtherLabel, raw.label("initech"), "beyond the cap")
+	assert.Equal(t, "acme", raw.label("acme"), "tenants seen keep their label")
+	assert.Equal(t, UnknownLabel, raw.label(""))
+
+	hashed := newTenantLabeler(LabelPolicy{TenantLabel: TenantLabelHash})
+	assert.Len(t, hashed.label("acme"), 12)
+	assert.NotContains(t, hashed.label("acme"), "acme")
+	assert.Equal(t, hashed.label("acme"), hashed.label("acme"))
+
+	buckets := newTenantLabeler(LabelPolicy{TenantLabel: TenantLabelBucket, TenantBuckets: 4})
+	values := map[string]bool{}
+	for _, tenant := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		values[buckets.label(tenant)] = true
+	}
+	assert.LessOrEqual(t, len(values), 4)
+	assert.Contains(t, buckets.label("a"), "bucket-")
+
+	assert.Equal(t, "all", newTenantLabeler(LabelPolicy{TenantLabel: TenantLabelNone}).label("acme"))
+}
@@ -1,14 +1,20 @@
 package metrics
 
 import (
+	"context"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// traceIDLabel names the exemplar label holding the trace ID, the one Grafana
+// links to Jaeger or Tempo by default
+const traceIDLabel = "trace_id"
+
 // Metrics holds all Prometheus metrics for the application
 type Metrics struct {
 	// HTTP metrics
@@ -16,18 +22,30 @@ type Metrics struct {
 	HTTPRequestDuration  *prometheus.HistogramVec
 	HTTPRequestsInFlight prometheus.Gauge
 
+	// Latency objectives, labelled by objective only to keep series bounded
+	SLORequestsTotal   *prometheus.CounterVec
+	SLORequestDuration *prometheus.HistogramVec
+
 	// AI processing metrics
 	AIRequestsTotal    *prometheus.CounterVec
 	AIRequestDuration  *prometheus.HistogramVec
 	AITokensUsed       *prometheus.CounterVec
 	AIRequestsInFlight prometheus.Gauge
 
+	// Bedrock region metrics
+	BedrockRegionCalls *prometheus.CounterVec
+	BedrockFailovers   *prometheus.CounterVec
+	BedrockFailedOver  *prometheus.GaugeVec
+
 	// Database metrics
 	DBConnectionsActive prometheus.Gauge
 	DBConnectionsIdle   prometheus.Gauge
 	DBQueriesTotal      *prometheus.CounterVec
 	DBQueryDuration     *prometheus.HistogramVec
 
+	// Slow dependency calls, above the thresholds of pkg/slowlog
+	SlowOperationDuration *prometheus.HistogramVec
+
 	// Business metrics
 	VideosTotal         *prometheus.CounterVec
 	VideoProcessingTime *prometheus.HistogramVec
@@ -35,14 +53,26 @@ type Metrics struct {
 	CampaignSuccess     *prometheus.CounterVec
 	MagicBrushRequests  *prometheus.CounterVec
 
+	// Stats freshness, set by the stats-freshness job
+	StatsLastSync *prometheus.GaugeVec
+
 	// System metrics
 	ErrorsTotal *prometheus.CounterVec
 	PanicTotal  prometheus.Counter
+
+	// Background loop metrics
+	BackgroundLoopUp       *prometheus.GaugeVec
+	BackgroundLoopRestarts *prometheus.CounterVec
+
+	tenants *tenantLabeler
 }
 
-// New creates and registers all Prometheus metrics
-func New() *Metrics {
+// New creates and registers all Prometheus metrics, labelling tenants as the
+// policy says
+func New(policy LabelPolicy) *Metrics {
 	return &Metrics{
+		tenants: newTenantLabeler(policy),
+
 		// HTTP metrics
 		HTTPRequestsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -66,6 +96,23 @@ func New() *Metrics {
 			},
 		),
 
+		// Latency objectives
+		SLORequestsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "slo_requests_total",
+				Help: "Requests of endpoints with a latency objective by Apdex outcome",
+			},
+			[]string{"objective", "outcome"},
+		),
+		SLORequestDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "slo_request_duration_seconds",
+				Help:    "Duration of requests of endpoints with a latency objective",
+				Buckets: []float64{0.05, 0.1, 0.2, 0.3, 0.5, 0.75, 1, 1.2, 2, 3, 5, 10, 20, 30},
+			},
+			[]string{"objective"},
+		),
+
 		// AI processing metrics
 		AIRequestsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -96,6 +143,29 @@ func New() *Metrics {
 			},
 		),
 
+		// Bedrock region metrics
+		BedrockRegionCalls: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "bedrock_region_calls_total",
+				Help: "Total number of Bedrock calls per region",
+			},
+			[]string{"region", "status"},
+		),
+		BedrockFailovers: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "bedrock_failovers_total",
+				Help: "Total number of switches between the primary and secondary Bedrock regions",
+			},
+			[]string{"name", "from", "to"},
+		),
+		BedrockFailedOver: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "bedrock_failed_over",
+				Help: "Whether calls go to the secondary Bedrock region (1) or the primary one (0)",
+			},
+			[]string{"name"},
+		),
+
 		// Database metrics
 		DBConnectionsActive: promauto.NewGauge(
 			prometheus.GaugeOpts{
@@ -125,6 +195,16 @@ func New() *Metrics {
 			[]string{"operation", "table", "tenant_id"},
 		),
 
+		// Slow dependency calls
+		SlowOperationDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "slow_operation_duration_seconds",
+				Help:    "Duration of database, platform and Bedrock calls slower than their threshold, in seconds",
+				Buckets: []float64{0.2, 0.5, 1, 2, 5, 10, 20, 30, 60, 120},
+			},
+			[]string{"dependency", "target"},
+		),
+
 		// Business metrics
 		VideosTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -163,6 +243,15 @@ func New() *Metrics {
 			[]string{"brush_type", "status", "tenant_id"},
 		),
 
+		// Stats freshness
+		StatsLastSync: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "stats_last_sync_timestamp_seconds",
+				Help: "Unix time of the latest platform stats sync of each tenant and platform",
+			},
+			[]string{"tenant_id", "platform"},
+		),
+
 		// System metrics
 		ErrorsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -177,17 +266,29 @@ func New() *Metrics {
 				Help: "Total number of panics",
 			},
 		),
+		BackgroundLoopUp: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "background_loop_up",
+				Help: "Whether each supervised background loop is running (1) or waiting to restart (0)",
+			},
+			[]string{"loop"},
+		),
+		BackgroundLoopRestarts: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "background_loop_restarts_total",
+				Help: "Total number of restarts of supervised background loops, by why they stopped",
+			},
+			[]string{"loop", "reason"},
+		),
 	}
 }
 
-// HTTPMiddleware returns a Gin middleware for HTTP metrics collection
+// HTTPMiddleware returns a Gin middleware for HTTP metrics collection.
+// Requests are labelled with their route template, never their path, and
+// with the tenant authentication put on the context.
 func (m *Metrics) HTTPMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-		tenantID := c.GetHeader("X-Tenant-ID")
-		if tenantID == "" {
-			tenantID = "unknown"
-		}
 
 		// Increment in-flight requests
 		m.HTTPRequestsInFlight.Inc()
@@ -199,27 +300,51 @@ func (m *Metrics) HTTPMiddleware() gin.HandlerFunc {
 		// Record metrics
 		duration := time.Since(start).Seconds()
 		statusCode := strconv.Itoa(c.Writer.Status())
+		endpoint := c.FullPath()
+		if endpoint == "" {
+			endpoint = UnmatchedRoute
+		}
 
 		labels := prometheus.Labels{
 			"method":      c.Request.Method,
-			"endpoint":    c.FullPath(),
+			"endpoint":    endpoint,
 			"status_code": statusCode,
-			"tenant_id":   tenantID,
+			"tenant_id":   m.tenants.label(c.GetString("tenant_id")),
 		}
 
 		m.HTTPRequestsTotal.With(labels).Inc()
-		m.HTTPRequestDuration.With(labels).Observe(duration)
+		observeWithTrace(c.Request.Context(), m.HTTPRequestDuration.With(labels), duration)
 	}
 }
 
-// RecordAIRequest records metrics for AI requests
-func (m *Metrics) RecordAIRequest(model, promptKey, brushType, status, tenantID string, duration time.Duration, tokensUsed int) {
+// observeWithTrace observes value, with the sampled trace of ctx as its
+// exemplar so a slow bucket links to a trace showing why. Exemplars are only
+// exposed in the OpenMetrics format.
+func observeWithTrace(ctx context.Context, observer prometheus.Observer, value float64) {
+	span := trace.SpanContextFromContext(ctx)
+	if exemplars, ok := observer.(prometheus.ExemplarObserver); ok && span.IsSampled() {
+		exemplars.ObserveWithExemplar(value, prometheus.Labels{traceIDLabel: span.TraceID().String()})
+		return
+	}
+	observer.Observe(value)
+}
+
+// RecordSLORequest records a request of an endpoint with a latency objective
+func (m *Metrics) RecordSLORequest(objective, outcome string, duration time.Duration) {
+	m.SLORequestsTotal.With(prometheus.Labels{"objective": objective, "outcome": outcome}).Inc()
+	m.SLORequestDuration.With(prometheus.Labels{"objective": objective}).Observe(duration.Seconds())
+}
+
+// RecordAIRequest records metrics for AI requests, the duration with the
+// trace of ctx as its exemplar
+func (m *Metrics) RecordAIRequest(ctx context.Context, model, promptKey, brushType, status, tenantID string, duration time.Duration, tokensUsed int) {
+	tenant := m.tenants.label(tenantID)
 	labels := prometheus.Labels{
 		"model":      model,
 		"prompt_key": promptKey,
 		"brush_type": brushType,
 		"status":     status,
-		"tenant_id":  tenantID,
+		"tenant_id":  tenant,
 	}
 
 	m.AIRequestsTotal.With(labels).Inc()
@@ -228,91 +353,123 @@ func (m *Metrics) RecordAIRequest(model, promptKey, brushType, status, tenantID
 		"model":      model,
 		"prompt_key": promptKey,
 		"brush_type": brushType,
-		"tenant_id":  tenantID,
+		"tenant_id":  tenant,
 	}
-	m.AIRequestDuration.With(durationLabels).Observe(duration.Seconds())
+	observeWithTrace(ctx, m.AIRequestDuration.With(durationLabels), duration.Seconds())
 
 	if tokensUsed > 0 {
 		tokenLabels := prometheus.Labels{
 			"model":      model,
 			"prompt_key": promptKey,
 			"type":       "total",
-			"tenant_id":  tenantID,
+			"tenant_id":  tenant,
 		}
 		m.AITokensUsed.With(tokenLabels).Add(float64(tokensUsed))
 	}
 }
 
+// RecordBedrockRegionCall records a Bedrock call to a region
+func (m *Metrics) RecordBedrockRegionCall(region, status string) {
+	m.BedrockRegionCalls.With(prometheus.Labels{"region": region, "status": status}).Inc()
+}
+
+// RecordBedrockFailover records a switch of the preferred Bedrock region
+func (m *Metrics) RecordBedrockFailover(name, from, to string, failedOver bool) {
+	m.BedrockFailovers.With(prometheus.Labels{"name": name, "from": from, "to": to}).Inc()
+	value := 0.0
+	if failedOver {
+		value = 1
+	}
+	m.BedrockFailedOver.With(prometheus.Labels{"name": name}).Set(value)
+}
+
 // RecordDBQuery records metrics for database queries
 func (m *Metrics) RecordDBQuery(operation, table, status, tenantID string, duration time.Duration) {
+	tenant := m.tenants.label(tenantID)
 	queryLabels := prometheus.Labels{
 		"operation": operation,
 		"table":     table,
 		"status":    status,
-		"tenant_id": tenantID,
+		"tenant_id": tenant,
 	}
 	m.DBQueriesTotal.With(queryLabels).Inc()
 
 	durationLabels := prometheus.Labels{
 		"operation": operation,
 		"table":     table,
-		"tenant_id": tenantID,
+		"tenant_id": tenant,
 	}
 	m.DBQueryDuration.With(durationLabels).Observe(duration.Seconds())
 }
 
+// RecordSlowOperation records a call of dependency to target slower than its threshold
+func (m *Metrics) RecordSlowOperation(dependency, target string, duration time.Duration) {
+	m.SlowOperationDuration.With(prometheus.Labels{"dependency": dependency, "target": target}).Observe(duration.Seconds())
+}
+
 // RecordVideo records metrics for video operations
 func (m *Metrics) RecordVideo(status, tenantID string) {
+	tenant := m.tenants.label(tenantID)
 	labels := prometheus.Labels{
 		"status":    status,
-		"tenant_id": tenantID,
+		"tenant_id": tenant,
 	}
 	m.VideosTotal.With(labels).Inc()
 }
 
 // RecordVideoProcessing records metrics for video processing duration
 func (m *Metrics) RecordVideoProcessing(status, tenantID string, duration time.Duration) {
+	tenant := m.tenants.label(tenantID)
 	labels := prometheus.Labels{
 		"status":    status,
-		"tenant_id": tenantID,
+		"tenant_id": tenant,
 	}
 	m.VideoProcessingTime.With(labels).Observe(duration.Seconds())
 }
 
 // RecordCampaign records metrics for campaign operations
 func (m *Metrics) RecordCampaign(status, tenantID string) {
+	tenant := m.tenants.label(tenantID)
 	labels := prometheus.Labels{
 		"status":    status,
-		"tenant_id": tenantID,
+		"tenant_id": tenant,
 	}
 	m.CampaignsTotal.With(labels).Inc()
 }
 
 // RecordCampaignSuccess records metrics for successful campaign operations
 func (m *Metrics) RecordCampaignSuccess(operation, tenantID string) {
+	tenant := m.tenants.label(tenantID)
 	labels := prometheus.Labels{
 		"operation": operation,
-		"tenant_id": tenantID,
+		"tenant_id": tenant,
 	}
 	m.CampaignSuccess.With(labels).Inc()
 }
 
 // RecordMagicBrush records metrics for magic brush requests
 func (m *Metrics) RecordMagicBrush(brushType, status, tenantID string) {
+	tenant := m.tenants.label(tenantID)
 	labels := prometheus.Labels{
 		"brush_type": brushType,
 		"status":     status,
-		"tenant_id":  tenantID,
+		"tenant_id":  tenant,
 	}
 	m.MagicBrushRequests.With(labels).Inc()
 }
 
+// RecordStatsLastSync records when a tenant's stats of a platform were last synced
+func (m *Metrics) RecordStatsLastSync(tenantID, platform string, at time.Time) {
+	m.StatsLastSync.With(prometheus.Labels{"tenant_id": m.tenants.label(tenantID), "platform": platform}).Set(float64(at.Unix()))
+}
+
 // RecordError records metrics for errors
 func (m *Metrics) RecordError(errorType, component, tenantID string) {
+	tenant := m.tenants.label(tenantID)
 	labels := prometheus.Labels{
 		"type":      errorType,
 		"component": component,
-		"tenant_id": tenantID,
+		"tenant_id": tenant,
 	}
 	m.ErrorsTotal.With(labels).Inc()
 }
@@ -322,6 +479,24 @@ func (m *Metrics) RecordPanic() {
 	m.PanicTotal.Inc()
 }
 
+// SetLoopUp records whether a background loop is running
+func (m *Metrics) SetLoopUp(loop string, up bool) {
+	value := 0.0
+	if up {
+		value = 1
+	}
+	m.BackgroundLoopUp.With(prometheus.Labels{"loop": loop}).Set(value)
+}
+
+// RecordLoopRestart records a restart of a background loop that panicked,
+// failed or returned
+func (m *Metrics) RecordLoopRestart(loop, reason string) {
+	m.BackgroundLoopRestarts.With(prometheus.Labels{"loop": loop, "reason": reason}).Inc()
+	if reason == "panic" {
+		m.PanicTotal.Inc()
+	}
+}
+
 // UpdateDBConnections updates database connection metrics
 func (m *Metrics) UpdateDBConnections(active, idle int) {
 	m.DBConnectionsActive.Set(float64(active))
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRecordAIRequestExemplars(t *testing.T) {
+	m := New(LabelPolicy{TenantLabel: TenantLabelNone})
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanID := trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+	traced := func(flags trace.TraceFlags) context.Context {
+		return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID, SpanID: spanID, TraceFlags: flags,
+		}))
+	}
+
+	m.RecordAIRequest(traced(trace.FlagsSampled), "sampled", "title", "", "success", "acme", 300*time.Millisecond, 0)
+	m.RecordAIRequest(traced(0), "unsampled", "title", "", "success", "acme", 300*time.Millisecond, 0)
+	m.RecordAIRequest(context.Background(), "untraced", "title", "", "success", "acme", 300*time.Millisecond, 0)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	w := httptest.NewRecorder()
+	promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}).ServeHTTP(w, req)
+	require.Contains(t, w.Header().Get("Content-Type"), "application/openmetrics-text")
+
+	exemplars := map[string][]string{}
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if !strings.HasPrefix(line, "ai_request_duration_seconds_bucket{") {
+			continue
+		}
+		for _, model := range []string{"sampled", "unsampled", "untraced"} {
+			if strings.Contains(line, `model="`+model+`"`) {
+				if _, exemplar, ok := strings.Cut(line, " # "); ok {
+					exemplars[model] = append(exemplars[model], exemplar)
+				}
+			}
+		}
+	}
+
+	require.Len(t, exemplars["sampled"], 1, "the bucket the duration falls in holds the exemplar")
+	assert.Contains(t, exemplars["sampled"][0], `{trace_id="`+traceID.String()+`"} 0.3`)
+	assert.Empty(t, exemplars["unsampled"], "unsampled traces are not kept, so link to nothing")
+	assert.Empty(t, exemplars["untraced"])
+}
@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// Mailer delivers notifications to users by email
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// HTMLMailer is a Mailer able to send an HTML part with the plain text, such
+// as the branded notification emails; other mailers get the plain text
+type HTMLMailer interface {
+	Mailer
+	SendHTML(ctx context.Context, to string, email *Email) error
+}
+
+// LogMailer logs emails instead of sending them, for development and until
+// an email provider is configured
+type LogMailer struct {
+	logger *logger.Logger
+}
+
+var _ HTMLMailer = (*LogMailer)(nil)
+
+// NewLogMailer creates a mailer writing to logger
+func NewLogMailer(logger *logger.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+// Send logs the email at info level
+func (m *LogMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.logger.Info("Email: "+subject, "to", to, "body", body)
+	return nil
+}
+
+// SendHTML logs the email's plain text and the size of its HTML part
+func (m *LogMailer) SendHTML(ctx context.Context, to string, email *Email) error {
+	m.logger.Info("Email: "+email.Subject, "to", to, "body", email.Text, "html_bytes", len(email.HTML))
+	return nil
+}
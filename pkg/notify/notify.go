@@ -0,0 +1,80 @@
+// Package notify alerts the admins operating the platform, through a chat
+// webhook or, when none is configured, the error log.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// Notifier delivers alerts to the platform admins
+type Notifier interface {
+	Notify(ctx context.Context, subject, message string) error
+}
+
+// webhookTimeout bounds a delivery, so a hung chat service cannot hold up the job alerting
+const webhookTimeout = 10 * time.Second
+
+// Webhook posts alerts as {"text": "..."} JSON, the payload of Slack,
+// Mattermost and Google Chat incoming webhooks
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+var _ Notifier = (*Webhook)(nil)
+
+// NewWebhook creates a notifier posting to url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{url: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// Notify posts the alert, failing on any non-2xx answer
+func (w *Webhook) Notify(ctx context.Context, subject, message string) error {
+	payload, err := json.Marshal(map[string]string{"text": fmt.Sprintf("*%s*\n%s", subject, message)})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook answered %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Log writes alerts to the error log, for log-based alerting
+type Log struct {
+	logger *logger.Logger
+}
+
+var _ Notifier = (*Log)(nil)
+
+// NewLog creates a notifier writing to logger
+func NewLog(logger *logger.Logger) *Log {
+	return &Log{logger: logger}
+}
+
+// Notify logs the alert at error level
+func (l *Log) Notify(ctx context.Context, subject, message string) error {
+	l.logger.Error("Alert: "+subject, "message", message)
+	return nil
+}
@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	var received map[string]string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhook(server.URL)
+	require.NoError(t, notifier.Notify(context.Background(), "Stats are stale", "acme/youtube last synced 3h ago"))
+	assert.Equal(t, "*Stats are stale*\nacme/youtube last synced 3h ago", received["text"])
+
+	status = http.StatusInternalServerError
+	assert.Error(t, notifier.Notify(context.Background(), "Stats are stale", "again"))
+}
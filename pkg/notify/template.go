@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Email templates
+const (
+	// TemplateAlert is a notification emailed as it happens
+	TemplateAlert = "alert"
+	// TemplateDigest is the notifications held for a digest
+	TemplateDigest = "digest"
+)
+
+// Templates lists the email templates
+var Templates = []string{TemplateAlert, TemplateDigest}
+
+// ErrUnknownTemplate is returned when rendering a template not in Templates
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+//go:embed templates
+var templateFiles embed.FS
+
+var (
+	htmlTemplates = make(map[string]*htmltemplate.Template)
+	textTemplates = make(map[string]*texttemplate.Template)
+)
+
+func init() {
+	for _, name := range Templates {
+		htmlTemplates[name] = htmltemplate.Must(htmltemplate.ParseFS(templateFiles, "templates/layout.html", "templates/"+name+".html"))
+		textTemplates[name] = texttemplate.Must(texttemplate.ParseFS(templateFiles, "templates/"+name+".txt"))
+	}
+}
+
+// Branding styles a tenant's emails: a header band in AccentColor showing the
+// logo, or else the name in TextColor, above the content and the footer
+type Branding struct {
+	Name        string `json:"name"`
+	LogoURL     string `json:"logo_url,omitempty"`
+	AccentColor string `json:"accent_color"`
+	TextColor   string `json:"text_color"`
+	Footer      string `json:"footer,omitempty"`
+}
+
+// EmailItem is one notification of an email, already translated
+type EmailItem struct {
+	Time    string
+	Subject string
+	Message string
+}
+
+// EmailData is what a template renders
+type EmailData struct {
+	Language string
+	Subject  string
+	Brand    Branding
+	Items    []EmailItem
+}
+
+// Email is a rendered email, with its HTML part and the plain text sent to
+// mail clients without HTML
+type Email struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// Render renders the template with data. Data is escaped for HTML, so
+// tenant branding cannot inject markup.
+func Render(template string, data *EmailData) (*Email, error) {
+	html, ok := htmlTemplates[template]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTemplate, template)
+	}
+	var htmlBody, textBody strings.Builder
+	if err := html.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return nil, fmt.Errorf("failed to render %s email: %w", template, err)
+	}
+	if err := textTemplates[template].Execute(&textBody, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s email text: %w", template, err)
+	}
+	return &Email{Subject: data.Subject, Text: textBody.String(), HTML: htmlBody.String()}, nil
+}
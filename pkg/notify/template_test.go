@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	brand := Branding{Name: "Acme <Studios>", AccentColor: "#1A2B3C", TextColor: "#FFFFFF", Footer: "Acme Studios\n<script>alert(1)</script>"}
+
+	email, err := Render(TemplateDigest, &EmailData{
+		Language: "en",
+		Subject:  "Your daily digest",
+		Brand:    brand,
+		Items: []EmailItem{
+			{Time: "Oct 12 08:00", Subject: "Transfer declined"},
+			{Time: "Oct 12 09:30", Subject: "Publication approved", Message: "Your publication of video v1 on tiktok was approved"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Your daily digest", email.Subject)
+	assert.Equal(t, "Oct 12 08:00  Transfer declined\nOct 12 09:30  Publication approved\n  Your publication of video v1 on tiktok was approved\n"+
+		"\n--\nAcme Studios\n<script>alert(1)</script>\n", email.Text)
+	assert.Contains(t, email.HTML, "background:#1A2B3C;color:#FFFFFF")
+	assert.Contains(t, email.HTML, "Acme &lt;Studios&gt;", "without a logo the header shows the name")
+	assert.NotContains(t, email.HTML, "<script>", "branding is escaped")
+
+	brand.LogoURL = "https://cdn.acme.test/logo.png"
+	brand.Footer = ""
+	email, err = Render(TemplateAlert, &EmailData{Subject: "Alert: 10K views", Brand: brand, Items: []EmailItem{{Message: "views is now 10000"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "views is now 10000", email.Text)
+	assert.Contains(t, email.HTML, `<img src="https://cdn.acme.test/logo.png" alt="Acme &lt;Studios&gt;"`)
+	assert.NotContains(t, email.HTML, "border-top", "no footer row without a footer")
+
+	_, err = Render("newsletter", &EmailData{})
+	assert.ErrorIs(t, err, ErrUnknownTemplate)
+}
@@ -0,0 +1,258 @@
+package partners
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// Bulk metadata formats, for the systems videos are distributed through
+// without an integration
+const (
+	// BulkYouTubeCSV is the spreadsheet of YouTube's bulk upload
+	BulkYouTubeCSV = "youtube_csv"
+	// BulkMRSS is a Media RSS feed, read by most video CMSs and OTT apps
+	BulkMRSS = "mrss"
+	// BulkCMSXML is a flat XML manifest of every field partners ask for
+	BulkCMSXML = "cms_xml"
+)
+
+// BulkFormats are the formats NewBulkWriter writes
+var BulkFormats = []string{BulkYouTubeCSV, BulkMRSS, BulkCMSXML}
+
+// youTubeBulkHeader are the columns of the YouTube bulk upload spreadsheet
+// filled from a video; uploads are private until someone reviews them
+var youTubeBulkHeader = []string{"filename", "custom_id", "title", "description", "keywords", "privacy", "custom_thumbnail"}
+
+// BulkWriter writes the metadata of videos one at a time, so exports of
+// any size stream
+type BulkWriter interface {
+	Begin() error
+	Write(video *models.Video) error
+	End() error
+	// ContentType and Extension describe the file being written
+	ContentType() string
+	Extension() string
+}
+
+// NewBulkWriter returns the writer of the format; feed names the export in
+// the formats that carry a title
+func NewBulkWriter(format, feed string, w io.Writer) (BulkWriter, error) {
+	switch format {
+	case BulkYouTubeCSV:
+		return &youTubeCSVWriter{w: csv.NewWriter(w)}, nil
+	case BulkMRSS:
+		return &mrssWriter{w: w, enc: xml.NewEncoder(w), feed: feed}, nil
+	case BulkCMSXML:
+		return &cmsXMLWriter{w: w, enc: xml.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown export format %q, expected one of %v", models.ErrInvalidInput, format, BulkFormats)
+	}
+}
+
+// youTubeCSVWriter writes the metadata as YouTube renders it at upload, so
+// titles, descriptions and keywords already fit its limits
+type youTubeCSVWriter struct {
+	w *csv.Writer
+}
+
+func (y *youTubeCSVWriter) Begin() error {
+	return y.w.Write(youTubeBulkHeader)
+}
+
+func (y *youTubeCSVWriter) Write(video *models.Video) error {
+	meta, _, err := RenderMetadata(models.PlatformYouTube, video)
+	if err != nil {
+		return err
+	}
+	if err := y.w.Write([]string{
+		video.FileName,
+		video.ID,
+		meta.Title,
+		meta.Description,
+		strings.Join(meta.Tags, "|"),
+		"private",
+		video.ThumbnailURL,
+	}); err != nil {
+		return err
+	}
+	// Flushed per video so the caller's flushes send whole rows
+	y.w.Flush()
+	return y.w.Error()
+}
+
+func (y *youTubeCSVWriter) End() error {
+	y.w.Flush()
+	return y.w.Error()
+}
+
+func (y *youTubeCSVWriter) ContentType() string { return "text/csv; charset=utf-8" }
+func (y *youTubeCSVWriter) Extension() string   { return "csv" }
+
+// mrssItem is an item of a Media RSS feed
+type mrssItem struct {
+	XMLName     xml.Name       `xml:"item"`
+	Title       string         `xml:"title"`
+	Description string         `xml:"description,omitempty"`
+	GUID        mrssGUID       `xml:"guid"`
+	PubDate     string         `xml:"pubDate"`
+	Content     mrssContent    `xml:"media:content"`
+	Thumbnail   *mrssThumbnail `xml:"media:thumbnail,omitempty"`
+	Keywords    string         `xml:"media:keywords,omitempty"`
+}
+
+type mrssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type mrssContent struct {
+	URL      string `xml:"url,attr"`
+	FileSize int64  `xml:"fileSize,attr,omitempty"`
+	Medium   string `xml:"medium,attr"`
+	Duration int    `xml:"duration,attr,omitempty"`
+	Title    string `xml:"media:title"`
+}
+
+type mrssThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+// mrssWriter writes a Media RSS 2.0 feed with one item per video
+type mrssWriter struct {
+	w    io.Writer
+	enc  *xml.Encoder
+	feed string
+}
+
+func (m *mrssWriter) Begin() error {
+	_, err := fmt.Fprintf(m.w, "%s<rss version=\"2.0\" xmlns:media=\"http://search.yahoo.com/mrss/\"><channel>", xml.Header)
+	if err != nil {
+		return err
+	}
+	return m.enc.EncodeElement(m.feed, xml.StartElement{Name: xml.Name{Local: "title"}})
+}
+
+func (m *mrssWriter) Write(video *models.Video) error {
+	item := mrssItem{
+		Title:       video.PublishTitle(),
+		Description: video.Description,
+		GUID:        mrssGUID{Value: video.ID},
+		PubDate:     video.CreatedAt.UTC().Format(time.RFC1123Z),
+		Content: mrssContent{
+			URL:      video.FileURL,
+			FileSize: video.FileSize,
+			Medium:   "video",
+			Duration: video.Duration,
+			Title:    video.PublishTitle(),
+		},
+		Keywords: strings.Join(video.GetTags(), ", "),
+	}
+	if video.ThumbnailURL != "" {
+		item.Thumbnail = &mrssThumbnail{URL: video.ThumbnailURL}
+	}
+	return m.enc.Encode(item)
+}
+
+func (m *mrssWriter) End() error {
+	if err := m.enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(m.w, "</channel></rss>\n")
+	return err
+}
+
+func (m *mrssWriter) ContentType() string { return "application/rss+xml; charset=utf-8" }
+func (m *mrssWriter) Extension() string   { return "xml" }
+
+// cmsVideo is a video of the CMS manifest
+type cmsVideo struct {
+	XMLName     xml.Name    `xml:"video"`
+	ID          string      `xml:"id,attr"`
+	Title       string      `xml:"title"`
+	Description string      `xml:"description,omitempty"`
+	Tags        []string    `xml:"tags>tag,omitempty"`
+	Duration    int         `xml:"duration"`
+	File        cmsFile     `xml:"file"`
+	Thumbnail   string      `xml:"thumbnail,omitempty"`
+	Series      *cmsEpisode `xml:"series,omitempty"`
+	Rights      *cmsRights  `xml:"rights,omitempty"`
+	CreatedAt   string      `xml:"created_at"`
+}
+
+type cmsFile struct {
+	URL        string `xml:"url,attr"`
+	Name       string `xml:"name,attr"`
+	Size       int64  `xml:"size,attr"`
+	Format     string `xml:"format,attr,omitempty"`
+	Resolution string `xml:"resolution,attr,omitempty"`
+}
+
+type cmsEpisode struct {
+	ID     string `xml:"id,attr"`
+	Season int    `xml:"season,attr,omitempty"`
+	Number int    `xml:"episode,attr,omitempty"`
+}
+
+type cmsRights struct {
+	Owner     string   `xml:"owner,omitempty"`
+	License   string   `xml:"license,omitempty"`
+	Platforms []string `xml:"platforms>platform,omitempty"`
+	ExpiresAt string   `xml:"expires_at,omitempty"`
+}
+
+// cmsXMLWriter writes a <videos> manifest with one <video> per video
+type cmsXMLWriter struct {
+	w   io.Writer
+	enc *xml.Encoder
+}
+
+func (c *cmsXMLWriter) Begin() error {
+	_, err := io.WriteString(c.w, xml.Header+"<videos>")
+	return err
+}
+
+func (c *cmsXMLWriter) Write(video *models.Video) error {
+	item := cmsVideo{
+		ID:          video.ID,
+		Title:       video.PublishTitle(),
+		Description: video.Description,
+		Tags:        video.Tags,
+		Duration:    video.Duration,
+		File: cmsFile{
+			URL:        video.FileURL,
+			Name:       video.FileName,
+			Size:       video.FileSize,
+			Format:     video.Format,
+			Resolution: video.Resolution,
+		},
+		Thumbnail: video.ThumbnailURL,
+		CreatedAt: video.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if video.Episode.SeriesID != "" {
+		item.Series = &cmsEpisode{ID: video.Episode.SeriesID, Season: video.Episode.Season, Number: video.Episode.Number}
+	}
+	if rights := video.Rights; rights.Owner != "" || rights.LicenseType != "" || len(rights.Platforms) > 0 || rights.ExpiresAt != nil {
+		item.Rights = &cmsRights{Owner: rights.Owner, License: rights.LicenseType, Platforms: rights.Platforms}
+		if rights.ExpiresAt != nil {
+			item.Rights.ExpiresAt = rights.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+	}
+	return c.enc.Encode(item)
+}
+
+func (c *cmsXMLWriter) End() error {
+	if err := c.enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(c.w, "</videos>\n")
+	return err
+}
+
+func (c *cmsXMLWriter) ContentType() string { return "application/xml; charset=utf-8" }
+func (c *cmsXMLWriter) Extension() string   { return "xml" }
@@ -0,0 +1,113 @@
+package partners
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// bulkVideos are two videos, the second in a series
+func bulkVideos() []*models.Video {
+	created := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	return []*models.Video{
+		{
+			ID: "video-1", Title: "The <lighthouse> keepers", Description: "Three keepers vanished.", FileName: "keepers.mp4",
+			FileURL: "https://cdn.example.com/keepers.mp4", FileSize: 1024, Duration: 610, ThumbnailURL: "https://cdn.example.com/keepers.jpg",
+			Tags: []string{"mystery", "true crime"}, CreatedAt: created,
+		},
+		{
+			ID: "video-2", Title: "Untitled", FileName: "manor.mp4", FileURL: "https://cdn.example.com/manor.mp4", CreatedAt: created,
+			Episode: models.VideoEpisode{SeriesID: "series-1", Season: 1, Number: 2, Title: "Cold Cases S1E2"},
+			Rights:  models.VideoRights{Owner: "Archive Co", LicenseType: models.LicenseExclusive},
+		},
+	}
+}
+
+func writeBulk(t *testing.T, format string) []byte {
+	var buf bytes.Buffer
+	w, err := NewBulkWriter(format, "Video library", &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Begin())
+	for _, video := range bulkVideos() {
+		require.NoError(t, w.Write(video))
+	}
+	require.NoError(t, w.End())
+	return buf.Bytes()
+}
+
+func TestBulkWriter_YouTubeCSV(t *testing.T) {
+	rows, err := csv.NewReader(bytes.NewReader(writeBulk(t, BulkYouTubeCSV))).ReadAll()
+	require.NoError(t, err)
+
+	require.Len(t, rows, 3)
+	assert.Equal(t, youTubeBulkHeader, rows[0])
+	assert.Equal(t, []string{"keepers.mp4", "video-1", "The lighthouse keepers", "Three keepers vanished.", "mystery|true crime", "private", "https://cdn.example.com/keepers.jpg"}, rows[1],
+		"rows hold what YouTube accepts")
+	assert.Equal(t, "Cold Cases S1E2", rows[2][2], "episodes go out under their series title")
+}
+
+func TestBulkWriter_MRSS(t *testing.T) {
+	var feed struct {
+		Title string `xml:"channel>title"`
+		Items []struct {
+			Title   string `xml:"title"`
+			GUID    string `xml:"guid"`
+			PubDate string `xml:"pubDate"`
+			Content struct {
+				URL      string `xml:"url,attr"`
+				Duration int    `xml:"duration,attr"`
+			} `xml:"http://search.yahoo.com/mrss/ content"`
+			Thumbnail *struct {
+				URL string `xml:"url,attr"`
+			} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+			Keywords string `xml:"http://search.yahoo.com/mrss/ keywords"`
+		} `xml:"channel>item"`
+	}
+	require.NoError(t, xml.Unmarshal(writeBulk(t, BulkMRSS), &feed))
+
+	assert.Equal(t, "Video library", feed.Title)
+	require.Len(t, feed.Items, 2)
+	assert.Equal(t, "video-1", feed.Items[0].GUID)
+	assert.Equal(t, "Thu, 01 Oct 2026 09:30:00 +0000", feed.Items[0].PubDate)
+	assert.Equal(t, "https://cdn.example.com/keepers.mp4", feed.Items[0].Content.URL)
+	assert.Equal(t, 610, feed.Items[0].Content.Duration)
+	assert.Equal(t, "mystery, true crime", feed.Items[0].Keywords)
+	require.NotNil(t, feed.Items[0].Thumbnail)
+	assert.Nil(t, feed.Items[1].Thumbnail)
+}
+
+func TestBulkWriter_CMSXML(t *testing.T) {
+	var manifest struct {
+		Videos []struct {
+			ID     string   `xml:"id,attr"`
+			Title  string   `xml:"title"`
+			Tags   []string `xml:"tags>tag"`
+			Series *struct {
+				ID     string `xml:"id,attr"`
+				Number int    `xml:"episode,attr"`
+			} `xml:"series"`
+			License string `xml:"rights>license"`
+		} `xml:"video"`
+	}
+	require.NoError(t, xml.Unmarshal(writeBulk(t, BulkCMSXML), &manifest))
+
+	require.Len(t, manifest.Videos, 2)
+	assert.Equal(t, "The <lighthouse> keepers", manifest.Videos[0].Title, "the manifest keeps the fields as they are")
+	assert.Equal(t, []string{"mystery", "true crime"}, manifest.Videos[0].Tags)
+	assert.Nil(t, manifest.Videos[0].Series)
+	require.NotNil(t, manifest.Videos[1].Series)
+	assert.Equal(t, 2, manifest.Videos[1].Series.Number)
+	assert.Equal(t, models.LicenseExclusive, manifest.Videos[1].License)
+}
+
+func TestNewBulkWriter_UnknownFormat(t *testing.T) {
+	_, err := NewBulkWriter("excel", "", &bytes.Buffer{})
+	assert.ErrorIs(t, err, models.ErrInvalidInput)
+}
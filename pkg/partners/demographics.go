@@ -0,0 +1,255 @@
+package partners
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// ErrDemographicsUnsupported is returned for platforms whose audience
+// payload has no mapper
+var ErrDemographicsUnsupported = errors.New("demographics not supported")
+
+// DemographicsClient is implemented by the clients of platforms that report
+// the audience of a video, in the platform's own shape
+type DemographicsClient interface {
+	Client
+	// FetchDemographics returns the payload NormalizeDemographics reads for
+	// the client's platform
+	FetchDemographics(ctx context.Context, video *models.Video) (json.RawMessage, error)
+}
+
+// demographicsMappers read the audience payload of each platform
+var demographicsMappers = map[models.Platform]func([]byte) (*models.Demographics, error){
+	models.PlatformYouTube:   mapYouTubeDemographics,
+	models.PlatformTikTok:    mapTikTokDemographics,
+	models.PlatformInstagram: mapGraphDemographics,
+	models.PlatformFacebook:  mapGraphDemographics,
+}
+
+// NormalizeDemographics maps a platform's audience payload to the shares of
+// models.Demographics
+func NormalizeDemographics(platform string, raw []byte) (*models.Demographics, error) {
+	mapper, ok := demographicsMappers[models.Platform(platform)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDemographicsUnsupported, platform)
+	}
+	demographics, err := mapper(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s demographics: %w", platform, err)
+	}
+	return demographics, nil
+}
+
+// youtubeDemographics holds the Analytics API reports of a video's audience:
+// viewerPercentage by ageGroup and gender, and views by country
+type youtubeDemographics struct {
+	AgeGender youtubeReport `json:"age_gender"`
+	Countries youtubeReport `json:"countries"`
+}
+
+// youtubeReport is the part of an Analytics API result table mappers read
+type youtubeReport struct {
+	ColumnHeaders []struct {
+		Name string `json:"name"`
+	} `json:"columnHeaders"`
+	Rows [][]interface{} `json:"rows"`
+}
+
+// column returns the index of a column, or -1
+func (r *youtubeReport) column(name string) int {
+	for i, header := range r.ColumnHeaders {
+		if header.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func mapYouTubeDemographics(raw []byte) (*models.Demographics, error) {
+	var payload youtubeDemographics
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	d := &models.Demographics{}
+
+	age, gender, percent := payload.AgeGender.column("ageGroup"), payload.AgeGender.column("gender"), payload.AgeGender.column("viewerPercentage")
+	if len(payload.AgeGender.Rows) > 0 && (age < 0 || gender < 0 || percent < 0) {
+		return nil, fmt.Errorf("age and gender report lacks ageGroup, gender or viewerPercentage")
+	}
+	for _, row := range payload.AgeGender.Rows {
+		if len(row) <= max(age, gender, percent) {
+			continue
+		}
+		label, _ := row[age].(string)
+		sex, _ := row[gender].(string)
+		value, _ := row[percent].(float64)
+		// Percentages of all viewers, split by age and gender
+		if bucket, ok := ageBucket(label); ok {
+			addShare(&d.Age, bucket, value/100)
+		}
+		addShare(&d.Gender, normalizeGender(sex), value/100)
+	}
+
+	country, views := payload.Countries.column("country"), payload.Countries.column("views")
+	if len(payload.Countries.Rows) > 0 && (country < 0 || views < 0) {
+		return nil, fmt.Errorf("country report lacks country or views")
+	}
+	counts := map[string]float64{}
+	for _, row := range payload.Countries.Rows {
+		if len(row) <= max(country, views) {
+			continue
+		}
+		code, _ := row[country].(string)
+		value, _ := row[views].(float64)
+		counts[strings.ToUpper(code)] += value
+	}
+	d.Countries = shares(counts)
+	return d, nil
+}
+
+// tiktokDemographics is the audience of a video in TikTok video insights,
+// with percentages as fractions
+type tiktokDemographics struct {
+	AudienceAges []struct {
+		Age        string  `json:"age"`
+		Percentage float64 `json:"percentage"`
+	} `json:"audience_ages"`
+	AudienceGenders []struct {
+		Gender     string  `json:"gender"`
+		Percentage float64 `json:"percentage"`
+	} `json:"audience_genders"`
+	AudienceCountries []struct {
+		Country    string  `json:"country"`
+		Percentage float64 `json:"percentage"`
+	} `json:"audience_countries"`
+}
+
+func mapTikTokDemographics(raw []byte) (*models.Demographics, error) {
+	var payload tiktokDemographics
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	d := &models.Demographics{}
+	for _, a := range payload.AudienceAges {
+		if bucket, ok := ageBucket(a.Age); ok {
+			addShare(&d.Age, bucket, a.Percentage)
+		}
+	}
+	for _, g := range payload.AudienceGenders {
+		addShare(&d.Gender, normalizeGender(g.Gender), g.Percentage)
+	}
+	for _, c := range payload.AudienceCountries {
+		addShare(&d.Countries, strings.ToUpper(c.Country), c.Percentage)
+	}
+	return d, nil
+}
+
+// graphInsights are Graph API insights of Instagram and Facebook, whose
+// audience metrics count viewers: audience_gender_age by "F.18-24" keys and
+// audience_country by country code
+type graphInsights struct {
+	Data []struct {
+		Name   string `json:"name"`
+		Values []struct {
+			Value map[string]float64 `json:"value"`
+		} `json:"values"`
+	} `json:"data"`
+}
+
+func mapGraphDemographics(raw []byte) (*models.Demographics, error) {
+	var payload graphInsights
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	ages, genders, countries := map[string]float64{}, map[string]float64{}, map[string]float64{}
+	for _, metric := range payload.Data {
+		if len(metric.Values) == 0 {
+			continue
+		}
+		// The last value is the most recent period
+		counts := metric.Values[len(metric.Values)-1].Value
+		switch metric.Name {
+		case "audience_gender_age":
+			for key, count := range counts {
+				sex, label, ok := strings.Cut(key, ".")
+				if !ok {
+					return nil, fmt.Errorf("unexpected audience_gender_age key %q", key)
+				}
+				genders[normalizeGender(sex)] += count
+				if bucket, ok := ageBucket(label); ok {
+					ages[bucket] += count
+				}
+			}
+		case "audience_country":
+			for code, count := range counts {
+				countries[strings.ToUpper(code)] += count
+			}
+		}
+	}
+	return &models.Demographics{Age: shares(ages), Gender: shares(genders), Countries: shares(countries)}, nil
+}
+
+// ageBucket returns the normalized bucket of a platform's age range from its
+// lower bound, e.g. "age25-34", "25-34" or "65+"
+func ageBucket(label string) (string, bool) {
+	start := strings.IndexFunc(label, unicode.IsDigit)
+	if start < 0 {
+		return "", false
+	}
+	end := start
+	for end < len(label) && unicode.IsDigit(rune(label[end])) {
+		end++
+	}
+	low, err := strconv.Atoi(label[start:end])
+	if err != nil || low < 13 {
+		return "", false
+	}
+	for i := len(models.AgeBuckets) - 1; i >= 0; i-- {
+		bucketLow, _ := strconv.Atoi(strings.TrimRight(strings.SplitN(models.AgeBuckets[i], "-", 2)[0], "+"))
+		if low >= bucketLow {
+			return models.AgeBuckets[i], true
+		}
+	}
+	return "", false
+}
+
+// normalizeGender maps platform genders to female, male or other
+func normalizeGender(gender string) string {
+	switch strings.ToLower(strings.TrimSpace(gender)) {
+	case "f", "female":
+		return models.GenderFemale
+	case "m", "male":
+		return models.GenderMale
+	}
+	return models.GenderOther
+}
+
+func addShare(into *map[string]float64, key string, share float64) {
+	if *into == nil {
+		*into = make(map[string]float64)
+	}
+	(*into)[key] += share
+}
+
+// shares turns counts into fractions of their total, nil without any
+func shares(counts map[string]float64) map[string]float64 {
+	var total float64
+	for _, count := range counts {
+		total += count
+	}
+	if total <= 0 {
+		return nil
+	}
+	out := make(map[string]float64, len(counts))
+	for key, count := range counts {
+		out[key] = count / total
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package partners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDemographics(t *testing.T) {
+	read := func(platform string) []byte {
+		raw, err := os.ReadFile(filepath.Join("testdata", "demographics", platform+".json"))
+		require.NoError(t, err)
+		return raw
+	}
+
+	youtube, err := NormalizeDemographics("youtube", read("youtube"))
+	require.NoError(t, err)
+	assert.InDeltaMapValues(t, map[string]float64{"18-24": 0.45, "25-34": 0.4, "55+": 0.15}, youtube.Age, 1e-9, "55-64 and 65+ fold into 55+")
+	assert.InDeltaMapValues(t, map[string]float64{"female": 0.355, "male": 0.555, "other": 0.09}, youtube.Gender, 1e-9)
+	assert.InDeltaMapValues(t, map[string]float64{"US": 0.6, "FR": 0.3, "DE": 0.1}, youtube.Countries, 1e-9)
+
+	tiktok, err := NormalizeDemographics("tiktok", read("tiktok"))
+	require.NoError(t, err)
+	assert.Equal(t, 0.52, tiktok.Age["18-24"])
+	assert.Equal(t, 0.02, tiktok.Age["55+"])
+	assert.Equal(t, map[string]float64{"female": 0.58, "male": 0.4, "other": 0.02}, tiktok.Gender)
+	assert.Equal(t, map[string]float64{"US": 0.45, "GB": 0.15}, tiktok.Countries, "small countries are left out")
+
+	instagram, err := NormalizeDemographics("instagram", read("instagram"))
+	require.NoError(t, err)
+	assert.InDeltaMapValues(t, map[string]float64{"13-17": 0.01, "18-24": 0.49, "25-34": 0.3, "55+": 0.2}, instagram.Age, 1e-9)
+	assert.InDeltaMapValues(t, map[string]float64{"female": 0.3, "male": 0.5, "other": 0.2}, instagram.Gender, 1e-9)
+	assert.InDeltaMapValues(t, map[string]float64{"US": 0.75, "BR": 0.25}, instagram.Countries, 1e-9)
+
+	_, err = NormalizeDemographics("twitter", []byte(`{}`))
+	assert.ErrorIs(t, err, ErrDemographicsUnsupported)
+	_, err = NormalizeDemographics("youtube", []byte(`{"age_gender":{"columnHeaders":[{"name":"day"}],"rows":[["2026-03-02"]]}}`))
+	assert.Error(t, err)
+	_, err = NormalizeDemographics("instagram", []byte(`{"data":[{"name":"audience_gender_age","values":[{"value":{"18-24":3}}]}]}`))
+	assert.Error(t, err)
+}
+
+func TestAgeBucket(t *testing.T) {
+	for label, want := range map[string]string{"age13-17": "13-17", "18-24": "18-24", "age45-54": "45-54", "55-64": "55+", "age65-": "55+", "65+": "55+"} {
+		got, ok := ageBucket(label)
+		assert.True(t, ok, label)
+		assert.Equal(t, want, got, label)
+	}
+	for _, label := range []string{"unknown", "0-12"} {
+		_, ok := ageBucket(label)
+		assert.False(t, ok, label)
+	}
+}
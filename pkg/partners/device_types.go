@@ -0,0 +1,78 @@
+package partners
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// ErrDeviceTypesUnsupported is returned for platforms whose device type
+// payload has no mapper
+var ErrDeviceTypesUnsupported = errors.New("device types not supported")
+
+// DeviceTypesClient is implemented by the clients of platforms that report
+// which devices a video is watched on, in the platform's own shape
+type DeviceTypesClient interface {
+	Client
+	// FetchDeviceTypes returns the payload NormalizeDeviceTypes reads for the
+	// client's platform
+	FetchDeviceTypes(ctx context.Context, video *models.Video) (json.RawMessage, error)
+}
+
+// deviceTypeMappers read the device type payload of each platform
+var deviceTypeMappers = map[models.Platform]func([]byte) (map[string]float64, error){
+	models.PlatformYouTube: mapYouTubeDeviceTypes,
+}
+
+// NormalizeDeviceTypes maps a platform's device type payload to shares of
+// views by models.DeviceTypes
+func NormalizeDeviceTypes(platform string, raw []byte) (map[string]float64, error) {
+	mapper, ok := deviceTypeMappers[models.Platform(platform)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDeviceTypesUnsupported, platform)
+	}
+	devices, err := mapper(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s device types: %w", platform, err)
+	}
+	return devices, nil
+}
+
+// youtubeDeviceTypes maps the deviceType values of the Analytics API; those
+// missing, UNKNOWN_PLATFORM included, are other
+var youtubeDeviceTypes = map[string]string{
+	"MOBILE":       models.DeviceMobile,
+	"DESKTOP":      models.DeviceDesktop,
+	"TABLET":       models.DeviceTablet,
+	"TV":           models.DeviceTV,
+	"GAME_CONSOLE": models.DeviceConsole,
+}
+
+// mapYouTubeDeviceTypes reads an Analytics API report of views by deviceType
+func mapYouTubeDeviceTypes(raw []byte) (map[string]float64, error) {
+	var report youtubeReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return nil, err
+	}
+	device, views := report.column("deviceType"), report.column("views")
+	if len(report.Rows) > 0 && (device < 0 || views < 0) {
+		return nil, fmt.Errorf("device type report lacks deviceType or views")
+	}
+	counts := map[string]float64{}
+	for _, row := range report.Rows {
+		if len(row) <= max(device, views) {
+			continue
+		}
+		name, _ := row[device].(string)
+		value, _ := row[views].(float64)
+		normalized, ok := youtubeDeviceTypes[name]
+		if !ok {
+			normalized = models.DeviceOther
+		}
+		counts[normalized] += value
+	}
+	return shares(counts), nil
+}
@@ -0,0 +1,24 @@
+package partners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDeviceTypes(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "fixtures", "youtube", "reports_device_types.json"))
+	require.NoError(t, err)
+
+	youtube, err := NormalizeDeviceTypes("youtube", raw)
+	require.NoError(t, err)
+	assert.InDeltaMapValues(t, map[string]float64{"mobile": 0.65, "desktop": 0.2, "tv": 0.08, "tablet": 0.05, "console": 0.01, "other": 0.01}, youtube, 1e-9)
+
+	_, err = NormalizeDeviceTypes("tiktok", []byte(`{}`))
+	assert.ErrorIs(t, err, ErrDeviceTypesUnsupported)
+	_, err = NormalizeDeviceTypes("youtube", []byte(`{"columnHeaders":[{"name":"day"}],"rows":[["2026-03-02"]]}`))
+	assert.Error(t, err)
+}
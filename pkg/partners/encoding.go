@@ -0,0 +1,59 @@
+package partners
+
+import (
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// EncodingLimits are what a platform accepts of the files uploaded to it;
+// zero is no limit
+type EncodingLimits struct {
+	VideoCodecs         []string `json:"video_codecs"`
+	AudioCodecs         []string `json:"audio_codecs"`
+	MaxLongSide         int      `json:"max_long_side"` // In pixels
+	MaxVideoBitrateKbps int      `json:"max_video_bitrate_kbps,omitempty"`
+	MaxAudioBitrateKbps int      `json:"max_audio_bitrate_kbps,omitempty"`
+	MinFrameRate        int      `json:"min_frame_rate,omitempty"`
+	MaxFrameRate        int      `json:"max_frame_rate"`
+}
+
+// PlatformEncodingLimits holds the documented upload limits of each platform
+var PlatformEncodingLimits = map[models.Platform]EncodingLimits{
+	models.PlatformYouTube: {VideoCodecs: []string{transcode.CodecH264, transcode.CodecHEVC, transcode.CodecVP9},
+		AudioCodecs: []string{transcode.CodecAAC, transcode.CodecOpus}, MaxLongSide: 7680, MaxVideoBitrateKbps: 85000, MaxFrameRate: 60},
+	models.PlatformTikTok: {VideoCodecs: []string{transcode.CodecH264, transcode.CodecHEVC},
+		AudioCodecs: []string{transcode.CodecAAC}, MaxLongSide: 4096, MinFrameRate: 23, MaxFrameRate: 60},
+	models.PlatformInstagram: {VideoCodecs: []string{transcode.CodecH264, transcode.CodecHEVC},
+		AudioCodecs: []string{transcode.CodecAAC}, MaxLongSide: 1920, MaxVideoBitrateKbps: 25000, MaxAudioBitrateKbps: 128, MinFrameRate: 23, MaxFrameRate: 60},
+	models.PlatformFacebook: {VideoCodecs: []string{transcode.CodecH264, transcode.CodecHEVC},
+		AudioCodecs: []string{transcode.CodecAAC}, MaxLongSide: 4096, MaxFrameRate: 60},
+	models.PlatformTwitter: {VideoCodecs: []string{transcode.CodecH264},
+		AudioCodecs: []string{transcode.CodecAAC}, MaxLongSide: 1920, MaxVideoBitrateKbps: 25000, MaxFrameRate: 60},
+	models.PlatformLinkedIn: {VideoCodecs: []string{transcode.CodecH264},
+		AudioCodecs: []string{transcode.CodecAAC}, MaxLongSide: 4096, MinFrameRate: 10, MaxFrameRate: 60},
+	models.PlatformSnapchat: {VideoCodecs: []string{transcode.CodecH264},
+		AudioCodecs: []string{transcode.CodecAAC}, MaxLongSide: 1920, MaxFrameRate: 60},
+}
+
+// ProfilePlatforms returns the platforms taking the rendition profile
+func ProfilePlatforms(profile string) []models.Platform {
+	var platforms []models.Platform
+	for _, platform := range models.Platforms {
+		if PlatformCapabilities[platform].Rendition == profile {
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms
+}
+
+// MultiAudio reports whether the renditions of the profile carry the dubbed
+// audio tracks of their video, which they do when a platform taking it
+// offers them. The others play the original track, first in the file.
+func MultiAudio(profile string) bool {
+	for _, platform := range ProfilePlatforms(profile) {
+		if PlatformCapabilities[platform].MultiAudio {
+			return true
+		}
+	}
+	return false
+}
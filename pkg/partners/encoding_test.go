@@ -0,0 +1,42 @@
+package partners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+func TestPlatformEncodingLimits_AcceptDefaultProfiles(t *testing.T) {
+	for _, platform := range models.Platforms {
+		limits, ok := PlatformEncodingLimits[platform]
+		if !assert.True(t, ok, "%s has no encoding limits", platform) {
+			continue
+		}
+		profile := transcode.Profiles[PlatformCapabilities[platform].Rendition]
+		assert.Contains(t, limits.VideoCodecs, profile.VideoCodec, platform)
+		assert.Contains(t, limits.AudioCodecs, profile.AudioCodec, platform)
+		assert.LessOrEqual(t, max(profile.Width, profile.Height), limits.MaxLongSide, platform)
+		assert.LessOrEqual(t, profile.FrameRate, limits.MaxFrameRate, platform)
+		assert.GreaterOrEqual(t, profile.FrameRate, limits.MinFrameRate, platform)
+		if limits.MaxVideoBitrateKbps > 0 {
+			assert.LessOrEqual(t, profile.VideoBitrateKbps, limits.MaxVideoBitrateKbps, platform)
+		}
+		if limits.MaxAudioBitrateKbps > 0 {
+			assert.LessOrEqual(t, profile.AudioBitrateKbps, limits.MaxAudioBitrateKbps, platform)
+		}
+	}
+}
+
+func TestProfilePlatforms(t *testing.T) {
+	assert.Equal(t, []models.Platform{models.PlatformTwitter}, ProfilePlatforms(transcode.Landscape720p))
+	assert.Empty(t, ProfilePlatforms("square_1080p"))
+}
+
+func TestMultiAudio(t *testing.T) {
+	assert.True(t, MultiAudio(transcode.Landscape1080p))
+	assert.False(t, MultiAudio(transcode.Vertical1080p))
+	assert.False(t, MultiAudio("square_1080p"))
+}
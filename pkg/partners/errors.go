@@ -0,0 +1,39 @@
+package partners
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidToken is returned when a platform rejects the workspace credentials
+	ErrInvalidToken = errors.New("platform rejected the access token")
+	// ErrQuotaExceeded is returned when a platform refuses a call because of quota or rate limits
+	ErrQuotaExceeded = errors.New("platform quota exceeded")
+	// ErrInvalidMetadata is returned when a platform refuses the title,
+	// description, tags or settings a video is published with
+	ErrInvalidMetadata = errors.New("platform rejected the video metadata")
+	// ErrUnpublishUnsupported is returned when a platform's API cannot take a
+	// published video down, so it has to be removed by hand
+	ErrUnpublishUnsupported = errors.New("platform API cannot take videos down")
+)
+
+// APIError is an error response returned by a platform API
+type APIError struct {
+	Platform   string
+	StatusCode int
+	Code       string // Platform-specific error code or reason
+	Message    string
+	kind       error
+}
+
+// Error formats the platform error
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error (status %d, code %s): %s", e.Platform, e.StatusCode, e.Code, e.Message)
+}
+
+// Unwrap returns ErrInvalidToken, ErrQuotaExceeded or ErrInvalidMetadata when
+// the error is one of those
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
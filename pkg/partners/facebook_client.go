@@ -1,6 +1,7 @@
 package partners
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/huandu/facebook/v2"
@@ -9,10 +10,14 @@ import (
 )
 
 type facebookClient struct {
-	session *facebook.Session
+	session  *facebook.Session
+	appID    string
+	appToken string // Authenticates calls made for the app rather than the page
 }
 
-func (c *facebookClient) Authenticate(ws *models.Workspace) error {
+var _ WebhookSubscriber = (*facebookClient)(nil)
+
+func (c *facebookClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
 	appID := os.Getenv("FB_APP_ID")
 	appSecret := os.Getenv("FB_APP_SECRET")
 	token := ws.FacebookPageToken
@@ -22,13 +27,19 @@ func (c *facebookClient) Authenticate(ws *models.Workspace) error {
 	app := facebook.New(appID, appSecret)
 	session := app.Session(token)
 	c.session = session
+	c.appID = appID
+	c.appToken = app.AppAccessToken()
 	return nil
 }
 
-func (c *facebookClient) Upload(video *models.Video) (string, error) {
-	res, err := c.session.Post("/me/videos", facebook.Params{
+func (c *facebookClient) Upload(ctx context.Context, video *models.Video) (string, error) {
+	meta, _, err := RenderMetadata(models.PlatformFacebook, video)
+	if err != nil {
+		return "", err
+	}
+	res, err := c.session.WithContext(ctx).Post("/me/videos", facebook.Params{
 		"file_url":    video.FileURL,
-		"description": video.Description,
+		"description": meta.Description,
 	})
 	if err != nil {
 		return "", err
@@ -37,13 +48,49 @@ func (c *facebookClient) Upload(video *models.Video) (string, error) {
 	return id, nil
 }
 
-func (c *facebookClient) Publish(video *models.Video, ws *models.Workspace) error {
-	_, err := c.session.Post(fmt.Sprintf("/%s/feed", ws.FacebookPageID), facebook.Params{
+func (c *facebookClient) Publish(ctx context.Context, video *models.Video, ws *models.Workspace) error {
+	_, err := c.session.WithContext(ctx).Post(fmt.Sprintf("/%s/feed", ws.FacebookPageID), facebook.Params{
 		"message": fmt.Sprintf("Nouvelle vidéo : https://facebook.com/%s/videos/%s", ws.FacebookPageID, video.FacebookID),
 	})
 	return err
 }
 
-func (c *facebookClient) FetchStats(video *models.Video) (*models.VideoStats, error) {
+// Unpublish deletes the video, which removes it from the posts sharing it
+func (c *facebookClient) Unpublish(ctx context.Context, video *models.Video) error {
+	_, err := c.session.WithContext(ctx).Delete("/"+video.FacebookID, nil)
+	return err
+}
+
+func (c *facebookClient) FetchStats(ctx context.Context, video *models.Video) (*models.VideoStats, error) {
 	return nil, fmt.Errorf("fetch stats not implemented")
 }
+
+// facebookWebhookFields are the page fields Meta pushes changes of
+const facebookWebhookFields = "feed"
+
+// SubscribeWebhook points the app's page webhooks at the callback, then
+// subscribes the app to the workspace's page. Page subscriptions do not
+// expire, and Meta checks the callback before accepting it.
+func (c *facebookClient) SubscribeWebhook(ctx context.Context, ws *models.Workspace, callback WebhookCallback) (*WebhookLease, error) {
+	session := c.session.WithContext(ctx)
+	_, err := session.Post(fmt.Sprintf("/%s/subscriptions", c.appID), facebook.Params{
+		"object":       "page",
+		"callback_url": callback.URL,
+		"fields":       facebookWebhookFields,
+		"verify_token": callback.Secrets.MetaVerifyToken,
+		"access_token": c.appToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := session.Post(fmt.Sprintf("/%s/subscribed_apps", ws.FacebookPageID), facebook.Params{
+		"subscribed_fields": facebookWebhookFields,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ok, _ := res.Get("success").(bool); !ok {
+		return nil, fmt.Errorf("facebook did not subscribe page %s", ws.FacebookPageID)
+	}
+	return &WebhookLease{Topic: ws.FacebookPageID, Verified: true}, nil
+}
@@ -0,0 +1,47 @@
+package partners
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/huandu/facebook/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func newTestFacebookClient(server *fixtureServer) *facebookClient {
+	app := facebook.New("app-1", "app-secret")
+	session := app.Session("page-token")
+	session.BaseURL = server.URL + "/"
+	session.HttpClient = server.Client()
+	return &facebookClient{session: session, appID: "app-1", appToken: app.AppAccessToken()}
+}
+
+func TestFacebookClient_SubscribeWebhook(t *testing.T) {
+	server := newFixtureServer(t,
+		fixture{"POST", "/app-1/subscriptions", 200, "facebook/success.json"},
+		fixture{"POST", "/page-1/subscribed_apps", 200, "facebook/success.json"},
+	)
+	client := newTestFacebookClient(server)
+
+	lease, err := client.SubscribeWebhook(context.Background(), &models.Workspace{FacebookPageID: "page-1"}, WebhookCallback{
+		URL:     "https://api.example.com/webhooks/facebook",
+		Secrets: WebhookSecrets{MetaVerifyToken: "verify-me"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &WebhookLease{Topic: "page-1", Verified: true}, lease)
+
+	requests := server.received()
+	require.Len(t, requests, 2)
+	app, err := url.ParseQuery(string(requests[0].body))
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/webhooks/facebook", app.Get("callback_url"))
+	assert.Equal(t, "verify-me", app.Get("verify_token"))
+	assert.Equal(t, "app-1|app-secret", app.Get("access_token"), "the app subscription is made with the app token")
+	page, err := url.ParseQuery(string(requests[1].body))
+	require.NoError(t, err)
+	assert.Equal(t, "page-token", page.Get("access_token"))
+}
@@ -0,0 +1,87 @@
+package partners
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// fixture is a recorded platform response served for one route. Response
+// bodies live in testdata/fixtures/<platform>/ and are captured from the
+// real APIs with identifiers and tokens replaced.
+type fixture struct {
+	method string
+	path   string
+	status int
+	file   string // Relative to testdata/fixtures
+}
+
+// recordedRequest is a request received by the fixture server
+type recordedRequest struct {
+	method string
+	path   string
+	query  map[string][]string
+	header http.Header
+	body   []byte
+}
+
+// fixtureServer serves recorded responses and records the requests it receives
+type fixtureServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+// newFixtureServer starts a server answering each method and path with its
+// fixture; unexpected requests fail the test
+func newFixtureServer(t *testing.T, fixtures ...fixture) *fixtureServer {
+	t.Helper()
+
+	bodies := make(map[string][]byte, len(fixtures))
+	for _, f := range fixtures {
+		body, err := os.ReadFile(filepath.Join("testdata", "fixtures", f.file))
+		if err != nil {
+			t.Fatalf("failed to read fixture %s: %v", f.file, err)
+		}
+		bodies[f.file] = body
+	}
+
+	s := &fixtureServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, recordedRequest{
+			method: r.Method,
+			path:   r.URL.Path,
+			query:  r.URL.Query(),
+			header: r.Header.Clone(),
+			body:   body,
+		})
+		s.mu.Unlock()
+
+		for _, f := range fixtures {
+			if f.method == r.Method && f.path == r.URL.Path {
+				w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				w.WriteHeader(f.status)
+				_, _ = w.Write(bodies[f.file])
+				return
+			}
+		}
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// received returns the requests received so far
+func (s *fixtureServer) received() []recordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]recordedRequest(nil), s.requests...)
+}
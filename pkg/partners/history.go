@@ -0,0 +1,37 @@
+package partners
+
+import (
+	"context"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// DailyStats are the stats a video gained on one day, in UTC
+type DailyStats struct {
+	Day      time.Time
+	Views    int64
+	Likes    int64
+	Comments int64
+	Shares   int64
+}
+
+// HistoryClient is implemented by the clients of platforms that report past
+// stats day by day, which lets a backfill rebuild a video's history
+type HistoryClient interface {
+	Client
+	// FetchDailyStats returns the stats the video gained on each day of
+	// [from, to), oldest first. Days without activity may be left out, and
+	// platforms may not report the last few days yet.
+	FetchDailyStats(ctx context.Context, video *models.Video, from, to time.Time) ([]*DailyStats, error)
+}
+
+// SupportsHistory reports whether the platform's client can fetch past daily stats
+func SupportsHistory(platform string) bool {
+	client, err := New(platform)
+	if err != nil {
+		return false
+	}
+	_, ok := client.(HistoryClient)
+	return ok
+}
@@ -1,65 +1,119 @@
 package partners
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
 )
 
+// instagramGraphURL is the Graph API version used for Instagram publishing
+const instagramGraphURL = "https://graph.facebook.com/v17.0"
+
 type instagramClient struct {
 	httpClient  *http.Client
+	baseURL     string
 	userID      string
 	accessToken string
 }
 
-func (c *instagramClient) Authenticate(ws *models.Workspace) error {
+var _ Client = (*instagramClient)(nil)
+
+func (c *instagramClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
 	if ws.InstagramUserID == "" || ws.InstagramAccessToken == "" {
 		return fmt.Errorf("Instagram credentials missing in workspace")
 	}
 	c.userID = ws.InstagramUserID
 	c.accessToken = ws.InstagramAccessToken
-	c.httpClient = http.DefaultClient
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.baseURL == "" {
+		c.baseURL = instagramGraphURL
+	}
 	return nil
 }
 
-func (c *instagramClient) Upload(video *models.Video) (string, error) {
-	url := fmt.Sprintf("https://graph.facebook.com/v17.0/%s/media?image_url=%s&caption=%s&access_token=%s",
-		c.userID, video.FileURL, video.Description, c.accessToken)
-	resp, err := c.httpClient.Post(url, "application/json", nil)
+func (c *instagramClient) Upload(ctx context.Context, video *models.Video) (string, error) {
+	meta, _, err := RenderMetadata(models.PlatformInstagram, video)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
+	return c.post(ctx, "media", url.Values{
+		"image_url": {video.FileURL},
+		"caption":   {meta.Description},
+	})
+}
 
-	var out struct {
-		ID string `json:"id"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return "", err
-	}
-	return out.ID, nil
+func (c *instagramClient) Publish(ctx context.Context, video *models.Video, ws *models.Workspace) error {
+	_, err := c.post(ctx, "media_publish", url.Values{"creation_id": {video.InstagramID}})
+	return err
+}
+
+// Unpublish is not supported. The Graph API cannot delete Instagram media.
+func (c *instagramClient) Unpublish(ctx context.Context, video *models.Video) error {
+	return fmt.Errorf("instagram: %w", ErrUnpublishUnsupported)
+}
+
+func (c *instagramClient) FetchStats(ctx context.Context, video *models.Video) (*models.VideoStats, error) {
+	return nil, fmt.Errorf("fetch stats not implemented")
 }
 
-func (c *instagramClient) Publish(video *models.Video, ws *models.Workspace) error {
-	url := fmt.Sprintf("https://graph.facebook.com/v17.0/%s/media_publish?creation_id=%s&access_token=%s",
-		c.userID, video.InstagramID, c.accessToken)
-	resp, err := c.httpClient.Post(url, "application/json", nil)
+// post calls a Graph API edge of the Instagram user and returns the created object ID
+func (c *instagramClient) post(ctx context.Context, edge string, params url.Values) (string, error) {
+	params.Set("access_token", c.accessToken)
+	endpoint := fmt.Sprintf("%s/%s/%s?%s", c.baseURL, c.userID, edge, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
 	if err != nil {
-		return err
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	var out struct {
-		ID string `json:"id"`
+		ID    string `json:"id"`
+		Error *struct {
+			Message      string `json:"message"`
+			Type         string `json:"type"`
+			Code         int    `json:"code"`
+			ErrorSubcode int    `json:"error_subcode"`
+		} `json:"error"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return err
+		return "", fmt.Errorf("failed to decode Instagram response (status %d): %w", resp.StatusCode, err)
 	}
-	return nil
+	if out.Error != nil || resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{Platform: "instagram", StatusCode: resp.StatusCode}
+		if out.Error != nil {
+			apiErr.Code = strconv.Itoa(out.Error.Code)
+			apiErr.Message = out.Error.Message
+			apiErr.kind = instagramErrorKind(out.Error.Code, out.Error.ErrorSubcode)
+		}
+		return "", apiErr
+	}
+	return out.ID, nil
 }
 
-func (c *instagramClient) FetchStats(video *models.Video) (*models.VideoStats, error) {
-	return nil, fmt.Errorf("fetch stats not implemented")
+// instagramErrorKind classifies Graph API error codes
+func instagramErrorKind(code, subcode int) error {
+	switch {
+	case code == 190:
+		return ErrInvalidToken
+	case code == 4, code == 17, code == 32, code == 613, subcode == 2207042:
+		// App, user and page rate limits, and the daily publishing limit
+		return ErrQuotaExceeded
+	case code == 100:
+		// Invalid parameter, such as a caption over 2,200 characters
+		return ErrInvalidMetadata
+	default:
+		return nil
+	}
 }
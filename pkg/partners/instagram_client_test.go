@@ -0,0 +1,80 @@
+package partners
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+const (
+	instagramMediaPath   = "/17841400000000000/media"
+	instagramPublishPath = "/17841400000000000/media_publish"
+)
+
+func newTestInstagramClient(t *testing.T, server *fixtureServer) *instagramClient {
+	client := &instagramClient{httpClient: server.Client(), baseURL: server.URL}
+	require.NoError(t, client.Authenticate(context.Background(), &models.Workspace{
+		InstagramUserID:      "17841400000000000",
+		InstagramAccessToken: "EAAtest-token",
+	}))
+	return client
+}
+
+func TestInstagramClient_UploadAndPublish(t *testing.T) {
+	server := newFixtureServer(t,
+		fixture{"POST", instagramMediaPath, 200, "instagram/media.json"},
+		fixture{"POST", instagramPublishPath, 200, "instagram/media_publish.json"},
+	)
+	client := newTestInstagramClient(t, server)
+	video := &models.Video{Description: "Three keepers & one locked door #mystery", FileURL: "https://cdn.example.com/demo-01.mp4"}
+
+	containerID, err := client.Upload(context.Background(), video)
+	require.NoError(t, err)
+	assert.Equal(t, "17889455560051444", containerID)
+
+	video.InstagramID = containerID
+	require.NoError(t, client.Publish(context.Background(), video, &models.Workspace{}))
+
+	requests := server.received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, video.Description, requests[0].query["caption"][0], "the caption is URL-encoded")
+	assert.Equal(t, "EAAtest-token", requests[0].query["access_token"][0])
+	assert.Equal(t, "17889455560051444", requests[1].query["creation_id"][0])
+}
+
+func TestInstagramClient_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		file   string
+		kind   error
+		code   string
+	}{
+		{"rate limit", 400, "instagram/rate_limit.json", ErrQuotaExceeded, "4"},
+		{"invalid token", 400, "instagram/invalid_token.json", ErrInvalidToken, "190"},
+		{"invalid caption", 400, "instagram/invalid_caption.json", ErrInvalidMetadata, "100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFixtureServer(t,
+				fixture{"POST", instagramMediaPath, tt.status, tt.file},
+				fixture{"POST", instagramPublishPath, tt.status, tt.file},
+			)
+			client := newTestInstagramClient(t, server)
+
+			_, err := client.Upload(context.Background(), &models.Video{FileURL: "https://cdn.example.com/demo.mp4"})
+			assert.ErrorIs(t, err, tt.kind)
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.code, apiErr.Code)
+
+			err = client.Publish(context.Background(), &models.Video{InstagramID: "17889455560051444"}, &models.Workspace{})
+			assert.ErrorIs(t, err, tt.kind)
+		})
+	}
+}
@@ -1,17 +1,47 @@
 package partners
 
 import (
+	"context"
 	"fmt"
+
 	"github.com/jibe0123/mysteryfactory/internal/models"
 )
 
-// Client defines platform client capabilities.
+// Client defines platform client capabilities. Calls stop when their
+// context is cancelled.
 type Client interface {
-	Authenticate(*models.Workspace) error
-	Upload(*models.Video) (string, error)
-	Publish(*models.Video, *models.Workspace) error
+	Authenticate(context.Context, *models.Workspace) error
+	Upload(context.Context, *models.Video) (string, error)
+	Publish(context.Context, *models.Video, *models.Workspace) error
 	// FetchStats retrieves statistics for the provided video from the platform.
-	FetchStats(*models.Video) (*models.VideoStats, error)
+	FetchStats(context.Context, *models.Video) (*models.VideoStats, error)
+	// Unpublish takes the video down as its platform's Capabilities.Takedown
+	// says, failing with ErrUnpublishUnsupported when the API cannot.
+	Unpublish(context.Context, *models.Video) error
+}
+
+// wrapper is implemented by clients decorating another, such as the timed
+// and quota clients
+type wrapper interface {
+	Unwrap() Client
+}
+
+// Capability returns the client, or the first one it decorates, implementing
+// an optional interface such as DemographicsClient. Calls through it skip the
+// decorators above it.
+func Capability[T any](client Client) (T, bool) {
+	for client != nil {
+		if c, ok := client.(T); ok {
+			return c, true
+		}
+		w, ok := client.(wrapper)
+		if !ok {
+			break
+		}
+		client = w.Unwrap()
+	}
+	var none T
+	return none, false
 }
 
 // Factory creates a new client for the specified platform.
@@ -0,0 +1,242 @@
+package partners
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+// Capabilities are the metadata limits of a platform, in characters, and
+// how videos are prepared for it
+type Capabilities struct {
+	// Title is false on platforms whose posts only carry a caption
+	Title          bool `json:"title"`
+	MaxTitle       int  `json:"max_title,omitempty"`
+	MaxDescription int  `json:"max_description"`
+	// Tags is true on platforms taking tags apart from the description
+	Tags           bool `json:"tags"`
+	MaxTagsLength  int  `json:"max_tags_length,omitempty"` // All tags together
+	MaxHashtags    int  `json:"max_hashtags,omitempty"`
+	NoAngleBracket bool `json:"no_angle_brackets,omitempty"` // < and > are rejected
+	// Staging is true on platforms where an upload stays hidden until it is
+	// published, so it can be done ahead of the release
+	Staging bool `json:"staging"`
+	// StagingWindowHours is how long before publishing an upload may be
+	// staged, when the platform discards unpublished uploads
+	StagingWindowHours int `json:"staging_window_hours,omitempty"`
+	// Takedown is what unpublishing does on the platform; empty when the
+	// video has to be removed by hand
+	Takedown Takedown `json:"takedown,omitempty"`
+	// Rendition is the transcoding profile of the videos uploaded to the platform
+	Rendition string `json:"rendition"`
+	// MultiAudio is true on platforms offering viewers the audio tracks of
+	// an upload in each of their languages
+	MultiAudio bool `json:"multi_audio"`
+}
+
+// Takedown is how a video is taken down from a platform
+type Takedown string
+
+const (
+	// TakedownPrivate makes the video private, keeping it and its stats
+	TakedownPrivate Takedown = "private"
+	// TakedownDelete deletes the video from the platform
+	TakedownDelete Takedown = "delete"
+)
+
+// PlatformCapabilities holds the documented limits of each platform
+var PlatformCapabilities = map[models.Platform]Capabilities{
+	models.PlatformYouTube:   {Title: true, MaxTitle: 100, MaxDescription: 5000, Tags: true, MaxTagsLength: 500, NoAngleBracket: true, Staging: true, Takedown: TakedownPrivate, Rendition: transcode.Landscape1080p, MultiAudio: true},
+	models.PlatformTikTok:    {Title: true, MaxTitle: 2200, MaxDescription: 2200, Rendition: transcode.Vertical1080p},
+	models.PlatformInstagram: {MaxDescription: 2200, MaxHashtags: 30, Staging: true, StagingWindowHours: 24, Rendition: transcode.Vertical1080p},
+	models.PlatformFacebook:  {MaxDescription: 63206, Takedown: TakedownDelete, Rendition: transcode.Landscape1080p},
+	models.PlatformTwitter:   {MaxDescription: 280, Rendition: transcode.Landscape720p},
+	models.PlatformLinkedIn:  {MaxDescription: 3000, Rendition: transcode.Landscape1080p},
+	models.PlatformSnapchat:  {MaxDescription: 160, Rendition: transcode.Vertical1080p},
+}
+
+// metadataTemplate renders the title and description sent to a platform
+type metadataTemplate struct {
+	title       *template.Template
+	description *template.Template
+	hashtags    bool // The description carries the tags
+}
+
+var metadataFuncs = template.FuncMap{
+	"hashtags":   hashtags,
+	"paragraphs": paragraphs,
+}
+
+func newMetadataTemplate(title, description string) metadataTemplate {
+	return metadataTemplate{
+		title:       template.Must(template.New("title").Funcs(metadataFuncs).Parse(title)),
+		description: template.Must(template.New("description").Funcs(metadataFuncs).Parse(description)),
+		hashtags:    strings.Contains(description, "hashtags"),
+	}
+}
+
+// metadataTemplates renders the video's fields for each platform. Caption-only
+// platforms get the tags as hashtags after the description.
+var metadataTemplates = map[models.Platform]metadataTemplate{
+	models.PlatformYouTube:   newMetadataTemplate("{{.Title}}", "{{.Description}}"),
+	models.PlatformTikTok:    newMetadataTemplate("{{.Title}}", "{{.Description}}"),
+	models.PlatformInstagram: newMetadataTemplate("", "{{paragraphs .Description (hashtags .Tags)}}"),
+	models.PlatformFacebook:  newMetadataTemplate("", "{{.Description}}"),
+	models.PlatformTwitter:   newMetadataTemplate("", "{{paragraphs .Description (hashtags .Tags)}}"),
+	models.PlatformLinkedIn:  newMetadataTemplate("", "{{paragraphs .Description (hashtags .Tags)}}"),
+	models.PlatformSnapchat:  newMetadataTemplate("", "{{.Description}}"),
+}
+
+// Metadata is what is sent to a platform along with a video
+type Metadata struct {
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// Adjustment is a change made to a video's metadata to fit a platform
+type Adjustment struct {
+	Field   string `json:"field"` // title, description or tags
+	Rule    string `json:"rule"`  // truncated, dropped or removed_characters
+	Message string `json:"message"`
+}
+
+// Adjustment rules
+const (
+	AdjustTruncated         = "truncated"
+	AdjustDropped           = "dropped"
+	AdjustRemovedCharacters = "removed_characters"
+)
+
+// RenderMetadata renders the video's metadata for the platform through its
+// template and fits it to the platform's capabilities, returning the changes
+// that were needed
+func RenderMetadata(platform models.Platform, video *models.Video) (*Metadata, []Adjustment, error) {
+	caps, ok := PlatformCapabilities[platform]
+	tmpl, hasTemplate := metadataTemplates[platform]
+	if !ok || !hasTemplate {
+		return nil, nil, fmt.Errorf("%w: %s", models.ErrInvalidPlatform, platform)
+	}
+
+	adjustments := []Adjustment{}
+	tags := video.GetTags()
+	if tmpl.hashtags && caps.MaxHashtags > 0 && len(tags) > caps.MaxHashtags {
+		adjustments = append(adjustments, Adjustment{"tags", AdjustDropped,
+			fmt.Sprintf("kept the first %d of %d hashtags", caps.MaxHashtags, len(tags))})
+		tags = tags[:caps.MaxHashtags]
+	}
+
+	data := struct {
+		Title       string
+		Description string
+		Tags        []string
+	}{video.PublishTitle(), video.Description, tags}
+	var title, description strings.Builder
+	if err := tmpl.title.Execute(&title, data); err != nil {
+		return nil, nil, fmt.Errorf("failed to render %s title: %w", platform, err)
+	}
+	if err := tmpl.description.Execute(&description, data); err != nil {
+		return nil, nil, fmt.Errorf("failed to render %s description: %w", platform, err)
+	}
+
+	meta := &Metadata{Title: strings.TrimSpace(title.String()), Description: strings.TrimSpace(description.String())}
+	if !caps.Title && video.Title != "" {
+		adjustments = append(adjustments, Adjustment{"title", AdjustDropped,
+			fmt.Sprintf("%s posts have no title", platform)})
+	}
+	if caps.NoAngleBracket {
+		meta.Title = removeAngleBrackets(meta.Title, "title", &adjustments)
+		meta.Description = removeAngleBrackets(meta.Description, "description", &adjustments)
+	}
+	if caps.Title {
+		meta.Title = truncate(meta.Title, caps.MaxTitle, "title", &adjustments)
+	}
+	meta.Description = truncate(meta.Description, caps.MaxDescription, "description", &adjustments)
+
+	if caps.Tags {
+		meta.Tags = fitTags(tags, caps.MaxTagsLength, &adjustments)
+	} else if len(tags) > 0 && !tmpl.hashtags {
+		adjustments = append(adjustments, Adjustment{"tags", AdjustDropped,
+			fmt.Sprintf("%s posts have no tags", platform)})
+	}
+	return meta, adjustments, nil
+}
+
+// truncate shortens s to max characters, ending on a word when one ends in
+// the last fifth, and marks the cut with an ellipsis
+func truncate(s string, max int, field string, adjustments *[]Adjustment) string {
+	length := utf8.RuneCountInString(s)
+	if max <= 0 || length <= max {
+		return s
+	}
+	cut := string([]rune(s)[:max-1]) // Room for the ellipsis
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 && utf8.RuneCountInString(cut[:i]) >= (max-1)*4/5 {
+		cut = cut[:i]
+	}
+	out := strings.TrimRightFunc(cut, unicode.IsSpace) + "…"
+	*adjustments = append(*adjustments, Adjustment{field, AdjustTruncated,
+		fmt.Sprintf("shortened from %d to %d characters", length, utf8.RuneCountInString(out))})
+	return out
+}
+
+// removeAngleBrackets drops the < and > characters
+func removeAngleBrackets(s, field string, adjustments *[]Adjustment) string {
+	out := strings.NewReplacer("<", "", ">", "").Replace(s)
+	if out != s {
+		*adjustments = append(*adjustments, Adjustment{field, AdjustRemovedCharacters,
+			"removed the < and > characters the platform rejects"})
+	}
+	return out
+}
+
+// fitTags keeps the tags that fit in max characters, counted like YouTube:
+// tags are separated by commas and those with spaces are quoted
+func fitTags(tags []string, max int, adjustments *[]Adjustment) []string {
+	var kept []string
+	length := 0
+	for i, tag := range tags {
+		size := utf8.RuneCountInString(tag)
+		if strings.ContainsRune(tag, ' ') {
+			size += 2
+		}
+		if len(kept) > 0 {
+			size++
+		}
+		if max > 0 && length+size > max {
+			*adjustments = append(*adjustments, Adjustment{"tags", AdjustDropped,
+				fmt.Sprintf("kept %d of %d tags to stay within %d characters", i, len(tags), max)})
+			break
+		}
+		kept = append(kept, tag)
+		length += size
+	}
+	return kept
+}
+
+// hashtags writes the tags as hashtags, without their spaces
+func hashtags(tags []string) string {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(strings.TrimPrefix(tag, "#")), "")
+		if tag != "" {
+			out = append(out, "#"+tag)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// paragraphs joins the non-empty parts with blank lines
+func paragraphs(parts ...string) string {
+	var out []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
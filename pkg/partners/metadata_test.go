@@ -0,0 +1,79 @@
+package partners
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/transcode"
+)
+
+func TestRenderMetadata_YouTubeFitsLimits(t *testing.T) {
+	video := &models.Video{
+		Title:       "The <Unsolved> Case of the Lighthouse Keepers Who Vanished From Flannan Isles Without a Trace in December 1900",
+		Description: "Three keepers, one locked door.",
+		Tags:        []string{strings.Repeat("a", 300), "flannan isles", strings.Repeat("b", 200)},
+	}
+
+	meta, adjustments, err := RenderMetadata(models.PlatformYouTube, video)
+	require.NoError(t, err)
+
+	assert.LessOrEqual(t, utf8.RuneCountInString(meta.Title), 100)
+	assert.True(t, strings.HasSuffix(meta.Title, "…"))
+	assert.True(t, strings.HasPrefix(meta.Title, "The Unsolved Case"), "angle brackets are removed")
+	assert.Equal(t, video.Description, meta.Description)
+	assert.Equal(t, []string{strings.Repeat("a", 300), "flannan isles"}, meta.Tags)
+
+	rules := map[string]string{}
+	for _, a := range adjustments {
+		rules[a.Field+"/"+a.Rule] = a.Message
+	}
+	assert.Contains(t, rules, "title/"+AdjustRemovedCharacters)
+	assert.Contains(t, rules, "title/"+AdjustTruncated)
+	assert.Equal(t, "kept 2 of 3 tags to stay within 500 characters", rules["tags/"+AdjustDropped])
+}
+
+func TestRenderMetadata_CaptionPlatforms(t *testing.T) {
+	video := &models.Video{Title: "Flannan Isles", Description: "Three keepers, one locked door.", Tags: []string{"mystery", "true crime"}}
+
+	meta, adjustments, err := RenderMetadata(models.PlatformInstagram, video)
+	require.NoError(t, err)
+	assert.Empty(t, meta.Title)
+	assert.Equal(t, "Three keepers, one locked door.\n\n#mystery #truecrime", meta.Description)
+	require.Len(t, adjustments, 1)
+	assert.Equal(t, Adjustment{"title", AdjustDropped, "instagram posts have no title"}, adjustments[0])
+
+	// Tweets are cut to 280 characters, on a word
+	video.Description = strings.Repeat("keeper ", 50)
+	meta, adjustments, err = RenderMetadata(models.PlatformTwitter, video)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("keeper ", 38)+"keeper…", meta.Description)
+	assert.Equal(t, "description", adjustments[1].Field)
+	assert.Equal(t, AdjustTruncated, adjustments[1].Rule)
+
+	_, _, err = RenderMetadata("myspace", video)
+	assert.ErrorIs(t, err, models.ErrInvalidPlatform)
+}
+
+func TestRenderMetadata_Unchanged(t *testing.T) {
+	video := &models.Video{Title: "Flannan Isles", Description: "Three keepers, one locked door.", Tags: []string{"mystery"}}
+
+	meta, adjustments, err := RenderMetadata(models.PlatformYouTube, video)
+	require.NoError(t, err)
+	assert.Equal(t, &Metadata{Title: video.Title, Description: video.Description, Tags: video.Tags}, meta)
+	assert.Empty(t, adjustments)
+}
+
+func TestPlatformCapabilities_Renditions(t *testing.T) {
+	for _, platform := range models.Platforms {
+		profile, ok := transcode.Profiles[PlatformCapabilities[platform].Rendition]
+		require.True(t, ok, "%s has no rendition profile", platform)
+		if platform == models.PlatformTikTok || platform == models.PlatformInstagram || platform == models.PlatformSnapchat {
+			assert.Equal(t, "9:16", profile.AspectRatio, "%s takes vertical videos", platform)
+		}
+	}
+}
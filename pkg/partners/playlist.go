@@ -0,0 +1,15 @@
+package partners
+
+import "context"
+
+// PlaylistClient is implemented by the clients of platforms that group a
+// channel's videos in ordered playlists
+type PlaylistClient interface {
+	Client
+	// CreatePlaylist creates a public playlist on the channel of the
+	// workspace the client authenticated with, returning its ID
+	CreatePlaylist(ctx context.Context, title, description string) (string, error)
+	// InsertPlaylistItem adds the video the platform knows as videoID to the
+	// playlist at position, counted from 0, moving the videos after it down
+	InsertPlaylistItem(ctx context.Context, playlistID, videoID string, position int) error
+}
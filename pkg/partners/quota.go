@@ -0,0 +1,203 @@
+package partners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata" // Quota days follow the platform's time zone
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// ErrQuotaDeferred is returned when low-priority work is held back to keep
+// the rest of the day's quota for higher-priority operations
+var ErrQuotaDeferred = errors.New("platform quota reserved for higher-priority operations")
+
+// Operation is a kind of platform work budgeted against the daily quota
+type Operation string
+
+const (
+	OpUpload  Operation = "upload"
+	OpPublish Operation = "publish"
+	OpComment Operation = "comment"
+	OpSync    Operation = "sync"
+)
+
+// Operations lists the budgeted operations, highest priority first
+var Operations = []Operation{OpUpload, OpPublish, OpComment, OpSync}
+
+// Priority decides how much of the daily quota an operation may use
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+// Priority returns the priority of the operation: publishing work comes
+// first, replies to comments next and stats syncs last
+func (op Operation) Priority() Priority {
+	switch op {
+	case OpUpload, OpPublish:
+		return PriorityHigh
+	case OpComment:
+		return PriorityNormal
+	default:
+		return PriorityLow
+	}
+}
+
+// QuotaPolicy describes the daily unit quota of a platform API
+type QuotaPolicy struct {
+	// Costs are the units each operation is charged
+	Costs map[Operation]int64
+	// Location is where the platform's quota day starts at midnight
+	Location *time.Location
+}
+
+// Day returns the date of the quota day t falls in, at midnight UTC like the
+// dates read from the database
+func (p QuotaPolicy) Day(t time.Time) time.Time {
+	y, m, d := t.In(p.Location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// ResetAt returns when the quota day t falls in ends
+func (p QuotaPolicy) ResetAt(t time.Time) time.Time {
+	y, m, d := t.In(p.Location).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, p.Location)
+}
+
+// QuotaPolicies are the platforms whose APIs charge a daily unit quota. The
+// YouTube Data API resets at midnight Pacific time; its costs are those of
+// videos.insert, videos.update, commentThreads.insert and videos.list.
+var QuotaPolicies = map[string]QuotaPolicy{
+	string(models.PlatformYouTube): {
+		Costs: map[Operation]int64{
+			OpUpload:  1600,
+			OpPublish: 50,
+			OpComment: 50,
+			OpSync:    1,
+		},
+		Location: mustLoadLocation("America/Los_Angeles"),
+	},
+}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("partners: %v", err))
+	}
+	return loc
+}
+
+// Budget charges platform operations against a tenant's daily quota
+type Budget interface {
+	// Reserve charges the operation, failing with ErrQuotaExceeded when the
+	// quota is spent or ErrQuotaDeferred when what is left is kept for
+	// higher-priority operations
+	Reserve(ctx context.Context, tenantID, platform string, op Operation) error
+	// Exhausted records that the platform refused a call for quota, so
+	// nothing more is attempted until the quota resets
+	Exhausted(ctx context.Context, tenantID, platform string) error
+}
+
+// quotaClient charges the calls of a platform client to a budget before
+// making them
+type quotaClient struct {
+	inner    Client
+	platform string
+	budget   Budget
+}
+
+var _ Client = (*quotaClient)(nil)
+
+// NewQuotaFactory wraps the clients created by factory to charge their calls
+// to budget. Clients of platforms without a QuotaPolicy are returned as is.
+func NewQuotaFactory(factory func(string) (Client, error), budget Budget) func(string) (Client, error) {
+	return func(platform string) (Client, error) {
+		client, err := factory(platform)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := QuotaPolicies[platform]; !ok {
+			return client, nil
+		}
+		quota := &quotaClient{inner: client, platform: platform, budget: budget}
+		if history, ok := client.(HistoryClient); ok {
+			return &quotaHistoryClient{quotaClient: quota, history: history}, nil
+		}
+		return quota, nil
+	}
+}
+
+// Unwrap returns the budgeted client
+func (c *quotaClient) Unwrap() Client {
+	return c.inner
+}
+
+// Authenticate is not charged: OAuth calls do not count towards API quotas
+func (c *quotaClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
+	return c.inner.Authenticate(ctx, ws)
+}
+
+// Upload uploads the video once its cost is reserved
+func (c *quotaClient) Upload(ctx context.Context, v *models.Video) (string, error) {
+	if err := c.budget.Reserve(ctx, v.TenantID, c.platform, OpUpload); err != nil {
+		return "", err
+	}
+	id, err := c.inner.Upload(ctx, v)
+	return id, c.refused(ctx, v.TenantID, err)
+}
+
+// Publish publishes the video once its cost is reserved
+func (c *quotaClient) Publish(ctx context.Context, v *models.Video, ws *models.Workspace) error {
+	if err := c.budget.Reserve(ctx, v.TenantID, c.platform, OpPublish); err != nil {
+		return err
+	}
+	return c.refused(ctx, v.TenantID, c.inner.Publish(ctx, v, ws))
+}
+
+// FetchStats fetches the video stats once their cost is reserved
+func (c *quotaClient) FetchStats(ctx context.Context, v *models.Video) (*models.VideoStats, error) {
+	if err := c.budget.Reserve(ctx, v.TenantID, c.platform, OpSync); err != nil {
+		return nil, err
+	}
+	stats, err := c.inner.FetchStats(ctx, v)
+	return stats, c.refused(ctx, v.TenantID, err)
+}
+
+// Unpublish takes the video down once its cost is reserved. It costs what a
+// publish does and has its priority, so takedowns are never deferred.
+func (c *quotaClient) Unpublish(ctx context.Context, v *models.Video) error {
+	if err := c.budget.Reserve(ctx, v.TenantID, c.platform, OpPublish); err != nil {
+		return err
+	}
+	return c.refused(ctx, v.TenantID, c.inner.Unpublish(ctx, v))
+}
+
+// refused marks the tenant's quota spent when the platform refused the call
+// for quota, whatever the budget counted
+func (c *quotaClient) refused(ctx context.Context, tenantID string, err error) error {
+	if errors.Is(err, ErrQuotaExceeded) {
+		// The call's error matters more than failing to record it
+		_ = c.budget.Exhausted(context.WithoutCancel(ctx), tenantID, c.platform)
+	}
+	return err
+}
+
+// quotaHistoryClient keeps the history capability of the client it budgets.
+// Past stats come from a separate API with its own quota, so they are not charged.
+type quotaHistoryClient struct {
+	*quotaClient
+	history HistoryClient
+}
+
+var _ HistoryClient = (*quotaHistoryClient)(nil)
+
+// FetchDailyStats fetches the video's daily stats
+func (c *quotaHistoryClient) FetchDailyStats(ctx context.Context, v *models.Video, from, to time.Time) ([]*DailyStats, error) {
+	return c.history.FetchDailyStats(ctx, v, from, to)
+}
@@ -0,0 +1,99 @@
+package partners
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// recordingBudget records the reservations and refuses those listed
+type recordingBudget struct {
+	reserved  []Operation
+	refuse    map[Operation]error
+	exhausted []string
+}
+
+func (b *recordingBudget) Reserve(ctx context.Context, tenantID, platform string, op Operation) error {
+	if err := b.refuse[op]; err != nil {
+		return err
+	}
+	b.reserved = append(b.reserved, op)
+	return nil
+}
+
+func (b *recordingBudget) Exhausted(ctx context.Context, tenantID, platform string) error {
+	b.exhausted = append(b.exhausted, tenantID+"/"+platform)
+	return nil
+}
+
+// stubClient counts the calls that reached the platform
+type stubClient struct {
+	Client
+	calls    int
+	statsErr error
+}
+
+func (c *stubClient) Publish(ctx context.Context, v *models.Video, ws *models.Workspace) error {
+	c.calls++
+	return nil
+}
+
+func (c *stubClient) FetchStats(ctx context.Context, v *models.Video) (*models.VideoStats, error) {
+	c.calls++
+	return &models.VideoStats{}, c.statsErr
+}
+
+func TestQuotaFactory_ChargesBeforeCalling(t *testing.T) {
+	inner := &stubClient{}
+	budget := &recordingBudget{refuse: map[Operation]error{OpSync: ErrQuotaDeferred}}
+	factory := NewQuotaFactory(func(string) (Client, error) { return inner, nil }, budget)
+	video := &models.Video{TenantID: "acme"}
+
+	client, err := factory("youtube")
+	require.NoError(t, err)
+	require.NoError(t, client.Publish(context.Background(), video, &models.Workspace{}))
+	_, err = client.FetchStats(context.Background(), video)
+	assert.ErrorIs(t, err, ErrQuotaDeferred)
+
+	assert.Equal(t, []Operation{OpPublish}, budget.reserved)
+	assert.Equal(t, 1, inner.calls, "deferred sync must not reach the platform")
+
+	// Platforms without a quota policy are not wrapped
+	client, err = factory("tiktok")
+	require.NoError(t, err)
+	assert.Same(t, inner, client)
+}
+
+func TestQuotaFactory_RecordsPlatformRefusal(t *testing.T) {
+	inner := &stubClient{statsErr: fmt.Errorf("youtube: %w", ErrQuotaExceeded)}
+	budget := &recordingBudget{}
+	client, err := NewQuotaFactory(func(string) (Client, error) { return inner, nil }, budget)("youtube")
+	require.NoError(t, err)
+
+	_, err = client.FetchStats(context.Background(), &models.Video{TenantID: "acme"})
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Equal(t, []string{"acme/youtube"}, budget.exhausted)
+}
+
+func TestCapability_ThroughDecorators(t *testing.T) {
+	factory := NewQuotaFactory(NewTimedFactory(New, nil), &recordingBudget{})
+
+	client, err := factory("youtube")
+	require.NoError(t, err)
+	_, ok := client.(RetentionClient)
+	assert.False(t, ok, "decorators only implement the core client")
+	_, ok = Capability[RetentionClient](client)
+	assert.True(t, ok)
+	_, ok = Capability[DemographicsClient](client)
+	assert.True(t, ok)
+
+	client, err = factory("twitter")
+	require.NoError(t, err)
+	_, ok = Capability[RetentionClient](client)
+	assert.False(t, ok)
+}
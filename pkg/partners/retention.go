@@ -0,0 +1,16 @@
+package partners
+
+import (
+	"context"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// RetentionClient is implemented by the clients of platforms that report
+// how much of a video's audience keeps watching along it
+type RetentionClient interface {
+	Client
+	// FetchRetention returns the video's audience retention from its start
+	// to its end, by elapsed position
+	FetchRetention(ctx context.Context, video *models.Video) ([]models.RetentionPoint, error)
+}
@@ -20,7 +20,9 @@ type snapchatClient struct {
 	profileId   string
 }
 
-func (c *snapchatClient) Authenticate(ws *models.Workspace) error {
+var _ Client = (*snapchatClient)(nil)
+
+func (c *snapchatClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
 	if ws.SnapchatAccessToken == "" || ws.SnapchatProfileID == "" {
 		return fmt.Errorf("Snapchat credentials missing in workspace")
 	}
@@ -30,7 +32,7 @@ func (c *snapchatClient) Authenticate(ws *models.Workspace) error {
 	return nil
 }
 
-func (c *snapchatClient) Upload(video *models.Video) (string, error) {
+func (c *snapchatClient) Upload(ctx context.Context, video *models.Video) (string, error) {
 	buf := &bytes.Buffer{}
 	writer := multipart.NewWriter(buf)
 	file, err := os.Open(video.FilePath)
@@ -49,7 +51,7 @@ func (c *snapchatClient) Upload(video *models.Video) (string, error) {
 	writer.Close()
 
 	url := fmt.Sprintf("https://businessapi.snapchat.com/v1/public_profiles/%s/media", c.profileId)
-	req, err := http.NewRequestWithContext(context.Background(), "POST", url, buf)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, buf)
 	if err != nil {
 		return "", err
 	}
@@ -76,15 +78,19 @@ func (c *snapchatClient) Upload(video *models.Video) (string, error) {
 	return out.MediaID, nil
 }
 
-func (c *snapchatClient) Publish(video *models.Video, ws *models.Workspace) error {
+func (c *snapchatClient) Publish(ctx context.Context, video *models.Video, ws *models.Workspace) error {
+	meta, _, err := RenderMetadata(models.PlatformSnapchat, video)
+	if err != nil {
+		return err
+	}
 	payload := map[string]interface{}{
 		"media_id": video.SnapchatMediaID,
-		"caption":  video.Description,
+		"caption":  meta.Description,
 	}
 	body, _ := json.Marshal(payload)
 
 	url := fmt.Sprintf("https://businessapi.snapchat.com/v1/public_profiles/%s/stories", c.profileId)
-	req, err := http.NewRequestWithContext(context.Background(), "POST", url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return err
 	}
@@ -104,6 +110,11 @@ func (c *snapchatClient) Publish(video *models.Video, ws *models.Workspace) erro
 	return nil
 }
 
-func (c *snapchatClient) FetchStats(video *models.Video) (*models.VideoStats, error) {
+// Unpublish is not supported. The Public Profile API cannot delete stories; they expire after a day.
+func (c *snapchatClient) Unpublish(ctx context.Context, video *models.Video) error {
+	return fmt.Errorf("snapchat: %w", ErrUnpublishUnsupported)
+}
+
+func (c *snapchatClient) FetchStats(ctx context.Context, video *models.Video) (*models.VideoStats, error) {
 	return nil, fmt.Errorf("fetch stats not implemented")
 }
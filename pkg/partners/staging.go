@@ -0,0 +1,45 @@
+package partners
+
+import (
+	"context"
+	"fmt"
+)
+
+// Visibility is how a staged upload is shown until it is released
+type Visibility string
+
+const (
+	// VisibilityPrivate hides the video from everyone but its owner
+	VisibilityPrivate Visibility = "private"
+	// VisibilityUnlisted lets anyone with the link watch the video
+	VisibilityUnlisted Visibility = "unlisted"
+)
+
+// ParseVisibility reads a staged visibility, private when empty
+func ParseVisibility(s string) (Visibility, error) {
+	switch v := Visibility(s); v {
+	case "":
+		return VisibilityPrivate, nil
+	case VisibilityPrivate, VisibilityUnlisted:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid visibility %q: must be private or unlisted", s)
+	}
+}
+
+type visibilityKey struct{}
+
+// WithStagedVisibility sets the visibility of the videos uploaded with ctx
+// on platforms that upload hidden. It travels in the context so that it
+// reaches the client through the quota and timing wrappers.
+func WithStagedVisibility(ctx context.Context, v Visibility) context.Context {
+	return context.WithValue(ctx, visibilityKey{}, v)
+}
+
+// stagedVisibility returns the visibility set on ctx, private by default
+func stagedVisibility(ctx context.Context) Visibility {
+	if v, ok := ctx.Value(visibilityKey{}).(Visibility); ok && v != "" {
+		return v
+	}
+	return VisibilityPrivate
+}
@@ -1,18 +1,29 @@
 package partners
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
 
 	"github.com/HiWay-Media/tiktok-go-sdk/tiktok"
 	"github.com/jibe0123/mysteryfactory/internal/models"
-	"os"
 )
 
+// tiktokAPIURL is the base URL of the TikTok Content Posting API
+const tiktokAPIURL = "https://open.tiktokapis.com"
+
 type tiktokClient struct {
-	sdk tiktok.ITiktok
+	httpClient  *http.Client
+	baseURL     string
+	accessToken string
 }
 
-func (c *tiktokClient) Authenticate(ws *models.Workspace) error {
+var _ Client = (*tiktokClient)(nil)
+
+func (c *tiktokClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
 	appID := os.Getenv("TIKTOK_APP_ID")
 	secret := os.Getenv("TIKTOK_APP_SECRET")
 	client, err := tiktok.NewTikTok(appID, secret, false)
@@ -21,25 +32,122 @@ func (c *tiktokClient) Authenticate(ws *models.Workspace) error {
 	}
 	authURL := client.CodeAuthUrl()
 	fmt.Printf("Visit this URL to authorize: %s\n", authURL)
-	token := ws.OAuthCode
-	client.SetAccessToken(token)
-	c.sdk = client
+	c.accessToken = ws.OAuthCode
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.baseURL == "" {
+		c.baseURL = tiktokAPIURL
+	}
 	return nil
 }
 
-func (c *tiktokClient) Upload(video *models.Video) (string, error) {
-	resp, err := c.sdk.PostVideoInit(video.Title, video.Description, video.FileURL, "PUBLIC", false, false, false)
+func (c *tiktokClient) Upload(ctx context.Context, video *models.Video) (string, error) {
+	meta, _, err := RenderMetadata(models.PlatformTikTok, video)
 	if err != nil {
 		return "", err
 	}
-	return resp.Data.PubblishId, nil
+	request := map[string]interface{}{
+		"post_info": map[string]interface{}{
+			"title":           meta.Title,
+			"description":     meta.Description,
+			"privacy_level":   string(tiktok.PUBLIC_TO_EVERYONE),
+			"disable_duet":    false,
+			"disable_comment": false,
+			"disable_stitch":  false,
+		},
+		"source_info": map[string]interface{}{
+			"source":    "PULL_FROM_URL",
+			"video_url": video.FileURL,
+		},
+	}
+	var data struct {
+		PublishID string `json:"publish_id"`
+	}
+	if err := c.post(ctx, "/v2/post/publish/video/init/", request, &data); err != nil {
+		return "", err
+	}
+	return data.PublishID, nil
+}
+
+func (c *tiktokClient) Publish(ctx context.Context, video *models.Video, ws *models.Workspace) error {
+	var data struct {
+		Status     string `json:"status"`
+		FailReason string `json:"fail_reason"`
+	}
+	if err := c.post(ctx, "/v2/post/publish/status/fetch/", map[string]string{"publish_id": video.TikTokID}, &data); err != nil {
+		return err
+	}
+	if data.Status == "FAILED" {
+		return fmt.Errorf("TikTok publish failed: %s", data.FailReason)
+	}
+	return nil
 }
 
-func (c *tiktokClient) Publish(video *models.Video, ws *models.Workspace) error {
-	_, err := c.sdk.PublishVideo(video.TikTokID)
-	return err
+// Unpublish is not supported. The Content Posting API cannot delete posts.
+func (c *tiktokClient) Unpublish(ctx context.Context, video *models.Video) error {
+	return fmt.Errorf("tiktok: %w", ErrUnpublishUnsupported)
 }
 
-func (c *tiktokClient) FetchStats(video *models.Video) (*models.VideoStats, error) {
+func (c *tiktokClient) FetchStats(ctx context.Context, video *models.Video) (*models.VideoStats, error) {
 	return nil, fmt.Errorf("fetch stats not implemented")
 }
+
+// post calls a Content Posting API endpoint and decodes the data member of
+// the response into out
+func (c *tiktokClient) post(ctx context.Context, path string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Data  json.RawMessage `json:"data"`
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("failed to decode TikTok response (status %d): %w", resp.StatusCode, err)
+	}
+	if (envelope.Error.Code != "" && envelope.Error.Code != "ok") || resp.StatusCode >= http.StatusBadRequest {
+		return &APIError{
+			Platform:   "tiktok",
+			StatusCode: resp.StatusCode,
+			Code:       envelope.Error.Code,
+			Message:    envelope.Error.Message,
+			kind:       tiktokErrorKind(envelope.Error.Code),
+		}
+	}
+	if len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// tiktokErrorKind classifies Content Posting API error codes
+func tiktokErrorKind(code string) error {
+	switch code {
+	case "access_token_invalid":
+		return ErrInvalidToken
+	case "rate_limit_exceeded", "spam_risk_too_many_posts", "spam_risk_too_many_pending_share":
+		return ErrQuotaExceeded
+	case "invalid_params", "spam_risk_text":
+		return ErrInvalidMetadata
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,95 @@
+package partners
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+const (
+	tiktokInitPath   = "/v2/post/publish/video/init/"
+	tiktokStatusPath = "/v2/post/publish/status/fetch/"
+)
+
+func newTestTikTokClient(server *fixtureServer) *tiktokClient {
+	return &tiktokClient{httpClient: server.Client(), baseURL: server.URL, accessToken: "act.test-token"}
+}
+
+func TestTikTokClient_UploadAndPublish(t *testing.T) {
+	server := newFixtureServer(t,
+		fixture{"POST", tiktokInitPath, 200, "tiktok/video_init.json"},
+		fixture{"POST", tiktokStatusPath, 200, "tiktok/status_fetch.json"},
+	)
+	client := newTestTikTokClient(server)
+	video := &models.Video{Title: "Five Ciphers Nobody Has Cracked", FileURL: "https://cdn.example.com/demo-02.mp4"}
+
+	publishID, err := client.Upload(context.Background(), video)
+	require.NoError(t, err)
+	assert.Equal(t, "v_pub_url~v2-1.7425806236651847698", publishID)
+
+	video.TikTokID = publishID
+	require.NoError(t, client.Publish(context.Background(), video, &models.Workspace{}))
+
+	requests := server.received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "Bearer act.test-token", requests[0].header.Get("Authorization"))
+
+	var init struct {
+		PostInfo   map[string]interface{} `json:"post_info"`
+		SourceInfo map[string]interface{} `json:"source_info"`
+	}
+	require.NoError(t, json.Unmarshal(requests[0].body, &init))
+	assert.Equal(t, "PUBLIC_TO_EVERYONE", init.PostInfo["privacy_level"])
+	assert.Equal(t, "PULL_FROM_URL", init.SourceInfo["source"])
+	assert.Equal(t, video.FileURL, init.SourceInfo["video_url"])
+	assert.JSONEq(t, `{"publish_id":"v_pub_url~v2-1.7425806236651847698"}`, string(requests[1].body))
+}
+
+func TestTikTokClient_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		file   string
+		kind   error
+		code   string
+	}{
+		{"rate limit exceeded", 429, "tiktok/rate_limit_exceeded.json", ErrQuotaExceeded, "rate_limit_exceeded"},
+		{"invalid token", 401, "tiktok/access_token_invalid.json", ErrInvalidToken, "access_token_invalid"},
+		{"invalid params", 400, "tiktok/invalid_params.json", ErrInvalidMetadata, "invalid_params"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFixtureServer(t,
+				fixture{"POST", tiktokInitPath, tt.status, tt.file},
+				fixture{"POST", tiktokStatusPath, tt.status, tt.file},
+			)
+			client := newTestTikTokClient(server)
+
+			_, err := client.Upload(context.Background(), &models.Video{FileURL: "https://cdn.example.com/demo.mp4"})
+			assert.ErrorIs(t, err, tt.kind)
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.code, apiErr.Code)
+
+			err = client.Publish(context.Background(), &models.Video{TikTokID: "v_pub_url~v2-1.7425806236651847698"}, &models.Workspace{})
+			assert.ErrorIs(t, err, tt.kind)
+		})
+	}
+}
+
+func TestTikTokClient_CancelledContext(t *testing.T) {
+	server := newFixtureServer(t, fixture{"POST", tiktokInitPath, 200, "tiktok/video_init.json"})
+	client := newTestTikTokClient(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.Upload(ctx, &models.Video{FileURL: "https://cdn.example.com/demo.mp4"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, server.received(), "a cancelled upload never reaches the platform")
+}
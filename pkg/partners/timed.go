@@ -0,0 +1,115 @@
+package partners
+
+import (
+	"context"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/slowlog"
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
+)
+
+// timedClient reports the calls of a platform client slower than the
+// platform threshold
+type timedClient struct {
+	inner    Client
+	platform string
+	observer *slowlog.Observer
+}
+
+var _ Client = (*timedClient)(nil)
+
+// NewTimedFactory wraps the clients created by factory to report their slow calls
+func NewTimedFactory(factory func(string) (Client, error), observer *slowlog.Observer) func(string) (Client, error) {
+	return func(platform string) (Client, error) {
+		client, err := factory(platform)
+		if err != nil {
+			return nil, err
+		}
+		timed := &timedClient{inner: client, platform: platform, observer: observer}
+		if history, ok := client.(HistoryClient); ok {
+			return &timedHistoryClient{timedClient: timed, history: history}, nil
+		}
+		return timed, nil
+	}
+}
+
+// timedHistoryClient keeps the history capability of the client it times
+type timedHistoryClient struct {
+	*timedClient
+	history HistoryClient
+}
+
+var _ HistoryClient = (*timedHistoryClient)(nil)
+
+// FetchDailyStats fetches the video's daily stats and reports the call when slow
+func (c *timedHistoryClient) FetchDailyStats(ctx context.Context, v *models.Video, from, to time.Time) ([]*DailyStats, error) {
+	start := time.Now()
+	days, err := c.history.FetchDailyStats(ctx, v, from, to)
+	c.observe(ctx, "fetch_daily_stats", v.TenantID, start, err, "video_id", v.ID)
+	return days, err
+}
+
+// Unwrap returns the timed client
+func (c *timedClient) Unwrap() Client {
+	return c.inner
+}
+
+// Authenticate authenticates the workspace and reports the call when slow
+func (c *timedClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
+	start := time.Now()
+	err := c.inner.Authenticate(ctx, ws)
+	tenantID := ""
+	if ws != nil {
+		tenantID = ws.TenantID
+	}
+	c.observe(ctx, "authenticate", tenantID, start, err, "workspace_id", workspaceID(ws))
+	return err
+}
+
+// Upload uploads the video and reports the call when slow
+func (c *timedClient) Upload(ctx context.Context, v *models.Video) (string, error) {
+	start := time.Now()
+	id, err := c.inner.Upload(ctx, v)
+	c.observe(ctx, "upload", v.TenantID, start, err, "video_id", v.ID)
+	return id, err
+}
+
+// Publish publishes the video and reports the call when slow
+func (c *timedClient) Publish(ctx context.Context, v *models.Video, ws *models.Workspace) error {
+	start := time.Now()
+	err := c.inner.Publish(ctx, v, ws)
+	c.observe(ctx, "publish", v.TenantID, start, err, "video_id", v.ID, "workspace_id", workspaceID(ws))
+	return err
+}
+
+// FetchStats fetches the video stats and reports the call when slow
+func (c *timedClient) FetchStats(ctx context.Context, v *models.Video) (*models.VideoStats, error) {
+	start := time.Now()
+	stats, err := c.inner.FetchStats(ctx, v)
+	c.observe(ctx, "fetch_stats", v.TenantID, start, err, "video_id", v.ID)
+	return stats, err
+}
+
+// Unpublish takes the video down and reports the call when slow
+func (c *timedClient) Unpublish(ctx context.Context, v *models.Video) error {
+	start := time.Now()
+	err := c.inner.Unpublish(ctx, v)
+	c.observe(ctx, "unpublish", v.TenantID, start, err, "video_id", v.ID)
+	return err
+}
+
+// observe reports a call, tagged with the tenant of the video or workspace
+func (c *timedClient) observe(ctx context.Context, operation, tenantID string, start time.Time, err error, fields ...interface{}) {
+	if tenantID != "" {
+		ctx = tenancy.WithTenant(ctx, tenantID)
+	}
+	c.observer.Observe(ctx, slowlog.Platform, c.platform, start, err, append([]interface{}{"operation", operation}, fields...)...)
+}
+
+func workspaceID(ws *models.Workspace) string {
+	if ws == nil {
+		return ""
+	}
+	return ws.ID
+}
@@ -0,0 +1,124 @@
+package partners
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// ErrTrafficSourcesUnsupported is returned for platforms whose traffic
+// source payload has no mapper
+var ErrTrafficSourcesUnsupported = errors.New("traffic sources not supported")
+
+// TrafficSourcesClient is implemented by the clients of platforms that
+// report how viewers reached a video, in the platform's own shape
+type TrafficSourcesClient interface {
+	Client
+	// FetchTrafficSources returns the payload NormalizeTrafficSources reads
+	// for the client's platform
+	FetchTrafficSources(ctx context.Context, video *models.Video) (json.RawMessage, error)
+}
+
+// trafficSourceMappers read the traffic source payload of each platform
+var trafficSourceMappers = map[models.Platform]func([]byte) (models.TrafficSources, error){
+	models.PlatformYouTube: mapYouTubeTrafficSources,
+	models.PlatformTikTok:  mapTikTokTrafficSources,
+}
+
+// NormalizeTrafficSources maps a platform's traffic source payload to shares
+// of views by models.TrafficSourceTypes
+func NormalizeTrafficSources(platform string, raw []byte) (models.TrafficSources, error) {
+	mapper, ok := trafficSourceMappers[models.Platform(platform)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTrafficSourcesUnsupported, platform)
+	}
+	sources, err := mapper(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s traffic sources: %w", platform, err)
+	}
+	return sources, nil
+}
+
+// youtubeTrafficSources maps the insightTrafficSourceType values of the
+// Analytics API; those missing are other
+var youtubeTrafficSources = map[string]string{
+	"YT_SEARCH":        models.TrafficSearch,
+	"HASHTAGS":         models.TrafficSearch,
+	"RELATED_VIDEO":    models.TrafficSuggested,
+	"END_SCREEN":       models.TrafficSuggested,
+	"ANNOTATION":       models.TrafficSuggested,
+	"VIDEO_REMIXES":    models.TrafficSuggested,
+	"EXT_URL":          models.TrafficExternal,
+	"NO_LINK_EMBEDDED": models.TrafficExternal,
+	"SUBSCRIBER":       models.TrafficBrowse,
+	"YT_CHANNEL":       models.TrafficBrowse,
+	"YT_OTHER_PAGE":    models.TrafficBrowse,
+	"YT_PLAYLIST_PAGE": models.TrafficBrowse,
+	"PLAYLIST":         models.TrafficBrowse,
+	"NOTIFICATION":     models.TrafficBrowse,
+	"SHORTS":           models.TrafficBrowse,
+	"SOUND_PAGE":       models.TrafficBrowse,
+}
+
+// mapYouTubeTrafficSources reads an Analytics API report of views by
+// insightTrafficSourceType
+func mapYouTubeTrafficSources(raw []byte) (models.TrafficSources, error) {
+	var report youtubeReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return nil, err
+	}
+	source, views := report.column("insightTrafficSourceType"), report.column("views")
+	if len(report.Rows) > 0 && (source < 0 || views < 0) {
+		return nil, fmt.Errorf("traffic source report lacks insightTrafficSourceType or views")
+	}
+	counts := map[string]float64{}
+	for _, row := range report.Rows {
+		if len(row) <= max(source, views) {
+			continue
+		}
+		name, _ := row[source].(string)
+		value, _ := row[views].(float64)
+		normalized, ok := youtubeTrafficSources[name]
+		if !ok {
+			normalized = models.TrafficOther
+		}
+		counts[normalized] += value
+	}
+	return shares(counts), nil
+}
+
+// tiktokTrafficSources is where the views of a video come from in TikTok
+// video insights, with percentages as fractions
+type tiktokTrafficSources struct {
+	ImpressionSources []struct {
+		Source     string  `json:"impression_source"`
+		Percentage float64 `json:"percentage"`
+	} `json:"impression_sources"`
+}
+
+func mapTikTokTrafficSources(raw []byte) (models.TrafficSources, error) {
+	var payload tiktokTrafficSources
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	var sources map[string]float64
+	for _, s := range payload.ImpressionSources {
+		var normalized string
+		switch strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s.Source), " ", "_")) {
+		case "search", "hashtag":
+			normalized = models.TrafficSearch
+		case "for_you":
+			normalized = models.TrafficSuggested
+		case "follow", "following", "personal_profile", "sound":
+			normalized = models.TrafficBrowse
+		default:
+			normalized = models.TrafficOther
+		}
+		addShare(&sources, normalized, s.Percentage)
+	}
+	return sources, nil
+}
@@ -0,0 +1,32 @@
+package partners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTrafficSources(t *testing.T) {
+	read := func(path ...string) []byte {
+		raw, err := os.ReadFile(filepath.Join(append([]string{"testdata"}, path...)...))
+		require.NoError(t, err)
+		return raw
+	}
+
+	// The Analytics API report is the YouTube payload as is
+	youtube, err := NormalizeTrafficSources("youtube", read("fixtures", "youtube", "reports_traffic_sources.json"))
+	require.NoError(t, err)
+	assert.InDeltaMapValues(t, map[string]float64{"suggested": 0.45, "search": 0.3, "browse": 0.12, "external": 0.08, "other": 0.05}, youtube, 1e-9)
+
+	tiktok, err := NormalizeTrafficSources("tiktok", read("traffic_sources", "tiktok.json"))
+	require.NoError(t, err)
+	assert.InDeltaMapValues(t, map[string]float64{"suggested": 0.82, "browse": 0.1, "search": 0.06, "other": 0.02}, tiktok, 1e-9)
+
+	_, err = NormalizeTrafficSources("instagram", []byte(`{}`))
+	assert.ErrorIs(t, err, ErrTrafficSourcesUnsupported)
+	_, err = NormalizeTrafficSources("youtube", []byte(`{"columnHeaders":[{"name":"day"}],"rows":[["2026-03-02"]]}`))
+	assert.Error(t, err)
+}
@@ -12,24 +12,35 @@ type twitterClient struct {
 	client *twitter.Client
 }
 
-func (c *twitterClient) Authenticate(ws *models.Workspace) error {
+var _ Client = (*twitterClient)(nil)
+
+func (c *twitterClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
 	cfg := oauth1.NewConfig(ws.TwitterConsumerKey, ws.TwitterConsumerSecret)
 	token := oauth1.NewToken(ws.TwitterAccessToken, ws.TwitterAccessSecret)
-	httpClient := cfg.Client(context.Background(), token)
+	httpClient := cfg.Client(ctx, token)
 	c.client = twitter.NewClient(httpClient)
 	return nil
 }
 
-func (c *twitterClient) Upload(video *models.Video) (string, error) {
+func (c *twitterClient) Upload(ctx context.Context, video *models.Video) (string, error) {
 	// Media upload not implemented
 	return "", nil
 }
 
-func (c *twitterClient) Publish(video *models.Video, ws *models.Workspace) error {
-	_, _, err := c.client.Statuses.Update(video.Description, nil)
+func (c *twitterClient) Publish(ctx context.Context, video *models.Video, ws *models.Workspace) error {
+	meta, _, err := RenderMetadata(models.PlatformTwitter, video)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.client.Statuses.Update(meta.Description, nil)
 	return err
 }
 
-func (c *twitterClient) FetchStats(video *models.Video) (*models.VideoStats, error) {
+// Unpublish is not supported. The tweet sharing the video is not recorded, so it cannot be deleted.
+func (c *twitterClient) Unpublish(ctx context.Context, video *models.Video) error {
+	return fmt.Errorf("twitter: %w", ErrUnpublishUnsupported)
+}
+
+func (c *twitterClient) FetchStats(ctx context.Context, video *models.Video) (*models.VideoStats, error) {
 	return nil, fmt.Errorf("fetch stats not implemented")
 }
@@ -0,0 +1,161 @@
+package partners
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+var (
+	// ErrWebhookNotConfigured is returned for platforms whose webhook secret is not set
+	ErrWebhookNotConfigured = errors.New("webhooks are not configured for this platform")
+	// ErrInvalidSignature is returned when a webhook signature or challenge does not verify
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// tiktokSignatureTolerance bounds the age of a signed TikTok event, so a
+// captured request cannot be replayed later
+const tiktokSignatureTolerance = 5 * time.Minute
+
+// WebhookSecrets holds the secrets platforms sign their webhooks with
+type WebhookSecrets struct {
+	YouTube string // hub.secret of the WebSub subscription
+	TikTok  string // client secret of the TikTok app
+	// MetaAppSecret signs Facebook and Instagram events; MetaVerifyToken is
+	// the token echoed in their subscription challenge
+	MetaAppSecret   string
+	MetaVerifyToken string
+	TwitterSecret   string // consumer secret of the Account Activity app
+}
+
+// VerifyWebhook checks the signature a platform put on a webhook body
+func VerifyWebhook(platform string, secrets WebhookSecrets, header http.Header, body []byte, now time.Time) error {
+	switch models.Platform(platform) {
+	case models.PlatformYouTube:
+		return verifyHexSignature(secrets.YouTube, header.Get("X-Hub-Signature"), "sha1=", sha1.New, body)
+	case models.PlatformFacebook, models.PlatformInstagram:
+		return verifyHexSignature(secrets.MetaAppSecret, header.Get("X-Hub-Signature-256"), "sha256=", sha256.New, body)
+	case models.PlatformTikTok:
+		return verifyTikTokSignature(secrets.TikTok, header.Get("TikTok-Signature"), body, now)
+	case models.PlatformTwitter:
+		if secrets.TwitterSecret == "" {
+			return ErrWebhookNotConfigured
+		}
+		signature, ok := strings.CutPrefix(header.Get("X-Twitter-Webhooks-Signature"), "sha256=")
+		if !ok {
+			return ErrInvalidSignature
+		}
+		expected := hmacSum(sha256.New, secrets.TwitterSecret, body)
+		decoded, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil || !hmac.Equal(decoded, expected) {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return ErrWebhookNotConfigured
+	}
+}
+
+// WebhookChallenge answers the subscription challenge of a platform: the
+// echoed hub.challenge for YouTube and Meta, the signed CRC token for Twitter
+func WebhookChallenge(platform string, secrets WebhookSecrets, query url.Values) (contentType string, body []byte, err error) {
+	switch models.Platform(platform) {
+	case models.PlatformYouTube:
+		if secrets.YouTube == "" {
+			return "", nil, ErrWebhookNotConfigured
+		}
+		mode, challenge := query.Get("hub.mode"), query.Get("hub.challenge")
+		if (mode != "subscribe" && mode != "unsubscribe") || challenge == "" {
+			return "", nil, ErrInvalidSignature
+		}
+		return "text/plain", []byte(challenge), nil
+	case models.PlatformFacebook, models.PlatformInstagram:
+		if secrets.MetaVerifyToken == "" {
+			return "", nil, ErrWebhookNotConfigured
+		}
+		challenge := query.Get("hub.challenge")
+		token := query.Get("hub.verify_token")
+		if query.Get("hub.mode") != "subscribe" || challenge == "" ||
+			!hmac.Equal([]byte(token), []byte(secrets.MetaVerifyToken)) {
+			return "", nil, ErrInvalidSignature
+		}
+		return "text/plain", []byte(challenge), nil
+	case models.PlatformTwitter:
+		if secrets.TwitterSecret == "" {
+			return "", nil, ErrWebhookNotConfigured
+		}
+		crcToken := query.Get("crc_token")
+		if crcToken == "" {
+			return "", nil, ErrInvalidSignature
+		}
+		token := base64.StdEncoding.EncodeToString(hmacSum(sha256.New, secrets.TwitterSecret, []byte(crcToken)))
+		return "application/json", []byte(fmt.Sprintf(`{"response_token":"sha256=%s"}`, token)), nil
+	default:
+		return "", nil, ErrWebhookNotConfigured
+	}
+}
+
+// verifyHexSignature checks a "<algo>=<hex digest>" HMAC header
+func verifyHexSignature(secret, header, prefix string, newHash func() hash.Hash, body []byte) error {
+	if secret == "" {
+		return ErrWebhookNotConfigured
+	}
+	signature, ok := strings.CutPrefix(header, prefix)
+	if !ok {
+		return ErrInvalidSignature
+	}
+	decoded, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, hmacSum(newHash, secret, body)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifyTikTokSignature checks a "t=<unix>,s=<hex>" header signing "<t>.<body>"
+func verifyTikTokSignature(secret, header string, body []byte, now time.Time) error {
+	if secret == "" {
+		return ErrWebhookNotConfigured
+	}
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "s":
+			signature = value
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || signature == "" {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tiktokSignatureTolerance || age < -tiktokSignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+
+	decoded, err := hex.DecodeString(signature)
+	signed := append([]byte(timestamp+"."), body...)
+	if err != nil || !hmac.Equal(decoded, hmacSum(sha256.New, secret, signed)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func hmacSum(newHash func() hash.Hash, secret string, data []byte) []byte {
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,250 @@
+package partners
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// PayloadError reports a webhook body that does not match the payload its
+// platform sends. Reason is models.WebhookUnparseable when the body could not
+// be decoded at all, models.WebhookMalformed when fields are missing or wrong.
+type PayloadError struct {
+	Platform string
+	Reason   string
+	Fields   []models.WebhookFieldError
+}
+
+func (e *PayloadError) Error() string {
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("%s %s webhook payload", e.Reason, e.Platform)
+	}
+	problems := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		problems[i] = f.Field + ": " + f.Message
+	}
+	return fmt.Sprintf("%s %s webhook payload: %s", e.Reason, e.Platform, strings.Join(problems, "; "))
+}
+
+// youtubeFeed is a WebSub notification of the YouTube Data API: an Atom feed
+// with an entry per published or updated video, or a deleted entry
+type youtubeFeed struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	Entries []struct {
+		VideoID   string `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
+		ChannelID string `xml:"http://www.youtube.com/xml/schemas/2015 channelId"`
+	} `xml:"http://www.w3.org/2005/Atom entry"`
+	Deleted []struct {
+		Ref string `xml:"ref,attr"`
+	} `xml:"http://purl.org/atompub/tombstones/1.0 deleted-entry"`
+}
+
+// metaNotification is a Graph API webhook of a Facebook page or Instagram
+// account. Object is "page" for Facebook and "instagram" for Instagram.
+type metaNotification struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		ID      string `json:"id"`
+		Time    int64  `json:"time"`
+		Changes []struct {
+			Field string          `json:"field"`
+			Value json.RawMessage `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+// tiktokEvent is a TikTok developer webhook; Content is the event data
+// serialized as a JSON string
+type tiktokEvent struct {
+	ClientKey  string `json:"client_key"`
+	Event      string `json:"event"`
+	CreateTime int64  `json:"create_time"`
+	UserOpenID string `json:"user_openid"`
+	Content    string `json:"content"`
+}
+
+// twitterActivity is an Account Activity API event of a subscribed user,
+// carrying one or more <kind>_events arrays
+type twitterActivity struct {
+	ForUserID string `json:"for_user_id"`
+}
+
+// ValidateWebhookPayload checks a webhook body against the payload its
+// platform sends, returning a *PayloadError naming what is wrong. Fields the
+// payloads do not require are ignored, so platforms can add new ones.
+// LinkedIn and Snapchat bodies only have to be JSON objects.
+func ValidateWebhookPayload(platform string, body []byte) error {
+	v := &payloadValidator{platform: platform}
+	switch models.Platform(platform) {
+	case models.PlatformYouTube:
+		v.youtube(body)
+	case models.PlatformFacebook:
+		v.meta(body, "page")
+	case models.PlatformInstagram:
+		v.meta(body, "instagram")
+	case models.PlatformTikTok:
+		v.tiktok(body)
+	case models.PlatformTwitter:
+		v.twitter(body)
+	case models.PlatformLinkedIn, models.PlatformSnapchat:
+		var object map[string]json.RawMessage
+		v.decodeJSON(body, &object)
+	default:
+		return fmt.Errorf("%w: %s", models.ErrInvalidPlatform, platform)
+	}
+	return v.err()
+}
+
+// payloadValidator collects the problems of one payload
+type payloadValidator struct {
+	platform    string
+	unparseable bool
+	fields      []models.WebhookFieldError
+}
+
+func (v *payloadValidator) fail(field, format string, args ...interface{}) {
+	v.fields = append(v.fields, models.WebhookFieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *payloadValidator) err() error {
+	switch {
+	case v.unparseable:
+		return &PayloadError{Platform: v.platform, Reason: models.WebhookUnparseable, Fields: v.fields}
+	case len(v.fields) > 0:
+		return &PayloadError{Platform: v.platform, Reason: models.WebhookMalformed, Fields: v.fields}
+	default:
+		return nil
+	}
+}
+
+// decodeJSON decodes a JSON object into dest, reporting whether it worked.
+// A value of the wrong type is a malformed field; anything else that stops
+// decoding makes the body unparseable.
+func (v *payloadValidator) decodeJSON(body []byte, dest interface{}) bool {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		v.unparseable = true
+		v.fail("$", "body is not a JSON object")
+		return false
+	}
+
+	err := json.Unmarshal(trimmed, dest)
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &typeErr):
+		v.fail(typeErr.Field, "must be %s, got %s", typeErr.Type.Kind(), typeErr.Value)
+		return false
+	default:
+		v.unparseable = true
+		v.fail("$", "invalid JSON: %v", err)
+		return false
+	}
+}
+
+func (v *payloadValidator) youtube(body []byte) {
+	var feed youtubeFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		v.unparseable = true
+		v.fail("$", "invalid Atom XML: %v", err)
+		return
+	}
+
+	if len(feed.Entries) == 0 && len(feed.Deleted) == 0 {
+		v.fail("feed", "must hold an entry or a deleted-entry")
+	}
+	for i, entry := range feed.Entries {
+		if entry.VideoID == "" {
+			v.fail(fmt.Sprintf("entry[%d].yt:videoId", i), "is required")
+		}
+		if entry.ChannelID == "" {
+			v.fail(fmt.Sprintf("entry[%d].yt:channelId", i), "is required")
+		}
+	}
+	for i, deleted := range feed.Deleted {
+		if !strings.HasPrefix(deleted.Ref, "yt:video:") {
+			v.fail(fmt.Sprintf("deleted-entry[%d].ref", i), "must be a yt:video: reference")
+		}
+	}
+}
+
+func (v *payloadValidator) meta(body []byte, object string) {
+	var n metaNotification
+	if !v.decodeJSON(body, &n) {
+		return
+	}
+
+	if n.Object != object {
+		v.fail("object", "must be %q", object)
+	}
+	if len(n.Entry) == 0 {
+		v.fail("entry", "must hold at least one entry")
+	}
+	for i, entry := range n.Entry {
+		if entry.ID == "" {
+			v.fail(fmt.Sprintf("entry[%d].id", i), "is required")
+		}
+		if entry.Time <= 0 {
+			v.fail(fmt.Sprintf("entry[%d].time", i), "is required")
+		}
+		for j, change := range entry.Changes {
+			if change.Field == "" {
+				v.fail(fmt.Sprintf("entry[%d].changes[%d].field", i, j), "is required")
+			}
+		}
+	}
+}
+
+func (v *payloadValidator) tiktok(body []byte) {
+	var event tiktokEvent
+	if !v.decodeJSON(body, &event) {
+		return
+	}
+
+	if event.ClientKey == "" {
+		v.fail("client_key", "is required")
+	}
+	if event.Event == "" {
+		v.fail("event", "is required")
+	}
+	if event.CreateTime <= 0 {
+		v.fail("create_time", "is required")
+	}
+	if event.UserOpenID == "" {
+		v.fail("user_openid", "is required")
+	}
+	if event.Content != "" && !json.Valid([]byte(event.Content)) {
+		v.fail("content", "must be a JSON document")
+	}
+}
+
+func (v *payloadValidator) twitter(body []byte) {
+	var raw map[string]json.RawMessage
+	if !v.decodeJSON(body, &raw) {
+		return
+	}
+	var activity twitterActivity
+	if !v.decodeJSON(body, &activity) {
+		return
+	}
+
+	if activity.ForUserID == "" {
+		v.fail("for_user_id", "is required")
+	}
+	hasEvents := false
+	for key := range raw {
+		if strings.HasSuffix(key, "_events") {
+			hasEvents = true
+			break
+		}
+	}
+	if !hasEvents {
+		v.fail("$", "must hold an *_events array")
+	}
+}
@@ -0,0 +1,86 @@
+package partners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+func TestValidateWebhookPayload_AcceptsSamples(t *testing.T) {
+	// Samples in testdata/webhooks follow the platforms' documented payloads
+	samples := map[string]string{
+		"youtube":   "youtube.xml",
+		"facebook":  "facebook.json",
+		"instagram": "instagram.json",
+		"tiktok":    "tiktok.json",
+		"twitter":   "twitter.json",
+	}
+	for platform, file := range samples {
+		t.Run(platform, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", "webhooks", file))
+			require.NoError(t, err)
+			assert.NoError(t, ValidateWebhookPayload(platform, body))
+		})
+	}
+
+	assert.NoError(t, ValidateWebhookPayload("youtube",
+		[]byte(`<feed xmlns="http://www.w3.org/2005/Atom" xmlns:at="http://purl.org/atompub/tombstones/1.0">`+
+			`<at:deleted-entry ref="yt:video:dQw4w9WgXcQ" when="2026-10-15T09:12:44+00:00"/></feed>`)),
+		"a deleted video is a valid notification")
+	assert.NoError(t, ValidateWebhookPayload("linkedin", []byte(`{"any":"object"}`)))
+}
+
+func TestValidateWebhookPayload_Rejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		body     string
+		reason   string
+		fields   []string
+	}{
+		{"empty body", "tiktok", ``, models.WebhookUnparseable, []string{"$"}},
+		{"not an object", "facebook", `[{"object":"page"}]`, models.WebhookUnparseable, []string{"$"}},
+		{"truncated JSON", "instagram", `{"object":"instagram","entry":[`, models.WebhookUnparseable, []string{"$"}},
+		{"not XML", "youtube", `{"feed":[]}`, models.WebhookUnparseable, []string{"$"}},
+		{"not an Atom feed", "youtube", `<rss><channel/></rss>`, models.WebhookUnparseable, []string{"$"}},
+		{"empty feed", "youtube", `<feed xmlns="http://www.w3.org/2005/Atom"/>`, models.WebhookMalformed, []string{"feed"}},
+		{
+			"entry without video", "youtube",
+			`<feed xmlns="http://www.w3.org/2005/Atom" xmlns:yt="http://www.youtube.com/xml/schemas/2015">` +
+				`<entry><yt:channelId>UC1</yt:channelId></entry></feed>`,
+			models.WebhookMalformed, []string{"entry[0].yt:videoId"},
+		},
+		{"wrong Meta object", "facebook", `{"object":"instagram","entry":[{"id":"1","time":1}]}`, models.WebhookMalformed, []string{"object"}},
+		{"Meta entry fields", "instagram", `{"object":"instagram","entry":[{"changes":[{}]}]}`, models.WebhookMalformed,
+			[]string{"entry[0].id", "entry[0].time", "entry[0].changes[0].field"}},
+		{"wrong type", "tiktok", `{"client_key":"k","event":"e","create_time":"yesterday","user_openid":"u"}`, models.WebhookMalformed, []string{"create_time"}},
+		{"TikTok fields", "tiktok", `{"content":"not json"}`, models.WebhookMalformed,
+			[]string{"client_key", "event", "create_time", "user_openid", "content"}},
+		{"Twitter without events", "twitter", `{"for_user_id":"1"}`, models.WebhookMalformed, []string{"$"}},
+		{"Twitter without user", "twitter", `{"favorite_events":[]}`, models.WebhookMalformed, []string{"for_user_id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWebhookPayload(tt.platform, []byte(tt.body))
+			var payloadErr *PayloadError
+			require.ErrorAs(t, err, &payloadErr)
+			assert.Equal(t, tt.platform, payloadErr.Platform)
+			assert.Equal(t, tt.reason, payloadErr.Reason)
+
+			fields := make([]string, len(payloadErr.Fields))
+			for i, f := range payloadErr.Fields {
+				fields[i] = f.Field
+				assert.NotEmpty(t, f.Message)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+
+	assert.ErrorIs(t, ValidateWebhookPayload("myspace", []byte(`{}`)), models.ErrInvalidPlatform)
+}
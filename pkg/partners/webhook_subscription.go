@@ -0,0 +1,34 @@
+package partners
+
+import (
+	"context"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+// WebhookCallback is where a platform is asked to push its notifications
+type WebhookCallback struct {
+	URL     string
+	Secrets WebhookSecrets // Echoed by the platform or used to sign what it pushes
+	Lease   time.Duration  // Asked for; the platform may grant less
+}
+
+// WebhookLease is a platform's registration of a callback for a channel
+type WebhookLease struct {
+	Topic     string     // What the platform notifies about, such as a channel feed
+	ExpiresAt *time.Time // Nil when the subscription lasts until removed
+	// Verified is false while the platform has yet to check the callback
+	// with a challenge, which confirms the granted lease
+	Verified bool
+}
+
+// WebhookSubscriber is implemented by the clients of platforms that push
+// notifications to a callback once it is subscribed
+type WebhookSubscriber interface {
+	Client
+	// SubscribeWebhook registers the callback for the channel of the
+	// workspace the client authenticated with, or renews its lease.
+	// Subscribing again is harmless.
+	SubscribeWebhook(ctx context.Context, ws *models.Workspace, callback WebhookCallback) (*WebhookLease, error)
+}
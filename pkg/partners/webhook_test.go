@@ -0,0 +1,97 @@
+package partners
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecrets = WebhookSecrets{
+	YouTube:         "yt-secret",
+	TikTok:          "tt-secret",
+	MetaAppSecret:   "meta-secret",
+	MetaVerifyToken: "meta-token",
+	TwitterSecret:   "tw-secret",
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	body := []byte(`{"event":"video.published"}`)
+	now := time.Unix(1760000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	youtube := hex.EncodeToString(hmacSum(sha1.New, "yt-secret", body))
+	meta := hex.EncodeToString(hmacSum(sha256.New, "meta-secret", body))
+	tiktok := hex.EncodeToString(hmacSum(sha256.New, "tt-secret", append([]byte(ts+"."), body...)))
+	twitter := base64.StdEncoding.EncodeToString(hmacSum(sha256.New, "tw-secret", body))
+
+	tests := []struct {
+		name     string
+		platform string
+		header   string
+		value    string
+		now      time.Time
+		err      error
+	}{
+		{name: "youtube", platform: "youtube", header: "X-Hub-Signature", value: "sha1=" + youtube},
+		{name: "youtube wrong signature", platform: "youtube", header: "X-Hub-Signature", value: "sha1=" + meta, err: ErrInvalidSignature},
+		{name: "youtube missing signature", platform: "youtube", err: ErrInvalidSignature},
+		{name: "instagram", platform: "instagram", header: "X-Hub-Signature-256", value: "sha256=" + meta},
+		{name: "facebook not hex", platform: "facebook", header: "X-Hub-Signature-256", value: "sha256=zz", err: ErrInvalidSignature},
+		{name: "tiktok", platform: "tiktok", header: "TikTok-Signature", value: "t=" + ts + ",s=" + tiktok},
+		{name: "tiktok replayed", platform: "tiktok", header: "TikTok-Signature", value: "t=" + ts + ",s=" + tiktok, now: now.Add(time.Hour), err: ErrInvalidSignature},
+		{name: "twitter", platform: "twitter", header: "X-Twitter-Webhooks-Signature", value: "sha256=" + twitter},
+		{name: "unsupported platform", platform: "snapchat", err: ErrWebhookNotConfigured},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set(tt.header, tt.value)
+			}
+			at := now
+			if !tt.now.IsZero() {
+				at = tt.now
+			}
+			err := VerifyWebhook(tt.platform, testSecrets, header, body, at)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	err := VerifyWebhook("youtube", WebhookSecrets{}, http.Header{"X-Hub-Signature": {"sha1=" + youtube}}, body, now)
+	assert.ErrorIs(t, err, ErrWebhookNotConfigured, "a platform without a secret accepts nothing")
+}
+
+func TestWebhookChallenge(t *testing.T) {
+	contentType, body, err := WebhookChallenge("youtube", testSecrets, url.Values{"hub.mode": {"subscribe"}, "hub.challenge": {"abc"}})
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", contentType)
+	assert.Equal(t, "abc", string(body))
+
+	_, body, err = WebhookChallenge("instagram", testSecrets, url.Values{"hub.mode": {"subscribe"}, "hub.challenge": {"42"}, "hub.verify_token": {"meta-token"}})
+	require.NoError(t, err)
+	assert.Equal(t, "42", string(body))
+
+	_, _, err = WebhookChallenge("facebook", testSecrets, url.Values{"hub.mode": {"subscribe"}, "hub.challenge": {"42"}, "hub.verify_token": {"guess"}})
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	contentType, body, err = WebhookChallenge("twitter", testSecrets, url.Values{"crc_token": {"crc"}})
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", contentType)
+	assert.JSONEq(t, `{"response_token":"sha256=`+base64.StdEncoding.EncodeToString(hmacSum(sha256.New, "tw-secret", []byte("crc")))+`"}`, string(body))
+
+	_, _, err = WebhookChallenge("tiktok", testSecrets, url.Values{})
+	assert.ErrorIs(t, err, ErrWebhookNotConfigured, "TikTok has no subscription challenge")
+}
@@ -3,14 +3,23 @@ package partners
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
+	"google.golang.org/api/youtubeanalytics/v2"
 
 	"github.com/jibe0123/mysteryfactory/internal/models"
 )
@@ -20,7 +29,7 @@ func getYouTubeClient(ctx context.Context, credsPath, tokenPath string) (*http.C
 	if err != nil {
 		return nil, fmt.Errorf("unable to read credentials: %w", err)
 	}
-	config, err := google.ConfigFromJSON(b, youtube.YoutubeUploadScope, youtube.YoutubeForceSslScope)
+	config, err := google.ConfigFromJSON(b, youtube.YoutubeUploadScope, youtube.YoutubeForceSslScope, youtubeanalytics.YtAnalyticsReadonlyScope)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse credentials: %w", err)
 	}
@@ -69,54 +78,387 @@ func getTokenFromWeb(ctx context.Context, config *oauth2.Config) (*oauth2.Token,
 	return config.Exchange(ctx, code)
 }
 
+// youtubeHubURL is the WebSub hub YouTube pushes channel feed updates through
+const youtubeHubURL = "https://pubsubhubbub.appspot.com/subscribe"
+
 type youtubeClient struct {
-	service *youtube.Service
+	service   *youtube.Service
+	analytics *youtubeanalytics.Service
+	hubURL    string // youtubeHubURL when empty
 }
 
-func (c *youtubeClient) Authenticate(ws *models.Workspace) error {
-	ctx := context.Background()
+var (
+	_ HistoryClient  = (*youtubeClient)(nil)
+	_ PlaylistClient = (*youtubeClient)(nil)
+)
+
+func (c *youtubeClient) Authenticate(ctx context.Context, ws *models.Workspace) error {
 	client, err := getYouTubeClient(ctx, ws.CredentialsPath, ws.TokenDir)
 	if err != nil {
 		return err
 	}
-	srv, err := youtube.New(client)
+	srv, err := newYouTubeService(ctx, client, "")
 	if err != nil {
-		return fmt.Errorf("youtube service init: %w", err)
+		return err
+	}
+	analytics, err := newYouTubeAnalyticsService(ctx, client, "")
+	if err != nil {
+		return err
 	}
 	c.service = srv
+	c.analytics = analytics
 	return nil
 }
 
-func (c *youtubeClient) Upload(video *models.Video) (string, error) {
+// newYouTubeService creates a Data API service; endpoint overrides the API
+// host when set
+func newYouTubeService(ctx context.Context, client *http.Client, endpoint string) (*youtube.Service, error) {
+	opts := []option.ClientOption{option.WithHTTPClient(client)}
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+	srv, err := youtube.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("youtube service init: %w", err)
+	}
+	return srv, nil
+}
+
+// newYouTubeAnalyticsService creates an Analytics API service; endpoint
+// overrides the API host when set
+func newYouTubeAnalyticsService(ctx context.Context, client *http.Client, endpoint string) (*youtubeanalytics.Service, error) {
+	opts := []option.ClientOption{option.WithHTTPClient(client)}
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+	srv, err := youtubeanalytics.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("youtube analytics service init: %w", err)
+	}
+	return srv, nil
+}
+
+// Upload uploads the video hidden, private unless WithStagedVisibility set
+// otherwise; Publish makes it public
+func (c *youtubeClient) Upload(ctx context.Context, video *models.Video) (string, error) {
+	meta, _, err := RenderMetadata(models.PlatformYouTube, video)
+	if err != nil {
+		return "", err
+	}
 	call := c.service.Videos.Insert([]string{"snippet", "status"}, &youtube.Video{
 		Snippet: &youtube.VideoSnippet{
-			Title:       video.Title,
-			Description: video.Description,
-			Tags:        video.GetTags(),
+			Title:       meta.Title,
+			Description: meta.Description,
+			Tags:        meta.Tags,
 		},
-		Status: &youtube.VideoStatus{PrivacyStatus: "private"},
+		Status: &youtube.VideoStatus{PrivacyStatus: string(stagedVisibility(ctx))},
 	})
 	file, err := os.Open(video.FilePath)
 	if err != nil {
 		return "", err
 	}
 	defer file.Close()
-	res, err := call.Media(file).Do()
+	res, err := call.Media(file).Context(ctx).Do()
 	if err != nil {
-		return "", err
+		return "", youtubeError(err)
 	}
 	return res.Id, nil
 }
 
-func (c *youtubeClient) Publish(video *models.Video, ws *models.Workspace) error {
+func (c *youtubeClient) Publish(ctx context.Context, video *models.Video, ws *models.Workspace) error {
 	_, err := c.service.Videos.Update([]string{"status"}, &youtube.Video{
 		Id:     video.YouTubeID,
 		Status: &youtube.VideoStatus{PrivacyStatus: "public"},
-	}).Do()
-	return err
+	}).Context(ctx).Do()
+	return youtubeError(err)
+}
+
+// Unpublish makes the video private, like it was before it was published
+func (c *youtubeClient) Unpublish(ctx context.Context, video *models.Video) error {
+	_, err := c.service.Videos.Update([]string{"status"}, &youtube.Video{
+		Id:     video.YouTubeID,
+		Status: &youtube.VideoStatus{PrivacyStatus: string(VisibilityPrivate)},
+	}).Context(ctx).Do()
+	return youtubeError(err)
+}
+
+// CreatePlaylist creates a public playlist on the authenticated channel
+func (c *youtubeClient) CreatePlaylist(ctx context.Context, title, description string) (string, error) {
+	res, err := c.service.Playlists.Insert([]string{"snippet", "status"}, &youtube.Playlist{
+		Snippet: &youtube.PlaylistSnippet{Title: title, Description: description},
+		Status:  &youtube.PlaylistStatus{PrivacyStatus: "public"},
+	}).Context(ctx).Do()
+	if err != nil {
+		return "", youtubeError(err)
+	}
+	return res.Id, nil
 }
 
-func (c *youtubeClient) FetchStats(video *models.Video) (*models.VideoStats, error) {
+// InsertPlaylistItem adds the video to the playlist at position
+func (c *youtubeClient) InsertPlaylistItem(ctx context.Context, playlistID, videoID string, position int) error {
+	_, err := c.service.PlaylistItems.Insert([]string{"snippet"}, &youtube.PlaylistItem{
+		Snippet: &youtube.PlaylistItemSnippet{
+			PlaylistId: playlistID,
+			Position:   int64(position),
+			ResourceId: &youtube.ResourceId{Kind: "youtube#video", VideoId: videoID},
+			// Position 0 would be dropped as empty, appending the video
+			ForceSendFields: []string{"Position"},
+		},
+	}).Context(ctx).Do()
+	return youtubeError(err)
+}
+
+func (c *youtubeClient) FetchStats(ctx context.Context, video *models.Video) (*models.VideoStats, error) {
 	// Stats retrieval not implemented in this example
 	return nil, fmt.Errorf("fetch stats not implemented")
 }
+
+// youtubeDailyMetrics are the Analytics API metrics of a daily stats report
+const youtubeDailyMetrics = "views,likes,comments,shares"
+
+// FetchDailyStats reads a daily report of the video from the Analytics API.
+// Its dates are inclusive, and the API leaves out the last two or three days
+// until their data is final.
+func (c *youtubeClient) FetchDailyStats(ctx context.Context, video *models.Video, from, to time.Time) ([]*DailyStats, error) {
+	if video.YouTubeID == "" {
+		return nil, fmt.Errorf("video %s has no YouTube ID", video.ID)
+	}
+	if !from.Before(to) {
+		return nil, nil
+	}
+	res, err := c.analytics.Reports.Query().
+		Ids("channel==MINE").
+		StartDate(from.UTC().Format(time.DateOnly)).
+		EndDate(to.UTC().AddDate(0, 0, -1).Format(time.DateOnly)).
+		Metrics(youtubeDailyMetrics).
+		Dimensions("day").
+		Filters("video==" + video.YouTubeID).
+		Sort("day").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, youtubeError(err)
+	}
+
+	columns := make(map[string]int, len(res.ColumnHeaders))
+	for i, header := range res.ColumnHeaders {
+		columns[header.Name] = i
+	}
+	metric := func(row []interface{}, name string) int64 {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return 0
+		}
+		n, _ := row[i].(float64) // JSON numbers
+		return int64(n)
+	}
+
+	days := make([]*DailyStats, 0, len(res.Rows))
+	for _, row := range res.Rows {
+		i, ok := columns["day"]
+		if !ok || i >= len(row) {
+			return nil, fmt.Errorf("youtube analytics report has no day column")
+		}
+		value, _ := row[i].(string)
+		day, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return nil, fmt.Errorf("youtube analytics report has invalid day %q: %w", value, err)
+		}
+		days = append(days, &DailyStats{
+			Day:      day,
+			Views:    metric(row, "views"),
+			Likes:    metric(row, "likes"),
+			Comments: metric(row, "comments"),
+			Shares:   metric(row, "shares"),
+		})
+	}
+	return days, nil
+}
+
+// FetchDemographics reads the video's audience since its creation from the
+// Analytics API, as the youtubeDemographics reports
+func (c *youtubeClient) FetchDemographics(ctx context.Context, video *models.Video) (json.RawMessage, error) {
+	if video.YouTubeID == "" {
+		return nil, fmt.Errorf("video %s has no YouTube ID", video.ID)
+	}
+	query := func(metrics, dimensions string) (*youtubeanalytics.QueryResponse, error) {
+		res, err := c.analytics.Reports.Query().
+			Ids("channel==MINE").
+			StartDate(video.CreatedAt.UTC().Format(time.DateOnly)).
+			EndDate(time.Now().UTC().Format(time.DateOnly)).
+			Metrics(metrics).
+			Dimensions(dimensions).
+			Filters("video==" + video.YouTubeID).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, youtubeError(err)
+		}
+		return res, nil
+	}
+
+	ageGender, err := query("viewerPercentage", "ageGroup,gender")
+	if err != nil {
+		return nil, err
+	}
+	countries, err := query("views", "country")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]*youtubeanalytics.QueryResponse{"age_gender": ageGender, "countries": countries})
+}
+
+// FetchTrafficSources reads the video's views since its creation by traffic
+// source from the Analytics API, as the report mapYouTubeTrafficSources reads
+func (c *youtubeClient) FetchTrafficSources(ctx context.Context, video *models.Video) (json.RawMessage, error) {
+	if video.YouTubeID == "" {
+		return nil, fmt.Errorf("video %s has no YouTube ID", video.ID)
+	}
+	res, err := c.analytics.Reports.Query().
+		Ids("channel==MINE").
+		StartDate(video.CreatedAt.UTC().Format(time.DateOnly)).
+		EndDate(time.Now().UTC().Format(time.DateOnly)).
+		Metrics("views").
+		Dimensions("insightTrafficSourceType").
+		Filters("video==" + video.YouTubeID).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, youtubeError(err)
+	}
+	return json.Marshal(res)
+}
+
+// FetchDeviceTypes reads the video's views since its creation by device type
+// from the Analytics API, as the report mapYouTubeDeviceTypes reads
+func (c *youtubeClient) FetchDeviceTypes(ctx context.Context, video *models.Video) (json.RawMessage, error) {
+	if video.YouTubeID == "" {
+		return nil, fmt.Errorf("video %s has no YouTube ID", video.ID)
+	}
+	res, err := c.analytics.Reports.Query().
+		Ids("channel==MINE").
+		StartDate(video.CreatedAt.UTC().Format(time.DateOnly)).
+		EndDate(time.Now().UTC().Format(time.DateOnly)).
+		Metrics("views").
+		Dimensions("deviceType").
+		Filters("video==" + video.YouTubeID).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, youtubeError(err)
+	}
+	return json.Marshal(res)
+}
+
+// SubscribeWebhook asks the WebSub hub to push the uploads feed of the
+// authenticated channel to the callback. The hub verifies the callback
+// asynchronously, with a challenge carrying the lease it grants.
+func (c *youtubeClient) SubscribeWebhook(ctx context.Context, ws *models.Workspace, callback WebhookCallback) (*WebhookLease, error) {
+	res, err := c.service.Channels.List([]string{"id"}).Mine(true).Context(ctx).Do()
+	if err != nil {
+		return nil, youtubeError(err)
+	}
+	if len(res.Items) == 0 {
+		return nil, fmt.Errorf("workspace %s has no YouTube channel", ws.ID)
+	}
+	topic := "https://www.youtube.com/xml/feeds/videos.xml?channel_id=" + res.Items[0].Id
+
+	form := url.Values{
+		"hub.callback":      {callback.URL},
+		"hub.topic":         {topic},
+		"hub.mode":          {"subscribe"},
+		"hub.verify":        {"async"},
+		"hub.lease_seconds": {strconv.Itoa(int(callback.Lease.Seconds()))},
+	}
+	if callback.Secrets.YouTube != "" {
+		form.Set("hub.secret", callback.Secrets.YouTube)
+	}
+	hubURL := c.hubURL
+	if hubURL == "" {
+		hubURL = youtubeHubURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hubURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &APIError{Platform: "youtube", StatusCode: resp.StatusCode, Code: "hubRejected", Message: strings.TrimSpace(string(body))}
+	}
+
+	expiresAt := time.Now().Add(callback.Lease)
+	return &WebhookLease{Topic: topic, ExpiresAt: &expiresAt}, nil
+}
+
+// FetchRetention reads the video's audience retention since its creation from
+// the Analytics API, in hundredths of the video
+func (c *youtubeClient) FetchRetention(ctx context.Context, video *models.Video) ([]models.RetentionPoint, error) {
+	if video.YouTubeID == "" {
+		return nil, fmt.Errorf("video %s has no YouTube ID", video.ID)
+	}
+	res, err := c.analytics.Reports.Query().
+		Ids("channel==MINE").
+		StartDate(video.CreatedAt.UTC().Format(time.DateOnly)).
+		EndDate(time.Now().UTC().Format(time.DateOnly)).
+		Metrics("audienceWatchRatio").
+		Dimensions("elapsedVideoTimeRatio").
+		Filters("video==" + video.YouTubeID).
+		Sort("elapsedVideoTimeRatio").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, youtubeError(err)
+	}
+
+	elapsed, ratio := -1, -1
+	for i, header := range res.ColumnHeaders {
+		switch header.Name {
+		case "elapsedVideoTimeRatio":
+			elapsed = i
+		case "audienceWatchRatio":
+			ratio = i
+		}
+	}
+	if len(res.Rows) > 0 && (elapsed < 0 || ratio < 0) {
+		return nil, fmt.Errorf("youtube retention report lacks elapsedVideoTimeRatio or audienceWatchRatio")
+	}
+	points := make([]models.RetentionPoint, 0, len(res.Rows))
+	for _, row := range res.Rows {
+		if len(row) <= max(elapsed, ratio) {
+			continue
+		}
+		at, _ := row[elapsed].(float64) // JSON numbers
+		watching, _ := row[ratio].(float64)
+		points = append(points, models.RetentionPoint{Elapsed: at, Watching: watching})
+	}
+	return points, nil
+}
+
+// youtubeError converts Data and Analytics API error responses into an APIError
+func youtubeError(err error) error {
+	var gErr *googleapi.Error
+	if !errors.As(err, &gErr) {
+		return err
+	}
+
+	apiErr := &APIError{Platform: "youtube", StatusCode: gErr.Code, Message: gErr.Message}
+	if len(gErr.Errors) > 0 {
+		apiErr.Code = gErr.Errors[0].Reason
+	}
+	switch {
+	case gErr.Code == http.StatusUnauthorized:
+		apiErr.kind = ErrInvalidToken
+	case apiErr.Code == "quotaExceeded", apiErr.Code == "rateLimitExceeded", apiErr.Code == "uploadLimitExceeded":
+		apiErr.kind = ErrQuotaExceeded
+	case strings.HasPrefix(apiErr.Code, "invalid") && apiErr.Code != "invalidCredentials":
+		// invalidTitle, invalidDescription, invalidTags, invalidCategoryId...
+		apiErr.kind = ErrInvalidMetadata
+	}
+	return apiErr
+}
@@ -0,0 +1,247 @@
+package partners
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+)
+
+const (
+	youtubeUploadPath  = "/upload/youtube/v3/videos"
+	youtubeVideosPath  = "/youtube/v3/videos"
+	youtubeReportsPath = "/v2/reports"
+)
+
+func newTestYouTubeClient(t *testing.T, server *fixtureServer) *youtubeClient {
+	srv, err := newYouTubeService(context.Background(), server.Client(), server.URL+"/")
+	require.NoError(t, err)
+	analytics, err := newYouTubeAnalyticsService(context.Background(), server.Client(), server.URL+"/")
+	require.NoError(t, err)
+	return &youtubeClient{service: srv, analytics: analytics}
+}
+
+func testYouTubeVideo(t *testing.T) *models.Video {
+	path := filepath.Join(t.TempDir(), "video.mp4")
+	require.NoError(t, os.WriteFile(path, []byte("not really an mp4"), 0o600))
+	return &models.Video{Title: "The Lighthouse Keeper Who Vanished", FilePath: path, Tags: []string{"mystery", "history"}}
+}
+
+func TestYouTubeClient_UploadAndPublish(t *testing.T) {
+	server := newFixtureServer(t,
+		fixture{"POST", youtubeUploadPath, 200, "youtube/videos_insert.json"},
+		fixture{"PUT", youtubeVideosPath, 200, "youtube/videos_update.json"},
+	)
+	client := newTestYouTubeClient(t, server)
+	video := testYouTubeVideo(t)
+
+	externalID, err := client.Upload(context.Background(), video)
+	require.NoError(t, err)
+	assert.Equal(t, "Ks-_Mh1QhMc", externalID)
+
+	video.YouTubeID = externalID
+	require.NoError(t, client.Publish(context.Background(), video, &models.Workspace{}))
+
+	requests := server.received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "multipart", requests[0].query["uploadType"][0])
+	assert.Contains(t, string(requests[0].body), `"privacyStatus":"private"`, "videos are uploaded private")
+	assert.Contains(t, string(requests[1].body), `"privacyStatus":"public"`)
+	assert.Contains(t, string(requests[1].body), `"id":"Ks-_Mh1QhMc"`)
+}
+
+func TestYouTubeClient_Unpublish(t *testing.T) {
+	server := newFixtureServer(t, fixture{"PUT", youtubeVideosPath, 200, "youtube/videos_update.json"})
+	client := newTestYouTubeClient(t, server)
+
+	require.NoError(t, client.Unpublish(context.Background(), &models.Video{YouTubeID: "Ks-_Mh1QhMc"}))
+
+	requests := server.received()
+	require.Len(t, requests, 1)
+	assert.Contains(t, string(requests[0].body), `"privacyStatus":"private"`)
+	assert.Contains(t, string(requests[0].body), `"id":"Ks-_Mh1QhMc"`)
+}
+
+func TestYouTubeClient_Playlists(t *testing.T) {
+	server := newFixtureServer(t,
+		fixture{"POST", "/youtube/v3/playlists", 200, "youtube/playlists_insert.json"},
+		fixture{"POST", "/youtube/v3/playlistItems", 200, "youtube/playlist_items_insert.json"},
+	)
+	client := newTestYouTubeClient(t, server)
+
+	playlistID, err := client.CreatePlaylist(context.Background(), "Cold Cases", "")
+	require.NoError(t, err)
+	assert.Equal(t, "PLx9Fq2Tz7Rk4Vn1Wb6Jc3Ld8Mh5Yp0Gs", playlistID)
+	require.NoError(t, client.InsertPlaylistItem(context.Background(), playlistID, "Ks-_Mh1QhMc", 0))
+
+	requests := server.received()
+	require.Len(t, requests, 2)
+	assert.Contains(t, string(requests[0].body), `"privacyStatus":"public"`)
+	assert.Contains(t, string(requests[1].body), `"position":0`, "the first position is sent")
+	assert.Contains(t, string(requests[1].body), `"videoId":"Ks-_Mh1QhMc"`)
+}
+
+func TestYouTubeClient_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		file   string
+		kind   error
+		code   string
+	}{
+		{"quota exceeded", 403, "youtube/quota_exceeded.json", ErrQuotaExceeded, "quotaExceeded"},
+		{"invalid token", 401, "youtube/invalid_token.json", ErrInvalidToken, "authError"},
+		{"invalid title", 400, "youtube/invalid_title.json", ErrInvalidMetadata, "invalidTitle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFixtureServer(t,
+				fixture{"POST", youtubeUploadPath, tt.status, tt.file},
+				fixture{"PUT", youtubeVideosPath, tt.status, tt.file},
+			)
+			client := newTestYouTubeClient(t, server)
+			video := testYouTubeVideo(t)
+
+			_, err := client.Upload(context.Background(), video)
+			assert.ErrorIs(t, err, tt.kind)
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.code, apiErr.Code)
+
+			video.YouTubeID = "Ks-_Mh1QhMc"
+			assert.ErrorIs(t, client.Publish(context.Background(), video, &models.Workspace{}), tt.kind)
+		})
+	}
+}
+
+func TestYouTubeClient_FetchDailyStats(t *testing.T) {
+	server := newFixtureServer(t, fixture{"GET", youtubeReportsPath, 200, "youtube/reports_query.json"})
+	client := newTestYouTubeClient(t, server)
+	video := &models.Video{ID: "v1", YouTubeID: "Ks-_Mh1QhMc"}
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	days, err := client.FetchDailyStats(context.Background(), video, from, from.AddDate(0, 0, 7))
+	require.NoError(t, err)
+	require.Len(t, days, 3, "days without activity are left out")
+	assert.Equal(t, &DailyStats{Day: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Views: 1843, Likes: 97, Comments: 12, Shares: 8}, days[0])
+	assert.Equal(t, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), days[2].Day)
+
+	requests := server.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "2026-03-01", requests[0].query["startDate"][0])
+	assert.Equal(t, "2026-03-07", requests[0].query["endDate"][0], "the end date is inclusive")
+	assert.Equal(t, "video==Ks-_Mh1QhMc", requests[0].query["filters"][0])
+	assert.Equal(t, "day", requests[0].query["dimensions"][0])
+}
+
+func TestYouTubeClient_FetchDailyStatsQuotaExceeded(t *testing.T) {
+	server := newFixtureServer(t, fixture{"GET", youtubeReportsPath, 403, "youtube/quota_exceeded.json"})
+	client := newTestYouTubeClient(t, server)
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err := client.FetchDailyStats(context.Background(), &models.Video{YouTubeID: "Ks-_Mh1QhMc"}, from, from.AddDate(0, 0, 7))
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.True(t, SupportsHistory("youtube"))
+	assert.False(t, SupportsHistory("tiktok"))
+}
+
+func TestYouTubeClient_FetchRetention(t *testing.T) {
+	server := newFixtureServer(t, fixture{"GET", youtubeReportsPath, 200, "youtube/reports_retention.json"})
+	client := newTestYouTubeClient(t, server)
+
+	points, err := client.FetchRetention(context.Background(), &models.Video{ID: "v1", YouTubeID: "Ks-_Mh1QhMc"})
+	require.NoError(t, err)
+	require.Len(t, points, 4)
+	assert.Equal(t, models.RetentionPoint{Elapsed: 0.02, Watching: 0.812}, points[1])
+
+	requests := server.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "elapsedVideoTimeRatio", requests[0].query["dimensions"][0])
+	assert.Equal(t, "audienceWatchRatio", requests[0].query["metrics"][0])
+}
+
+func TestYouTubeClient_FetchTrafficSources(t *testing.T) {
+	server := newFixtureServer(t, fixture{"GET", youtubeReportsPath, 200, "youtube/reports_traffic_sources.json"})
+	client := newTestYouTubeClient(t, server)
+
+	raw, err := client.FetchTrafficSources(context.Background(), &models.Video{ID: "v1", YouTubeID: "Ks-_Mh1QhMc"})
+	require.NoError(t, err)
+	sources, err := NormalizeTrafficSources("youtube", raw)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.45, sources[models.TrafficSuggested], 1e-9)
+
+	requests := server.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "insightTrafficSourceType", requests[0].query["dimensions"][0])
+	assert.Equal(t, "video==Ks-_Mh1QhMc", requests[0].query["filters"][0])
+}
+
+func TestYouTubeClient_FetchDeviceTypes(t *testing.T) {
+	server := newFixtureServer(t, fixture{"GET", youtubeReportsPath, 200, "youtube/reports_device_types.json"})
+	client := newTestYouTubeClient(t, server)
+
+	raw, err := client.FetchDeviceTypes(context.Background(), &models.Video{ID: "v1", YouTubeID: "Ks-_Mh1QhMc"})
+	require.NoError(t, err)
+	devices, err := NormalizeDeviceTypes("youtube", raw)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.65, devices[models.DeviceMobile], 1e-9)
+
+	requests := server.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "deviceType", requests[0].query["dimensions"][0])
+	assert.Equal(t, "video==Ks-_Mh1QhMc", requests[0].query["filters"][0])
+}
+
+func TestYouTubeClient_SubscribeWebhook(t *testing.T) {
+	server := newFixtureServer(t,
+		fixture{"GET", "/youtube/v3/channels", 200, "youtube/channels_list.json"},
+		fixture{"POST", "/subscribe", 202, "youtube/hub_subscribe.txt"},
+	)
+	client := newTestYouTubeClient(t, server)
+	client.hubURL = server.URL + "/subscribe"
+
+	lease, err := client.SubscribeWebhook(context.Background(), &models.Workspace{ID: "ws-1"}, WebhookCallback{
+		URL:     "https://api.example.com/webhooks/youtube",
+		Secrets: WebhookSecrets{YouTube: "hub-secret"},
+		Lease:   5 * 24 * time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.youtube.com/xml/feeds/videos.xml?channel_id=UCm4Qw8Xr2Lk9Tz1Vb6Nc3Hd", lease.Topic)
+	require.NotNil(t, lease.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(5*24*time.Hour), *lease.ExpiresAt, time.Minute)
+	assert.False(t, lease.Verified, "the hub verifies asynchronously")
+
+	requests := server.received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "true", requests[0].query["mine"][0])
+	form, err := url.ParseQuery(string(requests[1].body))
+	require.NoError(t, err)
+	assert.Equal(t, "subscribe", form.Get("hub.mode"))
+	assert.Equal(t, lease.Topic, form.Get("hub.topic"))
+	assert.Equal(t, "https://api.example.com/webhooks/youtube", form.Get("hub.callback"))
+	assert.Equal(t, "432000", form.Get("hub.lease_seconds"))
+	assert.Equal(t, "hub-secret", form.Get("hub.secret"))
+}
+
+func TestYouTubeClient_SubscribeWebhookRejected(t *testing.T) {
+	server := newFixtureServer(t,
+		fixture{"GET", "/youtube/v3/channels", 200, "youtube/channels_list.json"},
+		fixture{"POST", "/subscribe", 400, "youtube/hub_subscribe.txt"},
+	)
+	client := newTestYouTubeClient(t, server)
+	client.hubURL = server.URL + "/subscribe"
+
+	_, err := client.SubscribeWebhook(context.Background(), &models.Workspace{ID: "ws-1"}, WebhookCallback{URL: "https://api.example.com/webhooks/youtube", Lease: time.Hour})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+}
@@ -0,0 +1,159 @@
+// Package redact removes credentials and secrets from HTTP exchanges before
+// they are stored or shown, keeping their shape so they stay useful to debug.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Placeholder replaces every redacted value
+const Placeholder = "[REDACTED]"
+
+// sensitiveFragments mark a header, field or parameter name as secret when
+// the lowercased name contains one of them
+var sensitiveFragments = []string{
+	"password", "passwd", "secret", "authoriz", "authent", "oauth",
+	"cookie", "session", "signature", "credential", "api_key", "apikey",
+	"api-key", "private_key", "private-key",
+}
+
+// sensitiveSuffixes end secret names: access_token and X-Csrf-Token are
+// secret, token counts such as tokens_used are not
+var sensitiveSuffixes = []string{"token"}
+
+// sensitiveNames are secret only as whole names, being too short to match on
+var sensitiveNames = map[string]bool{
+	"code":  true, // OAuth authorization codes
+	"key":   true,
+	"jwt":   true,
+	"otp":   true,
+	"pin":   true,
+	"ssn":   true,
+	"cvc":   true,
+	"cvv":   true,
+	"state": true, // OAuth state, bound to the session
+}
+
+// IsSensitive reports whether a header, field or parameter name holds a secret
+func IsSensitive(name string) bool {
+	lower := strings.ToLower(name)
+	if sensitiveNames[lower] {
+		return true
+	}
+	for _, fragment := range sensitiveFragments {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Headers returns a copy of h with the values of sensitive headers replaced
+func Headers(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		if IsSensitive(name) {
+			out[name] = []string{Placeholder}
+			continue
+		}
+		out[name] = append([]string(nil), values...)
+	}
+	return out
+}
+
+// Query returns a raw query string with the values of sensitive parameters
+// replaced. An unparseable query is dropped.
+func Query(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Placeholder
+	}
+	return redactValues(values).Encode()
+}
+
+// Body returns a body as text that is safe to store: JSON and form bodies
+// with sensitive fields replaced and other text as is. Binary bodies, and
+// JSON or form bodies that cannot be parsed, for instance because they were
+// truncated, are replaced by a note since they could not be checked.
+func Body(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			return omitted(len(body), "unparseable JSON")
+		}
+		redacted, err := json.Marshal(redactJSON(value))
+		if err != nil {
+			return omitted(len(body), "unparseable JSON")
+		}
+		return string(redacted)
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return omitted(len(body), "unparseable form")
+		}
+		return redactValues(values).Encode()
+	case strings.HasPrefix(mediaType, "text/"):
+		return string(body)
+	case mediaType == "":
+		return omitted(len(body), "untyped")
+	default:
+		return omitted(len(body), mediaType)
+	}
+}
+
+// redactJSON replaces the values of sensitive object keys, at any depth
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if IsSensitive(key) {
+				v[key] = Placeholder
+				continue
+			}
+			v[key] = redactJSON(field)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func redactValues(values url.Values) url.Values {
+	for name := range values {
+		if IsSensitive(name) {
+			values[name] = []string{Placeholder}
+		}
+	}
+	return values
+}
+
+func omitted(size int, kind string) string {
+	return fmt.Sprintf("[%d bytes of %s body omitted]", size, kind)
+}
@@ -0,0 +1,69 @@
+package redact
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaders(t *testing.T) {
+	h := http.Header{
+		"Authorization":       {"Bearer eyJhbGciOi"},
+		"Cookie":              {"session=abc"},
+		"X-Hub-Signature-256": {"sha256=deadbeef"},
+		"X-Api-Key":           {"k-123"},
+		"Content-Type":        {"application/json"},
+		"X-Request-Id":        {"req-1"},
+	}
+
+	out := Headers(h)
+	assert.Equal(t, []string{Placeholder}, out["Authorization"])
+	assert.Equal(t, []string{Placeholder}, out["Cookie"])
+	assert.Equal(t, []string{Placeholder}, out["X-Hub-Signature-256"])
+	assert.Equal(t, []string{Placeholder}, out["X-Api-Key"])
+	assert.Equal(t, []string{"application/json"}, out["Content-Type"])
+	assert.Equal(t, []string{"req-1"}, out["X-Request-Id"])
+	assert.Equal(t, []string{"Bearer eyJhbGciOi"}, h["Authorization"], "the original headers are untouched")
+}
+
+func TestQuery(t *testing.T) {
+	assert.Equal(t, "code=%5BREDACTED%5D&platform=youtube", Query("platform=youtube&code=4/0Ad"))
+	assert.Equal(t, "", Query(""))
+}
+
+func TestBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:        "nested JSON secrets",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"email":"a@acme.test","password":"hunter2","workspace":{"tiktok_secret":"s","name":"Acme"},"tokens_used":12,"items":[{"access_token":"t"}]}`,
+			want:        `{"email":"a@acme.test","items":[{"access_token":"[REDACTED]"}],"password":"[REDACTED]","tokens_used":12,"workspace":{"name":"Acme","tiktok_secret":"[REDACTED]"}}`,
+		},
+		{name: "truncated JSON", contentType: "application/json", body: `{"password":"hun`, want: "[16 bytes of unparseable JSON body omitted]"},
+		{name: "form", contentType: "application/x-www-form-urlencoded", body: "grant_type=refresh&refresh_token=r1", want: "grant_type=refresh&refresh_token=%5BREDACTED%5D"},
+		{name: "text", contentType: "text/csv", body: "video_id,views\nv1,10\n", want: "video_id,views\nv1,10\n"},
+		{name: "binary", contentType: "video/mp4", body: "\x00\x00\x00\x18ftyp", want: "[8 bytes of video/mp4 body omitted]"},
+		{name: "untyped", contentType: "", body: "raw", want: "[3 bytes of untyped body omitted]"},
+		{name: "empty", contentType: "application/json", body: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Body(tt.contentType, []byte(tt.body)))
+		})
+	}
+}
+
+func TestIsSensitive(t *testing.T) {
+	for _, name := range []string{"password", "X-Twitter-Webhooks-Signature", "client_secret", "code", "Set-Cookie", "refreshToken", "X-Csrf-Token"} {
+		assert.True(t, IsSensitive(name), name)
+	}
+	for _, name := range []string{"author", "country_code", "keywords", "privacy", "prompt_key", "input_tokens", "title"} {
+		assert.False(t, IsSensitive(name), name)
+	}
+}
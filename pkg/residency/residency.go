@@ -0,0 +1,153 @@
+// Package residency describes where tenant data is stored and processed, so
+// tenants bound to a region never have their data sent outside it.
+package residency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Residency is a data residency zone
+type Residency string
+
+const (
+	US Residency = "us"
+	EU Residency = "eu"
+)
+
+// All lists the supported residencies
+var All = []Residency{US, EU}
+
+var (
+	// ErrUnknownResidency is returned for a residency that is not supported
+	ErrUnknownResidency = errors.New("unknown residency")
+	// ErrNotConfigured is returned for a supported residency without a placement
+	ErrNotConfigured = errors.New("residency is not configured")
+	// ErrCrossRegion is returned when data of a pinned tenant would leave its residency
+	ErrCrossRegion = errors.New("cross-region data movement is forbidden for this tenant")
+)
+
+// Parse validates a residency name
+func Parse(name string) (Residency, error) {
+	r := Residency(strings.ToLower(strings.TrimSpace(name)))
+	for _, known := range All {
+		if r == known {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownResidency, name)
+}
+
+// Placement is where the data of one residency lives
+type Placement struct {
+	Residency     Residency `json:"residency"`
+	AWSRegion     string    `json:"aws_region"`
+	S3Bucket      string    `json:"s3_bucket"`
+	BedrockRegion string    `json:"bedrock_region"`
+	// BedrockSecondaryRegion takes Bedrock calls when BedrockRegion fails;
+	// it must be in the same residency. Empty disables failover.
+	BedrockSecondaryRegion string `json:"bedrock_secondary_region,omitempty"`
+}
+
+// Placements holds the placement of every configured residency
+type Placements struct {
+	byResidency map[Residency]Placement
+	defaultRes  Residency
+}
+
+// NewPlacements validates the placements; defaultResidency is used for
+// tenants that never chose one and must be among them
+func NewPlacements(defaultResidency Residency, placements ...Placement) (*Placements, error) {
+	p := &Placements{byResidency: make(map[Residency]Placement), defaultRes: defaultResidency}
+	for _, placement := range placements {
+		if _, err := Parse(string(placement.Residency)); err != nil {
+			return nil, err
+		}
+		if placement.AWSRegion == "" {
+			return nil, fmt.Errorf("residency %s: AWS region is required", placement.Residency)
+		}
+		if placement.BedrockRegion == "" {
+			placement.BedrockRegion = placement.AWSRegion
+		}
+		if placement.BedrockSecondaryRegion == placement.BedrockRegion {
+			return nil, fmt.Errorf("residency %s: Bedrock secondary region must differ from %s", placement.Residency, placement.BedrockRegion)
+		}
+		if _, dup := p.byResidency[placement.Residency]; dup {
+			return nil, fmt.Errorf("residency %s is configured twice", placement.Residency)
+		}
+		p.byResidency[placement.Residency] = placement
+	}
+
+	for r, placement := range p.byResidency {
+		for other, o := range p.byResidency {
+			if r != other && placement.S3Bucket != "" && placement.S3Bucket == o.S3Bucket {
+				return nil, fmt.Errorf("residencies %s and %s share bucket %s", r, other, placement.S3Bucket)
+			}
+		}
+	}
+
+	if _, ok := p.byResidency[defaultResidency]; !ok {
+		return nil, fmt.Errorf("%w: default residency %s", ErrNotConfigured, defaultResidency)
+	}
+	return p, nil
+}
+
+// Default returns the residency of tenants that never chose one
+func (p *Placements) Default() Residency {
+	return p.defaultRes
+}
+
+// Get returns the placement of a residency; the empty residency is the default
+func (p *Placements) Get(r Residency) (Placement, error) {
+	if r == "" {
+		r = p.defaultRes
+	}
+	placement, ok := p.byResidency[r]
+	if !ok {
+		return Placement{}, fmt.Errorf("%w: %s", ErrNotConfigured, r)
+	}
+	return placement, nil
+}
+
+// Configured lists the configured residencies in name order
+func (p *Placements) Configured() []Residency {
+	configured := make([]Residency, 0, len(p.byResidency))
+	for r := range p.byResidency {
+		configured = append(configured, r)
+	}
+	sort.Slice(configured, func(i, j int) bool { return configured[i] < configured[j] })
+	return configured
+}
+
+// BucketResidency returns the residency owning bucket
+func (p *Placements) BucketResidency(bucket string) (Residency, bool) {
+	for r, placement := range p.byResidency {
+		if bucket != "" && placement.S3Bucket == bucket {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+type contextKey struct{}
+
+// Scope is the residency a request runs under. Pinned tenants fail instead of
+// falling back to another residency's resources.
+type Scope struct {
+	Residency Residency
+	Pinned    bool
+}
+
+// WithScope returns a context carrying the residency of the current tenant
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, contextKey{}, scope)
+}
+
+// FromContext returns the residency scope carried by ctx
+func FromContext(ctx context.Context) (Scope, bool) {
+	scope, ok := ctx.Value(contextKey{}).(Scope)
+	return scope, ok
+}
@@ -0,0 +1,69 @@
+package residency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	r, err := Parse(" EU ")
+	require.NoError(t, err)
+	assert.Equal(t, EU, r)
+
+	_, err = Parse("apac")
+	assert.ErrorIs(t, err, ErrUnknownResidency)
+}
+
+func TestNewPlacements(t *testing.T) {
+	us := Placement{Residency: US, AWSRegion: "us-east-1", S3Bucket: "videos-us"}
+	eu := Placement{Residency: EU, AWSRegion: "eu-west-1", S3Bucket: "videos-eu", BedrockRegion: "eu-central-1"}
+
+	p, err := NewPlacements(US, us, eu)
+	require.NoError(t, err)
+	assert.Equal(t, []Residency{EU, US}, p.Configured())
+
+	got, err := p.Get("")
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", got.BedrockRegion, "Bedrock defaults to the AWS region")
+
+	got, err = p.Get(EU)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-central-1", got.BedrockRegion)
+
+	r, ok := p.BucketResidency("videos-eu")
+	assert.True(t, ok)
+	assert.Equal(t, EU, r)
+	_, ok = p.BucketResidency("")
+	assert.False(t, ok)
+
+	tests := []struct {
+		name       string
+		def        Residency
+		placements []Placement
+	}{
+		{name: "default not configured", def: EU, placements: []Placement{us}},
+		{name: "missing region", def: US, placements: []Placement{{Residency: US}}},
+		{name: "unknown residency", def: US, placements: []Placement{us, {Residency: "apac", AWSRegion: "ap-south-1"}}},
+		{name: "secondary Bedrock region is the primary", def: US, placements: []Placement{{Residency: US, AWSRegion: "us-east-1", BedrockSecondaryRegion: "us-east-1"}}},
+		{name: "shared bucket", def: US, placements: []Placement{us, {Residency: EU, AWSRegion: "eu-west-1", S3Bucket: "videos-us"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPlacements(tt.def, tt.placements...)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScopeContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := WithScope(context.Background(), Scope{Residency: EU, Pinned: true})
+	scope, ok := FromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, Scope{Residency: EU, Pinned: true}, scope)
+}
@@ -0,0 +1,208 @@
+// Package scheduler runs periodic background jobs on one server instance at a
+// time. Every instance runs the same Scheduler; before each run a job's lease
+// is taken or renewed, and only the lease holder runs it, so adding replicas
+// does not multiply the work or the external API calls.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// releaseTimeout bounds releasing the leases on shutdown
+const releaseTimeout = 5 * time.Second
+
+// Locker grants time-limited leases shared by all instances
+type Locker interface {
+	// AcquireLease takes or renews the lease on name for ttl and reports
+	// whether holder now holds it
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// ReleaseLease gives up the lease on name if holder holds it
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+// Job is a task run every Interval by the instance holding its lease
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// leaseTTL keeps the lease across one missed tick, so the holder stays leader
+// while it is alive and another instance takes over within two intervals when
+// it is not
+func (j Job) leaseTTL() time.Duration {
+	return 2 * j.Interval
+}
+
+// Scheduler runs jobs on the instance holding their lease
+type Scheduler struct {
+	locker Locker
+	holder string
+	logger *logger.Logger
+	jobs   []Job
+}
+
+// New creates a scheduler identified by holder in the leases
+func New(locker Locker, holder string, logger *logger.Logger) *Scheduler {
+	return &Scheduler{locker: locker, holder: holder, logger: logger}
+}
+
+// DefaultHolder identifies this process: the host name, which is the pod
+// name on Kubernetes, and a random suffix so restarts are told apart
+func DefaultHolder() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "instance"
+	}
+	return fmt.Sprintf("%s-%s", host, id.New()[24:])
+}
+
+// Register adds a job; it must be called before Run
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Interval <= 0 || job.Run == nil {
+		return fmt.Errorf("invalid job %q: name, a positive interval and a run function are required", job.Name)
+	}
+	for _, registered := range s.jobs {
+		if registered.Name == job.Name {
+			return fmt.Errorf("job %q is already registered", job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+// Run runs the jobs until ctx is cancelled, then releases the leases this
+// instance holds
+func (s *Scheduler) Run(ctx context.Context) {
+	s.logger.Info("Starting scheduler", "holder", s.holder, "jobs", len(s.jobs))
+
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+
+	s.logger.Info("Scheduler stopped", "holder", s.holder)
+}
+
+// loop attempts the job at start and then every interval
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	leader := false
+	for {
+		leader = s.tick(ctx, job, leader)
+
+		select {
+		case <-ctx.Done():
+			if leader {
+				s.release(job)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick runs the job once if this instance holds or takes its lease, and
+// returns whether it still holds the lease
+func (s *Scheduler) tick(ctx context.Context, job Job, wasLeader bool) bool {
+	leader, err := s.locker.AcquireLease(ctx, job.Name, s.holder, job.leaseTTL())
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("Failed to acquire job lease", "error", err, "job", job.Name)
+		}
+		return false
+	}
+	if leader != wasLeader {
+		if leader {
+			s.logger.Info("Took over job", "job", job.Name, "holder", s.holder)
+		} else {
+			s.logger.Info("Job taken over by another instance", "job", job.Name, "holder", s.holder)
+		}
+	}
+	if !leader {
+		return false
+	}
+	return s.run(ctx, job)
+}
+
+// run calls the job while renewing its lease, so a run longer than the lease
+// does not let another instance start it too. The run is cancelled if the
+// lease is lost.
+func (s *Scheduler) run(ctx context.Context, job Job) bool {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lost := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(job.leaseTTL() / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				held, err := s.locker.AcquireLease(runCtx, job.Name, s.holder, job.leaseTTL())
+				if err == nil && !held {
+					s.logger.Warn("Lost job lease during run", "job", job.Name, "holder", s.holder)
+					close(lost)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	start := time.Now()
+	err := runJob(runCtx, job)
+	close(done)
+
+	if err != nil {
+		s.logger.Error("Job failed", "error", err, "job", job.Name, "duration", time.Since(start))
+	} else {
+		s.logger.Debug("Job completed", "job", job.Name, "duration", time.Since(start))
+	}
+
+	select {
+	case <-lost:
+		return false
+	default:
+		return true
+	}
+}
+
+// runJob calls the job, turning a panic into an error so one broken job does
+// not take the other jobs and the process down
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// release gives up the job's lease so another instance takes over at its next
+// tick instead of after the lease expires
+func (s *Scheduler) release(job Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := s.locker.ReleaseLease(ctx, job.Name, s.holder); err != nil {
+		s.logger.Warn("Failed to release job lease", "error", err, "job", job.Name)
+	}
+}
@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memoryLocker is a Locker shared by the schedulers of one test
+type memoryLocker struct {
+	mu     sync.Mutex
+	now    time.Time
+	leases map[string]memoryLease
+	err    error
+}
+
+type memoryLease struct {
+	holder  string
+	expires time.Time
+}
+
+func newMemoryLocker() *memoryLocker {
+	return &memoryLocker{now: time.Now(), leases: make(map[string]memoryLease)}
+}
+
+func (l *memoryLocker) AcquireLease(_ context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return false, l.err
+	}
+	lease, ok := l.leases[name]
+	if !ok || !lease.expires.After(l.now) || lease.holder == holder {
+		l.leases[name] = memoryLease{holder: holder, expires: l.now.Add(ttl)}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (l *memoryLocker) ReleaseLease(_ context.Context, name, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lease, ok := l.leases[name]; ok && lease.holder == holder {
+		lease.expires = l.now
+		l.leases[name] = lease
+	}
+	return nil
+}
+
+func (l *memoryLocker) advance(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = l.now.Add(d)
+}
+
+func countingJob(runs *int32) Job {
+	return Job{
+		Name:     "stats-sync",
+		Interval: time.Hour,
+		Run: func(context.Context) error {
+			atomic.AddInt32(runs, 1)
+			return nil
+		},
+	}
+}
+
+func TestScheduler_OneReplicaRunsEachTick(t *testing.T) {
+	locker := newMemoryLocker()
+	log := logger.New("error", "test")
+	replicas := []*Scheduler{New(locker, "pod-a", log), New(locker, "pod-b", log), New(locker, "pod-c", log)}
+
+	var runs int32
+	job := countingJob(&runs)
+	leaders := make([]bool, len(replicas))
+	for tick := 0; tick < 3; tick++ {
+		for i, s := range replicas {
+			leaders[i] = s.tick(context.Background(), job, leaders[i])
+		}
+		locker.advance(job.Interval)
+	}
+
+	assert.Equal(t, int32(3), runs, "three replicas run the job once per tick")
+	assert.Equal(t, []bool{true, false, false}, leaders, "the first replica keeps the lease")
+}
+
+func TestScheduler_TakesOverAfterRelease(t *testing.T) {
+	locker := newMemoryLocker()
+	log := logger.New("error", "test")
+	a, b := New(locker, "pod-a", log), New(locker, "pod-b", log)
+
+	var runs int32
+	job := countingJob(&runs)
+	require.True(t, a.tick(context.Background(), job, false))
+	assert.False(t, b.tick(context.Background(), job, false))
+
+	a.release(job)
+	assert.True(t, b.tick(context.Background(), job, false), "a released lease is taken at the next tick")
+	assert.Equal(t, int32(2), runs)
+}
+
+func TestScheduler_TakesOverAfterExpiry(t *testing.T) {
+	locker := newMemoryLocker()
+	log := logger.New("error", "test")
+	a, b := New(locker, "pod-a", log), New(locker, "pod-b", log)
+
+	var runs int32
+	job := countingJob(&runs)
+	require.True(t, a.tick(context.Background(), job, false))
+
+	// pod-a stops without releasing the lease
+	locker.advance(job.Interval)
+	assert.False(t, b.tick(context.Background(), job, false), "the lease outlives one missed tick")
+	locker.advance(job.Interval)
+	assert.True(t, b.tick(context.Background(), job, false))
+	assert.Equal(t, int32(2), runs)
+}
+
+func TestScheduler_SkipsRunWhenLockerFails(t *testing.T) {
+	locker := newMemoryLocker()
+	locker.err = errors.New("database unavailable")
+	s := New(locker, "pod-a", logger.New("error", "test"))
+
+	var runs int32
+	assert.False(t, s.tick(context.Background(), countingJob(&runs), true))
+	assert.Zero(t, runs)
+}
+
+func TestScheduler_RecoversJobPanic(t *testing.T) {
+	s := New(newMemoryLocker(), "pod-a", logger.New("error", "test"))
+	job := Job{Name: "stats-sync", Interval: time.Hour, Run: func(context.Context) error { panic("nil map") }}
+
+	assert.True(t, s.tick(context.Background(), job, false), "the instance keeps the lease after a panic")
+	assert.ErrorContains(t, runJob(context.Background(), job), "job panicked: nil map")
+}
+
+func TestScheduler_Register(t *testing.T) {
+	s := New(newMemoryLocker(), "pod-a", logger.New("error", "test"))
+	var runs int32
+
+	require.NoError(t, s.Register(countingJob(&runs)))
+	assert.Error(t, s.Register(countingJob(&runs)), "job names are unique")
+	assert.Error(t, s.Register(Job{Name: "no-interval", Run: func(context.Context) error { return nil }}))
+}
+
+func TestScheduler_RunReleasesLeasesOnShutdown(t *testing.T) {
+	locker := newMemoryLocker()
+	s := New(locker, "pod-a", logger.New("error", "test"))
+
+	started := make(chan struct{})
+	var once sync.Once
+	require.NoError(t, s.Register(Job{
+		Name:     "campaign-scheduler",
+		Interval: time.Hour,
+		Run: func(context.Context) error {
+			once.Do(func() { close(started) })
+			return nil
+		},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(stopped)
+	}()
+
+	<-started
+	cancel()
+	<-stopped
+
+	held, err := locker.AcquireLease(context.Background(), "campaign-scheduler", "pod-b", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, held)
+}
+
+func TestDefaultHolder(t *testing.T) {
+	assert.NotEqual(t, DefaultHolder(), DefaultHolder(), "restarts on the same host get a new holder")
+}
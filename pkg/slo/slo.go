@@ -0,0 +1,247 @@
+// Package slo tracks latency objectives of HTTP endpoints, such as 99% of
+// GET /api/v1/videos answered within 300ms, and their Apdex.
+package slo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+)
+
+// Outcomes of a request against its objective's threshold T, as in Apdex:
+// satisfied within T, tolerating within 4T, frustrated beyond 4T or when the
+// request failed with a server error
+const (
+	Satisfied  = "satisfied"
+	Tolerating = "tolerating"
+	Frustrated = "frustrated"
+)
+
+// toleratingFactor is how many thresholds a tolerated request may take
+const toleratingFactor = 4
+
+// Objective is the share of an endpoint's requests that must be answered
+// within a threshold
+type Objective struct {
+	Method    string
+	Route     string
+	Threshold time.Duration
+	// Target is the share of requests that must be satisfied, from 0 to 1
+	Target float64
+}
+
+// Name identifies the objective in metrics and reports
+func (o Objective) Name() string {
+	return o.Method + " " + o.Route
+}
+
+// Parse reads objectives written as METHOD ROUTE=THRESHOLD@PERCENT and
+// separated by semicolons, such as
+// "GET /api/v1/videos=300ms@99;POST /api/v1/ai/magic-brush=5s@95". Routes
+// are Gin route patterns, with their parameters: /api/v1/videos/:id.
+func Parse(spec string) ([]Objective, error) {
+	var objectives []Objective
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		endpoint, budget, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid SLO %q: expected METHOD ROUTE=THRESHOLD@PERCENT", entry)
+		}
+		fields := strings.Fields(endpoint)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "/") {
+			return nil, fmt.Errorf("invalid SLO %q: expected a method and a route", entry)
+		}
+		threshold, percent, ok := strings.Cut(budget, "@")
+		if !ok {
+			return nil, fmt.Errorf("invalid SLO %q: expected THRESHOLD@PERCENT", entry)
+		}
+
+		objective := Objective{Method: strings.ToUpper(fields[0]), Route: fields[1]}
+		var err error
+		if objective.Threshold, err = time.ParseDuration(strings.TrimSpace(threshold)); err != nil || objective.Threshold <= 0 {
+			return nil, fmt.Errorf("invalid SLO %q: threshold must be a positive duration such as 300ms", entry)
+		}
+		target, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(percent), "%"), 64)
+		if err != nil || target <= 0 || target > 100 {
+			return nil, fmt.Errorf("invalid SLO %q: percent must be above 0 and at most 100", entry)
+		}
+		objective.Target = target / 100
+
+		if seen[objective.Name()] {
+			return nil, fmt.Errorf("invalid SLO %q: %s already has an objective", entry, objective.Name())
+		}
+		seen[objective.Name()] = true
+		objectives = append(objectives, objective)
+	}
+	return objectives, nil
+}
+
+// Recorder is told about every request of an endpoint with an objective, to
+// export metrics
+type Recorder interface {
+	// RecordSLORequest observes a request of the named objective
+	RecordSLORequest(objective, outcome string, duration time.Duration)
+}
+
+// Compliance is how an endpoint met its objective over the tracker's window
+type Compliance struct {
+	Objective   string  `json:"objective"`
+	Method      string  `json:"method"`
+	Route       string  `json:"route"`
+	ThresholdMs int64   `json:"threshold_ms"`
+	Target      float64 `json:"target"`
+	Requests    int     `json:"requests"`
+	Satisfied   int     `json:"satisfied"`
+	Tolerating  int     `json:"tolerating"`
+	Frustrated  int     `json:"frustrated"`
+	// Compliance is the share of satisfied requests, 1 without requests
+	Compliance float64 `json:"compliance"`
+	// Apdex is (satisfied + tolerating/2) / requests, 1 without requests
+	Apdex float64 `json:"apdex"`
+	Met   bool    `json:"met"`
+}
+
+// minute holds the outcomes counted during one minute
+type minute struct {
+	start                             time.Time
+	satisfied, tolerating, frustrated int
+}
+
+type tracked struct {
+	objective Objective
+	minutes   []minute
+}
+
+// Tracker counts the outcomes of the requests of endpoints with objectives
+// over a sliding window, one minute at a time. It is safe for concurrent use.
+// Each replica tracks its own requests; the metrics cover them all.
+type Tracker struct {
+	mu        sync.Mutex
+	endpoints map[string]*tracked
+	order     []string
+	window    time.Duration
+	recorder  Recorder
+	clock     clock.Clock
+}
+
+// NewTracker creates a tracker of objectives reporting over window;
+// recorder may be nil
+func NewTracker(objectives []Objective, window time.Duration, recorder Recorder, clk clock.Clock) *Tracker {
+	t := &Tracker{
+		endpoints: make(map[string]*tracked, len(objectives)),
+		window:    window,
+		recorder:  recorder,
+		clock:     clk,
+	}
+	for _, objective := range objectives {
+		t.endpoints[objective.Name()] = &tracked{objective: objective}
+		t.order = append(t.order, objective.Name())
+	}
+	return t
+}
+
+// Outcome classifies a request taking duration and answered with status
+// against threshold
+func Outcome(threshold, duration time.Duration, status int) string {
+	switch {
+	case status >= 500 || duration > toleratingFactor*threshold:
+		return Frustrated
+	case duration > threshold:
+		return Tolerating
+	default:
+		return Satisfied
+	}
+}
+
+// Observe counts a request of method on route when the endpoint has an
+// objective, and ignores it otherwise
+func (t *Tracker) Observe(method, route string, status int, duration time.Duration) {
+	if t == nil || route == "" {
+		return
+	}
+	endpoint, ok := t.endpoints[method+" "+route]
+	if !ok {
+		return
+	}
+	outcome := Outcome(endpoint.objective.Threshold, duration, status)
+	if t.recorder != nil {
+		t.recorder.RecordSLORequest(endpoint.objective.Name(), outcome, duration)
+	}
+
+	now := t.clock.Now().Truncate(time.Minute)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n := len(endpoint.minutes); n == 0 || !endpoint.minutes[n-1].start.Equal(now) {
+		endpoint.minutes = append(t.expired(endpoint.minutes, now), minute{start: now})
+	}
+	current := &endpoint.minutes[len(endpoint.minutes)-1]
+	switch outcome {
+	case Satisfied:
+		current.satisfied++
+	case Tolerating:
+		current.tolerating++
+	default:
+		current.frustrated++
+	}
+}
+
+// expired drops the minutes that left the window ending at now
+func (t *Tracker) expired(minutes []minute, now time.Time) []minute {
+	cutoff := now.Add(-t.window)
+	i := sort.Search(len(minutes), func(i int) bool {
+		return minutes[i].start.After(cutoff)
+	})
+	return append(minutes[:0], minutes[i:]...)
+}
+
+// Report returns the compliance of every objective over the window, in the
+// order they were defined
+func (t *Tracker) Report() []Compliance {
+	now := t.clock.Now().Truncate(time.Minute)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := make([]Compliance, 0, len(t.order))
+	for _, name := range t.order {
+		endpoint := t.endpoints[name]
+		endpoint.minutes = t.expired(endpoint.minutes, now)
+
+		c := Compliance{
+			Objective:   name,
+			Method:      endpoint.objective.Method,
+			Route:       endpoint.objective.Route,
+			ThresholdMs: endpoint.objective.Threshold.Milliseconds(),
+			Target:      endpoint.objective.Target,
+			Compliance:  1,
+			Apdex:       1,
+		}
+		for _, m := range endpoint.minutes {
+			c.Satisfied += m.satisfied
+			c.Tolerating += m.tolerating
+			c.Frustrated += m.frustrated
+		}
+		c.Requests = c.Satisfied + c.Tolerating + c.Frustrated
+		if c.Requests > 0 {
+			c.Compliance = float64(c.Satisfied) / float64(c.Requests)
+			c.Apdex = (float64(c.Satisfied) + float64(c.Tolerating)/2) / float64(c.Requests)
+		}
+		c.Met = c.Compliance >= c.Target
+		report = append(report, c)
+	}
+	return report
+}
+
+// Window is the duration reports cover
+func (t *Tracker) Window() time.Duration {
+	return t.window
+}
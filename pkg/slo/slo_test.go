@@ -0,0 +1,104 @@
+package slo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedRequest struct {
+	objective string
+	outcome   string
+}
+
+type recordingRecorder struct {
+	requests []recordedRequest
+}
+
+func (r *recordingRecorder) RecordSLORequest(objective, outcome string, duration time.Duration) {
+	r.requests = append(r.requests, recordedRequest{objective: objective, outcome: outcome})
+}
+
+func TestParse(t *testing.T) {
+	objectives, err := Parse(" GET /api/v1/videos=300ms@99 ; post /api/v1/ai/magic-brush=5s@99.5%;")
+	require.NoError(t, err)
+	assert.Equal(t, []Objective{
+		{Method: "GET", Route: "/api/v1/videos", Threshold: 300 * time.Millisecond, Target: 0.99},
+		{Method: "POST", Route: "/api/v1/ai/magic-brush", Threshold: 5 * time.Second, Target: 0.995},
+	}, objectives)
+
+	objectives, err = Parse("")
+	require.NoError(t, err)
+	assert.Empty(t, objectives)
+
+	for _, spec := range []string{
+		"GET /api/v1/videos",
+		"/api/v1/videos=300ms@99",
+		"GET /api/v1/videos=300ms",
+		"GET /api/v1/videos=fast@99",
+		"GET /api/v1/videos=300ms@0",
+		"GET /api/v1/videos=300ms@101",
+		"GET /api/v1/videos=300ms@99;GET /api/v1/videos=1s@90",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	threshold := 300 * time.Millisecond
+	assert.Equal(t, Satisfied, Outcome(threshold, 300*time.Millisecond, 200))
+	assert.Equal(t, Tolerating, Outcome(threshold, 301*time.Millisecond, 200))
+	assert.Equal(t, Tolerating, Outcome(threshold, 1200*time.Millisecond, 404))
+	assert.Equal(t, Frustrated, Outcome(threshold, 1201*time.Millisecond, 200))
+	assert.Equal(t, Frustrated, Outcome(threshold, 10*time.Millisecond, 503))
+}
+
+func TestTracker_Report(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(now)
+	recorder := &recordingRecorder{}
+	objectives := []Objective{
+		{Method: "GET", Route: "/api/v1/videos", Threshold: 300 * time.Millisecond, Target: 0.75},
+		{Method: "GET", Route: "/api/v1/videos/:id", Threshold: 100 * time.Millisecond, Target: 0.99},
+	}
+	tracker := NewTracker(objectives, time.Hour, recorder, clk)
+
+	// An old request leaves the window before the report
+	tracker.Observe("GET", "/api/v1/videos", 200, 2*time.Second)
+	clk.Advance(90 * time.Minute)
+
+	tracker.Observe("GET", "/api/v1/videos", 200, 100*time.Millisecond)
+	tracker.Observe("GET", "/api/v1/videos", 200, 200*time.Millisecond)
+	clk.Advance(time.Minute)
+	tracker.Observe("GET", "/api/v1/videos", 200, 250*time.Millisecond)
+	tracker.Observe("GET", "/api/v1/videos", 200, 500*time.Millisecond)
+	tracker.Observe("POST", "/api/v1/videos", 201, 5*time.Second)
+	tracker.Observe("GET", "", 404, time.Millisecond)
+
+	report := tracker.Report()
+	require.Len(t, report, 2)
+	videos := report[0]
+	assert.Equal(t, "GET /api/v1/videos", videos.Objective)
+	assert.Equal(t, int64(300), videos.ThresholdMs)
+	assert.Equal(t, 4, videos.Requests)
+	assert.Equal(t, 3, videos.Satisfied)
+	assert.Equal(t, 1, videos.Tolerating)
+	assert.InDelta(t, 0.75, videos.Compliance, 1e-9)
+	assert.InDelta(t, 0.875, videos.Apdex, 1e-9)
+	assert.True(t, videos.Met)
+
+	idle := report[1]
+	assert.Zero(t, idle.Requests)
+	assert.Equal(t, 1.0, idle.Apdex)
+	assert.True(t, idle.Met, "an endpoint without requests meets its objective")
+
+	assert.Len(t, recorder.requests, 5, "only endpoints with an objective are recorded")
+	assert.Equal(t, recordedRequest{objective: "GET /api/v1/videos", outcome: Frustrated}, recorder.requests[0])
+
+	var unset *Tracker
+	assert.NotPanics(t, func() { unset.Observe("GET", "/api/v1/videos", 200, time.Second) })
+}
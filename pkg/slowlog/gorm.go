@@ -0,0 +1,79 @@
+package slowlog
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// startKey holds the start time of a statement in its instance settings
+const startKey = "slowlog:start"
+
+// Plugin is a GORM plugin reporting statements slower than the database
+// threshold, with their SQL (placeholders only, never the bound values),
+// table and rows affected
+type Plugin struct {
+	observer *Observer
+}
+
+var _ gorm.Plugin = (*Plugin)(nil)
+
+// NewPlugin creates the slow query plugin
+func NewPlugin(observer *Observer) *Plugin {
+	return &Plugin{observer: observer}
+}
+
+// Name returns the plugin name
+func (p *Plugin) Name() string {
+	return "slowlog"
+}
+
+// Initialize times every kind of statement from its first callback to its last
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	registrations := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("*").Register, cb.Create().After("*").Register},
+		{"query", cb.Query().Before("*").Register, cb.Query().After("*").Register},
+		{"update", cb.Update().Before("*").Register, cb.Update().After("*").Register},
+		{"delete", cb.Delete().Before("*").Register, cb.Delete().After("*").Register},
+		{"row", cb.Row().Before("*").Register, cb.Row().After("*").Register},
+		{"raw", cb.Raw().Before("*").Register, cb.Raw().After("*").Register},
+	}
+	for _, r := range registrations {
+		if err := r.before("slowlog:before_"+r.operation, start); err != nil {
+			return err
+		}
+		if err := r.after("slowlog:after_"+r.operation, p.finish(r.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func start(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func (p *Plugin) finish(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startKey)
+		started, isTime := value.(time.Time)
+		if !ok || !isTime {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "raw"
+		}
+		p.observer.Observe(db.Statement.Context, Database, table, started, db.Error,
+			"operation", operation,
+			"sql", db.Statement.SQL.String(),
+			"rows", db.Statement.RowsAffected,
+		)
+	}
+}
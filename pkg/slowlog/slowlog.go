@@ -0,0 +1,93 @@
+// Package slowlog reports calls to the database, partner platforms and
+// Bedrock that take longer than their dependency's threshold.
+package slowlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
+)
+
+// Dependencies whose calls are timed
+const (
+	Database = "database"
+	Platform = "platform"
+	Bedrock  = "bedrock"
+)
+
+// Recorder is told about slow calls, to export metrics
+type Recorder interface {
+	// RecordSlowOperation observes the duration of a slow call of dependency
+	// to target: a table, a platform or a model
+	RecordSlowOperation(dependency, target string, duration time.Duration)
+}
+
+// Thresholds are the durations above which calls to each dependency are slow
+type Thresholds struct {
+	Database time.Duration
+	Platform time.Duration
+	Bedrock  time.Duration
+}
+
+// Observer logs and counts slow calls. A nil Observer ignores every call, so
+// clients can be built without one in tests and tools.
+type Observer struct {
+	thresholds Thresholds
+	recorder   Recorder
+	logger     *logger.Logger
+}
+
+// New creates an observer; recorder may be nil
+func New(thresholds Thresholds, recorder Recorder, logger *logger.Logger) *Observer {
+	return &Observer{thresholds: thresholds, recorder: recorder, logger: logger}
+}
+
+// Threshold returns the duration above which calls to dependency are slow,
+// or 0 for an unknown dependency
+func (o *Observer) Threshold(dependency string) time.Duration {
+	switch dependency {
+	case Database:
+		return o.thresholds.Database
+	case Platform:
+		return o.thresholds.Platform
+	case Bedrock:
+		return o.thresholds.Bedrock
+	default:
+		return 0
+	}
+}
+
+// Observe reports a call of dependency to target started at start, when it
+// took longer than the dependency's threshold. fields are key-value pairs
+// describing the call, added to the log entry along with the tenant and
+// trace carried by ctx.
+func (o *Observer) Observe(ctx context.Context, dependency, target string, start time.Time, err error, fields ...interface{}) {
+	if o == nil {
+		return
+	}
+	duration := time.Since(start)
+	threshold := o.Threshold(dependency)
+	if threshold <= 0 || duration <= threshold {
+		return
+	}
+
+	if o.recorder != nil {
+		o.recorder.RecordSlowOperation(dependency, target, duration)
+	}
+
+	entry := append([]interface{}{
+		"dependency", dependency,
+		"target", target,
+		"duration_ms", duration.Milliseconds(),
+		"threshold_ms", threshold.Milliseconds(),
+	}, fields...)
+	if tenantID, ok := tenancy.FromContext(ctx); ok {
+		entry = append(entry, "tenant_id", tenantID)
+	}
+	if err != nil {
+		entry = append(entry, "error", err)
+	}
+	o.logger.WithContext(ctx).Warn("Slow "+dependency+" call", entry...)
+}
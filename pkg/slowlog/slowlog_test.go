@@ -0,0 +1,73 @@
+package slowlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type slowCall struct {
+	dependency string
+	target     string
+}
+
+type recordingRecorder struct {
+	calls []slowCall
+}
+
+func (r *recordingRecorder) RecordSlowOperation(dependency, target string, duration time.Duration) {
+	r.calls = append(r.calls, slowCall{dependency: dependency, target: target})
+}
+
+func TestObserve_OnlyReportsCallsAboveThreshold(t *testing.T) {
+	recorder := &recordingRecorder{}
+	observer := New(Thresholds{Database: 200 * time.Millisecond, Bedrock: 10 * time.Second}, recorder, logger.New("error", "test"))
+
+	observer.Observe(context.Background(), Database, "videos", time.Now().Add(-50*time.Millisecond), nil)
+	observer.Observe(context.Background(), Bedrock, "claude", time.Now().Add(-5*time.Second), nil)
+	observer.Observe(context.Background(), Database, "videos", time.Now().Add(-300*time.Millisecond), nil)
+	observer.Observe(context.Background(), Platform, "youtube", time.Now().Add(-time.Hour), nil)
+
+	assert.Equal(t, []slowCall{{dependency: Database, target: "videos"}}, recorder.calls, "dependencies without a threshold are not reported")
+
+	var unset *Observer
+	assert.NotPanics(t, func() {
+		unset.Observe(context.Background(), Database, "videos", time.Now().Add(-time.Hour), nil)
+	})
+}
+
+func TestPlugin_ReportsSlowStatements(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	recorder := &recordingRecorder{}
+	observer := New(Thresholds{Database: 20 * time.Millisecond}, recorder, logger.New("error", "test"))
+	require.NoError(t, gormDB.Use(NewPlugin(observer)))
+
+	mock.ExpectQuery("SELECT \\* FROM `documents`").
+		WillDelayFor(50 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("doc-1"))
+	mock.ExpectQuery("SELECT \\* FROM `documents`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("doc-1"))
+
+	var rows []map[string]interface{}
+	require.NoError(t, gormDB.Table("documents").Find(&rows).Error)
+	require.NoError(t, gormDB.Table("documents").Find(&rows).Error)
+
+	assert.Equal(t, []slowCall{{dependency: Database, target: "documents"}}, recorder.calls)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,205 @@
+// Package supervisor keeps long-lived background loops running. A loop that
+// panics, fails or returns before it is told to stop is restarted after a
+// backoff doubling at each consecutive failure, so one broken worker neither
+// takes the process down nor spins. Loops report whether they are up, and
+// the instance is not ready while a critical one is down.
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// Default restart backoff: it starts at minBackoff, doubles up to maxBackoff
+// and starts over once a loop stayed up for maxBackoff
+const (
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = time.Minute
+)
+
+// Why a loop stopped, as told to the Recorder
+const (
+	ReasonPanic = "panic"
+	ReasonError = "error"
+	ReasonExit  = "exit" // It returned nil before being told to stop
+)
+
+// Recorder is told when loops start and stop, to export metrics
+type Recorder interface {
+	// SetLoopUp records whether the loop is running
+	SetLoopUp(loop string, up bool)
+	// RecordLoopRestart records that the loop stopped for reason and will
+	// be restarted
+	RecordLoopRestart(loop, reason string)
+}
+
+// Loop is a background task running until its context is cancelled
+type Loop struct {
+	Name string
+	Run  func(ctx context.Context) error
+	// Critical loops make the instance unready while they are down
+	Critical bool
+}
+
+// Status is the state of a loop
+type Status struct {
+	Name      string    `json:"name"`
+	Critical  bool      `json:"critical"`
+	Up        bool      `json:"up"`
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"last_error,omitempty"`
+	Since     time.Time `json:"since"` // When it last started or stopped
+}
+
+// Supervisor runs loops and tracks their status
+type Supervisor struct {
+	recorder   Recorder
+	logger     *logger.Logger
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu    sync.Mutex
+	loops map[string]*Status
+}
+
+// New creates a supervisor; recorder may be nil
+func New(recorder Recorder, logger *logger.Logger) *Supervisor {
+	return &Supervisor{
+		recorder:   recorder,
+		logger:     logger,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+		loops:      make(map[string]*Status),
+	}
+}
+
+// Start runs the loop in the background until ctx is cancelled, restarting
+// it whenever it stops before. The returned channel is closed once the loop
+// has returned for good.
+func (s *Supervisor) Start(ctx context.Context, loop Loop) (<-chan struct{}, error) {
+	if loop.Name == "" || loop.Run == nil {
+		return nil, fmt.Errorf("invalid loop %q: a name and a run function are required", loop.Name)
+	}
+	s.mu.Lock()
+	if _, ok := s.loops[loop.Name]; ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("loop %q is already started", loop.Name)
+	}
+	s.loops[loop.Name] = &Status{Name: loop.Name, Critical: loop.Critical}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.supervise(ctx, loop)
+	}()
+	return done, nil
+}
+
+// supervise runs the loop until ctx is cancelled, waiting longer after each
+// failure that follows a short run
+func (s *Supervisor) supervise(ctx context.Context, loop Loop) {
+	backoff := s.minBackoff
+	for {
+		s.setUp(loop.Name, true, nil)
+		start := time.Now()
+		reason, err := s.runOnce(ctx, loop)
+		s.setUp(loop.Name, false, err)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(start) >= s.maxBackoff {
+			backoff = s.minBackoff
+		}
+		s.logger.Error("Background loop stopped, restarting", "loop", loop.Name, "reason", reason, "error", err, "backoff", backoff)
+		s.mu.Lock()
+		s.loops[loop.Name].Restarts++
+		s.mu.Unlock()
+		if s.recorder != nil {
+			s.recorder.RecordLoopRestart(loop.Name, reason)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, s.maxBackoff)
+	}
+}
+
+// runOnce calls the loop, turning a panic into an error
+func (s *Supervisor) runOnce(ctx context.Context, loop Loop) (reason string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Background loop panicked", "loop", loop.Name, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+			reason, err = ReasonPanic, fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	if err := loop.Run(ctx); err != nil {
+		return ReasonError, err
+	}
+	if ctx.Err() == nil {
+		return ReasonExit, errors.New("returned before being stopped")
+	}
+	return "", nil
+}
+
+func (s *Supervisor) setUp(name string, up bool, err error) {
+	s.mu.Lock()
+	status := s.loops[name]
+	status.Up = up
+	status.Since = time.Now()
+	if err != nil {
+		status.LastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	if s.recorder != nil {
+		s.recorder.SetLoopUp(name, up)
+	}
+}
+
+// Statuses returns the state of every loop, by name
+func (s *Supervisor) Statuses() []Status {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]Status, 0, len(s.loops))
+	for _, status := range s.loops {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Ready returns an error naming the critical loops that are down; a nil
+// supervisor is always ready
+func (s *Supervisor) Ready() error {
+	var down []string
+	for _, status := range s.Statuses() {
+		if status.Critical && !status.Up {
+			down = append(down, status.Name)
+		}
+	}
+	if len(down) > 0 {
+		return fmt.Errorf("background loops down: %s", strings.Join(down, ", "))
+	}
+	return nil
+}
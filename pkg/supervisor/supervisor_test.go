@@ -0,0 +1,114 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// memoryRecorder keeps what the supervisor recorded
+type memoryRecorder struct {
+	mu       sync.Mutex
+	up       map[string]bool
+	restarts map[string][]string
+}
+
+func newMemoryRecorder() *memoryRecorder {
+	return &memoryRecorder{up: make(map[string]bool), restarts: make(map[string][]string)}
+}
+
+func (r *memoryRecorder) SetLoopUp(loop string, up bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.up[loop] = up
+}
+
+func (r *memoryRecorder) RecordLoopRestart(loop, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.restarts[loop] = append(r.restarts[loop], reason)
+}
+
+func (r *memoryRecorder) reasons(loop string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.restarts[loop]...)
+}
+
+func newTestSupervisor(recorder Recorder) *Supervisor {
+	s := New(recorder, logger.New("error", "test"))
+	s.minBackoff = time.Millisecond
+	s.maxBackoff = 4 * time.Millisecond
+	return s
+}
+
+func TestSupervisor_RestartsAfterPanicsAndFailures(t *testing.T) {
+	recorder := newMemoryRecorder()
+	s := newTestSupervisor(recorder)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	done, err := s.Start(ctx, Loop{Name: "worker", Critical: true, Run: func(ctx context.Context) error {
+		switch runs.Add(1) {
+		case 1:
+			panic("nil map")
+		case 2:
+			return errors.New("connection reset")
+		case 3:
+			return nil
+		}
+		<-ctx.Done()
+		return nil
+	}})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return runs.Load() == 4 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{ReasonPanic, ReasonError, ReasonExit}, recorder.reasons("worker"))
+	require.Eventually(t, func() bool { return s.Ready() == nil }, time.Second, time.Millisecond)
+	statuses := s.Statuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, 3, statuses[0].Restarts)
+	assert.Equal(t, "returned before being stopped", statuses[0].LastError)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the loop was not stopped")
+	}
+	assert.Len(t, recorder.reasons("worker"), 3, "a loop told to stop is not restarted")
+	assert.False(t, recorder.up["worker"])
+}
+
+func TestSupervisor_Ready(t *testing.T) {
+	s := newTestSupervisor(nil)
+	s.minBackoff = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	failing := func(ctx context.Context) error { return errors.New("broken") }
+	_, err := s.Start(ctx, Loop{Name: "scheduler", Critical: true, Run: failing})
+	require.NoError(t, err)
+	_, err = s.Start(ctx, Loop{Name: "prompts", Run: failing})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return s.Ready() != nil }, time.Second, time.Millisecond)
+	assert.EqualError(t, s.Ready(), "background loops down: scheduler", "only critical loops make the instance unready")
+
+	_, err = s.Start(ctx, Loop{Name: "scheduler", Run: failing})
+	assert.Error(t, err, "names are unique")
+	_, err = s.Start(ctx, Loop{Name: "nameless"})
+	assert.Error(t, err)
+
+	var none *Supervisor
+	assert.NoError(t, none.Ready())
+}
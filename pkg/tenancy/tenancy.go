@@ -0,0 +1,186 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Column is the column that identifies the owning tenant of a row
+const Column = "tenant_id"
+
+var (
+	// ErrMissingTenant is returned when a statement on a tenant-scoped table has no tenant in its context
+	ErrMissingTenant = errors.New("tenant-scoped query without tenant context")
+	// ErrTenantMismatch is returned when a written record belongs to another tenant than the context
+	ErrTenantMismatch = errors.New("record belongs to another tenant")
+	// ErrUnscopedUpsert is returned for upserts that would overwrite every column of a conflicting row
+	ErrUnscopedUpsert = errors.New("upsert updating all columns is not allowed on tenant-scoped tables")
+)
+
+type contextKey struct{}
+
+// scope is the tenancy scope carried by a context
+type scope struct {
+	tenantID   string
+	allTenants bool
+}
+
+// WithTenant returns a context scoping database statements to tenantID
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, scope{tenantID: tenantID})
+}
+
+// WithAllTenants returns a context for system work that deliberately spans
+// tenants, such as schedulers, TTL cleanup and seeding
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, scope{allTenants: true})
+}
+
+// FromContext returns the tenant carried by ctx
+func FromContext(ctx context.Context) (string, bool) {
+	s, _ := ctx.Value(contextKey{}).(scope)
+	return s.tenantID, s.tenantID != ""
+}
+
+// Plugin is a GORM plugin enforcing tenant isolation on every model with a
+// tenant_id column: reads, updates and deletes are filtered by the context
+// tenant, creates are stamped with it, and statements without a tenant in
+// their context fail with ErrMissingTenant. Raw SQL is not inspected.
+type Plugin struct{}
+
+var _ gorm.Plugin = (*Plugin)(nil)
+
+// NewPlugin creates the tenancy plugin
+func NewPlugin() *Plugin {
+	return &Plugin{}
+}
+
+// Name returns the plugin name
+func (p *Plugin) Name() string {
+	return "tenancy"
+}
+
+// Initialize registers the tenancy callbacks. Writes are checked before the
+// transaction opens so rejected statements never reach the database.
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:begin_transaction").Register("tenancy:create", assignTenant); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tenancy:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tenancy:row", scopeTenant); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:begin_transaction").Register("tenancy:update", scopeTenant); err != nil {
+		return err
+	}
+	return cb.Delete().Before("gorm:begin_transaction").Register("tenancy:delete", scopeTenant)
+}
+
+// scopeTenant adds the tenant filter to reads, updates and deletes
+func scopeTenant(db *gorm.DB) {
+	field := tenantField(db.Statement)
+	if db.Error != nil || field == nil || db.Statement.SQL.Len() > 0 {
+		return
+	}
+
+	tenantID, ok := resolveTenant(db)
+	if !ok || tenantID == "" {
+		return
+	}
+
+	// Updates must not move the row to another tenant
+	if db.Statement.ReflectValue.Kind() == reflect.Struct {
+		checkRecord(db, field, db.Statement.ReflectValue, tenantID, false)
+	}
+	if values, ok := db.Statement.Dest.(map[string]interface{}); ok {
+		for _, key := range []string{field.Name, field.DBName} {
+			if v, found := values[key]; found && v != tenantID {
+				db.AddError(fmt.Errorf("%w: %s", ErrTenantMismatch, db.Statement.Table))
+			}
+		}
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: Column}, Value: tenantID},
+	}})
+}
+
+// assignTenant stamps created records with the context tenant
+func assignTenant(db *gorm.DB) {
+	field := tenantField(db.Statement)
+	if db.Error != nil || field == nil {
+		return
+	}
+
+	tenantID, ok := resolveTenant(db)
+	if !ok || tenantID == "" {
+		return
+	}
+
+	// ON DUPLICATE KEY UPDATE of every column could overwrite another tenant's row
+	if c, found := db.Statement.Clauses["ON CONFLICT"]; found {
+		if onConflict, isOnConflict := c.Expression.(clause.OnConflict); isOnConflict && onConflict.UpdateAll {
+			db.AddError(fmt.Errorf("%w: %s", ErrUnscopedUpsert, db.Statement.Table))
+			return
+		}
+	}
+
+	switch rv := db.Statement.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			checkRecord(db, field, reflect.Indirect(rv.Index(i)), tenantID, true)
+		}
+	case reflect.Struct:
+		checkRecord(db, field, rv, tenantID, true)
+	}
+}
+
+// resolveTenant returns the context tenant, an empty tenant for cross-tenant
+// work, or records ErrMissingTenant and reports false
+func resolveTenant(db *gorm.DB) (string, bool) {
+	s, _ := db.Statement.Context.Value(contextKey{}).(scope)
+	if s.allTenants {
+		return "", true
+	}
+	if s.tenantID == "" {
+		db.AddError(fmt.Errorf("%w: %s", ErrMissingTenant, db.Statement.Table))
+		return "", false
+	}
+	return s.tenantID, true
+}
+
+// checkRecord verifies a record belongs to tenantID, assigning it when empty and assign is set
+func checkRecord(db *gorm.DB, field *schema.Field, rv reflect.Value, tenantID string, assign bool) {
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+	value, isZero := field.ValueOf(db.Statement.Context, rv)
+	if isZero {
+		if assign {
+			if err := field.Set(db.Statement.Context, rv, tenantID); err != nil {
+				db.AddError(err)
+			}
+		}
+		return
+	}
+	if value != tenantID {
+		db.AddError(fmt.Errorf("%w: %s", ErrTenantMismatch, db.Statement.Table))
+	}
+}
+
+// tenantField returns the tenant_id field of the statement model, if any
+func tenantField(stmt *gorm.Statement) *schema.Field {
+	if stmt.Schema == nil {
+		return nil
+	}
+	return stmt.Schema.LookUpField(Column)
+}
@@ -0,0 +1,185 @@
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type document struct {
+	ID       string `gorm:"primaryKey;type:varchar(36)"`
+	TenantID string `gorm:"type:varchar(36)"`
+	Title    string
+}
+
+type setting struct {
+	ID    string `gorm:"primaryKey;type:varchar(36)"`
+	Value string
+}
+
+func newTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gormDB.Use(NewPlugin()))
+	return gormDB, mock
+}
+
+func tenantDB(db *gorm.DB, tenantID string) *gorm.DB {
+	return db.WithContext(WithTenant(context.Background(), tenantID))
+}
+
+func TestQueryIsScopedToContextTenant(t *testing.T) {
+	db, mock := newTestDB(t)
+
+	mock.ExpectQuery("SELECT \\* FROM `documents` WHERE id = \\? AND `documents`.`tenant_id` = \\?").
+		WithArgs("doc-1", "tenant-b", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "title"}))
+
+	var doc document
+	err := tenantDB(db, "tenant-b").Where("id = ?", "doc-1").First(&doc).Error
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "tenant-b cannot read tenant-a's document")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryWithoutTenantIsRejected(t *testing.T) {
+	db, mock := newTestDB(t)
+
+	var docs []document
+	err := db.Find(&docs).Error
+	assert.ErrorIs(t, err, ErrMissingTenant)
+
+	err = tenantDB(db, "").Find(&docs).Error
+	assert.ErrorIs(t, err, ErrMissingTenant, "an empty tenant is not a scope")
+
+	err = db.Model(&document{}).Where("id = ?", "doc-1").Update("title", "x").Error
+	assert.ErrorIs(t, err, ErrMissingTenant)
+
+	err = db.Where("id = ?", "doc-1").Delete(&document{}).Error
+	assert.ErrorIs(t, err, ErrMissingTenant)
+
+	require.NoError(t, mock.ExpectationsWereMet(), "no SQL reaches the database")
+}
+
+func TestUpdateAndDeleteAreScoped(t *testing.T) {
+	db, mock := newTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `documents` SET `title`=\\? WHERE id = \\? AND `documents`.`tenant_id` = \\?").
+		WithArgs("renamed", "doc-1", "tenant-b").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `documents` WHERE id = \\? AND `documents`.`tenant_id` = \\?").
+		WithArgs("doc-1", "tenant-b").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	res := tenantDB(db, "tenant-b").Model(&document{}).Where("id = ?", "doc-1").Update("title", "renamed")
+	require.NoError(t, res.Error)
+	assert.Zero(t, res.RowsAffected)
+
+	res = tenantDB(db, "tenant-b").Where("id = ?", "doc-1").Delete(&document{})
+	require.NoError(t, res.Error)
+	assert.Zero(t, res.RowsAffected)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCannotMoveRecordToAnotherTenant(t *testing.T) {
+	db, mock := newTestDB(t)
+
+	doc := &document{ID: "doc-1", TenantID: "tenant-a", Title: "Doc"}
+	err := tenantDB(db, "tenant-b").Select("*").Save(doc).Error
+	assert.ErrorIs(t, err, ErrTenantMismatch)
+
+	err = tenantDB(db, "tenant-b").Model(&document{}).Where("id = ?", "doc-1").
+		Updates(map[string]interface{}{"tenant_id": "tenant-a"}).Error
+	assert.ErrorIs(t, err, ErrTenantMismatch)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStampsTenant(t *testing.T) {
+	db, mock := newTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `documents`").
+		WithArgs("doc-1", "tenant-a", "Doc").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	doc := &document{ID: "doc-1", Title: "Doc"}
+	require.NoError(t, tenantDB(db, "tenant-a").Create(doc).Error)
+	assert.Equal(t, "tenant-a", doc.TenantID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateRejectsForeignTenant(t *testing.T) {
+	db, mock := newTestDB(t)
+
+	docs := []*document{
+		{ID: "doc-1", TenantID: "tenant-a"},
+		{ID: "doc-2", TenantID: "tenant-b"},
+	}
+	err := tenantDB(db, "tenant-a").Create(&docs).Error
+	assert.ErrorIs(t, err, ErrTenantMismatch)
+
+	err = db.Create(&document{ID: "doc-3"}).Error
+	assert.ErrorIs(t, err, ErrMissingTenant)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertOverwritingAllColumnsIsRejected(t *testing.T) {
+	db, mock := newTestDB(t)
+
+	doc := &document{ID: "doc-1", TenantID: "tenant-a"}
+	err := tenantDB(db, "tenant-a").Clauses(clause.OnConflict{UpdateAll: true}).Create(doc).Error
+	assert.ErrorIs(t, err, ErrUnscopedUpsert)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAllTenantsSkipsFilter(t *testing.T) {
+	db, mock := newTestDB(t)
+
+	mock.ExpectQuery("SELECT \\* FROM `documents`$").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}).AddRow("doc-1", "tenant-a").AddRow("doc-2", "tenant-b"))
+
+	var docs []document
+	require.NoError(t, db.WithContext(WithAllTenants(context.Background())).Find(&docs).Error)
+	assert.Len(t, docs, 2)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestModelsWithoutTenantColumnAreUnaffected(t *testing.T) {
+	db, mock := newTestDB(t)
+
+	mock.ExpectQuery("SELECT \\* FROM `settings`$").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "value"}))
+
+	var settings []setting
+	require.NoError(t, db.Find(&settings).Error)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFromContext(t *testing.T) {
+	tenantID, ok := FromContext(WithTenant(context.Background(), "tenant-a"))
+	assert.True(t, ok)
+	assert.Equal(t, "tenant-a", tenantID)
+
+	_, ok = FromContext(WithAllTenants(context.Background()))
+	assert.False(t, ok)
+
+	_, ok = FromContext(context.Background())
+	assert.False(t, ok)
+}
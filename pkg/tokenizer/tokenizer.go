@@ -0,0 +1,52 @@
+package tokenizer
+
+import (
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// Tokenizer counts the tokens a model would see for a piece of text
+type Tokenizer interface {
+	Count(text string) int
+}
+
+// encodingName is the BPE vocabulary the tokenizer encodes with
+const encodingName = "cl100k_base"
+
+// loadEncoding reads the vocabulary embedded in the binary once, so no
+// tokenizer ever downloads it at runtime
+var loadEncoding = sync.OnceValues(func() (*tiktoken.Tiktoken, error) {
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+	return tiktoken.GetEncoding(encodingName)
+})
+
+// bpe counts tokens by running the cl100k_base byte-pair encoding. Claude's own
+// vocabulary is not published, so counts are close to but not exactly what
+// Bedrock bills; billed usage comes from the usage each response reports.
+type bpe struct {
+	encoding *tiktoken.Tiktoken
+}
+
+var _ Tokenizer = (*bpe)(nil)
+
+// NewBPE creates a tokenizer backed by the embedded cl100k_base vocabulary.
+// It panics if the embedded vocabulary cannot be decoded, which only a broken
+// build can cause.
+func NewBPE() Tokenizer {
+	encoding, err := loadEncoding()
+	if err != nil {
+		panic("tokenizer: failed to load " + encodingName + ": " + err.Error())
+	}
+	return &bpe{encoding: encoding}
+}
+
+// Count returns the number of tokens in the given text. Special tokens such as
+// <|endoftext|> are counted as the plain text they are written with.
+func (t *bpe) Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	return len(t.encoding.EncodeOrdinary(text))
+}
@@ -0,0 +1,38 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The expected counts are the published cl100k_base reference counts
+func TestTokenizer_Count(t *testing.T) {
+	tok := NewBPE()
+
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{name: "Empty text", text: "", expected: 0},
+		{name: "Two words", text: "hello world", expected: 2},
+		{name: "Punctuation", text: "Hello, world!", expected: 4},
+		{name: "Sentence", text: "tiktoken is great!", expected: 6},
+		{name: "Long word split by merges", text: "antidisestablishmentarianism", expected: 6},
+		{name: "Arithmetic", text: "2 + 2 = 4", expected: 7},
+		{name: "Non-Latin script", text: "お誕生日おめでとう", expected: 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tok.Count(tt.text))
+		})
+	}
+}
+
+func TestTokenizer_CountsSpecialTokensAsText(t *testing.T) {
+	tok := NewBPE()
+
+	assert.Greater(t, tok.Count("<|endoftext|>"), 1)
+}
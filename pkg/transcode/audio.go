@@ -0,0 +1,64 @@
+package transcode
+
+import (
+	"strconv"
+	"strings"
+)
+
+// languageCodes are the ISO 639-2 codes MP4 tags audio tracks with, by
+// ISO 639-1 code
+var languageCodes = map[string]string{
+	"ar": "ara", "de": "deu", "en": "eng", "es": "spa", "fr": "fra", "hi": "hin", "id": "ind", "it": "ita",
+	"ja": "jpn", "ko": "kor", "nl": "nld", "pl": "pol", "pt": "por", "ru": "rus", "sv": "swe", "tr": "tur",
+	"uk": "ukr", "vi": "vie", "zh": "zho",
+}
+
+// LanguageCode returns the ISO 639-2 code of a language such as "pt-BR",
+// or "und" when it is unknown
+func LanguageCode(language string) string {
+	base, _, _ := strings.Cut(strings.ToLower(language), "-")
+	if code, ok := languageCodes[base]; ok {
+		return code
+	}
+	return "und"
+}
+
+// AudioInput is an audio file to add to a rendition as a track
+type AudioInput struct {
+	Language string `json:"language"`
+	Path     string `json:"path"`
+	// Offset delays the track by seconds, the length of the intro stitched
+	// before the video
+	Offset float64 `json:"offset,omitempty"`
+}
+
+// MuxArgs returns the ffmpeg arguments, up to the output path, adding the
+// dubbed audio tracks to a transcoded rendition. Its video and first audio
+// track, tagged with the original language and played by default, are
+// copied; the dubs are encoded and normalized as the profile's audio, and
+// delayed past the intro when one was stitched.
+func (p Profile) MuxArgs(rendition, language string, dubs []AudioInput) []string {
+	args := []string{"-i", rendition}
+	for _, dub := range dubs {
+		if dub.Offset > 0 {
+			args = append(args, "-itsoffset", strconv.FormatFloat(dub.Offset, 'f', -1, 64))
+		}
+		args = append(args, "-i", dub.Path)
+	}
+	args = append(args, "-map", "0:v:0", "-map", "0:a:0")
+	for i := range dubs {
+		args = append(args, "-map", strconv.Itoa(i+1)+":a:0")
+	}
+
+	args = append(args, "-c:v", "copy", "-c:a:0", "copy",
+		"-metadata:s:a:0", "language="+LanguageCode(language), "-disposition:a:0", "default")
+	for i, dub := range dubs {
+		stream := strconv.Itoa(i + 1)
+		args = append(args, "-c:a:"+stream, AudioEncoders[p.AudioCodec], "-b:a:"+stream, strconv.Itoa(p.AudioBitrateKbps)+"k")
+		if p.LoudnessLUFS != 0 {
+			args = append(args, "-filter:a:"+stream, p.loudnorm())
+		}
+		args = append(args, "-metadata:s:a:"+stream, "language="+LanguageCode(dub.Language), "-disposition:a:"+stream, "0")
+	}
+	return append(args, "-movflags", "+faststart")
+}
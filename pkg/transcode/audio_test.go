@@ -0,0 +1,33 @@
+package transcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageCode(t *testing.T) {
+	assert.Equal(t, "fra", LanguageCode("fr"))
+	assert.Equal(t, "por", LanguageCode("pt-BR"))
+	assert.Equal(t, "und", LanguageCode("tlh"))
+}
+
+func TestProfile_MuxArgs(t *testing.T) {
+	profile := Profiles[Landscape1080p]
+	assert.Equal(t, []string{
+		"-i", "video.mp4",
+		"-map", "0:v:0", "-map", "0:a:0",
+		"-c:v", "copy", "-c:a:0", "copy", "-metadata:s:a:0", "language=eng", "-disposition:a:0", "default",
+		"-movflags", "+faststart",
+	}, profile.MuxArgs("video.mp4", "en", nil))
+
+	profile.LoudnessLUFS = -14
+	assert.Equal(t, []string{
+		"-i", "video.mp4", "-i", "fr.wav", "-itsoffset", "4.5", "-i", "es.wav",
+		"-map", "0:v:0", "-map", "0:a:0", "-map", "1:a:0", "-map", "2:a:0",
+		"-c:v", "copy", "-c:a:0", "copy", "-metadata:s:a:0", "language=eng", "-disposition:a:0", "default",
+		"-c:a:1", "aac", "-b:a:1", "192k", "-filter:a:1", "loudnorm=I=-14:TP=-1.5:LRA=11", "-metadata:s:a:1", "language=fra", "-disposition:a:1", "0",
+		"-c:a:2", "aac", "-b:a:2", "192k", "-filter:a:2", "loudnorm=I=-14:TP=-1.5:LRA=11", "-metadata:s:a:2", "language=spa", "-disposition:a:2", "0",
+		"-movflags", "+faststart",
+	}, profile.MuxArgs("video.mp4", "en", []AudioInput{{Language: "fr", Path: "fr.wav"}, {Language: "es", Path: "es.wav", Offset: 4.5}}))
+}
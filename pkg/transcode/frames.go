@@ -0,0 +1,96 @@
+package transcode
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// Frames extracted to pick thumbnails from, at the size thumbnails are
+// rendered. Long videos space their frames further apart so they never need
+// more than maxIntervalFrames.
+const (
+	maxIntervalFrames = 300
+	// SceneThreshold is how different from the previous one a frame must be,
+	// from 0 to 1, to start a scene
+	SceneThreshold = 0.4
+	// MaxSceneFrames bounds the scene-change frames kept
+	MaxSceneFrames = 50
+)
+
+var sceneTime = regexp.MustCompile(`Parsed_showinfo_\d+[^\n]*pts_time:\s*([\d.]+)`)
+
+// FramePlan is how the frames of a video are extracted: one every
+// IntervalSeconds, numbered from 1 as FrameName, and the first frame of each
+// scene, numbered from 1 as SceneName
+type FramePlan struct {
+	IntervalSeconds int     `json:"interval_seconds"`
+	Frames          int     `json:"frames"`
+	SceneThreshold  float64 `json:"scene_threshold"`
+	MaxScenes       int     `json:"max_scenes"`
+}
+
+// PlanFrames plans the frames of a video lasting durationSeconds
+func PlanFrames(durationSeconds float64) (*FramePlan, error) {
+	if durationSeconds <= 0 {
+		return nil, ErrNoDuration
+	}
+	interval := int(math.Max(1, math.Ceil(durationSeconds/maxIntervalFrames)))
+	return &FramePlan{
+		IntervalSeconds: interval,
+		Frames:          int(math.Ceil(durationSeconds / float64(interval))),
+		SceneThreshold:  SceneThreshold,
+		MaxScenes:       MaxSceneFrames,
+	}, nil
+}
+
+// frameFilter scales and crops frames to the thumbnail size
+func frameFilter() string {
+	return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,setsar=1",
+		ThumbnailWidth, ThumbnailHeight, ThumbnailWidth, ThumbnailHeight)
+}
+
+// IntervalArgs returns the ffmpeg arguments extracting the interval frames
+// of the input, to be followed by a numbered output such as frame-%04d.jpg
+func (p *FramePlan) IntervalArgs(input string) []string {
+	return []string{
+		"-i", input,
+		"-vf", fmt.Sprintf("fps=1/%d,%s", p.IntervalSeconds, frameFilter()),
+		"-frames:v", strconv.Itoa(p.Frames), "-q:v", "3",
+	}
+}
+
+// SceneArgs returns the ffmpeg arguments extracting the first frame of each
+// scene, to be followed by a numbered output such as scene-%03d.jpg. The
+// showinfo lines it logs are what ParseSceneLog reads.
+func (p *FramePlan) SceneArgs(input string) []string {
+	return []string{
+		"-i", input,
+		"-vf", fmt.Sprintf("select='gt(scene,%.2f)',showinfo,%s", p.SceneThreshold, frameFilter()),
+		"-vsync", "vfr", "-frames:v", strconv.Itoa(p.MaxScenes), "-q:v", "3",
+	}
+}
+
+// ParseSceneLog returns the time of each scene-change frame logged by ffmpeg
+// run with SceneArgs, in the order they were written
+func ParseSceneLog(log []byte) []float64 {
+	var seconds []float64
+	for _, match := range sceneTime.FindAllSubmatch(log, -1) {
+		at, err := strconv.ParseFloat(string(match[1]), 64)
+		if err == nil {
+			seconds = append(seconds, at)
+		}
+	}
+	return seconds
+}
+
+// FrameName is the file name of the nth interval frame, from 1
+func FrameName(n int) string {
+	return fmt.Sprintf("frame-%04d.jpg", n)
+}
+
+// SceneName is the file name of the nth scene-change frame, from 1
+func SceneName(n int) string {
+	return fmt.Sprintf("scene-%03d.jpg", n)
+}
@@ -0,0 +1,41 @@
+package transcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanFrames(t *testing.T) {
+	plan, err := PlanFrames(95.5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, plan.IntervalSeconds)
+	assert.Equal(t, 96, plan.Frames)
+
+	plan, err = PlanFrames(3600)
+	require.NoError(t, err)
+	assert.Equal(t, 12, plan.IntervalSeconds)
+	assert.Equal(t, 300, plan.Frames)
+	assert.Equal(t, []string{
+		"-i", "in.mp4",
+		"-vf", "fps=1/12,scale=1280:720:force_original_aspect_ratio=increase,crop=1280:720,setsar=1",
+		"-frames:v", "300", "-q:v", "3",
+	}, plan.IntervalArgs("in.mp4"))
+	assert.Contains(t, plan.SceneArgs("in.mp4"), "select='gt(scene,0.40)',showinfo,scale=1280:720:force_original_aspect_ratio=increase,crop=1280:720,setsar=1")
+
+	_, err = PlanFrames(0)
+	assert.ErrorIs(t, err, ErrNoDuration)
+}
+
+func TestParseSceneLog(t *testing.T) {
+	log := []byte(`Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'in.mp4':
+  Duration: 00:01:35.50, start: 0.000000, bitrate: 2500 kb/s
+[Parsed_showinfo_1 @ 0x55d0] n:   0 pts:  38912 pts_time:3.04    duration:    512 fmt:yuv420p
+[Parsed_showinfo_1 @ 0x55d0] n:   1 pts: 551424 pts_time:43.08   duration:    512 fmt:yuv420p
+[Parsed_showinfo_1 @ 0x55d0] color_range:tv color_space:bt709
+frame=    2 fps=0.0 q=3.0 Lsize=N/A time=00:00:43.12 bitrate=N/A speed= 210x
+`)
+	assert.Equal(t, []float64{3.04, 43.08}, ParseSceneLog(log))
+	assert.Empty(t, ParseSceneLog([]byte("no scenes")))
+}
@@ -0,0 +1,82 @@
+package transcode
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Hover preview layout. Sprite sheets hold 100 tiles each; long videos space
+// their tiles further apart so they never need more than maxSpriteSheets.
+const (
+	SpriteColumns    = 10
+	SpriteRows       = 10
+	SpriteTileWidth  = 160
+	SpriteTileHeight = 90
+	maxSpriteSheets  = 3
+
+	// The animation plays AnimationFrames frames sampled across the whole
+	// video at AnimationFrameRate, looping
+	AnimationFrames    = 40
+	AnimationFrameRate = 10
+	AnimationWidth     = 320
+)
+
+// ErrNoDuration is returned when planning previews of a video of unknown length
+var ErrNoDuration = errors.New("video duration is unknown")
+
+// PreviewPlan is how the hover previews of a video are generated
+type PreviewPlan struct {
+	// IntervalSeconds is the time between two sprite tiles
+	IntervalSeconds int `json:"interval_seconds"`
+	Frames          int `json:"frames"`
+	Sheets          int `json:"sheets"`
+	Columns         int `json:"columns"`
+	Rows            int `json:"rows"`
+	TileWidth       int `json:"tile_width"`
+	TileHeight      int `json:"tile_height"`
+	// AnimationSampleRate is how many frames per second of video are kept in
+	// the animation
+	AnimationSampleRate float64 `json:"animation_sample_rate"`
+}
+
+// PlanPreview plans the sprite sheets and animation of a video lasting
+// durationSeconds
+func PlanPreview(durationSeconds float64) (*PreviewPlan, error) {
+	if durationSeconds <= 0 {
+		return nil, ErrNoDuration
+	}
+	perSheet := SpriteColumns * SpriteRows
+	interval := int(math.Max(1, math.Ceil(durationSeconds/float64(maxSpriteSheets*perSheet))))
+	frames := int(math.Ceil(durationSeconds / float64(interval)))
+	return &PreviewPlan{
+		IntervalSeconds:     interval,
+		Frames:              frames,
+		Sheets:              (frames + perSheet - 1) / perSheet,
+		Columns:             SpriteColumns,
+		Rows:                SpriteRows,
+		TileWidth:           SpriteTileWidth,
+		TileHeight:          SpriteTileHeight,
+		AnimationSampleRate: AnimationFrames / durationSeconds,
+	}, nil
+}
+
+// SpriteFilter returns the ffmpeg filter grabbing a tile every interval,
+// letterboxed to the tile size, and laying them out in sheets. Writing to a
+// numbered output such as sprite-%03d.jpg makes one file per sheet.
+func (p *PreviewPlan) SpriteFilter() string {
+	return fmt.Sprintf("fps=1/%d,scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,tile=%dx%d",
+		p.IntervalSeconds, p.TileWidth, p.TileHeight, p.TileWidth, p.TileHeight, p.Columns, p.Rows)
+}
+
+// AnimationFilter returns the ffmpeg filter sampling frames across the video
+// and playing them back at AnimationFrameRate
+func (p *PreviewPlan) AnimationFilter() string {
+	return fmt.Sprintf("fps=%.4f,scale=%d:-2,setpts=N/%d/TB", p.AnimationSampleRate, AnimationWidth, AnimationFrameRate)
+}
+
+// AnimationArgs returns the ffmpeg output options of the looping animated WebP
+func (p *PreviewPlan) AnimationArgs() []string {
+	return []string{"-frames:v", strconv.Itoa(AnimationFrames), "-c:v", "libwebp", "-loop", "0", "-quality", "60", "-an"}
+}
@@ -0,0 +1,43 @@
+package transcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanPreview(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration float64
+		interval int
+		frames   int
+		sheets   int
+	}{
+		{"short", 42.5, 1, 43, 1},
+		{"fills a sheet", 100, 1, 100, 1},
+		{"several sheets", 250, 1, 250, 3},
+		{"long", 3600, 12, 300, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanPreview(tt.duration)
+			require.NoError(t, err)
+			assert.Equal(t, tt.interval, plan.IntervalSeconds)
+			assert.Equal(t, tt.frames, plan.Frames)
+			assert.Equal(t, tt.sheets, plan.Sheets)
+		})
+	}
+
+	_, err := PlanPreview(0)
+	assert.ErrorIs(t, err, ErrNoDuration)
+}
+
+func TestPreviewPlan_Filters(t *testing.T) {
+	plan, err := PlanPreview(400)
+	require.NoError(t, err)
+	assert.Equal(t, "fps=1/2,scale=160:90:force_original_aspect_ratio=decrease,pad=160:90:(ow-iw)/2:(oh-ih)/2,tile=10x10", plan.SpriteFilter())
+	assert.Equal(t, "fps=0.1000,scale=320:-2,setpts=N/10/TB", plan.AnimationFilter())
+	assert.Contains(t, plan.AnimationArgs(), "libwebp")
+}
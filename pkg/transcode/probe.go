@@ -0,0 +1,219 @@
+package transcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ProbeArgs are the ffprobe options printing what ParseProbe reads; the input
+// path follows them
+var ProbeArgs = []string{"-v", "quiet", "-print_format", "json", "-show_format", "-show_streams"}
+
+// MediaInfo is what ffprobe reports of a video file
+type MediaInfo struct {
+	Container       string       `json:"container"`
+	DurationSeconds float64      `json:"duration_seconds"`
+	BitrateKbps     int          `json:"bitrate_kbps"`
+	Video           *VideoStream `json:"video,omitempty"`
+	Audio           *AudioStream `json:"audio,omitempty"`
+}
+
+// VideoStream is the first video stream of a file
+type VideoStream struct {
+	Codec            string  `json:"codec"`
+	Profile          string  `json:"profile,omitempty"`
+	Width            int     `json:"width"`
+	Height           int     `json:"height"`
+	BitrateKbps      int     `json:"bitrate_kbps,omitempty"`
+	FrameRate        float64 `json:"frame_rate"`         // Highest frame rate of the stream
+	AverageFrameRate float64 `json:"average_frame_rate"` // Frames over duration
+	PixelFormat      string  `json:"pixel_format,omitempty"`
+	ColorSpace       string  `json:"color_space,omitempty"`
+	ColorTransfer    string  `json:"color_transfer,omitempty"`
+	ColorPrimaries   string  `json:"color_primaries,omitempty"`
+}
+
+// AudioStream is the first audio stream of a file
+type AudioStream struct {
+	Codec         string `json:"codec"`
+	Channels      int    `json:"channels"`
+	ChannelLayout string `json:"channel_layout,omitempty"`
+	SampleRate    int    `json:"sample_rate"`
+	BitrateKbps   int    `json:"bitrate_kbps,omitempty"`
+}
+
+// probeOutput is the part of ffprobe's JSON output ParseProbe reads
+type probeOutput struct {
+	Streams []struct {
+		CodecType      string `json:"codec_type"`
+		CodecName      string `json:"codec_name"`
+		Profile        string `json:"profile"`
+		Width          int    `json:"width"`
+		Height         int    `json:"height"`
+		BitRate        string `json:"bit_rate"`
+		RFrameRate     string `json:"r_frame_rate"`
+		AvgFrameRate   string `json:"avg_frame_rate"`
+		PixFmt         string `json:"pix_fmt"`
+		ColorSpace     string `json:"color_space"`
+		ColorTransfer  string `json:"color_transfer"`
+		ColorPrimaries string `json:"color_primaries"`
+		Channels       int    `json:"channels"`
+		ChannelLayout  string `json:"channel_layout"`
+		SampleRate     string `json:"sample_rate"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+// ParseProbe reads the JSON output of ffprobe run with ProbeArgs
+func ParseProbe(data []byte) (*MediaInfo, error) {
+	var out probeOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("invalid ffprobe output: %w", err)
+	}
+	if out.Format.FormatName == "" {
+		return nil, fmt.Errorf("invalid ffprobe output: no format")
+	}
+
+	duration, _ := strconv.ParseFloat(out.Format.Duration, 64)
+	info := &MediaInfo{
+		Container:       out.Format.FormatName,
+		DurationSeconds: duration,
+		BitrateKbps:     kbps(out.Format.BitRate),
+	}
+	for _, s := range out.Streams {
+		switch {
+		case s.CodecType == "video" && info.Video == nil:
+			info.Video = &VideoStream{
+				Codec:            s.CodecName,
+				Profile:          s.Profile,
+				Width:            s.Width,
+				Height:           s.Height,
+				BitrateKbps:      kbps(s.BitRate),
+				FrameRate:        frameRate(s.RFrameRate),
+				AverageFrameRate: frameRate(s.AvgFrameRate),
+				PixelFormat:      s.PixFmt,
+				ColorSpace:       s.ColorSpace,
+				ColorTransfer:    s.ColorTransfer,
+				ColorPrimaries:   s.ColorPrimaries,
+			}
+		case s.CodecType == "audio" && info.Audio == nil:
+			sampleRate, _ := strconv.Atoi(s.SampleRate)
+			info.Audio = &AudioStream{
+				Codec:         s.CodecName,
+				Channels:      s.Channels,
+				ChannelLayout: s.ChannelLayout,
+				SampleRate:    sampleRate,
+				BitrateKbps:   kbps(s.BitRate),
+			}
+		}
+	}
+	return info, nil
+}
+
+// kbps converts a bitrate in bits per second, as ffprobe prints it
+func kbps(bitrate string) int {
+	bps, err := strconv.ParseInt(bitrate, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return int(bps / 1000)
+}
+
+// frameRate converts a frame rate ffprobe prints as a fraction, such as
+// 30000/1001, rounded to the hundredth
+func frameRate(rate string) float64 {
+	num, den, ok := strings.Cut(rate, "/")
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0
+	}
+	if ok {
+		d, err := strconv.ParseFloat(den, 64)
+		if err != nil || d == 0 {
+			return 0
+		}
+		n /= d
+	}
+	return math.Round(n*100) / 100
+}
+
+// Warning is a property of a file that platforms are known to reject or
+// play back badly
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Warning codes
+const (
+	WarnVariableFrameRate = "variable_frame_rate"
+	WarnHighFrameRate     = "high_frame_rate"
+	WarnVideoCodec        = "video_codec"
+	WarnOddDimensions     = "odd_dimensions"
+	WarnPixelFormat       = "pixel_format"
+	WarnHDR               = "hdr"
+	WarnColorSpace        = "color_space"
+	WarnNoAudio           = "no_audio"
+	WarnAudioCodec        = "audio_codec"
+	WarnAudioChannels     = "audio_channels"
+)
+
+// maxFrameRate is the highest frame rate every platform takes
+const maxFrameRate = 60
+
+// Warnings lists what in the file may make platforms reject it or degrade
+// it. Renditions are re-encoded to constant frame rate H.264 and AAC, so
+// most warnings explain rejections of the source rather than of them.
+func (m *MediaInfo) Warnings() []Warning {
+	warnings := []Warning{}
+	add := func(code, format string, args ...interface{}) {
+		warnings = append(warnings, Warning{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if v := m.Video; v != nil {
+		if v.AverageFrameRate > 0 && math.Abs(v.FrameRate-v.AverageFrameRate) > 0.01 {
+			add(WarnVariableFrameRate, "Variable frame rate (%.2f fps on average, up to %.2f fps): TikTok and Instagram reject some variable frame rate uploads and others drift out of sync with the audio", v.AverageFrameRate, v.FrameRate)
+		}
+		if v.FrameRate > maxFrameRate {
+			add(WarnHighFrameRate, "Frame rate of %.2f fps: platforms take at most %d fps and drop the extra frames", v.FrameRate, maxFrameRate)
+		}
+		switch v.Codec {
+		case "h264", "hevc":
+		default:
+			add(WarnVideoCodec, "Video codec %s: platforms expect H.264, and some take HEVC", v.Codec)
+		}
+		if v.Width%2 != 0 || v.Height%2 != 0 {
+			add(WarnOddDimensions, "Odd frame size %dx%d: H.264 encoders need even dimensions", v.Width, v.Height)
+		}
+		if v.PixelFormat != "" && v.PixelFormat != "yuv420p" && v.PixelFormat != "yuvj420p" {
+			add(WarnPixelFormat, "Pixel format %s: most platform players only play yuv420p", v.PixelFormat)
+		}
+		switch {
+		case v.ColorTransfer == "smpte2084" || v.ColorTransfer == "arib-std-b67":
+			add(WarnHDR, "HDR video (%s transfer): platforms without HDR support show it washed out", v.ColorTransfer)
+		case v.ColorSpace != "" && v.ColorSpace != "bt709" && v.ColorSpace != "unknown":
+			add(WarnColorSpace, "Color space %s: platforms assume bt709 and show shifted colors", v.ColorSpace)
+		}
+	}
+
+	if a := m.Audio; a == nil {
+		add(WarnNoAudio, "No audio track: some platforms reject silent uploads or refuse to add music to them")
+	} else {
+		switch a.Codec {
+		case "aac", "mp3":
+		default:
+			add(WarnAudioCodec, "Audio codec %s: platforms expect AAC", a.Codec)
+		}
+		if a.Channels > 2 {
+			add(WarnAudioChannels, "%d audio channels: most platforms only keep stereo and downmix the rest", a.Channels)
+		}
+	}
+	return warnings
+}
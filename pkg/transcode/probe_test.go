@@ -0,0 +1,66 @@
+package transcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const phoneProbe = `{
+	"streams": [
+		{"index": 0, "codec_type": "video", "codec_name": "hevc", "profile": "Main 10", "width": 1080, "height": 1920,
+		 "pix_fmt": "yuv420p10le", "color_space": "bt2020nc", "color_transfer": "arib-std-b67", "color_primaries": "bt2020",
+		 "r_frame_rate": "60/1", "avg_frame_rate": "27000/1001", "bit_rate": "9830120"},
+		{"index": 1, "codec_type": "audio", "codec_name": "aac", "sample_rate": "44100", "channels": 2,
+		 "channel_layout": "stereo", "bit_rate": "128000"},
+		{"index": 2, "codec_type": "audio", "codec_name": "pcm_s16le", "channels": 6}
+	],
+	"format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "31.466667", "bit_rate": "9961422"}
+}`
+
+func TestParseProbe(t *testing.T) {
+	info, err := ParseProbe([]byte(phoneProbe))
+	require.NoError(t, err)
+
+	assert.Equal(t, "mov,mp4,m4a,3gp,3g2,mj2", info.Container)
+	assert.Equal(t, 31.466667, info.DurationSeconds)
+	assert.Equal(t, 9961, info.BitrateKbps)
+	assert.Equal(t, &VideoStream{
+		Codec: "hevc", Profile: "Main 10", Width: 1080, Height: 1920, BitrateKbps: 9830,
+		FrameRate: 60, AverageFrameRate: 26.97, PixelFormat: "yuv420p10le",
+		ColorSpace: "bt2020nc", ColorTransfer: "arib-std-b67", ColorPrimaries: "bt2020",
+	}, info.Video)
+	assert.Equal(t, &AudioStream{Codec: "aac", Channels: 2, ChannelLayout: "stereo", SampleRate: 44100, BitrateKbps: 128}, info.Audio,
+		"the first audio stream is the one platforms play")
+
+	_, err = ParseProbe([]byte(`{}`))
+	assert.Error(t, err)
+	_, err = ParseProbe([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestMediaInfo_Warnings(t *testing.T) {
+	info, err := ParseProbe([]byte(phoneProbe))
+	require.NoError(t, err)
+	var codes []string
+	for _, w := range info.Warnings() {
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []string{WarnVariableFrameRate, WarnPixelFormat, WarnHDR}, codes)
+
+	clean := &MediaInfo{
+		Video: &VideoStream{Codec: "h264", Width: 1920, Height: 1080, FrameRate: 29.97, AverageFrameRate: 29.97, PixelFormat: "yuv420p", ColorSpace: "bt709"},
+		Audio: &AudioStream{Codec: "aac", Channels: 2},
+	}
+	assert.Empty(t, clean.Warnings())
+
+	odd := &MediaInfo{
+		Video: &VideoStream{Codec: "prores", Width: 1919, Height: 1080, FrameRate: 120, AverageFrameRate: 120, ColorSpace: "bt601"},
+	}
+	codes = nil
+	for _, w := range odd.Warnings() {
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []string{WarnHighFrameRate, WarnVideoCodec, WarnOddDimensions, WarnColorSpace, WarnNoAudio}, codes)
+}
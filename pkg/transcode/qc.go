@@ -0,0 +1,95 @@
+package transcode
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// Silence is quieter than silenceNoiseDB for at least a second
+const silenceNoiseDB = -50
+
+// QCArgs are the ffmpeg options logging what ParseQC reads, after -i and the
+// path of the file to check. Nothing is written out.
+var QCArgs = []string{
+	"-hide_banner", "-nostats",
+	"-vf", "blackdetect=d=0.5:pix_th=0.10",
+	"-af", "silencedetect=noise=" + strconv.Itoa(silenceNoiseDB) + "dB:d=1,ebur128",
+	"-f", "null", "-",
+}
+
+var (
+	qcDuration = regexp.MustCompile(`Duration: (\d+):(\d+):(\d+(?:\.\d+)?)`)
+	qcBlack    = regexp.MustCompile(`black_start:\s*([\d.]+)\s+black_end:\s*([\d.]+)`)
+	qcSilence  = regexp.MustCompile(`silence_(start|end):\s*(-?[\d.]+)`)
+	qcLoudness = regexp.MustCompile(`(?m)^\s+I:\s+(-?[\d.]+|-inf) LUFS`)
+	qcAudio    = regexp.MustCompile(`Stream #\d+:\d+[^:]*: Audio:`)
+)
+
+// Interval is a stretch of a file, in seconds
+type Interval struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// QCMeasures is what the ffmpeg filters of QCArgs measured of a file
+type QCMeasures struct {
+	DurationSeconds float64    `json:"duration_seconds"`
+	Black           []Interval `json:"black,omitempty"`
+	Silence         []Interval `json:"silence,omitempty"`
+	// IntegratedLUFS is the loudness of the whole file; nil without audio
+	IntegratedLUFS *float64 `json:"integrated_lufs,omitempty"`
+}
+
+// ParseQC reads the log of ffmpeg run with QCArgs. A silence running to the
+// end of the file ends with it.
+func ParseQC(log []byte) (*QCMeasures, error) {
+	match := qcDuration.FindSubmatch(log)
+	if match == nil {
+		return nil, ErrNoDuration
+	}
+	hours, _ := strconv.ParseFloat(string(match[1]), 64)
+	minutes, _ := strconv.ParseFloat(string(match[2]), 64)
+	seconds, _ := strconv.ParseFloat(string(match[3]), 64)
+	m := &QCMeasures{DurationSeconds: hours*3600 + minutes*60 + seconds}
+
+	for _, match := range qcBlack.FindAllSubmatch(log, -1) {
+		start, _ := strconv.ParseFloat(string(match[1]), 64)
+		end, _ := strconv.ParseFloat(string(match[2]), 64)
+		m.Black = append(m.Black, Interval{Start: start, End: end})
+	}
+
+	open := -1.0
+	for _, match := range qcSilence.FindAllSubmatch(log, -1) {
+		at, _ := strconv.ParseFloat(string(match[2]), 64)
+		if string(match[1]) == "start" {
+			open = math.Max(at, 0)
+		} else if open >= 0 {
+			m.Silence = append(m.Silence, Interval{Start: open, End: at})
+			open = -1
+		}
+	}
+	if open >= 0 {
+		m.Silence = append(m.Silence, Interval{Start: open, End: m.DurationSeconds})
+	}
+
+	if qcAudio.Match(log) {
+		if matches := qcLoudness.FindAllSubmatch(log, -1); len(matches) > 0 {
+			lufs, err := strconv.ParseFloat(string(matches[len(matches)-1][1]), 64)
+			if err != nil || math.IsInf(lufs, -1) {
+				lufs = -70 // Below ebur128's absolute gate
+			}
+			m.IntegratedLUFS = &lufs
+		}
+	}
+	return m, nil
+}
+
+// Total returns how long the intervals last together
+func Total(intervals []Interval) float64 {
+	total := 0.0
+	for _, interval := range intervals {
+		total += interval.End - interval.Start
+	}
+	return total
+}
@@ -0,0 +1,48 @@
+package transcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const qcLog = `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'rendition.mp4':
+  Duration: 00:01:02.50, start: 0.000000, bitrate: 8123 kb/s
+  Stream #0:0[0x1](und): Video: h264 (High) (avc1 / 0x31637661), yuv420p, 1920x1080, 7931 kb/s, 30 fps
+  Stream #0:1[0x2](und): Audio: aac (LC) (mp4a / 0x6134706D), 48000 Hz, stereo, fltp, 192 kb/s
+[blackdetect @ 0x55d5c8a3e2c0] black_start:0 black_end:3.2 black_duration:3.2
+[silencedetect @ 0x55d5c8a40a80] silence_start: -0.00133333
+[silencedetect @ 0x55d5c8a40a80] silence_end: 4.5 | silence_duration: 4.50133
+[blackdetect @ 0x55d5c8a3e2c0] black_start:30.1 black_end:30.8 black_duration:0.7
+[silencedetect @ 0x55d5c8a40a80] silence_start: 58.5
+[Parsed_ebur128_1 @ 0x55d5c8a41c00] Summary:
+
+  Integrated loudness:
+    I:         -19.4 LUFS
+    Threshold: -29.6 LUFS
+
+  Loudness range:
+    LRA:         6.1 LU
+`
+
+func TestParseQC(t *testing.T) {
+	m, err := ParseQC([]byte(qcLog))
+	require.NoError(t, err)
+	assert.Equal(t, 62.5, m.DurationSeconds)
+	assert.Equal(t, []Interval{{0, 3.2}, {30.1, 30.8}}, m.Black)
+	assert.Equal(t, []Interval{{0, 4.5}, {58.5, 62.5}}, m.Silence, "the last silence runs to the end")
+	require.NotNil(t, m.IntegratedLUFS)
+	assert.Equal(t, -19.4, *m.IntegratedLUFS)
+	assert.InDelta(t, 8.5, Total(m.Silence), 1e-9)
+}
+
+func TestParseQC_WithoutAudio(t *testing.T) {
+	m, err := ParseQC([]byte("  Duration: 00:00:10.00, start: 0.000000\n  Stream #0:0: Video: h264, yuv420p, 1080x1920\n"))
+	require.NoError(t, err)
+	assert.Nil(t, m.IntegratedLUFS)
+	assert.Empty(t, m.Silence)
+
+	_, err = ParseQC([]byte("rendition.mp4: No such file or directory"))
+	assert.ErrorIs(t, err, ErrNoDuration)
+}
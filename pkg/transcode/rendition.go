@@ -0,0 +1,87 @@
+package transcode
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Rendition profile names
+const (
+	Landscape1080p = "landscape_1080p"
+	Landscape720p  = "landscape_720p"
+	Vertical1080p  = "vertical_1080p"
+)
+
+// Codecs a rendition can be encoded with
+const (
+	CodecH264 = "h264"
+	CodecHEVC = "hevc"
+	CodecVP9  = "vp9"
+	CodecAAC  = "aac"
+	CodecOpus = "opus"
+)
+
+// VideoEncoders and AudioEncoders are the ffmpeg encoders of each codec
+var (
+	VideoEncoders = map[string]string{CodecH264: "libx264", CodecHEVC: "libx265", CodecVP9: "libvpx-vp9"}
+	AudioEncoders = map[string]string{CodecAAC: "aac", CodecOpus: "libopus"}
+)
+
+// Profile is how a video is transcoded into a rendition
+type Profile struct {
+	Name             string `json:"name"`
+	Width            int    `json:"width"`
+	Height           int    `json:"height"`
+	AspectRatio      string `json:"aspect_ratio"`
+	VideoBitrateKbps int    `json:"video_bitrate_kbps"`
+	AudioBitrateKbps int    `json:"audio_bitrate_kbps"`
+	FrameRate        int    `json:"frame_rate"`
+	VideoCodec       string `json:"video_codec"`
+	AudioCodec       string `json:"audio_codec"`
+	// LoudnessLUFS is the integrated loudness the audio is normalized to; 0
+	// leaves its level as it is
+	LoudnessLUFS float64 `json:"loudness_lufs,omitempty"`
+}
+
+// Profiles are the renditions a video can be transcoded into, by name
+var Profiles = map[string]Profile{
+	Landscape1080p: {Name: Landscape1080p, Width: 1920, Height: 1080, AspectRatio: "16:9", VideoBitrateKbps: 8000, AudioBitrateKbps: 192, FrameRate: 30, VideoCodec: CodecH264, AudioCodec: CodecAAC},
+	Landscape720p:  {Name: Landscape720p, Width: 1280, Height: 720, AspectRatio: "16:9", VideoBitrateKbps: 5000, AudioBitrateKbps: 128, FrameRate: 30, VideoCodec: CodecH264, AudioCodec: CodecAAC},
+	Vertical1080p:  {Name: Vertical1080p, Width: 1080, Height: 1920, AspectRatio: "9:16", VideoBitrateKbps: 6000, AudioBitrateKbps: 128, FrameRate: 30, VideoCodec: CodecH264, AudioCodec: CodecAAC},
+}
+
+// Filter returns the ffmpeg filter_complex scaling the first input to the
+// profile's frame, cropping what overflows its aspect ratio, then burning the
+// watermark in when there is one
+func (p Profile) Filter(watermark *Watermark) string {
+	scale := fmt.Sprintf("[0:v]scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,setsar=1", p.Width, p.Height, p.Width, p.Height)
+	if watermark == nil {
+		return scale
+	}
+	return scale + "[v];" + watermark.overlayFilter("[v]")
+}
+
+// OutputArgs returns the ffmpeg output options encoding the rendition with
+// its codecs in an MP4 that can be streamed before it is fully downloaded,
+// normalizing its loudness when it has a target
+func (p Profile) OutputArgs() []string {
+	rate := strconv.Itoa(p.VideoBitrateKbps) + "k"
+	args := []string{
+		"-c:v", VideoEncoders[p.VideoCodec], "-b:v", rate, "-maxrate", rate, "-bufsize", strconv.Itoa(2*p.VideoBitrateKbps) + "k",
+		"-r", strconv.Itoa(p.FrameRate),
+	}
+	if p.VideoCodec == CodecHEVC {
+		args = append(args, "-tag:v", "hvc1") // Played by Apple devices
+	}
+	args = append(args, "-c:a", AudioEncoders[p.AudioCodec], "-b:a", strconv.Itoa(p.AudioBitrateKbps)+"k")
+	if p.LoudnessLUFS != 0 {
+		args = append(args, "-af", p.loudnorm())
+	}
+	return append(args, "-movflags", "+faststart")
+}
+
+// loudnorm returns the ffmpeg filter normalizing audio to the profile's
+// loudness
+func (p Profile) loudnorm() string {
+	return fmt.Sprintf("loudnorm=I=%g:TP=-1.5:LRA=11", p.LoudnessLUFS)
+}
@@ -0,0 +1,41 @@
+package transcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiles_NamedAfterTheirKey(t *testing.T) {
+	for name, profile := range Profiles {
+		assert.Equal(t, name, profile.Name)
+	}
+}
+
+func TestProfile_Filter(t *testing.T) {
+	vertical := Profiles[Vertical1080p]
+	assert.Equal(t, "[0:v]scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920,setsar=1", vertical.Filter(nil))
+	assert.Equal(t, "[0:v]scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920,setsar=1[v];"+
+		"[1:v]format=rgba,colorchannelmixer=aa=0.80[wm];[v][wm]overlay=24:24",
+		vertical.Filter(&Watermark{Position: TopLeft, Opacity: 0.8}))
+}
+
+func TestProfile_OutputArgs(t *testing.T) {
+	assert.Equal(t, []string{
+		"-c:v", "libx264", "-b:v", "5000k", "-maxrate", "5000k", "-bufsize", "10000k",
+		"-r", "30",
+		"-c:a", "aac", "-b:a", "128k",
+		"-movflags", "+faststart",
+	}, Profiles[Landscape720p].OutputArgs())
+}
+
+func TestProfile_OutputArgsTuned(t *testing.T) {
+	profile := Profiles[Landscape1080p]
+	profile.VideoCodec, profile.AudioCodec, profile.LoudnessLUFS = CodecHEVC, CodecOpus, -14
+	assert.Equal(t, []string{
+		"-c:v", "libx265", "-b:v", "8000k", "-maxrate", "8000k", "-bufsize", "16000k",
+		"-r", "30", "-tag:v", "hvc1",
+		"-c:a", "libopus", "-b:a", "192k", "-af", "loudnorm=I=-14:TP=-1.5:LRA=11",
+		"-movflags", "+faststart",
+	}, profile.OutputArgs())
+}
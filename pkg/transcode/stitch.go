@@ -0,0 +1,50 @@
+package transcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StitchSampleRate is the audio sample rate the clips of a stitched rendition
+// are resampled to before being joined
+const StitchSampleRate = 48000
+
+// StitchArgs returns the ffmpeg arguments, up to the output path, playing
+// the intro before a transcoded rendition and the outro after it; either
+// may be empty. The clips are scaled and cropped to the profile's frame and
+// rate like the video, and need an audio track, silent if need be, to be
+// joined with the video's. The result is encoded as the rendition was.
+func (p Profile) StitchArgs(rendition, intro, outro string) []string {
+	inputs := []string{rendition}
+	if intro != "" {
+		inputs = append([]string{intro}, inputs...)
+	}
+	if outro != "" {
+		inputs = append(inputs, outro)
+	}
+
+	var args, filters []string
+	var streams strings.Builder
+	for i, input := range inputs {
+		args = append(args, "-i", input)
+		filters = append(filters,
+			fmt.Sprintf("[%d:v]scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,setsar=1,fps=%d[v%d]",
+				i, p.Width, p.Height, p.Width, p.Height, p.FrameRate, i),
+			fmt.Sprintf("[%d:a]aresample=%d,aformat=channel_layouts=stereo[a%d]", i, StitchSampleRate, i))
+		fmt.Fprintf(&streams, "[v%d][a%d]", i, i)
+	}
+	concat := fmt.Sprintf("%sconcat=n=%d:v=1:a=1[v][a]", streams.String(), len(inputs))
+	audio := "[a]"
+	if p.LoudnessLUFS != 0 {
+		// Clips mastered apart from the video are brought to its loudness
+		concat += ";[a]" + p.loudnorm() + "[an]"
+		audio = "[an]"
+	}
+	args = append(args, "-filter_complex", strings.Join(append(filters, concat), ";"), "-map", "[v]", "-map", audio)
+
+	// The loudness is normalized in the filter graph, which -af cannot be
+	// combined with
+	loudless := p
+	loudless.LoudnessLUFS = 0
+	return append(args, loudless.OutputArgs()...)
+}
@@ -0,0 +1,31 @@
+package transcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile_StitchArgs(t *testing.T) {
+	profile := Profiles[Vertical1080p]
+	assert.Equal(t, []string{
+		"-i", "intro.mp4", "-i", "video.mp4",
+		"-filter_complex", "[0:v]scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920,setsar=1,fps=30[v0];" +
+			"[0:a]aresample=48000,aformat=channel_layouts=stereo[a0];" +
+			"[1:v]scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920,setsar=1,fps=30[v1];" +
+			"[1:a]aresample=48000,aformat=channel_layouts=stereo[a1];" +
+			"[v0][a0][v1][a1]concat=n=2:v=1:a=1[v][a]",
+		"-map", "[v]", "-map", "[a]",
+		"-c:v", "libx264", "-b:v", "6000k", "-maxrate", "6000k", "-bufsize", "12000k",
+		"-r", "30",
+		"-c:a", "aac", "-b:a", "128k",
+		"-movflags", "+faststart",
+	}, profile.StitchArgs("video.mp4", "intro.mp4", ""))
+
+	profile.LoudnessLUFS = -14
+	args := profile.StitchArgs("video.mp4", "intro.mp4", "outro.mp4")
+	assert.Equal(t, []string{"-i", "intro.mp4", "-i", "video.mp4", "-i", "outro.mp4"}, args[:6])
+	assert.Contains(t, args[7], "[v0][a0][v1][a1][v2][a2]concat=n=3:v=1:a=1[v][a];[a]loudnorm=I=-14:TP=-1.5:LRA=11[an]")
+	assert.Equal(t, []string{"-map", "[v]", "-map", "[an]"}, args[8:12])
+	assert.NotContains(t, args, "-af", "loudness is normalized in the filter graph")
+}
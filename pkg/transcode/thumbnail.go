@@ -0,0 +1,112 @@
+package transcode
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidBrand is returned for brand fonts and colors the worker cannot
+// render thumbnails with
+var ErrInvalidBrand = errors.New("invalid brand")
+
+// Thumbnails are rendered at YouTube's recommended size
+const (
+	ThumbnailWidth  = 1280
+	ThumbnailHeight = 720
+)
+
+// ThumbnailLayout is how the hook text is laid over a thumbnail's frame
+type ThumbnailLayout string
+
+const (
+	// LayoutBanner writes the text on a band of the accent color across the
+	// bottom of the frame
+	LayoutBanner ThumbnailLayout = "banner"
+	// LayoutOutline writes the text large in the center, outlined in the
+	// accent color
+	LayoutOutline ThumbnailLayout = "outline"
+	// LayoutTag writes the text in a box of the accent color in the top left
+	// corner
+	LayoutTag ThumbnailLayout = "tag"
+)
+
+// ThumbnailLayouts lists the supported layouts
+var ThumbnailLayouts = []ThumbnailLayout{LayoutBanner, LayoutOutline, LayoutTag}
+
+// Valid reports whether the layout is supported
+func (l ThumbnailLayout) Valid() bool {
+	for _, known := range ThumbnailLayouts {
+		if l == known {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	brandFont  = regexp.MustCompile(`^[A-Za-z0-9 ]{1,50}$`)
+	brandColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+)
+
+// Brand is the font, a fontconfig family installed on the worker, and the
+// colors, as #RRGGBB, thumbnails are rendered with
+type Brand struct {
+	Font        string `json:"font"`
+	TextColor   string `json:"text_color"`
+	AccentColor string `json:"accent_color"`
+}
+
+// Validate checks the brand can be rendered
+func (b Brand) Validate() error {
+	if !brandFont.MatchString(b.Font) {
+		return fmt.Errorf("%w: font must be a font family name of letters, digits and spaces", ErrInvalidBrand)
+	}
+	if !brandColor.MatchString(b.TextColor) || !brandColor.MatchString(b.AccentColor) {
+		return fmt.Errorf("%w: colors must be written #RRGGBB", ErrInvalidBrand)
+	}
+	return nil
+}
+
+// ThumbnailFilter returns the ffmpeg filter filling the thumbnail's frame
+// with the input and laying the text over it in the brand's font and colors
+func (b Brand) ThumbnailFilter(layout ThumbnailLayout, text string) string {
+	frame := frameFilter()
+	draw := fmt.Sprintf("drawtext=font='%s':text='%s':expansion=none:fontcolor=%s",
+		b.Font, escapeDrawText(text), ffmpegColor(b.TextColor))
+	accent := ffmpegColor(b.AccentColor)
+
+	switch layout {
+	case LayoutOutline:
+		return frame + "," + draw + ":fontsize=128:borderw=8:bordercolor=" + accent + ":x=(w-text_w)/2:y=(h-text_h)/2"
+	case LayoutTag:
+		return frame + "," + draw + ":fontsize=72:box=1:boxcolor=" + accent + ":boxborderw=24:x=48:y=48"
+	default:
+		return frame + ",drawbox=x=0:y=ih-180:w=iw:h=180:color=" + accent + "@0.9:t=fill," +
+			draw + ":fontsize=96:x=(w-text_w)/2:y=h-90-text_h/2"
+	}
+}
+
+// ThumbnailArgs returns the ffmpeg arguments, up to the output path,
+// rendering a JPEG thumbnail from the frame of the input at the given second
+func (b Brand) ThumbnailArgs(input string, at float64, layout ThumbnailLayout, text string) []string {
+	return []string{
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64), "-i", input,
+		"-vf", b.ThumbnailFilter(layout, text),
+		"-frames:v", "1", "-q:v", "2",
+	}
+}
+
+// escapeDrawText quotes text for a single-quoted drawtext option: a quote
+// ends the quoting, is escaped, and quoting starts again
+func escapeDrawText(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	return strings.ReplaceAll(text, `'`, `'\''`)
+}
+
+// ffmpegColor writes a #RRGGBB color as ffmpeg's 0xRRGGBB
+func ffmpegColor(color string) string {
+	return "0x" + strings.TrimPrefix(color, "#")
+}
@@ -0,0 +1,35 @@
+package transcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrand_Validate(t *testing.T) {
+	assert.NoError(t, Brand{Font: "Bebas Neue", TextColor: "#FFFFFF", AccentColor: "#e50914"}.Validate())
+	assert.ErrorIs(t, Brand{Font: "../fonts/evil.ttf", TextColor: "#FFFFFF", AccentColor: "#E50914"}.Validate(), ErrInvalidBrand)
+	assert.ErrorIs(t, Brand{Font: "Anton", TextColor: "white", AccentColor: "#E50914"}.Validate(), ErrInvalidBrand)
+}
+
+func TestBrand_ThumbnailFilter(t *testing.T) {
+	brand := Brand{Font: "Anton", TextColor: "#FFFFFF", AccentColor: "#E50914"}
+	frame := "scale=1280:720:force_original_aspect_ratio=increase,crop=1280:720,setsar=1,"
+
+	assert.Equal(t, frame+"drawbox=x=0:y=ih-180:w=iw:h=180:color=0xE50914@0.9:t=fill,"+
+		"drawtext=font='Anton':text='WHO DID IT?':expansion=none:fontcolor=0xFFFFFF:fontsize=96:x=(w-text_w)/2:y=h-90-text_h/2",
+		brand.ThumbnailFilter(LayoutBanner, "WHO DID IT?"))
+	assert.Equal(t, frame+"drawtext=font='Anton':text='It wasn'\\''t him':expansion=none:fontcolor=0xFFFFFF"+
+		":fontsize=128:borderw=8:bordercolor=0xE50914:x=(w-text_w)/2:y=(h-text_h)/2",
+		brand.ThumbnailFilter(LayoutOutline, "It wasn't him"))
+	assert.Equal(t, frame+"drawtext=font='Anton':text='100% \\\\ real':expansion=none:fontcolor=0xFFFFFF"+
+		":fontsize=72:box=1:boxcolor=0xE50914:boxborderw=24:x=48:y=48",
+		brand.ThumbnailFilter(LayoutTag, `100% \ real`))
+}
+
+func TestBrand_ThumbnailArgs(t *testing.T) {
+	brand := Brand{Font: "Anton", TextColor: "#FFFFFF", AccentColor: "#E50914"}
+	args := brand.ThumbnailArgs("video.mp4", 12.5, LayoutTag, "Case closed")
+	assert.Equal(t, []string{"-ss", "12.500", "-i", "video.mp4", "-vf"}, args[:5])
+	assert.Equal(t, []string{"-frames:v", "1", "-q:v", "2"}, args[6:])
+}
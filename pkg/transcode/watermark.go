@@ -0,0 +1,89 @@
+// Package transcode describes how the processing worker inspects a video and
+// transcodes it for the platforms: what ffprobe reports, the rendition
+// profiles, the watermark burnt in, the intros and outros stitched on, the
+// hover previews, the frames thumbnails are picked from and the branded
+// thumbnails.
+package transcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidWatermark is returned for watermark options the worker cannot apply
+var ErrInvalidWatermark = errors.New("invalid watermark")
+
+// Position is the corner, or the center, of the frame a watermark is burnt into
+type Position string
+
+const (
+	TopLeft     Position = "top_left"
+	TopRight    Position = "top_right"
+	BottomLeft  Position = "bottom_left"
+	BottomRight Position = "bottom_right"
+	Center      Position = "center"
+)
+
+// Positions lists the supported watermark positions
+var Positions = []Position{TopLeft, TopRight, BottomLeft, BottomRight, Center}
+
+// Valid reports whether the position is supported
+func (p Position) Valid() bool {
+	for _, known := range Positions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// WatermarkMargin is the distance in pixels between a watermark in a corner
+// and the edges of the frame
+const WatermarkMargin = 24
+
+// Watermark is an image burnt into every frame of an output
+type Watermark struct {
+	Position Position `json:"position"`
+	// Opacity goes from 0, invisible, to 1, opaque
+	Opacity float64 `json:"opacity"`
+}
+
+// Validate checks the watermark can be applied
+func (w Watermark) Validate() error {
+	if !w.Position.Valid() {
+		return fmt.Errorf("%w: position must be one of %v", ErrInvalidWatermark, Positions)
+	}
+	if w.Opacity <= 0 || w.Opacity > 1 {
+		return fmt.Errorf("%w: opacity must be greater than 0 and at most 1", ErrInvalidWatermark)
+	}
+	return nil
+}
+
+// Filter returns the ffmpeg filter_complex overlaying the watermark, read
+// from the second input, on the video of the first one
+func (w Watermark) Filter() string {
+	return w.overlayFilter("[0:v]")
+}
+
+// overlayFilter overlays the watermark on the video stream labelled video
+func (w Watermark) overlayFilter(video string) string {
+	return fmt.Sprintf("[1:v]format=rgba,colorchannelmixer=aa=%.2f[wm];%s[wm]overlay=%s", w.Opacity, video, w.overlay())
+}
+
+// overlay returns the coordinates of the watermark's top left corner, in
+// ffmpeg overlay expressions
+func (w Watermark) overlay() string {
+	m := WatermarkMargin
+	switch w.Position {
+	case TopLeft:
+		return fmt.Sprintf("%d:%d", m, m)
+	case TopRight:
+		return fmt.Sprintf("W-w-%d:%d", m, m)
+	case BottomLeft:
+		return fmt.Sprintf("%d:H-h-%d", m, m)
+	case Center:
+		return "(W-w)/2:(H-h)/2"
+	default:
+		return fmt.Sprintf("W-w-%d:H-h-%d", m, m)
+	}
+}
@@ -0,0 +1,34 @@
+package transcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatermark_Validate(t *testing.T) {
+	assert.NoError(t, Watermark{Position: BottomRight, Opacity: 0.8}.Validate())
+	assert.NoError(t, Watermark{Position: Center, Opacity: 1}.Validate())
+	assert.ErrorIs(t, Watermark{Position: "middle", Opacity: 0.8}.Validate(), ErrInvalidWatermark)
+	assert.ErrorIs(t, Watermark{Position: TopLeft, Opacity: 0}.Validate(), ErrInvalidWatermark)
+	assert.ErrorIs(t, Watermark{Position: TopLeft, Opacity: 1.5}.Validate(), ErrInvalidWatermark)
+}
+
+func TestWatermark_Filter(t *testing.T) {
+	tests := []struct {
+		position Position
+		overlay  string
+	}{
+		{TopLeft, "24:24"},
+		{TopRight, "W-w-24:24"},
+		{BottomLeft, "24:H-h-24"},
+		{BottomRight, "W-w-24:H-h-24"},
+		{Center, "(W-w)/2:(H-h)/2"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.position), func(t *testing.T) {
+			w := Watermark{Position: tt.position, Opacity: 0.5}
+			assert.Equal(t, "[1:v]format=rgba,colorchannelmixer=aa=0.50[wm];[0:v][wm]overlay="+tt.overlay, w.Filter())
+		})
+	}
+}
@@ -0,0 +1,179 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/repositories"
+	"github.com/jibe0123/mysteryfactory/internal/services"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+	"github.com/jibe0123/mysteryfactory/pkg/logger"
+)
+
+// benchTenant is a tenant loaded with the demo data set, shared by the
+// benchmarks so seeding runs once per test binary
+var benchTenant struct {
+	once     sync.Once
+	tenantID string
+	videoIDs []string
+	statsIDs []string
+	err      error
+}
+
+// seedBenchTenant loads 200 videos with 30 days of history on three platforms
+func seedBenchTenant(b *testing.B) (string, []string, []string) {
+	b.Helper()
+	benchTenant.once.Do(func() {
+		tenantID := id.New()
+		if _, err := db.SeedDemo(mysqlServer.DB.DB, db.DemoOptions{TenantID: tenantID, Videos: 200, HistoryDays: 30, Seed: 1}); err != nil {
+			benchTenant.err = err
+			return
+		}
+		repo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+		if err := repo.RefreshSummaries(context.Background(), tenantID, nil); err != nil {
+			benchTenant.err = err
+			return
+		}
+		stats, err := repo.List(context.Background(), tenantID, 1000, 0)
+		if err != nil {
+			benchTenant.err = err
+			return
+		}
+		seen := make(map[string]bool)
+		for _, s := range stats {
+			benchTenant.statsIDs = append(benchTenant.statsIDs, s.ID)
+			if !seen[s.VideoID] {
+				seen[s.VideoID] = true
+				benchTenant.videoIDs = append(benchTenant.videoIDs, s.VideoID)
+			}
+		}
+		benchTenant.tenantID = tenantID
+	})
+	if benchTenant.err != nil {
+		b.Fatalf("failed to seed benchmark tenant: %v", benchTenant.err)
+	}
+	return benchTenant.tenantID, benchTenant.videoIDs, benchTenant.statsIDs
+}
+
+func BenchmarkVideoStatsRepository_GetAggregatedStats(b *testing.B) {
+	tenantID, videoIDs, _ := seedBenchTenant(b)
+	repo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.GetAggregatedStats(context.Background(), tenantID, videoIDs[i%len(videoIDs)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVideoStatsRepository_GetTopPerforming(b *testing.B) {
+	tenantID, _, _ := seedBenchTenant(b)
+	repo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.GetTopPerforming(context.Background(), tenantID, "views", 10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVideoStatsRepository_RefreshSummaries(b *testing.B) {
+	tenantID, videoIDs, _ := seedBenchTenant(b)
+	repo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+
+	b.Run("tenant", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := repo.RefreshSummaries(context.Background(), tenantID, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("video", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := repo.RefreshSummaries(context.Background(), tenantID, videoIDs[i%len(videoIDs):i%len(videoIDs)+1]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkVideoStatsRepository_GetSnapshots(b *testing.B) {
+	_, _, statsIDs := seedBenchTenant(b)
+	repo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.GetSnapshots(context.Background(), statsIDs[i%len(statsIDs)], 30); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkVideoStatsRepository_UpsertBatch upserts the benchmark tenant's
+// stats, one row per video and platform, the way a sync run rewrites them
+func BenchmarkVideoStatsRepository_UpsertBatch(b *testing.B) {
+	tenantID, videoIDs, _ := seedBenchTenant(b)
+	repo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+	platforms := []models.Platform{models.PlatformYouTube, models.PlatformTikTok, models.PlatformInstagram}
+
+	for _, size := range []int{50, 200, models.DefaultStatsBatchSize, 1000} {
+		b.Run(fmt.Sprintf("batch=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				stats := make([]*models.VideoStats, 0, len(videoIDs)*len(platforms))
+				for _, videoID := range videoIDs {
+					for _, platform := range platforms {
+						stats = append(stats, &models.VideoStats{
+							VideoID:    videoID,
+							Platform:   string(platform),
+							Views:      int64(i),
+							LastSyncAt: time.Now().UTC(),
+						})
+					}
+				}
+				b.StartTimer()
+				if err := repo.UpsertBatch(context.Background(), tenantID, stats, size); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(videoIDs)*len(platforms)), "rows/op")
+		})
+	}
+}
+
+func BenchmarkAnalyticsService_GetDashboardStats(b *testing.B) {
+	tenantID, _, _ := seedBenchTenant(b)
+	svc := services.NewAnalyticsService(repositories.NewVideoRepository(mysqlServer.DB.DB), repositories.NewWorkspaceRepository(mysqlServer.DB.DB), repositories.NewVideoStatsRepository(mysqlServer.DB.DB), repositories.NewTenantRepository(mysqlServer.DB.DB), nil, logger.New("error", "test"))
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.GetDashboardStats(ctx, tenantID); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAnalyticsService_GetVideosStats(b *testing.B) {
+	tenantID, videoIDs, _ := seedBenchTenant(b)
+	svc := services.NewAnalyticsService(repositories.NewVideoRepository(mysqlServer.DB.DB), repositories.NewWorkspaceRepository(mysqlServer.DB.DB), repositories.NewVideoStatsRepository(mysqlServer.DB.DB), repositories.NewTenantRepository(mysqlServer.DB.DB), nil, logger.New("error", "test"))
+	ctx := context.Background()
+	viewer := &models.User{ID: id.New(), TenantID: tenantID, Role: string(models.RoleAdmin)}
+	page := videoIDs[:20]
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.GetVideosStats(ctx, viewer, page); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+//go:build integration
+
+// Package integration runs repositories and services against a real MySQL
+// server started with testcontainers, so the suite only needs a Docker
+// daemon and runs the same locally and in CI:
+//
+//	go test -tags=integration ./test/integration/...
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcmysql "github.com/testcontainers/testcontainers-go/modules/mysql"
+	"gorm.io/gorm"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/pkg/db"
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+	"github.com/jibe0123/mysteryfactory/pkg/tenancy"
+)
+
+// mysqlImage matches the server version used by docker-compose
+const mysqlImage = "mysql:8.0"
+
+// MySQL is a migrated MySQL server running in a container
+type MySQL struct {
+	DB        *db.DB
+	DSN       string
+	container testcontainers.Container
+}
+
+// StartMySQL starts a MySQL container and applies the GORM schema
+func StartMySQL(ctx context.Context) (*MySQL, error) {
+	container, err := tcmysql.Run(ctx, mysqlImage,
+		tcmysql.WithDatabase("mysteryfactory_test"),
+		tcmysql.WithUsername("test"),
+		tcmysql.WithPassword("test"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start mysql container: %w", err)
+	}
+
+	dsn, err := container.ConnectionString(ctx, "charset=utf8mb4", "parseTime=True", "loc=UTC")
+	if err != nil {
+		_ = container.Terminate(ctx)
+		return nil, fmt.Errorf("failed to get mysql connection string: %w", err)
+	}
+
+	database, err := db.New(dsn)
+	if err != nil {
+		_ = container.Terminate(ctx)
+		return nil, err
+	}
+	if err := database.AutoMigrate(); err != nil {
+		database.Close()
+		_ = container.Terminate(ctx)
+		return nil, err
+	}
+
+	return &MySQL{DB: database, DSN: dsn, container: container}, nil
+}
+
+// Stop closes the connection and removes the container
+func (m *MySQL) Stop(ctx context.Context) error {
+	m.DB.Close()
+	return m.container.Terminate(ctx)
+}
+
+// Factory creates records for one tenant. Every test gets its own tenant so
+// tests share the database without truncating tables between them.
+type Factory struct {
+	t        *testing.T
+	db       *gorm.DB
+	TenantID string
+}
+
+// NewFactory creates a tenant and returns a factory scoped to it
+func NewFactory(t *testing.T, gdb *gorm.DB) *Factory {
+	t.Helper()
+	tenantID := id.New()
+	f := &Factory{t: t, db: gdb.WithContext(tenancy.WithTenant(context.Background(), tenantID)), TenantID: tenantID}
+	f.create(&models.Tenant{ID: tenantID, Name: "Tenant " + tenantID, Domain: tenantID, Status: "active"})
+	return f
+}
+
+// User creates an active user with the given role
+func (f *Factory) User(role models.UserRole) *models.User {
+	f.t.Helper()
+	userID := id.New()
+	user := &models.User{
+		ID:        userID,
+		TenantID:  f.TenantID,
+		Email:     userID + "@example.com",
+		Password:  "not-a-hash",
+		FirstName: "Test",
+		LastName:  string(role),
+		Role:      string(role),
+		Status:    string(models.StatusActive),
+	}
+	f.create(user)
+	return user
+}
+
+// Workspace creates a workspace owned by user
+func (f *Factory) Workspace(user *models.User) *models.Workspace {
+	f.t.Helper()
+	ws := &models.Workspace{ID: id.New(), TenantID: f.TenantID, UserID: user.ID, Name: "Workspace"}
+	f.create(ws)
+	return ws
+}
+
+// Video creates a ready video owned by user
+func (f *Factory) Video(user *models.User, tags ...string) *models.Video {
+	f.t.Helper()
+	video := &models.Video{
+		ID:       id.New(),
+		TenantID: f.TenantID,
+		UserID:   user.ID,
+		Title:    "Video",
+		FileName: "video.mp4",
+		FileSize: 1024,
+		Format:   "mp4",
+		Status:   string(models.StatusReady),
+		Metadata: "{}",
+		Tags:     tags,
+	}
+	f.create(video)
+	return video
+}
+
+// Stats creates platform stats for video
+func (f *Factory) Stats(video *models.Video, platform models.Platform, views int64) *models.VideoStats {
+	f.t.Helper()
+	stats := &models.VideoStats{
+		ID:         id.New(),
+		TenantID:   f.TenantID,
+		VideoID:    video.ID,
+		Platform:   string(platform),
+		Views:      views,
+		LastSyncAt: time.Now().UTC(),
+	}
+	f.create(stats)
+	return stats
+}
+
+// PublicationJob creates a pending publication job for video
+func (f *Factory) PublicationJob(video *models.Video, platform models.Platform) *models.PublicationJob {
+	f.t.Helper()
+	job := &models.PublicationJob{
+		ID:         id.New(),
+		TenantID:   f.TenantID,
+		VideoID:    video.ID,
+		UserID:     video.UserID,
+		Platform:   string(platform),
+		Status:     string(models.PublicationPending),
+		Config:     map[string]interface{}{},
+		MaxRetries: 3,
+	}
+	f.create(job)
+	return job
+}
+
+func (f *Factory) create(record interface{}) {
+	f.t.Helper()
+	if err := f.db.Create(record).Error; err != nil {
+		f.t.Fatalf("failed to create %T: %v", record, err)
+	}
+}
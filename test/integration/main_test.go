@@ -0,0 +1,30 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+)
+
+// mysqlServer is shared by every test in the package
+var mysqlServer *MySQL
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+
+	var err error
+	mysqlServer, err = StartMySQL(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "integration: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	if err := mysqlServer.Stop(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "integration: failed to stop mysql: %v\n", err)
+	}
+	os.Exit(code)
+}
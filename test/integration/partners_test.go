@@ -0,0 +1,133 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/partners"
+	"github.com/jibe0123/mysteryfactory/internal/repositories"
+	pkgpartners "github.com/jibe0123/mysteryfactory/pkg/partners"
+)
+
+// stubClient stands in for a partner platform
+type stubClient struct {
+	externalID string
+	stats      *models.VideoStats
+	publishErr error
+}
+
+func (c *stubClient) Authenticate(context.Context, *models.Workspace) error { return nil }
+
+func (c *stubClient) Upload(context.Context, *models.Video) (string, error) { return c.externalID, nil }
+
+func (c *stubClient) Publish(context.Context, *models.Video, *models.Workspace) error {
+	return c.publishErr
+}
+
+func (c *stubClient) FetchStats(context.Context, *models.Video) (*models.VideoStats, error) {
+	stats := *c.stats
+	return &stats, nil
+}
+
+func (c *stubClient) Unpublish(context.Context, *models.Video) error { return nil }
+
+func stubService(client *stubClient) *partners.Service {
+	return partners.NewService(func(string) (pkgpartners.Client, error) { return client, nil })
+}
+
+func TestStatsSync_UpdatesStatsAndKeepsHistory(t *testing.T) {
+	f := NewFactory(t, mysqlServer.DB.DB)
+	statsRepo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+	statsService := models.NewVideoStatsService(statsRepo)
+
+	user := f.User(models.RoleEditor)
+	ws := f.Workspace(user)
+	video := f.Video(user)
+	stored := f.Stats(video, models.PlatformYouTube, 100)
+
+	client := &stubClient{stats: &models.VideoStats{Views: 450, Likes: 30, Comments: 4}}
+	fetched, err := stubService(client).SyncStats(context.Background(), ws, video, models.PlatformYouTube)
+	require.NoError(t, err)
+
+	_, err = statsService.UpdateVideoStats(context.Background(), f.TenantID, stored.ID, map[string]interface{}{
+		"views":    fetched.Views,
+		"likes":    fetched.Likes,
+		"comments": fetched.Comments,
+	})
+	require.NoError(t, err)
+
+	got, err := statsRepo.GetByVideoAndPlatform(context.Background(), f.TenantID, video.ID, string(models.PlatformYouTube))
+	require.NoError(t, err)
+	assert.Equal(t, int64(450), got.Views)
+	assert.Equal(t, int64(30), got.Likes)
+
+	snapshots, err := statsRepo.GetSnapshots(context.Background(), stored.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, int64(100), snapshots[0].Views, "the previous values are kept as a snapshot")
+}
+
+func TestPublication_CompletesJobAndRecordsExternalID(t *testing.T) {
+	f := NewFactory(t, mysqlServer.DB.DB)
+	videoRepo := repositories.NewVideoRepository(mysqlServer.DB.DB)
+	jobRepo := repositories.NewPublicationJobRepository(mysqlServer.DB.DB)
+	statsRepo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+
+	user := f.User(models.RolePublisher)
+	ws := f.Workspace(user)
+	video := f.Video(user)
+	job := f.PublicationJob(video, models.PlatformTikTok)
+
+	client := &stubClient{externalID: "tt-123", stats: &models.VideoStats{Views: 12}}
+	stats, err := stubService(client).PublishVideo(context.Background(), ws, video, models.PlatformTikTok)
+	require.NoError(t, err)
+
+	require.NoError(t, videoRepo.Update(context.Background(), video))
+	stats.TenantID, stats.VideoID, stats.Platform, stats.ExternalID = f.TenantID, video.ID, string(models.PlatformTikTok), video.TikTokID
+	require.NoError(t, statsRepo.Create(context.Background(), stats))
+	job.ExternalID = video.TikTokID
+	job.Status = string(models.PublicationCompleted)
+	require.NoError(t, jobRepo.Update(context.Background(), job))
+
+	gotVideo, err := videoRepo.GetByID(context.Background(), f.TenantID, video.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "tt-123", gotVideo.TikTokID)
+
+	completed, err := jobRepo.GetByStatus(context.Background(), f.TenantID, models.PublicationCompleted, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, completed, 1)
+	assert.Equal(t, "tt-123", completed[0].ExternalID)
+
+	gotStats, err := statsRepo.GetByVideoAndPlatform(context.Background(), f.TenantID, video.ID, string(models.PlatformTikTok))
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), gotStats.Views)
+}
+
+func TestPublication_FailureIsRetried(t *testing.T) {
+	f := NewFactory(t, mysqlServer.DB.DB)
+	jobRepo := repositories.NewPublicationJobRepository(mysqlServer.DB.DB)
+
+	user := f.User(models.RolePublisher)
+	ws := f.Workspace(user)
+	video := f.Video(user)
+	job := f.PublicationJob(video, models.PlatformInstagram)
+
+	client := &stubClient{externalID: "ig-1", publishErr: errors.New("quota exceeded")}
+	_, err := stubService(client).PublishVideo(context.Background(), ws, video, models.PlatformInstagram)
+	require.Error(t, err)
+
+	require.NoError(t, jobRepo.IncrementRetryCount(context.Background(), f.TenantID, job.ID))
+	require.NoError(t, jobRepo.UpdateStatus(context.Background(), f.TenantID, job.ID, models.PublicationFailed))
+
+	got, err := jobRepo.GetByID(context.Background(), f.TenantID, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.RetryCount)
+	assert.Equal(t, string(models.PublicationFailed), got.Status)
+}
@@ -0,0 +1,257 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/internal/models"
+	"github.com/jibe0123/mysteryfactory/internal/repositories"
+)
+
+func TestVideoRepository_RoundTripsTags(t *testing.T) {
+	f := NewFactory(t, mysqlServer.DB.DB)
+	repo := repositories.NewVideoRepository(mysqlServer.DB.DB)
+	video := f.Video(f.User(models.RoleEditor), "mystery", "history")
+
+	got, err := repo.GetByID(context.Background(), f.TenantID, video.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mystery", "history"}, got.Tags)
+
+	got.Title = "Renamed"
+	require.NoError(t, repo.Update(context.Background(), got))
+	got, err = repo.GetByID(context.Background(), f.TenantID, video.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", got.Title)
+}
+
+func TestVideoRepository_IsolatesTenants(t *testing.T) {
+	owner := NewFactory(t, mysqlServer.DB.DB)
+	other := NewFactory(t, mysqlServer.DB.DB)
+	repo := repositories.NewVideoRepository(mysqlServer.DB.DB)
+	video := owner.Video(owner.User(models.RoleEditor))
+
+	_, err := repo.GetByID(context.Background(), other.TenantID, video.ID)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	require.NoError(t, repo.Delete(context.Background(), other.TenantID, video.ID))
+	_, err = repo.GetByID(context.Background(), owner.TenantID, video.ID)
+	assert.NoError(t, err, "another tenant cannot delete the video")
+
+	videos, err := repo.List(context.Background(), other.TenantID, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, videos)
+}
+
+func TestUserRepository_EncryptsNamesTransparently(t *testing.T) {
+	f := NewFactory(t, mysqlServer.DB.DB)
+	repo := repositories.NewUserRepository(mysqlServer.DB.DB)
+	user := f.User(models.RoleViewer)
+
+	got, err := repo.GetByEmail(context.Background(), f.TenantID, user.Email)
+	require.NoError(t, err)
+	assert.Equal(t, user.FirstName, got.FirstName)
+	assert.Equal(t, user.LastName, got.LastName)
+}
+
+func TestVideoStatsRepository_AggregatesAndSnapshots(t *testing.T) {
+	f := NewFactory(t, mysqlServer.DB.DB)
+	repo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+	video := f.Video(f.User(models.RoleEditor))
+	youtube := f.Stats(video, models.PlatformYouTube, 1000)
+	f.Stats(video, models.PlatformTikTok, 250)
+
+	agg, err := repo.GetAggregatedStats(context.Background(), f.TenantID, video.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1250), agg.TotalViews)
+
+	require.NoError(t, repo.CreateSnapshot(context.Background(), &models.VideoStatsSnapshot{StatsID: youtube.ID, Views: 900, CreatedAt: time.Now().Add(-time.Hour)}))
+	require.NoError(t, repo.CreateSnapshot(context.Background(), &models.VideoStatsSnapshot{StatsID: youtube.ID, Views: 1000}))
+	snapshots, err := repo.GetSnapshots(context.Background(), youtube.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, int64(1000), snapshots[0].Views, "newest snapshot first")
+}
+
+func TestVideoStatsRepository_AggregatesVideosInOneQuery(t *testing.T) {
+	f := NewFactory(t, mysqlServer.DB.DB)
+	other := NewFactory(t, mysqlServer.DB.DB)
+	repo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+	first := f.Video(f.User(models.RoleEditor))
+	f.Stats(first, models.PlatformYouTube, 1000)
+	f.Stats(first, models.PlatformInstagram, 40)
+	second := f.Video(f.User(models.RoleEditor))
+	f.Stats(second, models.PlatformTikTok, 300)
+	foreign := other.Video(other.User(models.RoleEditor))
+	other.Stats(foreign, models.PlatformTikTok, 999)
+
+	aggs, err := repo.GetAggregatedStatsForVideos(context.Background(), f.TenantID, []string{first.ID, second.ID, foreign.ID})
+	require.NoError(t, err)
+	views := make(map[string]int64)
+	for _, agg := range aggs {
+		views[agg.VideoID] = agg.TotalViews
+	}
+	assert.Equal(t, map[string]int64{first.ID: 1040, second.ID: 300}, views, "other tenants' videos are not aggregated")
+}
+
+func TestVideoStatsRepository_UpsertBatchUpdatesExistingRows(t *testing.T) {
+	f := NewFactory(t, mysqlServer.DB.DB)
+	repo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+	video := f.Video(f.User(models.RoleEditor))
+	existing := f.Stats(video, models.PlatformYouTube, 100)
+
+	stats := []*models.VideoStats{
+		{VideoID: video.ID, Platform: string(models.PlatformYouTube), Views: 150},
+		{VideoID: video.ID, Platform: string(models.PlatformTikTok), Views: 40},
+	}
+	for _, s := range stats {
+		s.LastSyncAt = time.Now().UTC()
+	}
+	require.NoError(t, repo.UpsertBatch(context.Background(), f.TenantID, stats, 1))
+
+	rows, err := repo.GetByVideoID(context.Background(), f.TenantID, video.ID)
+	require.NoError(t, err)
+	require.Len(t, rows, 2, "the YouTube row is updated in place")
+	youtube, err := repo.GetByVideoAndPlatform(context.Background(), f.TenantID, video.ID, string(models.PlatformYouTube))
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, youtube.ID)
+	assert.Equal(t, int64(150), youtube.Views)
+}
+
+func TestVideoStatsRepository_SummariesFollowStatsWrites(t *testing.T) {
+	f := NewFactory(t, mysqlServer.DB.DB)
+	repo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+	first := f.Video(f.User(models.RoleEditor))
+	second := f.Video(f.User(models.RoleEditor))
+
+	newStats := func(video *models.Video, platform models.Platform, views, likes int64) *models.VideoStats {
+		return &models.VideoStats{
+			TenantID: f.TenantID, VideoID: video.ID, Platform: string(platform), Views: views, Likes: likes,
+			LastSyncAt: time.Now().UTC(),
+		}
+	}
+	require.NoError(t, repo.UpsertBatch(context.Background(), f.TenantID, []*models.VideoStats{
+		newStats(first, models.PlatformYouTube, 500, 10),
+		newStats(first, models.PlatformTikTok, 300, 90),
+		newStats(second, models.PlatformYouTube, 600, 20),
+	}, 0))
+
+	top, err := repo.GetTopPerforming(context.Background(), f.TenantID, "views", 10)
+	require.NoError(t, err)
+	require.Len(t, top, 2)
+	assert.Equal(t, first.ID, top[0].ID, "views are summed across platforms")
+	assert.Equal(t, int64(800), top[0].Views)
+	assert.Equal(t, 2, top[0].PlatformCount)
+	assert.InDelta(t, 0.125, top[0].Engagement, 1e-4)
+
+	tiktok, err := repo.GetByVideoAndPlatform(context.Background(), f.TenantID, first.ID, string(models.PlatformTikTok))
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(context.Background(), f.TenantID, tiktok.ID))
+
+	top, err = repo.GetTopPerforming(context.Background(), f.TenantID, "views", 10)
+	require.NoError(t, err)
+	require.Len(t, top, 2)
+	assert.Equal(t, second.ID, top[0].ID, "deleting stats updates the ranking")
+}
+
+func TestVideoStatsRepository_BreakdownsFollowStatsWrites(t *testing.T) {
+	f := NewFactory(t, mysqlServer.DB.DB)
+	repo := repositories.NewVideoStatsRepository(mysqlServer.DB.DB)
+	video := f.Video(f.User(models.RoleEditor))
+	ctx := context.Background()
+	all := models.BreakdownFilter{From: video.CreatedAt.Add(-time.Hour), To: time.Now().UTC().Add(time.Hour)}
+
+	require.NoError(t, repo.UpsertBatch(ctx, f.TenantID, []*models.VideoStats{
+		{VideoID: video.ID, Platform: string(models.PlatformYouTube), Views: 100, LastSyncAt: time.Now().UTC(),
+			DeviceTypes: map[string]int64{models.DeviceMobile: 70, models.DeviceDesktop: 30},
+			Locations:   map[string]int64{"US": 60, "FR": 40}},
+		{VideoID: video.ID, Platform: string(models.PlatformTikTok), Views: 50, LastSyncAt: time.Now().UTC(),
+			DeviceTypes: map[string]int64{models.DeviceMobile: 50}},
+	}, 0))
+
+	devices, err := repo.SumViewsByDevice(ctx, f.TenantID, all)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	assert.Equal(t, &models.BreakdownViews{Key: models.DeviceMobile, Views: 120}, devices[0])
+
+	// Stats synced again replace their views; stats without keep theirs
+	require.NoError(t, repo.UpsertBatch(ctx, f.TenantID, []*models.VideoStats{
+		{VideoID: video.ID, Platform: string(models.PlatformYouTube), Views: 200, LastSyncAt: time.Now().UTC(),
+			DeviceTypes: map[string]int64{models.DeviceTV: 200}},
+	}, 0))
+	youtube := all
+	youtube.Platform = string(models.PlatformYouTube)
+	devices, err = repo.SumViewsByDevice(ctx, f.TenantID, youtube)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.BreakdownViews{{Key: models.DeviceTV, Views: 200}}, devices)
+	countries, err := repo.SumViewsByCountry(ctx, f.TenantID, youtube)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.BreakdownViews{{Key: "US", Views: 60}, {Key: "FR", Views: 40}}, countries)
+
+	before := all
+	before.To = video.CreatedAt.Add(-time.Minute)
+	countries, err = repo.SumViewsByCountry(ctx, f.TenantID, before)
+	require.NoError(t, err)
+	assert.Empty(t, countries, "the period filters videos by upload")
+}
+
+func TestPublicationJobRepository_ScheduledJobsSpanTenants(t *testing.T) {
+	first := NewFactory(t, mysqlServer.DB.DB)
+	second := NewFactory(t, mysqlServer.DB.DB)
+	repo := repositories.NewPublicationJobRepository(mysqlServer.DB.DB)
+
+	var ids []string
+	for _, f := range []*Factory{first, second} {
+		job := f.PublicationJob(f.Video(f.User(models.RolePublisher)), models.PlatformYouTube)
+		job.Status = string(models.PublicationScheduled)
+		job.ScheduledAt.Time, job.ScheduledAt.Valid = time.Now().UTC().Add(-time.Minute), true
+		require.NoError(t, repo.Update(context.Background(), job))
+		ids = append(ids, job.ID)
+	}
+
+	jobs, err := repo.GetScheduledJobs(context.Background(), time.Now().UTC(), 1000)
+	require.NoError(t, err)
+	var found []string
+	for _, job := range jobs {
+		found = append(found, job.ID)
+	}
+	assert.Subset(t, found, ids)
+}
+
+func TestVideoRepository_ListArchivableSkipsPublishedAndRecentVideos(t *testing.T) {
+	f := NewFactory(t, mysqlServer.DB.DB)
+	repo := repositories.NewVideoRepository(mysqlServer.DB.DB)
+	user := f.User(models.RoleEditor)
+	old := time.Now().UTC().AddDate(-1, 0, 0)
+
+	stale, recent, published := f.Video(user), f.Video(user), f.Video(user)
+	for _, video := range []*models.Video{stale, published} {
+		require.NoError(t, f.db.Model(video).UpdateColumns(map[string]interface{}{"s3_key": video.ID + ".mp4", "updated_at": old}).Error)
+	}
+	require.NoError(t, f.db.Model(recent).UpdateColumn("s3_key", recent.ID+".mp4").Error)
+	require.NoError(t, f.db.Model(published).UpdateColumn("youtube_id", "yt-"+published.ID).Error)
+
+	videos, err := repo.ListArchivable(context.Background(), f.TenantID, time.Now().UTC().AddDate(0, -6, 0), 10)
+	require.NoError(t, err)
+	require.Len(t, videos, 1)
+	assert.Equal(t, stale.ID, videos[0].ID)
+
+	now := time.Now().UTC()
+	videos[0].Status = string(models.StatusArchived)
+	videos[0].RestoreStatus = models.RestoreInProgress
+	videos[0].RestoreRequestedAt = &now
+	require.NoError(t, repo.Update(context.Background(), videos[0]))
+
+	restoring, err := repo.ListRestoring(context.Background(), 1000)
+	require.NoError(t, err)
+	var found []string
+	for _, video := range restoring {
+		found = append(found, video.ID)
+	}
+	assert.Contains(t, found, stale.ID)
+}
@@ -0,0 +1,57 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jibe0123/mysteryfactory/pkg/id"
+)
+
+func TestJobLease_OneHolderAtATime(t *testing.T) {
+	ctx := context.Background()
+	database := mysqlServer.DB
+	job := id.New()
+
+	held, err := database.AcquireLease(ctx, job, "pod-a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	held, err = database.AcquireLease(ctx, job, "pod-b", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held, "pod-b cannot take a live lease")
+
+	held, err = database.AcquireLease(ctx, job, "pod-a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held, "the holder renews its lease")
+
+	require.NoError(t, database.ReleaseLease(ctx, job, "pod-b"), "releasing a lease held by another instance is a no-op")
+	held, err = database.AcquireLease(ctx, job, "pod-b", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	require.NoError(t, database.ReleaseLease(ctx, job, "pod-a"))
+	held, err = database.AcquireLease(ctx, job, "pod-b", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held, "a released lease is free")
+}
+
+func TestJobLease_ExpiredLeaseIsTakenOver(t *testing.T) {
+	ctx := context.Background()
+	database := mysqlServer.DB
+	job := id.New()
+
+	held, err := database.AcquireLease(ctx, job, "pod-a", 50*time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, held)
+
+	time.Sleep(100 * time.Millisecond)
+	held, err = database.AcquireLease(ctx, job, "pod-b", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held)
+}
@@ -0,0 +1,67 @@
+// k6 load profile for the dashboard and stats endpoints.
+//
+//   k6 run -e BASE_URL=http://localhost:8080 test/load/stats.js
+//
+// The thresholds are the P95 latency budgets; k6 exits non-zero when one is
+// exceeded. Run `make seed` first so the demo tenant has data to aggregate.
+import http from 'k6/http';
+import { check, group } from 'k6';
+
+const BASE_URL = __ENV.BASE_URL || 'http://localhost:8080';
+const EMAIL = __ENV.LOAD_EMAIL || 'viewer@demo.local';
+const PASSWORD = __ENV.LOAD_PASSWORD || 'demo1234';
+
+export const options = {
+  scenarios: {
+    dashboard: {
+      executor: 'ramping-vus',
+      startVUs: 0,
+      stages: [
+        { duration: '30s', target: 20 },
+        { duration: '2m', target: 50 },
+        { duration: '30s', target: 0 },
+      ],
+    },
+  },
+  thresholds: {
+    http_req_failed: ['rate<0.01'],
+    'http_req_duration{endpoint:dashboard}': ['p(95)<300'],
+    'http_req_duration{endpoint:videos_stats}': ['p(95)<250'],
+    'http_req_duration{endpoint:video_stats}': ['p(95)<150'],
+    'http_req_duration{endpoint:video_history}': ['p(95)<200'],
+    'http_req_duration{endpoint:performance}': ['p(95)<400'],
+  },
+};
+
+export function setup() {
+  const res = http.post(`${BASE_URL}/api/v1/auth/login`, JSON.stringify({ email: EMAIL, password: PASSWORD }), {
+    headers: { 'Content-Type': 'application/json' },
+  });
+  check(res, { 'login succeeded': (r) => r.status === 200 });
+  return { token: res.json('token') };
+}
+
+export default function (data) {
+  const params = (endpoint) => ({
+    headers: { Authorization: `Bearer ${data.token}` },
+    tags: { endpoint },
+  });
+
+  group('dashboard', () => {
+    check(http.get(`${BASE_URL}/api/v1/stats/dashboard`, params('dashboard')), { 'dashboard 200': (r) => r.status === 200 });
+  });
+
+  group('stats', () => {
+    const list = http.get(`${BASE_URL}/api/v1/stats/videos?limit=20`, params('videos_stats'));
+    check(list, { 'videos stats 200': (r) => r.status === 200 });
+
+    const items = list.json('data') || [];
+    const videoID = items.length > 0 ? items[Math.floor(Math.random() * items.length)].video_id : 'unknown';
+    check(http.get(`${BASE_URL}/api/v1/stats/videos/${videoID}`, params('video_stats')), { 'video stats 200': (r) => r.status === 200 });
+
+    check(http.get(`${BASE_URL}/api/v1/stats/videos/${videoID}/history?days=30`, params('video_history')), {
+      'video history 200': (r) => r.status === 200,
+    });
+    check(http.get(`${BASE_URL}/api/v1/stats/performance?period=30d`, params('performance')), { 'performance 200': (r) => r.status === 200 });
+  });
+}